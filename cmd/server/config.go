@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s-dashboard/pkg/config"
+)
+
+const configUsage = `usage:
+  kgo config validate [path]   check a config file for unknown keys and invalid values
+  kgo config print [flags]     print the effective config (defaults + file + env + flags)`
+
+// runConfigCommand runs a "config" subcommand and returns the exit code
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, configUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "validate":
+		return validateConfig(args[1:], stdout, stderr)
+	case "print":
+		return printConfig(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown config command %q\n%s\n", args[0], configUsage)
+		return 2
+	}
+}
+
+// validateConfig prints "ok" or every problem in the config file
+func validateConfig(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 1 {
+		fmt.Fprintln(stderr, configUsage)
+		return 2
+	}
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	cfg, err := config.LoadConfig(path)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Fprintln(stdout, err)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, "ok")
+	return 0
+}
+
+// printConfig prints the effective configuration with secrets masked
+func printConfig(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "path to configuration file")
+	kubeconfig := flags.String("kubeconfig", "", "path to kubeconfig file (overrides config file)")
+	port := flags.String("port", "", "server port (overrides config file)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	cfg.ApplyFlags(*kubeconfig, *port)
+
+	if source := cfg.Source(); source != "" {
+		fmt.Fprintf(stdout, "# Effective configuration, loaded from %s\n", source)
+	} else {
+		fmt.Fprintln(stdout, "# Effective configuration, no config file found")
+	}
+	if err := cfg.WriteEffective(stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// exitForConfigCommand runs "kgo config ..." when requested and exits
+func exitForConfigCommand() {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+}
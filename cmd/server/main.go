@@ -1,25 +1,55 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"k8s-dashboard/pkg/api"
 	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/crypto"
+	kgogrpc "k8s-dashboard/pkg/grpc"
 	"k8s-dashboard/pkg/k8s"
-	"k8s-dashboard/pkg/metrics"
 	"k8s-dashboard/pkg/tui"
+	"k8s-dashboard/pkg/version"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"k8s.io/klog/v2"
 )
 
+// credentialCheckInterval is how often the server checks the kubeconfig for
+// changed credentials
+const credentialCheckInterval = time.Minute
+
 func main() {
+	exitForConfigCommand()
+	exitForBackupCommand()
+	exitForSnapshotCommand()
+
 	configPath := flag.String("config", "", "path to configuration file")
 	kubeconfig := flag.String("kubeconfig", "", "path to kubeconfig file (overrides config file)")
 	port := flag.String("port", "", "server port (overrides config file)")
 	tuiMode := flag.Bool("tui", false, "run in terminal UI mode")
+	grpcPort := flag.String("grpc-port", "", "also serve the gRPC API on this port")
+	grpcAddress := flag.String("grpc-address", "", "with -tui, load resources from the kgo gRPC server at this address instead of the kubeconfig")
+	noRestoreSession := flag.Bool("no-restore-session", false, "with -tui, start on the cluster overview instead of the namespace and view saved in ~/.kgo/session.json")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "how long to wait for in-flight requests on SIGINT or SIGTERM")
+	allowVersionSkew := flag.Bool("allow-version-skew", false, "with -grpc-address, connect to a kgo server of another major version")
+	printVersion := flag.Bool("version", false, "print the kgo version and exit")
 	flag.Parse()
+	if *printVersion {
+		fmt.Println(version.Version)
+		return
+	}
+	if *shutdownTimeout <= 0 {
+		klog.Fatalf("-shutdown-timeout must be positive, got %s", *shutdownTimeout)
+	}
 
 	// Load configuration
 	cfg, err := config.LoadConfig(*configPath)
@@ -28,72 +58,213 @@ func main() {
 	}
 
 	// Override config with command line flags
-	if *kubeconfig != "" {
-		cfg.Kubernetes.Kubeconfig = *kubeconfig
+	cfg.ApplyFlags(*kubeconfig, *port)
+	if err := cfg.Validate(); err != nil {
+		klog.Fatalf("Invalid config:\n%v", err)
+	}
+	recipients := cfg.Crypto.AgeRecipients
+	if cfg.Crypto.Backend == crypto.BackendKMS {
+		recipients = cfg.Crypto.KMSKeys
+	}
+	configMapKeys, err := crypto.LoadKeys(cfg.Crypto.Backend, recipients, cfg.Crypto.AgeKeyFile)
+	if err != nil {
+		klog.Fatalf("Failed to load the configmap encryption keys: %v", err)
 	}
-	if *port != "" {
-		cfg.Server.Port = *port
+
+	if *tuiMode && *grpcAddress != "" {
+		runGRPCTUI(*grpcAddress, cfg, configMapKeys, !*noRestoreSession, *allowVersionSkew)
+		return
+	}
+
+	credentials, err := k8s.NewCredentialReloader(cfg.Kubernetes.Kubeconfig)
+	if err != nil {
+		klog.Fatalf("Failed to load k8s config: %v", err)
 	}
+	clientInfo := credentials.Info()
 
-	clientset, err := k8s.NewClient(cfg.Kubernetes.Kubeconfig)
+	clientset, err := credentials.Clientset()
 	if err != nil {
 		klog.Fatalf("Failed to create k8s client: %v", err)
 	}
 
+	dynamicClient, err := credentials.DynamicClient()
+	if err != nil {
+		klog.Fatalf("Failed to create dynamic k8s client: %v", err)
+	}
+
+	guard, err := k8s.NewNamespaceGuard(cfg.Kubernetes.ProtectedNamespaces)
+	if err != nil {
+		klog.Fatalf("Invalid protected namespaces: %v", err)
+	}
+
 	if *tuiMode {
+		logFile, restoreLogs := redirectLogs(cfg)
+		defer restoreLogs()
+
 		// Run TUI directly with clientset
-		tui, err := tui.NewTUI(clientset)
+		tui, err := tui.NewTUI(tui.NewClusterSource(clientset), cfg)
 		if err != nil {
 			klog.Fatalf("Failed to create TUI: %v", err)
 		}
+		tui.SetLogFile(logFile)
+		tui.SetDynamicClient(dynamicClient)
+		tui.SetClientInfo(clientInfo)
+		tui.SetConfigMapKeys(configMapKeys)
+		setSession(tui, !*noRestoreSession)
+
+		metricsClient, err := credentials.MetricsClient()
+		if err != nil {
+			klog.Fatalf("Failed to create metrics client: %v", err)
+		}
+		tui.SetMetricsClientset(metricsClient)
+
+		restConfig, err := k8s.NewRESTConfig(cfg.Kubernetes.Kubeconfig)
+		if err != nil {
+			klog.Fatalf("Failed to create exec config: %v", err)
+		}
+		tui.SetPodExec(k8s.NewPodExec(clientset, restConfig))
 
 		if err := tui.Run(); err != nil {
 			klog.Fatalf("TUI error: %v", err)
 		}
 	} else {
 		// Run web server
-		handler := api.NewHandler(clientset)
-		resourceHandler := api.NewResourceHandler(clientset)
-		metricsHandler := metrics.NewMetricsHandler(clientset)
+		var coalescer *k8s.ListCoalescer
+		if cfg.Server.ListCoalescing {
+			ttl := time.Duration(cfg.Server.ListCoalesceTTLMs) * time.Millisecond
+			coalescer = k8s.NewListCoalescer(cfg.Kubernetes.Context, ttl)
+		}
+
+		imagePolicy := k8s.NewImagePolicy(cfg.Features.AllowedRegistries)
+		manifestFetcher := k8s.NewManifestFetcher(cfg.Features.AllowedManifestHosts, cfg.Features.AllowedManifestSchemes)
+
+		metricsClient, err := credentials.MetricsClient()
+		if err != nil {
+			klog.Fatalf("Failed to create metrics client: %v", err)
+		}
 
 		r := gin.Default()
 		r.Use(cors.Default())
+		if cfg.Server.CompressionLevel > 0 {
+			r.Use(api.CompressMiddlewareWithLevel(cfg.Server.CompressionLevel))
+		}
+		api.RegisterRoutes(r, clientset, api.RouterOptions{
+			Guard:               guard,
+			Coalescer:           coalescer,
+			DynamicClient:       dynamicClient,
+			EnableTokenCreation: cfg.Features.EnableTokenCreation,
+			RequiredAnnotations: cfg.Policies.RequiredAnnotations,
+			ImagePolicy:         imagePolicy,
+			ManifestFetcher:     manifestFetcher,
+			ConfigMapKeys:       configMapKeys,
+			MetricsClientset:    metricsClient,
+			ClientInfo:          clientInfo,
+			Credentials:         credentials,
+			EnablePprof:         cfg.Server.EnablePprof,
+		})
+
+		// In-flight requests get -shutdown-timeout to finish on SIGINT or SIGTERM
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		// Credentials inline in the kubeconfig are read once, so changes to
+		// them are picked up by checking it
+		go credentials.Run(ctx, credentialCheckInterval)
 
-		v1 := r.Group("/api/v1")
-		{
-			// Pod operations
-			v1.GET("/pods", handler.ListPods)
-			v1.POST("/pods/:namespace", handler.CreatePod)
-			v1.PUT("/pods/:namespace/:name", handler.UpdatePod)
-			v1.DELETE("/pods/:namespace/:name", handler.DeletePod)
-			v1.GET("/pods/watch", handler.WatchPods)
-			v1.GET("/pods/:namespace/:name/logs", resourceHandler.GetPodLogs)
-			v1.GET("/pods/:namespace/:name/exec", resourceHandler.ExecPod)
-
-			// Deployment operations
-			v1.GET("/deployments", resourceHandler.ListDeployments)
-			v1.POST("/deployments/:namespace", resourceHandler.CreateDeployment)
-			v1.PUT("/deployments/:namespace/:name", resourceHandler.UpdateDeployment)
-			v1.DELETE("/deployments/:namespace/:name", resourceHandler.DeleteDeployment)
-
-			// Service operations
-			v1.GET("/services", resourceHandler.ListServices)
-			v1.POST("/services/:namespace", resourceHandler.CreateService)
-			v1.PUT("/services/:namespace/:name", resourceHandler.UpdateService)
-			v1.DELETE("/services/:namespace/:name", resourceHandler.DeleteService)
-
-			// ConfigMap operations
-			v1.GET("/configmaps", resourceHandler.ListConfigMaps)
-			v1.POST("/configmaps/:namespace", resourceHandler.CreateConfigMap)
-			v1.PUT("/configmaps/:namespace/:name", resourceHandler.UpdateConfigMap)
-			v1.DELETE("/configmaps/:namespace/:name", resourceHandler.DeleteConfigMap)
-
-			// Metrics operations
-			v1.GET("/metrics/cluster", metricsHandler.GetClusterMetrics)
-			v1.GET("/metrics/namespace/:namespace", metricsHandler.GetNamespaceMetrics)
+		grpcDone := make(chan struct{})
+		if *grpcPort != "" {
+			lis, err := net.Listen("tcp", ":"+*grpcPort)
+			if err != nil {
+				klog.Fatalf("Failed to listen for gRPC: %v", err)
+			}
+			klog.Info("Starting gRPC server on :" + *grpcPort)
+			go func() {
+				defer close(grpcDone)
+				server := kgogrpc.NewServer(clientset, guard)
+				server.SetImagePolicy(imagePolicy)
+				var opts []kgogrpc.ServeOption
+				if cfg.Server.EnablePprof {
+					opts = append(opts, kgogrpc.WithChannelz())
+				}
+				if err := kgogrpc.Serve(ctx, lis, server, *shutdownTimeout, opts...); err != nil {
+					klog.Errorf("gRPC server error: %v", err)
+				}
+			}()
+		} else {
+			close(grpcDone)
 		}
 
+		lis, err := net.Listen("tcp", ":"+cfg.Server.Port)
+		if err != nil {
+			klog.Fatalf("Failed to listen: %v", err)
+		}
 		klog.Info("Starting API server on :" + cfg.Server.Port)
-		r.Run(":" + cfg.Server.Port)
+		if err := api.Serve(ctx, &http.Server{Handler: r}, lis, *shutdownTimeout); err != nil {
+			klog.Errorf("API server error: %v", err)
+		}
+		stop()
+		<-grpcDone
+		klog.Info("Server stopped")
+	}
+}
+
+// redirectLogs sends klog to ui.logFile while the TUI runs, returning the
+// file and the function restoring logging to stderr. Logging stays on stderr
+// when the file cannot be opened.
+func redirectLogs(cfg *config.Config) (string, func()) {
+	path := cfg.UI.LogFile
+	if path == "" {
+		var err error
+		if path, err = tui.DefaultLogPath(); err != nil {
+			klog.Warningf("Logging to stderr: %v", err)
+			return "", func() {}
+		}
+	}
+	restore, err := tui.RedirectKlog(path, int64(cfg.UI.LogFileMaxSizeMB)<<20)
+	if err != nil {
+		klog.Warningf("Logging to stderr: %v", err)
+		return "", func() {}
+	}
+	return path, restore
+}
+
+// setSession saves the TUI's session to ~/.kgo/session.json, and restores
+// it on start when restore is set
+func setSession(ui *tui.TUI, restore bool) {
+	path, err := tui.DefaultSessionPath()
+	if err != nil {
+		klog.Warningf("Not saving the TUI session: %v", err)
+		return
+	}
+	ui.SetSession(path, restore)
+}
+
+// runGRPCTUI runs the TUI on the resources of a kgo gRPC server. Operations
+// that need the cluster's API directly are hidden. A server of another major
+// version is refused unless allowSkew is set. The values of encrypted
+// configmaps are decrypted with configMapKeys on this side.
+func runGRPCTUI(address string, cfg *config.Config, configMapKeys *crypto.Keys, restoreSession, allowSkew bool) {
+	var opts []kgogrpc.ClientOption
+	if allowSkew {
+		opts = append(opts, kgogrpc.WithAllowMajorSkew())
+	}
+	client, err := kgogrpc.NewClient(address, opts...)
+	if err != nil {
+		klog.Fatalf("Failed to connect to the gRPC server at %s: %v", address, err)
+	}
+	defer client.Close()
+
+	logFile, restoreLogs := redirectLogs(cfg)
+	defer restoreLogs()
+
+	ui, err := tui.NewTUI(tui.NewGRPCSource(client), cfg)
+	if err != nil {
+		klog.Fatalf("Failed to create TUI: %v", err)
+	}
+	ui.SetLogFile(logFile)
+	ui.SetConfigMapKeys(configMapKeys)
+	setSession(ui, restoreSession)
+	if err := ui.Run(); err != nil {
+		klog.Fatalf("TUI error: %v", err)
 	}
 }
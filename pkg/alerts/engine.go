@@ -0,0 +1,96 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Event is emitted when a rule fires for an object
+type Event struct {
+	Time      time.Time `json:"time"`
+	Rule      string    `json:"rule"`
+	Condition string    `json:"condition"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+}
+
+// Engine evaluates rules against objects and suppresses repeated firings for
+// the same object and rule within a cooldown
+type Engine struct {
+	rules    []*Rule
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	lastFired map[string]time.Time
+}
+
+// NewEngine creates an engine for the given rules
+func NewEngine(rules []*Rule, cooldown time.Duration) *Engine {
+	return &Engine{
+		rules:     rules,
+		cooldown:  cooldown,
+		now:       time.Now,
+		lastFired: make(map[string]time.Time),
+	}
+}
+
+// Evaluate returns an event for every rule that newly fires for the object
+func (e *Engine) Evaluate(obj runtime.Object) []Event {
+	if e == nil {
+		return nil
+	}
+	kind, namespace, name := describe(obj)
+
+	var events []Event
+	for _, rule := range e.rules {
+		if !rule.Matches(obj) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s|%s/%s/%s", rule.Name, kind, namespace, name)
+		now := e.now()
+
+		e.mu.Lock()
+		last, fired := e.lastFired[key]
+		if fired && now.Sub(last) < e.cooldown {
+			e.mu.Unlock()
+			continue
+		}
+		e.lastFired[key] = now
+		e.mu.Unlock()
+
+		events = append(events, Event{
+			Time:      now,
+			Rule:      rule.Name,
+			Condition: rule.Condition.String(),
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+		})
+	}
+	return events
+}
+
+// RunCommand runs a shell command with the event as JSON on stdin, for
+// example to raise a desktop notification
+func RunCommand(command string, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("alert command failed: %v: %s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package alerts
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestEngineDeduplicatesWithinCooldown(t *testing.T) {
+	failed, _ := NewRule("failed", "pod", "phase=Failed", nil)
+	crash, _ := NewRule("crash", "pod", "restarts>5", nil)
+	engine := NewEngine([]*Rule{failed, crash}, time.Minute)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	pod := newPod("default", "web", v1.PodFailed, 9)
+
+	events := engine.Evaluate(pod)
+	if len(events) != 2 {
+		t.Fatalf("Expected both rules to fire, got %+v", events)
+	}
+	if events[0].Rule != "failed" || events[0].Kind != "pod" || events[0].Namespace != "default" || events[0].Name != "web" {
+		t.Errorf("Unexpected event %+v", events[0])
+	}
+
+	// Same object and rules within the cooldown are suppressed
+	now = now.Add(30 * time.Second)
+	if events := engine.Evaluate(pod); len(events) != 0 {
+		t.Errorf("Expected no events within cooldown, got %+v", events)
+	}
+
+	// Another object fires independently
+	if events := engine.Evaluate(newPod("default", "api", v1.PodFailed, 0)); len(events) != 1 {
+		t.Errorf("Expected one event for another pod, got %+v", events)
+	}
+
+	// After the cooldown the rules fire again
+	now = now.Add(time.Minute)
+	if events := engine.Evaluate(pod); len(events) != 2 {
+		t.Errorf("Expected rules to fire again after cooldown, got %+v", events)
+	}
+}
+
+func TestNilEngine(t *testing.T) {
+	var engine *Engine
+	if events := engine.Evaluate(newPod("default", "web", v1.PodFailed, 0)); events != nil {
+		t.Errorf("Expected nil engine to produce no events, got %+v", events)
+	}
+}
+
+func TestRunCommandReceivesEventJSON(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	event := Event{Rule: "failed", Condition: "phase=Failed", Kind: "pod", Namespace: "default", Name: "web"}
+
+	if err := RunCommand("cat > "+out, event); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read command output: %v", err)
+	}
+	var received Event
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("Command did not receive JSON: %v", err)
+	}
+	if received.Rule != "failed" || received.Name != "web" {
+		t.Errorf("Unexpected event on stdin: %+v", received)
+	}
+
+	if err := RunCommand("exit 3", event); err == nil {
+		t.Error("Expected failing command to return an error")
+	}
+}
@@ -0,0 +1,212 @@
+package alerts
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// operators are the supported comparison operators, longest first so that
+// ">=" is not parsed as ">"
+var operators = []string{">=", "<=", "!=", "=", ">", "<"}
+
+// Condition compares a field of an object against a value, e.g. "restarts>5"
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// ParseCondition parses a condition of the form <field><op><value>
+func ParseCondition(s string) (*Condition, error) {
+	for _, op := range operators {
+		if i := strings.Index(s, op); i > 0 {
+			field := strings.TrimSpace(s[:i])
+			value := strings.TrimSpace(s[i+len(op):])
+			if field == "" || value == "" {
+				break
+			}
+			return &Condition{Field: field, Op: op, Value: value}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid condition %q: expected <field><op><value> with op one of %s", s, strings.Join(operators, " "))
+}
+
+// String returns the condition in its parseable form
+func (c *Condition) String() string {
+	return c.Field + c.Op + c.Value
+}
+
+// Eval reports whether an actual field value satisfies the condition. Values
+// that both parse as numbers are compared numerically, others as strings.
+func (c *Condition) Eval(actual string) bool {
+	a, aErr := strconv.ParseFloat(actual, 64)
+	b, bErr := strconv.ParseFloat(c.Value, 64)
+	if aErr == nil && bErr == nil {
+		switch c.Op {
+		case "=":
+			return a == b
+		case "!=":
+			return a != b
+		case ">":
+			return a > b
+		case "<":
+			return a < b
+		case ">=":
+			return a >= b
+		case "<=":
+			return a <= b
+		}
+		return false
+	}
+
+	switch c.Op {
+	case "=":
+		return strings.EqualFold(actual, c.Value)
+	case "!=":
+		return !strings.EqualFold(actual, c.Value)
+	case ">":
+		return actual > c.Value
+	case "<":
+		return actual < c.Value
+	case ">=":
+		return actual >= c.Value
+	case "<=":
+		return actual <= c.Value
+	}
+	return false
+}
+
+// Rule fires when an object of Kind in one of Namespaces satisfies Condition
+type Rule struct {
+	Name       string
+	Kind       string
+	Condition  *Condition
+	Namespaces []string
+}
+
+// NewRule creates a rule, validating its kind, condition and namespace globs
+func NewRule(name, kind, condition string, namespaces []string) (*Rule, error) {
+	kind = strings.ToLower(kind)
+	fields, ok := kindFields[kind]
+	if !ok {
+		return nil, fmt.Errorf("rule %s: unsupported kind %q", name, kind)
+	}
+
+	cond, err := ParseCondition(condition)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %v", name, err)
+	}
+	if !fields[cond.Field] {
+		return nil, fmt.Errorf("rule %s: unknown field %q for kind %s", name, cond.Field, kind)
+	}
+
+	for _, pattern := range namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid namespace pattern %q: %v", name, pattern, err)
+		}
+	}
+
+	if name == "" {
+		name = kind + " " + cond.String()
+	}
+	return &Rule{Name: name, Kind: kind, Condition: cond, Namespaces: namespaces}, nil
+}
+
+// Matches reports whether the rule applies to the object and its condition holds
+func (r *Rule) Matches(obj runtime.Object) bool {
+	kind, namespace, _ := describe(obj)
+	if kind != r.Kind || !r.inNamespace(namespace) {
+		return false
+	}
+	value, ok := fieldValue(obj, r.Condition.Field)
+	return ok && r.Condition.Eval(value)
+}
+
+// inNamespace reports whether the namespace matches the rule's namespace globs;
+// a rule without namespaces applies everywhere
+func (r *Rule) inNamespace(namespace string) bool {
+	if len(r.Namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range r.Namespaces {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// kindFields lists the fields a condition may reference for each kind
+var kindFields = map[string]map[string]bool{
+	"pod": {
+		"name": true, "phase": true, "restarts": true, "ready": true, "node": true, "reason": true,
+	},
+	"deployment": {
+		"name": true, "replicas": true, "readyReplicas": true, "unavailableReplicas": true,
+	},
+}
+
+// describe returns the kind, namespace and name of a supported object
+func describe(obj runtime.Object) (kind, namespace, name string) {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		return "pod", o.Namespace, o.Name
+	case *appsv1.Deployment:
+		return "deployment", o.Namespace, o.Name
+	}
+	return "", "", ""
+}
+
+// fieldValue extracts a condition field from an object as a string
+func fieldValue(obj runtime.Object, field string) (string, bool) {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		switch field {
+		case "name":
+			return o.Name, true
+		case "phase":
+			return string(o.Status.Phase), true
+		case "node":
+			return o.Spec.NodeName, true
+		case "restarts":
+			restarts := int32(0)
+			for _, cs := range o.Status.ContainerStatuses {
+				restarts += cs.RestartCount
+			}
+			return strconv.Itoa(int(restarts)), true
+		case "ready":
+			for _, cond := range o.Status.Conditions {
+				if cond.Type == v1.PodReady {
+					return strconv.FormatBool(cond.Status == v1.ConditionTrue), true
+				}
+			}
+			return "false", true
+		case "reason":
+			// The waiting reason of the first unhealthy container, e.g. CrashLoopBackOff
+			for _, cs := range o.Status.ContainerStatuses {
+				if cs.State.Waiting != nil {
+					return cs.State.Waiting.Reason, true
+				}
+			}
+			return o.Status.Reason, true
+		}
+	case *appsv1.Deployment:
+		switch field {
+		case "name":
+			return o.Name, true
+		case "replicas":
+			return strconv.Itoa(int(o.Status.Replicas)), true
+		case "readyReplicas":
+			return strconv.Itoa(int(o.Status.ReadyReplicas)), true
+		case "unavailableReplicas":
+			return strconv.Itoa(int(o.Status.UnavailableReplicas)), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,161 @@
+package alerts
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(namespace, name string, phase v1.PodPhase, restarts int32) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: v1.PodStatus{
+			Phase: phase,
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "app", RestartCount: restarts},
+			},
+		},
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		input string
+		field string
+		op    string
+		value string
+	}{
+		{"phase=Failed", "phase", "=", "Failed"},
+		{"restarts>5", "restarts", ">", "5"},
+		{"restarts >= 5", "restarts", ">=", "5"},
+		{"readyReplicas<1", "readyReplicas", "<", "1"},
+		{"phase!=Running", "phase", "!=", "Running"},
+		{"restarts<=2", "restarts", "<=", "2"},
+	}
+
+	for _, tt := range tests {
+		cond, err := ParseCondition(tt.input)
+		if err != nil {
+			t.Errorf("ParseCondition(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if cond.Field != tt.field || cond.Op != tt.op || cond.Value != tt.value {
+			t.Errorf("ParseCondition(%q) = %+v, expected %s %s %s", tt.input, cond, tt.field, tt.op, tt.value)
+		}
+	}
+
+	for _, invalid := range []string{"", "phase", "=Failed", "phase=", ">5"} {
+		if _, err := ParseCondition(invalid); err == nil {
+			t.Errorf("Expected ParseCondition(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestConditionEval(t *testing.T) {
+	tests := []struct {
+		condition string
+		actual    string
+		expected  bool
+	}{
+		{"restarts>5", "6", true},
+		{"restarts>5", "5", false},
+		{"restarts>5", "10", true}, // numeric, not lexical
+		{"restarts>=5", "5", true},
+		{"restarts<2", "1", true},
+		{"phase=Failed", "Failed", true},
+		{"phase=failed", "Failed", true},
+		{"phase=Failed", "Running", false},
+		{"phase!=Running", "Pending", true},
+	}
+
+	for _, tt := range tests {
+		cond, err := ParseCondition(tt.condition)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q) failed: %v", tt.condition, err)
+		}
+		if got := cond.Eval(tt.actual); got != tt.expected {
+			t.Errorf("%s with %q = %v, expected %v", tt.condition, tt.actual, got, tt.expected)
+		}
+	}
+}
+
+func TestNewRuleValidation(t *testing.T) {
+	if _, err := NewRule("x", "widget", "phase=Failed", nil); err == nil {
+		t.Error("Expected unsupported kind to be rejected")
+	}
+	if _, err := NewRule("x", "pod", "replicas>1", nil); err == nil {
+		t.Error("Expected field of another kind to be rejected")
+	}
+	if _, err := NewRule("x", "pod", "phase=Failed", []string{"prod-["}); err == nil {
+		t.Error("Expected invalid namespace glob to be rejected")
+	}
+
+	rule, err := NewRule("", "Pod", "phase=Failed", nil)
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	if rule.Kind != "pod" || rule.Name != "pod phase=Failed" {
+		t.Errorf("Expected normalized kind and generated name, got %+v", rule)
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	restarts, err := NewRule("crash", "pod", "restarts>5", []string{"prod-*"})
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+
+	if !restarts.Matches(newPod("prod-eu", "web", v1.PodRunning, 7)) {
+		t.Error("Expected pod with 7 restarts in prod-eu to match")
+	}
+	if restarts.Matches(newPod("prod-eu", "web", v1.PodRunning, 2)) {
+		t.Error("Expected pod with 2 restarts not to match")
+	}
+	if restarts.Matches(newPod("staging", "web", v1.PodRunning, 7)) {
+		t.Error("Expected pod outside the rule namespaces not to match")
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod-eu"},
+		Status:     appsv1.DeploymentStatus{UnavailableReplicas: 9},
+	}
+	if restarts.Matches(deployment) {
+		t.Error("Expected pod rule not to match a deployment")
+	}
+
+	unavailable, err := NewRule("unavailable", "deployment", "unavailableReplicas>0", nil)
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	if !unavailable.Matches(deployment) {
+		t.Error("Expected deployment with unavailable replicas to match")
+	}
+}
+
+func TestPodFieldValues(t *testing.T) {
+	pod := newPod("default", "web", v1.PodPending, 3)
+	pod.Spec.NodeName = "node-1"
+	pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+		Name:         "sidecar",
+		RestartCount: 2,
+		State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	})
+	pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse}}
+
+	expected := map[string]string{
+		"name":     "web",
+		"phase":    "Pending",
+		"node":     "node-1",
+		"restarts": "5",
+		"ready":    "false",
+		"reason":   "CrashLoopBackOff",
+	}
+	for field, want := range expected {
+		got, ok := fieldValue(pod, field)
+		if !ok || got != want {
+			t.Errorf("fieldValue(%s) = %q, %v; expected %q", field, got, ok, want)
+		}
+	}
+}
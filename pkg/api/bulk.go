@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// maxBulkOperations caps the operations of one bulk request
+const maxBulkOperations = 100
+
+// bulkParallelism is how many operations of a ?parallel=true bulk request
+// run at once
+const bulkParallelism = 10
+
+// Actions of a bulk operation
+const (
+	bulkCreate = "create"
+	bulkUpdate = "update"
+	bulkDelete = "delete"
+)
+
+// bulkKind creates, updates and deletes the objects of one resource type
+type bulkKind struct {
+	// write decodes the body of a create or update, checks it and writes
+	// it, returning the name of the object and the kubectl command doing
+	// the same
+	write func(h *BulkOperationHandler, op BulkOperation) (string, string, error)
+	del   deleteFunc
+}
+
+// bulkKinds maps the resources bulk operations accept to their kind
+var bulkKinds = map[string]bulkKind{
+	"pod":        newBulkKind(validation.Pod, k8s.CreatePod, k8s.UpdatePod, k8s.DeletePod),
+	"deployment": newBulkKind(validation.Deployment, k8s.CreateDeployment, k8s.UpdateDeployment, k8s.DeleteDeployment),
+	"service":    newBulkKind(validation.Service, k8s.CreateService, k8s.UpdateService, k8s.DeleteService),
+	"configmap":  newBulkKind(validation.ConfigMap, k8s.CreateConfigMap, k8s.UpdateConfigMap, k8s.DeleteConfigMap),
+}
+
+// newBulkKind builds the bulkKind of a typed object from the same validation
+// and k8s functions its own endpoints use
+func newBulkKind[T any, PT interface {
+	*T
+	metav1.Object
+	runtime.Object
+}](
+	validate func(PT) error,
+	create, update func(kubernetes.Interface, string, PT) (PT, error),
+	del deleteFunc,
+) bulkKind {
+	write := func(h *BulkOperationHandler, op BulkOperation) (string, string, error) {
+		obj := PT(new(T))
+		if err := json.Unmarshal(op.Body, obj); err != nil {
+			return "", "", fmt.Errorf("invalid body: %v", err)
+		}
+		obj.SetNamespace(op.Namespace)
+		if op.Name != "" {
+			obj.SetName(op.Name)
+		}
+		if err := validate(obj); err != nil {
+			return obj.GetName(), "", err
+		}
+		if op.Action == bulkCreate {
+			if err := checkAnnotationPolicy(h.requiredAnnotations, op.Namespace, obj); err != nil {
+				return obj.GetName(), "", err
+			}
+		}
+		if err := h.imagePolicy.CheckObject(obj); err != nil {
+			return obj.GetName(), "", err
+		}
+
+		apply, command := create, k8s.KubectlCreate(op.Namespace, obj)
+		if op.Action == bulkUpdate {
+			apply, command = update, k8s.KubectlReplace(op.Namespace)
+		}
+		written, err := apply(h.clientset, op.Namespace, obj)
+		if err != nil {
+			return obj.GetName(), "", err
+		}
+		return written.GetName(), command, nil
+	}
+	return bulkKind{write: write, del: del}
+}
+
+// Validate checks the fields of an operation, before any of a request runs
+func (op BulkOperation) Validate() error {
+	if _, ok := bulkKinds[op.Resource]; !ok {
+		resources := make([]string, 0, len(bulkKinds))
+		for resource := range bulkKinds {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		return fmt.Errorf("unsupported resource %q, expected one of %s", op.Resource, strings.Join(resources, ", "))
+	}
+	if errs := utilvalidation.IsDNS1123Label(op.Namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", op.Namespace, errs[0])
+	}
+	switch op.Action {
+	case bulkCreate, bulkUpdate:
+		if len(op.Body) == 0 {
+			return fmt.Errorf("%s requires a body", op.Action)
+		}
+	case bulkDelete:
+		if op.Name == "" {
+			return fmt.Errorf("delete requires a name")
+		}
+	default:
+		return fmt.Errorf("unsupported action %q, expected create, update or delete", op.Action)
+	}
+	return nil
+}
+
+// BulkOperationHandler runs several creates, updates and deletes in one
+// request. As the namespaces are in the body rather than the path, it checks
+// the namespace guard and the annotation policy of each operation itself.
+type BulkOperationHandler struct {
+	clientset           kubernetes.Interface
+	guard               *k8s.NamespaceGuard
+	requiredAnnotations []string
+	imagePolicy         *k8s.ImagePolicy
+}
+
+// NewBulkOperationHandler creates a bulk API handler. Operations in
+// namespaces the guard protects need the X-KGO-Confirm header to name their
+// namespace, and creates outside kube-system need requiredAnnotations.
+func NewBulkOperationHandler(clientset kubernetes.Interface, guard *k8s.NamespaceGuard, requiredAnnotations []string) *BulkOperationHandler {
+	return &BulkOperationHandler{clientset: clientset, guard: guard, requiredAnnotations: requiredAnnotations}
+}
+
+// SetImagePolicy rejects created and updated pods and deployments whose
+// images come from registries the policy does not allow
+func (h *BulkOperationHandler) SetImagePolicy(policy *k8s.ImagePolicy) {
+	h.imagePolicy = policy
+}
+
+// Bulk handles POST /api/v1/bulk: up to maxBulkOperations operations, run in
+// order, or bulkParallelism at a time with ?parallel=true. A request with an
+// invalid operation is rejected before any runs; once they run, each
+// operation succeeds or fails on its own and the response has a result for
+// each.
+func (h *BulkOperationHandler) Bulk(c *gin.Context) {
+	var request BulkRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+		return
+	}
+	switch n := len(request.Operations); {
+	case n == 0:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at least one operation is required"})
+		return
+	case n > maxBulkOperations:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("at most %d operations are allowed per request, got %d", maxBulkOperations, n)})
+		return
+	}
+	for i, op := range request.Operations {
+		if err := op.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("operation %d: %v", i, err)})
+			return
+		}
+	}
+
+	confirmation := c.GetHeader(ConfirmHeader)
+	results := make([]BulkResult, len(request.Operations))
+	if c.Query("parallel") == "true" {
+		var g errgroup.Group
+		g.SetLimit(bulkParallelism)
+		for i, op := range request.Operations {
+			g.Go(func() error {
+				results[i] = h.run(i, op, confirmation)
+				return nil
+			})
+		}
+		_ = g.Wait()
+	} else {
+		for i, op := range request.Operations {
+			results[i] = h.run(i, op, confirmation)
+		}
+	}
+
+	c.JSON(http.StatusOK, BulkResponse{Results: results})
+}
+
+// run runs the operation at index i
+func (h *BulkOperationHandler) run(i int, op BulkOperation, confirmation string) BulkResult {
+	result := BulkResult{Index: i, Name: op.Name}
+	err := h.guard.Check(op.Namespace, confirmation)
+	if err == nil {
+		kind := bulkKinds[op.Resource]
+		if op.Action == bulkDelete {
+			err = kind.del(h.clientset, op.Namespace, op.Name)
+			result.KubectlEquivalent = k8s.KubectlDelete(op.Namespace, op.Resource, op.Name)
+		} else {
+			result.Name, result.KubectlEquivalent, err = kind.write(h, op)
+		}
+	}
+	if err != nil {
+		klog.Errorf("Bulk operation %d, %s %s %s/%s, failed: %v", i, op.Action, op.Resource, op.Namespace, result.Name, err)
+		result.Error = err.Error()
+		result.KubectlEquivalent = ""
+		return result
+	}
+	result.Success = true
+	return result
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// postBulk posts a bulk request with optional X-KGO-Confirm header
+func postBulk(t *testing.T, r *gin.Engine, path, body, confirm string) (int, BulkResponse, string) {
+	t.Helper()
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	if confirm != "" {
+		req.Header.Set(ConfirmHeader, confirm)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var response BulkResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response, w.Body.String()
+}
+
+func newBulkRouter(t *testing.T, clientset kubernetes.Interface) *gin.Engine {
+	guard, err := k8s.NewNamespaceGuard([]string{"prod"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	r := gin.New()
+	r.POST("/bulk", NewBulkOperationHandler(clientset, guard, []string{"owner"}).Bulk)
+	return r
+}
+
+func TestBulkMixedResults(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}, Data: map[string]string{"mode": "dev"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "prod"}},
+	)
+	r := newBulkRouter(t, clientset)
+
+	body := `{"operations": [
+		{"action": "create", "resource": "pod", "namespace": "default", "body": {"metadata": {"name": "pod-abc", "annotations": {"owner": "alice"}}, "spec": {"containers": [{"name": "app", "image": "nginx"}]}}},
+		{"action": "delete", "resource": "deployment", "namespace": "default", "name": "old-app"},
+		{"action": "update", "resource": "configmap", "namespace": "default", "name": "settings", "body": {"data": {"mode": "prod"}}},
+		{"action": "create", "resource": "service", "namespace": "default", "body": {"metadata": {"name": "web"}, "spec": {"ports": [{"port": 80}]}}},
+		{"action": "create", "resource": "pod", "namespace": "default", "body": {"metadata": {"name": "Bad_Name", "annotations": {"owner": "alice"}}, "spec": {"containers": [{"name": "app", "image": "nginx"}]}}},
+		{"action": "delete", "resource": "configmap", "namespace": "prod", "name": "settings"}
+	]}`
+	code, response, raw := postBulk(t, r, "/bulk", body, "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", code, raw)
+	}
+
+	expected := []struct {
+		success bool
+		name    string
+		err     string
+		kubectl string
+	}{
+		{true, "pod-abc", "", "kubectl -n default run pod-abc --image=nginx"},
+		{false, "old-app", "not found", ""},
+		{true, "settings", "", "kubectl -n default replace -f -"},
+		{false, "web", "missing required annotations: owner", ""},
+		{false, "Bad_Name", "Invalid value", ""},
+		{false, "settings", "protected", ""},
+	}
+	if len(response.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d: %s", len(expected), len(response.Results), raw)
+	}
+	for i, want := range expected {
+		got := response.Results[i]
+		if got.Index != i || got.Success != want.success || got.Name != want.name || !strings.Contains(got.Error, want.err) {
+			t.Errorf("Result %d: expected success=%v name=%s error containing %q, got %+v", i, want.success, want.name, want.err, got)
+		}
+		if got.KubectlEquivalent != want.kubectl {
+			t.Errorf("Result %d: expected kubectlEquivalent %q, got %q", i, want.kubectl, got.KubectlEquivalent)
+		}
+	}
+
+	configMap, _ := clientset.CoreV1().ConfigMaps("default").Get(t.Context(), "settings", metav1.GetOptions{})
+	if configMap.Data["mode"] != "prod" {
+		t.Errorf("Expected the configmap to be updated, got %v", configMap.Data)
+	}
+	if _, err := clientset.CoreV1().ConfigMaps("prod").Get(t.Context(), "settings", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the unconfirmed delete in prod to be refused, got %v", err)
+	}
+
+	// Confirming the namespace lets the same delete through
+	code, response, raw = postBulk(t, r, "/bulk", `{"operations": [{"action": "delete", "resource": "configmap", "namespace": "prod", "name": "settings"}]}`, "prod")
+	if code != http.StatusOK || len(response.Results) != 1 || !response.Results[0].Success {
+		t.Fatalf("Expected the confirmed delete to succeed, got %d: %s", code, raw)
+	}
+	if want := "kubectl -n prod delete configmap settings"; response.Results[0].KubectlEquivalent != want {
+		t.Errorf("Expected kubectlEquivalent %q, got %q", want, response.Results[0].KubectlEquivalent)
+	}
+}
+
+func TestBulkValidation(t *testing.T) {
+	r := newBulkRouter(t, fake.NewSimpleClientset())
+
+	tooMany := make([]BulkOperation, maxBulkOperations+1)
+	for i := range tooMany {
+		tooMany[i] = BulkOperation{Action: "delete", Resource: "pod", Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}
+	}
+	tooManyBody, _ := json.Marshal(BulkRequest{Operations: tooMany})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"invalid json", `{"operations": [`, "Invalid JSON"},
+		{"no operations", `{"operations": []}`, "at least one operation"},
+		{"too many operations", string(tooManyBody), "at most 100 operations"},
+		{"unknown action", `{"operations": [{"action": "patch", "resource": "pod", "namespace": "default", "name": "a"}]}`, `operation 0: unsupported action \"patch\"`},
+		{"unknown resource", `{"operations": [{"action": "delete", "resource": "pod", "namespace": "default", "name": "a"}, {"action": "delete", "resource": "widget", "namespace": "default", "name": "a"}]}`, `operation 1: unsupported resource \"widget\"`},
+		{"missing namespace", `{"operations": [{"action": "delete", "resource": "pod", "name": "a"}]}`, "invalid namespace"},
+		{"delete without name", `{"operations": [{"action": "delete", "resource": "pod", "namespace": "default"}]}`, "delete requires a name"},
+		{"create without body", `{"operations": [{"action": "create", "resource": "pod", "namespace": "default"}]}`, "create requires a body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, raw := postBulk(t, r, "/bulk", tt.body, "")
+			if code != http.StatusBadRequest || !strings.Contains(raw, tt.want) {
+				t.Errorf("Expected status 400 with %q, got %d: %s", tt.want, code, raw)
+			}
+		})
+	}
+}
+
+func TestBulkParallel(t *testing.T) {
+	// The fake clientset serializes reactors, so swap in a kind whose
+	// deletes block until all of them are in flight; running them in order
+	// would time out
+	const operations = 4
+	var inFlight int32
+	release := make(chan struct{})
+	saved := bulkKinds
+	bulkKinds = map[string]bulkKind{"pod": {del: func(cs kubernetes.Interface, namespace, name string) error {
+		if atomic.AddInt32(&inFlight, 1) == operations {
+			close(release)
+		}
+		select {
+		case <-release:
+			if name == "pod-2" {
+				return fmt.Errorf("pod %q not found", name)
+			}
+			return nil
+		case <-time.After(2 * time.Second):
+			return fmt.Errorf("delete of %s was not run concurrently", name)
+		}
+	}}}
+	defer func() { bulkKinds = saved }()
+
+	var request BulkRequest
+	for i := 0; i < operations; i++ {
+		request.Operations = append(request.Operations, BulkOperation{Action: "delete", Resource: "pod", Namespace: "default", Name: fmt.Sprintf("pod-%d", i)})
+	}
+	body, _ := json.Marshal(request)
+
+	code, response, raw := postBulk(t, newBulkRouter(t, fake.NewSimpleClientset()), "/bulk?parallel=true", string(body), "")
+	if code != http.StatusOK || len(response.Results) != operations {
+		t.Fatalf("Expected %d results, got %d: %s", operations, code, raw)
+	}
+	for i, result := range response.Results {
+		wantSuccess := i != 2
+		if result.Index != i || result.Name != fmt.Sprintf("pod-%d", i) || result.Success != wantSuccess {
+			t.Errorf("Result %d: expected success=%v in request order, got %+v", i, wantSuccess, result)
+		}
+	}
+	if !strings.Contains(response.Results[2].Error, "not found") {
+		t.Errorf("Expected the failed delete's error, got %q", response.Results[2].Error)
+	}
+}
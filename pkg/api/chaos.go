@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// probeFailureRevertTimeout bounds the revert of an injected probe failure
+const probeFailureRevertTimeout = 30 * time.Second
+
+// ChaosHandler injects failures into workloads and reverts them after a
+// while. The reverts run in the server process: a server stopped before
+// they are due leaves the failures in place.
+type ChaosHandler struct {
+	clientset kubernetes.Interface
+
+	// mu guards active, the revert timers of the injected probe failures
+	// by namespace/deployment/container
+	mu     sync.Mutex
+	active map[string]*time.Timer
+}
+
+// NewChaosHandler creates a chaos API handler
+func NewChaosHandler(clientset kubernetes.Interface) *ChaosHandler {
+	return &ChaosHandler{clientset: clientset, active: make(map[string]*time.Timer)}
+}
+
+// ProbeFailure handles POST
+// /api/v1/deployments/:namespace/:name/chaos/probe-failure: the liveness
+// probe of the container fails until durationSeconds have passed, then the
+// original probe is restored. It answers 202 once the failure is injected.
+func (h *ChaosHandler) ProbeFailure(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+
+	var request ProbeFailureRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if request.Container == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "container is required"})
+		return
+	}
+	duration := time.Duration(request.DurationSeconds) * time.Second
+	if err := k8s.CheckProbeFailureDuration(duration); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", namespace, name, request.Container)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.active[key]; ok {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: fmt.Sprintf("a probe failure is already injected into %s", key)})
+		return
+	}
+
+	original, err := k8s.StartProbeFailure(c.Request.Context(), h.clientset, namespace, name, request.Container)
+	switch {
+	case goerrors.Is(err, k8s.ErrProbeFailureActive):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		return
+	case goerrors.Is(err, k8s.ErrNoLivenessProbe):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	case err != nil:
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	h.active[key] = time.AfterFunc(duration, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), probeFailureRevertTimeout)
+		defer cancel()
+		if err := k8s.RevertProbeFailure(ctx, h.clientset, namespace, name, request.Container, original); err != nil {
+			klog.Errorf("Failed to revert the probe failure of %s: %v", key, err)
+		}
+		h.mu.Lock()
+		delete(h.active, key)
+		h.mu.Unlock()
+	})
+
+	c.JSON(http.StatusAccepted, ProbeFailureResponse{
+		Namespace: namespace,
+		Name:      name,
+		Container: request.Container,
+		RevertAt:  metav1.NewTime(time.Now().Add(duration)),
+	})
+}
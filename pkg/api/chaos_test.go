@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProbeFailure(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "web", Image: "nginx", LivenessProbe: &v1.Probe{ProbeHandler: v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt32(80)}}}},
+			{Name: "sidecar", Image: "envoy"},
+		}}}},
+	}
+	clientset := fake.NewSimpleClientset(deployment)
+	r := gin.New()
+	r.POST("/deployments/:namespace/:name/chaos/probe-failure", NewChaosHandler(clientset).ProbeFailure)
+
+	post := func(name, body string) (int, string) {
+		req, _ := http.NewRequest("POST", "/deployments/default/"+name+"/chaos/probe-failure", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+	livenessProbe := func() *v1.Probe {
+		d, err := clientset.AppsV1().Deployments("default").Get(t.Context(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		return d.Spec.Template.Spec.Containers[0].LivenessProbe
+	}
+
+	start := time.Now()
+	code, body := post("web", `{"container": "web", "durationSeconds": 1}`)
+	if code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", code, body)
+	}
+	var response ProbeFailureResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	// revertAt has a resolution of a second
+	if revertIn := response.RevertAt.Sub(start.Truncate(time.Second)); response.Container != "web" || revertIn < 0 || revertIn > 2*time.Second {
+		t.Errorf("Expected a revert a second from now, got %+v", response)
+	}
+	if probe := livenessProbe(); probe.Exec == nil || probe.Exec.Command[0] != "false" {
+		t.Errorf("Expected the liveness probe to fail, got %+v", probe)
+	}
+
+	tests := []struct {
+		name, deployment, body string
+		code                   int
+		want                   string
+	}{
+		{"already injected", "web", `{"container": "web", "durationSeconds": 60}`, http.StatusConflict, "already injected"},
+		{"no probe", "web", `{"container": "sidecar", "durationSeconds": 60}`, http.StatusBadRequest, "has none"},
+		{"no container", "web", `{"durationSeconds": 60}`, http.StatusBadRequest, "container is required"},
+		{"too long", "web", `{"container": "web", "durationSeconds": 7200}`, http.StatusBadRequest, "duration must be between"},
+		{"missing deployment", "api", `{"container": "web", "durationSeconds": 60}`, http.StatusNotFound, "not found"},
+	}
+	for _, tt := range tests {
+		if code, body := post(tt.deployment, tt.body); code != tt.code || !strings.Contains(body, tt.want) {
+			t.Errorf("%s: expected status %d with %q, got %d: %s", tt.name, tt.code, tt.want, code, body)
+		}
+	}
+
+	// The original probe is back once the duration has passed
+	deadline := time.Now().Add(5 * time.Second)
+	for livenessProbe().TCPSocket == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the probe to be reverted, got %+v", livenessProbe())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if code, body := post("web", `{"container": "web", "durationSeconds": 1}`); code != http.StatusAccepted {
+		t.Errorf("Expected a new injection once reverted, got %d: %s", code, body)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/version"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClusterHandler struct holds the clientset and the description of the
+// cluster it was configured for
+type ClusterHandler struct {
+	clientset   kubernetes.Interface
+	info        *k8s.ClientInfo
+	credentials *k8s.CredentialReloader
+}
+
+// NewClusterHandler creates a new cluster API handler
+func NewClusterHandler(clientset kubernetes.Interface, info *k8s.ClientInfo) *ClusterHandler {
+	return &ClusterHandler{clientset: clientset, info: info}
+}
+
+// SetCredentials reports the credentials credentials last reloaded instead
+// of the fixed description the handler was created with
+func (h *ClusterHandler) SetCredentials(credentials *k8s.CredentialReloader) {
+	h.credentials = credentials
+}
+
+// Info handles GET /api/v1/cluster/info and reports which API server,
+// kubeconfig and context the server uses, who it is authenticated as, the
+// server version and latency, and the API groups and resource types it
+// serves. Credentials are never included.
+func (h *ClusterHandler) Info(c *gin.Context) {
+	info := h.info
+	if h.credentials != nil {
+		info = h.credentials.Info()
+	}
+	c.JSON(http.StatusOK, k8s.GetClusterInfo(c.Request.Context(), h.clientset, info))
+}
+
+// CredentialMetrics handles GET /api/v1/metrics/credentials and reports when
+// the credentials of the Kubernetes client expire and how often they were
+// reloaded
+func CredentialMetrics(credentials *k8s.CredentialReloader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if credentials == nil {
+			c.JSON(http.StatusOK, CredentialMetricsResponse{})
+			return
+		}
+		reloads, lastReload := credentials.Reloads()
+		response := CredentialMetricsResponse{Reloading: true, Reloads: reloads}
+		if !lastReload.IsZero() {
+			response.LastReload = &metav1.Time{Time: lastReload}
+		}
+		if expiry := credentials.Info().CredentialExpiry; expiry != nil {
+			expiresIn := int64(time.Until(*expiry).Seconds())
+			response.Expiry = &metav1.Time{Time: *expiry}
+			response.ExpiresInSeconds = &expiresIn
+			response.ExpiringSoon = time.Until(*expiry) < k8s.CredentialExpiryWarning
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// Version handles GET /api/v1/version and reports the version of the kgo
+// server, which clients compare to their own
+func Version(c *gin.Context) {
+	c.JSON(http.StatusOK, VersionResponse{Version: version.Version})
+}
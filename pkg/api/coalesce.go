@@ -0,0 +1,25 @@
+package api
+
+import (
+	"net/http"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CoalescingMetrics handles GET /api/v1/metrics/coalescing and reports how many
+// list requests shared an upstream call instead of issuing their own
+func CoalescingMetrics(coalescer *k8s.ListCoalescer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if coalescer == nil {
+			c.JSON(http.StatusOK, CoalescingResponse{})
+			return
+		}
+		c.JSON(http.StatusOK, CoalescingResponse{
+			Enabled:           true,
+			CoalescedRequests: coalescer.Coalesced(),
+			UpstreamCalls:     coalescer.Upstream(),
+		})
+	}
+}
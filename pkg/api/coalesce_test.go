@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestListPodsCoalescesParallelRequests(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var listCalls int32
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		atomic.AddInt32(&listCalls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return false, nil, nil
+	})
+
+	coalescer := k8s.NewListCoalescer("test", time.Minute)
+	handler := NewHandler(clientset)
+	handler.SetCoalescer(coalescer)
+
+	r := gin.New()
+	r.GET("/pods", handler.ListPods)
+	r.GET("/metrics/coalescing", CoalescingMetrics(coalescer))
+
+	const requests = 5
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "/pods?namespace=default", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", w.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&listCalls); got != 1 {
+		t.Errorf("Expected 1 upstream list call for %d parallel requests, got %d", requests, got)
+	}
+
+	req, _ := http.NewRequest("GET", "/metrics/coalescing", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var metrics map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &metrics); err != nil {
+		t.Fatalf("Failed to unmarshal metrics: %v", err)
+	}
+	if metrics["coalescedRequests"] != float64(requests-1) {
+		t.Errorf("Expected %d coalesced requests, got %v", requests-1, metrics["coalescedRequests"])
+	}
+
+	// noCache bypasses both the in-flight sharing and the TTL cache
+	req, _ = http.NewRequest("GET", "/pods?namespace=default&noCache=true", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := atomic.LoadInt32(&listCalls); got != 2 {
+		t.Errorf("Expected noCache request to reach the API, got %d list calls", got)
+	}
+}
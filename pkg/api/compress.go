@@ -0,0 +1,164 @@
+package api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+// minCompressSize is the smallest response CompressMiddleware compresses;
+// gzip would barely shrink smaller ones
+const minCompressSize = 1024
+
+// CompressMiddleware gzips responses of at least minCompressSize bytes to
+// clients sending Accept-Encoding: gzip, at the default compression level
+func CompressMiddleware() gin.HandlerFunc {
+	return CompressMiddlewareWithLevel(gzip.DefaultCompression)
+}
+
+// CompressMiddlewareWithLevel is CompressMiddleware at a compress/gzip level,
+// from gzip.BestSpeed to gzip.BestCompression. WebSocket upgrades and
+// responses that already have a Content-Encoding are left alone, and a
+// stream flushed before reaching minCompressSize is sent uncompressed.
+func CompressMiddlewareWithLevel(level int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) || c.Request.Method == http.MethodHead ||
+			strings.EqualFold(c.GetHeader("Connection"), "upgrade") || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer, level: level}
+		c.Writer = writer
+		defer writer.finish()
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, e.g.
+// "gzip, deflate" but not "gzip;q=0"
+func acceptsGzip(acceptEncoding string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				weight, err := strconv.ParseFloat(q, 64)
+				return err == nil && weight > 0
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter holds a response back until it reaches minCompressSize,
+// then sends it gzipped; a shorter one is sent as it is when the handler
+// finishes
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	level   int
+	pending []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	w.pending = append(w.pending, data...)
+	if len(w.pending) >= minCompressSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports a response being held back as written, so that gin does
+// not write its headers a second time
+func (w *gzipResponseWriter) Written() bool {
+	return len(w.pending) > 0 || w.ResponseWriter.Written()
+}
+
+// WriteHeaderNow sends the headers, so the response can no longer be gzipped
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			klog.Errorf("Failed to write response: %v", err)
+		}
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what has been written so far, committing a stream to being
+// uncompressed when it is still short
+func (w *gzipResponseWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			klog.Errorf("Failed to write response: %v", err)
+		}
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide sends the headers and the response held back, gzipped when
+// compress is set and the handler has not encoded the response itself
+func (w *gzipResponseWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if compress && header.Get("Content-Encoding") == "" && bodyAllowed(w.Status()) {
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		gz, err := gzip.NewWriterLevel(w.ResponseWriter, w.level)
+		if err != nil {
+			gz = gzip.NewWriter(w.ResponseWriter)
+		}
+		w.gz = gz
+	}
+
+	pending := w.pending
+	w.pending = nil
+	if len(pending) == 0 {
+		return nil
+	}
+	_, err := w.Write(pending)
+	return err
+}
+
+// finish sends a response still held back and ends the gzip stream
+func (w *gzipResponseWriter) finish() {
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			klog.Errorf("Failed to write response: %v", err)
+		}
+	}
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			klog.Errorf("Failed to finish gzipped response: %v", err)
+		}
+	}
+}
+
+// bodyAllowed reports whether a response with status may have a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/crypto"
+	"k8s-dashboard/pkg/k8s"
+
+	"filippo.io/age"
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newConfigMapKeyRouter() (*gin.Engine, *fake.Clientset) {
+	clientset := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string]string{"config.yaml": "debug: true\n", "nginx/site.conf": "server {}"},
+		BinaryData: map[string][]byte{"logo.png": {0x89, 'P', 'N', 'G'}},
+	})
+	handler := NewResourceHandler(clientset)
+	r := gin.New()
+	r.GET("/configmaps", handler.ListConfigMaps)
+	r.GET("/configmaps/:namespace/:name/data/*key", handler.GetConfigMapKey)
+	r.PUT("/configmaps/:namespace/:name/data/*key", handler.SetConfigMapKey)
+	r.DELETE("/configmaps/:namespace/:name/data/*key", handler.DeleteConfigMapKey)
+	r.POST("/configmaps/:namespace/from-data", handler.CreateConfigMapFromData)
+	return r, clientset
+}
+
+func getConfigMap(t *testing.T, clientset *fake.Clientset) *v1.ConfigMap {
+	t.Helper()
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "app", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap: %v", err)
+	}
+	return configMap
+}
+
+func TestGetConfigMapKey(t *testing.T) {
+	r, _ := newConfigMapKeyRouter()
+
+	tests := []struct {
+		path        string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"/configmaps/default/app/data/config.yaml", http.StatusOK, "text/plain; charset=utf-8", "debug: true\n"},
+		{"/configmaps/default/app/data/logo.png", http.StatusOK, "application/octet-stream", "\x89PNG"},
+		{"/configmaps/default/app/data/nginx%2Fsite.conf", http.StatusOK, "text/plain; charset=utf-8", "server {}"},
+		{"/configmaps/default/app/data/missing", http.StatusNotFound, "", ""},
+		{"/configmaps/default/other/data/config.yaml", http.StatusNotFound, "", ""},
+		{"/configmaps/default/app/data/", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.path, tt.code, w.Code, w.Body.String())
+			continue
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: expected content type %q, got %q", tt.path, tt.contentType, ct)
+		}
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestSetConfigMapKey(t *testing.T) {
+	r, clientset := newConfigMapKeyRouter()
+
+	put := func(path, contentType string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put("/configmaps/default/app/data/config.yaml", "text/plain", []byte("debug: false\n"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body ConfigMapResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	want := `kubectl -n default patch configmap app --type=merge -p '{"data":{"config.yaml":"debug: false\n"}}'`
+	if body.Name != "app" || body.KubectlEquivalent != want {
+		t.Errorf("Expected the configmap and kubectlEquivalent %s, got %q and %s", want, body.Name, body.KubectlEquivalent)
+	}
+	if w := put("/configmaps/default/app/data/conf.d%2Fextra.conf", "text/plain", []byte("gzip on;")); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for encoded key, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := put("/configmaps/default/app/data/cert.der", "application/octet-stream", []byte{0x30, 0x82}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for binary value, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := put("/configmaps/default/missing/data/key", "text/plain", []byte("x")); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for missing configmap, got %d", w.Code)
+	}
+
+	configMap := getConfigMap(t, clientset)
+	if configMap.Data["config.yaml"] != "debug: false\n" {
+		t.Errorf("Expected updated value, got %q", configMap.Data["config.yaml"])
+	}
+	if configMap.Data["conf.d/extra.conf"] != "gzip on;" {
+		t.Errorf("Expected key with slash to be set, got %v", configMap.Data)
+	}
+	if !bytes.Equal(configMap.BinaryData["cert.der"], []byte{0x30, 0x82}) {
+		t.Errorf("Expected binary value in binaryData, got %v", configMap.BinaryData)
+	}
+	// Other keys are left untouched by the patch
+	if configMap.Data["nginx/site.conf"] != "server {}" || len(configMap.BinaryData["logo.png"]) != 4 {
+		t.Errorf("Expected other keys to be preserved, got %v %v", configMap.Data, configMap.BinaryData)
+	}
+}
+
+func TestDeleteConfigMapKey(t *testing.T) {
+	r, clientset := newConfigMapKeyRouter()
+
+	del := func(path string) int {
+		req, _ := http.NewRequest("DELETE", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := del("/configmaps/default/app/data/nginx%2Fsite.conf"); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if code := del("/configmaps/default/app/data/logo.png"); code != http.StatusOK {
+		t.Fatalf("Expected status 200 for binary key, got %d", code)
+	}
+	if code := del("/configmaps/default/app/data/nginx%2Fsite.conf"); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for deleted key, got %d", code)
+	}
+
+	configMap := getConfigMap(t, clientset)
+	if _, ok := configMap.Data["nginx/site.conf"]; ok {
+		t.Errorf("Expected key to be deleted, got %v", configMap.Data)
+	}
+	if _, ok := configMap.BinaryData["logo.png"]; ok {
+		t.Errorf("Expected binary key to be deleted, got %v", configMap.BinaryData)
+	}
+	if configMap.Data["config.yaml"] != "debug: true\n" {
+		t.Errorf("Expected other keys to be preserved, got %v", configMap.Data)
+	}
+}
+
+func TestListConfigMapsOmitData(t *testing.T) {
+	r, _ := newConfigMapKeyRouter()
+
+	req, _ := http.NewRequest("GET", "/configmaps?namespace=default&omitData=true", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "debug: true") || strings.Contains(w.Body.String(), `"data"`) {
+		t.Errorf("Expected values to be omitted, got %s", w.Body.String())
+	}
+
+	var list ConfigMapSummaryListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.ConfigMaps) != 1 {
+		t.Fatalf("Expected one configmap, got %+v", list.ConfigMaps)
+	}
+	summary := list.ConfigMaps[0]
+	want := []k8s.ConfigMapKeySize{
+		{Key: "config.yaml", Size: 12},
+		{Key: "logo.png", Size: 4, Binary: true},
+		{Key: "nginx/site.conf", Size: 9},
+	}
+	if summary.Name != "app" || summary.Size != 25 || !reflect.DeepEqual(summary.Keys, want) {
+		t.Errorf("Expected app with keys %+v and size 25, got %+v", want, summary)
+	}
+
+	req, _ = http.NewRequest("GET", "/configmaps?namespace=default", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "debug: true") {
+		t.Errorf("Expected values without omitData, got %s", w.Body.String())
+	}
+}
+
+// configMapForm builds a multipart from-data body from form fields and files
+func configMapForm(t *testing.T, fields [][2]string, files map[string][]byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range files {
+		part, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+func TestCreateConfigMapFromData(t *testing.T) {
+	r, clientset := newConfigMapKeyRouter()
+
+	post := func(body *bytes.Buffer, contentType string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/configmaps/default/from-data", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	logo := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0xff}
+	body, contentType := configMapForm(t,
+		[][2]string{{"name", "web"}, {"literal", "mode=on"}, {"literal", "greeting=a=b"}},
+		map[string][]byte{"nginx.conf": []byte("server {}\n"), "logo.png": logo},
+	)
+	w := post(body, contentType)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ConfigMapResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	want := "kubectl -n default create configmap web --from-literal=greeting=a=b --from-literal=mode=on --from-file=logo.png --from-file=nginx.conf"
+	if resp.KubectlEquivalent != want {
+		t.Errorf("Expected kubectlEquivalent %s, got %s", want, resp.KubectlEquivalent)
+	}
+
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap: %v", err)
+	}
+	wantData := map[string]string{"mode": "on", "greeting": "a=b", "nginx.conf": "server {}\n"}
+	if !reflect.DeepEqual(configMap.Data, wantData) {
+		t.Errorf("Expected data %v, got %v", wantData, configMap.Data)
+	}
+	if len(configMap.BinaryData) != 1 || !bytes.Equal(configMap.BinaryData["logo.png"], logo) {
+		t.Errorf("Expected the binary file in binaryData, got %v", configMap.BinaryData)
+	}
+
+	w = post(bytes.NewBufferString(`{"name": "literals", "literals": {"mode": "off"}}`), "application/json")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201 for JSON literals, got %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name   string
+		fields [][2]string
+		files  map[string][]byte
+		json   string
+		status int
+		want   string
+	}{
+		{name: "no data", fields: [][2]string{{"name", "empty"}}, status: http.StatusBadRequest, want: "at least one"},
+		{name: "bad literal", fields: [][2]string{{"name", "x"}, {"literal", "mode"}}, status: http.StatusBadRequest, want: "not key=value"},
+		{name: "literal and file", fields: [][2]string{{"name", "x"}, {"literal", "a=1"}}, files: map[string][]byte{"a": []byte("2")}, status: http.StatusBadRequest, want: "more than once"},
+		{name: "separator in literal", json: `{"name": "x", "literals": {"conf/app": "1"}}`, status: http.StatusUnprocessableEntity, want: "must not contain path separators"},
+		{name: "separator in file name", fields: [][2]string{{"name", "x"}}, files: map[string][]byte{`certs\\ca.der`: {0xff}}, status: http.StatusUnprocessableEntity, want: "binaryData"},
+		{name: "invalid name", json: `{"name": "Web", "literals": {"a": "1"}}`, status: http.StatusUnprocessableEntity, want: "metadata.name"},
+		{name: "over 1MiB", fields: [][2]string{{"name", "x"}}, files: map[string][]byte{"big": make([]byte, k8s.MaxConfigMapSize+1)}, status: http.StatusRequestEntityTooLarge, want: "exceeds 1MiB"},
+		{name: "body too large", fields: [][2]string{{"name", "x"}}, files: map[string][]byte{"big": make([]byte, maxConfigMapFormSize)}, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w *httptest.ResponseRecorder
+			if tt.json != "" {
+				w = post(bytes.NewBufferString(tt.json), "application/json")
+			} else {
+				w = post(configMapForm(t, tt.fields, tt.files))
+			}
+			if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("Expected status %d with %q, got %d: %s", tt.status, tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateEncryptedConfigMap(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate an age identity: %v", err)
+	}
+	clientset := fake.NewSimpleClientset()
+	handler := NewResourceHandler(clientset)
+	r := gin.New()
+	r.GET("/configmaps", handler.ListConfigMaps)
+	r.POST("/configmaps/:namespace", handler.CreateConfigMap)
+	r.GET("/configmaps/:namespace/:name/data/*key", handler.GetConfigMapKey)
+
+	create := func(name, encrypt string) *httptest.ResponseRecorder {
+		body := `{"metadata":{"name":"` + name + `"},"data":{"password":"hunter2"}}`
+		req, _ := http.NewRequest("POST", "/configmaps/default", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(EncryptHeader, encrypt)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path string) string {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	if w := create("db", "sops"); w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without keys, got %d: %s", w.Code, w.Body.String())
+	}
+
+	handler.SetConfigMapKeys(&crypto.Keys{Backend: crypto.BackendAge, Encrypt: identity.Recipient().String()})
+	if w := create("db", "pgp"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unsupported X-Encrypt, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := create("db", "sops"); w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	stored := getConfigMapNamed(t, clientset, "db")
+	if !k8s.IsEncryptedConfigMap(stored) || !crypto.IsEncrypted([]byte(stored.Data["password"])) {
+		t.Fatalf("Expected the stored value to be a SOPS document, got %+v", stored)
+	}
+
+	// Without an identity values are returned as stored
+	if body := get("/configmaps/default/db/data/password"); body != stored.Data["password"] {
+		t.Errorf("Expected the encrypted value without an identity, got %q", body)
+	}
+
+	handler.SetConfigMapKeys(&crypto.Keys{Backend: crypto.BackendAge, Encrypt: identity.Recipient().String(), Decrypt: identity.String()})
+	if body := get("/configmaps/default/db/data/password"); body != "hunter2" {
+		t.Errorf("Expected the decrypted value, got %q", body)
+	}
+	if body := get("/configmaps?namespace=default"); !strings.Contains(body, `"password":"hunter2"`) {
+		t.Errorf("Expected the list to hold decrypted values, got %s", body)
+	}
+	if w := create("plain", ""); w.Code != http.StatusCreated || k8s.IsEncryptedConfigMap(getConfigMapNamed(t, clientset, "plain")) {
+		t.Errorf("Expected a configmap without X-Encrypt to be stored as is, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func getConfigMapNamed(t *testing.T, clientset *fake.Clientset, name string) *v1.ConfigMap {
+	t.Helper()
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap: %v", err)
+	}
+	return configMap
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/dynamic"
+)
+
+// CRDHandler struct holds the dynamic client CustomResourceDefinitions are
+// read through
+type CRDHandler struct {
+	dynamicClient dynamic.Interface
+}
+
+// NewCRDHandler creates a new CRD API handler. Without a dynamic client CRDs
+// cannot be listed.
+func NewCRDHandler(dynamicClient dynamic.Interface) *CRDHandler {
+	return &CRDHandler{dynamicClient: dynamicClient}
+}
+
+// ListCRDs handles GET /api/v1/crds
+func (h *CRDHandler) ListCRDs(c *gin.Context) {
+	if h.dynamicClient == nil {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "listing CRDs needs a dynamic client"})
+		return
+	}
+
+	crds, err := k8s.ListCRDs(c.Request.Context(), h.dynamicClient)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, CRDListResponse{CRDs: crds})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+// newTestCRD returns a minimal cluster-scoped CRD object
+func newTestCRD(name, group, kind string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"group":    group,
+			"scope":    "Cluster",
+			"names":    map[string]interface{}{"kind": kind, "plural": "widgets"},
+			"versions": []interface{}{map[string]interface{}{"name": "v1", "served": true, "storage": true}},
+		},
+	}}
+}
+
+func newCRDDynamicClient(objects ...runtime.Object) *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{k8s.CRDResource: "CustomResourceDefinitionList"}, objects...)
+}
+
+func TestListCRDs(t *testing.T) {
+	r := gin.New()
+	r.GET("/crds", NewCRDHandler(newCRDDynamicClient(newTestCRD("widgets.example.com", "example.com", "Widget"))).ListCRDs)
+
+	req, _ := http.NewRequest("GET", "/crds", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var list CRDListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.CRDs) != 1 || list.CRDs[0].Kind != "Widget" || list.CRDs[0].Scope != "Cluster" || list.CRDs[0].Version != "v1" {
+		t.Errorf("Expected the Widget CRD, got %+v", list.CRDs)
+	}
+}
+
+func TestListCRDsWithoutDynamicClient(t *testing.T) {
+	r := gin.New()
+	r.GET("/crds", NewCRDHandler(nil).ListCRDs)
+
+	req, _ := http.NewRequest("GET", "/crds", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 without a dynamic client, got %d", w.Code)
+	}
+}
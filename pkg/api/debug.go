@@ -0,0 +1,95 @@
+package api
+
+import (
+	goerrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// debugLogTailLines is how far back the logs of a debug container start
+const debugLogTailLines = 100
+
+// DebugPod handles POST /api/v1/pods/:namespace/:name/debug, adding an
+// ephemeral container to a running pod for images without a shell to exec
+// into. Clusters without ephemeral containers and unknown target containers
+// are bad requests.
+func (h *Handler) DebugPod(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var req DebugRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		klog.Errorf("Failed to bind JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if req.Image == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "image is required"})
+		return
+	}
+	if err := h.imagePolicy.CheckImage(req.Image); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	pod, err := k8s.AddEphemeralContainer(c.Request.Context(), h.clientset, namespace, name, k8s.NewDebugContainer(req.Image, req.Command, req.TargetContainer))
+	if err != nil {
+		status := statusForError(err)
+		if goerrors.Is(err, k8s.ErrEphemeralContainersUnavailable) || goerrors.Is(err, k8s.ErrUnknownTargetContainer) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	debugContainer := pod.Spec.EphemeralContainers[len(pod.Spec.EphemeralContainers)-1].Name
+	klog.Infof("AUDIT: debug container %s (%s) added to pod %s/%s from %s", debugContainer, req.Image, namespace, name, c.ClientIP())
+	c.JSON(http.StatusOK, DebugResponse{
+		Pod:               pod,
+		DebugContainer:    debugContainer,
+		KubectlEquivalent: k8s.KubectlDebug(namespace, name, req.Image, req.TargetContainer, req.Command),
+	})
+}
+
+// GetDebugContainerLogs handles GET
+// /api/v1/pods/:namespace/:name/debug/:container/logs, streaming the logs of
+// an ephemeral container. They are followed unless follow=false.
+func (h *Handler) GetDebugContainerLogs(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	container := c.Param("container")
+	follow := c.DefaultQuery("follow", "true") == "true"
+
+	pod, err := h.clientset.CoreV1().Pods(namespace).Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s in namespace %s: %v", name, namespace, err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !k8s.IsEphemeralContainer(pod, container) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: fmt.Sprintf("pod %s has no ephemeral container %q", name, container)})
+		return
+	}
+
+	var logStream io.ReadCloser
+	if follow {
+		logStream, err = k8s.FollowPodLogs(c.Request.Context(), h.clientset, namespace, name, container, debugLogTailLines)
+	} else {
+		logStream, err = k8s.GetPodLogs(h.clientset, namespace, name, container, false, debugLogTailLines)
+	}
+	if err != nil {
+		klog.Errorf("Failed to get logs of debug container %s: %v", container, err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer logStream.Close()
+
+	streamLogs(c, logStream, h.streamMetrics)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDebugPod(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "registry.internal/web"}}},
+	})
+	r := gin.New()
+	RegisterRoutes(r, clientset, RouterOptions{ImagePolicy: k8s.NewImagePolicy([]string{"docker.io", "registry.internal"})})
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/api/v1/pods/default/web/debug", `{"image": "busybox:1.36", "command": ["sh"], "targetContainer": "app"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body DebugResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if body.Pod == nil || len(body.Spec.EphemeralContainers) != 1 || body.Spec.EphemeralContainers[0].Name != body.DebugContainer {
+		t.Fatalf("Expected the updated pod with the debug container %q, got %s", body.DebugContainer, w.Body.String())
+	}
+	if added := body.Spec.EphemeralContainers[0]; added.Image != "busybox:1.36" || added.TargetContainerName != "app" {
+		t.Errorf("Expected busybox targeting app, got %+v", added)
+	}
+	if want := "kubectl -n default debug -it web --image=busybox:1.36 --target=app -- sh"; body.KubectlEquivalent != want {
+		t.Errorf("Expected kubectlEquivalent %s, got %s", want, body.KubectlEquivalent)
+	}
+
+	w = request("GET", "/api/v1/pods/default/web/debug/"+body.DebugContainer+"/logs?follow=false", "")
+	if w.Code != http.StatusOK || w.Body.String() != "fake logs" {
+		t.Errorf("Expected the debug container's logs, got %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{"no image", "POST", "/api/v1/pods/default/web/debug", `{}`, http.StatusBadRequest},
+		{"invalid JSON", "POST", "/api/v1/pods/default/web/debug", `{"image": 1}`, http.StatusBadRequest},
+		{"unknown target", "POST", "/api/v1/pods/default/web/debug", `{"image": "busybox", "targetContainer": "sidecar"}`, http.StatusBadRequest},
+		{"image not allowed", "POST", "/api/v1/pods/default/web/debug", `{"image": "ghcr.io/tools/debug"}`, http.StatusUnprocessableEntity},
+		{"missing pod", "POST", "/api/v1/pods/default/api/debug", `{"image": "busybox"}`, http.StatusNotFound},
+		{"logs of a regular container", "GET", "/api/v1/pods/default/web/debug/app/logs", "", http.StatusNotFound},
+		{"logs of a missing pod", "GET", "/api/v1/pods/default/api/debug/debugger-x/logs", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := request(tt.method, tt.path, tt.body); w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+// TestDebugPodUnavailable checks that clusters without ephemeral containers
+// get a 400 saying so, not the API server's 404
+func TestDebugPodUnavailable(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	clientset.PrependReactor("update", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "ephemeralcontainers" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods/ephemeralcontainers"}, "web")
+	})
+	r := gin.New()
+	RegisterRoutes(r, clientset, RouterOptions{})
+
+	req, _ := http.NewRequest("POST", "/api/v1/pods/default/web/debug", strings.NewReader(`{"image": "busybox"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusBadRequest || !strings.Contains(string(body), "ephemeral containers are not available") {
+		t.Errorf("Expected a 400 naming the missing feature, got %d: %s", w.Code, body)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// deleteFunc deletes one named object
+type deleteFunc func(clientset kubernetes.Interface, namespace, name string) error
+
+// deleteObject handles DELETE /api/v1/:kind/:namespace/:name for a plural
+// kind, e.g. "pods", whose singular is resource. The response warns when a
+// controller will recreate the object or finalizers hold it; with
+// ?force=true the finalizers are emptied first so that the delete completes
+// at once.
+func deleteObject(c *gin.Context, clientset kubernetes.Interface, kind, resource, message string, del deleteFunc) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	force := c.Query("force") == "true"
+
+	// The delete reports a missing object itself
+	safety, err := k8s.GetDeletionSafety(c.Request.Context(), clientset, namespace, kind, name)
+	if err != nil {
+		klog.Warningf("Deleting %s %s/%s without a safety check: %v", resource, namespace, name, err)
+	}
+
+	kubectl := k8s.KubectlDelete(namespace, resource, name)
+	var removed []string
+	if force && safety != nil && len(safety.Finalizers) > 0 {
+		if err := k8s.RemoveFinalizers(c.Request.Context(), clientset, kind, namespace, name); err != nil {
+			c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+			return
+		}
+		removed = safety.Finalizers
+		safety.BlockedBy = ""
+		kubectl = k8s.KubectlRemoveFinalizers(namespace, resource, name) + " && " + kubectl
+	}
+
+	if err := del(clientset, namespace, name); err != nil {
+		klog.Errorf("Failed to delete %s: %v", resource, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteResponse{
+		Message:           message,
+		KubectlEquivalent: kubectl,
+		Warnings:          safety.Warnings(),
+		FinalizersRemoved: removed,
+	})
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	goerrors "errors"
+	"net/http"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// diffKind fetches one object by name or every object in a namespace
+type diffKind struct {
+	get  func(clientset kubernetes.Interface, namespace, name string) (runtime.Object, error)
+	list func(clientset kubernetes.Interface, namespace string) (map[string]runtime.Object, error)
+}
+
+// diffKinds maps the singular kind names accepted by the diff endpoint to
+// their fetchers; the plural form selects the per-namespace comparison
+var diffKinds = map[string]diffKind{
+	"deployment": {
+		get: func(cs kubernetes.Interface, ns, name string) (runtime.Object, error) {
+			return k8s.GetDeployment(cs, ns, name)
+		},
+		list: func(cs kubernetes.Interface, ns string) (map[string]runtime.Object, error) {
+			items, err := k8s.ListDeployments(cs, ns)
+			return objectsByName(items, err)
+		},
+	},
+	"service": {
+		get: func(cs kubernetes.Interface, ns, name string) (runtime.Object, error) {
+			return k8s.GetService(cs, ns, name)
+		},
+		list: func(cs kubernetes.Interface, ns string) (map[string]runtime.Object, error) {
+			items, err := k8s.ListServices(cs, ns)
+			return objectsByName(items, err)
+		},
+	},
+	"configmap": {
+		get: func(cs kubernetes.Interface, ns, name string) (runtime.Object, error) {
+			return k8s.GetConfigMap(cs, ns, name)
+		},
+		list: func(cs kubernetes.Interface, ns string) (map[string]runtime.Object, error) {
+			items, err := k8s.ListConfigMaps(cs, ns)
+			return objectsByName(items, err)
+		},
+	},
+}
+
+// objectsByName indexes a typed list by object name
+func objectsByName[T any, PT interface {
+	*T
+	runtime.Object
+	GetName() string
+}](items []T, err error) (map[string]runtime.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	objects := make(map[string]runtime.Object, len(items))
+	for i := range items {
+		obj := PT(&items[i])
+		objects[obj.GetName()] = obj
+	}
+	return objects, nil
+}
+
+// DiffHandler struct holds the Kubernetes clientset
+type DiffHandler struct {
+	clientset kubernetes.Interface
+}
+
+// NewDiffHandler creates a new diff API handler
+func NewDiffHandler(clientset kubernetes.Interface) *DiffHandler {
+	return &DiffHandler{clientset: clientset}
+}
+
+// Diff handles GET /api/v1/diff?kind=deployment&a=ns1/name1&b=ns2/name2 and
+// GET /api/v1/diff?kind=deployments&aNamespace=ns1&bNamespace=ns2
+func (h *DiffHandler) Diff(c *gin.Context) {
+	kind := c.Query("kind")
+	if dk, ok := diffKinds[kind]; ok {
+		h.diffObjects(c, dk)
+		return
+	}
+	if dk, ok := diffKinds[strings.TrimSuffix(kind, "s")]; ok {
+		h.diffNamespaces(c, dk)
+		return
+	}
+	c.JSON(http.StatusBadRequest, ErrorResponse{Error: "unsupported kind: " + kind})
+}
+
+// diffObjects compares two named objects of the same kind
+func (h *DiffHandler) diffObjects(c *gin.Context, dk diffKind) {
+	aNamespace, aName, ok := splitObjectRef(c.Query("a"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "parameter a must be namespace/name"})
+		return
+	}
+	bNamespace, bName, ok := splitObjectRef(c.Query("b"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "parameter b must be namespace/name"})
+		return
+	}
+
+	a, err := dk.get(h.clientset, aNamespace, aName)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	b, err := dk.get(h.clientset, bNamespace, bName)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	diff, err := k8s.DiffObjects("a/"+c.Query("a"), "b/"+c.Query("b"), a, b)
+	if err != nil {
+		klog.Errorf("Failed to diff objects: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ObjectDiffResponse{
+		A:         c.Query("a"),
+		B:         c.Query("b"),
+		Identical: diff.Identical,
+		Changes:   diff.Changes,
+		Unified:   diff.Unified,
+	})
+}
+
+// DeploymentTemplateDiff handles GET /api/v1/deployments/:namespace/:name/diff
+func (h *DiffHandler) DeploymentTemplateDiff(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	diff, err := k8s.GetDeploymentTemplateDiff(c.Request.Context(), h.clientset, namespace, name)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeploymentDiffResponse{
+		Namespace: namespace,
+		Name:      name,
+		Identical: diff == "",
+		Unified:   diff,
+	})
+}
+
+// diffNamespaces compares every object of a kind by name across two namespaces
+func (h *DiffHandler) diffNamespaces(c *gin.Context, dk diffKind) {
+	aNamespace := c.Query("aNamespace")
+	bNamespace := c.Query("bNamespace")
+	if aNamespace == "" || bNamespace == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "aNamespace and bNamespace are required"})
+		return
+	}
+
+	a, err := dk.list(h.clientset, aNamespace)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	b, err := dk.list(h.clientset, bNamespace)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	summaries, err := k8s.DiffObjectSets(a, b)
+	if err != nil {
+		klog.Errorf("Failed to diff namespaces: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, NamespaceDiffResponse{
+		ANamespace: aNamespace,
+		BNamespace: bNamespace,
+		Results:    summaries,
+	})
+}
+
+// splitObjectRef parses a namespace/name reference
+func splitObjectRef(ref string) (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(ref, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// statusForError maps a Kubernetes API error to an HTTP status
+func statusForError(err error) int {
+	switch {
+	case errors.IsNotFound(err),
+		goerrors.Is(err, k8s.ErrNoPreviousReplicaSet),
+		goerrors.Is(err, k8s.ErrConfigMapKeyNotFound):
+		return http.StatusNotFound
+	case errors.IsInvalid(err):
+		return http.StatusUnprocessableEntity
+	case errors.IsResourceExpired(err):
+		return http.StatusGone
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newDiffDeployment(namespace, name, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ResourceVersion: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: image}}},
+			},
+		},
+	}
+}
+
+func newDiffRouter() *gin.Engine {
+	clientset := fake.NewSimpleClientset(
+		newDiffDeployment("staging", "web", "nginx:1.25"),
+		newDiffDeployment("prod", "web", "nginx:1.27"),
+		newDiffDeployment("staging", "api", "api:1"),
+		newDiffDeployment("prod", "api", "api:1"),
+		newDiffDeployment("staging", "debug", "busybox"),
+	)
+	handler := NewDiffHandler(clientset)
+	r := gin.New()
+	r.GET("/diff", handler.Diff)
+	return r
+}
+
+func TestDiffTwoObjects(t *testing.T) {
+	r := newDiffRouter()
+
+	req, _ := http.NewRequest("GET", "/diff?kind=deployment&a=staging/web&b=prod/web", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Identical bool             `json:"identical"`
+		Changes   []k8s.DiffChange `json:"changes"`
+		Unified   string           `json:"unified"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Identical {
+		t.Error("Expected deployments to differ")
+	}
+	if len(response.Changes) != 1 || response.Changes[0].Path != "spec.template.spec.containers[0].image" {
+		t.Errorf("Expected a single image change, got %+v", response.Changes)
+	}
+	if response.Unified == "" {
+		t.Error("Expected a unified diff")
+	}
+}
+
+func TestDiffNamespaces(t *testing.T) {
+	r := newDiffRouter()
+
+	req, _ := http.NewRequest("GET", "/diff?kind=deployments&aNamespace=staging&bNamespace=prod", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Results []k8s.DiffSummary `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	expected := []k8s.DiffSummary{
+		{Name: "api", Status: k8s.DiffIdentical},
+		{Name: "debug", Status: k8s.DiffOnlyInA},
+		{Name: "web", Status: k8s.DiffDifferent},
+	}
+	if len(response.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), response.Results)
+	}
+	for i, want := range expected {
+		if response.Results[i] != want {
+			t.Errorf("Result %d: expected %+v, got %+v", i, want, response.Results[i])
+		}
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	r := newDiffRouter()
+
+	tests := []struct {
+		url    string
+		status int
+	}{
+		{"/diff?kind=widget&a=x/y&b=x/y", http.StatusBadRequest},
+		{"/diff?kind=deployment&a=staging&b=prod/web", http.StatusBadRequest},
+		{"/diff?kind=deployments&aNamespace=staging", http.StatusBadRequest},
+		{"/diff?kind=deployment&a=staging/missing&b=prod/web", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.status, w.Code)
+		}
+	}
+}
+
+func TestDeploymentTemplateDiff(t *testing.T) {
+	deployment := newDiffDeployment("staging", "web", "nginx:1.26")
+	deployment.UID = "web-uid"
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	deployment.Spec.Template.Labels = map[string]string{"app": "web"}
+
+	previous := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-1",
+			Namespace:       "staging",
+			Labels:          map[string]string{"app": "web"},
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": "1"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: *deployment.Spec.Template.DeepCopy()},
+	}
+	previous.Spec.Template.Spec.Containers[0].Image = "nginx:1.25"
+
+	handler := NewDiffHandler(fake.NewSimpleClientset(deployment, previous, newDiffDeployment("staging", "fresh", "nginx:1.26")))
+	r := gin.New()
+	r.GET("/deployments/:namespace/:name/diff", handler.DeploymentTemplateDiff)
+
+	req, _ := http.NewRequest("GET", "/deployments/staging/web/diff", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Identical bool   `json:"identical"`
+		Unified   string `json:"unified"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Identical {
+		t.Error("Expected templates to differ")
+	}
+	if !strings.Contains(response.Unified, "-    - image: nginx:1.25") || !strings.Contains(response.Unified, "+    - image: nginx:1.26") {
+		t.Errorf("Expected the image change in the diff, got:\n%s", response.Unified)
+	}
+
+	for url, status := range map[string]int{
+		"/deployments/staging/missing/diff": http.StatusNotFound,
+		"/deployments/staging/fresh/diff":   http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("%s: expected status %d, got %d", url, status, w.Code)
+		}
+	}
+}
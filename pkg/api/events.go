@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// streamWatcher starts a watch on one resource type in a namespace from a
+// resourceVersion
+type streamWatcher func(clientset kubernetes.Interface, namespace, resourceVersion string) (*k8s.WatchHandle, error)
+
+// streamType pairs the singular resource reported in events with its watcher
+type streamType struct {
+	resource string
+	watch    streamWatcher
+}
+
+// streamTypes maps the plural type names accepted by the event stream to
+// their resource name and watcher
+var streamTypes = map[string]streamType{
+	"pods":        {"pod", k8s.WatchPods},
+	"deployments": {"deployment", k8s.WatchDeployments},
+	"services":    {"service", k8s.WatchServices},
+	"configmaps":  {"configmap", k8s.WatchConfigMaps},
+}
+
+// defaultStreamTypes are watched when the request does not specify types
+var defaultStreamTypes = []string{"pods", "deployments", "services", "configmaps"}
+
+// EventStreamHandler struct holds the Kubernetes clientset
+type EventStreamHandler struct {
+	clientset kubernetes.Interface
+	// streamMetrics counts the messages of event streams
+	streamMetrics *StreamMetrics
+}
+
+// NewEventStreamHandler creates a new event stream API handler
+func NewEventStreamHandler(clientset kubernetes.Interface) *EventStreamHandler {
+	return &EventStreamHandler{clientset: clientset}
+}
+
+// SetStreamMetrics counts the queued, sent and dropped messages of event
+// streams in metrics
+func (h *EventStreamHandler) SetStreamMetrics(metrics *StreamMetrics) {
+	h.streamMetrics = metrics
+}
+
+// StreamEvents handles GET /api/v1/events/stream?namespace=default&types=pods,deployments
+// by sending resource changes as Server-Sent Events until the client goes away.
+// A client that falls streamQueueSize events behind gets a resync event and
+// the stream ends.
+func (h *EventStreamHandler) StreamEvents(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+
+	types := splitQueryList(c.Query("types"))
+	if len(types) == 0 {
+		types = defaultStreamTypes
+	}
+	for _, t := range types {
+		if _, ok := streamTypes[t]; !ok {
+			supported := make([]string, 0, len(streamTypes))
+			for name := range streamTypes {
+				supported = append(supported, name)
+			}
+			sort.Strings(supported)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("unsupported type %q, supported: %v", t, supported)})
+			return
+		}
+	}
+
+	watchers := make(map[string]watch.Interface, len(types))
+	defer func() {
+		for _, watcher := range watchers {
+			watcher.Stop()
+		}
+	}()
+	for _, t := range types {
+		if _, ok := watchers[t]; ok {
+			continue
+		}
+		watcher, err := streamTypes[t].watch(h.clientset, namespace, "")
+		if err != nil {
+			klog.Errorf("Failed to start watching %s: %v", t, err)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		watchers[t] = watcher
+	}
+
+	// Fan in every watcher into one channel that closes once all of them end
+	events := make(chan StreamEvent)
+	done := make(chan struct{})
+	defer close(done)
+
+	var wg sync.WaitGroup
+	for t, watcher := range watchers {
+		wg.Add(1)
+		go func(resource string, watcher watch.Interface) {
+			defer wg.Done()
+			for event := range watcher.ResultChan() {
+				obj, err := meta.Accessor(event.Object)
+				if err != nil {
+					// Error events carry a Status instead of an object
+					klog.Errorf("Skipping %s watch event %s: %v", resource, event.Type, err)
+					continue
+				}
+				select {
+				case events <- StreamEvent{
+					Type:      string(event.Type),
+					Resource:  resource,
+					Name:      obj.GetName(),
+					Namespace: obj.GetNamespace(),
+				}:
+				case <-done:
+					return
+				}
+			}
+		}(streamTypes[t].resource, watcher)
+	}
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	header.Set("X-Accel-Buffering", "no")
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+
+	queue := newSendQueue(newSSEConn(c.Writer), streamQueueSize, streamWriteTimeout, h.streamMetrics)
+	defer queue.Close()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				klog.Info("All event stream watchers closed")
+				queue.Drain()
+				return
+			}
+			if !queue.Enqueue(event) {
+				// Wait for the slow client to be told to resync
+				<-queue.Done()
+				return
+			}
+		case <-queue.Done():
+			return
+		}
+	}
+}
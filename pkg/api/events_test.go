@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newStreamClientset returns a clientset whose watches are fake watchers the
+// test drives directly, keyed by resource
+func newStreamClientset() (*fake.Clientset, map[string]*watch.FakeWatcher) {
+	clientset := fake.NewSimpleClientset()
+	watchers := map[string]*watch.FakeWatcher{
+		"pods":        watch.NewFake(),
+		"deployments": watch.NewFake(),
+		"services":    watch.NewFake(),
+		"configmaps":  watch.NewFake(),
+	}
+	for resource, watcher := range watchers {
+		clientset.PrependWatchReactor(resource, func(action k8stesting.Action) (bool, watch.Interface, error) {
+			return true, watcher, nil
+		})
+	}
+	return clientset, watchers
+}
+
+// readStreamEvents parses the data lines of a Server-Sent Events body
+func readStreamEvents(t *testing.T, body string) []StreamEvent {
+	var events []StreamEvent
+	for _, chunk := range strings.Split(body, "\n\n") {
+		if chunk == "" {
+			continue
+		}
+		if !strings.HasPrefix(chunk, "data: ") {
+			t.Fatalf("Unexpected SSE chunk %q", chunk)
+		}
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(chunk, "data: ")), &event); err != nil {
+			t.Fatalf("Failed to unmarshal event %q: %v", chunk, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestStreamEventsMergesWatchers(t *testing.T) {
+	clientset, watchers := newStreamClientset()
+	r := gin.New()
+	r.GET("/events/stream", NewEventStreamHandler(clientset).StreamEvents)
+
+	req, _ := http.NewRequest("GET", "/events/stream?namespace=default&types=pods,deployments", nil)
+	w := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, req)
+		close(finished)
+	}()
+
+	// FakeWatcher sends are unbuffered, so each returns once the handler has
+	// taken the event
+	watchers["pods"].Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-abc", Namespace: "default"}})
+	watchers["deployments"].Modify(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	watchers["pods"].Delete(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-abc", Namespace: "default"}})
+
+	// The stream ends once every watcher has closed
+	watchers["pods"].Stop()
+	watchers["deployments"].Stop()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stream did not end after its watchers closed")
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	if w.Header().Get("X-Accel-Buffering") != "no" {
+		t.Error("Expected X-Accel-Buffering: no")
+	}
+	if !w.Flushed {
+		t.Error("Expected the stream to be flushed")
+	}
+
+	events := readStreamEvents(t, w.Body.String())
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d: %+v", len(events), events)
+	}
+
+	var pods []StreamEvent
+	sawDeployment := false
+	for _, event := range events {
+		switch event.Resource {
+		case "pod":
+			pods = append(pods, event)
+		case "deployment":
+			sawDeployment = event == StreamEvent{Type: "MODIFIED", Resource: "deployment", Name: "web", Namespace: "default"}
+		}
+	}
+	if !sawDeployment {
+		t.Errorf("Expected a MODIFIED deployment event, got %+v", events)
+	}
+	// Events from a single watcher keep their order
+	if len(pods) != 2 || pods[0].Type != "ADDED" || pods[1].Type != "DELETED" || pods[0].Name != "nginx-abc" {
+		t.Errorf("Expected ADDED then DELETED pod events, got %+v", pods)
+	}
+}
+
+func TestStreamEventsStopsOnDisconnect(t *testing.T) {
+	clientset, watchers := newStreamClientset()
+	r := gin.New()
+	r.GET("/events/stream", NewEventStreamHandler(clientset).StreamEvents)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/events/stream?types=services", nil)
+	w := httptest.NewRecorder()
+	finished := make(chan struct{})
+	go func() {
+		r.ServeHTTP(w, req)
+		close(finished)
+	}()
+
+	watchers["services"].Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	cancel()
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stream did not end after the client disconnected")
+	}
+	if !watchers["services"].IsStopped() {
+		t.Error("Expected the watcher to be stopped when the client goes away")
+	}
+}
+
+func TestStreamEventsValidation(t *testing.T) {
+	clientset, _ := newStreamClientset()
+	r := gin.New()
+	r.GET("/events/stream", NewEventStreamHandler(clientset).StreamEvents)
+
+	req, _ := http.NewRequest("GET", "/events/stream?types=pods,widgets", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unsupported type, got %d", w.Code)
+	}
+}
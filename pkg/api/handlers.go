@@ -2,19 +2,26 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
 
 // Handler struct holds the Kubernetes clientset
 type Handler struct {
-	clientset kubernetes.Interface
+	clientset   kubernetes.Interface
+	coalescer   *k8s.ListCoalescer
+	imagePolicy *k8s.ImagePolicy
+	// streamMetrics counts the messages of pod watches
+	streamMetrics *StreamMetrics
 }
 
 // NewHandler creates a new API handler with the given clientset
@@ -22,24 +29,127 @@ func NewHandler(clientset kubernetes.Interface) *Handler {
 	return &Handler{clientset: clientset}
 }
 
+// SetCoalescer makes list requests share identical concurrent upstream calls
+func (h *Handler) SetCoalescer(coalescer *k8s.ListCoalescer) {
+	h.coalescer = coalescer
+}
+
+// SetImagePolicy rejects pods whose images come from registries the policy
+// does not allow
+func (h *Handler) SetImagePolicy(policy *k8s.ImagePolicy) {
+	h.imagePolicy = policy
+}
+
+// SetStreamMetrics counts the queued, sent and dropped messages of pod
+// watches in metrics
+func (h *Handler) SetStreamMetrics(metrics *StreamMetrics) {
+	h.streamMetrics = metrics
+}
+
+// listCoalescer returns the coalescer to use for a request, or nil when the
+// client asked to bypass it with ?noCache=true
+func listCoalescer(c *gin.Context, coalescer *k8s.ListCoalescer) *k8s.ListCoalescer {
+	if c.Query("noCache") == "true" {
+		return nil
+	}
+	return coalescer
+}
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins for demo
 	},
 }
 
-// ListPods handles GET /api/v1/pods?namespace=default
+// ListPods handles GET /api/v1/pods?namespace=default. With ?limit=N it
+// returns one page and a continue token for the next, from ?continue=.
 func (h *Handler) ListPods(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
-	pods, err := k8s.ListPods(h.clientset, namespace)
+	if c.Query("limit") != "" {
+		h.listPodsPage(c, namespace)
+		return
+	}
+
+	pods, err := k8s.CoalescedList(listCoalescer(c, h.coalescer), "pods", namespace, metav1.ListOptions{}, func(opts metav1.ListOptions) ([]v1.Pod, error) {
+		return k8s.ListPodsWithOptions(h.clientset, namespace, opts)
+	})
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PodListResponse{Pods: pods})
+}
+
+// listPodsPage serves a single page of pods; pages are never coalesced
+func (h *Handler) listPodsPage(c *gin.Context, namespace string) {
+	limit, err := strconv.ParseInt(c.Query("limit"), 10, 64)
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive number"})
+		return
+	}
+
+	page, err := k8s.ListPodsPage(h.clientset, namespace, limit, c.Query("continue"))
 	if err != nil {
 		klog.Errorf("Failed to list pods: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pods": pods})
+	c.JSON(http.StatusOK, PodListResponse{Pods: page.Items, Continue: page.Continue})
+}
+
+// PodSummaries handles GET /api/v1/pods/summary?namespace=default: the
+// readiness breakdown of each pod. With ?notReady=true only running pods
+// that are not ready are returned.
+func (h *Handler) PodSummaries(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+
+	summaries, err := k8s.ListPodReadiness(c.Request.Context(), h.clientset, namespace)
+	if err != nil {
+		klog.Errorf("Failed to summarize pods: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if c.Query("notReady") == "true" {
+		notReady := make([]k8s.PodReadiness, 0, len(summaries))
+		for _, summary := range summaries {
+			if summary.NotReady {
+				notReady = append(notReady, summary)
+			}
+		}
+		summaries = notReady
+	}
+	c.JSON(http.StatusOK, PodSummaryListResponse{Pods: summaries})
+}
+
+// GetPod handles GET /api/v1/pods/:namespace/:name: the pod with what it
+// may do on its node, its containers' effective security contexts and the
+// pod security admission labels of its namespace
+func (h *Handler) GetPod(c *gin.Context) {
+	pod, security, err := k8s.GetPodSecurityByName(c.Request.Context(), h.clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, PodDetailsResponse{Pod: pod, Security: security})
+}
+
+// PodNetwork handles GET /api/v1/pods/:namespace/:name/network: the pod's
+// IPs, the services sending it traffic with their DNS names, and the service
+// ports targeting a port no container declares
+func (h *Handler) PodNetwork(c *gin.Context) {
+	network, err := k8s.GetPodNetwork(c.Request.Context(), h.clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, network)
 }
 
 // CreatePod handles POST /api/v1/pods/:namespace
@@ -49,21 +159,33 @@ func (h *Handler) CreatePod(c *gin.Context) {
 	var pod v1.Pod
 	if err := c.ShouldBindJSON(&pod); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
 	// Ensure namespace is set
 	pod.Namespace = namespace
 
+	if err := validation.Pod(&pod); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.imagePolicy.CheckObject(&pod); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	createdPod, err := k8s.CreatePod(h.clientset, namespace, &pod)
 	if err != nil {
 		klog.Errorf("Failed to create pod: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdPod)
+	c.JSON(http.StatusCreated, PodResponse{
+		Pod:               createdPod,
+		KubectlEquivalent: k8s.KubectlCreate(namespace, &pod),
+	})
 }
 
 // UpdatePod handles PUT /api/v1/pods/:namespace/:name
@@ -74,7 +196,7 @@ func (h *Handler) UpdatePod(c *gin.Context) {
 	var pod v1.Pod
 	if err := c.ShouldBindJSON(&pod); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
@@ -82,41 +204,46 @@ func (h *Handler) UpdatePod(c *gin.Context) {
 	pod.Name = name
 	pod.Namespace = namespace
 
+	if err := validation.Pod(&pod); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.imagePolicy.CheckObject(&pod); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	updatedPod, err := k8s.UpdatePod(h.clientset, namespace, &pod)
 	if err != nil {
 		klog.Errorf("Failed to update pod: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedPod)
+	c.JSON(http.StatusOK, PodResponse{
+		Pod:               updatedPod,
+		KubectlEquivalent: k8s.KubectlReplace(namespace),
+	})
 }
 
 // DeletePod handles DELETE /api/v1/pods/:namespace/:name
 func (h *Handler) DeletePod(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-
-	err := k8s.DeletePod(h.clientset, namespace, name)
-	if err != nil {
-		klog.Errorf("Failed to delete pod: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Pod deleted successfully"})
+	deleteObject(c, h.clientset, "pods", "pod", "Pod deleted successfully", k8s.DeletePod)
 }
 
-// WatchPods handles WebSocket connection for watching pod changes
+// WatchPods handles WebSocket connection for watching pod changes. Events
+// are written through a send queue; a client that falls streamQueueSize
+// events behind is closed with slowClientReason and must list again.
 func (h *Handler) WatchPods(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
-	watcher, err := k8s.WatchPods(h.clientset, namespace)
+	watcher, err := k8s.WatchPods(h.clientset, namespace, "")
 	if err != nil {
 		klog.Errorf("Failed to start watching pods: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
+	defer watcher.Stop()
 
 	// Upgrade to WebSocket
 	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -126,18 +253,24 @@ func (h *Handler) WatchPods(c *gin.Context) {
 	}
 	defer ws.Close()
 
+	queue := newSendQueue(wsConn{ws: ws}, streamQueueSize, streamWriteTimeout, h.streamMetrics)
+	defer queue.Close()
+
 	for {
 		select {
 		case event, ok := <-watcher.ResultChan():
 			if !ok {
 				klog.Info("Watcher channel closed")
+				queue.Drain()
 				return
 			}
-			err := ws.WriteJSON(event)
-			if err != nil {
-				klog.Errorf("Failed to write to WebSocket: %v", err)
+			if !queue.Enqueue(PodWatchEvent{Type: event.Type, Object: event.Object}) {
+				// Wait for the slow client to be told to resync
+				<-queue.Done()
 				return
 			}
+		case <-queue.Done():
+			return
 		}
 	}
 }
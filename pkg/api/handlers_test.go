@@ -2,15 +2,24 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
 
 	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestNewHandler(t *testing.T) {
@@ -85,6 +94,147 @@ func TestListPods(t *testing.T) {
 	}
 }
 
+func TestListPodsPaginated(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset()
+	// The fake clientset ignores limit and continue, so serve a fixed page
+	fakeClientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &v1.PodList{
+			ListMeta: metav1.ListMeta{Continue: "next-page"},
+			Items:    []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}},
+		}, nil
+	})
+	handler := NewHandler(fakeClientset)
+
+	r := gin.New()
+	r.GET("/pods", handler.ListPods)
+
+	req, _ := http.NewRequest("GET", "/pods?namespace=default&limit=1&continue=this-page", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response PodListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Pods) != 1 || response.Continue != "next-page" {
+		t.Errorf("Expected one pod and continue token 'next-page', got %+v", response)
+	}
+
+	req, _ = http.NewRequest("GET", "/pods?namespace=default&limit=0", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for limit=0, got %d", w.Code)
+	}
+}
+
+func TestPodSummaries(t *testing.T) {
+	condition := func(status v1.ConditionStatus) []v1.PodCondition {
+		return []v1.PodCondition{{Type: v1.PodReady, Status: status, LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute))}}
+	}
+	fakeClientset := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, Conditions: condition(v1.ConditionTrue), ContainerStatuses: []v1.ContainerStatus{{Name: "web", Ready: true}}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "unready", Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, Conditions: condition(v1.ConditionFalse), ContainerStatuses: []v1.ContainerStatus{{Name: "web"}}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "initializing", Namespace: "default"},
+			Spec:       v1.PodSpec{InitContainers: []v1.Container{{Name: "migrate"}}, Containers: []v1.Container{{Name: "web"}}},
+			Status: v1.PodStatus{Phase: v1.PodPending, InitContainerStatuses: []v1.ContainerStatus{{
+				Name:  "migrate",
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}},
+		},
+	)
+	handler := NewHandler(fakeClientset)
+
+	r := gin.New()
+	r.GET("/pods/summary", handler.PodSummaries)
+
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"?namespace=default", []string{"initializing", "ready", "unready"}},
+		{"?namespace=default&notReady=true", []string{"unready"}},
+	} {
+		req, _ := http.NewRequest("GET", "/pods/summary"+tt.query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response PodSummaryListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		var names []string
+		for _, pod := range response.Pods {
+			names = append(names, pod.Name)
+			if pod.Name == "initializing" && (pod.Status != "Init:CrashLoopBackOff" || pod.TotalContainers != 1 || len(pod.InitContainers) != 1) {
+				t.Errorf("Expected the init progress of initializing, got %+v", pod)
+			}
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Expected pods %v for %s, got %v", tt.want, tt.query, names)
+		}
+	}
+}
+
+func TestGetPod(t *testing.T) {
+	privileged := true
+	fakeClientset := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ops", Labels: map[string]string{"pod-security.kubernetes.io/enforce": "privileged"}}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "ops"},
+			Spec: v1.PodSpec{
+				HostNetwork: true,
+				Containers: []v1.Container{{
+					Name:            "agent",
+					SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+				}},
+			},
+		},
+	)
+	handler := NewHandler(fakeClientset)
+
+	r := gin.New()
+	r.GET("/pods/:namespace/:name", handler.GetPod)
+
+	req, _ := http.NewRequest("GET", "/pods/ops/agent", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response PodDetailsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	security := response.Security
+	if response.Pod == nil || response.Name != "agent" || !security.HostNetwork || len(security.Risks) != 1 || security.PodSecurityLabels["enforce"] != "privileged" {
+		t.Errorf("Expected the privileged agent with its namespace's labels, got %+v", response)
+	}
+
+	req, _ = http.NewRequest("GET", "/pods/ops/missing", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing pod, got %d", w.Code)
+	}
+}
+
 func TestCreatePod(t *testing.T) {
 	fakeClientset := fake.NewSimpleClientset()
 	handler := NewHandler(fakeClientset)
@@ -125,4 +275,256 @@ func TestCreatePod(t *testing.T) {
 	if createdPod.Name != "new-pod" {
 		t.Errorf("Expected pod name 'new-pod', got '%s'", createdPod.Name)
 	}
+
+	var body PodResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if want := "kubectl -n default run new-pod --image=nginx"; body.KubectlEquivalent != want {
+		t.Errorf("Expected kubectlEquivalent %q, got %q", want, body.KubectlEquivalent)
+	}
+}
+
+func TestCreatePodInvalid(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset()
+	handler := NewHandler(fakeClientset)
+
+	r := gin.Default()
+	r.POST("/pods/:namespace", handler.CreatePod)
+
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "new-pod"}}
+	podJSON, _ := json.Marshal(pod)
+	req, _ := http.NewRequest("POST", "/pods/default", bytes.NewBuffer(podJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422 for a pod without containers, got %d", w.Code)
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || !strings.Contains(body.Error, "spec.containers") {
+		t.Errorf("Expected the invalid field in the error, got %q, %v", body.Error, err)
+	}
+	if pods, _ := fakeClientset.CoreV1().Pods("default").List(context.TODO(), metav1.ListOptions{}); len(pods.Items) != 0 {
+		t.Errorf("Expected the invalid pod not to be created, got %d pods", len(pods.Items))
+	}
+}
+
+// TestImagePolicyEnforced checks that every REST path creating or changing a
+// pod spec rejects images from registries outside the allowlist
+func TestImagePolicyEnforced(t *testing.T) {
+	existing := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "registry.internal/web"}}},
+	}
+	clientset := fake.NewSimpleClientset(existing)
+	r := gin.New()
+	RegisterRoutes(r, clientset, RouterOptions{
+		ImagePolicy: k8s.NewImagePolicy([]string{"registry.internal"}),
+	})
+
+	pod := `{"metadata": {"name": "api"}, "spec": {"containers": [{"name": "api", "image": "%s"}]}}`
+	deployment := `{"metadata": {"name": "api"}, "spec": {"selector": {"matchLabels": {"app": "api"}}, "template": {"metadata": {"labels": {"app": "api"}}, "spec": {"containers": [{"name": "api", "image": "%s"}]}}}}`
+	manifest := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: applied\nspec:\n  containers:\n  - name: api\n    image: %s\n"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"create pod", "POST", "/api/v1/pods/default", pod},
+		{"update pod", "PUT", "/api/v1/pods/default/web", pod},
+		{"create deployment", "POST", "/api/v1/deployments/default", deployment},
+		{"apply", "POST", "/api/v1/apply/default", manifest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(fmt.Sprintf(tt.body, "nginx:1.25")))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected status 422, got %d: %s", w.Code, w.Body.String())
+			}
+			var body ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil ||
+				!strings.Contains(body.Error, `container "api"`) || !strings.Contains(body.Error, "allowed registries: registry.internal") {
+				t.Errorf("Expected the container and allowed registries in the error, got %q, %v", body.Error, err)
+			}
+
+			req, _ = http.NewRequest(tt.method, tt.path, strings.NewReader(fmt.Sprintf(tt.body, "registry.internal/api:1.0")))
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code >= 300 {
+				t.Errorf("Expected an allowed image to pass, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestApplyURL checks applying a manifest fetched from an allowed host, with
+// its checksum verified, and the refusals of the URLs and checksums
+func TestApplyURL(t *testing.T) {
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: fetched\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: web\nspec:\n  containers:\n  - name: web\n    image: nginx\n"
+	manifestServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ops/app.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(manifest))
+	}))
+	defer manifestServer.Close()
+	checksum, _ := k8s.VerifyManifestChecksum([]byte(manifest), "")
+	host := strings.TrimPrefix(manifestServer.URL, "http://")
+
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	clientset := fake.NewSimpleClientset()
+	r := gin.New()
+	RegisterRoutes(r, clientset, RouterOptions{
+		Guard:           guard,
+		ImagePolicy:     k8s.NewImagePolicy([]string{"registry.internal"}),
+		ManifestFetcher: k8s.NewManifestFetcher([]string{host}, []string{"http"}),
+	})
+	apply := func(body string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/apply/url", strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := apply(fmt.Sprintf(`{"url": "%s/ops/app.yaml", "sha256": "sha256:%s", "namespace": "default"}`, manifestServer.URL, checksum), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response ApplyURLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.SHA256 != checksum || len(response.Results) != 2 || response.KubectlEquivalent != "kubectl -n default apply -f "+manifestServer.URL+"/ops/app.yaml" {
+		t.Fatalf("Expected the checksum and a result per document, got %+v", response)
+	}
+	// The documents are applied on their own, and the image policy holds
+	if response.Results[0].Action != "created" || response.Results[0].Error != "" {
+		t.Errorf("Expected the configmap to be created, got %+v", response.Results[0])
+	}
+	if response.Results[1].Kind != "Pod" || !strings.Contains(response.Results[1].Error, "allowed registries") {
+		t.Errorf("Expected the pod to be rejected by the image policy, got %+v", response.Results[1])
+	}
+	if _, err := clientset.CoreV1().ConfigMaps("default").Get(context.Background(), "fetched", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the configmap to exist: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+		want    int
+	}{
+		{"missing namespace", fmt.Sprintf(`{"url": "%s/ops/app.yaml"}`, manifestServer.URL), nil, http.StatusBadRequest},
+		{"host not allowed", `{"url": "http://kubernetes.default.svc/api", "namespace": "default"}`, nil, http.StatusForbidden},
+		{"scheme not allowed", fmt.Sprintf(`{"url": "https://%s/ops/app.yaml", "namespace": "default"}`, host), nil, http.StatusForbidden},
+		{"checksum mismatch", fmt.Sprintf(`{"url": "%s/ops/app.yaml", "sha256": "%s", "namespace": "default"}`, manifestServer.URL, strings.Repeat("0", 64)), nil, http.StatusUnprocessableEntity},
+		{"invalid checksum", fmt.Sprintf(`{"url": "%s/ops/app.yaml", "sha256": "md5:abc", "namespace": "default"}`, manifestServer.URL), nil, http.StatusBadRequest},
+		{"missing manifest", fmt.Sprintf(`{"url": "%s/ops/missing.yaml", "namespace": "default"}`, manifestServer.URL), nil, http.StatusBadGateway},
+		{"protected namespace", fmt.Sprintf(`{"url": "%s/ops/app.yaml", "namespace": "prod-eu"}`, manifestServer.URL), nil, http.StatusPreconditionRequired},
+		{"confirmed namespace", fmt.Sprintf(`{"url": "%s/ops/app.yaml", "namespace": "prod-eu"}`, manifestServer.URL), map[string]string{ConfirmHeader: "prod-eu"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := apply(tt.body, tt.headers); w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, w.Code, w.Body.String())
+		}
+	}
+
+	// Without allowed hosts, applying from URLs is disabled
+	disabled := gin.New()
+	RegisterRoutes(disabled, clientset, RouterOptions{})
+	req, _ := http.NewRequest("POST", "/api/v1/apply/url", strings.NewReader(fmt.Sprintf(`{"url": "%s/ops/app.yaml", "namespace": "default"}`, manifestServer.URL)))
+	w = httptest.NewRecorder()
+	disabled.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 without allowed hosts, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestDeleteSafety checks the warnings of deletes, and that ?force=true
+// empties finalizers before deleting
+func TestDeleteSafety(t *testing.T) {
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-5d8f", Namespace: "default", UID: "rs-uid"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d8f-x", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+	}}
+	finalized := func() *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", Finalizers: []string{"example.com/cleanup"}}}
+	}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		path     string
+		warnings []string
+		removed  []string
+		kubectl  string
+		patched  bool
+		wantCode int
+	}{
+		{"recreated pod", []runtime.Object{rs, pod}, "/api/v1/pods/default/web-5d8f-x",
+			[]string{"It will be recreated by ReplicaSet/web-5d8f."}, nil, "kubectl -n default delete pod web-5d8f-x", false, http.StatusOK},
+		{"force without finalizers", []runtime.Object{rs, pod}, "/api/v1/pods/default/web-5d8f-x?force=true",
+			[]string{"It will be recreated by ReplicaSet/web-5d8f."}, nil, "kubectl -n default delete pod web-5d8f-x", false, http.StatusOK},
+		{"finalizers", []runtime.Object{finalized()}, "/api/v1/deployments/default/api",
+			[]string{"Deletion is blocked by finalizers example.com/cleanup."}, nil, "kubectl -n default delete deployment api", false, http.StatusOK},
+		{"force", []runtime.Object{finalized()}, "/api/v1/deployments/default/api?force=true",
+			nil, []string{"example.com/cleanup"},
+			`kubectl -n default patch deployment api --type=merge -p '{"metadata":{"finalizers":[]}}' && kubectl -n default delete deployment api`, true, http.StatusOK},
+		{"no concerns", []runtime.Object{&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}}, "/api/v1/configmaps/default/app",
+			nil, nil, "kubectl -n default delete configmap app", false, http.StatusOK},
+		{"missing", nil, "/api/v1/services/default/web", nil, nil, "", false, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.objects...)
+			r := gin.New()
+			RegisterRoutes(r, clientset, RouterOptions{})
+
+			req, _ := http.NewRequest("DELETE", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var body DeleteResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if strings.Join(body.Warnings, "|") != strings.Join(tt.warnings, "|") || strings.Join(body.FinalizersRemoved, ",") != strings.Join(tt.removed, ",") {
+				t.Errorf("Expected warnings %v and removed finalizers %v, got %+v", tt.warnings, tt.removed, body)
+			}
+			if body.KubectlEquivalent != tt.kubectl {
+				t.Errorf("Expected kubectl %q, got %q", tt.kubectl, body.KubectlEquivalent)
+			}
+
+			patched := false
+			for _, action := range clientset.Actions() {
+				if action.GetVerb() == "patch" {
+					patched = true
+				}
+			}
+			if patched != tt.patched {
+				t.Errorf("Expected a finalizer patch: %v, got %v", tt.patched, patched)
+			}
+		})
+	}
 }
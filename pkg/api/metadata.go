@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+// PatchLabels handles PATCH /api/v1/:kind/:namespace/:name/labels with a body
+// of {"set": {"tier": "frontend"}, "remove": ["team"]}
+func (h *ResourceHandler) PatchLabels(c *gin.Context) {
+	h.patchMetadata(c, k8s.LabelsField)
+}
+
+// PatchAnnotations handles PATCH /api/v1/:kind/:namespace/:name/annotations
+// with the body of PatchLabels
+func (h *ResourceHandler) PatchAnnotations(c *gin.Context) {
+	h.patchMetadata(c, k8s.AnnotationsField)
+}
+
+// patchMetadata sets and removes the labels or annotations of an object with
+// a JSON merge patch, leaving the keys the request does not name alone
+func (h *ResourceHandler) patchMetadata(c *gin.Context, field string) {
+	kind, namespace, name := c.Param("kind"), c.Param("namespace"), c.Param("name")
+	if !k8s.SupportsMetadataPatch(kind) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: fmt.Sprintf("Unsupported kind %q", kind)})
+		return
+	}
+
+	var change k8s.MetadataChange
+	if err := c.ShouldBindJSON(&change); err != nil {
+		klog.Errorf("Failed to bind JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if change.IsEmpty() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Nothing to change, expected keys to set or remove"})
+		return
+	}
+	for _, key := range change.Remove {
+		if _, ok := change.Set[key]; ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("Key %q is both set and removed", key)})
+			return
+		}
+	}
+	if err := validation.Metadata(kind, name, field, change.Set, change.Remove); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	obj, err := k8s.PatchMetadata(c.Request.Context(), h.clientset, kind, namespace, name, field, change)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Report objects without labels or annotations with empty maps, not null
+	labels, annotations := obj.GetLabels(), obj.GetAnnotations()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	c.JSON(http.StatusOK, MetadataResponse{
+		Kind:              kind,
+		Namespace:         obj.GetNamespace(),
+		Name:              obj.GetName(),
+		Labels:            labels,
+		Annotations:       annotations,
+		KubectlEquivalent: k8s.KubectlEditMetadata(namespace, kind, name, field, change),
+	})
+}
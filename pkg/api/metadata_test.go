@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPatchMetadata(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Namespace:   "default",
+		Labels:      map[string]string{"app": "web", "team": "a"},
+		Annotations: map[string]string{"owner": "alice"},
+	}})
+	handler := NewResourceHandler(clientset)
+	r := gin.New()
+	r.PATCH("/:kind/:namespace/:name/labels", handler.PatchLabels)
+	r.PATCH("/:kind/:namespace/:name/annotations", handler.PatchAnnotations)
+
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch("/deployments/default/web/labels", `{"set": {"tier": "frontend"}, "remove": ["team"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body MetadataResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(body.Labels) != 2 || body.Labels["app"] != "web" || body.Labels["tier"] != "frontend" {
+		t.Errorf("Expected app and tier labels, got %v", body.Labels)
+	}
+	if body.Annotations["owner"] != "alice" {
+		t.Errorf("Expected the annotations to be left alone, got %v", body.Annotations)
+	}
+	if want := "kubectl -n default label deployments web tier=frontend team- --overwrite"; body.KubectlEquivalent != want {
+		t.Errorf("Expected kubectlEquivalent %s, got %s", want, body.KubectlEquivalent)
+	}
+
+	w = patch("/deployments/default/web/annotations", `{"set": {"example.com/note": "see #42"}, "remove": ["owner"]}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"annotations":{"example.com/note":"see #42"}`) {
+		t.Errorf("Expected the annotations to be replaced, got %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		code int
+	}{
+		{"invalid label value", "/deployments/default/web/labels", `{"set": {"tier": "front end"}}`, http.StatusUnprocessableEntity},
+		{"invalid label key", "/deployments/default/web/labels", `{"remove": ["bad key"]}`, http.StatusUnprocessableEntity},
+		{"set and removed", "/deployments/default/web/labels", `{"set": {"app": "api"}, "remove": ["app"]}`, http.StatusBadRequest},
+		{"empty", "/deployments/default/web/labels", `{}`, http.StatusBadRequest},
+		{"invalid JSON", "/deployments/default/web/labels", `{"set": ["tier"]}`, http.StatusBadRequest},
+		{"unsupported kind", "/nodes/default/node-1/labels", `{"set": {"a": "b"}}`, http.StatusNotFound},
+		{"missing object", "/deployments/default/api/labels", `{"set": {"a": "b"}}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := patch(tt.path, tt.body); w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
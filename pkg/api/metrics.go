@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// MetricsHandler struct holds the Kubernetes clientset
+type MetricsHandler struct {
+	clientset kubernetes.Interface
+	// metricsClientset reads node usage from metrics-server; nil disables it
+	metricsClientset metricsclientset.Interface
+	// now stamps the metrics responses
+	now func() time.Time
+}
+
+// NewMetricsHandler creates a new metrics API handler
+func NewMetricsHandler(clientset kubernetes.Interface) *MetricsHandler {
+	return &MetricsHandler{clientset: clientset, now: time.Now}
+}
+
+// SetMetricsClientset sets the client node usage is read through
+func (h *MetricsHandler) SetMetricsClientset(metricsClientset metricsclientset.Interface) {
+	h.metricsClientset = metricsClientset
+}
+
+// GetClusterMetrics handles GET /api/v1/metrics/cluster
+func (h *MetricsHandler) GetClusterMetrics(c *gin.Context) {
+	clusterMetrics, err := metrics.CollectClusterMetrics(h.clientset, h.now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, clusterMetrics)
+}
+
+// GetNamespaceMetrics handles GET /api/v1/metrics/namespace/:namespace
+func (h *MetricsHandler) GetNamespaceMetrics(c *gin.Context) {
+	namespaceMetrics, err := metrics.CollectNamespaceMetrics(h.clientset, c.Param("namespace"), h.now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, namespaceMetrics)
+}
+
+// GetAllNamespaceMetrics handles GET /api/v1/metrics/namespaces, the metrics
+// of every namespace, or of those matching ?labelSelector=, in one response
+func (h *MetricsHandler) GetAllNamespaceMetrics(c *gin.Context) {
+	labelSelector := c.Query("labelSelector")
+	if _, err := labels.Parse(labelSelector); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid labelSelector: " + err.Error()})
+		return
+	}
+
+	allMetrics, err := metrics.CollectAllNamespaceMetrics(c.Request.Context(), h.clientset, labelSelector, h.now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, allMetrics)
+}
+
+// GetOverview handles GET /api/v1/overview
+func (h *MetricsHandler) GetOverview(c *gin.Context) {
+	overview, err := metrics.CollectOverview(c.Request.Context(), h.clientset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// GetNodeMetrics handles GET /api/v1/metrics/nodes, the usage of every node
+// like kubectl top node. Without metrics-server it fails with 503.
+func (h *MetricsHandler) GetNodeMetrics(c *gin.Context) {
+	if h.metricsClientset == nil {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "node metrics need a metrics client"})
+		return
+	}
+
+	nodes, err := k8s.GetNodeMetrics(c.Request.Context(), h.metricsClientset, h.clientset)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "node metrics unavailable, is metrics-server running? " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, NodeMetricsResponse{Nodes: nodes})
+}
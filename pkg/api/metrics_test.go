@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestGetClusterMetrics(t *testing.T) {
+	// Create a fake clientset
+	clientset := fake.NewSimpleClientset()
+
+	// Create metrics handler
+	handler := NewMetricsHandler(clientset)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/metrics/cluster", nil)
+	w := httptest.NewRecorder()
+
+	// Create gin context
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	// Call the handler
+	handler.GetClusterMetrics(c)
+
+	// Check response
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	// Check content type
+	contentType := w.Header().Get("Content-Type")
+	if contentType != "application/json; charset=utf-8" {
+		t.Errorf("Expected content type application/json, got %s", contentType)
+	}
+}
+
+func TestGetNamespaceMetrics(t *testing.T) {
+	// Create a fake clientset
+	clientset := fake.NewSimpleClientset()
+
+	// Create metrics handler
+	handler := NewMetricsHandler(clientset)
+
+	// Create a test request
+	req, _ := http.NewRequest("GET", "/api/v1/metrics/namespace/default", nil)
+	w := httptest.NewRecorder()
+
+	// Create gin context
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "namespace", Value: "default"}}
+
+	// Call the handler
+	handler.GetNamespaceMetrics(c)
+
+	// Check response
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestGetOverview(t *testing.T) {
+	replicas := int32(2)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodPending},
+		},
+	)
+	handler := NewMetricsHandler(clientset)
+
+	req, _ := http.NewRequest("GET", "/api/v1/overview", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetOverview(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var overview metrics.Overview
+	if err := json.Unmarshal(w.Body.Bytes(), &overview); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if overview.Pods.Total != 1 || overview.Pods.NotReady != 1 {
+		t.Errorf("Expected one pod that is not ready, got %+v", overview.Pods)
+	}
+	if len(overview.Deployments.Degraded) != 1 || overview.Deployments.Degraded[0].Name != "web" {
+		t.Errorf("Expected web to be degraded, got %+v", overview.Deployments)
+	}
+}
+
+func TestGetAllNamespaceMetricsRejectsSelector(t *testing.T) {
+	handler := NewMetricsHandler(fake.NewSimpleClientset())
+
+	req, _ := http.NewRequest("GET", "/api/v1/metrics/namespaces?labelSelector=env%3D%3D%3D", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetAllNamespaceMetrics(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid selector, got %d", w.Code)
+	}
+}
+
+func TestGetNodeMetrics(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("2"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		}},
+	})
+	get := func(handler *MetricsHandler) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/metrics/nodes", nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		handler.GetNodeMetrics(c)
+		return w
+	}
+
+	handler := NewMetricsHandler(clientset)
+	if w := get(handler); w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 without a metrics client, got %d", w.Code)
+	}
+
+	metricsClientset := &metricsfake.Clientset{}
+	metricsClientset.AddReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Usage:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("1700m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+		}}}, nil
+	})
+	handler.SetMetricsClientset(metricsClientset)
+	w := get(handler)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response NodeMetricsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Nodes) != 1 || response.Nodes[0].CPUPercent != 85 || response.Nodes[0].MemPercent != 25 {
+		t.Errorf("Expected node-1 at 85%% CPU and 25%% memory, got %+v", response.Nodes)
+	}
+
+	unavailable := &metricsfake.Clientset{}
+	unavailable.AddReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server could not find the requested resource")
+	})
+	handler.SetMetricsClientset(unavailable)
+	if w := get(handler); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without metrics-server, got %d", w.Code)
+	}
+}
+
+// TestMetricsContractMatchesGolden guards the full bodies of the metrics
+// endpoints, values included, over a fixed cluster and clock. Run with -update
+// after an intentional change, and raise metrics.SchemaVersion unless the
+// change only adds fields.
+func TestMetricsContractMatchesGolden(t *testing.T) {
+	pod := func(namespace, name string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Status: v1.PodStatus{Phase: phase}}
+	}
+	deployment := func(name string, replicas, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     appsv1.DeploymentStatus{Replicas: replicas, ReadyReplicas: ready},
+		}
+	}
+	clientset := fake.NewSimpleClientset(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "staging", Labels: map[string]string{"env": "staging"}}},
+		pod("default", "web-1", v1.PodRunning),
+		pod("default", "web-2", v1.PodRunning),
+		pod("default", "migrate", v1.PodSucceeded),
+		pod("default", "worker", v1.PodPending),
+		pod("staging", "web-1", v1.PodFailed),
+		pod("staging", "scheduling", ""),
+		deployment("web", 2, 2),
+		deployment("api", 3, 1),
+		deployment("worker", 1, 0),
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+	handler := NewMetricsHandler(clientset)
+	handler.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	r := gin.New()
+	r.GET("/api/v1/metrics/cluster", handler.GetClusterMetrics)
+	r.GET("/api/v1/metrics/namespace/:namespace", handler.GetNamespaceMetrics)
+	r.GET("/api/v1/metrics/namespaces", handler.GetAllNamespaceMetrics)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"metrics_cluster", "/api/v1/metrics/cluster"},
+		{"metrics_namespace", "/api/v1/metrics/namespace/default"},
+		{"metrics_namespaces", "/api/v1/metrics/namespaces"},
+		{"metrics_namespaces_selected", "/api/v1/metrics/namespaces?labelSelector=env%3Dstaging"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			// Indenting keeps the bytes, key order and number formatting
+			// included, while making the golden files readable
+			var body bytes.Buffer
+			if err := json.Indent(&body, w.Body.Bytes(), "", "  "); err != nil {
+				t.Fatalf("Failed to indent response: %v", err)
+			}
+			body.WriteByte('\n')
+
+			goldenPath := filepath.Join("testdata", "golden", "contract", tt.name+".json")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, body.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(golden, body.Bytes()) {
+				t.Errorf("Response changed from %s:\nwant:\n%s\ngot:\n%s", goldenPath, golden, body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// ConfirmHeader is the header a client sets to the target namespace to confirm
+// a mutating operation in a protected namespace
+const ConfirmHeader = "X-KGO-Confirm"
+
+const (
+	// DefaultMaxBodySize bounds the request bodies of the REST API
+	DefaultMaxBodySize int64 = 1 << 20
+	// ApplyMaxBodySize bounds the manifests of POST /api/v1/apply/:namespace,
+	// which may hold many objects
+	ApplyMaxBodySize int64 = 10 << 20
+)
+
+// MaxBodySizeMiddleware rejects requests whose body is over maxBytes with 413.
+// A Content-Length over it is rejected before the handler runs; a chunked
+// body is cut off at maxBytes, failing the handler's read with an
+// *http.MaxBytesError.
+func MaxBodySizeMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			klog.Warningf("Rejected %s %s: body of %d bytes is over %d", c.Request.Method, c.Request.URL.Path, c.Request.ContentLength, maxBytes)
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("request body is over %d bytes", maxBytes)})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// maxBodySizeByRoute limits request bodies to defaultMax, or to the limit of
+// their route in limits by full path, e.g. "/api/v1/apply/:namespace"
+func maxBodySizeByRoute(defaultMax int64, limits map[string]int64) gin.HandlerFunc {
+	limitDefault := MaxBodySizeMiddleware(defaultMax)
+	middlewares := make(map[string]gin.HandlerFunc, len(limits))
+	for route, maxBytes := range limits {
+		middlewares[route] = MaxBodySizeMiddleware(maxBytes)
+	}
+	return func(c *gin.Context) {
+		if middleware, ok := middlewares[c.FullPath()]; ok {
+			middleware(c)
+			return
+		}
+		limitDefault(c)
+	}
+}
+
+// isBodyTooLarge reports whether reading a request body failed because
+// MaxBodySizeMiddleware cut it off
+func isBodyTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// ProtectedNamespaceMiddleware rejects mutating requests against protected
+// namespaces unless the X-KGO-Confirm header names the target namespace
+func ProtectedNamespaceMiddleware(guard *k8s.NamespaceGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isReadOnlyMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if !checkNamespaceConfirmation(c, guard, c.GetHeader(ConfirmHeader)) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ConfirmedNamespaceMiddleware requires the confirmation of
+// ProtectedNamespaceMiddleware whatever the method, for GET routes that act on
+// the cluster, such as exec. A browser cannot set headers on a WebSocket
+// upgrade, so the confirmation may also come in the confirm query parameter.
+func ConfirmedNamespaceMiddleware(guard *k8s.NamespaceGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		confirmation := c.GetHeader(ConfirmHeader)
+		if confirmation == "" {
+			confirmation = c.Query("confirm")
+		}
+		if !checkNamespaceConfirmation(c, guard, confirmation) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// checkNamespaceConfirmation aborts the request with 428 unless the namespace
+// of its route is unprotected or confirmed, reporting whether it may go on
+func checkNamespaceConfirmation(c *gin.Context, guard *k8s.NamespaceGuard, confirmation string) bool {
+	if err := guard.Check(c.Param("namespace"), confirmation); err != nil {
+		klog.Warningf("Rejected %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, ErrorResponse{Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+// isReadOnlyMethod reports whether an HTTP method never mutates cluster state
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// annotationPolicyExemptNamespace is left out of the annotation policy, as
+// its objects belong to the cluster rather than to a team
+const annotationPolicyExemptNamespace = "kube-system"
+
+// AnnotationPolicyMiddleware rejects a create request whose object lacks any
+// of the required annotations, or has them empty, with 422 and the missing
+// keys. It goes on create routes whose body is a Kubernetes object; bodies
+// that do not decode are left to the handler. Requests for kube-system and
+// an empty policy pass.
+func AnnotationPolicyMiddleware(requiredAnnotations []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(requiredAnnotations) == 0 || c.Param("namespace") == annotationPolicyExemptNamespace || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if isBodyTooLarge(err) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			c.Next()
+			return
+		}
+		var object struct {
+			Metadata struct {
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(body, &object); err != nil {
+			c.Next()
+			return
+		}
+
+		if missing := missingAnnotations(requiredAnnotations, object.Metadata.Annotations); len(missing) > 0 {
+			rejectMissingAnnotations(c, &missingAnnotationsError{missing: missing})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// missingAnnotationsError is returned for an object lacking required
+// annotations
+type missingAnnotationsError struct {
+	missing []string
+}
+
+func (e *missingAnnotationsError) Error() string {
+	return "missing required annotations: " + strings.Join(e.missing, ", ")
+}
+
+// checkAnnotationPolicy is AnnotationPolicyMiddleware for handlers that build
+// or decode the objects they create themselves: it returns a
+// *missingAnnotationsError when obj, created in namespace, lacks any of the
+// required annotations. Objects without metadata pass.
+func checkAnnotationPolicy(requiredAnnotations []string, namespace string, obj runtime.Object) error {
+	if len(requiredAnnotations) == 0 || namespace == annotationPolicyExemptNamespace {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+	if missing := missingAnnotations(requiredAnnotations, accessor.GetAnnotations()); len(missing) > 0 {
+		return &missingAnnotationsError{missing: missing}
+	}
+	return nil
+}
+
+// rejectMissingAnnotations aborts a request with 422 and the annotations
+// missing from its object
+func rejectMissingAnnotations(c *gin.Context, err *missingAnnotationsError) {
+	klog.Warningf("Rejected %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, AnnotationPolicyResponse{
+		Error:   "missing required annotations",
+		Missing: err.missing,
+	})
+}
+
+// missingAnnotations returns the required annotations that are missing or
+// empty
+func missingAnnotations(requiredAnnotations []string, annotations map[string]string) []string {
+	var missing []string
+	for _, key := range requiredAnnotations {
+		if strings.TrimSpace(annotations[key]) == "" {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,380 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newProtectedRouter(t *testing.T) *gin.Engine {
+	guard, err := k8s.NewNamespaceGuard([]string{"kube-system", "prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod-eu"}}
+	handler := NewHandler(fake.NewSimpleClientset(pod))
+
+	r := gin.New()
+	v1 := r.Group("/api/v1")
+	v1.Use(ProtectedNamespaceMiddleware(guard))
+	v1.GET("/pods", handler.ListPods)
+	v1.POST("/pods/:namespace", handler.CreatePod)
+	v1.DELETE("/pods/:namespace/:name", handler.DeletePod)
+	v1.GET("/pods/:namespace/:name/exec", ConfirmedNamespaceMiddleware(guard), func(c *gin.Context) {
+		c.Status(http.StatusSwitchingProtocols)
+	})
+	return r
+}
+
+func TestProtectedNamespaceRequiresConfirmHeader(t *testing.T) {
+	r := newProtectedRouter(t)
+
+	req, _ := http.NewRequest("DELETE", "/api/v1/pods/prod-eu/web", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status 428, got %d", w.Code)
+	}
+
+	// Confirming the wrong namespace is not enough
+	req, _ = http.NewRequest("DELETE", "/api/v1/pods/prod-eu/web", nil)
+	req.Header.Set(ConfirmHeader, "prod-us")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Expected status 428 for mismatched confirmation, got %d", w.Code)
+	}
+
+	req, _ = http.NewRequest("DELETE", "/api/v1/pods/prod-eu/web", nil)
+	req.Header.Set(ConfirmHeader, "prod-eu")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with confirmation, got %d", w.Code)
+	}
+}
+
+// TestProtectedNamespaceGuardsExec tests that exec, though a GET, needs the
+// confirmation in a protected namespace, from the header or the query
+func TestProtectedNamespaceGuardsExec(t *testing.T) {
+	r := newProtectedRouter(t)
+
+	tests := []struct {
+		name   string
+		url    string
+		header string
+		want   int
+	}{
+		{"unconfirmed", "/api/v1/pods/prod-eu/web/exec", "", http.StatusPreconditionRequired},
+		{"wrong namespace", "/api/v1/pods/prod-eu/web/exec?confirm=prod-us", "", http.StatusPreconditionRequired},
+		{"header", "/api/v1/pods/prod-eu/web/exec", "prod-eu", http.StatusSwitchingProtocols},
+		{"query", "/api/v1/pods/prod-eu/web/exec?confirm=prod-eu", "", http.StatusSwitchingProtocols},
+		{"unprotected", "/api/v1/pods/default/web/exec", "", http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if tt.header != "" {
+				req.Header.Set(ConfirmHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestProtectedNamespaceAllowsReadsAndUnprotected(t *testing.T) {
+	r := newProtectedRouter(t)
+
+	req, _ := http.NewRequest("GET", "/api/v1/pods?namespace=prod-eu", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected read in protected namespace to succeed, got %d", w.Code)
+	}
+
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-pod"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "c", Image: "nginx"}}},
+	}
+	podJSON, _ := json.Marshal(pod)
+	req, _ = http.NewRequest("POST", "/api/v1/pods/default", bytes.NewBuffer(podJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected create in unprotected namespace to succeed, got %d", w.Code)
+	}
+}
+
+func TestAnnotationPolicyMiddleware(t *testing.T) {
+	handler := NewHandler(fake.NewSimpleClientset())
+	r := gin.New()
+	r.POST("/pods/:namespace", AnnotationPolicyMiddleware([]string{"owner", "team"}), handler.CreatePod)
+
+	create := func(namespace string, annotations map[string]string) *httptest.ResponseRecorder {
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: annotations},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx"}}},
+		}
+		podJSON, _ := json.Marshal(pod)
+		req, _ := http.NewRequest("POST", "/pods/"+namespace, bytes.NewBuffer(podJSON))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := create("default", map[string]string{"team": " "})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AnnotationPolicyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Error != "missing required annotations" || len(resp.Missing) != 2 || resp.Missing[0] != "owner" || resp.Missing[1] != "team" {
+		t.Errorf("Expected owner and team to be missing, got %+v", resp)
+	}
+
+	// The handler still gets the whole body once the policy is met
+	w = create("default", map[string]string{"owner": "alice", "team": "payments"})
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 with the annotations, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := create("kube-system", nil); w.Code != http.StatusCreated {
+		t.Errorf("Expected kube-system to be exempt, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Bodies that are not objects are left to the handler
+	req, _ := http.NewRequest("POST", "/pods/default", bytes.NewBufferString("not json"))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected the handler's status 400 for an invalid body, got %d", w.Code)
+	}
+}
+
+// TestAnnotationPolicyOnBuiltObjects tests that the routes building or
+// decoding their objects themselves enforce the policy: configmaps from data
+// and applied manifests
+func TestAnnotationPolicyOnBuiltObjects(t *testing.T) {
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: fetched\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: owned\n  annotations:\n    owner: alice\n"
+	manifestServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(manifest))
+	}))
+	defer manifestServer.Close()
+
+	r := gin.New()
+	RegisterRoutes(r, fake.NewSimpleClientset(), RouterOptions{
+		RequiredAnnotations: []string{"owner"},
+		ManifestFetcher:     k8s.NewManifestFetcher([]string{strings.TrimPrefix(manifestServer.URL, "http://")}, []string{"http"}),
+	})
+	post := func(url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	owned := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: applied\n  annotations:\n    owner: alice\n"
+	tests := []struct {
+		name string
+		url  string
+		body string
+		want int
+	}{
+		{"from data", "/api/v1/configmaps/default/from-data", `{"name": "settings", "literals": {"mode": "prod"}}`, http.StatusUnprocessableEntity},
+		{"annotated from data", "/api/v1/configmaps/default/from-data", `{"name": "settings", "literals": {"mode": "prod"}, "annotations": {"owner": "alice"}}`, http.StatusCreated},
+		{"apply", "/api/v1/apply/default", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: applied\n", http.StatusUnprocessableEntity},
+		{"annotated apply", "/api/v1/apply/default", owned, http.StatusOK},
+		{"apply to kube-system", "/api/v1/apply/kube-system", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: applied\n", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := post(tt.url, tt.body)
+			if w.Code != tt.want {
+				t.Fatalf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if tt.want != http.StatusUnprocessableEntity {
+				return
+			}
+			var resp AnnotationPolicyResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Missing) != 1 || resp.Missing[0] != "owner" {
+				t.Errorf("Expected owner to be missing, got %s", w.Body.String())
+			}
+		})
+	}
+
+	// Each document of a manifest from a URL is checked on its own
+	w := post("/api/v1/apply/url", `{"url": "`+manifestServer.URL+`/app.yaml", "namespace": "default"}`)
+	var response ApplyURLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response.Results) != 2 {
+		t.Fatalf("Expected a result per document, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.Results[0].Error != "missing required annotations: owner" {
+		t.Errorf("Expected the unannotated configmap to be rejected, got %+v", response.Results[0])
+	}
+	if response.Results[1].Action != "created" {
+		t.Errorf("Expected the annotated configmap to be created, got %+v", response.Results[1])
+	}
+}
+
+func TestAnnotationPolicyMiddlewareWithoutPolicy(t *testing.T) {
+	r := gin.New()
+	r.POST("/pods/:namespace", AnnotationPolicyMiddleware(nil), NewHandler(fake.NewSimpleClientset()).CreatePod)
+
+	podJSON := `{"metadata": {"name": "web"}, "spec": {"containers": [{"name": "web", "image": "nginx"}]}}`
+	req, _ := http.NewRequest("POST", "/pods/default", bytes.NewBufferString(podJSON))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 without a policy, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMaxBodySizeMiddleware(t *testing.T) {
+	r := gin.New()
+	v1 := r.Group("/api/v1")
+	v1.Use(maxBodySizeByRoute(DefaultMaxBodySize, map[string]int64{"/api/v1/apply/:namespace": ApplyMaxBodySize}))
+	read := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if isBodyTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"size": len(body)})
+	}
+	v1.POST("/configmaps/:namespace", read)
+	v1.POST("/apply/:namespace", read)
+
+	body := bytes.Repeat([]byte("x"), 2<<20)
+	tests := []struct {
+		path    string
+		chunked bool
+		status  int
+	}{
+		{"/api/v1/configmaps/default", false, http.StatusRequestEntityTooLarge},
+		// Without a Content-Length the body is cut off while it is read
+		{"/api/v1/configmaps/default", true, http.StatusRequestEntityTooLarge},
+		{"/api/v1/apply/default", false, http.StatusOK},
+		{"/api/v1/apply/default", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", tt.path, bytes.NewReader(body))
+		if tt.chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("Expected status %d for 2MiB to %s (chunked %v), got %d: %s", tt.status, tt.path, tt.chunked, w.Code, w.Body.String())
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/configmaps/default", strings.NewReader(`{"metadata": {"name": "small"}}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected a small body to pass, got %d", w.Code)
+	}
+
+	// RegisterRoutes limits every route
+	routes := gin.New()
+	RegisterRoutes(routes, fake.NewSimpleClientset(), RouterOptions{})
+	req, _ = http.NewRequest("POST", "/api/v1/pods/default", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for 2MiB to the pods route, got %d", w.Code)
+	}
+}
+
+func TestAnnotationPolicyRejectsLargeChunkedBody(t *testing.T) {
+	r := gin.New()
+	r.POST("/pods/:namespace", MaxBodySizeMiddleware(1024), AnnotationPolicyMiddleware([]string{"owner"}), NewHandler(fake.NewSimpleClientset()).CreatePod)
+
+	req, _ := http.NewRequest("POST", "/pods/default", bytes.NewReader(bytes.Repeat([]byte(" "), 2048)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	large := strings.Repeat("kgo ", 512)
+	r := gin.New()
+	r.Use(CompressMiddleware())
+	r.GET("/large", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": large}) })
+	r.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": "small"}) })
+	r.GET("/missing", func(c *gin.Context) { c.AbortWithStatus(http.StatusNotFound) })
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/large", "gzip, deflate")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("Expected a gzipped response, got %d with headers %v", w.Code, w.Header())
+	}
+	if w.Body.Len() >= len(large) {
+		t.Errorf("Expected the response to shrink, got %d bytes", w.Body.Len())
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("Failed to read gzip: %v", err)
+	}
+	var response map[string]string
+	if err := json.NewDecoder(gz).Decode(&response); err != nil || response["data"] != large {
+		t.Errorf("Expected the original JSON, got %v", err)
+	}
+
+	for _, tt := range []struct{ path, acceptEncoding string }{
+		{"/large", ""},
+		{"/large", "gzip;q=0, br"},
+		{"/small", "gzip"},
+		{"/missing", "gzip"},
+	} {
+		w := get(tt.path, tt.acceptEncoding)
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected %s with Accept-Encoding %q not to be gzipped", tt.path, tt.acceptEncoding)
+		}
+		if tt.path == "/small" && !strings.Contains(w.Body.String(), `"small"`) {
+			t.Errorf("Expected the small response as it is, got %q", w.Body.String())
+		}
+		if tt.path == "/missing" && w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	}
+}
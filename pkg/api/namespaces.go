@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/timefmt"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// ConfirmDeleteHeader is the header a client sets to the name of a namespace
+// annotated with k8s.DeletionProtectedAnnotation to delete it
+const ConfirmDeleteHeader = "X-Confirm-Delete"
+
+// NamespaceHandler struct holds the Kubernetes clientset and the dynamic
+// client used to enumerate arbitrary resource types
+type NamespaceHandler struct {
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+	guard         *k8s.NamespaceGuard
+}
+
+// NewNamespaceHandler creates a new namespace API handler. Without a dynamic
+// client the finalizer report is unavailable.
+func NewNamespaceHandler(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *NamespaceHandler {
+	return &NamespaceHandler{clientset: clientset, dynamicClient: dynamicClient}
+}
+
+// SetGuard makes deleting a namespace the guard protects need the
+// X-KGO-Confirm header, which ProtectedNamespaceMiddleware cannot check on
+// namespace routes
+func (h *NamespaceHandler) SetGuard(guard *k8s.NamespaceGuard) {
+	h.guard = guard
+}
+
+// ListNamespaces handles GET /api/v1/namespaces
+func (h *NamespaceHandler) ListNamespaces(c *gin.Context) {
+	namespaces, err := k8s.ListNamespaces(h.clientset)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	now := time.Now()
+	resp := NamespaceListResponse{Namespaces: make([]NamespaceInfo, 0, len(namespaces))}
+	for i := range namespaces {
+		resp.Namespaces = append(resp.Namespaces, NamespaceInfo{
+			Namespace:   namespaces[i],
+			AgeSeconds:  timefmt.AgeSeconds(namespaces[i].CreationTimestamp.Time, now),
+			Termination: k8s.NamespaceTerminationStatus(&namespaces[i], now),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// FinalizerReport handles GET /api/v1/namespaces/:name/finalizer-report
+func (h *NamespaceHandler) FinalizerReport(c *gin.Context) {
+	if h.dynamicClient == nil {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "finalizer reports need a dynamic client"})
+		return
+	}
+
+	report, err := k8s.NamespaceFinalizerReport(h.clientset, h.dynamicClient, c.Param("name"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DeleteNamespace handles DELETE /api/v1/namespaces/:name. A namespace with
+// k8s.DeletionProtectedAnnotation is only deleted when the X-Confirm-Delete
+// header names it, and otherwise refused with 403.
+func (h *NamespaceHandler) DeleteNamespace(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.guard.Check(name, c.GetHeader(ConfirmHeader)); err != nil {
+		klog.Warningf("Rejected %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.JSON(http.StatusPreconditionRequired, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	namespace, err := h.clientset.CoreV1().Namespaces().Get(c.Request.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get namespace %s: %v", name, err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if k8s.IsDeletionProtected(namespace) && c.GetHeader(ConfirmDeleteHeader) != name {
+		klog.Warningf("Rejected deleting deletion-protected namespace %s without confirmation", name)
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Error: fmt.Sprintf("namespace %s is deletion-protected: set the %s header to its name to delete it", name, ConfirmDeleteHeader),
+		})
+		return
+	}
+
+	if err := k8s.DeleteNamespace(h.clientset, name); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, DeleteResponse{
+		Message:           "Namespace deleted successfully",
+		KubectlEquivalent: k8s.KubectlDelete("", "namespace", name),
+	})
+}
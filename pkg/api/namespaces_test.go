@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func newNamespaceTestRouter(withDynamic bool) *gin.Engine {
+	created := metav1.NewTime(time.Now().Add(-72 * time.Hour))
+	deleted := metav1.NewTime(time.Now().Add(-3 * time.Hour))
+	stuck := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", CreationTimestamp: created, DeletionTimestamp: &deleted},
+		Status: v1.NamespaceStatus{
+			Phase: v1.NamespaceTerminating,
+			Conditions: []v1.NamespaceCondition{{
+				Type:    v1.NamespaceContentRemaining,
+				Status:  v1.ConditionTrue,
+				Message: "Some resources are remaining: pods has 1 resource instances",
+			}},
+		},
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "stuck", Finalizers: []string{"example.com/hold"}}}
+
+	clientset := fake.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, stuck)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list", "delete"}}},
+	}}
+
+	opts := RouterOptions{}
+	if withDynamic {
+		opts.DynamicClient = fakedynamic.NewSimpleDynamicClient(scheme.Scheme, pod)
+	}
+	r := gin.New()
+	RegisterRoutes(r, clientset, opts)
+	return r
+}
+
+func TestListNamespacesShowsTermination(t *testing.T) {
+	r := newNamespaceTestRouter(false)
+
+	req, _ := http.NewRequest("GET", "/api/v1/namespaces", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp NamespaceListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(resp.Namespaces) != 2 {
+		t.Fatalf("Expected 2 namespaces, got %d", len(resp.Namespaces))
+	}
+
+	for _, ns := range resp.Namespaces {
+		switch ns.Name {
+		case "default":
+			if ns.Termination != nil {
+				t.Errorf("Expected no termination for an active namespace, got %+v", ns.Termination)
+			}
+		case "stuck":
+			if ns.Status.Phase != v1.NamespaceTerminating || ns.Termination == nil {
+				t.Fatalf("Expected a terminating namespace, got %+v", ns)
+			}
+			if ns.AgeSeconds < 72*3600 || ns.CreationTimestamp.IsZero() {
+				t.Errorf("Expected the creation time and an age of at least 3d, got %v and %ds", ns.CreationTimestamp, ns.AgeSeconds)
+			}
+			if ns.Termination.Seconds < 3*3600 {
+				t.Errorf("Expected at least 3h terminating, got %ds", ns.Termination.Seconds)
+			}
+			want := k8s.NamespaceBlocker{Resource: "pods", Instances: 1}
+			if len(ns.Termination.Blockers) != 1 || ns.Termination.Blockers[0] != want {
+				t.Errorf("Expected %+v, got %+v", want, ns.Termination.Blockers)
+			}
+		}
+	}
+}
+
+func TestNamespaceFinalizerReport(t *testing.T) {
+	r := newNamespaceTestRouter(true)
+
+	req, _ := http.NewRequest("GET", "/api/v1/namespaces/stuck/finalizer-report", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report k8s.FinalizerReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(report.Kinds) != 1 || report.Kinds[0].Kind != "Pod" || len(report.Kinds[0].Objects) != 1 {
+		t.Fatalf("Expected the remaining pod, got %+v", report.Kinds)
+	}
+	if finalizers := report.Kinds[0].Objects[0].Finalizers; len(finalizers) != 1 || finalizers[0] != "example.com/hold" {
+		t.Errorf("Expected the pod's finalizer, got %v", finalizers)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/v1/namespaces/missing/finalizer-report", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing namespace, got %d", w.Code)
+	}
+}
+
+func TestNamespaceFinalizerReportWithoutDynamicClient(t *testing.T) {
+	r := newNamespaceTestRouter(false)
+
+	req, _ := http.NewRequest("GET", "/api/v1/namespaces/stuck/finalizer-report", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501, got %d", w.Code)
+	}
+}
+
+func TestDeleteNamespaceDeletionProtection(t *testing.T) {
+	protected := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "payments",
+		Annotations: map[string]string{k8s.DeletionProtectedAnnotation: "true"},
+	}}
+	clientset := fake.NewSimpleClientset(protected, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}})
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	clientset.CoreV1().Namespaces().Create(t.Context(), &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-eu"}}, metav1.CreateOptions{})
+	r := gin.New()
+	RegisterRoutes(r, clientset, RouterOptions{Guard: guard})
+
+	del := func(name string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/api/v1/namespaces/"+name, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	exists := func(name string) bool {
+		_, err := clientset.CoreV1().Namespaces().Get(t.Context(), name, metav1.GetOptions{})
+		return err == nil
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		headers   map[string]string
+		want      int
+	}{
+		{"protected without header", "payments", nil, http.StatusForbidden},
+		{"protected with wrong header", "payments", map[string]string{ConfirmDeleteHeader: "payment"}, http.StatusForbidden},
+		{"guarded without confirmation", "prod-eu", nil, http.StatusPreconditionRequired},
+		{"missing", "missing", nil, http.StatusNotFound},
+		{"protected with header", "payments", map[string]string{ConfirmDeleteHeader: "payments"}, http.StatusOK},
+		{"unprotected", "scratch", nil, http.StatusOK},
+		{"guarded with confirmation", "prod-eu", map[string]string{ConfirmHeader: "prod-eu"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := del(tt.namespace, tt.headers)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, w.Code, w.Body.String())
+		}
+		if deleted := !exists(tt.namespace); tt.namespace != "missing" && deleted != (tt.want == http.StatusOK) {
+			t.Errorf("%s: expected deleted to be %v", tt.name, tt.want == http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes"
+)
+
+// permissionsCacheTTL is how long the permissions of a namespace are served
+// before they are checked again
+const permissionsCacheTTL = 30 * time.Second
+
+// PermissionsHandler struct holds the cache of checked permissions
+type PermissionsHandler struct {
+	cache *k8s.PermissionCache
+}
+
+// NewPermissionsHandler creates a new permissions API handler
+func NewPermissionsHandler(clientset kubernetes.Interface) *PermissionsHandler {
+	return &PermissionsHandler{cache: k8s.NewPermissionCache(clientset, permissionsCacheTTL)}
+}
+
+// Permissions handles GET /api/v1/permissions?namespace=default and returns
+// which verbs the server's identity may use on each supported resource type
+// of the namespace, checked with SelfSubjectAccessReviews
+func (h *PermissionsHandler) Permissions(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+	matrix, err := h.cache.Get(c.Request.Context(), namespace)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, matrix)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPermissions(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	reviews := 0
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Namespace == "shop" && attrs.Group == "apps" && attrs.Verb != "delete"
+		return true, review, nil
+	})
+	r := gin.New()
+	r.GET("/permissions", NewPermissionsHandler(clientset).Permissions)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/permissions?namespace=shop")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var matrix k8s.PermissionMatrix
+	if err := json.Unmarshal(w.Body.Bytes(), &matrix); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if matrix.Namespace != "shop" || !matrix.Can("update", "deployments") || matrix.Can("delete", "deployments") || matrix.Can("get", "pods") {
+		t.Errorf("Expected deployments but no deletes or pods, got %+v", matrix.Allowed)
+	}
+
+	// The namespace is not checked again within the TTL
+	checked := reviews
+	if w := get("/permissions?namespace=shop"); w.Code != http.StatusOK || reviews != checked {
+		t.Errorf("Expected the cached permissions, got %d after %d reviews", w.Code, reviews-checked)
+	}
+
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	if w := get("/permissions"); w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 for the default namespace, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recentGCPauses is how many of the last garbage collection pauses
+// /debug/vars reports
+const recentGCPauses = 10
+
+// registerDebugRoutes registers the Go profiler under /debug/pprof and the
+// runtime stats at /debug/vars. They live outside /api/v1, which is the
+// documented API.
+func registerDebugRoutes(r gin.IRouter, metrics *StreamMetrics, coalescer *k8s.ListCoalescer) {
+	debug := r.Group("/debug")
+	debug.GET("/vars", DebugVars(metrics, coalescer))
+	debug.GET("/pprof/*profile", pprofHandler)
+	debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+}
+
+// pprofHandler serves the profile named by the path the way net/http/pprof
+// does on the default mux: the index, and the heap, goroutine and other
+// profiles it links to, by name
+func pprofHandler(c *gin.Context) {
+	switch c.Param("profile") {
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// DebugVars handles GET /debug/vars and reports the goroutines, heap and
+// garbage collections of the process with its open streams and cached lists
+func DebugVars(metrics *StreamMetrics, coalescer *k8s.ListCoalescer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		// PauseNs is a circular buffer, the latest pause at (NumGC+255)%256
+		pauses := make([]uint64, 0, recentGCPauses)
+		for i := uint32(0); i < mem.NumGC && i < recentGCPauses; i++ {
+			pauses = append(pauses, mem.PauseNs[(mem.NumGC-1-i)%uint32(len(mem.PauseNs))])
+		}
+
+		response := DebugVarsResponse{
+			Goroutines:       runtime.NumGoroutine(),
+			HeapAllocBytes:   mem.HeapAlloc,
+			HeapInuseBytes:   mem.HeapInuse,
+			HeapObjects:      mem.HeapObjects,
+			GCCycles:         mem.NumGC,
+			GCPauseTotalNs:   mem.PauseTotalNs,
+			RecentGCPausesNs: pauses,
+			ActiveWatches:    metrics.Connections(),
+			ActiveLogStreams: metrics.LogStreams(),
+		}
+		if coalescer != nil {
+			response.CachedObjects = coalescer.Cached()
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDebugRoutes(t *testing.T) {
+	get := func(r *gin.Engine, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	paths := []string{"/debug/vars", "/debug/pprof/", "/debug/pprof/cmdline", "/debug/pprof/goroutine?debug=1"}
+
+	disabled := gin.New()
+	RegisterRoutes(disabled, fake.NewSimpleClientset(), RouterOptions{})
+	for _, path := range paths {
+		if w := get(disabled, path); w.Code != http.StatusNotFound {
+			t.Errorf("Expected %s to be missing when disabled, got %d", path, w.Code)
+		}
+	}
+
+	enabled := gin.New()
+	coalescer := k8s.NewListCoalescer("test", time.Minute)
+	RegisterRoutes(enabled, fake.NewSimpleClientset(), RouterOptions{EnablePprof: true, Coalescer: coalescer})
+	for _, path := range paths {
+		if w := get(enabled, path); w.Code != http.StatusOK {
+			t.Errorf("Expected %s to respond when enabled, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+	if body := get(enabled, "/debug/pprof/").Body.String(); !strings.Contains(body, "goroutine") {
+		t.Errorf("Expected the profile index, got %s", body)
+	}
+
+	// The debug endpoints are not part of the versioned API
+	for _, route := range enabled.Routes() {
+		if strings.HasPrefix(route.Path, "/api/") && strings.Contains(route.Path, "debug/pprof") {
+			t.Errorf("Expected no profiler route under /api, got %s", route.Path)
+		}
+	}
+
+	get(enabled, "/api/v1/pods?namespace=default")
+	var vars DebugVarsResponse
+	if err := json.Unmarshal(get(enabled, "/debug/vars").Body.Bytes(), &vars); err != nil {
+		t.Fatalf("Failed to parse the runtime stats: %v", err)
+	}
+	if vars.Goroutines == 0 || vars.HeapAllocBytes == 0 || vars.CachedObjects != 1 || vars.ActiveWatches != 0 {
+		t.Errorf("Expected the runtime stats with the cached pod list, got %+v", vars)
+	}
+}
@@ -1,14 +1,23 @@
 package api
 
 import (
+	goerrors "errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
+	"k8s-dashboard/pkg/crypto"
 	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
 
 	"github.com/gin-gonic/gin"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
@@ -16,7 +25,16 @@ import (
 
 // ResourceHandler struct holds the Kubernetes clientset
 type ResourceHandler struct {
-	clientset kubernetes.Interface
+	clientset       kubernetes.Interface
+	coalescer       *k8s.ListCoalescer
+	imagePolicy     *k8s.ImagePolicy
+	streamMetrics   *StreamMetrics
+	guard           *k8s.NamespaceGuard
+	manifestFetcher *k8s.ManifestFetcher
+	configMapKeys   *crypto.Keys
+	// requiredAnnotations are checked by the handlers creating objects
+	// AnnotationPolicyMiddleware cannot decode
+	requiredAnnotations []string
 }
 
 // NewResourceHandler creates a new resource API handler
@@ -24,18 +42,70 @@ func NewResourceHandler(clientset kubernetes.Interface) *ResourceHandler {
 	return &ResourceHandler{clientset: clientset}
 }
 
+// SetStreamMetrics counts the pod logs being streamed in metrics
+func (h *ResourceHandler) SetStreamMetrics(metrics *StreamMetrics) {
+	h.streamMetrics = metrics
+}
+
+// SetCoalescer makes list requests share identical concurrent upstream calls
+func (h *ResourceHandler) SetCoalescer(coalescer *k8s.ListCoalescer) {
+	h.coalescer = coalescer
+}
+
+// SetImagePolicy rejects deployments and applied manifests whose images come
+// from registries the policy does not allow
+func (h *ResourceHandler) SetImagePolicy(policy *k8s.ImagePolicy) {
+	h.imagePolicy = policy
+}
+
+// SetGuard protects namespaces from requests naming their namespace in the
+// body, which ProtectedNamespaceMiddleware cannot see
+func (h *ResourceHandler) SetGuard(guard *k8s.NamespaceGuard) {
+	h.guard = guard
+}
+
+// SetRequiredAnnotations requires annotations on the configmaps created from
+// data and the objects of applied manifests, outside kube-system
+func (h *ResourceHandler) SetRequiredAnnotations(requiredAnnotations []string) {
+	h.requiredAnnotations = requiredAnnotations
+}
+
+// SetManifestFetcher enables applying manifests from the URLs the fetcher
+// allows
+func (h *ResourceHandler) SetManifestFetcher(fetcher *k8s.ManifestFetcher) {
+	h.manifestFetcher = fetcher
+}
+
+// SetConfigMapKeys enables X-Encrypt: sops on configmap creation when keys
+// can encrypt, and decrypts the values of encrypted configmaps on reads when
+// they can decrypt
+func (h *ResourceHandler) SetConfigMapKeys(keys *crypto.Keys) {
+	h.configMapKeys = keys
+}
+
+// decryptConfigMap returns a configmap with its values decrypted when it is
+// encrypted and the handler has keys to decrypt it, see k8s.DecryptConfigMap
+func (h *ResourceHandler) decryptConfigMap(configMap *v1.ConfigMap) (*v1.ConfigMap, error) {
+	if !h.configMapKeys.CanDecrypt() {
+		return configMap, nil
+	}
+	return k8s.DecryptConfigMap(configMap, h.configMapKeys.Decrypt)
+}
+
 // ListDeployments handles GET /api/v1/deployments?namespace=default
 func (h *ResourceHandler) ListDeployments(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
-	deployments, err := k8s.ListDeployments(h.clientset, namespace)
+	deployments, err := k8s.CoalescedList(listCoalescer(c, h.coalescer), "deployments", namespace, metav1.ListOptions{}, func(opts metav1.ListOptions) ([]appsv1.Deployment, error) {
+		return k8s.ListDeploymentsWithOptions(h.clientset, namespace, opts)
+	})
 	if err != nil {
 		klog.Errorf("Failed to list deployments: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"deployments": deployments})
+	c.JSON(http.StatusOK, DeploymentListResponse{Deployments: deployments})
 }
 
 // CreateDeployment handles POST /api/v1/deployments/:namespace
@@ -45,21 +115,33 @@ func (h *ResourceHandler) CreateDeployment(c *gin.Context) {
 	var deployment appsv1.Deployment
 	if err := c.ShouldBindJSON(&deployment); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
 	// Ensure namespace is set
 	deployment.Namespace = namespace
 
+	if err := validation.Deployment(&deployment); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.imagePolicy.CheckObject(&deployment); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	createdDeployment, err := k8s.CreateDeployment(h.clientset, namespace, &deployment)
 	if err != nil {
 		klog.Errorf("Failed to create deployment: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdDeployment)
+	c.JSON(http.StatusCreated, DeploymentResponse{
+		Deployment:        createdDeployment,
+		KubectlEquivalent: k8s.KubectlCreate(namespace, &deployment),
+	})
 }
 
 // UpdateDeployment handles PUT /api/v1/deployments/:namespace/:name
@@ -70,7 +152,7 @@ func (h *ResourceHandler) UpdateDeployment(c *gin.Context) {
 	var deployment appsv1.Deployment
 	if err := c.ShouldBindJSON(&deployment); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
@@ -78,43 +160,79 @@ func (h *ResourceHandler) UpdateDeployment(c *gin.Context) {
 	deployment.Name = name
 	deployment.Namespace = namespace
 
+	if err := validation.Deployment(&deployment); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := h.imagePolicy.CheckObject(&deployment); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	updatedDeployment, err := k8s.UpdateDeployment(h.clientset, namespace, &deployment)
 	if err != nil {
 		klog.Errorf("Failed to update deployment: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedDeployment)
+	c.JSON(http.StatusOK, DeploymentResponse{
+		Deployment:        updatedDeployment,
+		KubectlEquivalent: k8s.KubectlReplace(namespace),
+	})
 }
 
 // DeleteDeployment handles DELETE /api/v1/deployments/:namespace/:name
 func (h *ResourceHandler) DeleteDeployment(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
+	deleteObject(c, h.clientset, "deployments", "deployment", "Deployment deleted successfully", k8s.DeleteDeployment)
+}
 
-	err := k8s.DeleteDeployment(h.clientset, namespace, name)
-	if err != nil {
-		klog.Errorf("Failed to delete deployment: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// PauseDeployment handles POST /api/v1/deployments/:namespace/:name/pause
+func (h *ResourceHandler) PauseDeployment(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+	if err := k8s.PauseDeployment(c.Request.Context(), h.clientset, namespace, name); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Deployment deleted successfully"})
+	c.JSON(http.StatusOK, RolloutResponse{
+		Namespace:         namespace,
+		Name:              name,
+		Paused:            true,
+		KubectlEquivalent: k8s.KubectlPause(namespace, "deployment", name),
+	})
+}
+
+// ResumeDeployment handles POST /api/v1/deployments/:namespace/:name/resume
+func (h *ResourceHandler) ResumeDeployment(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+	if err := k8s.ResumeDeployment(c.Request.Context(), h.clientset, namespace, name); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RolloutResponse{
+		Namespace:         namespace,
+		Name:              name,
+		Paused:            false,
+		KubectlEquivalent: k8s.KubectlResume(namespace, "deployment", name),
+	})
 }
 
 // ListServices handles GET /api/v1/services?namespace=default
 func (h *ResourceHandler) ListServices(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
-	services, err := k8s.ListServices(h.clientset, namespace)
+	services, err := k8s.CoalescedList(listCoalescer(c, h.coalescer), "services", namespace, metav1.ListOptions{}, func(opts metav1.ListOptions) ([]v1.Service, error) {
+		return k8s.ListServicesWithOptions(h.clientset, namespace, opts)
+	})
 	if err != nil {
 		klog.Errorf("Failed to list services: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"services": services})
+	c.JSON(http.StatusOK, ServiceListResponse{Services: services})
 }
 
 // CreateService handles POST /api/v1/services/:namespace
@@ -124,21 +242,29 @@ func (h *ResourceHandler) CreateService(c *gin.Context) {
 	var service v1.Service
 	if err := c.ShouldBindJSON(&service); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
 	// Ensure namespace is set
 	service.Namespace = namespace
 
+	if err := validation.Service(&service); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	createdService, err := k8s.CreateService(h.clientset, namespace, &service)
 	if err != nil {
 		klog.Errorf("Failed to create service: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdService)
+	c.JSON(http.StatusCreated, ServiceResponse{
+		Service:           createdService,
+		KubectlEquivalent: k8s.KubectlCreate(namespace, &service),
+	})
 }
 
 // UpdateService handles PUT /api/v1/services/:namespace/:name
@@ -149,7 +275,7 @@ func (h *ResourceHandler) UpdateService(c *gin.Context) {
 	var service v1.Service
 	if err := c.ShouldBindJSON(&service); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
@@ -157,67 +283,258 @@ func (h *ResourceHandler) UpdateService(c *gin.Context) {
 	service.Name = name
 	service.Namespace = namespace
 
+	if err := validation.Service(&service); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	updatedService, err := k8s.UpdateService(h.clientset, namespace, &service)
 	if err != nil {
 		klog.Errorf("Failed to update service: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedService)
+	c.JSON(http.StatusOK, ServiceResponse{
+		Service:           updatedService,
+		KubectlEquivalent: k8s.KubectlReplace(namespace),
+	})
 }
 
 // DeleteService handles DELETE /api/v1/services/:namespace/:name
 func (h *ResourceHandler) DeleteService(c *gin.Context) {
-	namespace := c.Param("namespace")
-	name := c.Param("name")
-
-	err := k8s.DeleteService(h.clientset, namespace, name)
-	if err != nil {
-		klog.Errorf("Failed to delete service: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Service deleted successfully"})
+	deleteObject(c, h.clientset, "services", "service", "Service deleted successfully", k8s.DeleteService)
 }
 
-// ListConfigMaps handles GET /api/v1/configmaps?namespace=default
+// ListConfigMaps handles GET /api/v1/configmaps?namespace=default. With
+// ?omitData=true only metadata, key names and value sizes are returned.
+// Encrypted values are decrypted when the handler has the keys; configmaps
+// failing to decrypt are returned as stored.
 func (h *ResourceHandler) ListConfigMaps(c *gin.Context) {
 	namespace := c.DefaultQuery("namespace", "default")
 
-	configmaps, err := k8s.ListConfigMaps(h.clientset, namespace)
+	configmaps, err := k8s.CoalescedList(listCoalescer(c, h.coalescer), "configmaps", namespace, metav1.ListOptions{}, func(opts metav1.ListOptions) ([]v1.ConfigMap, error) {
+		return k8s.ListConfigMapsWithOptions(h.clientset, namespace, opts)
+	})
 	if err != nil {
 		klog.Errorf("Failed to list configmaps: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"configmaps": configmaps})
+	if c.Query("omitData") == "true" {
+		c.JSON(http.StatusOK, ConfigMapSummaryListResponse{ConfigMaps: k8s.SummarizeConfigMaps(configmaps)})
+		return
+	}
+
+	// The list may be shared with other requests by the coalescer, so
+	// decrypted configmaps go into a new one
+	decrypted := make([]v1.ConfigMap, len(configmaps))
+	for i := range configmaps {
+		configMap, err := h.decryptConfigMap(&configmaps[i])
+		if err != nil {
+			klog.Warningf("Failed to decrypt configmap %s/%s: %v", configmaps[i].Namespace, configmaps[i].Name, err)
+			configMap = &configmaps[i]
+		}
+		decrypted[i] = *configMap
+	}
+	c.JSON(http.StatusOK, ConfigMapListResponse{ConfigMaps: decrypted})
 }
 
-// CreateConfigMap handles POST /api/v1/configmaps/:namespace
+// EncryptHeader set to "sops" on POST /api/v1/configmaps/:namespace stores
+// the values of the configmap as SOPS documents
+const EncryptHeader = "X-Encrypt"
+
+// CreateConfigMap handles POST /api/v1/configmaps/:namespace. With an
+// X-Encrypt: sops header every value is stored as a SOPS document encrypted
+// with the configured keys, and the configmap is annotated with
+// k8s.EncryptedAnnotation.
 func (h *ResourceHandler) CreateConfigMap(c *gin.Context) {
 	namespace := c.Param("namespace")
 
 	var configmap v1.ConfigMap
 	if err := c.ShouldBindJSON(&configmap); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
 	// Ensure namespace is set
 	configmap.Namespace = namespace
 
+	if err := validation.ConfigMap(&configmap); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	switch encryption := c.GetHeader(EncryptHeader); encryption {
+	case "":
+	case k8s.EncryptionSOPS:
+		if h.configMapKeys == nil || h.configMapKeys.Encrypt == "" {
+			c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "configmap encryption is not configured; set crypto.backend and crypto.ageRecipients"})
+			return
+		}
+		if err := k8s.EncryptConfigMap(&configmap, h.configMapKeys.Encrypt); err != nil {
+			klog.Errorf("Failed to encrypt configmap: %v", err)
+			status := http.StatusInternalServerError
+			if goerrors.Is(err, k8s.ErrConfigMapTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			c.JSON(status, ErrorResponse{Error: err.Error()})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("unsupported %s %q; only %q is supported", EncryptHeader, encryption, k8s.EncryptionSOPS)})
+		return
+	}
+
 	createdConfigMap, err := k8s.CreateConfigMap(h.clientset, namespace, &configmap)
 	if err != nil {
 		klog.Errorf("Failed to create configmap: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
+	responseConfigMap, err := h.decryptConfigMap(createdConfigMap)
+	if err != nil {
+		klog.Warningf("Failed to decrypt configmap %s/%s: %v", namespace, createdConfigMap.Name, err)
+		responseConfigMap = createdConfigMap
+	}
+
+	c.JSON(http.StatusCreated, ConfigMapResponse{
+		ConfigMap:         responseConfigMap,
+		KubectlEquivalent: k8s.KubectlCreate(namespace, &configmap),
+	})
+}
+
+// maxConfigMapFormSize bounds the body of a configmap created from data: the
+// configmap limit, with room for multipart headers and JSON escaping
+const maxConfigMapFormSize = 2 * k8s.MaxConfigMapSize
+
+// CreateConfigMapFromData handles POST /api/v1/configmaps/:namespace/from-data,
+// the equivalent of kubectl create configmap --from-file and --from-literal.
+// A multipart/form-data body names the configmap in a "name" field, may repeat
+// a "literal" field of key=value, and every uploaded file becomes a key named
+// after the file; files that are not UTF-8 go to binaryData. Repeated
+// "annotation" fields of key=value annotate it. Any other body is a
+// ConfigMapFromDataRequest in JSON.
+func (h *ResourceHandler) CreateConfigMapFromData(c *gin.Context) {
+	namespace := c.Param("namespace")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxConfigMapFormSize)
+
+	var req ConfigMapFromDataRequest
+	var files map[string][]byte
+	var err error
+	if c.ContentType() == "multipart/form-data" {
+		req, files, err = readConfigMapForm(c)
+	} else if err = c.ShouldBindJSON(&req); err != nil {
+		err = fmt.Errorf("invalid JSON: %v", err)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		if isBodyTooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(req.Literals) == 0 && len(files) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at least one literal or file is required"})
+		return
+	}
+
+	configMap, err := k8s.NewConfigMapFromData(namespace, req.Name, req.Literals, files)
+	if err != nil {
+		status := http.StatusBadRequest
+		if goerrors.Is(err, k8s.ErrConfigMapTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, ErrorResponse{Error: err.Error()})
+		return
+	}
+	configMap.Annotations = req.Annotations
+	if err := validation.ConfigMap(configMap); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	var missing *missingAnnotationsError
+	if err := checkAnnotationPolicy(h.requiredAnnotations, namespace, configMap); goerrors.As(err, &missing) {
+		rejectMissingAnnotations(c, missing)
+		return
+	}
+
+	createdConfigMap, err := k8s.CreateConfigMap(h.clientset, namespace, configMap)
+	if err != nil {
+		klog.Errorf("Failed to create configmap: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	fileKeys := make([]string, 0, len(files))
+	for key := range files {
+		fileKeys = append(fileKeys, key)
+	}
+	c.JSON(http.StatusCreated, ConfigMapResponse{
+		ConfigMap:         createdConfigMap,
+		KubectlEquivalent: k8s.KubectlCreateConfigMapFromData(namespace, req.Name, req.Literals, fileKeys),
+	})
+}
+
+// readConfigMapForm reads the name, literals and files of a multipart
+// configmap request
+func readConfigMapForm(c *gin.Context) (ConfigMapFromDataRequest, map[string][]byte, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return ConfigMapFromDataRequest{}, nil, fmt.Errorf("invalid form: %w", err)
+	}
+
+	var req ConfigMapFromDataRequest
+	if names := form.Value["name"]; len(names) > 0 {
+		req.Name = names[0]
+	}
+	for _, literal := range form.Value["literal"] {
+		key, value, ok := strings.Cut(literal, "=")
+		if !ok {
+			return req, nil, fmt.Errorf("literal %q is not key=value", literal)
+		}
+		if _, ok := req.Literals[key]; ok {
+			return req, nil, fmt.Errorf("key %q is given more than once", key)
+		}
+		if req.Literals == nil {
+			req.Literals = make(map[string]string)
+		}
+		req.Literals[key] = value
+	}
 
-	c.JSON(http.StatusCreated, createdConfigMap)
+	for _, annotation := range form.Value["annotation"] {
+		key, value, ok := strings.Cut(annotation, "=")
+		if !ok {
+			return req, nil, fmt.Errorf("annotation %q is not key=value", annotation)
+		}
+		if req.Annotations == nil {
+			req.Annotations = make(map[string]string)
+		}
+		req.Annotations[key] = value
+	}
+
+	files := make(map[string][]byte)
+	for _, headers := range form.File {
+		for _, header := range headers {
+			if _, ok := files[header.Filename]; ok {
+				return req, nil, fmt.Errorf("key %q is given more than once", header.Filename)
+			}
+			file, err := header.Open()
+			if err != nil {
+				return req, nil, fmt.Errorf("failed to read %s: %v", header.Filename, err)
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return req, nil, fmt.Errorf("failed to read %s: %v", header.Filename, err)
+			}
+			files[header.Filename] = data
+		}
+	}
+	return req, files, nil
 }
 
 // UpdateConfigMap handles PUT /api/v1/configmaps/:namespace/:name
@@ -228,7 +545,7 @@ func (h *ResourceHandler) UpdateConfigMap(c *gin.Context) {
 	var configmap v1.ConfigMap
 	if err := c.ShouldBindJSON(&configmap); err != nil {
 		klog.Errorf("Failed to bind JSON: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
 		return
 	}
 
@@ -236,29 +553,245 @@ func (h *ResourceHandler) UpdateConfigMap(c *gin.Context) {
 	configmap.Name = name
 	configmap.Namespace = namespace
 
+	if err := validation.ConfigMap(&configmap); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	updatedConfigMap, err := k8s.UpdateConfigMap(h.clientset, namespace, &configmap)
 	if err != nil {
 		klog.Errorf("Failed to update configmap: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updatedConfigMap)
+	c.JSON(http.StatusOK, ConfigMapResponse{
+		ConfigMap:         updatedConfigMap,
+		KubectlEquivalent: k8s.KubectlReplace(namespace),
+	})
 }
 
 // DeleteConfigMap handles DELETE /api/v1/configmaps/:namespace/:name
 func (h *ResourceHandler) DeleteConfigMap(c *gin.Context) {
+	deleteObject(c, h.clientset, "configmaps", "configmap", "ConfigMap deleted successfully", k8s.DeleteConfigMap)
+}
+
+// configMapKey returns the key of a per-key configmap route. The key is a
+// wildcard so that URL-encoded slashes reach the handler intact.
+func configMapKey(c *gin.Context) (string, bool) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "key is required"})
+		return "", false
+	}
+	return key, true
+}
+
+// GetConfigMapKey handles GET /api/v1/configmaps/:namespace/:name/data/:key.
+// The value of an encrypted configmap is decrypted when the handler has the
+// keys.
+func (h *ResourceHandler) GetConfigMapKey(c *gin.Context) {
+	key, ok := configMapKey(c)
+	if !ok {
+		return
+	}
+
+	configMap, err := k8s.GetConfigMap(h.clientset, c.Param("namespace"), c.Param("name"))
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	configMap, err = h.decryptConfigMap(configMap)
+	if err != nil {
+		klog.Errorf("Failed to decrypt configmap: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	value, binary, err := k8s.ConfigMapKey(configMap, key)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	contentType := "text/plain; charset=utf-8"
+	if binary {
+		contentType = "application/octet-stream"
+	}
+	c.Data(http.StatusOK, contentType, value)
+}
+
+// SetConfigMapKey handles PUT /api/v1/configmaps/:namespace/:name/data/:key
+// with the raw value as the body. An application/octet-stream body is stored
+// in binaryData.
+func (h *ResourceHandler) SetConfigMapKey(c *gin.Context) {
+	key, ok := configMapKey(c)
+	if !ok {
+		return
+	}
+
+	value, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read body: " + err.Error()})
+		return
+	}
+
+	namespace, name := c.Param("namespace"), c.Param("name")
+	binary := c.ContentType() == "application/octet-stream"
+	updatedConfigMap, err := k8s.SetConfigMapKey(h.clientset, namespace, name, key, value, binary)
+	if err != nil {
+		klog.Errorf("Failed to set configmap key: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ConfigMapResponse{
+		ConfigMap:         updatedConfigMap,
+		KubectlEquivalent: k8s.KubectlSetConfigMapKey(namespace, name, key, value, binary),
+	})
+}
+
+// DeleteConfigMapKey handles DELETE /api/v1/configmaps/:namespace/:name/data/:key
+func (h *ResourceHandler) DeleteConfigMapKey(c *gin.Context) {
+	key, ok := configMapKey(c)
+	if !ok {
+		return
+	}
+
+	namespace, name := c.Param("namespace"), c.Param("name")
+	if err := k8s.DeleteConfigMapKey(h.clientset, namespace, name, key); err != nil {
+		klog.Errorf("Failed to delete configmap key: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteResponse{
+		Message:           "ConfigMap key deleted successfully",
+		KubectlEquivalent: k8s.KubectlDeleteConfigMapKey(namespace, name, key),
+	})
+}
+
+// Apply handles POST /api/v1/apply/:namespace with a YAML manifest as the
+// body. Like kubectl apply, an existing resource is patched.
+func (h *ResourceHandler) Apply(c *gin.Context) {
 	namespace := c.Param("namespace")
-	name := c.Param("name")
 
-	err := k8s.DeleteConfigMap(h.clientset, namespace, name)
+	manifest, err := c.GetRawData()
 	if err != nil {
-		klog.Errorf("Failed to delete configmap: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		status := http.StatusBadRequest
+		if isBodyTooLarge(err) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, ErrorResponse{Error: "Failed to read body: " + err.Error()})
+		return
+	}
+	if len(manifest) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "manifest is required"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "ConfigMap deleted successfully"})
+	if err := h.imagePolicy.CheckManifest(string(manifest)); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	// Apply may create the object, so it gets the checks of a create; a
+	// manifest that does not decode fails in ApplyYaml
+	if obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(manifest, nil, nil); err == nil {
+		var missing *missingAnnotationsError
+		if err := checkAnnotationPolicy(h.requiredAnnotations, namespace, obj); goerrors.As(err, &missing) {
+			rejectMissingAnnotations(c, missing)
+			return
+		}
+	}
+
+	if err := k8s.ApplyYaml(h.clientset, namespace, string(manifest)); err != nil {
+		klog.Errorf("Failed to apply manifest: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ApplyResponse{
+		Message:           "Manifest applied successfully",
+		KubectlEquivalent: k8s.KubectlApply(namespace),
+	})
+}
+
+// ApplyURL handles POST /api/v1/apply/url: it fetches the manifest at the
+// URL of the body from an allowed host, verifies its checksum when one is
+// given, and applies each of its documents. Documents fail on their own, so
+// the response has a result for each.
+func (h *ResourceHandler) ApplyURL(c *gin.Context) {
+	var request ApplyURLRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if request.URL == "" || request.Namespace == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "url and namespace are required"})
+		return
+	}
+	if !h.manifestFetcher.Enabled() {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "applying manifests from URLs is disabled; list hosts in features.allowedManifestHosts"})
+		return
+	}
+	if err := h.guard.Check(request.Namespace, c.GetHeader(ConfirmHeader)); err != nil {
+		c.JSON(http.StatusPreconditionRequired, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	fetched, err := h.manifestFetcher.Fetch(c.Request.Context(), request.URL, request.SHA256)
+	if err != nil {
+		klog.Errorf("Failed to fetch manifest %s: %v", request.URL, err)
+		c.JSON(manifestFetchStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	results, err := k8s.ApplyYamlDocuments(c.Request.Context(), h.clientset, request.Namespace, string(fetched.Content), k8s.ApplyOptions{
+		Check: func(obj runtime.Object) error {
+			if err := h.imagePolicy.CheckObject(obj); err != nil {
+				return err
+			}
+			return checkAnnotationPolicy(h.requiredAnnotations, request.Namespace, obj)
+		},
+	})
+	if err != nil {
+		// The manifest is not YAML; no document was applied
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error()})
+		return
+	}
+	response := ApplyURLResponse{
+		URL:               fetched.URL,
+		SHA256:            fetched.SHA256,
+		Results:           make([]ApplyDocumentResult, 0, len(results)),
+		KubectlEquivalent: k8s.KubectlApplyURL(request.Namespace, fetched.URL),
+	}
+	for _, result := range results {
+		documentResult := ApplyDocumentResult{Kind: result.Kind, Name: result.Name, Namespace: result.Namespace, Action: string(result.Action)}
+		if result.Err != nil {
+			klog.Errorf("Failed to apply %s %s from %s: %v", result.Kind, result.Name, fetched.URL, result.Err)
+			documentResult.Error = result.Err.Error()
+		}
+		response.Results = append(response.Results, documentResult)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// manifestFetchStatus returns the status of a request whose manifest could
+// not be fetched: the caller's fault for URLs and checksums, the manifest
+// server's otherwise
+func manifestFetchStatus(err error) int {
+	switch {
+	case goerrors.Is(err, k8s.ErrManifestURLNotAllowed):
+		return http.StatusForbidden
+	case goerrors.Is(err, k8s.ErrInvalidManifestChecksum):
+		return http.StatusBadRequest
+	case goerrors.Is(err, k8s.ErrManifestChecksumMismatch):
+		return http.StatusUnprocessableEntity
+	case goerrors.Is(err, k8s.ErrManifestTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case k8s.IsManifestFetchTimeout(err):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
 }
 
 // GetPodLogs handles GET /api/v1/pods/:namespace/:name/logs
@@ -272,11 +805,19 @@ func (h *ResourceHandler) GetPodLogs(c *gin.Context) {
 	logStream, err := k8s.GetPodLogs(h.clientset, namespace, name, container, follow, tailLines)
 	if err != nil {
 		klog.Errorf("Failed to get pod logs: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 	defer logStream.Close()
 
+	streamLogs(c, logStream, h.streamMetrics)
+}
+
+// streamLogs writes a log stream to the response as plain text, flushing
+// every read, until the stream ends. It is counted in metrics meanwhile.
+func streamLogs(c *gin.Context, logStream io.Reader, metrics *StreamMetrics) {
+	defer metrics.trackLogStream()()
+
 	// Set headers for streaming
 	c.Header("Content-Type", "text/plain")
 	c.Header("Cache-Control", "no-cache")
@@ -324,7 +865,7 @@ func (h *ResourceHandler) ExecPod(c *gin.Context) {
 		config, err = clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
 		if err != nil {
 			klog.Errorf("Failed to get config for exec: %v", err)
-			ws.WriteJSON(gin.H{"error": "Failed to get cluster config"})
+			ws.WriteJSON(ErrorResponse{Error: "Failed to get cluster config"})
 			return
 		}
 	}
@@ -333,10 +874,10 @@ func (h *ResourceHandler) ExecPod(c *gin.Context) {
 	err = k8s.ExecPod(h.clientset, config, namespace, name, container, cmd)
 	if err != nil {
 		klog.Errorf("Failed to exec pod: %v", err)
-		ws.WriteJSON(gin.H{"error": err.Error()})
+		ws.WriteJSON(ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// Send completion message
-	ws.WriteJSON(gin.H{"status": "completed"})
+	ws.WriteJSON(ExecResponse{Status: "completed"})
 }
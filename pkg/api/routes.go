@@ -0,0 +1,193 @@
+package api
+
+import (
+	"k8s-dashboard/pkg/crypto"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// RouterOptions holds the optional parts of the REST API
+type RouterOptions struct {
+	// Guard protects namespaces from unconfirmed mutating requests; nil
+	// protects nothing
+	Guard *k8s.NamespaceGuard
+	// Coalescer shares concurrent identical list calls; nil disables it
+	Coalescer *k8s.ListCoalescer
+	// DynamicClient lists arbitrary resource types for namespace finalizer
+	// reports and CRDs; nil disables them
+	DynamicClient       dynamic.Interface
+	EnableTokenCreation bool
+	// RequiredAnnotations must be set on every pod, deployment, service and
+	// configmap created outside kube-system; empty requires none
+	RequiredAnnotations []string
+	// ImagePolicy restricts the registries of the images of created and
+	// updated pods and deployments, and of applied manifests; nil allows all
+	ImagePolicy *k8s.ImagePolicy
+	// ManifestFetcher fetches the manifests of /apply/url from the hosts it
+	// allows; nil disables it
+	ManifestFetcher *k8s.ManifestFetcher
+	// ConfigMapKeys encrypt the values of configmaps created with an
+	// X-Encrypt: sops header and decrypt them on reads; nil rejects the
+	// header and returns values as stored
+	ConfigMapKeys *crypto.Keys
+	// MetricsClientset reads node usage from metrics-server for
+	// /metrics/nodes; nil disables it
+	MetricsClientset metricsclientset.Interface
+	// ClientInfo describes the cluster the clientset was configured for, as
+	// reported by /cluster/info; nil reports only what the server says
+	ClientInfo *k8s.ClientInfo
+	// Credentials reloads the credentials of clientset when they change;
+	// /cluster/info then reports its ClientInfo instead of ClientInfo
+	Credentials *k8s.CredentialReloader
+	// EnablePprof serves the Go profiler under /debug/pprof and runtime
+	// stats at /debug/vars
+	EnablePprof bool
+}
+
+// RegisterRoutes registers every /api/v1 endpoint on r, and the /debug
+// endpoints when opts.EnablePprof is set
+func RegisterRoutes(r gin.IRouter, clientset kubernetes.Interface, opts RouterOptions) {
+	handler := NewHandler(clientset)
+	resourceHandler := NewResourceHandler(clientset)
+	if opts.Coalescer != nil {
+		handler.SetCoalescer(opts.Coalescer)
+		resourceHandler.SetCoalescer(opts.Coalescer)
+	}
+	handler.SetImagePolicy(opts.ImagePolicy)
+	resourceHandler.SetImagePolicy(opts.ImagePolicy)
+	resourceHandler.SetGuard(opts.Guard)
+	resourceHandler.SetManifestFetcher(opts.ManifestFetcher)
+	resourceHandler.SetConfigMapKeys(opts.ConfigMapKeys)
+	metricsHandler := NewMetricsHandler(clientset)
+	metricsHandler.SetMetricsClientset(opts.MetricsClientset)
+	searchHandler := NewSearchHandler(clientset)
+	diffHandler := NewDiffHandler(clientset)
+	eventStreamHandler := NewEventStreamHandler(clientset)
+	streamMetrics := NewStreamMetrics()
+	handler.SetStreamMetrics(streamMetrics)
+	eventStreamHandler.SetStreamMetrics(streamMetrics)
+	resourceHandler.SetStreamMetrics(streamMetrics)
+	serviceAccountHandler := NewServiceAccountHandler(clientset, opts.EnableTokenCreation)
+	namespaceHandler := NewNamespaceHandler(clientset, opts.DynamicClient)
+	namespaceHandler.SetGuard(opts.Guard)
+	crdHandler := NewCRDHandler(opts.DynamicClient)
+	clusterHandler := NewClusterHandler(clientset, opts.ClientInfo)
+	if opts.Credentials != nil {
+		clusterHandler.SetCredentials(opts.Credentials)
+	}
+	permissionsHandler := NewPermissionsHandler(clientset)
+	annotationPolicy := AnnotationPolicyMiddleware(opts.RequiredAnnotations)
+	resourceHandler.SetRequiredAnnotations(opts.RequiredAnnotations)
+	bulkHandler := NewBulkOperationHandler(clientset, opts.Guard, opts.RequiredAnnotations)
+	bulkHandler.SetImagePolicy(opts.ImagePolicy)
+	chaosHandler := NewChaosHandler(clientset)
+
+	v1 := r.Group("/api/v1")
+	v1.Use(ProtectedNamespaceMiddleware(opts.Guard), maxBodySizeByRoute(DefaultMaxBodySize, map[string]int64{
+		"/api/v1/apply/:namespace":                ApplyMaxBodySize,
+		"/api/v1/configmaps/:namespace/from-data": maxConfigMapFormSize,
+	}))
+	{
+		// Pod operations
+		v1.GET("/pods", handler.ListPods)
+		v1.POST("/pods/:namespace", annotationPolicy, handler.CreatePod)
+		v1.GET("/pods/:namespace/:name", handler.GetPod)
+		v1.PUT("/pods/:namespace/:name", handler.UpdatePod)
+		v1.DELETE("/pods/:namespace/:name", handler.DeletePod)
+		v1.GET("/pods/watch", handler.WatchPods)
+		v1.GET("/pods/summary", handler.PodSummaries)
+		v1.GET("/pods/:namespace/:name/logs", resourceHandler.GetPodLogs)
+		v1.GET("/pods/:namespace/:name/exec", ConfirmedNamespaceMiddleware(opts.Guard), resourceHandler.ExecPod)
+		v1.POST("/pods/:namespace/:name/debug", handler.DebugPod)
+		v1.GET("/pods/:namespace/:name/debug/:container/logs", handler.GetDebugContainerLogs)
+		v1.GET("/pods/:namespace/:name/network", handler.PodNetwork)
+
+		// Deployment operations
+		v1.GET("/deployments", resourceHandler.ListDeployments)
+		v1.POST("/deployments/:namespace", annotationPolicy, resourceHandler.CreateDeployment)
+		v1.PUT("/deployments/:namespace/:name", resourceHandler.UpdateDeployment)
+		v1.DELETE("/deployments/:namespace/:name", resourceHandler.DeleteDeployment)
+		v1.GET("/deployments/:namespace/:name/diff", diffHandler.DeploymentTemplateDiff)
+		v1.POST("/deployments/:namespace/:name/pause", resourceHandler.PauseDeployment)
+		v1.POST("/deployments/:namespace/:name/resume", resourceHandler.ResumeDeployment)
+		v1.POST("/deployments/:namespace/:name/chaos/probe-failure", chaosHandler.ProbeFailure)
+
+		// Service operations
+		v1.GET("/services", resourceHandler.ListServices)
+		v1.POST("/services/:namespace", annotationPolicy, resourceHandler.CreateService)
+		v1.PUT("/services/:namespace/:name", resourceHandler.UpdateService)
+		v1.DELETE("/services/:namespace/:name", resourceHandler.DeleteService)
+
+		// ConfigMap operations
+		v1.GET("/configmaps", resourceHandler.ListConfigMaps)
+		v1.POST("/configmaps/:namespace", annotationPolicy, resourceHandler.CreateConfigMap)
+		v1.POST("/configmaps/:namespace/from-data", resourceHandler.CreateConfigMapFromData)
+		v1.PUT("/configmaps/:namespace/:name", resourceHandler.UpdateConfigMap)
+		v1.DELETE("/configmaps/:namespace/:name", resourceHandler.DeleteConfigMap)
+		v1.GET("/configmaps/:namespace/:name/data/*key", resourceHandler.GetConfigMapKey)
+		v1.PUT("/configmaps/:namespace/:name/data/*key", resourceHandler.SetConfigMapKey)
+		v1.DELETE("/configmaps/:namespace/:name/data/*key", resourceHandler.DeleteConfigMapKey)
+
+		// ServiceAccount operations
+		v1.POST("/serviceaccounts/:namespace/:name/token", serviceAccountHandler.CreateToken)
+
+		// Namespace operations
+		v1.GET("/namespaces", namespaceHandler.ListNamespaces)
+		v1.DELETE("/namespaces/:name", namespaceHandler.DeleteNamespace)
+		v1.GET("/namespaces/:name/finalizer-report", namespaceHandler.FinalizerReport)
+
+		// CustomResourceDefinition operations
+		v1.GET("/crds", crdHandler.ListCRDs)
+
+		// Label and annotation operations, for any kind k8s.PatchMetadata supports
+		v1.PATCH("/:kind/:namespace/:name/labels", resourceHandler.PatchLabels)
+		v1.PATCH("/:kind/:namespace/:name/annotations", resourceHandler.PatchAnnotations)
+
+		// Workload operations, for any kind k8s.ScaleWorkload and
+		// k8s.RestartWorkload support
+		v1.PUT("/:kind/:namespace/:name/scale", resourceHandler.ScaleWorkload)
+		v1.POST("/:kind/:namespace/:name/restart", resourceHandler.RestartWorkload)
+
+		// Apply operations
+		v1.POST("/apply/url", resourceHandler.ApplyURL)
+		v1.POST("/apply/:namespace", resourceHandler.Apply)
+
+		// Bulk operations
+		v1.POST("/bulk", bulkHandler.Bulk)
+
+		// Cluster operations
+		v1.GET("/cluster/info", clusterHandler.Info)
+		v1.GET("/version", Version)
+		v1.GET("/permissions", permissionsHandler.Permissions)
+
+		// Metrics operations
+		v1.GET("/metrics/cluster", metricsHandler.GetClusterMetrics)
+		v1.GET("/metrics/namespace/:namespace", metricsHandler.GetNamespaceMetrics)
+		v1.GET("/metrics/namespaces", metricsHandler.GetAllNamespaceMetrics)
+		v1.GET("/metrics/nodes", metricsHandler.GetNodeMetrics)
+		v1.GET("/metrics/coalescing", CoalescingMetrics(opts.Coalescer))
+		v1.GET("/metrics/streams", StreamMetricsHandler(streamMetrics))
+		v1.GET("/metrics/credentials", CredentialMetrics(opts.Credentials))
+		v1.GET("/overview", metricsHandler.GetOverview)
+
+		// Selector operations
+		v1.GET("/selectors/preview", resourceHandler.PreviewSelector)
+
+		// Search operations
+		v1.GET("/search", searchHandler.Search)
+
+		// Diff operations
+		v1.GET("/diff", diffHandler.Diff)
+
+		// Event stream operations
+		v1.GET("/events/stream", eventStreamHandler.StreamEvents)
+	}
+
+	if opts.EnablePprof {
+		registerDebugRoutes(r, streamMetrics, opts.Coalescer)
+	}
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Match scores, highest first: a name hit is more relevant than a label hit,
+// which is more relevant than an annotation hit
+const (
+	scoreName       = 3
+	scoreLabel      = 2
+	scoreAnnotation = 1
+)
+
+// searchLister lists the objects of one resource type in a namespace
+type searchLister func(clientset kubernetes.Interface, namespace string) ([]metav1.Object, error)
+
+// searchType pairs the singular type reported in results with its lister
+type searchType struct {
+	kind string
+	list searchLister
+}
+
+// searchTypes maps the plural type names accepted by the search endpoint to
+// their result type and lister
+var searchTypes = map[string]searchType{
+	"pods": {"pod", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListPods(cs, ns)
+		return toObjects(items, err)
+	}},
+	"deployments": {"deployment", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListDeployments(cs, ns)
+		return toObjects(items, err)
+	}},
+	"services": {"service", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListServices(cs, ns)
+		return toObjects(items, err)
+	}},
+	"configmaps": {"configmap", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListConfigMaps(cs, ns)
+		return toObjects(items, err)
+	}},
+	"statefulsets": {"statefulset", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListStatefulSets(cs, ns)
+		return toObjects(items, err)
+	}},
+	"daemonsets": {"daemonset", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListDaemonSets(cs, ns)
+		return toObjects(items, err)
+	}},
+	"jobs": {"job", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListJobs(cs, ns)
+		return toObjects(items, err)
+	}},
+	"cronjobs": {"cronjob", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListCronJobs(cs, ns)
+		return toObjects(items, err)
+	}},
+	"ingresses": {"ingress", func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		items, err := k8s.ListIngresses(cs, ns)
+		return toObjects(items, err)
+	}},
+}
+
+// defaultSearchTypes are searched when the request does not specify types
+var defaultSearchTypes = []string{"pods", "deployments", "services", "configmaps"}
+
+// toObjects converts a typed list into its object metadata
+func toObjects[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T, err error) ([]metav1.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]metav1.Object, 0, len(items))
+	for i := range items {
+		objects = append(objects, PT(&items[i]))
+	}
+	return objects, nil
+}
+
+// SearchHandler struct holds the Kubernetes clientset
+type SearchHandler struct {
+	clientset kubernetes.Interface
+}
+
+// NewSearchHandler creates a new search API handler
+func NewSearchHandler(clientset kubernetes.Interface) *SearchHandler {
+	return &SearchHandler{clientset: clientset}
+}
+
+// Search handles GET /api/v1/search?q=nginx&namespaces=default,staging&types=pods,deployments
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := strings.ToLower(strings.TrimSpace(c.Query("q")))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "query parameter q is required"})
+		return
+	}
+
+	namespaces := splitQueryList(c.DefaultQuery("namespaces", "default"))
+	types := splitQueryList(c.Query("types"))
+	if len(types) == 0 {
+		types = defaultSearchTypes
+	}
+	for _, t := range types {
+		if _, ok := searchTypes[t]; !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "unsupported resource type: " + t})
+			return
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		results = []SearchResult{}
+		g       errgroup.Group
+	)
+	for _, namespace := range namespaces {
+		for _, t := range types {
+			st := searchTypes[t]
+			g.Go(func() error {
+				objects, err := st.list(h.clientset, namespace)
+				if err != nil {
+					return err
+				}
+				matches := matchObjects(st.kind, objects, query)
+				mu.Lock()
+				results = append(results, matches...)
+				mu.Unlock()
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		klog.Errorf("Failed to search resources: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	c.JSON(http.StatusOK, SearchResponse{Results: results})
+}
+
+// matchObjects returns a result for every object whose name, labels or
+// annotations contain the lowercase query, scored by its best matching field
+func matchObjects(kind string, objects []metav1.Object, query string) []SearchResult {
+	var results []SearchResult
+	for _, obj := range objects {
+		field, score := "", 0
+		switch {
+		case strings.Contains(strings.ToLower(obj.GetName()), query):
+			field, score = "name", scoreName
+		case mapContains(obj.GetLabels(), query):
+			field, score = "labels", scoreLabel
+		case mapContains(obj.GetAnnotations(), query):
+			field, score = "annotations", scoreAnnotation
+		default:
+			continue
+		}
+		results = append(results, SearchResult{
+			Type:       kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			MatchField: field,
+			Score:      score,
+		})
+	}
+	return results
+}
+
+// mapContains reports whether any key or value contains the lowercase query
+func mapContains(m map[string]string, query string) bool {
+	for k, v := range m {
+		if strings.Contains(strings.ToLower(k), query) || strings.Contains(strings.ToLower(v), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitQueryList splits a comma-separated query parameter, dropping blanks
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newSearchRouter(clientset *fake.Clientset) *gin.Engine {
+	handler := NewSearchHandler(clientset)
+	r := gin.New()
+	r.GET("/search", handler.Search)
+	return r
+}
+
+func TestSearchResultStructureAndScoring(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-abc", Namespace: "default"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Annotations: map[string]string{"image": "NGINX:1.25"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "staging", Labels: map[string]string{"app": "nginx"}}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-other", Namespace: "prod"}},
+	)
+	r := newSearchRouter(clientset)
+
+	req, _ := http.NewRequest("GET", "/search?q=Nginx&namespaces=default,staging&types=pods,deployments", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Results []SearchResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	expected := []SearchResult{
+		{Type: "pod", Namespace: "default", Name: "nginx-abc", MatchField: "name", Score: scoreName},
+		{Type: "deployment", Namespace: "staging", Name: "frontend", MatchField: "labels", Score: scoreLabel},
+		{Type: "pod", Namespace: "default", Name: "web-1", MatchField: "annotations", Score: scoreAnnotation},
+	}
+	if len(response.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d: %+v", len(expected), len(response.Results), response.Results)
+	}
+	for i, want := range expected {
+		if response.Results[i] != want {
+			t.Errorf("Result %d: expected %+v, got %+v", i, want, response.Results[i])
+		}
+	}
+}
+
+func TestSearchValidation(t *testing.T) {
+	r := newSearchRouter(fake.NewSimpleClientset())
+
+	for _, url := range []string{"/search", "/search?q=x&types=widgets"} {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, w.Code)
+		}
+	}
+}
+
+func TestSearchListsInParallel(t *testing.T) {
+	// The fake clientset serializes reactors, so swap in listers that block
+	// until all four (2 namespaces x 2 types) are in flight; a sequential
+	// implementation would time out
+	const expectedCalls = 4
+	var inFlight int32
+	release := make(chan struct{})
+	blocking := func(cs kubernetes.Interface, ns string) ([]metav1.Object, error) {
+		if atomic.AddInt32(&inFlight, 1) == expectedCalls {
+			close(release)
+		}
+		select {
+		case <-release:
+			return []metav1.Object{&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "x-" + ns, Namespace: ns}}}, nil
+		case <-time.After(2 * time.Second):
+			return nil, fmt.Errorf("list in %s was not run concurrently", ns)
+		}
+	}
+
+	saved := searchTypes
+	searchTypes = map[string]searchType{
+		"pods":     {"pod", blocking},
+		"services": {"service", blocking},
+	}
+	defer func() { searchTypes = saved }()
+
+	r := newSearchRouter(fake.NewSimpleClientset())
+	req, _ := http.NewRequest("GET", "/search?q=x&namespaces=a,b&types=pods,services", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Results []SearchResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Results) != expectedCalls {
+		t.Errorf("Expected %d merged results, got %d", expectedCalls, len(response.Results))
+	}
+}
+
+func TestSearchListError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("boom")
+	})
+
+	r := newSearchRouter(clientset)
+	req, _ := http.NewRequest("GET", "/search?q=x&types=pods,services", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// PreviewSelector handles GET /api/v1/selectors/preview?namespace=default&selector=app%3Dweb:
+// the pods and deployments a label selector would match, from the same
+// lists as /pods and /deployments. An empty selector is reported as
+// matching everything.
+func (h *ResourceHandler) PreviewSelector(c *gin.Context) {
+	namespace := c.DefaultQuery("namespace", "default")
+
+	selector, err := k8s.ParseSelector(c.Query("selector"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	coalescer := listCoalescer(c, h.coalescer)
+	pods, err := k8s.CoalescedList(coalescer, "pods", namespace, metav1.ListOptions{}, func(opts metav1.ListOptions) ([]v1.Pod, error) {
+		return k8s.ListPodsWithOptions(h.clientset, namespace, opts)
+	})
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	deployments, err := k8s.CoalescedList(coalescer, "deployments", namespace, metav1.ListOptions{}, func(opts metav1.ListOptions) ([]appsv1.Deployment, error) {
+		return k8s.ListDeploymentsWithOptions(h.clientset, namespace, opts)
+	})
+	if err != nil {
+		klog.Errorf("Failed to list deployments: %v", err)
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, k8s.PreviewSelector(selector, pods, deployments))
+}
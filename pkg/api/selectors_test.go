@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPreviewSelector(t *testing.T) {
+	web := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	web.Spec.Template.Labels = map[string]string{"app": "web", "tier": "frontend"}
+	clientset := fake.NewSimpleClientset(
+		web,
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web", "tier": "frontend"}}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default", Labels: map[string]string{"app": "db", "tier": "backend"}}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "staging", Labels: map[string]string{"app": "web"}}},
+	)
+	r := gin.New()
+	r.GET("/selectors/preview", NewResourceHandler(clientset).PreviewSelector)
+
+	preview := func(selector string) (int, k8s.SelectorPreview, string) {
+		req, _ := http.NewRequest("GET", "/selectors/preview?namespace=default&selector="+url.QueryEscape(selector), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var response k8s.SelectorPreview
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response, w.Body.String()
+	}
+
+	code, response, body := preview("tier in (frontend, edge)")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", code, body)
+	}
+	if !reflect.DeepEqual(response.Pods, []string{"web-1"}) || !reflect.DeepEqual(response.Deployments, []string{"web"}) || response.MatchesEverything {
+		t.Errorf("Expected web-1 and the web deployment, got %+v", response)
+	}
+
+	if _, response, _ := preview(""); !response.MatchesEverything || len(response.Pods) != 2 {
+		t.Errorf("Expected an empty selector to match everything in the namespace, got %+v", response)
+	}
+
+	code, _, body = preview("app in web")
+	if code != http.StatusBadRequest || !strings.Contains(body, `invalid selector \"app in web\"`) {
+		t.Errorf("Expected status 400 naming the selector, got %d: %s", code, body)
+	}
+}
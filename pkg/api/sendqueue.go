@@ -0,0 +1,381 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// streamQueueSize is how many messages a streaming connection may fall
+	// behind before it is closed as too slow
+	streamQueueSize = 256
+	// streamWriteTimeout bounds writing one message to a streaming client
+	streamWriteTimeout = 10 * time.Second
+)
+
+// slowClientReason is sent to clients whose send queue overflowed. They
+// missed events, so they must list again before watching.
+const slowClientReason = "too slow, resync required"
+
+// errSlowClient is the error of a send queue closed because it overflowed
+var errSlowClient = errors.New(slowClientReason)
+
+// StreamMetrics counts the messages of streaming connections: WebSocket
+// watches and Server-Sent Event streams. A nil StreamMetrics counts nothing.
+type StreamMetrics struct {
+	connections   atomic.Int64
+	queued        atomic.Int64
+	maxQueued     atomic.Int64
+	sent          atomic.Int64
+	dropped       atomic.Int64
+	slowClosed    atomic.Int64
+	writeFailures atomic.Int64
+	// logStreams counts the pod log responses being streamed
+	logStreams atomic.Int64
+}
+
+// NewStreamMetrics creates empty stream metrics
+func NewStreamMetrics() *StreamMetrics {
+	return &StreamMetrics{}
+}
+
+// Snapshot returns the current values of the metrics
+func (m *StreamMetrics) Snapshot() StreamMetricsResponse {
+	if m == nil {
+		return StreamMetricsResponse{}
+	}
+	return StreamMetricsResponse{
+		Connections:       m.connections.Load(),
+		QueuedMessages:    m.queued.Load(),
+		MaxQueueDepth:     m.maxQueued.Load(),
+		SentMessages:      m.sent.Load(),
+		DroppedMessages:   m.dropped.Load(),
+		SlowClientsClosed: m.slowClosed.Load(),
+		WriteFailures:     m.writeFailures.Load(),
+	}
+}
+
+// Connections returns how many watches and event streams are open
+func (m *StreamMetrics) Connections() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.connections.Load()
+}
+
+// LogStreams returns how many pod logs are being streamed
+func (m *StreamMetrics) LogStreams() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.logStreams.Load()
+}
+
+// trackLogStream counts a log stream until the returned function is called
+func (m *StreamMetrics) trackLogStream() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.logStreams.Add(1)
+	return func() { m.logStreams.Add(-1) }
+}
+
+// observeDepth records that a connection's queue holds depth messages
+func (m *StreamMetrics) observeDepth(depth int) {
+	if m == nil {
+		return
+	}
+	for {
+		max := m.maxQueued.Load()
+		if int64(depth) <= max || m.maxQueued.CompareAndSwap(max, int64(depth)) {
+			return
+		}
+	}
+}
+
+// addQueued adds n to the messages waiting on every connection's queue
+func (m *StreamMetrics) addQueued(n int) {
+	if m != nil {
+		m.queued.Add(int64(n))
+	}
+}
+
+// StreamMetricsHandler handles GET /api/v1/metrics/streams and reports the
+// queue depth of streaming connections and how many slow clients were dropped
+func StreamMetricsHandler(metrics *StreamMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, metrics.Snapshot())
+	}
+}
+
+// streamConn is the client end of a streaming connection
+type streamConn interface {
+	// WriteMessage writes one message, failing once deadline passes
+	WriteMessage(msg interface{}, deadline time.Time) error
+	// CloseSlow tells the client it fell behind and must resync
+	CloseSlow(deadline time.Time) error
+}
+
+// sendQueue decouples producing a connection's messages from writing them:
+// messages are queued without blocking and a writer goroutine drains them
+// with write deadlines, so a slow client never backs up its watch. A client
+// that falls more than the queue's size behind is closed with
+// slowClientReason.
+type sendQueue struct {
+	conn    streamConn
+	timeout time.Duration
+	metrics *StreamMetrics
+
+	messages chan interface{}
+	// overflow is closed when a message did not fit, stop when the
+	// producer gave up and drain when it has nothing more to send
+	overflow  chan struct{}
+	stop      chan struct{}
+	stopOnce  sync.Once
+	drain     chan struct{}
+	drainOnce sync.Once
+	// done is closed when the writer returned, with err set
+	done chan struct{}
+	err  error
+
+	// mu guards overflowed and closed, so that nothing is queued once the
+	// writer returned
+	mu         sync.Mutex
+	overflowed bool
+	closed     bool
+}
+
+// newSendQueue starts the writer of a queue of size messages to conn
+func newSendQueue(conn streamConn, size int, timeout time.Duration, metrics *StreamMetrics) *sendQueue {
+	q := &sendQueue{
+		conn:     conn,
+		timeout:  timeout,
+		metrics:  metrics,
+		messages: make(chan interface{}, size),
+		overflow: make(chan struct{}),
+		stop:     make(chan struct{}),
+		drain:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if metrics != nil {
+		metrics.connections.Add(1)
+	}
+	go q.write()
+	return q
+}
+
+// Enqueue queues a message without blocking. It returns false once the
+// queue overflowed or its writer stopped; the connection is then closing and
+// the caller should stop producing.
+func (q *sendQueue) Enqueue(msg interface{}) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || q.overflowed {
+		return false
+	}
+	// Counted before the writer can take it off the queue
+	q.metrics.addQueued(1)
+	select {
+	case q.messages <- msg:
+		q.metrics.observeDepth(len(q.messages))
+		return true
+	default:
+		q.metrics.addQueued(-1)
+		q.overflowed = true
+		close(q.overflow)
+		return false
+	}
+}
+
+// Done is closed once the writer stopped, because the queue overflowed, a
+// write failed or Close was called
+func (q *sendQueue) Done() <-chan struct{} {
+	return q.done
+}
+
+// Err returns why the writer stopped: errSlowClient, a write error or nil
+func (q *sendQueue) Err() error {
+	<-q.done
+	return q.err
+}
+
+// Depth returns how many messages are waiting to be written
+func (q *sendQueue) Depth() int {
+	return len(q.messages)
+}
+
+// Close stops the writer without writing the messages still queued, and
+// waits for it to return
+func (q *sendQueue) Close() {
+	q.stopOnce.Do(func() { close(q.stop) })
+	<-q.done
+}
+
+// Drain writes the messages still queued, then stops the writer and waits
+// for it to return. Nothing may be enqueued after it.
+func (q *sendQueue) Drain() {
+	q.drainOnce.Do(func() { close(q.drain) })
+	<-q.done
+}
+
+// write writes queued messages until the queue overflows, a write fails, or
+// it is closed or drained
+func (q *sendQueue) write() {
+	defer func() {
+		q.mu.Lock()
+		q.closed = true
+		// Messages left behind are dropped
+		dropped := len(q.messages)
+		for len(q.messages) > 0 {
+			<-q.messages
+		}
+		q.mu.Unlock()
+		if q.metrics != nil {
+			q.metrics.addQueued(-dropped)
+			q.metrics.dropped.Add(int64(dropped))
+			q.metrics.connections.Add(-1)
+		}
+		close(q.done)
+	}()
+
+	for {
+		// Overflow and stop win over queued messages: a slow client is
+		// closed at once rather than after the backlog it cannot keep up with
+		select {
+		case <-q.overflow:
+			q.closeSlow()
+			return
+		case <-q.stop:
+			return
+		default:
+		}
+
+		select {
+		case <-q.overflow:
+			q.closeSlow()
+			return
+		case <-q.stop:
+			return
+		case msg := <-q.messages:
+			if !q.writeOne(msg) {
+				return
+			}
+		case <-q.drain:
+			for {
+				select {
+				case msg := <-q.messages:
+					if !q.writeOne(msg) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeOne writes a message taken off the queue, and reports whether the
+// writer may go on
+func (q *sendQueue) writeOne(msg interface{}) bool {
+	q.metrics.addQueued(-1)
+	if err := q.conn.WriteMessage(msg, time.Now().Add(q.timeout)); err != nil {
+		klog.Errorf("Failed to write to streaming client: %v", err)
+		q.err = err
+		if q.metrics != nil {
+			q.metrics.writeFailures.Add(1)
+			q.metrics.dropped.Add(1)
+		}
+		return false
+	}
+	if q.metrics != nil {
+		q.metrics.sent.Add(1)
+	}
+	return true
+}
+
+// closeSlow sends the slow client message on an overflowed queue
+func (q *sendQueue) closeSlow() {
+	klog.Warningf("Closing streaming client that fell %d messages behind", cap(q.messages))
+	q.err = errSlowClient
+	if q.metrics != nil {
+		q.metrics.slowClosed.Add(1)
+		// The message that did not fit
+		q.metrics.dropped.Add(1)
+	}
+	if err := q.conn.CloseSlow(time.Now().Add(q.timeout)); err != nil {
+		klog.Errorf("Failed to close slow streaming client: %v", err)
+	}
+}
+
+// wsConn writes a WebSocket connection's messages as JSON
+type wsConn struct {
+	ws *websocket.Conn
+}
+
+func (c wsConn) WriteMessage(msg interface{}, deadline time.Time) error {
+	if err := c.ws.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	return c.ws.WriteJSON(msg)
+}
+
+func (c wsConn) CloseSlow(deadline time.Time) error {
+	return c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, slowClientReason), deadline)
+}
+
+// sseConn writes the messages of a Server-Sent Events response as JSON data
+// lines
+type sseConn struct {
+	writer     gin.ResponseWriter
+	controller *http.ResponseController
+}
+
+func newSSEConn(writer gin.ResponseWriter) sseConn {
+	return sseConn{writer: writer, controller: http.NewResponseController(writer)}
+}
+
+// setDeadline sets the write deadline where the server supports one
+func (c sseConn) setDeadline(deadline time.Time) error {
+	if err := c.controller.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (c sseConn) WriteMessage(msg interface{}, deadline time.Time) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := c.setDeadline(deadline); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.writer, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return nil
+}
+
+// CloseSlow sends a resync event; the handler ends the response after it
+func (c sseConn) CloseSlow(deadline time.Time) error {
+	data, _ := json.Marshal(ErrorResponse{Error: slowClientReason})
+	if err := c.setDeadline(deadline); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.writer, "event: resync\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return nil
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// stalledConn is a streaming client that does not read until released: its
+// writes block until then, or until their deadline passes
+type stalledConn struct {
+	writing chan interface{}
+	release chan struct{}
+
+	mu       sync.Mutex
+	written  []interface{}
+	closedAs int
+}
+
+func newStalledConn() *stalledConn {
+	return &stalledConn{writing: make(chan interface{}, 100), release: make(chan struct{})}
+}
+
+func (c *stalledConn) WriteMessage(msg interface{}, deadline time.Time) error {
+	c.writing <- msg
+	select {
+	case <-c.release:
+	case <-time.After(time.Until(deadline)):
+		return errors.New("i/o timeout")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, msg)
+	return nil
+}
+
+func (c *stalledConn) CloseSlow(deadline time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closedAs++
+	return nil
+}
+
+func (c *stalledConn) state() ([]interface{}, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]interface{}(nil), c.written...), c.closedAs
+}
+
+func TestSendQueueClosesSlowClient(t *testing.T) {
+	conn := newStalledConn()
+	metrics := NewStreamMetrics()
+	queue := newSendQueue(conn, 2, time.Minute, metrics)
+
+	// The writer takes the first message and stalls writing it, so two more
+	// fill the queue and the fourth overflows it
+	if !queue.Enqueue(1) {
+		t.Fatal("Expected the first message to be queued")
+	}
+	<-conn.writing
+	if !queue.Enqueue(2) || !queue.Enqueue(3) {
+		t.Fatal("Expected the queue to hold two messages")
+	}
+	if got := metrics.Snapshot(); got.Connections != 1 || got.QueuedMessages != 2 || got.MaxQueueDepth != 2 {
+		t.Errorf("Expected one connection with two queued messages, got %+v", got)
+	}
+	if queue.Enqueue(4) {
+		t.Fatal("Expected the fourth message to overflow the queue")
+	}
+	if queue.Enqueue(5) {
+		t.Error("Expected nothing to be queued after an overflow")
+	}
+
+	// The stalled write finishes, and the client is closed instead of being
+	// sent its backlog
+	close(conn.release)
+	if err := queue.Err(); !errors.Is(err, errSlowClient) {
+		t.Errorf("Expected errSlowClient, got %v", err)
+	}
+	written, closed := conn.state()
+	if len(written) != 1 || closed != 1 {
+		t.Errorf("Expected one message written and the client closed once, got %v, %d", written, closed)
+	}
+
+	want := StreamMetricsResponse{MaxQueueDepth: 2, SentMessages: 1, DroppedMessages: 3, SlowClientsClosed: 1}
+	if got := metrics.Snapshot(); got != want {
+		t.Errorf("Expected metrics %+v, got %+v", want, got)
+	}
+	queue.Close()
+}
+
+func TestSendQueueWriteDeadline(t *testing.T) {
+	conn := newStalledConn()
+	metrics := NewStreamMetrics()
+	queue := newSendQueue(conn, 4, 20*time.Millisecond, metrics)
+
+	queue.Enqueue("event")
+	select {
+	case <-queue.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the write deadline to stop the writer")
+	}
+	if err := queue.Err(); err == nil || errors.Is(err, errSlowClient) {
+		t.Errorf("Expected the write error, got %v", err)
+	}
+	if queue.Enqueue("later") {
+		t.Error("Expected nothing to be queued after the writer stopped")
+	}
+	if got := metrics.Snapshot(); got.WriteFailures != 1 || got.DroppedMessages != 1 || got.Connections != 0 || got.QueuedMessages != 0 {
+		t.Errorf("Expected one failed write and no open connections, got %+v", got)
+	}
+}
+
+func TestSendQueueDrain(t *testing.T) {
+	conn := newStalledConn()
+	close(conn.release)
+	metrics := NewStreamMetrics()
+	queue := newSendQueue(conn, 8, time.Minute, metrics)
+
+	for i := 0; i < 5; i++ {
+		if !queue.Enqueue(i) {
+			t.Fatalf("Expected message %d to be queued", i)
+		}
+	}
+	queue.Drain()
+	if err := queue.Err(); err != nil {
+		t.Errorf("Expected no error after draining, got %v", err)
+	}
+	written, closed := conn.state()
+	if len(written) != 5 || written[4] != 4 || closed != 0 {
+		t.Errorf("Expected every message written in order, got %v, closed %d", written, closed)
+	}
+	if got := metrics.Snapshot(); got.SentMessages != 5 || got.DroppedMessages != 0 || got.QueuedMessages != 0 {
+		t.Errorf("Expected five sent messages, got %+v", got)
+	}
+}
+
+func TestSendQueueCloseDropsBacklog(t *testing.T) {
+	conn := newStalledConn()
+	metrics := NewStreamMetrics()
+	queue := newSendQueue(conn, 8, time.Minute, metrics)
+
+	queue.Enqueue(1)
+	<-conn.writing
+	queue.Enqueue(2)
+	queue.Enqueue(3)
+
+	closed := make(chan struct{})
+	go func() {
+		queue.Close()
+		close(closed)
+	}()
+	// The writer sees the stop once its stalled write returns
+	<-queue.stop
+	close(conn.release)
+	<-closed
+
+	if written, slow := conn.state(); len(written) != 1 || slow != 0 {
+		t.Errorf("Expected only the message being written to be sent, got %v, closed %d", written, slow)
+	}
+	if got := metrics.Snapshot(); got.DroppedMessages != 2 || got.Connections != 0 || got.QueuedMessages != 0 {
+		t.Errorf("Expected the backlog to be dropped, got %+v", got)
+	}
+
+	// A nil StreamMetrics counts nothing
+	unmetered := newSendQueue(newStalledConn(), 1, time.Minute, nil)
+	unmetered.Close()
+	if got := (*StreamMetrics)(nil).Snapshot(); got != (StreamMetricsResponse{}) {
+		t.Errorf("Expected empty metrics, got %+v", got)
+	}
+}
+
+func TestWatchPodsWritesThroughQueue(t *testing.T) {
+	clientset, watchers := newStreamClientset()
+	handler := NewHandler(clientset)
+	metrics := NewStreamMetrics()
+	handler.SetStreamMetrics(metrics)
+	r := gin.New()
+	r.GET("/pods/watch", handler.WatchPods)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/pods/watch", nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer ws.Close()
+
+	// Events queued when the watch ends are still written
+	watchers["pods"].Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}})
+	watchers["pods"].Delete(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}})
+	watchers["pods"].Stop()
+
+	var types []string
+	for {
+		var event struct {
+			Type string `json:"type"`
+		}
+		if err := ws.ReadJSON(&event); err != nil {
+			break
+		}
+		types = append(types, event.Type)
+	}
+	if strings.Join(types, ",") != "ADDED,DELETED" {
+		t.Errorf("Expected ADDED and DELETED events, got %v", types)
+	}
+	if got := metrics.Snapshot(); got.SentMessages != 2 || got.Connections != 0 {
+		t.Errorf("Expected two sent messages on a closed connection, got %+v", got)
+	}
+}
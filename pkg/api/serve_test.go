@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServeFinishesInFlightRequestsOnSIGTERM(t *testing.T) {
+	// Catching SIGTERM keeps it from terminating the test binary
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sleep", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(5 * time.Second)
+		io.WriteString(w, "done")
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	url := "http://" + lis.Addr().String() + "/sleep"
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, &http.Server{Handler: mux}, lis, 30*time.Second)
+	}()
+
+	bodies := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			bodies <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		bodies <- string(body)
+	}()
+	<-started
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find the test process: %v", err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send SIGTERM: %v", err)
+	}
+	signalled := time.Now()
+
+	if body := <-bodies; body != "done" {
+		t.Errorf("Expected the in-flight request to finish, got %q", body)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+	if waited := time.Since(signalled); waited < 4*time.Second {
+		t.Errorf("Expected Serve to wait for the 5s request, it returned after %s", waited)
+	}
+
+	if _, err := http.Get(url); err == nil {
+		t.Error("Expected new requests to be refused after shutdown")
+	}
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hang", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, &http.Server{Handler: mux}, lis, 100*time.Millisecond)
+	}()
+	go http.Get("http://" + lis.Addr().String() + "/hang")
+	<-started
+
+	cancel()
+	select {
+	case err := <-served:
+		if err == nil {
+			t.Error("Expected an error when requests outlive the shutdown timeout")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Serve to give up after the shutdown timeout")
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// defaultTokenExpirationSeconds is used when a token request omits an expiry
+const defaultTokenExpirationSeconds = 3600
+
+// minTokenExpirationSeconds is the shortest expiry the API server accepts
+const minTokenExpirationSeconds = 600
+
+// ServiceAccountHandler struct holds the Kubernetes clientset
+type ServiceAccountHandler struct {
+	clientset           kubernetes.Interface
+	enableTokenCreation bool
+}
+
+// NewServiceAccountHandler creates a new service account API handler. Token
+// creation is only served when enableTokenCreation is set.
+func NewServiceAccountHandler(clientset kubernetes.Interface, enableTokenCreation bool) *ServiceAccountHandler {
+	return &ServiceAccountHandler{clientset: clientset, enableTokenCreation: enableTokenCreation}
+}
+
+// CreateToken handles POST /api/v1/serviceaccounts/:namespace/:name/token
+func (h *ServiceAccountHandler) CreateToken(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if !h.enableTokenCreation {
+		klog.Warningf("AUDIT: denied token request for serviceaccount %s/%s from %s: token creation is disabled", namespace, name, c.ClientIP())
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "token creation is disabled (features.enableTokenCreation)"})
+		return
+	}
+
+	req := TokenRequest{ExpirationSeconds: defaultTokenExpirationSeconds}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			klog.Errorf("Failed to bind JSON: %v", err)
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+			return
+		}
+	}
+	if req.ExpirationSeconds < minTokenExpirationSeconds {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "expirationSeconds must be at least 600"})
+		return
+	}
+
+	klog.Infof("AUDIT: token requested for serviceaccount %s/%s from %s (expirationSeconds=%d, audiences=%v)",
+		namespace, name, c.ClientIP(), req.ExpirationSeconds, req.Audiences)
+
+	token, err := k8s.CreateServiceAccountToken(h.clientset, namespace, name, req.ExpirationSeconds, req.Audiences)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	klog.Infof("AUDIT: token issued for serviceaccount %s/%s, expires %s",
+		namespace, name, token.Status.ExpirationTimestamp.UTC().Format(time.RFC3339))
+
+	c.JSON(http.StatusCreated, TokenResponse{
+		Token:               token.Status.Token,
+		ExpirationTimestamp: token.Status.ExpirationTimestamp,
+		KubectlEquivalent:   k8s.KubectlCreateToken(namespace, name, req.ExpirationSeconds, req.Audiences),
+	})
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTokenRouter returns a router backed by a fake that issues a mock token
+// and records the token request it received
+func newTokenRouter(enabled bool, received **authv1.TokenRequest) *gin.Engine {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create := action.(k8stesting.CreateAction)
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		request := create.GetObject().(*authv1.TokenRequest)
+		*received = request
+		response := request.DeepCopy()
+		response.Status = authv1.TokenRequestStatus{
+			Token:               "mock-token",
+			ExpirationTimestamp: metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		}
+		return true, response, nil
+	})
+
+	handler := NewServiceAccountHandler(clientset, enabled)
+	r := gin.New()
+	r.POST("/serviceaccounts/:namespace/:name/token", handler.CreateToken)
+	return r
+}
+
+func TestCreateServiceAccountToken(t *testing.T) {
+	var received *authv1.TokenRequest
+	r := newTokenRouter(true, &received)
+
+	body, _ := json.Marshal(TokenRequest{ExpirationSeconds: 1800, Audiences: []string{"api"}})
+	req, _ := http.NewRequest("POST", "/serviceaccounts/default/debugger/token", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response["token"] != "mock-token" {
+		t.Errorf("Expected mock-token, got %q", response["token"])
+	}
+	if response["expirationTimestamp"] != "2030-01-01T00:00:00Z" {
+		t.Errorf("Unexpected expirationTimestamp %q", response["expirationTimestamp"])
+	}
+
+	if received == nil {
+		t.Fatal("Expected a TokenRequest to reach the API")
+	}
+	if *received.Spec.ExpirationSeconds != 1800 {
+		t.Errorf("Expected expirationSeconds 1800, got %d", *received.Spec.ExpirationSeconds)
+	}
+	if len(received.Spec.Audiences) != 1 || received.Spec.Audiences[0] != "api" {
+		t.Errorf("Expected audiences [api], got %v", received.Spec.Audiences)
+	}
+}
+
+func TestCreateServiceAccountTokenDefaults(t *testing.T) {
+	var received *authv1.TokenRequest
+	r := newTokenRouter(true, &received)
+
+	req, _ := http.NewRequest("POST", "/serviceaccounts/default/debugger/token", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if received == nil || *received.Spec.ExpirationSeconds != defaultTokenExpirationSeconds {
+		t.Errorf("Expected default expiration of %d seconds", defaultTokenExpirationSeconds)
+	}
+
+	body, _ := json.Marshal(TokenRequest{ExpirationSeconds: 60})
+	req, _ = http.NewRequest("POST", "/serviceaccounts/default/debugger/token", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too short expiry, got %d", w.Code)
+	}
+}
+
+func TestCreateServiceAccountTokenDisabled(t *testing.T) {
+	var received *authv1.TokenRequest
+	r := newTokenRouter(false, &received)
+
+	body, _ := json.Marshal(TokenRequest{ExpirationSeconds: 3600})
+	req, _ := http.NewRequest("POST", "/serviceaccounts/default/debugger/token", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+	if received != nil {
+		t.Error("Expected no TokenRequest to reach the API when disabled")
+	}
+}
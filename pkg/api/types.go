@@ -0,0 +1,423 @@
+package api
+
+import (
+	"encoding/json"
+
+	"k8s-dashboard/pkg/k8s"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// The types in this file are the request and response bodies of the /api/v1
+// endpoints. Their JSON field names are the v1 wire format: renaming or
+// removing a field breaks existing clients, so only add optional fields.
+// testdata/golden holds the expected shapes.
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// AnnotationPolicyResponse is the body of a create request rejected for
+// missing required annotations: an ErrorResponse naming the annotations
+type AnnotationPolicyResponse struct {
+	Error   string   `json:"error"`
+	Missing []string `json:"missing"`
+}
+
+// DeleteResponse is the body of a successful delete
+type DeleteResponse struct {
+	Message string `json:"message"`
+	// KubectlEquivalent is the kubectl command doing the same delete
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+	// Warnings say when a controller will recreate the object, or
+	// finalizers hold it
+	Warnings []string `json:"warnings,omitempty"`
+	// FinalizersRemoved are the finalizers ?force=true emptied
+	FinalizersRemoved []string `json:"finalizersRemoved,omitempty"`
+}
+
+// VersionResponse is the body of GET /api/v1/version
+type VersionResponse struct {
+	// Version is the semantic version of the kgo server
+	Version string `json:"version"`
+}
+
+// ApplyResponse is the body of a successfully applied manifest
+type ApplyResponse struct {
+	Message string `json:"message"`
+	// KubectlEquivalent is the kubectl command applying the same manifest
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// ApplyURLRequest is the JSON body of POST /api/v1/apply/url
+type ApplyURLRequest struct {
+	URL string `json:"url"`
+	// SHA256 is the expected hex checksum of the manifest, optionally
+	// prefixed "sha256:"; empty skips the verification
+	SHA256    string `json:"sha256,omitempty"`
+	Namespace string `json:"namespace"`
+}
+
+// ApplyDocumentResult is the outcome of applying a document of a manifest
+type ApplyDocumentResult struct {
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace"`
+	// Action is created, updated or unchanged, and empty when Error is set
+	Action string `json:"action,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ApplyURLResponse is the body of a manifest applied from a URL, with a
+// result per document in manifest order
+type ApplyURLResponse struct {
+	// URL is where the manifest was read, after redirects
+	URL string `json:"url"`
+	// SHA256 is the hex checksum of the manifest that was applied
+	SHA256  string                `json:"sha256"`
+	Results []ApplyDocumentResult `json:"results"`
+	// KubectlEquivalent is the kubectl command applying the same manifest
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// The responses below are the bodies of a created or updated object: the
+// object's own fields, plus the kubectl command doing the same change.
+
+// PodResponse is the body of a created or updated pod
+type PodResponse struct {
+	*v1.Pod
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// PodDetailsResponse is a pod with the summary of its effective security
+// context
+type PodDetailsResponse struct {
+	*v1.Pod
+	Security k8s.PodSecurity `json:"security"`
+}
+
+// DebugRequest is the JSON body adding a debug container to a pod, as with
+// kubectl debug
+type DebugRequest struct {
+	Image string `json:"image"`
+	// Command replaces the image's entrypoint when given
+	Command []string `json:"command,omitempty"`
+	// TargetContainer is the container whose process namespace the debug
+	// container shares, optional
+	TargetContainer string `json:"targetContainer,omitempty"`
+}
+
+// DebugResponse is the body of a pod a debug container was added to
+type DebugResponse struct {
+	*v1.Pod
+	// DebugContainer is the name of the ephemeral container added, whose
+	// logs are under debug/<name>/logs
+	DebugContainer    string `json:"debugContainer"`
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// DeploymentResponse is the body of a created or updated deployment
+type DeploymentResponse struct {
+	*appsv1.Deployment
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// RolloutResponse is the body of a paused or resumed deployment
+type RolloutResponse struct {
+	Namespace         string `json:"namespace"`
+	Name              string `json:"name"`
+	Paused            bool   `json:"paused"`
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// ProbeFailureRequest is the JSON body of a liveness probe failure injected
+// into a container of a deployment
+type ProbeFailureRequest struct {
+	Container       string `json:"container"`
+	DurationSeconds int    `json:"durationSeconds"`
+}
+
+// ProbeFailureResponse is the body of an injected probe failure, reverted at
+// RevertAt
+type ProbeFailureResponse struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Container string      `json:"container"`
+	RevertAt  metav1.Time `json:"revertAt"`
+}
+
+// ScaleRequest is the JSON body of a scale of a deployment or statefulset
+type ScaleRequest struct {
+	Replicas *int32 `json:"replicas"`
+}
+
+// ScaleResponse is the body of a scaled deployment or statefulset
+type ScaleResponse struct {
+	Kind              string `json:"kind"`
+	Namespace         string `json:"namespace"`
+	Name              string `json:"name"`
+	Replicas          int32  `json:"replicas"`
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// RestartResponse is the body of a restarted deployment, statefulset or
+// daemonset. Warning says which pods the restart leaves alone, if any.
+type RestartResponse struct {
+	Kind              string `json:"kind"`
+	Namespace         string `json:"namespace"`
+	Name              string `json:"name"`
+	RestartedAt       string `json:"restartedAt"`
+	Warning           string `json:"warning,omitempty"`
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// ServiceResponse is the body of a created or updated service
+type ServiceResponse struct {
+	*v1.Service
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// ConfigMapResponse is the body of a created or updated configmap
+type ConfigMapResponse struct {
+	*v1.ConfigMap
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// MetadataResponse is the body of a labels or annotations patch: the
+// object's labels and annotations after it
+type MetadataResponse struct {
+	Kind              string            `json:"kind"`
+	Namespace         string            `json:"namespace"`
+	Name              string            `json:"name"`
+	Labels            map[string]string `json:"labels"`
+	Annotations       map[string]string `json:"annotations"`
+	KubectlEquivalent string            `json:"kubectlEquivalent,omitempty"`
+}
+
+// PodListResponse is the body of a pod list. Continue is set when the list
+// was limited and more pods remain; pass it back as ?continue= for the next page.
+type PodListResponse struct {
+	Pods     []v1.Pod `json:"pods"`
+	Continue string   `json:"continue,omitempty"`
+}
+
+// PodSummaryListResponse is the body of GET /api/v1/pods/summary
+type PodSummaryListResponse struct {
+	Pods []k8s.PodReadiness `json:"pods"`
+}
+
+// NodeMetricsResponse is the body of GET /api/v1/metrics/nodes
+type NodeMetricsResponse struct {
+	Nodes []k8s.NodeMetricSummary `json:"nodes"`
+}
+
+// DeploymentListResponse is the body of a deployment list
+type DeploymentListResponse struct {
+	Deployments []appsv1.Deployment `json:"deployments"`
+}
+
+// ServiceListResponse is the body of a service list
+type ServiceListResponse struct {
+	Services []v1.Service `json:"services"`
+}
+
+// ConfigMapListResponse is the body of a configmap list
+type ConfigMapListResponse struct {
+	ConfigMaps []v1.ConfigMap `json:"configmaps"`
+}
+
+// ConfigMapSummaryListResponse is the body of a configmap list requested
+// with ?omitData=true: values are left out, key names and sizes are kept
+type ConfigMapSummaryListResponse struct {
+	ConfigMaps []k8s.ConfigMapSummary `json:"configmaps"`
+}
+
+// CRDListResponse is the body of a CustomResourceDefinition list
+type CRDListResponse struct {
+	CRDs []k8s.CRD `json:"crds"`
+}
+
+// PodWatchEvent is a single message on the pod watch WebSocket. Object holds
+// the pod, or a metav1.Status when Type is ERROR.
+type PodWatchEvent struct {
+	Type   watch.EventType `json:"Type"`
+	Object runtime.Object  `json:"Object"`
+}
+
+// ExecResponse is the final message on the pod exec WebSocket
+type ExecResponse struct {
+	Status string `json:"status"`
+}
+
+// StreamEvent is a single change sent to event stream clients
+type StreamEvent struct {
+	Type      string `json:"type"`
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// SearchResult is a single resource matching a search query
+type SearchResult struct {
+	Type       string `json:"type"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	MatchField string `json:"matchField"`
+	Score      int    `json:"score"`
+}
+
+// SearchResponse is the body of a search, best matches first
+type SearchResponse struct {
+	Results []SearchResult `json:"results"`
+}
+
+// ObjectDiffResponse is the body of a diff between two named objects
+type ObjectDiffResponse struct {
+	A         string           `json:"a"`
+	B         string           `json:"b"`
+	Identical bool             `json:"identical"`
+	Changes   []k8s.DiffChange `json:"changes"`
+	Unified   string           `json:"unified"`
+}
+
+// NamespaceDiffResponse is the body of a per-name comparison of two namespaces
+type NamespaceDiffResponse struct {
+	ANamespace string            `json:"aNamespace"`
+	BNamespace string            `json:"bNamespace"`
+	Results    []k8s.DiffSummary `json:"results"`
+}
+
+// DeploymentDiffResponse is the body of a deployment pod template diff
+// against its previous ReplicaSet
+type DeploymentDiffResponse struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Identical bool   `json:"identical"`
+	Unified   string `json:"unified"`
+}
+
+// BulkOperation is one create, update or delete of a bulk request. Body is
+// the object to create or update; Name names the object to delete, and
+// overrides the body's name when set.
+type BulkOperation struct {
+	Action    string          `json:"action"`
+	Resource  string          `json:"resource"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// BulkRequest is the JSON body of POST /api/v1/bulk
+type BulkRequest struct {
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkResult is the outcome of the bulk operation at Index
+type BulkResult struct {
+	Index   int    `json:"index"`
+	Success bool   `json:"success"`
+	Name    string `json:"name,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// KubectlEquivalent is the kubectl command doing what a successful
+	// operation did
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// BulkResponse is the body of a bulk request, a result per operation in
+// request order
+type BulkResponse struct {
+	Results []BulkResult `json:"results"`
+}
+
+// ConfigMapFromDataRequest is the JSON body of a configmap created from
+// literals, as with kubectl create configmap --from-literal
+type ConfigMapFromDataRequest struct {
+	Name        string            `json:"name"`
+	Literals    map[string]string `json:"literals"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// TokenRequest is the body of a service account token request
+type TokenRequest struct {
+	ExpirationSeconds int64    `json:"expirationSeconds"`
+	Audiences         []string `json:"audiences"`
+}
+
+// TokenResponse is the body of a created service account token
+type TokenResponse struct {
+	Token               string      `json:"token"`
+	ExpirationTimestamp metav1.Time `json:"expirationTimestamp"`
+	// KubectlEquivalent is the kubectl command requesting the same token
+	KubectlEquivalent string `json:"kubectlEquivalent,omitempty"`
+}
+
+// CoalescingResponse is the body of the list coalescing metrics
+type CoalescingResponse struct {
+	Enabled           bool  `json:"enabled"`
+	CoalescedRequests int64 `json:"coalescedRequests"`
+	UpstreamCalls     int64 `json:"upstreamCalls"`
+}
+
+// CredentialMetricsResponse is the body of the credential metrics
+type CredentialMetricsResponse struct {
+	// Reloading is set when the server reloads changed credentials
+	Reloading  bool         `json:"reloading"`
+	Reloads    int64        `json:"reloads"`
+	LastReload *metav1.Time `json:"lastReload,omitempty"`
+	// Expiry is when the client certificate or inline bearer token expires
+	Expiry           *metav1.Time `json:"expiry,omitempty"`
+	ExpiresInSeconds *int64       `json:"expiresInSeconds,omitempty"`
+	// ExpiringSoon is set once they expire within 24 hours
+	ExpiringSoon bool `json:"expiringSoon"`
+}
+
+// StreamMetricsResponse is the body of the streaming connection metrics
+type StreamMetricsResponse struct {
+	// Connections are the open WebSocket watches and event streams
+	Connections int64 `json:"connections"`
+	// QueuedMessages are waiting to be written, over every connection
+	QueuedMessages int64 `json:"queuedMessages"`
+	// MaxQueueDepth is the deepest any one connection's queue has been
+	MaxQueueDepth     int64 `json:"maxQueueDepth"`
+	SentMessages      int64 `json:"sentMessages"`
+	DroppedMessages   int64 `json:"droppedMessages"`
+	SlowClientsClosed int64 `json:"slowClientsClosed"`
+	WriteFailures     int64 `json:"writeFailures"`
+}
+
+// DebugVarsResponse is the body of the runtime stats of /debug/vars
+type DebugVarsResponse struct {
+	Goroutines     int    `json:"goroutines"`
+	HeapAllocBytes uint64 `json:"heapAllocBytes"`
+	HeapInuseBytes uint64 `json:"heapInuseBytes"`
+	HeapObjects    uint64 `json:"heapObjects"`
+	GCCycles       uint32 `json:"gcCycles"`
+	GCPauseTotalNs uint64 `json:"gcPauseTotalNs"`
+	// RecentGCPausesNs are the pauses of the last garbage collections, the
+	// latest first
+	RecentGCPausesNs []uint64 `json:"recentGcPausesNs"`
+	// ActiveWatches are the open WebSocket watches and event streams
+	ActiveWatches    int64 `json:"activeWatches"`
+	ActiveLogStreams int64 `json:"activeLogStreams"`
+	// CachedObjects are the list results kept by list coalescing
+	CachedObjects int `json:"cachedObjects"`
+}
+
+// NamespaceInfo is a namespace, with how long and on what its deletion has
+// been stuck when it is terminating
+type NamespaceInfo struct {
+	v1.Namespace
+	// AgeSeconds is the age of the namespace when it was listed
+	AgeSeconds  int64                     `json:"ageSeconds"`
+	Termination *k8s.NamespaceTermination `json:"termination,omitempty"`
+}
+
+// NamespaceListResponse is the body of a namespace list
+type NamespaceListResponse struct {
+	Namespaces []NamespaceInfo `json:"namespaces"`
+}
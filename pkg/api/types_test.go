@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden response shapes in testdata/golden")
+
+// jsonShape replaces every value in a decoded JSON document with its type,
+// keeping object keys, so that only the wire format is compared
+func jsonShape(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		shape := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			shape[key] = jsonShape(item)
+		}
+		return shape
+	case []interface{}:
+		if len(v) == 0 {
+			return []interface{}{}
+		}
+		return []interface{}{jsonShape(v[0])}
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
+
+// newGoldenRouter serves the API over a fixed set of objects
+func newGoldenRouter() *gin.Engine {
+	deployment := newDiffDeployment("default", "web", "nginx:1.26")
+	deployment.UID = "web-uid"
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	deployment.Spec.Template.Labels = map[string]string{"app": "web"}
+	previous := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-1",
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "web"},
+			Annotations:     map[string]string{"deployment.kubernetes.io/revision": "1"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: *deployment.Spec.Template.DeepCopy()},
+	}
+	previous.Spec.Template.Spec.Containers[0].Image = "nginx:1.25"
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx:1.26"}}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+
+	clientset := fake.NewSimpleClientset(
+		deployment,
+		previous,
+		newDiffDeployment("staging", "web", "nginx:1.27"),
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		pod,
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 80}}},
+		},
+		&v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []v1.EndpointSubset{{
+				Addresses: []v1.EndpointAddress{{IP: "10.0.0.5", TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "web-abc"}}},
+				Ports:     []v1.EndpointPort{{Port: 80}},
+			}},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Data:       map[string]string{"key": "value"},
+			BinaryData: map[string][]byte{"logo.png": {0x89, 'P', 'N', 'G'}},
+		},
+	)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list", "delete"}}},
+	}}
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &authv1.TokenRequest{Status: authv1.TokenRequestStatus{
+			Token:               "t0ken",
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+		}}, nil
+	})
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.28.3"}
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &authv1.SelfSubjectReview{Status: authv1.SelfSubjectReviewStatus{UserInfo: authv1.UserInfo{
+			Username: "system:serviceaccount:kgo:kgo",
+			Groups:   []string{"system:authenticated"},
+		}}}, nil
+	})
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb == "get"
+		return true, review, nil
+	})
+	metricsClientset := &metricsfake.Clientset{}
+	metricsClientset.AddReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Usage:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+		}}}, nil
+	})
+
+	r := gin.New()
+	RegisterRoutes(r, clientset, RouterOptions{
+		DynamicClient: fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme,
+			map[schema.GroupVersionResource]string{k8s.CRDResource: "CustomResourceDefinitionList"},
+			pod, newTestCRD("widgets.example.com", "example.com", "Widget")),
+		EnableTokenCreation: true,
+		RequiredAnnotations: []string{"owner"},
+		MetricsClientset:    metricsClientset,
+		ClientInfo: &k8s.ClientInfo{
+			Server:     "https://prod.example.com:6443",
+			Kubeconfig: "/home/dev/.kube/config",
+			Context:    "prod",
+			Auth:       "bearer token (redacted)",
+		},
+	})
+	return r
+}
+
+// TestResponseShapesMatchGolden guards the v1 wire format. Run with -update
+// after an intentional, backwards compatible change to regenerate the files.
+func TestResponseShapesMatchGolden(t *testing.T) {
+	r := newGoldenRouter()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"pods_list", "GET", "/api/v1/pods?namespace=default", "", http.StatusOK},
+		{"deployments_list", "GET", "/api/v1/deployments?namespace=default", "", http.StatusOK},
+		{"services_list", "GET", "/api/v1/services?namespace=default", "", http.StatusOK},
+		{"configmaps_list", "GET", "/api/v1/configmaps?namespace=default", "", http.StatusOK},
+		{"configmaps_summary_list", "GET", "/api/v1/configmaps?namespace=default&omitData=true", "", http.StatusOK},
+		{"search", "GET", "/api/v1/search?q=web&types=pods", "", http.StatusOK},
+		{"selector_preview", "GET", "/api/v1/selectors/preview?namespace=default&selector=app%3Dweb", "", http.StatusOK},
+		{"diff_objects", "GET", "/api/v1/diff?kind=deployment&a=default/web&b=staging/web", "", http.StatusOK},
+		{"diff_namespaces", "GET", "/api/v1/diff?kind=deployments&aNamespace=default&bNamespace=staging", "", http.StatusOK},
+		{"deployment_diff", "GET", "/api/v1/deployments/default/web/diff", "", http.StatusOK},
+		{"metrics_cluster", "GET", "/api/v1/metrics/cluster", "", http.StatusOK},
+		{"metrics_namespace", "GET", "/api/v1/metrics/namespace/default", "", http.StatusOK},
+		{"metrics_namespaces", "GET", "/api/v1/metrics/namespaces", "", http.StatusOK},
+		{"metrics_nodes", "GET", "/api/v1/metrics/nodes", "", http.StatusOK},
+		{"metrics_coalescing", "GET", "/api/v1/metrics/coalescing", "", http.StatusOK},
+		{"metrics_streams", "GET", "/api/v1/metrics/streams", "", http.StatusOK},
+		{"metrics_credentials", "GET", "/api/v1/metrics/credentials", "", http.StatusOK},
+		{"overview", "GET", "/api/v1/overview", "", http.StatusOK},
+		{"cluster_info", "GET", "/api/v1/cluster/info", "", http.StatusOK},
+		{"version", "GET", "/api/v1/version", "", http.StatusOK},
+		{"permissions", "GET", "/api/v1/permissions?namespace=default", "", http.StatusOK},
+		{"namespaces_list", "GET", "/api/v1/namespaces", "", http.StatusOK},
+		{"crds_list", "GET", "/api/v1/crds", "", http.StatusOK},
+		{"namespace_finalizer_report", "GET", "/api/v1/namespaces/default/finalizer-report", "", http.StatusOK},
+		{"serviceaccount_token", "POST", "/api/v1/serviceaccounts/default/builder/token", `{"expirationSeconds": 3600}`, http.StatusCreated},
+		{"pod_create", "POST", "/api/v1/pods/default", `{"metadata": {"name": "api", "annotations": {"owner": "alice"}}, "spec": {"containers": [{"name": "api", "image": "nginx"}]}}`, http.StatusCreated},
+		{"annotation_policy", "POST", "/api/v1/pods/default", `{"metadata": {"name": "api2"}, "spec": {"containers": [{"name": "api", "image": "nginx"}]}}`, http.StatusUnprocessableEntity},
+		{"apply", "POST", "/api/v1/apply/default", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: applied\n  annotations:\n    owner: alice\n", http.StatusOK},
+		{"bulk", "POST", "/api/v1/bulk", `{"operations": [{"action": "create", "resource": "configmap", "namespace": "default", "body": {"metadata": {"name": "bulk", "annotations": {"owner": "alice"}}}}, {"action": "delete", "resource": "pod", "namespace": "default", "name": "missing"}]}`, http.StatusOK},
+		{"deployment_pause", "POST", "/api/v1/deployments/default/web/pause", "", http.StatusOK},
+		{"workload_restart", "POST", "/api/v1/deployments/default/web/restart", "", http.StatusOK},
+		{"pod_get", "GET", "/api/v1/pods/default/web-abc", "", http.StatusOK},
+		{"pod_network", "GET", "/api/v1/pods/default/web-abc/network", "", http.StatusOK},
+		{"pod_debug", "POST", "/api/v1/pods/default/web-abc/debug", `{"image": "busybox:1.36"}`, http.StatusOK},
+		{"labels_patch", "PATCH", "/api/v1/pods/default/web-abc/labels", `{"set": {"tier": "frontend"}, "remove": ["app"]}`, http.StatusOK},
+		{"delete", "DELETE", "/api/v1/configmaps/default/web", "", http.StatusOK},
+		{"error", "GET", "/api/v1/deployments/default/missing/diff", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+
+			var body interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			shape, err := json.MarshalIndent(jsonShape(body), "", "  ")
+			if err != nil {
+				t.Fatalf("Failed to marshal shape: %v", err)
+			}
+			shape = append(shape, '\n')
+
+			goldenPath := filepath.Join("testdata", "golden", tt.name+".json")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, shape, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(golden, shape) {
+				t.Errorf("Response shape changed from %s:\nwant:\n%s\ngot:\n%s", goldenPath, golden, shape)
+			}
+		})
+	}
+}
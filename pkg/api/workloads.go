@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/klog/v2"
+)
+
+// ScaleWorkload handles PUT /api/v1/:kind/:namespace/:name/scale with a body
+// of {"replicas": 3}, for deployments and statefulsets
+func (h *ResourceHandler) ScaleWorkload(c *gin.Context) {
+	kind, namespace, name := c.Param("kind"), c.Param("namespace"), c.Param("name")
+	if !k8s.SupportsScale(kind) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: fmt.Sprintf("Unsupported kind %q", kind)})
+		return
+	}
+
+	var req ScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		klog.Errorf("Failed to bind JSON: %v", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON: " + err.Error()})
+		return
+	}
+	if req.Replicas == nil || *req.Replicas < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Expected replicas of 0 or more"})
+		return
+	}
+
+	if err := k8s.ScaleWorkload(c.Request.Context(), h.clientset, kind, namespace, name, *req.Replicas); err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ScaleResponse{
+		Kind:              kind,
+		Namespace:         namespace,
+		Name:              name,
+		Replicas:          *req.Replicas,
+		KubectlEquivalent: k8s.KubectlScale(namespace, kind, name, *req.Replicas),
+	})
+}
+
+// RestartWorkload handles POST /api/v1/:kind/:namespace/:name/restart, for
+// deployments, statefulsets and daemonsets
+func (h *ResourceHandler) RestartWorkload(c *gin.Context) {
+	kind, namespace, name := c.Param("kind"), c.Param("namespace"), c.Param("name")
+	if !k8s.SupportsRestart(kind) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: fmt.Sprintf("Unsupported kind %q", kind)})
+		return
+	}
+
+	now := time.Now()
+	obj, err := k8s.RestartWorkload(c.Request.Context(), h.clientset, kind, namespace, name, now)
+	if err != nil {
+		c.JSON(statusForError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, RestartResponse{
+		Kind:              kind,
+		Namespace:         namespace,
+		Name:              name,
+		RestartedAt:       now.Format(time.RFC3339),
+		Warning:           k8s.RestartWarning(obj),
+		KubectlEquivalent: k8s.KubectlRestart(namespace, kind, name),
+	})
+}
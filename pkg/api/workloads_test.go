@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestScaleAndRestartWorkloads(t *testing.T) {
+	replicas, partition := int32(3), int32(1)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+				},
+			},
+		},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"}},
+	)
+	// The fake clientset has no scale subresource; serve the statefulset's
+	// replicas as the API server does
+	gvr := appsv1.SchemeGroupVersion.WithResource("statefulsets")
+	clientset.PrependReactor("*", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		name := ""
+		if update, ok := action.(k8stesting.UpdateAction); ok {
+			name = update.GetObject().(*autoscalingv1.Scale).Name
+		} else {
+			name = action.(k8stesting.GetAction).GetName()
+		}
+		obj, err := clientset.Tracker().Get(gvr, action.GetNamespace(), name)
+		if err != nil {
+			return true, nil, err
+		}
+		sts := obj.(*appsv1.StatefulSet)
+		if update, ok := action.(k8stesting.UpdateAction); ok {
+			scale := update.GetObject().(*autoscalingv1.Scale)
+			sts.Spec.Replicas = &scale.Spec.Replicas
+			return true, scale, clientset.Tracker().Update(gvr, sts, action.GetNamespace())
+		}
+		return true, &autoscalingv1.Scale{ObjectMeta: sts.ObjectMeta, Spec: autoscalingv1.ScaleSpec{Replicas: *sts.Spec.Replicas}}, nil
+	})
+
+	handler := NewResourceHandler(clientset)
+	r := gin.New()
+	r.PUT("/:kind/:namespace/:name/scale", handler.ScaleWorkload)
+	r.POST("/:kind/:namespace/:name/restart", handler.RestartWorkload)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("PUT", "/statefulsets/default/db/scale", `{"replicas": 5}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var scaled ScaleResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &scaled); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if scaled.Replicas != 5 || scaled.KubectlEquivalent != "kubectl -n default scale statefulsets/db --replicas=5" {
+		t.Errorf("Unexpected scale response %+v", scaled)
+	}
+
+	w = serve("POST", "/statefulsets/default/db/restart", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var restarted RestartResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &restarted); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if restarted.Warning != "rolling update partition is 1, so pods with an ordinal below 1 are not restarted" {
+		t.Errorf("Expected the partition warning, got %+v", restarted)
+	}
+	sts, _ := clientset.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+	if sts == nil || sts.Spec.Template.Annotations[k8s.RestartedAtAnnotation] != restarted.RestartedAt || *sts.Spec.Replicas != 5 {
+		t.Errorf("Expected the restart to annotate the scaled statefulset at %s, got %+v", restarted.RestartedAt, sts)
+	}
+
+	w = serve("POST", "/daemonsets/default/agent/restart", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	restarted = RestartResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &restarted); err != nil || restarted.Warning != "" || restarted.KubectlEquivalent != "kubectl -n default rollout restart daemonsets/agent" {
+		t.Errorf("Expected a daemonset restart without warning, got %+v, %v", restarted, err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{"negative replicas", "PUT", "/statefulsets/default/db/scale", `{"replicas": -1}`, http.StatusBadRequest},
+		{"missing replicas", "PUT", "/statefulsets/default/db/scale", `{}`, http.StatusBadRequest},
+		{"invalid JSON", "PUT", "/statefulsets/default/db/scale", `{"replicas": "3"}`, http.StatusBadRequest},
+		{"daemonsets cannot scale", "PUT", "/daemonsets/default/agent/scale", `{"replicas": 3}`, http.StatusNotFound},
+		{"missing statefulset", "PUT", "/statefulsets/default/cache/scale", `{"replicas": 3}`, http.StatusNotFound},
+		{"pods cannot restart", "POST", "/pods/default/db-0/restart", "", http.StatusNotFound},
+		{"missing daemonset", "POST", "/daemonsets/default/missing/restart", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(tt.method, tt.path, tt.body); w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
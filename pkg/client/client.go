@@ -0,0 +1,261 @@
+// Package client is a typed Go client for the kgo REST API. Request and
+// response bodies are the types the API handlers use, so the client and the
+// server cannot drift apart.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"k8s-dashboard/pkg/api"
+	"k8s-dashboard/pkg/version"
+
+	"k8s.io/klog/v2"
+)
+
+// Client talks to a kgo server
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	tlsConfig  *tls.Config
+
+	allowMajorSkew bool
+	// versionMu guards the version check the first request makes; a check
+	// that could not reach the server is retried by the next request
+	versionMu      sync.Mutex
+	versionChecked bool
+	versionErr     error
+	serverVersion  string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken sends token as a bearer token with every request, for servers
+// behind an authenticating proxy
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for https and wss requests
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for requests. A TLS config
+// set with WithTLSConfig is still used for WebSocket connections.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAllowMajorSkew talks to servers of another major version than the
+// client's, which requests fail against otherwise
+func WithAllowMajorSkew() Option {
+	return func(c *Client) {
+		c.allowMajorSkew = true
+	}
+}
+
+// New creates a client for the kgo server at baseURL, e.g. http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %v", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+
+	c := &Client{baseURL: u}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = c.tlsConfig
+		c.httpClient = &http.Client{Transport: transport}
+	}
+	return c, nil
+}
+
+// APIError is returned when the server answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("kgo: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// CallOption changes a single request
+type CallOption func(*http.Request)
+
+// Confirm sets the confirmation header required for mutating requests in a
+// protected namespace. The namespace must be the request's target namespace.
+func Confirm(namespace string) CallOption {
+	return func(req *http.Request) {
+		req.Header.Set(api.ConfirmHeader, namespace)
+	}
+}
+
+// ConfirmDelete sets the header required to delete a namespace annotated
+// deletion-protected. It must name the namespace being deleted.
+func ConfirmDelete(namespace string) CallOption {
+	return func(req *http.Request) {
+		req.Header.Set(api.ConfirmDeleteHeader, namespace)
+	}
+}
+
+// endpoint builds an API URL from path segments, escaping each one
+func (c *Client) endpoint(query url.Values, segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	// RawPath keeps a "/" inside a segment, such as a configmap key, encoded
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + "/api/v1/" + strings.Join(segments, "/")
+	u.RawPath = c.baseURL.EscapedPath() + "/api/v1/" + strings.Join(escaped, "/")
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// newRequest creates a request with the client's auth and the call options
+func (c *Client) newRequest(ctx context.Context, method, endpoint, contentType string, body io.Reader, opts []CallOption) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req, nil
+}
+
+// CheckVersion compares the server's version to the client's with
+// version.CheckServer, as the first request does, and returns the server's
+// version. Minor skew is logged; major skew fails every request unless
+// allowed. A server predating /api/v1/version is logged and accepted.
+func (c *Client) CheckVersion(ctx context.Context) (string, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if c.versionChecked {
+		return c.serverVersion, c.versionErr
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(nil, "version"), "", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		klog.Warningf("The kgo server does not report its version, it predates client version %s", version.Version)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return "", &APIError{StatusCode: resp.StatusCode, Message: "failed to get the server version"}
+	default:
+		var body api.VersionResponse
+		if err := decodeBody(resp, &body); err != nil {
+			return "", err
+		}
+		c.serverVersion = body.Version
+		c.versionErr = version.CheckServer(body.Version, c.allowMajorSkew)
+	}
+	c.versionChecked = true
+	return c.serverVersion, c.versionErr
+}
+
+// send performs a request and returns the response, or an APIError built
+// from the error body when the status is not 2xx
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if _, err := c.CheckVersion(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	var errResp api.ErrorResponse
+	if json.Unmarshal(data, &errResp) != nil || errResp.Error == "" {
+		errResp.Error = strings.TrimSpace(string(data))
+	}
+	return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+}
+
+// do sends in as JSON, when it is not nil, and decodes the response into out
+func (c *Client) do(ctx context.Context, method, endpoint string, in, out interface{}, opts []CallOption) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := c.newRequest(ctx, method, endpoint, contentType, body, opts)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return decodeBody(resp, out)
+}
+
+// decodeBody decodes a JSON response body into out
+func decodeBody(resp *http.Response, out interface{}) error {
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %v", resp.Request.Method, resp.Request.URL.Path, err)
+	}
+	return nil
+}
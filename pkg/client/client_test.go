@@ -0,0 +1,751 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/api"
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/version"
+
+	"github.com/gin-gonic/gin"
+	appsv1 "k8s.io/api/apps/v1"
+	authv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestServer runs the real API router over a fake clientset with "prod"
+// protected and token creation enabled
+func newTestServer(t *testing.T, clientset *fake.Clientset, opts ...Option) *Client {
+	t.Helper()
+	guard, err := k8s.NewNamespaceGuard([]string{"prod"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	r := gin.New()
+	api.RegisterRoutes(r, clientset, api.RouterOptions{Guard: guard, EnableTokenCreation: true})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	c, err := New(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return c
+}
+
+func TestResourceCRUD(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset())
+	ctx := context.Background()
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx:1.25"}}},
+	}
+	created, err := c.CreatePod(ctx, "default", pod)
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	if created.Namespace != "default" || created.Name != "web" {
+		t.Errorf("Expected default/web, got %s/%s", created.Namespace, created.Name)
+	}
+	created.Spec.Containers[0].Image = "nginx:1.27"
+	updated, err := c.UpdatePod(ctx, "default", created)
+	if err != nil {
+		t.Fatalf("UpdatePod failed: %v", err)
+	}
+	if updated.Spec.Containers[0].Image != "nginx:1.27" {
+		t.Errorf("Expected updated image, got %s", updated.Spec.Containers[0].Image)
+	}
+	pods, err := c.ListPods(ctx, "default")
+	if err != nil || len(pods) != 1 {
+		t.Fatalf("Expected 1 pod, got %v, %v", pods, err)
+	}
+	if err := c.DeletePod(ctx, "default", "web"); err != nil {
+		t.Fatalf("DeletePod failed: %v", err)
+	}
+	if pods, _ := c.ListPods(ctx, "default"); len(pods) != 0 {
+		t.Errorf("Expected no pods after delete, got %d", len(pods))
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: pod.Spec}},
+	}
+	if _, err := c.CreateDeployment(ctx, "default", deployment); err != nil {
+		t.Fatalf("CreateDeployment failed: %v", err)
+	}
+	deployment.Labels = map[string]string{"tier": "frontend"}
+	if updated, err := c.UpdateDeployment(ctx, "default", deployment); err != nil || updated.Labels["tier"] != "frontend" {
+		t.Fatalf("UpdateDeployment failed: %v, %v", updated, err)
+	}
+	if deployments, err := c.ListDeployments(ctx, "default"); err != nil || len(deployments) != 1 {
+		t.Fatalf("Expected 1 deployment, got %v, %v", deployments, err)
+	}
+	if err := c.DeleteDeployment(ctx, "default", "web"); err != nil {
+		t.Fatalf("DeleteDeployment failed: %v", err)
+	}
+
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	if _, err := c.CreateService(ctx, "default", service); err != nil {
+		t.Fatalf("CreateService failed: %v", err)
+	}
+	service.Spec.Ports = []v1.ServicePort{{Port: 80}}
+	if _, err := c.UpdateService(ctx, "default", service); err != nil {
+		t.Fatalf("UpdateService failed: %v", err)
+	}
+	if services, err := c.ListServices(ctx, "default"); err != nil || len(services) != 1 || services[0].Spec.Ports[0].Port != 80 {
+		t.Fatalf("Expected the updated service, got %v, %v", services, err)
+	}
+	if err := c.DeleteService(ctx, "default", "web"); err != nil {
+		t.Fatalf("DeleteService failed: %v", err)
+	}
+
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app"}, Data: map[string]string{"a": "1"}}
+	if _, err := c.CreateConfigMap(ctx, "default", configMap); err != nil {
+		t.Fatalf("CreateConfigMap failed: %v", err)
+	}
+	configMap.Data["b"] = "2"
+	if _, err := c.UpdateConfigMap(ctx, "default", configMap); err != nil {
+		t.Fatalf("UpdateConfigMap failed: %v", err)
+	}
+	if configMaps, err := c.ListConfigMaps(ctx, "default"); err != nil || len(configMaps) != 1 || configMaps[0].Data["b"] != "2" {
+		t.Fatalf("Expected the updated configmap, got %v, %v", configMaps, err)
+	}
+	if err := c.DeleteConfigMap(ctx, "default", "app"); err != nil {
+		t.Fatalf("DeleteConfigMap failed: %v", err)
+	}
+	if err := c.DeleteConfigMap(ctx, "default", "app"); err == nil {
+		t.Error("Expected deleting a missing configmap to fail")
+	}
+}
+
+func TestConfigMapKeys(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Data:       map[string]string{"config.yaml": "debug: true\n"},
+	}))
+	ctx := context.Background()
+
+	if _, err := c.SetConfigMapKey(ctx, "default", "app", "nginx/site.conf", []byte("server {}"), false); err != nil {
+		t.Fatalf("SetConfigMapKey failed: %v", err)
+	}
+	updated, err := c.SetConfigMapKey(ctx, "default", "app", "logo.png", []byte{0x89, 'P', 'N', 'G'}, true)
+	if err != nil {
+		t.Fatalf("SetConfigMapKey binary failed: %v", err)
+	}
+	if len(updated.BinaryData["logo.png"]) != 4 || updated.Data["config.yaml"] == "" {
+		t.Errorf("Expected binary key added and others kept, got %v %v", updated.Data, updated.BinaryData)
+	}
+
+	value, binary, err := c.GetConfigMapKey(ctx, "default", "app", "nginx/site.conf")
+	if err != nil || binary || string(value) != "server {}" {
+		t.Errorf("Expected text value for key with slash, got %q, %v, %v", value, binary, err)
+	}
+	value, binary, err = c.GetConfigMapKey(ctx, "default", "app", "logo.png")
+	if err != nil || !binary || string(value) != "\x89PNG" {
+		t.Errorf("Expected binary value, got %q, %v, %v", value, binary, err)
+	}
+
+	if err := c.DeleteConfigMapKey(ctx, "default", "app", "nginx/site.conf"); err != nil {
+		t.Fatalf("DeleteConfigMapKey failed: %v", err)
+	}
+	if _, _, err := c.GetConfigMapKey(ctx, "default", "app", "nginx/site.conf"); !IsNotFound(err) {
+		t.Errorf("Expected a not found error for a deleted key, got %v", err)
+	}
+}
+
+func TestPatchLabelsAndAnnotations(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "app",
+		Namespace: "default",
+		Labels:    map[string]string{"app": "web", "team": "a"},
+	}}))
+	ctx := context.Background()
+
+	metadata, err := c.PatchLabels(ctx, "configmaps", "default", "app", k8s.MetadataChange{Set: map[string]string{"tier": "frontend"}, Remove: []string{"team"}})
+	if err != nil {
+		t.Fatalf("PatchLabels failed: %v", err)
+	}
+	if len(metadata.Labels) != 2 || metadata.Labels["tier"] != "frontend" {
+		t.Errorf("Expected app and tier labels, got %v", metadata.Labels)
+	}
+	metadata, err = c.PatchAnnotations(ctx, "configmaps", "default", "app", k8s.MetadataChange{Set: map[string]string{"owner": "alice"}})
+	if err != nil || metadata.Annotations["owner"] != "alice" || len(metadata.Labels) != 2 {
+		t.Errorf("Expected the owner annotation next to the labels, got %+v, %v", metadata, err)
+	}
+
+	var apiErr *APIError
+	if _, err := c.PatchLabels(ctx, "configmaps", "default", "app", k8s.MetadataChange{Set: map[string]string{"tier": "front end"}}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a 422 for an invalid label value, got %v", err)
+	}
+}
+
+func TestProtectedNamespaceConfirmation(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset())
+	ctx := context.Background()
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+
+	_, err := c.CreateConfigMap(ctx, "prod", configMap)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusPreconditionRequired {
+		t.Fatalf("Expected a 428 APIError, got %v", err)
+	}
+	if apiErr.Message == "" {
+		t.Error("Expected the server error message to be kept")
+	}
+
+	if _, err := c.CreateConfigMap(ctx, "prod", configMap, Confirm("prod")); err != nil {
+		t.Errorf("Expected confirmed create to succeed, got %v", err)
+	}
+}
+
+func TestDeleteProtectedNamespace(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "payments",
+		Annotations: map[string]string{k8s.DeletionProtectedAnnotation: "true"},
+	}}))
+	ctx := context.Background()
+
+	var apiErr *APIError
+	if err := c.DeleteNamespace(ctx, "payments"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected a 403 APIError, got %v", err)
+	}
+	if err := c.DeleteNamespace(ctx, "payments", ConfirmDelete("payments")); err != nil {
+		t.Errorf("Expected confirmed delete to succeed, got %v", err)
+	}
+	if err := c.DeleteNamespace(ctx, "payments", ConfirmDelete("payments")); !IsNotFound(err) {
+		t.Errorf("Expected not found once deleted, got %v", err)
+	}
+}
+
+func TestApplyURLDisabled(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset())
+
+	var apiErr *APIError
+	_, err := c.ApplyURL(context.Background(), api.ApplyURLRequest{URL: "https://git.internal/app.yaml", Namespace: "default"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden || !strings.Contains(apiErr.Message, "allowedManifestHosts") {
+		t.Errorf("Expected a 403 APIError without allowed hosts, got %v", err)
+	}
+}
+
+func TestApplyAndQueries(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-abc", Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		},
+	)
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &authv1.TokenRequest{Status: authv1.TokenRequestStatus{
+			Token:               "t0ken",
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+		}}, nil
+	})
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = true
+		return true, review, nil
+	})
+	c := newTestServer(t, clientset)
+	ctx := context.Background()
+
+	manifest := []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: nginx\n")
+	if err := c.Apply(ctx, "default", manifest); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if err := c.Apply(ctx, "staging", manifest); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	namespaces, err := c.ListNamespaces(ctx)
+	if err != nil || len(namespaces) != 1 || namespaces[0].Name != "default" || namespaces[0].Termination != nil {
+		t.Errorf("Expected the active default namespace, got %+v, %v", namespaces, err)
+	}
+	var apiErr *APIError
+	if _, err := c.NamespaceFinalizerReport(ctx, "default"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without a dynamic client, got %v", err)
+	}
+
+	pod, err := c.GetPod(ctx, "default", "nginx-abc")
+	if err != nil || pod.Pod == nil || pod.Name != "nginx-abc" || len(pod.Security.Containers) != len(pod.Spec.Containers) {
+		t.Errorf("Expected nginx-abc with the security of its containers, got %+v, %v", pod, err)
+	}
+	if _, err := c.GetPod(ctx, "default", "missing"); !IsNotFound(err) {
+		t.Errorf("Expected not found for a missing pod, got %v", err)
+	}
+
+	network, err := c.PodNetwork(ctx, "default", "nginx-abc")
+	if err != nil || network.Name != "nginx-abc" || len(network.Services) != 0 {
+		t.Errorf("Expected the network of nginx-abc without services, got %+v, %v", network, err)
+	}
+	if _, err := c.PodNetwork(ctx, "default", "missing"); !IsNotFound(err) {
+		t.Errorf("Expected not found for a missing pod, got %v", err)
+	}
+
+	page, err := c.ListPodsPage(ctx, "default", 10, "")
+	if err != nil || len(page.Pods) != 1 || page.Continue != "" {
+		t.Errorf("Expected a single, final page of pods, got %+v, %v", page, err)
+	}
+
+	results, err := c.Search(ctx, "nginx", SearchOptions{Types: []string{"pods", "deployments"}})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Expected 2 search results, got %v, %v", results, err)
+	}
+
+	diff, err := c.Diff(ctx, "deployment", "default/nginx", "staging/nginx")
+	if err != nil || !diff.Identical {
+		t.Errorf("Expected identical deployments, got %+v, %v", diff, err)
+	}
+	namespaceDiff, err := c.DiffNamespaces(ctx, "deployment", "default", "staging")
+	if err != nil || len(namespaceDiff.Results) != 1 || namespaceDiff.Results[0].Name != "nginx" {
+		t.Errorf("Expected one per-name result, got %+v, %v", namespaceDiff, err)
+	}
+	if _, err := c.DeploymentTemplateDiff(ctx, "default", "missing"); !IsNotFound(err) {
+		t.Errorf("Expected not found for a missing deployment, got %v", err)
+	}
+
+	if err := c.PauseDeployment(ctx, "default", "nginx"); err != nil {
+		t.Fatalf("PauseDeployment failed: %v", err)
+	}
+	if deployment, _ := clientset.AppsV1().Deployments("default").Get(ctx, "nginx", metav1.GetOptions{}); !deployment.Spec.Paused {
+		t.Error("Expected nginx to be paused")
+	}
+	if err := c.ResumeDeployment(ctx, "default", "nginx"); err != nil {
+		t.Fatalf("ResumeDeployment failed: %v", err)
+	}
+	if deployment, _ := clientset.AppsV1().Deployments("default").Get(ctx, "nginx", metav1.GetOptions{}); deployment.Spec.Paused {
+		t.Error("Expected nginx to be resumed")
+	}
+	if err := c.PauseDeployment(ctx, "default", "missing"); !IsNotFound(err) {
+		t.Errorf("Expected not found for a missing deployment, got %v", err)
+	}
+	restart, err := c.RestartWorkload(ctx, "deployments", "default", "nginx")
+	if err != nil || restart.RestartedAt == "" || restart.Warning != "" {
+		t.Errorf("Expected nginx to be restarted without warning, got %+v, %v", restart, err)
+	}
+	if err := c.ScaleWorkload(ctx, "daemonsets", "default", "nginx", 2); !IsNotFound(err) {
+		t.Errorf("Expected not found for scaling a daemonset, got %v", err)
+	}
+
+	cluster, err := c.ClusterMetrics(ctx)
+	if err != nil || cluster.Cluster.Pods != 1 || cluster.Cluster.Namespaces != 1 {
+		t.Errorf("Unexpected cluster metrics %+v, %v", cluster, err)
+	}
+	namespace, err := c.NamespaceMetrics(ctx, "default")
+	if err != nil || namespace.Pods.Running != 1 || namespace.Deployments.Total != 1 {
+		t.Errorf("Unexpected namespace metrics %+v, %v", namespace, err)
+	}
+	all, err := c.AllNamespaceMetrics(ctx, "")
+	if err != nil || len(all.Namespaces) != 1 || all.Namespaces[0].Pods.Running != 1 {
+		t.Errorf("Unexpected metrics of all namespaces %+v, %v", all, err)
+	}
+	if stats, err := c.CoalescingMetrics(ctx); err != nil || stats.Enabled {
+		t.Errorf("Expected coalescing to be disabled, got %+v, %v", stats, err)
+	}
+	if _, err := c.NodeMetrics(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotImplemented {
+		t.Errorf("Expected node metrics to need a metrics client, got %v", err)
+	}
+	if info, err := c.ClusterInfo(ctx); err != nil || info.ServerVersion == "" {
+		t.Errorf("Expected the server version, got %+v, %v", info, err)
+	}
+	if matrix, err := c.Permissions(ctx, "default"); err != nil || matrix.Namespace != "default" || !matrix.Can("delete", "pods") {
+		t.Errorf("Expected the default namespace's permissions, got %+v, %v", matrix, err)
+	}
+
+	token, err := c.CreateServiceAccountToken(ctx, "default", "builder", api.TokenRequest{ExpirationSeconds: 3600})
+	if err != nil || token.Token != "t0ken" || token.ExpirationTimestamp.IsZero() {
+		t.Errorf("Unexpected token %+v, %v", token, err)
+	}
+}
+
+func TestGetPodLogs(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+	}))
+
+	logs, err := c.GetPodLogs(context.Background(), "default", "web", LogOptions{Container: "app"})
+	if err != nil {
+		t.Fatalf("GetPodLogs failed: %v", err)
+	}
+	defer logs.Close()
+	data, err := io.ReadAll(logs)
+	if err != nil || string(data) != "fake logs" {
+		t.Errorf("Expected fake logs, got %q, %v", data, err)
+	}
+}
+
+func TestDebugPod(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "distroless/app"}}},
+	}))
+	ctx := context.Background()
+
+	debugged, err := c.DebugPod(ctx, "default", "web", api.DebugRequest{Image: "busybox:1.36", TargetContainer: "app"})
+	if err != nil {
+		t.Fatalf("DebugPod failed: %v", err)
+	}
+	if len(debugged.Spec.EphemeralContainers) != 1 || debugged.Spec.EphemeralContainers[0].Name != debugged.DebugContainer {
+		t.Fatalf("Expected the pod with debug container %q, got %+v", debugged.DebugContainer, debugged.Spec.EphemeralContainers)
+	}
+
+	logs, err := c.GetDebugContainerLogs(ctx, "default", "web", debugged.DebugContainer, false)
+	if err != nil {
+		t.Fatalf("GetDebugContainerLogs failed: %v", err)
+	}
+	defer logs.Close()
+	if data, err := io.ReadAll(logs); err != nil || string(data) != "fake logs" {
+		t.Errorf("Expected fake logs, got %q, %v", data, err)
+	}
+	if _, err := c.GetDebugContainerLogs(ctx, "default", "web", "app", false); !IsNotFound(err) {
+		t.Errorf("Expected not found for a container that is not ephemeral, got %v", err)
+	}
+}
+
+func TestStreamsDeliverEvents(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	podWatcher := watch.NewFake()
+	serviceWatcher := watch.NewFake()
+	clientset.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, podWatcher, nil
+	})
+	clientset.PrependWatchReactor("services", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, serviceWatcher, nil
+	})
+	c := newTestServer(t, clientset)
+	ctx := context.Background()
+
+	go func() {
+		serviceWatcher.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+		serviceWatcher.Stop()
+	}()
+	var events []api.StreamEvent
+	err := c.StreamEvents(ctx, "default", []string{"services"}, func(event api.StreamEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamEvents failed: %v", err)
+	}
+	if len(events) != 1 || events[0] != (api.StreamEvent{Type: "ADDED", Resource: "service", Name: "web", Namespace: "default"}) {
+		t.Errorf("Unexpected stream events %+v", events)
+	}
+
+	go func() {
+		podWatcher.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+		podWatcher.Error(&metav1.Status{Status: metav1.StatusFailure, Message: "too old"})
+	}()
+	stop := errors.New("stop")
+	var podEvents []api.PodWatchEvent
+	err = c.WatchPods(ctx, "default", func(event api.PodWatchEvent) error {
+		podEvents = append(podEvents, event)
+		if len(podEvents) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Expected the callback error, got %v", err)
+	}
+	if pod, ok := podEvents[0].Object.(*v1.Pod); podEvents[0].Type != watch.Added || !ok || pod.Name != "web" {
+		t.Errorf("Expected an ADDED pod event, got %+v", podEvents[0])
+	}
+	if status, ok := podEvents[1].Object.(*metav1.Status); podEvents[1].Type != watch.Error || !ok || status.Message != "too old" {
+		t.Errorf("Expected an ERROR status event, got %+v", podEvents[1])
+	}
+}
+
+func TestStreamEventsCancellation(t *testing.T) {
+	c := newTestServer(t, fake.NewSimpleClientset())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := c.StreamEvents(ctx, "default", []string{"pods"}, func(api.StreamEvent) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+}
+
+func TestTokenAndTLS(t *testing.T) {
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"enabled":true,"coalescedRequests":3,"upstreamCalls":1}`)
+	}))
+	defer server.Close()
+
+	// Without the server's CA the TLS handshake fails
+	untrusted, _ := New(server.URL)
+	if _, err := untrusted.CoalescingMetrics(context.Background()); err == nil {
+		t.Error("Expected an untrusted certificate to be rejected")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	c, err := New(server.URL+"/", WithToken("s3cret"), WithTLSConfig(&tls.Config{RootCAs: roots}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	stats, err := c.CoalescingMetrics(context.Background())
+	if err != nil || stats.CoalescedRequests != 3 {
+		t.Fatalf("Unexpected stats %+v, %v", stats, err)
+	}
+	if authorization != "Bearer s3cret" {
+		t.Errorf("Expected bearer token, got %q", authorization)
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	saved := version.Version
+	version.Version = "1.4.0"
+	t.Cleanup(func() { version.Version = saved })
+
+	serverVersion := ""
+	versionRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/version" {
+			versionRequests++
+			if serverVersion == "" {
+				http.NotFound(w, r)
+				return
+			}
+			io.WriteString(w, `{"version":"`+serverVersion+`"}`)
+			return
+		}
+		io.WriteString(w, `{"enabled":true}`)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		server  string
+		opts    []Option
+		refused bool
+	}{
+		{server: "1.4.3"},
+		{server: "1.5.0"},
+		{server: "2.0.0", refused: true},
+		{server: "2.0.0", opts: []Option{WithAllowMajorSkew()}},
+		// A server predating /api/v1/version
+		{server: ""},
+	}
+	for _, tt := range tests {
+		serverVersion, versionRequests = tt.server, 0
+		c, _ := New(server.URL, tt.opts...)
+		for i := 0; i < 2; i++ {
+			_, err := c.CoalescingMetrics(ctx)
+			var skewErr *version.SkewError
+			if tt.refused != errors.As(err, &skewErr) {
+				t.Errorf("Server %q: expected refused=%v, got %v", tt.server, tt.refused, err)
+			}
+		}
+		if versionRequests != 1 {
+			t.Errorf("Server %q: expected the version to be checked once, got %d requests", tt.server, versionRequests)
+		}
+		if got, _ := c.CheckVersion(ctx); got != tt.server {
+			t.Errorf("Expected server version %q, got %q", tt.server, got)
+		}
+	}
+}
+
+func TestNewRejectsInvalidURL(t *testing.T) {
+	for _, baseURL := range []string{"localhost:8080", "ftp://example.com", "://"} {
+		if _, err := New(baseURL); err == nil {
+			t.Errorf("Expected %q to be rejected", baseURL)
+		}
+	}
+}
+
+// clientRoutes names the client method calling each route the API registers,
+// or why there is none
+var clientRoutes = map[string]string{
+	"GET /api/v1/pods":                                              "ListPods",
+	"GET /api/v1/pods/watch":                                        "WatchPods",
+	"GET /api/v1/pods/summary":                                      "PodSummaries",
+	"GET /api/v1/pods/:namespace/:name":                             "GetPod",
+	"GET /api/v1/pods/:namespace/:name/logs":                        "GetPodLogs",
+	"GET /api/v1/pods/:namespace/:name/debug/:container/logs":       "GetDebugContainerLogs",
+	"GET /api/v1/pods/:namespace/:name/network":                     "PodNetwork",
+	"GET /api/v1/permissions":                                       "Permissions",
+	"GET /api/v1/metrics/namespace/:namespace":                      "NamespaceMetrics",
+	"GET /api/v1/metrics/namespaces":                                "AllNamespaceMetrics",
+	"GET /api/v1/metrics/nodes":                                     "NodeMetrics",
+	"GET /api/v1/metrics/cluster":                                   "ClusterMetrics",
+	"GET /api/v1/metrics/coalescing":                                "CoalescingMetrics",
+	"GET /api/v1/metrics/credentials":                               "CredentialMetrics",
+	"GET /api/v1/metrics/streams":                                   "StreamMetrics",
+	"GET /api/v1/configmaps":                                        "ListConfigMaps",
+	"GET /api/v1/configmaps/:namespace/:name/data/*key":             "GetConfigMapKey",
+	"GET /api/v1/crds":                                              "ListCRDs",
+	"GET /api/v1/cluster/info":                                      "ClusterInfo",
+	"GET /api/v1/services":                                          "ListServices",
+	"GET /api/v1/selectors/preview":                                 "PreviewSelector",
+	"GET /api/v1/search":                                            "Search",
+	"GET /api/v1/deployments":                                       "ListDeployments",
+	"GET /api/v1/deployments/:namespace/:name/diff":                 "DeploymentTemplateDiff",
+	"GET /api/v1/diff":                                              "Diff",
+	"GET /api/v1/namespaces":                                        "ListNamespaces",
+	"GET /api/v1/namespaces/:name/finalizer-report":                 "NamespaceFinalizerReport",
+	"GET /api/v1/version":                                           "CheckVersion",
+	"GET /api/v1/overview":                                          "Overview",
+	"GET /api/v1/events/stream":                                     "StreamEvents",
+	"POST /api/v1/deployments/:namespace":                           "CreateDeployment",
+	"POST /api/v1/deployments/:namespace/:name/pause":               "PauseDeployment",
+	"POST /api/v1/deployments/:namespace/:name/resume":              "ResumeDeployment",
+	"POST /api/v1/deployments/:namespace/:name/chaos/probe-failure": "InjectProbeFailure",
+	"POST /api/v1/pods/:namespace":                                  "CreatePod",
+	"POST /api/v1/pods/:namespace/:name/debug":                      "DebugPod",
+	"POST /api/v1/configmaps/:namespace":                            "CreateConfigMap",
+	"POST /api/v1/configmaps/:namespace/from-data":                  "CreateConfigMapFromData",
+	"POST /api/v1/services/:namespace":                              "CreateService",
+	"POST /api/v1/serviceaccounts/:namespace/:name/token":           "CreateServiceAccountToken",
+	"POST /api/v1/apply/url":                                        "ApplyURL",
+	"POST /api/v1/apply/:namespace":                                 "Apply",
+	"POST /api/v1/bulk":                                             "Bulk",
+	"POST /api/v1/:kind/:namespace/:name/restart":                   "RestartWorkload",
+	"PUT /api/v1/configmaps/:namespace/:name":                       "UpdateConfigMap",
+	"PUT /api/v1/configmaps/:namespace/:name/data/*key":             "SetConfigMapKey",
+	"PUT /api/v1/pods/:namespace/:name":                             "UpdatePod",
+	"PUT /api/v1/deployments/:namespace/:name":                      "UpdateDeployment",
+	"PUT /api/v1/services/:namespace/:name":                         "UpdateService",
+	"PUT /api/v1/:kind/:namespace/:name/scale":                      "ScaleWorkload",
+	"DELETE /api/v1/configmaps/:namespace/:name":                    "DeleteConfigMap",
+	"DELETE /api/v1/configmaps/:namespace/:name/data/*key":          "DeleteConfigMapKey",
+	"DELETE /api/v1/pods/:namespace/:name":                          "DeletePod",
+	"DELETE /api/v1/deployments/:namespace/:name":                   "DeleteDeployment",
+	"DELETE /api/v1/services/:namespace/:name":                      "DeleteService",
+	"DELETE /api/v1/namespaces/:name":                               "DeleteNamespace",
+	"PATCH /api/v1/:kind/:namespace/:name/labels":                   "PatchLabels",
+	"PATCH /api/v1/:kind/:namespace/:name/annotations":              "PatchAnnotations",
+}
+
+// routesWithoutClient are the routes the client leaves out on purpose
+var routesWithoutClient = map[string]string{
+	"GET /api/v1/pods/:namespace/:name/exec": "an interactive terminal over a WebSocket, for browsers",
+}
+
+// TestClientCoversRoutes fails when a route is registered without a client
+// method calling it, so that the client keeps up with the API
+func TestClientCoversRoutes(t *testing.T) {
+	r := gin.New()
+	api.RegisterRoutes(r, fake.NewSimpleClientset(), api.RouterOptions{})
+
+	clientType := reflect.TypeOf(&Client{})
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		if _, ok := routesWithoutClient[key]; ok {
+			continue
+		}
+		method, ok := clientRoutes[key]
+		if !ok {
+			t.Errorf("No client method calls %s", key)
+			continue
+		}
+		if _, ok := clientType.MethodByName(method); !ok {
+			t.Errorf("%s names %s, which the client does not have", key, method)
+		}
+	}
+}
+
+func TestBulkAndClusterQueries(t *testing.T) {
+	probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz"}}}
+	labels := map[string]string{"app": "web"}
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: labels},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+			Status: v1.PodStatus{
+				Phase:             v1.PodRunning,
+				ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: false}},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", LivenessProbe: probe}}},
+			}},
+		},
+	)
+	c := newTestServer(t, clientset)
+	ctx := context.Background()
+
+	summaries, err := c.PodSummaries(ctx, "default", true)
+	if err != nil || len(summaries) != 1 || summaries[0].Name != "web-1" || !summaries[0].NotReady {
+		t.Errorf("Expected web-1 as not ready, got %+v, %v", summaries, err)
+	}
+
+	preview, err := c.PreviewSelector(ctx, "default", "app=web")
+	if err != nil || fmt.Sprint(preview.Pods, preview.Deployments) != "[web-1] [web]" {
+		t.Errorf("Expected app=web to select web-1 and web, got %+v, %v", preview, err)
+	}
+	var apiErr *APIError
+	if _, err := c.PreviewSelector(ctx, "default", "app in"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid selector, got %v", err)
+	}
+
+	overview, err := c.Overview(ctx)
+	if err != nil || overview.Pods.Total != 1 || overview.Deployments.Total != 1 {
+		t.Errorf("Expected one pod and one deployment in the overview, got %+v, %v", overview, err)
+	}
+	if _, err := c.ListCRDs(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without a dynamic client, got %v", err)
+	}
+	if streams, err := c.StreamMetrics(ctx); err != nil || streams.Connections != 0 {
+		t.Errorf("Expected no open streams, got %+v, %v", streams, err)
+	}
+	if credentials, err := c.CredentialMetrics(ctx); err != nil || credentials.Reloading {
+		t.Errorf("Expected no credential reloads, got %+v, %v", credentials, err)
+	}
+
+	injected, err := c.InjectProbeFailure(ctx, "default", "web", api.ProbeFailureRequest{Container: "app", DurationSeconds: 300})
+	if err != nil || injected.Container != "app" || time.Until(injected.RevertAt.Time) < 4*time.Minute {
+		t.Fatalf("Expected a 5 minute probe failure of app, got %+v, %v", injected, err)
+	}
+	if _, err := c.InjectProbeFailure(ctx, "default", "web", api.ProbeFailureRequest{Container: "app", DurationSeconds: 300}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409 while the probe already fails, got %v", err)
+	}
+
+	created, err := c.CreateConfigMapFromData(ctx, "default", api.ConfigMapFromDataRequest{
+		Name:     "settings",
+		Literals: map[string]string{"mode": "fast"},
+	}, map[string][]byte{"app.conf": []byte("port=80\n"), "logo.png": {0x89, 'P', 'N', 'G', 0xff}})
+	if err != nil {
+		t.Fatalf("CreateConfigMapFromData failed: %v", err)
+	}
+	if created.Data["mode"] != "fast" || created.Data["app.conf"] != "port=80\n" || len(created.BinaryData["logo.png"]) != 5 {
+		t.Errorf("Expected the literal and both files, got data %v and binary data %v", created.Data, created.BinaryData)
+	}
+	literals, err := c.CreateConfigMapFromData(ctx, "default", api.ConfigMapFromDataRequest{Name: "flags", Literals: map[string]string{"debug": "true"}}, nil)
+	if err != nil || literals.Data["debug"] != "true" {
+		t.Errorf("Expected a configmap of the literal, got %+v, %v", literals, err)
+	}
+
+	results, err := c.Bulk(ctx, api.BulkRequest{Operations: []api.BulkOperation{
+		{Action: "delete", Resource: "configmap", Namespace: "default", Name: "settings"},
+		{Action: "delete", Resource: "configmap", Namespace: "default", Name: "missing"},
+	}}, false)
+	if err != nil || len(results) != 2 || !results[0].Success || results[1].Success {
+		t.Errorf("Expected the first delete to succeed and the second to fail, got %+v, %v", results, err)
+	}
+}
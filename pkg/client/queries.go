@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s-dashboard/pkg/api"
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/metrics"
+)
+
+// SearchOptions narrows a search. Empty fields use the server defaults: the
+// default namespace and every supported type.
+type SearchOptions struct {
+	Namespaces []string
+	Types      []string
+}
+
+// Search finds resources whose name, labels or annotations contain query,
+// best matches first
+func (c *Client) Search(ctx context.Context, query string, searchOpts SearchOptions, opts ...CallOption) ([]api.SearchResult, error) {
+	params := url.Values{"q": {query}}
+	if len(searchOpts.Namespaces) > 0 {
+		params.Set("namespaces", strings.Join(searchOpts.Namespaces, ","))
+	}
+	if len(searchOpts.Types) > 0 {
+		params.Set("types", strings.Join(searchOpts.Types, ","))
+	}
+
+	var resp api.SearchResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(params, "search"), nil, &resp, opts)
+	return resp.Results, err
+}
+
+// Diff compares two objects of a kind, each given as namespace/name
+func (c *Client) Diff(ctx context.Context, kind, a, b string, opts ...CallOption) (*api.ObjectDiffResponse, error) {
+	params := url.Values{"kind": {kind}, "a": {a}, "b": {b}}
+
+	var diff api.ObjectDiffResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(params, "diff"), nil, &diff, opts); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// DiffNamespaces compares every object of a kind by name across two
+// namespaces. kind is singular, e.g. "deployment".
+func (c *Client) DiffNamespaces(ctx context.Context, kind, aNamespace, bNamespace string, opts ...CallOption) (*api.NamespaceDiffResponse, error) {
+	params := url.Values{"kind": {kind + "s"}, "aNamespace": {aNamespace}, "bNamespace": {bNamespace}}
+
+	var diff api.NamespaceDiffResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(params, "diff"), nil, &diff, opts); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// ClusterMetrics returns object counts for the whole cluster
+func (c *Client) ClusterMetrics(ctx context.Context, opts ...CallOption) (*metrics.ClusterMetrics, error) {
+	var m metrics.ClusterMetrics
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "metrics", "cluster"), nil, &m, opts); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// NamespaceMetrics returns object counts for a namespace
+func (c *Client) NamespaceMetrics(ctx context.Context, namespace string, opts ...CallOption) (*metrics.NamespaceMetrics, error) {
+	var m metrics.NamespaceMetrics
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "metrics", "namespace", namespace), nil, &m, opts); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// AllNamespaceMetrics returns object counts for every namespace matching
+// labelSelector, empty for all of them, in one call
+func (c *Client) AllNamespaceMetrics(ctx context.Context, labelSelector string, opts ...CallOption) (*metrics.AllNamespaceMetrics, error) {
+	var query url.Values
+	if labelSelector != "" {
+		query = url.Values{"labelSelector": {labelSelector}}
+	}
+	var m metrics.AllNamespaceMetrics
+	if err := c.do(ctx, http.MethodGet, c.endpoint(query, "metrics", "namespaces"), nil, &m, opts); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// CoalescingMetrics reports how many list requests shared an upstream call
+func (c *Client) CoalescingMetrics(ctx context.Context, opts ...CallOption) (*api.CoalescingResponse, error) {
+	var stats api.CoalescingResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "metrics", "coalescing"), nil, &stats, opts); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// NodeMetrics returns the CPU and memory usage of every node, like kubectl
+// top node
+func (c *Client) NodeMetrics(ctx context.Context, opts ...CallOption) ([]k8s.NodeMetricSummary, error) {
+	var response api.NodeMetricsResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "metrics", "nodes"), nil, &response, opts); err != nil {
+		return nil, err
+	}
+	return response.Nodes, nil
+}
+
+// Permissions reports which verbs the server's identity may use on each
+// supported resource type of a namespace
+func (c *Client) Permissions(ctx context.Context, namespace string, opts ...CallOption) (*k8s.PermissionMatrix, error) {
+	var matrix k8s.PermissionMatrix
+	if err := c.do(ctx, http.MethodGet, c.endpoint(url.Values{"namespace": {namespace}}, "permissions"), nil, &matrix, opts); err != nil {
+		return nil, err
+	}
+	return &matrix, nil
+}
+
+// ClusterInfo reports which API server, kubeconfig and context the server
+// uses, who it is authenticated as and the server version
+func (c *Client) ClusterInfo(ctx context.Context, opts ...CallOption) (*k8s.ClusterInfo, error) {
+	var info k8s.ClusterInfo
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "cluster", "info"), nil, &info, opts); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// StreamMetrics reports the WebSocket watches and event streams the server
+// has open and how their send queues are doing
+func (c *Client) StreamMetrics(ctx context.Context, opts ...CallOption) (*api.StreamMetricsResponse, error) {
+	var stats api.StreamMetricsResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "metrics", "streams"), nil, &stats, opts); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CredentialMetrics reports whether the server reloads changed credentials,
+// and how often it has
+func (c *Client) CredentialMetrics(ctx context.Context, opts ...CallOption) (*api.CredentialMetricsResponse, error) {
+	var stats api.CredentialMetricsResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "metrics", "credentials"), nil, &stats, opts); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Overview returns the cluster overview: node, pod and deployment health,
+// the latest warning events and the pods of each namespace
+func (c *Client) Overview(ctx context.Context, opts ...CallOption) (*metrics.Overview, error) {
+	var overview metrics.Overview
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "overview"), nil, &overview, opts); err != nil {
+		return nil, err
+	}
+	return &overview, nil
+}
+
+// PreviewSelector returns the pods and deployments of a namespace a label
+// selector such as "app=web,tier!=db" matches
+func (c *Client) PreviewSelector(ctx context.Context, namespace, selector string, opts ...CallOption) (*k8s.SelectorPreview, error) {
+	query := url.Values{"namespace": {namespace}, "selector": {selector}}
+
+	var preview k8s.SelectorPreview
+	if err := c.do(ctx, http.MethodGet, c.endpoint(query, "selectors", "preview"), nil, &preview, opts); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
@@ -0,0 +1,454 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"k8s-dashboard/pkg/api"
+	"k8s-dashboard/pkg/k8s"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// namespaceQuery returns the query selecting a namespace for list calls
+func namespaceQuery(namespace string) url.Values {
+	return url.Values{"namespace": {namespace}}
+}
+
+// ListPods lists the pods in a namespace
+func (c *Client) ListPods(ctx context.Context, namespace string, opts ...CallOption) ([]v1.Pod, error) {
+	var list api.PodListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(namespaceQuery(namespace), "pods"), nil, &list, opts)
+	return list.Pods, err
+}
+
+// ListPodsPage lists at most limit pods in a namespace, starting at the
+// continue token of the previous page, or at the first pod for ""
+func (c *Client) ListPodsPage(ctx context.Context, namespace string, limit int64, continueToken string, opts ...CallOption) (*api.PodListResponse, error) {
+	query := namespaceQuery(namespace)
+	query.Set("limit", strconv.FormatInt(limit, 10))
+	if continueToken != "" {
+		query.Set("continue", continueToken)
+	}
+
+	var page api.PodListResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(query, "pods"), nil, &page, opts); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// CreatePod creates a pod in a namespace
+func (c *Client) CreatePod(ctx context.Context, namespace string, pod *v1.Pod, opts ...CallOption) (*v1.Pod, error) {
+	var created v1.Pod
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "pods", namespace), pod, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetPod returns a pod with the summary of its effective security context
+func (c *Client) GetPod(ctx context.Context, namespace, name string, opts ...CallOption) (*api.PodDetailsResponse, error) {
+	var pod api.PodDetailsResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "pods", namespace, name), nil, &pod, opts); err != nil {
+		return nil, err
+	}
+	return &pod, nil
+}
+
+// UpdatePod replaces a pod
+func (c *Client) UpdatePod(ctx context.Context, namespace string, pod *v1.Pod, opts ...CallOption) (*v1.Pod, error) {
+	var updated v1.Pod
+	if err := c.do(ctx, http.MethodPut, c.endpoint(nil, "pods", namespace, pod.Name), pod, &updated, opts); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// PodSummaries returns the readiness breakdown of each pod in a namespace,
+// or only of the running pods that are not ready with notReady
+func (c *Client) PodSummaries(ctx context.Context, namespace string, notReady bool, opts ...CallOption) ([]k8s.PodReadiness, error) {
+	query := namespaceQuery(namespace)
+	if notReady {
+		query.Set("notReady", "true")
+	}
+
+	var list api.PodSummaryListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(query, "pods", "summary"), nil, &list, opts)
+	return list.Pods, err
+}
+
+// PodNetwork returns the IPs of a pod, the services sending it traffic and
+// the service ports targeting a port no container declares
+func (c *Client) PodNetwork(ctx context.Context, namespace, name string, opts ...CallOption) (*k8s.PodNetwork, error) {
+	var network k8s.PodNetwork
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "pods", namespace, name, "network"), nil, &network, opts); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+// DebugPod adds an ephemeral debug container to a running pod, as kubectl
+// debug does, returning the updated pod and the debug container's name
+func (c *Client) DebugPod(ctx context.Context, namespace, name string, request api.DebugRequest, opts ...CallOption) (*api.DebugResponse, error) {
+	var debugged api.DebugResponse
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "pods", namespace, name, "debug"), request, &debugged, opts); err != nil {
+		return nil, err
+	}
+	return &debugged, nil
+}
+
+// DeletePod deletes a pod
+func (c *Client) DeletePod(ctx context.Context, namespace, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint(nil, "pods", namespace, name), nil, nil, opts)
+}
+
+// ListDeployments lists the deployments in a namespace
+func (c *Client) ListDeployments(ctx context.Context, namespace string, opts ...CallOption) ([]appsv1.Deployment, error) {
+	var list api.DeploymentListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(namespaceQuery(namespace), "deployments"), nil, &list, opts)
+	return list.Deployments, err
+}
+
+// CreateDeployment creates a deployment in a namespace
+func (c *Client) CreateDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment, opts ...CallOption) (*appsv1.Deployment, error) {
+	var created appsv1.Deployment
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "deployments", namespace), deployment, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateDeployment replaces a deployment
+func (c *Client) UpdateDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment, opts ...CallOption) (*appsv1.Deployment, error) {
+	var updated appsv1.Deployment
+	if err := c.do(ctx, http.MethodPut, c.endpoint(nil, "deployments", namespace, deployment.Name), deployment, &updated, opts); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteDeployment deletes a deployment
+func (c *Client) DeleteDeployment(ctx context.Context, namespace, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint(nil, "deployments", namespace, name), nil, nil, opts)
+}
+
+// PauseDeployment pauses the rollouts of a deployment
+func (c *Client) PauseDeployment(ctx context.Context, namespace, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodPost, c.endpoint(nil, "deployments", namespace, name, "pause"), nil, nil, opts)
+}
+
+// ResumeDeployment resumes the rollouts of a paused deployment
+func (c *Client) ResumeDeployment(ctx context.Context, namespace, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodPost, c.endpoint(nil, "deployments", namespace, name, "resume"), nil, nil, opts)
+}
+
+// DeploymentTemplateDiff diffs a deployment's pod template against its
+// previous ReplicaSet
+func (c *Client) DeploymentTemplateDiff(ctx context.Context, namespace, name string, opts ...CallOption) (*api.DeploymentDiffResponse, error) {
+	var diff api.DeploymentDiffResponse
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "deployments", namespace, name, "diff"), nil, &diff, opts); err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+// InjectProbeFailure makes the liveness probe of a container of a deployment
+// fail for a while, after which the server restores it
+func (c *Client) InjectProbeFailure(ctx context.Context, namespace, name string, request api.ProbeFailureRequest, opts ...CallOption) (*api.ProbeFailureResponse, error) {
+	var injected api.ProbeFailureResponse
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "deployments", namespace, name, "chaos", "probe-failure"), request, &injected, opts); err != nil {
+		return nil, err
+	}
+	return &injected, nil
+}
+
+// ListServices lists the services in a namespace
+func (c *Client) ListServices(ctx context.Context, namespace string, opts ...CallOption) ([]v1.Service, error) {
+	var list api.ServiceListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(namespaceQuery(namespace), "services"), nil, &list, opts)
+	return list.Services, err
+}
+
+// CreateService creates a service in a namespace
+func (c *Client) CreateService(ctx context.Context, namespace string, service *v1.Service, opts ...CallOption) (*v1.Service, error) {
+	var created v1.Service
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "services", namespace), service, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateService replaces a service
+func (c *Client) UpdateService(ctx context.Context, namespace string, service *v1.Service, opts ...CallOption) (*v1.Service, error) {
+	var updated v1.Service
+	if err := c.do(ctx, http.MethodPut, c.endpoint(nil, "services", namespace, service.Name), service, &updated, opts); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteService deletes a service
+func (c *Client) DeleteService(ctx context.Context, namespace, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint(nil, "services", namespace, name), nil, nil, opts)
+}
+
+// ListConfigMaps lists the configmaps in a namespace
+func (c *Client) ListConfigMaps(ctx context.Context, namespace string, opts ...CallOption) ([]v1.ConfigMap, error) {
+	var list api.ConfigMapListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(namespaceQuery(namespace), "configmaps"), nil, &list, opts)
+	return list.ConfigMaps, err
+}
+
+// ListConfigMapSummaries lists the configmaps in a namespace without their
+// values, only key names and sizes
+func (c *Client) ListConfigMapSummaries(ctx context.Context, namespace string, opts ...CallOption) ([]k8s.ConfigMapSummary, error) {
+	query := namespaceQuery(namespace)
+	query.Set("omitData", "true")
+
+	var list api.ConfigMapSummaryListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(query, "configmaps"), nil, &list, opts)
+	return list.ConfigMaps, err
+}
+
+// CreateConfigMap creates a configmap in a namespace
+func (c *Client) CreateConfigMap(ctx context.Context, namespace string, configMap *v1.ConfigMap, opts ...CallOption) (*v1.ConfigMap, error) {
+	var created v1.ConfigMap
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "configmaps", namespace), configMap, &created, opts); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// CreateConfigMapFromData creates a configmap from literals and files, as
+// kubectl create configmap --from-literal and --from-file do. Each file
+// becomes a key named after it; files that are not UTF-8 go to binaryData.
+func (c *Client) CreateConfigMapFromData(ctx context.Context, namespace string, request api.ConfigMapFromDataRequest, files map[string][]byte, opts ...CallOption) (*api.ConfigMapResponse, error) {
+	endpoint := c.endpoint(nil, "configmaps", namespace, "from-data")
+	var created api.ConfigMapResponse
+	if len(files) == 0 {
+		if err := c.do(ctx, http.MethodPost, endpoint, request, &created, opts); err != nil {
+			return nil, err
+		}
+		return &created, nil
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("name", request.Name)
+	for key, value := range request.Literals {
+		form.WriteField("literal", key+"="+value)
+	}
+	for key, value := range request.Annotations {
+		form.WriteField("annotation", key+"="+value)
+	}
+	for name, data := range files {
+		part, err := form.CreateFormFile("file", name)
+		if err != nil {
+			return nil, err
+		}
+		part.Write(data)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, endpoint, form.FormDataContentType(), &body, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := decodeBody(resp, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateConfigMap replaces a configmap
+func (c *Client) UpdateConfigMap(ctx context.Context, namespace string, configMap *v1.ConfigMap, opts ...CallOption) (*v1.ConfigMap, error) {
+	var updated v1.ConfigMap
+	if err := c.do(ctx, http.MethodPut, c.endpoint(nil, "configmaps", namespace, configMap.Name), configMap, &updated, opts); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteConfigMap deletes a configmap
+func (c *Client) DeleteConfigMap(ctx context.Context, namespace, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint(nil, "configmaps", namespace, name), nil, nil, opts)
+}
+
+// GetConfigMapKey returns the value of a single configmap key and whether it
+// is stored in binaryData
+func (c *Client) GetConfigMapKey(ctx context.Context, namespace, name, key string, opts ...CallOption) ([]byte, bool, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(nil, "configmaps", namespace, name, "data", key), "", nil, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	value, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, resp.Header.Get("Content-Type") == "application/octet-stream", nil
+}
+
+// SetConfigMapKey sets a single configmap key, leaving the others untouched.
+// A binary value is stored in binaryData.
+func (c *Client) SetConfigMapKey(ctx context.Context, namespace, name, key string, value []byte, binary bool, opts ...CallOption) (*v1.ConfigMap, error) {
+	contentType := "text/plain; charset=utf-8"
+	if binary {
+		contentType = "application/octet-stream"
+	}
+	req, err := c.newRequest(ctx, http.MethodPut, c.endpoint(nil, "configmaps", namespace, name, "data", key), contentType, bytes.NewReader(value), opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var updated v1.ConfigMap
+	if err := decodeBody(resp, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteConfigMapKey removes a single configmap key
+func (c *Client) DeleteConfigMapKey(ctx context.Context, namespace, name, key string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint(nil, "configmaps", namespace, name, "data", key), nil, nil, opts)
+}
+
+// PatchLabels sets and removes labels of an object of a plural kind such as
+// "deployments", leaving its other labels alone
+func (c *Client) PatchLabels(ctx context.Context, kind, namespace, name string, change k8s.MetadataChange, opts ...CallOption) (*api.MetadataResponse, error) {
+	return c.patchMetadata(ctx, kind, namespace, name, k8s.LabelsField, change, opts)
+}
+
+// PatchAnnotations sets and removes annotations of an object of a plural
+// kind, leaving its other annotations alone
+func (c *Client) PatchAnnotations(ctx context.Context, kind, namespace, name string, change k8s.MetadataChange, opts ...CallOption) (*api.MetadataResponse, error) {
+	return c.patchMetadata(ctx, kind, namespace, name, k8s.AnnotationsField, change, opts)
+}
+
+func (c *Client) patchMetadata(ctx context.Context, kind, namespace, name, field string, change k8s.MetadataChange, opts []CallOption) (*api.MetadataResponse, error) {
+	var metadata api.MetadataResponse
+	if err := c.do(ctx, http.MethodPatch, c.endpoint(nil, kind, namespace, name, field), change, &metadata, opts); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// ScaleWorkload sets the replicas of an object of a plural kind, "deployments"
+// or "statefulsets"
+func (c *Client) ScaleWorkload(ctx context.Context, kind, namespace, name string, replicas int32, opts ...CallOption) error {
+	return c.do(ctx, http.MethodPut, c.endpoint(nil, kind, namespace, name, "scale"), api.ScaleRequest{Replicas: &replicas}, nil, opts)
+}
+
+// RestartWorkload restarts the pods of an object of a plural kind,
+// "deployments", "statefulsets" or "daemonsets". The response warns about
+// pods the restart leaves alone.
+func (c *Client) RestartWorkload(ctx context.Context, kind, namespace, name string, opts ...CallOption) (*api.RestartResponse, error) {
+	var restart api.RestartResponse
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, kind, namespace, name, "restart"), nil, &restart, opts); err != nil {
+		return nil, err
+	}
+	return &restart, nil
+}
+
+// ListCRDs lists the CustomResourceDefinitions of the cluster
+func (c *Client) ListCRDs(ctx context.Context, opts ...CallOption) ([]k8s.CRD, error) {
+	var list api.CRDListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(nil, "crds"), nil, &list, opts)
+	return list.CRDs, err
+}
+
+// ListNamespaces lists every namespace, with termination details for those
+// being deleted
+func (c *Client) ListNamespaces(ctx context.Context, opts ...CallOption) ([]api.NamespaceInfo, error) {
+	var list api.NamespaceListResponse
+	err := c.do(ctx, http.MethodGet, c.endpoint(nil, "namespaces"), nil, &list, opts)
+	return list.Namespaces, err
+}
+
+// DeleteNamespace deletes a namespace and everything in it. A namespace
+// annotated deletion-protected needs the ConfirmDelete option.
+func (c *Client) DeleteNamespace(ctx context.Context, name string, opts ...CallOption) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint(nil, "namespaces", name), nil, nil, opts)
+}
+
+// NamespaceFinalizerReport lists every object left in a namespace, grouped by
+// kind, to show what is keeping a terminating namespace around
+func (c *Client) NamespaceFinalizerReport(ctx context.Context, name string, opts ...CallOption) (*k8s.FinalizerReport, error) {
+	var report k8s.FinalizerReport
+	if err := c.do(ctx, http.MethodGet, c.endpoint(nil, "namespaces", name, "finalizer-report"), nil, &report, opts); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// CreateServiceAccountToken requests a short-lived token for a service account
+func (c *Client) CreateServiceAccountToken(ctx context.Context, namespace, name string, request api.TokenRequest, opts ...CallOption) (*api.TokenResponse, error) {
+	var token api.TokenResponse
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "serviceaccounts", namespace, name, "token"), request, &token, opts); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Apply applies a YAML manifest to a namespace, patching the resource if it
+// already exists
+func (c *Client) Apply(ctx context.Context, namespace string, manifest []byte, opts ...CallOption) error {
+	req, err := c.newRequest(ctx, http.MethodPost, c.endpoint(nil, "apply", namespace), "application/yaml", bytes.NewReader(manifest), opts)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ApplyURL has the server fetch the manifest at a URL on one of its allowed
+// hosts and apply it, returning the result of each document
+func (c *Client) ApplyURL(ctx context.Context, request api.ApplyURLRequest, opts ...CallOption) (*api.ApplyURLResponse, error) {
+	var response api.ApplyURLResponse
+	if err := c.do(ctx, http.MethodPost, c.endpoint(nil, "apply", "url"), request, &response, opts); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Bulk runs up to 100 operations in one request, in order or, with parallel,
+// concurrently, returning the result of each. A failed operation does not
+// stop the others.
+func (c *Client) Bulk(ctx context.Context, request api.BulkRequest, parallel bool, opts ...CallOption) ([]api.BulkResult, error) {
+	var query url.Values
+	if parallel {
+		query = url.Values{"parallel": {"true"}}
+	}
+
+	var response api.BulkResponse
+	err := c.do(ctx, http.MethodPost, c.endpoint(query, "bulk"), request, &response, opts)
+	return response.Results, err
+}
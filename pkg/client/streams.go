@@ -0,0 +1,168 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s-dashboard/pkg/api"
+
+	"github.com/gorilla/websocket"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// LogOptions selects the logs returned by GetPodLogs
+type LogOptions struct {
+	// Container defaults to the pod's only container
+	Container string
+	// Follow keeps the stream open for new lines until ctx is done
+	Follow bool
+}
+
+// GetPodLogs returns a pod's logs. The caller must close the returned reader.
+func (c *Client) GetPodLogs(ctx context.Context, namespace, name string, logOpts LogOptions, opts ...CallOption) (io.ReadCloser, error) {
+	query := url.Values{}
+	if logOpts.Container != "" {
+		query.Set("container", logOpts.Container)
+	}
+	if logOpts.Follow {
+		query.Set("follow", "true")
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(query, "pods", namespace, name, "logs"), "", nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// GetDebugContainerLogs returns the logs of an ephemeral container added by
+// DebugPod, following them when follow is set. The caller must close the
+// returned reader.
+func (c *Client) GetDebugContainerLogs(ctx context.Context, namespace, name, container string, follow bool, opts ...CallOption) (io.ReadCloser, error) {
+	query := url.Values{"follow": {fmt.Sprint(follow)}}
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(query, "pods", namespace, name, "debug", container, "logs"), "", nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// WatchPods calls fn for every pod change in a namespace until ctx is done,
+// the server closes the watch, or fn returns an error, which is returned.
+// The Object of an event is a *v1.Pod, or a *metav1.Status for ERROR events.
+func (c *Client) WatchPods(ctx context.Context, namespace string, fn func(api.PodWatchEvent) error, opts ...CallOption) error {
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(namespaceQuery(namespace), "pods", "watch"), "", nil, opts)
+	if err != nil {
+		return err
+	}
+	if _, err := c.CheckVersion(ctx); err != nil {
+		return err
+	}
+	wsURL := *req.URL
+	wsURL.Scheme = strings.Replace(wsURL.Scheme, "http", "ws", 1)
+
+	dialer := websocket.Dialer{TLSClientConfig: c.tlsConfig, Proxy: http.ProxyFromEnvironment}
+	conn, resp, err := dialer.DialContext(ctx, wsURL.String(), req.Header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			return &APIError{StatusCode: resp.StatusCode, Message: err.Error()}
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the read below when ctx is done
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		var message struct {
+			Type   watch.EventType `json:"Type"`
+			Object json.RawMessage `json:"Object"`
+		}
+		if err := conn.ReadJSON(&message); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) || websocket.IsUnexpectedCloseError(err) {
+				return nil
+			}
+			return err
+		}
+
+		var object runtime.Object = &v1.Pod{}
+		if message.Type == watch.Error {
+			object = &metav1.Status{}
+		}
+		if err := json.Unmarshal(message.Object, object); err != nil {
+			return fmt.Errorf("failed to decode %s watch event: %v", message.Type, err)
+		}
+		if err := fn(api.PodWatchEvent{Type: message.Type, Object: object}); err != nil {
+			return err
+		}
+	}
+}
+
+// StreamEvents calls fn for every change to the given resource types in a
+// namespace until ctx is done, the server ends the stream, or fn returns an
+// error, which is returned. No types selects every supported type.
+func (c *Client) StreamEvents(ctx context.Context, namespace string, types []string, fn func(api.StreamEvent) error, opts ...CallOption) error {
+	query := namespaceQuery(namespace)
+	if len(types) > 0 {
+		query.Set("types", strings.Join(types, ","))
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, c.endpoint(query, "events", "stream"), "", nil, opts)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event api.StreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode stream event %q: %v", data, err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
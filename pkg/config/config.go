@@ -1,38 +1,192 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+
+	"k8s-dashboard/pkg/timefmt"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
 )
 
+// configEnvVar names an environment variable pointing at a config file
+const configEnvVar = "KGO_CONFIG"
+
 // Config represents the application configuration
 type Config struct {
 	Server struct {
 		Port     string `yaml:"port" json:"port"`
 		Host     string `yaml:"host" json:"host"`
 		LogLevel string `yaml:"logLevel" json:"logLevel"`
+
+		// ListCoalescing shares identical concurrent list calls between REST
+		// requests; results are reused for ListCoalesceTTLMs milliseconds
+		ListCoalescing    bool `yaml:"listCoalescing" json:"listCoalescing"`
+		ListCoalesceTTLMs int  `yaml:"listCoalesceTTLMs" json:"listCoalesceTTLMs"`
+
+		// EnablePprof serves the Go profiler under /debug/pprof and runtime
+		// stats at /debug/vars, and registers channelz on the gRPC server.
+		// They expose internals of the process, so they are off by default.
+		EnablePprof bool `yaml:"enablePprof" json:"enablePprof"`
+
+		// CompressionLevel is the gzip level of REST responses to clients
+		// accepting gzip, from 1 (fastest) to 9 (smallest); 0 disables it
+		CompressionLevel int `yaml:"compressionLevel" json:"compressionLevel"`
 	} `yaml:"server" json:"server"`
 
 	Kubernetes struct {
 		Kubeconfig string `yaml:"kubeconfig" json:"kubeconfig"`
 		Context    string `yaml:"context" json:"context"`
 		Namespace  string `yaml:"namespace" json:"namespace"`
+
+		// ProtectedNamespaces lists glob patterns of namespaces where mutating
+		// operations require an explicit confirmation
+		ProtectedNamespaces []string `yaml:"protectedNamespaces" json:"protectedNamespaces"`
 	} `yaml:"kubernetes" json:"kubernetes"`
 
 	UI struct {
 		Theme       string `yaml:"theme" json:"theme"`
 		AutoRefresh int    `yaml:"autoRefresh" json:"autoRefresh"`
-		MaxLogs     int    `yaml:"maxLogs" json:"maxLogs"`
+		// MaxLogs is how many lines the TUI's log view keeps, dropping the
+		// oldest beyond it
+		MaxLogs int `yaml:"maxLogs" json:"maxLogs"`
+		// LogTailLines is how many earlier lines the log view starts with;
+		// 'T' in the view changes it
+		LogTailLines int `yaml:"logTailLines" json:"logTailLines"`
+
+		// NamespaceLabelTemplates are the labels offered when creating a
+		// namespace in the TUI, each as key=default value, e.g. "team="
+		NamespaceLabelTemplates []string `yaml:"namespaceLabelTemplates" json:"namespaceLabelTemplates"`
+
+		// LoadRetries is how many times the TUI retries a failed load of a
+		// resource type, waiting LoadRetryBackoffMs before the first retry
+		// and twice as long before each next one; 0 disables retries
+		LoadRetries        int `yaml:"loadRetries" json:"loadRetries"`
+		LoadRetryBackoffMs int `yaml:"loadRetryBackoffMs" json:"loadRetryBackoffMs"`
+		// LoadTimeoutSeconds is how long the TUI waits for a load of a
+		// resource type before giving up on it; 0 waits for as long as it
+		// takes
+		LoadTimeoutSeconds int `yaml:"loadTimeoutSeconds" json:"loadTimeoutSeconds"`
+
+		// RedrawIntervalMs is the shortest time between two redraws caused by
+		// background updates; key presses always redraw at once
+		RedrawIntervalMs int `yaml:"redrawIntervalMs" json:"redrawIntervalMs"`
+
+		// TimestampFormat shows ages and timestamps as "relative" durations,
+		// "absolute" times in Timezone, or "both"; 'z' in the TUI cycles them
+		TimestampFormat string `yaml:"timestampFormat" json:"timestampFormat"`
+		// Timezone is the zone of absolute times, e.g. "UTC", or "Local"
+		Timezone string `yaml:"timezone" json:"timezone"`
+
+		// DebugImage is the image of the debug containers the TUI adds to
+		// pods with 'x', for images without a shell to exec into
+		DebugImage string `yaml:"debugImage" json:"debugImage"`
+
+		// LogFile is where the TUI writes its log instead of stderr, which
+		// would write over the screen; empty is ~/.cache/kgo/kgo.log. It is
+		// moved aside to LogFile.1 once it reaches LogFileMaxSizeMB, 0 for
+		// no limit.
+		LogFile          string `yaml:"logFile" json:"logFile"`
+		LogFileMaxSizeMB int    `yaml:"logFileMaxSizeMB" json:"logFileMaxSizeMB"`
+
+		// KeyBindings is the TUI's key scheme: "vim" moves with j/k and
+		// switches tabs with h/l, "classic" keeps the earlier keys
+		KeyBindings string `yaml:"keyBindings" json:"keyBindings"`
+
+		// PinNameColumn keeps the name column of the TUI's tables on screen
+		// when columns that do not fit are scrolled into view
+		PinNameColumn bool `yaml:"pinNameColumn" json:"pinNameColumn"`
+
+		// MaxScaleReplicas is the end of the replicas slider of the TUI's
+		// scale dialog
+		MaxScaleReplicas int `yaml:"maxScaleReplicas" json:"maxScaleReplicas"`
 	} `yaml:"ui" json:"ui"`
 
 	Features struct {
 		EnableMetrics bool `yaml:"enableMetrics" json:"enableMetrics"`
 		EnableExec    bool `yaml:"enableExec" json:"enableExec"`
 		EnableLogs    bool `yaml:"enableLogs" json:"enableLogs"`
+
+		// EnableTokenCreation allows minting short-lived service account tokens
+		// through the REST API
+		EnableTokenCreation bool `yaml:"enableTokenCreation" json:"enableTokenCreation"`
+
+		// EnableServiceProbing lets the TUI dial a service's cluster IP and
+		// ports, which are only routable when kgo runs inside the cluster
+		EnableServiceProbing bool `yaml:"enableServiceProbing" json:"enableServiceProbing"`
+
+		// EnableDNSResolution lets the TUI look up the external name of
+		// ExternalName services when showing their details
+		EnableDNSResolution bool `yaml:"enableDNSResolution" json:"enableDNSResolution"`
+
+		// EnableRegistryInspection lets the TUI fetch image manifests from
+		// container registries to show the size and layers of pod images
+		EnableRegistryInspection bool `yaml:"enableRegistryInspection" json:"enableRegistryInspection"`
+
+		// AllowedRegistries are the registry hosts images created through
+		// kgo may come from, e.g. "registry.internal:5000"; "docker.io"
+		// allows images naming no registry. Empty allows every registry.
+		AllowedRegistries []string `yaml:"allowedRegistries" json:"allowedRegistries"`
+
+		// AllowedManifestHosts are the hosts POST /api/v1/apply/url fetches
+		// manifests from, e.g. "git.internal" for any of its ports or
+		// "git.internal:8443"; empty disables it. AllowedManifestSchemes are
+		// the URL schemes it accepts from them.
+		AllowedManifestHosts   []string `yaml:"allowedManifestHosts" json:"allowedManifestHosts"`
+		AllowedManifestSchemes []string `yaml:"allowedManifestSchemes" json:"allowedManifestSchemes"`
 	} `yaml:"features" json:"features"`
+
+	Policies struct {
+		// RequiredAnnotations must be set, and not empty, on every object
+		// created through the REST API outside kube-system, e.g. "owner"
+		RequiredAnnotations []string `yaml:"requiredAnnotations" json:"requiredAnnotations"`
+	} `yaml:"policies" json:"policies"`
+
+	Crypto struct {
+		// Backend is the SOPS key backend encrypting the values of configmaps
+		// created through the REST API with an X-Encrypt: sops header, "age"
+		// or "kms"; empty rejects such requests
+		Backend string `yaml:"backend" json:"backend"`
+		// AgeRecipients are the age public keys values are encrypted for
+		// with the age backend
+		AgeRecipients []string `yaml:"ageRecipients" json:"ageRecipients"`
+		// KMSKeys are the ARNs of the AWS KMS keys values are encrypted for
+		// with the kms backend. Values are encrypted and decrypted with the
+		// AWS credentials of the environment.
+		KMSKeys []string `yaml:"kmsKeys" json:"kmsKeys"`
+		// AgeKeyFile is an age key file, as written by age-keygen, whose
+		// identities the REST API and the TUI decrypt values with, with or
+		// without a backend; empty shows them encrypted
+		AgeKeyFile string `yaml:"ageKeyFile" json:"ageKeyFile"`
+	} `yaml:"crypto" json:"crypto"`
+
+	Alerts struct {
+		Rules           []AlertRule `yaml:"rules" json:"rules"`
+		CooldownSeconds int         `yaml:"cooldownSeconds" json:"cooldownSeconds"`
+
+		// Command is run through sh with the alert event as JSON on stdin. It
+		// often embeds webhook tokens, so it is masked when printed.
+		Command string `yaml:"command" json:"command" secret:"true"`
+	} `yaml:"alerts" json:"alerts"`
+
+	// source is the file the config was loaded from, and lines maps the
+	// dotted path of each key in it to its line number
+	source string
+	lines  map[string]int
+}
+
+// AlertRule describes a condition the TUI alerts on, e.g. kind "pod" with
+// condition "restarts>5"
+type AlertRule struct {
+	Name       string   `yaml:"name" json:"name"`
+	Kind       string   `yaml:"kind" json:"kind"`
+	Condition  string   `yaml:"condition" json:"condition"`
+	Namespaces []string `yaml:"namespaces" json:"namespaces"`
 }
 
 // DefaultConfig returns a default configuration
@@ -43,42 +197,71 @@ func DefaultConfig() *Config {
 	config.Server.Port = "8080"
 	config.Server.Host = "0.0.0.0"
 	config.Server.LogLevel = "info"
+	config.Server.ListCoalescing = true
+	config.Server.ListCoalesceTTLMs = 1000
+	config.Server.CompressionLevel = 6
 
 	// Kubernetes defaults
 	config.Kubernetes.Kubeconfig = ""
 	config.Kubernetes.Context = ""
 	config.Kubernetes.Namespace = "default"
+	config.Kubernetes.ProtectedNamespaces = []string{"kube-system"}
 
 	// UI defaults
 	config.UI.Theme = "dark"
 	config.UI.AutoRefresh = 30
 	config.UI.MaxLogs = 1000
+	config.UI.LogTailLines = 100
+	config.UI.NamespaceLabelTemplates = []string{"team=", "env="}
+	config.UI.RedrawIntervalMs = 200
+	config.UI.LoadRetries = 3
+	config.UI.LoadRetryBackoffMs = 500
+	config.UI.LoadTimeoutSeconds = 15
+	config.UI.TimestampFormat = timefmt.Relative
+	config.UI.Timezone = "Local"
+	config.UI.DebugImage = "busybox:1.36"
+	config.UI.LogFileMaxSizeMB = 10
+	config.UI.KeyBindings = KeyBindingsVim
+	config.UI.PinNameColumn = true
+	config.UI.MaxScaleReplicas = 50
 
 	// Features defaults
 	config.Features.EnableMetrics = true
 	config.Features.EnableExec = true
 	config.Features.EnableLogs = true
+	config.Features.EnableTokenCreation = false
+	config.Features.EnableServiceProbing = false
+	config.Features.EnableDNSResolution = false
+	config.Features.EnableRegistryInspection = false
+	config.Features.AllowedManifestSchemes = []string{"https"}
+
+	// Alerts defaults
+	config.Alerts.CooldownSeconds = 300
 
 	return config
 }
 
-// LoadConfig loads configuration from file
+// LoadConfig loads configuration from file. An explicit path, or else the file
+// named by KGO_CONFIG, must exist; otherwise the first file found in the
+// search paths is used, and defaults when there is none.
 func LoadConfig(configPath string) (*Config, error) {
 	config := DefaultConfig()
 
 	if configPath == "" {
-		// Try to find config file in current directory or home directory
-		possiblePaths := []string{
-			"./kgo.yaml",
-			"./kgo.yml",
-			"./config.yaml",
-			"./config.yml",
-			filepath.Join(os.Getenv("HOME"), ".kgo.yaml"),
-			filepath.Join(os.Getenv("HOME"), ".kgo.yml"),
-			filepath.Join(os.Getenv("HOME"), ".config", "kgo", "config.yaml"),
-		}
+		configPath = os.Getenv(configEnvVar)
+	}
 
-		for _, path := range possiblePaths {
+	if configPath != "" {
+		// An explicitly requested file must exist, so that a typo in the
+		// path is not silently replaced by defaults
+		if _, err := os.Stat(configPath); err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("config file %s does not exist", configPath)
+			}
+			return nil, fmt.Errorf("failed to access config file %s: %v", configPath, err)
+		}
+	} else {
+		for _, path := range searchPaths() {
 			if _, err := os.Stat(path); err == nil {
 				configPath = path
 				break
@@ -87,20 +270,174 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	if configPath != "" {
+		klog.Infof("Using config file %s", configPath)
+
 		data, err := os.ReadFile(configPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read config file %s: %v", configPath, err)
 		}
 
-		// Try YAML first
-		if err := yaml.Unmarshal(data, config); err != nil {
+		config.source = configPath
+		if err := config.parse(data); err != nil {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				return nil, validationErr
+			}
 			return nil, fmt.Errorf("failed to parse config file %s: %v", configPath, err)
 		}
 	}
 
+	config.applyEnv()
 	return config, nil
 }
 
+// parse decodes YAML config data over the current values. Unknown keys are
+// rejected, since a typo would otherwise silently leave the default in place.
+func (c *Config) parse(data []byte) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if len(root.Content) == 0 {
+		// Empty file
+		return nil
+	}
+
+	c.lines = make(map[string]int)
+	var problems []*FieldError
+	checkKeys(&root, reflect.TypeOf(*c), "", c.lines, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{File: c.source, Errors: problems}
+	}
+	return root.Decode(c)
+}
+
+// envOverrides are environment variables applied over the config file
+var envOverrides = []struct {
+	name    string
+	keyPath string
+	apply   func(c *Config, value string)
+}{
+	{"KGO_PORT", "server.port", func(c *Config, v string) { c.Server.Port = v }},
+	{"KGO_LOG_LEVEL", "server.logLevel", func(c *Config, v string) { c.Server.LogLevel = v }},
+	{"KGO_KUBECONFIG", "kubernetes.kubeconfig", func(c *Config, v string) { c.Kubernetes.Kubeconfig = v }},
+	{"KGO_CONTEXT", "kubernetes.context", func(c *Config, v string) { c.Kubernetes.Context = v }},
+	{"KGO_NAMESPACE", "kubernetes.namespace", func(c *Config, v string) { c.Kubernetes.Namespace = v }},
+}
+
+// applyEnv applies the set environment overrides. Overridden keys no longer
+// come from the file, so their line numbers are dropped.
+func (c *Config) applyEnv() {
+	for _, override := range envOverrides {
+		if value := os.Getenv(override.name); value != "" {
+			override.apply(c, value)
+			delete(c.lines, override.keyPath)
+		}
+	}
+}
+
+// ApplyFlags applies command line overrides; empty values are ignored
+func (c *Config) ApplyFlags(kubeconfig, port string) {
+	if kubeconfig != "" {
+		c.Kubernetes.Kubeconfig = kubeconfig
+		delete(c.lines, "kubernetes.kubeconfig")
+	}
+	if port != "" {
+		c.Server.Port = port
+		delete(c.lines, "server.port")
+	}
+}
+
+// Source returns the config file the configuration was loaded from, or an
+// empty string when only defaults were used
+func (c *Config) Source() string {
+	return c.source
+}
+
+// searchPaths lists the locations checked for a config file when none is given,
+// in order. Home and config directories that cannot be determined, e.g. when
+// HOME is unset in a container, are skipped.
+func searchPaths() []string {
+	paths := []string{"./kgo.yaml", "./kgo.yml", "./config.yaml", "./config.yml"}
+
+	home, homeErr := os.UserHomeDir()
+	if homeErr == nil {
+		paths = append(paths, filepath.Join(home, ".kgo.yaml"), filepath.Join(home, ".kgo.yml"))
+	}
+
+	// XDG_CONFIG_HOME is honored on every platform, not only where it is
+	// the native config directory
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		paths = append(paths, filepath.Join(xdg, "kgo", "config.yaml"))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "kgo", "config.yaml"))
+	}
+	if homeErr == nil {
+		// Kept for compatibility where UserConfigDir is not ~/.config
+		paths = append(paths, filepath.Join(home, ".config", "kgo", "config.yaml"))
+	}
+
+	// Drop duplicates, e.g. when UserConfigDir is XDG_CONFIG_HOME
+	seen := make(map[string]bool, len(paths))
+	unique := paths[:0]
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+	return unique
+}
+
+// secretMask replaces secret values in printed configs
+const secretMask = "********"
+
+// WriteEffective writes the resolved configuration as YAML, with fields
+// tagged secret:"true" masked
+func (c *Config) WriteEffective(w io.Writer) error {
+	masked := *c
+	maskSecrets(reflect.ValueOf(&masked).Elem())
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&masked); err != nil {
+		return fmt.Errorf("failed to marshal config: %v", err)
+	}
+	return encoder.Close()
+}
+
+// maskSecrets masks every non-empty secret string field in v. Slices are
+// copied before their elements are touched so the original config is intact.
+func maskSecrets(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get("secret") == "true" && field.Type.Kind() == reflect.String {
+				if v.Field(i).String() != "" {
+					v.Field(i).SetString(secretMask)
+				}
+				continue
+			}
+			maskSecrets(v.Field(i))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		v.Set(copied)
+		for i := 0; i < v.Len(); i++ {
+			maskSecrets(v.Index(i))
+		}
+	}
+}
+
 // SaveConfig saves configuration to file
 func (c *Config) SaveConfig(configPath string) error {
 	if configPath == "" {
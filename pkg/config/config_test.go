@@ -20,6 +20,10 @@ func TestDefaultConfig(t *testing.T) {
 	if !config.Features.EnableMetrics {
 		t.Error("Expected metrics to be enabled by default")
 	}
+
+	if config.Features.EnableServiceProbing {
+		t.Error("Expected service probing to be disabled by default")
+	}
 }
 
 func TestLoadConfig(t *testing.T) {
@@ -93,3 +97,77 @@ func TestSaveConfig(t *testing.T) {
 		t.Errorf("Expected saved theme light, got %s", loadedConfig.UI.Theme)
 	}
 }
+
+func TestLoadConfigExplicitPathMissing(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "typo.yaml")
+
+	if _, err := LoadConfig(missing); err == nil {
+		t.Error("Expected an error for a missing explicit config path")
+	}
+
+	t.Setenv("KGO_CONFIG", missing)
+	if _, err := LoadConfig(""); err == nil {
+		t.Error("Expected an error when KGO_CONFIG points at a missing file")
+	}
+}
+
+func TestLoadConfigEnvOverride(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "env-config.yaml")
+	if err := os.WriteFile(configPath, []byte("server:\n  port: \"7070\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	t.Setenv("KGO_CONFIG", configPath)
+
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Server.Port != "7070" {
+		t.Errorf("Expected port from KGO_CONFIG file, got %s", config.Server.Port)
+	}
+
+	// An explicit path still wins over the environment
+	explicitPath := filepath.Join(t.TempDir(), "explicit.yaml")
+	if err := os.WriteFile(explicitPath, []byte("server:\n  port: \"6060\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	config, err = LoadConfig(explicitPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Server.Port != "6060" {
+		t.Errorf("Expected port from explicit path, got %s", config.Server.Port)
+	}
+}
+
+func TestLoadConfigXDGConfigHome(t *testing.T) {
+	xdg := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(xdg, "kgo"), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(xdg, "kgo", "config.yaml"), []byte("server:\n  port: \"5050\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	t.Setenv("KGO_CONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Server.Port != "5050" {
+		t.Errorf("Expected port from XDG_CONFIG_HOME config, got %s", config.Server.Port)
+	}
+}
+
+func TestSearchPathsWithoutHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, path := range searchPaths() {
+		if base := filepath.Base(path); base == ".kgo.yaml" || base == ".kgo.yml" {
+			t.Errorf("Expected no home config paths when HOME is unset, got %q", path)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s-dashboard/pkg/crypto"
+	"k8s-dashboard/pkg/timefmt"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// logLevels are the accepted values of server.logLevel
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// The key schemes of ui.keyBindings
+const (
+	KeyBindingsVim     = "vim"
+	KeyBindingsClassic = "classic"
+)
+
+// FieldError is a problem with a single config key. Line is the line in the
+// config file the key came from, or 0 when it was not set by a file.
+type FieldError struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationError lists every problem found in a config, one per line in
+// the form file:line: key: message
+type ValidationError struct {
+	File   string
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		switch {
+		case e.File != "" && err.Line > 0:
+			messages = append(messages, fmt.Sprintf("%s:%d: %s: %s", e.File, err.Line, err.Path, err.Message))
+		case e.File != "":
+			messages = append(messages, fmt.Sprintf("%s: %s: %s", e.File, err.Path, err.Message))
+		default:
+			messages = append(messages, err.Error())
+		}
+	}
+	return strings.Join(messages, "\n")
+}
+
+// checkKeys reports every key in node without a matching field in t and
+// records the line of each known key by its dotted path
+func checkKeys(node *yaml.Node, t reflect.Type, keyPath string, lines map[string]int, problems *[]*FieldError) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			checkKeys(child, t, keyPath, lines, problems)
+		}
+		return
+	case yaml.AliasNode:
+		node = node.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Kind mismatches such as a list where a map is expected are left to the
+	// decoder, which reports them with their line
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childPath := joinKey(keyPath, key.Value)
+			field, ok := fields[key.Value]
+			if !ok {
+				message := "unknown key"
+				if suggestion := suggestKey(key.Value, fields); suggestion != "" {
+					message += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				*problems = append(*problems, &FieldError{Path: childPath, Line: key.Line, Message: message})
+				continue
+			}
+			lines[childPath] = key.Line
+			checkKeys(value, field.Type, childPath, lines, problems)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			itemPath := fmt.Sprintf("%s[%d]", keyPath, i)
+			lines[itemPath] = item.Line
+			checkKeys(item, t.Elem(), itemPath, lines, problems)
+		}
+	}
+}
+
+// yamlFields maps the YAML keys of a struct type to its fields
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// joinKey appends a key to a dotted path
+func joinKey(keyPath, key string) string {
+	if keyPath == "" {
+		return key
+	}
+	return keyPath + "." + key
+}
+
+// suggestKey returns the known key closest to an unknown one, if it is close
+// enough to be a likely typo
+func suggestKey(key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	if bestDistance > 2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Damerau-Levenshtein distance (with adjacent
+// transpositions) between two strings
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	d := make([][]int, len(ar)+1)
+	for i := range d {
+		d[i] = make([]int, len(br)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ar)][len(br)]
+}
+
+// Validate checks that config values are within their allowed ranges. It
+// returns a *ValidationError listing every problem, or nil.
+func (c *Config) Validate() error {
+	var problems []*FieldError
+	report := func(keyPath, format string, args ...interface{}) {
+		problems = append(problems, &FieldError{Path: keyPath, Line: c.lines[keyPath], Message: fmt.Sprintf(format, args...)})
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		report("server.port", "must be a port number between 1 and 65535, got %q", c.Server.Port)
+	}
+	validLevel := false
+	for _, level := range logLevels {
+		if c.Server.LogLevel == level {
+			validLevel = true
+		}
+	}
+	if !validLevel {
+		report("server.logLevel", "must be one of %s, got %q", strings.Join(logLevels, ", "), c.Server.LogLevel)
+	}
+	if c.Server.ListCoalesceTTLMs < 0 {
+		report("server.listCoalesceTTLMs", "must not be negative, got %d", c.Server.ListCoalesceTTLMs)
+	}
+	if c.Server.CompressionLevel < 0 || c.Server.CompressionLevel > 9 {
+		report("server.compressionLevel", "must be between 0 (off) and 9, got %d", c.Server.CompressionLevel)
+	}
+
+	for i, pattern := range c.Kubernetes.ProtectedNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			report(fmt.Sprintf("kubernetes.protectedNamespaces[%d]", i), "invalid glob %q", pattern)
+		}
+	}
+
+	if c.UI.AutoRefresh < 0 {
+		report("ui.autoRefresh", "must not be negative, got %d", c.UI.AutoRefresh)
+	}
+	if c.UI.MaxLogs <= 0 {
+		report("ui.maxLogs", "must be positive, got %d", c.UI.MaxLogs)
+	}
+	if c.UI.LogTailLines <= 0 {
+		report("ui.logTailLines", "must be positive, got %d", c.UI.LogTailLines)
+	}
+	if c.UI.LoadRetries < 0 {
+		report("ui.loadRetries", "must not be negative, got %d", c.UI.LoadRetries)
+	}
+	if c.UI.LoadRetryBackoffMs < 0 {
+		report("ui.loadRetryBackoffMs", "must not be negative, got %d", c.UI.LoadRetryBackoffMs)
+	}
+	if c.UI.LoadTimeoutSeconds < 0 {
+		report("ui.loadTimeoutSeconds", "must not be negative, got %d", c.UI.LoadTimeoutSeconds)
+	}
+	if c.UI.RedrawIntervalMs <= 0 {
+		report("ui.redrawIntervalMs", "must be positive, got %d", c.UI.RedrawIntervalMs)
+	}
+	if !timefmt.Valid(c.UI.TimestampFormat) {
+		report("ui.timestampFormat", "must be one of %v, got %q", timefmt.Formats, c.UI.TimestampFormat)
+	}
+	if _, err := timefmt.LoadLocation(c.UI.Timezone); err != nil {
+		report("ui.timezone", "unknown time zone %q", c.UI.Timezone)
+	}
+	if c.UI.DebugImage == "" || strings.ContainsAny(c.UI.DebugImage, " \t") {
+		report("ui.debugImage", "must be an image such as busybox:1.36, got %q", c.UI.DebugImage)
+	}
+	if c.UI.LogFileMaxSizeMB < 0 {
+		report("ui.logFileMaxSizeMB", "must not be negative, got %d", c.UI.LogFileMaxSizeMB)
+	}
+	if c.UI.KeyBindings != KeyBindingsVim && c.UI.KeyBindings != KeyBindingsClassic {
+		report("ui.keyBindings", "must be %s or %s, got %q", KeyBindingsVim, KeyBindingsClassic, c.UI.KeyBindings)
+	}
+	if c.UI.MaxScaleReplicas <= 0 {
+		report("ui.maxScaleReplicas", "must be positive, got %d", c.UI.MaxScaleReplicas)
+	}
+	for i, template := range c.UI.NamespaceLabelTemplates {
+		key, value, ok := strings.Cut(template, "=")
+		keyPath := fmt.Sprintf("ui.namespaceLabelTemplates[%d]", i)
+		switch {
+		case !ok:
+			report(keyPath, "must be key=default value, got %q", template)
+		case len(validation.IsQualifiedName(key)) > 0:
+			report(keyPath, "invalid label key %q: %s", key, validation.IsQualifiedName(key)[0])
+		case len(validation.IsValidLabelValue(value)) > 0:
+			report(keyPath, "invalid label value %q: %s", value, validation.IsValidLabelValue(value)[0])
+		}
+	}
+
+	for i, registry := range c.Features.AllowedRegistries {
+		if registry == "" || strings.ContainsAny(registry, "/@ \t") {
+			report(fmt.Sprintf("features.allowedRegistries[%d]", i), "must be a registry host such as registry.internal:5000, got %q", registry)
+		}
+	}
+
+	for i, host := range c.Features.AllowedManifestHosts {
+		if host == "" || strings.ContainsAny(host, "/@ \t") {
+			report(fmt.Sprintf("features.allowedManifestHosts[%d]", i), "must be a host such as git.internal or git.internal:8443, got %q", host)
+		}
+	}
+	for i, scheme := range c.Features.AllowedManifestSchemes {
+		if scheme != "http" && scheme != "https" {
+			report(fmt.Sprintf("features.allowedManifestSchemes[%d]", i), "must be http or https, got %q", scheme)
+		}
+	}
+
+	switch c.Crypto.Backend {
+	case "":
+	case crypto.BackendAge:
+		if len(c.Crypto.AgeRecipients) == 0 {
+			report("crypto.ageRecipients", "must list the age recipients values are encrypted for")
+		}
+		for i, recipient := range c.Crypto.AgeRecipients {
+			if err := crypto.ValidateRecipient(crypto.BackendAge, recipient); err != nil {
+				report(fmt.Sprintf("crypto.ageRecipients[%d]", i), "must be an age public key such as age1..., got %q", recipient)
+			}
+		}
+	case crypto.BackendKMS:
+		if len(c.Crypto.KMSKeys) == 0 {
+			report("crypto.kmsKeys", "must list the AWS KMS keys values are encrypted for")
+		}
+		for i, arn := range c.Crypto.KMSKeys {
+			if !crypto.IsKMSKey(arn) {
+				report(fmt.Sprintf("crypto.kmsKeys[%d]", i), "must be an AWS KMS key ARN such as arn:aws:kms:us-east-1:123456789012:key/..., got %q", arn)
+			}
+		}
+	default:
+		report("crypto.backend", "must be %s, %s or empty, got %q", crypto.BackendAge, crypto.BackendKMS, c.Crypto.Backend)
+	}
+
+	if c.Alerts.CooldownSeconds < 0 {
+		report("alerts.cooldownSeconds", "must not be negative, got %d", c.Alerts.CooldownSeconds)
+	}
+	for i, rule := range c.Alerts.Rules {
+		if rule.Kind == "" {
+			report(fmt.Sprintf("alerts.rules[%d]", i), "kind is required")
+		}
+		if rule.Condition == "" {
+			report(fmt.Sprintf("alerts.rules[%d]", i), "condition is required")
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{File: c.source, Errors: problems}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to a config file in a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "kgo.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	return configPath
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	configPath := writeConfig(t, `server:
+  port: "9090"
+  prot: "9091"
+features:
+  enalbeMetrics: false
+alerts:
+  rules:
+    - kind: pod
+      conditon: "restarts>5"
+bogus: true
+`)
+
+	_, err := LoadConfig(configPath)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+
+	expected := []FieldError{
+		{Path: "server.prot", Line: 3, Message: `unknown key, did you mean "port"?`},
+		{Path: "features.enalbeMetrics", Line: 5, Message: `unknown key, did you mean "enableMetrics"?`},
+		{Path: "alerts.rules[0].conditon", Line: 9, Message: `unknown key, did you mean "condition"?`},
+		{Path: "bogus", Line: 10, Message: "unknown key"},
+	}
+	if len(validationErr.Errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), err)
+	}
+	for i, want := range expected {
+		if *validationErr.Errors[i] != want {
+			t.Errorf("Error %d: expected %+v, got %+v", i, want, *validationErr.Errors[i])
+		}
+	}
+
+	if !strings.HasPrefix(err.Error(), configPath+":3: server.prot: unknown key") {
+		t.Errorf("Expected file:line prefixed message, got %q", err.Error())
+	}
+}
+
+func TestLoadConfigReportsTypeErrors(t *testing.T) {
+	configPath := writeConfig(t, "ui:\n  maxLogs: lots\n")
+
+	_, err := LoadConfig(configPath)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected a type error with its line, got %v", err)
+	}
+}
+
+func TestLoadConfigEmptyFile(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, ""))
+	if err != nil {
+		t.Fatalf("Expected empty config file to load, got %v", err)
+	}
+	if config.Server.Port != "8080" {
+		t.Errorf("Expected defaults for empty file, got port %s", config.Server.Port)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Expected default config to be valid, got %v", err)
+	}
+
+	configPath := writeConfig(t, `server:
+  port: "99999"
+  logLevel: verbose
+  compressionLevel: 10
+ui:
+  maxLogs: 0
+  logTailLines: -5
+  namespaceLabelTemplates: ["team=", "env", "Bad Key=x"]
+  redrawIntervalMs: 0
+  timestampFormat: iso
+  timezone: Mars/Olympus_Mons
+  debugImage: ""
+  keyBindings: emacs
+  maxScaleReplicas: 0
+  loadRetries: -1
+  loadTimeoutSeconds: -1
+  logFileMaxSizeMB: -1
+kubernetes:
+  protectedNamespaces: ["kube-system", "prod-["]
+alerts:
+  rules:
+    - name: incomplete
+features:
+  allowedRegistries: ["registry.internal:5000", "https://registry.internal/team"]
+  allowedManifestHosts: ["git.internal", "https://git.internal/ops"]
+  allowedManifestSchemes: ["https", "file"]
+crypto:
+  backend: age
+  ageRecipients: ["age1bogus"]
+`)
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	err = config.Validate()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+
+	lines := make(map[string]int)
+	for _, fieldErr := range validationErr.Errors {
+		lines[fieldErr.Path] = fieldErr.Line
+	}
+	expected := map[string]int{
+		"server.port":                        2,
+		"server.logLevel":                    3,
+		"server.compressionLevel":            4,
+		"ui.maxLogs":                         6,
+		"ui.logTailLines":                    7,
+		"ui.namespaceLabelTemplates[1]":      8,
+		"ui.namespaceLabelTemplates[2]":      8,
+		"ui.redrawIntervalMs":                9,
+		"ui.timestampFormat":                 10,
+		"ui.timezone":                        11,
+		"ui.debugImage":                      12,
+		"ui.keyBindings":                     13,
+		"ui.maxScaleReplicas":                14,
+		"ui.loadRetries":                     15,
+		"ui.loadTimeoutSeconds":              16,
+		"ui.logFileMaxSizeMB":                17,
+		"kubernetes.protectedNamespaces[1]":  19,
+		"alerts.rules[0]":                    22,
+		"features.allowedRegistries[1]":      24,
+		"features.allowedManifestHosts[1]":   25,
+		"features.allowedManifestSchemes[1]": 26,
+		"crypto.ageRecipients[0]":            29,
+	}
+	for path, line := range expected {
+		if got, ok := lines[path]; !ok || got != line {
+			t.Errorf("Expected error for %s at line %d, got %v", path, line, validationErr.Errors)
+		}
+	}
+
+	for backend, path := range map[string]string{"kms": "crypto.kmsKeys", "vault": "crypto.backend", "age": "crypto.ageRecipients"} {
+		config := DefaultConfig()
+		config.Crypto.Backend = backend
+		if err := config.Validate(); err == nil || !strings.Contains(err.Error(), path+":") {
+			t.Errorf("Expected an error for %s with backend %s, got %v", path, backend, err)
+		}
+	}
+
+	// A value overridden by a flag is no longer attributed to the file
+	config.ApplyFlags("", "0")
+	validationErr = nil
+	if errors.As(config.Validate(), &validationErr) {
+		for _, fieldErr := range validationErr.Errors {
+			if fieldErr.Path == "server.port" && fieldErr.Line != 0 {
+				t.Errorf("Expected flag override to have no line, got %d", fieldErr.Line)
+			}
+		}
+	}
+}
+
+func TestWriteEffectiveResolvesAllSources(t *testing.T) {
+	configPath := writeConfig(t, `server:
+  port: "9090"
+  logLevel: debug
+kubernetes:
+  namespace: from-file
+alerts:
+  command: "curl -H 'Authorization: Bearer s3cret' https://hooks.example.com"
+  rules:
+    - kind: pod
+      condition: phase=Failed
+`)
+	t.Setenv("KGO_CONFIG", "")
+	t.Setenv("KGO_NAMESPACE", "from-env")
+	t.Setenv("KGO_PORT", "7070")
+
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	config.ApplyFlags("/tmp/kubeconfig", "6060")
+
+	var out strings.Builder
+	if err := config.WriteEffective(&out); err != nil {
+		t.Fatalf("WriteEffective failed: %v", err)
+	}
+	printed := out.String()
+
+	for _, want := range []string{
+		`port: "6060"`,                // flag beats env and file
+		"namespace: from-env",         // env beats file
+		"logLevel: debug",             // file beats default
+		"host: 0.0.0.0",               // default
+		"kubeconfig: /tmp/kubeconfig", // flag
+		"cooldownSeconds: 300",        // nested default
+		"condition: phase=Failed",     // nested list from file
+		`command: '********'`,         // secret masked
+	} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("Expected %q in effective config:\n%s", want, printed)
+		}
+	}
+	if strings.Contains(printed, "s3cret") {
+		t.Errorf("Expected secret to be masked:\n%s", printed)
+	}
+	if !strings.Contains(config.Alerts.Command, "s3cret") {
+		t.Error("Expected masking not to modify the config itself")
+	}
+	if config.Source() != configPath {
+		t.Errorf("Expected source %s, got %s", configPath, config.Source())
+	}
+}
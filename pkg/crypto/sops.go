@@ -0,0 +1,268 @@
+// Package crypto encrypts values at rest as SOPS documents with
+// https://github.com/getsops/sops, in the format of its binary files, with the
+// data key encrypted for age recipients or AWS KMS keys.
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/getsops/sops/v3/kms"
+	"github.com/getsops/sops/v3/stores/json"
+	"github.com/getsops/sops/v3/version"
+	"google.golang.org/grpc"
+)
+
+// SOPS key backends
+const (
+	BackendAge = "age"
+	BackendKMS = "kms"
+)
+
+// sopsDataKey is the key holding the value in SOPS documents of binary
+// files, which is how values are encrypted
+const sopsDataKey = "data"
+
+// ErrNotEncrypted is returned when decrypting a value that is not a SOPS
+// document from Encrypt
+var ErrNotEncrypted = errors.New("not a SOPS document")
+
+// ErrNoIdentityMatch is returned when none of the age identities can decrypt
+// the data key of a document, e.g. values encrypted for another recipient
+var ErrNoIdentityMatch = errors.New("no identity matched any of the recipients")
+
+// kmsARN matches the AWS KMS key ARNs SOPS accepts, see IsKMSKey
+var kmsARN = regexp.MustCompile(`^arn:aws[\w-]*:kms:(.+):[0-9]+:(key|alias)/.+$`)
+
+// Keys are the keys the values of a backend are encrypted for and decrypted
+// with
+type Keys struct {
+	Backend string
+	// Encrypt is the key Encrypt is called with, age recipients or KMS key
+	// ARNs; empty when there is no backend to encrypt with
+	Encrypt string
+	// Decrypt is the key Decrypt is called with, age identities; empty
+	// leaves values encrypted for age recipients as they are
+	Decrypt string
+}
+
+// CanDecrypt reports whether values can be decrypted: with age identities,
+// or through AWS KMS with the kms backend
+func (k *Keys) CanDecrypt() bool {
+	return k != nil && (k.Decrypt != "" || k.Backend == BackendKMS)
+}
+
+// LoadKeys returns the keys of a backend: the recipients values are
+// encrypted for, age public keys or KMS key ARNs, and the age identities of
+// keyFile decrypting them. KMS keys decrypt with the AWS credentials of the
+// environment. Without a backend nothing is encrypted, but values are still
+// decrypted with keyFile; without either, LoadKeys returns nil.
+func LoadKeys(backend string, recipients []string, keyFile string) (*Keys, error) {
+	switch backend {
+	case "":
+		if keyFile == "" {
+			return nil, nil
+		}
+	case BackendAge, BackendKMS:
+		if len(recipients) == 0 {
+			return nil, fmt.Errorf("no %s recipients", backend)
+		}
+	default:
+		return nil, fmt.Errorf("unknown backend %q", backend)
+	}
+
+	for _, recipient := range recipients {
+		if err := ValidateRecipient(backend, recipient); err != nil {
+			return nil, err
+		}
+	}
+	keys := &Keys{Backend: backend, Encrypt: strings.Join(recipients, ",")}
+	if keyFile != "" {
+		identities, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := age.ParseIdentities(strings.NewReader(string(identities))); err != nil {
+			return nil, fmt.Errorf("%s: %w", keyFile, err)
+		}
+		keys.Decrypt = string(identities)
+	}
+	return keys, nil
+}
+
+// ValidateRecipient checks a recipient of a backend: an age public key
+// ("age1...") or an AWS KMS key ARN
+func ValidateRecipient(backend, recipient string) error {
+	if backend == BackendKMS {
+		if !IsKMSKey(recipient) {
+			return fmt.Errorf("malformed AWS KMS key ARN %q", recipient)
+		}
+		return nil
+	}
+	_, err := age.ParseX25519Recipient(recipient)
+	return err
+}
+
+// IsKMSKey reports whether a key is an AWS KMS key ARN, e.g.
+// "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+func IsKMSKey(key string) bool {
+	return kmsARN.MatchString(key)
+}
+
+// binaryStore reads and writes the SOPS documents of binary files
+func binaryStore() *json.BinaryStore {
+	return json.NewBinaryStore(&config.JSONBinaryStoreConfig{})
+}
+
+// Encrypt encrypts plaintext into a SOPS document for key: one or more
+// comma-separated age recipients ("age1...") or AWS KMS key ARNs
+func Encrypt(key string, plaintext []byte) ([]byte, error) {
+	var group sops.KeyGroup
+	for _, recipient := range strings.Split(key, ",") {
+		recipient = strings.TrimSpace(recipient)
+		if IsKMSKey(recipient) {
+			group = append(group, kms.NewMasterKeyFromArn(recipient, nil, ""))
+			continue
+		}
+		masterKey, err := sopsage.MasterKeyFromRecipient(recipient)
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, masterKey)
+	}
+
+	store := binaryStore()
+	branches, err := store.LoadPlainFile(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	tree := sops.Tree{Branches: branches, Metadata: sops.Metadata{
+		KeyGroups:         []sops.KeyGroup{group},
+		UnencryptedSuffix: sops.DefaultUnencryptedSuffix,
+		Version:           version.Version,
+	}}
+	dataKey, errs := tree.GenerateDataKey()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("failed to encrypt the data key: %w", errors.Join(errs...))
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Encrypt(dataKey, cipher)
+	if err != nil {
+		return nil, err
+	}
+	tree.Metadata.LastModified = time.Now().UTC()
+	tree.Metadata.MessageAuthenticationCode, err = cipher.Encrypt(mac, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt the MAC: %w", err)
+	}
+	return store.EmitEncryptedFile(tree)
+}
+
+// Decrypt decrypts a SOPS document from Encrypt, or from sops encrypting a
+// binary file, with key: age identities ("AGE-SECRET-KEY-1..."), one per line
+// as in an age key file. Data keys encrypted for KMS keys are decrypted with
+// the AWS credentials of the environment.
+func Decrypt(key string, ciphertext []byte) ([]byte, error) {
+	tree, err := loadDocument(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	service := &identityKeyService{KeyServiceClient: keyservice.NewLocalClient()}
+	if key != "" {
+		if err := service.identities.Import(key); err != nil {
+			return nil, err
+		}
+	}
+
+	dataKey, err := tree.Metadata.GetDataKeyWithKeyServices([]keyservice.KeyServiceClient{service}, nil)
+	if err != nil {
+		if service.noMatch {
+			return nil, fmt.Errorf("%w: %v", ErrNoIdentityMatch, err)
+		}
+		return nil, err
+	}
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(dataKey, cipher)
+	if err != nil {
+		return nil, err
+	}
+	documentMAC, err := cipher.Decrypt(tree.Metadata.MessageAuthenticationCode, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the MAC: %w", err)
+	}
+	if documentMAC != mac {
+		return nil, errors.New("MAC mismatch: the document was modified")
+	}
+	return binaryStore().EmitPlainFile(tree.Branches)
+}
+
+// IsEncrypted reports whether a value is a SOPS document from Encrypt: a
+// binary file's document with its only value encrypted, a MAC, and at least
+// one key to decrypt them with
+func IsEncrypted(value []byte) bool {
+	_, err := loadDocument(value)
+	return err == nil
+}
+
+// loadDocument parses a SOPS document from Encrypt, or returns
+// ErrNotEncrypted
+func loadDocument(value []byte) (sops.Tree, error) {
+	tree, err := binaryStore().LoadEncryptedFile(value)
+	if err != nil {
+		return sops.Tree{}, ErrNotEncrypted
+	}
+	if len(tree.Branches) != 1 || len(tree.Branches[0]) != 1 || tree.Branches[0][0].Key != sopsDataKey {
+		return sops.Tree{}, ErrNotEncrypted
+	}
+	if data, ok := tree.Branches[0][0].Value.(string); !ok || !strings.HasPrefix(data, "ENC[AES256_GCM,") {
+		return sops.Tree{}, ErrNotEncrypted
+	}
+	if tree.Metadata.MessageAuthenticationCode == "" || tree.Metadata.MasterKeyCount() == 0 {
+		return sops.Tree{}, ErrNotEncrypted
+	}
+	return tree, nil
+}
+
+// identityKeyService decrypts the data keys of age recipients with
+// identities rather than those of the environment, and others, e.g. KMS
+// keys, with the local key service
+type identityKeyService struct {
+	keyservice.KeyServiceClient
+	identities sopsage.ParsedIdentities
+	// noMatch is set once none of the identities matched an age recipient
+	noMatch bool
+}
+
+func (s *identityKeyService) Decrypt(ctx context.Context, req *keyservice.DecryptRequest, opts ...grpc.CallOption) (*keyservice.DecryptResponse, error) {
+	ageKey := req.GetKey().GetAgeKey()
+	if ageKey == nil {
+		return s.KeyServiceClient.Decrypt(ctx, req, opts...)
+	}
+	if len(s.identities) == 0 {
+		s.noMatch = true
+		return nil, ErrNoIdentityMatch
+	}
+	masterKey := &sopsage.MasterKey{Recipient: ageKey.Recipient, EncryptedKey: string(req.GetCiphertext())}
+	s.identities.ApplyToMasterKey(masterKey)
+	plaintext, err := masterKey.Decrypt()
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			s.noMatch = true
+		}
+		return nil, err
+	}
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
@@ -0,0 +1,279 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+)
+
+// The identity and recipient of the age test kit, whose private key is 32
+// bytes of 0x42
+const (
+	testIdentity  = "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX"
+	testRecipient = "age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869xeexvn73equnujwj"
+)
+
+const testKMSKey = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+// sopsFields parses a SOPS document into its data value and metadata
+func sopsFields(t *testing.T, document []byte) (string, map[string]any) {
+	t.Helper()
+	var parsed struct {
+		Data string         `json:"data"`
+		SOPS map[string]any `json:"sops"`
+	}
+	if err := json.Unmarshal(document, &parsed); err != nil {
+		t.Fatalf("Expected a JSON document, got %v", err)
+	}
+	return parsed.Data, parsed.SOPS
+}
+
+func TestSOPSRoundTrip(t *testing.T) {
+	plaintext := []byte("password: hunter2\n")
+	document, err := Encrypt(testRecipient, plaintext)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if strings.Contains(string(document), "hunter2") {
+		t.Fatalf("Expected the value to be encrypted, got:\n%s", document)
+	}
+	if !IsEncrypted(document) || IsEncrypted(plaintext) {
+		t.Error("Expected only the document to be reported as encrypted")
+	}
+	data, metadata := sopsFields(t, document)
+	if !strings.HasPrefix(data, "ENC[AES256_GCM,data:") || !strings.Contains(string(document), `"recipient": "`+testRecipient+`"`) || metadata["mac"] == "" {
+		t.Errorf("Expected a SOPS document for %s, got:\n%s", testRecipient, document)
+	}
+
+	decrypted, err := Decrypt(testIdentity, document)
+	if err != nil || string(decrypted) != string(plaintext) {
+		t.Errorf("Expected %q, got %q, %v", plaintext, decrypted, err)
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate an identity: %v", err)
+	}
+	if _, err := Decrypt(other.String(), document); !errors.Is(err, ErrNoIdentityMatch) {
+		t.Errorf("Expected %v for another key, got %v", ErrNoIdentityMatch, err)
+	}
+	if _, err := Decrypt("", document); !errors.Is(err, ErrNoIdentityMatch) {
+		t.Errorf("Expected %v without a key, got %v", ErrNoIdentityMatch, err)
+	}
+
+	// A document for several recipients decrypts with either key
+	document, err = Encrypt(other.Recipient().String()+","+testRecipient, plaintext)
+	if err != nil {
+		t.Fatalf("Failed to encrypt for two recipients: %v", err)
+	}
+	for _, key := range []string{testIdentity, other.String()} {
+		if decrypted, err := Decrypt(key, document); err != nil || string(decrypted) != string(plaintext) {
+			t.Errorf("Expected either key to decrypt, got %q, %v", decrypted, err)
+		}
+	}
+}
+
+// TestSOPSCLIInterop checks values decrypt from the output of the sops CLI,
+// testdata/sops-cli.json from
+//
+//	sops encrypt --age age1zvkyg2lq... --input-type binary --output-type binary
+//
+// and, when sops is installed, that it decrypts what Encrypt writes
+func TestSOPSCLIInterop(t *testing.T) {
+	document, err := os.ReadFile(filepath.Join("testdata", "sops-cli.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the sops document: %v", err)
+	}
+	if decrypted, err := Decrypt(testIdentity, document); err != nil || string(decrypted) != "password: hunter2\n" {
+		t.Errorf("Expected the sops document to decrypt, got %q, %v", decrypted, err)
+	}
+
+	path, err := exec.LookPath("sops")
+	if err != nil {
+		t.Skip("sops is not installed")
+	}
+	document, err = Encrypt(testRecipient, []byte("replicas=3"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(keyFile, []byte(testIdentity+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write the key file: %v", err)
+	}
+	cmd := exec.Command(path, "decrypt", "--input-type", "binary", "--output-type", "binary", "/dev/stdin")
+	cmd.Env = append(os.Environ(), "SOPS_AGE_KEY_FILE="+keyFile)
+	cmd.Stdin = bytes.NewReader(document)
+	if output, err := cmd.Output(); err != nil || string(output) != "replicas=3" {
+		t.Errorf("Expected sops to decrypt the document, got %q, %v", output, err)
+	}
+}
+
+func TestSOPSTampering(t *testing.T) {
+	document, err := Encrypt(testRecipient, []byte("replicas=3"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// Swapping in the encrypted MAC as the value, or another modification
+	// time, fails authentication
+	var parsed map[string]any
+	if err := json.Unmarshal(document, &parsed); err != nil {
+		t.Fatalf("Failed to parse the document: %v", err)
+	}
+	metadata := parsed["sops"].(map[string]any)
+	for name, tamper := range map[string]func(){
+		"value":        func() { parsed["data"] = metadata["mac"] },
+		"lastmodified": func() { metadata["lastmodified"] = "2000-01-01T00:00:00Z" },
+	} {
+		data, mac, lastModified := parsed["data"], metadata["mac"], metadata["lastmodified"]
+		tamper()
+		tampered, err := json.Marshal(parsed)
+		if err != nil {
+			t.Fatalf("Failed to marshal the document: %v", err)
+		}
+		if _, err := Decrypt(testIdentity, tampered); err == nil {
+			t.Errorf("Expected a tampered %s to fail to decrypt", name)
+		}
+		parsed["data"], metadata["mac"], metadata["lastmodified"] = data, mac, lastModified
+	}
+
+	if _, err := Decrypt(testIdentity, []byte("replicas=3")); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Expected %v for a plain value, got %v", ErrNotEncrypted, err)
+	}
+}
+
+// TestIsEncrypted checks JSON values that merely look like SOPS documents are
+// not taken for them
+func TestIsEncrypted(t *testing.T) {
+	for _, value := range []string{
+		`{"sops": {"version": "3.11.0"}}`,
+		`{"data": "plain", "sops": {"version": "3.11.0", "lastmodified": "2024-01-01T00:00:00Z", "mac": "ENC[AES256_GCM,data:x,iv:x,tag:x,type:str]"}}`,
+		`{"data": "ENC[AES256_GCM,data:x,iv:x,tag:x,type:str]", "sops": {"version": "3.11.0", "lastmodified": "2024-01-01T00:00:00Z"}}`,
+		`{"data": "ENC[AES256_GCM,data:x,iv:x,tag:x,type:str]", "other": "value", "sops": {"age": [{"recipient": "` + testRecipient + `", "enc": "x"}], "version": "3.11.0", "lastmodified": "2024-01-01T00:00:00Z", "mac": "ENC[AES256_GCM,data:x,iv:x,tag:x,type:str]"}}`,
+	} {
+		if IsEncrypted([]byte(value)) {
+			t.Errorf("Expected %s not to be reported as encrypted", value)
+		}
+		if _, err := Decrypt(testIdentity, []byte(value)); !errors.Is(err, ErrNotEncrypted) {
+			t.Errorf("Expected %v for %s, got %v", ErrNotEncrypted, value, err)
+		}
+	}
+}
+
+// fakeKMS serves the Encrypt and Decrypt actions of AWS KMS, "encrypting"
+// by prefixing the plaintext, and points the AWS SDK at it
+func fakeKMS(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			KeyId          string
+			Plaintext      []byte
+			CiphertextBlob []byte
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			json.NewEncoder(w).Encode(map[string]string{"KeyId": req.KeyId, "CiphertextBlob": base64.StdEncoding.EncodeToString(append([]byte("kms:"), req.Plaintext...))})
+		case "TrentService.Decrypt":
+			plaintext, ok := bytes.CutPrefix(req.CiphertextBlob, []byte("kms:"))
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"__type": "InvalidCiphertextException"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"KeyId": req.KeyId, "Plaintext": base64.StdEncoding.EncodeToString(plaintext)})
+		default:
+			http.Error(w, "unexpected action", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("AWS_ENDPOINT_URL_KMS", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+func TestKMSRoundTrip(t *testing.T) {
+	fakeKMS(t)
+
+	document, err := Encrypt(testKMSKey, []byte("value"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if !strings.Contains(string(document), `"arn": "`+testKMSKey+`"`) {
+		t.Errorf("Expected a document for %s, got:\n%s", testKMSKey, document)
+	}
+	// KMS keys need no age identities
+	if decrypted, err := Decrypt("", document); err != nil || string(decrypted) != "value" {
+		t.Errorf("Expected the document to decrypt through KMS, got %q, %v", decrypted, err)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	if keys, err := LoadKeys("", nil, ""); keys != nil || err != nil {
+		t.Errorf("Expected no keys without a backend, got %+v, %v", keys, err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(keyFile, []byte("# public key: "+testRecipient+"\n"+testIdentity+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write the key file: %v", err)
+	}
+	keys, err := LoadKeys(BackendAge, []string{testRecipient}, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to load keys: %v", err)
+	}
+	document, err := Encrypt(keys.Encrypt, []byte("value"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if decrypted, err := Decrypt(keys.Decrypt, document); err != nil || string(decrypted) != "value" {
+		t.Errorf("Expected the loaded keys to round-trip, got %q, %v", decrypted, err)
+	}
+
+	// Without a backend, values are only decrypted
+	keys, err = LoadKeys("", nil, keyFile)
+	if err != nil || keys.Encrypt != "" || !keys.CanDecrypt() {
+		t.Errorf("Expected only a decryption key without a backend, got %+v, %v", keys, err)
+	}
+
+	// KMS keys decrypt without a key file
+	keys, err = LoadKeys(BackendKMS, []string{testKMSKey}, "")
+	if err != nil || keys.Encrypt != testKMSKey || !keys.CanDecrypt() {
+		t.Errorf("Expected the KMS key, got %+v, %v", keys, err)
+	}
+
+	for _, tc := range []struct {
+		backend    string
+		recipients []string
+		keyFile    string
+	}{
+		{BackendAge, []string{"age1invalid"}, ""},
+		{BackendAge, nil, ""},
+		{BackendKMS, []string{testRecipient}, ""},
+		{BackendKMS, nil, ""},
+		{"vault", nil, ""},
+		{BackendAge, []string{testRecipient}, filepath.Join(t.TempDir(), "missing.txt")},
+	} {
+		if _, err := LoadKeys(tc.backend, tc.recipients, tc.keyFile); err == nil {
+			t.Errorf("Expected an error for %+v", tc)
+		}
+	}
+}
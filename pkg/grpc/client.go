@@ -2,12 +2,18 @@ package grpc
 
 import (
 	"context"
+	"io"
+	"math/rand/v2"
 	"time"
 
+	"k8s-dashboard/pkg/version"
 	"k8s-dashboard/proto"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -19,24 +25,101 @@ import (
 type Client struct {
 	conn   *grpc.ClientConn
 	client proto.K8SServiceClient
+
+	// confirmed holds protected namespaces the caller has confirmed mutations for
+	confirmed map[string]bool
+
+	allowMajorSkew bool
+	// serverVersion is the version the server reported, empty before
+	// CheckVersion or for servers predating GetVersion
+	serverVersion string
+}
+
+// ClientOption configures a Client created with NewClient
+type ClientOption func(*Client)
+
+// WithAllowMajorSkew connects to servers of another major version than the
+// client's, which NewClient refuses otherwise
+func WithAllowMajorSkew() ClientOption {
+	return func(c *Client) {
+		c.allowMajorSkew = true
+	}
 }
 
-// NewClient creates a new gRPC client
-func NewClient(address string) (*Client, error) {
+// NewClient creates a new gRPC client, failing if the server cannot be reached
+// within the dial timeout or runs an incompatible version
+func NewClient(address string, opts ...ClientOption) (*Client, error) {
 	conn, err := grpc.Dial(address,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
 		grpc.WithTimeout(5*time.Second),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	client := proto.NewK8SServiceClient(conn)
+	c := NewClientFromConn(conn)
+	for _, opt := range opts {
+		opt(c)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.CheckVersion(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
 
+// NewClientFromConn creates a client on an established connection, which
+// Close closes
+func NewClientFromConn(conn *grpc.ClientConn) *Client {
 	return &Client{
-		conn:   conn,
-		client: client,
-	}, nil
+		conn:      conn,
+		client:    proto.NewK8SServiceClient(conn),
+		confirmed: make(map[string]bool),
+	}
+}
+
+// ConfirmNamespace records that the caller has confirmed mutating operations in
+// a protected namespace; subsequent create/update/delete calls send the confirm field
+func (c *Client) ConfirmNamespace(namespace string) {
+	if c.confirmed == nil {
+		c.confirmed = make(map[string]bool)
+	}
+	c.confirmed[namespace] = true
+}
+
+// confirmation returns the confirm value to send for a namespace
+func (c *Client) confirmation(namespace string) string {
+	if c.confirmed[namespace] {
+		return namespace
+	}
+	return ""
+}
+
+// CheckVersion compares the server's version to the client's with
+// version.CheckServer: minor skew is logged and major skew fails unless
+// allowed. A server predating GetVersion is logged and accepted.
+func (c *Client) CheckVersion(ctx context.Context) error {
+	resp, err := c.client.GetVersion(ctx, &emptypb.Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		klog.Warningf("The kgo gRPC server does not report its version, it predates client version %s", version.Version)
+		return nil
+	}
+	if err != nil {
+		klog.Errorf("Failed to get the server version via gRPC: %v", err)
+		return err
+	}
+
+	c.serverVersion = resp.Version
+	return version.CheckServer(resp.Version, c.allowMajorSkew)
+}
+
+// ServerVersion returns the version the server reported to CheckVersion, or
+// an empty string
+func (c *Client) ServerVersion() string {
+	return c.serverVersion
 }
 
 // Close closes the gRPC connection
@@ -64,6 +147,85 @@ func (c *Client) ListPods(namespace string) ([]v1.Pod, error) {
 	return pods, nil
 }
 
+// defaultPageSize is the page size used by ListAllPods
+const defaultPageSize = 500
+
+// PodPage is one page of a paged pod list
+type PodPage struct {
+	Pods            []v1.Pod
+	NextPageToken   string
+	ResourceVersion string
+}
+
+// ListPodsPage fetches a single page of pods. An empty pageToken starts from
+// the beginning; an empty NextPageToken in the result marks the last page.
+func (c *Client) ListPodsPage(ctx context.Context, namespace string, pageSize int64, pageToken string) (*PodPage, error) {
+	resp, err := c.client.ListPods(ctx, &proto.ListRequest{
+		Namespace: namespace,
+		PageSize:  pageSize,
+		PageToken: pageToken,
+	})
+	if err != nil {
+		klog.Errorf("Failed to list pods via gRPC: %v", err)
+		return nil, err
+	}
+
+	page := &PodPage{
+		Pods:            make([]v1.Pod, 0, len(resp.Pods)),
+		NextPageToken:   resp.NextPageToken,
+		ResourceVersion: resp.ResourceVersion,
+	}
+	for _, protoPod := range resp.Pods {
+		page.Pods = append(page.Pods, *c.convertProtoToPod(protoPod))
+	}
+	return page, nil
+}
+
+// ForEachPod calls fn for every pod in the namespace, fetching pages of
+// pageSize as it goes instead of building one large slice. It stops at the
+// first error from fn or when ctx is cancelled between pages. The resource
+// version of the first page is returned so a watch can follow on from it.
+func (c *Client) ForEachPod(ctx context.Context, namespace string, pageSize int64, fn func(v1.Pod) error) (string, error) {
+	var resourceVersion, pageToken string
+	for first := true; first || pageToken != ""; first = false {
+		if err := ctx.Err(); err != nil {
+			return resourceVersion, err
+		}
+
+		pageCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		page, err := c.ListPodsPage(pageCtx, namespace, pageSize, pageToken)
+		cancel()
+		if err != nil {
+			return resourceVersion, err
+		}
+
+		if first {
+			resourceVersion = page.ResourceVersion
+		}
+		for _, pod := range page.Pods {
+			if err := fn(pod); err != nil {
+				return resourceVersion, err
+			}
+		}
+		pageToken = page.NextPageToken
+	}
+	return resourceVersion, nil
+}
+
+// ListAllPods lists every pod in the namespace, transparently walking pages.
+// It also returns the resource version of the first page.
+func (c *Client) ListAllPods(namespace string) ([]v1.Pod, string, error) {
+	var pods []v1.Pod
+	resourceVersion, err := c.ForEachPod(context.Background(), namespace, defaultPageSize, func(pod v1.Pod) error {
+		pods = append(pods, pod)
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return pods, resourceVersion, nil
+}
+
 // ListDeployments lists deployments in the specified namespace
 func (c *Client) ListDeployments(namespace string) ([]appsv1.Deployment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -138,6 +300,48 @@ func (c *Client) ListNamespaces() ([]*proto.Namespace, error) {
 	return resp.Namespaces, nil
 }
 
+// FieldViolation is a single invalid field of a rejected request
+type FieldViolation struct {
+	// Field is the path of the invalid field, e.g. "spec.containers[0].image"
+	Field       string
+	Description string
+}
+
+// ValidationError is returned by create and update calls the server rejected
+// with InvalidArgument, listing every invalid field
+type ValidationError struct {
+	Message    string
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// validationError decodes the BadRequest details of an InvalidArgument status
+// into a *ValidationError, returning any other error unchanged
+func validationError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		return err
+	}
+
+	validationErr := &ValidationError{Message: st.Message()}
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, violation := range badRequest.FieldViolations {
+			validationErr.Violations = append(validationErr.Violations, FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Description,
+			})
+		}
+	}
+	return validationErr
+}
+
 // CreatePod creates a new pod
 func (c *Client) CreatePod(namespace string, spec *proto.PodSpec) (*proto.Pod, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -145,11 +349,12 @@ func (c *Client) CreatePod(namespace string, spec *proto.PodSpec) (*proto.Pod, e
 
 	resp, err := c.client.CreatePod(ctx, &proto.CreatePodRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to create pod via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Pod, nil
@@ -162,12 +367,13 @@ func (c *Client) UpdatePod(namespace, name string, spec *proto.PodSpec) (*proto.
 
 	resp, err := c.client.UpdatePod(ctx, &proto.UpdatePodRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to update pod via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Pod, nil
@@ -180,6 +386,7 @@ func (c *Client) DeletePod(namespace, name string) error {
 
 	_, err := c.client.DeletePod(ctx, &proto.DeleteRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 	})
 	if err != nil {
@@ -197,11 +404,12 @@ func (c *Client) CreateDeployment(namespace string, spec *proto.DeploymentSpec)
 
 	resp, err := c.client.CreateDeployment(ctx, &proto.CreateDeploymentRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to create deployment via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Deployment, nil
@@ -214,12 +422,13 @@ func (c *Client) UpdateDeployment(namespace, name string, spec *proto.Deployment
 
 	resp, err := c.client.UpdateDeployment(ctx, &proto.UpdateDeploymentRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to update deployment via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Deployment, nil
@@ -232,6 +441,7 @@ func (c *Client) DeleteDeployment(namespace, name string) error {
 
 	_, err := c.client.DeleteDeployment(ctx, &proto.DeleteRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 	})
 	if err != nil {
@@ -249,11 +459,12 @@ func (c *Client) CreateService(namespace string, spec *proto.ServiceSpec) (*prot
 
 	resp, err := c.client.CreateService(ctx, &proto.CreateServiceRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to create service via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Service, nil
@@ -266,12 +477,13 @@ func (c *Client) UpdateService(namespace, name string, spec *proto.ServiceSpec)
 
 	resp, err := c.client.UpdateService(ctx, &proto.UpdateServiceRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to update service via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Service, nil
@@ -284,6 +496,7 @@ func (c *Client) DeleteService(namespace, name string) error {
 
 	_, err := c.client.DeleteService(ctx, &proto.DeleteRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 	})
 	if err != nil {
@@ -301,11 +514,12 @@ func (c *Client) CreateConfigMap(namespace string, spec *proto.ConfigMapSpec) (*
 
 	resp, err := c.client.CreateConfigMap(ctx, &proto.CreateConfigMapRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to create configmap via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Configmap, nil
@@ -318,12 +532,13 @@ func (c *Client) UpdateConfigMap(namespace, name string, spec *proto.ConfigMapSp
 
 	resp, err := c.client.UpdateConfigMap(ctx, &proto.UpdateConfigMapRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 		Spec:      spec,
 	})
 	if err != nil {
 		klog.Errorf("Failed to update configmap via gRPC: %v", err)
-		return nil, err
+		return nil, validationError(err)
 	}
 
 	return resp.Configmap, nil
@@ -336,6 +551,7 @@ func (c *Client) DeleteConfigMap(namespace, name string) error {
 
 	_, err := c.client.DeleteConfigMap(ctx, &proto.DeleteRequest{
 		Namespace: namespace,
+		Confirm:   c.confirmation(namespace),
 		Name:      name,
 	})
 	if err != nil {
@@ -346,6 +562,152 @@ func (c *Client) DeleteConfigMap(namespace, name string) error {
 	return nil
 }
 
+// ScaleWorkload sets the replicas of an object of a plural kind,
+// "deployments" or "statefulsets"
+func (c *Client) ScaleWorkload(kind, namespace, name string, replicas int32) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := c.client.ScaleWorkload(ctx, &proto.ScaleRequest{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Replicas:  replicas,
+		Confirm:   c.confirmation(namespace),
+	})
+	if err != nil {
+		klog.Errorf("Failed to scale %s via gRPC: %v", kind, err)
+		return err
+	}
+
+	return nil
+}
+
+// RestartWorkload restarts the pods of an object of a plural kind,
+// "deployments", "statefulsets" or "daemonsets", and returns the warning
+// about pods the restart leaves alone, if any
+func (c *Client) RestartWorkload(kind, namespace, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.RestartWorkload(ctx, &proto.RestartRequest{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Confirm:   c.confirmation(namespace),
+	})
+	if err != nil {
+		klog.Errorf("Failed to restart %s via gRPC: %v", kind, err)
+		return "", err
+	}
+
+	return resp.Warning, nil
+}
+
+// applyChunkSize is how much of a manifest each message of ApplyYAMLStream
+// carries
+const applyChunkSize = 64 << 10
+
+// applyStreamTimeout bounds applying a manifest with ApplyYAMLStream
+const applyStreamTimeout = 5 * time.Minute
+
+// ApplyYAML applies the documents of a manifest in a namespace and returns
+// the result of each. A dry run reports what applying would do without
+// changing the cluster. An empty fieldManager leaves it to the server.
+func (c *Client) ApplyYAML(namespace, manifest string, dryRun bool, fieldManager string) ([]*proto.ApplyResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := c.client.ApplyYAML(ctx, &proto.ApplyRequest{
+		YamlContent:  manifest,
+		Namespace:    namespace,
+		DryRun:       dryRun,
+		FieldManager: fieldManager,
+		Confirm:      c.confirmation(namespace),
+	})
+	if err != nil {
+		klog.Errorf("Failed to apply manifest via gRPC: %v", err)
+		return nil, err
+	}
+
+	return resp.Results, nil
+}
+
+// ApplyYAMLStream applies a manifest too large for a single message like
+// ApplyYAML, sending it in chunks as it is read. The results received before
+// a failure are returned with the error.
+func (c *Client) ApplyYAMLStream(namespace string, manifest io.Reader, dryRun bool, fieldManager string) ([]*proto.ApplyResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), applyStreamTimeout)
+	defer cancel()
+
+	stream, err := c.client.ApplyYAMLStream(ctx)
+	if err != nil {
+		klog.Errorf("Failed to apply manifest via gRPC: %v", err)
+		return nil, err
+	}
+
+	// Results arrive while the rest of the manifest is sent
+	sendErr := make(chan error, 1)
+	go func() {
+		err := sendManifest(stream, manifest, &proto.ApplyRequest{
+			Namespace:    namespace,
+			DryRun:       dryRun,
+			FieldManager: fieldManager,
+			Confirm:      c.confirmation(namespace),
+		})
+		sendErr <- err
+		if err != nil {
+			// A manifest that cannot be read aborts the stream
+			cancel()
+		}
+	}()
+
+	var results []*proto.ApplyResult
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			select {
+			case readErr := <-sendErr:
+				if readErr != nil {
+					err = readErr
+				}
+			default:
+			}
+			klog.Errorf("Failed to apply manifest via gRPC: %v", err)
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, <-sendErr
+}
+
+// sendManifest sends a manifest in chunks of applyChunkSize, the first along
+// with the options of first, and closes the sending side of the stream. It
+// returns nil when the server ends the stream early, which Recv reports.
+func sendManifest(stream proto.K8SService_ApplyYAMLStreamClient, manifest io.Reader, first *proto.ApplyRequest) error {
+	req := first
+	buf := make([]byte, applyChunkSize)
+	for {
+		n, err := io.ReadFull(manifest, buf)
+		if n > 0 || req == first {
+			req.YamlContent = string(buf[:n])
+			if err := stream.Send(req); err != nil {
+				return nil
+			}
+			req = &proto.ApplyRequest{}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return stream.CloseSend()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 // GetPodLogs retrieves logs from a pod
 func (c *Client) GetPodLogs(namespace, podName, containerName string, tailLines int32, follow bool) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -366,6 +728,90 @@ func (c *Client) GetPodLogs(namespace, podName, containerName string, tailLines
 	return resp.Logs, nil
 }
 
+// Reconnect backoff bounds for StreamWithRetry
+var (
+	streamRetryInitialBackoff = time.Second
+	streamRetryMaxBackoff     = 60 * time.Second
+)
+
+// StreamWithRetry forwards the messages of the server stream opened by fn,
+// reopening it whenever opening or receiving fails with codes.Unavailable,
+// which is how gRPC reports a dropped connection. Reconnects back off
+// exponentially from 1s to 60s with jitter, restarting at 1s once a stream has
+// delivered a message. fn should open the stream with ctx.
+//
+// Every failed attempt's error is sent on the error channel. Both channels are
+// closed when ctx is done, the stream ends cleanly, or it fails with any other
+// error, which is sent last, so the caller must receive from both until then.
+func StreamWithRetry[T any](ctx context.Context, fn func() (grpc.ServerStreamingClient[T], error)) (<-chan *T, <-chan error) {
+	items := make(chan *T)
+	errs := make(chan error)
+
+	go func() {
+		defer close(items)
+		defer close(errs)
+
+		backoff := streamRetryInitialBackoff
+		for {
+			received, err := receiveStream(ctx, fn, items)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return
+			}
+			if status.Code(err) != codes.Unavailable {
+				return
+			}
+
+			if received {
+				backoff = streamRetryInitialBackoff
+			}
+			delay := backoff/2 + rand.N(backoff/2+1)
+			klog.Warningf("gRPC stream failed, reconnecting in %v: %v", delay, err)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+			backoff = min(backoff*2, streamRetryMaxBackoff)
+		}
+	}()
+
+	return items, errs
+}
+
+// receiveStream opens a stream with fn and forwards its messages to items. It
+// returns nil when the server ends the stream, and whether any message arrived.
+func receiveStream[T any](ctx context.Context, fn func() (grpc.ServerStreamingClient[T], error), items chan<- *T) (bool, error) {
+	stream, err := fn()
+	if err != nil {
+		return false, err
+	}
+
+	received := false
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		received = true
+
+		select {
+		case items <- item:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
+
 // Conversion functions from protobuf to Kubernetes types
 
 func (c *Client) convertProtoToPod(protoPod *proto.Pod) *v1.Pod {
@@ -1,11 +1,29 @@
 package grpc
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"k8s-dashboard/pkg/version"
 	"k8s-dashboard/proto"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestConvertProtoToPod(t *testing.T) {
@@ -138,3 +156,374 @@ func TestConvertProtoToConfigMap(t *testing.T) {
 		t.Errorf("Expected data 'key=value', got '%s'", cm.Data["key"])
 	}
 }
+
+// pagedPodServer serves nine pods in three pages of three
+type pagedPodServer struct {
+	proto.UnimplementedK8SServiceServer
+
+	mu       sync.Mutex
+	requests []*proto.ListRequest
+}
+
+func (s *pagedPodServer) ListPods(ctx context.Context, req *proto.ListRequest) (*proto.PodListResponse, error) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	pages := map[string]struct {
+		start int
+		next  string
+		rv    string
+	}{
+		"":       {0, "page-2", "100"},
+		"page-2": {3, "page-3", "101"},
+		"page-3": {6, "", "102"},
+	}
+	page, ok := pages[req.PageToken]
+	if !ok {
+		return nil, fmt.Errorf("unknown page token %q", req.PageToken)
+	}
+
+	resp := &proto.PodListResponse{NextPageToken: page.next, ResourceVersion: page.rv}
+	for i := page.start; i < page.start+3; i++ {
+		resp.Pods = append(resp.Pods, &proto.Pod{Name: fmt.Sprintf("pod-%d", i), Namespace: req.Namespace})
+	}
+	return resp, nil
+}
+
+func (s *pagedPodServer) requestCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// newBufconnClient starts srv on an in-memory listener and returns a client for it
+func newBufconnClient(t *testing.T, srv proto.K8SServiceServer) *Client {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	proto.RegisterK8SServiceServer(server, srv)
+	reflection.Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &Client{conn: conn, client: proto.NewK8SServiceClient(conn), confirmed: make(map[string]bool)}
+}
+
+func TestListAllPodsWalksPages(t *testing.T) {
+	srv := &pagedPodServer{}
+	client := newBufconnClient(t, srv)
+
+	pods, resourceVersion, err := client.ListAllPods("default")
+	if err != nil {
+		t.Fatalf("ListAllPods failed: %v", err)
+	}
+
+	if len(pods) != 9 {
+		t.Fatalf("Expected 9 pods, got %d", len(pods))
+	}
+	for i, pod := range pods {
+		if want := fmt.Sprintf("pod-%d", i); pod.Name != want {
+			t.Errorf("Pod %d: expected %s, got %s", i, want, pod.Name)
+		}
+	}
+	if resourceVersion != "100" {
+		t.Errorf("Expected resource version of the first page, got %q", resourceVersion)
+	}
+	if srv.requestCount() != 3 {
+		t.Errorf("Expected 3 page requests, got %d", srv.requestCount())
+	}
+	for _, req := range srv.requests {
+		if req.PageSize != defaultPageSize {
+			t.Errorf("Expected page size %d, got %d", defaultPageSize, req.PageSize)
+		}
+	}
+}
+
+func TestListPodsPage(t *testing.T) {
+	client := newBufconnClient(t, &pagedPodServer{})
+
+	page, err := client.ListPodsPage(context.Background(), "default", 3, "page-3")
+	if err != nil {
+		t.Fatalf("ListPodsPage failed: %v", err)
+	}
+	if len(page.Pods) != 3 || page.Pods[0].Name != "pod-6" {
+		t.Errorf("Unexpected page contents: %+v", page.Pods)
+	}
+	if page.NextPageToken != "" {
+		t.Errorf("Expected last page to have no next token, got %q", page.NextPageToken)
+	}
+}
+
+func TestForEachPodStopsOnCallbackError(t *testing.T) {
+	srv := &pagedPodServer{}
+	client := newBufconnClient(t, srv)
+
+	stop := errors.New("stop")
+	var seen []string
+	_, err := client.ForEachPod(context.Background(), "default", 3, func(pod v1.Pod) error {
+		seen = append(seen, pod.Name)
+		if pod.Name == "pod-4" {
+			return stop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, stop) {
+		t.Fatalf("Expected callback error, got %v", err)
+	}
+	if len(seen) != 5 {
+		t.Errorf("Expected iteration to stop after pod-4, saw %v", seen)
+	}
+	if srv.requestCount() != 2 {
+		t.Errorf("Expected no page fetched after the error, got %d requests", srv.requestCount())
+	}
+}
+
+func TestForEachPodRespectsCancellation(t *testing.T) {
+	srv := &pagedPodServer{}
+	client := newBufconnClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	resourceVersion, err := client.ForEachPod(ctx, "default", 3, func(pod v1.Pod) error {
+		count++
+		if count == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if count != 3 || srv.requestCount() != 1 {
+		t.Errorf("Expected to stop after the first page, got %d pods and %d requests", count, srv.requestCount())
+	}
+	if resourceVersion != "100" {
+		t.Errorf("Expected first page resource version, got %q", resourceVersion)
+	}
+}
+
+// droppingExecServer drops the first exec stream with codes.Unavailable after
+// three messages and ends the next one cleanly after two more
+type droppingExecServer struct {
+	proto.UnimplementedK8SServiceServer
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *droppingExecServer) ExecPod(req *proto.ExecRequest, stream proto.K8SService_ExecPodServer) error {
+	s.mu.Lock()
+	s.calls++
+	call := s.calls
+	s.mu.Unlock()
+
+	count := 3
+	if call > 1 {
+		count = 2
+	}
+	for i := 0; i < count; i++ {
+		if err := stream.Send(&proto.ExecResponse{Output: fmt.Sprintf("call-%d-line-%d", call, i)}); err != nil {
+			return err
+		}
+	}
+	if call == 1 {
+		return status.Error(codes.Unavailable, "connection dropped")
+	}
+	return nil
+}
+
+// useFastStreamRetry shortens the reconnect backoff for the duration of a test
+func useFastStreamRetry(t *testing.T) {
+	initial, maxBackoff := streamRetryInitialBackoff, streamRetryMaxBackoff
+	streamRetryInitialBackoff, streamRetryMaxBackoff = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() {
+		streamRetryInitialBackoff, streamRetryMaxBackoff = initial, maxBackoff
+	})
+}
+
+func TestStreamWithRetryReconnects(t *testing.T) {
+	useFastStreamRetry(t)
+	client := newBufconnClient(t, &droppingExecServer{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attempts := 0
+	items, errs := StreamWithRetry(ctx, func() (grpc.ServerStreamingClient[proto.ExecResponse], error) {
+		attempts++
+		if attempts == 2 {
+			return nil, status.Error(codes.Unavailable, "still down")
+		}
+		return client.client.ExecPod(ctx, &proto.ExecRequest{Namespace: "default", PodName: "web", Command: "ls"})
+	})
+
+	var outputs []string
+	var failures []error
+	for items != nil || errs != nil {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				items = nil
+				continue
+			}
+			outputs = append(outputs, item.Output)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			failures = append(failures, err)
+		}
+	}
+
+	want := []string{"call-1-line-0", "call-1-line-1", "call-1-line-2", "call-2-line-0", "call-2-line-1"}
+	if fmt.Sprint(outputs) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, outputs)
+	}
+	if len(failures) != 2 || status.Code(failures[0]) != codes.Unavailable || status.Code(failures[1]) != codes.Unavailable {
+		t.Errorf("Expected the dropped stream and the failed reconnect, got %v", failures)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if ctx.Err() != nil {
+		t.Errorf("Expected the stream to end before the deadline")
+	}
+}
+
+func TestStreamWithRetryStopsOnPermanentError(t *testing.T) {
+	useFastStreamRetry(t)
+
+	attempts := 0
+	items, errs := StreamWithRetry(context.Background(), func() (grpc.ServerStreamingClient[proto.ExecResponse], error) {
+		attempts++
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	})
+
+	if err := <-errs; status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied, got %v", err)
+	}
+	if _, ok := <-items; ok {
+		t.Error("Expected the item channel to be closed")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected no retry of a permanent error, got %d attempts", attempts)
+	}
+}
+
+func TestStreamWithRetryRespectsCancellation(t *testing.T) {
+	initial := streamRetryInitialBackoff
+	streamRetryInitialBackoff = time.Hour
+	t.Cleanup(func() { streamRetryInitialBackoff = initial })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	items, errs := StreamWithRetry(ctx, func() (grpc.ServerStreamingClient[proto.ExecResponse], error) {
+		return nil, status.Error(codes.Unavailable, "down")
+	})
+
+	if err := <-errs; status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected Unavailable, got %v", err)
+	}
+	// The helper is now waiting out the backoff
+	cancel()
+	if _, ok := <-errs; ok {
+		t.Error("Expected the error channel to be closed after cancellation")
+	}
+	if _, ok := <-items; ok {
+		t.Error("Expected the item channel to be closed after cancellation")
+	}
+}
+
+func TestClientApplyYAMLStream(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := newBufconnClient(t, NewServer(clientset, nil))
+
+	// Documents larger than a chunk, and split between chunks
+	var manifest strings.Builder
+	padding := strings.Repeat("x", applyChunkSize/3)
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&manifest, "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings-%d\ndata:\n  padding: %s\n", i, padding)
+	}
+
+	results, err := client.ApplyYAMLStream("default", strings.NewReader(manifest.String()), true, "")
+	if err != nil {
+		t.Fatalf("Expected dry run to succeed, got %v", err)
+	}
+	if len(results) != 8 || results[7].Name != "settings-7" || results[7].Action != "created" {
+		t.Fatalf("Expected 8 configmaps to be created, got %v", results)
+	}
+	if list, _ := clientset.CoreV1().ConfigMaps("default").List(context.Background(), metav1.ListOptions{}); len(list.Items) != 0 {
+		t.Fatalf("Expected a dry run to create nothing, got %d configmaps", len(list.Items))
+	}
+
+	results, err = client.ApplyYAMLStream("default", strings.NewReader(manifest.String()), false, "")
+	if err != nil || len(results) != 8 {
+		t.Fatalf("Expected 8 results, got %v, %v", results, err)
+	}
+	list, err := clientset.CoreV1().ConfigMaps("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(list.Items) != 8 {
+		t.Errorf("Expected 8 configmaps, got %v, %v", list, err)
+	}
+}
+
+func TestClientApplyYAMLStreamReadError(t *testing.T) {
+	client := newBufconnClient(t, NewServer(fake.NewSimpleClientset(), nil))
+	readErr := errors.New("disk on fire")
+
+	manifest := io.MultiReader(strings.NewReader(applyManifest), iotest.ErrReader(readErr))
+	if _, err := client.ApplyYAMLStream("default", manifest, true, ""); !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
+
+func TestClientCheckVersion(t *testing.T) {
+	saved := version.Version
+	version.Version = "1.4.0"
+	t.Cleanup(func() { version.Version = saved })
+
+	tests := []struct {
+		server  string
+		allow   bool
+		refused bool
+	}{
+		{server: "1.4.3"},
+		{server: "1.6.0"},
+		{server: "2.0.0", refused: true},
+		{server: "2.0.0", allow: true},
+	}
+	for _, tt := range tests {
+		server := NewServer(fake.NewSimpleClientset(), nil)
+		server.version = tt.server
+		client := newBufconnClient(t, server)
+		client.allowMajorSkew = tt.allow
+
+		err := client.CheckVersion(context.Background())
+		var skewErr *version.SkewError
+		if tt.refused != errors.As(err, &skewErr) {
+			t.Errorf("Server %s: expected refused=%v, got %v", tt.server, tt.refused, err)
+		}
+		if client.ServerVersion() != tt.server {
+			t.Errorf("Expected server version %s, got %q", tt.server, client.ServerVersion())
+		}
+	}
+
+	// A server predating GetVersion is accepted
+	client := newBufconnClient(t, &pagedPodServer{})
+	if err := client.CheckVersion(context.Background()); err != nil || client.ServerVersion() != "" {
+		t.Errorf("Expected a server without GetVersion to be accepted, got %v, %q", err, client.ServerVersion())
+	}
+}
@@ -0,0 +1,224 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"k8s.io/klog/v2"
+)
+
+// DynamicClient calls any unary method of the server without generated stubs:
+// the method's request and response types are looked up with the gRPC
+// reflection API the first time it is called, and messages are built from and
+// returned as JSON-like maps
+type DynamicClient struct {
+	conn *grpc.ClientConn
+
+	mu sync.Mutex
+	// files holds the descriptors fetched so far, with their dependencies
+	files *protoregistry.Files
+}
+
+// NewDynamicClient creates a dynamic client, failing if the server cannot be
+// reached within the dial timeout
+func NewDynamicClient(address string) (*DynamicClient, error) {
+	client, err := NewClient(address)
+	if err != nil {
+		return nil, err
+	}
+	return client.Dynamic(), nil
+}
+
+// Dynamic returns a dynamic client sharing this client's connection
+func (c *Client) Dynamic() *DynamicClient {
+	return &DynamicClient{conn: c.conn, files: new(protoregistry.Files)}
+}
+
+// Close closes the gRPC connection
+func (c *DynamicClient) Close() error {
+	return c.conn.Close()
+}
+
+// Invoke calls a unary method, named "package.Service/Method" (a leading slash
+// or a dot before the method name also work), with a request built from req.
+// Keys are the proto or JSON names of the fields and values follow the
+// protobuf JSON mapping, so 64-bit integers may be numbers or strings. The
+// response is returned the same way, keyed by proto field names, with unset
+// fields left out. A nil req sends an empty message.
+func (c *DynamicClient) Invoke(ctx context.Context, method string, req map[string]interface{}) (map[string]interface{}, error) {
+	descriptor, err := c.resolveMethod(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	if descriptor.IsStreamingClient() || descriptor.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is a streaming method; only unary methods can be invoked", descriptor.FullName())
+	}
+
+	if req == nil {
+		req = map[string]interface{}{}
+	}
+	in := dynamicpb.NewMessage(descriptor.Input())
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the request: %v", err)
+	}
+	if err := (protojson.UnmarshalOptions{Resolver: c.types()}).Unmarshal(body, in); err != nil {
+		return nil, fmt.Errorf("invalid request for %s: %v", descriptor.Input().FullName(), err)
+	}
+
+	out := dynamicpb.NewMessage(descriptor.Output())
+	fullMethod := fmt.Sprintf("/%s/%s", descriptor.Parent().FullName(), descriptor.Name())
+	if err := c.conn.Invoke(ctx, fullMethod, in, out); err != nil {
+		klog.Errorf("Failed to invoke %s via gRPC: %v", fullMethod, err)
+		return nil, err
+	}
+
+	body, err = protojson.MarshalOptions{UseProtoNames: true, Resolver: c.types()}.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the response: %v", err)
+	}
+	resp := make(map[string]interface{})
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode the response: %v", err)
+	}
+	return resp, nil
+}
+
+// types resolves message types from the fetched descriptors, for Any fields
+func (c *DynamicClient) types() *dynamicpb.Types {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return dynamicpb.NewTypes(c.files)
+}
+
+// resolveMethod returns the descriptor of a method, fetching the file of its
+// service from the server the first time
+func (c *DynamicClient) resolveMethod(ctx context.Context, method string) (protoreflect.MethodDescriptor, error) {
+	name := strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[:i] + "." + name[i+1:]
+	}
+	fullName := protoreflect.FullName(name)
+	if !fullName.IsValid() || fullName.Parent() == "" {
+		return nil, fmt.Errorf("invalid method name %q, expected package.Service/Method", method)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.files.FindDescriptorByName(fullName.Parent()); err != nil {
+		if err := c.fetchSymbol(ctx, string(fullName.Parent())); err != nil {
+			return nil, err
+		}
+	}
+	found, err := c.files.FindDescriptorByName(fullName)
+	if err != nil {
+		return nil, fmt.Errorf("unknown method %s: %v", fullName, err)
+	}
+	descriptor, ok := found.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a method", fullName)
+	}
+	return descriptor, nil
+}
+
+// fetchSymbol asks the server's reflection service for the file defining a
+// symbol and registers it with its dependencies. Callers hold mu.
+func (c *DynamicClient) fetchSymbol(ctx context.Context, symbol string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := reflectionpb.NewServerReflectionClient(c.conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		klog.Errorf("Failed to open the gRPC reflection stream: %v", err)
+		return err
+	}
+	defer stream.CloseSend()
+
+	pending := make(map[string]*descriptorpb.FileDescriptorProto)
+	fetched, err := fetchFiles(stream, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %v", symbol, err)
+	}
+	for _, file := range fetched {
+		pending[file.GetName()] = file
+	}
+
+	// The server sends the dependencies the stream has not seen yet; any
+	// others are asked for by name while registering
+	var register func(name string) error
+	register = func(name string) error {
+		if _, err := c.files.FindFileByPath(name); err == nil {
+			return nil
+		}
+		file, ok := pending[name]
+		if !ok {
+			fetched, err := fetchFiles(stream, &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to fetch %s: %v", name, err)
+			}
+			for _, f := range fetched {
+				pending[f.GetName()] = f
+			}
+			if file, ok = pending[name]; !ok {
+				return fmt.Errorf("server did not send %s", name)
+			}
+		}
+		for _, dependency := range file.GetDependency() {
+			if err := register(dependency); err != nil {
+				return err
+			}
+		}
+		descriptor, err := protodesc.NewFile(file, c.files)
+		if err != nil {
+			return fmt.Errorf("invalid descriptor %s: %v", name, err)
+		}
+		return c.files.RegisterFile(descriptor)
+	}
+	for _, file := range fetched {
+		if err := register(file.GetName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchFiles sends a reflection request and decodes the files in its answer
+func fetchFiles(stream reflectionpb.ServerReflection_ServerReflectionInfoClient, req *reflectionpb.ServerReflectionRequest) ([]*descriptorpb.FileDescriptorProto, error) {
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return nil, fmt.Errorf("%s", errResp.GetErrorMessage())
+	}
+
+	var files []*descriptorpb.FileDescriptorProto
+	for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := new(descriptorpb.FileDescriptorProto)
+		if err := proto.Unmarshal(raw, file); err != nil {
+			return nil, fmt.Errorf("invalid file descriptor: %v", err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
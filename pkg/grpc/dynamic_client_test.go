@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDynamicClientInvokeListPods(t *testing.T) {
+	client := newBufconnClient(t, &pagedPodServer{})
+	dynamic := client.Dynamic()
+	ctx := context.Background()
+
+	typed, err := client.ListPodsPage(ctx, "default", 3, "page-2")
+	if err != nil {
+		t.Fatalf("ListPodsPage failed: %v", err)
+	}
+	resp, err := dynamic.Invoke(ctx, "k8s.K8sService/ListPods", map[string]interface{}{
+		"namespace":  "default",
+		"page_size":  3,
+		"page_token": "page-2",
+	})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	pods, ok := resp["pods"].([]interface{})
+	if !ok || len(pods) != len(typed.Pods) {
+		t.Fatalf("Expected %d pods, got %v", len(typed.Pods), resp["pods"])
+	}
+	for i, pod := range pods {
+		fields := pod.(map[string]interface{})
+		if fields["name"] != typed.Pods[i].Name || fields["namespace"] != typed.Pods[i].Namespace {
+			t.Errorf("Pod %d: expected %s/%s, got %v", i, typed.Pods[i].Namespace, typed.Pods[i].Name, fields)
+		}
+	}
+	if resp["next_page_token"] != typed.NextPageToken || resp["resource_version"] != typed.ResourceVersion {
+		t.Errorf("Expected next page %q at %q, got %v", typed.NextPageToken, typed.ResourceVersion, resp)
+	}
+
+	// The descriptors are fetched once; other spellings of the name work too
+	if _, err := dynamic.Invoke(ctx, "/k8s.K8sService.ListPods", map[string]interface{}{"namespace": "default"}); err != nil {
+		t.Errorf("Invoke with a leading slash failed: %v", err)
+	}
+}
+
+func TestDynamicClientInvokeErrors(t *testing.T) {
+	dynamic := newBufconnClient(t, &pagedPodServer{}).Dynamic()
+	ctx := context.Background()
+
+	tests := []struct {
+		method string
+		req    map[string]interface{}
+		want   string
+	}{
+		{"ListPods", nil, "invalid method name"},
+		{"k8s.K8sService/Missing", nil, "unknown method"},
+		{"k8s.Missing/ListPods", nil, "failed to look up"},
+		{"k8s.K8sService/ExecPod", nil, "streaming method"},
+		{"k8s.K8sService/ListPods", map[string]interface{}{"colour": "red"}, "invalid request"},
+		// The method exists but the test server does not implement it
+		{"k8s.K8sService/ListServices", nil, "Unimplemented"},
+	}
+	for _, tt := range tests {
+		_, err := dynamic.Invoke(ctx, tt.method, tt.req)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Invoke(%s): expected an error containing %q, got %v", tt.method, tt.want, err)
+		}
+	}
+}
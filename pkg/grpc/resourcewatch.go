@@ -0,0 +1,244 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// resourceWatcher starts a watch on one resource type in a namespace from a
+// resourceVersion
+type resourceWatcher func(clientset kubernetes.Interface, namespace, resourceVersion string) (*k8s.WatchHandle, error)
+
+// resourceWatchers maps the types WatchResources accepts to their watcher
+var resourceWatchers = map[proto.ResourceType]resourceWatcher{
+	proto.ResourceType_RESOURCE_TYPE_PODS:        k8s.WatchPods,
+	proto.ResourceType_RESOURCE_TYPE_DEPLOYMENTS: k8s.WatchDeployments,
+	proto.ResourceType_RESOURCE_TYPE_SERVICES:    k8s.WatchServices,
+	proto.ResourceType_RESOURCE_TYPE_CONFIGMAPS:  k8s.WatchConfigMaps,
+}
+
+// requestedResourceTypes returns the distinct types of a WatchResources
+// request, resource_type first
+func requestedResourceTypes(req *proto.WatchRequest) ([]proto.ResourceType, error) {
+	requested := req.ResourceTypes
+	if req.ResourceType != proto.ResourceType_RESOURCE_TYPE_UNSPECIFIED {
+		requested = append([]proto.ResourceType{req.ResourceType}, requested...)
+	}
+
+	var types []proto.ResourceType
+	seen := make(map[proto.ResourceType]bool, len(requested))
+	for _, resourceType := range requested {
+		if _, ok := resourceWatchers[resourceType]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot watch resource type %s", resourceType)
+		}
+		if !seen[resourceType] {
+			seen[resourceType] = true
+			types = append(types, resourceType)
+		}
+	}
+	if len(types) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "resource_type or resource_types is required")
+	}
+	return types, nil
+}
+
+// WatchResources streams the changes to every requested resource type of a
+// namespace after the requested resource version, with a bookmark per type
+// every watchBookmarkInterval. The stream ends when any upstream watch does,
+// and fails as WatchPods does when a watch expires.
+func (s *Server) WatchResources(req *proto.WatchRequest, stream proto.K8SService_WatchResourcesServer) error {
+	types, err := requestedResourceTypes(req)
+	if err != nil {
+		return err
+	}
+
+	watchers := make(map[proto.ResourceType]*k8s.WatchHandle, len(types))
+	defer func() {
+		for _, watcher := range watchers {
+			watcher.Stop()
+		}
+	}()
+	for _, resourceType := range types {
+		watcher, err := resourceWatchers[resourceType](s.clientset, req.Namespace, req.ResourceVersion)
+		if err != nil {
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				return watchExpired(err)
+			}
+			klog.Errorf("Failed to watch %s: %v", resourceType, err)
+			return err
+		}
+		watchers[resourceType] = watcher
+	}
+
+	// Fan in every watcher; the first to end or fail ends the stream
+	events := make(chan *proto.ResourceEvent)
+	ended := make(chan error, len(watchers))
+	done := make(chan struct{})
+	defer close(done)
+	for resourceType, watcher := range watchers {
+		go s.forwardResourceWatch(resourceType, watcher, events, ended, done)
+	}
+
+	ticker := time.NewTicker(watchBookmarkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+			for _, resourceType := range types {
+				if err := stream.Send(&proto.ResourceEvent{
+					Type:            bookmarkEvent,
+					ResourceType:    resourceType,
+					ResourceVersion: watchers[resourceType].ResourceVersion(),
+				}); err != nil {
+					return err
+				}
+			}
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case err := <-ended:
+			return err
+		}
+	}
+}
+
+// forwardResourceWatch converts the events of one watcher onto events until
+// it ends or done is closed, then reports on ended why it stopped: nil when
+// the upstream watch ended, or the error it failed with
+func (s *Server) forwardResourceWatch(resourceType proto.ResourceType, watcher *k8s.WatchHandle, events chan<- *proto.ResourceEvent, ended chan<- error, done <-chan struct{}) {
+	for event := range watcher.ResultChan() {
+		if event.Type == watch.Error {
+			err := apierrors.FromObject(event.Object)
+			if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+				err = watchExpired(err)
+			} else {
+				klog.Errorf("%s watch failed: %v", resourceType, err)
+			}
+			ended <- err
+			return
+		}
+		converted := s.convertResourceEvent(resourceType, event)
+		if converted == nil {
+			continue
+		}
+		select {
+		case events <- converted:
+		case <-done:
+			return
+		}
+	}
+	ended <- nil
+}
+
+// convertResourceEvent converts a watch event to a ResourceEvent, or returns
+// nil when it holds an object of another type
+func (s *Server) convertResourceEvent(resourceType proto.ResourceType, event watch.Event) *proto.ResourceEvent {
+	converted := &proto.ResourceEvent{Type: string(event.Type), ResourceType: resourceType}
+	switch obj := event.Object.(type) {
+	case *v1.Pod:
+		converted.Payload = &proto.ResourceEvent_Pod{Pod: s.convertPodToProto(obj)}
+		converted.ResourceVersion = obj.ResourceVersion
+	case *appsv1.Deployment:
+		converted.Payload = &proto.ResourceEvent_Deployment{Deployment: s.convertDeploymentToProto(obj)}
+		converted.ResourceVersion = obj.ResourceVersion
+	case *v1.Service:
+		converted.Payload = &proto.ResourceEvent_Service{Service: s.convertServiceToProto(obj)}
+		converted.ResourceVersion = obj.ResourceVersion
+	case *v1.ConfigMap:
+		converted.Payload = &proto.ResourceEvent_ConfigMap{ConfigMap: s.convertConfigMapToProto(obj)}
+		converted.ResourceVersion = obj.ResourceVersion
+	default:
+		return nil
+	}
+	return converted
+}
+
+// ResourceEvent is a change received with Client.WatchResources
+type ResourceEvent struct {
+	// Type is ADDED, MODIFIED, DELETED or BOOKMARK
+	Type string
+	// ResourceType is the type of Object, or of the watch a bookmark
+	// belongs to
+	ResourceType proto.ResourceType
+	// Object is the changed *v1.Pod, *appsv1.Deployment, *v1.Service or
+	// *v1.ConfigMap; nil for bookmarks
+	Object runtime.Object
+	// ResourceVersion is where the watch of ResourceType continues from
+	ResourceVersion string
+}
+
+// WatchResources watches several resource types of a namespace after
+// resourceVersion in one stream. Unlike WatchPods it does not resume: the
+// channels are closed when ctx is done or the stream ends, after sending the
+// error it failed with, if any. Bookmarks are passed on so that the caller
+// can watch again from each type's last resource version.
+func (c *Client) WatchResources(ctx context.Context, namespace, resourceVersion string, types ...proto.ResourceType) (<-chan ResourceEvent, <-chan error) {
+	events := make(chan ResourceEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		stream, err := c.client.WatchResources(ctx, &proto.WatchRequest{
+			Namespace:       namespace,
+			ResourceVersion: resourceVersion,
+			ResourceTypes:   types,
+		})
+		if err != nil {
+			errs <- err
+			return
+		}
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF || ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case events <- c.convertResourceEvent(event):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// convertResourceEvent converts a received ResourceEvent to its Kubernetes
+// object
+func (c *Client) convertResourceEvent(event *proto.ResourceEvent) ResourceEvent {
+	converted := ResourceEvent{Type: event.Type, ResourceType: event.ResourceType, ResourceVersion: event.ResourceVersion}
+	switch payload := event.Payload.(type) {
+	case *proto.ResourceEvent_Pod:
+		converted.Object = c.convertProtoToPod(payload.Pod)
+	case *proto.ResourceEvent_Deployment:
+		converted.Object = c.convertProtoToDeployment(payload.Deployment)
+	case *proto.ResourceEvent_Service:
+		converted.Object = c.convertProtoToService(payload.Service)
+	case *proto.ResourceEvent_ConfigMap:
+		converted.Object = c.convertProtoToConfigMap(payload.ConfigMap)
+	}
+	return converted
+}
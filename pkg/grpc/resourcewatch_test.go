@@ -0,0 +1,206 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newResourceWatchClientset returns a clientset whose pod, deployment,
+// service and configmap watches are the returned fake watchers
+func newResourceWatchClientset() (*fake.Clientset, map[string]*watch.FakeWatcher) {
+	clientset := fake.NewSimpleClientset()
+	watchers := make(map[string]*watch.FakeWatcher)
+	for _, resource := range []string{"pods", "deployments", "services", "configmaps"} {
+		fakeWatch := watch.NewFake()
+		watchers[resource] = fakeWatch
+		clientset.PrependWatchReactor(resource, func(action k8stesting.Action) (bool, watch.Interface, error) {
+			return true, fakeWatch, nil
+		})
+	}
+	return clientset, watchers
+}
+
+// webDeployment is the web deployment at a resource version
+func webDeployment(resourceVersion string) *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", ResourceVersion: resourceVersion},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func TestServerWatchResourcesInterleavesTypes(t *testing.T) {
+	clientset, watchers := newResourceWatchClientset()
+	client := newBufconnClient(t, NewServer(clientset, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.client.WatchResources(ctx, &proto.WatchRequest{
+		Namespace:     "default",
+		ResourceType:  proto.ResourceType_RESOURCE_TYPE_PODS,
+		ResourceTypes: []proto.ResourceType{proto.ResourceType_RESOURCE_TYPE_DEPLOYMENTS, proto.ResourceType_RESOURCE_TYPE_SERVICES, proto.ResourceType_RESOURCE_TYPE_CONFIGMAPS, proto.ResourceType_RESOURCE_TYPE_PODS},
+	})
+	if err != nil {
+		t.Fatalf("WatchResources failed: %v", err)
+	}
+
+	// Each change is received before the next one is made, so the stream
+	// carries them in this order
+	changes := []struct {
+		change func()
+		want   string
+	}{
+		{func() {
+			watchers["pods"].Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", ResourceVersion: "10"}})
+		}, "ADDED RESOURCE_TYPE_PODS pod web-1 @10"},
+		{func() {
+			watchers["deployments"].Add(webDeployment("11"))
+		}, "ADDED RESOURCE_TYPE_DEPLOYMENTS deployment web @11"},
+		{func() {
+			watchers["pods"].Modify(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", ResourceVersion: "12"}})
+		}, "MODIFIED RESOURCE_TYPE_PODS pod web-1 @12"},
+		{func() {
+			watchers["configmaps"].Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", ResourceVersion: "13"}})
+		}, "ADDED RESOURCE_TYPE_CONFIGMAPS config_map settings @13"},
+		{func() {
+			watchers["services"].Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", ResourceVersion: "14"}})
+		}, "ADDED RESOURCE_TYPE_SERVICES service web @14"},
+		{func() {
+			watchers["deployments"].Delete(webDeployment("15"))
+		}, "DELETED RESOURCE_TYPE_DEPLOYMENTS deployment web @15"},
+	}
+	for _, c := range changes {
+		go c.change()
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected %q, got %v", c.want, err)
+		}
+		var payload string
+		switch p := event.Payload.(type) {
+		case *proto.ResourceEvent_Pod:
+			payload = "pod " + p.Pod.Name
+		case *proto.ResourceEvent_Deployment:
+			payload = "deployment " + p.Deployment.Name
+		case *proto.ResourceEvent_Service:
+			payload = "service " + p.Service.Name
+		case *proto.ResourceEvent_ConfigMap:
+			payload = "config_map " + p.ConfigMap.Name
+		}
+		if got := fmt.Sprintf("%s %s %s @%s", event.Type, event.ResourceType, payload, event.ResourceVersion); got != c.want {
+			t.Errorf("Expected %q, got %q", c.want, got)
+		}
+	}
+
+	// An expired watch of one type fails the whole stream
+	go watchers["services"].Error(&apierrors.NewResourceExpired("too old resource version: 14").ErrStatus)
+	if _, err := stream.Recv(); !IsWatchExpired(err) {
+		t.Errorf("Expected a WATCH_EXPIRED error, got %v", err)
+	}
+}
+
+func TestServerWatchResourcesBookmarksEachType(t *testing.T) {
+	interval := watchBookmarkInterval
+	watchBookmarkInterval = 20 * time.Millisecond
+	t.Cleanup(func() { watchBookmarkInterval = interval })
+
+	clientset, _ := newResourceWatchClientset()
+	client := newBufconnClient(t, NewServer(clientset, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.client.WatchResources(ctx, &proto.WatchRequest{
+		Namespace:       "default",
+		ResourceVersion: "7",
+		ResourceTypes:   []proto.ResourceType{proto.ResourceType_RESOURCE_TYPE_SERVICES, proto.ResourceType_RESOURCE_TYPE_PODS},
+	})
+	if err != nil {
+		t.Fatalf("WatchResources failed: %v", err)
+	}
+	for _, want := range []proto.ResourceType{proto.ResourceType_RESOURCE_TYPE_SERVICES, proto.ResourceType_RESOURCE_TYPE_PODS} {
+		event, err := stream.Recv()
+		if err != nil || event.Type != bookmarkEvent || event.ResourceType != want || event.Payload != nil || event.ResourceVersion != "7" {
+			t.Fatalf("Expected a %s bookmark at 7, got %v, %v", want, event, err)
+		}
+	}
+}
+
+func TestServerWatchResourcesRejectsTypes(t *testing.T) {
+	client := newBufconnClient(t, NewServer(fake.NewSimpleClientset(), nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, req := range []*proto.WatchRequest{
+		{Namespace: "default"},
+		{Namespace: "default", ResourceTypes: []proto.ResourceType{proto.ResourceType_RESOURCE_TYPE_PODS, proto.ResourceType(42)}},
+	} {
+		stream, err := client.client.WatchResources(ctx, req)
+		if err != nil {
+			t.Fatalf("WatchResources failed: %v", err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+}
+
+func TestClientWatchResources(t *testing.T) {
+	clientset, watchers := newResourceWatchClientset()
+	client := newBufconnClient(t, NewServer(clientset, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, errs := client.WatchResources(ctx, "default", "", proto.ResourceType_RESOURCE_TYPE_PODS, proto.ResourceType_RESOURCE_TYPE_CONFIGMAPS)
+	objects := []runtime.Object{
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", ResourceVersion: "3"}, Data: map[string]string{"mode": "fast"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", ResourceVersion: "4"}},
+	}
+	for _, obj := range objects {
+		if _, ok := obj.(*v1.Pod); ok {
+			go watchers["pods"].Add(obj)
+		} else {
+			go watchers["configmaps"].Add(obj)
+		}
+		select {
+		case event := <-events:
+			switch got := event.Object.(type) {
+			case *v1.ConfigMap:
+				if event.ResourceType != proto.ResourceType_RESOURCE_TYPE_CONFIGMAPS || got.Name != "settings" || got.Data["mode"] != "fast" || event.ResourceVersion != "3" {
+					t.Errorf("Expected the settings configmap at 3, got %+v", event)
+				}
+			case *v1.Pod:
+				if event.ResourceType != proto.ResourceType_RESOURCE_TYPE_PODS || got.Name != "web-1" || event.ResourceVersion != "4" {
+					t.Errorf("Expected the web-1 pod at 4, got %+v", event)
+				}
+			default:
+				t.Errorf("Expected a configmap or pod, got %+v", event)
+			}
+		case err := <-errs:
+			t.Fatalf("Expected an event, got %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for an event")
+		}
+	}
+
+	// The upstream watch ending closes both channels without an error
+	watchers["pods"].Stop()
+	for range events {
+	}
+	if err, ok := <-errs; ok {
+		t.Errorf("Expected no error after the watch ended, got %v", err)
+	}
+}
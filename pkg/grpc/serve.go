@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/reflection"
+	"k8s.io/klog/v2"
+)
+
+// ServeOption configures the server Serve runs
+type ServeOption func(*serveOptions)
+
+// serveOptions are the settings ServeOptions change
+type serveOptions struct {
+	channelz bool
+}
+
+// WithChannelz also registers the channelz service, which reports the
+// channels, sockets and call counts of the server for debugging
+func WithChannelz() ServeOption {
+	return func(o *serveOptions) {
+		o.channelz = true
+	}
+}
+
+// Serve serves srv, and the reflection service DynamicClient relies on, on
+// lis until ctx is done, then stops gracefully: new RPCs are refused and
+// in-flight ones, streams included, get up to shutdownTimeout to finish
+// before they are cancelled
+func Serve(ctx context.Context, lis net.Listener, srv proto.K8SServiceServer, shutdownTimeout time.Duration, opts ...ServeOption) error {
+	var options serveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	server := grpc.NewServer()
+	proto.RegisterK8SServiceServer(server, srv)
+	reflection.Register(server)
+	if options.channelz {
+		channelzservice.RegisterChannelzServiceToServer(server)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(lis)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	klog.Infof("Shutting down gRPC server, waiting up to %s for in-flight RPCs", shutdownTimeout)
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		klog.Warningf("gRPC server did not stop within %s, cancelling in-flight RPCs", shutdownTimeout)
+		server.Stop()
+		<-stopped
+	}
+	return <-errs
+}
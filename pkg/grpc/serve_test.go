@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// slowServer answers ListNamespaces after a delay, or once released
+type slowServer struct {
+	proto.UnimplementedK8SServiceServer
+	delay   time.Duration
+	started chan struct{}
+}
+
+func (s *slowServer) ListNamespaces(ctx context.Context, req *emptypb.Empty) (*proto.NamespaceListResponse, error) {
+	close(s.started)
+	select {
+	case <-time.After(s.delay):
+		return &proto.NamespaceListResponse{Namespaces: []*proto.Namespace{{Name: "default"}}}, nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// startServe runs Serve on a local port and returns a client connection to
+// it and the channel Serve's result is sent on
+func startServe(t *testing.T, ctx context.Context, srv proto.K8SServiceServer, shutdownTimeout time.Duration, opts ...ServeOption) (*grpc.ClientConn, chan error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, lis, srv, shutdownTimeout, opts...)
+	}()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, served
+}
+
+func TestServeFinishesInFlightRPCs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &slowServer{delay: time.Second, started: make(chan struct{})}
+	conn, served := startServe(t, ctx, srv, 30*time.Second)
+	client := proto.NewK8SServiceClient(conn)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.ListNamespaces(context.Background(), &emptypb.Empty{})
+		errs <- err
+	}()
+	<-srv.started
+	cancel()
+
+	if err := <-errs; err != nil {
+		t.Errorf("Expected the in-flight RPC to finish, got %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestServeCancelsRPCsAfterShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &slowServer{delay: time.Hour, started: make(chan struct{})}
+	conn, served := startServe(t, ctx, srv, 100*time.Millisecond)
+	client := proto.NewK8SServiceClient(conn)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.ListNamespaces(context.Background(), &emptypb.Empty{})
+		errs <- err
+	}()
+	<-srv.started
+	cancel()
+
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Serve to stop after the shutdown timeout")
+	}
+	if err := <-errs; status.Code(err) == codes.OK {
+		t.Error("Expected the hanging RPC to fail once the server stopped")
+	}
+}
+
+func TestServeRegistersChannelz(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []ServeOption
+		want codes.Code
+	}{
+		{"disabled", nil, codes.Unimplemented},
+		{"enabled", []ServeOption{WithChannelz()}, codes.OK},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		conn, served := startServe(t, ctx, &slowServer{}, time.Second, tt.opts...)
+		_, err := channelzpb.NewChannelzClient(conn).GetServers(context.Background(), &channelzpb.GetServersRequest{})
+		if status.Code(err) != tt.want {
+			t.Errorf("%s: expected GetServers to return %v, got %v", tt.name, tt.want, err)
+		}
+		cancel()
+		<-served
+	}
+}
@@ -2,19 +2,29 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"time"
 
 	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/util"
+	"k8s-dashboard/pkg/validation"
+	"k8s-dashboard/pkg/version"
 	"k8s-dashboard/proto"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 )
@@ -22,50 +32,85 @@ import (
 // Server implements the gRPC K8sService
 type Server struct {
 	proto.UnimplementedK8SServiceServer
-	clientset *kubernetes.Clientset
+	clientset   kubernetes.Interface
+	guard       *k8s.NamespaceGuard
+	imagePolicy *k8s.ImagePolicy
+	// version is what GetVersion reports
+	version string
 }
 
-// calculateAge calculates the age of a resource from its creation timestamp
-func calculateAge(creationTime metav1.Time) string {
-	duration := time.Since(creationTime.Time)
+// NewServer creates a new gRPC server instance. Mutating RPCs against
+// namespaces protected by guard must carry a matching confirm field.
+func NewServer(clientset kubernetes.Interface, guard *k8s.NamespaceGuard) *Server {
+	return &Server{
+		clientset: clientset,
+		guard:     guard,
+		version:   version.Version,
+	}
+}
+
+// SetImagePolicy rejects pods and deployments whose images come from
+// registries the policy does not allow
+func (s *Server) SetImagePolicy(policy *k8s.ImagePolicy) {
+	s.imagePolicy = policy
+}
 
-	if duration.Hours() > 24 {
-		days := int(duration.Hours() / 24)
-		return fmt.Sprintf("%dd", days)
-	} else if duration.Hours() >= 1 {
-		hours := int(duration.Hours())
-		return fmt.Sprintf("%dh", hours)
-	} else if duration.Minutes() >= 1 {
-		minutes := int(duration.Minutes())
-		return fmt.Sprintf("%dm", minutes)
-	} else {
-		seconds := int(duration.Seconds())
-		return fmt.Sprintf("%ds", seconds)
+// checkNamespace enforces the protected namespace guard for mutating RPCs
+func (s *Server) checkNamespace(namespace, confirm string) error {
+	if err := s.guard.Check(namespace, confirm); err != nil {
+		klog.Warningf("Rejected gRPC mutation: %v", err)
+		return status.Error(codes.FailedPrecondition, err.Error())
 	}
+	return nil
 }
 
-// NewServer creates a new gRPC server instance
-func NewServer(clientset *kubernetes.Clientset) *Server {
-	return &Server{
-		clientset: clientset,
+// invalidArgument converts a validation error into an InvalidArgument status
+// with a BadRequest field violation for each invalid field
+func invalidArgument(err error) error {
+	st := status.New(codes.InvalidArgument, err.Error())
+
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return st.Err()
 	}
+	badRequest := &errdetails.BadRequest{}
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       cause.Field,
+			Description: cause.Message,
+		})
+	}
+	if detailed, detailsErr := st.WithDetails(badRequest); detailsErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// missingSpec rejects a create or update request without a spec
+func missingSpec(kind string) error {
+	return invalidArgument(apierrors.NewInvalid(schema.GroupKind{Kind: kind}, "",
+		field.ErrorList{field.Required(field.NewPath("spec"), "")}))
 }
 
 // ListPods lists pods in the specified namespace
 func (s *Server) ListPods(ctx context.Context, req *proto.ListRequest) (*proto.PodListResponse, error) {
-	pods, err := k8s.ListPods(s.clientset, req.Namespace)
+	pods, err := k8s.ListPodsPage(s.clientset, req.Namespace, req.PageSize, req.PageToken)
 	if err != nil {
 		klog.Errorf("Failed to list pods: %v", err)
 		return nil, err
 	}
 
 	var protoPods []*proto.Pod
-	for _, pod := range pods {
+	for _, pod := range pods.Items {
 		protoPod := s.convertPodToProto(&pod)
 		protoPods = append(protoPods, protoPod)
 	}
 
-	return &proto.PodListResponse{Pods: protoPods}, nil
+	return &proto.PodListResponse{
+		Pods:            protoPods,
+		NextPageToken:   pods.Continue,
+		ResourceVersion: pods.ResourceVersion,
+	}, nil
 }
 
 // ListDeployments lists deployments in the specified namespace
@@ -132,7 +177,7 @@ func (s *Server) ListNamespaces(ctx context.Context, req *emptypb.Empty) (*proto
 		protoNs := &proto.Namespace{
 			Name:   ns.Name,
 			Status: string(ns.Status.Phase),
-			Age:    calculateAge(ns.CreationTimestamp),
+			Age:    util.HumanDuration(time.Since(ns.CreationTimestamp.Time)),
 		}
 		protoNamespaces = append(protoNamespaces, protoNs)
 	}
@@ -140,8 +185,21 @@ func (s *Server) ListNamespaces(ctx context.Context, req *emptypb.Empty) (*proto
 	return &proto.NamespaceListResponse{Namespaces: protoNamespaces}, nil
 }
 
+// GetVersion reports the version of the server, which clients compare to
+// their own
+func (s *Server) GetVersion(ctx context.Context, req *emptypb.Empty) (*proto.VersionResponse, error) {
+	return &proto.VersionResponse{Version: s.version}, nil
+}
+
 // CreatePod creates a new pod
 func (s *Server) CreatePod(ctx context.Context, req *proto.CreatePodRequest) (*proto.PodResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("Pod")
+	}
+
 	// Convert proto spec to Kubernetes pod spec
 	podSpec := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -170,6 +228,13 @@ func (s *Server) CreatePod(ctx context.Context, req *proto.CreatePodRequest) (*p
 		podSpec.Spec.Containers = append(podSpec.Spec.Containers, container)
 	}
 
+	if err := validation.Pod(podSpec); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := s.imagePolicy.CheckObject(podSpec); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	pod, err := s.clientset.CoreV1().Pods(req.Namespace).Create(ctx, podSpec, metav1.CreateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to create pod: %v", err)
@@ -181,6 +246,13 @@ func (s *Server) CreatePod(ctx context.Context, req *proto.CreatePodRequest) (*p
 
 // UpdatePod updates an existing pod
 func (s *Server) UpdatePod(ctx context.Context, req *proto.UpdatePodRequest) (*proto.PodResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("Pod")
+	}
+
 	// Get existing pod
 	existingPod, err := s.clientset.CoreV1().Pods(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
 	if err != nil {
@@ -213,6 +285,13 @@ func (s *Server) UpdatePod(ctx context.Context, req *proto.UpdatePodRequest) (*p
 		existingPod.Spec.Containers = containers
 	}
 
+	if err := validation.Pod(existingPod); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := s.imagePolicy.CheckObject(existingPod); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	pod, err := s.clientset.CoreV1().Pods(req.Namespace).Update(ctx, existingPod, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to update pod: %v", err)
@@ -224,6 +303,10 @@ func (s *Server) UpdatePod(ctx context.Context, req *proto.UpdatePodRequest) (*p
 
 // DeletePod deletes a pod
 func (s *Server) DeletePod(ctx context.Context, req *proto.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+
 	err := s.clientset.CoreV1().Pods(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{})
 	if err != nil {
 		klog.Errorf("Failed to delete pod: %v", err)
@@ -235,6 +318,13 @@ func (s *Server) DeletePod(ctx context.Context, req *proto.DeleteRequest) (*empt
 
 // CreateDeployment creates a new deployment
 func (s *Server) CreateDeployment(ctx context.Context, req *proto.CreateDeploymentRequest) (*proto.DeploymentResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("Deployment")
+	}
+
 	deploymentSpec := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      req.Spec.Name,
@@ -244,11 +334,11 @@ func (s *Server) CreateDeployment(ctx context.Context, req *proto.CreateDeployme
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &req.Spec.Replicas,
 			Selector: &metav1.LabelSelector{
-				MatchLabels: req.Spec.Template.Labels,
+				MatchLabels: req.Spec.GetTemplate().GetLabels(),
 			},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: req.Spec.Template.Labels,
+					Labels: req.Spec.GetTemplate().GetLabels(),
 				},
 				Spec: v1.PodSpec{},
 			},
@@ -256,7 +346,7 @@ func (s *Server) CreateDeployment(ctx context.Context, req *proto.CreateDeployme
 	}
 
 	// Add containers to pod template
-	for _, containerSpec := range req.Spec.Template.Containers {
+	for _, containerSpec := range req.Spec.GetTemplate().GetContainers() {
 		container := v1.Container{
 			Name:  containerSpec.Name,
 			Image: containerSpec.Image,
@@ -272,6 +362,13 @@ func (s *Server) CreateDeployment(ctx context.Context, req *proto.CreateDeployme
 		deploymentSpec.Spec.Template.Spec.Containers = append(deploymentSpec.Spec.Template.Spec.Containers, container)
 	}
 
+	if err := validation.Deployment(deploymentSpec); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := s.imagePolicy.CheckObject(deploymentSpec); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	deployment, err := s.clientset.AppsV1().Deployments(req.Namespace).Create(ctx, deploymentSpec, metav1.CreateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to create deployment: %v", err)
@@ -283,6 +380,13 @@ func (s *Server) CreateDeployment(ctx context.Context, req *proto.CreateDeployme
 
 // UpdateDeployment updates an existing deployment
 func (s *Server) UpdateDeployment(ctx context.Context, req *proto.UpdateDeploymentRequest) (*proto.DeploymentResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("Deployment")
+	}
+
 	// Get existing deployment
 	existingDep, err := s.clientset.AppsV1().Deployments(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
 	if err != nil {
@@ -303,6 +407,10 @@ func (s *Server) UpdateDeployment(ctx context.Context, req *proto.UpdateDeployme
 		existingDep.Spec.Selector.MatchLabels = req.Spec.Template.Labels
 	}
 
+	if err := validation.Deployment(existingDep); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	deployment, err := s.clientset.AppsV1().Deployments(req.Namespace).Update(ctx, existingDep, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to update deployment: %v", err)
@@ -314,6 +422,10 @@ func (s *Server) UpdateDeployment(ctx context.Context, req *proto.UpdateDeployme
 
 // DeleteDeployment deletes a deployment
 func (s *Server) DeleteDeployment(ctx context.Context, req *proto.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+
 	err := s.clientset.AppsV1().Deployments(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{})
 	if err != nil {
 		klog.Errorf("Failed to delete deployment: %v", err)
@@ -325,6 +437,13 @@ func (s *Server) DeleteDeployment(ctx context.Context, req *proto.DeleteRequest)
 
 // CreateService creates a new service
 func (s *Server) CreateService(ctx context.Context, req *proto.CreateServiceRequest) (*proto.ServiceResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("Service")
+	}
+
 	serviceSpec := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      req.Spec.Name,
@@ -346,6 +465,10 @@ func (s *Server) CreateService(ctx context.Context, req *proto.CreateServiceRequ
 		serviceSpec.Spec.Ports = append(serviceSpec.Spec.Ports, servicePort)
 	}
 
+	if err := validation.Service(serviceSpec); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	service, err := s.clientset.CoreV1().Services(req.Namespace).Create(ctx, serviceSpec, metav1.CreateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to create service: %v", err)
@@ -357,6 +480,13 @@ func (s *Server) CreateService(ctx context.Context, req *proto.CreateServiceRequ
 
 // UpdateService updates an existing service
 func (s *Server) UpdateService(ctx context.Context, req *proto.UpdateServiceRequest) (*proto.ServiceResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("Service")
+	}
+
 	// Get existing service
 	existingSvc, err := s.clientset.CoreV1().Services(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
 	if err != nil {
@@ -372,6 +502,10 @@ func (s *Server) UpdateService(ctx context.Context, req *proto.UpdateServiceRequ
 		existingSvc.Spec.Selector = req.Spec.Selector
 	}
 
+	if err := validation.Service(existingSvc); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	service, err := s.clientset.CoreV1().Services(req.Namespace).Update(ctx, existingSvc, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to update service: %v", err)
@@ -383,6 +517,10 @@ func (s *Server) UpdateService(ctx context.Context, req *proto.UpdateServiceRequ
 
 // DeleteService deletes a service
 func (s *Server) DeleteService(ctx context.Context, req *proto.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+
 	err := s.clientset.CoreV1().Services(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{})
 	if err != nil {
 		klog.Errorf("Failed to delete service: %v", err)
@@ -394,6 +532,13 @@ func (s *Server) DeleteService(ctx context.Context, req *proto.DeleteRequest) (*
 
 // CreateConfigMap creates a new configmap
 func (s *Server) CreateConfigMap(ctx context.Context, req *proto.CreateConfigMapRequest) (*proto.ConfigMapResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("ConfigMap")
+	}
+
 	configMapSpec := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      req.Spec.Name,
@@ -403,6 +548,10 @@ func (s *Server) CreateConfigMap(ctx context.Context, req *proto.CreateConfigMap
 		Data: req.Spec.Data,
 	}
 
+	if err := validation.ConfigMap(configMapSpec); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	configMap, err := s.clientset.CoreV1().ConfigMaps(req.Namespace).Create(ctx, configMapSpec, metav1.CreateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to create configmap: %v", err)
@@ -414,6 +563,13 @@ func (s *Server) CreateConfigMap(ctx context.Context, req *proto.CreateConfigMap
 
 // UpdateConfigMap updates an existing configmap
 func (s *Server) UpdateConfigMap(ctx context.Context, req *proto.UpdateConfigMapRequest) (*proto.ConfigMapResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if req.Spec == nil {
+		return nil, missingSpec("ConfigMap")
+	}
+
 	// Get existing configmap
 	existingCm, err := s.clientset.CoreV1().ConfigMaps(req.Namespace).Get(ctx, req.Name, metav1.GetOptions{})
 	if err != nil {
@@ -429,6 +585,10 @@ func (s *Server) UpdateConfigMap(ctx context.Context, req *proto.UpdateConfigMap
 		existingCm.Labels = req.Spec.Labels
 	}
 
+	if err := validation.ConfigMap(existingCm); err != nil {
+		return nil, invalidArgument(err)
+	}
+
 	configMap, err := s.clientset.CoreV1().ConfigMaps(req.Namespace).Update(ctx, existingCm, metav1.UpdateOptions{})
 	if err != nil {
 		klog.Errorf("Failed to update configmap: %v", err)
@@ -440,6 +600,10 @@ func (s *Server) UpdateConfigMap(ctx context.Context, req *proto.UpdateConfigMap
 
 // DeleteConfigMap deletes a configmap
 func (s *Server) DeleteConfigMap(ctx context.Context, req *proto.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+
 	err := s.clientset.CoreV1().ConfigMaps(req.Namespace).Delete(ctx, req.Name, metav1.DeleteOptions{})
 	if err != nil {
 		klog.Errorf("Failed to delete configmap: %v", err)
@@ -449,6 +613,184 @@ func (s *Server) DeleteConfigMap(ctx context.Context, req *proto.DeleteRequest)
 	return &emptypb.Empty{}, nil
 }
 
+// ScaleWorkload sets the replicas of a deployment or statefulset through its
+// scale subresource
+func (s *Server) ScaleWorkload(ctx context.Context, req *proto.ScaleRequest) (*emptypb.Empty, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if !k8s.SupportsScale(req.Kind) {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot scale kind %q, expected deployments or statefulsets", req.Kind)
+	}
+	if req.Replicas < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "replicas must not be negative, got %d", req.Replicas)
+	}
+
+	if err := k8s.ScaleWorkload(ctx, s.clientset, req.Kind, req.Namespace, req.Name, req.Replicas); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RestartWorkload restarts the pods of a deployment, statefulset or daemonset
+// and warns about the pods the restart leaves alone
+func (s *Server) RestartWorkload(ctx context.Context, req *proto.RestartRequest) (*proto.RestartResponse, error) {
+	if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+		return nil, err
+	}
+	if !k8s.SupportsRestart(req.Kind) {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot restart kind %q, expected deployments, statefulsets or daemonsets", req.Kind)
+	}
+
+	now := time.Now()
+	obj, err := k8s.RestartWorkload(ctx, s.clientset, req.Kind, req.Namespace, req.Name, now)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.RestartResponse{
+		RestartedAt: now.Format(time.RFC3339),
+		Warning:     k8s.RestartWarning(obj),
+	}, nil
+}
+
+// rolloutStatusInterval is how often StreamRolloutStatus sends the progress
+// of a rollout
+var rolloutStatusInterval = 5 * time.Second
+
+// defaultRolloutStatusTimeout is how long StreamRolloutStatus streams when
+// the request sets no timeout
+const defaultRolloutStatusTimeout = 10 * time.Minute
+
+// GetDeploymentRolloutStatus returns the rollout progress of a deployment,
+// with its conditions and ReplicaSets
+func (s *Server) GetDeploymentRolloutStatus(ctx context.Context, req *proto.GetRequest) (*proto.RolloutStatusResponse, error) {
+	rolloutStatus, err := k8s.GetDeploymentRolloutStatus(ctx, s.clientset, req.Namespace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return s.convertRolloutStatusToProto(rolloutStatus), nil
+}
+
+// StreamRolloutStatus sends the rollout progress of a deployment every
+// rolloutStatusInterval, starting at once, until the rollout completes or
+// fails. It fails with DeadlineExceeded once the request's timeout passes.
+func (s *Server) StreamRolloutStatus(req *proto.RolloutStatusRequest, stream proto.K8SService_StreamRolloutStatusServer) error {
+	if req.TimeoutSeconds < 0 {
+		return status.Errorf(codes.InvalidArgument, "timeout_seconds must not be negative, got %d", req.TimeoutSeconds)
+	}
+	timeout := defaultRolloutStatusTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(rolloutStatusInterval)
+	defer ticker.Stop()
+
+	for {
+		rolloutStatus, err := k8s.GetDeploymentRolloutStatus(stream.Context(), s.clientset, req.Namespace, req.Name)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.convertRolloutStatusToProto(rolloutStatus)); err != nil {
+			return err
+		}
+		if rolloutStatus.Complete || rolloutStatus.Failed {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return status.Errorf(codes.DeadlineExceeded, "rollout of deployment %s/%s did not complete within %v: %s",
+				req.Namespace, req.Name, timeout, rolloutStatus.Message)
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// ApplyYAML applies the documents of a manifest like kubectl apply and
+// reports what happened to each. A dry run needs no confirmation.
+func (s *Server) ApplyYAML(ctx context.Context, req *proto.ApplyRequest) (*proto.ApplyResponse, error) {
+	opts, err := s.applyOptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := k8s.ApplyYamlDocuments(ctx, s.clientset, req.Namespace, req.YamlContent, opts)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &proto.ApplyResponse{}
+	for _, result := range results {
+		resp.Results = append(resp.Results, s.convertApplyResultToProto(result))
+	}
+	return resp, nil
+}
+
+// ApplyYAMLStream applies a manifest sent in chunks, streaming back the
+// result of each document once it has arrived and been applied. The first
+// message carries the options.
+func (s *Server) ApplyYAMLStream(stream proto.K8SService_ApplyYAMLStreamServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	opts, err := s.applyOptions(first)
+	if err != nil {
+		return err
+	}
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	go func() {
+		req := first
+		for {
+			if _, err := writer.Write([]byte(req.YamlContent)); err != nil {
+				// The manifest stopped being read
+				return
+			}
+			var err error
+			req, err = stream.Recv()
+			if err == io.EOF {
+				writer.Close()
+				return
+			}
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	err = k8s.ApplyYamlStream(stream.Context(), s.clientset, first.Namespace, reader, opts, func(result k8s.ApplyResult) error {
+		return stream.Send(s.convertApplyResultToProto(result))
+	})
+	if err != nil && status.Code(err) == codes.Unknown {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+// applyOptions checks an apply request against the namespace guard and the
+// image policy
+func (s *Server) applyOptions(req *proto.ApplyRequest) (k8s.ApplyOptions, error) {
+	if !req.DryRun {
+		if err := s.checkNamespace(req.Namespace, req.Confirm); err != nil {
+			return k8s.ApplyOptions{}, err
+		}
+	}
+	return k8s.ApplyOptions{
+		DryRun:       req.DryRun,
+		FieldManager: req.FieldManager,
+		Check:        s.imagePolicy.CheckObject,
+	}, nil
+}
+
 // GetPodLogs retrieves logs from a pod
 func (s *Server) GetPodLogs(ctx context.Context, req *proto.PodLogsRequest) (*proto.LogsResponse, error) {
 	logOptions := &v1.PodLogOptions{
@@ -514,7 +856,7 @@ func (s *Server) convertPodToProto(pod *v1.Pod) *proto.Pod {
 		Namespace: pod.Namespace,
 		Status:    string(pod.Status.Phase),
 		Node:      pod.Spec.NodeName,
-		Age:       calculateAge(pod.CreationTimestamp),
+		Age:       util.HumanDuration(time.Since(pod.CreationTimestamp.Time)),
 		Labels:    pod.Labels,
 	}
 
@@ -558,11 +900,54 @@ func (s *Server) convertDeploymentToProto(dep *appsv1.Deployment) *proto.Deploym
 		Replicas:          *dep.Spec.Replicas,
 		ReadyReplicas:     dep.Status.ReadyReplicas,
 		AvailableReplicas: dep.Status.AvailableReplicas,
-		Age:               calculateAge(dep.CreationTimestamp),
+		Age:               util.HumanDuration(time.Since(dep.CreationTimestamp.Time)),
 		Labels:            dep.Labels,
 	}
 }
 
+func (s *Server) convertRolloutStatusToProto(rolloutStatus *k8s.DeploymentRolloutStatus) *proto.RolloutStatusResponse {
+	dep := rolloutStatus.Deployment
+	var desired int32
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	resp := &proto.RolloutStatusResponse{
+		DeploymentName:    dep.Name,
+		DesiredReplicas:   desired,
+		UpdatedReplicas:   dep.Status.UpdatedReplicas,
+		ReadyReplicas:     dep.Status.ReadyReplicas,
+		AvailableReplicas: dep.Status.AvailableReplicas,
+		IsPaused:          dep.Spec.Paused,
+		Complete:          rolloutStatus.Complete,
+		Failed:            rolloutStatus.Failed,
+		Message:           rolloutStatus.Message,
+	}
+	for _, condition := range dep.Status.Conditions {
+		resp.Conditions = append(resp.Conditions, &proto.DeploymentCondition{
+			Type:           string(condition.Type),
+			Status:         string(condition.Status),
+			Reason:         condition.Reason,
+			Message:        condition.Message,
+			LastUpdateTime: condition.LastUpdateTime.UTC().Format(time.RFC3339),
+		})
+	}
+	for _, rs := range rolloutStatus.ReplicaSets {
+		var rsDesired int32
+		if rs.Spec.Replicas != nil {
+			rsDesired = *rs.Spec.Replicas
+		}
+		resp.Replicasets = append(resp.Replicasets, &proto.ReplicaSetSummary{
+			Name:              rs.Name,
+			Revision:          rs.Revision,
+			DesiredReplicas:   rsDesired,
+			ReadyReplicas:     rs.Status.ReadyReplicas,
+			AvailableReplicas: rs.Status.AvailableReplicas,
+			IsCurrent:         rs.Current,
+		})
+	}
+	return resp
+}
+
 func (s *Server) convertServiceToProto(svc *v1.Service) *proto.Service {
 	protoSvc := &proto.Service{
 		Name:       svc.Name,
@@ -570,7 +955,7 @@ func (s *Server) convertServiceToProto(svc *v1.Service) *proto.Service {
 		Type:       string(svc.Spec.Type),
 		ClusterIp:  svc.Spec.ClusterIP,
 		ExternalIp: getExternalIP(svc),
-		Age:        calculateAge(svc.CreationTimestamp),
+		Age:        util.HumanDuration(time.Since(svc.CreationTimestamp.Time)),
 		Labels:     svc.Labels,
 	}
 
@@ -588,11 +973,25 @@ func (s *Server) convertConfigMapToProto(cm *v1.ConfigMap) *proto.ConfigMap {
 		Name:      cm.Name,
 		Namespace: cm.Namespace,
 		Data:      cm.Data,
-		Age:       calculateAge(cm.CreationTimestamp),
+		Age:       util.HumanDuration(time.Since(cm.CreationTimestamp.Time)),
 		Labels:    cm.Labels,
 	}
 }
 
+// convertApplyResultToProto converts the result of applying a document
+func (s *Server) convertApplyResultToProto(result k8s.ApplyResult) *proto.ApplyResult {
+	protoResult := &proto.ApplyResult{
+		Kind:      result.Kind,
+		Name:      result.Name,
+		Namespace: result.Namespace,
+		Action:    string(result.Action),
+	}
+	if result.Err != nil {
+		protoResult.Error = result.Err.Error()
+	}
+	return protoResult
+}
+
 // Helper functions
 
 func getExternalIP(svc *v1.Service) string {
@@ -0,0 +1,527 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestServerProtectedNamespaceRequiresConfirm(t *testing.T) {
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod-eu"}}
+	server := NewServer(fake.NewSimpleClientset(pod), guard)
+
+	_, err = server.DeletePod(context.Background(), &proto.DeleteRequest{Namespace: "prod-eu", Name: "web"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got %v", err)
+	}
+
+	_, err = server.CreatePod(context.Background(), &proto.CreatePodRequest{
+		Namespace: "prod-eu",
+		Spec:      &proto.PodSpec{Name: "other", Containers: []*proto.ContainerSpec{{Name: "c", Image: "nginx"}}},
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for create, got %v", err)
+	}
+
+	_, err = server.DeletePod(context.Background(), &proto.DeleteRequest{Namespace: "prod-eu", Name: "web", Confirm: "prod-eu"})
+	if err != nil {
+		t.Errorf("Expected confirmed delete to succeed, got %v", err)
+	}
+}
+
+func TestServerUnprotectedNamespaceAndReads(t *testing.T) {
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	pods := []runtime.Object{
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod-eu"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	}
+	server := NewServer(fake.NewSimpleClientset(pods...), guard)
+
+	resp, err := server.ListPods(context.Background(), &proto.ListRequest{Namespace: "prod-eu"})
+	if err != nil {
+		t.Fatalf("Expected list in protected namespace to succeed, got %v", err)
+	}
+	if len(resp.Pods) != 1 {
+		t.Errorf("Expected 1 pod, got %d", len(resp.Pods))
+	}
+
+	if _, err := server.DeletePod(context.Background(), &proto.DeleteRequest{Namespace: "default", Name: "web"}); err != nil {
+		t.Errorf("Expected delete in unprotected namespace to succeed, got %v", err)
+	}
+}
+
+func TestServerWorkloads(t *testing.T) {
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	replicas, partition := int32(3), int32(2)
+	server := NewServer(fake.NewSimpleClientset(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod-eu"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+	}), guard)
+	ctx := context.Background()
+
+	restart := &proto.RestartRequest{Kind: "statefulsets", Namespace: "prod-eu", Name: "db"}
+	if _, err := server.RestartWorkload(ctx, restart); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without confirm, got %v", err)
+	}
+	restart.Confirm = "prod-eu"
+	resp, err := server.RestartWorkload(ctx, restart)
+	if err != nil {
+		t.Fatalf("RestartWorkload failed: %v", err)
+	}
+	if resp.RestartedAt == "" || resp.Warning != "rolling update partition is 2, so pods with an ordinal below 2 are not restarted" {
+		t.Errorf("Expected the partition warning, got %+v", resp)
+	}
+
+	invalid := []struct {
+		name string
+		call func() error
+	}{
+		{"restart pods", func() error {
+			_, err := server.RestartWorkload(ctx, &proto.RestartRequest{Kind: "pods", Namespace: "default", Name: "web"})
+			return err
+		}},
+		{"scale daemonsets", func() error {
+			_, err := server.ScaleWorkload(ctx, &proto.ScaleRequest{Kind: "daemonsets", Namespace: "default", Name: "agent", Replicas: 2})
+			return err
+		}},
+		{"negative replicas", func() error {
+			_, err := server.ScaleWorkload(ctx, &proto.ScaleRequest{Kind: "statefulsets", Namespace: "default", Name: "db", Replicas: -1})
+			return err
+		}},
+	}
+	for _, tt := range invalid {
+		if err := tt.call(); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestServerRejectsInvalidSpecs(t *testing.T) {
+	existing := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx"}}},
+	}
+	client := newBufconnClient(t, NewServer(fake.NewSimpleClientset(existing), nil))
+
+	container := func(port int32, protocol string) []*proto.ContainerSpec {
+		return []*proto.ContainerSpec{{
+			Name:  "app",
+			Image: "nginx",
+			Ports: []*proto.PortSpec{{ContainerPort: port, Protocol: protocol}},
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		call  func() error
+		field string
+	}{
+		{"empty pod name", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Containers: container(80, "TCP")})
+			return err
+		}, "metadata.name"},
+		{"malformed pod name", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "Web_1", Containers: container(80, "TCP")})
+			return err
+		}, "metadata.name"},
+		{"no containers", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "web"})
+			return err
+		}, "spec.containers"},
+		{"empty container name", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "web", Containers: []*proto.ContainerSpec{{Image: "nginx"}}})
+			return err
+		}, "spec.containers[0].name"},
+		{"empty image", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "web", Containers: []*proto.ContainerSpec{{Name: "app"}}})
+			return err
+		}, "spec.containers[0].image"},
+		{"zero port", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "web", Containers: container(0, "TCP")})
+			return err
+		}, "spec.containers[0].ports[0].containerPort"},
+		{"port above range", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "web", Containers: container(65536, "TCP")})
+			return err
+		}, "spec.containers[0].ports[0].containerPort"},
+		{"unknown protocol", func() error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "web", Containers: container(80, "HTTP")})
+			return err
+		}, "spec.containers[0].ports[0].protocol"},
+		{"update to invalid image", func() error {
+			_, err := client.UpdatePod("default", "web", &proto.PodSpec{Containers: []*proto.ContainerSpec{{Name: "app"}}})
+			return err
+		}, "spec.containers[0].image"},
+		{"deployment without containers", func() error {
+			_, err := client.CreateDeployment("default", &proto.DeploymentSpec{Name: "web", Replicas: 1})
+			return err
+		}, "spec.template.spec.containers"},
+		{"negative replicas", func() error {
+			_, err := client.CreateDeployment("default", &proto.DeploymentSpec{
+				Name:     "web",
+				Replicas: -1,
+				Template: &proto.PodSpec{Containers: container(80, "TCP")},
+			})
+			return err
+		}, "spec.replicas"},
+		{"service port above range", func() error {
+			_, err := client.CreateService("default", &proto.ServiceSpec{Name: "web", Ports: []*proto.PortSpec{{ContainerPort: 70000}}})
+			return err
+		}, "spec.ports[0].port"},
+		{"service protocol", func() error {
+			_, err := client.CreateService("default", &proto.ServiceSpec{Name: "web", Ports: []*proto.PortSpec{{ContainerPort: 80, Protocol: "ICMP"}}})
+			return err
+		}, "spec.ports[0].protocol"},
+		{"configmap key", func() error {
+			_, err := client.CreateConfigMap("default", &proto.ConfigMapSpec{Name: "app", Data: map[string]string{"bad key": "v"}})
+			return err
+		}, "data[bad key]"},
+		{"missing spec", func() error {
+			_, err := client.client.CreateConfigMap(context.Background(), &proto.CreateConfigMapRequest{Namespace: "default"})
+			return validationError(err)
+		}, "spec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a *ValidationError, got %v", err)
+			}
+			if len(validationErr.Violations) == 0 || validationErr.Violations[0].Field != tt.field {
+				t.Fatalf("Expected a violation of %s, got %+v", tt.field, validationErr.Violations)
+			}
+			if validationErr.Violations[0].Description == "" {
+				t.Error("Expected the violation to be described")
+			}
+		})
+	}
+
+	// Nothing invalid reached the cluster, and the existing pod is unchanged
+	pods, err := client.ListPods("default")
+	if err != nil || len(pods) != 1 || pods[0].Spec.Containers[0].Image != "nginx" {
+		t.Errorf("Expected only the unchanged existing pod, got %v, %v", pods, err)
+	}
+}
+
+func TestServerAcceptsValidSpec(t *testing.T) {
+	client := newBufconnClient(t, NewServer(fake.NewSimpleClientset(), nil))
+
+	pod, err := client.CreatePod("default", &proto.PodSpec{
+		Name: "web",
+		Containers: []*proto.ContainerSpec{{
+			Name:  "app",
+			Image: "nginx",
+			Ports: []*proto.PortSpec{{ContainerPort: 65535, Protocol: "UDP"}, {ContainerPort: 1}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected a valid pod to be created, got %v", err)
+	}
+	if pod.Name != "web" {
+		t.Errorf("Expected pod web, got %s", pod.Name)
+	}
+}
+
+func TestServerEnforcesImagePolicy(t *testing.T) {
+	existing := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "registry.internal:5000/web"}}},
+	}
+	server := NewServer(fake.NewSimpleClientset(existing), nil)
+	server.SetImagePolicy(k8s.NewImagePolicy([]string{"registry.internal:5000"}))
+	client := newBufconnClient(t, server)
+
+	containers := func(image string) []*proto.ContainerSpec {
+		return []*proto.ContainerSpec{{Name: "app", Image: image}}
+	}
+	tests := []struct {
+		name  string
+		call  func(image string) error
+		field string
+	}{
+		{"create pod", func(image string) error {
+			_, err := client.CreatePod("default", &proto.PodSpec{Name: "api", Containers: containers(image)})
+			return err
+		}, "spec.containers[0].image"},
+		{"update pod", func(image string) error {
+			_, err := client.UpdatePod("default", "web", &proto.PodSpec{Containers: containers(image)})
+			return err
+		}, "spec.containers[0].image"},
+		{"create deployment", func(image string) error {
+			_, err := client.CreateDeployment("default", &proto.DeploymentSpec{Name: "api", Replicas: 1, Template: &proto.PodSpec{Containers: containers(image)}})
+			return err
+		}, "spec.template.spec.containers[0].image"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call("registry.internal/web@" + "sha256:" + strings.Repeat("ab", 32))
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a *ValidationError, got %v", err)
+			}
+			if len(validationErr.Violations) != 1 || validationErr.Violations[0].Field != tt.field ||
+				!strings.Contains(validationErr.Violations[0].Description, "allowed registries: registry.internal:5000") {
+				t.Fatalf("Expected a violation of %s naming the allowed registries, got %+v", tt.field, validationErr.Violations)
+			}
+
+			if err := tt.call("registry.internal:5000/web:1.1"); err != nil {
+				t.Errorf("Expected an allowed image to pass, got %v", err)
+			}
+		})
+	}
+}
+
+const applyManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: production
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: app
+    image: nginx:1.27
+`
+
+func TestServerApplyYAML(t *testing.T) {
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	existing := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "prod-eu"},
+		Data:       map[string]string{"mode": "debug"},
+	}
+	clientset := fake.NewSimpleClientset(existing)
+	server := NewServer(clientset, guard)
+	ctx := context.Background()
+
+	// A dry run reports the actions without confirmation or changes
+	resp, err := server.ApplyYAML(ctx, &proto.ApplyRequest{YamlContent: applyManifest, Namespace: "prod-eu", DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected dry run to succeed, got %v", err)
+	}
+	expected := []*proto.ApplyResult{
+		{Kind: "ConfigMap", Name: "settings", Namespace: "prod-eu", Action: "updated"},
+		{Kind: "Pod", Name: "web", Namespace: "prod-eu", Action: "created"},
+	}
+	if len(resp.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %v", len(expected), resp.Results)
+	}
+	for i := range expected {
+		if resp.Results[i].String() != expected[i].String() {
+			t.Errorf("Expected %v, got %v", expected[i], resp.Results[i])
+		}
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("Expected a dry run to only read, got %s %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+
+	_, err = server.ApplyYAML(ctx, &proto.ApplyRequest{YamlContent: applyManifest, Namespace: "prod-eu"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition without confirmation, got %v", err)
+	}
+	resp, err = server.ApplyYAML(ctx, &proto.ApplyRequest{YamlContent: applyManifest, Namespace: "prod-eu", Confirm: "prod-eu", FieldManager: "ci"})
+	if err != nil {
+		t.Fatalf("Expected confirmed apply to succeed, got %v", err)
+	}
+	if resp.Results[0].Action != "updated" || resp.Results[1].Action != "created" {
+		t.Errorf("Expected the configmap updated and the pod created, got %v", resp.Results)
+	}
+	if _, err := clientset.CoreV1().Pods("prod-eu").Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the pod to be created, got %v", err)
+	}
+
+	// Applying again changes nothing
+	resp, err = server.ApplyYAML(ctx, &proto.ApplyRequest{YamlContent: applyManifest, Namespace: "prod-eu", Confirm: "prod-eu"})
+	if err != nil || resp.Results[0].Action != "unchanged" || resp.Results[1].Action != "unchanged" {
+		t.Errorf("Expected both documents unchanged, got %v, %v", resp, err)
+	}
+}
+
+func TestServerApplyYAMLReportsFailedDocuments(t *testing.T) {
+	server := NewServer(fake.NewSimpleClientset(), nil)
+	server.SetImagePolicy(k8s.NewImagePolicy([]string{"registry.internal:5000"}))
+
+	resp, err := server.ApplyYAML(context.Background(), &proto.ApplyRequest{YamlContent: applyManifest, Namespace: "default"})
+	if err != nil {
+		t.Fatalf("Expected failed documents in the results, got %v", err)
+	}
+	if resp.Results[0].Action != "created" || resp.Results[0].Error != "" {
+		t.Errorf("Expected the configmap to be created, got %v", resp.Results[0])
+	}
+	if resp.Results[1].Action != "" || !strings.Contains(resp.Results[1].Error, "allowed registries") {
+		t.Errorf("Expected the pod to be rejected by the image policy, got %v", resp.Results[1])
+	}
+}
+
+// rollingClientset returns a clientset whose deployment web advances through
+// stages, one per get, staying at the last one
+func rollingClientset(stages ...appsv1.DeploymentStatus) *fake.Clientset {
+	replicas := int32(3)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: map[string]string{"deployment.kubernetes.io/revision": "2"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+	newRS := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-2", Namespace: "default",
+		Labels:          map[string]string{"app": "web"},
+		Annotations:     map[string]string{"deployment.kubernetes.io/revision": "2"},
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+	}}
+	clientset := fake.NewSimpleClientset(deployment, newRS)
+
+	var mu sync.Mutex
+	gets := 0
+	clientset.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		progressed := deployment.DeepCopy()
+		progressed.Status = stages[min(gets, len(stages)-1)]
+		gets++
+		return true, progressed, nil
+	})
+	return clientset
+}
+
+func TestServerDeploymentRolloutStatus(t *testing.T) {
+	server := NewServer(rollingClientset(
+		appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, ReadyReplicas: 3, AvailableReplicas: 3},
+		appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3, Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: v1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+		}},
+	), nil)
+	req := &proto.GetRequest{Namespace: "default", Name: "web"}
+
+	resp, err := server.GetDeploymentRolloutStatus(context.Background(), req)
+	if err != nil {
+		t.Fatalf("GetDeploymentRolloutStatus failed: %v", err)
+	}
+	if resp.DeploymentName != "web" || resp.DesiredReplicas != 3 || resp.UpdatedReplicas != 1 || resp.Complete {
+		t.Errorf("Expected the rollout in progress, got %v", resp)
+	}
+	if len(resp.Replicasets) != 1 || resp.Replicasets[0].Name != "web-2" || !resp.Replicasets[0].IsCurrent {
+		t.Errorf("Expected the current ReplicaSet, got %v", resp.Replicasets)
+	}
+
+	resp, err = server.GetDeploymentRolloutStatus(context.Background(), req)
+	if err != nil || !resp.Complete || resp.AvailableReplicas != 3 || len(resp.Conditions) != 1 || resp.Conditions[0].Reason != "NewReplicaSetAvailable" {
+		t.Errorf("Expected the rollout to be complete, got %v, %v", resp, err)
+	}
+}
+
+func TestServerStreamRolloutStatus(t *testing.T) {
+	saved := rolloutStatusInterval
+	rolloutStatusInterval = 10 * time.Millisecond
+	t.Cleanup(func() { rolloutStatusInterval = saved })
+
+	client := newBufconnClient(t, NewServer(rollingClientset(
+		appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3},
+		appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3},
+		appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2},
+		appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+	), nil))
+	stream, err := client.client.StreamRolloutStatus(context.Background(), &proto.RolloutStatusRequest{Namespace: "default", Name: "web"})
+	if err != nil {
+		t.Fatalf("StreamRolloutStatus failed: %v", err)
+	}
+	var messages []string
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Expected the stream to end with the rollout, got %v", err)
+		}
+		messages = append(messages, resp.Message)
+	}
+	want := []string{
+		"1 out of 3 new replicas have been updated",
+		"1 old replicas are pending termination",
+		"2 of 3 updated replicas are available",
+		"successfully rolled out",
+	}
+	if len(messages) != len(want) {
+		t.Fatalf("Expected %d updates, got %q", len(want), messages)
+	}
+	for i := range want {
+		if !strings.Contains(messages[i], want[i]) {
+			t.Errorf("Update %d: expected %q, got %q", i, want[i], messages[i])
+		}
+	}
+}
+
+func TestServerStreamRolloutStatusTimeout(t *testing.T) {
+	saved := rolloutStatusInterval
+	rolloutStatusInterval = 10 * time.Millisecond
+	t.Cleanup(func() { rolloutStatusInterval = saved })
+
+	client := newBufconnClient(t, NewServer(rollingClientset(
+		appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3},
+	), nil))
+	stream, err := client.client.StreamRolloutStatus(context.Background(), &proto.RolloutStatusRequest{Namespace: "default", Name: "web", TimeoutSeconds: 1})
+	if err != nil {
+		t.Fatalf("StreamRolloutStatus failed: %v", err)
+	}
+	updates := 0
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		updates++
+	}
+	if status.Code(err) != codes.DeadlineExceeded || !strings.Contains(err.Error(), "1 out of 3") {
+		t.Errorf("Expected DeadlineExceeded with the last progress, got %v", err)
+	}
+	if updates < 2 {
+		t.Errorf("Expected several updates before the timeout, got %d", updates)
+	}
+}
@@ -0,0 +1,255 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/proto"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// WatchExpiredReason is the ErrorInfo reason of a watch whose resource
+	// version is too old to resume from; the watcher has to list again
+	WatchExpiredReason = "WATCH_EXPIRED"
+	// errorDomain is the ErrorInfo domain of kgo's typed errors
+	errorDomain = "k8s-dashboard"
+
+	// bookmarkEvent is the type of events that only carry a resource version
+	bookmarkEvent = "BOOKMARK"
+	// PodsResynced is the type of the PodEvent sent after an expired watch
+	// was replaced by a new list
+	PodsResynced = "RESYNCED"
+)
+
+// watchBookmarkInterval is how often watch streams send a bookmark, keeping
+// idle streams alive through load balancers
+var watchBookmarkInterval = 30 * time.Second
+
+// watchExpired returns the typed error of an expired watch
+func watchExpired(err error) error {
+	st, detailErr := status.New(codes.OutOfRange, err.Error()).WithDetails(&errdetails.ErrorInfo{
+		Reason: WatchExpiredReason,
+		Domain: errorDomain,
+	})
+	if detailErr != nil {
+		return status.Error(codes.OutOfRange, err.Error())
+	}
+	return st.Err()
+}
+
+// IsWatchExpired reports whether a watch stream failed because its resource
+// version is too old to resume from
+func IsWatchExpired(err error) bool {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == WatchExpiredReason && info.Domain == errorDomain {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchPods streams the changes to the pods of a namespace after the
+// requested resource version, with a bookmark every watchBookmarkInterval.
+// The stream ends when the upstream watch does; the client resumes it from
+// the last resource version it received.
+func (s *Server) WatchPods(req *proto.WatchRequest, stream proto.K8SService_WatchPodsServer) error {
+	watcher, err := k8s.WatchPods(s.clientset, req.Namespace, req.ResourceVersion)
+	if err != nil {
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			return watchExpired(err)
+		}
+		klog.Errorf("Failed to watch pods: %v", err)
+		return err
+	}
+	defer watcher.Stop()
+
+	ticker := time.NewTicker(watchBookmarkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+			if err := stream.Send(&proto.PodWatchEvent{Type: bookmarkEvent, ResourceVersion: watcher.ResourceVersion()}); err != nil {
+				return err
+			}
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			if event.Type == watch.Error {
+				err := apierrors.FromObject(event.Object)
+				if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return watchExpired(err)
+				}
+				klog.Errorf("Pod watch failed: %v", err)
+				return err
+			}
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(&proto.PodWatchEvent{
+				Type:            string(event.Type),
+				Pod:             s.convertPodToProto(pod),
+				ResourceVersion: pod.ResourceVersion,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// PodEvent is a change to the pods watched with Client.WatchPods
+type PodEvent struct {
+	// Type is ADDED, MODIFIED or DELETED, or PodsResynced when the watch
+	// expired and the pods were listed again
+	Type string
+	// Pod is the changed pod
+	Pod *v1.Pod
+	// Pods holds every pod of the namespace after a resync
+	Pods []v1.Pod
+	// ResourceVersion is where the watch continues from
+	ResourceVersion string
+}
+
+// WatchPods watches the pods of a namespace after resourceVersion, usually
+// that of a list. A dropped stream is resumed from the last resource version
+// received, bookmarks included, with the backoff of StreamWithRetry. When the
+// server reports the watch expired, the pods are listed again and sent as one
+// PodsResynced event before the watch goes on.
+//
+// Every failed attempt's error is sent on the error channel. Both channels are
+// closed when ctx is done or the watch fails with an error other than
+// codes.Unavailable, which is sent last.
+func (c *Client) WatchPods(ctx context.Context, namespace, resourceVersion string) (<-chan PodEvent, <-chan error) {
+	open := func(ctx context.Context, resourceVersion string) (grpc.ServerStreamingClient[proto.PodWatchEvent], error) {
+		return c.client.WatchPods(ctx, &proto.WatchRequest{Namespace: namespace, ResourceVersion: resourceVersion})
+	}
+	list := func(ctx context.Context) ([]v1.Pod, string, error) {
+		var pods []v1.Pod
+		resourceVersion, err := c.ForEachPod(ctx, namespace, defaultPageSize, func(pod v1.Pod) error {
+			pods = append(pods, pod)
+			return nil
+		})
+		return pods, resourceVersion, err
+	}
+	return c.watchPods(ctx, resourceVersion, open, list)
+}
+
+// podWatchOpener opens a pod watch stream after a resource version
+type podWatchOpener func(ctx context.Context, resourceVersion string) (grpc.ServerStreamingClient[proto.PodWatchEvent], error)
+
+// podLister lists the watched pods and the resource version of the list
+type podLister func(ctx context.Context) ([]v1.Pod, string, error)
+
+// watchPods runs the resume logic of WatchPods over open and list
+func (c *Client) watchPods(ctx context.Context, resourceVersion string, open podWatchOpener, list podLister) (<-chan PodEvent, <-chan error) {
+	events := make(chan PodEvent)
+	errs := make(chan error)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		backoff := streamRetryInitialBackoff
+		for {
+			received, err := c.receivePodWatch(ctx, open, &resourceVersion, events)
+			if ctx.Err() != nil {
+				return
+			}
+			if received {
+				backoff = streamRetryInitialBackoff
+			}
+
+			if IsWatchExpired(err) {
+				klog.Warningf("Pod watch expired at resource version %s, listing again: %v", resourceVersion, err)
+				var pods []v1.Pod
+				var listVersion string
+				if pods, listVersion, err = list(ctx); err == nil {
+					resourceVersion = listVersion
+					select {
+					case events <- PodEvent{Type: PodsResynced, Pods: pods, ResourceVersion: listVersion}:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+			}
+
+			if err != nil {
+				select {
+				case errs <- err:
+				case <-ctx.Done():
+					return
+				}
+				if status.Code(err) != codes.Unavailable {
+					return
+				}
+			} else if received {
+				// The upstream watch timed out; carry on at once
+				continue
+			}
+
+			delay := backoff/2 + rand.N(backoff/2+1)
+			klog.Warningf("Pod watch ended, resuming from resource version %s in %v", resourceVersion, delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+			backoff = min(backoff*2, streamRetryMaxBackoff)
+		}
+	}()
+
+	return events, errs
+}
+
+// receivePodWatch opens a watch after *resourceVersion and forwards its
+// changes to events, keeping *resourceVersion at the last one received. It
+// returns nil when the server ends the stream, and whether any message
+// arrived.
+func (c *Client) receivePodWatch(ctx context.Context, open podWatchOpener, resourceVersion *string, events chan<- PodEvent) (bool, error) {
+	stream, err := open(ctx, *resourceVersion)
+	if err != nil {
+		return false, err
+	}
+
+	received := false
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		received = true
+		if event.ResourceVersion != "" {
+			*resourceVersion = event.ResourceVersion
+		}
+		if event.Type == bookmarkEvent || event.Pod == nil {
+			continue
+		}
+
+		select {
+		case events <- PodEvent{Type: event.Type, Pod: c.convertProtoToPod(event.Pod), ResourceVersion: event.ResourceVersion}:
+		case <-ctx.Done():
+			return received, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// scriptedWatchStream plays back events, then fails with err, or ends
+// cleanly when err is nil
+type scriptedWatchStream struct {
+	grpc.ClientStream
+	events []*proto.PodWatchEvent
+	err    error
+}
+
+func (s *scriptedWatchStream) Recv() (*proto.PodWatchEvent, error) {
+	if len(s.events) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+// podWatchEvent is a scripted event about a pod
+func podWatchEvent(eventType, name, resourceVersion string) *proto.PodWatchEvent {
+	return &proto.PodWatchEvent{
+		Type:            eventType,
+		Pod:             &proto.Pod{Name: name, Namespace: "default", Status: "Running"},
+		ResourceVersion: resourceVersion,
+	}
+}
+
+// drainPodWatch collects a watch's events and errors until both are closed
+func drainPodWatch(t *testing.T, events <-chan PodEvent, errs <-chan error) ([]string, []error) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	var got []string
+	var failures []error
+	for events != nil || errs != nil {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if event.Type == PodsResynced {
+				got = append(got, fmt.Sprintf("%s %d pods @%s", event.Type, len(event.Pods), event.ResourceVersion))
+			} else {
+				got = append(got, fmt.Sprintf("%s %s @%s", event.Type, event.Pod.Name, event.ResourceVersion))
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			failures = append(failures, err)
+		case <-timeout:
+			t.Fatal("Timed out waiting for the watch to end")
+		}
+	}
+	return got, failures
+}
+
+func TestWatchPodsResumesAndResyncs(t *testing.T) {
+	useFastStreamRetry(t)
+
+	// Each stream expects the resource version the previous one left off at
+	scripts := []struct {
+		resourceVersion string
+		stream          *scriptedWatchStream
+		err             error
+	}{
+		// Dropped after a bookmark: resume from the bookmark without a list
+		{"10", &scriptedWatchStream{events: []*proto.PodWatchEvent{
+			podWatchEvent("ADDED", "web-1", "11"),
+			{Type: bookmarkEvent, ResourceVersion: "15"},
+		}, err: status.Error(codes.Unavailable, "idle timeout")}, nil},
+		// Expired: list again and send one resync
+		{"15", &scriptedWatchStream{events: []*proto.PodWatchEvent{
+			podWatchEvent("MODIFIED", "web-1", "16"),
+		}, err: watchExpired(apierrors.NewResourceExpired("too old resource version: 16"))}, nil},
+		// Ended by the server: resume at once from the last event
+		{"20", &scriptedWatchStream{events: []*proto.PodWatchEvent{
+			podWatchEvent("DELETED", "web-2", "21"),
+		}}, nil},
+		{"21", nil, status.Error(codes.PermissionDenied, "forbidden")},
+	}
+	var opened []string
+	open := func(ctx context.Context, resourceVersion string) (grpc.ServerStreamingClient[proto.PodWatchEvent], error) {
+		opened = append(opened, resourceVersion)
+		if len(opened) > len(scripts) {
+			t.Errorf("Unexpected watch from %s", resourceVersion)
+			return nil, status.Error(codes.Internal, "unexpected watch")
+		}
+		script := scripts[len(opened)-1]
+		if resourceVersion != script.resourceVersion {
+			t.Errorf("Expected watch %d from %s, got %s", len(opened), script.resourceVersion, resourceVersion)
+		}
+		if script.err != nil {
+			return nil, script.err
+		}
+		return script.stream, nil
+	}
+	lists := 0
+	list := func(ctx context.Context) ([]v1.Pod, string, error) {
+		lists++
+		return []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web-1"}}, {ObjectMeta: metav1.ObjectMeta{Name: "web-2"}}}, "20", nil
+	}
+
+	client := &Client{}
+	events, errs := client.watchPods(context.Background(), "10", open, list)
+	got, failures := drainPodWatch(t, events, errs)
+
+	want := []string{"ADDED web-1 @11", "MODIFIED web-1 @16", "RESYNCED 2 pods @20", "DELETED web-2 @21"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if len(failures) != 2 || status.Code(failures[0]) != codes.Unavailable || status.Code(failures[1]) != codes.PermissionDenied {
+		t.Errorf("Expected the dropped stream and the final PermissionDenied, got %v", failures)
+	}
+	if lists != 1 {
+		t.Errorf("Expected a single list after the expiry, got %d", lists)
+	}
+	if len(opened) != len(scripts) {
+		t.Errorf("Expected %d watches, got %v", len(scripts), opened)
+	}
+}
+
+func TestWatchPodsRelistFailure(t *testing.T) {
+	useFastStreamRetry(t)
+
+	expired := watchExpired(apierrors.NewResourceExpired("too old"))
+	open := func(ctx context.Context, resourceVersion string) (grpc.ServerStreamingClient[proto.PodWatchEvent], error) {
+		return nil, expired
+	}
+	list := func(ctx context.Context) ([]v1.Pod, string, error) {
+		return nil, "", status.Error(codes.PermissionDenied, "cannot list pods")
+	}
+
+	events, errs := (&Client{}).watchPods(context.Background(), "5", open, list)
+	got, failures := drainPodWatch(t, events, errs)
+	if len(got) != 0 {
+		t.Errorf("Expected no events, got %v", got)
+	}
+	if len(failures) != 1 || status.Code(failures[0]) != codes.PermissionDenied {
+		t.Errorf("Expected the list error, got %v", failures)
+	}
+}
+
+func TestServerWatchPods(t *testing.T) {
+	interval := watchBookmarkInterval
+	watchBookmarkInterval = 20 * time.Millisecond
+	t.Cleanup(func() { watchBookmarkInterval = interval })
+
+	clientset := fake.NewSimpleClientset()
+	fakeWatch := watch.NewFake()
+	clientset.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		if action.(k8stesting.WatchActionImpl).WatchRestrictions.ResourceVersion == "1" {
+			return true, nil, apierrors.NewResourceExpired("too old resource version: 1")
+		}
+		return true, fakeWatch, nil
+	})
+	client := newBufconnClient(t, NewServer(clientset, nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.client.WatchPods(ctx, &proto.WatchRequest{Namespace: "default", ResourceVersion: "7"})
+	if err != nil {
+		t.Fatalf("WatchPods failed: %v", err)
+	}
+	go fakeWatch.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", ResourceVersion: "8"}})
+
+	event, err := stream.Recv()
+	if err != nil || event.Type != "ADDED" || event.Pod.GetName() != "web-1" || event.ResourceVersion != "8" {
+		t.Fatalf("Expected web-1 to be added at 8, got %v, %v", event, err)
+	}
+	event, err = stream.Recv()
+	if err != nil || event.Type != bookmarkEvent || event.Pod != nil || event.ResourceVersion != "8" {
+		t.Fatalf("Expected a bookmark at 8, got %v, %v", event, err)
+	}
+
+	// A 410 in the watch becomes the typed expiry error
+	go fakeWatch.Error(&apierrors.NewResourceExpired("too old resource version: 8").ErrStatus)
+	for {
+		if event, err = stream.Recv(); err != nil {
+			break
+		}
+		if event.Type != bookmarkEvent {
+			t.Fatalf("Expected only bookmarks before the error, got %v", event)
+		}
+	}
+	if !IsWatchExpired(err) || status.Code(err) != codes.OutOfRange {
+		t.Errorf("Expected a WATCH_EXPIRED error, got %v", err)
+	}
+
+	// So does a resource version the API server rejects at once
+	stream, err = client.client.WatchPods(ctx, &proto.WatchRequest{Namespace: "default", ResourceVersion: "1"})
+	if err != nil {
+		t.Fatalf("WatchPods failed: %v", err)
+	}
+	if _, err := stream.Recv(); !IsWatchExpired(err) {
+		t.Errorf("Expected a WATCH_EXPIRED error, got %v", err)
+	}
+	if IsWatchExpired(status.Error(codes.OutOfRange, "other")) {
+		t.Error("Expected an error without the ErrorInfo not to count as expired")
+	}
+}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// MaxProbeFailureDuration caps how long a probe failure is injected for
+const MaxProbeFailureDuration = time.Hour
+
+// probeFailureRevertTimeout bounds the revert of InjectProbeFailure, which
+// runs even once its context is cancelled
+const probeFailureRevertTimeout = 30 * time.Second
+
+// failingProbeCommand is the exec command of an injected liveness probe
+var failingProbeCommand = []string{"false"}
+
+// ErrProbeFailureActive is returned when the liveness probe of a container
+// already fails by injection
+var ErrProbeFailureActive = errors.New("a probe failure is already injected")
+
+// ErrNoLivenessProbe is returned when the container to fail the liveness
+// probe of has none, or does not exist
+var ErrNoLivenessProbe = errors.New("no liveness probe to fail")
+
+// FailingProbe returns a copy of a probe that always fails: its handler is
+// replaced by the exec command false, its timings are kept so that the
+// kubelet restarts the container as it would on a real failure
+func FailingProbe(probe *v1.Probe) *v1.Probe {
+	failing := probe.DeepCopy()
+	failing.ProbeHandler = v1.ProbeHandler{Exec: &v1.ExecAction{Command: failingProbeCommand}}
+	return failing
+}
+
+// isFailingProbe reports whether a probe is one FailingProbe returned
+func isFailingProbe(probe *v1.Probe) bool {
+	return probe != nil && probe.Exec != nil && reflect.DeepEqual(probe.Exec.Command, failingProbeCommand)
+}
+
+// LivenessProbePatch returns the JSON patch replacing the liveness probe of a
+// container of a deployment's pod template with probe. It tests the name of
+// the container at the index it replaces, and the probe it replaces when
+// current is set, so that it fails rather than clobber a change made since
+// the deployment was read.
+func LivenessProbePatch(deployment *appsv1.Deployment, containerName string, current, probe *v1.Probe) ([]byte, error) {
+	for i, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != containerName {
+			continue
+		}
+		path := fmt.Sprintf("/spec/template/spec/containers/%d", i)
+		ops := []map[string]interface{}{{"op": "test", "path": path + "/name", "value": containerName}}
+		if current != nil {
+			ops = append(ops, map[string]interface{}{"op": "test", "path": path + "/livenessProbe", "value": current})
+		}
+		ops = append(ops, map[string]interface{}{"op": "replace", "path": path + "/livenessProbe", "value": probe})
+		patch, err := json.Marshal(ops)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build the probe patch: %v", err)
+		}
+		return patch, nil
+	}
+	return nil, fmt.Errorf("deployment %s has no container %s", deployment.Name, containerName)
+}
+
+// StartProbeFailure makes the liveness probe of a container of a deployment
+// fail, which rolls out pods whose container the kubelet keeps restarting. It
+// returns the probe RevertProbeFailure restores.
+func StartProbeFailure(ctx context.Context, clientset kubernetes.Interface, namespace, deploymentName, containerName string) (*v1.Probe, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get deployment %s/%s: %v", namespace, deploymentName, err)
+		return nil, err
+	}
+	var original *v1.Probe
+	found := false
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == containerName {
+			original, found = container.LivenessProbe, true
+		}
+	}
+	switch {
+	case !found:
+		return nil, fmt.Errorf("%w: deployment %s has no container %s", ErrNoLivenessProbe, deploymentName, containerName)
+	case original == nil:
+		return nil, fmt.Errorf("%w: container %s of deployment %s has none", ErrNoLivenessProbe, containerName, deploymentName)
+	case isFailingProbe(original):
+		return nil, fmt.Errorf("%w on container %s of deployment %s", ErrProbeFailureActive, containerName, deploymentName)
+	}
+
+	patch, err := LivenessProbePatch(deployment, containerName, original, FailingProbe(original))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, deploymentName, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager}); err != nil {
+		klog.Errorf("Failed to inject a probe failure into deployment %s/%s: %v", namespace, deploymentName, err)
+		return nil, err
+	}
+	klog.Infof("Injected a liveness probe failure into container %s of deployment %s/%s", containerName, namespace, deploymentName)
+	return original, nil
+}
+
+// RevertProbeFailure restores the liveness probe StartProbeFailure replaced.
+// It fails rather than overwrite a probe changed since.
+func RevertProbeFailure(ctx context.Context, clientset kubernetes.Interface, namespace, deploymentName, containerName string, original *v1.Probe) error {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get deployment %s/%s: %v", namespace, deploymentName, err)
+		return err
+	}
+	patch, err := LivenessProbePatch(deployment, containerName, FailingProbe(original), original)
+	if err != nil {
+		return err
+	}
+	if _, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, deploymentName, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager}); err != nil {
+		klog.Errorf("Failed to revert the probe failure of deployment %s/%s: %v", namespace, deploymentName, err)
+		return err
+	}
+	klog.Infof("Reverted the liveness probe of container %s of deployment %s/%s", containerName, namespace, deploymentName)
+	return nil
+}
+
+// CheckProbeFailureDuration checks that a probe failure lasts between a
+// second and MaxProbeFailureDuration
+func CheckProbeFailureDuration(duration time.Duration) error {
+	if duration < time.Second || duration > MaxProbeFailureDuration {
+		return fmt.Errorf("duration must be between 1s and %v, got %v", MaxProbeFailureDuration, duration)
+	}
+	return nil
+}
+
+// InjectProbeFailure makes the liveness probe of a container of a deployment
+// fail for duration, then reverts it. It blocks until reverted; cancelling
+// ctx ends the failure early, and the revert still runs.
+func InjectProbeFailure(ctx context.Context, clientset kubernetes.Interface, namespace, deploymentName, containerName string, duration time.Duration) error {
+	if err := CheckProbeFailureDuration(duration); err != nil {
+		return err
+	}
+	original, err := StartProbeFailure(ctx, clientset, namespace, deploymentName, containerName)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	revertCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeFailureRevertTimeout)
+	defer cancel()
+	return RevertProbeFailure(revertCtx, clientset, namespace, deploymentName, containerName, original)
+}
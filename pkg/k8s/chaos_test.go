@@ -0,0 +1,175 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// webLivenessProbe returns the liveness probe of the web container of
+// probedDeployment as stored in clientset
+func webLivenessProbe(t *testing.T, clientset *fake.Clientset) *v1.Probe {
+	t.Helper()
+	deployment, err := clientset.AppsV1().Deployments("shop").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	return deployment.Spec.Template.Spec.Containers[1].LivenessProbe
+}
+
+func TestLivenessProbePatch(t *testing.T) {
+	deployment := probedDeployment()
+	original := deployment.Spec.Template.Spec.Containers[1].LivenessProbe
+	failing := FailingProbe(original)
+
+	patch, err := LivenessProbePatch(deployment, "web", original, failing)
+	if err != nil {
+		t.Fatalf("LivenessProbePatch failed: %v", err)
+	}
+	want := `[{"op":"test","path":"/spec/template/spec/containers/1/name","value":"web"},` +
+		`{"op":"test","path":"/spec/template/spec/containers/1/livenessProbe","value":{"tcpSocket":{"port":8080}}},` +
+		`{"op":"replace","path":"/spec/template/spec/containers/1/livenessProbe","value":{"exec":{"command":["false"]}}}]`
+	if string(patch) != want {
+		t.Errorf("Expected patch\n%s\ngot\n%s", want, patch)
+	}
+
+	if _, err := LivenessProbePatch(deployment, "api", nil, failing); err == nil || !strings.Contains(err.Error(), "no container api") {
+		t.Errorf("Expected an error for a missing container, got %v", err)
+	}
+}
+
+func TestFailingProbeKeepsTimings(t *testing.T) {
+	probe := &v1.Probe{
+		ProbeHandler:     v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz"}},
+		PeriodSeconds:    5,
+		FailureThreshold: 2,
+	}
+	failing := FailingProbe(probe)
+	if failing.HTTPGet != nil || !reflect.DeepEqual(failing.Exec.Command, []string{"false"}) {
+		t.Errorf("Expected only an exec false handler, got %+v", failing.ProbeHandler)
+	}
+	if failing.PeriodSeconds != 5 || failing.FailureThreshold != 2 {
+		t.Errorf("Expected the timings to be kept, got %+v", failing)
+	}
+	if probe.HTTPGet == nil {
+		t.Error("Expected the original probe to be left alone")
+	}
+}
+
+func TestStartAndRevertProbeFailure(t *testing.T) {
+	clientset := fake.NewSimpleClientset(probedDeployment())
+	ctx := context.Background()
+	original := webLivenessProbe(t, clientset)
+
+	saved, err := StartProbeFailure(ctx, clientset, "shop", "web", "web")
+	if err != nil {
+		t.Fatalf("StartProbeFailure failed: %v", err)
+	}
+	if !reflect.DeepEqual(saved, original) {
+		t.Errorf("Expected the original probe to be returned, got %+v", saved)
+	}
+	if probe := webLivenessProbe(t, clientset); !isFailingProbe(probe) {
+		t.Fatalf("Expected the liveness probe to fail, got %+v", probe)
+	}
+
+	// A second injection would save the failing probe as the original
+	if _, err := StartProbeFailure(ctx, clientset, "shop", "web", "web"); !errors.Is(err, ErrProbeFailureActive) {
+		t.Errorf("Expected ErrProbeFailureActive, got %v", err)
+	}
+
+	if err := RevertProbeFailure(ctx, clientset, "shop", "web", "web", saved); err != nil {
+		t.Fatalf("RevertProbeFailure failed: %v", err)
+	}
+	if probe := webLivenessProbe(t, clientset); !reflect.DeepEqual(probe, original) {
+		t.Errorf("Expected the original probe back, got %+v", probe)
+	}
+
+	// Reverting again finds another probe than the failing one, and leaves it
+	if err := RevertProbeFailure(ctx, clientset, "shop", "web", "web", saved); err == nil {
+		t.Error("Expected reverting a probe that no longer fails to be refused")
+	}
+}
+
+func TestStartProbeFailureErrors(t *testing.T) {
+	deployment := probedDeployment()
+	deployment.Spec.Template.Spec.Containers[1].LivenessProbe = nil
+	clientset := fake.NewSimpleClientset(deployment)
+
+	tests := []struct {
+		deployment, container string
+		want                  string
+	}{
+		{"web", "web", "has none"},
+		{"web", "api", "no container api"},
+		{"shop", "web", "not found"},
+	}
+	for _, tt := range tests {
+		if _, err := StartProbeFailure(context.Background(), clientset, "shop", tt.deployment, tt.container); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s/%s: expected an error containing %q, got %v", tt.deployment, tt.container, tt.want, err)
+		}
+	}
+	if _, err := StartProbeFailure(context.Background(), clientset, "shop", "web", "web"); !errors.Is(err, ErrNoLivenessProbe) {
+		t.Errorf("Expected ErrNoLivenessProbe, got %v", err)
+	}
+}
+
+func TestInjectProbeFailure(t *testing.T) {
+	clientset := fake.NewSimpleClientset(probedDeployment())
+	original := webLivenessProbe(t, clientset)
+
+	// Record the probes the patches write
+	var patched []*v1.Probe
+	clientset.PrependReactor("patch", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := clientset.Tracker().Get(appsv1.SchemeGroupVersion.WithResource("deployments"), "shop", "web")
+		if err == nil {
+			patched = append(patched, obj.(*appsv1.Deployment).Spec.Template.Spec.Containers[1].LivenessProbe)
+		}
+		return false, nil, nil
+	})
+
+	start := time.Now()
+	if err := InjectProbeFailure(context.Background(), clientset, "shop", "web", "web", time.Second); err != nil {
+		t.Fatalf("InjectProbeFailure failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected the failure to last a second, reverted after %v", elapsed)
+	}
+	if probe := webLivenessProbe(t, clientset); !reflect.DeepEqual(probe, original) {
+		t.Errorf("Expected the original probe back, got %+v", probe)
+	}
+	// The reactor sees each deployment before its patch: the original, then
+	// the failing probe
+	if len(patched) != 2 || !reflect.DeepEqual(patched[0], original) || !isFailingProbe(patched[1]) {
+		t.Errorf("Expected a failing patch then a revert, got %+v", patched)
+	}
+
+	// Cancelling ends the failure early, and still reverts
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start = time.Now()
+	if err := InjectProbeFailure(ctx, clientset, "shop", "web", "web", time.Hour); err != nil {
+		t.Fatalf("InjectProbeFailure failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected cancelling to end the failure, took %v", elapsed)
+	}
+	if probe := webLivenessProbe(t, clientset); !reflect.DeepEqual(probe, original) {
+		t.Errorf("Expected the original probe back after cancelling, got %+v", probe)
+	}
+
+	for _, duration := range []time.Duration{0, time.Millisecond, 2 * time.Hour} {
+		if err := InjectProbeFailure(context.Background(), clientset, "shop", "web", "web", duration); err == nil {
+			t.Errorf("Expected a duration of %v to be refused", duration)
+		}
+	}
+}
@@ -1,18 +1,33 @@
 package k8s
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	appsv1 "k8s.io/api/apps/v1"
+	authv1 "k8s.io/api/authentication/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -20,42 +35,100 @@ import (
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/yaml"
 )
 
-// NewClient creates a new Kubernetes clientset from kubeconfig or in-cluster config
-func NewClient(kubeconfig string) (kubernetes.Interface, error) {
-	var config *rest.Config
-	var err error
+// NewClient creates a new Kubernetes clientset from kubeconfig or in-cluster
+// config, and describes the cluster it was configured for
+func NewClient(kubeconfig string) (kubernetes.Interface, *ClientInfo, error) {
+	config, info, err := buildConfig(kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Errorf("Failed to create clientset: %v", err)
+		return nil, nil, err
+	}
+
+	return clientset, info, nil
+}
+
+// NewDynamicClient creates a dynamic client from kubeconfig or in-cluster config
+func NewDynamicClient(kubeconfig string) (dynamic.Interface, error) {
+	config, _, err := buildConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		klog.Errorf("Failed to create dynamic client: %v", err)
+		return nil, err
+	}
+	return dynamicClient, nil
+}
+
+// NewMetricsClient creates a metrics.k8s.io client from kubeconfig or
+// in-cluster config. Creating it succeeds without metrics-server; only its
+// requests fail.
+func NewMetricsClient(kubeconfig string) (metricsclientset.Interface, error) {
+	config, _, err := buildConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	metricsClient, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		klog.Errorf("Failed to create metrics client: %v", err)
+		return nil, err
+	}
+	return metricsClient, nil
+}
 
+// buildConfig loads the REST config from kubeconfig, or from the in-cluster
+// config falling back to the default kubeconfig when kubeconfig is empty
+func buildConfig(kubeconfig string) (*rest.Config, *ClientInfo, error) {
 	if kubeconfig == "" {
 		// Try in-cluster config first
-		config, err = rest.InClusterConfig()
-		if err != nil {
-			klog.Warningf("Failed to get in-cluster config: %v, falling back to default kubeconfig", err)
-			// Fall back to default kubeconfig location
-			config, err = clientcmd.BuildConfigFromFlags("", clientcmd.RecommendedHomeFile)
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			return config, NewClientInfo(config, "", ""), nil
 		}
-	} else {
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		klog.Warningf("Failed to get in-cluster config: %v, falling back to default kubeconfig", err)
+		// Fall back to default kubeconfig location
+		kubeconfig = clientcmd.RecommendedHomeFile
 	}
 
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}, &clientcmd.ConfigOverrides{})
+	config, err := loader.ClientConfig()
 	if err != nil {
 		klog.Errorf("Failed to build config: %v", err)
-		return nil, err
+		return nil, nil, err
 	}
-
-	clientset, err := kubernetes.NewForConfig(config)
+	raw, err := loader.RawConfig()
 	if err != nil {
-		klog.Errorf("Failed to create clientset: %v", err)
-		return nil, err
+		klog.Errorf("Failed to load kubeconfig %s: %v", kubeconfig, err)
+		return nil, nil, err
 	}
 
-	return clientset, nil
+	return config, NewClientInfo(config, kubeconfig, raw.CurrentContext), nil
 }
 
+// fieldManager identifies kgo as the manager of fields it patches
+const fieldManager = "kgo"
+
 // ListPods lists all pods in the specified namespace
 func ListPods(clientset kubernetes.Interface, namespace string) ([]v1.Pod, error) {
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	return ListPodsWithOptions(clientset, namespace, metav1.ListOptions{})
+}
+
+// ListPodsWithOptions lists the pods in the specified namespace matching opts
+func ListPodsWithOptions(clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]v1.Pod, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), opts)
 	if err != nil {
 		klog.Errorf("Failed to list pods in namespace %s: %v", namespace, err)
 		return nil, err
@@ -63,6 +136,30 @@ func ListPods(clientset kubernetes.Interface, namespace string) ([]v1.Pod, error
 	return pods.Items, nil
 }
 
+// ListPodsPage lists up to limit pods in the specified namespace, continuing
+// from a previous page's continue token
+func ListPodsPage(clientset kubernetes.Interface, namespace string, limit int64, continueToken string) (*v1.PodList, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		Limit:    limit,
+		Continue: continueToken,
+	})
+	if err != nil {
+		klog.Errorf("Failed to list pods in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return pods, nil
+}
+
+// GetPod gets a pod by name in the specified namespace
+func GetPod(clientset kubernetes.Interface, namespace, name string) (*v1.Pod, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return pod, nil
+}
+
 // CreatePod creates a new pod in the specified namespace
 func CreatePod(clientset kubernetes.Interface, namespace string, pod *v1.Pod) (*v1.Pod, error) {
 	createdPod, err := clientset.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
@@ -83,6 +180,16 @@ func UpdatePod(clientset kubernetes.Interface, namespace string, pod *v1.Pod) (*
 	return updatedPod, nil
 }
 
+// PatchPod applies a strategic merge patch to a pod in the specified namespace
+func PatchPod(clientset kubernetes.Interface, namespace, name string, patch []byte) (*v1.Pod, error) {
+	patchedPod, err := clientset.CoreV1().Pods(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch pod %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedPod, nil
+}
+
 // DeletePod deletes a pod in the specified namespace
 func DeletePod(clientset kubernetes.Interface, namespace, name string) error {
 	err := clientset.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
@@ -93,19 +200,58 @@ func DeletePod(clientset kubernetes.Interface, namespace, name string) error {
 	return nil
 }
 
-// WatchPods watches for changes to pods in the specified namespace
-func WatchPods(clientset kubernetes.Interface, namespace string) (watch.Interface, error) {
-	watcher, err := clientset.CoreV1().Pods(namespace).Watch(context.TODO(), metav1.ListOptions{})
+// WatchPods watches for changes to pods in the specified namespace from
+// resourceVersion, or from now when it is empty
+func WatchPods(clientset kubernetes.Interface, namespace, resourceVersion string) (*WatchHandle, error) {
+	watcher, err := clientset.CoreV1().Pods(namespace).Watch(context.TODO(), watchOptions(resourceVersion))
 	if err != nil {
 		klog.Errorf("Failed to watch pods in namespace %s: %v", namespace, err)
 		return nil, err
 	}
-	return watcher, nil
+	return newWatchHandle(watcher, resourceVersion), nil
+}
+
+// WatchDeployments watches for changes to deployments in the specified namespace from
+// resourceVersion, or from now when it is empty
+func WatchDeployments(clientset kubernetes.Interface, namespace, resourceVersion string) (*WatchHandle, error) {
+	watcher, err := clientset.AppsV1().Deployments(namespace).Watch(context.TODO(), watchOptions(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to watch deployments in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return newWatchHandle(watcher, resourceVersion), nil
+}
+
+// WatchServices watches for changes to services in the specified namespace from
+// resourceVersion, or from now when it is empty
+func WatchServices(clientset kubernetes.Interface, namespace, resourceVersion string) (*WatchHandle, error) {
+	watcher, err := clientset.CoreV1().Services(namespace).Watch(context.TODO(), watchOptions(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to watch services in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return newWatchHandle(watcher, resourceVersion), nil
+}
+
+// WatchConfigMaps watches for changes to configmaps in the specified namespace from
+// resourceVersion, or from now when it is empty
+func WatchConfigMaps(clientset kubernetes.Interface, namespace, resourceVersion string) (*WatchHandle, error) {
+	watcher, err := clientset.CoreV1().ConfigMaps(namespace).Watch(context.TODO(), watchOptions(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to watch configmaps in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return newWatchHandle(watcher, resourceVersion), nil
 }
 
 // ListDeployments lists all deployments in the specified namespace
 func ListDeployments(clientset kubernetes.Interface, namespace string) ([]appsv1.Deployment, error) {
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	return ListDeploymentsWithOptions(clientset, namespace, metav1.ListOptions{})
+}
+
+// ListDeploymentsWithOptions lists the deployments in the specified namespace matching opts
+func ListDeploymentsWithOptions(clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]appsv1.Deployment, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), opts)
 	if err != nil {
 		klog.Errorf("Failed to list deployments in namespace %s: %v", namespace, err)
 		return nil, err
@@ -113,6 +259,16 @@ func ListDeployments(clientset kubernetes.Interface, namespace string) ([]appsv1
 	return deployments.Items, nil
 }
 
+// GetDeployment gets a deployment by name in the specified namespace
+func GetDeployment(clientset kubernetes.Interface, namespace, name string) (*appsv1.Deployment, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get deployment %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return deployment, nil
+}
+
 // CreateDeployment creates a new deployment in the specified namespace
 func CreateDeployment(clientset kubernetes.Interface, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
 	createdDeployment, err := clientset.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
@@ -133,6 +289,16 @@ func UpdateDeployment(clientset kubernetes.Interface, namespace string, deployme
 	return updatedDeployment, nil
 }
 
+// PatchDeployment applies a strategic merge patch to a deployment in the specified namespace
+func PatchDeployment(clientset kubernetes.Interface, namespace, name string, patch []byte) (*appsv1.Deployment, error) {
+	patchedDeployment, err := clientset.AppsV1().Deployments(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch deployment %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedDeployment, nil
+}
+
 // DeleteDeployment deletes a deployment in the specified namespace
 func DeleteDeployment(clientset kubernetes.Interface, namespace, name string) error {
 	err := clientset.AppsV1().Deployments(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
@@ -145,7 +311,12 @@ func DeleteDeployment(clientset kubernetes.Interface, namespace, name string) er
 
 // ListServices lists all services in the specified namespace
 func ListServices(clientset kubernetes.Interface, namespace string) ([]v1.Service, error) {
-	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	return ListServicesWithOptions(clientset, namespace, metav1.ListOptions{})
+}
+
+// ListServicesWithOptions lists the services in the specified namespace matching opts
+func ListServicesWithOptions(clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]v1.Service, error) {
+	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), opts)
 	if err != nil {
 		klog.Errorf("Failed to list services in namespace %s: %v", namespace, err)
 		return nil, err
@@ -153,6 +324,16 @@ func ListServices(clientset kubernetes.Interface, namespace string) ([]v1.Servic
 	return services.Items, nil
 }
 
+// GetService gets a service by name in the specified namespace
+func GetService(clientset kubernetes.Interface, namespace, name string) (*v1.Service, error) {
+	service, err := clientset.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get service %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return service, nil
+}
+
 // CreateService creates a new service in the specified namespace
 func CreateService(clientset kubernetes.Interface, namespace string, service *v1.Service) (*v1.Service, error) {
 	createdService, err := clientset.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
@@ -173,6 +354,16 @@ func UpdateService(clientset kubernetes.Interface, namespace string, service *v1
 	return updatedService, nil
 }
 
+// PatchService applies a strategic merge patch to a service in the specified namespace
+func PatchService(clientset kubernetes.Interface, namespace, name string, patch []byte) (*v1.Service, error) {
+	patchedService, err := clientset.CoreV1().Services(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch service %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedService, nil
+}
+
 // DeleteService deletes a service in the specified namespace
 func DeleteService(clientset kubernetes.Interface, namespace, name string) error {
 	err := clientset.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
@@ -185,7 +376,12 @@ func DeleteService(clientset kubernetes.Interface, namespace, name string) error
 
 // ListConfigMaps lists all configmaps in the specified namespace
 func ListConfigMaps(clientset kubernetes.Interface, namespace string) ([]v1.ConfigMap, error) {
-	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	return ListConfigMapsWithOptions(clientset, namespace, metav1.ListOptions{})
+}
+
+// ListConfigMapsWithOptions lists the configmaps in the specified namespace matching opts
+func ListConfigMapsWithOptions(clientset kubernetes.Interface, namespace string, opts metav1.ListOptions) ([]v1.ConfigMap, error) {
+	configmaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), opts)
 	if err != nil {
 		klog.Errorf("Failed to list configmaps in namespace %s: %v", namespace, err)
 		return nil, err
@@ -193,6 +389,16 @@ func ListConfigMaps(clientset kubernetes.Interface, namespace string) ([]v1.Conf
 	return configmaps.Items, nil
 }
 
+// GetConfigMap gets a configmap by name in the specified namespace
+func GetConfigMap(clientset kubernetes.Interface, namespace, name string) (*v1.ConfigMap, error) {
+	configmap, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get configmap %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return configmap, nil
+}
+
 // CreateConfigMap creates a new configmap in the specified namespace
 func CreateConfigMap(clientset kubernetes.Interface, namespace string, configmap *v1.ConfigMap) (*v1.ConfigMap, error) {
 	createdConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), configmap, metav1.CreateOptions{})
@@ -213,6 +419,86 @@ func UpdateConfigMap(clientset kubernetes.Interface, namespace string, configmap
 	return updatedConfigMap, nil
 }
 
+// PatchConfigMap applies a strategic merge patch to a configmap in the specified namespace
+func PatchConfigMap(clientset kubernetes.Interface, namespace, name string, patch []byte) (*v1.ConfigMap, error) {
+	patchedConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch configmap %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedConfigMap, nil
+}
+
+// ErrConfigMapKeyNotFound is returned when a configmap has no such key in
+// either data or binaryData
+var ErrConfigMapKeyNotFound = goerrors.New("configmap key not found")
+
+// ConfigMapKey returns the value of a single key of a configmap, and whether
+// it is held in binaryData
+func ConfigMapKey(configmap *v1.ConfigMap, key string) ([]byte, bool, error) {
+	if value, ok := configmap.Data[key]; ok {
+		return []byte(value), false, nil
+	}
+	if value, ok := configmap.BinaryData[key]; ok {
+		return value, true, nil
+	}
+	return nil, false, fmt.Errorf("%s/%s: %w: %s", configmap.Namespace, configmap.Name, ErrConfigMapKeyNotFound, key)
+}
+
+// SetConfigMapKey sets a single configmap key with a strategic merge patch,
+// leaving every other key untouched. The value goes to binaryData when binary
+// is set, when it is not valid UTF-8, or when the key is already binary.
+func SetConfigMapKey(clientset kubernetes.Interface, namespace, name, key string, value []byte, binary bool) (*v1.ConfigMap, error) {
+	configmap, err := GetConfigMap(clientset, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	_, inBinaryData := configmap.BinaryData[key]
+	binary = binary || inBinaryData || !utf8.Valid(value)
+
+	patch := map[string]interface{}{}
+	if binary {
+		// encoding/json writes []byte as base64, as binaryData expects
+		patch["binaryData"] = map[string]interface{}{key: value}
+		if _, inData := configmap.Data[key]; inData {
+			// A key may only appear in one of data and binaryData
+			patch["data"] = map[string]interface{}{key: nil}
+		}
+	} else {
+		patch["data"] = map[string]interface{}{key: string(value)}
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return PatchConfigMap(clientset, namespace, name, data)
+}
+
+// DeleteConfigMapKey removes a single configmap key with a strategic merge patch
+func DeleteConfigMapKey(clientset kubernetes.Interface, namespace, name, key string) error {
+	configmap, err := GetConfigMap(clientset, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	field := "data"
+	if _, ok := configmap.Data[key]; !ok {
+		if _, ok := configmap.BinaryData[key]; !ok {
+			return fmt.Errorf("%s/%s: %w: %s", namespace, name, ErrConfigMapKeyNotFound, key)
+		}
+		field = "binaryData"
+	}
+
+	data, err := json.Marshal(map[string]interface{}{field: map[string]interface{}{key: nil}})
+	if err != nil {
+		return err
+	}
+	_, err = PatchConfigMap(clientset, namespace, name, data)
+	return err
+}
+
 // DeleteConfigMap deletes a configmap in the specified namespace
 func DeleteConfigMap(clientset kubernetes.Interface, namespace, name string) error {
 	err := clientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
@@ -223,6 +509,162 @@ func DeleteConfigMap(clientset kubernetes.Interface, namespace, name string) err
 	return nil
 }
 
+// ListStatefulSets lists all statefulsets in the specified namespace
+func ListStatefulSets(clientset kubernetes.Interface, namespace string) ([]appsv1.StatefulSet, error) {
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list statefulsets in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return statefulSets.Items, nil
+}
+
+// ListDaemonSets lists all daemonsets in the specified namespace
+func ListDaemonSets(clientset kubernetes.Interface, namespace string) ([]appsv1.DaemonSet, error) {
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list daemonsets in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return daemonSets.Items, nil
+}
+
+// ListJobs lists all jobs in the specified namespace
+func ListJobs(clientset kubernetes.Interface, namespace string) ([]batchv1.Job, error) {
+	jobs, err := clientset.BatchV1().Jobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list jobs in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return jobs.Items, nil
+}
+
+// ListCronJobs lists all cronjobs in the specified namespace
+func ListCronJobs(clientset kubernetes.Interface, namespace string) ([]batchv1.CronJob, error) {
+	cronJobs, err := clientset.BatchV1().CronJobs(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list cronjobs in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return cronJobs.Items, nil
+}
+
+// ListIngresses lists all ingresses in the specified namespace
+func ListIngresses(clientset kubernetes.Interface, namespace string) ([]networkingv1.Ingress, error) {
+	ingresses, err := clientset.NetworkingV1().Ingresses(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list ingresses in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return ingresses.Items, nil
+}
+
+// CreateIngress creates a new ingress in the specified namespace
+func CreateIngress(clientset kubernetes.Interface, namespace string, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+	createdIngress, err := clientset.NetworkingV1().Ingresses(namespace).Create(context.TODO(), ingress, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create ingress %s in namespace %s: %v", ingress.Name, namespace, err)
+		return nil, err
+	}
+	return createdIngress, nil
+}
+
+// PatchIngress applies a strategic merge patch to a ingress in the specified namespace
+func PatchIngress(clientset kubernetes.Interface, namespace, name string, patch []byte) (*networkingv1.Ingress, error) {
+	patchedIngress, err := clientset.NetworkingV1().Ingresses(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch ingress %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedIngress, nil
+}
+
+// ListPVCs lists all persistent volume claims in the specified namespace
+func ListPVCs(clientset kubernetes.Interface, namespace string) ([]v1.PersistentVolumeClaim, error) {
+	pVCs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list persistent volume claims in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return pVCs.Items, nil
+}
+
+// ListSecrets lists all secrets in the specified namespace
+func ListSecrets(clientset kubernetes.Interface, namespace string) ([]v1.Secret, error) {
+	secrets, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list secrets in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return secrets.Items, nil
+}
+
+// CreateSecret creates a new secret in the specified namespace
+func CreateSecret(clientset kubernetes.Interface, namespace string, secret *v1.Secret) (*v1.Secret, error) {
+	createdSecret, err := clientset.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create secret %s in namespace %s: %v", secret.Name, namespace, err)
+		return nil, err
+	}
+	return createdSecret, nil
+}
+
+// PatchSecret applies a strategic merge patch to a secret in the specified namespace
+func PatchSecret(clientset kubernetes.Interface, namespace, name string, patch []byte) (*v1.Secret, error) {
+	patchedSecret, err := clientset.CoreV1().Secrets(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch secret %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedSecret, nil
+}
+
+// ListServiceAccounts lists all service accounts in the specified namespace
+func ListServiceAccounts(clientset kubernetes.Interface, namespace string) ([]v1.ServiceAccount, error) {
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list service accounts in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return serviceAccounts.Items, nil
+}
+
+// CreateServiceAccount creates a new service account in the specified namespace
+func CreateServiceAccount(clientset kubernetes.Interface, namespace string, serviceAccount *v1.ServiceAccount) (*v1.ServiceAccount, error) {
+	createdServiceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Create(context.TODO(), serviceAccount, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create service account %s in namespace %s: %v", serviceAccount.Name, namespace, err)
+		return nil, err
+	}
+	return createdServiceAccount, nil
+}
+
+// PatchServiceAccount applies a strategic merge patch to a service account in the specified namespace
+func PatchServiceAccount(clientset kubernetes.Interface, namespace, name string, patch []byte) (*v1.ServiceAccount, error) {
+	patchedServiceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Patch(context.TODO(), name, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to patch service account %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return patchedServiceAccount, nil
+}
+
+// CreateServiceAccountToken requests a short-lived token for a service account
+func CreateServiceAccountToken(clientset kubernetes.Interface, namespace, name string, expirationSeconds int64, audiences []string) (*authv1.TokenRequest, error) {
+	request := &authv1.TokenRequest{
+		Spec: authv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	token, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(context.TODO(), name, request, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create token for service account %s in namespace %s: %v", name, namespace, err)
+		return nil, err
+	}
+	return token, nil
+}
+
 // GetPodLogs retrieves logs from a pod
 func GetPodLogs(clientset kubernetes.Interface, namespace, podName, containerName string, follow bool, tailLines int64) (io.ReadCloser, error) {
 	req := clientset.CoreV1().Pods(namespace).GetLogs(podName, &v1.PodLogOptions{
@@ -234,6 +676,21 @@ func GetPodLogs(clientset kubernetes.Interface, namespace, podName, containerNam
 	return req.Stream(context.TODO())
 }
 
+// FollowPodLogs streams the logs of a pod's container from tailLines lines
+// back until ctx is done
+func FollowPodLogs(ctx context.Context, clientset kubernetes.Interface, namespace, podName, containerName string, tailLines int64) (io.ReadCloser, error) {
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(podName, &v1.PodLogOptions{
+		Container: containerName,
+		Follow:    true,
+		TailLines: &tailLines,
+	}).Stream(ctx)
+	if err != nil {
+		klog.Errorf("Failed to stream logs of pod %s in namespace %s: %v", podName, namespace, err)
+		return nil, err
+	}
+	return stream, nil
+}
+
 // ExecPod executes a command in a pod container
 func ExecPod(clientset kubernetes.Interface, config *rest.Config, namespace, podName, containerName string, command []string) error {
 	req := clientset.CoreV1().RESTClient().Post().
@@ -263,33 +720,254 @@ func ExecPod(clientset kubernetes.Interface, config *rest.Config, namespace, pod
 	})
 }
 
-// ApplyYaml applies a YAML file to the cluster
+// ApplyAction is what applying an object did, or would do in a dry run
+type ApplyAction string
+
+const (
+	ApplyCreated   ApplyAction = "created"
+	ApplyUpdated   ApplyAction = "updated"
+	ApplyUnchanged ApplyAction = "unchanged"
+)
+
+// ApplyOptions control how ApplyYamlDocuments and ApplyYamlStream apply a
+// manifest
+type ApplyOptions struct {
+	// DryRun reports what applying would do without changing the cluster:
+	// objects are read and the patch merged into them locally
+	DryRun bool
+	// FieldManager manages the applied fields; kgo when empty
+	FieldManager string
+	// Check, if set, rejects an object before it is applied
+	Check func(runtime.Object) error
+}
+
+// ApplyResult is the result of applying a document of a manifest. Action is
+// empty when Err is set.
+type ApplyResult struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Action    ApplyAction
+	Err       error
+}
+
+// ApplyYaml applies a YAML file to the cluster. Like kubectl apply, a resource
+// that already exists is updated with a strategic merge patch of the YAML.
 func ApplyYaml(clientset kubernetes.Interface, namespace string, yamlFile string) error {
-	// Decode YAML file
+	return applyDocument(context.TODO(), clientset, namespace, []byte(yamlFile), ApplyOptions{}).Err
+}
+
+// ApplyYamlDocuments applies each document of a manifest like ApplyYaml and
+// returns their results in order. A document that fails does not stop the
+// others.
+func ApplyYamlDocuments(ctx context.Context, clientset kubernetes.Interface, namespace, manifest string, opts ApplyOptions) ([]ApplyResult, error) {
+	var results []ApplyResult
+	err := ApplyYamlStream(ctx, clientset, namespace, strings.NewReader(manifest), opts, func(result ApplyResult) error {
+		results = append(results, result)
+		return nil
+	})
+	return results, err
+}
+
+// ApplyYamlStream applies the documents of a manifest as they are read,
+// calling fn with the result of each. Reading stops at the first error of
+// the reader or of fn, which is returned.
+func ApplyYamlStream(ctx context.Context, clientset kubernetes.Interface, namespace string, manifest io.Reader, opts ApplyOptions, fn func(ApplyResult) error) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(manifest))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		result := applyDocument(ctx, clientset, namespace, document, opts)
+		if result.Kind == "" && result.Err == nil {
+			// Only comments
+			continue
+		}
+		if err := fn(result); err != nil {
+			return err
+		}
+	}
+}
+
+// applyDocument applies the object of a YAML document
+func applyDocument(ctx context.Context, clientset kubernetes.Interface, namespace string, document []byte, opts ApplyOptions) ApplyResult {
+	result := ApplyResult{Namespace: namespace}
+
+	// The API server expects strategic merge patches as JSON
+	patch, err := yaml.YAMLToJSON(document)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if string(patch) == "null" {
+		return result
+	}
+	// Name the object in the result even when it does not decode
+	var meta metav1.PartialObjectMetadata
+	if err := json.Unmarshal(patch, &meta); err == nil {
+		result.Kind, result.Name = meta.Kind, meta.Name
+	}
+
 	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
-	obj, _, err := decode([]byte(yamlFile), nil, nil)
+	obj, _, err := decode(patch, nil, nil)
 	if err != nil {
-		return err
+		result.Err = err
+		return result
+	}
+	if opts.Check != nil {
+		if result.Err = opts.Check(obj); result.Err != nil {
+			return result
+		}
 	}
 
 	// Switch on the type of the object
 	switch obj := obj.(type) {
 	case *v1.Pod:
-		_, err = CreatePod(clientset, namespace, obj)
+		result.Action, result.Err = applyObject(ctx, clientset.CoreV1().Pods(namespace), obj, patch, opts)
 	case *appsv1.Deployment:
-		_, err = CreateDeployment(clientset, namespace, obj)
+		result.Action, result.Err = applyObject(ctx, clientset.AppsV1().Deployments(namespace), obj, patch, opts)
 	case *v1.Service:
-		_, err = CreateService(clientset, namespace, obj)
+		result.Action, result.Err = applyObject(ctx, clientset.CoreV1().Services(namespace), obj, patch, opts)
 	case *v1.ConfigMap:
-		_, err = CreateConfigMap(clientset, namespace, obj)
+		result.Action, result.Err = applyObject(ctx, clientset.CoreV1().ConfigMaps(namespace), obj, patch, opts)
+	case *v1.Secret:
+		result.Action, result.Err = applyObject(ctx, clientset.CoreV1().Secrets(namespace), obj, patch, opts)
+	case *v1.ServiceAccount:
+		result.Action, result.Err = applyObject(ctx, clientset.CoreV1().ServiceAccounts(namespace), obj, patch, opts)
+	case *networkingv1.Ingress:
+		result.Action, result.Err = applyObject(ctx, clientset.NetworkingV1().Ingresses(namespace), obj, patch, opts)
 	default:
-		return fmt.Errorf("unsupported object type %T", obj)
+		result.Err = fmt.Errorf("unsupported object type %T", obj)
+	}
+	return result
+}
+
+// applyClient is the part of a typed client of a namespace that applying an
+// object uses
+type applyClient[T runtime.Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Create(ctx context.Context, obj T, opts metav1.CreateOptions) (T, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
+}
+
+// applyObject creates obj, or patches the existing object with patch, the
+// JSON of obj, when that changes it. An object created between the Get and
+// the Create is patched as well.
+func applyObject[T interface {
+	runtime.Object
+	metav1.Object
+}](ctx context.Context, client applyClient[T], obj T, patch []byte, opts ApplyOptions) (ApplyAction, error) {
+	manager := opts.FieldManager
+	if manager == "" {
+		manager = fieldManager
+	}
+
+	existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if opts.DryRun {
+			return ApplyCreated, nil
+		}
+		_, err = client.Create(ctx, obj, metav1.CreateOptions{FieldManager: manager})
+		if err == nil {
+			return ApplyCreated, nil
+		}
+		if !errors.IsAlreadyExists(err) {
+			klog.Errorf("Failed to create %T %s in namespace %s: %v", obj, obj.GetName(), obj.GetNamespace(), err)
+			return "", err
+		}
+		// Created by someone else since the Get; patch it as kubectl apply
+		// would have
+		existing, err = client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	}
+	if err != nil {
+		return "", err
+	}
+
+	changed, err := patchChanges(existing, patch)
+	if err != nil {
+		return "", err
+	}
+	if !changed {
+		return ApplyUnchanged, nil
+	}
+	if !opts.DryRun {
+		err := patchOnConflict(func() error {
+			_, err := client.Patch(ctx, obj.GetName(), types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: manager})
+			return err
+		})
+		if err != nil {
+			klog.Errorf("Failed to patch %T %s: %v", obj, obj.GetName(), err)
+			return "", err
+		}
 	}
+	return ApplyUpdated, nil
+}
 
+// patchChanges reports whether a strategic merge patch changes an object,
+// merging it locally the way the API server does
+func patchChanges(existing runtime.Object, patch []byte) (bool, error) {
+	original, err := json.Marshal(existing)
+	if err != nil {
+		return false, err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, patch, existing)
+	if err != nil {
+		return false, err
+	}
+	patched, err := runtime.Decode(scheme.Codecs.UniversalDeserializer(), merged)
+	if err != nil {
+		return false, err
+	}
+
+	// Typed clients leave out the kind of the objects they return
+	before := existing.DeepCopyObject()
+	before.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	patched.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return false, err
+	}
+	patchedJSON, err := json.Marshal(patched)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(beforeJSON, patchedJSON), nil
+}
+
+// applyBackoff bounds the retries of a patch that hits a resource version conflict
+var applyBackoff = wait.Backoff{
+	Duration: 100 * time.Millisecond,
+	Factor:   2.0,
+	Steps:    3,
+}
+
+// patchOnConflict runs patch, retrying with exponential backoff while it
+// fails with a conflict. The last conflict is returned if retries run out.
+func patchOnConflict(patch func() error) error {
+	var lastErr error
+	err := wait.ExponentialBackoff(applyBackoff, func() (bool, error) {
+		lastErr = patch()
+		if errors.IsConflict(lastErr) {
+			return false, nil
+		}
+		return true, lastErr
+	})
+	if wait.Interrupted(err) {
+		return lastErr
+	}
 	return err
 }
 
-// DeleteYaml deletes a resource defined in a YAML file from the cluster
+// DeleteYaml deletes a resource defined in a YAML file from the cluster, of
+// the kinds ApplyYaml applies. A resource that does not exist is treated as
+// already deleted.
 func DeleteYaml(clientset kubernetes.Interface, namespace string, yamlFile string) error {
 	// Decode YAML file
 	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
@@ -299,6 +977,7 @@ func DeleteYaml(clientset kubernetes.Interface, namespace string, yamlFile strin
 	}
 
 	// Switch on the type of the object
+	ctx := context.TODO()
 	switch obj := obj.(type) {
 	case *v1.Pod:
 		err = DeletePod(clientset, namespace, obj.Name)
@@ -308,10 +987,19 @@ func DeleteYaml(clientset kubernetes.Interface, namespace string, yamlFile strin
 		err = DeleteService(clientset, namespace, obj.Name)
 	case *v1.ConfigMap:
 		err = DeleteConfigMap(clientset, namespace, obj.Name)
+	case *v1.Secret:
+		err = clientset.CoreV1().Secrets(namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
+	case *v1.ServiceAccount:
+		err = clientset.CoreV1().ServiceAccounts(namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
+	case *networkingv1.Ingress:
+		err = clientset.NetworkingV1().Ingresses(namespace).Delete(ctx, obj.Name, metav1.DeleteOptions{})
 	default:
 		return fmt.Errorf("unsupported object type %T", obj)
 	}
 
+	if errors.IsNotFound(err) {
+		return nil
+	}
 	return err
 }
 
@@ -361,3 +1049,59 @@ func ListNamespaces(clientset kubernetes.Interface) ([]v1.Namespace, error) {
 	}
 	return namespaces.Items, nil
 }
+
+// CreateNamespace creates a namespace with the given labels
+func CreateNamespace(clientset kubernetes.Interface, name string, labels map[string]string) (*v1.Namespace, error) {
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	createdNamespace, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create namespace %s: %v", name, err)
+		return nil, err
+	}
+	return createdNamespace, nil
+}
+
+// CreateResourceQuota creates a resource quota in a namespace
+func CreateResourceQuota(clientset kubernetes.Interface, namespace string, quota *v1.ResourceQuota) (*v1.ResourceQuota, error) {
+	createdQuota, err := clientset.CoreV1().ResourceQuotas(namespace).Create(context.TODO(), quota, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create resource quota %s in namespace %s: %v", quota.Name, namespace, err)
+		return nil, err
+	}
+	return createdQuota, nil
+}
+
+// CreateLimitRange creates a limit range in a namespace
+func CreateLimitRange(clientset kubernetes.Interface, namespace string, limitRange *v1.LimitRange) (*v1.LimitRange, error) {
+	createdLimitRange, err := clientset.CoreV1().LimitRanges(namespace).Create(context.TODO(), limitRange, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to create limit range %s in namespace %s: %v", limitRange.Name, namespace, err)
+		return nil, err
+	}
+	return createdLimitRange, nil
+}
+
+// ListNodes lists all nodes in the cluster
+func ListNodes(clientset kubernetes.Interface) ([]v1.Node, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list nodes: %v", err)
+		return nil, err
+	}
+	return nodes.Items, nil
+}
+
+// GetNodePressureConditions returns the memory, disk and PID pressure
+// condition types that are True on a node, in the node's condition order
+func GetNodePressureConditions(node v1.Node) []string {
+	var pressure []string
+	for _, condition := range node.Status.Conditions {
+		switch condition.Type {
+		case v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure:
+			if condition.Status == v1.ConditionTrue {
+				pressure = append(pressure, string(condition.Type))
+			}
+		}
+	}
+	return pressure
+}
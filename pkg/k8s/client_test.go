@@ -0,0 +1,547 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const podYAML = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  containers:
+  - name: app
+    image: nginx:1.27
+`
+
+const deploymentYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.27
+`
+
+const serviceYAML = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 8080
+`
+
+const configMapYAML = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  mode: production
+`
+
+const secretYAML = `apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+stringData:
+  password: hunter2
+`
+
+const serviceAccountYAML = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+`
+
+const ingressYAML = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  defaultBackend:
+    service:
+      name: web
+      port:
+        number: 80
+`
+
+// useFastApplyBackoff shortens the conflict backoff for the duration of a test
+func useFastApplyBackoff(t *testing.T) {
+	saved := applyBackoff
+	applyBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 2.0, Steps: 3}
+	t.Cleanup(func() { applyBackoff = saved })
+}
+
+func int32Value(p *int32) int32 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func TestApplyYamlCreatesAndPatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		existing runtime.Object
+		verify   func(t *testing.T, clientset *fake.Clientset)
+	}{
+		{
+			name: "pod",
+			yaml: podYAML,
+			existing: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"tier": "frontend"}},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx:1.25"}}},
+			},
+			verify: func(t *testing.T, clientset *fake.Clientset) {
+				pod, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "web", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Failed to get pod: %v", err)
+				}
+				if pod.Spec.Containers[0].Image != "nginx:1.27" || pod.Labels["app"] != "web" {
+					t.Errorf("Pod was not applied: %+v", pod)
+				}
+			},
+		},
+		{
+			name: "deployment",
+			yaml: deploymentYAML,
+			existing: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: func() *int32 { r := int32(1); return &r }(),
+				},
+			},
+			verify: func(t *testing.T, clientset *fake.Clientset) {
+				deployment, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Failed to get deployment: %v", err)
+				}
+				if int32Value(deployment.Spec.Replicas) != 3 {
+					t.Errorf("Expected 3 replicas, got %d", int32Value(deployment.Spec.Replicas))
+				}
+			},
+		},
+		{
+			name: "service",
+			yaml: serviceYAML,
+			existing: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 80}}},
+			},
+			verify: func(t *testing.T, clientset *fake.Clientset) {
+				service, err := clientset.CoreV1().Services("default").Get(context.TODO(), "web", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Failed to get service: %v", err)
+				}
+				found := false
+				for _, port := range service.Spec.Ports {
+					if port.Port == 8080 {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Expected port 8080 to be applied, got %+v", service.Spec.Ports)
+				}
+			},
+		},
+		{
+			name: "configmap",
+			yaml: configMapYAML,
+			existing: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+				Data:       map[string]string{"mode": "debug", "keep": "me"},
+			},
+			verify: func(t *testing.T, clientset *fake.Clientset) {
+				configmap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "web", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Failed to get configmap: %v", err)
+				}
+				if configmap.Data["mode"] != "production" {
+					t.Errorf("Expected mode=production, got %v", configmap.Data)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/create", func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			if err := ApplyYaml(clientset, "default", tt.yaml); err != nil {
+				t.Fatalf("ApplyYaml failed: %v", err)
+			}
+			tt.verify(t, clientset)
+		})
+
+		t.Run(tt.name+"/patch existing", func(t *testing.T) {
+			clientset := fake.NewSimpleClientset(tt.existing)
+			if err := ApplyYaml(clientset, "default", tt.yaml); err != nil {
+				t.Fatalf("ApplyYaml failed: %v", err)
+			}
+			tt.verify(t, clientset)
+
+			patched := false
+			for _, action := range clientset.Actions() {
+				if patch, ok := action.(k8stesting.PatchAction); ok {
+					patched = true
+					if patch.GetPatchType() != "application/strategic-merge-patch+json" {
+						t.Errorf("Expected strategic merge patch, got %s", patch.GetPatchType())
+					}
+				}
+			}
+			if !patched {
+				t.Error("Expected existing resource to be patched")
+			}
+		})
+	}
+}
+
+func TestApplyYamlMergesIntoExisting(t *testing.T) {
+	existing := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Data:       map[string]string{"mode": "debug", "keep": "me"},
+	}
+	clientset := fake.NewSimpleClientset(existing)
+
+	if err := ApplyYaml(clientset, "default", configMapYAML); err != nil {
+		t.Fatalf("ApplyYaml failed: %v", err)
+	}
+
+	configmap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap: %v", err)
+	}
+	expected := map[string]string{"mode": "production", "keep": "me"}
+	for k, v := range expected {
+		if configmap.Data[k] != v {
+			t.Errorf("Expected %s=%s after merge, got %v", k, v, configmap.Data)
+		}
+	}
+}
+
+func TestApplyYamlRetriesConflicts(t *testing.T) {
+	useFastApplyBackoff(t)
+
+	existing := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	conflict := errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "web", nil)
+
+	t.Run("succeeds after conflicts", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(existing)
+		attempts := 0
+		clientset.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			attempts++
+			if attempts < 3 {
+				return true, nil, conflict
+			}
+			return false, nil, nil
+		})
+
+		if err := ApplyYaml(clientset, "default", configMapYAML); err != nil {
+			t.Fatalf("Expected apply to succeed after retries, got %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 patch attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("gives up after retries", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(existing)
+		attempts := 0
+		clientset.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			attempts++
+			return true, nil, conflict
+		})
+
+		err := ApplyYaml(clientset, "default", configMapYAML)
+		if !errors.IsConflict(err) {
+			t.Errorf("Expected the last conflict error, got %v", err)
+		}
+		if attempts != applyBackoff.Steps {
+			t.Errorf("Expected %d patch attempts, got %d", applyBackoff.Steps, attempts)
+		}
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(existing)
+		attempts := 0
+		clientset.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			attempts++
+			return true, nil, errors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "web", nil)
+		})
+
+		if err := ApplyYaml(clientset, "default", configMapYAML); !errors.IsForbidden(err) {
+			t.Errorf("Expected forbidden error, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("Expected a single attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestApplyYamlErrors(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	if err := ApplyYaml(clientset, "default", "not: [valid"); err == nil {
+		t.Error("Expected invalid YAML to fail")
+	}
+
+	pvcYAML := "apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: data\n"
+	if err := ApplyYaml(clientset, "default", pvcYAML); err == nil {
+		t.Error("Expected unsupported kind to fail")
+	}
+
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Resource: "pods"}, "web", nil)
+	})
+	if err := ApplyYaml(clientset, "default", podYAML); !errors.IsForbidden(err) {
+		t.Errorf("Expected create errors other than AlreadyExists to be returned, got %v", err)
+	}
+}
+
+// applyManifest has a document for each action ApplyYamlDocuments reports
+// against applyExisting, and one that fails
+const applyManifest = "# comment only\n---\n" + configMapYAML + "---\n" + serviceYAML + "---\n" + podYAML +
+	"---\napiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: data\n"
+
+// applyExisting returns the objects applyManifest updates and leaves
+// unchanged
+func applyExisting() []runtime.Object {
+	return []runtime.Object{
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Data:       map[string]string{"mode": "debug"},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 8080}}},
+		},
+	}
+}
+
+func TestApplyYamlDocuments(t *testing.T) {
+	clientset := fake.NewSimpleClientset(applyExisting()...)
+
+	results, err := ApplyYamlDocuments(context.Background(), clientset, "default", applyManifest, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("ApplyYamlDocuments failed: %v", err)
+	}
+	expected := []struct {
+		kind   string
+		action ApplyAction
+	}{
+		{"ConfigMap", ApplyUpdated},
+		{"Service", ApplyUnchanged},
+		{"Pod", ApplyCreated},
+		{"PersistentVolumeClaim", ""},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Kind != want.kind || got.Action != want.action || got.Namespace != "default" {
+			t.Errorf("Expected %s %s in default, got %+v", want.kind, want.action, got)
+		}
+	}
+	if results[3].Err == nil || results[3].Name != "data" {
+		t.Errorf("Expected the unsupported claim to fail by name, got %+v", results[3])
+	}
+
+	for _, action := range clientset.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok && patch.GetResource().Resource == "services" {
+			t.Error("Expected the unchanged service not to be patched")
+		}
+	}
+	if _, err := clientset.CoreV1().Pods("default").Get(context.TODO(), "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the pod to be created, got %v", err)
+	}
+}
+
+func TestApplyYamlDocumentsDryRun(t *testing.T) {
+	clientset := fake.NewSimpleClientset(applyExisting()...)
+
+	results, err := ApplyYamlDocuments(context.Background(), clientset, "default", applyManifest, ApplyOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyYamlDocuments failed: %v", err)
+	}
+	actions := []ApplyAction{ApplyUpdated, ApplyUnchanged, ApplyCreated}
+	for i, action := range actions {
+		if results[i].Action != action {
+			t.Errorf("Expected result %d to be %s, got %+v", i, action, results[i])
+		}
+	}
+
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("Expected a dry run to only read, got %s %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+	configmap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil || configmap.Data["mode"] != "debug" {
+		t.Errorf("Expected the configmap to be left alone, got %v, %v", configmap, err)
+	}
+}
+
+func TestApplyYamlPatchesObjectCreatedMeanwhile(t *testing.T) {
+	// The configmap shows up between the Get and the Create, as when another
+	// client applies it at the same time
+	clientset := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Data:       map[string]string{"mode": "debug"},
+	})
+	gets := 0
+	clientset.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if gets++; gets == 1 {
+			return true, nil, errors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "web")
+		}
+		return false, nil, nil
+	})
+
+	results, err := ApplyYamlDocuments(context.Background(), clientset, "default", configMapYAML, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("ApplyYamlDocuments failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil || results[0].Action != ApplyUpdated {
+		t.Fatalf("Expected the configmap to be updated, got %+v", results)
+	}
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil || configMap.Data["mode"] != "production" {
+		t.Errorf("Expected the patched configmap, got %v, %v", configMap, err)
+	}
+}
+
+func TestApplyYamlStreamStopsOnCallbackError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	stop := errors.NewBadRequest("stop")
+
+	calls := 0
+	err := ApplyYamlStream(context.Background(), clientset, "default", strings.NewReader(applyManifest), ApplyOptions{}, func(ApplyResult) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected the callback error after one document, got %v after %d", err, calls)
+	}
+}
+
+func TestDeleteYamlIsIdempotent(t *testing.T) {
+	for name, yaml := range map[string]string{
+		"pod":            podYAML,
+		"deployment":     deploymentYAML,
+		"service":        serviceYAML,
+		"configmap":      configMapYAML,
+		"secret":         secretYAML,
+		"serviceaccount": serviceAccountYAML,
+		"ingress":        ingressYAML,
+	} {
+		t.Run(name, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			if err := ApplyYaml(clientset, "default", yaml); err != nil {
+				t.Fatalf("ApplyYaml failed: %v", err)
+			}
+
+			if err := DeleteYaml(clientset, "default", yaml); err != nil {
+				t.Errorf("Expected delete of existing resource to succeed, got %v", err)
+			}
+			if err := DeleteYaml(clientset, "default", yaml); err != nil {
+				t.Errorf("Expected delete of missing resource to succeed, got %v", err)
+			}
+		})
+	}
+
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(schema.GroupResource{Resource: "pods"}, "web", nil)
+	})
+	if err := DeleteYaml(clientset, "default", podYAML); !errors.IsForbidden(err) {
+		t.Errorf("Expected other delete errors to be returned, got %v", err)
+	}
+}
+
+func TestGetNodePressureConditions(t *testing.T) {
+	condition := func(conditionType v1.NodeConditionType, status v1.ConditionStatus) v1.NodeCondition {
+		return v1.NodeCondition{Type: conditionType, Status: status}
+	}
+
+	tests := []struct {
+		name       string
+		conditions []v1.NodeCondition
+		want       []string
+	}{
+		{
+			name: "healthy",
+			conditions: []v1.NodeCondition{
+				condition(v1.NodeReady, v1.ConditionTrue),
+				condition(v1.NodeMemoryPressure, v1.ConditionFalse),
+				condition(v1.NodeDiskPressure, v1.ConditionFalse),
+				condition(v1.NodePIDPressure, v1.ConditionFalse),
+			},
+		},
+		{
+			name: "no conditions",
+		},
+		{
+			name: "memory pressure",
+			conditions: []v1.NodeCondition{
+				condition(v1.NodeReady, v1.ConditionTrue),
+				condition(v1.NodeMemoryPressure, v1.ConditionTrue),
+				condition(v1.NodeDiskPressure, v1.ConditionFalse),
+			},
+			want: []string{"MemoryPressure"},
+		},
+		{
+			name: "all pressure in condition order",
+			conditions: []v1.NodeCondition{
+				condition(v1.NodePIDPressure, v1.ConditionTrue),
+				condition(v1.NodeMemoryPressure, v1.ConditionTrue),
+				condition(v1.NodeDiskPressure, v1.ConditionTrue),
+			},
+			want: []string{"PIDPressure", "MemoryPressure", "DiskPressure"},
+		},
+		{
+			name: "unknown status and other true conditions are ignored",
+			conditions: []v1.NodeCondition{
+				condition(v1.NodeReady, v1.ConditionTrue),
+				condition(v1.NodeNetworkUnavailable, v1.ConditionTrue),
+				condition(v1.NodeMemoryPressure, v1.ConditionUnknown),
+				condition(v1.NodeDiskPressure, v1.ConditionTrue),
+			},
+			want: []string{"DiskPressure"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetNodePressureConditions(v1.Node{Status: v1.NodeStatus{Conditions: tt.conditions}})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
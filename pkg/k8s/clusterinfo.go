@@ -0,0 +1,212 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	authv1beta1 "k8s.io/api/authentication/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+// InClusterContext is the context reported for the in-cluster config
+const InClusterContext = "in-cluster"
+
+// ClientInfo describes the cluster a client was configured for. It never
+// holds credentials: bearer tokens and client key paths are left out.
+type ClientInfo struct {
+	// Server is the URL of the API server
+	Server string `json:"server"`
+	// Kubeconfig is the path of the kubeconfig file, empty in-cluster
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context is the kubeconfig's current context, or InClusterContext
+	Context   string `json:"context"`
+	InCluster bool   `json:"inCluster"`
+	// Auth describes how the client authenticates, e.g. "token file
+	// /var/run/secrets/kubernetes.io/serviceaccount/token"
+	Auth string `json:"auth"`
+	// CredentialExpiry is when the client certificate or inline bearer
+	// token expires, if either does
+	CredentialExpiry *time.Time `json:"credentialExpiry,omitempty"`
+}
+
+// NewClientInfo describes a REST config loaded from kubeconfig with its
+// current context, or from the in-cluster config when kubeconfig is empty
+func NewClientInfo(config *rest.Config, kubeconfig, context string) *ClientInfo {
+	info := &ClientInfo{
+		Server:           config.Host,
+		Kubeconfig:       kubeconfig,
+		Context:          context,
+		Auth:             describeAuth(config),
+		CredentialExpiry: CredentialExpiry(config),
+	}
+	if kubeconfig == "" {
+		info.InCluster = true
+		info.Context = InClusterContext
+	}
+	return info
+}
+
+// describeAuth names the credentials of a REST config without revealing
+// them
+func describeAuth(config *rest.Config) string {
+	var methods []string
+	switch {
+	case config.BearerTokenFile != "":
+		methods = append(methods, "token file "+config.BearerTokenFile)
+	case config.BearerToken != "":
+		methods = append(methods, "bearer token (redacted)")
+	}
+	switch {
+	case config.CertFile != "":
+		methods = append(methods, "client certificate "+config.CertFile+" (key redacted)")
+	case len(config.CertData) > 0:
+		methods = append(methods, "client certificate (inline, key redacted)")
+	}
+	if config.ExecProvider != nil {
+		methods = append(methods, "exec plugin "+config.ExecProvider.Command)
+	}
+	if config.AuthProvider != nil {
+		methods = append(methods, "auth provider "+config.AuthProvider.Name)
+	}
+	if config.Username != "" {
+		methods = append(methods, "basic auth as "+config.Username)
+	}
+	if len(methods) == 0 {
+		return "none"
+	}
+	return strings.Join(methods, ", ")
+}
+
+// ClusterInfo is where a running instance is pointed at: its client's
+// configuration, who the API server authenticates it as, and what the server
+// runs and serves
+type ClusterInfo struct {
+	ClientInfo
+	// User is the authenticated user or service account, from a
+	// SelfSubjectReview
+	User   string   `json:"user,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// ServerVersion is the API server's git version, e.g. "v1.28.3"
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// APIServer is the server's latency, API groups and resource types
+	APIServer *ServerInfo `json:"apiServer,omitempty"`
+	// FeatureGates are the server's feature gates, from its metrics
+	FeatureGates []FeatureGate `json:"featureGates,omitempty"`
+	// Warnings say what could not be asked of the API server
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GetClusterInfo asks the API server who clientset is, which version it runs,
+// what it serves and which feature gates it has. Any may be unavailable, e.g.
+// SelfSubjectReview before Kubernetes 1.27, /metrics without RBAC or an
+// unreachable server; the answer then carries a warning instead.
+func GetClusterInfo(ctx context.Context, clientset kubernetes.Interface, client *ClientInfo) *ClusterInfo {
+	info := &ClusterInfo{}
+	if client != nil {
+		info.ClientInfo = *client
+	}
+	if expiry := info.CredentialExpiry; expiry != nil && time.Until(*expiry) < CredentialExpiryWarning {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("credentials expire at %s", expiry.Format(time.RFC3339)))
+	}
+
+	user, err := reviewSelf(ctx, clientset)
+	if err != nil {
+		klog.Warningf("Failed to review the authenticated user: %v", err)
+		info.Warnings = append(info.Warnings, fmt.Sprintf("user unavailable: %v", err))
+	} else {
+		info.User = user.Username
+		info.Groups = user.Groups
+	}
+
+	server, err := GetServerInfo(ctx, clientset)
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("server version unavailable: %v", err))
+		return info
+	}
+	info.ServerVersion = server.Version
+	info.APIServer = server
+
+	gates, err := GetFeatureGates(ctx, clientset)
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("feature gates unavailable: %v", err))
+	} else {
+		info.FeatureGates = gates
+	}
+	return info
+}
+
+// reviewSelf returns the user the API server authenticates clientset as,
+// through authentication.k8s.io/v1 or, before Kubernetes 1.28, v1beta1
+func reviewSelf(ctx context.Context, clientset kubernetes.Interface) (authv1.UserInfo, error) {
+	review, err := clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		return review.Status.UserInfo, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return authv1.UserInfo{}, err
+	}
+	betaReview, err := clientset.AuthenticationV1beta1().SelfSubjectReviews().Create(ctx, &authv1beta1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return authv1.UserInfo{}, err
+	}
+	return betaReview.Status.UserInfo, nil
+}
+
+// Lines returns the cluster info as "Label: value" lines, for the TUI
+func (i *ClusterInfo) Lines() []string {
+	kubeconfig := i.Kubeconfig
+	if i.InCluster {
+		kubeconfig = "(in-cluster config)"
+	}
+	user := i.User
+	if user == "" {
+		user = "unknown"
+	}
+	if len(i.Groups) > 0 {
+		user += " (groups: " + strings.Join(i.Groups, ", ") + ")"
+	}
+	version := i.ServerVersion
+	if version == "" {
+		version = "unknown"
+	}
+	lines := []string{
+		"Server:         " + i.Server,
+		"Kubeconfig:     " + kubeconfig,
+		"Context:        " + i.Context,
+		"Credentials:    " + i.Auth,
+		"User:           " + user,
+		"Server version: " + version,
+	}
+	if i.CredentialExpiry != nil {
+		lines = append(lines, "Cred. expiry:   "+i.CredentialExpiry.Local().Format(time.RFC3339))
+	}
+	if i.APIServer != nil {
+		lines = append(lines,
+			fmt.Sprintf("Latency:        %s", i.APIServer.Latency.Round(time.Millisecond/10)),
+			fmt.Sprintf("API groups:     %d (%d resource types)", len(i.APIServer.APIGroups), len(i.APIServer.SupportedResources)),
+		)
+		if len(i.FeatureGates) > 0 {
+			enabled := 0
+			for _, gate := range i.FeatureGates {
+				if gate.Enabled {
+					enabled++
+				}
+			}
+			lines = append(lines, fmt.Sprintf("Feature gates:  %d (%d enabled)", len(i.FeatureGates), enabled))
+		}
+		for _, warning := range i.APIServer.Warnings {
+			lines = append(lines, "Warning:        discovery failed for "+warning)
+		}
+	}
+	for _, warning := range i.Warnings {
+		lines = append(lines, "Warning:        "+warning)
+	}
+	return lines
+}
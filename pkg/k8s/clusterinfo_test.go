@@ -0,0 +1,238 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	authv1 "k8s.io/api/authentication/v1"
+	authv1beta1 "k8s.io/api/authentication/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestNewClientInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *rest.Config
+		kubeconfig string
+		context    string
+		want       ClientInfo
+		secrets    []string
+	}{
+		{
+			name: "in-cluster",
+			config: &rest.Config{
+				Host:            "https://10.96.0.1:443",
+				BearerToken:     "eyJhbGciOiJSUzI1NiJ9.secret",
+				BearerTokenFile: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+			},
+			want: ClientInfo{
+				Server:    "https://10.96.0.1:443",
+				Context:   InClusterContext,
+				InCluster: true,
+				Auth:      "token file /var/run/secrets/kubernetes.io/serviceaccount/token",
+			},
+			secrets: []string{"eyJhbGciOiJSUzI1NiJ9.secret"},
+		},
+		{
+			name: "kubeconfig with a client certificate",
+			config: &rest.Config{
+				Host: "https://prod.example.com:6443",
+				TLSClientConfig: rest.TLSClientConfig{
+					CertFile: "/home/dev/.kube/prod.crt",
+					KeyFile:  "/home/dev/.kube/prod.key",
+				},
+			},
+			kubeconfig: "/home/dev/.kube/config",
+			context:    "prod",
+			want: ClientInfo{
+				Server:     "https://prod.example.com:6443",
+				Kubeconfig: "/home/dev/.kube/config",
+				Context:    "prod",
+				Auth:       "client certificate /home/dev/.kube/prod.crt (key redacted)",
+			},
+			secrets: []string{"/home/dev/.kube/prod.key"},
+		},
+		{
+			name: "kubeconfig with a token and inline certificate",
+			config: &rest.Config{
+				Host:            "https://staging.example.com",
+				BearerToken:     "s3cr3t",
+				TLSClientConfig: rest.TLSClientConfig{CertData: []byte("cert"), KeyData: []byte("private-key")},
+				ExecProvider:    &clientcmdapi.ExecConfig{Command: "aws"},
+			},
+			kubeconfig: "/etc/kgo/kubeconfig",
+			context:    "staging",
+			want: ClientInfo{
+				Server:     "https://staging.example.com",
+				Kubeconfig: "/etc/kgo/kubeconfig",
+				Context:    "staging",
+				Auth:       "bearer token (redacted), client certificate (inline, key redacted), exec plugin aws",
+			},
+			secrets: []string{"s3cr3t", "private-key"},
+		},
+		{
+			name:       "no credentials",
+			config:     &rest.Config{Host: "http://localhost:8001"},
+			kubeconfig: "/tmp/proxy",
+			context:    "proxy",
+			want:       ClientInfo{Server: "http://localhost:8001", Kubeconfig: "/tmp/proxy", Context: "proxy", Auth: "none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := NewClientInfo(tt.config, tt.kubeconfig, tt.context)
+			if *info != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, *info)
+			}
+			data, err := json.Marshal(info)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			for _, secret := range tt.secrets {
+				if strings.Contains(string(data), secret) {
+					t.Errorf("Expected %q to be redacted, got %s", secret, data)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildConfigReportsKubeconfigContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+users:
+- name: dev-admin
+  user:
+    token: s3cr3t
+contexts:
+- name: dev-admin@dev
+  context:
+    cluster: dev
+    user: dev-admin
+current-context: dev-admin@dev
+`
+	if err := os.WriteFile(kubeconfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, info, err := buildConfig(kubeconfig)
+	if err != nil {
+		t.Fatalf("Failed to build config: %v", err)
+	}
+	if config.BearerToken != "s3cr3t" {
+		t.Errorf("Expected the kubeconfig's token in the REST config, got %q", config.BearerToken)
+	}
+	want := ClientInfo{
+		Server:     "https://dev.example.com:6443",
+		Kubeconfig: kubeconfig,
+		Context:    "dev-admin@dev",
+		Auth:       "bearer token (redacted)",
+	}
+	if *info != want {
+		t.Errorf("Expected %+v, got %+v", want, *info)
+	}
+}
+
+func TestGetClusterInfo(t *testing.T) {
+	client := &ClientInfo{Server: "https://10.96.0.1:443", Context: InClusterContext, InCluster: true, Auth: "none"}
+
+	clientset := fake.NewSimpleClientset()
+	discovery := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.28.3"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "services"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments"}}},
+	}
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := &authv1.SelfSubjectReview{}
+		review.Status.UserInfo = authv1.UserInfo{
+			Username: "system:serviceaccount:kgo:kgo",
+			Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+		}
+		return true, review, nil
+	})
+
+	info := GetClusterInfo(context.Background(), clientset, client)
+	if info.ClientInfo != *client || info.User != "system:serviceaccount:kgo:kgo" || info.ServerVersion != "v1.28.3" || len(info.Warnings) != 0 {
+		t.Errorf("Expected the client, user and version, got %+v", info)
+	}
+	if !reflect.DeepEqual(info.Groups, []string{"system:serviceaccounts", "system:authenticated"}) {
+		t.Errorf("Expected the user's groups, got %v", info.Groups)
+	}
+	lines := strings.Join(info.Lines(), "\n")
+	for _, want := range []string{"Kubeconfig:     (in-cluster config)", "User:           system:serviceaccount:kgo:kgo (groups:", "Server version: v1.28.3", "API groups:     2 (3 resource types)"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("Expected %q in the lines, got:\n%s", want, lines)
+		}
+	}
+}
+
+func TestGetClusterInfoFallsBackToV1beta1(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Version == "v1" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "authentication.k8s.io", Resource: "selfsubjectreviews"}, "")
+		}
+		review := &authv1beta1.SelfSubjectReview{}
+		review.Status.UserInfo.Username = "dev-admin"
+		return true, review, nil
+	})
+
+	info := GetClusterInfo(context.Background(), clientset, nil)
+	if info.User != "dev-admin" {
+		t.Errorf("Expected the v1beta1 review's user, got %+v", info)
+	}
+}
+
+func TestGetClusterInfoWarnsWithoutReview(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "authentication.k8s.io", Resource: "selfsubjectreviews"}, "", nil)
+	})
+
+	info := GetClusterInfo(context.Background(), clientset, nil)
+	if info.User != "" || len(info.Warnings) != 1 || !strings.HasPrefix(info.Warnings[0], "user unavailable: ") {
+		t.Errorf("Expected a warning instead of the user, got %+v", info)
+	}
+	if !strings.Contains(strings.Join(info.Lines(), "\n"), "User:           unknown") {
+		t.Errorf("Expected an unknown user, got %v", info.Lines())
+	}
+}
+
+func TestGetClusterInfoWarnsOfExpiringCredentials(t *testing.T) {
+	soon := time.Now().Add(3 * time.Hour)
+	info := GetClusterInfo(context.Background(), fake.NewSimpleClientset(), &ClientInfo{CredentialExpiry: &soon})
+	if !strings.Contains(strings.Join(info.Warnings, "\n"), "credentials expire at "+soon.Format(time.RFC3339)) {
+		t.Errorf("Expected a warning about the expiring credentials, got %v", info.Warnings)
+	}
+	if !strings.Contains(strings.Join(info.Lines(), "\n"), "Cred. expiry:   ") {
+		t.Errorf("Expected the expiry in the lines, got %v", info.Lines())
+	}
+
+	later := time.Now().Add(30 * 24 * time.Hour)
+	info = GetClusterInfo(context.Background(), fake.NewSimpleClientset(), &ClientInfo{CredentialExpiry: &later})
+	if strings.Contains(strings.Join(info.Warnings, "\n"), "credentials expire") {
+		t.Errorf("Expected no warning a month ahead, got %v", info.Warnings)
+	}
+}
@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListCoalescer shares the result of identical concurrent list calls, and
+// reuses a result for a short TTL to absorb rapid sequential polls. Results
+// are shared between callers and must not be modified.
+//
+// kgo keeps no informer cache: every list it serves is a LIST call to the API
+// server, which is what the coalescer saves. A server serving lists from an
+// informer cache should pass a nil coalescer instead.
+type ListCoalescer struct {
+	cluster string
+	ttl     time.Duration
+	group   singleflight.Group
+
+	mu      sync.Mutex
+	results map[string]coalescedResult
+	// swept is when expired results were last dropped
+	swept time.Time
+
+	upstream  atomic.Int64
+	coalesced atomic.Int64
+}
+
+// coalescedResult is a list result kept until it expires
+type coalescedResult struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewListCoalescer creates a coalescer for the named cluster. A zero TTL only
+// shares calls that are in flight at the same time.
+func NewListCoalescer(cluster string, ttl time.Duration) *ListCoalescer {
+	return &ListCoalescer{
+		cluster: cluster,
+		ttl:     ttl,
+		results: make(map[string]coalescedResult),
+	}
+}
+
+// Do runs fn once for all concurrent callers with the same kind, namespace
+// and list options, and returns its shared result
+func (c *ListCoalescer) Do(kind, namespace string, opts metav1.ListOptions, fn func() (interface{}, error)) (interface{}, error) {
+	key := fmt.Sprintf("%s/%s/%s/%s", c.cluster, kind, namespace, optionsKey(opts))
+
+	c.mu.Lock()
+	if cached, ok := c.results[key]; ok {
+		if time.Now().Before(cached.expires) {
+			c.mu.Unlock()
+			c.coalesced.Add(1)
+			return cached.value, nil
+		}
+		delete(c.results, key)
+	}
+	c.mu.Unlock()
+
+	// Only the caller that runs fn counts as an upstream call; every other
+	// caller sharing its result was coalesced
+	leader := false
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		leader = true
+		c.upstream.Add(1)
+		value, err := fn()
+		if err == nil && c.ttl > 0 {
+			c.store(key, value)
+		}
+		return value, err
+	})
+	if !leader {
+		c.coalesced.Add(1)
+	}
+	return value, err
+}
+
+// store keeps a result for the TTL. Results of keys that are not asked for
+// again are never replaced, so expired ones are dropped here, at most once
+// per TTL, to keep the map from growing with every key ever listed.
+func (c *ListCoalescer) store(key string, value interface{}) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.Sub(c.swept) >= c.ttl {
+		for k, result := range c.results {
+			if !now.Before(result.expires) {
+				delete(c.results, k)
+			}
+		}
+		c.swept = now
+	}
+	c.results[key] = coalescedResult{value: value, expires: now.Add(c.ttl)}
+}
+
+// optionsKey is the part of the coalescing key for list options: those
+// changing what a list returns
+func optionsKey(opts metav1.ListOptions) string {
+	return fmt.Sprintf("labels=%s,fields=%s,rv=%s,rvMatch=%s,limit=%d,continue=%s",
+		opts.LabelSelector, opts.FieldSelector, opts.ResourceVersion, opts.ResourceVersionMatch, opts.Limit, opts.Continue)
+}
+
+// Coalesced returns how many requests were served without their own upstream call
+func (c *ListCoalescer) Coalesced() int64 {
+	return c.coalesced.Load()
+}
+
+// Upstream returns how many list calls actually reached the API server
+func (c *ListCoalescer) Upstream() int64 {
+	return c.upstream.Load()
+}
+
+// Cached returns how many list results are kept for reuse, expired ones
+// not yet dropped included
+func (c *ListCoalescer) Cached() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.results)
+}
+
+// CoalescedList runs list with opts through the coalescer, or directly when
+// the coalescer is nil
+func CoalescedList[T any](c *ListCoalescer, kind, namespace string, opts metav1.ListOptions, list func(metav1.ListOptions) ([]T, error)) ([]T, error) {
+	if c == nil {
+		return list(opts)
+	}
+	value, err := c.Do(kind, namespace, opts, func() (interface{}, error) {
+		return list(opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]T), nil
+}
@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListCoalescerSharesConcurrentCalls(t *testing.T) {
+	coalescer := NewListCoalescer("test", 0)
+
+	const callers = 10
+	var calls int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([][]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = CoalescedList(coalescer, "pods", "default", metav1.ListOptions{}, func(metav1.ListOptions) ([]string, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return []string{"a", "b"}, nil
+			})
+		}(i)
+	}
+
+	// Give every caller time to join the in-flight call before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", calls)
+	}
+	if coalescer.Upstream() != 1 || coalescer.Coalesced() != callers-1 {
+		t.Errorf("Expected 1 upstream and %d coalesced, got %d and %d", callers-1, coalescer.Upstream(), coalescer.Coalesced())
+	}
+	for i, result := range results {
+		if len(result) != 2 {
+			t.Errorf("Caller %d got %v", i, result)
+		}
+	}
+}
+
+func TestListCoalescerTTL(t *testing.T) {
+	coalescer := NewListCoalescer("test", 50*time.Millisecond)
+
+	var calls int32
+	list := func(metav1.ListOptions) ([]string, error) {
+		atomic.AddInt32(&calls, 1)
+		return []string{"a"}, nil
+	}
+
+	CoalescedList(coalescer, "pods", "default", metav1.ListOptions{}, list)
+	CoalescedList(coalescer, "pods", "default", metav1.ListOptions{}, list)
+	if calls != 1 {
+		t.Errorf("Expected sequential call within TTL to be served from cache, got %d calls", calls)
+	}
+
+	// A different namespace is a different key
+	CoalescedList(coalescer, "pods", "other", metav1.ListOptions{}, list)
+	if calls != 2 {
+		t.Errorf("Expected a separate call for another namespace, got %d calls", calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	CoalescedList(coalescer, "pods", "default", metav1.ListOptions{}, list)
+	if calls != 3 {
+		t.Errorf("Expected a new call after the TTL expired, got %d calls", calls)
+	}
+}
+
+func TestListCoalescerDoesNotCacheErrors(t *testing.T) {
+	coalescer := NewListCoalescer("test", time.Minute)
+
+	var calls int32
+	list := func(metav1.ListOptions) ([]string, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, errors.New("boom")
+		}
+		return []string{"a"}, nil
+	}
+
+	if _, err := CoalescedList(coalescer, "pods", "default", metav1.ListOptions{}, list); err == nil {
+		t.Error("Expected first call to fail")
+	}
+	result, err := CoalescedList(coalescer, "pods", "default", metav1.ListOptions{}, list)
+	if err != nil || len(result) != 1 {
+		t.Errorf("Expected retry to succeed, got %v, %v", result, err)
+	}
+}
+
+func TestNilListCoalescer(t *testing.T) {
+	var calls int32
+	for i := 0; i < 3; i++ {
+		CoalescedList[string](nil, "pods", "default", metav1.ListOptions{}, func(metav1.ListOptions) ([]string, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, nil
+		})
+	}
+	if calls != 3 {
+		t.Errorf("Expected nil coalescer to call through every time, got %d calls", calls)
+	}
+}
+
+func TestListCoalescerKeysOnListOptions(t *testing.T) {
+	coalescer := NewListCoalescer("test", time.Minute)
+
+	var selectors []string
+	list := func(opts metav1.ListOptions) ([]string, error) {
+		selectors = append(selectors, opts.LabelSelector)
+		return []string{opts.LabelSelector}, nil
+	}
+
+	web, _ := CoalescedList(coalescer, "pods", "default", metav1.ListOptions{LabelSelector: "app=web"}, list)
+	db, _ := CoalescedList(coalescer, "pods", "default", metav1.ListOptions{LabelSelector: "app=db"}, list)
+	again, _ := CoalescedList(coalescer, "pods", "default", metav1.ListOptions{LabelSelector: "app=web"}, list)
+	if len(selectors) != 2 || selectors[0] != "app=web" || selectors[1] != "app=db" {
+		t.Errorf("Expected one list per selector, with its selector, got %q", selectors)
+	}
+	if web[0] != "app=web" || db[0] != "app=db" || again[0] != "app=web" {
+		t.Errorf("Expected each selector's own result, got %v, %v and %v", web, db, again)
+	}
+}
+
+func TestListCoalescerSweepsExpiredResults(t *testing.T) {
+	coalescer := NewListCoalescer("test", 20*time.Millisecond)
+	list := func(metav1.ListOptions) ([]string, error) {
+		return []string{"a"}, nil
+	}
+
+	for _, namespace := range []string{"a", "b", "c"} {
+		CoalescedList(coalescer, "pods", namespace, metav1.ListOptions{}, list)
+	}
+	if cached := coalescer.Cached(); cached != 3 {
+		t.Fatalf("Expected 3 cached results, got %d", cached)
+	}
+
+	// The results of namespaces never listed again are dropped by the next
+	// result stored after they expire
+	time.Sleep(30 * time.Millisecond)
+	CoalescedList(coalescer, "pods", "d", metav1.ListOptions{}, list)
+	if cached := coalescer.Cached(); cached != 1 {
+		t.Errorf("Expected only the new result to be kept, got %d", cached)
+	}
+}
@@ -0,0 +1,198 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"k8s-dashboard/pkg/crypto"
+	"k8s-dashboard/pkg/timefmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ConfigMapKeySize is the size in bytes of one configmap value
+type ConfigMapKeySize struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+	// Binary is set for keys held in binaryData
+	Binary bool `json:"binary,omitempty"`
+}
+
+// ConfigMapSummary is a configmap without its values: its metadata, and the
+// name and size of every key. Large configmaps, such as CA bundles, are
+// cheap to list and render this way.
+type ConfigMapSummary struct {
+	metav1.ObjectMeta `json:"metadata"`
+	// Size is the total size of the values in bytes
+	Size int                `json:"size"`
+	Keys []ConfigMapKeySize `json:"keys"`
+	// AgeSeconds is the age of the configmap when it was summarized, next to
+	// metadata.creationTimestamp
+	AgeSeconds int64 `json:"ageSeconds"`
+}
+
+// MaxConfigMapSize is the most data the API server accepts in one configmap
+const MaxConfigMapSize = 1 << 20
+
+// ErrConfigMapTooLarge is returned for configmap data over MaxConfigMapSize
+var ErrConfigMapTooLarge = errors.New("configmap data exceeds 1MiB")
+
+// NewConfigMapFromData builds a configmap the way kubectl create configmap
+// does with --from-literal and --from-file: every literal and every file is a
+// key. Files that are valid UTF-8 go to data and others to binaryData. Keys
+// are not validated here, but a key given twice is an error.
+func NewConfigMapFromData(namespace, name string, literals map[string]string, files map[string][]byte) (*v1.ConfigMap, error) {
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	for key, value := range literals {
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[key] = value
+	}
+	for key, value := range files {
+		if _, ok := configMap.Data[key]; ok {
+			return nil, fmt.Errorf("key %q is given more than once", key)
+		}
+		if utf8.Valid(value) {
+			if configMap.Data == nil {
+				configMap.Data = make(map[string]string)
+			}
+			configMap.Data[key] = string(value)
+			continue
+		}
+		if configMap.BinaryData == nil {
+			configMap.BinaryData = make(map[string][]byte)
+		}
+		configMap.BinaryData[key] = value
+	}
+	if size := ConfigMapDataSize(configMap); size > MaxConfigMapSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrConfigMapTooLarge, size)
+	}
+	return configMap, nil
+}
+
+// ConfigMapDataSize returns the total size in bytes of the data and
+// binaryData values of a configmap
+func ConfigMapDataSize(configMap *v1.ConfigMap) int {
+	size := 0
+	for _, value := range configMap.Data {
+		size += len(value)
+	}
+	for _, value := range configMap.BinaryData {
+		size += len(value)
+	}
+	return size
+}
+
+// SummarizeConfigMap drops the values of a configmap, keeping its metadata and
+// the size of each key. Keys are sorted by name.
+func SummarizeConfigMap(configMap *v1.ConfigMap) ConfigMapSummary {
+	summary := ConfigMapSummary{
+		ObjectMeta: *configMap.ObjectMeta.DeepCopy(),
+		Size:       ConfigMapDataSize(configMap),
+		Keys:       make([]ConfigMapKeySize, 0, len(configMap.Data)+len(configMap.BinaryData)),
+		AgeSeconds: timefmt.AgeSeconds(configMap.CreationTimestamp.Time, time.Now()),
+	}
+	for key, value := range configMap.Data {
+		summary.Keys = append(summary.Keys, ConfigMapKeySize{Key: key, Size: len(value)})
+	}
+	for key, value := range configMap.BinaryData {
+		summary.Keys = append(summary.Keys, ConfigMapKeySize{Key: key, Size: len(value), Binary: true})
+	}
+	sort.Slice(summary.Keys, func(i, j int) bool {
+		return summary.Keys[i].Key < summary.Keys[j].Key
+	})
+	return summary
+}
+
+// SummarizeConfigMaps summarizes each configmap of a list
+func SummarizeConfigMaps(configMaps []v1.ConfigMap) []ConfigMapSummary {
+	summaries := make([]ConfigMapSummary, len(configMaps))
+	for i := range configMaps {
+		summaries[i] = SummarizeConfigMap(&configMaps[i])
+	}
+	return summaries
+}
+
+// ListConfigMapSummaries lists the configmaps in a namespace without their
+// values. The values are still transferred from the API server, which has no
+// projection of configmap data, but are not kept.
+func ListConfigMapSummaries(clientset kubernetes.Interface, namespace string) ([]ConfigMapSummary, error) {
+	configMaps, err := ListConfigMaps(clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeConfigMaps(configMaps), nil
+}
+
+// EncryptedAnnotation set to EncryptionSOPS marks a configmap whose values
+// are SOPS documents, as created by POST /api/v1/configmaps/:namespace with
+// an X-Encrypt: sops header
+const (
+	EncryptedAnnotation = "kgo.io/encrypted"
+	EncryptionSOPS      = "sops"
+)
+
+// IsEncryptedConfigMap reports whether the values of a configmap are SOPS
+// documents
+func IsEncryptedConfigMap(configMap *v1.ConfigMap) bool {
+	return configMap.Annotations[EncryptedAnnotation] == EncryptionSOPS
+}
+
+// EncryptConfigMap replaces every data and binaryData value of a configmap
+// with a SOPS document encrypting it for key, see crypto.Encrypt, and sets
+// EncryptedAnnotation
+func EncryptConfigMap(configMap *v1.ConfigMap, key string) error {
+	for name, value := range configMap.Data {
+		encrypted, err := crypto.Encrypt(key, []byte(value))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt key %s: %w", name, err)
+		}
+		configMap.Data[name] = string(encrypted)
+	}
+	for name, value := range configMap.BinaryData {
+		encrypted, err := crypto.Encrypt(key, value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt key %s: %w", name, err)
+		}
+		configMap.BinaryData[name] = encrypted
+	}
+	if size := ConfigMapDataSize(configMap); size > MaxConfigMapSize {
+		return fmt.Errorf("%w once encrypted: %d bytes", ErrConfigMapTooLarge, size)
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	configMap.Annotations[EncryptedAnnotation] = EncryptionSOPS
+	return nil
+}
+
+// DecryptConfigMap returns a copy of a configmap with EncryptedAnnotation
+// with its values decrypted with key, see crypto.Decrypt. Other configmaps
+// are returned as they are.
+func DecryptConfigMap(configMap *v1.ConfigMap, key string) (*v1.ConfigMap, error) {
+	if !IsEncryptedConfigMap(configMap) {
+		return configMap, nil
+	}
+	decrypted := configMap.DeepCopy()
+	for name, value := range decrypted.Data {
+		plaintext, err := crypto.Decrypt(key, []byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key %s: %w", name, err)
+		}
+		decrypted.Data[name] = string(plaintext)
+	}
+	for name, value := range decrypted.BinaryData {
+		plaintext, err := crypto.Decrypt(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key %s: %w", name, err)
+		}
+		decrypted.BinaryData[name] = plaintext
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,136 @@
+package k8s
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s-dashboard/pkg/crypto"
+
+	"filippo.io/age"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newSizedConfigMap() *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Data:       map[string]string{"ca.crt": "0123456789", "mode": "on"},
+		BinaryData: map[string][]byte{"bundle.der": make([]byte, 1000)},
+	}
+}
+
+func TestConfigMapDataSize(t *testing.T) {
+	if size := ConfigMapDataSize(newSizedConfigMap()); size != 1012 {
+		t.Errorf("Expected data and binaryData values to add up to 1012 bytes, got %d", size)
+	}
+	if size := ConfigMapDataSize(&v1.ConfigMap{}); size != 0 {
+		t.Errorf("Expected an empty configmap to have size 0, got %d", size)
+	}
+}
+
+func TestSummarizeConfigMap(t *testing.T) {
+	configMap := newSizedConfigMap()
+	summary := SummarizeConfigMap(configMap)
+
+	if summary.Name != "ca" || summary.Namespace != "default" || summary.Labels["app"] != "web" {
+		t.Errorf("Expected metadata to be kept, got %+v", summary.ObjectMeta)
+	}
+	if summary.Size != 1012 {
+		t.Errorf("Expected size 1012, got %d", summary.Size)
+	}
+	want := []ConfigMapKeySize{
+		{Key: "bundle.der", Size: 1000, Binary: true},
+		{Key: "ca.crt", Size: 10},
+		{Key: "mode", Size: 2},
+	}
+	if !reflect.DeepEqual(summary.Keys, want) {
+		t.Errorf("Expected keys %+v, got %+v", want, summary.Keys)
+	}
+
+	summary.Labels["app"] = "changed"
+	if configMap.Labels["app"] != "web" {
+		t.Error("Expected the summary not to share metadata with the configmap")
+	}
+}
+
+func TestListConfigMapSummaries(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newSizedConfigMap())
+	summaries, err := ListConfigMapSummaries(clientset, "default")
+	if err != nil {
+		t.Fatalf("ListConfigMapSummaries failed: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Name != "ca" || len(summaries[0].Keys) != 3 {
+		t.Errorf("Expected one summary with three keys, got %+v", summaries)
+	}
+}
+
+func TestNewConfigMapFromData(t *testing.T) {
+	configMap, err := NewConfigMapFromData("default", "app",
+		map[string]string{"mode": "on"},
+		map[string][]byte{"app.conf": []byte("listen 80\n"), "logo.png": {0x89, 'P', 'N', 'G', 0xff}},
+	)
+	if err != nil {
+		t.Fatalf("NewConfigMapFromData failed: %v", err)
+	}
+	if configMap.Name != "app" || configMap.Namespace != "default" {
+		t.Errorf("Unexpected metadata %+v", configMap.ObjectMeta)
+	}
+	wantData := map[string]string{"mode": "on", "app.conf": "listen 80\n"}
+	if !reflect.DeepEqual(configMap.Data, wantData) {
+		t.Errorf("Expected data %v, got %v", wantData, configMap.Data)
+	}
+	if len(configMap.BinaryData) != 1 || len(configMap.BinaryData["logo.png"]) != 5 {
+		t.Errorf("Expected the non-UTF-8 file in binaryData, got %v", configMap.BinaryData)
+	}
+
+	if _, err := NewConfigMapFromData("default", "app", map[string]string{"mode": "on"}, map[string][]byte{"mode": []byte("off")}); err == nil {
+		t.Error("Expected an error for a key given as a literal and a file")
+	}
+	_, err = NewConfigMapFromData("default", "app", nil, map[string][]byte{"big": make([]byte, MaxConfigMapSize+1)})
+	if !errors.Is(err, ErrConfigMapTooLarge) {
+		t.Errorf("Expected ErrConfigMapTooLarge, got %v", err)
+	}
+}
+
+func TestEncryptConfigMap(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate an age identity: %v", err)
+	}
+	configMap := newSizedConfigMap()
+	if err := EncryptConfigMap(configMap, identity.Recipient().String()); err != nil {
+		t.Fatalf("EncryptConfigMap failed: %v", err)
+	}
+	if !IsEncryptedConfigMap(configMap) {
+		t.Errorf("Expected %s to be set, got %v", EncryptedAnnotation, configMap.Annotations)
+	}
+	if !crypto.IsEncrypted([]byte(configMap.Data["mode"])) || !crypto.IsEncrypted(configMap.BinaryData["bundle.der"]) {
+		t.Errorf("Expected every value to be a SOPS document, got %v", configMap.Data)
+	}
+
+	decrypted, err := DecryptConfigMap(configMap, identity.String())
+	if err != nil {
+		t.Fatalf("DecryptConfigMap failed: %v", err)
+	}
+	want := newSizedConfigMap()
+	if !reflect.DeepEqual(decrypted.Data, want.Data) || !reflect.DeepEqual(decrypted.BinaryData, want.BinaryData) {
+		t.Errorf("Expected the values to round-trip, got %v", decrypted.Data)
+	}
+	if !crypto.IsEncrypted([]byte(configMap.Data["mode"])) {
+		t.Error("Expected DecryptConfigMap to leave the configmap it was given encrypted")
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate an age identity: %v", err)
+	}
+	if _, err := DecryptConfigMap(configMap, other.String()); !errors.Is(err, crypto.ErrNoIdentityMatch) {
+		t.Errorf("Expected %v for another key, got %v", crypto.ErrNoIdentityMatch, err)
+	}
+	plain := newSizedConfigMap()
+	if got, err := DecryptConfigMap(plain, other.String()); got != plain || err != nil {
+		t.Errorf("Expected a configmap without %s to be returned as is, got %v", EncryptedAnnotation, err)
+	}
+}
@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/metrics"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Weights of the RestartCost of a container
+const (
+	restartCostPerRestart     = 100
+	restartCostPerFailedProbe = 10
+)
+
+// containerSamples keeps the last two readings of each container for the
+// CPU rate of change. Containers not read for 10 minutes are forgotten.
+var containerSamples = metrics.NewSampler(10 * time.Minute)
+
+// ContainerStats is the usage of a container from metrics-server, compared
+// with its previous reading, and how costly its restarts have been
+type ContainerStats struct {
+	Namespace string
+	Pod       string
+	Container string
+	// Timestamp is when metrics-server took the reading
+	Timestamp time.Time
+	CPUMilli  int64
+	// CPURateOfChange is the change of CPU usage since the previous reading
+	// of the container, in percent; nil on the first reading, or when the
+	// previous one used no CPU
+	CPURateOfChange *float64
+	// MemoryRSS is the memory metrics-server reports for the container,
+	// which is its working set: the closest the metrics API has to RSS
+	MemoryRSS    int64
+	RestartCount int32
+	// FailedProbes counts the failures of the container's probes in the
+	// pod's events
+	FailedProbes int
+	// RestartCost scores how disruptive the container has been:
+	// 100 per restart plus 10 per failed probe
+	RestartCost int
+}
+
+// GetContainerStats returns the latest usage of a container from
+// metrics-server, with its restarts and probe failures from the API server.
+// Each reading is kept so that the next call can give the rate of change
+// of the CPU usage. It fails when metrics-server has no metrics for the
+// container; when the pod's events cannot be listed FailedProbes is 0.
+func GetContainerStats(ctx context.Context, metricsClientset metricsclientset.Interface, clientset kubernetes.Interface, namespace, podName, containerName string) (*ContainerStats, error) {
+	podMetrics, err := metricsClientset.MetricsV1beta1().PodMetricses(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get metrics of pod %s/%s: %v", namespace, podName, err)
+		return nil, err
+	}
+
+	stats := &ContainerStats{Namespace: namespace, Pod: podName, Container: containerName, Timestamp: podMetrics.Timestamp.Time}
+	found := false
+	for _, container := range podMetrics.Containers {
+		if container.Name == containerName {
+			stats.CPUMilli = container.Usage.Cpu().MilliValue()
+			stats.MemoryRSS = container.Usage.Memory().Value()
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no metrics for container %s of pod %s/%s", containerName, namespace, podName)
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s/%s: %v", namespace, podName, err)
+		return nil, err
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == containerName {
+			stats.RestartCount = status.RestartCount
+		}
+	}
+
+	events, err := ListPodEvents(ctx, clientset, namespace, podName)
+	if err != nil {
+		klog.Warningf("Failed to list events of pod %s/%s, probe failures are not counted: %v", namespace, podName, err)
+	}
+	stats.FailedProbes = countProbeFailures(pod, containerName, events)
+	stats.RestartCost = int(stats.RestartCount)*restartCostPerRestart + stats.FailedProbes*restartCostPerFailedProbe
+
+	latest := metrics.Sample{At: stats.Timestamp, CPUMilli: stats.CPUMilli, MemoryBytes: stats.MemoryRSS}
+	if previous, ok := containerSamples.Record(namespace+"/"+podName+"/"+containerName, latest); ok {
+		if rate, ok := metrics.CPURateOfChange(previous, latest); ok {
+			stats.CPURateOfChange = &rate
+		}
+	}
+	return stats, nil
+}
+
+// countProbeFailures adds up the probe failures of a container in the
+// kubelet's Unhealthy events of its pod, each event counting as many times as
+// it was repeated
+func countProbeFailures(pod *v1.Pod, containerName string, events []v1.Event) int {
+	failures := 0
+	for _, event := range events {
+		if event.Reason != "Unhealthy" || event.InvolvedObject.Kind != "Pod" ||
+			event.InvolvedObject.Namespace != pod.Namespace || event.InvolvedObject.Name != pod.Name ||
+			event.InvolvedObject.FieldPath != "spec.containers{"+containerName+"}" {
+			continue
+		}
+		if !strings.HasSuffix(strings.SplitN(event.Message, ":", 2)[0], "probe failed") {
+			continue
+		}
+		failures += int(max(event.Count, 1))
+	}
+	return failures
+}
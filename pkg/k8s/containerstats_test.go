@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/metrics"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestGetContainerStats(t *testing.T) {
+	previous := containerSamples
+	containerSamples = metrics.NewSampler(time.Hour)
+	defer func() { containerSamples = previous }()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+			{Name: "web", RestartCount: 2},
+			{Name: "sidecar", RestartCount: 7},
+		}},
+	}
+	repeated := probeEvent("web-1", "web", "Liveness probe failed: connection refused", now)
+	repeated.Count = 3
+	failed := probeEvent("web-1", "web", "Readiness probe failed: HTTP probe failed with statuscode: 500", now)
+	sidecar := probeEvent("web-1", "sidecar", "Readiness probe failed: timeout", now)
+	clientset := fake.NewSimpleClientset(pod, &repeated, &failed, &sidecar)
+
+	// Each get serves the next reading, as metrics-server does after a scrape
+	readings := []string{"100m", "100m", "112m"}
+	at := []time.Time{now, now, now.Add(15 * time.Second)}
+	gets := 0
+	metricsClient := &metricsfake.Clientset{}
+	metricsClient.AddReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		i := min(gets, len(readings)-1)
+		gets++
+		return true, &metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+			Timestamp:  metav1.NewTime(at[i]),
+			Containers: []metricsv1beta1.ContainerMetrics{{
+				Name: "web",
+				Usage: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse(readings[i]),
+					v1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			}},
+		}, nil
+	})
+
+	stats, err := GetContainerStats(context.Background(), metricsClient, clientset, "shop", "web-1", "web")
+	if err != nil {
+		t.Fatalf("GetContainerStats failed: %v", err)
+	}
+	if stats.CPUMilli != 100 || stats.MemoryRSS != 64<<20 || stats.CPURateOfChange != nil {
+		t.Errorf("Expected 100m and 64Mi without a rate on the first reading, got %+v", stats)
+	}
+	// Two restarts, and the three repeated liveness failures plus a readiness
+	// failure; the sidecar's are not counted
+	if stats.RestartCount != 2 || stats.FailedProbes != 4 || stats.RestartCost != 240 {
+		t.Errorf("Expected 2 restarts, 4 failed probes and a cost of 240, got %+v", stats)
+	}
+
+	// The same reading has nothing to compare against yet
+	if stats, err = GetContainerStats(context.Background(), metricsClient, clientset, "shop", "web-1", "web"); err != nil || stats.CPURateOfChange != nil {
+		t.Errorf("Expected no rate for a repeated reading, got %+v, %v", stats, err)
+	}
+
+	stats, err = GetContainerStats(context.Background(), metricsClient, clientset, "shop", "web-1", "web")
+	if err != nil {
+		t.Fatalf("GetContainerStats failed: %v", err)
+	}
+	if stats.CPURateOfChange == nil || *stats.CPURateOfChange < 11.99 || *stats.CPURateOfChange > 12.01 {
+		t.Errorf("Expected CPU up 12%%, got %+v", stats)
+	}
+
+	if _, err := GetContainerStats(context.Background(), metricsClient, clientset, "shop", "web-1", "missing"); err == nil {
+		t.Error("Expected an error for a container without metrics")
+	}
+}
@@ -0,0 +1,182 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s-dashboard/pkg/timefmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+// CRDResource is the resource CustomResourceDefinitions are served as
+var CRDResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// CRD is an installed CustomResourceDefinition. CRDs are read through the
+// dynamic client, so that kgo needs no apiextensions clientset.
+type CRD struct {
+	Name   string `json:"name"`
+	Group  string `json:"group"`
+	Kind   string `json:"kind"`
+	Plural string `json:"plural"`
+	// Version is the storage version, or the first version when none is
+	// marked for storage
+	Version string `json:"version"`
+	// Versions are the names of every served version
+	Versions          []string    `json:"versions"`
+	Scope             string      `json:"scope"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	// AgeSeconds is the age of the CRD when it was listed
+	AgeSeconds int64 `json:"ageSeconds"`
+	// Schema is the OpenAPI v3 schema of Version, if it has one
+	Schema map[string]interface{} `json:"schema,omitempty"`
+}
+
+// ListCRDs lists the CustomResourceDefinitions installed in the cluster,
+// sorted by name
+func ListCRDs(ctx context.Context, dynamicClient dynamic.Interface) ([]CRD, error) {
+	list, err := dynamicClient.Resource(CRDResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list custom resource definitions: %v", err)
+		return nil, err
+	}
+
+	crds := make([]CRD, 0, len(list.Items))
+	for i := range list.Items {
+		crds = append(crds, crdFromUnstructured(&list.Items[i]))
+	}
+	sort.Slice(crds, func(i, j int) bool { return crds[i].Name < crds[j].Name })
+	return crds, nil
+}
+
+// crdFromUnstructured reads the fields kgo shows from a CRD object
+func crdFromUnstructured(obj *unstructured.Unstructured) CRD {
+	crd := CRD{
+		Name:              obj.GetName(),
+		CreationTimestamp: obj.GetCreationTimestamp(),
+		AgeSeconds:        timefmt.AgeSeconds(obj.GetCreationTimestamp().Time, time.Now()),
+	}
+	crd.Group, _, _ = unstructured.NestedString(obj.Object, "spec", "group")
+	crd.Kind, _, _ = unstructured.NestedString(obj.Object, "spec", "names", "kind")
+	crd.Plural, _, _ = unstructured.NestedString(obj.Object, "spec", "names", "plural")
+	crd.Scope, _, _ = unstructured.NestedString(obj.Object, "spec", "scope")
+
+	versions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	var stored map[string]interface{}
+	for _, item := range versions {
+		version, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		if served, _, _ := unstructured.NestedBool(version, "served"); served {
+			crd.Versions = append(crd.Versions, name)
+		}
+		if storage, _, _ := unstructured.NestedBool(version, "storage"); storage || stored == nil {
+			stored = version
+		}
+	}
+	if stored != nil {
+		crd.Version, _, _ = unstructured.NestedString(stored, "name")
+		crd.Schema, _, _ = unstructured.NestedMap(stored, "schema", "openAPIV3Schema")
+	}
+	return crd
+}
+
+// SchemaTree renders the properties of an OpenAPI v3 schema as a tree, one
+// line per field with its type, e.g.
+//
+//	spec: object
+//	├─ replicas: integer (required)
+//	└─ image: string
+func SchemaTree(schema map[string]interface{}) []string {
+	var lines []string
+	schemaTree(schema, "", true, &lines)
+	return lines
+}
+
+// schemaTree appends a line per property of schema, and the properties of
+// each nested object, array item or map value below it. Top-level fields have
+// no parent to hang from, so they get no connector.
+func schemaTree(schema map[string]interface{}, prefix string, top bool, lines *[]string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if names, ok := schema["required"].([]interface{}); ok {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+
+		connector, childPrefix := "├─ ", "│  "
+		switch {
+		case top:
+			connector, childPrefix = "", ""
+		case i == len(names)-1:
+			connector, childPrefix = "└─ ", "   "
+		}
+
+		line := fmt.Sprintf("%s%s%s: %s", prefix, connector, name, schemaType(property))
+		if required[name] {
+			line += " (required)"
+		}
+		*lines = append(*lines, line)
+		schemaTree(schemaChild(property), prefix+childPrefix, false, lines)
+	}
+}
+
+// schemaChild returns the schema whose properties nest below a field: the
+// field itself for objects, its items for arrays, its values for maps
+func schemaChild(property map[string]interface{}) map[string]interface{} {
+	if items, ok := property["items"].(map[string]interface{}); ok {
+		return schemaChild(items)
+	}
+	if values, ok := property["additionalProperties"].(map[string]interface{}); ok {
+		return schemaChild(values)
+	}
+	return property
+}
+
+// schemaType describes the type of a schema field, e.g. "[]string" or
+// "map[string]integer"
+func schemaType(property map[string]interface{}) string {
+	if intOrString, _ := property["x-kubernetes-int-or-string"].(bool); intOrString {
+		return "int-or-string"
+	}
+
+	typ, _ := property["type"].(string)
+	switch typ {
+	case "array":
+		items, _ := property["items"].(map[string]interface{})
+		return "[]" + schemaType(items)
+	case "object":
+		if values, ok := property["additionalProperties"].(map[string]interface{}); ok {
+			return "map[string]" + schemaType(values)
+		}
+		if _, ok := property["properties"]; !ok {
+			if preserve, _ := property["x-kubernetes-preserve-unknown-fields"].(bool); preserve {
+				return "object (any fields)"
+			}
+		}
+		return "object"
+	case "":
+		return "any"
+	}
+	return typ
+}
@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+// newTestCRD returns a namespaced CRD with a v1beta1 version and a v1
+// storage version carrying a schema
+func newTestCRD(name, group, kind string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"group": group,
+			"scope": "Namespaced",
+			"names": map[string]interface{}{"kind": kind, "plural": "widgets"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1beta1", "served": true, "storage": false},
+				map[string]interface{}{"name": "v1", "served": true, "storage": true, "schema": map[string]interface{}{
+					"openAPIV3Schema": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"spec": map[string]interface{}{
+								"type":     "object",
+								"required": []interface{}{"size"},
+								"properties": map[string]interface{}{
+									"size": map[string]interface{}{"type": "integer"},
+									"port": map[string]interface{}{"x-kubernetes-int-or-string": true},
+									"ports": map[string]interface{}{
+										"type": "array",
+										"items": map[string]interface{}{
+											"type":       "object",
+											"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+										},
+									},
+									"labels": map[string]interface{}{
+										"type":                 "object",
+										"additionalProperties": map[string]interface{}{"type": "string"},
+									},
+								},
+							},
+							"status": map[string]interface{}{"type": "object", "x-kubernetes-preserve-unknown-fields": true},
+						},
+					},
+				}},
+			},
+		},
+	}}
+}
+
+func newCRDClient(objects ...runtime.Object) *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{CRDResource: "CustomResourceDefinitionList"}, objects...)
+}
+
+func TestListCRDs(t *testing.T) {
+	client := newCRDClient(
+		newTestCRD("widgets.example.com", "example.com", "Widget"),
+		newTestCRD("gadgets.example.com", "example.com", "Gadget"),
+	)
+
+	crds, err := ListCRDs(context.Background(), client)
+	if err != nil {
+		t.Fatalf("ListCRDs failed: %v", err)
+	}
+	if len(crds) != 2 || crds[0].Name != "gadgets.example.com" {
+		t.Fatalf("Expected two CRDs sorted by name, got %+v", crds)
+	}
+
+	crd := crds[1]
+	if crd.Group != "example.com" || crd.Kind != "Widget" || crd.Plural != "widgets" || crd.Scope != "Namespaced" {
+		t.Errorf("Expected the CRD names and scope, got %+v", crd)
+	}
+	if crd.Version != "v1" || !reflect.DeepEqual(crd.Versions, []string{"v1beta1", "v1"}) {
+		t.Errorf("Expected storage version v1 of v1beta1 and v1, got %q of %v", crd.Version, crd.Versions)
+	}
+	if crd.Schema["type"] != "object" {
+		t.Errorf("Expected the storage version's schema, got %v", crd.Schema)
+	}
+}
+
+func TestListCRDsEmpty(t *testing.T) {
+	crds, err := ListCRDs(context.Background(), newCRDClient())
+	if err != nil || len(crds) != 0 {
+		t.Errorf("Expected no CRDs, got %v, %v", crds, err)
+	}
+}
+
+func TestSchemaTree(t *testing.T) {
+	crd := crdFromUnstructured(newTestCRD("widgets.example.com", "example.com", "Widget"))
+	want := []string{
+		"spec: object",
+		"├─ labels: map[string]string",
+		"├─ port: int-or-string",
+		"├─ ports: []object",
+		"│  └─ name: string",
+		"└─ size: integer (required)",
+		"status: object (any fields)",
+	}
+	if got := SchemaTree(crd.Schema); !reflect.DeepEqual(got, want) {
+		t.Errorf("SchemaTree() =\n%v\nwant\n%v", got, want)
+	}
+	if got := SchemaTree(nil); len(got) != 0 {
+		t.Errorf("Expected no lines without a schema, got %v", got)
+	}
+}
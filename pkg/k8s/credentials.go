@@ -0,0 +1,263 @@
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// CredentialExpiryWarning is how long before its credentials expire a client
+// warns about them
+const CredentialExpiryWarning = 24 * time.Hour
+
+// CredentialReloader keeps the clients of a long-running server working when
+// their credentials change on disk. client-go already rereads token files,
+// the in-cluster service account token included, and client certificates
+// given as file paths. Credentials inline in a kubeconfig are read once,
+// though, so the reloader checks the kubeconfig periodically and, when they
+// changed, swaps the transport of every client it created for one with the
+// new credentials. The clients themselves are kept.
+type CredentialReloader struct {
+	kubeconfig string
+	transport  *swappableTransport
+	// config is what the clients are created from: the server and rate
+	// limits of the loaded config, its credentials left to the transport
+	config *rest.Config
+
+	mu          sync.Mutex
+	fingerprint [sha256.Size]byte
+	info        *ClientInfo
+	reloads     int64
+	lastReload  time.Time
+}
+
+// NewCredentialReloader loads the config of kubeconfig, or the in-cluster
+// config when kubeconfig is empty, as NewClient does
+func NewCredentialReloader(kubeconfig string) (*CredentialReloader, error) {
+	config, info, err := buildConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		klog.Errorf("Failed to create transport: %v", err)
+		return nil, err
+	}
+
+	r := &CredentialReloader{
+		kubeconfig:  info.Kubeconfig,
+		transport:   &swappableTransport{},
+		fingerprint: credentialFingerprint(config),
+		info:        info,
+	}
+	r.transport.current.Store(&transport)
+	r.config = &rest.Config{
+		Host:      config.Host,
+		APIPath:   config.APIPath,
+		UserAgent: config.UserAgent,
+		QPS:       config.QPS,
+		Burst:     config.Burst,
+		Timeout:   config.Timeout,
+		Transport: r.transport,
+	}
+	return r, nil
+}
+
+// Clientset creates a clientset whose credentials follow the reloads
+func (r *CredentialReloader) Clientset() (kubernetes.Interface, error) {
+	clientset, err := kubernetes.NewForConfig(rest.CopyConfig(r.config))
+	if err != nil {
+		klog.Errorf("Failed to create clientset: %v", err)
+		return nil, err
+	}
+	return clientset, nil
+}
+
+// DynamicClient creates a dynamic client whose credentials follow the reloads
+func (r *CredentialReloader) DynamicClient() (dynamic.Interface, error) {
+	dynamicClient, err := dynamic.NewForConfig(rest.CopyConfig(r.config))
+	if err != nil {
+		klog.Errorf("Failed to create dynamic client: %v", err)
+		return nil, err
+	}
+	return dynamicClient, nil
+}
+
+// MetricsClient creates a metrics.k8s.io client whose credentials follow the
+// reloads
+func (r *CredentialReloader) MetricsClient() (metricsclientset.Interface, error) {
+	metricsClient, err := metricsclientset.NewForConfig(rest.CopyConfig(r.config))
+	if err != nil {
+		klog.Errorf("Failed to create metrics client: %v", err)
+		return nil, err
+	}
+	return metricsClient, nil
+}
+
+// Info describes the cluster the current credentials were loaded for
+func (r *CredentialReloader) Info() *ClientInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info := *r.info
+	return &info
+}
+
+// Reloads returns how many times the credentials were reloaded, and when
+// last
+func (r *CredentialReloader) Reloads() (int64, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reloads, r.lastReload
+}
+
+// Check loads the config again and swaps the transport when its credentials
+// changed, reporting whether they did. A config that fails to load keeps the
+// current transport. Requests in flight, watches included, finish on the old
+// transport, whose idle connections are closed.
+func (r *CredentialReloader) Check() (bool, error) {
+	config, info, err := buildConfig(r.kubeconfig)
+	if err != nil {
+		return false, err
+	}
+	fingerprint := credentialFingerprint(config)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fingerprint == r.fingerprint {
+		// Certificate and token files are reread by client-go, so only
+		// their expiry may have moved
+		r.info = info
+		return false, nil
+	}
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		klog.Errorf("Failed to create transport for the changed credentials: %v", err)
+		return false, err
+	}
+	old := r.transport.current.Swap(&transport)
+	utilnet.CloseIdleConnectionsFor(*old)
+	r.fingerprint, r.info = fingerprint, info
+	r.reloads++
+	r.lastReload = time.Now()
+	klog.Infof("Reloaded the credentials of %s", info.Server)
+	return true, nil
+}
+
+// Run checks the credentials every interval until ctx is done, warning while
+// they expire within CredentialExpiryWarning
+func (r *CredentialReloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := r.Check(); err != nil {
+			klog.Errorf("Failed to check the credentials for changes: %v", err)
+		}
+		if expiry := r.Info().CredentialExpiry; expiry != nil && time.Until(*expiry) < CredentialExpiryWarning {
+			klog.Warningf("The credentials of the Kubernetes client expire at %s", expiry.Format(time.RFC3339))
+		}
+	}
+}
+
+// swappableTransport sends requests through the transport last stored
+type swappableTransport struct {
+	current atomic.Pointer[http.RoundTripper]
+}
+
+func (t *swappableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return (*t.current.Load()).RoundTrip(req)
+}
+
+// credentialFingerprint hashes the server and the credentials of a config
+// that client-go does not reload by itself: data inline in the kubeconfig,
+// and the paths of files, whose contents client-go rereads
+func credentialFingerprint(config *rest.Config) [sha256.Size]byte {
+	token := config.BearerToken
+	if config.BearerTokenFile != "" {
+		// The token read from the file when the config was loaded
+		token = ""
+	}
+	parts := []string{
+		config.Host, config.BearerTokenFile, token, config.Username, config.Password,
+		config.CAFile, string(config.CAData),
+		config.CertFile, string(config.CertData), config.KeyFile, string(config.KeyData),
+		config.Impersonate.UserName,
+	}
+	if config.ExecProvider != nil {
+		parts = append(parts, config.ExecProvider.Command)
+		parts = append(parts, config.ExecProvider.Args...)
+	}
+	if config.AuthProvider != nil {
+		parts = append(parts, config.AuthProvider.Name)
+	}
+	return sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+}
+
+// CredentialExpiry returns when the credentials of a config expire: the
+// earliest of the expiry of its client certificate and of a bearer token
+// inline in the config, or nil when neither has one. Token files are
+// rotated, e.g. by the kubelet for service account tokens, and reread, so
+// their expiry is not reported.
+func CredentialExpiry(config *rest.Config) *time.Time {
+	var expiry *time.Time
+	earlier := func(t time.Time) {
+		if expiry == nil || t.Before(*expiry) {
+			expiry = &t
+		}
+	}
+
+	certData := config.CertData
+	if len(certData) == 0 && config.CertFile != "" {
+		certData, _ = os.ReadFile(config.CertFile)
+	}
+	if block, _ := pem.Decode(certData); block != nil {
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			earlier(cert.NotAfter)
+		}
+	}
+	if config.BearerTokenFile == "" {
+		if exp, ok := tokenExpiry(config.BearerToken); ok {
+			earlier(exp)
+		}
+	}
+	return expiry
+}
+
+// tokenExpiry returns the exp claim of a JWT bearer token
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,217 @@
+package k8s
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// testCA issues short-lived certificates
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a client certificate for
+// user, valid for validity
+func (ca *testCA) issue(t *testing.T, user string, validity time.Duration) ([]byte, []byte, time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := time.Now().Add(validity).Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: user},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), notAfter
+}
+
+// writeKubeconfig writes a kubeconfig for server with the client
+// certificate and key inline
+func writeKubeconfig(t *testing.T, path string, server *httptest.Server, cert, key []byte) {
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: test
+  user:
+    client-certificate-data: %s
+    client-key-data: %s
+contexts:
+- name: test
+  context: {cluster: test, user: test}
+current-context: test
+`, server.URL, base64.StdEncoding.EncodeToString(serverCA), base64.StdEncoding.EncodeToString(cert), base64.StdEncoding.EncodeToString(key))
+	if err := os.WriteFile(path, []byte(kubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCredentialReloaderRebuildsOnRenewedCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	// The API server accepts only the certificate of the current user, as
+	// if the previous one had been revoked or had expired
+	var accepted atomic.Value
+	accepted.Store("old")
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != accepted.Load() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Unauthorized","code":401}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"default"}}]}`)
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	oldCert, oldKey, oldExpiry := ca.issue(t, "old", 2*time.Hour)
+	writeKubeconfig(t, path, server, oldCert, oldKey)
+
+	reloader, err := NewCredentialReloader(path)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+	clientset, err := reloader.Clientset()
+	if err != nil {
+		t.Fatalf("Failed to create clientset: %v", err)
+	}
+	list := func() error {
+		_, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+		return err
+	}
+	if err := list(); err != nil {
+		t.Fatalf("Expected the first certificate to work, got %v", err)
+	}
+	if expiry := reloader.Info().CredentialExpiry; expiry == nil || !expiry.Equal(oldExpiry) {
+		t.Errorf("Expected the expiry of the first certificate, got %v", expiry)
+	}
+
+	// Nothing changed on disk
+	if reloaded, err := reloader.Check(); reloaded || err != nil {
+		t.Errorf("Expected no reload, got %t, %v", reloaded, err)
+	}
+
+	// The certificate is renewed and the old one rejected
+	newCert, newKey, newExpiry := ca.issue(t, "new", 30*time.Minute)
+	writeKubeconfig(t, path, server, newCert, newKey)
+	accepted.Store("new")
+	if err := list(); err == nil {
+		t.Fatal("Expected the old certificate to be rejected")
+	}
+	if reloaded, err := reloader.Check(); !reloaded || err != nil {
+		t.Fatalf("Expected the renewed certificate to be loaded, got %t, %v", reloaded, err)
+	}
+	if err := list(); err != nil {
+		t.Errorf("Expected the same clientset to work with the renewed certificate, got %v", err)
+	}
+	if expiry := reloader.Info().CredentialExpiry; expiry == nil || !expiry.Equal(newExpiry) {
+		t.Errorf("Expected the expiry of the renewed certificate, got %v", expiry)
+	}
+	if reloads, last := reloader.Reloads(); reloads != 1 || last.IsZero() {
+		t.Errorf("Expected one reload, got %d at %v", reloads, last)
+	}
+
+	// A kubeconfig caught mid-write keeps the working credentials
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nclusters: ["), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := reloader.Check(); reloaded || err == nil {
+		t.Errorf("Expected an invalid kubeconfig to fail the check, got %t, %v", reloaded, err)
+	}
+	if err := list(); err != nil {
+		t.Errorf("Expected the renewed certificate to keep working, got %v", err)
+	}
+}
+
+func TestCredentialExpiry(t *testing.T) {
+	ca := newTestCA(t)
+	cert, _, certExpiry := ca.issue(t, "user", 48*time.Hour)
+	payload := func(exp int64) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"user","exp":%d}`, exp)))
+	}
+	tokenExpiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	token := "eyJhbGciOiJSUzI1NiJ9." + payload(tokenExpiry.Unix()) + ".signature"
+
+	tests := []struct {
+		name   string
+		config *rest.Config
+		want   *time.Time
+	}{
+		{"none", &rest.Config{BearerToken: "opaque"}, nil},
+		{"certificate", &rest.Config{TLSClientConfig: rest.TLSClientConfig{CertData: cert}}, &certExpiry},
+		{"token sooner", &rest.Config{BearerToken: token, TLSClientConfig: rest.TLSClientConfig{CertData: cert}}, &tokenExpiry},
+		{"token file", &rest.Config{BearerToken: token, BearerTokenFile: "/var/run/secrets/token"}, nil},
+	}
+	for _, tt := range tests {
+		got := CredentialExpiry(tt.config)
+		if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
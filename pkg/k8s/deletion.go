@@ -0,0 +1,129 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// DeletionSafety is what deleting an object will do besides removing it
+type DeletionSafety struct {
+	// Finalizers must be removed by their controllers before the object is
+	// gone
+	Finalizers []string `json:"finalizers,omitempty"`
+	// WillBeRecreated is set when a controller owns the object and will
+	// replace it, e.g. the ReplicaSet of a deployment's pod
+	WillBeRecreated bool `json:"willBeRecreated"`
+	// RecreatedBy names the controller, e.g. "Deployment/web via
+	// ReplicaSet/web-5d8f"
+	RecreatedBy string `json:"recreatedBy,omitempty"`
+	// BlockedBy says why a delete will not complete at once
+	BlockedBy string `json:"blockedBy,omitempty"`
+}
+
+// Warnings returns the safety concerns as sentences to show before deleting
+func (s *DeletionSafety) Warnings() []string {
+	if s == nil {
+		return nil
+	}
+	var warnings []string
+	if s.WillBeRecreated {
+		warnings = append(warnings, fmt.Sprintf("It will be recreated by %s.", s.RecreatedBy))
+	}
+	if s.BlockedBy != "" {
+		warnings = append(warnings, fmt.Sprintf("Deletion is blocked by %s.", s.BlockedBy))
+	}
+	return warnings
+}
+
+// objectGetter gets one object of a kind
+type objectGetter func(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (metav1.Object, error)
+
+// objectGetters maps the plural kinds GetDeletionSafety supports to their
+// getters
+var objectGetters = map[string]objectGetter{
+	"pods": func(ctx context.Context, cs kubernetes.Interface, ns, name string) (metav1.Object, error) {
+		return cs.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+	},
+	"deployments": func(ctx context.Context, cs kubernetes.Interface, ns, name string) (metav1.Object, error) {
+		return cs.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+	},
+	"services": func(ctx context.Context, cs kubernetes.Interface, ns, name string) (metav1.Object, error) {
+		return cs.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{})
+	},
+	"configmaps": func(ctx context.Context, cs kubernetes.Interface, ns, name string) (metav1.Object, error) {
+		return cs.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{})
+	},
+}
+
+// GetDeletionSafety returns what deleting an object of a plural kind, e.g.
+// "pods", will do: whether finalizers hold it, and whether its controller
+// will recreate it
+func GetDeletionSafety(ctx context.Context, clientset kubernetes.Interface, namespace, kind, name string) (*DeletionSafety, error) {
+	get, ok := objectGetters[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedKind, kind)
+	}
+	obj, err := get(ctx, clientset, namespace, name)
+	if err != nil {
+		klog.Errorf("Failed to get %s %s in namespace %s: %v", kind, name, namespace, err)
+		return nil, err
+	}
+
+	safety := &DeletionSafety{Finalizers: obj.GetFinalizers()}
+	if len(safety.Finalizers) > 0 {
+		safety.BlockedBy = "finalizers " + strings.Join(safety.Finalizers, ", ")
+		if deleted := obj.GetDeletionTimestamp(); deleted != nil {
+			safety.BlockedBy = fmt.Sprintf("finalizers %s, which have held it since %s", strings.Join(safety.Finalizers, ", "), deleted.UTC().Format("2006-01-02 15:04:05 UTC"))
+		}
+	}
+
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || !recreatesOwned(owner, obj) {
+		return safety, nil
+	}
+	safety.WillBeRecreated = true
+	safety.RecreatedBy = owner.Kind + "/" + owner.Name
+	// Name the deployment behind a ReplicaSet, which is what users scale
+	if owner.Kind == "ReplicaSet" {
+		rs, err := clientset.AppsV1().ReplicaSets(namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err == nil {
+			if deployment := metav1.GetControllerOf(rs); deployment != nil && deployment.Kind == "Deployment" {
+				safety.RecreatedBy = fmt.Sprintf("Deployment/%s via ReplicaSet/%s", deployment.Name, owner.Name)
+			}
+		}
+	}
+	return safety, nil
+}
+
+// recreatesOwned reports whether a controller replaces an object it owns
+// once deleted. Jobs leave finished pods be.
+func recreatesOwned(owner *metav1.OwnerReference, obj metav1.Object) bool {
+	if pod, ok := obj.(*v1.Pod); ok && owner.Kind == "Job" {
+		return pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+	}
+	return true
+}
+
+// finalizersPatch is the merge patch emptying an object's finalizers
+var finalizersPatch = []byte(`{"metadata":{"finalizers":[]}}`)
+
+// RemoveFinalizers empties the finalizers of an object of a plural kind, so
+// that deleting it does not wait for their controllers. Whatever those
+// controllers would have cleaned up is left behind.
+func RemoveFinalizers(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) error {
+	patcher, ok := metadataPatchers[kind]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnsupportedKind, kind)
+	}
+	if _, err := patcher(ctx, clientset, namespace, name, finalizersPatch); err != nil {
+		klog.Errorf("Failed to remove the finalizers of %s %s in namespace %s: %v", kind, name, namespace, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetDeletionSafety(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"}}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d8f", Namespace: "default", UID: "rs-uid",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+	}}
+	bareRS := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "bare", Namespace: "default", UID: "bare-uid"}}
+	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default", UID: "ds-uid"}}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default", UID: "job-uid"}}
+
+	pod := func(name string, phase v1.PodPhase, owner metav1.Object, kind string, finalizers ...string) *v1.Pod {
+		p := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Finalizers: finalizers},
+			Status:     v1.PodStatus{Phase: phase},
+		}
+		if owner != nil {
+			p.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind(kind))}
+		}
+		return p
+	}
+	finalized := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "api", Namespace: "default", Finalizers: []string{"example.com/cleanup", "foregroundDeletion"},
+	}}
+	deleted := metav1.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	terminating := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "stuck", Namespace: "default", Finalizers: []string{"example.com/hold"}, DeletionTimestamp: &deleted,
+	}}
+
+	clientset := fake.NewSimpleClientset(deployment, rs, bareRS, ds, job, finalized, terminating,
+		pod("web-5d8f-x", v1.PodRunning, rs, "ReplicaSet"),
+		pod("bare-x", v1.PodRunning, bareRS, "ReplicaSet"),
+		pod("agent-x", v1.PodRunning, ds, "DaemonSet", "example.com/drain"),
+		pod("migrate-running", v1.PodRunning, job, "Job"),
+		pod("migrate-done", v1.PodSucceeded, job, "Job"),
+		pod("standalone", v1.PodRunning, nil, ""),
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+
+	tests := []struct {
+		kind, name  string
+		recreatedBy string
+		finalizers  []string
+		blockedBy   string
+	}{
+		{"pods", "web-5d8f-x", "Deployment/web via ReplicaSet/web-5d8f", nil, ""},
+		{"pods", "bare-x", "ReplicaSet/bare", nil, ""},
+		{"pods", "agent-x", "DaemonSet/agent", []string{"example.com/drain"}, "finalizers example.com/drain"},
+		{"pods", "migrate-running", "Job/migrate", nil, ""},
+		{"pods", "migrate-done", "", nil, ""},
+		{"pods", "standalone", "", nil, ""},
+		{"deployments", "web", "", nil, ""},
+		{"deployments", "api", "", []string{"example.com/cleanup", "foregroundDeletion"}, "finalizers example.com/cleanup, foregroundDeletion"},
+		{"services", "web", "", nil, ""},
+		{"configmaps", "stuck", "", []string{"example.com/hold"}, "finalizers example.com/hold, which have held it since 2024-01-01"},
+	}
+	for _, tt := range tests {
+		safety, err := GetDeletionSafety(context.Background(), clientset, "default", tt.kind, tt.name)
+		if err != nil {
+			t.Errorf("%s/%s: GetDeletionSafety failed: %v", tt.kind, tt.name, err)
+			continue
+		}
+		if safety.WillBeRecreated != (tt.recreatedBy != "") || safety.RecreatedBy != tt.recreatedBy {
+			t.Errorf("%s/%s: expected recreated by %q, got %+v", tt.kind, tt.name, tt.recreatedBy, safety)
+		}
+		if strings.Join(safety.Finalizers, ",") != strings.Join(tt.finalizers, ",") || !strings.HasPrefix(safety.BlockedBy, tt.blockedBy) || (tt.blockedBy == "") != (safety.BlockedBy == "") {
+			t.Errorf("%s/%s: expected finalizers %v blocking with %q, got %+v", tt.kind, tt.name, tt.finalizers, tt.blockedBy, safety)
+		}
+		if got := len(safety.Warnings()); got != boolCount(safety.WillBeRecreated, safety.BlockedBy != "") {
+			t.Errorf("%s/%s: expected a warning per concern, got %v", tt.kind, tt.name, safety.Warnings())
+		}
+	}
+
+	if _, err := GetDeletionSafety(context.Background(), clientset, "default", "pods", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected NotFound for a missing pod, got %v", err)
+	}
+	if _, err := GetDeletionSafety(context.Background(), clientset, "default", "widgets", "web"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("Expected ErrUnsupportedKind, got %v", err)
+	}
+}
+
+// boolCount counts the true values
+func boolCount(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDeletionSafetyWarnings(t *testing.T) {
+	safety := &DeletionSafety{WillBeRecreated: true, RecreatedBy: "DaemonSet/agent", BlockedBy: "finalizers example.com/drain"}
+	want := "It will be recreated by DaemonSet/agent.|Deletion is blocked by finalizers example.com/drain."
+	if got := strings.Join(safety.Warnings(), "|"); got != want {
+		t.Errorf("Warnings() = %q, want %q", got, want)
+	}
+	if (*DeletionSafety)(nil).Warnings() != nil || (&DeletionSafety{}).Warnings() != nil {
+		t.Error("Expected no warnings without concerns")
+	}
+}
+
+func TestRemoveFinalizers(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: "api", Namespace: "default", Finalizers: []string{"example.com/cleanup"},
+	}})
+	if err := RemoveFinalizers(context.Background(), clientset, "deployments", "default", "api"); err != nil {
+		t.Fatalf("RemoveFinalizers failed: %v", err)
+	}
+	deployment, _ := clientset.AppsV1().Deployments("default").Get(context.Background(), "api", metav1.GetOptions{})
+	if len(deployment.Finalizers) != 0 {
+		t.Errorf("Expected no finalizers, got %v", deployment.Finalizers)
+	}
+	if err := RemoveFinalizers(context.Background(), clientset, "deployments", "default", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected NotFound for a missing deployment, got %v", err)
+	}
+	if got, want := KubectlRemoveFinalizers("default", "deployment", "api"), `kubectl -n default patch deployment api --type=merge -p '{"metadata":{"finalizers":[]}}'`; got != want {
+		t.Errorf("KubectlRemoveFinalizers() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// DeletionProtectedAnnotation set to "true" on a namespace makes deleting it
+// require typing its name in the TUI, and the X-Confirm-Delete header naming
+// it over REST
+const DeletionProtectedAnnotation = "kgo.io/deletion-protected"
+
+// IsDeletionProtected reports whether a namespace has DeletionProtectedAnnotation
+// set to "true"
+func IsDeletionProtected(namespace *v1.Namespace) bool {
+	return namespace.Annotations[DeletionProtectedAnnotation] == "true"
+}
+
+// SetDeletionProtection sets DeletionProtectedAnnotation on a namespace, or
+// removes it, with a merge patch leaving its other annotations in place
+func SetDeletionProtection(ctx context.Context, clientset kubernetes.Interface, namespace string, protected bool) error {
+	change := MetadataChange{Remove: []string{DeletionProtectedAnnotation}}
+	if protected {
+		change = MetadataChange{Set: map[string]string{DeletionProtectedAnnotation: "true"}}
+	}
+	patch, err := MetadataMergePatch(AnnotationsField, change)
+	if err != nil {
+		return err
+	}
+	if _, err := clientset.CoreV1().Namespaces().Patch(ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager}); err != nil {
+		klog.Errorf("Failed to set the deletion protection of namespace %s: %v", namespace, err)
+		return err
+	}
+	klog.Infof("Set the deletion protection of namespace %s to %v", namespace, protected)
+	return nil
+}
+
+// DeleteNamespace deletes a namespace and everything in it. It does not
+// check DeletionProtectedAnnotation, which is up to the caller.
+func DeleteNamespace(clientset kubernetes.Interface, name string) error {
+	err := clientset.CoreV1().Namespaces().Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil {
+		klog.Errorf("Failed to delete namespace %s: %v", name, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package k8s
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSetDeletionProtection(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "payments",
+		Annotations: map[string]string{"team": "billing"},
+	}})
+	get := func() *v1.Namespace {
+		ns, err := clientset.CoreV1().Namespaces().Get(t.Context(), "payments", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get namespace: %v", err)
+		}
+		return ns
+	}
+
+	if err := SetDeletionProtection(t.Context(), clientset, "payments", true); err != nil {
+		t.Fatalf("Failed to protect namespace: %v", err)
+	}
+	if ns := get(); !IsDeletionProtected(ns) || ns.Annotations["team"] != "billing" {
+		t.Errorf("Expected the namespace protected with its annotations kept, got %v", ns.Annotations)
+	}
+	if err := SetDeletionProtection(t.Context(), clientset, "payments", false); err != nil {
+		t.Fatalf("Failed to unprotect namespace: %v", err)
+	}
+	if ns := get(); IsDeletionProtected(ns) || len(ns.Annotations) != 1 {
+		t.Errorf("Expected the annotation removed, got %v", ns.Annotations)
+	}
+}
+
+func TestIsDeletionProtected(t *testing.T) {
+	for value, want := range map[string]bool{"true": true, "false": false, "yes": false, "": false} {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{DeletionProtectedAnnotation: value}}}
+		if got := IsDeletionProtected(ns); got != want {
+			t.Errorf("Expected %q to be protected %v, got %v", value, want, got)
+		}
+	}
+	if IsDeletionProtected(&v1.Namespace{}) {
+		t.Error("Expected a namespace without annotations to be unprotected")
+	}
+}
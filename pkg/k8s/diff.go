@@ -0,0 +1,611 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Change types reported in a structured diff
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Statuses reported when diffing every object of a kind across two namespaces
+const (
+	DiffIdentical = "identical"
+	DiffDifferent = "different"
+	DiffOnlyInA   = "only-in-a"
+	DiffOnlyInB   = "only-in-b"
+)
+
+// diffContextLines is the number of unchanged lines shown around each hunk
+const diffContextLines = 3
+
+// serverManagedMetadata lists metadata fields set by the API server
+var serverManagedMetadata = []string{
+	"resourceVersion", "uid", "creationTimestamp", "generation",
+	"managedFields", "selfLink", "namespace", "ownerReferences",
+}
+
+// generatedLabels lists labels added by controllers rather than users
+var generatedLabels = []string{
+	"pod-template-hash", "controller-revision-hash", "controller-uid",
+}
+
+// generatedAnnotations lists annotations added by controllers or kubectl
+var generatedAnnotations = []string{
+	"deployment.kubernetes.io/revision",
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// DiffChange is a single difference between two normalized objects
+type DiffChange struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"`
+	A    interface{} `json:"a,omitempty"`
+	B    interface{} `json:"b,omitempty"`
+}
+
+// ResourceDiff is the result of comparing two objects
+type ResourceDiff struct {
+	Identical bool         `json:"identical"`
+	Changes   []DiffChange `json:"changes"`
+	Unified   string       `json:"unified"`
+}
+
+// DiffSummary is the comparison status of one object name across two namespaces
+type DiffSummary struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// NormalizeObject converts an object to a generic map and strips fields that
+// are managed by the server, so that two objects can be compared by intent
+func NormalizeObject(obj runtime.Object) (map[string]interface{}, error) {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(m, "status")
+	normalizeMetadata(m)
+
+	if _, ok := obj.(*v1.Service); ok {
+		// Cluster IPs are allocated by the server
+		if spec, ok := m["spec"].(map[string]interface{}); ok {
+			delete(spec, "clusterIP")
+			delete(spec, "clusterIPs")
+		}
+	}
+
+	pruneEmpty(m)
+	return m, nil
+}
+
+// normalizeMetadata strips server-managed fields from every metadata block in
+// the tree, including pod templates
+func normalizeMetadata(m map[string]interface{}) {
+	for key, value := range m {
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			if list, ok := value.([]interface{}); ok {
+				for _, item := range list {
+					if itemMap, ok := item.(map[string]interface{}); ok {
+						normalizeMetadata(itemMap)
+					}
+				}
+			}
+			continue
+		}
+		if key == "metadata" {
+			for _, field := range serverManagedMetadata {
+				delete(child, field)
+			}
+			if labels, ok := child["labels"].(map[string]interface{}); ok {
+				for _, label := range generatedLabels {
+					delete(labels, label)
+				}
+			}
+			if annotations, ok := child["annotations"].(map[string]interface{}); ok {
+				for _, annotation := range generatedAnnotations {
+					delete(annotations, annotation)
+				}
+			}
+		}
+		normalizeMetadata(child)
+	}
+}
+
+// pruneEmpty removes nil values and maps left empty by normalization
+func pruneEmpty(m map[string]interface{}) {
+	for key, value := range m {
+		switch v := value.(type) {
+		case nil:
+			delete(m, key)
+		case map[string]interface{}:
+			pruneEmpty(v)
+			if len(v) == 0 {
+				delete(m, key)
+			}
+		case []interface{}:
+			for _, item := range v {
+				if itemMap, ok := item.(map[string]interface{}); ok {
+					pruneEmpty(itemMap)
+				}
+			}
+		}
+	}
+}
+
+// DiffObjects normalizes and compares two objects. The labels name each side
+// in the unified diff headers.
+func DiffObjects(aLabel, bLabel string, a, b runtime.Object) (*ResourceDiff, error) {
+	aMap, err := NormalizeObject(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize %s: %v", aLabel, err)
+	}
+	bMap, err := NormalizeObject(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize %s: %v", bLabel, err)
+	}
+
+	changes := []DiffChange{}
+	diffValues("", aMap, bMap, &changes)
+
+	result := &ResourceDiff{Identical: len(changes) == 0, Changes: changes}
+	if !result.Identical {
+		aText, err := toYAML(aMap)
+		if err != nil {
+			return nil, err
+		}
+		bText, err := toYAML(bMap)
+		if err != nil {
+			return nil, err
+		}
+		result.Unified = UnifiedDiff(aLabel, bLabel, aText, bText)
+	}
+	return result, nil
+}
+
+// DiffObjectSets compares objects by name across two namespaces and returns
+// a per-name summary sorted by name
+func DiffObjectSets(a, b map[string]runtime.Object) ([]DiffSummary, error) {
+	names := make(map[string]bool)
+	for name := range a {
+		names[name] = true
+	}
+	for name := range b {
+		names[name] = true
+	}
+
+	summaries := make([]DiffSummary, 0, len(names))
+	for name := range names {
+		aObj, inA := a[name]
+		bObj, inB := b[name]
+
+		status := DiffIdentical
+		switch {
+		case !inB:
+			status = DiffOnlyInA
+		case !inA:
+			status = DiffOnlyInB
+		default:
+			diff, err := DiffObjects(name, name, aObj, bObj)
+			if err != nil {
+				return nil, err
+			}
+			if !diff.Identical {
+				status = DiffDifferent
+			}
+		}
+		summaries = append(summaries, DiffSummary{Name: name, Status: status})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries, nil
+}
+
+// revisionAnnotation records the rollout revision of a deployment's ReplicaSets
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// ErrNoPreviousReplicaSet is returned when a deployment has no earlier rollout
+// to compare its pod template against
+var ErrNoPreviousReplicaSet = errors.New("no previous ReplicaSet")
+
+// GetDeploymentTemplateDiff returns a unified diff from the pod template of the
+// most recent previous ReplicaSet to the deployment's current pod template.
+// The diff is empty when the templates are equal, e.g. after a rollback.
+func GetDeploymentTemplateDiff(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (string, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get deployment %s/%s: %v", namespace, name, err)
+		return "", err
+	}
+
+	current, err := templateYAML(deployment.Spec.Template)
+	if err != nil {
+		return "", err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector on deployment %s: %v", name, err)
+	}
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		klog.Errorf("Failed to list replicasets for deployment %s/%s: %v", namespace, name, err)
+		return "", err
+	}
+
+	// The previous ReplicaSet is the newest one whose template differs from
+	// the deployment's; the ReplicaSet running the current template is skipped
+	var previous *appsv1.ReplicaSet
+	var previousYAML string
+	previousRevision := int64(-1)
+	for i := range replicaSets.Items {
+		rs := &replicaSets.Items[i]
+		if !metav1.IsControlledBy(rs, deployment) {
+			continue
+		}
+		text, err := templateYAML(rs.Spec.Template)
+		if err != nil {
+			return "", err
+		}
+		if text == current {
+			continue
+		}
+		revision, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		if revision > previousRevision {
+			previous, previousYAML, previousRevision = rs, text, revision
+		}
+	}
+	if previous == nil {
+		return "", fmt.Errorf("deployment %s/%s: %w", namespace, name, ErrNoPreviousReplicaSet)
+	}
+
+	return UnifiedDiff(
+		fmt.Sprintf("replicaset/%s (revision %d)", previous.Name, previousRevision),
+		"deployment/"+name,
+		previousYAML, current,
+	), nil
+}
+
+// templateYAML renders a pod template as YAML without controller-generated
+// labels such as pod-template-hash
+func templateYAML(template v1.PodTemplateSpec) (string, error) {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&template)
+	if err != nil {
+		return "", err
+	}
+	normalizeMetadata(m)
+	pruneEmpty(m)
+	return toYAML(m)
+}
+
+// diffValues recursively records the differences between two generic values
+func diffValues(path string, a, b interface{}, changes *[]DiffChange) {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		keys := make(map[string]bool)
+		for k := range aMap {
+			keys[k] = true
+		}
+		for k := range bMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			aVal, inA := aMap[k]
+			bVal, inB := bMap[k]
+			switch {
+			case !inB:
+				*changes = append(*changes, DiffChange{Path: childPath, Type: ChangeRemoved, A: aVal})
+			case !inA:
+				*changes = append(*changes, DiffChange{Path: childPath, Type: ChangeAdded, B: bVal})
+			default:
+				diffValues(childPath, aVal, bVal, changes)
+			}
+		}
+		return
+	}
+
+	aList, aIsList := a.([]interface{})
+	bList, bIsList := b.([]interface{})
+	if aIsList && bIsList {
+		for i := 0; i < len(aList) || i < len(bList); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(bList):
+				*changes = append(*changes, DiffChange{Path: childPath, Type: ChangeRemoved, A: aList[i]})
+			case i >= len(aList):
+				*changes = append(*changes, DiffChange{Path: childPath, Type: ChangeAdded, B: bList[i]})
+			default:
+				diffValues(childPath, aList[i], bList[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, DiffChange{Path: path, Type: ChangeChanged, A: a, B: b})
+	}
+}
+
+// toYAML renders a normalized object with sorted keys
+func toYAML(m map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// diffOp is one line of an edit script
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// UnifiedDiff returns a unified diff between two texts, or an empty string
+// when they are equal
+func UnifiedDiff(aLabel, bLabel, aText, bText string) string {
+	if aText == bText {
+		return ""
+	}
+
+	ops := lineDiff(splitLines(aText), splitLines(bText))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aLabel, bLabel)
+
+	// Group changes into hunks that keep diffContextLines of context
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		hunkEnd := start
+		for unchanged := 0; hunkEnd < len(ops); hunkEnd++ {
+			if ops[hunkEnd].kind == ' ' {
+				unchanged++
+				if unchanged > 2*diffContextLines {
+					break
+				}
+			} else {
+				unchanged = 0
+			}
+		}
+		// Trim trailing context down to diffContextLines
+		trailing := 0
+		for i := hunkEnd - 1; i >= 0 && ops[i].kind == ' '; i-- {
+			trailing++
+		}
+		if trailing > diffContextLines {
+			hunkEnd -= trailing - diffContextLines
+		}
+
+		aStart, bStart := 1, 1
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				aStart++
+			}
+			if op.kind != '-' {
+				bStart++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[hunkStart:hunkEnd] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+		start = hunkEnd
+	}
+
+	return sb.String()
+}
+
+// maxDiffCost bounds the edit distance lineDiff searches for in each part of
+// its inputs. A part that differs more is shown as replaced entirely, which
+// keeps diffing large unrelated values, e.g. two CA bundles, from taking time
+// quadratic in their size.
+const maxDiffCost = 1024
+
+// lineDiff computes an edit script with the linear space variant of Myers'
+// algorithm: minimal up to maxDiffCost edits per part, and in memory linear
+// in the number of lines
+func lineDiff(a, b []string) []diffOp {
+	d := &differ{a: a, b: b}
+	d.compare(0, len(a), 0, len(b))
+
+	// Show the removed lines of each change before the added ones
+	for start := 0; start < len(d.ops); {
+		end := start
+		for end < len(d.ops) && d.ops[end].kind != ' ' {
+			end++
+		}
+		sort.SliceStable(d.ops[start:end], func(i, j int) bool {
+			return d.ops[start+i].kind == '-' && d.ops[start+j].kind == '+'
+		})
+		start = end + 1
+	}
+	return d.ops
+}
+
+// differ accumulates the edit script of a and b
+type differ struct {
+	a, b []string
+	ops  []diffOp
+}
+
+// compare appends the edit script of a[aLo:aHi] and b[bLo:bHi]
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	prefix := 0
+	for aLo+prefix < aHi && bLo+prefix < bHi && d.a[aLo+prefix] == d.b[bLo+prefix] {
+		prefix++
+	}
+	suffix := 0
+	for aHi-suffix > aLo+prefix && bHi-suffix > bLo+prefix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	for _, line := range d.a[aLo : aLo+prefix] {
+		d.ops = append(d.ops, diffOp{' ', line})
+	}
+	aLo, bLo = aLo+prefix, bLo+prefix
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch x, y, ok := d.middleSnake(aLo, aHi, bLo, bHi); {
+	case aLo == aHi || bLo == bHi || !ok:
+		for _, line := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, diffOp{'-', line})
+		}
+		for _, line := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, diffOp{'+', line})
+		}
+	default:
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	}
+
+	for _, line := range d.a[aHi : aHi+suffix] {
+		d.ops = append(d.ops, diffOp{' ', line})
+	}
+}
+
+// middleSnake finds the point (x, y) where the forward and backward searches
+// for the shortest edit path of a[aLo:aHi] and b[bLo:bHi] meet, splitting it
+// into two smaller problems. It reports false when both parts are empty, or
+// when the path needs more than maxDiffCost edits.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[k] and backward[k] are the furthest x reached on diagonal k,
+	// counted from the start and from the end of both parts
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	// With an odd delta the paths meet on a forward step, otherwise on a
+	// backward one
+	odd := delta%2 != 0
+
+	// Diagonals that run off the edit graph are skipped from then on
+	var fStart, fEnd, bStart, bEnd int
+	for step := 0; step < maxD && step <= maxDiffCost; step++ {
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if i := offset + delta - k; i >= 0 && i < len(backward) && backward[i] != -1 && x >= n-backward[i] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -step + bStart; k <= step-bEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if i := offset + delta - k; i >= 0 && i < len(forward) && forward[i] != -1 {
+					fx := forward[i]
+					if fx >= n-x {
+						return aLo + fx, bLo + fx - (i - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// splitLines splits text into lines without the trailing empty line
+func splitLines(text string) []string {
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,406 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	goruntime "runtime"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+// deploymentFixture builds a deployment as the API server would return it,
+// including server-managed fields that must not show up in a diff
+func deploymentFixture(namespace, name, image string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               types.UID(namespace + "-" + name),
+			ResourceVersion:   namespace + "-rv",
+			Generation:        int64(len(namespace)),
+			CreationTimestamp: metav1.Now(),
+			Labels:            map[string]string{"app": name},
+			Annotations: map[string]string{
+				"deployment.kubernetes.io/revision": namespace,
+			},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl-" + namespace}},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": name, "pod-template-hash": namespace},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "app", Image: image}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: replicas, ObservedGeneration: int64(len(namespace))},
+	}
+}
+
+func TestNormalizeObjectStripsServerFields(t *testing.T) {
+	m, err := NormalizeObject(deploymentFixture("prod", "web", "nginx:1.25", 3))
+	if err != nil {
+		t.Fatalf("NormalizeObject failed: %v", err)
+	}
+
+	if _, ok := m["status"]; ok {
+		t.Error("Expected status to be removed")
+	}
+
+	metadata := m["metadata"].(map[string]interface{})
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "namespace", "annotations"} {
+		if _, ok := metadata[field]; ok {
+			t.Errorf("Expected metadata.%s to be removed", field)
+		}
+	}
+	if metadata["name"] != "web" {
+		t.Errorf("Expected metadata.name to be kept, got %v", metadata["name"])
+	}
+
+	template := m["spec"].(map[string]interface{})["template"].(map[string]interface{})
+	templateLabels := template["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+	if _, ok := templateLabels["pod-template-hash"]; ok {
+		t.Error("Expected generated pod-template-hash label to be removed")
+	}
+	if _, ok := template["metadata"].(map[string]interface{})["creationTimestamp"]; ok {
+		t.Error("Expected template creationTimestamp to be removed")
+	}
+}
+
+func TestDiffObjectsIgnoresServerFields(t *testing.T) {
+	a := deploymentFixture("staging", "web", "nginx:1.25", 3)
+	b := deploymentFixture("prod", "web", "nginx:1.25", 3)
+
+	diff, err := DiffObjects("a", "b", a, b)
+	if err != nil {
+		t.Fatalf("DiffObjects failed: %v", err)
+	}
+	if !diff.Identical {
+		t.Errorf("Expected identical deployments, got changes %+v", diff.Changes)
+	}
+	if diff.Unified != "" {
+		t.Errorf("Expected empty unified diff, got:\n%s", diff.Unified)
+	}
+}
+
+func TestDiffObjectsReportsChanges(t *testing.T) {
+	a := deploymentFixture("staging", "web", "nginx:1.25", 1)
+	b := deploymentFixture("prod", "web", "nginx:1.27", 3)
+	b.Spec.Template.Spec.Containers = append(b.Spec.Template.Spec.Containers, v1.Container{Name: "sidecar", Image: "envoy"})
+	b.Labels["tier"] = "frontend"
+
+	diff, err := DiffObjects("a/staging/web", "b/prod/web", a, b)
+	if err != nil {
+		t.Fatalf("DiffObjects failed: %v", err)
+	}
+	if diff.Identical {
+		t.Fatal("Expected deployments to differ")
+	}
+
+	expected := map[string]string{
+		"metadata.labels.tier":                   ChangeAdded,
+		"spec.replicas":                          ChangeChanged,
+		"spec.template.spec.containers[0].image": ChangeChanged,
+		"spec.template.spec.containers[1]":       ChangeAdded,
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %d: %+v", len(expected), len(diff.Changes), diff.Changes)
+	}
+	for _, change := range diff.Changes {
+		if expected[change.Path] != change.Type {
+			t.Errorf("Unexpected change %+v", change)
+		}
+	}
+
+	for _, want := range []string{
+		"--- a/staging/web\n+++ b/prod/web\n",
+		"-  replicas: 1\n+  replicas: 3\n",
+		"-        - image: nginx:1.25\n+        - image: nginx:1.27\n",
+		"+        - image: envoy\n+          name: sidecar\n",
+		"@@ -",
+	} {
+		if !strings.Contains(diff.Unified, want) {
+			t.Errorf("Expected unified diff to contain %q, got:\n%s", want, diff.Unified)
+		}
+	}
+}
+
+func TestDiffServiceIgnoresClusterIP(t *testing.T) {
+	service := func(namespace, ip string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				ClusterIP:  ip,
+				ClusterIPs: []string{ip},
+				Ports:      []v1.ServicePort{{Port: 80}},
+			},
+		}
+	}
+
+	diff, err := DiffObjects("a", "b", service("staging", "10.0.0.1"), service("prod", "10.0.0.2"))
+	if err != nil {
+		t.Fatalf("DiffObjects failed: %v", err)
+	}
+	if !diff.Identical {
+		t.Errorf("Expected cluster IPs to be ignored, got %+v", diff.Changes)
+	}
+}
+
+func TestDiffObjectSets(t *testing.T) {
+	a := map[string]runtime.Object{
+		"same":    deploymentFixture("staging", "same", "nginx", 1),
+		"changed": deploymentFixture("staging", "changed", "nginx:1", 1),
+		"a-only":  deploymentFixture("staging", "a-only", "nginx", 1),
+	}
+	b := map[string]runtime.Object{
+		"same":    deploymentFixture("prod", "same", "nginx", 1),
+		"changed": deploymentFixture("prod", "changed", "nginx:2", 1),
+		"b-only":  deploymentFixture("prod", "b-only", "nginx", 1),
+	}
+
+	summaries, err := DiffObjectSets(a, b)
+	if err != nil {
+		t.Fatalf("DiffObjectSets failed: %v", err)
+	}
+
+	expected := []DiffSummary{
+		{Name: "a-only", Status: DiffOnlyInA},
+		{Name: "b-only", Status: DiffOnlyInB},
+		{Name: "changed", Status: DiffDifferent},
+		{Name: "same", Status: DiffIdentical},
+	}
+	if len(summaries) != len(expected) {
+		t.Fatalf("Expected %d summaries, got %+v", len(expected), summaries)
+	}
+	for i, want := range expected {
+		if summaries[i] != want {
+			t.Errorf("Summary %d: expected %+v, got %+v", i, want, summaries[i])
+		}
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		a = append(a, line)
+		b = append(b, line)
+	}
+	b[1] = "B"
+	b[18] = "S"
+
+	got := UnifiedDiff("old", "new", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n")
+	expected := "--- old\n+++ new\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -16,5 +16,5 @@\n p\n q\n r\n-s\n+S\n t\n"
+	if got != expected {
+		t.Errorf("Unexpected unified diff:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	if UnifiedDiff("old", "new", "x\n", "x\n") != "" {
+		t.Error("Expected empty diff for equal texts")
+	}
+}
+
+// applyDiff returns the old and new lines an edit script was made from
+func applyDiff(ops []diffOp) (a, b []string) {
+	for _, op := range ops {
+		if op.kind != '+' {
+			a = append(a, op.line)
+		}
+		if op.kind != '-' {
+			b = append(b, op.line)
+		}
+	}
+	return a, b
+}
+
+// lcsEdits is the minimal number of edits between a and b
+func lcsEdits(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+func TestLineDiffMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		ops := lineDiff(a, b)
+		gotA, gotB := applyDiff(ops)
+		if strings.Join(gotA, ",") != strings.Join(a, ",") || strings.Join(gotB, ",") != strings.Join(b, ",") {
+			t.Fatalf("Edit script of %v and %v does not reproduce them: %v", a, b, ops)
+		}
+		edits := 0
+		for _, op := range ops {
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if expected := lcsEdits(a, b); edits != expected {
+			t.Errorf("Expected %d edits between %v and %v, got %d: %v", expected, a, b, edits, ops)
+		}
+	}
+}
+
+// TestLineDiffLarge diffs values of a few hundred thousand lines, e.g. CA
+// bundles in configmaps, without allocating memory quadratic in their size
+func TestLineDiffLarge(t *testing.T) {
+	const lines = 200000
+	a := make([]string, lines)
+	b := make([]string, lines)
+	for i := range a {
+		a[i] = fmt.Sprintf("MIIDdzCCAl+gAwIBAgIE%08d", i)
+		b[i] = a[i]
+	}
+	b[10], b[lines/2], b[lines-10] = "changed", "changed", "changed"
+
+	// Unrelated values are shown as replaced once they differ too much
+	unrelated := make([]string, lines)
+	for i := range unrelated {
+		unrelated[i] = fmt.Sprintf("other %d", i)
+	}
+
+	var before, after goruntime.MemStats
+	goruntime.ReadMemStats(&before)
+	ops := lineDiff(a, b)
+	replaced := lineDiff(a, unrelated)
+	goruntime.ReadMemStats(&after)
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 256<<20 {
+		t.Errorf("Expected the diffs to allocate less than 256MiB, got %dMiB", allocated>>20)
+	}
+	if gotA, gotB := applyDiff(ops); len(gotA) != lines || len(gotB) != lines || gotB[lines/2] != "changed" {
+		t.Errorf("Expected the edit script to reproduce both values")
+	}
+	if len(ops) != lines+3 {
+		t.Errorf("Expected 3 lines changed, got %d operations", len(ops))
+	}
+	if gotA, gotB := applyDiff(replaced); len(gotA) != lines || len(gotB) != lines || gotB[0] != "other 0" {
+		t.Errorf("Expected the edit script of unrelated values to reproduce both")
+	}
+}
+
+// replicaSetFixture builds a ReplicaSet owned by the deployment at a revision
+func replicaSetFixture(deployment *appsv1.Deployment, name, image, revision string) *appsv1.ReplicaSet {
+	template := *deployment.Spec.Template.DeepCopy()
+	template.Labels["pod-template-hash"] = name
+	template.Spec.Containers[0].Image = image
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            deployment.Name + "-" + name,
+			Namespace:       deployment.Namespace,
+			Labels:          template.Labels,
+			Annotations:     map[string]string{revisionAnnotation: revision},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Selector: deployment.Spec.Selector, Template: template},
+	}
+}
+
+func TestGetDeploymentTemplateDiffImageChange(t *testing.T) {
+	deployment := deploymentFixture("default", "web", "nginx:1.26", 3)
+	orphan := replicaSetFixture(deployment, "orphan", "nginx:0.1", "9")
+	orphan.OwnerReferences = nil
+	clientset := fake.NewSimpleClientset(
+		deployment,
+		replicaSetFixture(deployment, "aaa", "nginx:1.24", "1"),
+		replicaSetFixture(deployment, "bbb", "nginx:1.25", "2"),
+		replicaSetFixture(deployment, "ccc", "nginx:1.26", "3"),
+		orphan,
+	)
+
+	diff, err := GetDeploymentTemplateDiff(context.TODO(), clientset, "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeploymentTemplateDiff failed: %v", err)
+	}
+
+	if !strings.HasPrefix(diff, "--- replicaset/web-bbb (revision 2)\n+++ deployment/web\n") {
+		t.Errorf("Expected diff against the previous revision, got:\n%s", diff)
+	}
+
+	var removed, added []string
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		case strings.HasPrefix(line, "-"):
+			removed = append(removed, strings.TrimSpace(line[1:]))
+		case strings.HasPrefix(line, "+"):
+			added = append(added, strings.TrimSpace(line[1:]))
+		}
+	}
+	if len(removed) != 1 || removed[0] != "- image: nginx:1.25" {
+		t.Errorf("Expected only the old image to be removed, got %q", removed)
+	}
+	if len(added) != 1 || added[0] != "- image: nginx:1.26" {
+		t.Errorf("Expected only the new image to be added, got %q", added)
+	}
+	if strings.Contains(diff, "pod-template-hash") {
+		t.Errorf("Expected pod-template-hash to be ignored, got:\n%s", diff)
+	}
+}
+
+func TestGetDeploymentTemplateDiffNewReplicaSetPending(t *testing.T) {
+	// The template was just changed and the controller has not yet created a
+	// ReplicaSet for it, so the newest ReplicaSet is the previous one
+	deployment := deploymentFixture("default", "web", "nginx:1.26", 3)
+	clientset := fake.NewSimpleClientset(
+		deployment,
+		replicaSetFixture(deployment, "aaa", "nginx:1.24", "1"),
+		replicaSetFixture(deployment, "bbb", "nginx:1.25", "2"),
+	)
+
+	diff, err := GetDeploymentTemplateDiff(context.TODO(), clientset, "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeploymentTemplateDiff failed: %v", err)
+	}
+	if !strings.HasPrefix(diff, "--- replicaset/web-bbb (revision 2)") {
+		t.Errorf("Expected diff against the newest ReplicaSet, got:\n%s", diff)
+	}
+}
+
+func TestGetDeploymentTemplateDiffErrors(t *testing.T) {
+	deployment := deploymentFixture("default", "web", "nginx:1.26", 3)
+	clientset := fake.NewSimpleClientset(deployment, replicaSetFixture(deployment, "aaa", "nginx:1.26", "1"))
+
+	if _, err := GetDeploymentTemplateDiff(context.TODO(), clientset, "default", "web"); !errors.Is(err, ErrNoPreviousReplicaSet) {
+		t.Errorf("Expected ErrNoPreviousReplicaSet for a first rollout, got %v", err)
+	}
+	if _, err := GetDeploymentTemplateDiff(context.TODO(), clientset, "default", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected NotFound for a missing deployment, got %v", err)
+	}
+}
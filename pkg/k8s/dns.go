@@ -0,0 +1,38 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// HostResolver looks up the addresses of a host name, as *net.Resolver does
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// ExternalNameResolver resolves the DNS names of ExternalName services. It is
+// the system resolver unless replaced, e.g. by tests.
+var ExternalNameResolver HostResolver = net.DefaultResolver
+
+// ResolveExternalNameService looks up the IP addresses the external name of
+// an ExternalName service resolves to from where kgo runs, which may differ
+// from what pods in the cluster get
+func ResolveExternalNameService(ctx context.Context, svc v1.Service) ([]string, error) {
+	if svc.Spec.Type != v1.ServiceTypeExternalName {
+		return nil, fmt.Errorf("service %s/%s is of type %s, not ExternalName", svc.Namespace, svc.Name, svc.Spec.Type)
+	}
+	if svc.Spec.ExternalName == "" {
+		return nil, fmt.Errorf("service %s/%s has no external name", svc.Namespace, svc.Name)
+	}
+
+	ips, err := ExternalNameResolver.LookupHost(ctx, svc.Spec.ExternalName)
+	if err != nil {
+		klog.Errorf("Failed to resolve external name %s of service %s/%s: %v", svc.Spec.ExternalName, svc.Namespace, svc.Name, err)
+		return nil, err
+	}
+	return ips, nil
+}
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeResolver answers lookups from a map of host names to addresses
+type fakeResolver struct {
+	hosts   map[string][]string
+	lookups []string
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.lookups = append(r.lookups, host)
+	ips, ok := r.hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+	return ips, nil
+}
+
+func TestResolveExternalNameService(t *testing.T) {
+	resolver := &fakeResolver{hosts: map[string][]string{"db.example.com": {"10.1.2.3", "10.1.2.4"}}}
+	previous := ExternalNameResolver
+	ExternalNameResolver = resolver
+	defer func() { ExternalNameResolver = previous }()
+
+	service := func(serviceType v1.ServiceType, externalName string) v1.Service {
+		return v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       v1.ServiceSpec{Type: serviceType, ExternalName: externalName},
+		}
+	}
+
+	ips, err := ResolveExternalNameService(context.Background(), service(v1.ServiceTypeExternalName, "db.example.com"))
+	if err != nil {
+		t.Fatalf("ResolveExternalNameService failed: %v", err)
+	}
+	if !reflect.DeepEqual(ips, []string{"10.1.2.3", "10.1.2.4"}) {
+		t.Errorf("Expected both addresses, got %v", ips)
+	}
+
+	if _, err := ResolveExternalNameService(context.Background(), service(v1.ServiceTypeExternalName, "missing.example.com")); err == nil {
+		t.Error("Expected an error for an unknown host")
+	}
+
+	for _, svc := range []v1.Service{service(v1.ServiceTypeClusterIP, ""), service(v1.ServiceTypeExternalName, "")} {
+		if _, err := ResolveExternalNameService(context.Background(), svc); err == nil {
+			t.Errorf("Expected an error for %s service with external name %q", svc.Spec.Type, svc.Spec.ExternalName)
+		}
+	}
+	if len(resolver.lookups) != 2 {
+		t.Errorf("Expected only the two ExternalName services to be looked up, got %v", resolver.lookups)
+	}
+}
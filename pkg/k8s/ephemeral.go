@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// debugContainerPrefix starts the names of the debug containers kgo adds,
+// like those of kubectl debug
+const debugContainerPrefix = "debugger-"
+
+// ErrEphemeralContainersUnavailable is returned when the API server does not
+// serve the ephemeralcontainers subresource of pods
+var ErrEphemeralContainersUnavailable = errors.New("ephemeral containers are not available on this cluster: they need Kubernetes 1.23 or later, or the EphemeralContainers feature gate")
+
+// ErrUnknownTargetContainer is returned for a debug container targeting a
+// container the pod does not have
+var ErrUnknownTargetContainer = errors.New("target container not found in pod")
+
+// NewDebugContainer returns an interactive ephemeral container running image,
+// to be named by AddEphemeralContainer. Command, when given, replaces the
+// image's entrypoint. With a targetContainer the debug container shares that
+// container's process namespace, so that its processes can be inspected.
+func NewDebugContainer(image string, command []string, targetContainer string) v1.EphemeralContainer {
+	return v1.EphemeralContainer{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Image:   image,
+			Command: command,
+			// Like kubectl debug -it, so that a shell keeps running until
+			// it is attached to
+			Stdin:                    true,
+			TTY:                      true,
+			TerminationMessagePolicy: v1.TerminationMessageReadFile,
+		},
+		TargetContainerName: targetContainer,
+	}
+}
+
+// AddEphemeralContainer adds an ephemeral container to a running pod through
+// the ephemeralcontainers subresource and returns the updated pod, whose last
+// ephemeral container is the one added. A container without a name gets a
+// debugger- name no container of the pod has. Clusters that do not serve the
+// subresource fail with ErrEphemeralContainersUnavailable.
+func AddEphemeralContainer(ctx context.Context, clientset kubernetes.Interface, namespace, podName string, container v1.EphemeralContainer) (*v1.Pod, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s in namespace %s: %v", podName, namespace, err)
+		return nil, err
+	}
+
+	names := containerNames(pod)
+	if target := container.TargetContainerName; target != "" && !names[target] {
+		return nil, fmt.Errorf("%w: %s has no container %q", ErrUnknownTargetContainer, podName, target)
+	}
+	if container.Name == "" {
+		container.Name = debugContainerPrefix + utilrand.String(5)
+		for names[container.Name] {
+			container.Name = debugContainerPrefix + utilrand.String(5)
+		}
+	}
+
+	pod = pod.DeepCopy()
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+	updated, err := clientset.CoreV1().Pods(namespace).UpdateEphemeralContainers(ctx, podName, pod, metav1.UpdateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to add ephemeral container %s to pod %s in namespace %s: %v", container.Name, podName, namespace, err)
+		// The pod exists, so a missing subresource is a missing feature
+		if apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+			return nil, fmt.Errorf("%w (%v)", ErrEphemeralContainersUnavailable, err)
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// containerNames returns the names of every container of a pod, including
+// init and ephemeral containers
+func containerNames(pod *v1.Pod) map[string]bool {
+	names := make(map[string]bool)
+	for _, container := range pod.Spec.InitContainers {
+		names[container.Name] = true
+	}
+	for _, container := range pod.Spec.Containers {
+		names[container.Name] = true
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		names[container.Name] = true
+	}
+	return names
+}
+
+// IsEphemeralContainer reports whether a pod has an ephemeral container of
+// the given name
+func IsEphemeralContainer(pod *v1.Pod, name string) bool {
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAddEphemeralContainer(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers:          []v1.Container{{Name: "app", Image: "distroless/app"}},
+			EphemeralContainers: []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debugger-old"}}},
+		},
+	}
+	clientset := fake.NewSimpleClientset(pod)
+
+	updated, err := AddEphemeralContainer(context.Background(), clientset, "default", "web", NewDebugContainer("busybox:1.36", []string{"sh"}, "app"))
+	if err != nil {
+		t.Fatalf("AddEphemeralContainer failed: %v", err)
+	}
+	if len(updated.Spec.EphemeralContainers) != 2 {
+		t.Fatalf("Expected 2 ephemeral containers, got %+v", updated.Spec.EphemeralContainers)
+	}
+	added := updated.Spec.EphemeralContainers[1]
+	if !strings.HasPrefix(added.Name, "debugger-") || added.Name == "debugger-old" {
+		t.Errorf("Expected a new debugger- name, got %q", added.Name)
+	}
+	if added.Image != "busybox:1.36" || added.TargetContainerName != "app" || !added.Stdin || !added.TTY {
+		t.Errorf("Expected an interactive busybox container targeting app, got %+v", added)
+	}
+	if !IsEphemeralContainer(updated, added.Name) || IsEphemeralContainer(updated, "app") {
+		t.Errorf("Expected only %s and debugger-old to be ephemeral", added.Name)
+	}
+
+	// The container is added through the subresource, and stored
+	var subresource string
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "update" {
+			subresource = action.GetSubresource()
+		}
+	}
+	if subresource != "ephemeralcontainers" {
+		t.Errorf("Expected an update of the ephemeralcontainers subresource, got %q", subresource)
+	}
+	stored, _ := clientset.CoreV1().Pods("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if !IsEphemeralContainer(stored, added.Name) {
+		t.Errorf("Expected the stored pod to have %s, got %+v", added.Name, stored.Spec.EphemeralContainers)
+	}
+
+	if _, err := AddEphemeralContainer(context.Background(), clientset, "default", "web", NewDebugContainer("busybox:1.36", nil, "sidecar")); !errors.Is(err, ErrUnknownTargetContainer) {
+		t.Errorf("Expected ErrUnknownTargetContainer, got %v", err)
+	}
+	if _, err := AddEphemeralContainer(context.Background(), clientset, "default", "missing", NewDebugContainer("busybox:1.36", nil, "")); !apierrors.IsNotFound(err) || errors.Is(err, ErrEphemeralContainersUnavailable) {
+		t.Errorf("Expected a missing pod to be not found, got %v", err)
+	}
+
+	// Without the subresource the API server answers 404 for an existing pod
+	clientset.PrependReactor("update", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "ephemeralcontainers" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods/ephemeralcontainers"}, "web")
+	})
+	if _, err := AddEphemeralContainer(context.Background(), clientset, "default", "web", NewDebugContainer("busybox:1.36", nil, "")); !errors.Is(err, ErrEphemeralContainersUnavailable) {
+		t.Errorf("Expected ErrEphemeralContainersUnavailable, got %v", err)
+	}
+}
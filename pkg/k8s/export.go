@@ -0,0 +1,219 @@
+package k8s
+
+import (
+	goerrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// ExportTypes are the resource types a namespace export can contain, in the
+// order an import applies them so that referenced objects exist first
+var ExportTypes = []string{"serviceaccounts", "configmaps", "secrets", "services", "deployments", "pods", "ingresses"}
+
+// exportedObject is one object of a namespace export
+type exportedObject struct {
+	name string
+	obj  runtime.Object
+}
+
+// ExportNamespace writes every object of the given types in a namespace to
+// <outputDir>/<namespace>/<type>/<name>.yaml, exporting all ExportTypes when
+// types is empty. Fields the cluster sets, such as status, uid and
+// resourceVersion, are stripped so that the files can be applied to another
+// cluster, and secret values are never written. It returns the paths of the
+// files written.
+func ExportNamespace(clientset kubernetes.Interface, namespace, outputDir string, types []string) ([]string, error) {
+	if len(types) == 0 {
+		types = ExportTypes
+	}
+	for _, resourceType := range types {
+		if !contains(ExportTypes, resourceType) {
+			return nil, fmt.Errorf("unsupported export type %q, expected one of %v", resourceType, ExportTypes)
+		}
+	}
+
+	var written []string
+	for _, resourceType := range types {
+		objects, err := listExportedObjects(clientset, namespace, resourceType)
+		if err != nil {
+			return written, err
+		}
+		if len(objects) == 0 {
+			continue
+		}
+
+		dir := filepath.Join(outputDir, namespace, resourceType)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return written, err
+		}
+		for _, object := range objects {
+			data, err := exportYAML(object.obj)
+			if err != nil {
+				return written, fmt.Errorf("failed to export %s %s: %w", resourceType, object.name, err)
+			}
+			path := filepath.Join(dir, object.name+".yaml")
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				return written, err
+			}
+			written = append(written, path)
+		}
+	}
+	return written, nil
+}
+
+// listExportedObjects lists the objects of one export type. Objects with a
+// controller, such as the pods of a deployment, are skipped because importing
+// their controller recreates them. Listed items carry no apiVersion or kind,
+// so they are set here for the YAML to be applicable.
+func listExportedObjects(clientset kubernetes.Interface, namespace, resourceType string) ([]exportedObject, error) {
+	var objects []exportedObject
+	add := func(name string, obj runtime.Object, gvk schema.GroupVersionKind) {
+		if metav1.GetControllerOf(obj.(metav1.Object)) != nil {
+			return
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+		objects = append(objects, exportedObject{name: name, obj: obj})
+	}
+
+	switch resourceType {
+	case "pods":
+		pods, err := ListPods(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range pods {
+			add(pods[i].Name, &pods[i], v1.SchemeGroupVersion.WithKind("Pod"))
+		}
+	case "deployments":
+		deployments, err := ListDeployments(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range deployments {
+			add(deployments[i].Name, &deployments[i], appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		}
+	case "services":
+		services, err := ListServices(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range services {
+			add(services[i].Name, &services[i], v1.SchemeGroupVersion.WithKind("Service"))
+		}
+	case "configmaps":
+		configMaps, err := ListConfigMaps(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range configMaps {
+			add(configMaps[i].Name, &configMaps[i], v1.SchemeGroupVersion.WithKind("ConfigMap"))
+		}
+	case "secrets":
+		secrets, err := ListSecrets(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range secrets {
+			add(secrets[i].Name, &secrets[i], v1.SchemeGroupVersion.WithKind("Secret"))
+		}
+	case "ingresses":
+		ingresses, err := ListIngresses(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range ingresses {
+			add(ingresses[i].Name, &ingresses[i], networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+		}
+	case "serviceaccounts":
+		serviceAccounts, err := ListServiceAccounts(clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for i := range serviceAccounts {
+			add(serviceAccounts[i].Name, &serviceAccounts[i], v1.SchemeGroupVersion.WithKind("ServiceAccount"))
+		}
+	}
+	return objects, nil
+}
+
+// exportYAML marshals an object without the fields that are not portable
+// between clusters. The namespace is dropped too, so that an import can target
+// any namespace.
+func exportYAML(obj runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "namespace"} {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	delete(content, "status")
+
+	switch obj.(type) {
+	case *v1.Service:
+		// Cluster IPs are allocated by the cluster the service lives in
+		unstructured.RemoveNestedField(content, "spec", "clusterIP")
+		unstructured.RemoveNestedField(content, "spec", "clusterIPs")
+	case *v1.Secret:
+		delete(content, "data")
+		delete(content, "stringData")
+	}
+
+	return yaml.Marshal(content)
+}
+
+// ImportNamespace applies the YAML files of a namespace export directory, as
+// written by ExportNamespace under <outputDir>/<namespace>, to a namespace.
+// Types are applied in ExportTypes order and files within a type by name.
+// Every file is attempted; the paths applied are returned along with an error
+// joining every failure.
+func ImportNamespace(clientset kubernetes.Interface, namespace, inputDir string) ([]string, error) {
+	if _, err := os.Stat(inputDir); err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	var errs []error
+	for _, resourceType := range ExportTypes {
+		paths, err := filepath.Glob(filepath.Join(inputDir, resourceType, "*.yaml"))
+		if err != nil {
+			return applied, err
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err == nil {
+				err = ApplyYaml(clientset, namespace, string(data))
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			applied = append(applied, path)
+		}
+	}
+	return applied, goerrors.Join(errs...)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
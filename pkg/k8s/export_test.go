@@ -0,0 +1,222 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// exportFixture is a namespace with one object of every export type, plus a
+// pod owned by a replica set
+func exportFixture() *fake.Clientset {
+	cluster := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "shop",
+			UID:               types.UID("uid-" + name),
+			ResourceVersion:   "42",
+			CreationTimestamp: metav1.Now(),
+			Labels:            map[string]string{"app": "web"},
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		}
+	}
+	isController := true
+	owned := cluster("web-7d9f-abcde")
+	owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-7d9f", UID: "rs", Controller: &isController}}
+	podSpec := v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx"}}}
+
+	return fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: cluster("debug"), Spec: podSpec, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		&v1.Pod{ObjectMeta: owned, Spec: podSpec},
+		&appsv1.Deployment{ObjectMeta: cluster("web"), Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: v1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}, Spec: podSpec},
+		}, Status: appsv1.DeploymentStatus{ReadyReplicas: 1}},
+		&v1.Service{ObjectMeta: cluster("web"), Spec: v1.ServiceSpec{
+			ClusterIP:  "10.96.0.12",
+			ClusterIPs: []string{"10.96.0.12"},
+			Selector:   map[string]string{"app": "web"},
+			Ports:      []v1.ServicePort{{Port: 80}},
+		}},
+		&v1.ConfigMap{ObjectMeta: cluster("web-config"), Data: map[string]string{"mode": "production"}},
+		&v1.Secret{ObjectMeta: cluster("web-tls"), Type: v1.SecretTypeTLS, Data: map[string][]byte{"tls.key": []byte("private")}},
+		&networkingv1.Ingress{ObjectMeta: cluster("web"), Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{Host: "shop.example.com"}},
+		}},
+		&v1.ServiceAccount{ObjectMeta: cluster("web")},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "elsewhere", Namespace: "other"}},
+	)
+}
+
+// readExport reads an exported file into a map
+func readExport(t *testing.T, path string) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	var content map[string]interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+	return content
+}
+
+func TestExportNamespace(t *testing.T) {
+	dir := t.TempDir()
+	written, err := ExportNamespace(exportFixture(), "shop", dir, nil)
+	if err != nil {
+		t.Fatalf("ExportNamespace failed: %v", err)
+	}
+
+	var got []string
+	for _, path := range written {
+		rel, _ := filepath.Rel(dir, path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{
+		"shop/serviceaccounts/web.yaml",
+		"shop/configmaps/web-config.yaml",
+		"shop/secrets/web-tls.yaml",
+		"shop/services/web.yaml",
+		"shop/deployments/web.yaml",
+		"shop/pods/debug.yaml",
+		"shop/ingresses/web.yaml",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected files %v, got %v", want, got)
+	}
+
+	for _, path := range written {
+		content := readExport(t, path)
+		if content["apiVersion"] == nil || content["kind"] == nil {
+			t.Errorf("%s: expected apiVersion and kind, got %v", path, content)
+		}
+		if _, ok := content["status"]; ok {
+			t.Errorf("%s: expected status to be stripped", path)
+		}
+		metadata := content["metadata"].(map[string]interface{})
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "creationTimestamp", "namespace"} {
+			if _, ok := metadata[field]; ok {
+				t.Errorf("%s: expected metadata.%s to be stripped", path, field)
+			}
+		}
+		if labels, _ := metadata["labels"].(map[string]interface{}); labels["app"] != "web" {
+			t.Errorf("%s: expected labels to be kept, got %v", path, metadata["labels"])
+		}
+	}
+
+	secret := readExport(t, filepath.Join(dir, "shop", "secrets", "web-tls.yaml"))
+	if _, ok := secret["data"]; ok {
+		t.Errorf("Expected secret values to be stripped, got %v", secret["data"])
+	}
+	if secret["kind"] != "Secret" || secret["type"] != string(v1.SecretTypeTLS) {
+		t.Errorf("Expected the secret kind and type to be kept, got %v", secret)
+	}
+
+	service := readExport(t, filepath.Join(dir, "shop", "services", "web.yaml"))
+	spec := service["spec"].(map[string]interface{})
+	if _, ok := spec["clusterIP"]; ok {
+		t.Errorf("Expected the cluster IP to be stripped, got %v", spec)
+	}
+	if spec["selector"] == nil || spec["ports"] == nil {
+		t.Errorf("Expected the service spec to be kept, got %v", spec)
+	}
+
+	configMap := readExport(t, filepath.Join(dir, "shop", "configmaps", "web-config.yaml"))
+	if data := configMap["data"].(map[string]interface{}); data["mode"] != "production" {
+		t.Errorf("Expected configmap data to be kept, got %v", configMap["data"])
+	}
+}
+
+func TestExportNamespaceTypes(t *testing.T) {
+	dir := t.TempDir()
+	written, err := ExportNamespace(exportFixture(), "shop", dir, []string{"configmaps"})
+	if err != nil {
+		t.Fatalf("ExportNamespace failed: %v", err)
+	}
+	if len(written) != 1 || filepath.Base(written[0]) != "web-config.yaml" {
+		t.Errorf("Expected only the configmap to be exported, got %v", written)
+	}
+
+	if _, err := ExportNamespace(exportFixture(), "shop", dir, []string{"jobs"}); err == nil {
+		t.Error("Expected an unsupported type to fail")
+	}
+}
+
+func TestImportNamespace(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := ExportNamespace(exportFixture(), "shop", dir, nil); err != nil {
+		t.Fatalf("ExportNamespace failed: %v", err)
+	}
+
+	target := fake.NewSimpleClientset()
+	applied, err := ImportNamespace(target, "shop-restore", filepath.Join(dir, "shop"))
+	if err != nil {
+		t.Fatalf("ImportNamespace failed: %v", err)
+	}
+	if len(applied) != 7 {
+		t.Errorf("Expected 7 files to be applied, got %v", applied)
+	}
+
+	ctx := context.Background()
+	if _, err := target.CoreV1().Pods("shop-restore").Get(ctx, "debug", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the pod to be imported: %v", err)
+	}
+	if _, err := target.AppsV1().Deployments("shop-restore").Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the deployment to be imported: %v", err)
+	}
+	if _, err := target.NetworkingV1().Ingresses("shop-restore").Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the ingress to be imported: %v", err)
+	}
+	if _, err := target.CoreV1().ServiceAccounts("shop-restore").Get(ctx, "web", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the service account to be imported: %v", err)
+	}
+	secret, err := target.CoreV1().Secrets("shop-restore").Get(ctx, "web-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the secret to be imported: %v", err)
+	}
+	if len(secret.Data) != 0 {
+		t.Errorf("Expected the imported secret to have no values, got %v", secret.Data)
+	}
+
+	// Importing again patches the existing objects
+	if _, err := ImportNamespace(target, "shop-restore", filepath.Join(dir, "shop")); err != nil {
+		t.Errorf("Expected a second import to succeed, got %v", err)
+	}
+}
+
+func TestImportNamespaceErrors(t *testing.T) {
+	if _, err := ImportNamespace(fake.NewSimpleClientset(), "shop", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected a missing directory to fail")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "configmaps"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "configmaps", "bad.yaml"), []byte("not: [valid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "configmaps", "good.yaml"), []byte(configMapYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := ImportNamespace(fake.NewSimpleClientset(), "shop", dir)
+	if err == nil || !strings.Contains(err.Error(), "bad.yaml") {
+		t.Errorf("Expected the bad file to be reported, got %v", err)
+	}
+	if len(applied) != 1 || filepath.Base(applied[0]) != "good.yaml" {
+		t.Errorf("Expected the other files to still be applied, got %v", applied)
+	}
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// featureEnabledMetric is the API server metric telling whether a feature
+// gate is enabled, served on /metrics since Kubernetes 1.26
+const featureEnabledMetric = "kubernetes_feature_enabled"
+
+// FeatureGate is a feature gate of the API server
+type FeatureGate struct {
+	Name string `json:"name"`
+	// Stage is ALPHA, BETA, GA or DEPRECATED
+	Stage   string `json:"stage"`
+	Enabled bool   `json:"enabled"`
+}
+
+// GetFeatureGates reads the feature gates of the API server from its
+// kubernetes_feature_enabled metric. Reading /metrics needs RBAC on the
+// non-resource URL, which many users lack, and servers before Kubernetes 1.26
+// do not report the metric. Clientsets that cannot make raw requests, such as
+// fakes, have no feature gates.
+func GetFeatureGates(ctx context.Context, clientset kubernetes.Interface) ([]FeatureGate, error) {
+	restClient := clientset.Discovery().RESTClient()
+	if restClient == nil {
+		return nil, nil
+	}
+	metrics, err := restClient.Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		klog.Warningf("Failed to read the API server metrics: %v", err)
+		return nil, err
+	}
+	gates := ParseFeatureGates(metrics)
+	if len(gates) == 0 {
+		return nil, errors.New("the API server does not report " + featureEnabledMetric)
+	}
+	return gates, nil
+}
+
+// ParseFeatureGates returns the feature gates in the kubernetes_feature_enabled
+// samples of Prometheus text metrics, sorted by name, e.g. from
+// kubernetes_feature_enabled{name="APIListChunking",stage=""} 1
+func ParseFeatureGates(metrics []byte) []FeatureGate {
+	var gates []FeatureGate
+	scanner := bufio.NewScanner(bytes.NewReader(metrics))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, featureEnabledMetric+"{") {
+			continue
+		}
+		end := strings.LastIndex(line, "}")
+		if end < 0 {
+			continue
+		}
+		labels := metricLabels(line[len(featureEnabledMetric)+1 : end])
+		if labels["name"] == "" {
+			continue
+		}
+		stage := labels["stage"]
+		if stage == "" {
+			stage = "GA"
+		}
+		gates = append(gates, FeatureGate{
+			Name:    labels["name"],
+			Stage:   stage,
+			Enabled: strings.TrimSpace(line[end+1:]) == "1",
+		})
+	}
+	sort.Slice(gates, func(i, j int) bool {
+		return gates[i].Name < gates[j].Name
+	})
+	return gates
+}
+
+// metricLabels parses the labels of a metric sample, e.g. name="a",stage=""
+func metricLabels(labels string) map[string]string {
+	parsed := map[string]string{}
+	for _, label := range strings.Split(labels, ",") {
+		key, value, ok := strings.Cut(label, "=")
+		if !ok {
+			continue
+		}
+		parsed[strings.TrimSpace(key)] = strings.Trim(value, `"`)
+	}
+	return parsed
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+const testMetrics = `# HELP kubernetes_feature_enabled [BETA] This metric records the data about the stage and enablement of a k8s feature.
+# TYPE kubernetes_feature_enabled gauge
+kubernetes_feature_enabled{name="SidecarContainers",stage="BETA"} 1
+kubernetes_feature_enabled{name="APIListChunking",stage=""} 1
+kubernetes_feature_enabled{name="InPlacePodVerticalScaling",stage="ALPHA"} 0
+apiserver_request_total{code="200",verb="GET"} 42
+`
+
+func TestParseFeatureGates(t *testing.T) {
+	want := []FeatureGate{
+		{Name: "APIListChunking", Stage: "GA", Enabled: true},
+		{Name: "InPlacePodVerticalScaling", Stage: "ALPHA", Enabled: false},
+		{Name: "SidecarContainers", Stage: "BETA", Enabled: true},
+	}
+	if got := ParseFeatureGates([]byte(testMetrics)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if got := ParseFeatureGates([]byte("apiserver_request_total 1\n")); len(got) != 0 {
+		t.Errorf("Expected no feature gates without the metric, got %+v", got)
+	}
+
+	info := ClusterInfo{APIServer: &ServerInfo{}, FeatureGates: want}
+	if lines := strings.Join(info.Lines(), "\n"); !strings.Contains(lines, "Feature gates:  3 (2 enabled)") {
+		t.Errorf("Expected the feature gate count in the lines, got:\n%s", lines)
+	}
+}
+
+func TestGetFeatureGates(t *testing.T) {
+	status, body := http.StatusOK, testMetrics
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create the clientset: %v", err)
+	}
+
+	gates, err := GetFeatureGates(context.Background(), clientset)
+	if err != nil || len(gates) != 3 || gates[0].Name != "APIListChunking" {
+		t.Errorf("Expected the 3 feature gates, got %+v, %v", gates, err)
+	}
+
+	status, body = http.StatusForbidden, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`
+	if _, err := GetFeatureGates(context.Background(), clientset); err == nil {
+		t.Error("Expected an error when /metrics is forbidden")
+	}
+
+	status, body = http.StatusOK, "apiserver_request_total 1\n"
+	if _, err := GetFeatureGates(context.Background(), clientset); err == nil {
+		t.Error("Expected an error without the feature metric")
+	}
+
+	// A fake clientset has no feature gates to read
+	if gates, err := GetFeatureGates(context.Background(), fake.NewSimpleClientset()); gates != nil || err != nil {
+		t.Errorf("Expected no feature gates from a fake clientset, got %+v, %v", gates, err)
+	}
+}
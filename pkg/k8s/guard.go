@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"fmt"
+	"path"
+)
+
+// ConfirmationRequiredError is returned when a mutating operation targets a
+// protected namespace without the matching confirmation
+type ConfirmationRequiredError struct {
+	Namespace string
+}
+
+func (e *ConfirmationRequiredError) Error() string {
+	return fmt.Sprintf("namespace %s is protected: mutating operations require confirmation", e.Namespace)
+}
+
+// NamespaceGuard decides which namespaces need an explicit confirmation before
+// a mutating operation is allowed. It is shared by the REST, gRPC and TUI layers
+// so all three surfaces enforce the same rules.
+type NamespaceGuard struct {
+	patterns []string
+}
+
+// NewNamespaceGuard creates a guard from a list of glob patterns such as "prod-*"
+func NewNamespaceGuard(patterns []string) (*NamespaceGuard, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid protected namespace pattern %q: %v", pattern, err)
+		}
+	}
+	return &NamespaceGuard{patterns: patterns}, nil
+}
+
+// IsProtected reports whether the namespace matches any protected pattern
+func (g *NamespaceGuard) IsProtected(namespace string) bool {
+	if g == nil {
+		return false
+	}
+	for _, pattern := range g.patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns a ConfirmationRequiredError if the namespace is protected and
+// the confirmation does not name it exactly
+func (g *NamespaceGuard) Check(namespace, confirmation string) error {
+	if !g.IsProtected(namespace) {
+		return nil
+	}
+	if confirmation != namespace {
+		return &ConfirmationRequiredError{Namespace: namespace}
+	}
+	return nil
+}
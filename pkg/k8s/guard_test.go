@@ -0,0 +1,77 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNamespaceGuardGlobMatching(t *testing.T) {
+	guard, err := NewNamespaceGuard([]string{"kube-system", "prod-*", "team-?-live"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	tests := []struct {
+		namespace string
+		protected bool
+	}{
+		{"kube-system", true},
+		{"prod-eu", true},
+		{"prod-", true},
+		{"production", false},
+		{"staging-prod-eu", false},
+		{"team-a-live", true},
+		{"team-ab-live", false},
+		{"default", false},
+	}
+
+	for _, tt := range tests {
+		if got := guard.IsProtected(tt.namespace); got != tt.protected {
+			t.Errorf("IsProtected(%q) = %v, expected %v", tt.namespace, got, tt.protected)
+		}
+	}
+}
+
+func TestNamespaceGuardCheck(t *testing.T) {
+	guard, err := NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	if err := guard.Check("default", ""); err != nil {
+		t.Errorf("Expected unprotected namespace to pass, got %v", err)
+	}
+
+	err = guard.Check("prod-eu", "")
+	var confirmErr *ConfirmationRequiredError
+	if !errors.As(err, &confirmErr) {
+		t.Fatalf("Expected ConfirmationRequiredError, got %v", err)
+	}
+	if confirmErr.Namespace != "prod-eu" {
+		t.Errorf("Expected namespace prod-eu in error, got %s", confirmErr.Namespace)
+	}
+
+	if err := guard.Check("prod-eu", "prod-us"); err == nil {
+		t.Error("Expected confirmation naming another namespace to be rejected")
+	}
+
+	if err := guard.Check("prod-eu", "prod-eu"); err != nil {
+		t.Errorf("Expected matching confirmation to pass, got %v", err)
+	}
+}
+
+func TestNamespaceGuardInvalidPattern(t *testing.T) {
+	if _, err := NewNamespaceGuard([]string{"prod-["}); err == nil {
+		t.Error("Expected invalid glob pattern to be rejected")
+	}
+}
+
+func TestNilNamespaceGuard(t *testing.T) {
+	var guard *NamespaceGuard
+	if guard.IsProtected("kube-system") {
+		t.Error("Expected nil guard to protect nothing")
+	}
+	if err := guard.Check("kube-system", ""); err != nil {
+		t.Errorf("Expected nil guard to allow everything, got %v", err)
+	}
+}
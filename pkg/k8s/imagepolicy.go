@@ -0,0 +1,251 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// DefaultRegistry is the registry of image references that name none, e.g.
+// "nginx" or "bitnami/redis"
+const DefaultRegistry = "docker.io"
+
+// dockerHubAliases are the hosts Docker Hub images are also pulled from
+var dockerHubAliases = map[string]bool{
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+var (
+	// repositoryComponentPattern matches one path component of a repository
+	repositoryComponentPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	tagPattern                 = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	digestPattern              = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+)
+
+// ImageReference is a container image reference split into its parts, with
+// the implicit Docker Hub registry and library/ namespace made explicit
+type ImageReference struct {
+	// Registry is the registry host, with its port if it has one
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// String returns the fully qualified reference
+func (r ImageReference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// ParseImageReference parses an image reference the way the container
+// runtime resolves it: the first path component is the registry host when it
+// contains a '.' or a ':' or is localhost, and Docker Hub otherwise, e.g.
+//
+//	nginx                          → docker.io/library/nginx
+//	registry.internal:5000/web:1.2 → registry.internal:5000/web, tag 1.2
+//	ghcr.io/org/app@sha256:...     → ghcr.io/org/app, digest sha256:...
+func ParseImageReference(image string) (ImageReference, error) {
+	var ref ImageReference
+	if image == "" {
+		return ref, fmt.Errorf("image reference is empty")
+	}
+	if strings.TrimSpace(image) != image || strings.ContainsAny(image, " \t\n") {
+		return ref, fmt.Errorf("image reference %q contains whitespace", image)
+	}
+
+	name := image
+	if at := strings.Index(name, "@"); at >= 0 {
+		name, ref.Digest = name[:at], name[at+1:]
+		if !digestPattern.MatchString(ref.Digest) {
+			return ref, fmt.Errorf("image reference %q has an invalid digest", image)
+		}
+	}
+
+	// The tag follows the last ':' after the last '/', so that a registry
+	// port is not mistaken for one
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:colon], name[colon+1:]
+		if !tagPattern.MatchString(ref.Tag) {
+			return ref, fmt.Errorf("image reference %q has an invalid tag", image)
+		}
+	}
+
+	ref.Registry, ref.Repository = DefaultRegistry, name
+	if slash := strings.Index(name, "/"); slash >= 0 {
+		host := name[:slash]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry, ref.Repository = normalizeRegistry(host), name[slash+1:]
+		}
+	}
+	if ref.Registry == DefaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	for _, component := range strings.Split(ref.Repository, "/") {
+		if !repositoryComponentPattern.MatchString(component) {
+			return ref, fmt.Errorf("image reference %q has an invalid repository %q", image, ref.Repository)
+		}
+	}
+	return ref, nil
+}
+
+// normalizeRegistry lowercases a registry host and maps the Docker Hub
+// aliases to DefaultRegistry
+func normalizeRegistry(host string) string {
+	host = strings.ToLower(host)
+	if dockerHubAliases[host] {
+		return DefaultRegistry
+	}
+	return host
+}
+
+// ImagePolicy restricts the registries container images may come from. It is
+// shared by every path that creates or changes pod specs, REST, gRPC and the
+// TUI, so that no surface can bypass it. A nil policy, or one without
+// registries, allows every image.
+type ImagePolicy struct {
+	// registries are the allowed hosts, normalized and in configured order
+	registries []string
+	allowed    map[string]bool
+}
+
+// NewImagePolicy returns a policy allowing images from the given registry
+// hosts, e.g. "registry.internal" or "registry.internal:5000". "docker.io"
+// allows images that name no registry.
+func NewImagePolicy(allowedRegistries []string) *ImagePolicy {
+	policy := &ImagePolicy{allowed: make(map[string]bool)}
+	for _, registry := range allowedRegistries {
+		registry = normalizeRegistry(strings.TrimSpace(registry))
+		if registry == "" || policy.allowed[registry] {
+			continue
+		}
+		policy.allowed[registry] = true
+		policy.registries = append(policy.registries, registry)
+	}
+	return policy
+}
+
+// Enabled reports whether the policy restricts any image
+func (p *ImagePolicy) Enabled() bool {
+	return p != nil && len(p.registries) > 0
+}
+
+// AllowedRegistries returns the allowed registry hosts
+func (p *ImagePolicy) AllowedRegistries() []string {
+	if p == nil {
+		return nil
+	}
+	return p.registries
+}
+
+// CheckImage returns why an image is not allowed, or nil
+func (p *ImagePolicy) CheckImage(image string) error {
+	if !p.Enabled() {
+		return nil
+	}
+	ref, err := ParseImageReference(image)
+	if err != nil {
+		return err
+	}
+	if !p.allowed[ref.Registry] {
+		return fmt.Errorf("image %q is from registry %s, allowed registries: %s", image, ref.Registry, strings.Join(p.registries, ", "))
+	}
+	return nil
+}
+
+// CheckPodSpec returns a field error for every container, init container and
+// ephemeral container of a pod spec whose image is not allowed
+func (p *ImagePolicy) CheckPodSpec(spec *v1.PodSpec, path *field.Path) field.ErrorList {
+	if !p.Enabled() {
+		return nil
+	}
+	var errs field.ErrorList
+	check := func(name, image string, imagePath *field.Path) {
+		if err := p.CheckImage(image); err != nil {
+			errs = append(errs, field.Forbidden(imagePath, fmt.Sprintf("container %q: %v", name, err)))
+		}
+	}
+	for i, container := range spec.InitContainers {
+		check(container.Name, container.Image, path.Child("initContainers").Index(i).Child("image"))
+	}
+	for i, container := range spec.Containers {
+		check(container.Name, container.Image, path.Child("containers").Index(i).Child("image"))
+	}
+	for i, container := range spec.EphemeralContainers {
+		check(container.Name, container.Image, path.Child("ephemeralContainers").Index(i).Child("image"))
+	}
+	return errs
+}
+
+// CheckObject checks the pod spec of a pod or workload, returning a
+// Kubernetes Invalid error naming every container whose image is not
+// allowed. Objects without a pod spec pass.
+func (p *ImagePolicy) CheckObject(obj runtime.Object) error {
+	if !p.Enabled() {
+		return nil
+	}
+	spec, specPath, gk, name := podSpecOf(obj)
+	if spec == nil {
+		return nil
+	}
+	if errs := p.CheckPodSpec(spec, specPath); len(errs) > 0 {
+		return apierrors.NewInvalid(gk, name, errs)
+	}
+	return nil
+}
+
+// CheckManifest checks the object of a manifest applied with ApplyYaml.
+// Manifests that do not decode pass, and are rejected by ApplyYaml.
+func (p *ImagePolicy) CheckManifest(manifest string) error {
+	if !p.Enabled() {
+		return nil
+	}
+	decode := serializer.NewCodecFactory(scheme.Scheme).UniversalDeserializer().Decode
+	obj, _, err := decode([]byte(manifest), nil, nil)
+	if err != nil {
+		return nil
+	}
+	return p.CheckObject(obj)
+}
+
+// podSpecOf returns the pod spec of a pod or workload with its field path,
+// and the object's group kind and name
+func podSpecOf(obj runtime.Object) (*v1.PodSpec, *field.Path, schema.GroupKind, string) {
+	template := field.NewPath("spec", "template", "spec")
+	switch o := obj.(type) {
+	case *v1.Pod:
+		return &o.Spec, field.NewPath("spec"), schema.GroupKind{Kind: "Pod"}, o.Name
+	case *appsv1.Deployment:
+		return &o.Spec.Template.Spec, template, schema.GroupKind{Group: appsv1.GroupName, Kind: "Deployment"}, o.Name
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template.Spec, template, schema.GroupKind{Group: appsv1.GroupName, Kind: "StatefulSet"}, o.Name
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template.Spec, template, schema.GroupKind{Group: appsv1.GroupName, Kind: "DaemonSet"}, o.Name
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Template.Spec, template, schema.GroupKind{Group: appsv1.GroupName, Kind: "ReplicaSet"}, o.Name
+	case *batchv1.Job:
+		return &o.Spec.Template.Spec, template, schema.GroupKind{Group: batchv1.GroupName, Kind: "Job"}, o.Name
+	case *batchv1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template.Spec, field.NewPath("spec", "jobTemplate", "spec", "template", "spec"),
+			schema.GroupKind{Group: batchv1.GroupName, Kind: "CronJob"}, o.Name
+	}
+	return nil, nil, schema.GroupKind{}, ""
+}
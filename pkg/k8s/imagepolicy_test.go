@@ -0,0 +1,273 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  ImageReference
+	}{
+		{"nginx", ImageReference{Registry: "docker.io", Repository: "library/nginx"}},
+		{"nginx:1.25", ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"}},
+		{"bitnami/redis:7.2", ImageReference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"}},
+		{"docker.io/nginx", ImageReference{Registry: "docker.io", Repository: "library/nginx"}},
+		{"docker.io/library/nginx:latest", ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"index.docker.io/nginx", ImageReference{Registry: "docker.io", Repository: "library/nginx"}},
+		{"registry-1.docker.io/bitnami/redis", ImageReference{Registry: "docker.io", Repository: "bitnami/redis"}},
+		{"ghcr.io/org/app", ImageReference{Registry: "ghcr.io", Repository: "org/app"}},
+		{"GHCR.io/org/app", ImageReference{Registry: "ghcr.io", Repository: "org/app"}},
+		{"registry.internal:5000/web", ImageReference{Registry: "registry.internal:5000", Repository: "web"}},
+		{"registry.internal:5000/team/web:1.2", ImageReference{Registry: "registry.internal:5000", Repository: "team/web", Tag: "1.2"}},
+		{"localhost/web", ImageReference{Registry: "localhost", Repository: "web"}},
+		{"localhost:5000/web:dev", ImageReference{Registry: "localhost:5000", Repository: "web", Tag: "dev"}},
+		{"gcr.io/distroless/static@" + testDigest, ImageReference{Registry: "gcr.io", Repository: "distroless/static", Digest: testDigest}},
+		{"nginx:1.25@" + testDigest, ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: testDigest}},
+		{"registry.internal:5000/web@" + testDigest, ImageReference{Registry: "registry.internal:5000", Repository: "web", Digest: testDigest}},
+		{"my-registry/app", ImageReference{Registry: "docker.io", Repository: "my-registry/app"}},
+		{"a/b/c/d:v1", ImageReference{Registry: "docker.io", Repository: "a/b/c/d", Tag: "v1"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseImageReference(tt.image)
+		if err != nil {
+			t.Errorf("ParseImageReference(%q) failed: %v", tt.image, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseImageReference(%q) = %+v, want %+v", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestParseImageReferenceInvalid(t *testing.T) {
+	for _, image := range []string{
+		"",
+		" nginx",
+		"nginx latest",
+		"Nginx",
+		"nginx:",
+		"nginx:-bad",
+		"nginx@sha256:short",
+		"nginx@" + strings.ToUpper(testDigest),
+		"registry.internal:5000/",
+		"registry.internal:5000/Web",
+		"//nginx",
+		"nginx//app",
+	} {
+		if ref, err := ParseImageReference(image); err == nil {
+			t.Errorf("Expected ParseImageReference(%q) to fail, got %+v", image, ref)
+		}
+	}
+}
+
+func TestImageReferenceString(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                               "docker.io/library/nginx",
+		"registry.internal:5000/web:1.2":      "registry.internal:5000/web:1.2",
+		"ghcr.io/org/app:v1@" + testDigest:    "ghcr.io/org/app:v1@" + testDigest,
+		"index.docker.io/bitnami/redis:7.2.4": "docker.io/bitnami/redis:7.2.4",
+	} {
+		ref, err := ParseImageReference(image)
+		if err != nil {
+			t.Fatalf("ParseImageReference(%q) failed: %v", image, err)
+		}
+		if got := ref.String(); got != want {
+			t.Errorf("String() of %q = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestImagePolicyCheckImage(t *testing.T) {
+	policy := NewImagePolicy([]string{"registry.internal:5000", " GHCR.io ", "index.docker.io", "ghcr.io"})
+	if got := strings.Join(policy.AllowedRegistries(), ","); got != "registry.internal:5000,ghcr.io,docker.io" {
+		t.Errorf("Expected normalized, deduplicated registries, got %s", got)
+	}
+
+	tests := []struct {
+		image string
+		allow bool
+	}{
+		{"registry.internal:5000/web:1.2", true},
+		{"registry.internal:5000/web@" + testDigest, true},
+		{"ghcr.io/org/app", true},
+		{"nginx", true}, // docker.io is allowed
+		{"docker.io/bitnami/redis", true},
+		{"registry.internal/web", false},      // the port is part of the host
+		{"registry.internal:5001/web", false}, // so is a different one
+		{"quay.io/org/app", false},
+		{"evil.ghcr.io/org/app", false},
+		{"localhost/web", false},
+		{"Nginx", false}, // invalid references are not allowed
+	}
+	for _, tt := range tests {
+		err := policy.CheckImage(tt.image)
+		if tt.allow && err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", tt.image, err)
+		}
+		if !tt.allow && err == nil {
+			t.Errorf("Expected %q to be rejected", tt.image)
+		}
+	}
+
+	err := policy.CheckImage("quay.io/org/app")
+	if err == nil || !strings.Contains(err.Error(), "registry quay.io") || !strings.Contains(err.Error(), "allowed registries: registry.internal:5000, ghcr.io, docker.io") {
+		t.Errorf("Expected the registry and allowed registries in the error, got %v", err)
+	}
+
+	onlyInternal := NewImagePolicy([]string{"registry.internal"})
+	if err := onlyInternal.CheckImage("nginx"); err == nil || !strings.Contains(err.Error(), "registry docker.io") {
+		t.Errorf("Expected an implicit Docker Hub image to be rejected, got %v", err)
+	}
+}
+
+func TestImagePolicyDisabled(t *testing.T) {
+	for _, policy := range []*ImagePolicy{nil, NewImagePolicy(nil), NewImagePolicy([]string{"", " "})} {
+		if policy.Enabled() {
+			t.Errorf("Expected %+v to be disabled", policy)
+		}
+		if err := policy.CheckImage("quay.io/anything"); err != nil {
+			t.Errorf("Expected a disabled policy to allow every image, got %v", err)
+		}
+		if err := policy.CheckObject(&v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "Not Valid"}}}}); err != nil {
+			t.Errorf("Expected a disabled policy to allow every pod, got %v", err)
+		}
+	}
+}
+
+func TestImagePolicyCheckPodSpec(t *testing.T) {
+	policy := NewImagePolicy([]string{"registry.internal"})
+	spec := &v1.PodSpec{
+		InitContainers: []v1.Container{{Name: "migrate", Image: "registry.internal/migrate"}},
+		Containers: []v1.Container{
+			{Name: "app", Image: "registry.internal/app:1.0"},
+			{Name: "sidecar", Image: "envoyproxy/envoy:v1.29"},
+		},
+		EphemeralContainers: []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debug", Image: "busybox"}}},
+	}
+
+	errs := policy.CheckPodSpec(spec, field.NewPath("spec"))
+	if len(errs) != 2 {
+		t.Fatalf("Expected the sidecar and debug containers to be rejected, got %v", errs)
+	}
+	if errs[0].Field != "spec.containers[1].image" || errs[0].Type != field.ErrorTypeForbidden || !strings.Contains(errs[0].Detail, `container "sidecar"`) {
+		t.Errorf("Expected the sidecar image to be forbidden, got %+v", errs[0])
+	}
+	if errs[1].Field != "spec.ephemeralContainers[0].image" || !strings.Contains(errs[1].Detail, `container "debug"`) {
+		t.Errorf("Expected the debug image to be forbidden, got %+v", errs[1])
+	}
+
+	spec.Containers[1].Image = "registry.internal/envoy"
+	spec.InitContainers[0].Image = "migrate"
+	if errs := policy.CheckPodSpec(spec, field.NewPath("spec")); len(errs) != 2 || errs[0].Field != "spec.initContainers[0].image" {
+		t.Errorf("Expected the init container to be checked first, got %v", errs)
+	}
+}
+
+func TestImagePolicyCheckObject(t *testing.T) {
+	policy := NewImagePolicy([]string{"registry.internal"})
+	template := v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx"}}}}
+	meta := metav1.ObjectMeta{Name: "web"}
+
+	objects := map[string]struct {
+		check func() error
+		field string
+	}{
+		"Pod": {func() error {
+			return policy.CheckObject(&v1.Pod{ObjectMeta: meta, Spec: template.Spec})
+		}, "spec.containers[0].image"},
+		"Deployment": {func() error {
+			return policy.CheckObject(&appsv1.Deployment{ObjectMeta: meta, Spec: appsv1.DeploymentSpec{Template: template}})
+		}, "spec.template.spec.containers[0].image"},
+		"StatefulSet": {func() error {
+			return policy.CheckObject(&appsv1.StatefulSet{ObjectMeta: meta, Spec: appsv1.StatefulSetSpec{Template: template}})
+		}, "spec.template.spec.containers[0].image"},
+		"DaemonSet": {func() error {
+			return policy.CheckObject(&appsv1.DaemonSet{ObjectMeta: meta, Spec: appsv1.DaemonSetSpec{Template: template}})
+		}, "spec.template.spec.containers[0].image"},
+		"ReplicaSet": {func() error {
+			return policy.CheckObject(&appsv1.ReplicaSet{ObjectMeta: meta, Spec: appsv1.ReplicaSetSpec{Template: template}})
+		}, "spec.template.spec.containers[0].image"},
+		"Job": {func() error {
+			return policy.CheckObject(&batchv1.Job{ObjectMeta: meta, Spec: batchv1.JobSpec{Template: template}})
+		}, "spec.template.spec.containers[0].image"},
+		"CronJob": {func() error {
+			return policy.CheckObject(&batchv1.CronJob{ObjectMeta: meta, Spec: batchv1.CronJobSpec{
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: template}},
+			}})
+		}, "spec.jobTemplate.spec.template.spec.containers[0].image"},
+	}
+	for kind, tt := range objects {
+		err := tt.check()
+		if !apierrors.IsInvalid(err) {
+			t.Errorf("%s: expected an Invalid error, got %v", kind, err)
+			continue
+		}
+		status := err.(apierrors.APIStatus).Status()
+		if status.Details.Kind != kind || status.Details.Name != "web" {
+			t.Errorf("%s: expected the error to name %s web, got %+v", kind, kind, status.Details)
+		}
+		if len(status.Details.Causes) != 1 || status.Details.Causes[0].Field != tt.field {
+			t.Errorf("%s: expected a cause on %s, got %+v", kind, tt.field, status.Details.Causes)
+		}
+		if !strings.Contains(err.Error(), `container "web": image "nginx" is from registry docker.io, allowed registries: registry.internal`) {
+			t.Errorf("%s: expected the container and allowed registries in %q", kind, err.Error())
+		}
+	}
+
+	if err := policy.CheckObject(&v1.ConfigMap{ObjectMeta: meta}); err != nil {
+		t.Errorf("Expected objects without a pod spec to pass, got %v", err)
+	}
+	allowed := &v1.Pod{ObjectMeta: meta, Spec: v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "registry.internal/web"}}}}
+	if err := policy.CheckObject(allowed); err != nil {
+		t.Errorf("Expected an allowed pod to pass, got %v", err)
+	}
+}
+
+func TestImagePolicyCheckManifest(t *testing.T) {
+	policy := NewImagePolicy([]string{"registry.internal"})
+
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: registry.internal/web:1.0
+      - name: proxy
+        image: quay.io/proxy:2
+`
+	err := policy.CheckManifest(deployment)
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), `container "proxy"`) {
+		t.Errorf("Expected the proxy container to be rejected, got %v", err)
+	}
+	if err := policy.CheckManifest(strings.Replace(deployment, "quay.io", "registry.internal", 1)); err != nil {
+		t.Errorf("Expected an allowed deployment to pass, got %v", err)
+	}
+
+	if err := policy.CheckManifest("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"); err != nil {
+		t.Errorf("Expected a configmap to pass, got %v", err)
+	}
+	if err := policy.CheckManifest("not: [a manifest"); err != nil {
+		t.Errorf("Expected undecodable manifests to be left to ApplyYaml, got %v", err)
+	}
+}
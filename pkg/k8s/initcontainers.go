@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// InitContainerState is how far an init container of a pod got
+type InitContainerState string
+
+const (
+	InitContainerPending   InitContainerState = "pending"
+	InitContainerRunning   InitContainerState = "running"
+	InitContainerSucceeded InitContainerState = "succeeded"
+	InitContainerFailed    InitContainerState = "failed"
+)
+
+// InitContainerStep is an init container of a pod as a step of its
+// initialization
+type InitContainerStep struct {
+	Name  string             `json:"name"`
+	State InitContainerState `json:"state"`
+	// Duration is how long the container ran, for those that terminated
+	Duration *time.Duration `json:"duration,omitempty"`
+	// ExitCode is that of a failed container
+	ExitCode int32 `json:"exitCode,omitempty"`
+	// Restartable is set for sidecars, init containers that keep running
+	// next to the app containers. Ready is whether a sidecar is ready.
+	Restartable bool `json:"restartable,omitempty"`
+	Ready       bool `json:"ready,omitempty"`
+}
+
+// sidecarStarted reports whether the kubelet moved on from a sidecar to the
+// next init container
+func sidecarStarted(status v1.ContainerStatus) bool {
+	return status.Started != nil && *status.Started
+}
+
+// GetInitContainerProgress returns the init containers of a pod as the steps
+// of its initialization, in the order the kubelet runs them. A container
+// waiting to be restarted after it failed, as in CrashLoopBackOff, is
+// failed with the exit code of its last run.
+func GetInitContainerProgress(pod v1.Pod) []InitContainerStep {
+	statuses := make(map[string]v1.ContainerStatus, len(pod.Status.InitContainerStatuses))
+	for _, status := range pod.Status.InitContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	steps := make([]InitContainerStep, 0, len(pod.Spec.InitContainers))
+	for _, container := range pod.Spec.InitContainers {
+		step := InitContainerStep{
+			Name:        container.Name,
+			State:       InitContainerPending,
+			Restartable: container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways,
+		}
+		status, ok := statuses[container.Name]
+		switch {
+		case !ok:
+		case status.State.Running != nil:
+			step.State = InitContainerRunning
+			step.Ready = step.Restartable && status.Ready
+		case status.State.Terminated != nil:
+			terminated := status.State.Terminated
+			step.State = InitContainerSucceeded
+			if terminated.ExitCode != 0 {
+				step.State, step.ExitCode = InitContainerFailed, terminated.ExitCode
+			}
+			if !terminated.StartedAt.IsZero() && !terminated.FinishedAt.IsZero() {
+				duration := terminated.FinishedAt.Sub(terminated.StartedAt.Time)
+				step.Duration = &duration
+			}
+		case status.LastTerminationState.Terminated != nil && status.LastTerminationState.Terminated.ExitCode != 0:
+			step.State, step.ExitCode = InitContainerFailed, status.LastTerminationState.Terminated.ExitCode
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// IsInitializing reports whether a pod with init containers has not finished
+// running them
+func IsInitializing(pod v1.Pod) bool {
+	if len(pod.Spec.InitContainers) == 0 {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodInitialized {
+			return condition.Status != v1.ConditionTrue
+		}
+	}
+	// Before the kubelet reports the condition, a pending pod is yet to be
+	// initialized
+	return pod.Status.Phase == v1.PodPending || pod.Status.Phase == ""
+}
+
+// PodStatusReason returns the status of a pod as kubectl shows it while the
+// pod is initializing: "Init:1/2" while its second init container is yet to
+// finish, or the reason the current one is stuck, e.g.
+// "Init:CrashLoopBackOff" or "Init:Error". Sidecars count as finished once
+// started. Other pods get their phase.
+func PodStatusReason(pod v1.Pod) string {
+	restartable := make(map[string]bool, len(pod.Spec.InitContainers))
+	for _, container := range pod.Spec.InitContainers {
+		restartable[container.Name] = container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways
+	}
+
+	for i, status := range pod.Status.InitContainerStatuses {
+		terminated, waiting := status.State.Terminated, status.State.Waiting
+		switch {
+		case terminated != nil && terminated.ExitCode == 0:
+			continue
+		case restartable[status.Name] && sidecarStarted(status):
+			continue
+		case terminated != nil && terminated.Reason != "":
+			return "Init:" + terminated.Reason
+		case terminated != nil && terminated.Signal != 0:
+			return fmt.Sprintf("Init:Signal:%d", terminated.Signal)
+		case terminated != nil:
+			return fmt.Sprintf("Init:ExitCode:%d", terminated.ExitCode)
+		case waiting != nil && waiting.Reason != "" && waiting.Reason != "PodInitializing":
+			return "Init:" + waiting.Reason
+		default:
+			return fmt.Sprintf("Init:%d/%d", i, len(pod.Spec.InitContainers))
+		}
+	}
+	if len(pod.Status.InitContainerStatuses) == 0 && IsInitializing(pod) && pod.Spec.NodeName != "" {
+		return fmt.Sprintf("Init:0/%d", len(pod.Spec.InitContainers))
+	}
+	return string(pod.Status.Phase)
+}
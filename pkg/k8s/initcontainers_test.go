@@ -0,0 +1,214 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetInitContainerProgress(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	terminated := func(exitCode int32, took time.Duration) v1.ContainerState {
+		return v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+			ExitCode:   exitCode,
+			StartedAt:  metav1.NewTime(start),
+			FinishedAt: metav1.NewTime(start.Add(took)),
+		}}
+	}
+	always := v1.ContainerRestartPolicyAlways
+	pod := func(statuses ...v1.ContainerStatus) v1.Pod {
+		return v1.Pod{
+			Spec: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "init-db"}, {Name: "init-config"}, {Name: "proxy", RestartPolicy: &always}},
+				Containers:     []v1.Container{{Name: "app"}},
+			},
+			Status: v1.PodStatus{InitContainerStatuses: statuses},
+		}
+	}
+	seconds := func(s int) *time.Duration {
+		d := time.Duration(s) * time.Second
+		return &d
+	}
+
+	tests := []struct {
+		name string
+		pod  v1.Pod
+		want []InitContainerStep
+	}{
+		{
+			name: "not started",
+			pod:  pod(),
+			want: []InitContainerStep{
+				{Name: "init-db", State: InitContainerPending},
+				{Name: "init-config", State: InitContainerPending},
+				{Name: "proxy", State: InitContainerPending, Restartable: true},
+			},
+		},
+		{
+			name: "second running",
+			pod: pod(
+				v1.ContainerStatus{Name: "init-db", State: terminated(0, 3*time.Second)},
+				v1.ContainerStatus{Name: "init-config", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+				v1.ContainerStatus{Name: "proxy", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+			),
+			want: []InitContainerStep{
+				{Name: "init-db", State: InitContainerSucceeded, Duration: seconds(3)},
+				{Name: "init-config", State: InitContainerRunning},
+				{Name: "proxy", State: InitContainerPending, Restartable: true},
+			},
+		},
+		{
+			name: "failed",
+			pod: pod(
+				v1.ContainerStatus{Name: "init-db", State: terminated(0, 3*time.Second)},
+				v1.ContainerStatus{Name: "init-config", State: terminated(2, 5*time.Second)},
+			),
+			want: []InitContainerStep{
+				{Name: "init-db", State: InitContainerSucceeded, Duration: seconds(3)},
+				{Name: "init-config", State: InitContainerFailed, ExitCode: 2, Duration: seconds(5)},
+				{Name: "proxy", State: InitContainerPending, Restartable: true},
+			},
+		},
+		{
+			name: "crash looping",
+			pod: pod(
+				v1.ContainerStatus{Name: "init-db", State: terminated(0, time.Second)},
+				v1.ContainerStatus{
+					Name:                 "init-config",
+					State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: terminated(137, time.Second),
+				},
+			),
+			want: []InitContainerStep{
+				{Name: "init-db", State: InitContainerSucceeded, Duration: seconds(1)},
+				{Name: "init-config", State: InitContainerFailed, ExitCode: 137},
+				{Name: "proxy", State: InitContainerPending, Restartable: true},
+			},
+		},
+		{
+			name: "sidecar running",
+			pod: pod(
+				v1.ContainerStatus{Name: "init-db", State: terminated(0, time.Second)},
+				v1.ContainerStatus{Name: "init-config", State: terminated(0, 2*time.Second)},
+				v1.ContainerStatus{Name: "proxy", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+			),
+			want: []InitContainerStep{
+				{Name: "init-db", State: InitContainerSucceeded, Duration: seconds(1)},
+				{Name: "init-config", State: InitContainerSucceeded, Duration: seconds(2)},
+				{Name: "proxy", State: InitContainerRunning, Restartable: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetInitContainerProgress(tt.pod)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d steps, got %+v", len(tt.want), got)
+			}
+			for i, step := range got {
+				want := tt.want[i]
+				if step.Name != want.Name || step.State != want.State || step.ExitCode != want.ExitCode || step.Restartable != want.Restartable {
+					t.Errorf("Step %d: expected %+v, got %+v", i, want, step)
+				}
+				if (step.Duration == nil) != (want.Duration == nil) || (step.Duration != nil && *step.Duration != *want.Duration) {
+					t.Errorf("Step %d: expected duration %v, got %v", i, want.Duration, step.Duration)
+				}
+			}
+		})
+	}
+
+	if steps := GetInitContainerProgress(v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}}}}); len(steps) != 0 {
+		t.Errorf("Expected no steps without init containers, got %+v", steps)
+	}
+}
+
+func TestIsInitializing(t *testing.T) {
+	withInit := v1.PodSpec{InitContainers: []v1.Container{{Name: "init-db"}}}
+	tests := []struct {
+		name string
+		pod  v1.Pod
+		want bool
+	}{
+		{"no init containers", v1.Pod{Status: v1.PodStatus{Phase: v1.PodPending}}, false},
+		{"pending without conditions", v1.Pod{Spec: withInit, Status: v1.PodStatus{Phase: v1.PodPending}}, true},
+		{"not initialized", v1.Pod{Spec: withInit, Status: v1.PodStatus{
+			Phase:      v1.PodPending,
+			Conditions: []v1.PodCondition{{Type: v1.PodInitialized, Status: v1.ConditionFalse}},
+		}}, true},
+		{"initialized", v1.Pod{Spec: withInit, Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodInitialized, Status: v1.ConditionTrue}},
+		}}, false},
+	}
+	for _, tt := range tests {
+		if got := IsInitializing(tt.pod); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPodStatusReason(t *testing.T) {
+	always := v1.ContainerRestartPolicyAlways
+	started, notStarted := true, false
+	pod := func(phase v1.PodPhase, statuses ...v1.ContainerStatus) v1.Pod {
+		return v1.Pod{
+			Spec: v1.PodSpec{
+				NodeName:       "node-1",
+				InitContainers: []v1.Container{{Name: "init-db"}, {Name: "proxy", RestartPolicy: &always}},
+				Containers:     []v1.Container{{Name: "app"}},
+			},
+			Status: v1.PodStatus{Phase: phase, InitContainerStatuses: statuses},
+		}
+	}
+	done := v1.ContainerStatus{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}}
+	waiting := func(name, reason string) v1.ContainerStatus {
+		return v1.ContainerStatus{Name: name, State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}}}
+	}
+
+	tests := []struct {
+		name string
+		pod  v1.Pod
+		want string
+	}{
+		{"no init containers", v1.Pod{Status: v1.PodStatus{Phase: v1.PodRunning}}, "Running"},
+		{"scheduled without statuses", pod(v1.PodPending), "Init:0/2"},
+		{"unscheduled", func() v1.Pod { p := pod(v1.PodPending); p.Spec.NodeName = ""; return p }(), "Pending"},
+		{"first running", pod(v1.PodPending,
+			v1.ContainerStatus{Name: "init-db", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+			waiting("proxy", "PodInitializing"),
+		), "Init:0/2"},
+		{"sidecar not started", pod(v1.PodPending,
+			done,
+			v1.ContainerStatus{Name: "proxy", Started: &notStarted, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+		), "Init:1/2"},
+		{"sidecar started", pod(v1.PodRunning,
+			done,
+			v1.ContainerStatus{Name: "proxy", Started: &started, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+		), "Running"},
+		{"crash looping", pod(v1.PodPending,
+			v1.ContainerStatus{
+				Name:                 "init-db",
+				State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+			},
+		), "Init:CrashLoopBackOff"},
+		{"failed", pod(v1.PodPending,
+			v1.ContainerStatus{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}}},
+		), "Init:Error"},
+		{"failed without reason", pod(v1.PodPending,
+			v1.ContainerStatus{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 3}}},
+		), "Init:ExitCode:3"},
+		{"killed", pod(v1.PodPending,
+			v1.ContainerStatus{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 137, Signal: 9}}},
+		), "Init:Signal:9"},
+		{"image pull", pod(v1.PodPending, waiting("init-db", "ImagePullBackOff")), "Init:ImagePullBackOff"},
+	}
+	for _, tt := range tests {
+		if got := PodStatusReason(tt.pod); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The functions in this file translate kgo's mutating actions into the
+// kubectl command doing the same, e.g.
+//
+//	kubectl -n default scale deployment/web --replicas=3
+//
+// They only build strings from the action's parameters and never talk to the
+// cluster. Every argument is quoted for a POSIX shell where needed, so the
+// command can be pasted as is.
+
+// shellQuote returns s as a single shell word, in single quotes unless it
+// only holds characters no shell treats specially
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	for _, r := range s {
+		safe := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,+@%", r)
+		if !safe {
+			return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+		}
+	}
+	return s
+}
+
+// kubectl joins a kubectl command line in a namespace, quoting each argument
+func kubectl(namespace string, args ...string) string {
+	words := []string{"kubectl"}
+	if namespace != "" {
+		words = append(words, "-n", shellQuote(namespace))
+	}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// KubectlCreate returns the kubectl command creating obj in a namespace. Pods
+// with a single container, deployments, services, configmaps and namespaces
+// without labels map to their imperative kubectl commands; anything else is
+// piped to kubectl create -f -.
+func KubectlCreate(namespace string, obj runtime.Object) string {
+	switch o := obj.(type) {
+	case *v1.Pod:
+		if len(o.Spec.Containers) != 1 {
+			break
+		}
+		container := o.Spec.Containers[0]
+		args := []string{"run", o.Name, "--image=" + container.Image}
+		if len(container.Ports) > 0 {
+			args = append(args, fmt.Sprintf("--port=%d", container.Ports[0].ContainerPort))
+		}
+		if len(o.Labels) > 0 {
+			args = append(args, "--labels="+joinLabels(o.Labels))
+		}
+		return kubectl(namespace, args...)
+	case *appsv1.Deployment:
+		containers := o.Spec.Template.Spec.Containers
+		if len(containers) == 0 {
+			break
+		}
+		args := []string{"create", "deployment", o.Name}
+		for _, container := range containers {
+			args = append(args, "--image="+container.Image)
+		}
+		if o.Spec.Replicas != nil {
+			args = append(args, fmt.Sprintf("--replicas=%d", *o.Spec.Replicas))
+		}
+		if len(containers) == 1 && len(containers[0].Ports) > 0 {
+			args = append(args, fmt.Sprintf("--port=%d", containers[0].Ports[0].ContainerPort))
+		}
+		return kubectl(namespace, args...)
+	case *v1.Service:
+		return kubectlCreateService(namespace, o)
+	case *v1.ConfigMap:
+		args := []string{"create", "configmap", o.Name}
+		for _, key := range sortedKeys(o.Data) {
+			args = append(args, "--from-literal="+key+"="+o.Data[key])
+		}
+		binaryKeys := make([]string, 0, len(o.BinaryData))
+		for key := range o.BinaryData {
+			binaryKeys = append(binaryKeys, key)
+		}
+		sort.Strings(binaryKeys)
+		// Binary values cannot be passed as literals; they are read from a
+		// file named after the key
+		for _, key := range binaryKeys {
+			args = append(args, "--from-file="+key+"="+key)
+		}
+		return kubectl(namespace, args...)
+	case *v1.Namespace:
+		// kubectl create namespace cannot set labels
+		if len(o.Labels) == 0 {
+			return kubectl("", "create", "namespace", o.Name)
+		}
+		return KubectlCreateFromManifest("")
+	}
+	return KubectlCreateFromManifest(namespace)
+}
+
+// kubectlCreateService returns the kubectl create service command for a
+// service, falling back to a manifest for types kubectl cannot create
+func kubectlCreateService(namespace string, service *v1.Service) string {
+	serviceType := service.Spec.Type
+	if serviceType == "" {
+		serviceType = v1.ServiceTypeClusterIP
+	}
+
+	args := []string{"create", "service", strings.ToLower(string(serviceType)), service.Name}
+	switch serviceType {
+	case v1.ServiceTypeExternalName:
+		return kubectl(namespace, append(args, "--external-name="+service.Spec.ExternalName)...)
+	case v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer:
+	default:
+		return KubectlCreateFromManifest(namespace)
+	}
+	// kubectl create service always selects app=<name>
+	if len(service.Spec.Selector) > 0 && (len(service.Spec.Selector) != 1 || service.Spec.Selector["app"] != service.Name) {
+		return KubectlCreateFromManifest(namespace)
+	}
+
+	for _, port := range service.Spec.Ports {
+		target := port.TargetPort.String()
+		if port.TargetPort.IntValue() == 0 && port.TargetPort.StrVal == "" {
+			target = fmt.Sprintf("%d", port.Port)
+		}
+		args = append(args, fmt.Sprintf("--tcp=%d:%s", port.Port, target))
+	}
+	if serviceType == v1.ServiceTypeClusterIP && service.Spec.ClusterIP == v1.ClusterIPNone {
+		args = append(args, "--clusterip=None")
+	}
+	return kubectl(namespace, args...)
+}
+
+// KubectlCreateFromManifest returns the kubectl command creating the objects
+// of a manifest read from stdin
+func KubectlCreateFromManifest(namespace string) string {
+	return kubectl(namespace, "create", "-f", "-")
+}
+
+// KubectlReplace returns the kubectl command replacing an object with the
+// manifest read from stdin, as kgo's updates do
+func KubectlReplace(namespace string) string {
+	return kubectl(namespace, "replace", "-f", "-")
+}
+
+// KubectlApply returns the kubectl command applying a manifest read from stdin
+func KubectlApply(namespace string) string {
+	return kubectl(namespace, "apply", "-f", "-")
+}
+
+// KubectlApplyURL returns the kubectl command applying the manifest at a URL
+func KubectlApplyURL(namespace, manifestURL string) string {
+	return kubectl(namespace, "apply", "-f", manifestURL)
+}
+
+// KubectlDelete returns the kubectl command deleting a named object, e.g.
+// resource "deployment" and name "web"
+func KubectlDelete(namespace, resource, name string) string {
+	return kubectl(namespace, "delete", resource, name)
+}
+
+// KubectlRemoveFinalizers returns the kubectl command emptying the
+// finalizers of a named object
+func KubectlRemoveFinalizers(namespace, resource, name string) string {
+	patch := map[string]map[string][]string{"metadata": {"finalizers": {}}}
+	return kubectlMergePatch(namespace, resource, name, patch)
+}
+
+// KubectlDeleteSelector returns the kubectl command deleting every object of a
+// resource matching a label selector
+func KubectlDeleteSelector(namespace, resource, selector string) string {
+	return kubectl(namespace, "delete", resource, "-l", selector)
+}
+
+// KubectlScale returns the kubectl command scaling a workload to replicas
+func KubectlScale(namespace, resource, name string, replicas int32) string {
+	return kubectl(namespace, "scale", resource+"/"+name, fmt.Sprintf("--replicas=%d", replicas))
+}
+
+// KubectlRestart returns the kubectl command restarting a workload's pods
+func KubectlRestart(namespace, resource, name string) string {
+	return kubectl(namespace, "rollout", "restart", resource+"/"+name)
+}
+
+// KubectlPause returns the kubectl command pausing a workload's rollouts
+func KubectlPause(namespace, resource, name string) string {
+	return kubectl(namespace, "rollout", "pause", resource+"/"+name)
+}
+
+// KubectlResume returns the kubectl command resuming a workload's rollouts
+func KubectlResume(namespace, resource, name string) string {
+	return kubectl(namespace, "rollout", "resume", resource+"/"+name)
+}
+
+// KubectlSetImage returns the kubectl command setting the image of a
+// workload's container
+func KubectlSetImage(namespace, resource, name, container, image string) string {
+	return kubectl(namespace, "set", "image", resource+"/"+name, container+"="+image)
+}
+
+// KubectlDebug returns the kubectl command attaching an interactive debug
+// container running image to a pod, sharing the process namespace of
+// targetContainer when given
+func KubectlDebug(namespace, pod, image, targetContainer string, command []string) string {
+	args := []string{"debug", "-it", pod, "--image=" + image}
+	if targetContainer != "" {
+		args = append(args, "--target="+targetContainer)
+	}
+	if len(command) > 0 {
+		args = append(append(args, "--"), command...)
+	}
+	return kubectl(namespace, args...)
+}
+
+// KubectlCopyFromPod returns the kubectl command copying a file or directory
+// of a container to a local path
+func KubectlCopyFromPod(namespace, pod, container, src, dest string) string {
+	return kubectl(namespace, "cp", pod+":"+src, dest, "-c", container)
+}
+
+// KubectlWritePodFile returns the kubectl command replacing a file of a
+// container with the content read from stdin
+func KubectlWritePodFile(namespace, pod, container, file string) string {
+	return kubectl(namespace, "exec", "-i", pod, "-c", container, "--", "tee", file)
+}
+
+// KubectlSetConfigMapKey returns the kubectl command setting one key of a
+// configmap, as a merge patch of data, or of binaryData when binary is set
+func KubectlSetConfigMapKey(namespace, name, key string, value []byte, binary bool) string {
+	patch := map[string]map[string]interface{}{"data": {key: string(value)}}
+	if binary {
+		patch = map[string]map[string]interface{}{"binaryData": {key: base64.StdEncoding.EncodeToString(value)}}
+	}
+	return kubectlMergePatch(namespace, "configmap", name, patch)
+}
+
+// KubectlCreateConfigMapFromData returns the kubectl create configmap command
+// with a --from-literal per literal and a --from-file per file, where each
+// file is named after its key
+func KubectlCreateConfigMapFromData(namespace, name string, literals map[string]string, fileKeys []string) string {
+	args := []string{"create", "configmap", name}
+	for _, key := range sortedKeys(literals) {
+		args = append(args, "--from-literal="+key+"="+literals[key])
+	}
+	fileKeys = append([]string(nil), fileKeys...)
+	sort.Strings(fileKeys)
+	for _, key := range fileKeys {
+		args = append(args, "--from-file="+key)
+	}
+	return kubectl(namespace, args...)
+}
+
+// KubectlDeleteConfigMapKey returns the kubectl command removing one key from
+// a configmap's data and binaryData
+func KubectlDeleteConfigMapKey(namespace, name, key string) string {
+	patch := map[string]map[string]interface{}{"data": {key: nil}, "binaryData": {key: nil}}
+	return kubectlMergePatch(namespace, "configmap", name, patch)
+}
+
+// kubectlMergePatch returns the kubectl patch command applying a JSON merge patch
+func kubectlMergePatch(namespace, resource, name string, patch interface{}) string {
+	// The patch is built from maps of strings, which always marshal
+	data, _ := json.Marshal(patch)
+	return kubectl(namespace, "patch", resource, name, "--type=merge", "-p", string(data))
+}
+
+// KubectlJSONPatch returns the kubectl patch command applying a JSON patch
+func KubectlJSONPatch(namespace, resource, name string, patch []byte) string {
+	return kubectl(namespace, "patch", resource, name, "--type=json", "-p", string(patch))
+}
+
+// KubectlEditMetadata returns the kubectl label or annotate command applying
+// a change to the labels or annotations of an object, e.g.
+//
+//	kubectl -n default label deployment web tier=frontend team- --overwrite
+func KubectlEditMetadata(namespace, resource, name, field string, change MetadataChange) string {
+	verb := "label"
+	if field == AnnotationsField {
+		verb = "annotate"
+	}
+	args := []string{verb, resource, name}
+	for _, key := range sortedKeys(change.Set) {
+		args = append(args, key+"="+change.Set[key])
+	}
+	for _, key := range change.Remove {
+		args = append(args, key+"-")
+	}
+	return kubectl(namespace, append(args, "--overwrite")...)
+}
+
+// KubectlCreateToken returns the kubectl command requesting a token for a
+// service account
+func KubectlCreateToken(namespace, serviceAccount string, expirationSeconds int64, audiences []string) string {
+	args := []string{"create", "token", serviceAccount, fmt.Sprintf("--duration=%ds", expirationSeconds)}
+	for _, audience := range audiences {
+		args = append(args, "--audience="+audience)
+	}
+	return kubectl(namespace, args...)
+}
+
+// joinLabels formats labels as kubectl's key=value,... list, sorted by key
+func joinLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range sortedKeys(labels) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// sortedKeys returns the keys of a string map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,304 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"web", "web"},
+		{"nginx:1.27", "nginx:1.27"},
+		{"--replicas=3", "--replicas=3"},
+		{"app=web,tier=frontend", "app=web,tier=frontend"},
+		{"", "''"},
+		{"my app", "'my app'"},
+		{"app in (web,api)", "'app in (web,api)'"},
+		{"tier!=db", "'tier!=db'"},
+		{"$(rm -rf /)", "'$(rm -rf /)'"},
+		{"it's", `'it'\''s'`},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKubectlCreate(t *testing.T) {
+	replicas := int32(3)
+	tests := []struct {
+		name string
+		obj  runtime.Object
+		want string
+	}{
+		{
+			name: "pod",
+			obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"tier": "frontend", "app": "web"}},
+				Spec: v1.PodSpec{Containers: []v1.Container{{
+					Name: "web", Image: "nginx:1.27", Ports: []v1.ContainerPort{{ContainerPort: 80}},
+				}}},
+			},
+			want: "kubectl -n default run web --image=nginx:1.27 --port=80 --labels=app=web,tier=frontend",
+		},
+		{
+			name: "pod with sidecar",
+			obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Image: "nginx"}, {Image: "envoy"}}},
+			},
+			want: "kubectl -n default create -f -",
+		},
+		{
+			name: "deployment",
+			obj: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+						{Image: "nginx:1.27", Ports: []v1.ContainerPort{{ContainerPort: 8080}}},
+					}}},
+				},
+			},
+			want: "kubectl -n default create deployment web --image=nginx:1.27 --replicas=3 --port=8080",
+		},
+		{
+			name: "clusterip service",
+			obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec: v1.ServiceSpec{Ports: []v1.ServicePort{
+					{Port: 80, TargetPort: intstr.FromInt(8080)},
+					{Port: 443},
+				}},
+			},
+			want: "kubectl -n default create service clusterip web --tcp=80:8080 --tcp=443:443",
+		},
+		{
+			name: "headless service",
+			obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "db"},
+				Spec: v1.ServiceSpec{
+					ClusterIP: v1.ClusterIPNone,
+					Ports:     []v1.ServicePort{{Port: 5432, TargetPort: intstr.FromString("postgres")}},
+				},
+			},
+			want: "kubectl -n default create service clusterip db --tcp=5432:postgres --clusterip=None",
+		},
+		{
+			name: "service selecting other pods than app=<name>",
+			obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec: v1.ServiceSpec{
+					Selector: map[string]string{"app": "web", "tier": "frontend"},
+					Ports:    []v1.ServicePort{{Port: 80}},
+				},
+			},
+			want: "kubectl -n default create -f -",
+		},
+		{
+			name: "externalname service",
+			obj: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "api"},
+				Spec:       v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "api.example.com"},
+			},
+			want: "kubectl -n default create service externalname api --external-name=api.example.com",
+		},
+		{
+			name: "configmap",
+			obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+				Data:       map[string]string{"mode": "on", "greeting": "hello world"},
+				BinaryData: map[string][]byte{"logo.png": {0x89}},
+			},
+			want: "kubectl -n default create configmap settings '--from-literal=greeting=hello world' --from-literal=mode=on --from-file=logo.png=logo.png",
+		},
+		{
+			name: "namespace",
+			obj:  &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+			want: "kubectl create namespace team-a",
+		},
+		{
+			name: "namespace with labels",
+			obj:  &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+			want: "kubectl create -f -",
+		},
+		{
+			name: "secret",
+			obj:  &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token"}},
+			want: "kubectl -n default create -f -",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := KubectlCreate("default", tt.obj)
+			if got != tt.want {
+				t.Errorf("KubectlCreate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubectlDelete(t *testing.T) {
+	if got, want := KubectlDelete("default", "pod", "web"), "kubectl -n default delete pod web"; got != want {
+		t.Errorf("KubectlDelete() = %s, want %s", got, want)
+	}
+	if got, want := KubectlDelete("", "namespace", "team a"), "kubectl delete namespace 'team a'"; got != want {
+		t.Errorf("KubectlDelete() without a namespace = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlDeleteSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"app=web", "kubectl -n default delete pods -l app=web"},
+		{"app=web,tier!=db", "kubectl -n default delete pods -l 'app=web,tier!=db'"},
+		{"env in (prod, staging)", "kubectl -n default delete pods -l 'env in (prod, staging)'"},
+	}
+	for _, tt := range tests {
+		if got := KubectlDeleteSelector("default", "pods", tt.selector); got != tt.want {
+			t.Errorf("KubectlDeleteSelector(%q) = %s, want %s", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestKubectlScale(t *testing.T) {
+	if got, want := KubectlScale("default", "deployment", "web", 3), "kubectl -n default scale deployment/web --replicas=3"; got != want {
+		t.Errorf("KubectlScale() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlRestart(t *testing.T) {
+	if got, want := KubectlRestart("prod", "deployment", "web"), "kubectl -n prod rollout restart deployment/web"; got != want {
+		t.Errorf("KubectlRestart() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlPauseResume(t *testing.T) {
+	if got, want := KubectlPause("prod", "deployment", "web"), "kubectl -n prod rollout pause deployment/web"; got != want {
+		t.Errorf("KubectlPause() = %s, want %s", got, want)
+	}
+	if got, want := KubectlResume("prod", "deployment", "web"), "kubectl -n prod rollout resume deployment/web"; got != want {
+		t.Errorf("KubectlResume() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlSetImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx:1.27", "kubectl -n default set image deployment/web app=nginx:1.27"},
+		{"registry.example.com/team/web@sha256:abc", "kubectl -n default set image deployment/web app=registry.example.com/team/web@sha256:abc"},
+		{"nginx;reboot", "kubectl -n default set image deployment/web 'app=nginx;reboot'"},
+	}
+	for _, tt := range tests {
+		if got := KubectlSetImage("default", "deployment", "web", "app", tt.image); got != tt.want {
+			t.Errorf("KubectlSetImage(%q) = %s, want %s", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestKubectlDebug(t *testing.T) {
+	if got, want := KubectlDebug("default", "web", "busybox:1.36", "", nil), "kubectl -n default debug -it web --image=busybox:1.36"; got != want {
+		t.Errorf("KubectlDebug() = %s, want %s", got, want)
+	}
+	got := KubectlDebug("default", "web", "busybox:1.36", "app", []string{"sh", "-c", "ps aux"})
+	if want := "kubectl -n default debug -it web --image=busybox:1.36 --target=app -- sh -c 'ps aux'"; got != want {
+		t.Errorf("KubectlDebug() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlPodFiles(t *testing.T) {
+	if got, want := KubectlCopyFromPod("default", "web", "app", "/etc/nginx/nginx.conf", "nginx.conf"), "kubectl -n default cp web:/etc/nginx/nginx.conf nginx.conf -c app"; got != want {
+		t.Errorf("KubectlCopyFromPod() = %s, want %s", got, want)
+	}
+	if got, want := KubectlWritePodFile("default", "web", "app", "/srv/my notes.txt"), "kubectl -n default exec -i web -c app -- tee '/srv/my notes.txt'"; got != want {
+		t.Errorf("KubectlWritePodFile() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlEditMetadata(t *testing.T) {
+	change := MetadataChange{Set: map[string]string{"tier": "frontend", "app.kubernetes.io/name": "web"}, Remove: []string{"team"}}
+	want := "kubectl -n default label deployment web app.kubernetes.io/name=web tier=frontend team- --overwrite"
+	if got := KubectlEditMetadata("default", "deployment", "web", LabelsField, change); got != want {
+		t.Errorf("KubectlEditMetadata() = %s, want %s", got, want)
+	}
+	want = "kubectl -n default annotate pod web-1 'note=hello world' --overwrite"
+	if got := KubectlEditMetadata("default", "pod", "web-1", AnnotationsField, MetadataChange{Set: map[string]string{"note": "hello world"}}); got != want {
+		t.Errorf("KubectlEditMetadata() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlCreateConfigMapFromData(t *testing.T) {
+	got := KubectlCreateConfigMapFromData("default", "app", map[string]string{"mode": "on", "greeting": "hello world"}, []string{"logo.png", "app.conf"})
+	want := "kubectl -n default create configmap app '--from-literal=greeting=hello world' --from-literal=mode=on --from-file=app.conf --from-file=logo.png"
+	if got != want {
+		t.Errorf("KubectlCreateConfigMapFromData() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlApply(t *testing.T) {
+	if got, want := KubectlApply("default"), "kubectl -n default apply -f -"; got != want {
+		t.Errorf("KubectlApply() = %s, want %s", got, want)
+	}
+	if got, want := KubectlReplace("default"), "kubectl -n default replace -f -"; got != want {
+		t.Errorf("KubectlReplace() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlConfigMapKey(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "set",
+			got:  KubectlSetConfigMapKey("default", "settings", "mode", []byte("it's on"), false),
+			want: `kubectl -n default patch configmap settings --type=merge -p '{"data":{"mode":"it'\''s on"}}'`,
+		},
+		{
+			name: "set binary",
+			got:  KubectlSetConfigMapKey("default", "settings", "logo.png", []byte{0x89, 'P'}, true),
+			want: `kubectl -n default patch configmap settings --type=merge -p '{"binaryData":{"logo.png":"iVA="}}'`,
+		},
+		{
+			name: "delete",
+			got:  KubectlDeleteConfigMapKey("default", "settings", "mode"),
+			want: `kubectl -n default patch configmap settings --type=merge -p '{"binaryData":{"mode":null},"data":{"mode":null}}'`,
+		},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestKubectlCreateToken(t *testing.T) {
+	got := KubectlCreateToken("default", "builder", 3600, []string{"https://kubernetes.default.svc", "vault"})
+	want := "kubectl -n default create token builder --duration=3600s --audience=https://kubernetes.default.svc --audience=vault"
+	if got != want {
+		t.Errorf("KubectlCreateToken() = %s, want %s", got, want)
+	}
+}
+
+func TestKubectlJSONPatch(t *testing.T) {
+	patch := []byte(`[{"op":"remove","path":"/spec/template/spec/containers/0/livenessProbe"}]`)
+	want := `kubectl -n default patch deployment web --type=json -p '[{"op":"remove","path":"/spec/template/spec/containers/0/livenessProbe"}]'`
+	if got := KubectlJSONPatch("default", "deployment", "web", patch); got != want {
+		t.Errorf("KubectlJSONPatch() = %s, want %s", got, want)
+	}
+}
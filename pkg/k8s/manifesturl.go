@@ -0,0 +1,203 @@
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// maxManifestURLSize bounds the manifests fetched from URLs
+	maxManifestURLSize = 4 << 20
+	// manifestFetchTimeout bounds fetching a manifest, redirects included
+	manifestFetchTimeout = 15 * time.Second
+	// maxManifestRedirects is how many redirects fetching a manifest follows
+	maxManifestRedirects = 5
+)
+
+var (
+	// ErrManifestURLNotAllowed is returned for URLs, redirects included,
+	// whose scheme or host the allowlist does not name
+	ErrManifestURLNotAllowed = errors.New("manifest URL not allowed")
+	// ErrManifestChecksumMismatch is returned when a fetched manifest does
+	// not have the expected SHA-256
+	ErrManifestChecksumMismatch = errors.New("manifest checksum mismatch")
+	// ErrInvalidManifestChecksum is returned for an expected checksum that
+	// is not a hex SHA-256
+	ErrInvalidManifestChecksum = errors.New("invalid manifest checksum")
+	// ErrManifestTooLarge is returned for manifests over the size cap
+	ErrManifestTooLarge = errors.New("manifest too large")
+)
+
+// FetchedManifest is a manifest fetched from a URL
+type FetchedManifest struct {
+	// URL is where the manifest was read, after redirects
+	URL     string
+	Content []byte
+	// SHA256 is the hex SHA-256 of Content
+	SHA256 string
+}
+
+// ManifestFetcher fetches manifests to apply over HTTP(S) from the hosts of
+// an allowlist, so that the REST API cannot be used to reach services inside
+// the cluster network. Without allowed hosts it fetches nothing.
+type ManifestFetcher struct {
+	httpClient *http.Client
+	schemes    map[string]bool
+	hosts      map[string]bool
+	maxSize    int64
+}
+
+// NewManifestFetcher creates a fetcher for URLs on allowedHosts, e.g.
+// "git.internal" for any port of it or "git.internal:8443" for that port
+// only, with one of allowedSchemes, "https" when empty
+func NewManifestFetcher(allowedHosts, allowedSchemes []string) *ManifestFetcher {
+	f := &ManifestFetcher{
+		schemes: make(map[string]bool),
+		hosts:   make(map[string]bool),
+		maxSize: maxManifestURLSize,
+	}
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			f.hosts[host] = true
+		}
+	}
+	if len(allowedSchemes) == 0 {
+		allowedSchemes = []string{"https"}
+	}
+	for _, scheme := range allowedSchemes {
+		f.schemes[strings.ToLower(strings.TrimSpace(scheme))] = true
+	}
+	f.httpClient = &http.Client{
+		Timeout: manifestFetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxManifestRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxManifestRedirects)
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+// Enabled reports whether the fetcher allows any host
+func (f *ManifestFetcher) Enabled() bool {
+	return f != nil && len(f.hosts) > 0
+}
+
+// CheckURL parses a manifest URL and returns why it may not be fetched, or
+// nil
+func (f *ManifestFetcher) CheckURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrManifestURLNotAllowed, err)
+	}
+	return u, f.checkURL(u)
+}
+
+func (f *ManifestFetcher) checkURL(u *url.URL) error {
+	if !f.Enabled() {
+		return fmt.Errorf("%w: no hosts are allowed", ErrManifestURLNotAllowed)
+	}
+	if !f.schemes[strings.ToLower(u.Scheme)] {
+		return fmt.Errorf("%w: scheme %q is not allowed", ErrManifestURLNotAllowed, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URLs are not allowed", ErrManifestURLNotAllowed)
+	}
+	host := strings.ToLower(u.Host)
+	if u.Hostname() == "" || !(f.hosts[host] || f.hosts[strings.ToLower(u.Hostname())]) {
+		return fmt.Errorf("%w: host %q is not allowed", ErrManifestURLNotAllowed, u.Host)
+	}
+	return nil
+}
+
+// Fetch reads the manifest at rawURL, following redirects to allowed URLs
+// only. With a non-empty expected SHA-256 in hex, optionally prefixed
+// "sha256:", a manifest with another checksum is rejected.
+func (f *ManifestFetcher) Fetch(ctx context.Context, rawURL, expectedSHA256 string) (*FetchedManifest, error) {
+	if expectedSHA256 != "" {
+		if _, err := parseManifestChecksum(expectedSHA256); err != nil {
+			return nil, err
+		}
+	}
+	u, err := f.CheckURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		// Redirects to URLs that are not allowed come back wrapped
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && errors.Is(urlErr.Err, ErrManifestURLNotAllowed) {
+			return nil, urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", u.Redacted(), resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", u.Redacted(), err)
+	}
+	if int64(len(content)) > f.maxSize {
+		return nil, fmt.Errorf("%w: %s is over %d bytes", ErrManifestTooLarge, u.Redacted(), f.maxSize)
+	}
+
+	checksum, err := VerifyManifestChecksum(content, expectedSHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &FetchedManifest{URL: resp.Request.URL.Redacted(), Content: content, SHA256: checksum}, nil
+}
+
+// VerifyManifestChecksum returns the hex SHA-256 of content, and
+// ErrManifestChecksumMismatch when expected is set and differs from it
+func VerifyManifestChecksum(content []byte, expected string) (string, error) {
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	if expected == "" {
+		return checksum, nil
+	}
+	want, err := parseManifestChecksum(expected)
+	if err != nil {
+		return checksum, err
+	}
+	if want != checksum {
+		return checksum, fmt.Errorf("%w: expected sha256 %s, got %s", ErrManifestChecksumMismatch, want, checksum)
+	}
+	return checksum, nil
+}
+
+// parseManifestChecksum returns a SHA-256 given in hex, optionally prefixed
+// "sha256:", in lower case
+func parseManifestChecksum(checksum string) (string, error) {
+	checksum = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(checksum), "sha256:"))
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("%w: expected 64 hex digits, got %q", ErrInvalidManifestChecksum, checksum)
+	}
+	return checksum, nil
+}
+
+// IsManifestFetchTimeout reports whether fetching a manifest failed because
+// the server took too long
+func IsManifestFetchTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,154 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Metadata fields edited with PatchMetadata
+const (
+	LabelsField      = "labels"
+	AnnotationsField = "annotations"
+)
+
+// ErrUnsupportedKind is returned by PatchMetadata for a kind it cannot patch
+var ErrUnsupportedKind = errors.New("unsupported kind")
+
+// MetadataChange sets and removes labels or annotations of an object; it is
+// also the body of PATCH /api/v1/:kind/:namespace/:name/labels and
+// .../annotations
+type MetadataChange struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// IsEmpty reports whether the change neither sets nor removes anything
+func (c MetadataChange) IsEmpty() bool {
+	return len(c.Set) == 0 && len(c.Remove) == 0
+}
+
+// DiffMetadata returns the change turning current labels or annotations into
+// desired ones, with the removed keys sorted
+func DiffMetadata(current, desired map[string]string) MetadataChange {
+	var change MetadataChange
+	for key, value := range desired {
+		if old, ok := current[key]; !ok || old != value {
+			if change.Set == nil {
+				change.Set = make(map[string]string)
+			}
+			change.Set[key] = value
+		}
+	}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			change.Remove = append(change.Remove, key)
+		}
+	}
+	sort.Strings(change.Remove)
+	return change
+}
+
+// MetadataMergePatch returns the JSON merge patch applying a change to the
+// labels or annotations of an object. Removed keys are set to null, which a
+// merge patch takes as deleting them; keys the change does not name are left
+// as they are.
+func MetadataMergePatch(field string, change MetadataChange) ([]byte, error) {
+	if field != LabelsField && field != AnnotationsField {
+		return nil, fmt.Errorf("unknown metadata field %q, expected %q or %q", field, LabelsField, AnnotationsField)
+	}
+
+	values := make(map[string]interface{}, len(change.Set)+len(change.Remove))
+	for key, value := range change.Set {
+		values[key] = value
+	}
+	for _, key := range change.Remove {
+		if _, ok := change.Set[key]; ok {
+			return nil, fmt.Errorf("%s key %q is both set and removed", field, key)
+		}
+		values[key] = nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{field: values},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the %s patch: %v", field, err)
+	}
+	return patch, nil
+}
+
+// metadataPatcher applies a merge patch to one object of a kind
+type metadataPatcher func(ctx context.Context, clientset kubernetes.Interface, namespace, name string, patch []byte) (metav1.Object, error)
+
+// metadataPatchers maps the plural kinds PatchMetadata supports to their
+// patchers
+var metadataPatchers = map[string]metadataPatcher{
+	"pods": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.CoreV1().Pods(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"deployments": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.AppsV1().Deployments(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"services": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.CoreV1().Services(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"configmaps": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.CoreV1().ConfigMaps(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"secrets": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.CoreV1().Secrets(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"serviceaccounts": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.CoreV1().ServiceAccounts(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"statefulsets": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.AppsV1().StatefulSets(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"daemonsets": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.AppsV1().DaemonSets(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"jobs": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.BatchV1().Jobs(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"cronjobs": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.BatchV1().CronJobs(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+	"ingresses": func(ctx context.Context, cs kubernetes.Interface, ns, name string, patch []byte) (metav1.Object, error) {
+		return cs.NetworkingV1().Ingresses(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	},
+}
+
+// SupportsMetadataPatch reports whether PatchMetadata can patch a plural kind
+func SupportsMetadataPatch(kind string) bool {
+	_, ok := metadataPatchers[kind]
+	return ok
+}
+
+// PatchMetadata applies a change to the labels or annotations of a
+// namespaced object of a plural kind, e.g. "deployments", and returns the
+// patched object's metadata
+func PatchMetadata(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name, field string, change MetadataChange) (metav1.Object, error) {
+	patcher, ok := metadataPatchers[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedKind, kind)
+	}
+	patch, err := MetadataMergePatch(field, change)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := patcher(ctx, clientset, namespace, name, patch)
+	if err != nil {
+		klog.Errorf("Failed to patch the %s of %s %s in namespace %s: %v", field, kind, name, namespace, err)
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMetadataMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		field  string
+		change MetadataChange
+		want   string
+	}{
+		{
+			name:   "set and remove",
+			field:  LabelsField,
+			change: MetadataChange{Set: map[string]string{"tier": "frontend"}, Remove: []string{"team"}},
+			// null deletes the key, keys not named are kept
+			want: `{"metadata":{"labels":{"team":null,"tier":"frontend"}}}`,
+		},
+		{
+			name:   "annotations",
+			field:  AnnotationsField,
+			change: MetadataChange{Set: map[string]string{"example.com/note": ""}},
+			want:   `{"metadata":{"annotations":{"example.com/note":""}}}`,
+		},
+		{
+			name:  "nothing to change",
+			field: LabelsField,
+			want:  `{"metadata":{"labels":{}}}`,
+		},
+	}
+	for _, tt := range tests {
+		patch, err := MetadataMergePatch(tt.field, tt.change)
+		if err != nil {
+			t.Errorf("%s: MetadataMergePatch failed: %v", tt.name, err)
+			continue
+		}
+		if string(patch) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, patch)
+		}
+	}
+
+	if _, err := MetadataMergePatch(LabelsField, MetadataChange{Set: map[string]string{"team": "a"}, Remove: []string{"team"}}); err == nil {
+		t.Error("Expected an error for a key both set and removed")
+	}
+	if _, err := MetadataMergePatch("finalizers", MetadataChange{Remove: []string{"a"}}); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+func TestDiffMetadata(t *testing.T) {
+	current := map[string]string{"app": "web", "tier": "backend", "team": "a", "old": "x"}
+	desired := map[string]string{"app": "web", "tier": "frontend", "env": "prod"}
+
+	change := DiffMetadata(current, desired)
+	if len(change.Set) != 2 || change.Set["tier"] != "frontend" || change.Set["env"] != "prod" {
+		t.Errorf("Expected tier and env to be set, got %v", change.Set)
+	}
+	if len(change.Remove) != 2 || change.Remove[0] != "old" || change.Remove[1] != "team" {
+		t.Errorf("Expected [old team] to be removed, got %v", change.Remove)
+	}
+	if !DiffMetadata(current, current).IsEmpty() {
+		t.Error("Expected no change between equal maps")
+	}
+}
+
+func TestPatchMetadata(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "web",
+		Namespace: "default",
+		Labels:    map[string]string{"app": "web", "team": "a"},
+	}})
+
+	obj, err := PatchMetadata(context.Background(), clientset, "deployments", "default", "web", LabelsField,
+		MetadataChange{Set: map[string]string{"tier": "frontend"}, Remove: []string{"team"}})
+	if err != nil {
+		t.Fatalf("PatchMetadata failed: %v", err)
+	}
+	labels := obj.GetLabels()
+	if len(labels) != 2 || labels["app"] != "web" || labels["tier"] != "frontend" {
+		t.Errorf("Expected app and tier labels, got %v", labels)
+	}
+
+	if _, err := PatchMetadata(context.Background(), clientset, "nodes", "", "node-1", LabelsField, MetadataChange{}); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("Expected ErrUnsupportedKind, got %v", err)
+	}
+	if _, err := PatchMetadata(context.Background(), clientset, "deployments", "default", "api", LabelsField, MetadataChange{Set: map[string]string{"a": "b"}}); err == nil {
+		t.Error("Expected an error for a missing deployment")
+	}
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lastAppliedAnnotation is the annotation kubectl apply records the applied
+// manifest in
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// GetLastModifiedTime returns when a client last changed obj, as opposed to
+// when it was created or its status last changed. It reads the field
+// managers kept in managedFields:
+//
+//  1. the latest write of the kubectl last-applied-configuration annotation
+//     or of the spec, by any manager not writing the status subresource
+//  2. otherwise the time of the first managedFields entry
+//
+// It returns nil when obj has no managedFields with a time.
+func GetLastModifiedTime(obj metav1.Object) *time.Time {
+	managedFields := obj.GetManagedFields()
+
+	var latest *time.Time
+	for _, entry := range managedFields {
+		if entry.Time == nil || entry.Subresource != "" {
+			continue
+		}
+		if !managesSpec(entry) {
+			continue
+		}
+		if latest == nil || entry.Time.After(*latest) {
+			modified := entry.Time.Time
+			latest = &modified
+		}
+	}
+	if latest != nil {
+		return latest
+	}
+
+	if len(managedFields) > 0 && managedFields[0].Time != nil {
+		modified := managedFields[0].Time.Time
+		return &modified
+	}
+	return nil
+}
+
+// managesSpec reports whether a managedFields entry owns the spec, or the
+// last-applied-configuration annotation kubectl apply writes with it
+func managesSpec(entry metav1.ManagedFieldsEntry) bool {
+	if entry.FieldsV1 == nil {
+		return false
+	}
+
+	var fields struct {
+		Spec     json.RawMessage `json:"f:spec"`
+		Metadata struct {
+			Annotations map[string]json.RawMessage `json:"f:annotations"`
+		} `json:"f:metadata"`
+	}
+	if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+		return false
+	}
+	if fields.Spec != nil {
+		return true
+	}
+	_, ok := fields.Metadata.Annotations["f:"+lastAppliedAnnotation]
+	return ok
+}
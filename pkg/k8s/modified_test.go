@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// managedFieldsEntry returns a managedFields entry of a manager at t owning
+// the given fieldsV1 JSON
+func managedFieldsEntry(manager, subresource string, t time.Time, fields string) metav1.ManagedFieldsEntry {
+	at := metav1.NewTime(t)
+	return metav1.ManagedFieldsEntry{
+		Manager:     manager,
+		Operation:   metav1.ManagedFieldsOperationUpdate,
+		Subresource: subresource,
+		Time:        &at,
+		FieldsType:  "FieldsV1",
+		FieldsV1:    &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func TestGetLastModifiedTime(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	applied := created.Add(time.Hour)
+	scaled := created.Add(2 * time.Hour)
+	status := created.Add(3 * time.Hour)
+
+	const (
+		specFields        = `{"f:spec":{"f:replicas":{}}}`
+		lastAppliedFields = `{"f:metadata":{"f:annotations":{".":{},"f:kubectl.kubernetes.io/last-applied-configuration":{}}}}`
+		labelFields       = `{"f:metadata":{"f:labels":{"f:app":{}}}}`
+		statusFields      = `{"f:status":{"f:phase":{}}}`
+	)
+
+	tests := []struct {
+		name          string
+		managedFields []metav1.ManagedFieldsEntry
+		want          *time.Time
+	}{
+		{
+			name: "last-applied annotation",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kube-controller-manager", "", created, labelFields),
+				managedFieldsEntry("kubectl-client-side-apply", "", applied, lastAppliedFields),
+			},
+			want: &applied,
+		},
+		{
+			name: "spec written after the last apply",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kubectl-client-side-apply", "", applied, lastAppliedFields),
+				managedFieldsEntry("kubectl", "", scaled, specFields),
+			},
+			want: &scaled,
+		},
+		{
+			name: "status updates are not modifications",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kgo", "", applied, specFields),
+				managedFieldsEntry("kubelet", "status", status, statusFields),
+			},
+			want: &applied,
+		},
+		{
+			name: "first entry without spec or annotation",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kgo", "", created, `{"f:data":{"f:key":{}}}`),
+				managedFieldsEntry("kubelet", "status", status, statusFields),
+			},
+			want: &created,
+		},
+		{
+			name: "unparsable fields fall back to the first entry",
+			managedFields: []metav1.ManagedFieldsEntry{
+				managedFieldsEntry("kgo", "", applied, `not json`),
+			},
+			want: &applied,
+		},
+		{
+			name: "no managed fields",
+		},
+		{
+			name:          "entry without a time",
+			managedFields: []metav1.ManagedFieldsEntry{{Manager: "kgo", FieldsV1: &metav1.FieldsV1{Raw: []byte(specFields)}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:              "web",
+				CreationTimestamp: metav1.NewTime(created),
+				ManagedFields:     tt.managedFields,
+			}}
+			got := GetLastModifiedTime(pod)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("Expected no modification time, got %v", *got)
+			case tt.want != nil && (got == nil || !got.Equal(*tt.want)):
+				t.Errorf("Expected %v, got %v", *tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// NamespaceBlocker is a resource type, or a finalizer, that the namespace
+// controller reports as still having instances in a terminating namespace
+type NamespaceBlocker struct {
+	// Resource is set for remaining content, e.g. "pods" or "widgets.example.com"
+	Resource string `json:"resource,omitempty"`
+	// Finalizer is set for remaining content that holds this finalizer
+	Finalizer string `json:"finalizer,omitempty"`
+	Instances int    `json:"instances"`
+}
+
+// NamespaceTermination describes a namespace that is being deleted
+type NamespaceTermination struct {
+	DeletionTimestamp metav1.Time `json:"deletionTimestamp"`
+	// Seconds is how long the namespace has been terminating
+	Seconds  int64              `json:"seconds"`
+	Blockers []NamespaceBlocker `json:"blockers"`
+	// Failures holds the messages of deletion conditions reporting that the
+	// controller itself failed, e.g. to discover an API group
+	Failures []string `json:"failures,omitempty"`
+}
+
+var (
+	// The namespace controller reports remaining content as
+	// "Some resources are remaining: pods has 2 resource instances, ..."
+	remainingResourcePattern = regexp.MustCompile(`^(\S+) has (\d+) resource instances$`)
+	// and remaining finalizers as "Some content in the namespace has
+	// finalizers remaining: example.com/cleanup in 1 resource instances, ..."
+	remainingFinalizerPattern = regexp.MustCompile(`^(\S+) in (\d+) resource instances$`)
+)
+
+// NamespaceTerminationStatus returns how long a namespace has been terminating
+// at now and what its conditions say is blocking the deletion, or nil when the
+// namespace is not being deleted
+func NamespaceTerminationStatus(namespace *v1.Namespace, now time.Time) *NamespaceTermination {
+	if namespace.DeletionTimestamp == nil {
+		return nil
+	}
+
+	termination := &NamespaceTermination{
+		DeletionTimestamp: *namespace.DeletionTimestamp,
+		Seconds:           int64(now.Sub(namespace.DeletionTimestamp.Time).Seconds()),
+		Blockers:          []NamespaceBlocker{},
+	}
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case v1.NamespaceContentRemaining:
+			for _, match := range matchConditionItems(condition.Message, remainingResourcePattern) {
+				termination.Blockers = append(termination.Blockers, NamespaceBlocker{Resource: match.name, Instances: match.count})
+			}
+		case v1.NamespaceFinalizersRemaining:
+			for _, match := range matchConditionItems(condition.Message, remainingFinalizerPattern) {
+				termination.Blockers = append(termination.Blockers, NamespaceBlocker{Finalizer: match.name, Instances: match.count})
+			}
+		case v1.NamespaceDeletionDiscoveryFailure, v1.NamespaceDeletionGVParsingFailure, v1.NamespaceDeletionContentFailure:
+			termination.Failures = append(termination.Failures, condition.Message)
+		}
+	}
+	return termination
+}
+
+// conditionItem is one "<name> ... <count> resource instances" entry of a
+// namespace condition message
+type conditionItem struct {
+	name  string
+	count int
+}
+
+// matchConditionItems parses the comma separated entries after the colon of a
+// namespace condition message, skipping entries that do not match pattern
+func matchConditionItems(message string, pattern *regexp.Regexp) []conditionItem {
+	_, list, found := strings.Cut(message, ": ")
+	if !found {
+		return nil
+	}
+
+	var items []conditionItem
+	for _, entry := range strings.Split(list, ", ") {
+		match := pattern.FindStringSubmatch(strings.TrimSpace(entry))
+		if match == nil {
+			continue
+		}
+		count, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		items = append(items, conditionItem{name: match[1], count: count})
+	}
+	return items
+}
+
+// RemainingObject is an object left in a namespace
+type RemainingObject struct {
+	Name              string       `json:"name"`
+	Finalizers        []string     `json:"finalizers,omitempty"`
+	DeletionTimestamp *metav1.Time `json:"deletionTimestamp,omitempty"`
+}
+
+// RemainingKind groups the remaining objects of one resource type
+type RemainingKind struct {
+	Group    string            `json:"group"`
+	Version  string            `json:"version"`
+	Resource string            `json:"resource"`
+	Kind     string            `json:"kind"`
+	Objects  []RemainingObject `json:"objects"`
+}
+
+// FinalizerReport lists everything left in a namespace, grouped by kind
+type FinalizerReport struct {
+	Namespace string            `json:"namespace"`
+	Phase     v1.NamespacePhase `json:"phase"`
+	// Finalizers are the namespace's own spec.finalizers
+	Finalizers  []v1.FinalizerName    `json:"finalizers"`
+	Termination *NamespaceTermination `json:"termination,omitempty"`
+	Kinds       []RemainingKind       `json:"kinds"`
+	// Errors records resource types that could not be discovered or listed,
+	// so that an incomplete report is not mistaken for an empty namespace
+	Errors []string `json:"errors,omitempty"`
+}
+
+// NamespaceFinalizerReport enumerates every listable object left in a
+// namespace, discovering the namespaced resource types the server serves and
+// listing each through the dynamic client
+func NamespaceFinalizerReport(clientset kubernetes.Interface, dynamicClient dynamic.Interface, name string) (*FinalizerReport, error) {
+	namespace, err := clientset.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get namespace %s: %v", name, err)
+		return nil, err
+	}
+
+	report := &FinalizerReport{
+		Namespace:   name,
+		Phase:       namespace.Status.Phase,
+		Finalizers:  namespace.Spec.Finalizers,
+		Termination: NamespaceTerminationStatus(namespace, time.Now()),
+		Kinds:       []RemainingKind{},
+	}
+
+	resourceLists, err := discovery.ServerPreferredNamespacedResources(clientset.Discovery())
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			klog.Errorf("Failed to discover namespaced resources: %v", err)
+			return nil, err
+		}
+		// Some aggregated APIs are down; report on the rest
+		report.Errors = append(report.Errors, err.Error())
+	}
+	resourceLists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, resourceLists)
+
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			gvr := gv.WithResource(resource.Name)
+			list, err := dynamicClient.Resource(gvr).Namespace(name).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", gvr.GroupResource(), err))
+				continue
+			}
+			if len(list.Items) == 0 {
+				continue
+			}
+
+			kind := RemainingKind{Group: gv.Group, Version: gv.Version, Resource: resource.Name, Kind: resource.Kind}
+			for _, item := range list.Items {
+				kind.Objects = append(kind.Objects, RemainingObject{
+					Name:              item.GetName(),
+					Finalizers:        item.GetFinalizers(),
+					DeletionTimestamp: item.GetDeletionTimestamp(),
+				})
+			}
+			sort.Slice(kind.Objects, func(i, j int) bool { return kind.Objects[i].Name < kind.Objects[j].Name })
+			report.Kinds = append(report.Kinds, kind)
+		}
+	}
+
+	sort.Slice(report.Kinds, func(i, j int) bool {
+		if report.Kinds[i].Group != report.Kinds[j].Group {
+			return report.Kinds[i].Group < report.Kinds[j].Group
+		}
+		return report.Kinds[i].Resource < report.Kinds[j].Resource
+	})
+	return report, nil
+}
@@ -0,0 +1,155 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// terminatingNamespace is a namespace deleted two hours before now whose
+// deletion is held up by a pod and a widget with a finalizer
+func terminatingNamespace(now time.Time) *v1.Namespace {
+	deleted := metav1.NewTime(now.Add(-2 * time.Hour))
+	return &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", DeletionTimestamp: &deleted},
+		Spec:       v1.NamespaceSpec{Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes}},
+		Status: v1.NamespaceStatus{
+			Phase: v1.NamespaceTerminating,
+			Conditions: []v1.NamespaceCondition{
+				{
+					Type:    v1.NamespaceDeletionDiscoveryFailure,
+					Status:  v1.ConditionFalse,
+					Message: "All resources successfully discovered",
+				},
+				{
+					Type:    v1.NamespaceContentRemaining,
+					Status:  v1.ConditionTrue,
+					Message: "Some resources are remaining: pods has 1 resource instances, widgets.example.com has 2 resource instances",
+				},
+				{
+					Type:    v1.NamespaceFinalizersRemaining,
+					Status:  v1.ConditionTrue,
+					Message: "Some content in the namespace has finalizers remaining: example.com/cleanup in 2 resource instances",
+				},
+			},
+		},
+	}
+}
+
+func TestNamespaceTerminationStatus(t *testing.T) {
+	now := time.Now()
+
+	if status := NamespaceTerminationStatus(&v1.Namespace{}, now); status != nil {
+		t.Errorf("Expected nil for an active namespace, got %+v", status)
+	}
+
+	status := NamespaceTerminationStatus(terminatingNamespace(now), now)
+	if status == nil {
+		t.Fatal("Expected a termination status")
+	}
+	if status.Seconds != 7200 {
+		t.Errorf("Expected 7200 seconds, got %d", status.Seconds)
+	}
+	want := []NamespaceBlocker{
+		{Resource: "pods", Instances: 1},
+		{Resource: "widgets.example.com", Instances: 2},
+		{Finalizer: "example.com/cleanup", Instances: 2},
+	}
+	if len(status.Blockers) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, status.Blockers)
+	}
+	for i := range want {
+		if status.Blockers[i] != want[i] {
+			t.Errorf("Blocker %d: expected %+v, got %+v", i, want[i], status.Blockers[i])
+		}
+	}
+	if len(status.Failures) != 0 {
+		t.Errorf("Expected no failures from a false condition, got %v", status.Failures)
+	}
+}
+
+func TestNamespaceFinalizerReport(t *testing.T) {
+	namespace := terminatingNamespace(time.Now())
+	clientset := fake.NewSimpleClientset(namespace)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: []string{"create"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"list", "delete"}},
+			},
+		},
+	}
+
+	widget := func(namespace, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("example.com/v1")
+		u.SetKind("Widget")
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetFinalizers([]string{"example.com/cleanup"})
+		return u
+	}
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme,
+		map[schema.GroupVersionResource]string{
+			{Group: "example.com", Version: "v1", Resource: "widgets"}: "WidgetList",
+		},
+		widget("stuck", "b"),
+		widget("stuck", "a"),
+		// Objects in other namespaces must not be reported
+		widget("default", "elsewhere"),
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "stuck"}},
+	)
+
+	report, err := NamespaceFinalizerReport(clientset, dynamicClient, "stuck")
+	if err != nil {
+		t.Fatalf("NamespaceFinalizerReport failed: %v", err)
+	}
+
+	if report.Phase != v1.NamespaceTerminating || report.Termination == nil {
+		t.Errorf("Expected a terminating namespace, got phase %q and %+v", report.Phase, report.Termination)
+	}
+	if len(report.Finalizers) != 1 || report.Finalizers[0] != v1.FinalizerKubernetes {
+		t.Errorf("Expected the kubernetes finalizer, got %v", report.Finalizers)
+	}
+	if len(report.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", report.Errors)
+	}
+	if len(report.Kinds) != 2 {
+		t.Fatalf("Expected pods and widgets, got %+v", report.Kinds)
+	}
+
+	pods, widgetKind := report.Kinds[0], report.Kinds[1]
+	if pods.Kind != "Pod" || len(pods.Objects) != 1 || pods.Objects[0].Name != "web" {
+		t.Errorf("Unexpected pods %+v", pods)
+	}
+	if widgetKind.Group != "example.com" || widgetKind.Kind != "Widget" || len(widgetKind.Objects) != 2 {
+		t.Fatalf("Unexpected widgets %+v", widgetKind)
+	}
+	if widgetKind.Objects[0].Name != "a" || widgetKind.Objects[0].Finalizers[0] != "example.com/cleanup" {
+		t.Errorf("Expected widgets sorted by name with their finalizers, got %+v", widgetKind.Objects)
+	}
+}
+
+func TestNamespaceFinalizerReportMissingNamespace(t *testing.T) {
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(scheme.Scheme)
+	if _, err := NamespaceFinalizerReport(fake.NewSimpleClientset(), dynamicClient, "missing"); err == nil {
+		t.Error("Expected an error for a missing namespace")
+	}
+}
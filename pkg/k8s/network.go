@@ -0,0 +1,256 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// ClusterDomain is the DNS domain of services in PodNetwork, the kubelet's
+// default
+const ClusterDomain = "cluster.local"
+
+// Endpoint states of a pod in a service's endpoints
+const (
+	EndpointReady    = "ready"
+	EndpointNotReady = "notReady"
+	EndpointMissing  = "missing"
+)
+
+// NetworkContainerPort is a port declared by a container of a pod
+type NetworkContainerPort struct {
+	Container string      `json:"container"`
+	Name      string      `json:"name,omitempty"`
+	Port      int32       `json:"port"`
+	Protocol  v1.Protocol `json:"protocol"`
+}
+
+// NetworkServicePort is a port of a service selecting a pod, and whether its
+// target port is one the pod's containers declare
+type NetworkServicePort struct {
+	Name     string      `json:"name,omitempty"`
+	Port     int32       `json:"port"`
+	Protocol v1.Protocol `json:"protocol"`
+	// TargetPort is the number or container port name the service sends to
+	TargetPort string `json:"targetPort"`
+	// Declared is false when no container port matches TargetPort. A
+	// numeric target can still be served by a container that does not
+	// declare its port; a named one cannot.
+	Declared bool `json:"declared"`
+}
+
+// NetworkService is a service sending traffic to a pod, through its selector
+// or through endpoints naming the pod
+type NetworkService struct {
+	Name      string               `json:"name"`
+	Type      v1.ServiceType       `json:"type"`
+	ClusterIP string               `json:"clusterIP,omitempty"`
+	DNSName   string               `json:"dnsName"`
+	Ports     []NetworkServicePort `json:"ports"`
+	// Endpoint is whether the pod is among the service's ready or not ready
+	// addresses, or missing from its endpoints
+	Endpoint string `json:"endpoint"`
+}
+
+// PodNetwork gathers the networking facts of a pod, from the pod and the
+// services and endpoints of its namespace; nothing is probed
+type PodNetwork struct {
+	Name           string                 `json:"name"`
+	Namespace      string                 `json:"namespace"`
+	PodIPs         []string               `json:"podIPs"`
+	HostNetwork    bool                   `json:"hostNetwork"`
+	NodeName       string                 `json:"nodeName,omitempty"`
+	NodeIP         string                 `json:"nodeIP,omitempty"`
+	ContainerPorts []NetworkContainerPort `json:"containerPorts"`
+	Services       []NetworkService       `json:"services"`
+	// Mismatches describes the service ports targeting a port no container
+	// declares
+	Mismatches []string `json:"mismatches"`
+}
+
+// AnalyzePodNetwork joins a pod with the services and endpoints of its
+// namespace: the services whose selector matches the pod or whose endpoints
+// name it, with their ports checked against the pod's container ports.
+// Services and endpoints of other namespaces are ignored.
+func AnalyzePodNetwork(pod *v1.Pod, services []v1.Service, endpoints []v1.Endpoints) PodNetwork {
+	network := PodNetwork{
+		Name:           pod.Name,
+		Namespace:      pod.Namespace,
+		PodIPs:         []string{},
+		HostNetwork:    pod.Spec.HostNetwork,
+		NodeName:       pod.Spec.NodeName,
+		NodeIP:         pod.Status.HostIP,
+		ContainerPorts: []NetworkContainerPort{},
+		Services:       []NetworkService{},
+		Mismatches:     []string{},
+	}
+	for _, ip := range pod.Status.PodIPs {
+		network.PodIPs = append(network.PodIPs, ip.IP)
+	}
+	if len(network.PodIPs) == 0 && pod.Status.PodIP != "" {
+		network.PodIPs = append(network.PodIPs, pod.Status.PodIP)
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			network.ContainerPorts = append(network.ContainerPorts, NetworkContainerPort{
+				Container: container.Name,
+				Name:      port.Name,
+				Port:      port.ContainerPort,
+				Protocol:  protocolOrTCP(port.Protocol),
+			})
+		}
+	}
+
+	endpointStates := make(map[string]string)
+	for i := range endpoints {
+		if endpoints[i].Namespace == pod.Namespace {
+			endpointStates[endpoints[i].Name] = podEndpointState(pod, &endpoints[i])
+		}
+	}
+
+	for _, svc := range services {
+		if svc.Namespace != pod.Namespace {
+			continue
+		}
+		state, listed := endpointStates[svc.Name]
+		if !listed {
+			state = EndpointMissing
+		}
+		if !ServiceSelectsPod(svc, *pod) && state == EndpointMissing {
+			continue
+		}
+
+		service := NetworkService{
+			Name:      svc.Name,
+			Type:      svc.Spec.Type,
+			ClusterIP: svc.Spec.ClusterIP,
+			DNSName:   fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.Namespace, ClusterDomain),
+			Ports:     []NetworkServicePort{},
+			Endpoint:  state,
+		}
+		for _, port := range svc.Spec.Ports {
+			target := port.TargetPort
+			if target.Type == intstr.Int && target.IntVal == 0 && target.StrVal == "" {
+				// An unset target port is the service port
+				target = intstr.FromInt32(port.Port)
+			}
+			servicePort := NetworkServicePort{
+				Name:       port.Name,
+				Port:       port.Port,
+				Protocol:   protocolOrTCP(port.Protocol),
+				TargetPort: target.String(),
+				Declared:   declaresPort(network.ContainerPorts, target, protocolOrTCP(port.Protocol)),
+			}
+			if !servicePort.Declared {
+				network.Mismatches = append(network.Mismatches, fmt.Sprintf("service %s port %d targets %s/%s, which no container declares",
+					svc.Name, port.Port, servicePort.TargetPort, servicePort.Protocol))
+			}
+			service.Ports = append(service.Ports, servicePort)
+		}
+		network.Services = append(network.Services, service)
+	}
+	sort.Slice(network.Services, func(i, j int) bool { return network.Services[i].Name < network.Services[j].Name })
+	return network
+}
+
+// ServiceSelectsPod reports whether the selector of a service matches a pod
+// of its namespace. A service without a selector selects no pods; its
+// endpoints are managed by hand.
+func ServiceSelectsPod(svc v1.Service, pod v1.Pod) bool {
+	return svc.Namespace == pod.Namespace && len(svc.Spec.Selector) > 0 &&
+		labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels))
+}
+
+// FindServicesForPod returns the services whose selector matches a pod, in
+// the order given
+func FindServicesForPod(pod v1.Pod, services []v1.Service) []v1.Service {
+	var selecting []v1.Service
+	for _, svc := range services {
+		if ServiceSelectsPod(svc, pod) {
+			selecting = append(selecting, svc)
+		}
+	}
+	return selecting
+}
+
+// protocolOrTCP returns a port protocol, defaulting to TCP like the API
+// server does
+func protocolOrTCP(protocol v1.Protocol) v1.Protocol {
+	if protocol == "" {
+		return v1.ProtocolTCP
+	}
+	return protocol
+}
+
+// declaresPort reports whether a container port matches a service target
+// port: by number, or by name for a named target, with the same protocol
+func declaresPort(ports []NetworkContainerPort, target intstr.IntOrString, protocol v1.Protocol) bool {
+	for _, port := range ports {
+		if port.Protocol != protocol {
+			continue
+		}
+		if target.Type == intstr.String && port.Name == target.StrVal || target.Type == intstr.Int && port.Port == target.IntVal {
+			return true
+		}
+	}
+	return false
+}
+
+// podEndpointState returns whether a pod is a ready or not ready address of
+// an endpoints object, or missing from it
+func podEndpointState(pod *v1.Pod, endpoints *v1.Endpoints) string {
+	isPod := func(address v1.EndpointAddress) bool {
+		return address.TargetRef != nil && address.TargetRef.Kind == "Pod" && address.TargetRef.Name == pod.Name &&
+			(address.TargetRef.Namespace == "" || address.TargetRef.Namespace == pod.Namespace)
+	}
+	state := EndpointMissing
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if isPod(address) {
+				return EndpointReady
+			}
+		}
+		for _, address := range subset.NotReadyAddresses {
+			if isPod(address) {
+				state = EndpointNotReady
+			}
+		}
+	}
+	return state
+}
+
+// GetPodNetwork returns the networking facts of a pod, from the services and
+// endpoints of its namespace
+func GetPodNetwork(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*PodNetwork, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s/%s: %v", namespace, name, err)
+		return nil, err
+	}
+	return DescribePodNetwork(ctx, clientset, pod)
+}
+
+// DescribePodNetwork lists the services and endpoints of the namespace of a
+// pod already at hand and joins them with it
+func DescribePodNetwork(ctx context.Context, clientset kubernetes.Interface, pod *v1.Pod) (*PodNetwork, error) {
+	services, err := clientset.CoreV1().Services(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list services in namespace %s: %v", pod.Namespace, err)
+		return nil, err
+	}
+	endpoints, err := clientset.CoreV1().Endpoints(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list endpoints in namespace %s: %v", pod.Namespace, err)
+		return nil, err
+	}
+
+	network := AnalyzePodNetwork(pod, services.Items, endpoints.Items)
+	return &network, nil
+}
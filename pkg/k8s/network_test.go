@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAnalyzePodNetwork(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web", "tier": "frontend"}},
+		Spec: v1.PodSpec{
+			NodeName: "node-a",
+			Containers: []v1.Container{
+				{Name: "web", Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+				{Name: "metrics", Ports: []v1.ContainerPort{{ContainerPort: 9090}, {Name: "dns", ContainerPort: 53, Protocol: v1.ProtocolUDP}}},
+			},
+		},
+		Status: v1.PodStatus{
+			HostIP: "192.168.1.10",
+			PodIP:  "10.0.0.5",
+			PodIPs: []v1.PodIP{{IP: "10.0.0.5"}, {IP: "fd00::5"}},
+		},
+	}
+	service := func(namespace, name string, selector map[string]string, ports ...v1.ServicePort) v1.Service {
+		return v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "10.96.0.1", Selector: selector, Ports: ports},
+		}
+	}
+	services := []v1.Service{
+		// Named, numeric and defaulted target ports the pod declares
+		service("shop", "web", map[string]string{"app": "web"},
+			v1.ServicePort{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+			v1.ServicePort{Name: "metrics", Port: 9090}),
+		// A wrong port, a missing name, and a declared port of another protocol
+		service("shop", "broken", map[string]string{"tier": "frontend"},
+			v1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8081)},
+			v1.ServicePort{Port: 443, TargetPort: intstr.FromString("https")},
+			v1.ServicePort{Port: 53, TargetPort: intstr.FromInt32(53)}),
+		// Selecting other pods, or pods in another namespace
+		service("shop", "api", map[string]string{"app": "api"}, v1.ServicePort{Port: 80}),
+		service("other", "web", map[string]string{"app": "web"}, v1.ServicePort{Port: 80}),
+		// Without a selector, but with endpoints naming the pod
+		service("shop", "manual", nil, v1.ServicePort{Port: 8080}),
+	}
+	address := func(name string) v1.EndpointAddress {
+		return v1.EndpointAddress{IP: "10.0.0.5", TargetRef: &v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: name}}
+	}
+	endpoints := []v1.Endpoints{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Subsets: []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{address("web-1")}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "shop"}, Subsets: []v1.EndpointSubset{{NotReadyAddresses: []v1.EndpointAddress{address("web-1")}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"}, Subsets: []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{address("api-1")}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "manual", Namespace: "shop"}, Subsets: []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{address("web-1")}}}},
+	}
+
+	network := AnalyzePodNetwork(pod, services, endpoints)
+
+	if strings.Join(network.PodIPs, ",") != "10.0.0.5,fd00::5" || network.NodeIP != "192.168.1.10" || network.NodeName != "node-a" || network.HostNetwork {
+		t.Errorf("Unexpected addresses: %+v", network)
+	}
+	if len(network.ContainerPorts) != 3 || network.ContainerPorts[1].Protocol != v1.ProtocolTCP || network.ContainerPorts[2].Container != "metrics" {
+		t.Errorf("Expected 3 container ports defaulting to TCP, got %+v", network.ContainerPorts)
+	}
+
+	var names []string
+	for _, svc := range network.Services {
+		names = append(names, svc.Name+":"+svc.Endpoint)
+	}
+	if got := strings.Join(names, ","); got != "broken:notReady,manual:ready,web:ready" {
+		t.Fatalf("Expected the selecting services and the one with endpoints, got %s", got)
+	}
+	web := network.Services[2]
+	if web.DNSName != "web.shop.svc.cluster.local" || len(web.Ports) != 2 || !web.Ports[0].Declared || !web.Ports[1].Declared || web.Ports[1].TargetPort != "9090" {
+		t.Errorf("Expected both web ports declared, got %+v", web)
+	}
+	broken := network.Services[0]
+	for i, port := range broken.Ports {
+		if port.Declared {
+			t.Errorf("Expected broken port %d to be flagged, got %+v", i, port)
+		}
+	}
+
+	want := []string{
+		"service broken port 80 targets 8081/TCP, which no container declares",
+		"service broken port 443 targets https/TCP, which no container declares",
+		"service broken port 53 targets 53/TCP, which no container declares",
+	}
+	if strings.Join(network.Mismatches, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected mismatches:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(network.Mismatches, "\n"))
+	}
+}
+
+func TestAnalyzePodNetworkHostNetwork(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+		Spec:       v1.PodSpec{HostNetwork: true},
+		Status:     v1.PodStatus{HostIP: "192.168.1.10", PodIP: "192.168.1.10"},
+	}
+	network := AnalyzePodNetwork(pod, nil, nil)
+	if !network.HostNetwork || len(network.PodIPs) != 1 || network.PodIPs[0] != "192.168.1.10" {
+		t.Errorf("Expected a host network pod on the node's IP, got %+v", network)
+	}
+	if network.Services == nil || network.Mismatches == nil || network.ContainerPorts == nil {
+		t.Errorf("Expected empty, non-nil lists, got %+v", network)
+	}
+}
+
+func TestFindServicesForPod(t *testing.T) {
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web", "tier": "frontend"}}}
+	service := func(namespace, name string, selector map[string]string) v1.Service {
+		return v1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: v1.ServiceSpec{Selector: selector}}
+	}
+	services := []v1.Service{
+		service("shop", "web", map[string]string{"app": "web"}),
+		service("shop", "api", map[string]string{"app": "api"}),
+		service("shop", "frontend", map[string]string{"app": "web", "tier": "frontend"}),
+		service("shop", "external", nil),
+		service("other", "web", map[string]string{"app": "web"}),
+	}
+
+	var names []string
+	for _, svc := range FindServicesForPod(pod, services) {
+		names = append(names, svc.Name)
+	}
+	if got := strings.Join(names, ","); got != "web,frontend" {
+		t.Errorf("Expected web and frontend, got %s", got)
+	}
+}
+
+func TestGetPodNetwork(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop", Labels: map[string]string{"app": "web"}}},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       v1.ServiceSpec{Selector: map[string]string{"app": "web"}, Ports: []v1.ServicePort{{Port: 80}}},
+		},
+	)
+
+	network, err := GetPodNetwork(context.Background(), clientset, "shop", "web-1")
+	if err != nil {
+		t.Fatalf("GetPodNetwork failed: %v", err)
+	}
+	if len(network.Services) != 1 || network.Services[0].Endpoint != EndpointMissing || len(network.Mismatches) != 1 {
+		t.Errorf("Expected web without endpoints and its port flagged, got %+v", network)
+	}
+
+	if _, err := GetPodNetwork(context.Background(), clientset, "shop", "missing"); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// NodeMetricSummary is the current resource usage of a node from
+// metrics-server, like a line of kubectl top node
+type NodeMetricSummary struct {
+	NodeName string `json:"nodeName"`
+	// CPUUsage and CPUCapacity are in millicores. The capacity is the node's
+	// allocatable, what pods can use, as kubectl top node measures against.
+	CPUUsage    int64 `json:"cpuUsage"`
+	CPUCapacity int64 `json:"cpuCapacity"`
+	// CPUPercent and MemPercent are -1 when the capacity is not known
+	CPUPercent float64 `json:"cpuPercent"`
+	// MemUsage and MemCapacity are in bytes
+	MemUsage    int64   `json:"memUsage"`
+	MemCapacity int64   `json:"memCapacity"`
+	MemPercent  float64 `json:"memPercent"`
+}
+
+// GetNodeMetrics returns the usage of every node with metrics, by name. It
+// fails when metrics-server is not available; when the nodes cannot be listed
+// the percentages are unknown.
+func GetNodeMetrics(ctx context.Context, metricsClientset metricsclientset.Interface, clientset kubernetes.Interface) ([]NodeMetricSummary, error) {
+	nodeMetrics, err := metricsClientset.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list node metrics: %v", err)
+		return nil, err
+	}
+
+	allocatable := make(map[string]v1.ResourceList)
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Warningf("Failed to list nodes, node usage is shown without percentages: %v", err)
+	} else {
+		for _, node := range nodes.Items {
+			allocatable[node.Name] = node.Status.Allocatable
+		}
+	}
+
+	summaries := make([]NodeMetricSummary, 0, len(nodeMetrics.Items))
+	for _, metrics := range nodeMetrics.Items {
+		summaries = append(summaries, summarizeNodeMetrics(metrics, allocatable[metrics.Name]))
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].NodeName < summaries[j].NodeName
+	})
+	return summaries, nil
+}
+
+// summarizeNodeMetrics relates a node's usage to its allocatable, which is
+// nil when the node is not known
+func summarizeNodeMetrics(metrics metricsv1beta1.NodeMetrics, allocatable v1.ResourceList) NodeMetricSummary {
+	summary := NodeMetricSummary{
+		NodeName:    metrics.Name,
+		CPUUsage:    metrics.Usage.Cpu().MilliValue(),
+		CPUCapacity: allocatable.Cpu().MilliValue(),
+		MemUsage:    metrics.Usage.Memory().Value(),
+		MemCapacity: allocatable.Memory().Value(),
+	}
+	summary.CPUPercent = percentOf(summary.CPUUsage, summary.CPUCapacity)
+	summary.MemPercent = percentOf(summary.MemUsage, summary.MemCapacity)
+	return summary
+}
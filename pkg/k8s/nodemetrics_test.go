@@ -0,0 +1,137 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// nodeMetrics returns the metrics of a node
+func nodeMetrics(name, cpu, memory string) metricsv1beta1.NodeMetrics {
+	return metricsv1beta1.NodeMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Usage: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		},
+	}
+}
+
+// allocatableNode returns a node with the given allocatable CPU and memory
+func allocatableNode(name, cpu, memory string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}
+}
+
+func TestSummarizeNodeMetrics(t *testing.T) {
+	tests := []struct {
+		name                   string
+		metrics                metricsv1beta1.NodeMetrics
+		allocatable            v1.ResourceList
+		wantCPU, wantMem       float64
+		wantCPUUsage, wantMemB int64
+	}{
+		{
+			name:         "quarter CPU, half memory",
+			metrics:      nodeMetrics("node-1", "1", "4Gi"),
+			allocatable:  allocatableNode("node-1", "4", "8Gi").Status.Allocatable,
+			wantCPU:      25,
+			wantMem:      50,
+			wantCPUUsage: 1000,
+			wantMemB:     4 << 30,
+		},
+		{
+			name:         "millicores and fractions",
+			metrics:      nodeMetrics("node-2", "1850m", "1500Mi"),
+			allocatable:  allocatableNode("node-2", "1930m", "1536Mi").Status.Allocatable,
+			wantCPU:      1850.0 * 100 / 1930,
+			wantMem:      1500.0 * 100 / 1536,
+			wantCPUUsage: 1850,
+			wantMemB:     1500 << 20,
+		},
+		{
+			name:         "over allocatable",
+			metrics:      nodeMetrics("node-3", "2100m", "3Gi"),
+			allocatable:  allocatableNode("node-3", "2", "2Gi").Status.Allocatable,
+			wantCPU:      105,
+			wantMem:      150,
+			wantCPUUsage: 2100,
+			wantMemB:     3 << 30,
+		},
+		{
+			name:         "unknown node",
+			metrics:      nodeMetrics("node-4", "100m", "1Gi"),
+			wantCPU:      -1,
+			wantMem:      -1,
+			wantCPUUsage: 100,
+			wantMemB:     1 << 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := summarizeNodeMetrics(tt.metrics, tt.allocatable)
+			if summary.NodeName != tt.metrics.Name || summary.CPUUsage != tt.wantCPUUsage || summary.MemUsage != tt.wantMemB {
+				t.Errorf("Expected %s using %dm and %d bytes, got %+v", tt.metrics.Name, tt.wantCPUUsage, tt.wantMemB, summary)
+			}
+			if math.Abs(summary.CPUPercent-tt.wantCPU) > 0.001 || math.Abs(summary.MemPercent-tt.wantMem) > 0.001 {
+				t.Errorf("Expected %.2f%% CPU and %.2f%% memory, got %.2f%% and %.2f%%", tt.wantCPU, tt.wantMem, summary.CPUPercent, summary.MemPercent)
+			}
+		})
+	}
+}
+
+func TestGetNodeMetrics(t *testing.T) {
+	metrics := &metricsfake.Clientset{}
+	metrics.AddReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{
+			nodeMetrics("worker-2", "3", "6Gi"),
+			nodeMetrics("worker-1", "500m", "1Gi"),
+		}}, nil
+	})
+	clientset := fake.NewSimpleClientset(allocatableNode("worker-1", "2", "4Gi"), allocatableNode("worker-2", "4", "8Gi"))
+
+	summaries, err := GetNodeMetrics(context.Background(), metrics, clientset)
+	if err != nil {
+		t.Fatalf("GetNodeMetrics failed: %v", err)
+	}
+	want := []NodeMetricSummary{
+		{NodeName: "worker-1", CPUUsage: 500, CPUCapacity: 2000, CPUPercent: 25, MemUsage: 1 << 30, MemCapacity: 4 << 30, MemPercent: 25},
+		{NodeName: "worker-2", CPUUsage: 3000, CPUCapacity: 4000, CPUPercent: 75, MemUsage: 6 << 30, MemCapacity: 8 << 30, MemPercent: 75},
+	}
+	if len(summaries) != len(want) || summaries[0] != want[0] || summaries[1] != want[1] {
+		t.Errorf("Expected %+v, got %+v", want, summaries)
+	}
+
+	// Without the nodes the percentages are unknown
+	clientset.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	summaries, err = GetNodeMetrics(context.Background(), metrics, clientset)
+	if err != nil || len(summaries) != 2 || summaries[0].CPUPercent != -1 || summaries[0].MemCapacity != 0 {
+		t.Errorf("Expected usage without percentages, got %+v, %v", summaries, err)
+	}
+
+	// Without metrics-server there is nothing to show
+	metrics.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("the server could not find the requested resource")
+	})
+	if _, err := GetNodeMetrics(context.Background(), metrics, clientset); err == nil {
+		t.Error("Expected the metrics error to be returned")
+	}
+}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+var (
+	// PermissionResources are the resources of the namespace permissions grid
+	PermissionResources = []string{"pods", "deployments", "services", "configmaps", "secrets", "ingresses", "serviceaccounts"}
+	// PermissionVerbs are the verbs of the namespace permissions grid
+	PermissionVerbs = []string{"get", "list", "create", "update", "delete"}
+)
+
+// resourceGroups are the API groups of the resources outside the core group
+// that can be checked by their plain name
+var resourceGroups = map[string]string{
+	"deployments":  "apps",
+	"statefulsets": "apps",
+	"daemonsets":   "apps",
+	"replicasets":  "apps",
+	"jobs":         "batch",
+	"cronjobs":     "batch",
+	"ingresses":    "networking.k8s.io",
+}
+
+// CanI asks the API server with a SelfSubjectAccessReview whether the
+// current user may use verb on resource in namespace. Resources are named as
+// for CheckPermissions.
+func CanI(ctx context.Context, clientset kubernetes.Interface, verb, resource, namespace string) (bool, error) {
+	name, group := resource, resourceGroups[resource]
+	if i := strings.Index(resource, "."); i >= 0 {
+		name, group = resource[:i], resource[i+1:]
+	}
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     group,
+				Resource:  name,
+			},
+		},
+	}
+	result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		klog.Errorf("Failed to check whether %s on %s is allowed in namespace %s: %v", verb, resource, namespace, err)
+		return false, err
+	}
+	return result.Status.Allowed, nil
+}
+
+// CheckPermissions asks the API server, with one SelfSubjectAccessReview per
+// verb and resource, what the current user can do in a namespace. Resources
+// are plain names such as "pods", or "widgets.example.com" for other groups.
+// The result maps each resource to each verb to whether it is allowed.
+func CheckPermissions(ctx context.Context, clientset kubernetes.Interface, namespace string, resources, verbs []string) (map[string]map[string]bool, error) {
+	permissions := make(map[string]map[string]bool, len(resources))
+	for _, resource := range resources {
+		permissions[resource] = make(map[string]bool, len(verbs))
+		for _, verb := range verbs {
+			allowed, err := CanI(ctx, clientset, verb, resource, namespace)
+			if err != nil {
+				return nil, err
+			}
+			permissions[resource][verb] = allowed
+		}
+	}
+	return permissions, nil
+}
+
+// PermissionMatrix is what the current user can do with each of
+// PermissionResources in a namespace
+type PermissionMatrix struct {
+	Namespace string   `json:"namespace"`
+	Resources []string `json:"resources"`
+	Verbs     []string `json:"verbs"`
+	// Allowed maps each resource to each verb to whether it is allowed
+	Allowed   map[string]map[string]bool `json:"allowed"`
+	CheckedAt time.Time                  `json:"checkedAt"`
+}
+
+// GetPermissionMatrix checks every verb of PermissionVerbs on every resource
+// of PermissionResources in a namespace
+func GetPermissionMatrix(ctx context.Context, clientset kubernetes.Interface, namespace string) (*PermissionMatrix, error) {
+	allowed, err := CheckPermissions(ctx, clientset, namespace, PermissionResources, PermissionVerbs)
+	if err != nil {
+		return nil, err
+	}
+	return &PermissionMatrix{
+		Namespace: namespace,
+		Resources: PermissionResources,
+		Verbs:     PermissionVerbs,
+		Allowed:   allowed,
+		CheckedAt: time.Now(),
+	}, nil
+}
+
+// Can reports whether verb is allowed on resource. Verbs and resources
+// outside the matrix are not.
+func (m *PermissionMatrix) Can(verb, resource string) bool {
+	return m.Allowed[resource][verb]
+}
+
+// PermissionCache keeps the permission matrix of each namespace for a TTL.
+// Concurrent checks of the same namespace share one set of reviews. Matrices
+// are shared between callers and must not be modified.
+type PermissionCache struct {
+	clientset kubernetes.Interface
+	ttl       time.Duration
+	group     singleflight.Group
+	// now returns the current time; tests move it forward
+	now func() time.Time
+
+	mu       sync.Mutex
+	matrices map[string]*PermissionMatrix
+}
+
+// NewPermissionCache creates a cache checking permissions with clientset and
+// keeping them for ttl
+func NewPermissionCache(clientset kubernetes.Interface, ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		clientset: clientset,
+		ttl:       ttl,
+		now:       time.Now,
+		matrices:  make(map[string]*PermissionMatrix),
+	}
+}
+
+// Get returns the permission matrix of a namespace, checking it again once
+// the cached one is older than the TTL. Failed checks are not cached.
+func (c *PermissionCache) Get(ctx context.Context, namespace string) (*PermissionMatrix, error) {
+	c.mu.Lock()
+	cached, ok := c.matrices[namespace]
+	if ok && c.now().Sub(cached.CheckedAt) < c.ttl {
+		c.mu.Unlock()
+		return cached, nil
+	}
+	c.mu.Unlock()
+
+	value, err, _ := c.group.Do(namespace, func() (interface{}, error) {
+		matrix, err := GetPermissionMatrix(ctx, c.clientset, namespace)
+		if err != nil {
+			return nil, err
+		}
+		matrix.CheckedAt = c.now()
+		c.mu.Lock()
+		c.matrices[namespace] = matrix
+		c.mu.Unlock()
+		return matrix, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*PermissionMatrix), nil
+}
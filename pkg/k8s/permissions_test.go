@@ -0,0 +1,178 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeAccessReviews answers SelfSubjectAccessReviews from a set of allowed
+// "verb group/resource namespace" attributes, and records the reviews
+func fakeAccessReviews(clientset *fake.Clientset, allowed map[string]bool) *[]string {
+	var reviews []string
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		key := attrs.Verb + " " + attrs.Group + "/" + attrs.Resource + " " + attrs.Namespace
+		reviews = append(reviews, key)
+		review.Status.Allowed = allowed[key]
+		return true, review, nil
+	})
+	return &reviews
+}
+
+func TestCheckPermissions(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	reviews := fakeAccessReviews(clientset, map[string]bool{
+		"get /pods shop":                     true,
+		"list /pods shop":                    true,
+		"get apps/deployments shop":          true,
+		"delete apps/deployments shop":       true,
+		"get example.com/widgets shop":       true,
+		"get /pods other-namespace-entirely": true,
+	})
+
+	permissions, err := CheckPermissions(context.Background(), clientset, "shop",
+		[]string{"pods", "deployments", "widgets.example.com"}, []string{"get", "list", "delete"})
+	if err != nil {
+		t.Fatalf("CheckPermissions failed: %v", err)
+	}
+
+	want := map[string]map[string]bool{
+		"pods":                {"get": true, "list": true, "delete": false},
+		"deployments":         {"get": true, "list": false, "delete": true},
+		"widgets.example.com": {"get": true, "list": false, "delete": false},
+	}
+	if !reflect.DeepEqual(permissions, want) {
+		t.Errorf("Expected permissions %v, got %v", want, permissions)
+	}
+	if len(*reviews) != 9 {
+		t.Errorf("Expected one review per resource and verb, got %v", *reviews)
+	}
+}
+
+func TestCheckPermissionsError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	if _, err := CheckPermissions(context.Background(), clientset, "shop", PermissionResources, PermissionVerbs); err == nil {
+		t.Error("Expected the review error to be returned")
+	}
+}
+
+func TestCanI(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	reviews := fakeAccessReviews(clientset, map[string]bool{"delete batch/jobs shop": true})
+
+	for _, tt := range []struct {
+		verb, resource string
+		want           bool
+	}{
+		{"delete", "jobs", true},
+		{"delete", "pods", false},
+		{"delete", "jobs.batch", true},
+	} {
+		if allowed, err := CanI(context.Background(), clientset, tt.verb, tt.resource, "shop"); err != nil || allowed != tt.want {
+			t.Errorf("CanI(%s, %s): expected %v, got %v, %v", tt.verb, tt.resource, tt.want, allowed, err)
+		}
+	}
+	if want := []string{"delete batch/jobs shop", "delete /pods shop", "delete batch/jobs shop"}; !reflect.DeepEqual(*reviews, want) {
+		t.Errorf("Expected reviews %v, got %v", want, *reviews)
+	}
+}
+
+func TestGetPermissionMatrix(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	fakeAccessReviews(clientset, map[string]bool{
+		"list /pods shop":                         true,
+		"delete /pods shop":                       true,
+		"update apps/deployments shop":            true,
+		"create networking.k8s.io/ingresses shop": true,
+	})
+
+	matrix, err := GetPermissionMatrix(context.Background(), clientset, "shop")
+	if err != nil {
+		t.Fatalf("GetPermissionMatrix failed: %v", err)
+	}
+	if matrix.Namespace != "shop" || !reflect.DeepEqual(matrix.Resources, PermissionResources) || !reflect.DeepEqual(matrix.Verbs, PermissionVerbs) {
+		t.Errorf("Expected the shop matrix of every resource and verb, got %+v", matrix)
+	}
+	allowed := 0
+	for _, resource := range matrix.Resources {
+		for _, verb := range matrix.Verbs {
+			if matrix.Can(verb, resource) {
+				allowed++
+			}
+		}
+	}
+	if allowed != 4 || !matrix.Can("delete", "pods") || !matrix.Can("update", "deployments") || !matrix.Can("create", "ingresses") {
+		t.Errorf("Expected exactly the four allowed cells, got %v", matrix.Allowed)
+	}
+	if matrix.Can("delete", "deployments") || matrix.Can("delete", "widgets") || matrix.Can("escalate", "pods") {
+		t.Error("Expected denied and unknown cells not to be allowed")
+	}
+}
+
+func TestPermissionCache(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	reviews := fakeAccessReviews(clientset, map[string]bool{"delete /pods shop": true})
+	perMatrix := len(PermissionResources) * len(PermissionVerbs)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewPermissionCache(clientset, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	matrix, err := cache.Get(context.Background(), "shop")
+	if err != nil || !matrix.Can("delete", "pods") || !matrix.CheckedAt.Equal(now) {
+		t.Fatalf("Expected the shop matrix checked now, got %+v, %v", matrix, err)
+	}
+	if len(*reviews) != perMatrix {
+		t.Errorf("Expected %d reviews, got %d", perMatrix, len(*reviews))
+	}
+
+	// Within the TTL the namespace is not checked again, others are
+	now = now.Add(59 * time.Second)
+	if cached, err := cache.Get(context.Background(), "shop"); err != nil || cached != matrix {
+		t.Errorf("Expected the cached matrix, got %+v, %v", cached, err)
+	}
+	if other, err := cache.Get(context.Background(), "other"); err != nil || other.Can("delete", "pods") {
+		t.Errorf("Expected the other namespace to be checked on its own, got %+v, %v", other, err)
+	}
+	if len(*reviews) != 2*perMatrix {
+		t.Errorf("Expected %d reviews, got %d", 2*perMatrix, len(*reviews))
+	}
+
+	// After it the namespace is checked again
+	now = now.Add(time.Second)
+	if refreshed, err := cache.Get(context.Background(), "shop"); err != nil || refreshed == matrix || !refreshed.CheckedAt.Equal(now) {
+		t.Errorf("Expected a new matrix, got %+v, %v", refreshed, err)
+	}
+	if len(*reviews) != 3*perMatrix {
+		t.Errorf("Expected %d reviews, got %d", 3*perMatrix, len(*reviews))
+	}
+
+	// Failures are returned and not cached
+	failures := 0
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		failures++
+		return true, nil, errors.New("connection refused")
+	})
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get(context.Background(), "shop"); err == nil {
+			t.Error("Expected the review error to be returned")
+		}
+	}
+	if failures != 2 {
+		t.Errorf("Expected each check to fail on its own review, got %d failed reviews", failures)
+	}
+}
@@ -0,0 +1,362 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/klog/v2"
+)
+
+// PodExec runs a command in a container without a TTY, feeding it stdin when
+// not nil and writing its standard output to stdout. The error of a failed
+// command carries what it wrote to standard error.
+type PodExec func(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error
+
+// NewRESTConfig loads the REST config from kubeconfig or in-cluster config,
+// for the streaming subresources such as exec that a clientset cannot reach
+func NewRESTConfig(kubeconfig string) (*rest.Config, error) {
+	config, _, err := buildConfig(kubeconfig)
+	return config, err
+}
+
+// NewPodExec returns a PodExec using the exec subresource of the API server
+// config points to
+func NewPodExec(clientset kubernetes.Interface, config *rest.Config) PodExec {
+	return func(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+		req := clientset.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Name(pod).
+			Namespace(namespace).
+			SubResource("exec").
+			VersionedParams(&v1.PodExecOptions{
+				Container: container,
+				Command:   command,
+				Stdin:     stdin != nil,
+				Stdout:    true,
+				Stderr:    true,
+			}, scheme.ParameterCodec)
+
+		executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+		if err != nil {
+			return err
+		}
+		var stderr bytes.Buffer
+		err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: &stderr})
+		if err != nil {
+			klog.Errorf("Failed to exec %v in pod %s in namespace %s: %v", command, pod, namespace, err)
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				return fmt.Errorf("%s: %w", message, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// FileEntry is a file of a directory in a container, as ls -la lists it
+type FileEntry struct {
+	Name string `json:"name"`
+	// Mode is the type and permissions, e.g. "drwxr-xr-x"
+	Mode string `json:"mode"`
+	// Size is in bytes, 0 for devices
+	Size int64 `json:"size"`
+	// Modified is the modification time as ls prints it, e.g. "Jan 2 15:04"
+	// for recent files and "Jan 2 2023" for older ones
+	Modified string `json:"modified"`
+	// LinkTarget is where a symbolic link points
+	LinkTarget string `json:"linkTarget,omitempty"`
+}
+
+// IsDir reports whether the entry is a directory
+func (e FileEntry) IsDir() bool {
+	return strings.HasPrefix(e.Mode, "d")
+}
+
+// IsLink reports whether the entry is a symbolic link
+func (e FileEntry) IsLink() bool {
+	return strings.HasPrefix(e.Mode, "l")
+}
+
+// isoDate matches the dates of ls --time-style=long-iso, which some
+// distributions make the default
+var isoDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// ParseDirectoryListing parses the output of ls -la, of GNU coreutils or
+// busybox, leaving out the total and the . and .. entries. Directories come
+// first, then files, each sorted by name.
+func ParseDirectoryListing(output string) ([]FileEntry, error) {
+	entries := []FileEntry{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "total ") {
+			continue
+		}
+		entry, err := parseListingLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sortFileEntries(entries)
+	return entries, nil
+}
+
+// sortFileEntries puts directories first, then files, each sorted by name
+func sortFileEntries(entries []FileEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// parseListingLine parses one entry of ls -la: mode, links, owner, group,
+// size (major and minor for devices), date and name
+func parseListingLine(line string) (FileEntry, error) {
+	fields, offsets := fieldsWithOffsets(line)
+	if len(fields) < 8 || len(fields[0]) < 10 || !strings.ContainsRune("-dlcbps", rune(fields[0][0])) {
+		return FileEntry{}, fmt.Errorf("unexpected ls output: %q", line)
+	}
+	entry := FileEntry{Mode: fields[0][:10]}
+
+	i := 4
+	if (entry.Mode[0] == 'c' || entry.Mode[0] == 'b') && strings.HasSuffix(fields[i], ",") {
+		// Devices list their major and minor numbers instead of a size
+		i += 2
+	} else {
+		size, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return FileEntry{}, fmt.Errorf("unexpected size in ls output: %q", line)
+		}
+		entry.Size = size
+		i++
+	}
+
+	dateFields := 3
+	if i < len(fields) && isoDate.MatchString(fields[i]) {
+		dateFields = 2
+	}
+	if i+dateFields >= len(fields) {
+		return FileEntry{}, fmt.Errorf("unexpected ls output: %q", line)
+	}
+	entry.Modified = strings.Join(fields[i:i+dateFields], " ")
+
+	// The name is the rest of the line, spaces included
+	entry.Name = line[offsets[i+dateFields]:]
+	if entry.IsLink() {
+		if name, target, ok := strings.Cut(entry.Name, " -> "); ok {
+			entry.Name, entry.LinkTarget = name, target
+		}
+	}
+	return entry, nil
+}
+
+// fieldsWithOffsets splits a line around runs of spaces, like strings.Fields,
+// returning where each field starts
+func fieldsWithOffsets(line string) ([]string, []int) {
+	var fields []string
+	var offsets []int
+	start := -1
+	for i, r := range line {
+		if r == ' ' || r == '\t' {
+			if start >= 0 {
+				fields = append(fields, line[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			offsets = append(offsets, i)
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, line[start:])
+	}
+	return fields, offsets
+}
+
+// ListPodDirectory lists a directory of a container with ls -la
+func ListPodDirectory(ctx context.Context, exec PodExec, namespace, pod, container, dir string) ([]FileEntry, error) {
+	var stdout bytes.Buffer
+	// The trailing slash lists the directory a symbolic link points to
+	if err := exec(ctx, namespace, pod, container, []string{"ls", "-la", strings.TrimSuffix(dir, "/") + "/"}, nil, &stdout); err != nil {
+		return nil, err
+	}
+	return ParseDirectoryListing(stdout.String())
+}
+
+// ListLocalDirectory lists a local directory in the form ListPodDirectory
+// lists one of a container, for picking files to upload. Links are listed,
+// not followed.
+func ListLocalDirectory(dir string) ([]FileEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]FileEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		entry := FileEntry{
+			Name:     dirEntry.Name(),
+			Mode:     localFileMode(info.Mode()),
+			Size:     info.Size(),
+			Modified: localModified(info.ModTime()),
+		}
+		if entry.IsLink() {
+			entry.LinkTarget, _ = os.Readlink(filepath.Join(dir, entry.Name))
+		}
+		entries = append(entries, entry)
+	}
+	sortFileEntries(entries)
+	return entries, nil
+}
+
+// localFileMode returns a file mode as ls prints it, e.g. "drwxr-xr-x"
+func localFileMode(mode os.FileMode) string {
+	kind := "-"
+	switch {
+	case mode.IsDir():
+		kind = "d"
+	case mode&os.ModeSymlink != 0:
+		kind = "l"
+	case mode&os.ModeNamedPipe != 0:
+		kind = "p"
+	case mode&os.ModeSocket != 0:
+		kind = "s"
+	case mode&os.ModeCharDevice != 0:
+		kind = "c"
+	case mode&os.ModeDevice != 0:
+		kind = "b"
+	}
+	return kind + mode.Perm().String()[1:]
+}
+
+// localModified returns a modification time as ls prints it: with the time
+// within six months, with the year otherwise
+func localModified(modified time.Time) string {
+	if time.Since(modified) < 182*24*time.Hour {
+		return modified.Format("Jan _2 15:04")
+	}
+	return modified.Format("Jan _2 2006")
+}
+
+// ReadPodFile returns the content of a file of a container, read with cat
+func ReadPodFile(ctx context.Context, exec PodExec, namespace, pod, container, file string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := exec(ctx, namespace, pod, container, []string{"cat", file}, nil, &stdout); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// WritePodFile replaces the content of a file of a container, written with
+// tee
+func WritePodFile(ctx context.Context, exec PodExec, namespace, pod, container, file string, data []byte) error {
+	return exec(ctx, namespace, pod, container, []string{"tee", file}, bytes.NewReader(data), io.Discard)
+}
+
+// CopyFromPod copies a file or directory of a container into the local
+// directory destDir, as kubectl cp does: tar archives it in the container and
+// it is extracted locally. Symbolic links and entries that would land outside
+// destDir are skipped. It returns the local path of the copy.
+func CopyFromPod(ctx context.Context, exec PodExec, namespace, pod, container, src, destDir string) (string, error) {
+	src = path.Clean(src)
+	if src == "/" {
+		return "", errors.New("cannot copy the root directory")
+	}
+
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := exec(ctx, namespace, pod, container, []string{"tar", "cf", "-", "-C", path.Dir(src), path.Base(src)}, nil, writer)
+		writer.CloseWithError(err)
+		done <- err
+	}()
+
+	extractErr := extractTar(reader, destDir)
+	if extractErr != nil {
+		// Stop the command instead of waiting for it to write the rest
+		reader.CloseWithError(extractErr)
+	} else {
+		// tar pads the archive past the end the reader stops at
+		io.Copy(io.Discard, reader)
+	}
+	if err := <-done; err != nil && extractErr == nil {
+		return "", err
+	}
+	if extractErr != nil {
+		return "", extractErr
+	}
+	return filepath.Join(destDir, path.Base(src)), nil
+}
+
+// extractTar writes the directories and regular files of a tar archive under
+// destDir
+func extractTar(r io.Reader, destDir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(destDir, filepath.FromSlash(header.Name))
+		if rel, err := filepath.Rel(destDir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			klog.Warningf("Skipping %s, which is outside the destination", header.Name)
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, header.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			klog.Warningf("Skipping %s, which is not a regular file or directory", header.Name)
+		}
+	}
+}
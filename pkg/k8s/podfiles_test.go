@@ -0,0 +1,191 @@
+package k8s
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDirectoryListing(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []FileEntry
+	}{
+		{
+			name: "GNU coreutils",
+			output: `total 24
+drwxr-xr-x 1 root root 4096 Mar  4 10:15 .
+drwxr-xr-x 1 root root 4096 Mar  4 10:15 ..
+-rw-r--r-- 1 root root  615 Nov 12  2023 mime.types
+drwxr-xr-x 2 root root 4096 Mar  4 10:15 conf.d
+-rw-r--r-- 1 root root 1234 Mar  4 10:15 my notes.txt
+lrwxrwxrwx 1 root root   22 Mar  4 10:15 modules -> /usr/lib/nginx/modules
+`,
+			want: []FileEntry{
+				{Name: "conf.d", Mode: "drwxr-xr-x", Size: 4096, Modified: "Mar 4 10:15"},
+				{Name: "mime.types", Mode: "-rw-r--r--", Size: 615, Modified: "Nov 12 2023"},
+				{Name: "modules", Mode: "lrwxrwxrwx", Size: 22, Modified: "Mar 4 10:15", LinkTarget: "/usr/lib/nginx/modules"},
+				{Name: "my notes.txt", Mode: "-rw-r--r--", Size: 1234, Modified: "Mar 4 10:15"},
+			},
+		},
+		{
+			name: "busybox with devices",
+			output: `total 0
+drwxr-xr-x    5 root     root           360 Mar  4 10:15 .
+crw-rw-rw-    1 root     root        1,   3 Mar  4 10:15 null
+-rw-r--r--+   1 1000     1000            12 Mar  4 10:15 notes
+`,
+			want: []FileEntry{
+				{Name: "notes", Mode: "-rw-r--r--", Size: 12, Modified: "Mar 4 10:15"},
+				{Name: "null", Mode: "crw-rw-rw-", Modified: "Mar 4 10:15"},
+			},
+		},
+		{
+			name:   "long-iso time style",
+			output: "-rw-r----- 1 app app 2048 2024-03-04 10:15 app.log\r\n",
+			want:   []FileEntry{{Name: "app.log", Mode: "-rw-r-----", Size: 2048, Modified: "2024-03-04 10:15"}},
+		},
+		{
+			name:   "empty directory",
+			output: "total 0\n",
+			want:   []FileEntry{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDirectoryListing(tt.output)
+			if err != nil {
+				t.Fatalf("ParseDirectoryListing failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDirectoryListing() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, output := range []string{
+		"ls: /nope: No such file or directory",
+		"-rw-r--r-- 1 root root big Mar  4 10:15 file",
+		"-rw-r--r-- 1 root root 12 Mar  4",
+	} {
+		if _, err := ParseDirectoryListing(output); err == nil {
+			t.Errorf("Expected an error for %q", output)
+		}
+	}
+
+	if entries, _ := ParseDirectoryListing("drwxr-xr-x 2 root root 4096 Mar  4 10:15 conf.d\n"); !entries[0].IsDir() || entries[0].IsLink() {
+		t.Errorf("Expected conf.d to be a directory, got %+v", entries[0])
+	}
+}
+
+// fakeExec runs commands against a map of files, archiving them for tar
+func fakeExec(files map[string]string, commands *[][]string) PodExec {
+	return func(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+		*commands = append(*commands, command)
+		switch command[0] {
+		case "cat":
+			content, ok := files[command[1]]
+			if !ok {
+				return errors.New("cat: " + command[1] + ": No such file or directory")
+			}
+			_, err := io.WriteString(stdout, content)
+			return err
+		case "tee":
+			data, err := io.ReadAll(stdin)
+			files[command[1]] = string(data)
+			return err
+		case "tar":
+			tw := tar.NewWriter(stdout)
+			tw.WriteHeader(&tar.Header{Name: command[5] + "/", Typeflag: tar.TypeDir, Mode: 0755})
+			for name, content := range files {
+				tw.WriteHeader(&tar.Header{Name: command[5] + "/" + name, Typeflag: tar.TypeReg, Mode: 0640, Size: int64(len(content))})
+				io.WriteString(tw, content)
+			}
+			tw.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644, Size: 1})
+			io.WriteString(tw, "x")
+			return tw.Close()
+		}
+		return errors.New(command[0] + ": not found")
+	}
+}
+
+func TestPodFiles(t *testing.T) {
+	files := map[string]string{"nginx.conf": "worker_processes 1;\n"}
+	var commands [][]string
+	exec := fakeExec(files, &commands)
+	ctx := context.Background()
+
+	data, err := ReadPodFile(ctx, exec, "default", "web", "app", "nginx.conf")
+	if err != nil || string(data) != "worker_processes 1;\n" {
+		t.Errorf("Expected the file content, got %q, %v", data, err)
+	}
+	if err := WritePodFile(ctx, exec, "default", "web", "app", "nginx.conf", []byte("worker_processes 2;\n")); err != nil || files["nginx.conf"] != "worker_processes 2;\n" {
+		t.Errorf("Expected the file to be written, got %q, %v", files["nginx.conf"], err)
+	}
+	if _, err := ReadPodFile(ctx, exec, "default", "web", "app", "missing"); err == nil || !strings.Contains(err.Error(), "No such file") {
+		t.Errorf("Expected cat's error, got %v", err)
+	}
+
+	dest := t.TempDir()
+	copied, err := CopyFromPod(ctx, exec, "default", "web", "app", "/etc/nginx/", dest)
+	if err != nil {
+		t.Fatalf("CopyFromPod failed: %v", err)
+	}
+	if copied != filepath.Join(dest, "nginx") {
+		t.Errorf("Expected the copy at %s, got %s", filepath.Join(dest, "nginx"), copied)
+	}
+	if want := []string{"tar", "cf", "-", "-C", "/etc", "nginx"}; !reflect.DeepEqual(commands[len(commands)-1], want) {
+		t.Errorf("Expected %v, got %v", want, commands[len(commands)-1])
+	}
+	if data, err := os.ReadFile(filepath.Join(copied, "nginx.conf")); err != nil || string(data) != "worker_processes 2;\n" {
+		t.Errorf("Expected the copied file, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "escape")); !os.IsNotExist(err) {
+		t.Errorf("Expected entries outside the destination to be skipped, got %v", err)
+	}
+
+	if _, err := CopyFromPod(ctx, exec, "default", "web", "app", "/", dest); err == nil {
+		t.Error("Expected copying the root directory to fail")
+	}
+}
+
+func TestListLocalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.conf"), []byte("port=80\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app.conf", filepath.Join(dir, "current.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ListLocalDirectory(dir)
+	if err != nil {
+		t.Fatalf("ListLocalDirectory failed: %v", err)
+	}
+	var got []string
+	for _, entry := range entries {
+		got = append(got, fmt.Sprintf("%s %s %s", entry.Mode, entry.Name, entry.LinkTarget))
+	}
+	want := []string{"drwxr-xr-x nested ", "-rw-r----- app.conf ", "lrwxrwxrwx current.conf app.conf"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if entries[1].Size != 8 || entries[1].Modified == "" {
+		t.Errorf("Expected the size and modification time of app.conf, got %+v", entries[1])
+	}
+
+	if _, err := ListLocalDirectory(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected listing a missing directory to fail")
+	}
+}
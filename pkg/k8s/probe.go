@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// EndpointProbeResult is the outcome of a TCP dial to one service IP and port
+type EndpointProbeResult struct {
+	IP        string        `json:"ip"`
+	Port      int32         `json:"port"`
+	Reachable bool          `json:"reachable"`
+	Latency   time.Duration `json:"latency"`
+	// Error is the dial error of an unreachable endpoint
+	Error string `json:"error,omitempty"`
+}
+
+// serviceIPs returns the cluster IPs of a service, skipping headless services
+// which have none to dial
+func serviceIPs(service v1.Service) []string {
+	ips := service.Spec.ClusterIPs
+	if len(ips) == 0 && service.Spec.ClusterIP != "" {
+		ips = []string{service.Spec.ClusterIP}
+	}
+
+	var routable []string
+	for _, ip := range ips {
+		if ip != "" && ip != v1.ClusterIPNone {
+			routable = append(routable, ip)
+		}
+	}
+	return routable
+}
+
+// ProbeServiceEndpoints dials every TCP port of a service on each of its
+// cluster IPs, giving each dial at most timeout. Results are ordered by IP,
+// then by port. Cluster IPs are only routable from inside the cluster, so
+// every endpoint is unreachable when kgo runs elsewhere.
+func ProbeServiceEndpoints(ctx context.Context, service v1.Service, timeout time.Duration) []EndpointProbeResult {
+	var results []EndpointProbeResult
+	for _, ip := range serviceIPs(service) {
+		for _, port := range service.Spec.Ports {
+			if port.Protocol != "" && port.Protocol != v1.ProtocolTCP {
+				continue
+			}
+			results = append(results, EndpointProbeResult{IP: ip, Port: port.Port})
+		}
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(result *EndpointProbeResult) {
+			defer wg.Done()
+			start := time.Now()
+			conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(result.IP, strconv.Itoa(int(result.Port))))
+			result.Latency = time.Since(start)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			conn.Close()
+			result.Reachable = true
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
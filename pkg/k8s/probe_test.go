@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// closedPort returns a local port that nothing listens on
+func closedPort(t *testing.T) int32 {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return int32(port)
+}
+
+func TestProbeServiceEndpoints(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	open := int32(listener.Addr().(*net.TCPAddr).Port)
+	closed := closedPort(t)
+
+	service := v1.Service{Spec: v1.ServiceSpec{
+		ClusterIP: "127.0.0.1",
+		Ports: []v1.ServicePort{
+			{Name: "http", Port: open, Protocol: v1.ProtocolTCP},
+			{Name: "dns", Port: 53, Protocol: v1.ProtocolUDP},
+			{Name: "admin", Port: closed},
+		},
+	}}
+
+	results := ProbeServiceEndpoints(context.Background(), service, time.Second)
+	if len(results) != 2 {
+		t.Fatalf("Expected the two TCP ports to be probed, got %+v", results)
+	}
+	if results[0].IP != "127.0.0.1" || results[0].Port != open || !results[0].Reachable || results[0].Error != "" {
+		t.Errorf("Expected the listening port to be reachable, got %+v", results[0])
+	}
+	if results[1].Port != closed || results[1].Reachable || results[1].Error == "" {
+		t.Errorf("Expected the closed port to be unreachable with an error, got %+v", results[1])
+	}
+}
+
+func TestProbeServiceEndpointsHeadless(t *testing.T) {
+	service := v1.Service{Spec: v1.ServiceSpec{
+		ClusterIP: v1.ClusterIPNone,
+		Ports:     []v1.ServicePort{{Port: 80}},
+	}}
+	if results := ProbeServiceEndpoints(context.Background(), service, time.Second); len(results) != 0 {
+		t.Errorf("Expected no endpoints for a headless service, got %+v", results)
+	}
+}
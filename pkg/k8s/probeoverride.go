@@ -0,0 +1,161 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Probe types of DisableContainerProbe
+const (
+	LivenessProbe  = "liveness"
+	ReadinessProbe = "readiness"
+	StartupProbe   = "startup"
+)
+
+// ProbeTypes lists the probe types in the order they are shown
+var ProbeTypes = []string{LivenessProbe, ReadinessProbe, StartupProbe}
+
+// ErrNotDeploymentPod is returned by PodDeployment for a pod no deployment
+// manages
+var ErrNotDeploymentPod = errors.New("pod is not managed by a deployment")
+
+// probeFields maps the probe types to their fields in a container spec
+var probeFields = map[string]string{
+	LivenessProbe:  "livenessProbe",
+	ReadinessProbe: "readinessProbe",
+	StartupProbe:   "startupProbe",
+}
+
+// ContainerProbe returns the probe of a type of a container, or nil when it
+// has none
+func ContainerProbe(container v1.Container, probeType string) *v1.Probe {
+	switch probeType {
+	case LivenessProbe:
+		return container.LivenessProbe
+	case ReadinessProbe:
+		return container.ReadinessProbe
+	case StartupProbe:
+		return container.StartupProbe
+	}
+	return nil
+}
+
+// DescribeProbe describes a probe for display, e.g. "httpGet :8080/healthz
+// every 10s, 3 failures", or returns an empty string for no probe
+func DescribeProbe(probe *v1.Probe) string {
+	if probe == nil {
+		return ""
+	}
+	period, threshold := probe.PeriodSeconds, probe.FailureThreshold
+	// The API server defaults both; show the defaults for objects it has
+	// not seen, e.g. in tests
+	if period == 0 {
+		period = 10
+	}
+	if threshold == 0 {
+		threshold = 3
+	}
+	return fmt.Sprintf("%s every %ds, %d failures", describeProbe(probe), period, threshold)
+}
+
+// ProbeRef names the probe of a type of a container
+type ProbeRef struct {
+	Container string
+	Type      string
+}
+
+// DisableProbesPatch returns the JSON patch removing probes from the
+// containers of a deployment's pod template. The patch tests the name of each
+// container at the index it removes from, so it fails rather than touch
+// another container when the template changed since the deployment was read.
+func DisableProbesPatch(deployment *appsv1.Deployment, probes []ProbeRef) ([]byte, error) {
+	indexes := make(map[string]int, len(deployment.Spec.Template.Spec.Containers))
+	for i, container := range deployment.Spec.Template.Spec.Containers {
+		indexes[container.Name] = i
+	}
+
+	var ops []map[string]interface{}
+	for _, probe := range probes {
+		field, ok := probeFields[probe.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown probe type %q, expected %s, %s or %s", probe.Type, LivenessProbe, ReadinessProbe, StartupProbe)
+		}
+		i, ok := indexes[probe.Container]
+		if !ok {
+			return nil, fmt.Errorf("deployment %s has no container %s", deployment.Name, probe.Container)
+		}
+		if ContainerProbe(deployment.Spec.Template.Spec.Containers[i], probe.Type) == nil {
+			return nil, fmt.Errorf("container %s of deployment %s has no %s probe", probe.Container, deployment.Name, probe.Type)
+		}
+		path := fmt.Sprintf("/spec/template/spec/containers/%d", i)
+		ops = append(ops,
+			map[string]interface{}{"op": "test", "path": path + "/name", "value": probe.Container},
+			map[string]interface{}{"op": "remove", "path": path + "/" + field})
+	}
+	if len(ops) == 0 {
+		return nil, errors.New("no probes to disable")
+	}
+
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the probe patch: %v", err)
+	}
+	return patch, nil
+}
+
+// DisableContainerProbe removes the liveness, readiness or startup probe of
+// a container from a deployment's pod template. Pods are immutable, so this
+// rolls out new pods without the probe.
+func DisableContainerProbe(ctx context.Context, clientset kubernetes.Interface, namespace, deploymentName, containerName, probeType string) error {
+	_, err := DisableContainerProbes(ctx, clientset, namespace, deploymentName, []ProbeRef{{Container: containerName, Type: probeType}})
+	return err
+}
+
+// DisableContainerProbes removes probes from a deployment's pod template in
+// a single patch, so that they cost one rollout, and returns the patch
+func DisableContainerProbes(ctx context.Context, clientset kubernetes.Interface, namespace, deploymentName string, probes []ProbeRef) ([]byte, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get deployment %s/%s: %v", namespace, deploymentName, err)
+		return nil, err
+	}
+	patch, err := DisableProbesPatch(deployment, probes)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = clientset.AppsV1().Deployments(namespace).Patch(ctx, deploymentName, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to remove probes of deployment %s/%s: %v", namespace, deploymentName, err)
+		return nil, err
+	}
+	return patch, nil
+}
+
+// PodDeployment returns the name of the deployment managing a pod through
+// its replica set
+func PodDeployment(ctx context.Context, clientset kubernetes.Interface, pod *v1.Pod) (string, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "ReplicaSet" {
+		return "", fmt.Errorf("%w: %s", ErrNotDeploymentPod, pod.Name)
+	}
+	replicaSet, err := clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get replica set %s/%s: %v", pod.Namespace, owner.Name, err)
+		return "", err
+	}
+	owner = metav1.GetControllerOf(replicaSet)
+	if owner == nil || owner.Kind != "Deployment" {
+		return "", fmt.Errorf("%w: %s", ErrNotDeploymentPod, pod.Name)
+	}
+	return owner.Name, nil
+}
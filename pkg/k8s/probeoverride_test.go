@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// probedDeployment returns a deployment whose sidecar and web containers
+// both have liveness and readiness probes
+func probedDeployment() *appsv1.Deployment {
+	probe := func(port int32) *v1.Probe {
+		return &v1.Probe{ProbeHandler: v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt32(port)}}}
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "sidecar", Image: "envoy", LivenessProbe: probe(9901), ReadinessProbe: probe(9901)},
+			{Name: "web", Image: "nginx", LivenessProbe: probe(8080), ReadinessProbe: probe(8080), StartupProbe: probe(8080)},
+		}}}},
+	}
+}
+
+func TestDisableProbesPatch(t *testing.T) {
+	patch, err := DisableProbesPatch(probedDeployment(), []ProbeRef{{Container: "web", Type: LivenessProbe}, {Container: "web", Type: StartupProbe}})
+	if err != nil {
+		t.Fatalf("DisableProbesPatch failed: %v", err)
+	}
+	want := `[{"op":"test","path":"/spec/template/spec/containers/1/name","value":"web"},` +
+		`{"op":"remove","path":"/spec/template/spec/containers/1/livenessProbe"},` +
+		`{"op":"test","path":"/spec/template/spec/containers/1/name","value":"web"},` +
+		`{"op":"remove","path":"/spec/template/spec/containers/1/startupProbe"}]`
+	if string(patch) != want {
+		t.Errorf("Expected patch\n%s\ngot\n%s", want, patch)
+	}
+
+	tests := []struct {
+		name  string
+		probe ProbeRef
+		want  string
+	}{
+		{"unknown type", ProbeRef{Container: "web", Type: "health"}, "unknown probe type"},
+		{"missing container", ProbeRef{Container: "api", Type: LivenessProbe}, "no container api"},
+		{"missing probe", ProbeRef{Container: "sidecar", Type: StartupProbe}, "has no startup probe"},
+	}
+	for _, tt := range tests {
+		if _, err := DisableProbesPatch(probedDeployment(), []ProbeRef{tt.probe}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+	if _, err := DisableProbesPatch(probedDeployment(), nil); err == nil {
+		t.Error("Expected an error without probes")
+	}
+}
+
+func TestDisableContainerProbe(t *testing.T) {
+	clientset := fake.NewSimpleClientset(probedDeployment())
+
+	if err := DisableContainerProbe(context.Background(), clientset, "shop", "web", "web", ReadinessProbe); err != nil {
+		t.Fatalf("DisableContainerProbe failed: %v", err)
+	}
+	deployment, err := clientset.AppsV1().Deployments("shop").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	sidecar, web := deployment.Spec.Template.Spec.Containers[0], deployment.Spec.Template.Spec.Containers[1]
+	if web.ReadinessProbe != nil || web.LivenessProbe == nil || web.StartupProbe == nil {
+		t.Errorf("Expected only the web readiness probe to be removed, got %+v", web)
+	}
+	if sidecar.ReadinessProbe == nil || sidecar.LivenessProbe == nil {
+		t.Errorf("Expected the sidecar probes to be kept, got %+v", sidecar)
+	}
+
+	// Removing it again fails rather than patching another container
+	if err := DisableContainerProbe(context.Background(), clientset, "shop", "web", "web", ReadinessProbe); err == nil {
+		t.Error("Expected an error for a probe already removed")
+	}
+	if err := DisableContainerProbe(context.Background(), clientset, "shop", "missing", "web", LivenessProbe); err == nil {
+		t.Error("Expected an error for a missing deployment")
+	}
+}
+
+func TestPodDeployment(t *testing.T) {
+	deployment := probedDeployment()
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-5d9c",
+		Namespace:       "shop",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+	}}
+	clientset := fake.NewSimpleClientset(deployment, replicaSet)
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-5d9c-x7k2p",
+		Namespace:       "shop",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(replicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+	}}
+	if name, err := PodDeployment(context.Background(), clientset, pod); err != nil || name != "web" {
+		t.Errorf("Expected deployment web, got %q, %v", name, err)
+	}
+
+	bare := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "shop"}}
+	if _, err := PodDeployment(context.Background(), clientset, bare); !errors.Is(err, ErrNotDeploymentPod) {
+		t.Errorf("Expected ErrNotDeploymentPod for a bare pod, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/timefmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Prefixes of the messages of the kubelet's Unhealthy events
+const (
+	readinessFailurePrefix = "Readiness probe failed: "
+	livenessFailurePrefix  = "Liveness probe failed: "
+)
+
+// ContainerReadiness is the readiness of one container of a pod, with the
+// last failures of its readiness and liveness probes
+type ContainerReadiness struct {
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+	// ReadinessProbe and LivenessProbe describe the probes, e.g.
+	// "httpGet :8080/healthz", and are empty when there is none
+	ReadinessProbe string `json:"readinessProbe,omitempty"`
+	LivenessProbe  string `json:"livenessProbe,omitempty"`
+	// LastReadinessFailure and LastLivenessFailure are the messages of the
+	// latest Unhealthy events of the probes, without the "... probe failed: "
+	LastReadinessFailure     string       `json:"lastReadinessFailure,omitempty"`
+	LastReadinessFailureTime *metav1.Time `json:"lastReadinessFailureTime,omitempty"`
+	LastLivenessFailure      string       `json:"lastLivenessFailure,omitempty"`
+	LastLivenessFailureTime  *metav1.Time `json:"lastLivenessFailureTime,omitempty"`
+}
+
+// PodReadiness breaks down why a pod is or is not ready
+type PodReadiness struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Phase     v1.PodPhase `json:"phase"`
+	// Status is the phase, or the init progress of an initializing pod as
+	// PodStatusReason gives it, e.g. "Init:1/2"
+	Status string `json:"status"`
+	// PodIP is the primary IP of the pod, and PodIPs every IP of it on a
+	// dual-stack cluster
+	PodIP  string   `json:"podIP,omitempty"`
+	PodIPs []string `json:"podIPs,omitempty"`
+	// CreationTimestamp is when the pod was created, and AgeSeconds how long
+	// before now that was
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+	AgeSeconds        int64       `json:"ageSeconds"`
+	Ready             bool        `json:"ready"`
+	// ReadyContainers and TotalContainers count the app containers only;
+	// init containers, sidecars included, are in InitContainers
+	ReadyContainers int                 `json:"readyContainers"`
+	TotalContainers int                 `json:"totalContainers"`
+	InitContainers  []InitContainerStep `json:"initContainers,omitempty"`
+	// NotReady is set for running pods that are not ready, which still
+	// receive no traffic from services
+	NotReady bool `json:"notReady"`
+	// UnreadySince and UnreadySeconds say how long a NotReady pod has been
+	// unready
+	UnreadySince   *metav1.Time         `json:"unreadySince,omitempty"`
+	UnreadySeconds int64                `json:"unreadySeconds,omitempty"`
+	Containers     []ContainerReadiness `json:"containers"`
+}
+
+// DerivePodReadiness joins the container statuses of a pod with the probe
+// failures reported in events, which may include events of other objects
+func DerivePodReadiness(pod *v1.Pod, events []v1.Event, now time.Time) PodReadiness {
+	readiness := PodReadiness{
+		Name:              pod.Name,
+		Namespace:         pod.Namespace,
+		Phase:             pod.Status.Phase,
+		Status:            PodStatusReason(*pod),
+		PodIP:             pod.Status.PodIP,
+		CreationTimestamp: pod.CreationTimestamp,
+		AgeSeconds:        timefmt.AgeSeconds(pod.CreationTimestamp.Time, now),
+		TotalContainers:   len(pod.Spec.Containers),
+	}
+	if len(pod.Spec.InitContainers) > 0 {
+		readiness.InitContainers = GetInitContainerProgress(*pod)
+	}
+
+	for _, ip := range pod.Status.PodIPs {
+		readiness.PodIPs = append(readiness.PodIPs, ip.IP)
+	}
+
+	statuses := make(map[string]v1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+	for _, container := range pod.Spec.Containers {
+		status := statuses[container.Name]
+		if status.Ready {
+			readiness.ReadyContainers++
+		}
+		readiness.Containers = append(readiness.Containers, ContainerReadiness{
+			Name:           container.Name,
+			Ready:          status.Ready,
+			RestartCount:   status.RestartCount,
+			ReadinessProbe: describeProbe(container.ReadinessProbe),
+			LivenessProbe:  describeProbe(container.LivenessProbe),
+		})
+	}
+	addProbeFailures(readiness.Containers, pod, events)
+
+	var readyCondition *v1.PodCondition
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == v1.PodReady {
+			readyCondition = &pod.Status.Conditions[i]
+		}
+	}
+	readiness.Ready = readyCondition != nil && readyCondition.Status == v1.ConditionTrue
+	readiness.NotReady = pod.Status.Phase == v1.PodRunning && !readiness.Ready
+	if !readiness.NotReady {
+		return readiness
+	}
+
+	// The condition turned false when the pod became unready; a pod that has
+	// never reported it has been unready since it started
+	since := pod.Status.StartTime
+	if readyCondition != nil && !readyCondition.LastTransitionTime.IsZero() {
+		since = &readyCondition.LastTransitionTime
+	}
+	if since != nil {
+		readiness.UnreadySince = since
+		readiness.UnreadySeconds = int64(now.Sub(since.Time).Seconds())
+	}
+	return readiness
+}
+
+// IsUnreadyFor reports whether a pod is running but has not been ready for
+// longer than d at now
+func IsUnreadyFor(pod *v1.Pod, d time.Duration, now time.Time) bool {
+	readiness := DerivePodReadiness(pod, nil, now)
+	return readiness.NotReady && readiness.UnreadySince != nil && now.Sub(readiness.UnreadySince.Time) > d
+}
+
+// describeProbe describes the handler of a probe, e.g. "httpGet :8080/healthz"
+// or "exec", or returns an empty string for no probe
+func describeProbe(probe *v1.Probe) string {
+	switch {
+	case probe == nil:
+		return ""
+	case probe.HTTPGet != nil:
+		return fmt.Sprintf("httpGet :%s%s", probe.HTTPGet.Port.String(), probe.HTTPGet.Path)
+	case probe.TCPSocket != nil:
+		return fmt.Sprintf("tcpSocket :%s", probe.TCPSocket.Port.String())
+	case probe.GRPC != nil:
+		return fmt.Sprintf("grpc :%d", probe.GRPC.Port)
+	case probe.Exec != nil:
+		return "exec " + strings.Join(probe.Exec.Command, " ")
+	}
+	return "unknown"
+}
+
+// addProbeFailures sets the latest readiness and liveness failures of the
+// containers from the kubelet's Unhealthy events of the pod
+func addProbeFailures(containers []ContainerReadiness, pod *v1.Pod, events []v1.Event) {
+	byName := make(map[string]*ContainerReadiness, len(containers))
+	for i := range containers {
+		byName[containers[i].Name] = &containers[i]
+	}
+
+	for i := range events {
+		event := &events[i]
+		if event.Reason != "Unhealthy" || event.InvolvedObject.Kind != "Pod" ||
+			event.InvolvedObject.Namespace != pod.Namespace || event.InvolvedObject.Name != pod.Name {
+			continue
+		}
+		// The field path names the container, as "spec.containers{web}"
+		name := strings.TrimSuffix(strings.TrimPrefix(event.InvolvedObject.FieldPath, "spec.containers{"), "}")
+		container, ok := byName[name]
+		if !ok {
+			continue
+		}
+
+		at := eventTime(event)
+		switch {
+		case strings.HasPrefix(event.Message, readinessFailurePrefix):
+			if container.LastReadinessFailureTime == nil || at.After(container.LastReadinessFailureTime.Time) {
+				container.LastReadinessFailure = strings.TrimSpace(strings.TrimPrefix(event.Message, readinessFailurePrefix))
+				container.LastReadinessFailureTime = &at
+			}
+		case strings.HasPrefix(event.Message, livenessFailurePrefix):
+			if container.LastLivenessFailureTime == nil || at.After(container.LastLivenessFailureTime.Time) {
+				container.LastLivenessFailure = strings.TrimSpace(strings.TrimPrefix(event.Message, livenessFailurePrefix))
+				container.LastLivenessFailureTime = &at
+			}
+		}
+	}
+}
+
+// eventTime returns when an event last happened, from whichever of its
+// timestamps is set
+func eventTime(event *v1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.NewTime(event.EventTime.Time)
+	}
+	return event.FirstTimestamp
+}
+
+// ListPodEvents lists the events of a pod
+func ListPodEvents(ctx context.Context, clientset kubernetes.Interface, namespace, name string) ([]v1.Event, error) {
+	return ListEventsByInvolvedObject(ctx, clientset, "Pod", namespace, name)
+}
+
+// ListPodReadiness returns the readiness breakdown of every pod in a
+// namespace, or in every namespace when it is empty, ordered by name
+func ListPodReadiness(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]PodReadiness, error) {
+	pods, err := ListPods(clientset, namespace)
+	if err != nil {
+		return nil, err
+	}
+	selector := fields.Set{"involvedObject.kind": "Pod", "reason": "Unhealthy"}.AsSelector().String()
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		klog.Errorf("Failed to list events in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	readiness := make([]PodReadiness, 0, len(pods))
+	for i := range pods {
+		readiness = append(readiness, DerivePodReadiness(&pods[i], events.Items, now))
+	}
+	sort.Slice(readiness, func(i, j int) bool {
+		if readiness[i].Namespace != readiness[j].Namespace {
+			return readiness[i].Namespace < readiness[j].Namespace
+		}
+		return readiness[i].Name < readiness[j].Name
+	})
+	return readiness, nil
+}
@@ -0,0 +1,214 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// readinessTestPod returns a running pod with a web container probed over
+// HTTP and a sidecar without probes, ready as given
+func readinessTestPod(name string, webReady bool, readySince time.Time) *v1.Pod {
+	probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}}}
+	condition := v1.ConditionFalse
+	if webReady {
+		condition = v1.ConditionTrue
+	}
+	start := metav1.NewTime(readySince.Add(-time.Hour))
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "web", ReadinessProbe: probe, LivenessProbe: &v1.Probe{ProbeHandler: v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(8080)}}}},
+			{Name: "proxy"},
+		}},
+		Status: v1.PodStatus{
+			Phase:     v1.PodRunning,
+			StartTime: &start,
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: condition, LastTransitionTime: metav1.NewTime(readySince)},
+			},
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "web", Ready: webReady, RestartCount: 2},
+				{Name: "proxy", Ready: true},
+			},
+		},
+	}
+}
+
+// probeEvent returns an Unhealthy event of a container of a pod
+func probeEvent(pod, container, message string, at time.Time) v1.Event {
+	return v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: pod + "." + message, Namespace: "shop"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: pod, FieldPath: "spec.containers{" + container + "}"},
+		Reason:         "Unhealthy",
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestDerivePodReadinessFailingProbe(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pod := readinessTestPod("web-1", false, now.Add(-90*time.Second))
+	events := []v1.Event{
+		probeEvent("web-1", "web", "Readiness probe failed: HTTP probe failed with statuscode: 500", now.Add(-time.Minute)),
+		probeEvent("web-1", "web", "Readiness probe failed: HTTP probe failed with statuscode: 503", now.Add(-10*time.Second)),
+		probeEvent("web-1", "web", "Liveness probe failed: dial tcp 10.0.0.5:8080: connect: connection refused", now.Add(-20*time.Second)),
+		// Events of other pods are ignored
+		probeEvent("web-2", "web", "Readiness probe failed: timeout", now),
+	}
+
+	readiness := DerivePodReadiness(pod, events, now)
+	if readiness.Ready || !readiness.NotReady {
+		t.Errorf("Expected a running but not ready pod, got ready=%v notReady=%v", readiness.Ready, readiness.NotReady)
+	}
+	if readiness.ReadyContainers != 1 || readiness.TotalContainers != 2 {
+		t.Errorf("Expected 1/2 ready containers, got %d/%d", readiness.ReadyContainers, readiness.TotalContainers)
+	}
+	if readiness.UnreadySeconds != 90 || !readiness.UnreadySince.Time.Equal(now.Add(-90*time.Second)) {
+		t.Errorf("Expected the pod to be unready for 90s, got %ds since %v", readiness.UnreadySeconds, readiness.UnreadySince)
+	}
+
+	web := readiness.Containers[0]
+	if web.ReadinessProbe != "httpGet :8080/healthz" || web.LivenessProbe != "tcpSocket :8080" {
+		t.Errorf("Expected the probe types, got readiness %q and liveness %q", web.ReadinessProbe, web.LivenessProbe)
+	}
+	if web.LastReadinessFailure != "HTTP probe failed with statuscode: 503" || !web.LastReadinessFailureTime.Time.Equal(now.Add(-10*time.Second)) {
+		t.Errorf("Expected the latest readiness failure, got %q at %v", web.LastReadinessFailure, web.LastReadinessFailureTime)
+	}
+	if web.LastLivenessFailure != "dial tcp 10.0.0.5:8080: connect: connection refused" {
+		t.Errorf("Expected the liveness failure apart from readiness, got %q", web.LastLivenessFailure)
+	}
+	if web.Ready || web.RestartCount != 2 {
+		t.Errorf("Expected an unready web container with 2 restarts, got %+v", web)
+	}
+}
+
+func TestDerivePodReadinessSucceedingProbe(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pod := readinessTestPod("web-1", true, now.Add(-time.Hour))
+	pod.Status.PodIP = "10.0.0.5"
+	pod.Status.PodIPs = []v1.PodIP{{IP: "10.0.0.5"}, {IP: "fd00::5"}}
+	// A failure before the pod became ready is still reported
+	events := []v1.Event{probeEvent("web-1", "web", "Readiness probe failed: timeout", now.Add(-2*time.Hour))}
+
+	readiness := DerivePodReadiness(pod, events, now)
+	if !readiness.Ready || readiness.NotReady || readiness.UnreadySince != nil || readiness.UnreadySeconds != 0 {
+		t.Errorf("Expected a ready pod, got %+v", readiness)
+	}
+	if readiness.PodIP != "10.0.0.5" || len(readiness.PodIPs) != 2 || readiness.PodIPs[1] != "fd00::5" {
+		t.Errorf("Expected both IPs of the dual-stack pod, got %q and %v", readiness.PodIP, readiness.PodIPs)
+	}
+	if readiness.ReadyContainers != 2 || !readiness.Containers[0].Ready {
+		t.Errorf("Expected every container to be ready, got %+v", readiness.Containers)
+	}
+	if readiness.Containers[0].LastReadinessFailure != "timeout" {
+		t.Errorf("Expected the earlier failure, got %q", readiness.Containers[0].LastReadinessFailure)
+	}
+	if IsUnreadyFor(pod, time.Minute, now) {
+		t.Error("Expected a ready pod not to be unready")
+	}
+}
+
+func TestDerivePodReadinessWithoutProbes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	start := metav1.NewTime(now.Add(-30 * time.Second))
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch-1", Namespace: "shop"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "worker"}}},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			StartTime:         &start,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "worker"}},
+		},
+	}
+
+	readiness := DerivePodReadiness(pod, nil, now)
+	if !readiness.NotReady || readiness.UnreadySeconds != 30 {
+		t.Errorf("Expected a pod without a Ready condition to be unready since it started, got %+v", readiness)
+	}
+	worker := readiness.Containers[0]
+	if worker.ReadinessProbe != "" || worker.LivenessProbe != "" || worker.LastReadinessFailure != "" {
+		t.Errorf("Expected no probes or failures, got %+v", worker)
+	}
+	if IsUnreadyFor(pod, time.Minute, now) || !IsUnreadyFor(pod, time.Minute, now.Add(time.Minute)) {
+		t.Error("Expected the pod to count as unready once it has been for over a minute")
+	}
+
+	// Pending pods are not ready either, but are not NotReady
+	pod.Status.Phase = v1.PodPending
+	if readiness := DerivePodReadiness(pod, nil, now); readiness.NotReady {
+		t.Errorf("Expected a pending pod not to be NotReady, got %+v", readiness)
+	}
+}
+
+func TestDerivePodReadinessWithInitContainers(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	always := v1.ContainerRestartPolicyAlways
+	started := true
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "init-db"}, {Name: "proxy", RestartPolicy: &always}},
+			Containers:     []v1.Container{{Name: "web"}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			InitContainerStatuses: []v1.ContainerStatus{
+				{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}},
+				{Name: "proxy", Ready: true, Started: &started, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+			},
+			ContainerStatuses: []v1.ContainerStatus{{Name: "web", Ready: true}},
+		},
+	}
+
+	// The sidecar is listed with the init containers, not counted as an app
+	// container
+	readiness := DerivePodReadiness(pod, nil, now)
+	if readiness.Status != "Running" || readiness.ReadyContainers != 1 || readiness.TotalContainers != 1 || len(readiness.Containers) != 1 {
+		t.Errorf("Expected 1/1 app containers ready, got %+v", readiness)
+	}
+	if len(readiness.InitContainers) != 2 || readiness.InitContainers[0].State != InitContainerSucceeded ||
+		!readiness.InitContainers[1].Restartable || !readiness.InitContainers[1].Ready {
+		t.Errorf("Expected init-db succeeded and the proxy sidecar ready, got %+v", readiness.InitContainers)
+	}
+
+	// A pod stuck on an init container reports so in its status
+	pod.Status.Phase = v1.PodPending
+	pod.Status.InitContainerStatuses = []v1.ContainerStatus{{
+		Name:  "init-db",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+	}}
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{{Name: "web"}}
+	if readiness := DerivePodReadiness(pod, nil, now); readiness.Status != "Init:CrashLoopBackOff" || readiness.ReadyContainers != 0 {
+		t.Errorf("Expected Init:CrashLoopBackOff with no app container ready, got %+v", readiness)
+	}
+}
+
+func TestListPodReadiness(t *testing.T) {
+	now := time.Now()
+	event := probeEvent("web-1", "web", "Readiness probe failed: timeout", now)
+	clientset := fake.NewSimpleClientset(
+		readinessTestPod("web-2", true, now.Add(-time.Hour)),
+		readinessTestPod("web-1", false, now.Add(-time.Minute)),
+		&event,
+	)
+
+	readiness, err := ListPodReadiness(context.Background(), clientset, "shop")
+	if err != nil {
+		t.Fatalf("ListPodReadiness failed: %v", err)
+	}
+	if len(readiness) != 2 || readiness[0].Name != "web-1" || readiness[1].Name != "web-2" {
+		t.Fatalf("Expected web-1 and web-2, got %+v", readiness)
+	}
+	if !readiness[0].NotReady || readiness[0].Containers[0].LastReadinessFailure != "timeout" {
+		t.Errorf("Expected web-1 to be unready with its probe failure, got %+v", readiness[0])
+	}
+	if readiness[1].NotReady {
+		t.Errorf("Expected web-2 to be ready, got %+v", readiness[1])
+	}
+}
@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// deploymentPausedReason is the reason of the Progressing condition the
+// deployment controller sets while a deployment is paused
+const deploymentPausedReason = "DeploymentPaused"
+
+// progressDeadlineExceededReason is the reason of the Progressing condition
+// of a deployment whose rollout stopped progressing for longer than its
+// progressDeadlineSeconds
+const progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+
+// PauseDeployment pauses the rollouts of a deployment, like kubectl rollout
+// pause: changes to its pod template no longer roll out until it is resumed
+func PauseDeployment(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	return setDeploymentPaused(ctx, clientset, namespace, name, true)
+}
+
+// ResumeDeployment resumes the rollouts of a paused deployment, like kubectl
+// rollout resume
+func ResumeDeployment(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	return setDeploymentPaused(ctx, clientset, namespace, name, false)
+}
+
+// setDeploymentPaused sets spec.paused of a deployment with a merge patch
+func setDeploymentPaused(ctx context.Context, clientset kubernetes.Interface, namespace, name string, paused bool) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, paused))
+	_, err := clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to set paused=%t on deployment %s/%s: %v", paused, namespace, name, err)
+		return err
+	}
+	return nil
+}
+
+// DeploymentPausedSince returns when a deployment was paused, from the
+// Progressing condition the deployment controller sets when it sees the
+// pause. It returns false for a deployment that is not paused, and the zero
+// time for one the controller has not seen paused yet.
+func DeploymentPausedSince(deployment *appsv1.Deployment) (time.Time, bool) {
+	if !deployment.Spec.Paused {
+		return time.Time{}, false
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type != appsv1.DeploymentProgressing || condition.Reason != deploymentPausedReason {
+			continue
+		}
+		if !condition.LastTransitionTime.IsZero() {
+			return condition.LastTransitionTime.Time, true
+		}
+		return condition.LastUpdateTime.Time, true
+	}
+	return time.Time{}, true
+}
+
+// RolloutReplicaSet is a ReplicaSet of a deployment with its rollout revision
+type RolloutReplicaSet struct {
+	appsv1.ReplicaSet
+	Revision int64
+	// Current is set on the ReplicaSet of the deployment's current revision
+	Current bool
+}
+
+// DeploymentRolloutStatus is the progress of the rollout of a deployment
+type DeploymentRolloutStatus struct {
+	Deployment *appsv1.Deployment
+	// ReplicaSets are the ReplicaSets the deployment controls, the newest
+	// revision first
+	ReplicaSets []RolloutReplicaSet
+	Complete    bool
+	// Failed is set once the rollout exceeded its progress deadline
+	Failed  bool
+	Message string
+}
+
+// GetDeploymentRolloutStatus returns the rollout progress of a deployment
+// with its ReplicaSets
+func GetDeploymentRolloutStatus(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*DeploymentRolloutStatus, error) {
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get deployment %s/%s: %v", namespace, name, err)
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s: %v", name, err)
+	}
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		klog.Errorf("Failed to list replicasets for deployment %s/%s: %v", namespace, name, err)
+		return nil, err
+	}
+
+	rolloutStatus := &DeploymentRolloutStatus{Deployment: deployment}
+	rolloutStatus.Complete, rolloutStatus.Failed, rolloutStatus.Message = DeploymentRolloutProgress(deployment)
+	for _, rs := range replicaSets.Items {
+		if !metav1.IsControlledBy(&rs, deployment) {
+			continue
+		}
+		revision, _ := strconv.ParseInt(rs.Annotations[revisionAnnotation], 10, 64)
+		rolloutStatus.ReplicaSets = append(rolloutStatus.ReplicaSets, RolloutReplicaSet{
+			ReplicaSet: rs,
+			Revision:   revision,
+			Current:    rs.Annotations[revisionAnnotation] != "" && rs.Annotations[revisionAnnotation] == deployment.Annotations[revisionAnnotation],
+		})
+	}
+	sort.Slice(rolloutStatus.ReplicaSets, func(i, j int) bool {
+		return rolloutStatus.ReplicaSets[i].Revision > rolloutStatus.ReplicaSets[j].Revision
+	})
+	return rolloutStatus, nil
+}
+
+// DeploymentRolloutProgress reports whether the rollout of a deployment is
+// complete or failed, and what it waits for, with the checks and messages of
+// kubectl rollout status
+func DeploymentRolloutProgress(deployment *appsv1.Deployment) (complete, failed bool, message string) {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false, false, "Waiting for deployment spec update to be observed..."
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == progressDeadlineExceededReason {
+			return false, true, fmt.Sprintf("deployment %q exceeded its progress deadline", deployment.Name)
+		}
+	}
+	status := deployment.Status
+	if deployment.Spec.Replicas != nil && status.UpdatedReplicas < *deployment.Spec.Replicas {
+		return false, false, fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...",
+			deployment.Name, status.UpdatedReplicas, *deployment.Spec.Replicas)
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return false, false, fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...",
+			deployment.Name, status.Replicas-status.UpdatedReplicas)
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return false, false, fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...",
+			deployment.Name, status.AvailableReplicas, status.UpdatedReplicas)
+	}
+	return true, false, fmt.Sprintf("deployment %q successfully rolled out", deployment.Name)
+}
@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPauseResumeDeployment(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}})
+	paused := func() bool {
+		deployment, err := clientset.AppsV1().Deployments("shop").Get(context.Background(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		return deployment.Spec.Paused
+	}
+
+	if err := PauseDeployment(context.Background(), clientset, "shop", "web"); err != nil {
+		t.Fatalf("PauseDeployment failed: %v", err)
+	}
+	patch := clientset.Actions()[len(clientset.Actions())-1].(k8stesting.PatchAction)
+	if string(patch.GetPatch()) != `{"spec":{"paused":true}}` {
+		t.Errorf("Unexpected pause patch %s", patch.GetPatch())
+	}
+	if !paused() {
+		t.Error("Expected the deployment to be paused")
+	}
+
+	if err := ResumeDeployment(context.Background(), clientset, "shop", "web"); err != nil {
+		t.Fatalf("ResumeDeployment failed: %v", err)
+	}
+	if paused() {
+		t.Error("Expected the deployment to be resumed")
+	}
+
+	if err := PauseDeployment(context.Background(), clientset, "shop", "missing"); err == nil {
+		t.Error("Expected an error for a missing deployment")
+	}
+}
+
+func TestDeploymentPausedSince(t *testing.T) {
+	pausedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	progressing := func(reason string) appsv1.DeploymentCondition {
+		return appsv1.DeploymentCondition{
+			Type:               appsv1.DeploymentProgressing,
+			Status:             v1.ConditionUnknown,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(pausedAt),
+		}
+	}
+	deployment := func(paused bool, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+		return &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Paused: paused}, Status: appsv1.DeploymentStatus{Conditions: conditions}}
+	}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		since      time.Time
+		paused     bool
+	}{
+		{"running", deployment(false, progressing("NewReplicaSetAvailable")), time.Time{}, false},
+		{"paused", deployment(true, progressing(deploymentPausedReason)), pausedAt, true},
+		{"not seen by the controller yet", deployment(true, progressing("NewReplicaSetAvailable")), time.Time{}, true},
+		{"resumed but not seen yet", deployment(false, progressing(deploymentPausedReason)), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		since, paused := DeploymentPausedSince(tt.deployment)
+		if !since.Equal(tt.since) || paused != tt.paused {
+			t.Errorf("%s: expected %v, %t, got %v, %t", tt.name, tt.since, tt.paused, since, paused)
+		}
+	}
+}
+
+func TestDeploymentRolloutProgress(t *testing.T) {
+	replicas := int32(3)
+	deployment := func(generation, observed int64, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: generation},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: observed, Replicas: status.Replicas, UpdatedReplicas: status.UpdatedReplicas, AvailableReplicas: status.AvailableReplicas, Conditions: status.Conditions},
+		}
+	}
+	tests := []struct {
+		name             string
+		deployment       *appsv1.Deployment
+		complete, failed bool
+		message          string
+	}{
+		{"not observed", deployment(2, 1, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}), false, false, "spec update to be observed"},
+		{"updating", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1}), false, false, "1 out of 3 new replicas have been updated"},
+		{"old replicas", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3}), false, false, "1 old replicas are pending termination"},
+		{"unavailable", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 2}), false, false, "2 of 3 updated replicas are available"},
+		{"complete", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}), true, false, "successfully rolled out"},
+		{"deadline exceeded", deployment(2, 2, appsv1.DeploymentStatus{Replicas: 4, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+		}}), false, true, "exceeded its progress deadline"},
+	}
+	for _, tt := range tests {
+		complete, failed, message := DeploymentRolloutProgress(tt.deployment)
+		if complete != tt.complete || failed != tt.failed || !strings.Contains(message, tt.message) {
+			t.Errorf("%s: expected complete=%t failed=%t with %q, got %t, %t, %q", tt.name, tt.complete, tt.failed, tt.message, complete, failed, message)
+		}
+	}
+}
+
+func TestGetDeploymentRolloutStatus(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid", Annotations: map[string]string{"deployment.kubernetes.io/revision": "2"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2},
+	}
+	replicaSet := func(name, revision string, owned bool) *appsv1.ReplicaSet {
+		rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default",
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": revision},
+		}}
+		if owned {
+			rs.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))}
+		}
+		return rs
+	}
+	clientset := fake.NewSimpleClientset(deployment,
+		replicaSet("web-1", "1", true), replicaSet("web-2", "2", true), replicaSet("stray", "9", false))
+
+	rolloutStatus, err := GetDeploymentRolloutStatus(context.Background(), clientset, "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeploymentRolloutStatus failed: %v", err)
+	}
+	if rolloutStatus.Complete || !strings.Contains(rolloutStatus.Message, "1 out of 2") {
+		t.Errorf("Expected the rollout in progress, got %+v", rolloutStatus)
+	}
+	if len(rolloutStatus.ReplicaSets) != 2 {
+		t.Fatalf("Expected the 2 owned ReplicaSets, got %+v", rolloutStatus.ReplicaSets)
+	}
+	if rs := rolloutStatus.ReplicaSets[0]; rs.Name != "web-2" || rs.Revision != 2 || !rs.Current {
+		t.Errorf("Expected the current revision first, got %+v", rs)
+	}
+	if rs := rolloutStatus.ReplicaSets[1]; rs.Name != "web-1" || rs.Current {
+		t.Errorf("Expected the old revision last, got %+v", rs)
+	}
+
+	if _, err := GetDeploymentRolloutStatus(context.Background(), clientset, "default", "missing"); err == nil {
+		t.Error("Expected a missing deployment to fail")
+	}
+}
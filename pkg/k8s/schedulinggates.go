@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// ReadinessGateStatus is a readiness gate of a pod with the status of its
+// condition, which is Unknown until a controller reports it
+type ReadinessGateStatus struct {
+	ConditionType v1.PodConditionType `json:"conditionType"`
+	Status        v1.ConditionStatus  `json:"status"`
+}
+
+// IsSchedulingGated reports whether the scheduler is holding a pod back
+// because of its scheduling gates
+func IsSchedulingGated(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled {
+			return condition.Status == v1.ConditionFalse && condition.Reason == v1.PodReasonSchedulingGated
+		}
+	}
+	// Pods created with gates have the condition set straight away, but fall
+	// back to the spec for API servers that have not reported it yet
+	return len(pod.Spec.SchedulingGates) > 0 && pod.Spec.NodeName == ""
+}
+
+// GetReadinessGates returns the readiness gates of a pod with the status of
+// their conditions
+func GetReadinessGates(pod *v1.Pod) []ReadinessGateStatus {
+	gates := make([]ReadinessGateStatus, 0, len(pod.Spec.ReadinessGates))
+	for _, gate := range pod.Spec.ReadinessGates {
+		status := ReadinessGateStatus{ConditionType: gate.ConditionType, Status: v1.ConditionUnknown}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == gate.ConditionType {
+				status.Status = condition.Status
+			}
+		}
+		gates = append(gates, status)
+	}
+	return gates
+}
+
+// ClearSchedulingGate removes a scheduling gate from a pod with a strategic
+// merge patch, leaving its other gates in place. Once the last gate is gone
+// the scheduler considers the pod.
+func ClearSchedulingGate(ctx context.Context, clientset kubernetes.Interface, namespace, podName, gateName string) error {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s in namespace %s: %v", podName, namespace, err)
+		return err
+	}
+	found := false
+	for _, gate := range pod.Spec.SchedulingGates {
+		found = found || gate.Name == gateName
+	}
+	if !found {
+		return fmt.Errorf("pod %s/%s has no scheduling gate %q", namespace, podName, gateName)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"schedulingGates": []map[string]string{{"$patch": "delete", "name": gateName}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build the scheduling gate patch: %v", err)
+	}
+	_, err = clientset.CoreV1().Pods(namespace).Patch(ctx, podName, types.StrategicMergePatchType, patch, metav1.PatchOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to clear scheduling gate %s of pod %s in namespace %s: %v", gateName, podName, namespace, err)
+		return err
+	}
+	return nil
+}
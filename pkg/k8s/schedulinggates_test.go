@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newGatedPod() *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch-1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers:      []v1.Container{{Name: "app", Image: "busybox"}},
+			SchedulingGates: []v1.PodSchedulingGate{{Name: "example.com/quota"}, {Name: "example.com/data"}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			Conditions: []v1.PodCondition{{
+				Type:   v1.PodScheduled,
+				Status: v1.ConditionFalse,
+				Reason: v1.PodReasonSchedulingGated,
+			}},
+		},
+	}
+}
+
+func TestIsSchedulingGated(t *testing.T) {
+	gated := newGatedPod()
+	if !IsSchedulingGated(gated) {
+		t.Error("Expected a pod with the SchedulingGated condition to be gated")
+	}
+
+	unschedulable := newGatedPod()
+	unschedulable.Spec.SchedulingGates = nil
+	unschedulable.Status.Conditions[0].Reason = v1.PodReasonUnschedulable
+	if IsSchedulingGated(unschedulable) {
+		t.Error("Expected an unschedulable pod not to be gated")
+	}
+
+	reported := newGatedPod()
+	reported.Status.Conditions = nil
+	if !IsSchedulingGated(reported) {
+		t.Error("Expected a pod with gates and no condition yet to be gated")
+	}
+}
+
+func TestGetReadinessGates(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{ReadinessGates: []v1.PodReadinessGate{
+			{ConditionType: "example.com/lb-ready"},
+			{ConditionType: "example.com/warm"},
+		}},
+		Status: v1.PodStatus{Conditions: []v1.PodCondition{
+			{Type: v1.PodReady, Status: v1.ConditionFalse},
+			{Type: "example.com/lb-ready", Status: v1.ConditionTrue},
+		}},
+	}
+	gates := GetReadinessGates(pod)
+	want := []ReadinessGateStatus{
+		{ConditionType: "example.com/lb-ready", Status: v1.ConditionTrue},
+		{ConditionType: "example.com/warm", Status: v1.ConditionUnknown},
+	}
+	if len(gates) != len(want) || gates[0] != want[0] || gates[1] != want[1] {
+		t.Errorf("Expected %+v, got %+v", want, gates)
+	}
+}
+
+func TestClearSchedulingGate(t *testing.T) {
+	clientset := fake.NewSimpleClientset(newGatedPod())
+	ctx := context.Background()
+
+	if err := ClearSchedulingGate(ctx, clientset, "default", "batch-1", "example.com/quota"); err != nil {
+		t.Fatalf("Failed to clear the gate: %v", err)
+	}
+	pod, err := clientset.CoreV1().Pods("default").Get(ctx, "batch-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get the pod: %v", err)
+	}
+	if len(pod.Spec.SchedulingGates) != 1 || pod.Spec.SchedulingGates[0].Name != "example.com/data" {
+		t.Errorf("Expected only the data gate to remain, got %+v", pod.Spec.SchedulingGates)
+	}
+	if len(pod.Spec.Containers) != 1 {
+		t.Errorf("Expected the rest of the spec to be kept, got %+v", pod.Spec)
+	}
+
+	err = ClearSchedulingGate(ctx, clientset, "default", "batch-1", "example.com/quota")
+	if err == nil || !strings.Contains(err.Error(), "no scheduling gate") {
+		t.Errorf("Expected an error for a gate that is gone, got %v", err)
+	}
+	if err := ClearSchedulingGate(ctx, clientset, "default", "missing", "example.com/data"); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
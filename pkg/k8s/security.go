@@ -0,0 +1,225 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// podSecurityLabelPrefix starts the pod security admission labels of a
+	// namespace, e.g. pod-security.kubernetes.io/enforce
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	// The annotations setting seccomp and AppArmor profiles before the
+	// securityContext fields did
+	seccompPodAnnotation              = "seccomp.security.alpha.kubernetes.io/pod"
+	seccompContainerAnnotationPrefix  = "container.seccomp.security.alpha.kubernetes.io/"
+	appArmorContainerAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+// ContainerSecurity is the security context a container effectively runs
+// with, its own settings overriding those of its pod
+type ContainerSecurity struct {
+	Name string `json:"name"`
+	Init bool   `json:"init,omitempty"`
+	// RunAsUser and RunAsNonRoot are nil when neither the container nor
+	// the pod sets them, leaving them to the image
+	RunAsUser    *int64 `json:"runAsUser,omitempty"`
+	RunAsNonRoot *bool  `json:"runAsNonRoot,omitempty"`
+	Privileged   bool   `json:"privileged"`
+	// CapabilitiesAdded and CapabilitiesDropped are upper case, without the
+	// CAP_ prefix
+	CapabilitiesAdded   []string `json:"capabilitiesAdded,omitempty"`
+	CapabilitiesDropped []string `json:"capabilitiesDropped,omitempty"`
+	// SeccompProfile is e.g. "RuntimeDefault" or "Localhost/profiles/audit.json",
+	// and AppArmorProfile e.g. "runtime/default"; empty when not set
+	SeccompProfile  string `json:"seccompProfile,omitempty"`
+	AppArmorProfile string `json:"appArmorProfile,omitempty"`
+}
+
+// HostPathVolume is a hostPath volume of a pod, writable when a container
+// mounts it without readOnly
+type HostPathVolume struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Writable bool   `json:"writable"`
+}
+
+// PodSecurity summarizes what a pod may do on its node
+type PodSecurity struct {
+	HostNetwork     bool                `json:"hostNetwork"`
+	HostPID         bool                `json:"hostPID"`
+	HostIPC         bool                `json:"hostIPC"`
+	HostPathVolumes []HostPathVolume    `json:"hostPathVolumes,omitempty"`
+	Containers      []ContainerSecurity `json:"containers"`
+	// PodSecurityLabels are the pod security admission labels of the pod's
+	// namespace, e.g. "enforce": "restricted", when it could be read
+	PodSecurityLabels map[string]string `json:"podSecurityLabels,omitempty"`
+	// Risks name privileged containers, containers adding CAP_SYS_ADMIN and
+	// writable hostPath volumes
+	Risks []string `json:"risks,omitempty"`
+}
+
+// EffectiveContainerSecurity resolves the security context of a container of
+// a pod: the container's securityContext wins over the pod's, which wins over
+// the seccomp annotations predating both
+func EffectiveContainerSecurity(pod *v1.Pod, container *v1.Container) ContainerSecurity {
+	security := ContainerSecurity{Name: container.Name}
+	podContext := pod.Spec.SecurityContext
+	if podContext == nil {
+		podContext = &v1.PodSecurityContext{}
+	}
+	containerContext := container.SecurityContext
+	if containerContext == nil {
+		containerContext = &v1.SecurityContext{}
+	}
+
+	security.RunAsUser = podContext.RunAsUser
+	if containerContext.RunAsUser != nil {
+		security.RunAsUser = containerContext.RunAsUser
+	}
+	security.RunAsNonRoot = podContext.RunAsNonRoot
+	if containerContext.RunAsNonRoot != nil {
+		security.RunAsNonRoot = containerContext.RunAsNonRoot
+	}
+	security.Privileged = containerContext.Privileged != nil && *containerContext.Privileged
+	if containerContext.Capabilities != nil {
+		security.CapabilitiesAdded = normalizeCapabilities(containerContext.Capabilities.Add)
+		security.CapabilitiesDropped = normalizeCapabilities(containerContext.Capabilities.Drop)
+	}
+
+	switch {
+	case containerContext.SeccompProfile != nil:
+		security.SeccompProfile = formatSeccompProfile(containerContext.SeccompProfile)
+	case podContext.SeccompProfile != nil:
+		security.SeccompProfile = formatSeccompProfile(podContext.SeccompProfile)
+	case pod.Annotations[seccompContainerAnnotationPrefix+container.Name] != "":
+		security.SeccompProfile = pod.Annotations[seccompContainerAnnotationPrefix+container.Name]
+	default:
+		security.SeccompProfile = pod.Annotations[seccompPodAnnotation]
+	}
+	security.AppArmorProfile = pod.Annotations[appArmorContainerAnnotationPrefix+container.Name]
+	return security
+}
+
+// normalizeCapabilities returns capabilities upper case without the CAP_
+// prefix, which the runtime accepts either way
+func normalizeCapabilities(capabilities []v1.Capability) []string {
+	if len(capabilities) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		names = append(names, strings.TrimPrefix(strings.ToUpper(string(capability)), "CAP_"))
+	}
+	return names
+}
+
+func formatSeccompProfile(profile *v1.SeccompProfile) string {
+	if profile.Type == v1.SeccompProfileTypeLocalhost && profile.LocalhostProfile != nil {
+		return fmt.Sprintf("%s/%s", profile.Type, *profile.LocalhostProfile)
+	}
+	return string(profile.Type)
+}
+
+// GetPodSecurity summarizes the security of a pod and its init and app
+// containers. namespace may be nil when it could not be read, leaving out
+// its pod security admission labels.
+func GetPodSecurity(pod *v1.Pod, namespace *v1.Namespace) PodSecurity {
+	security := PodSecurity{
+		HostNetwork: pod.Spec.HostNetwork,
+		HostPID:     pod.Spec.HostPID,
+		HostIPC:     pod.Spec.HostIPC,
+	}
+
+	containers := make([]v1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	writable := make(map[string][]string)
+	for i := range containers {
+		container := &containers[i]
+		effective := EffectiveContainerSecurity(pod, container)
+		effective.Init = i < len(pod.Spec.InitContainers)
+		security.Containers = append(security.Containers, effective)
+
+		if effective.Privileged {
+			security.Risks = append(security.Risks, fmt.Sprintf("container %s is privileged", container.Name))
+		}
+		for _, capability := range effective.CapabilitiesAdded {
+			if capability == "SYS_ADMIN" || capability == "ALL" {
+				security.Risks = append(security.Risks, fmt.Sprintf("container %s adds CAP_%s", container.Name, capability))
+			}
+		}
+		for _, mount := range container.VolumeMounts {
+			if !mount.ReadOnly {
+				writable[mount.Name] = append(writable[mount.Name], container.Name)
+			}
+		}
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath == nil {
+			continue
+		}
+		writers := writable[volume.Name]
+		security.HostPathVolumes = append(security.HostPathVolumes, HostPathVolume{
+			Name:     volume.Name,
+			Path:     volume.HostPath.Path,
+			Writable: len(writers) > 0,
+		})
+		if len(writers) > 0 {
+			security.Risks = append(security.Risks, fmt.Sprintf("hostPath volume %s (%s) is writable by %s", volume.Name, volume.HostPath.Path, strings.Join(writers, ", ")))
+		}
+	}
+
+	if namespace != nil {
+		for key, value := range namespace.Labels {
+			if mode, ok := strings.CutPrefix(key, podSecurityLabelPrefix); ok {
+				if security.PodSecurityLabels == nil {
+					security.PodSecurityLabels = make(map[string]string)
+				}
+				security.PodSecurityLabels[mode] = value
+			}
+		}
+	}
+	return security
+}
+
+// FormatPodSecurityLabels formats pod security admission labels as e.g.
+// "enforce=restricted, warn=baseline", in order
+func FormatPodSecurityLabels(labels map[string]string) string {
+	modes := make([]string, 0, len(labels))
+	for mode := range labels {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	for i, mode := range modes {
+		modes[i] = mode + "=" + labels[mode]
+	}
+	return strings.Join(modes, ", ")
+}
+
+// GetPodSecurityByName reads a pod and its namespace and summarizes the pod's
+// security. A namespace the caller may not read leaves out its labels.
+func GetPodSecurityByName(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*v1.Pod, PodSecurity, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get pod %s/%s: %v", namespace, name, err)
+		return nil, PodSecurity{}, err
+	}
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsForbidden(err) && !apierrors.IsNotFound(err) {
+			klog.Warningf("Failed to get namespace %s for its pod security labels: %v", namespace, err)
+		}
+		ns = nil
+	}
+	return pod, GetPodSecurity(pod, ns), nil
+}
@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int64Ptr(i int64) *int64 { return &i }
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestEffectiveContainerSecurity(t *testing.T) {
+	localhost := "profiles/audit.json"
+	tests := []struct {
+		name      string
+		pod       v1.PodSecurityContext
+		container *v1.SecurityContext
+		annotated map[string]string
+		want      ContainerSecurity
+	}{
+		{"nothing set", v1.PodSecurityContext{}, nil, nil, ContainerSecurity{Name: "app"}},
+		{
+			"pod level only",
+			v1.PodSecurityContext{RunAsUser: int64Ptr(1000), RunAsNonRoot: boolPtr(true), SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault}},
+			nil, nil,
+			ContainerSecurity{Name: "app", RunAsUser: int64Ptr(1000), RunAsNonRoot: boolPtr(true), SeccompProfile: "RuntimeDefault"},
+		},
+		{
+			"container overrides pod",
+			v1.PodSecurityContext{RunAsUser: int64Ptr(1000), RunAsNonRoot: boolPtr(true), SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault}},
+			&v1.SecurityContext{RunAsUser: int64Ptr(0), RunAsNonRoot: boolPtr(false), SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhost}},
+			nil,
+			ContainerSecurity{Name: "app", RunAsUser: int64Ptr(0), RunAsNonRoot: boolPtr(false), SeccompProfile: "Localhost/profiles/audit.json"},
+		},
+		{
+			"container sets only some fields",
+			v1.PodSecurityContext{RunAsUser: int64Ptr(1000), RunAsNonRoot: boolPtr(true)},
+			&v1.SecurityContext{RunAsUser: int64Ptr(2000)},
+			nil,
+			ContainerSecurity{Name: "app", RunAsUser: int64Ptr(2000), RunAsNonRoot: boolPtr(true)},
+		},
+		{
+			"privileged with capabilities",
+			v1.PodSecurityContext{},
+			&v1.SecurityContext{Privileged: boolPtr(true), Capabilities: &v1.Capabilities{Add: []v1.Capability{"CAP_SYS_ADMIN", "net_admin"}, Drop: []v1.Capability{"ALL"}}},
+			nil,
+			ContainerSecurity{Name: "app", Privileged: true, CapabilitiesAdded: []string{"SYS_ADMIN", "NET_ADMIN"}, CapabilitiesDropped: []string{"ALL"}},
+		},
+		{
+			"annotations",
+			v1.PodSecurityContext{}, nil,
+			map[string]string{
+				"seccomp.security.alpha.kubernetes.io/pod":           "docker/default",
+				"container.apparmor.security.beta.kubernetes.io/app": "runtime/default",
+			},
+			ContainerSecurity{Name: "app", SeccompProfile: "docker/default", AppArmorProfile: "runtime/default"},
+		},
+		{
+			"container annotation over pod annotation",
+			v1.PodSecurityContext{}, nil,
+			map[string]string{
+				"seccomp.security.alpha.kubernetes.io/pod":               "docker/default",
+				"container.seccomp.security.alpha.kubernetes.io/app":     "unconfined",
+				"container.apparmor.security.beta.kubernetes.io/sidecar": "unconfined",
+			},
+			ContainerSecurity{Name: "app", SeccompProfile: "unconfined"},
+		},
+		{
+			"field over annotation",
+			v1.PodSecurityContext{SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeUnconfined}}, nil,
+			map[string]string{"container.seccomp.security.alpha.kubernetes.io/app": "docker/default"},
+			ContainerSecurity{Name: "app", SeccompProfile: "Unconfined"},
+		},
+	}
+	for _, tt := range tests {
+		podContext := tt.pod
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotated},
+			Spec:       v1.PodSpec{SecurityContext: &podContext},
+		}
+		container := &v1.Container{Name: "app", SecurityContext: tt.container}
+		if got := EffectiveContainerSecurity(pod, container); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+
+	// A pod without a securityContext leaves everything unset
+	if got := EffectiveContainerSecurity(&v1.Pod{}, &v1.Container{Name: "app"}); !reflect.DeepEqual(got, ContainerSecurity{Name: "app"}) {
+		t.Errorf("Expected nothing set, got %+v", got)
+	}
+}
+
+func TestGetPodSecurity(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			HostNetwork: true,
+			HostPID:     true,
+			InitContainers: []v1.Container{{
+				Name:            "setup",
+				SecurityContext: &v1.SecurityContext{Capabilities: &v1.Capabilities{Add: []v1.Capability{"SYS_ADMIN"}}},
+				VolumeMounts:    []v1.VolumeMount{{Name: "host-logs", MountPath: "/logs", ReadOnly: true}},
+			}},
+			Containers: []v1.Container{{
+				Name:            "agent",
+				SecurityContext: &v1.SecurityContext{Privileged: boolPtr(true)},
+				VolumeMounts: []v1.VolumeMount{
+					{Name: "host-root", MountPath: "/host"},
+					{Name: "host-logs", MountPath: "/logs", ReadOnly: true},
+				},
+			}},
+			Volumes: []v1.Volume{
+				{Name: "host-root", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/"}}},
+				{Name: "host-logs", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/var/log"}}},
+				{Name: "scratch", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+		"pod-security.kubernetes.io/enforce": "privileged",
+		"pod-security.kubernetes.io/warn":    "baseline",
+		"team":                               "ops",
+	}}}
+
+	security := GetPodSecurity(pod, namespace)
+	if !security.HostNetwork || !security.HostPID || security.HostIPC {
+		t.Errorf("Expected hostNetwork and hostPID, got %+v", security)
+	}
+	if len(security.Containers) != 2 || !security.Containers[0].Init || security.Containers[1].Init || !security.Containers[1].Privileged {
+		t.Errorf("Expected the init container then the privileged one, got %+v", security.Containers)
+	}
+	wantVolumes := []HostPathVolume{{Name: "host-root", Path: "/", Writable: true}, {Name: "host-logs", Path: "/var/log"}}
+	if !reflect.DeepEqual(security.HostPathVolumes, wantVolumes) {
+		t.Errorf("Expected %+v, got %+v", wantVolumes, security.HostPathVolumes)
+	}
+	wantRisks := []string{
+		"container setup adds CAP_SYS_ADMIN",
+		"container agent is privileged",
+		"hostPath volume host-root (/) is writable by agent",
+	}
+	if !reflect.DeepEqual(security.Risks, wantRisks) {
+		t.Errorf("Expected %v, got %v", wantRisks, security.Risks)
+	}
+	if got := FormatPodSecurityLabels(security.PodSecurityLabels); got != "enforce=privileged, warn=baseline" {
+		t.Errorf("Expected the pod security labels, got %q", got)
+	}
+
+	if security := GetPodSecurity(&v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app"}}}}, nil); len(security.Risks) != 0 || security.PodSecurityLabels != nil {
+		t.Errorf("Expected no risks and no labels, got %+v", security)
+	}
+}
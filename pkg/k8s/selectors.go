@@ -0,0 +1,58 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SelectorPreview is what a label selector would select among the pods and
+// deployments of a namespace
+type SelectorPreview struct {
+	Selector string `json:"selector"`
+	// MatchesEverything is set for an empty selector, which selects every
+	// pod rather than none
+	MatchesEverything bool     `json:"matchesEverything"`
+	Pods              []string `json:"pods"`
+	// Deployments are those whose pod template labels match, so that the
+	// pods they will create are selected as well
+	Deployments []string `json:"deployments"`
+}
+
+// ParseSelector parses a label selector as kubectl -l takes it: equality
+// (app=web, tier!=db) and set-based (env in (prod,staging), !canary)
+// requirements, comma-separated
+func ParseSelector(selector string) (labels.Selector, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %v", selector, err)
+	}
+	return parsed, nil
+}
+
+// PreviewSelector returns the names of the pods and deployments a selector
+// matches, sorted. The lists should be those of one namespace.
+func PreviewSelector(selector labels.Selector, pods []v1.Pod, deployments []appsv1.Deployment) SelectorPreview {
+	preview := SelectorPreview{
+		Selector:          selector.String(),
+		MatchesEverything: selector.Empty(),
+		Pods:              []string{},
+		Deployments:       []string{},
+	}
+	for _, pod := range pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			preview.Pods = append(preview.Pods, pod.Name)
+		}
+	}
+	for _, deployment := range deployments {
+		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			preview.Deployments = append(preview.Deployments, deployment.Name)
+		}
+	}
+	sort.Strings(preview.Pods)
+	sort.Strings(preview.Deployments)
+	return preview
+}
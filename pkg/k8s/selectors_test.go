@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreviewSelector(t *testing.T) {
+	pod := func(name string, labels map[string]string) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	pods := []v1.Pod{
+		pod("web-2", map[string]string{"app": "web", "env": "prod"}),
+		pod("web-1", map[string]string{"app": "web", "env": "staging"}),
+		pod("web-canary", map[string]string{"app": "web", "env": "prod", "canary": "true"}),
+		pod("db-0", map[string]string{"app": "db", "env": "prod"}),
+		pod("scratch", nil),
+	}
+	deployment := func(name string, labels map[string]string) appsv1.Deployment {
+		d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		d.Spec.Template.Labels = labels
+		return d
+	}
+	deployments := []appsv1.Deployment{
+		deployment("web", map[string]string{"app": "web", "env": "prod"}),
+		deployment("db", map[string]string{"app": "db", "env": "prod"}),
+	}
+
+	tests := []struct {
+		selector    string
+		pods        []string
+		deployments []string
+	}{
+		{"app=web", []string{"web-1", "web-2", "web-canary"}, []string{"web"}},
+		{"app==web,env=prod", []string{"web-2", "web-canary"}, []string{"web"}},
+		{"app!=web", []string{"db-0", "scratch"}, []string{"db"}},
+		{"env in (prod, staging),!canary", []string{"db-0", "web-1", "web-2"}, []string{"db", "web"}},
+		{"env notin (prod)", []string{"scratch", "web-1"}, []string{}},
+		{"canary", []string{"web-canary"}, []string{}},
+		{"app=api", []string{}, []string{}},
+	}
+	for _, tt := range tests {
+		selector, err := ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q) failed: %v", tt.selector, err)
+		}
+		preview := PreviewSelector(selector, pods, deployments)
+		if preview.MatchesEverything {
+			t.Errorf("%s: expected a non-empty selector not to match everything", tt.selector)
+		}
+		if !reflect.DeepEqual(preview.Pods, tt.pods) || !reflect.DeepEqual(preview.Deployments, tt.deployments) {
+			t.Errorf("%s: expected pods %v and deployments %v, got %v and %v",
+				tt.selector, tt.pods, tt.deployments, preview.Pods, preview.Deployments)
+		}
+	}
+}
+
+func TestPreviewSelectorEmpty(t *testing.T) {
+	for _, empty := range []string{"", "  "} {
+		selector, err := ParseSelector(empty)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q) failed: %v", empty, err)
+		}
+		preview := PreviewSelector(selector, []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web-1"}}}, nil)
+		if !preview.MatchesEverything || len(preview.Pods) != 1 {
+			t.Errorf("Expected %q to be reported as matching everything, got %+v", empty, preview)
+		}
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	for _, invalid := range []string{"app in (prod", "app in prod", "=web", "app=web,,", "app=w@b"} {
+		if _, err := ParseSelector(invalid); err == nil {
+			t.Errorf("Expected ParseSelector(%q) to fail", invalid)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// ServerInfo is what the API server serves: its version, how quickly it
+// answers, and the API groups and resource types it supports
+type ServerInfo struct {
+	// Version is the API server's git version, e.g. "v1.28.3"
+	Version string `json:"version"`
+	// Latency is how long the version request took, a round trip to the
+	// control plane
+	Latency time.Duration `json:"latency"`
+	// APIGroups are the preferred group versions, e.g. "v1" for the core
+	// group and "apps/v1"
+	APIGroups []string `json:"apiGroups"`
+	// SupportedResources are the resource types of the preferred group
+	// versions as group version and resource, e.g. "apps/v1/deployments".
+	// Subresources are left out.
+	SupportedResources []string `json:"supportedResources"`
+	// Warnings name the group versions whose resources could not be
+	// discovered, e.g. of an aggregated API that is down
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GetServerInfo asks the API server for its version, timing the request,
+// and discovers its API groups and their resource types. It fails when the
+// version or the groups cannot be read; a group whose resources cannot be
+// discovered only adds a warning.
+func GetServerInfo(ctx context.Context, clientset kubernetes.Interface) (*ServerInfo, error) {
+	discovery := clientset.Discovery()
+
+	start := time.Now()
+	version, err := discovery.ServerVersion()
+	if err != nil {
+		klog.Errorf("Failed to get the server version: %v", err)
+		return nil, err
+	}
+	info := &ServerInfo{Version: version.GitVersion, Latency: time.Since(start)}
+
+	groups, err := discovery.ServerGroups()
+	if err != nil {
+		klog.Errorf("Failed to discover API groups: %v", err)
+		return nil, err
+	}
+
+	info.APIGroups = []string{}
+	info.SupportedResources = []string{}
+	for _, group := range groups.Groups {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		groupVersion := group.PreferredVersion.GroupVersion
+		if groupVersion == "" && len(group.Versions) > 0 {
+			groupVersion = group.Versions[0].GroupVersion
+		}
+		if groupVersion == "" {
+			continue
+		}
+		info.APIGroups = append(info.APIGroups, groupVersion)
+
+		resources, err := discovery.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			klog.Warningf("Failed to discover the resources of %s: %v", groupVersion, err)
+			info.Warnings = append(info.Warnings, fmt.Sprintf("%s: %v", groupVersion, err))
+			continue
+		}
+		for _, resource := range resources.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			info.SupportedResources = append(info.SupportedResources, groupVersion+"/"+resource.Name)
+		}
+	}
+	sort.Strings(info.APIGroups)
+	sort.Strings(info.SupportedResources)
+	return info, nil
+}
+
+// SplitSupportedResource splits an entry of SupportedResources into its
+// resource and group version, e.g. "deployments" and "apps/v1"
+func SplitSupportedResource(entry string) (resource, groupVersion string) {
+	i := strings.LastIndex(entry, "/")
+	if i < 0 {
+		return entry, ""
+	}
+	return entry[i+1:], entry[:i]
+}
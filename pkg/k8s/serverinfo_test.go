@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGetServerInfo(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	discovery := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.28.3"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "pods/log"}, {Name: "configmaps"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments"}, {Name: "deployments/scale"}}},
+		{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs"}, {Name: "cronjobs"}}},
+	}
+
+	info, err := GetServerInfo(context.Background(), clientset)
+	if err != nil {
+		t.Fatalf("GetServerInfo failed: %v", err)
+	}
+	if info.Version != "v1.28.3" || info.Latency <= 0 {
+		t.Errorf("Expected v1.28.3 with a latency, got %s in %v", info.Version, info.Latency)
+	}
+	if want := []string{"apps/v1", "batch/v1", "v1"}; !reflect.DeepEqual(info.APIGroups, want) {
+		t.Errorf("Expected groups %v, got %v", want, info.APIGroups)
+	}
+	// Subresources are left out, and the list is sorted
+	want := []string{"apps/v1/deployments", "batch/v1/cronjobs", "batch/v1/jobs", "v1/configmaps", "v1/pods"}
+	if !reflect.DeepEqual(info.SupportedResources, want) {
+		t.Errorf("Expected resources %v, got %v", want, info.SupportedResources)
+	}
+	if len(info.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", info.Warnings)
+	}
+
+	// An unreachable server fails
+	clientset.PrependReactor("get", "version", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	if _, err := GetServerInfo(context.Background(), clientset); err == nil {
+		t.Error("Expected an error when the version cannot be read")
+	}
+}
+
+func TestSplitSupportedResource(t *testing.T) {
+	tests := []struct {
+		entry, resource, groupVersion string
+	}{
+		{"apps/v1/deployments", "deployments", "apps/v1"},
+		{"v1/pods", "pods", "v1"},
+		{"pods", "pods", ""},
+	}
+	for _, tt := range tests {
+		if resource, groupVersion := SplitSupportedResource(tt.entry); resource != tt.resource || groupVersion != tt.groupVersion {
+			t.Errorf("Expected %q to split into %q and %q, got %q and %q", tt.entry, tt.resource, tt.groupVersion, resource, groupVersion)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+// VolumeSnapshotResource and VolumeSnapshotClassResource are served by the
+// external-snapshotter CRDs, which not every cluster installs
+var (
+	VolumeSnapshotResource      = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+	VolumeSnapshotClassResource = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotclasses"}
+)
+
+// ErrVolumeSnapshotsUnavailable is returned when the cluster does not serve
+// the snapshot.storage.k8s.io/v1 API
+var ErrVolumeSnapshotsUnavailable = errors.New("volume snapshots are not available: the snapshot.storage.k8s.io/v1 CRDs are not installed")
+
+// VolumeSnapshotSummary is a VolumeSnapshot of a PVC
+type VolumeSnapshotSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// PVC is the claim the snapshot was taken from
+	PVC           string `json:"pvc"`
+	SnapshotClass string `json:"snapshotClass,omitempty"`
+	// ReadyToUse is false until the snapshot controller reports the snapshot
+	// can be restored from
+	ReadyToUse        bool        `json:"readyToUse"`
+	RestoreSize       string      `json:"restoreSize,omitempty"`
+	Error             string      `json:"error,omitempty"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+}
+
+// VolumeSnapshotClass is a class snapshots can be created with
+type VolumeSnapshotClass struct {
+	Name           string `json:"name"`
+	Driver         string `json:"driver"`
+	DeletionPolicy string `json:"deletionPolicy"`
+	// Default is set by the is-default-class annotation
+	Default bool `json:"default"`
+}
+
+// defaultSnapshotClassAnnotation marks the class used by snapshots that name
+// none
+const defaultSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+
+// snapshotsUnavailable turns the error of a request to a snapshot resource
+// the server does not serve into ErrVolumeSnapshotsUnavailable
+func snapshotsUnavailable(err error) error {
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: %v", ErrVolumeSnapshotsUnavailable, err)
+	}
+	return err
+}
+
+// CreateVolumeSnapshot snapshots a PVC and returns the name of the
+// VolumeSnapshot, which is named after the PVC and the time. An empty
+// snapshotClassName uses the cluster's default class.
+func CreateVolumeSnapshot(ctx context.Context, dynamicClient dynamic.Interface, namespace, pvcName, snapshotClassName string) (string, error) {
+	name := fmt.Sprintf("%s-%s", pvcName, time.Now().UTC().Format("20060102-150405"))
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{"persistentVolumeClaimName": pvcName},
+	}
+	if snapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = snapshotClassName
+	}
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": VolumeSnapshotResource.GroupVersion().String(),
+		"kind":       "VolumeSnapshot",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       spec,
+	}}
+
+	created, err := dynamicClient.Resource(VolumeSnapshotResource).Namespace(namespace).Create(ctx, snapshot, metav1.CreateOptions{FieldManager: fieldManager})
+	if err != nil {
+		klog.Errorf("Failed to snapshot PVC %s in namespace %s: %v", pvcName, namespace, err)
+		return "", snapshotsUnavailable(err)
+	}
+	return created.GetName(), nil
+}
+
+// ListVolumeSnapshots lists the VolumeSnapshots in a namespace, newest first
+func ListVolumeSnapshots(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]VolumeSnapshotSummary, error) {
+	list, err := dynamicClient.Resource(VolumeSnapshotResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list volume snapshots in namespace %s: %v", namespace, err)
+		return nil, snapshotsUnavailable(err)
+	}
+
+	snapshots := make([]VolumeSnapshotSummary, 0, len(list.Items))
+	for i := range list.Items {
+		snapshots = append(snapshots, volumeSnapshotFromUnstructured(&list.Items[i]))
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[j].CreationTimestamp.Before(&snapshots[i].CreationTimestamp)
+	})
+	return snapshots, nil
+}
+
+// PVCSnapshots returns the snapshots taken from one PVC
+func PVCSnapshots(snapshots []VolumeSnapshotSummary, pvcName string) []VolumeSnapshotSummary {
+	var taken []VolumeSnapshotSummary
+	for _, snapshot := range snapshots {
+		if snapshot.PVC == pvcName {
+			taken = append(taken, snapshot)
+		}
+	}
+	return taken
+}
+
+// volumeSnapshotFromUnstructured reads the fields kgo shows from a
+// VolumeSnapshot object
+func volumeSnapshotFromUnstructured(obj *unstructured.Unstructured) VolumeSnapshotSummary {
+	snapshot := VolumeSnapshotSummary{
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		CreationTimestamp: obj.GetCreationTimestamp(),
+	}
+	snapshot.PVC, _, _ = unstructured.NestedString(obj.Object, "spec", "source", "persistentVolumeClaimName")
+	snapshot.SnapshotClass, _, _ = unstructured.NestedString(obj.Object, "spec", "volumeSnapshotClassName")
+	snapshot.ReadyToUse, _, _ = unstructured.NestedBool(obj.Object, "status", "readyToUse")
+	snapshot.RestoreSize, _, _ = unstructured.NestedString(obj.Object, "status", "restoreSize")
+	snapshot.Error, _, _ = unstructured.NestedString(obj.Object, "status", "error", "message")
+	return snapshot
+}
+
+// ListVolumeSnapshotClasses lists the VolumeSnapshotClasses of the cluster,
+// the default class first and the rest by name
+func ListVolumeSnapshotClasses(ctx context.Context, dynamicClient dynamic.Interface) ([]VolumeSnapshotClass, error) {
+	list, err := dynamicClient.Resource(VolumeSnapshotClassResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list volume snapshot classes: %v", err)
+		return nil, snapshotsUnavailable(err)
+	}
+
+	classes := make([]VolumeSnapshotClass, 0, len(list.Items))
+	for _, item := range list.Items {
+		class := VolumeSnapshotClass{
+			Name:    item.GetName(),
+			Default: item.GetAnnotations()[defaultSnapshotClassAnnotation] == "true",
+		}
+		class.Driver, _, _ = unstructured.NestedString(item.Object, "driver")
+		class.DeletionPolicy, _, _ = unstructured.NestedString(item.Object, "deletionPolicy")
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if classes[i].Default != classes[j].Default {
+			return classes[i].Default
+		}
+		return classes[i].Name < classes[j].Name
+	})
+	return classes, nil
+}
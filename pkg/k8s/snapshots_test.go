@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newSnapshotClient(objects ...runtime.Object) *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		VolumeSnapshotResource:      "VolumeSnapshotList",
+		VolumeSnapshotClassResource: "VolumeSnapshotClassList",
+	}, objects...)
+}
+
+// newTestSnapshot returns a VolumeSnapshot of a PVC created at created
+func newTestSnapshot(name, pvc string, ready bool, created time.Time) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         "shop",
+			"creationTimestamp": created.Format(time.RFC3339),
+		},
+		"spec": map[string]interface{}{
+			"source":                  map[string]interface{}{"persistentVolumeClaimName": pvc},
+			"volumeSnapshotClassName": "csi-snap",
+		},
+		"status": map[string]interface{}{"readyToUse": ready, "restoreSize": "10Gi"},
+	}}
+}
+
+func newTestSnapshotClass(name string, isDefault bool) *unstructured.Unstructured {
+	class := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "snapshot.storage.k8s.io/v1",
+		"kind":           "VolumeSnapshotClass",
+		"metadata":       map[string]interface{}{"name": name},
+		"driver":         "ebs.csi.aws.com",
+		"deletionPolicy": "Delete",
+	}}
+	if isDefault {
+		class.SetAnnotations(map[string]string{defaultSnapshotClassAnnotation: "true"})
+	}
+	return class
+}
+
+func TestCreateVolumeSnapshot(t *testing.T) {
+	client := newSnapshotClient()
+
+	name, err := CreateVolumeSnapshot(context.Background(), client, "shop", "data-db-0", "csi-snap")
+	if err != nil {
+		t.Fatalf("CreateVolumeSnapshot failed: %v", err)
+	}
+	if !strings.HasPrefix(name, "data-db-0-") {
+		t.Errorf("Expected the snapshot to be named after the PVC, got %q", name)
+	}
+
+	obj, err := client.Resource(VolumeSnapshotResource).Namespace("shop").Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	snapshot := volumeSnapshotFromUnstructured(obj)
+	if snapshot.PVC != "data-db-0" || snapshot.SnapshotClass != "csi-snap" {
+		t.Errorf("Expected a snapshot of data-db-0 with class csi-snap, got %+v", snapshot)
+	}
+
+	// Without a class the snapshot is left to the default class
+	name, err = CreateVolumeSnapshot(context.Background(), client, "other", "logs", "")
+	if err != nil {
+		t.Fatalf("CreateVolumeSnapshot failed: %v", err)
+	}
+	obj, _ = client.Resource(VolumeSnapshotResource).Namespace("other").Get(context.Background(), name, metav1.GetOptions{})
+	if _, found, _ := unstructured.NestedString(obj.Object, "spec", "volumeSnapshotClassName"); found {
+		t.Errorf("Expected no volumeSnapshotClassName, got %v", obj.Object["spec"])
+	}
+}
+
+func TestListVolumeSnapshots(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := newSnapshotClient(
+		newTestSnapshot("data-db-0-old", "data-db-0", true, now.Add(-time.Hour)),
+		newTestSnapshot("data-db-0-new", "data-db-0", false, now),
+		newTestSnapshot("data-db-1-old", "data-db-1", true, now.Add(-2*time.Hour)),
+	)
+
+	snapshots, err := ListVolumeSnapshots(context.Background(), client, "shop")
+	if err != nil {
+		t.Fatalf("ListVolumeSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 3 || snapshots[0].Name != "data-db-0-new" || snapshots[2].Name != "data-db-1-old" {
+		t.Fatalf("Expected three snapshots newest first, got %+v", snapshots)
+	}
+	if snapshots[0].ReadyToUse || !snapshots[1].ReadyToUse || snapshots[1].RestoreSize != "10Gi" {
+		t.Errorf("Expected readyToUse and restoreSize from the status, got %+v", snapshots[:2])
+	}
+
+	taken := PVCSnapshots(snapshots, "data-db-0")
+	if len(taken) != 2 || taken[0].Name != "data-db-0-new" || taken[1].Name != "data-db-0-old" {
+		t.Errorf("Expected the two snapshots of data-db-0, got %+v", taken)
+	}
+}
+
+func TestListVolumeSnapshotClasses(t *testing.T) {
+	client := newSnapshotClient(newTestSnapshotClass("b-class", false), newTestSnapshotClass("z-default", true), newTestSnapshotClass("a-class", false))
+
+	classes, err := ListVolumeSnapshotClasses(context.Background(), client)
+	if err != nil {
+		t.Fatalf("ListVolumeSnapshotClasses failed: %v", err)
+	}
+	if len(classes) != 3 || classes[0].Name != "z-default" || !classes[0].Default || classes[1].Name != "a-class" {
+		t.Fatalf("Expected the default class first and the rest by name, got %+v", classes)
+	}
+	if classes[1].Driver != "ebs.csi.aws.com" || classes[1].DeletionPolicy != "Delete" {
+		t.Errorf("Expected the driver and deletion policy, got %+v", classes[1])
+	}
+}
+
+func TestVolumeSnapshotsUnavailable(t *testing.T) {
+	client := newSnapshotClient()
+	client.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+
+	if _, err := ListVolumeSnapshots(context.Background(), client, "shop"); !errors.Is(err, ErrVolumeSnapshotsUnavailable) {
+		t.Errorf("Expected ErrVolumeSnapshotsUnavailable listing snapshots, got %v", err)
+	}
+	if _, err := ListVolumeSnapshotClasses(context.Background(), client); !errors.Is(err, ErrVolumeSnapshotsUnavailable) {
+		t.Errorf("Expected ErrVolumeSnapshotsUnavailable listing classes, got %v", err)
+	}
+	if _, err := CreateVolumeSnapshot(context.Background(), client, "shop", "data-db-0", ""); !errors.Is(err, ErrVolumeSnapshotsUnavailable) {
+		t.Errorf("Expected ErrVolumeSnapshotsUnavailable creating a snapshot, got %v", err)
+	}
+}
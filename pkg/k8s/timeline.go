@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// TimelineEntry is one condition transition of a deployment, as recorded by
+// an event
+type TimelineEntry struct {
+	Time      metav1.Time                    `json:"time"`
+	Condition appsv1.DeploymentConditionType `json:"condition"`
+	Status    v1.ConditionStatus             `json:"status"`
+	Reason    string                         `json:"reason"`
+	Message   string                         `json:"message,omitempty"`
+}
+
+// conditionTransition is the condition and status an event reason records
+type conditionTransition struct {
+	condition appsv1.DeploymentConditionType
+	status    v1.ConditionStatus
+}
+
+// deploymentConditionReasons are the reasons the deployment controller sets
+// on its conditions, with the transition each stands for when an event
+// carries it
+var deploymentConditionReasons = map[string]conditionTransition{
+	"NewReplicaSetCreated":       {appsv1.DeploymentProgressing, v1.ConditionTrue},
+	"FoundNewReplicaSet":         {appsv1.DeploymentProgressing, v1.ConditionTrue},
+	"ReplicaSetUpdated":          {appsv1.DeploymentProgressing, v1.ConditionTrue},
+	"NewReplicaSetAvailable":     {appsv1.DeploymentProgressing, v1.ConditionTrue},
+	"ProgressDeadlineExceeded":   {appsv1.DeploymentProgressing, v1.ConditionFalse},
+	"DeploymentPaused":           {appsv1.DeploymentProgressing, v1.ConditionUnknown},
+	"DeploymentResumed":          {appsv1.DeploymentProgressing, v1.ConditionUnknown},
+	"MinimumReplicasAvailable":   {appsv1.DeploymentAvailable, v1.ConditionTrue},
+	"MinimumReplicasUnavailable": {appsv1.DeploymentAvailable, v1.ConditionFalse},
+	"ReplicaSetCreateError":      {appsv1.DeploymentReplicaFailure, v1.ConditionTrue},
+	"FailedCreate":               {appsv1.DeploymentReplicaFailure, v1.ConditionTrue},
+}
+
+// rolloutEventReasons are the reasons of the events of a rollout's steps,
+// which move the Progressing condition: True for Normal events, False for
+// Warning events
+var rolloutEventReasons = map[string]bool{
+	"ScalingReplicaSet":  true,
+	"DeploymentRollout":  true,
+	"DeploymentRollback": true,
+}
+
+// ParseDeploymentTimeline returns the condition transitions recorded by the
+// events of a deployment, oldest first. Events of other reasons are left out.
+func ParseDeploymentTimeline(events []v1.Event) []TimelineEntry {
+	var timeline []TimelineEntry
+	for i := range events {
+		event := &events[i]
+		transition, ok := deploymentConditionReasons[event.Reason]
+		if !ok && rolloutEventReasons[event.Reason] {
+			transition, ok = conditionTransition{appsv1.DeploymentProgressing, v1.ConditionUnknown}, true
+			switch event.Type {
+			case v1.EventTypeNormal:
+				transition.status = v1.ConditionTrue
+			case v1.EventTypeWarning:
+				transition.status = v1.ConditionFalse
+			}
+		}
+		if !ok {
+			continue
+		}
+		timeline = append(timeline, TimelineEntry{
+			Time:      eventTime(event),
+			Condition: transition.condition,
+			Status:    transition.status,
+			Reason:    event.Reason,
+			Message:   event.Message,
+		})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(&timeline[j].Time)
+	})
+	return timeline
+}
+
+// ListEventsByInvolvedObject lists the events of an object of a kind
+func ListEventsByInvolvedObject(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) ([]v1.Event, error) {
+	selector := fields.Set{"involvedObject.kind": kind, "involvedObject.name": name}.AsSelector().String()
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		klog.Errorf("Failed to list events of %s %s/%s: %v", kind, namespace, name, err)
+		return nil, err
+	}
+	return events.Items, nil
+}
+
+// GetDeploymentTimeline returns the condition transitions of a deployment
+func GetDeploymentTimeline(ctx context.Context, clientset kubernetes.Interface, namespace, name string) ([]TimelineEntry, error) {
+	events, err := ListEventsByInvolvedObject(ctx, clientset, "Deployment", namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeploymentTimeline(events), nil
+}
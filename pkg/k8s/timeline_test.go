@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// deploymentEvent returns an event of deployment web with a reason
+func deploymentEvent(name, eventType, reason, message string, at time.Time) v1.Event {
+	return v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Deployment", Namespace: "default", Name: "web"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestParseDeploymentTimeline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := []v1.Event{
+		deploymentEvent("available", v1.EventTypeNormal, "MinimumReplicasAvailable", "Deployment has minimum availability.", start.Add(time.Minute)),
+		deploymentEvent("scaled", v1.EventTypeNormal, "ScalingReplicaSet", "Scaled up replica set web-5d8f to 3", start),
+		deploymentEvent("pulled", v1.EventTypeNormal, "Pulled", "not a deployment condition", start.Add(30*time.Second)),
+		deploymentEvent("deadline", v1.EventTypeWarning, "ProgressDeadlineExceeded", `ReplicaSet "web-7c9d" has timed out progressing.`, start.Add(20*time.Minute)),
+		deploymentEvent("rollout", v1.EventTypeWarning, "DeploymentRollout", "rollout failed", start.Add(21*time.Minute)),
+		deploymentEvent("paused", "", "DeploymentPaused", "Deployment is paused", start.Add(22*time.Minute)),
+	}
+	// Events created through the events.k8s.io API only set eventTime
+	unavailable := deploymentEvent("unavailable", v1.EventTypeWarning, "MinimumReplicasUnavailable", "", time.Time{})
+	unavailable.LastTimestamp = metav1.Time{}
+	unavailable.EventTime = metav1.NewMicroTime(start.Add(10 * time.Minute))
+	events = append(events, unavailable)
+
+	timeline := ParseDeploymentTimeline(events)
+	want := []struct {
+		condition appsv1.DeploymentConditionType
+		status    v1.ConditionStatus
+		reason    string
+		at        time.Time
+	}{
+		{appsv1.DeploymentProgressing, v1.ConditionTrue, "ScalingReplicaSet", start},
+		{appsv1.DeploymentAvailable, v1.ConditionTrue, "MinimumReplicasAvailable", start.Add(time.Minute)},
+		{appsv1.DeploymentAvailable, v1.ConditionFalse, "MinimumReplicasUnavailable", start.Add(10 * time.Minute)},
+		{appsv1.DeploymentProgressing, v1.ConditionFalse, "ProgressDeadlineExceeded", start.Add(20 * time.Minute)},
+		{appsv1.DeploymentProgressing, v1.ConditionFalse, "DeploymentRollout", start.Add(21 * time.Minute)},
+		{appsv1.DeploymentProgressing, v1.ConditionUnknown, "DeploymentPaused", start.Add(22 * time.Minute)},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), timeline)
+	}
+	for i, w := range want {
+		got := timeline[i]
+		if got.Condition != w.condition || got.Status != w.status || got.Reason != w.reason || !got.Time.Time.Equal(w.at) {
+			t.Errorf("Entry %d: expected %s=%s (%s) at %s, got %+v", i, w.condition, w.status, w.reason, w.at, got)
+		}
+	}
+	if timeline[0].Message != "Scaled up replica set web-5d8f to 3" {
+		t.Errorf("Expected the event message to be kept, got %q", timeline[0].Message)
+	}
+
+	if timeline := ParseDeploymentTimeline(nil); len(timeline) != 0 {
+		t.Errorf("Expected no entries without events, got %+v", timeline)
+	}
+}
+
+func TestGetDeploymentTimeline(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	scaled := deploymentEvent("scaled", v1.EventTypeNormal, "ScalingReplicaSet", "Scaled up replica set web-5d8f to 1", start)
+	clientset := fake.NewSimpleClientset(&scaled)
+
+	timeline, err := GetDeploymentTimeline(context.Background(), clientset, "default", "web")
+	if err != nil {
+		t.Fatalf("GetDeploymentTimeline failed: %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].Reason != "ScalingReplicaSet" {
+		t.Errorf("Expected the scaling event, got %+v", timeline)
+	}
+}
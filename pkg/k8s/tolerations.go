@@ -0,0 +1,105 @@
+package k8s
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// MinReschedulableNodes is how many nodes a pod should tolerate for it to be
+// rescheduled when the node it runs on is drained
+const MinReschedulableNodes = 2
+
+// nodeTaints returns the taints of a node, adding the unschedulable taint of
+// a cordoned node in case the node controller has yet to
+func nodeTaints(node v1.Node) []v1.Taint {
+	taints := node.Spec.Taints
+	if !node.Spec.Unschedulable {
+		return taints
+	}
+	for _, taint := range taints {
+		if taint.Key == v1.TaintNodeUnschedulable {
+			return taints
+		}
+	}
+	return append(append([]v1.Taint(nil), taints...), v1.Taint{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule})
+}
+
+// BlockingTaints returns the taints of a node that keep a pod off it: those
+// with the NoSchedule or NoExecute effect the pod does not tolerate.
+// PreferNoSchedule taints only make the scheduler avoid the node.
+func BlockingTaints(pod v1.Pod, node v1.Node) []v1.Taint {
+	var blocking []v1.Taint
+	for _, taint := range nodeTaints(node) {
+		if taint.Effect == v1.TaintEffectPreferNoSchedule || tolerates(pod.Spec.Tolerations, taint) {
+			continue
+		}
+		blocking = append(blocking, taint)
+	}
+	return blocking
+}
+
+func tolerates(tolerations []v1.Toleration, taint v1.Taint) bool {
+	for _, toleration := range tolerations {
+		if toleration.ToleratesTaint(&taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// SimulateScheduling returns the nodes a pod could be scheduled on as far as
+// taints and tolerations go: those without BlockingTaints. It does not look
+// at resources, affinity or node selectors.
+func SimulateScheduling(pod v1.Pod, nodes []v1.Node) []v1.Node {
+	var schedulable []v1.Node
+	for _, node := range nodes {
+		if len(BlockingTaints(pod, node)) == 0 {
+			schedulable = append(schedulable, node)
+		}
+	}
+	return schedulable
+}
+
+// TolerationSuggestion is a toleration a pod lacks, with the nodes it would
+// be schedulable on once it has it
+type TolerationSuggestion struct {
+	Toleration v1.Toleration `json:"toleration"`
+	Nodes      []string      `json:"nodes"`
+}
+
+// SuggestTolerations returns a toleration for each taint blocking a pod from
+// some node, for the nodes where that taint is the only one blocking it.
+// Taints that only block together with others get no suggestion of their
+// own. Suggestions opening the most nodes come first.
+func SuggestTolerations(pod v1.Pod, nodes []v1.Node) []TolerationSuggestion {
+	byToleration := make(map[v1.Toleration]*TolerationSuggestion)
+	var suggestions []*TolerationSuggestion
+	for _, node := range nodes {
+		blocking := BlockingTaints(pod, node)
+		if len(blocking) != 1 {
+			continue
+		}
+		taint := blocking[0]
+		toleration := v1.Toleration{Key: taint.Key, Operator: v1.TolerationOpExists, Effect: taint.Effect}
+		if taint.Value != "" {
+			toleration.Operator, toleration.Value = v1.TolerationOpEqual, taint.Value
+		}
+		suggestion, ok := byToleration[toleration]
+		if !ok {
+			suggestion = &TolerationSuggestion{Toleration: toleration}
+			byToleration[toleration] = suggestion
+			suggestions = append(suggestions, suggestion)
+		}
+		suggestion.Nodes = append(suggestion.Nodes, node.Name)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return len(suggestions[i].Nodes) > len(suggestions[j].Nodes)
+	})
+	result := make([]TolerationSuggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		result = append(result, *suggestion)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func taintedNode(name string, taints ...v1.Taint) v1.Node {
+	return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1.NodeSpec{Taints: taints}}
+}
+
+func nodeNames(nodes []v1.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+func TestSimulateScheduling(t *testing.T) {
+	gpu := v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoSchedule}
+	spot := v1.Taint{Key: "spot", Effect: v1.TaintEffectPreferNoSchedule}
+	draining := v1.Taint{Key: "draining", Effect: v1.TaintEffectNoExecute}
+	cordoned := taintedNode("cordoned")
+	cordoned.Spec.Unschedulable = true
+	nodes := []v1.Node{
+		taintedNode("plain"),
+		taintedNode("gpu", gpu),
+		taintedNode("spot", spot),
+		taintedNode("draining", draining),
+		cordoned,
+	}
+
+	tests := []struct {
+		name        string
+		tolerations []v1.Toleration
+		want        []string
+	}{
+		// PreferNoSchedule never blocks
+		{"no tolerations", nil, []string{"plain", "spot"}},
+		{"NoSchedule with the value", []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true", Effect: v1.TaintEffectNoSchedule}}, []string{"plain", "gpu", "spot"}},
+		{"NoSchedule with another value", []v1.Toleration{{Key: "gpu", Value: "false"}}, []string{"plain", "spot"}},
+		{"NoExecute by key", []v1.Toleration{{Key: "draining", Operator: v1.TolerationOpExists}}, []string{"plain", "spot", "draining"}},
+		{"NoExecute with the wrong effect", []v1.Toleration{{Key: "draining", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}}, []string{"plain", "spot"}},
+		{"unschedulable", []v1.Toleration{{Key: v1.TaintNodeUnschedulable, Operator: v1.TolerationOpExists}}, []string{"plain", "spot", "cordoned"}},
+		{"everything", []v1.Toleration{{Operator: v1.TolerationOpExists}}, []string{"plain", "gpu", "spot", "draining", "cordoned"}},
+	}
+	for _, tt := range tests {
+		pod := v1.Pod{Spec: v1.PodSpec{Tolerations: tt.tolerations}}
+		if got := nodeNames(SimulateScheduling(pod, nodes)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	// The unschedulable taint the node controller adds is not doubled
+	cordoned.Spec.Taints = []v1.Taint{{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule}}
+	if taints := BlockingTaints(v1.Pod{}, cordoned); len(taints) != 1 {
+		t.Errorf("Expected the unschedulable taint once, got %+v", taints)
+	}
+}
+
+func TestSuggestTolerations(t *testing.T) {
+	gpu := v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoSchedule}
+	draining := v1.Taint{Key: "draining", Effect: v1.TaintEffectNoExecute}
+	nodes := []v1.Node{
+		taintedNode("plain"),
+		taintedNode("gpu-1", gpu),
+		taintedNode("gpu-2", gpu),
+		taintedNode("draining", draining),
+		taintedNode("both", gpu, draining),
+	}
+
+	suggestions := SuggestTolerations(v1.Pod{}, nodes)
+	want := []TolerationSuggestion{
+		{Toleration: v1.Toleration{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true", Effect: v1.TaintEffectNoSchedule}, Nodes: []string{"gpu-1", "gpu-2"}},
+		{Toleration: v1.Toleration{Key: "draining", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute}, Nodes: []string{"draining"}},
+	}
+	if !reflect.DeepEqual(suggestions, want) {
+		t.Errorf("Expected %+v, got %+v", want, suggestions)
+	}
+
+	// A pod tolerating the gpu taint only needs the other one
+	pod := v1.Pod{Spec: v1.PodSpec{Tolerations: []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpExists}}}}
+	suggestions = SuggestTolerations(pod, nodes)
+	if len(suggestions) != 1 || suggestions[0].Toleration.Key != "draining" || !reflect.DeepEqual(suggestions[0].Nodes, []string{"draining", "both"}) {
+		t.Errorf("Expected the draining toleration for draining and both, got %+v", suggestions)
+	}
+}
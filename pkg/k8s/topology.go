@@ -0,0 +1,70 @@
+package k8s
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ComputeTopologySkew groups the pods a topology spread constraint selects by
+// the domain they run in, the value of its topology key on their node, and
+// returns the count of each domain with the skew: how many more pods the most
+// populated domain has than the least populated one.
+//
+// Every value of the key on nodes is a domain, so empty domains count as 0.
+// As for the scheduler, only pods placed on a node that have neither finished
+// nor begun terminating count, and with fewer domains than the constraint's
+// minDomains the least populated count is 0. The pods should be those of the
+// namespace the constraint applies to.
+func ComputeTopologySkew(pods []v1.Pod, nodes []v1.Node, constraint v1.TopologySpreadConstraint) (map[string]int, int, error) {
+	if constraint.TopologyKey == "" {
+		return nil, 0, fmt.Errorf("topology spread constraint has no topologyKey")
+	}
+	// A constraint without a selector selects no pods
+	selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid labelSelector of topology spread constraint on %s: %v", constraint.TopologyKey, err)
+	}
+
+	counts := make(map[string]int)
+	domains := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if domain, ok := node.Labels[constraint.TopologyKey]; ok {
+			domains[node.Name] = domain
+			counts[domain] += 0
+		}
+	}
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil ||
+			pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		domain, ok := domains[pod.Spec.NodeName]
+		if !ok || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		counts[domain]++
+	}
+
+	if len(counts) == 0 {
+		return counts, 0, nil
+	}
+	first := true
+	var most, least int
+	for _, count := range counts {
+		if first || count > most {
+			most = count
+		}
+		if first || count < least {
+			least = count
+		}
+		first = false
+	}
+	if constraint.MinDomains != nil && len(counts) < int(*constraint.MinDomains) {
+		least = 0
+	}
+	return counts, most - least, nil
+}
@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// threeZoneNodes returns two nodes in each of zones a, b and c, and a node
+// without a zone label
+func threeZoneNodes() []v1.Node {
+	var nodes []v1.Node
+	for _, node := range []struct{ name, zone string }{
+		{"a-1", "a"}, {"a-2", "a"}, {"b-1", "b"}, {"b-2", "b"}, {"c-1", "c"}, {"c-2", "c"}, {"edge", ""},
+	} {
+		labels := map[string]string{"kubernetes.io/hostname": node.name}
+		if node.zone != "" {
+			labels["topology.kubernetes.io/zone"] = node.zone
+		}
+		nodes = append(nodes, v1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.name, Labels: labels}})
+	}
+	return nodes
+}
+
+// webPods returns a running web pod on each node
+func webPods(nodes ...string) []v1.Pod {
+	pods := make([]v1.Pod, 0, len(nodes))
+	for _, node := range nodes {
+		pods = append(pods, v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+			Spec:       v1.PodSpec{NodeName: node},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		})
+	}
+	return pods
+}
+
+func zoneConstraint() v1.TopologySpreadConstraint {
+	return v1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: v1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+	}
+}
+
+func TestComputeTopologySkew(t *testing.T) {
+	finished := webPods("c-1", "c-2")
+	finished[0].Status.Phase = v1.PodSucceeded
+	finished[1].DeletionTimestamp = &metav1.Time{}
+	other := webPods("a-1")
+	other[0].Labels["app"] = "api"
+
+	tests := []struct {
+		name   string
+		pods   []v1.Pod
+		counts map[string]int
+		skew   int
+	}{
+		{"even", webPods("a-1", "b-1", "c-2"), map[string]int{"a": 1, "b": 1, "c": 1}, 0},
+		{"one zone ahead", webPods("a-1", "a-2", "b-1", "c-1"), map[string]int{"a": 2, "b": 1, "c": 1}, 1},
+		{"empty zone", webPods("a-1", "a-2", "b-1"), map[string]int{"a": 2, "b": 1, "c": 0}, 2},
+		{"all in one zone", webPods("b-1", "b-2", "b-1"), map[string]int{"a": 0, "b": 3, "c": 0}, 3},
+		{"no pods", nil, map[string]int{"a": 0, "b": 0, "c": 0}, 0},
+		{"unscheduled and unlabeled nodes", webPods("", "edge", "gone", "a-1"), map[string]int{"a": 1, "b": 0, "c": 0}, 1},
+		{"finished, terminating and unselected pods", append(append(webPods("b-1"), finished...), other...), map[string]int{"a": 0, "b": 1, "c": 0}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, skew, err := ComputeTopologySkew(tt.pods, threeZoneNodes(), zoneConstraint())
+			if err != nil {
+				t.Fatalf("ComputeTopologySkew failed: %v", err)
+			}
+			if !reflect.DeepEqual(counts, tt.counts) || skew != tt.skew {
+				t.Errorf("Expected %v with skew %d, got %v with skew %d", tt.counts, tt.skew, counts, skew)
+			}
+		})
+	}
+}
+
+func TestComputeTopologySkewMinDomains(t *testing.T) {
+	constraint := zoneConstraint()
+	minDomains := int32(4)
+	constraint.MinDomains = &minDomains
+
+	// Three zones are fewer than minDomains, so the least populated is 0
+	_, skew, err := ComputeTopologySkew(webPods("a-1", "b-1", "c-1"), threeZoneNodes(), constraint)
+	if err != nil || skew != 1 {
+		t.Errorf("Expected skew 1 below minDomains, got %d, %v", skew, err)
+	}
+
+	minDomains = 3
+	if _, skew, _ := ComputeTopologySkew(webPods("a-1", "b-1", "c-1"), threeZoneNodes(), constraint); skew != 0 {
+		t.Errorf("Expected skew 0 with enough domains, got %d", skew)
+	}
+}
+
+func TestComputeTopologySkewHostnames(t *testing.T) {
+	constraint := zoneConstraint()
+	constraint.TopologyKey = "kubernetes.io/hostname"
+
+	counts, skew, err := ComputeTopologySkew(webPods("a-1", "a-1", "edge"), threeZoneNodes(), constraint)
+	if err != nil {
+		t.Fatalf("ComputeTopologySkew failed: %v", err)
+	}
+	if len(counts) != 7 || counts["a-1"] != 2 || counts["edge"] != 1 || skew != 2 {
+		t.Errorf("Expected a domain per node with a-1 at 2, got %v with skew %d", counts, skew)
+	}
+}
+
+func TestComputeTopologySkewErrors(t *testing.T) {
+	constraint := zoneConstraint()
+	constraint.TopologyKey = ""
+	if _, _, err := ComputeTopologySkew(nil, threeZoneNodes(), constraint); err == nil {
+		t.Error("Expected a constraint without a topology key to fail")
+	}
+
+	constraint = zoneConstraint()
+	constraint.LabelSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Near"}}}
+	if _, _, err := ComputeTopologySkew(nil, threeZoneNodes(), constraint); err == nil {
+		t.Error("Expected an invalid selector to fail")
+	}
+
+	// Without a selector no pods are counted
+	constraint.LabelSelector = nil
+	counts, skew, err := ComputeTopologySkew(webPods("a-1"), threeZoneNodes(), constraint)
+	if err != nil || counts["a"] != 0 || skew != 0 {
+		t.Errorf("Expected no pods to be selected, got %v with skew %d, %v", counts, skew, err)
+	}
+}
@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Sort orders accepted by GetTopPods
+const (
+	TopPodsByCPU    = "cpu"
+	TopPodsByMemory = "memory"
+)
+
+// PodMetricSummary is the current resource usage of a pod from metrics-server
+type PodMetricSummary struct {
+	Name        string
+	Namespace   string
+	Node        string
+	CPUMilli    int64
+	MemoryBytes int64
+	// CPUPercent and MemoryPercent are the usage against the pod's limit
+	// when every container sets one, else against its node's allocatable.
+	// They are -1 when neither is known.
+	CPUPercent    float64
+	MemoryPercent float64
+}
+
+// GetTopPods returns the n pods of a namespace using the most CPU or memory,
+// as sortBy says, in descending order. n <= 0 returns every pod with metrics.
+// It fails when metrics-server is not available; when the nodes cannot be
+// listed only limits are used for the percentages.
+func GetTopPods(ctx context.Context, metricsClientset metricsclientset.Interface, clientset kubernetes.Interface, namespace string, sortBy string, n int) ([]PodMetricSummary, error) {
+	if sortBy != TopPodsByCPU && sortBy != TopPodsByMemory {
+		return nil, fmt.Errorf("unknown sort order %q, expected %q or %q", sortBy, TopPodsByCPU, TopPodsByMemory)
+	}
+
+	podMetrics, err := metricsClientset.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list pod metrics in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list pods in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	podsByName := make(map[string]*v1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		podsByName[pods.Items[i].Name] = &pods.Items[i]
+	}
+
+	allocatable := make(map[string]v1.ResourceList)
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Warningf("Failed to list nodes, pod usage is only shown against limits: %v", err)
+	} else {
+		for _, node := range nodes.Items {
+			allocatable[node.Name] = node.Status.Allocatable
+		}
+	}
+
+	summaries := make([]PodMetricSummary, 0, len(podMetrics.Items))
+	for _, metrics := range podMetrics.Items {
+		summaries = append(summaries, summarizePodMetrics(metrics, podsByName[metrics.Name], allocatable))
+	}
+
+	sortTopPods(summaries, sortBy)
+	if n > 0 && len(summaries) > n {
+		summaries = summaries[:n]
+	}
+	return summaries, nil
+}
+
+// summarizePodMetrics adds up the container usage of a pod's metrics and
+// relates it to the pod's limits or its node's allocatable. pod is nil when
+// the pod has gone since its metrics were collected.
+func summarizePodMetrics(metrics metricsv1beta1.PodMetrics, pod *v1.Pod, allocatable map[string]v1.ResourceList) PodMetricSummary {
+	summary := PodMetricSummary{
+		Name:          metrics.Name,
+		Namespace:     metrics.Namespace,
+		CPUPercent:    -1,
+		MemoryPercent: -1,
+	}
+	for _, container := range metrics.Containers {
+		summary.CPUMilli += container.Usage.Cpu().MilliValue()
+		summary.MemoryBytes += container.Usage.Memory().Value()
+	}
+	if pod == nil {
+		return summary
+	}
+
+	summary.Node = pod.Spec.NodeName
+	if cpu, ok := usageBase(pod, v1.ResourceCPU, allocatable[pod.Spec.NodeName]); ok {
+		summary.CPUPercent = percentOf(summary.CPUMilli, cpu.MilliValue())
+	}
+	if memory, ok := usageBase(pod, v1.ResourceMemory, allocatable[pod.Spec.NodeName]); ok {
+		summary.MemoryPercent = percentOf(summary.MemoryBytes, memory.Value())
+	}
+	return summary
+}
+
+// usageBase returns what a pod's usage of a resource is measured against: the
+// sum of its containers' limits when every container sets one, else its
+// node's allocatable
+func usageBase(pod *v1.Pod, name v1.ResourceName, nodeAllocatable v1.ResourceList) (resource.Quantity, bool) {
+	var total resource.Quantity
+	limited := len(pod.Spec.Containers) > 0
+	for _, container := range pod.Spec.Containers {
+		limit, ok := container.Resources.Limits[name]
+		if !ok || limit.IsZero() {
+			limited = false
+			break
+		}
+		total.Add(limit)
+	}
+	if limited {
+		return total, true
+	}
+
+	allocatable, ok := nodeAllocatable[name]
+	if !ok || allocatable.IsZero() {
+		return resource.Quantity{}, false
+	}
+	return allocatable, true
+}
+
+// percentOf returns usage as a percentage of base, or -1 without a base
+func percentOf(usage, base int64) float64 {
+	if base <= 0 {
+		return -1
+	}
+	return float64(usage) * 100 / float64(base)
+}
+
+// sortTopPods orders pods by CPU or memory usage, highest first, and by name
+// between pods using the same
+func sortTopPods(pods []PodMetricSummary, sortBy string) {
+	sort.SliceStable(pods, func(i, j int) bool {
+		a, b := pods[i].CPUMilli, pods[j].CPUMilli
+		if sortBy == TopPodsByMemory {
+			a, b = pods[i].MemoryBytes, pods[j].MemoryBytes
+		}
+		if a != b {
+			return a > b
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
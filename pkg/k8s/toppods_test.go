@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// podMetrics returns the metrics of a single-container pod
+func podMetrics(name, cpu, memory string) metricsv1beta1.PodMetrics {
+	return metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{{
+			Name: "app",
+			Usage: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		}},
+	}
+}
+
+// metricsClient returns a metrics client listing the given pod metrics. The
+// fake clientset's tracker cannot map PodMetrics to its resource, so the list
+// is served by a reactor.
+func metricsClient(metrics ...metricsv1beta1.PodMetrics) *metricsfake.Clientset {
+	client := &metricsfake.Clientset{}
+	client.AddReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: metrics}, nil
+	})
+	return client
+}
+
+// limitedPod returns a pod on a node whose containers have the given limits;
+// an empty limit leaves the container unlimited
+func limitedPod(name, node string, cpuLimits ...string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.PodSpec{NodeName: node},
+	}
+	for i, limit := range cpuLimits {
+		container := v1.Container{Name: fmt.Sprintf("c%d", i)}
+		if limit != "" {
+			container.Resources.Limits = v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(limit),
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+			}
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, container)
+	}
+	return pod
+}
+
+func TestGetTopPods(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	clientset := fake.NewSimpleClientset(
+		node,
+		limitedPod("api", "node-1", "500m"),
+		limitedPod("web", "node-1", ""),
+		limitedPod("worker", "node-1", "1", "1"),
+	)
+	metrics := metricsClient(
+		podMetrics("api", "250m", "64Mi"),
+		podMetrics("web", "1", "2Gi"),
+		podMetrics("worker", "100m", "128Mi"),
+		podMetrics("gone", "100m", "32Mi"),
+	)
+
+	t.Run("by cpu", func(t *testing.T) {
+		top, err := GetTopPods(context.Background(), metrics, clientset, "default", TopPodsByCPU, 0)
+		if err != nil {
+			t.Fatalf("GetTopPods failed: %v", err)
+		}
+		var names []string
+		for _, pod := range top {
+			names = append(names, pod.Name)
+		}
+		if fmt.Sprint(names) != "[web api gone worker]" {
+			t.Errorf("Expected pods by CPU [web api gone worker], got %v", names)
+		}
+	})
+
+	t.Run("by memory limited to n", func(t *testing.T) {
+		top, err := GetTopPods(context.Background(), metrics, clientset, "default", TopPodsByMemory, 2)
+		if err != nil {
+			t.Fatalf("GetTopPods failed: %v", err)
+		}
+		if len(top) != 2 || top[0].Name != "web" || top[1].Name != "worker" {
+			t.Errorf("Expected top 2 pods by memory [web worker], got %+v", top)
+		}
+	})
+
+	t.Run("percentages", func(t *testing.T) {
+		top, err := GetTopPods(context.Background(), metrics, clientset, "default", TopPodsByCPU, 0)
+		if err != nil {
+			t.Fatalf("GetTopPods failed: %v", err)
+		}
+		want := map[string][2]float64{
+			// Against the 500m CPU and 256Mi memory limits
+			"api": {50, 25},
+			// Unlimited, so against the node's 4 CPUs and 8Gi
+			"web": {25, 25},
+			// Against the two containers' limits added up
+			"worker": {5, 25},
+			// Unknown pod
+			"gone": {-1, -1},
+		}
+		for _, pod := range top {
+			got := [2]float64{pod.CPUPercent, pod.MemoryPercent}
+			if math.Abs(got[0]-want[pod.Name][0]) > 0.01 || math.Abs(got[1]-want[pod.Name][1]) > 0.01 {
+				t.Errorf("Expected %s at CPU/memory %v%%, got %v%%", pod.Name, want[pod.Name], got)
+			}
+		}
+		if top[0].Node != "node-1" || top[0].CPUMilli != 1000 || top[0].MemoryBytes != 2<<30 {
+			t.Errorf("Expected web on node-1 using 1000m and 2Gi, got %+v", top[0])
+		}
+	})
+
+	t.Run("unknown sort order", func(t *testing.T) {
+		if _, err := GetTopPods(context.Background(), metrics, clientset, "default", "disk", 0); err == nil {
+			t.Error("Expected an error for an unknown sort order")
+		}
+	})
+}
+
+func TestGetTopPodsWithoutNodes(t *testing.T) {
+	clientset := fake.NewSimpleClientset(limitedPod("api", "node-1", "500m"), limitedPod("web", "node-1", ""))
+	clientset.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("nodes is forbidden")
+	})
+	metrics := metricsClient(podMetrics("api", "250m", "64Mi"), podMetrics("web", "1", "2Gi"))
+
+	top, err := GetTopPods(context.Background(), metrics, clientset, "default", TopPodsByCPU, 0)
+	if err != nil {
+		t.Fatalf("Expected node errors to be tolerated, got %v", err)
+	}
+	if top[0].Name != "web" || top[0].CPUPercent != -1 {
+		t.Errorf("Expected unlimited web without a percentage, got %+v", top[0])
+	}
+	if top[1].Name != "api" || top[1].CPUPercent != 50 {
+		t.Errorf("Expected api at 50%% of its limit, got %+v", top[1])
+	}
+}
+
+func TestGetTopPodsWithoutMetricsServer(t *testing.T) {
+	metrics := &metricsfake.Clientset{}
+	metrics.AddReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server could not find the requested resource")
+	})
+
+	if _, err := GetTopPods(context.Background(), metrics, fake.NewSimpleClientset(), "default", TopPodsByCPU, 20); err == nil {
+		t.Error("Expected an error without metrics-server")
+	}
+}
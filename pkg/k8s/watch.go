@@ -0,0 +1,146 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// WatchHandle is a watch that remembers the resourceVersion of the last event
+// it delivered. When the watch drops, passing ResourceVersion to the same
+// Watch function resumes it from there instead of re-listing. Bookmarks only
+// advance the resourceVersion and are not delivered.
+type WatchHandle struct {
+	watcher watch.Interface
+	result  chan watch.Event
+	stopped chan struct{}
+	stop    sync.Once
+
+	mu              sync.Mutex
+	resourceVersion string
+}
+
+// newWatchHandle wraps a watch started at resourceVersion
+func newWatchHandle(watcher watch.Interface, resourceVersion string) *WatchHandle {
+	h := &WatchHandle{
+		watcher:         watcher,
+		result:          make(chan watch.Event),
+		stopped:         make(chan struct{}),
+		resourceVersion: resourceVersion,
+	}
+	go h.forward()
+	return h
+}
+
+// ResultChan returns the events of the watch, and is closed when it ends
+func (h *WatchHandle) ResultChan() <-chan watch.Event {
+	return h.result
+}
+
+// Stop ends the watch
+func (h *WatchHandle) Stop() {
+	h.stop.Do(func() {
+		close(h.stopped)
+		h.watcher.Stop()
+	})
+}
+
+// ResourceVersion returns the resourceVersion to resume the watch from: that
+// of the last event delivered, or the one it was started at
+func (h *WatchHandle) ResourceVersion() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.resourceVersion
+}
+
+// forward passes events on, noting their resourceVersion first so that it is
+// current by the time the receiver sees the event
+func (h *WatchHandle) forward() {
+	defer close(h.result)
+	for event := range h.watcher.ResultChan() {
+		// Error events carry a Status rather than an object
+		if event.Type != watch.Error {
+			if obj, err := meta.Accessor(event.Object); err == nil && obj.GetResourceVersion() != "" {
+				h.mu.Lock()
+				h.resourceVersion = obj.GetResourceVersion()
+				h.mu.Unlock()
+			}
+		}
+		if event.Type == watch.Bookmark {
+			continue
+		}
+		select {
+		case h.result <- event:
+		case <-h.stopped:
+			return
+		}
+	}
+}
+
+// watchOptions starts a watch at resourceVersion, or at the current state
+// when it is empty
+func watchOptions(resourceVersion string) metav1.ListOptions {
+	return metav1.ListOptions{ResourceVersion: resourceVersion, Watch: true, AllowWatchBookmarks: true}
+}
+
+// listOptionsSince lets the API server answer a list from its watch cache
+// when it is at least as new as resourceVersion, instead of reading etcd
+// again. An empty resourceVersion asks for the latest state.
+func listOptionsSince(resourceVersion string) metav1.ListOptions {
+	if resourceVersion == "" {
+		return metav1.ListOptions{}
+	}
+	return metav1.ListOptions{ResourceVersion: resourceVersion, ResourceVersionMatch: metav1.ResourceVersionMatchNotOlderThan}
+}
+
+// ListPodsSince lists the pods in the specified namespace no older than
+// resourceVersion, and returns the list's resourceVersion to pass next time
+func ListPodsSince(clientset kubernetes.Interface, namespace, resourceVersion string) ([]v1.Pod, string, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), listOptionsSince(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to list pods in namespace %s: %v", namespace, err)
+		return nil, "", err
+	}
+	return pods.Items, pods.ResourceVersion, nil
+}
+
+// ListDeploymentsSince lists the deployments in the specified namespace no
+// older than resourceVersion, and returns the list's resourceVersion
+func ListDeploymentsSince(clientset kubernetes.Interface, namespace, resourceVersion string) ([]appsv1.Deployment, string, error) {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), listOptionsSince(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to list deployments in namespace %s: %v", namespace, err)
+		return nil, "", err
+	}
+	return deployments.Items, deployments.ResourceVersion, nil
+}
+
+// ListServicesSince lists the services in the specified namespace no older
+// than resourceVersion, and returns the list's resourceVersion
+func ListServicesSince(clientset kubernetes.Interface, namespace, resourceVersion string) ([]v1.Service, string, error) {
+	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), listOptionsSince(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to list services in namespace %s: %v", namespace, err)
+		return nil, "", err
+	}
+	return services.Items, services.ResourceVersion, nil
+}
+
+// ListConfigMapSummariesSince summarizes the configmaps in the specified
+// namespace no older than resourceVersion, and returns the list's
+// resourceVersion
+func ListConfigMapSummariesSince(clientset kubernetes.Interface, namespace, resourceVersion string) ([]ConfigMapSummary, string, error) {
+	configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), listOptionsSince(resourceVersion))
+	if err != nil {
+		klog.Errorf("Failed to list configmaps in namespace %s: %v", namespace, err)
+		return nil, "", err
+	}
+	return SummarizeConfigMaps(configMaps.Items), configMaps.ResourceVersion, nil
+}
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWatchForwardsResourceVersion(t *testing.T) {
+	tests := []struct {
+		resource string
+		watch    func(kubernetes.Interface, string, string) (*WatchHandle, error)
+	}{
+		{"pods", WatchPods},
+		{"deployments", WatchDeployments},
+		{"services", WatchServices},
+		{"configmaps", WatchConfigMaps},
+	}
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			clientset := fake.NewSimpleClientset()
+			var got []k8stesting.WatchRestrictions
+			clientset.PrependWatchReactor(tt.resource, func(action k8stesting.Action) (bool, watch.Interface, error) {
+				got = append(got, action.(k8stesting.WatchAction).GetWatchRestrictions())
+				return true, watch.NewFake(), nil
+			})
+
+			for _, rv := range []string{"", "42"} {
+				handle, err := tt.watch(clientset, "default", rv)
+				if err != nil {
+					t.Fatalf("Failed to watch: %v", err)
+				}
+				if handle.ResourceVersion() != rv {
+					t.Errorf("Expected the handle to start at %q, got %q", rv, handle.ResourceVersion())
+				}
+				handle.Stop()
+			}
+			if len(got) != 2 || got[0].ResourceVersion != "" || got[1].ResourceVersion != "42" {
+				t.Errorf("Expected watches from now and from 42, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestWatchHandleTracksResourceVersion(t *testing.T) {
+	fakeWatcher := watch.NewFake()
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, fakeWatcher, nil
+	})
+	handle, err := WatchPods(clientset, "default", "42")
+	if err != nil {
+		t.Fatalf("Failed to watch pods: %v", err)
+	}
+	defer handle.Stop()
+
+	pod := func(rv string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", ResourceVersion: rv}}
+	}
+
+	go fakeWatcher.Add(pod("43"))
+	event := <-handle.ResultChan()
+	if event.Type != watch.Added || handle.ResourceVersion() != "43" {
+		t.Errorf("Expected an ADDED event at 43, got %s at %q", event.Type, handle.ResourceVersion())
+	}
+
+	// Errors carry no resourceVersion to resume from
+	go fakeWatcher.Error(&metav1.Status{Reason: metav1.StatusReasonInternalError})
+	if event := <-handle.ResultChan(); event.Type != watch.Error || handle.ResourceVersion() != "43" {
+		t.Errorf("Expected an error event keeping 43, got %s at %q", event.Type, handle.ResourceVersion())
+	}
+
+	// Bookmarks move the resourceVersion on without being delivered
+	fakeWatcher.Action(watch.Bookmark, pod("50"))
+	fakeWatcher.Stop()
+	if event, ok := <-handle.ResultChan(); ok {
+		t.Errorf("Expected the bookmark to be swallowed and the watch to end, got %s", event.Type)
+	}
+	if handle.ResourceVersion() != "50" {
+		t.Errorf("Expected to resume from the bookmark's 50, got %q", handle.ResourceVersion())
+	}
+}
+
+func TestWatchHandleStopUnblocks(t *testing.T) {
+	fakeWatcher := watch.NewFakeWithChanSize(1, false)
+	handle := newWatchHandle(fakeWatcher, "")
+	fakeWatcher.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+
+	// Nobody reads the event, and stopping must not leave forward blocked
+	handle.Stop()
+	for range handle.ResultChan() {
+	}
+	if !fakeWatcher.IsStopped() {
+		t.Error("Expected Stop to stop the underlying watch")
+	}
+}
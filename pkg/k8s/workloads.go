@@ -0,0 +1,199 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// RestartedAtAnnotation is the pod template annotation kubectl rollout
+// restart sets; changing it rolls out new pods
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// scaleClient reads and writes the scale subresource of one kind
+type scaleClient interface {
+	GetScale(ctx context.Context, name string, options metav1.GetOptions) (*autoscalingv1.Scale, error)
+	UpdateScale(ctx context.Context, name string, scale *autoscalingv1.Scale, opts metav1.UpdateOptions) (*autoscalingv1.Scale, error)
+}
+
+// scaleClients maps the plural kinds ScaleWorkload supports to their scale
+// subresource
+var scaleClients = map[string]func(clientset kubernetes.Interface, namespace string) scaleClient{
+	"deployments": func(cs kubernetes.Interface, ns string) scaleClient {
+		return cs.AppsV1().Deployments(ns)
+	},
+	"statefulsets": func(cs kubernetes.Interface, ns string) scaleClient {
+		return cs.AppsV1().StatefulSets(ns)
+	},
+}
+
+// restartableKinds are the plural kinds RestartWorkload supports. Their pod
+// templates are patched through metadataPatchers.
+var restartableKinds = map[string]bool{
+	"deployments":  true,
+	"statefulsets": true,
+	"daemonsets":   true,
+}
+
+// SupportsScale reports whether ScaleWorkload supports a plural kind
+func SupportsScale(kind string) bool {
+	_, ok := scaleClients[kind]
+	return ok
+}
+
+// SupportsRestart reports whether RestartWorkload supports a plural kind
+func SupportsRestart(kind string) bool {
+	return restartableKinds[kind]
+}
+
+// ScaleWorkload sets the replicas of a deployment or statefulset through its
+// scale subresource, like kubectl scale
+func ScaleWorkload(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string, replicas int32) error {
+	newClient, ok := scaleClients[kind]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnsupportedKind, kind)
+	}
+	if replicas < 0 {
+		return fmt.Errorf("replicas must not be negative, got %d", replicas)
+	}
+
+	client := newClient(clientset, namespace)
+	scale, err := client.GetScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get the scale of %s %s in namespace %s: %v", kind, name, namespace, err)
+		return err
+	}
+	scale.Spec.Replicas = replicas
+	if _, err := client.UpdateScale(ctx, name, scale, metav1.UpdateOptions{FieldManager: fieldManager}); err != nil {
+		klog.Errorf("Failed to scale %s %s in namespace %s: %v", kind, name, namespace, err)
+		return err
+	}
+	return nil
+}
+
+// RestartWorkload restarts the pods of a deployment, statefulset or daemonset
+// by setting RestartedAtAnnotation on its pod template, like kubectl rollout
+// restart, and returns the patched object
+func RestartWorkload(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string, now time.Time) (metav1.Object, error) {
+	if !restartableKinds[kind] {
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedKind, kind)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{RestartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the restart patch: %v", err)
+	}
+
+	obj, err := metadataPatchers[kind](ctx, clientset, namespace, name, patch)
+	if err != nil {
+		klog.Errorf("Failed to restart %s %s in namespace %s: %v", kind, name, namespace, err)
+		return nil, err
+	}
+	return obj, nil
+}
+
+// StatefulSetPartition returns the rolling update partition of a
+// statefulset: pods with a lower ordinal keep their revision on updates
+func StatefulSetPartition(sts *appsv1.StatefulSet) int32 {
+	rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate == nil || rollingUpdate.Partition == nil {
+		return 0
+	}
+	return *rollingUpdate.Partition
+}
+
+// RestartWarning explains which pods a restart of a workload leaves alone:
+// all of them under the OnDelete update strategy, and those below the
+// partition of a statefulset. It returns an empty string when a restart
+// replaces every pod.
+func RestartWarning(obj metav1.Object) string {
+	switch w := obj.(type) {
+	case *appsv1.StatefulSet:
+		if w.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			return "update strategy is OnDelete, so pods are only restarted when they are deleted"
+		}
+		partition := StatefulSetPartition(w)
+		if partition == 0 {
+			return ""
+		}
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		if partition >= replicas {
+			return fmt.Sprintf("rolling update partition is %d, so none of the %d pods are restarted", partition, replicas)
+		}
+		return fmt.Sprintf("rolling update partition is %d, so pods with an ordinal below %d are not restarted", partition, partition)
+	case *appsv1.DaemonSet:
+		if w.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			return "update strategy is OnDelete, so pods are only restarted when they are deleted"
+		}
+	}
+	return ""
+}
+
+// GetStatefulSetPVCs returns the PVCs a statefulset created from its volume
+// claim templates, by the name of the pod they belong to, e.g. "web-0". PVCs
+// outlive their pods, so pods scaled away may still have some.
+func GetStatefulSetPVCs(ctx context.Context, clientset kubernetes.Interface, namespace, statefulSetName string) (map[string][]v1.PersistentVolumeClaim, error) {
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, statefulSetName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get statefulset %s in namespace %s: %v", statefulSetName, namespace, err)
+		return nil, err
+	}
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list persistent volume claims in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+	return GroupStatefulSetPVCs(sts, pvcs.Items), nil
+}
+
+// GroupStatefulSetPVCs picks the PVCs named <template>-<statefulset>-<ordinal>
+// after the volume claim templates of a statefulset and groups them by pod
+// name, in template order
+func GroupStatefulSetPVCs(sts *appsv1.StatefulSet, pvcs []v1.PersistentVolumeClaim) map[string][]v1.PersistentVolumeClaim {
+	grouped := make(map[string][]v1.PersistentVolumeClaim)
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		prefix := template.Name + "-" + sts.Name + "-"
+		for _, pvc := range pvcs {
+			ordinal, ok := strings.CutPrefix(pvc.Name, prefix)
+			if !ok || !isOrdinal(ordinal) {
+				continue
+			}
+			pod := sts.Name + "-" + ordinal
+			grouped[pod] = append(grouped[pod], pvc)
+		}
+	}
+	return grouped
+}
+
+// isOrdinal reports whether s is the ordinal of a statefulset pod, which
+// tells web-0 from the pods of a statefulset named web-cache
+func isOrdinal(s string) bool {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// serveStatefulSetScale emulates the scale subresource of statefulsets,
+// which the fake clientset would read and store as the statefulset itself
+func serveStatefulSetScale(clientset *fake.Clientset) {
+	gvr := appsv1.SchemeGroupVersion.WithResource("statefulsets")
+	clientset.PrependReactor("get", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := clientset.Tracker().Get(gvr, action.GetNamespace(), action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		sts := obj.(*appsv1.StatefulSet)
+		return true, &autoscalingv1.Scale{ObjectMeta: sts.ObjectMeta, Spec: autoscalingv1.ScaleSpec{Replicas: *sts.Spec.Replicas}}, nil
+	})
+	clientset.PrependReactor("update", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scale := action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		obj, err := clientset.Tracker().Get(gvr, action.GetNamespace(), scale.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		sts := obj.(*appsv1.StatefulSet)
+		sts.Spec.Replicas = &scale.Spec.Replicas
+		return true, scale, clientset.Tracker().Update(gvr, sts, action.GetNamespace())
+	})
+}
+
+// partitionedStatefulSet returns a statefulset of replicas pods whose rolling
+// updates only replace those with an ordinal of partition or more
+func partitionedStatefulSet(replicas, partition int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+	}
+}
+
+func TestScaleWorkload(t *testing.T) {
+	clientset := fake.NewSimpleClientset(partitionedStatefulSet(3, 0))
+	serveStatefulSetScale(clientset)
+
+	if err := ScaleWorkload(context.Background(), clientset, "statefulsets", "shop", "db", 5); err != nil {
+		t.Fatalf("ScaleWorkload failed: %v", err)
+	}
+	sts, err := clientset.AppsV1().StatefulSets("shop").Get(context.Background(), "db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get statefulset: %v", err)
+	}
+	if *sts.Spec.Replicas != 5 {
+		t.Errorf("Expected 5 replicas, got %d", *sts.Spec.Replicas)
+	}
+
+	if err := ScaleWorkload(context.Background(), clientset, "statefulsets", "shop", "db", -1); err == nil {
+		t.Error("Expected an error for negative replicas")
+	}
+	if err := ScaleWorkload(context.Background(), clientset, "daemonsets", "shop", "agent", 2); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("Expected ErrUnsupportedKind for daemonsets, got %v", err)
+	}
+	if err := ScaleWorkload(context.Background(), clientset, "statefulsets", "shop", "missing", 2); err == nil {
+		t.Error("Expected an error for a missing statefulset")
+	}
+}
+
+func TestRestartWorkload(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		partitionedStatefulSet(3, 2),
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "shop"}},
+	)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, kind := range []string{"statefulsets", "daemonsets"} {
+		name := map[string]string{"statefulsets": "db", "daemonsets": "agent"}[kind]
+		if _, err := RestartWorkload(context.Background(), clientset, kind, "shop", name, now); err != nil {
+			t.Fatalf("RestartWorkload(%s) failed: %v", kind, err)
+		}
+	}
+	sts, _ := clientset.AppsV1().StatefulSets("shop").Get(context.Background(), "db", metav1.GetOptions{})
+	ds, _ := clientset.AppsV1().DaemonSets("shop").Get(context.Background(), "agent", metav1.GetOptions{})
+	for kind, annotations := range map[string]map[string]string{"statefulset": sts.Spec.Template.Annotations, "daemonset": ds.Spec.Template.Annotations} {
+		if annotations[RestartedAtAnnotation] != "2024-01-01T12:00:00Z" {
+			t.Errorf("Expected the %s pod template to be annotated, got %v", kind, annotations)
+		}
+	}
+	if StatefulSetPartition(sts) != 2 || *sts.Spec.Replicas != 3 {
+		t.Errorf("Expected the restart to keep the partition and replicas, got %+v", sts.Spec)
+	}
+
+	if _, err := RestartWorkload(context.Background(), clientset, "pods", "shop", "db-0", now); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("Expected ErrUnsupportedKind for pods, got %v", err)
+	}
+}
+
+func TestRestartWarning(t *testing.T) {
+	onDelete := partitionedStatefulSet(3, 0)
+	onDelete.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	tests := []struct {
+		name string
+		obj  metav1.Object
+		want string
+	}{
+		{"no partition", partitionedStatefulSet(3, 0), ""},
+		{"partition", partitionedStatefulSet(3, 2), "rolling update partition is 2, so pods with an ordinal below 2 are not restarted"},
+		{"partition above replicas", partitionedStatefulSet(3, 3), "rolling update partition is 3, so none of the 3 pods are restarted"},
+		{"statefulset on delete", onDelete, "update strategy is OnDelete, so pods are only restarted when they are deleted"},
+		{"daemonset", &appsv1.DaemonSet{}, ""},
+		{"daemonset on delete", &appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}}}, "update strategy is OnDelete, so pods are only restarted when they are deleted"},
+		{"deployment", &appsv1.Deployment{}, ""},
+	}
+	for _, tt := range tests {
+		if got := RestartWarning(tt.obj); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestGroupStatefulSetPVCs(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+			{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "logs"}},
+		}},
+	}
+	pvc := func(name string, phase v1.PersistentVolumeClaimPhase) v1.PersistentVolumeClaim {
+		return v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	pvcs := []v1.PersistentVolumeClaim{
+		pvc("data-web-0", v1.ClaimBound),
+		pvc("logs-web-0", v1.ClaimBound),
+		pvc("data-web-1", v1.ClaimPending),
+		// Left behind by a scale down
+		pvc("data-web-12", v1.ClaimLost),
+		// Another statefulset's, or not a claim of a pod at all
+		pvc("data-web-cache-0", v1.ClaimBound),
+		pvc("data-web-01", v1.ClaimBound),
+		pvc("data-web-", v1.ClaimBound),
+		pvc("scratch-web-0", v1.ClaimBound),
+	}
+
+	grouped := GroupStatefulSetPVCs(sts, pvcs)
+	want := map[string][]string{
+		"web-0":  {"data-web-0", "logs-web-0"},
+		"web-1":  {"data-web-1"},
+		"web-12": {"data-web-12"},
+	}
+	if len(grouped) != len(want) {
+		t.Fatalf("Expected the PVCs of %d pods, got %+v", len(want), grouped)
+	}
+	for pod, names := range want {
+		var got []string
+		for _, claim := range grouped[pod] {
+			got = append(got, claim.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(names, ",") {
+			t.Errorf("Expected %v for %s, got %v", names, pod, got)
+		}
+	}
+	if grouped["web-12"][0].Status.Phase != v1.ClaimLost {
+		t.Errorf("Expected the PVCs with their status, got %+v", grouped["web-12"])
+	}
+
+	clientset := fake.NewSimpleClientset(sts, &pvcs[0], &pvcs[2], &pvcs[4])
+	fetched, err := GetStatefulSetPVCs(context.Background(), clientset, "default", "web")
+	if err != nil || len(fetched) != 2 || len(fetched["web-0"]) != 1 || len(fetched["web-1"]) != 1 {
+		t.Errorf("Expected the PVCs of web-0 and web-1, got %+v (%v)", fetched, err)
+	}
+	if missing, err := GetStatefulSetPVCs(context.Background(), clientset, "default", "missing"); !apierrors.IsNotFound(err) || missing != nil {
+		t.Errorf("Expected a not found error for a missing statefulset, got %+v (%v)", missing, err)
+	}
+
+	// A failed list is returned rather than read as no PVCs
+	clientset.PrependReactor("list", "persistentvolumeclaims", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("persistentvolumeclaims"), "", errors.New("rbac"))
+	})
+	if _, err := GetStatefulSetPVCs(context.Background(), clientset, "default", "web"); !apierrors.IsForbidden(err) {
+		t.Errorf("Expected the list error, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+// Package metrics collects object counts for the cluster and its namespaces
+package metrics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// SchemaVersion is the version of the ClusterMetrics and NamespaceMetrics
+// wire format. It is raised whenever a field is removed, renamed or changes
+// type, so that consumers can detect the change.
+const SchemaVersion = 1
+
+// ClusterMetrics summarises the whole cluster; it is also the body of
+// GET /api/v1/metrics/cluster
+type ClusterMetrics struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Cluster       ClusterCounts   `json:"cluster"`
+	PodStatus     PodStatusCounts `json:"pod_status"`
+	Timestamp     int64           `json:"timestamp"`
+}
+
+// ClusterCounts counts the objects in the cluster
+type ClusterCounts struct {
+	Nodes      int `json:"nodes"`
+	Pods       int `json:"pods"`
+	Namespaces int `json:"namespaces"`
+}
+
+// PodStatusCounts counts the pods in the cluster by phase. Pods without a
+// phase, or with one added by a later Kubernetes version, are unknown.
+type PodStatusCounts struct {
+	Running   int `json:"running"`
+	Pending   int `json:"pending"`
+	Failed    int `json:"failed"`
+	Succeeded int `json:"succeeded"`
+	Unknown   int `json:"unknown"`
+}
+
+// NamespaceMetrics summarises a namespace; it is also the body of
+// GET /api/v1/metrics/namespace/:namespace
+type NamespaceMetrics struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Namespace     string           `json:"namespace"`
+	Pods          PodCounts        `json:"pods"`
+	Deployments   DeploymentCounts `json:"deployments"`
+	Services      ServiceCounts    `json:"services"`
+	Timestamp     int64            `json:"timestamp"`
+}
+
+// AllNamespaceMetrics summarises several namespaces; it is the body of
+// GET /api/v1/metrics/namespaces
+type AllNamespaceMetrics struct {
+	SchemaVersion int                `json:"schemaVersion"`
+	Namespaces    []NamespaceMetrics `json:"namespaces"`
+	Timestamp     int64              `json:"timestamp"`
+}
+
+// PodCounts counts the pods in a namespace by phase
+type PodCounts struct {
+	Total     int `json:"total"`
+	Running   int `json:"running"`
+	Pending   int `json:"pending"`
+	Failed    int `json:"failed"`
+	Succeeded int `json:"succeeded"`
+}
+
+// DeploymentCounts counts the deployments in a namespace by rollout state
+type DeploymentCounts struct {
+	Total  int                    `json:"total"`
+	Status DeploymentStatusCounts `json:"status"`
+}
+
+// DeploymentStatusCounts counts deployments by whether all, some or none of
+// their replicas are ready
+type DeploymentStatusCounts struct {
+	Available   int `json:"available"`
+	Unavailable int `json:"unavailable"`
+	Updating    int `json:"updating"`
+}
+
+// ServiceCounts counts the services in a namespace
+type ServiceCounts struct {
+	Total int `json:"total"`
+}
+
+// CollectClusterMetrics counts the nodes, pods and namespaces in the cluster,
+// stamped with the given time
+func CollectClusterMetrics(clientset kubernetes.Interface, now time.Time) (*ClusterMetrics, error) {
+	// Get node count
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list nodes: %v", err)
+		return nil, err
+	}
+
+	// Get pod count across all namespaces
+	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		return nil, err
+	}
+
+	// Get namespace count
+	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list namespaces: %v", err)
+		return nil, err
+	}
+
+	// Calculate pod status counts
+	var podStatus PodStatusCounts
+	for _, pod := range pods.Items {
+		switch pod.Status.Phase {
+		case v1.PodRunning:
+			podStatus.Running++
+		case v1.PodPending:
+			podStatus.Pending++
+		case v1.PodFailed:
+			podStatus.Failed++
+		case v1.PodSucceeded:
+			podStatus.Succeeded++
+		default:
+			podStatus.Unknown++
+		}
+	}
+
+	return &ClusterMetrics{
+		SchemaVersion: SchemaVersion,
+		Cluster: ClusterCounts{
+			Nodes:      len(nodes.Items),
+			Pods:       len(pods.Items),
+			Namespaces: len(namespaces.Items),
+		},
+		PodStatus: podStatus,
+		Timestamp: now.Unix(),
+	}, nil
+}
+
+// CollectNamespaceMetrics counts the pods, deployments and services in a
+// namespace, stamped with the given time
+func CollectNamespaceMetrics(clientset kubernetes.Interface, namespace string, now time.Time) (*NamespaceMetrics, error) {
+	// Get pods in namespace
+	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list pods in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+
+	// Get deployments in namespace
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list deployments in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+
+	// Get services in namespace
+	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list services in namespace %s: %v", namespace, err)
+		return nil, err
+	}
+
+	m := &NamespaceMetrics{SchemaVersion: SchemaVersion, Namespace: namespace, Timestamp: now.Unix()}
+	for i := range pods.Items {
+		m.countPod(&pods.Items[i])
+	}
+	for i := range deployments.Items {
+		m.countDeployment(&deployments.Items[i])
+	}
+	m.Services.Total = len(services.Items)
+	return m, nil
+}
+
+// CollectAllNamespaceMetrics summarises every namespace matching
+// labelSelector, empty for all of them, stamped with the given time. It lists
+// pods, deployments and services once for the whole cluster rather than once
+// per namespace.
+func CollectAllNamespaceMetrics(ctx context.Context, clientset kubernetes.Interface, labelSelector string, now time.Time) (*AllNamespaceMetrics, error) {
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		klog.Errorf("Failed to list namespaces: %v", err)
+		return nil, err
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		return nil, err
+	}
+
+	deployments, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list deployments: %v", err)
+		return nil, err
+	}
+
+	services, err := clientset.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list services: %v", err)
+		return nil, err
+	}
+
+	names := make([]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	return &AllNamespaceMetrics{
+		SchemaVersion: SchemaVersion,
+		Namespaces:    GroupNamespaceMetrics(names, pods.Items, deployments.Items, services.Items, now),
+		Timestamp:     now.Unix(),
+	}, nil
+}
+
+// GroupNamespaceMetrics summarises each of namespaces from cluster-wide lists
+// of pods, deployments and services, in one pass over each list. The result
+// is sorted by namespace; objects of other namespaces are ignored.
+func GroupNamespaceMetrics(namespaces []string, pods []v1.Pod, deployments []appsv1.Deployment, services []v1.Service, now time.Time) []NamespaceMetrics {
+	sorted := append([]string(nil), namespaces...)
+	sort.Strings(sorted)
+
+	result := make([]NamespaceMetrics, len(sorted))
+	byName := make(map[string]*NamespaceMetrics, len(sorted))
+	for i, namespace := range sorted {
+		result[i] = NamespaceMetrics{SchemaVersion: SchemaVersion, Namespace: namespace, Timestamp: now.Unix()}
+		byName[namespace] = &result[i]
+	}
+
+	for i := range pods {
+		if m, ok := byName[pods[i].Namespace]; ok {
+			m.countPod(&pods[i])
+		}
+	}
+	for i := range deployments {
+		if m, ok := byName[deployments[i].Namespace]; ok {
+			m.countDeployment(&deployments[i])
+		}
+	}
+	for i := range services {
+		if m, ok := byName[services[i].Namespace]; ok {
+			m.Services.Total++
+		}
+	}
+	return result
+}
+
+// countPod adds a pod to the pod counts by its phase
+func (m *NamespaceMetrics) countPod(pod *v1.Pod) {
+	m.Pods.Total++
+	switch pod.Status.Phase {
+	case v1.PodRunning:
+		m.Pods.Running++
+	case v1.PodPending:
+		m.Pods.Pending++
+	case v1.PodFailed:
+		m.Pods.Failed++
+	case v1.PodSucceeded:
+		m.Pods.Succeeded++
+	}
+}
+
+// countDeployment adds a deployment to the deployment counts by whether all,
+// some or none of its replicas are ready
+func (m *NamespaceMetrics) countDeployment(deployment *appsv1.Deployment) {
+	m.Deployments.Total++
+	if deployment.Status.ReadyReplicas == deployment.Status.Replicas {
+		m.Deployments.Status.Available++
+	} else if deployment.Status.ReadyReplicas > 0 {
+		m.Deployments.Status.Updating++
+	} else {
+		m.Deployments.Status.Unavailable++
+	}
+}
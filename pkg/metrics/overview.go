@@ -0,0 +1,254 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// OverviewEventWindow is how far back the overview looks for Warning events
+	OverviewEventWindow = time.Hour
+	// OverviewEventLimit is how many of the latest Warning events it keeps
+	OverviewEventLimit = 10
+)
+
+// Overview answers "is the cluster okay" at a glance; it is also the body of
+// GET /api/v1/overview
+type Overview struct {
+	Nodes       NodeOverview       `json:"nodes"`
+	Pods        PodOverview        `json:"pods"`
+	Deployments DeploymentOverview `json:"deployments"`
+	// WarningEvents are the latest Warning events of the last
+	// OverviewEventWindow, newest first
+	WarningEvents []WarningEvent `json:"warningEvents"`
+	// Namespaces count the pods of every namespace with pods, most first
+	Namespaces []NamespacePods `json:"namespaces"`
+	Timestamp  int64           `json:"timestamp"`
+}
+
+// NodeOverview counts the nodes and those that are Ready
+type NodeOverview struct {
+	Total int `json:"total"`
+	Ready int `json:"ready"`
+}
+
+// PodOverview counts the pods by phase, and those that need attention
+type PodOverview struct {
+	Total    int            `json:"total"`
+	Phases   map[string]int `json:"phases"`
+	NotReady int            `json:"notReady"`
+}
+
+// DeploymentOverview counts the deployments and lists those not fully
+// available
+type DeploymentOverview struct {
+	Total    int                  `json:"total"`
+	Degraded []DegradedDeployment `json:"degraded"`
+}
+
+// DegradedDeployment is a deployment with fewer available replicas than desired
+type DegradedDeployment struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Desired   int32  `json:"desired"`
+	Available int32  `json:"available"`
+}
+
+// WarningEvent is a Warning event and the object it is about
+type WarningEvent struct {
+	Namespace string      `json:"namespace"`
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name"`
+	Reason    string      `json:"reason"`
+	Message   string      `json:"message"`
+	Count     int32       `json:"count"`
+	LastSeen  metav1.Time `json:"lastSeen"`
+}
+
+// NamespacePods counts the pods of a namespace and those that are not ready
+type NamespacePods struct {
+	Namespace string `json:"namespace"`
+	Pods      int    `json:"pods"`
+	NotReady  int    `json:"notReady"`
+}
+
+// CollectOverview lists the nodes, pods, deployments and Warning events of
+// the whole cluster and summarizes them with BuildOverview
+func CollectOverview(ctx context.Context, clientset kubernetes.Interface) (*Overview, error) {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list nodes: %v", err)
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		return nil, err
+	}
+	deployments, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list deployments: %v", err)
+		return nil, err
+	}
+	selector := fields.OneTermEqualSelector("type", v1.EventTypeWarning).String()
+	events, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		klog.Errorf("Failed to list warning events: %v", err)
+		return nil, err
+	}
+	return BuildOverview(nodes.Items, pods.Items, deployments.Items, events.Items, time.Now()), nil
+}
+
+// BuildOverview summarizes nodes, pods, deployments and events at now
+func BuildOverview(nodes []v1.Node, pods []v1.Pod, deployments []appsv1.Deployment, events []v1.Event, now time.Time) *Overview {
+	overview := &Overview{
+		Pods:          PodOverview{Phases: make(map[string]int)},
+		Deployments:   DeploymentOverview{Degraded: []DegradedDeployment{}},
+		WarningEvents: []WarningEvent{},
+		Namespaces:    []NamespacePods{},
+		Timestamp:     now.Unix(),
+	}
+
+	overview.Nodes.Total = len(nodes)
+	for i := range nodes {
+		if IsNodeReady(&nodes[i]) {
+			overview.Nodes.Ready++
+		}
+	}
+
+	namespaces := make(map[string]*NamespacePods)
+	overview.Pods.Total = len(pods)
+	for i := range pods {
+		pod := &pods[i]
+		phase := string(pod.Status.Phase)
+		if phase == "" {
+			phase = string(v1.PodUnknown)
+		}
+		overview.Pods.Phases[phase]++
+
+		counts, ok := namespaces[pod.Namespace]
+		if !ok {
+			counts = &NamespacePods{Namespace: pod.Namespace}
+			namespaces[pod.Namespace] = counts
+		}
+		counts.Pods++
+		if IsPodNotReady(pod) {
+			overview.Pods.NotReady++
+			counts.NotReady++
+		}
+	}
+	for _, counts := range namespaces {
+		overview.Namespaces = append(overview.Namespaces, *counts)
+	}
+	sort.Slice(overview.Namespaces, func(i, j int) bool {
+		a, b := overview.Namespaces[i], overview.Namespaces[j]
+		if a.Pods != b.Pods {
+			return a.Pods > b.Pods
+		}
+		return a.Namespace < b.Namespace
+	})
+
+	overview.Deployments.Total = len(deployments)
+	for i := range deployments {
+		deployment := &deployments[i]
+		if IsDeploymentDegraded(deployment) {
+			overview.Deployments.Degraded = append(overview.Deployments.Degraded, DegradedDeployment{
+				Namespace: deployment.Namespace,
+				Name:      deployment.Name,
+				Desired:   desiredReplicas(deployment),
+				Available: deployment.Status.AvailableReplicas,
+			})
+		}
+	}
+	sort.Slice(overview.Deployments.Degraded, func(i, j int) bool {
+		a, b := overview.Deployments.Degraded[i], overview.Deployments.Degraded[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	for i := range events {
+		event := &events[i]
+		lastSeen := eventLastSeen(event)
+		if event.Type != v1.EventTypeWarning || now.Sub(lastSeen.Time) > OverviewEventWindow {
+			continue
+		}
+		overview.WarningEvents = append(overview.WarningEvents, WarningEvent{
+			Namespace: event.InvolvedObject.Namespace,
+			Kind:      event.InvolvedObject.Kind,
+			Name:      event.InvolvedObject.Name,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     event.Count,
+			LastSeen:  lastSeen,
+		})
+	}
+	sort.SliceStable(overview.WarningEvents, func(i, j int) bool {
+		return overview.WarningEvents[i].LastSeen.After(overview.WarningEvents[j].LastSeen.Time)
+	})
+	if len(overview.WarningEvents) > OverviewEventLimit {
+		overview.WarningEvents = overview.WarningEvents[:OverviewEventLimit]
+	}
+	return overview
+}
+
+// IsNodeReady reports whether a node's Ready condition is True
+func IsNodeReady(node *v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// IsPodNotReady reports whether a pod needs attention: it is pending, failed
+// or unknown, or running without being ready. Completed pods are fine.
+func IsPodNotReady(pod *v1.Pod) bool {
+	switch pod.Status.Phase {
+	case v1.PodSucceeded:
+		return false
+	case v1.PodRunning:
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodReady {
+				return condition.Status != v1.ConditionTrue
+			}
+		}
+	}
+	return true
+}
+
+// IsDeploymentDegraded reports whether a deployment has fewer available
+// replicas than it wants
+func IsDeploymentDegraded(deployment *appsv1.Deployment) bool {
+	return deployment.Status.AvailableReplicas < desiredReplicas(deployment)
+}
+
+// desiredReplicas returns the replicas a deployment wants, which default to 1
+func desiredReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
+// eventLastSeen returns when an event last happened, from whichever of its
+// timestamps is set
+func eventLastSeen(event *v1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.NewTime(event.EventTime.Time)
+	}
+	return event.FirstTimestamp
+}
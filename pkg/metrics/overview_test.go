@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func overviewPod(namespace, name string, phase v1.PodPhase, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: v1.PodStatus{
+			Phase:      phase,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func overviewNode(name string, ready v1.ConditionStatus) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}}},
+	}
+}
+
+func overviewDeployment(namespace, name string, replicas, available int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+	}
+}
+
+func overviewEvent(name, eventType, reason string, ago time.Duration) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web-1"},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        reason + " happened",
+		Count:          2,
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-ago)),
+	}
+}
+
+func TestCollectOverview(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		overviewNode("node-1", v1.ConditionTrue),
+		overviewNode("node-2", v1.ConditionUnknown),
+		overviewPod("shop", "web-1", v1.PodRunning, true),
+		overviewPod("shop", "web-2", v1.PodRunning, false),
+		overviewPod("shop", "migrate", v1.PodSucceeded, false),
+		overviewPod("default", "pending", v1.PodPending, false),
+		overviewDeployment("shop", "web", 3, 1),
+		overviewDeployment("shop", "api", 2, 2),
+		overviewEvent("old", v1.EventTypeWarning, "BackOff", 2*time.Hour),
+		overviewEvent("normal", v1.EventTypeNormal, "Pulled", time.Minute),
+		overviewEvent("older", v1.EventTypeWarning, "Unhealthy", 10*time.Minute),
+		overviewEvent("newer", v1.EventTypeWarning, "BackOff", time.Minute),
+	)
+
+	overview, err := CollectOverview(context.Background(), clientset)
+	if err != nil {
+		t.Fatalf("CollectOverview failed: %v", err)
+	}
+
+	if overview.Nodes != (NodeOverview{Total: 2, Ready: 1}) {
+		t.Errorf("Unexpected nodes %+v", overview.Nodes)
+	}
+	if overview.Pods.Total != 4 || overview.Pods.NotReady != 2 ||
+		overview.Pods.Phases["Running"] != 2 || overview.Pods.Phases["Succeeded"] != 1 || overview.Pods.Phases["Pending"] != 1 {
+		t.Errorf("Unexpected pods %+v", overview.Pods)
+	}
+
+	want := DegradedDeployment{Namespace: "shop", Name: "web", Desired: 3, Available: 1}
+	if overview.Deployments.Total != 2 || len(overview.Deployments.Degraded) != 1 || overview.Deployments.Degraded[0] != want {
+		t.Errorf("Expected only %+v to be degraded, got %+v", want, overview.Deployments)
+	}
+
+	// Normal and old events are left out, and the newest comes first
+	if len(overview.WarningEvents) != 2 || overview.WarningEvents[0].Reason != "BackOff" || overview.WarningEvents[1].Reason != "Unhealthy" {
+		t.Fatalf("Expected the two recent warnings newest first, got %+v", overview.WarningEvents)
+	}
+	if event := overview.WarningEvents[0]; event.Kind != "Pod" || event.Name != "web-1" || event.Namespace != "shop" || event.Count != 2 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	wantNamespaces := []NamespacePods{{Namespace: "shop", Pods: 3, NotReady: 1}, {Namespace: "default", Pods: 1, NotReady: 1}}
+	if len(overview.Namespaces) != 2 || overview.Namespaces[0] != wantNamespaces[0] || overview.Namespaces[1] != wantNamespaces[1] {
+		t.Errorf("Expected %+v, got %+v", wantNamespaces, overview.Namespaces)
+	}
+}
+
+func TestBuildOverviewLimitsEvents(t *testing.T) {
+	var events []v1.Event
+	for i := 0; i < OverviewEventLimit+5; i++ {
+		events = append(events, *overviewEvent("e", v1.EventTypeWarning, "BackOff", time.Duration(i)*time.Minute))
+	}
+	overview := BuildOverview(nil, nil, nil, events, time.Now())
+	if len(overview.WarningEvents) != OverviewEventLimit {
+		t.Errorf("Expected %d events, got %d", OverviewEventLimit, len(overview.WarningEvents))
+	}
+	if overview.Namespaces == nil || overview.Deployments.Degraded == nil {
+		t.Error("Expected empty lists rather than null in JSON")
+	}
+}
+
+func TestIsPodNotReady(t *testing.T) {
+	tests := []struct {
+		pod  *v1.Pod
+		want bool
+	}{
+		{overviewPod("default", "ready", v1.PodRunning, true), false},
+		{overviewPod("default", "unready", v1.PodRunning, false), true},
+		{overviewPod("default", "done", v1.PodSucceeded, false), false},
+		{overviewPod("default", "failed", v1.PodFailed, false), true},
+		{overviewPod("default", "pending", v1.PodPending, false), true},
+	}
+	for _, tt := range tests {
+		if got := IsPodNotReady(tt.pod); got != tt.want {
+			t.Errorf("IsPodNotReady(%s) = %v, want %v", tt.pod.Name, got, tt.want)
+		}
+	}
+}
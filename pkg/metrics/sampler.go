@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is one reading of a container's usage from metrics-server
+type Sample struct {
+	// At is when metrics-server took the reading
+	At          time.Time
+	CPUMilli    int64
+	MemoryBytes int64
+}
+
+// Sampler keeps the last two samples of each container, so that their usage
+// can be compared between consecutive readings. It is safe for concurrent
+// use.
+type Sampler struct {
+	mu sync.Mutex
+	// samples holds the previous and the latest sample of each key
+	samples map[string][2]Sample
+	// maxAge is how long a key is kept after its latest sample
+	maxAge time.Duration
+}
+
+// NewSampler returns a sampler forgetting containers with no sample for
+// maxAge
+func NewSampler(maxAge time.Duration) *Sampler {
+	return &Sampler{samples: make(map[string][2]Sample), maxAge: maxAge}
+}
+
+// Record adds the latest sample of key and returns the one before it, if
+// any. metrics-server serves the same reading until its next scrape, so a
+// sample no newer than the latest one is not recorded again.
+func (s *Sampler) Record(key string, sample Sample) (Sample, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for other, samples := range s.samples {
+		if sample.At.Sub(samples[1].At) > s.maxAge {
+			delete(s.samples, other)
+		}
+	}
+
+	samples, ok := s.samples[key]
+	if !ok {
+		s.samples[key] = [2]Sample{{}, sample}
+		return Sample{}, false
+	}
+	if sample.At.After(samples[1].At) {
+		samples = [2]Sample{samples[1], sample}
+		s.samples[key] = samples
+	}
+	return samples[0], !samples[0].At.IsZero()
+}
+
+// CPURateOfChange returns how much the CPU usage changed from previous to
+// latest, as a percentage of previous. It is false when previous used no
+// CPU, as there is no rate to give.
+func CPURateOfChange(previous, latest Sample) (float64, bool) {
+	if previous.CPUMilli <= 0 {
+		return 0, false
+	}
+	return float64(latest.CPUMilli-previous.CPUMilli) * 100 / float64(previous.CPUMilli), true
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSamplerRecord(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sampler := NewSampler(10 * time.Minute)
+
+	if _, ok := sampler.Record("default/web/app", Sample{At: start, CPUMilli: 100}); ok {
+		t.Error("Expected no previous sample on the first reading")
+	}
+	previous, ok := sampler.Record("default/web/app", Sample{At: start.Add(15 * time.Second), CPUMilli: 150})
+	if !ok || previous.CPUMilli != 100 {
+		t.Errorf("Expected the first reading as previous, got %+v, %v", previous, ok)
+	}
+
+	// The same reading served again keeps comparing against the one before
+	previous, ok = sampler.Record("default/web/app", Sample{At: start.Add(15 * time.Second), CPUMilli: 150})
+	if !ok || previous.CPUMilli != 100 {
+		t.Errorf("Expected a repeated reading to keep its previous, got %+v, %v", previous, ok)
+	}
+	previous, ok = sampler.Record("default/web/app", Sample{At: start.Add(30 * time.Second), CPUMilli: 120})
+	if !ok || previous.CPUMilli != 150 {
+		t.Errorf("Expected the second reading as previous, got %+v, %v", previous, ok)
+	}
+
+	// Containers are sampled separately
+	if _, ok := sampler.Record("default/web/sidecar", Sample{At: start.Add(30 * time.Second), CPUMilli: 5}); ok {
+		t.Error("Expected no previous sample for another container")
+	}
+
+	// Containers gone quiet for maxAge are forgotten
+	sampler.Record("default/api/app", Sample{At: start.Add(time.Hour), CPUMilli: 1})
+	if _, ok := sampler.Record("default/web/app", Sample{At: start.Add(time.Hour), CPUMilli: 100}); ok {
+		t.Error("Expected an hour-old container to be forgotten")
+	}
+}
+
+func TestCPURateOfChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous int64
+		latest   int64
+		want     float64
+		ok       bool
+	}{
+		{"increase", 100, 112, 12, true},
+		{"decrease", 200, 150, -25, true},
+		{"unchanged", 80, 80, 0, true},
+		{"from idle", 0, 50, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := CPURateOfChange(Sample{CPUMilli: tt.previous}, Sample{CPUMilli: tt.latest})
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %v, %v, got %v, %v", tt.name, tt.want, tt.ok, got, ok)
+		}
+	}
+}
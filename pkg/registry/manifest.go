@@ -0,0 +1,368 @@
+// Package registry reads image manifests from container registries with the
+// Docker Registry HTTP API v2, to show the size and layers of an image
+// without pulling it
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Manifest and index media types accepted from registries
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+const (
+	// defaultRegistry is the registry of images without a registry host
+	defaultRegistry = "registry-1.docker.io"
+	// lookupTimeout bounds GetManifest, token requests included
+	lookupTimeout = 10 * time.Second
+	// maxManifestSize bounds the manifests and token responses read
+	maxManifestSize = 4 << 20
+)
+
+// Manifest is an image manifest, or an index of the manifests of an image
+// for several platforms (a Docker manifest list or an OCI index)
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+	// Manifests are the platform manifests of an index
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// Descriptor points to a blob or manifest by digest
+type Descriptor struct {
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Platform is the platform of a manifest in an index
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// IsIndex reports whether the manifest lists platform manifests rather than
+// layers
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == MediaTypeDockerManifestList || m.MediaType == MediaTypeOCIIndex
+}
+
+// Size returns the compressed size of the image: its config and layers, which
+// is what a pull downloads
+func (m *Manifest) Size() int64 {
+	size := m.Config.Size
+	for _, layer := range m.Layers {
+		size += layer.Size
+	}
+	return size
+}
+
+// PlatformManifest returns the manifest of an index for os and architecture
+func (m *Manifest) PlatformManifest(os, architecture string) (Descriptor, bool) {
+	for _, manifest := range m.Manifests {
+		if manifest.Platform != nil && manifest.Platform.OS == os && manifest.Platform.Architecture == architecture {
+			return manifest, true
+		}
+	}
+	return Descriptor{}, false
+}
+
+// ParseManifest decodes a manifest or index. OCI manifests may leave out
+// their media type, so contentType, the Content-Type of the response, is
+// used when the document has none.
+func ParseManifest(data []byte, contentType string) (*Manifest, error) {
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if manifest.SchemaVersion != 2 {
+		return nil, fmt.Errorf("unsupported manifest schema version %d", manifest.SchemaVersion)
+	}
+
+	if manifest.MediaType == "" {
+		manifest.MediaType, _, _ = strings.Cut(contentType, ";")
+		manifest.MediaType = strings.TrimSpace(manifest.MediaType)
+	}
+	if manifest.MediaType == "" || manifest.MediaType == "application/json" {
+		manifest.MediaType = MediaTypeOCIManifest
+		if len(manifest.Manifests) > 0 {
+			manifest.MediaType = MediaTypeOCIIndex
+		}
+	}
+
+	switch manifest.MediaType {
+	case MediaTypeDockerManifest, MediaTypeOCIManifest:
+		if manifest.Config.Digest == "" {
+			return nil, fmt.Errorf("invalid manifest: no config")
+		}
+	case MediaTypeDockerManifestList, MediaTypeOCIIndex:
+		if len(manifest.Manifests) == 0 {
+			return nil, fmt.Errorf("invalid manifest: empty index")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q", manifest.MediaType)
+	}
+	return &manifest, nil
+}
+
+// Reference is an image reference split into where its manifest is fetched
+// from, e.g. nginx:1.25 is tag 1.25 of library/nginx on Docker Hub
+type Reference struct {
+	Registry   string
+	Repository string
+	// Reference is the digest of the image when it has one, else its tag
+	Reference string
+}
+
+// ParseReference parses an image reference the way the container runtime
+// does: images without a registry host are on Docker Hub, official images
+// there are in library/, and the tag defaults to latest
+func ParseReference(image string) (Reference, error) {
+	name, digest, hasDigest := strings.Cut(image, "@")
+	if name == "" || (hasDigest && digest == "") {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	ref := Reference{Registry: defaultRegistry}
+	if host, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry = host
+		name = rest
+	}
+	if host := strings.ToLower(ref.Registry); host == "docker.io" || host == "index.docker.io" {
+		ref.Registry = defaultRegistry
+	}
+
+	tag := "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	if name == "" || tag == "" || name != strings.ToLower(name) {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+
+	ref.Reference = tag
+	if hasDigest {
+		ref.Reference = digest
+	}
+	return ref, nil
+}
+
+// Client fetches manifests, with anonymous bearer tokens for registries that
+// ask for one, as Docker Hub does for public images
+type Client struct {
+	httpClient *http.Client
+	// os and architecture pick the manifest of a multi-platform image
+	os           string
+	architecture string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient replaces the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithPlatform sets the platform whose manifest is read from a multi-platform
+// image, linux/amd64 by default
+func WithPlatform(os, architecture string) Option {
+	return func(c *Client) {
+		c.os = os
+		c.architecture = architecture
+	}
+}
+
+// NewClient creates a registry client
+func NewClient(opts ...Option) *Client {
+	c := &Client{httpClient: http.DefaultClient, os: "linux", architecture: "amd64"}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// DefaultClient is the client GetManifest uses. It reads linux/amd64
+// manifests unless replaced, e.g. by tests.
+var DefaultClient = NewClient()
+
+// GetManifest fetches the manifest of an image with DefaultClient, within a
+// 10s timeout
+func GetManifest(image string) (*Manifest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	return DefaultClient.GetManifest(ctx, image)
+}
+
+// GetManifest fetches the manifest of an image. For a multi-platform image it
+// returns the manifest of the client's platform.
+func (c *Client) GetManifest(ctx context.Context, image string) (*Manifest, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	var token string
+	manifest, err := c.fetchManifest(ctx, ref, ref.Reference, &token)
+	if err != nil {
+		return nil, err
+	}
+	if !manifest.IsIndex() {
+		return manifest, nil
+	}
+
+	platform, ok := manifest.PlatformManifest(c.os, c.architecture)
+	if !ok {
+		return nil, fmt.Errorf("%s has no %s/%s image", image, c.os, c.architecture)
+	}
+	manifest, err = c.fetchManifest(ctx, ref, platform.Digest, &token)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.IsIndex() {
+		return nil, fmt.Errorf("%s: nested index for %s/%s", image, c.os, c.architecture)
+	}
+	return manifest, nil
+}
+
+// fetchManifest gets a manifest by tag or digest. A 401 with a Bearer
+// challenge is answered with an anonymous token, kept in *token for the next
+// requests.
+func (c *Client) fetchManifest(ctx context.Context, ref Reference, reference string, token *string) (*Manifest, error) {
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Registry, ref.Repository, reference)
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join([]string{
+			MediaTypeOCIIndex, MediaTypeDockerManifestList, MediaTypeOCIManifest, MediaTypeDockerManifest,
+		}, ", "))
+		if *token != "" {
+			req.Header.Set("Authorization", "Bearer "+*token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the manifest of %s: %v", ref.Repository, err)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			if *token, err = c.fetchToken(ctx, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("registry %s answered %s for %s:%s", ref.Registry, resp.Status, ref.Repository, reference)
+		}
+		return ParseManifest(body, resp.Header.Get("Content-Type"))
+	}
+}
+
+// fetchToken gets an anonymous token for a Bearer challenge, e.g.
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"
+func (c *Client) fetchToken(ctx context.Context, challenge string) (string, error) {
+	params, ok := parseBearerChallenge(challenge)
+	if !ok || params["realm"] == "" {
+		return "", fmt.Errorf("registry requires authentication")
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %v", params["realm"], err)
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response: %v", err)
+	}
+	if body.Token == "" {
+		body.Token = body.AccessToken
+	}
+	if body.Token == "" {
+		return "", fmt.Errorf("token response has no token")
+	}
+	return body.Token, nil
+}
+
+// parseBearerChallenge parses the parameters of a WWW-Authenticate Bearer
+// challenge
+func parseBearerChallenge(challenge string) (map[string]string, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				return nil, false
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return params, true
+}
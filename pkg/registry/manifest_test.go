@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestParseManifest(t *testing.T) {
+	manifest, err := ParseManifest(readFixture(t, "docker-manifest.json"), MediaTypeDockerManifest)
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.IsIndex() || len(manifest.Layers) != 3 {
+		t.Errorf("Expected a manifest with 3 layers, got %+v", manifest)
+	}
+	if size := manifest.Size(); size != 7023+32654+16724+73109 {
+		t.Errorf("Expected the config and layers to add up, got %d", size)
+	}
+
+	// OCI manifests may leave the media type to the Content-Type header
+	manifest, err = ParseManifest(readFixture(t, "oci-manifest.json"), MediaTypeOCIManifest+"; charset=utf-8")
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if manifest.MediaType != MediaTypeOCIManifest || len(manifest.Layers) != 1 || manifest.Size() != 1472+3623807 {
+		t.Errorf("Unexpected OCI manifest %+v", manifest)
+	}
+
+	index, err := ParseManifest(readFixture(t, "oci-index.json"), "")
+	if err != nil {
+		t.Fatalf("ParseManifest failed: %v", err)
+	}
+	if !index.IsIndex() {
+		t.Fatalf("Expected an index, got %s", index.MediaType)
+	}
+	platform, ok := index.PlatformManifest("linux", "amd64")
+	if !ok || !strings.HasPrefix(platform.Digest, "sha256:2222") {
+		t.Errorf("Expected the linux/amd64 manifest, got %+v", platform)
+	}
+	if _, ok := index.PlatformManifest("windows", "amd64"); ok {
+		t.Error("Expected no windows/amd64 manifest")
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not JSON", `<html>`, "invalid manifest"},
+		{"schema 1", `{"schemaVersion": 1, "name": "library/nginx"}`, "schema version 1"},
+		{"no config", `{"schemaVersion": 2, "mediaType": "` + MediaTypeDockerManifest + `"}`, "no config"},
+		{"empty index", `{"schemaVersion": 2, "mediaType": "` + MediaTypeOCIIndex + `", "manifests": []}`, "empty index"},
+		{"unknown type", `{"schemaVersion": 2, "mediaType": "text/plain"}`, "unsupported manifest media type"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseManifest([]byte(tt.data), ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error with %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  Reference
+	}{
+		{"nginx", Reference{"registry-1.docker.io", "library/nginx", "latest"}},
+		{"nginx:1.25", Reference{"registry-1.docker.io", "library/nginx", "1.25"}},
+		{"docker.io/bitnami/redis:7.2", Reference{"registry-1.docker.io", "bitnami/redis", "7.2"}},
+		{"ghcr.io/org/app:v1", Reference{"ghcr.io", "org/app", "v1"}},
+		{"localhost:5000/app", Reference{"localhost:5000", "app", "latest"}},
+		{"registry.k8s.io/pause:3.9@sha256:abcd", Reference{"registry.k8s.io", "pause", "sha256:abcd"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseReference(tt.image)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseReference(%q) = %+v, %v; want %+v", tt.image, got, err, tt.want)
+		}
+	}
+
+	for _, image := range []string{"", "nginx:", "Nginx", "nginx@"} {
+		if _, err := ParseReference(image); err == nil {
+			t.Errorf("ParseReference(%q): expected an error", image)
+		}
+	}
+}
+
+func TestClientGetManifest(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:team/app:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"token": "anonymous"}`))
+		case r.Header.Get("Authorization") != "Bearer anonymous":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test",scope="repository:team/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/team/app/manifests/v1":
+			w.Header().Set("Content-Type", MediaTypeOCIIndex)
+			w.Write(readFixture(t, "oci-index.json"))
+		case r.URL.Path == "/v2/team/app/manifests/sha256:2222222222222222222222222222222222222222222222222222222222222222":
+			w.Header().Set("Content-Type", MediaTypeOCIManifest)
+			w.Write(readFixture(t, "oci-manifest.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	client := NewClient(WithHTTPClient(server.Client()))
+	manifest, err := client.GetManifest(context.Background(), host+"/team/app:v1")
+	if err != nil {
+		t.Fatalf("GetManifest failed: %v", err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Size() != 1472+3623807 {
+		t.Errorf("Expected the linux/amd64 manifest, got %+v", manifest)
+	}
+
+	if _, err := NewClient(WithHTTPClient(server.Client()), WithPlatform("linux", "s390x")).GetManifest(context.Background(), host+"/team/app:v1"); err == nil || !strings.Contains(err.Error(), "no linux/s390x image") {
+		t.Errorf("Expected a missing platform error, got %v", err)
+	}
+	if _, err := client.GetManifest(context.Background(), host+"/team/app:v2"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error, got %v", err)
+	}
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	params, ok := parseBearerChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+	if !ok || params["realm"] != "https://auth.docker.io/token" || params["service"] != "registry.docker.io" || params["scope"] != "repository:library/nginx:pull" {
+		t.Errorf("Unexpected params %v", params)
+	}
+	if _, ok := parseBearerChallenge(`Basic realm="registry"`); ok {
+		t.Error("Expected Basic challenges to be rejected")
+	}
+}
@@ -0,0 +1,181 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ChangeType says how an object differs between two snapshots
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// Change is an object added, removed or changed between two snapshots
+type Change struct {
+	Type      ChangeType `json:"type"`
+	Kind      string     `json:"kind"`
+	Namespace string     `json:"namespace"`
+	Name      string     `json:"name"`
+	// Fields are the top-level fields of a changed object that differ, e.g.
+	// "spec" or "status", with metadata ones as "metadata.labels"
+	Fields []string `json:"fields,omitempty"`
+}
+
+// String formats a change as "+ Pod default/web", "- Pod default/web" or
+// "~ Pod default/web (spec, status)"
+func (c Change) String() string {
+	object := fmt.Sprintf("%s %s/%s", c.Kind, c.Namespace, c.Name)
+	switch c.Type {
+	case Added:
+		return "+ " + object
+	case Removed:
+		return "- " + object
+	}
+	return fmt.Sprintf("~ %s (%s)", object, strings.Join(c.Fields, ", "))
+}
+
+// ignoredMetadata are the metadata fields the cluster changes on every write,
+// which are not compared
+var ignoredMetadata = []string{"resourceVersion", "managedFields", "generation"}
+
+// Diff returns the objects added, removed or changed from snapshot a to
+// snapshot b, ordered by kind, namespace and name
+func Diff(a, b *Snapshot) ([]Change, error) {
+	var changes []Change
+	for _, kind := range []struct {
+		name   string
+		before []metav1.Object
+		after  []metav1.Object
+	}{
+		{"Pod", objects(a.Pods), objects(b.Pods)},
+		{"Deployment", objects(a.Deployments), objects(b.Deployments)},
+		{"Service", objects(a.Services), objects(b.Services)},
+		{"ConfigMap", objects(a.ConfigMaps), objects(b.ConfigMaps)},
+	} {
+		kindChanges, err := diffObjects(kind.name, kind.before, kind.after)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, kindChanges...)
+	}
+	return changes, nil
+}
+
+// objects returns pointers to the items of a slice of objects as metav1.Object
+func objects[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T) []metav1.Object {
+	result := make([]metav1.Object, len(items))
+	for i := range items {
+		result[i] = PT(&items[i])
+	}
+	return result
+}
+
+// diffObjects compares the objects of one kind by namespace and name
+func diffObjects(kind string, before, after []metav1.Object) ([]Change, error) {
+	key := func(obj metav1.Object) string { return obj.GetNamespace() + "/" + obj.GetName() }
+
+	previous := make(map[string]metav1.Object, len(before))
+	for _, obj := range before {
+		previous[key(obj)] = obj
+	}
+
+	var changes []Change
+	for _, obj := range after {
+		old, ok := previous[key(obj)]
+		if !ok {
+			changes = append(changes, Change{Type: Added, Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()})
+			continue
+		}
+		delete(previous, key(obj))
+
+		fields, err := changedFields(old, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s %s: %v", kind, key(obj), err)
+		}
+		if len(fields) > 0 {
+			changes = append(changes, Change{Type: Changed, Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), Fields: fields})
+		}
+	}
+	for _, obj := range previous {
+		changes = append(changes, Change{Type: Removed, Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Namespace != changes[j].Namespace {
+			return changes[i].Namespace < changes[j].Namespace
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+// changedFields returns the top-level fields, and the metadata fields, that
+// differ between two versions of an object
+func changedFields(before, after metav1.Object) ([]string, error) {
+	old, err := fieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	current, err := fieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	oldMetadata, _ := old["metadata"].(map[string]interface{})
+	currentMetadata, _ := current["metadata"].(map[string]interface{})
+	for _, field := range ignoredMetadata {
+		delete(oldMetadata, field)
+		delete(currentMetadata, field)
+	}
+
+	var fields []string
+	for _, field := range differentKeys(oldMetadata, currentMetadata) {
+		fields = append(fields, "metadata."+field)
+	}
+	delete(old, "metadata")
+	delete(current, "metadata")
+	return append(fields, differentKeys(old, current)...), nil
+}
+
+// fieldMap returns an object as a map of its JSON fields
+func fieldMap(obj metav1.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// differentKeys returns the sorted keys whose values differ between two maps,
+// including keys only one of them has
+func differentKeys(a, b map[string]interface{}) []string {
+	var keys []string
+	for key, value := range a {
+		if !reflect.DeepEqual(value, b[key]) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiff(t *testing.T) {
+	before := testSnapshot()
+	after := testSnapshot()
+
+	// Changed: the pod's status and labels; only the resource version of the
+	// service, which is not a change
+	after.Pods[0].Status.Phase = v1.PodFailed
+	after.Pods[0].Labels["version"] = "2"
+	after.Services[0].ResourceVersion = "42"
+	// Added and removed configmaps
+	after.ConfigMaps = append(after.ConfigMaps, v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "feature-flags", Namespace: "shop"}})
+	after.ConfigMaps[0].Name = "web-config-v2"
+	// Removed deployment
+	after.Deployments = nil
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	want := []string{
+		"~ Pod shop/web-1 (metadata.labels, status)",
+		"- Deployment shop/web",
+		"+ ConfigMap shop/feature-flags",
+		"- ConfigMap shop/web-config",
+		"+ ConfigMap shop/web-config-v2",
+	}
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected changes:\n%v\ngot:\n%v", want, got)
+	}
+
+	if changes[0].Type != Changed || !reflect.DeepEqual(changes[0].Fields, []string{"metadata.labels", "status"}) {
+		t.Errorf("Expected the pod's labels and status to have changed, got %+v", changes[0])
+	}
+
+	same, err := Diff(before, testSnapshot())
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(same) != 0 {
+		t.Errorf("Expected no changes between equal snapshots, got %v", same)
+	}
+}
+
+func TestDiffAcrossNamespaces(t *testing.T) {
+	before := New("", []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "a"}}}, nil, nil, nil)
+	after := New("", []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "b"}}}, nil, nil, nil)
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if len(changes) != 2 || changes[0].String() != "- Pod a/web" || changes[1].String() != "+ Pod b/web" {
+		t.Errorf("Expected same-named pods in other namespaces to be distinct, got %v", changes)
+	}
+}
@@ -0,0 +1,165 @@
+// Package snapshot captures the pods, deployments, services and configmaps of
+// a namespace, or of every namespace, in a single JSON or YAML bundle for
+// offline analysis, and compares two such bundles.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// Version identifies the bundle format
+const Version = "kgo.snapshot/v1"
+
+// Formats a snapshot is written in
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Snapshot is a bundle of the objects of a namespace at one point in time
+type Snapshot struct {
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Namespace is the namespace the objects were taken from, or empty for
+	// every namespace
+	Namespace string   `json:"namespace,omitempty"`
+	Manifest  Manifest `json:"manifest"`
+
+	Pods        []v1.Pod            `json:"pods"`
+	Deployments []appsv1.Deployment `json:"deployments"`
+	Services    []v1.Service        `json:"services"`
+	ConfigMaps  []v1.ConfigMap      `json:"configMaps"`
+}
+
+// Manifest counts the objects of each type in a snapshot
+type Manifest struct {
+	Pods        int `json:"pods"`
+	Deployments int `json:"deployments"`
+	Services    int `json:"services"`
+	ConfigMaps  int `json:"configMaps"`
+}
+
+// New bundles objects into a snapshot taken now
+func New(namespace string, pods []v1.Pod, deployments []appsv1.Deployment, services []v1.Service, configMaps []v1.ConfigMap) *Snapshot {
+	return &Snapshot{
+		Version:   Version,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Namespace: namespace,
+		Manifest: Manifest{
+			Pods:        len(pods),
+			Deployments: len(deployments),
+			Services:    len(services),
+			ConfigMaps:  len(configMaps),
+		},
+		Pods:        pods,
+		Deployments: deployments,
+		Services:    services,
+		ConfigMaps:  configMaps,
+	}
+}
+
+// Collect lists the objects of a namespace, or of every namespace when it is
+// empty, into a snapshot
+func Collect(clientset kubernetes.Interface, namespace string) (*Snapshot, error) {
+	pods, err := k8s.ListPods(clientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+	deployments, err := k8s.ListDeployments(clientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %v", err)
+	}
+	services, err := k8s.ListServices(clientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+	configMaps, err := k8s.ListConfigMaps(clientset, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configmaps: %v", err)
+	}
+	return New(namespace, pods, deployments, services, configMaps), nil
+}
+
+// FormatForPath returns the format of a snapshot file from its extension:
+// YAML for .yaml and .yml, JSON otherwise
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return FormatJSON
+}
+
+// Marshal encodes a snapshot in a format
+func Marshal(s *Snapshot, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatYAML:
+		return yaml.Marshal(s)
+	}
+	return nil, fmt.Errorf("unknown snapshot format %q, expected %q or %q", format, FormatJSON, FormatYAML)
+}
+
+// Unmarshal decodes a snapshot in either format, and checks its version and
+// that its manifest matches its objects
+func Unmarshal(data []byte) (*Snapshot, error) {
+	var s Snapshot
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %v", err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %q, expected %q", s.Version, Version)
+	}
+
+	found := Manifest{
+		Pods:        len(s.Pods),
+		Deployments: len(s.Deployments),
+		Services:    len(s.Services),
+		ConfigMaps:  len(s.ConfigMaps),
+	}
+	if found != s.Manifest {
+		return nil, fmt.Errorf("snapshot manifest %+v does not match its objects %+v", s.Manifest, found)
+	}
+	return &s, nil
+}
+
+// WriteFile writes a snapshot to path, in the format its extension names.
+// Configmap values and pod environments may be sensitive, so only the owner
+// can read the file.
+func WriteFile(path string, s *Snapshot) error {
+	data, err := Marshal(s, FormatForPath(path))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// ReadFile reads a snapshot written by WriteFile
+func ReadFile(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testSnapshot returns a snapshot with one object of each type. Times are
+// whole seconds, which is all Kubernetes times keep when serialized.
+func testSnapshot() *Snapshot {
+	created := metav1.NewTime(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "shop", CreationTimestamp: created, Labels: map[string]string{"app": name}}
+	}
+	replicas := int32(2)
+	return New("shop",
+		[]v1.Pod{{
+			ObjectMeta: meta("web-1"),
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx:1.25"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		}},
+		[]appsv1.Deployment{{
+			ObjectMeta: meta("web"),
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}},
+		[]v1.Service{{
+			ObjectMeta: meta("web"),
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, Ports: []v1.ServicePort{{Port: 80}}},
+		}},
+		[]v1.ConfigMap{{
+			ObjectMeta: meta("web-config"),
+			Data:       map[string]string{"nginx.conf": "worker_processes 1;\n"},
+			BinaryData: map[string][]byte{"logo.png": {0x89, 'P', 'N', 'G'}},
+		}},
+	)
+}
+
+func TestNew(t *testing.T) {
+	s := testSnapshot()
+	if s.Version != Version || s.Namespace != "shop" {
+		t.Errorf("Expected a %s snapshot of shop, got %s of %q", Version, s.Version, s.Namespace)
+	}
+	if want := (Manifest{Pods: 1, Deployments: 1, Services: 1, ConfigMaps: 1}); s.Manifest != want {
+		t.Errorf("Expected manifest %+v, got %+v", want, s.Manifest)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, name := range []string{"snapshot.json", "snapshot.yaml", "snapshot.yml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			original := testSnapshot()
+			if err := WriteFile(path, original); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", path, err)
+			}
+			isJSON := strings.HasPrefix(string(data), "{")
+			if isJSON != (FormatForPath(path) == FormatJSON) {
+				t.Errorf("Expected %s to be written as %s, got:\n%s", name, FormatForPath(path), data)
+			}
+
+			read, err := ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile failed: %v", err)
+			}
+			// Decoding sets Kubernetes times to local time, so the snapshots
+			// are compared in their canonical JSON form
+			want, err := Marshal(original, FormatJSON)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			got, err := Marshal(read, FormatJSON)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Expected the snapshot to survive a round trip\nwrote:\n%s\nread:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRejectsInvalidSnapshots(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(s *Snapshot)
+		want   string
+	}{
+		{"unknown version", func(s *Snapshot) { s.Version = "kgo.snapshot/v9" }, "unsupported snapshot version"},
+		{"manifest mismatch", func(s *Snapshot) { s.Manifest.Pods = 3 }, "does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSnapshot()
+			tt.modify(s)
+			data, err := Marshal(s, FormatJSON)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if _, err := Unmarshal(data); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := Unmarshal([]byte("pods: [")); err == nil {
+		t.Error("Expected an error for malformed data")
+	}
+	if _, err := Marshal(testSnapshot(), "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestCollect(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "data"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"}, Data: map[string]string{"key": "value"}},
+	)
+
+	s, err := Collect(clientset, "shop")
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if want := (Manifest{Pods: 1, ConfigMaps: 1}); s.Manifest != want {
+		t.Errorf("Expected manifest %+v for shop, got %+v", want, s.Manifest)
+	}
+	if len(s.ConfigMaps) != 1 || s.ConfigMaps[0].Data["key"] != "value" {
+		t.Errorf("Expected configmaps with their values, got %+v", s.ConfigMaps)
+	}
+
+	all, err := Collect(clientset, "")
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if all.Namespace != "" || all.Manifest.Pods != 2 {
+		t.Errorf("Expected the pods of every namespace, got %+v", all.Manifest)
+	}
+}
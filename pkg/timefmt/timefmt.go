@@ -0,0 +1,121 @@
+// Package timefmt formats ages and timestamps the same way in the TUI, the
+// REST API and gRPC: as relative durations like "3d3h" from
+// util.HumanDuration, as absolute times in a configured zone, or as both.
+package timefmt
+
+import (
+	"fmt"
+	"time"
+
+	"k8s-dashboard/pkg/util"
+)
+
+// Timestamp formats
+const (
+	Relative = "relative"
+	Absolute = "absolute"
+	Both     = "both"
+)
+
+// Formats are the timestamp formats in the order the TUI cycles through them
+var Formats = []string{Relative, Absolute, Both}
+
+// shortLayout is the absolute time in table columns, which have no room for
+// seconds and the zone offset
+const shortLayout = "2006-01-02 15:04"
+
+// AgeSeconds returns how many whole seconds before now t was, or 0 when t
+// is zero
+func AgeSeconds(t, now time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return int64(now.Sub(t).Seconds())
+}
+
+// Next returns the format after format in Formats
+func Next(format string) string {
+	for i, f := range Formats {
+		if f == format {
+			return Formats[(i+1)%len(Formats)]
+		}
+	}
+	return Formats[0]
+}
+
+// Valid reports whether format is one of Formats
+func Valid(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadLocation returns the zone of a name such as "UTC" or "Europe/Berlin",
+// with an empty name or "Local" for the local zone
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// Formatter formats timestamps in Format, with absolute times in Location.
+// The zero Formatter formats relative ages.
+type Formatter struct {
+	Format   string
+	Location *time.Location
+}
+
+// New returns a Formatter for a format and a zone name as LoadLocation
+// accepts
+func New(format, timezone string) (Formatter, error) {
+	if !Valid(format) {
+		return Formatter{}, fmt.Errorf("unknown timestamp format %q, expected one of %v", format, Formats)
+	}
+	location, err := LoadLocation(timezone)
+	if err != nil {
+		return Formatter{}, fmt.Errorf("unknown time zone %q: %v", timezone, err)
+	}
+	return Formatter{Format: format, Location: location}, nil
+}
+
+// Timestamp formats t at now for details: its age, RFC3339 in the zone, or
+// both as "2024-05-01T10:32:00+02:00 (3d3h)". Zero times are "-".
+func (f Formatter) Timestamp(t, now time.Time) string {
+	return f.format(t, now, time.RFC3339)
+}
+
+// Short formats t at now for a table column: its age, the minute in the
+// zone, or both as "2024-05-01 10:32 (3d3h)". Zero times are "-".
+func (f Formatter) Short(t, now time.Time) string {
+	return f.format(t, now, shortLayout)
+}
+
+// Header returns the title of a table column of Short timestamps
+func (f Formatter) Header() string {
+	if f.Format == Absolute {
+		return "Created"
+	}
+	return "Age"
+}
+
+func (f Formatter) format(t, now time.Time, layout string) string {
+	if t.IsZero() {
+		return "-"
+	}
+	location := f.Location
+	if location == nil {
+		location = time.Local
+	}
+	absolute := t.In(location).Format(layout)
+	switch f.Format {
+	case Absolute:
+		return absolute
+	case Both:
+		return fmt.Sprintf("%s (%s)", absolute, util.HumanDuration(now.Sub(t)))
+	}
+	return util.HumanDuration(now.Sub(t))
+}
@@ -0,0 +1,87 @@
+package timefmt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatter(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("No time zone database: %v", err)
+	}
+	created := time.Date(2024, 5, 1, 8, 32, 0, 0, time.UTC)
+	now := created.Add(75 * time.Hour)
+
+	tests := []struct {
+		format    string
+		timestamp string
+		short     string
+		header    string
+	}{
+		{Relative, "3d3h", "3d3h", "Age"},
+		{Absolute, "2024-05-01T10:32:00+02:00", "2024-05-01 10:32", "Created"},
+		{Both, "2024-05-01T10:32:00+02:00 (3d3h)", "2024-05-01 10:32 (3d3h)", "Age"},
+	}
+	for _, tt := range tests {
+		f := Formatter{Format: tt.format, Location: berlin}
+		if got := f.Timestamp(created, now); got != tt.timestamp {
+			t.Errorf("%s Timestamp = %q, want %q", tt.format, got, tt.timestamp)
+		}
+		if got := f.Short(created, now); got != tt.short {
+			t.Errorf("%s Short = %q, want %q", tt.format, got, tt.short)
+		}
+		if got := f.Header(); got != tt.header {
+			t.Errorf("%s Header = %q, want %q", tt.format, got, tt.header)
+		}
+		if got := f.Short(time.Time{}, now); got != "-" {
+			t.Errorf("%s Short of a zero time = %q, want -", tt.format, got)
+		}
+	}
+
+	if got := (Formatter{}).Timestamp(created, now); got != "3d3h" {
+		t.Errorf("Expected the zero Formatter to be relative, got %q", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	f, err := New(Absolute, "")
+	if err != nil || f.Location != time.Local {
+		t.Errorf("Expected an empty zone to be local, got %v, %v", f.Location, err)
+	}
+	if f, err := New(Both, "UTC"); err != nil || f.Location.String() != "UTC" {
+		t.Errorf("Expected UTC, got %v, %v", f.Location, err)
+	}
+	if _, err := New("iso", "UTC"); err == nil || !strings.Contains(err.Error(), "unknown timestamp format") {
+		t.Errorf("Expected an unknown format error, got %v", err)
+	}
+	if _, err := New(Relative, "Mars/Olympus_Mons"); err == nil || !strings.Contains(err.Error(), "unknown time zone") {
+		t.Errorf("Expected an unknown zone error, got %v", err)
+	}
+}
+
+func TestNext(t *testing.T) {
+	format := Relative
+	var seen []string
+	for range Formats {
+		format = Next(format)
+		seen = append(seen, format)
+	}
+	if strings.Join(seen, ",") != "absolute,both,relative" {
+		t.Errorf("Expected to cycle through every format, got %v", seen)
+	}
+	if Next("bogus") != Relative {
+		t.Error("Expected an unknown format to start over")
+	}
+}
+
+func TestAgeSeconds(t *testing.T) {
+	now := time.Now()
+	if got := AgeSeconds(now.Add(-90*time.Second), now); got != 90 {
+		t.Errorf("Expected 90, got %d", got)
+	}
+	if got := AgeSeconds(time.Time{}, now); got != 0 {
+		t.Errorf("Expected 0 for a zero time, got %d", got)
+	}
+}
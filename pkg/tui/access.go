@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// lockGlyph marks the tabs of resource types the user may not list, and
+// deletion-protected namespaces
+const lockGlyph = "🔒"
+
+// recordAccess notes whether the user may list the resource type of a
+// finished load: a Forbidden error takes its tab out of the Tab cycle, and a
+// later successful load puts it back. Other errors leave it as it was.
+// Callers hold freshnessMu.
+func (t *TUI) recordAccess(update *DataUpdate) {
+	switch {
+	case update.Error == nil:
+		delete(t.noAccess, update.ResourceType)
+	case apierrors.IsForbidden(update.Error):
+		if t.noAccess == nil {
+			t.noAccess = make(map[ResourceType]bool)
+		}
+		t.noAccess[update.ResourceType] = true
+	}
+}
+
+// hasAccess reports whether the last load of a resource type was allowed
+func (t *TUI) hasAccess(rt ResourceType) bool {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	return !t.noAccess[rt]
+}
+
+// nextAccessibleView returns the first resource type in the direction of
+// delta from rt that the user may list, like adjacentView but skipping the
+// tabs marked no access. When every other tab is, it returns rt.
+func (t *TUI) nextAccessibleView(rt ResourceType, delta int) ResourceType {
+	step := 1
+	if delta < 0 {
+		step = -1
+	}
+	next := rt
+	for range loadingResourceTypes {
+		next = adjacentView(next, step)
+		if next == rt || t.hasAccess(next) {
+			return next
+		}
+	}
+	return rt
+}
+
+// headerTab returns the text and style of the header tab of a resource type.
+// A tab the user may not list is dimmed and marked with lockGlyph,
+// followed by a space the wide glyph draws over.
+func (t *TUI) headerTab(rt ResourceType, label string) (string, tcell.Style) {
+	tab := " " + label + " "
+	style := tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground)
+	if !t.hasAccess(rt) {
+		tab = " " + lockGlyph + " " + label + " "
+		style = style.Foreground(tcell.ColorGray).Dim(true)
+	}
+	if rt == t.currentView {
+		style = tcell.StyleDefault.Background(t.theme.selected).Foreground(tcell.ColorBlack).Bold(true)
+		tab = "▶" + tab[1:] + "◀"
+	}
+	return tab, style
+}
+
+// noAccessMessage describes a resource type the user may not list, shown in
+// place of its table
+func (t *TUI) noAccessMessage(rt ResourceType) string {
+	message := lockGlyph + " No access: you are not allowed to list " + rt.DisplayName()
+	if rt == ResourceNamespaces || rt == ResourceNodes || rt == ResourceCRDs {
+		return message
+	}
+	return message + " in namespace " + t.namespace
+}
+
+// promptNamespace asks for the namespace to switch to when namespaces cannot
+// be listed, reporting false when cancelled. The name must be a DNS label.
+func (t *TUI) promptNamespace() (string, bool) {
+	input := ""
+	errMsg := ""
+	for {
+		t.draw()
+		width, height := t.screen.Size()
+		prompt := "Namespaces cannot be listed. Namespace (Enter switches, Esc cancels): " + input + "_"
+		if errMsg != "" {
+			prompt += "  " + errMsg
+		}
+		if len(prompt) < width {
+			prompt += strings.Repeat(" ", width-len(prompt))
+		}
+		t.drawText(0, height-1, width, prompt, tcell.StyleDefault.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyEnter:
+			if errs := validation.IsDNS1123Label(input); len(errs) > 0 {
+				errMsg = "invalid namespace name"
+				continue
+			}
+			return input, true
+		case tcell.KeyEscape:
+			return "", false
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+			errMsg = ""
+		case tcell.KeyRune:
+			if len(input) < validation.DNS1123LabelMaxLength {
+				input += string(ev.Rune())
+			}
+			errMsg = ""
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestTUINoAccess tests that the tabs of resource types the user may not
+// list are marked, skipped by Tab, and that the namespace is typed when
+// namespaces cannot be listed
+func TestTUINoAccess(t *testing.T) {
+	tui, screen := newTestTUI(t, 160, 30,
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+	)
+	clientset := tui.clientset.(*fake.Clientset)
+	forbidden := map[string]bool{"services": true, "namespaces": true}
+	for resource := range forbidden {
+		clientset.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if !forbidden[resource] {
+				return false, nil, nil
+			}
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", errors.New("RBAC: access denied"))
+		})
+	}
+	tui.currentView = ResourcePods
+	load := func() {
+		tui.refreshData()
+		for tui.loading {
+			tui.handleDataUpdate(<-tui.dataChan)
+		}
+	}
+	load()
+
+	for _, rt := range loadingResourceTypes {
+		want := rt != ResourceServices && rt != ResourceNamespaces
+		if got := tui.hasAccess(rt); got != want {
+			t.Errorf("Expected access to %s to be %v, got %v", rt.DisplayName(), want, got)
+		}
+	}
+
+	// The header dims and locks the forbidden tabs
+	tui.drawHeader(160)
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	var header strings.Builder
+	locked := 0
+	for x := 0; x < width; x++ {
+		cell := cells[3*width+x]
+		header.WriteString(string(cell.Runes))
+		if string(cell.Runes) == lockGlyph {
+			locked++
+			if _, _, attrs := cell.Style.Decompose(); attrs&tcell.AttrDim == 0 {
+				t.Errorf("Expected the locked tab at %d to be dimmed", x)
+			}
+		}
+	}
+	if locked != 2 || !strings.Contains(header.String(), lockGlyph+" 3.Services") || !strings.Contains(header.String(), " 4.ConfigMaps") {
+		t.Errorf("Expected Services and Namespaces locked, got %q", header.String())
+	}
+
+	// Tab skips them both ways
+	tui.currentView = ResourceDeployments
+	tui.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if tui.currentView != ResourceConfigMaps {
+		t.Errorf("Expected Tab to skip Services, got %s", tui.currentView.DisplayName())
+	}
+	tui.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if tui.currentView != ResourceNodes {
+		t.Errorf("Expected Tab to skip Namespaces, got %s", tui.currentView.DisplayName())
+	}
+	if got := tui.nextAccessibleView(ResourceConfigMaps, -1); got != ResourceDeployments {
+		t.Errorf("Expected the previous tab of ConfigMaps to be Deployments, got %s", got.DisplayName())
+	}
+	// The number keys still open them, telling why they are empty
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone))
+	if want := "🔒 No access: you are not allowed to list Services in namespace default"; tui.currentView != ResourceServices || tui.noAccessMessage(ResourceServices) != want {
+		t.Errorf("Expected %q on the Services tab, got %q", want, tui.noAccessMessage(ResourceServices))
+	}
+
+	// Without access to namespaces, n asks for one, which must be a DNS label
+	go func() {
+		for _, r := range "Shop" {
+			screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		for range "Shop" {
+			screen.InjectKey(tcell.KeyBackspace, 0, tcell.ModNone)
+		}
+		for _, r := range "shop" {
+			screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.changeNamespace()
+	if tui.namespace != "shop" || !tui.loading {
+		t.Fatalf("Expected shop to be loading, got %s, loading %v", tui.namespace, tui.loading)
+	}
+	for tui.loading {
+		tui.handleDataUpdate(<-tui.dataChan)
+	}
+
+	// Access granted later puts the tabs back
+	forbidden["services"] = false
+	load()
+	if !tui.hasAccess(ResourceServices) || tui.hasAccess(ResourceNamespaces) {
+		t.Errorf("Expected only Namespaces to stay locked, got services %v", tui.hasAccess(ResourceServices))
+	}
+}
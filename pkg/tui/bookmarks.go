@@ -0,0 +1,396 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	"golang.org/x/sync/errgroup"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// bookmarkConcurrency bounds the Get calls of the bookmarks view in flight
+const bookmarkConcurrency = 4
+
+// bookmarkTimeout bounds the Get call of each bookmark
+const bookmarkTimeout = 10 * time.Second
+
+// Bookmark is a resource kept in the bookmarks view whatever the current
+// namespace, saved with the session
+type Bookmark struct {
+	// Kind is pod, deployment, service or configmap
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// bookmarkKinds maps the kinds that can be bookmarked to their tab
+var bookmarkKinds = map[string]ResourceType{
+	"pod":        ResourcePods,
+	"deployment": ResourceDeployments,
+	"service":    ResourceServices,
+	"configmap":  ResourceConfigMaps,
+}
+
+// bookmarkStatus is what a bookmark's Get call found: a short status, or
+// that the object no longer exists, or the error of the call
+type bookmarkStatus struct {
+	status  string
+	missing bool
+	err     error
+}
+
+// bookmarksView is the state of the bookmarks view. The statuses are written
+// by the goroutine fetching them and read when drawing, so guarded by mu.
+type bookmarksView struct {
+	mu       sync.Mutex
+	statuses map[Bookmark]bookmarkStatus
+	loadedAt time.Time
+	selected int
+	// cancel stops the fetch in progress; nil when there is none
+	cancel context.CancelFunc
+}
+
+// selectedBookmark returns the bookmark of the selected resource of the list
+// or details, if its kind can be bookmarked
+func (t *TUI) selectedBookmark() (Bookmark, bool) {
+	var kind, namespace string
+	switch r := t.getSelectedResource().(type) {
+	case v1.Pod:
+		kind, namespace = "pod", r.Namespace
+	case appsv1.Deployment:
+		kind, namespace = "deployment", r.Namespace
+	case v1.Service:
+		kind, namespace = "service", r.Namespace
+	case k8s.ConfigMapSummary:
+		kind, namespace = "configmap", r.Namespace
+	default:
+		return Bookmark{}, false
+	}
+	if namespace == "" {
+		namespace = t.namespace
+	}
+	return Bookmark{Kind: kind, Namespace: namespace, Name: t.getResourceName(t.getSelectedResource())}, true
+}
+
+// isBookmarked reports whether a resource is bookmarked
+func (t *TUI) isBookmarked(bookmark Bookmark) bool {
+	for _, b := range t.bookmarks {
+		if b == bookmark {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleBookmark bookmarks the selected resource, or removes its bookmark,
+// and saves the session
+func (t *TUI) toggleBookmark() {
+	if t.viewMode != ViewModeList && t.viewMode != ViewModeDetails {
+		return
+	}
+	bookmark, ok := t.selectedBookmark()
+	if !ok {
+		return
+	}
+	if !t.removeBookmark(bookmark) {
+		t.bookmarks = append(t.bookmarks, bookmark)
+		t.saveSession()
+	}
+}
+
+// removeBookmark removes a bookmark and saves the session, reporting whether
+// it was there
+func (t *TUI) removeBookmark(bookmark Bookmark) bool {
+	for i, b := range t.bookmarks {
+		if b == bookmark {
+			t.bookmarks = append(t.bookmarks[:i:i], t.bookmarks[i+1:]...)
+			t.saveSession()
+			return true
+		}
+	}
+	return false
+}
+
+// loadBookmarks reads the bookmarks of the session file, which are kept
+// whether or not the rest of the session is restored
+func (t *TUI) loadBookmarks() {
+	if t.sessionPath == "" {
+		return
+	}
+	session, err := LoadSession(t.sessionPath)
+	if err != nil || session == nil {
+		return
+	}
+	t.bookmarks = session.Bookmarks
+}
+
+// fetchBookmarks runs fetch for every bookmark, at most concurrency at a
+// time, and returns the statuses by bookmark
+func fetchBookmarks(bookmarks []Bookmark, concurrency int, fetch func(Bookmark) bookmarkStatus) map[Bookmark]bookmarkStatus {
+	statuses := make(map[Bookmark]bookmarkStatus, len(bookmarks))
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for _, bookmark := range bookmarks {
+		g.Go(func() error {
+			status := fetch(bookmark)
+			mu.Lock()
+			statuses[bookmark] = status
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return statuses
+}
+
+// getBookmarkStatus gets a bookmarked object and summarizes its status, e.g.
+// "Running, 2/2 ready" for a pod
+func getBookmarkStatus(ctx context.Context, clientset kubernetes.Interface, bookmark Bookmark) bookmarkStatus {
+	if clientset == nil {
+		return bookmarkStatus{err: fmt.Errorf("needs direct cluster access")}
+	}
+	ctx, cancel := context.WithTimeout(ctx, bookmarkTimeout)
+	defer cancel()
+
+	var status string
+	var err error
+	ns, name := bookmark.Namespace, bookmark.Name
+	switch bookmark.Kind {
+	case "pod":
+		var pod *v1.Pod
+		if pod, err = clientset.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			ready := 0
+			for _, container := range pod.Status.ContainerStatuses {
+				if container.Ready {
+					ready++
+				}
+			}
+			status = fmt.Sprintf("%s, %d/%d ready", getPodStatus(*pod), ready, len(pod.Spec.Containers))
+		}
+	case "deployment":
+		var deployment *appsv1.Deployment
+		if deployment, err = clientset.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			replicas := int32(1)
+			if deployment.Spec.Replicas != nil {
+				replicas = *deployment.Spec.Replicas
+			}
+			status = fmt.Sprintf("%d/%d ready", deployment.Status.ReadyReplicas, replicas)
+			if deployment.Spec.Paused {
+				status += ", paused"
+			}
+		}
+	case "service":
+		var service *v1.Service
+		if service, err = clientset.CoreV1().Services(ns).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			status = fmt.Sprintf("%s %s", service.Spec.Type, service.Spec.ClusterIP)
+		}
+	case "configmap":
+		var configMap *v1.ConfigMap
+		if configMap, err = clientset.CoreV1().ConfigMaps(ns).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			status = fmt.Sprintf("%d keys", len(configMap.Data)+len(configMap.BinaryData))
+		}
+	default:
+		err = fmt.Errorf("unsupported kind %q", bookmark.Kind)
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		return bookmarkStatus{missing: true}
+	case err != nil:
+		return bookmarkStatus{err: err}
+	}
+	return bookmarkStatus{status: status}
+}
+
+// openBookmarks shows the bookmarks and fetches their statuses
+func (t *TUI) openBookmarks() {
+	t.closeTopPods()
+	t.closeDashboard()
+	t.viewMode = ViewModeBookmarks
+	t.bookmarksView.mu.Lock()
+	t.bookmarksView.selected = 0
+	t.bookmarksView.mu.Unlock()
+	t.reloadBookmarks()
+}
+
+// reloadBookmarks fetches the statuses of the bookmarks in the background,
+// keeping the previous ones on screen until they are in
+func (t *TUI) reloadBookmarks() {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.bookmarksView.mu.Lock()
+	if t.bookmarksView.cancel != nil {
+		t.bookmarksView.cancel()
+	}
+	t.bookmarksView.cancel = cancel
+	t.bookmarksView.mu.Unlock()
+
+	bookmarks := append([]Bookmark(nil), t.bookmarks...)
+	clientset := t.clientset
+	go func() {
+		statuses := fetchBookmarks(bookmarks, bookmarkConcurrency, func(b Bookmark) bookmarkStatus {
+			return getBookmarkStatus(ctx, clientset, b)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		t.bookmarksView.mu.Lock()
+		t.bookmarksView.statuses = statuses
+		t.bookmarksView.loadedAt = time.Now()
+		t.bookmarksView.mu.Unlock()
+		t.requestRedraw()
+	}()
+}
+
+// closeBookmarks stops fetching statuses and returns to the list
+func (t *TUI) closeBookmarks() {
+	t.bookmarksView.mu.Lock()
+	if t.bookmarksView.cancel != nil {
+		t.bookmarksView.cancel()
+		t.bookmarksView.cancel = nil
+	}
+	t.bookmarksView.mu.Unlock()
+
+	if t.viewMode == ViewModeBookmarks {
+		t.viewMode = ViewModeList
+	}
+}
+
+// handleBookmarksKey handles the keys of the bookmarks view and reports
+// whether the key was used
+func (t *TUI) handleBookmarksKey(ev *tcell.EventKey) bool {
+	t.bookmarksView.mu.Lock()
+	selected := t.bookmarksView.selected
+	t.bookmarksView.mu.Unlock()
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		t.closeBookmarks()
+	case tcell.KeyUp:
+		t.selectBookmark(selected - 1)
+	case tcell.KeyDown:
+		t.selectBookmark(selected + 1)
+	case tcell.KeyEnter:
+		if selected < len(t.bookmarks) {
+			t.jumpToBookmark(t.bookmarks[selected])
+		}
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'b':
+			if selected < len(t.bookmarks) {
+				t.removeBookmark(t.bookmarks[selected])
+				t.selectBookmark(selected)
+			}
+		case 'r':
+			t.reloadBookmarks()
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// selectBookmark selects the bookmark at index, kept within the list
+func (t *TUI) selectBookmark(index int) {
+	t.bookmarksView.mu.Lock()
+	defer t.bookmarksView.mu.Unlock()
+	t.bookmarksView.selected = max(min(index, len(t.bookmarks)-1), 0)
+}
+
+// jumpToBookmark leaves the bookmarks view for the bookmarked resource: its
+// namespace, tab and selection, without a filter that would hide it. In
+// another namespace the resource is selected once it is loaded.
+func (t *TUI) jumpToBookmark(bookmark Bookmark) {
+	view, ok := bookmarkKinds[bookmark.Kind]
+	if !ok {
+		return
+	}
+	t.closeBookmarks()
+	t.filter = ""
+	t.notReadyFilter = false
+
+	if bookmark.Namespace != t.namespace {
+		t.namespace = bookmark.Namespace
+		t.currentView = view
+		t.restoredSelection = bookmark.Name
+		t.refreshData()
+		t.saveSession()
+		return
+	}
+	t.switchView(view)
+	t.restoredSelection = bookmark.Name
+	t.selectRestoredResource()
+}
+
+// bookmarkLines returns the rows of the bookmarks table
+func (t *TUI) bookmarkLines() []string {
+	t.bookmarksView.mu.Lock()
+	defer t.bookmarksView.mu.Unlock()
+
+	rows := make([]string, 0, len(t.bookmarks))
+	for _, bookmark := range t.bookmarks {
+		status := "…"
+		if s, ok := t.bookmarksView.statuses[bookmark]; ok {
+			switch {
+			case s.missing:
+				status = "missing"
+			case s.err != nil:
+				status = "error: " + s.err.Error()
+			default:
+				status = s.status
+			}
+		}
+		rows = append(rows, fmt.Sprintf("%-11s %-20s %-40s %s", bookmark.Kind, bookmark.Namespace, bookmark.Name, status))
+	}
+	return rows
+}
+
+// drawBookmarksView draws the bookmarks with their statuses over the whole
+// screen, missing ones in red
+func (t *TUI) drawBookmarksView(width, height int) {
+	header := " ★ Bookmarks "
+	t.drawText(0, 0, width, header+strings.Repeat(" ", max(width-len([]rune(header)), 0)), tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	t.bookmarksView.mu.Lock()
+	selected, loadedAt := t.bookmarksView.selected, t.bookmarksView.loadedAt
+	t.bookmarksView.mu.Unlock()
+
+	rows := t.bookmarkLines()
+	if len(rows) == 0 {
+		t.drawText(0, 2, width, "No bookmarks yet: b bookmarks the selected pod, deployment, service or configmap", tcell.StyleDefault.Foreground(tcell.ColorGray))
+	} else {
+		t.drawText(0, 2, width, fmt.Sprintf("%-11s %-20s %-40s %s", "Kind", "Namespace", "Name", "Status"), tcell.StyleDefault.Foreground(t.theme.header).Bold(true))
+	}
+	for i, row := range rows {
+		y := 3 + i
+		if y >= height-1 {
+			break
+		}
+		style := tcell.StyleDefault.Foreground(t.theme.foreground)
+		if strings.HasSuffix(row, " missing") {
+			style = style.Foreground(tcell.ColorRed)
+		}
+		if i == selected {
+			style = style.Reverse(true)
+		}
+		t.drawText(0, y, width, row, style)
+	}
+
+	footer := " ESC Back │ ↑↓ Select │ Enter Jump │ b Remove │ r Reload "
+	if !loadedAt.IsZero() {
+		footer += fmt.Sprintf("│ Updated %s ", loadedAt.Format("15:04:05"))
+	}
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
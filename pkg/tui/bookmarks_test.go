@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBookmarksPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	pods := []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "prod"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "prod"}},
+	}
+
+	tui := &TUI{namespace: "prod", currentView: ResourcePods, pods: pods, selected: 1}
+	tui.SetSession(path, false)
+	tui.toggleBookmark()
+	want := Bookmark{Kind: "pod", Namespace: "prod", Name: "web-1"}
+	if len(tui.bookmarks) != 1 || tui.bookmarks[0] != want {
+		t.Fatalf("Expected %+v to be bookmarked, got %+v", want, tui.bookmarks)
+	}
+
+	// The bookmarks are loaded even when the session is not restored
+	restarted := &TUI{namespace: "default"}
+	restarted.SetSession(path, false)
+	restarted.loadBookmarks()
+	if len(restarted.bookmarks) != 1 || restarted.bookmarks[0] != want || restarted.namespace != "default" {
+		t.Errorf("Expected only the bookmark of web-1 to be loaded, got %+v in %s", restarted.bookmarks, restarted.namespace)
+	}
+
+	// Toggling again removes it, from the file too
+	tui.toggleBookmark()
+	restarted.loadBookmarks()
+	if len(tui.bookmarks) != 0 || len(restarted.bookmarks) != 0 {
+		t.Errorf("Expected the bookmark to be removed, got %+v and %+v", tui.bookmarks, restarted.bookmarks)
+	}
+
+	// Namespaces and nodes are not bookmarked
+	tui.currentView = ResourceNodes
+	tui.nodes = []v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}
+	tui.selected = 0
+	tui.toggleBookmark()
+	if len(tui.bookmarks) != 0 {
+		t.Errorf("Expected no bookmark of a node, got %+v", tui.bookmarks)
+	}
+}
+
+func TestFetchBookmarksBoundedConcurrency(t *testing.T) {
+	var bookmarks []Bookmark
+	for i := 0; i < 12; i++ {
+		bookmarks = append(bookmarks, Bookmark{Kind: "pod", Namespace: "default", Name: fmt.Sprintf("pod-%d", i)})
+	}
+
+	const limit = 3
+	var inFlight, maxInFlight int32
+	statuses := fetchBookmarks(bookmarks, limit, func(b Bookmark) bookmarkStatus {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return bookmarkStatus{status: "Running " + b.Name}
+	})
+
+	if maxInFlight > limit || maxInFlight < 2 {
+		t.Errorf("Expected between 2 and %d fetches at once, got %d", limit, maxInFlight)
+	}
+	if len(statuses) != len(bookmarks) {
+		t.Fatalf("Expected %d statuses, got %d", len(bookmarks), len(statuses))
+	}
+	for _, b := range bookmarks {
+		if statuses[b].status != "Running "+b.Name {
+			t.Errorf("Expected the status of %s, got %+v", b.Name, statuses[b])
+		}
+	}
+}
+
+func TestGetBookmarkStatus(t *testing.T) {
+	replicas := int32(3)
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "prod"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "api"}, {Name: "proxy"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{Name: "api", Ready: true}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Paused: true},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "dev"}, Data: map[string]string{"a": "1", "b": "2"}},
+	)
+
+	tests := []struct {
+		bookmark Bookmark
+		want     bookmarkStatus
+	}{
+		{Bookmark{Kind: "pod", Namespace: "prod", Name: "api-1"}, bookmarkStatus{status: "Running, 1/2 ready"}},
+		{Bookmark{Kind: "deployment", Namespace: "prod", Name: "api"}, bookmarkStatus{status: "2/3 ready, paused"}},
+		{Bookmark{Kind: "configmap", Namespace: "dev", Name: "settings"}, bookmarkStatus{status: "2 keys"}},
+		{Bookmark{Kind: "pod", Namespace: "dev", Name: "api-1"}, bookmarkStatus{missing: true}},
+		{Bookmark{Kind: "service", Namespace: "prod", Name: "gone"}, bookmarkStatus{missing: true}},
+	}
+	for _, tt := range tests {
+		if got := getBookmarkStatus(t.Context(), clientset, tt.bookmark); got != tt.want {
+			t.Errorf("%+v: expected %+v, got %+v", tt.bookmark, tt.want, got)
+		}
+	}
+
+	if got := getBookmarkStatus(t.Context(), nil, tests[0].bookmark); got.err == nil {
+		t.Errorf("Expected an error without a clientset, got %+v", got)
+	}
+}
+
+// TestBookmarksView tests that missing bookmarks are shown and removed with b
+func TestBookmarksView(t *testing.T) {
+	present := Bookmark{Kind: "configmap", Namespace: "dev", Name: "settings"}
+	gone := Bookmark{Kind: "pod", Namespace: "prod", Name: "old-pod"}
+	tui, screen := newTestTUI(t, 120, 20, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "dev"}})
+	tui.currentView = ResourcePods
+	tui.bookmarks = []Bookmark{present, gone}
+	tui.bookmarksView.statuses = fetchBookmarks(tui.bookmarks, bookmarkConcurrency, func(b Bookmark) bookmarkStatus {
+		return getBookmarkStatus(t.Context(), tui.clientset, b)
+	})
+	tui.viewMode = ViewModeBookmarks
+	tui.draw()
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	var content strings.Builder
+	for i, cell := range cells {
+		content.WriteString(string(cell.Runes))
+		if (i+1)%width == 0 {
+			content.WriteString("\n")
+		}
+	}
+	for _, want := range []string{"Bookmarks", "settings", "0 keys", "old-pod", "missing"} {
+		if !strings.Contains(content.String(), want) {
+			t.Errorf("Expected %q in the bookmarks view, got:\n%s", want, content.String())
+		}
+	}
+
+	tui.handleKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone))
+	if len(tui.bookmarks) != 1 || tui.bookmarks[0] != present || tui.bookmarksView.selected != 0 {
+		t.Errorf("Expected the missing bookmark to be removed, got %+v selecting %d", tui.bookmarks, tui.bookmarksView.selected)
+	}
+
+	tui.handleKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if tui.viewMode != ViewModeList {
+		t.Errorf("Expected Esc to return to the list, got %v", tui.viewMode)
+	}
+}
+
+func TestJumpToBookmark(t *testing.T) {
+	deployment := func(namespace, name string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	tui, _ := newTestTUI(t, 120, 30,
+		deployment("default", "api"), deployment("default", "web"),
+		deployment("prod", "api"), deployment("prod", "db"), deployment("prod", "web"),
+	)
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeBookmarks
+	tui.filter = "api"
+	tui.deployments = []appsv1.Deployment{*deployment("default", "api"), *deployment("default", "web")}
+
+	// In the current namespace the loaded resource is selected right away,
+	// and the filter that would hide it is cleared
+	tui.jumpToBookmark(Bookmark{Kind: "deployment", Namespace: "default", Name: "web"})
+	if tui.viewMode != ViewModeList || tui.currentView != ResourceDeployments || tui.filter != "" {
+		t.Fatalf("Expected the deployments list unfiltered, got %v, %v, %q", tui.viewMode, tui.currentView, tui.filter)
+	}
+	if name := tui.getResourceName(tui.getSelectedResource()); name != "web" {
+		t.Errorf("Expected web to be selected, got %s", name)
+	}
+	// Switching to the deployments tab reloaded it in the background
+	tui.handleDataUpdate(<-tui.dataChan)
+
+	// In another namespace it is selected once that namespace is loaded
+	tui.viewMode = ViewModeBookmarks
+	tui.jumpToBookmark(Bookmark{Kind: "deployment", Namespace: "prod", Name: "db"})
+	if tui.namespace != "prod" || !tui.loading {
+		t.Fatalf("Expected prod to be loading, got %s, loading %v", tui.namespace, tui.loading)
+	}
+	for tui.loading {
+		tui.handleDataUpdate(<-tui.dataChan)
+	}
+	if name := tui.getResourceName(tui.getSelectedResource()); tui.currentView != ResourceDeployments || name != "db" {
+		t.Errorf("Expected deployment db to be selected, got %v %s", tui.currentView, name)
+	}
+
+	// Unknown kinds stay in the bookmarks view
+	tui.viewMode = ViewModeBookmarks
+	tui.jumpToBookmark(Bookmark{Kind: "widget", Namespace: "prod", Name: "db"})
+	if tui.viewMode != ViewModeBookmarks {
+		t.Errorf("Expected an unknown kind not to jump, got %v", tui.viewMode)
+	}
+}
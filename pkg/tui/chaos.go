@@ -0,0 +1,275 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// chaosTimeout bounds the patches injecting and reverting probe failures
+const chaosTimeout = 10 * time.Second
+
+// defaultChaosDuration is the duration the chaos menu offers
+const defaultChaosDuration = "1m"
+
+// ChaosInjection is a liveness probe failure injected into a container of a
+// deployment from the chaos menu, reverted when its timer fires or when the
+// TUI quits
+type ChaosInjection struct {
+	Namespace  string
+	Deployment string
+	Container  string
+	Until      time.Time
+	// original is the probe the revert restores
+	original *v1.Probe
+	timer    *time.Timer
+}
+
+// chaosKey is the key of the injection into a deployment in t.chaos
+func chaosKey(namespace, deployment string) string {
+	return namespace + "/" + deployment
+}
+
+// activeChaos returns the injection into a deployment, if any
+func (t *TUI) activeChaos(namespace, deployment string) (ChaosInjection, bool) {
+	t.chaosMu.Lock()
+	defer t.chaosMu.Unlock()
+	injection, ok := t.chaos[chaosKey(namespace, deployment)]
+	return injection, ok
+}
+
+// injectProbeFailure fails the liveness probe of a container of a deployment
+// for duration, returning the patch applied
+func (t *TUI) injectProbeFailure(deployment appsv1.Deployment, container string, duration time.Duration) ([]byte, error) {
+	if err := k8s.CheckProbeFailureDuration(duration); err != nil {
+		return nil, err
+	}
+	namespace := deployment.Namespace
+	ctx, cancel := context.WithTimeout(context.Background(), chaosTimeout)
+	original, err := k8s.StartProbeFailure(ctx, t.clientset, namespace, deployment.Name, container)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	key := chaosKey(namespace, deployment.Name)
+	injection := ChaosInjection{
+		Namespace:  namespace,
+		Deployment: deployment.Name,
+		Container:  container,
+		Until:      time.Now().Add(duration),
+		original:   original,
+	}
+	injection.timer = time.AfterFunc(duration, func() {
+		t.chaosMu.Lock()
+		current, ok := t.chaos[key]
+		delete(t.chaos, key)
+		t.chaosMu.Unlock()
+		if ok {
+			t.revertChaos(current)
+			t.requestRedraw()
+		}
+	})
+	t.chaosMu.Lock()
+	if t.chaos == nil {
+		t.chaos = make(map[string]ChaosInjection)
+	}
+	t.chaos[key] = injection
+	t.chaosMu.Unlock()
+
+	patch, _ := k8s.LivenessProbePatch(&deployment, container, original, k8s.FailingProbe(original))
+	return patch, nil
+}
+
+// endChaos stops the timer of the injection into a deployment and reverts it
+// now, reporting whether there was one
+func (t *TUI) endChaos(namespace, deployment string) (bool, error) {
+	t.chaosMu.Lock()
+	injection, ok := t.chaos[chaosKey(namespace, deployment)]
+	if ok && injection.timer.Stop() {
+		delete(t.chaos, chaosKey(namespace, deployment))
+	} else {
+		// The timer fired and is reverting it already
+		ok = false
+	}
+	t.chaosMu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, t.revertChaos(injection)
+}
+
+// endAllChaos reverts every injection, when the TUI quits
+func (t *TUI) endAllChaos() {
+	t.chaosMu.Lock()
+	var keys []string
+	for key := range t.chaos {
+		keys = append(keys, key)
+	}
+	t.chaosMu.Unlock()
+	for _, key := range keys {
+		namespace, deployment, _ := strings.Cut(key, "/")
+		t.endChaos(namespace, deployment)
+	}
+}
+
+// revertChaos restores the liveness probe an injection replaced
+func (t *TUI) revertChaos(injection ChaosInjection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), chaosTimeout)
+	defer cancel()
+	err := k8s.RevertProbeFailure(ctx, t.clientset, injection.Namespace, injection.Deployment, injection.Container, injection.original)
+	if err != nil {
+		klog.Errorf("Failed to revert the probe failure of deployment %s/%s: %v", injection.Namespace, injection.Deployment, err)
+	}
+	return err
+}
+
+// chaosStatus returns the status bar text of the active injections, e.g.
+// "[chaos: nginx-app]", or an empty string without any
+func (t *TUI) chaosStatus() string {
+	t.chaosMu.Lock()
+	names := make([]string, 0, len(t.chaos))
+	for _, injection := range t.chaos {
+		names = append(names, injection.Deployment)
+	}
+	t.chaosMu.Unlock()
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("[chaos: %s]", strings.Join(names, ", "))
+}
+
+// probedContainers returns the containers of a deployment with a liveness
+// probe to fail
+func probedContainers(deployment appsv1.Deployment) []string {
+	var names []string
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.LivenessProbe != nil {
+			names = append(names, container.Name)
+		}
+	}
+	return names
+}
+
+// chaosMenu holds the state of the chaos menu of a deployment
+type chaosMenu struct {
+	deployment appsv1.Deployment
+	containers []string
+	fields     []*wizardField
+	field      int
+	errMsg     string
+}
+
+// newChaosMenu creates the menu of a deployment, offering the first
+// container with a liveness probe
+func newChaosMenu(deployment appsv1.Deployment) *chaosMenu {
+	m := &chaosMenu{deployment: deployment, containers: probedContainers(deployment)}
+	container := ""
+	if len(m.containers) > 0 {
+		container = m.containers[0]
+	}
+	m.fields = []*wizardField{
+		{label: "Container", value: container},
+		{label: "Duration", value: defaultChaosDuration},
+	}
+	return m
+}
+
+// lines renders the menu, or the injection in progress
+func (m *chaosMenu) lines(active *ChaosInjection, now time.Time) []string {
+	lines := []string{fmt.Sprintf("Chaos: deployment '%s'", m.deployment.Name), ""}
+	if active != nil {
+		lines = append(lines,
+			fmt.Sprintf("The liveness probe of container '%s' fails until %s (%s left).", active.Container,
+				active.Until.Format("15:04:05"), active.Until.Sub(now).Truncate(time.Second)),
+			"Its pods are rolled out again, and the kubelet restarts the container on every failure.")
+		if m.errMsg != "" {
+			lines = append(lines, "Error: "+m.errMsg)
+		}
+		return append(lines, "", "r: Revert now | Esc: Close")
+	}
+
+	lines = append(lines, "Simulate a liveness probe failure: the probe is replaced by the command false,",
+		"then the original probe is restored after the duration, or when kgo quits.", "")
+	lines = append(lines, fieldLines(m.fields, m.field)...)
+	if len(m.containers) == 0 {
+		lines = append(lines, "", "No container of this deployment has a liveness probe.")
+	} else {
+		lines = append(lines, "", "Containers with a liveness probe: "+strings.Join(m.containers, ", "))
+	}
+	lines = append(lines, fmt.Sprintf("Durations are e.g. 30s or 5m, at most %v.", k8s.MaxProbeFailureDuration))
+	if m.errMsg != "" {
+		lines = append(lines, "Error: "+m.errMsg)
+	}
+	return append(lines, "", "Tab/↑↓: Field | Enter: Inject | Esc: Cancel")
+}
+
+// chaosMenuDialog runs the chaos menu of the deployment shown in the details
+func (t *TUI) chaosMenuDialog() {
+	deployment, ok := t.getSelectedResource().(appsv1.Deployment)
+	if !ok {
+		return
+	}
+	m := newChaosMenu(deployment)
+	for {
+		var active *ChaosInjection
+		if injection, ok := t.activeChaos(deployment.Namespace, deployment.Name); ok {
+			active = &injection
+		}
+		t.drawLines(m.lines(active, time.Now()))
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		if ev.Key() == tcell.KeyEscape {
+			return
+		}
+		if active != nil {
+			if ev.Key() == tcell.KeyRune && ev.Rune() == 'r' {
+				if _, err := t.endChaos(deployment.Namespace, deployment.Name); err != nil {
+					m.errMsg = err.Error()
+					continue
+				}
+				patch, _ := k8s.LivenessProbePatch(&deployment, active.Container, k8s.FailingProbe(active.original), active.original)
+				t.recordAction(fmt.Sprintf("Reverted the liveness probe of '%s' in deployment '%s'", active.Container, deployment.Name),
+					k8s.KubectlJSONPatch(deployment.Namespace, "deployment", deployment.Name, patch))
+				t.loadDeployments()
+				return
+			}
+			continue
+		}
+
+		if editFields(m.fields, &m.field, ev) || ev.Key() != tcell.KeyEnter {
+			continue
+		}
+		container := strings.TrimSpace(m.fields[0].value)
+		duration, err := time.ParseDuration(strings.TrimSpace(m.fields[1].value))
+		if err != nil {
+			m.errMsg = fmt.Sprintf("invalid duration %q", m.fields[1].value)
+			continue
+		}
+		if !t.confirmProtectedActionIn(deployment.Namespace, "inject a probe failure into", "deployment", deployment.Name) {
+			return
+		}
+		patch, err := t.injectProbeFailure(deployment, container, duration)
+		if err != nil {
+			m.errMsg = err.Error()
+			continue
+		}
+		t.recordAction(fmt.Sprintf("Failing the liveness probe of '%s' in deployment '%s' for %v", container, deployment.Name, duration),
+			k8s.KubectlJSONPatch(deployment.Namespace, "deployment", deployment.Name, patch))
+		t.loadDeployments()
+		return
+	}
+}
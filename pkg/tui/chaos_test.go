@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// probedDeployment returns a deployment whose app container has a liveness
+// probe, and whose sidecar has none
+func probedDeployment(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "sidecar", Image: "envoy"},
+			{Name: "app", Image: "nginx", LivenessProbe: &v1.Probe{ProbeHandler: v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt32(80)}}}},
+		}}}},
+	}
+}
+
+func TestTUIChaosInjection(t *testing.T) {
+	clientset := fake.NewSimpleClientset(probedDeployment("nginx-app"), probedDeployment("api"))
+	tui := &TUI{clientset: clientset}
+	liveness := func(name string) *v1.Probe {
+		d, err := clientset.AppsV1().Deployments("default").Get(t.Context(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		return d.Spec.Template.Spec.Containers[1].LivenessProbe
+	}
+
+	patch, err := tui.injectProbeFailure(*probedDeployment("nginx-app"), "app", time.Second)
+	if err != nil {
+		t.Fatalf("Failed to inject a probe failure: %v", err)
+	}
+	if !strings.Contains(string(patch), `"command":["false"]`) {
+		t.Errorf("Expected the patch to set the failing command, got %s", patch)
+	}
+	if _, err := tui.injectProbeFailure(*probedDeployment("api"), "app", time.Hour); err != nil {
+		t.Fatalf("Failed to inject a second probe failure: %v", err)
+	}
+	if status := tui.chaosStatus(); status != "[chaos: api, nginx-app]" {
+		t.Errorf("Expected both deployments in the status, got %q", status)
+	}
+	if _, err := tui.injectProbeFailure(*probedDeployment("api"), "sidecar", time.Hour); err == nil {
+		t.Error("Expected a container without a liveness probe to be refused")
+	}
+
+	// The timer reverts the first one
+	deadline := time.Now().Add(5 * time.Second)
+	for tui.chaosStatus() != "[chaos: api]" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected nginx-app to be reverted, got %q", tui.chaosStatus())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if probe := liveness("nginx-app"); probe.TCPSocket == nil {
+		t.Errorf("Expected the original probe of nginx-app back, got %+v", probe)
+	}
+
+	// Quitting reverts the others without waiting for their timers
+	if probe := liveness("api"); probe.Exec == nil {
+		t.Fatalf("Expected the probe of api to fail, got %+v", probe)
+	}
+	tui.endAllChaos()
+	if probe := liveness("api"); probe.TCPSocket == nil || tui.chaosStatus() != "" {
+		t.Errorf("Expected every injection to be reverted, got %+v and %q", probe, tui.chaosStatus())
+	}
+}
+
+// TestTUIChaosMenu tests that K in deployment details injects a failure and
+// reverts it with r
+func TestTUIChaosMenu(t *testing.T) {
+	deployment := probedDeployment("nginx-app")
+	tui, screen := newTestTUI(t, 120, 30, deployment)
+	tui.currentView = ResourceDeployments
+	tui.viewMode = ViewModeDetails
+	tui.deployments = []appsv1.Deployment{*deployment}
+
+	go func() {
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		// Replace the offered 1m
+		screen.InjectKey(tcell.KeyBackspace, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyBackspace, 0, tcell.ModNone)
+		for _, r := range "30s" {
+			screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModNone))
+
+	injection, ok := tui.activeChaos("default", "nginx-app")
+	if !ok || injection.Container != "app" || time.Until(injection.Until) > 30*time.Second {
+		t.Fatalf("Expected a 30s failure of the app container, got %+v", injection)
+	}
+	// The outcome of the injection covers the status bar for a while
+	tui.lastAction = nil
+	tui.drawStatusBar(120, 29)
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	var status strings.Builder
+	for x := 0; x < width; x++ {
+		status.WriteString(string(cells[29*width+x].Runes))
+	}
+	if !strings.Contains(status.String(), "[chaos: nginx-app]") {
+		t.Errorf("Expected the injection in the status bar, got %q", status.String())
+	}
+
+	go screen.InjectKey(tcell.KeyRune, 'r', tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'X', tcell.ModNone))
+	if _, ok := tui.activeChaos("default", "nginx-app"); ok {
+		t.Error("Expected r to revert the injection")
+	}
+	if tui.lastAction == nil || !strings.Contains(tui.lastAction.message, "Reverted the liveness probe") {
+		t.Errorf("Expected the revert to be recorded, got %+v", tui.lastAction)
+	}
+}
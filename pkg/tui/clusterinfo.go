@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// clusterInfoTimeout bounds asking the API server who the TUI is, which
+// version it runs and what it serves
+const clusterInfoTimeout = 5 * time.Second
+
+// clusterInfoView is the state of ViewModeClusterInfo
+type clusterInfoView struct {
+	info *k8s.ClusterInfo
+	// search filters the supported resource types as it is typed
+	search string
+	scroll int
+}
+
+// SetClientInfo sets the description of the cluster the clientset was
+// configured for, shown in help and on 'I'
+func (t *TUI) SetClientInfo(info *k8s.ClientInfo) {
+	t.clientInfo = info
+}
+
+// showClusterInfo opens the cluster info view: the API server, kubeconfig
+// and context in use, the authenticated user, the server version and
+// latency, the resource types the server supports and its feature gates.
+// The view is on 'I', and on Ctrl+I where the terminal tells it from Tab.
+func (t *TUI) showClusterInfo() {
+	t.closeTopPods()
+	t.closeDashboard()
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterInfoTimeout)
+	defer cancel()
+	t.clusterInfo = &clusterInfoView{info: k8s.GetClusterInfo(ctx, t.clientset, t.clientInfo)}
+	t.viewMode = ViewModeClusterInfo
+}
+
+// closeClusterInfo returns from the cluster info view to the list
+func (t *TUI) closeClusterInfo() {
+	t.clusterInfo = nil
+	if t.viewMode == ViewModeClusterInfo {
+		t.viewMode = ViewModeList
+	}
+}
+
+// handleClusterInfoKey handles the keys of the cluster info view: typing
+// searches the resource types, Backspace deletes from the search, ESC clears
+// it and then closes the view. Only Ctrl+C is left to the main loop.
+func (t *TUI) handleClusterInfoKey(ev *tcell.EventKey) bool {
+	view := t.clusterInfo
+	if view == nil {
+		t.closeClusterInfo()
+		return true
+	}
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return false
+	case tcell.KeyEscape:
+		if view.search != "" {
+			view.search = ""
+			view.scroll = 0
+		} else {
+			t.closeClusterInfo()
+		}
+	case tcell.KeyUp:
+		if view.scroll > 0 {
+			view.scroll--
+		}
+	case tcell.KeyDown:
+		view.scroll++
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if runes := []rune(view.search); len(runes) > 0 {
+			view.search = string(runes[:len(runes)-1])
+			view.scroll = 0
+		}
+	case tcell.KeyRune:
+		view.search += string(ev.Rune())
+		view.scroll = 0
+	}
+	return true
+}
+
+// matchingResources returns the supported resource types whose resource or
+// group version contains the search, ignoring case
+func (v *clusterInfoView) matchingResources() []string {
+	if v.info.APIServer == nil {
+		return nil
+	}
+	search := strings.ToLower(v.search)
+	var matching []string
+	for _, entry := range v.info.APIServer.SupportedResources {
+		if strings.Contains(strings.ToLower(entry), search) {
+			matching = append(matching, entry)
+		}
+	}
+	return matching
+}
+
+// matchingFeatureGates returns the feature gates whose name or stage contains
+// the search, ignoring case
+func (v *clusterInfoView) matchingFeatureGates() []k8s.FeatureGate {
+	search := strings.ToLower(v.search)
+	var matching []k8s.FeatureGate
+	for _, gate := range v.info.FeatureGates {
+		if strings.Contains(strings.ToLower(gate.Name), search) || strings.Contains(strings.ToLower(gate.Stage), search) {
+			matching = append(matching, gate)
+		}
+	}
+	return matching
+}
+
+// clusterInfoRow is a line of the scrolled part of the cluster info view
+type clusterInfoRow struct {
+	text  string
+	style tcell.Style
+}
+
+// clusterInfoRows returns the resource types matching the search, followed
+// by the matching feature gates with the enabled ones in green
+func (t *TUI) clusterInfoRows(view *clusterInfoView) []clusterInfoRow {
+	style := tcell.StyleDefault.Foreground(t.theme.foreground)
+	var rows []clusterInfoRow
+	for _, entry := range view.matchingResources() {
+		resource, groupVersion := k8s.SplitSupportedResource(entry)
+		rows = append(rows, clusterInfoRow{fmt.Sprintf("  %-40s %s", resource, groupVersion), style})
+	}
+	if len(view.info.FeatureGates) == 0 {
+		return rows
+	}
+
+	gates := view.matchingFeatureGates()
+	rows = append(rows,
+		clusterInfoRow{"", style},
+		clusterInfoRow{fmt.Sprintf("Feature gates (%d of %d)", len(gates), len(view.info.FeatureGates)), tcell.StyleDefault.Foreground(t.theme.header).Bold(true)},
+	)
+	for _, gate := range gates {
+		state, gateStyle := "disabled", style
+		if gate.Enabled {
+			state, gateStyle = "enabled", style.Foreground(tcell.ColorGreen)
+		}
+		rows = append(rows, clusterInfoRow{fmt.Sprintf("  %-40s %-10s %s", gate.Name, gate.Stage, state), gateStyle})
+	}
+	return rows
+}
+
+// clientInfoHelpLines returns the help section naming the cluster the TUI is
+// connected to, or none without a client info
+func (t *TUI) clientInfoHelpLines() []string {
+	if t.clientInfo == nil {
+		return nil
+	}
+	info := k8s.ClusterInfo{ClientInfo: *t.clientInfo}
+	lines := []string{" Connected to:"}
+	// The user and version need the API server; 'I' asks it
+	for _, line := range info.Lines()[:4] {
+		lines = append(lines, "   "+line)
+	}
+	return lines
+}
+
+// drawClusterInfoView draws the cluster info over the whole screen, followed
+// by the resource types and feature gates matching the search
+func (t *TUI) drawClusterInfoView(width, height int) {
+	header := " ℹ Cluster Info "
+	t.drawText(0, 0, width, header+strings.Repeat(" ", max(width-len([]rune(header)), 0)), tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	view := t.clusterInfo
+	style := tcell.StyleDefault.Foreground(t.theme.foreground)
+	y := 2
+	for _, line := range view.info.Lines() {
+		if y >= height-1 {
+			break
+		}
+		lineStyle := style
+		if strings.HasPrefix(line, "Warning:") {
+			lineStyle = style.Foreground(tcell.ColorYellow)
+		}
+		t.drawText(1, y, width-2, line, lineStyle)
+		y++
+	}
+
+	if view.info.APIServer != nil {
+		matching := view.matchingResources()
+		y++
+		title := fmt.Sprintf("Resource types (%d of %d)  Search: %s▏", len(matching), len(view.info.APIServer.SupportedResources), view.search)
+		t.drawText(1, y, width-2, title, tcell.StyleDefault.Foreground(t.theme.header).Bold(true))
+		y++
+
+		rows := t.clusterInfoRows(view)
+		visible := max(height-1-y, 0)
+		if view.scroll > max(len(rows)-visible, 0) {
+			view.scroll = max(len(rows)-visible, 0)
+		}
+		for _, row := range rows[view.scroll:] {
+			if y >= height-1 {
+				break
+			}
+			t.drawText(1, y, width-2, row.text, row.style)
+			y++
+		}
+	}
+
+	footer := " ESC Back │ Type to search resource types and feature gates │ ↑↓ Scroll "
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
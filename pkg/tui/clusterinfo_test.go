@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTUIClusterInfo(t *testing.T) {
+	screen := newTestScreen(t, 120, 40)
+	shownText := func() string {
+		screen.Show()
+		return screenText(screen)
+	}
+
+	clientset := fake.NewSimpleClientset()
+	discovery := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{GitVersion: "v1.28.3"}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "pods"}, {Name: "services"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments"}, {Name: "statefulsets"}}},
+	}
+	tui := &TUI{clientset: clientset, screen: screen, namespace: "default", currentView: ResourcePods, theme: DefaultTheme()}
+	tui.SetClientInfo(&k8s.ClientInfo{
+		Server:     "https://prod.example.com:6443",
+		Kubeconfig: "/home/dev/.kube/config",
+		Context:    "prod",
+		Auth:       "bearer token (redacted)",
+	})
+
+	// Help names the cluster without asking the API server
+	tui.drawHelpScreen(120, 40)
+	help := shownText()
+	for _, want := range []string{"Connected to:", "Server:         https://prod.example.com:6443", "Context:        prod"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("Expected %q in help, got:\n%s", want, help)
+		}
+	}
+	if strings.Contains(help, "Server version:") {
+		t.Error("Expected help to leave out the server version")
+	}
+
+	tui.showClusterInfo()
+	if tui.viewMode != ViewModeClusterInfo || tui.getViewModeName() != "Cluster Info" {
+		t.Fatalf("Expected the cluster info view, got %q", tui.getViewModeName())
+	}
+	screen.Clear()
+	tui.draw()
+	text := shownText()
+	for _, want := range []string{"Cluster Info", "Kubeconfig:     /home/dev/.kube/config", "Credentials:    bearer token (redacted)", "Server version: v1.28.3", "Latency:", "API groups:     2 (4 resource types)", "Resource types (4 of 4)", "statefulsets"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the cluster info view, got:\n%s", want, text)
+		}
+	}
+
+	// Typing searches the resource types, by name or group version
+	for _, r := range "apps" {
+		tui.handleClusterInfoKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	screen.Clear()
+	tui.draw()
+	text = shownText()
+	if !strings.Contains(text, "Resource types (2 of 4)  Search: apps") || !strings.Contains(text, "deployments") || strings.Contains(text, "  pods ") {
+		t.Errorf("Expected only the apps/v1 resource types, got:\n%s", text)
+	}
+	// The feature gates follow the resource types, and are searched too
+	tui.clusterInfo.info.FeatureGates = []k8s.FeatureGate{
+		{Name: "SidecarContainers", Stage: "BETA", Enabled: true},
+		{Name: "InPlacePodVerticalScaling", Stage: "ALPHA"},
+	}
+	tui.clusterInfo.search = ""
+	screen.Clear()
+	tui.draw()
+	text = shownText()
+	for _, want := range []string{"Feature gates (2 of 2)", "SidecarContainers                        BETA       enabled", "InPlacePodVerticalScaling                ALPHA      disabled"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the cluster info view, got:\n%s", want, text)
+		}
+	}
+	tui.clusterInfo.search = "sidecar"
+	screen.Clear()
+	tui.draw()
+	text = shownText()
+	if !strings.Contains(text, "Resource types (0 of 4)") || !strings.Contains(text, "Feature gates (1 of 2)") || strings.Contains(text, "InPlacePodVerticalScaling") {
+		t.Errorf("Expected only the SidecarContainers gate, got:\n%s", text)
+	}
+	tui.clusterInfo.search = "apps"
+
+	tui.handleClusterInfoKey(tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone))
+	if tui.clusterInfo.search != "app" {
+		t.Errorf("Expected Backspace to delete from the search, got %q", tui.clusterInfo.search)
+	}
+
+	// ESC clears the search, then closes the view
+	tui.handleClusterInfoKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if tui.viewMode != ViewModeClusterInfo || tui.clusterInfo.search != "" {
+		t.Errorf("Expected ESC to clear the search first, got %q", tui.clusterInfo.search)
+	}
+	tui.handleClusterInfoKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if tui.viewMode != ViewModeList || tui.clusterInfo != nil {
+		t.Errorf("Expected ESC to return to the list, got %q", tui.getViewModeName())
+	}
+
+	// Ctrl+I opens the view where the terminal reports the modifier, while
+	// a plain Tab still switches tabs
+	tui.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	if tui.viewMode != ViewModeList || tui.currentView != ResourceDeployments {
+		t.Errorf("Expected Tab to switch to deployments, got %q on %v", tui.getViewModeName(), tui.currentView)
+	}
+	tui.handleKey(tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModCtrl))
+	if tui.viewMode != ViewModeClusterInfo {
+		t.Errorf("Expected Ctrl+I to open the cluster info view, got %q", tui.getViewModeName())
+	}
+
+	// Without a clientset, as on a gRPC data source, I does nothing
+	if (&TUI{source: &GRPCSource{}}).keyAvailable('I') {
+		t.Error("Expected I to need a clientset")
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minColumnWidth is the narrowest a column of the list table gets. Columns
+// that do not fit next to each other at this width scroll sideways.
+const minColumnWidth = 10
+
+// tableLayout is which columns of the list table are on screen, and how wide
+// each of them is
+type tableLayout struct {
+	// columns are the indices of the columns on screen, in order
+	columns []int
+	widths  []int
+	// before and after count the columns scrolled out of view on the left
+	// and on the right
+	before, after int
+}
+
+// pinNameColumn reports whether the first column of the list table, the
+// name, stays on screen when the table scrolls sideways
+func (t *TUI) pinNameColumn() bool {
+	return t.config == nil || t.config.UI.PinNameColumn
+}
+
+// layoutTable fits numColumns columns on width cells. Columns that do not fit
+// scroll into view with columnScroll, which is kept within the columns.
+func (t *TUI) layoutTable(width, numColumns int) tableLayout {
+	// Each column takes its width and " │ " or "│ ", plus the final "│"
+	fit := min(max((width-1)/(minColumnWidth+3), 1), numColumns)
+
+	var layout tableLayout
+	first := 0
+	if fit < numColumns && t.pinNameColumn() && fit > 1 {
+		layout.columns = []int{0}
+		first, fit = 1, fit-1
+	}
+	t.columnScroll = max(min(t.columnScroll, numColumns-first-fit), 0)
+	for i := 0; i < fit; i++ {
+		layout.columns = append(layout.columns, first+t.columnScroll+i)
+	}
+	layout.before = t.columnScroll
+	layout.after = numColumns - first - t.columnScroll - fit
+	layout.widths = t.getColumnWidths(width, len(layout.columns))
+	return layout
+}
+
+// row joins the cells of the columns on screen into a line of the table,
+// cutting cells too long for their column short with "..."
+func (l tableLayout) row(cells []string) string {
+	line := "│ "
+	for i, column := range l.columns {
+		value := cells[column]
+		if len(value) > l.widths[i] {
+			value = value[:l.widths[i]-3] + "..."
+		}
+		line += fmt.Sprintf("%-*s", l.widths[i], value)
+		if i < len(l.columns)-1 {
+			line += " │ "
+		}
+	}
+	return line + " │"
+}
+
+// cell returns where a column starts on a line of the table and how wide it
+// is, or false when it is scrolled out of view
+func (l tableLayout) cell(column int) (x, width int, ok bool) {
+	// Cells are separated by " │ " after the leading "│ "
+	x = 2
+	for i, c := range l.columns {
+		if c == column {
+			return x, l.widths[i], true
+		}
+		x += l.widths[i] + 3
+	}
+	return 0, 0, false
+}
+
+// indicator describes the columns scrolled out of view, with arrows to the
+// sides they are on, e.g. "◀ 2 more columns ▶"
+func (l tableLayout) indicator() string {
+	hidden := l.before + l.after
+	if hidden == 0 {
+		return ""
+	}
+	var text strings.Builder
+	if l.before > 0 {
+		text.WriteString("◀ ")
+	}
+	if hidden == 1 {
+		text.WriteString("1 more column")
+	} else {
+		fmt.Fprintf(&text, "%d more columns", hidden)
+	}
+	if l.after > 0 {
+		text.WriteString(" ▶")
+	}
+	return text.String()
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+)
+
+func TestTUIColumnScroll(t *testing.T) {
+	tui, screen := keyBindingsTUI(t, config.KeyBindingsVim)
+	for i := range tui.pods {
+		tui.pods[i].Spec.NodeName = fmt.Sprintf("node-%02d", i)
+	}
+	row := func(y int) string {
+		screen.Clear()
+		tui.draw()
+		screen.Show()
+		cells, width, _ := screen.GetContents()
+		var text strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				text.WriteRune(runes[0])
+			}
+		}
+		return text.String()
+	}
+	// Find the table by its header
+	tableY := -1
+	for y := 0; y < 20 && tableY < 0; y++ {
+		if strings.Contains(row(y), "│ Name") {
+			tableY = y
+		}
+	}
+	if tableY < 0 {
+		t.Fatal("Expected the pod table on screen")
+	}
+
+	// Four of the six columns fit on 60 cells
+	if header, border := row(tableY), row(tableY-1); !strings.Contains(header, "Status") || strings.Contains(header, "Node") || !strings.Contains(border, " 2 more columns ▶ ") || strings.Contains(border, "◀") {
+		t.Errorf("Expected the first four columns and two more to the right, got:\n%s\n%s", border, header)
+	}
+
+	// Right scrolls the Node column into view, as far as it goes, next to
+	// the pinned names
+	pressKeys(t, tui, "<right><right><right><right>")
+	header, border, first := row(tableY), row(tableY-1), row(tableY+2)
+	if tui.columnScroll != 2 || !strings.HasPrefix(header, "│ Name") || !strings.Contains(header, "Node") || strings.Contains(header, "Ready") {
+		t.Errorf("Expected the name pinned next to the last columns, got:\n%s", header)
+	}
+	if !strings.Contains(border, " ◀ 2 more columns ") || strings.Contains(border, "▶") {
+		t.Errorf("Expected two more columns to the left, got:\n%s", border)
+	}
+	if !strings.HasPrefix(first, "│ pod-00") || !strings.Contains(first, "node-00") {
+		t.Errorf("Expected the first pod's name and node, got:\n%s", first)
+	}
+
+	// Unpinned, the names scroll out of view as well
+	tui.config.UI.PinNameColumn = false
+	header, border = row(tableY), row(tableY-1)
+	if strings.Contains(header, "Name") || !strings.Contains(header, "Ready") || !strings.Contains(header, "Node") || !strings.Contains(border, " ◀ 2 more columns ") {
+		t.Errorf("Expected the last four columns, got:\n%s\n%s", border, header)
+	}
+
+	layout := tableLayout{columns: []int{0, 3}, widths: []int{10, 12}, before: 1, after: 1}
+	if x, width, ok := layout.cell(3); !ok || x != 15 || width != 12 {
+		t.Errorf("Expected the second column on screen at 15, 12 wide, got %d, %d, %v", x, width, ok)
+	}
+	if _, _, ok := layout.cell(1); ok {
+		t.Error("Expected a scrolled column to be out of view")
+	}
+	if got := layout.row([]string{"web", "x", "y", "a-very-long-value"}); got != "│ web        │ a-very-lo... │" {
+		t.Errorf("Expected the row of the columns on screen, got %q", got)
+	}
+}
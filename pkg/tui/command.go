@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/snapshot"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// commandPrompt reads a command such as "snapshot out.json" on the bottom
+// line, as opened with ':', runs it and shows its result
+func (t *TUI) commandPrompt() {
+	input := ""
+
+	for {
+		t.draw()
+		width, height := t.screen.Size()
+		prompt := ":" + input + "_"
+		if len(prompt) < width {
+			prompt += strings.Repeat(" ", width-len(prompt))
+		}
+		t.drawText(0, height-1, width, prompt, tcell.StyleDefault.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyEnter:
+			if strings.TrimSpace(input) == "" {
+				return
+			}
+			t.drawLines(append(t.runCommand(input), "", "Press any key to continue..."))
+			for {
+				if _, ok := t.screen.PollEvent().(*tcell.EventKey); ok {
+					return
+				}
+			}
+		case tcell.KeyEscape:
+			return
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		case tcell.KeyRune:
+			input += string(ev.Rune())
+		}
+	}
+}
+
+// runCommand runs a command line and returns the lines of its result, with
+// a title first and failures starting with "Error: "
+func (t *TUI) runCommand(line string) []string {
+	args := strings.Fields(line)
+	switch args[0] {
+	case "snapshot":
+		if len(args) != 2 {
+			return []string{"Snapshot", "Error: usage: snapshot <path>"}
+		}
+		return t.writeSnapshot(args[1])
+	case "logs":
+		return t.showLogTail()
+	}
+	return []string{"Command", fmt.Sprintf("Error: unknown command %q, expected snapshot <path> or logs", args[0])}
+}
+
+// writeSnapshot writes the loaded pods, deployments and services of the
+// current namespace to a snapshot file. The configmaps tab keeps only
+// summaries, so configmaps are fetched with their values.
+func (t *TUI) writeSnapshot(path string) []string {
+	lines := []string{"Snapshot"}
+	configMaps, err := k8s.ListConfigMaps(t.clientset, t.namespace)
+	if err != nil {
+		return append(lines, fmt.Sprintf("Error: failed to list configmaps: %v", err))
+	}
+
+	s := snapshot.New(t.namespace, t.pods, t.deployments, t.services, configMaps)
+	if err := snapshot.WriteFile(path, s); err != nil {
+		return append(lines, fmt.Sprintf("Error: %v", err))
+	}
+	return append(lines,
+		fmt.Sprintf("Wrote %s as %s", path, strings.ToUpper(snapshot.FormatForPath(path))),
+		fmt.Sprintf("  Pods:        %d", s.Manifest.Pods),
+		fmt.Sprintf("  Deployments: %d", s.Manifest.Deployments),
+		fmt.Sprintf("  Services:    %d", s.Manifest.Services),
+		fmt.Sprintf("  ConfigMaps:  %d", s.Manifest.ConfigMaps),
+	)
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/snapshot"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTUISnapshotCommand(t *testing.T) {
+	tui, screen := newTestTUI(t, 120, 30, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "web-config", Namespace: "shop"}, Data: map[string]string{"key": "value"}})
+	tui.namespace = "shop"
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeList
+	tui.pods = []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}}}
+	tui.services = []v1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}}}
+
+	path := filepath.Join(t.TempDir(), "shop.yaml")
+	// The simulation screen drops keys beyond its small queue, so they are
+	// typed while the prompt reads them
+	go func() {
+		for _, r := range "snapshot " + path {
+			screen.PostEventWait(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+		screen.PostEventWait(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+		screen.PostEventWait(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone))
+	}()
+	tui.commandPrompt()
+
+	s, err := snapshot.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the snapshot to be written: %v", err)
+	}
+	if want := (snapshot.Manifest{Pods: 1, Services: 1, ConfigMaps: 1}); s.Namespace != "shop" || s.Manifest != want {
+		t.Errorf("Expected manifest %+v for shop, got %+v of %q", want, s.Manifest, s.Namespace)
+	}
+	if s.ConfigMaps[0].Data["key"] != "value" {
+		t.Errorf("Expected configmaps with their values, got %+v", s.ConfigMaps)
+	}
+
+	result := strings.Join(tui.runCommand("snapshot "+path), "\n")
+	if !strings.Contains(result, "Wrote "+path+" as YAML") || !strings.Contains(result, "Pods:        1") {
+		t.Errorf("Expected the path and counts, got:\n%s", result)
+	}
+	for _, line := range []string{"snapshot", "snapshot a b", "scale web 3"} {
+		if result := tui.runCommand(line); !strings.HasPrefix(result[len(result)-1], "Error: ") {
+			t.Errorf("Expected an error for %q, got %v", line, result)
+		}
+	}
+}
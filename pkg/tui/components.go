@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// spinnerFrames are the braille frames cycled by a Spinner
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// spinnerInterval is how long a spinner frame is shown
+var spinnerInterval = 100 * time.Millisecond
+
+// ProgressBar renders progress as "[████░░░░] 50% Pods 5/10". The loading
+// screen draws one per resource type; the TUI has no drain or bulk delete
+// to draw one for.
+type ProgressBar struct {
+	Current int
+	Total   int
+	Label   string
+}
+
+// NewProgressBar creates a progress bar for current out of total items
+func NewProgressBar(current, total int, label string) *ProgressBar {
+	return &ProgressBar{Current: current, Total: total, Label: label}
+}
+
+// Percent returns the completion percentage, clamped to 0-100
+func (p *ProgressBar) Percent() int {
+	if p.Total <= 0 {
+		return 0
+	}
+	return p.clampedCurrent() * 100 / p.Total
+}
+
+// clampedCurrent keeps Current within 0 and Total
+func (p *ProgressBar) clampedCurrent() int {
+	switch {
+	case p.Current < 0 || p.Total <= 0:
+		return 0
+	case p.Current > p.Total:
+		return p.Total
+	default:
+		return p.Current
+	}
+}
+
+// Render returns the bar as text no wider than width. The bar itself always
+// keeps at least one cell; the suffix is cut when space runs out.
+func (p *ProgressBar) Render(width int) string {
+	suffix := fmt.Sprintf(" %d%%", p.Percent())
+	if p.Label != "" {
+		suffix += " " + p.Label
+	}
+	suffix += fmt.Sprintf(" %d/%d", p.clampedCurrent(), p.Total)
+
+	barWidth := width - 2 - len([]rune(suffix))
+	if barWidth < 1 {
+		barWidth = 1
+	}
+
+	filled := 0
+	if p.Total > 0 {
+		filled = barWidth * p.clampedCurrent() / p.Total
+	}
+
+	text := "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]" + suffix
+	if runes := []rune(text); len(runes) > width {
+		text = string(runes[:width])
+	}
+	return text
+}
+
+// Draw renders the bar on screen at (x, y)
+func (p *ProgressBar) Draw(screen tcell.Screen, x, y, width int, style tcell.Style) {
+	for i, r := range []rune(p.Render(width)) {
+		screen.SetContent(x+i, y, r, nil, style)
+	}
+}
+
+// Spinner cycles through braille frames for operations of unknown duration
+type Spinner struct {
+	frame int
+}
+
+// Frame returns the current frame
+func (s *Spinner) Frame() rune {
+	return spinnerFrames[s.frame%len(spinnerFrames)]
+}
+
+// Next advances the spinner and returns the new frame
+func (s *Spinner) Next() rune {
+	s.frame = (s.frame + 1) % len(spinnerFrames)
+	return s.Frame()
+}
+
+// runWithSpinner runs fn in the background, redrawing message after the next
+// spinner frame at (x, y) until fn returns its error
+func (t *TUI) runWithSpinner(x, y, width int, message string, style tcell.Style, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		t.drawText(x, y, width, fmt.Sprintf("%c %s", t.spinner.Next(), message), style)
+		t.screen.Show()
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
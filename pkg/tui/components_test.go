@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestProgressBarRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  int
+		total    int
+		label    string
+		width    int
+		expected string
+	}{
+		{"zero", 0, 10, "Pods", 32, "[░░░░░░░░░░░░░░░░░] 0% Pods 0/10"},
+		{"half", 5, 10, "Pods", 32, "[████████░░░░░░░░] 50% Pods 5/10"},
+		{"full", 10, 10, "Pods", 34, "[████████████████] 100% Pods 10/10"},
+		{"overflow", 15, 10, "Pods", 34, "[████████████████] 100% Pods 10/10"},
+		{"negative", -3, 10, "Pods", 32, "[░░░░░░░░░░░░░░░░░] 0% Pods 0/10"},
+		{"zero total", 3, 0, "Pods", 22, "[░░░░░░░░] 0% Pods 0/0"},
+		{"no label", 1, 4, "", 22, "[███░░░░░░░░░] 25% 1/4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewProgressBar(tt.current, tt.total, tt.label).Render(tt.width)
+			if got != tt.expected {
+				t.Errorf("Render(%d) = %q, expected %q", tt.width, got, tt.expected)
+			}
+			if n := len([]rune(got)); n > tt.width {
+				t.Errorf("Rendered width %d exceeds %d", n, tt.width)
+			}
+		})
+	}
+}
+
+func TestProgressBarNarrowWidth(t *testing.T) {
+	got := NewProgressBar(5, 10, "Deployments").Render(10)
+	if n := len([]rune(got)); n != 10 {
+		t.Errorf("Expected output truncated to 10 cells, got %d: %q", n, got)
+	}
+	if !strings.HasPrefix(got, "[█]") && !strings.HasPrefix(got, "[░]") {
+		t.Errorf("Expected a one-cell bar when space runs out, got %q", got)
+	}
+}
+
+func TestProgressBarPercent(t *testing.T) {
+	if p := NewProgressBar(1, 3, "").Percent(); p != 33 {
+		t.Errorf("Expected 33%%, got %d%%", p)
+	}
+	if p := NewProgressBar(100, 3, "").Percent(); p != 100 {
+		t.Errorf("Expected overflow to clamp to 100%%, got %d%%", p)
+	}
+}
+
+func TestSpinnerCycles(t *testing.T) {
+	var s Spinner
+	if s.Frame() != '⠋' {
+		t.Errorf("Expected first frame ⠋, got %c", s.Frame())
+	}
+
+	seen := map[rune]bool{s.Frame(): true}
+	for i := 0; i < len(spinnerFrames)-1; i++ {
+		seen[s.Next()] = true
+	}
+	if len(seen) != len(spinnerFrames) {
+		t.Errorf("Expected %d distinct frames, got %d", len(spinnerFrames), len(seen))
+	}
+	if s.Next() != '⠋' {
+		t.Error("Expected spinner to wrap around to the first frame")
+	}
+}
+
+// TestRunWithSpinnerAnimates checks that the spinner of an operation keeps
+// moving until the operation returns
+func TestRunWithSpinnerAnimates(t *testing.T) {
+	screen := newTestScreen(t, 80, 5)
+	saved := spinnerInterval
+	spinnerInterval = time.Millisecond
+	defer func() { spinnerInterval = saved }()
+
+	tui := &TUI{screen: screen}
+	failed := errors.New("forbidden")
+	// The operation returns once it has seen three frames on the screen
+	err := tui.runWithSpinner(0, 1, 80, "Deleting pod 'web'...", tcell.StyleDefault, func() error {
+		seen := map[rune]bool{}
+		deadline := time.Now().Add(5 * time.Second)
+		for len(seen) < 3 && time.Now().Before(deadline) {
+			frame, _, _, _ := screen.GetContent(0, 1)
+			if strings.ContainsRune(string(spinnerFrames), frame) {
+				seen[frame] = true
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if len(seen) < 3 {
+			return fmt.Errorf("saw only the frames %v", seen)
+		}
+		return failed
+	})
+	if err != failed {
+		t.Errorf("Expected the operation's error once the spinner moved, got %v", err)
+	}
+}
+
+// TestLoadingScreenProgress tests that the loading screen shows per-resource progress
+func TestLoadingScreenProgress(t *testing.T) {
+	screen := newTestScreen(t, 80, 24)
+
+	tui := &TUI{
+		screen:          screen,
+		loading:         true,
+		loadingCounter:  3,
+		loadedResources: map[ResourceType]bool{ResourcePods: true, ResourceServices: true},
+	}
+	tui.drawLoadingScreen(80, 24)
+	screen.Show()
+
+	cells, width, height := screen.GetContents()
+	var text strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				text.WriteRune(runes[0])
+			}
+		}
+		text.WriteRune('\n')
+	}
+
+	for _, want := range []string{"20% Resources 2/10", "100% Pods 1/1", "0% Deployments 0/1", "100% Services 1/1", "0% Namespaces 0/1", "0% Nodes 0/1", "0% CRDs 0/1"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected loading screen to contain %q, got:\n%s", want, text.String())
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// configMapForm holds the state of the configmap creation form, which either
+// takes literal key=value pairs or a file or directory picked in the file
+// browser, like kubectl create configmap --from-literal and --from-file
+type configMapForm struct {
+	namespace string
+	// path is the picked file or directory of a form from a file
+	path   string
+	fields []*wizardField
+	field  int
+	errMsg string
+}
+
+// newConfigMapForm creates a form for a configmap in namespace, from the file
+// or directory at path when it is set and from literals otherwise
+func newConfigMapForm(namespace, path string) *configMapForm {
+	f := &configMapForm{namespace: namespace, path: path, fields: []*wizardField{{label: "Name"}}}
+	if path == "" {
+		f.fields = append(f.fields, &wizardField{label: "Literals"})
+	}
+	return f
+}
+
+// fromFile reports whether the form creates a configmap from a path
+func (f *configMapForm) fromFile() bool {
+	return f.path != ""
+}
+
+// handleKey applies a key press to the form
+func (f *configMapForm) handleKey(ev *tcell.EventKey) wizardAction {
+	if editFields(f.fields, &f.field, ev) {
+		return wizardContinue
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return wizardCancel
+	case tcell.KeyEnter:
+		return wizardDone
+	}
+	return wizardContinue
+}
+
+// build returns the configmap described by the form with its kubectl
+// equivalent. Files are read when the form is submitted.
+func (f *configMapForm) build() (*v1.ConfigMap, string, error) {
+	name := strings.TrimSpace(f.fields[0].value)
+
+	var literals map[string]string
+	var files map[string][]byte
+	var err error
+	if f.fromFile() {
+		files, err = readConfigMapFiles(f.path)
+	} else {
+		literals, err = parseLiterals(strings.TrimSpace(f.fields[1].value))
+		if err == nil && len(literals) == 0 {
+			err = fmt.Errorf("at least one key=value literal is required")
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	configMap, err := k8s.NewConfigMapFromData(f.namespace, name, literals, files)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := validation.ConfigMap(configMap); err != nil {
+		return nil, "", err
+	}
+
+	// kubectl takes the path itself, which also covers every file of a
+	// directory
+	var paths []string
+	if f.fromFile() {
+		paths = []string{f.path}
+	}
+	return configMap, k8s.KubectlCreateConfigMapFromData(f.namespace, name, literals, paths), nil
+}
+
+// lines renders the form
+func (f *configMapForm) lines() []string {
+	title := "Create ConfigMap from literals"
+	hint := "Literals are comma-separated key=value pairs, e.g. mode=prod,level=debug."
+	footer := "Tab/↑↓: Field | Enter: Create | Esc: Cancel"
+	if f.fromFile() {
+		title = "Create ConfigMap from file"
+		hint = "Each file becomes a key named after it; a directory adds every regular file in it. Files that are not UTF-8 go to binaryData."
+		footer = "Enter: Create | Ctrl-O: Pick another file | Esc: Cancel"
+	}
+	lines := append([]string{title, ""}, fieldLines(f.fields, f.field)...)
+	if f.fromFile() {
+		lines = append(lines, "  From: "+f.path)
+	}
+	lines = append(lines, "", hint)
+	if f.errMsg != "" {
+		lines = append(lines, "Error: "+f.errMsg)
+	}
+	return append(lines, "", footer)
+}
+
+// parseLiterals parses comma-separated key=value literals. Unlike labels,
+// keys are configmap keys, which validation.ConfigMap checks later.
+func parseLiterals(text string) (map[string]string, error) {
+	literals := make(map[string]string)
+	for _, pair := range splitList(text) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("literal %q is not key=value", pair)
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := literals[key]; ok {
+			return nil, fmt.Errorf("key %q is given more than once", key)
+		}
+		literals[key] = strings.TrimSpace(value)
+	}
+	return literals, nil
+}
+
+// readConfigMapFiles reads a file, or every regular file directly inside a
+// directory, keyed by file name
+func readConfigMapFiles(path string) (map[string][]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = nil
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				paths = append(paths, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%s has no regular files", path)
+		}
+		sort.Strings(paths)
+	}
+
+	files := make(map[string][]byte, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Base(p)] = data
+	}
+	return files, nil
+}
+
+// createConfigMapError describes why a configmap could not be created
+func createConfigMapError(name string, err error) string {
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		return fmt.Sprintf("configmap %q already exists", name)
+	case apierrors.IsForbidden(err):
+		return fmt.Sprintf("not allowed to create configmaps: %v", err)
+	}
+	return err.Error()
+}
+
+// createConfigMapDialog asks whether to create a configmap from literals or
+// from a file, picked in the file browser, then runs the matching form
+func (t *TUI) createConfigMapDialog() {
+	t.drawLines([]string{
+		fmt.Sprintf("Create ConfigMap in '%s'", t.namespace),
+		"",
+		"l: From literal (key=value pairs)",
+		"f: From file (pick a file or a directory)",
+		"",
+		"Esc: Cancel",
+	})
+
+	var f *configMapForm
+	for f == nil {
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch {
+		case ev.Key() == tcell.KeyEscape:
+			return
+		case ev.Rune() == 'l':
+			f = newConfigMapForm(t.namespace, "")
+		case ev.Rune() == 'f':
+			path, ok := t.pickLocalPath()
+			if !ok {
+				return
+			}
+			f = newConfigMapForm(t.namespace, path)
+		}
+	}
+
+	for {
+		t.drawLines(f.lines())
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		if f.fromFile() && ev.Key() == tcell.KeyCtrlO {
+			if path, ok := t.pickLocalPath(); ok {
+				f.path, f.errMsg = path, ""
+			}
+			continue
+		}
+		switch f.handleKey(ev) {
+		case wizardCancel:
+			return
+		case wizardDone:
+			configMap, kubectl, err := f.build()
+			if err != nil {
+				f.errMsg = err.Error()
+				continue
+			}
+			if !t.confirmProtectedActionIn(t.namespace, "create", "configmap", configMap.Name) {
+				return
+			}
+			if _, err := k8s.CreateConfigMap(t.clientset, t.namespace, configMap); err != nil {
+				f.errMsg = createConfigMapError(configMap.Name, err)
+				continue
+			}
+			t.recordAction(fmt.Sprintf("Created configmap '%s'", configMap.Name), kubectl)
+			t.loadConfigMaps()
+			return
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestTUICreateConfigMap tests creating configmaps from literals and from a
+// directory or file picked in the file browser, with a binary file landing
+// in binaryData
+func TestTUICreateConfigMap(t *testing.T) {
+	tui, screen := newTestTUI(t, 120, 30)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceConfigMaps
+
+	// A key with a path separator is rejected in the form, then fixed
+	go func() {
+		screen.InjectKey(tcell.KeyRune, 'l', tcell.ModNone)
+		typeText(screen, "settings")
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "conf/mode=prod")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		for range "conf/mode=prod" {
+			screen.InjectKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+		}
+		typeText(screen, "mode=prod, level=debug")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.createConfigMapDialog()
+
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "settings", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected configmap settings to be created: %v", err)
+	}
+	if want := map[string]string{"mode": "prod", "level": "debug"}; fmt.Sprint(configMap.Data) != fmt.Sprint(want) {
+		t.Errorf("Expected data %v, got %v", want, configMap.Data)
+	}
+	if len(tui.configMaps) != 1 {
+		t.Errorf("Expected the configmap list to be reloaded, got %d configmaps", len(tui.configMaps))
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "--from-literal=level=debug --from-literal=mode=prod") {
+		t.Errorf("Expected the literals in the status bar, got %q", status)
+	}
+
+	// The file browser starts in the working directory, where s picks the
+	// directory shown and every regular file in it becomes a key
+	t.Chdir(t.TempDir())
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logo := []byte{0x89, 'P', 'N', 'G', 0xff}
+	if err := os.WriteFile(filepath.Join(dir, "app.conf"), []byte("port=80\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), logo, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		screen.InjectKey(tcell.KeyRune, 'f', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 's', tcell.ModNone)
+		typeText(screen, "assets")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.createConfigMapDialog()
+
+	configMap, err = clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "assets", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected configmap assets to be created: %v", err)
+	}
+	if len(configMap.Data) != 1 || configMap.Data["app.conf"] != "port=80\n" {
+		t.Errorf("Expected app.conf in data, got %v", configMap.Data)
+	}
+	if len(configMap.BinaryData) != 1 || string(configMap.BinaryData["logo.png"]) != string(logo) {
+		t.Errorf("Expected logo.png in binaryData, got %v", configMap.BinaryData)
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "--from-file="+dir) {
+		t.Errorf("Expected the directory in the status bar, got %q", status)
+	}
+
+	// Enter picks the selected file, logo.png after the nested directory and
+	// app.conf, and Ctrl-O in the form picks app.conf instead
+	go func() {
+		screen.InjectKey(tcell.KeyRune, 'f', tcell.ModNone)
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		typeText(screen, "settings-file")
+		screen.InjectKey(tcell.KeyCtrlO, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.createConfigMapDialog()
+
+	configMap, err = clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "settings-file", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected configmap settings-file to be created: %v", err)
+	}
+	if len(configMap.Data) != 1 || configMap.Data["app.conf"] != "port=80\n" || len(configMap.BinaryData) != 0 {
+		t.Errorf("Expected only app.conf, got %v and %v", configMap.Data, configMap.BinaryData)
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "--from-file="+filepath.Join(dir, "app.conf")) {
+		t.Errorf("Expected the file in the status bar, got %q", status)
+	}
+
+	// Esc in the file browser creates nothing
+	go func() {
+		screen.InjectKey(tcell.KeyRune, 'f', tcell.ModNone)
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	}()
+	tui.createConfigMapDialog()
+	if list, _ := clientset.CoreV1().ConfigMaps("default").List(context.TODO(), metav1.ListOptions{}); len(list.Items) != 3 {
+		t.Errorf("Expected 3 configmaps after cancelling, got %d", len(list.Items))
+	}
+
+	// Creating it again fails inside the form
+	go func() {
+		screen.InjectKey(tcell.KeyRune, 'f', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 's', tcell.ModNone)
+		typeText(screen, "assets")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	}()
+	tui.createConfigMapDialog()
+	if text := screenText(screen); !strings.Contains(text, `Error: configmap "assets" already exists`) || !strings.Contains(text, "From: "+dir) {
+		t.Errorf("Expected the AlreadyExists error and the picked directory in the form, got:\n%s", text)
+	}
+}
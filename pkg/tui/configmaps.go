@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"k8s-dashboard/pkg/crypto"
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// configMapPreviewThreshold is the size above which a configmap value is
+	// only previewed until its full value is loaded
+	configMapPreviewThreshold = 64 * 1024
+	// configMapPreviewSize is how much of a large value the preview shows
+	configMapPreviewSize = 4 * 1024
+)
+
+// configMapValues holds the values of the configmap shown in the details or
+// YAML view. The list only keeps summaries, so values are fetched when one of
+// those views is first drawn for a configmap.
+type configMapValues struct {
+	namespace       string
+	name            string
+	resourceVersion string
+	configMap       *v1.ConfigMap
+	err             error
+	// full shows large values in full instead of a preview
+	full bool
+	// decryptErr is why the values of an encrypted configmap are shown as
+	// stored
+	decryptErr error
+}
+
+// errNoConfigMapKey is why encrypted values are shown as stored without an
+// age key file
+var errNoConfigMapKey = errors.New("no age key to decrypt them, set crypto.ageKeyFile")
+
+// SetConfigMapKeys sets the keys decrypting the values of configmaps created
+// with X-Encrypt: sops before the TUI shows them
+func (t *TUI) SetConfigMapKeys(keys *crypto.Keys) {
+	t.configMapKeys = keys
+}
+
+// formatSize formats a size in bytes, e.g. "512B", "1.5KiB" or "2.0MiB"
+func formatSize(size int) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KiB", "MiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f%s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1fGiB", value)
+}
+
+// previewValue returns the first configMapPreviewSize bytes of a value larger
+// than configMapPreviewThreshold, cut at a UTF-8 boundary, and whether it was
+// cut. Other values, and every value when full is set, are returned whole.
+func previewValue(value []byte, full bool) ([]byte, bool) {
+	if full || len(value) <= configMapPreviewThreshold {
+		return value, false
+	}
+	end := configMapPreviewSize
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+	return value[:end], true
+}
+
+// configMapValuesFor returns the values of a configmap, fetching them unless
+// the same version of it is cached
+func (t *TUI) configMapValuesFor(summary k8s.ConfigMapSummary) *configMapValues {
+	cached := t.configMapValues
+	if cached != nil && cached.namespace == summary.Namespace && cached.name == summary.Name && cached.resourceVersion == summary.ResourceVersion {
+		return cached
+	}
+
+	configMap, err := t.data().GetConfigMap(summary.Namespace, summary.Name)
+	t.configMapValues = &configMapValues{
+		namespace:       summary.Namespace,
+		name:            summary.Name,
+		resourceVersion: summary.ResourceVersion,
+		configMap:       configMap,
+		err:             err,
+	}
+	if err == nil && k8s.IsEncryptedConfigMap(configMap) {
+		t.configMapValues.configMap, t.configMapValues.decryptErr = t.decryptConfigMap(configMap)
+	}
+	return t.configMapValues
+}
+
+// decryptConfigMap returns an encrypted configmap with its values decrypted,
+// or as stored with the reason it could not be decrypted
+func (t *TUI) decryptConfigMap(configMap *v1.ConfigMap) (*v1.ConfigMap, error) {
+	if !t.configMapKeys.CanDecrypt() {
+		return configMap, errNoConfigMapKey
+	}
+	decrypted, err := k8s.DecryptConfigMap(configMap, t.configMapKeys.Decrypt)
+	if err != nil {
+		klog.Errorf("Failed to decrypt configmap %s/%s: %v", configMap.Namespace, configMap.Name, err)
+		return configMap, err
+	}
+	return decrypted, nil
+}
+
+// loadFullConfigMapValues shows the large values of the selected configmap in
+// full instead of a preview
+func (t *TUI) loadFullConfigMapValues() {
+	summary, ok := t.getSelectedResource().(k8s.ConfigMapSummary)
+	if !ok {
+		return
+	}
+	t.configMapValuesFor(summary).full = true
+}
+
+// previewConfigMap returns a copy of a configmap with every large value cut
+// to its preview, unless full is set
+func previewConfigMap(configMap *v1.ConfigMap, full bool) *v1.ConfigMap {
+	preview := configMap.DeepCopy()
+	for key, value := range preview.Data {
+		if cut, truncated := previewValue([]byte(value), full); truncated {
+			preview.Data[key] = string(cut) + fmt.Sprintf("\n... %s more, press E to load the full value", formatSize(len(value)-len(cut)))
+		}
+	}
+	for key, value := range preview.BinaryData {
+		preview.BinaryData[key], _ = previewValue(value, full)
+	}
+	return preview
+}
+
+// configMapYAMLObject returns what the YAML view shows for a configmap: the
+// configmap with large values previewed and, when toggled with U, base64
+// values decoded, or the error fetching it
+func (t *TUI) configMapYAMLObject(summary k8s.ConfigMapSummary) interface{} {
+	values := t.configMapValuesFor(summary)
+	if values.err != nil {
+		return map[string]string{"error": values.err.Error()}
+	}
+	preview := previewConfigMap(values.configMap, values.full)
+	if !t.decodeValues {
+		return preview
+	}
+	decoded, err := decodedConfigMap(preview)
+	if err != nil {
+		return map[string]string{"error": err.Error()}
+	}
+	return decoded
+}
+
+// getConfigMapDetails returns formatted details for a configmap: its keys and
+// sizes, then its values, with large values previewed
+func (t *TUI) getConfigMapDetails(cm k8s.ConfigMapSummary) []string {
+	binaryKeys := 0
+	for _, key := range cm.Keys {
+		if key.Binary {
+			binaryKeys++
+		}
+	}
+
+	details := []string{
+		fmt.Sprintf("Name: %s", cm.Name),
+		fmt.Sprintf("Namespace: %s", cm.Namespace),
+		fmt.Sprintf("Data items: %d", len(cm.Keys)-binaryKeys),
+		fmt.Sprintf("Binary data items: %d", binaryKeys),
+		fmt.Sprintf("Size: %s", formatSize(cm.Size)),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(cm.CreationTimestamp)),
+		"",
+		"Data keys:",
+	}
+	for _, key := range cm.Keys {
+		kind := ""
+		if key.Binary {
+			kind = ", binary"
+		}
+		details = append(details, fmt.Sprintf("  - %s (%s%s)", key.Key, formatSize(key.Size), kind))
+	}
+
+	values := t.configMapValuesFor(cm)
+	if values.err != nil {
+		return append(details, "", fmt.Sprintf("Failed to load values: %v", values.err))
+	}
+	if k8s.IsEncryptedConfigMap(values.configMap) {
+		if values.decryptErr != nil {
+			details = append(details, "", fmt.Sprintf("Values are encrypted with SOPS and shown as stored: %v", values.decryptErr))
+		} else {
+			details = append(details, "", "Values are encrypted with SOPS and shown decrypted")
+		}
+	}
+
+	truncated := false
+	for _, key := range cm.Keys {
+		value, ok := values.configMap.Data[key.Key]
+		if !ok {
+			continue
+		}
+		cut, cutShort := previewValue([]byte(value), values.full)
+		truncated = truncated || cutShort
+
+		details = append(details, "", fmt.Sprintf("── %s ──", key.Key))
+		for _, line := range strings.Split(strings.TrimSuffix(string(cut), "\n"), "\n") {
+			details = append(details, "  "+line)
+		}
+		if cutShort {
+			details = append(details, fmt.Sprintf("  ... %s more", formatSize(len(value)-len(cut))))
+		}
+	}
+	if truncated {
+		details = append(details, "", fmt.Sprintf("Values over %s show their first %s. Press E to load full values.",
+			formatSize(configMapPreviewThreshold), formatSize(configMapPreviewSize)))
+	}
+	return details
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/crypto"
+	"k8s-dashboard/pkg/k8s"
+
+	"filippo.io/age"
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int]string{
+		0:               "0B",
+		512:             "512B",
+		1536:            "1.5KiB",
+		2 * 1024 * 1024: "2.0MiB",
+		3 << 30:         "3.0GiB",
+	}
+	for size, want := range tests {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestPreviewValue(t *testing.T) {
+	small := []byte("debug: true")
+	if got, cut := previewValue(small, false); cut || string(got) != string(small) {
+		t.Errorf("Expected a small value to be kept whole, got %q (cut %v)", got, cut)
+	}
+
+	// A multi-byte rune straddling the preview size is left out whole
+	large := []byte(strings.Repeat("a", configMapPreviewSize-1) + "é" + strings.Repeat("b", configMapPreviewThreshold))
+	got, cut := previewValue(large, false)
+	if !cut || len(got) != configMapPreviewSize-1 {
+		t.Errorf("Expected the large value to be cut before the rune, got %d bytes (cut %v)", len(got), cut)
+	}
+	if got, cut := previewValue(large, true); cut || len(got) != len(large) {
+		t.Errorf("Expected the full value when requested, got %d bytes (cut %v)", len(got), cut)
+	}
+}
+
+func TestTUIConfigMapValuesAreLazy(t *testing.T) {
+	bundle := strings.Repeat("MIIB", configMapPreviewThreshold)
+	tui, screen := newTestTUI(t, 120, 30, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string]string{"ca.crt": bundle, "mode": "strict"},
+		BinaryData: map[string][]byte{"ca.der": {0x30, 0x82}},
+	})
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceConfigMaps
+	tui.viewMode = ViewModeDetails
+	if err := tui.loadConfigMaps(); err != nil {
+		t.Fatalf("loadConfigMaps failed: %v", err)
+	}
+	summary := tui.configMaps[0]
+	if summary.Size != len(bundle)+len("strict")+2 || len(summary.Keys) != 3 {
+		t.Fatalf("Expected sizes of all three keys, got %+v", summary)
+	}
+	if got := tui.getResourceColumnValue(summary, 2); got != "256.0KiB" {
+		t.Errorf("Expected the size column to read 256.0KiB, got %q", got)
+	}
+
+	gets := func() int {
+		count := 0
+		for _, action := range clientset.Actions() {
+			if action.GetVerb() == "get" {
+				count++
+			}
+		}
+		return count
+	}
+	if gets() != 0 {
+		t.Fatal("Expected values not to be fetched before the details are shown")
+	}
+
+	details := strings.Join(tui.getResourceDetails(summary), "\n")
+	if gets() != 1 {
+		t.Errorf("Expected the details to fetch the configmap once, got %d gets", gets())
+	}
+	if !strings.Contains(details, "ca.der (2B, binary)") || !strings.Contains(details, "  strict") {
+		t.Errorf("Expected key sizes and small values in the details, got:\n%s", details)
+	}
+	if strings.Contains(details, bundle) || !strings.Contains(details, "Press E to load full values") {
+		t.Errorf("Expected the bundle to be previewed, got %d bytes of details", len(details))
+	}
+	if yaml := tui.getResourceYAML(summary); strings.Contains(yaml, bundle) || !strings.Contains(yaml, "press E") {
+		t.Errorf("Expected the YAML to preview the bundle, got %d bytes", len(yaml))
+	}
+
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'E', tcell.ModNone))
+	details = strings.Join(tui.getResourceDetails(summary), "\n")
+	if !strings.Contains(details, bundle) {
+		t.Error("Expected the full bundle after loading full values")
+	}
+	if gets() != 1 {
+		t.Errorf("Expected cached values to be reused, got %d gets", gets())
+	}
+
+	// L opens the label editor in the configmap details as everywhere else
+	go func() {
+		for _, r := range "aowner" {
+			screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		for _, r := range "pki" {
+			screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		}
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 's', tcell.ModNone)
+	}()
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'L', tcell.ModNone))
+	configMap, err := clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "ca", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap: %v", err)
+	}
+	if configMap.Labels["owner"] != "pki" {
+		t.Errorf("Expected L to label the configmap owner=pki, got %v", configMap.Labels)
+	}
+}
+
+// TestTUIConfigMapDecryption tests that the values of a configmap created with
+// X-Encrypt: sops are decrypted before they are shown, and shown as stored
+// without the key
+func TestTUIConfigMapDecryption(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate an age identity: %v", err)
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string]string{"password": "hunter2"},
+	}
+	if err := k8s.EncryptConfigMap(configMap, identity.Recipient().String()); err != nil {
+		t.Fatalf("EncryptConfigMap failed: %v", err)
+	}
+	tui := &TUI{
+		clientset:   fake.NewSimpleClientset(configMap),
+		namespace:   "default",
+		currentView: ResourceConfigMaps,
+		viewMode:    ViewModeDetails,
+	}
+	if err := tui.loadConfigMaps(); err != nil {
+		t.Fatalf("loadConfigMaps failed: %v", err)
+	}
+	summary := tui.configMaps[0]
+
+	details := strings.Join(tui.getResourceDetails(summary), "\n")
+	if strings.Contains(details, "hunter2") || !strings.Contains(details, "shown as stored: "+errNoConfigMapKey.Error()) {
+		t.Errorf("Expected the values as stored without a key, got:\n%s", details)
+	}
+
+	tui.SetConfigMapKeys(&crypto.Keys{Backend: crypto.BackendAge, Decrypt: identity.String()})
+	tui.configMapValues = nil
+	details = strings.Join(tui.getResourceDetails(summary), "\n")
+	if !strings.Contains(details, "── password ──\n  hunter2") || !strings.Contains(details, "shown decrypted") {
+		t.Errorf("Expected the decrypted value in the details, got:\n%s", details)
+	}
+	if yaml := tui.getResourceYAML(summary); !strings.Contains(yaml, "hunter2") {
+		t.Errorf("Expected the decrypted value in the YAML, got:\n%s", yaml)
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate an age identity: %v", err)
+	}
+	tui.SetConfigMapKeys(&crypto.Keys{Backend: crypto.BackendAge, Decrypt: other.String()})
+	tui.configMapValues = nil
+	details = strings.Join(tui.getResourceDetails(summary), "\n")
+	if strings.Contains(details, "hunter2") || !strings.Contains(details, crypto.ErrNoIdentityMatch.Error()) {
+		t.Errorf("Expected why another key cannot decrypt the values, got:\n%s", details)
+	}
+}
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"k8s.io/client-go/dynamic"
+)
+
+// errNoDynamicClient is the error of a CRD load without a dynamic client
+var errNoDynamicClient = errors.New("listing CRDs needs a dynamic client")
+
+// SetDynamicClient sets the client CustomResourceDefinitions are listed
+// through. Without one the CRDs tab stays empty.
+func (t *TUI) SetDynamicClient(dynamicClient dynamic.Interface) {
+	t.dynamicClient = dynamicClient
+}
+
+// loadCRDsAsync loads CustomResourceDefinitions asynchronously
+func (t *TUI) loadCRDsAsync(load dataLoad) {
+	update := load.update()
+	update.Error = errNoDynamicClient
+	if t.dynamicClient != nil {
+		update.CRDs, update.Error = k8s.ListCRDs(context.TODO(), t.dynamicClient)
+	}
+	t.dataChan <- update
+}
+
+// getCRDDetails returns formatted details for a CRD, with the schema of its
+// storage version as a tree
+func (t *TUI) getCRDDetails(crd k8s.CRD) []string {
+	details := []string{
+		fmt.Sprintf("Name: %s", crd.Name),
+		fmt.Sprintf("Group: %s", crd.Group),
+		fmt.Sprintf("Kind: %s (%s)", crd.Kind, crd.Plural),
+		fmt.Sprintf("Scope: %s", crd.Scope),
+		fmt.Sprintf("Storage version: %s", crd.Version),
+		fmt.Sprintf("Served versions: %s", strings.Join(crd.Versions, ", ")),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(crd.CreationTimestamp)),
+		"",
+		fmt.Sprintf("Schema (%s):", crd.Version),
+	}
+
+	tree := k8s.SchemaTree(crd.Schema)
+	if len(tree) == 0 {
+		return append(details, "  no structural schema")
+	}
+	for _, line := range tree {
+		details = append(details, "  "+line)
+	}
+	return details
+}
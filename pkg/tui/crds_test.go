@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func TestTUICRDsView(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets.example.com"},
+		"spec": map[string]interface{}{
+			"group": "example.com",
+			"scope": "Namespaced",
+			"names": map[string]interface{}{"kind": "Widget", "plural": "widgets"},
+			"versions": []interface{}{map[string]interface{}{
+				"name": "v1", "served": true, "storage": true,
+				"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{"size": map[string]interface{}{"type": "integer"}},
+						},
+					},
+				}},
+			}},
+		},
+	}}
+	tui := &TUI{
+		config:      config.DefaultConfig(),
+		currentView: ResourceCRDs,
+		dataChan:    make(chan *DataUpdate, 1),
+	}
+
+	// Without a dynamic client the load fails rather than panics
+	tui.loadCRDsAsync(tui.newLoad(ResourceCRDs, false))
+	if update := <-tui.dataChan; update.Error == nil {
+		t.Error("Expected an error without a dynamic client")
+	}
+
+	tui.SetDynamicClient(fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{k8s.CRDResource: "CustomResourceDefinitionList"}, crd))
+	tui.loadCRDsAsync(tui.newLoad(ResourceCRDs, false))
+	tui.handleDataUpdate(<-tui.dataChan)
+	if len(tui.crds) != 1 {
+		t.Fatalf("Expected one CRD, got %+v", tui.crds)
+	}
+
+	resource := tui.getSelectedResource()
+	var row []string
+	for col := range tui.getTableHeaders() {
+		row = append(row, tui.getResourceColumnValue(resource, col))
+	}
+	if strings.Join(row[:4], ",") != "widgets.example.com,example.com,v1,Namespaced" {
+		t.Errorf("Expected Name/Group/Version/Scope columns, got %v", row)
+	}
+
+	details := strings.Join(tui.getResourceDetails(resource), "\n")
+	if !strings.Contains(details, "Kind: Widget (widgets)") || !strings.Contains(details, "  spec: object\n  └─ size: integer") {
+		t.Errorf("Expected the kind and schema tree in the details, got:\n%s", details)
+	}
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/klog/v2"
+)
+
+// loadDaemonSetsAsync loads daemonsets asynchronously
+func (t *TUI) loadDaemonSetsAsync(load dataLoad) {
+	update := load.update()
+	update.DaemonSets, update.Error = t.data().ListDaemonSets(load.namespace)
+	t.dataChan <- update
+}
+
+// loadDaemonSets fetches daemonsets from the current namespace
+func (t *TUI) loadDaemonSets() error {
+	daemonSets, err := t.data().ListDaemonSets(t.namespace)
+	if err != nil {
+		klog.Errorf("Failed to list daemonsets: %v", err)
+		return err
+	}
+	t.daemonSets = daemonSets
+	return nil
+}
+
+// getDaemonSetDetails returns formatted details for a daemonset, with the
+// pods a restart leaves alone
+func (t *TUI) getDaemonSetDetails(ds appsv1.DaemonSet) []string {
+	details := []string{
+		fmt.Sprintf("Name: %s", ds.Name),
+		fmt.Sprintf("Namespace: %s", ds.Namespace),
+		fmt.Sprintf("Desired: %d", ds.Status.DesiredNumberScheduled),
+		fmt.Sprintf("Current: %d", ds.Status.CurrentNumberScheduled),
+		fmt.Sprintf("Ready: %d", ds.Status.NumberReady),
+		fmt.Sprintf("Updated: %d", ds.Status.UpdatedNumberScheduled),
+		fmt.Sprintf("Available: %d", ds.Status.NumberAvailable),
+		fmt.Sprintf("Node selector: %s", formatNodeSelector(ds.Spec.Template.Spec.NodeSelector)),
+		fmt.Sprintf("Update strategy: %s", ds.Spec.UpdateStrategy.Type),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(ds.CreationTimestamp)),
+	}
+	if warning := k8s.RestartWarning(&ds); warning != "" {
+		details = append(details, "", "⚠ Restart: "+warning)
+	}
+	return details
+}
+
+// formatNodeSelector returns a node selector as sorted key=value pairs
+func formatNodeSelector(selector map[string]string) string {
+	if len(selector) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(selector))
+	for key, value := range selector {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
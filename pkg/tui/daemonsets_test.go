@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestDaemonSet(strategy appsv1.DaemonSetUpdateStrategyType) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{
+				NodeSelector: map[string]string{"kubernetes.io/os": "linux", "disk": "ssd"},
+			}},
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: strategy},
+		},
+		Status: appsv1.DaemonSetStatus{
+			DesiredNumberScheduled: 3,
+			CurrentNumberScheduled: 3,
+			NumberReady:            2,
+			UpdatedNumberScheduled: 1,
+			NumberAvailable:        2,
+		},
+	}
+}
+
+// TestDaemonSetDetails tests the DaemonSets tab, and the restart warning in
+// the details of a daemonset updated on delete
+func TestDaemonSetDetails(t *testing.T) {
+	tui := &TUI{
+		clientset:   fake.NewSimpleClientset(newTestDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType)),
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourceDaemonSets,
+		dataChan:    make(chan *DataUpdate, 1),
+	}
+
+	tui.loadDaemonSetsAsync(tui.newLoad(ResourceDaemonSets, false))
+	tui.handleDataUpdate(<-tui.dataChan)
+	selected, ok := tui.getSelectedResource().(appsv1.DaemonSet)
+	if !ok || selected.Name != "agent" {
+		t.Fatalf("Expected daemonset agent to be selected, got %+v", tui.getSelectedResource())
+	}
+	for col, want := range []string{"agent", "3", "2", "1", "2"} {
+		if got := tui.getResourceColumnValue(selected, col); got != want {
+			t.Errorf("Expected column %d to be %q, got %q", col, want, got)
+		}
+	}
+
+	details := strings.Join(tui.getResourceDetails(selected), "\n")
+	if !strings.Contains(details, "Node selector: disk=ssd,kubernetes.io/os=linux\nUpdate strategy: RollingUpdate") {
+		t.Errorf("Expected the node selector and update strategy, got:\n%s", details)
+	}
+	if strings.Contains(details, "Restart:") {
+		t.Errorf("Expected no restart warning for a rolling update, got:\n%s", details)
+	}
+
+	details = strings.Join(tui.getDaemonSetDetails(*newTestDaemonSet(appsv1.OnDeleteDaemonSetStrategyType)), "\n")
+	if want := "⚠ Restart: update strategy is OnDelete, so pods are only restarted when they are deleted"; !strings.Contains(details, want) {
+		t.Errorf("Expected %q in the details, got:\n%s", want, details)
+	}
+}
+
+// TestTUIRestartDaemonSet tests that O restarts a daemonset once y confirms
+// it, and that anything else cancels
+func TestTUIRestartDaemonSet(t *testing.T) {
+	ds := newTestDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType)
+	tui, screen := newTestTUI(t, 120, 30, ds)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceDaemonSets
+	tui.viewMode = ViewModeList
+	tui.daemonSets = []appsv1.DaemonSet{*ds}
+	restartedAt := func() string {
+		restarted, err := clientset.AppsV1().DaemonSets("default").Get(context.Background(), "agent", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get the daemonset: %v", err)
+		}
+		return restarted.Spec.Template.Annotations[k8s.RestartedAtAnnotation]
+	}
+
+	screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModNone))
+	if row, _ := screenRow(screen, 1); !strings.HasPrefix(row, "Restart daemonset 'agent'? (y/N)") {
+		t.Errorf("Expected the confirmation, got %q", row)
+	}
+	if restartedAt() != "" {
+		t.Fatal("Expected Esc to leave the daemonset alone")
+	}
+
+	screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModNone))
+	if restartedAt() == "" {
+		t.Fatal("Expected y to set the restartedAt annotation of the pod template")
+	}
+	if want := "kubectl -n default rollout restart daemonset/agent"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+	if len(tui.daemonSets) != 1 {
+		t.Errorf("Expected the daemonsets to be loaded again, got %+v", tui.daemonSets)
+	}
+}
@@ -0,0 +1,536 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/metrics"
+	"k8s-dashboard/pkg/util"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// dashboardInterval is how often the dashboard reloads without ui.autoRefresh
+const dashboardInterval = 30 * time.Second
+
+// dashboardMaxBarWidth caps the namespace bars on wide terminals
+const dashboardMaxBarWidth = 40
+
+// dashboardPhases are the pod phases in the order the dashboard lists them;
+// others follow by name
+var dashboardPhases = []string{"Running", "Pending", "Succeeded", "Failed", "Unknown"}
+
+// dashboardView is the state of the dashboard, written by the goroutine
+// reloading it and read when drawing, so guarded by mu
+type dashboardView struct {
+	mu       sync.Mutex
+	overview *metrics.Overview
+	err      error
+	loadedAt time.Time
+	// nodes is the usage of each node, loaded with a metrics client
+	nodes    []k8s.NodeMetricSummary
+	nodesErr error
+	// selected indexes the selectable lines
+	selected int
+	// cancel stops the goroutine reloading the view; nil while it is closed
+	cancel context.CancelFunc
+}
+
+// dashboardTarget is where Enter on a dashboard line jumps to: a tab, and
+// optionally a namespace, a name filter and the not-ready filter
+type dashboardTarget struct {
+	view      ResourceType
+	namespace string
+	filter    string
+	notReady  bool
+}
+
+// dashboardLine is a line of the dashboard, selectable when it has a target
+type dashboardLine struct {
+	text   string
+	style  tcell.Style
+	target *dashboardTarget
+}
+
+// dashboardSection is a summary line followed by a list that is cut short,
+// with an "… and N more" line, when the terminal is too small for it
+type dashboardSection struct {
+	summary []dashboardLine
+	items   []dashboardLine
+}
+
+// openDashboard shows the cluster overview, reloaded on the ui.autoRefresh
+// cadence until the view is closed
+func (t *TUI) openDashboard() {
+	t.closeTopPods()
+	t.closeDashboard()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.dashboard.mu.Lock()
+	t.dashboard.cancel = cancel
+	t.dashboard.mu.Unlock()
+
+	t.viewMode = ViewModeDashboard
+	go t.watchDashboard(ctx)
+}
+
+// closeDashboard stops reloading the dashboard and returns to the list
+func (t *TUI) closeDashboard() {
+	t.dashboard.mu.Lock()
+	if t.dashboard.cancel != nil {
+		t.dashboard.cancel()
+		t.dashboard.cancel = nil
+	}
+	t.dashboard.mu.Unlock()
+
+	if t.viewMode == ViewModeDashboard {
+		t.viewMode = ViewModeList
+	}
+}
+
+// dashboardRefreshInterval is ui.autoRefresh, or dashboardInterval when it is
+// not set
+func (t *TUI) dashboardRefreshInterval() time.Duration {
+	if interval := t.staleAfter(); interval > 0 {
+		return interval
+	}
+	return dashboardInterval
+}
+
+// watchDashboard loads the overview immediately and then on every refresh
+// until ctx is done. The last overview stays on screen while reloading.
+func (t *TUI) watchDashboard(ctx context.Context) {
+	ticker := time.NewTicker(t.dashboardRefreshInterval())
+	defer ticker.Stop()
+
+	for {
+		overview, err := metrics.CollectOverview(ctx, t.clientset)
+		var nodes []k8s.NodeMetricSummary
+		var nodesErr error
+		if t.metricsClientset != nil {
+			nodes, nodesErr = k8s.GetNodeMetrics(ctx, t.metricsClientset, t.clientset)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		t.dashboard.mu.Lock()
+		if err == nil {
+			t.dashboard.overview = overview
+		}
+		t.dashboard.err = err
+		t.dashboard.nodes = nodes
+		t.dashboard.nodesErr = nodesErr
+		t.dashboard.loadedAt = time.Now()
+		t.dashboard.mu.Unlock()
+		t.requestRedraw()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleDashboardKey handles the keys of the dashboard and reports whether
+// the key was used. Tab switches, h/l with the vim keys and the number keys
+// leave the dashboard for the list and are then handled as usual.
+func (t *TUI) handleDashboardKey(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		t.closeDashboard()
+		return true
+	case tcell.KeyUp:
+		t.moveDashboardSelection(-1)
+		return true
+	case tcell.KeyDown:
+		t.moveDashboardSelection(1)
+		return true
+	case tcell.KeyEnter:
+		t.activateDashboardSelection()
+		return true
+	case tcell.KeyTab:
+		t.closeDashboard()
+	case tcell.KeyRune:
+		switch r := ev.Rune(); {
+		case r == 'r':
+			t.openDashboard()
+			return true
+		case r >= '1' && r <= '7':
+			t.closeDashboard()
+		case (r == 'h' || r == 'l') && t.vimKeys():
+			t.closeDashboard()
+		}
+	}
+	return false
+}
+
+// dashboardTargets returns the targets of the selectable lines as drawn on
+// the current screen
+func (t *TUI) dashboardTargets() []dashboardTarget {
+	width, height := t.screen.Size()
+	var targets []dashboardTarget
+	for _, line := range t.dashboardLines(width, height) {
+		if line.target != nil {
+			targets = append(targets, *line.target)
+		}
+	}
+	return targets
+}
+
+// moveDashboardSelection moves the selection among the selectable lines
+func (t *TUI) moveDashboardSelection(delta int) {
+	count := len(t.dashboardTargets())
+
+	t.dashboard.mu.Lock()
+	defer t.dashboard.mu.Unlock()
+	t.dashboard.selected += delta
+	if t.dashboard.selected >= count {
+		t.dashboard.selected = count - 1
+	}
+	if t.dashboard.selected < 0 {
+		t.dashboard.selected = 0
+	}
+}
+
+// activateDashboardSelection jumps to the tab, namespace and filters of the
+// selected line
+func (t *TUI) activateDashboardSelection() {
+	targets := t.dashboardTargets()
+	t.dashboard.mu.Lock()
+	selected := t.dashboard.selected
+	t.dashboard.mu.Unlock()
+	if selected < 0 || selected >= len(targets) {
+		return
+	}
+	t.openDashboardTarget(targets[selected])
+}
+
+// openDashboardTarget leaves the dashboard for a tab with the filters of a
+// target, switching namespace first when it names another one
+func (t *TUI) openDashboardTarget(target dashboardTarget) {
+	t.closeDashboard()
+	t.filter = target.filter
+	t.notReadyFilter = target.notReady
+	if target.namespace != "" && target.namespace != t.namespace {
+		t.namespace = target.namespace
+		t.refreshData()
+	}
+	t.switchView(target.view)
+}
+
+// dashboardLines lays out the dashboard for a width x height screen, header
+// and footer excluded. Lists are shortened to fit the height, and the
+// namespace bars scale with the width.
+func (t *TUI) dashboardLines(width, height int) []dashboardLine {
+	t.dashboard.mu.Lock()
+	overview, err, loadedAt := t.dashboard.overview, t.dashboard.err, t.dashboard.loadedAt
+	nodes, nodesErr := t.dashboard.nodes, t.dashboard.nodesErr
+	t.dashboard.mu.Unlock()
+
+	gray := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	switch {
+	case overview == nil && loadedAt.IsZero():
+		return []dashboardLine{{text: "Loading cluster overview...", style: gray}}
+	case overview == nil:
+		return []dashboardLine{{text: fmt.Sprintf("Overview unavailable: %v", err), style: tcell.StyleDefault.Foreground(tcell.ColorRed)}}
+	}
+
+	sections := []dashboardSection{
+		{summary: []dashboardLine{t.dashboardNodesLine(overview), t.dashboardPodsLine(overview)}},
+		t.dashboardDeploymentsSection(overview),
+		t.dashboardEventsSection(overview, width),
+		t.dashboardNamespacesSection(overview, width),
+	}
+	if section, ok := t.dashboardNodeUsageSection(nodes, nodesErr, width); ok {
+		sections = append(sections, section)
+	}
+
+	// Every section keeps its summary and a blank line after it; the lists
+	// share what is left a row at a time so that each shows some items
+	room := height - 3
+	sizes := make([]int, len(sections))
+	for i, section := range sections {
+		room -= len(section.summary) + 1
+		sizes[i] = len(section.items)
+	}
+	shown := shareRows(sizes, room)
+
+	var lines []dashboardLine
+	for i, section := range sections {
+		lines = append(lines, section.summary...)
+		items := section.items
+		if shown[i] < len(items) {
+			// The last row left says how many more there are
+			keep := max(shown[i]-1, 0)
+			more := len(items) - keep
+			items = items[:keep:keep]
+			if shown[i] > 0 {
+				items = append(items, dashboardLine{text: fmt.Sprintf("  … and %d more", more), style: gray})
+			}
+		}
+		lines = append(lines, items...)
+		lines = append(lines, dashboardLine{})
+	}
+	if err != nil {
+		lines = append(lines, dashboardLine{text: fmt.Sprintf("Reload failed, showing the last overview: %v", err), style: tcell.StyleDefault.Foreground(tcell.ColorRed)})
+	}
+	return lines
+}
+
+// shareRows splits room rows between lists of the given sizes one row at a
+// time, so that small terminals still show the start of every list
+func shareRows(sizes []int, room int) []int {
+	shown := make([]int, len(sizes))
+	for room > 0 {
+		gave := false
+		for i := range sizes {
+			if room > 0 && shown[i] < sizes[i] {
+				shown[i]++
+				room--
+				gave = true
+			}
+		}
+		if !gave {
+			break
+		}
+	}
+	return shown
+}
+
+// dashboardStatusStyle is green when ok, and red otherwise
+func dashboardStatusStyle(ok bool) tcell.Style {
+	if ok {
+		return tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	}
+	return tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+}
+
+// dashboardNodesLine counts the ready nodes and jumps to the nodes that are not
+func (t *TUI) dashboardNodesLine(overview *metrics.Overview) dashboardLine {
+	ok := overview.Nodes.Ready == overview.Nodes.Total
+	return dashboardLine{
+		text:   fmt.Sprintf("%-13s%d/%d ready", "Nodes", overview.Nodes.Ready, overview.Nodes.Total),
+		style:  dashboardStatusStyle(ok),
+		target: &dashboardTarget{view: ResourceNodes, notReady: !ok},
+	}
+}
+
+// dashboardPodsLine totals the pods by phase and jumps to those not ready
+func (t *TUI) dashboardPodsLine(overview *metrics.Overview) dashboardLine {
+	var phases []string
+	for _, phase := range dashboardPhases {
+		if count := overview.Pods.Phases[phase]; count > 0 {
+			phases = append(phases, fmt.Sprintf("%d %s", count, phase))
+		}
+	}
+	var others []string
+	for phase := range overview.Pods.Phases {
+		if !containsString(dashboardPhases, phase) {
+			others = append(others, phase)
+		}
+	}
+	sort.Strings(others)
+	for _, phase := range others {
+		phases = append(phases, fmt.Sprintf("%d %s", overview.Pods.Phases[phase], phase))
+	}
+
+	text := fmt.Sprintf("%-13s%d total", "Pods", overview.Pods.Total)
+	if len(phases) > 0 {
+		text += ": " + strings.Join(phases, ", ")
+	}
+	ok := overview.Pods.NotReady == 0
+	if !ok {
+		text += fmt.Sprintf(" (%d not ready)", overview.Pods.NotReady)
+	}
+	return dashboardLine{text: text, style: dashboardStatusStyle(ok), target: &dashboardTarget{view: ResourcePods, notReady: !ok}}
+}
+
+// dashboardDeploymentsSection lists the deployments not fully available
+func (t *TUI) dashboardDeploymentsSection(overview *metrics.Overview) dashboardSection {
+	degraded := overview.Deployments.Degraded
+	if len(degraded) == 0 {
+		return dashboardSection{summary: []dashboardLine{{
+			text:   fmt.Sprintf("%-13sall %d available", "Deployments", overview.Deployments.Total),
+			style:  dashboardStatusStyle(true),
+			target: &dashboardTarget{view: ResourceDeployments},
+		}}}
+	}
+
+	section := dashboardSection{summary: []dashboardLine{{
+		text:   fmt.Sprintf("%-13s%d deployments degraded (of %d)", "Deployments", len(degraded), overview.Deployments.Total),
+		style:  dashboardStatusStyle(false),
+		target: &dashboardTarget{view: ResourceDeployments, notReady: true},
+	}}}
+	for _, deployment := range degraded {
+		section.items = append(section.items, dashboardLine{
+			text:   fmt.Sprintf("  %s/%s  %d/%d available", deployment.Namespace, deployment.Name, deployment.Available, deployment.Desired),
+			style:  tcell.StyleDefault.Foreground(tcell.ColorOrange),
+			target: &dashboardTarget{view: ResourceDeployments, namespace: deployment.Namespace, filter: deployment.Name},
+		})
+	}
+	return section
+}
+
+// dashboardEventsSection lists the recent Warning events, newest first
+func (t *TUI) dashboardEventsSection(overview *metrics.Overview, width int) dashboardSection {
+	header := tcell.StyleDefault.Foreground(t.theme.header).Bold(true)
+	if len(overview.WarningEvents) == 0 {
+		return dashboardSection{summary: []dashboardLine{{
+			text:  fmt.Sprintf("No warning events in the last %s", util.HumanDuration(metrics.OverviewEventWindow)),
+			style: dashboardStatusStyle(true),
+		}}}
+	}
+
+	section := dashboardSection{summary: []dashboardLine{{
+		text:  fmt.Sprintf("Warning events (last %s)", util.HumanDuration(metrics.OverviewEventWindow)),
+		style: header,
+	}}}
+	for _, event := range overview.WarningEvents {
+		text := fmt.Sprintf("  %-10s %s %s/%s  %s: %s", t.formatTimestamp(event.LastSeen), event.Kind, event.Namespace, event.Name, event.Reason, event.Message)
+		if event.Count > 1 {
+			text += fmt.Sprintf(" (x%d)", event.Count)
+		}
+		if runes := []rune(text); len(runes) > width && width > 3 {
+			text = string(runes[:width-3]) + "..."
+		}
+		line := dashboardLine{text: text, style: tcell.StyleDefault.Foreground(tcell.ColorYellow)}
+		if view, ok := eventKindViews[event.Kind]; ok {
+			target := &dashboardTarget{view: view, namespace: event.Namespace, filter: event.Name}
+			if view == ResourceNodes {
+				target.namespace = ""
+			}
+			line.target = target
+		}
+		section.items = append(section.items, line)
+	}
+	return section
+}
+
+// eventKindViews are the tabs of the kinds events can be about
+var eventKindViews = map[string]ResourceType{
+	"Pod":                   ResourcePods,
+	"Deployment":            ResourceDeployments,
+	"Service":               ResourceServices,
+	"ConfigMap":             ResourceConfigMaps,
+	"Node":                  ResourceNodes,
+	"StatefulSet":           ResourceStatefulSets,
+	"PersistentVolumeClaim": ResourcePVCs,
+	"DaemonSet":             ResourceDaemonSets,
+}
+
+// dashboardNamespacesSection draws the pods of each namespace as a bar
+// scaled to the busiest namespace and to the width of the screen
+func (t *TUI) dashboardNamespacesSection(overview *metrics.Overview, width int) dashboardSection {
+	section := dashboardSection{summary: []dashboardLine{{
+		text:  "Pods per namespace",
+		style: tcell.StyleDefault.Foreground(t.theme.header).Bold(true),
+	}}}
+	if len(overview.Namespaces) == 0 {
+		return section
+	}
+
+	nameWidth := 0
+	for _, namespace := range overview.Namespaces {
+		nameWidth = max(nameWidth, len(namespace.Namespace))
+	}
+	nameWidth = min(nameWidth, 24)
+	// Leave room for the indent, the name, the count and "(N not ready)"
+	barWidth := min(width-nameWidth-24, dashboardMaxBarWidth)
+	busiest := overview.Namespaces[0].Pods
+
+	for _, namespace := range overview.Namespaces {
+		name := namespace.Namespace
+		if len(name) > nameWidth {
+			name = name[:nameWidth-3] + "..."
+		}
+		text := fmt.Sprintf("  %-*s ", nameWidth, name)
+		if barWidth > 0 {
+			text += namespaceBar(namespace.Pods, busiest, barWidth) + " "
+		}
+		text += fmt.Sprintf("%d", namespace.Pods)
+		if namespace.NotReady > 0 {
+			text += fmt.Sprintf(" (%d not ready)", namespace.NotReady)
+		}
+		section.items = append(section.items, dashboardLine{
+			text:   text,
+			style:  dashboardStatusStyle(namespace.NotReady == 0),
+			target: &dashboardTarget{view: ResourcePods, namespace: namespace.Namespace},
+		})
+	}
+	return section
+}
+
+// namespaceBar draws count out of busiest as a bar of width block characters,
+// with at least one full block for a namespace with any pods
+func namespaceBar(count, busiest, width int) string {
+	filled := 0
+	if busiest > 0 {
+		filled = count * width / busiest
+	}
+	if filled == 0 && count > 0 {
+		filled = 1
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// matchesNotReady reports whether a resource passes the not-ready filter set
+// from the dashboard: pods that are not ready, degraded deployments and nodes
+// that are not Ready. Other kinds are not filtered.
+func (t *TUI) matchesNotReady(resource interface{}) bool {
+	if !t.notReadyFilter {
+		return true
+	}
+	switch r := resource.(type) {
+	case v1.Pod:
+		return metrics.IsPodNotReady(&r)
+	case appsv1.Deployment:
+		return metrics.IsDeploymentDegraded(&r)
+	case v1.Node:
+		return !metrics.IsNodeReady(&r)
+	}
+	return true
+}
+
+// drawDashboardView draws the cluster overview over the whole screen, with
+// the selected line highlighted
+func (t *TUI) drawDashboardView(width, height int) {
+	header := " 🩺 Cluster Overview "
+	t.drawText(0, 0, width, header+strings.Repeat(" ", max(width-len([]rune(header)), 0)), tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	t.dashboard.mu.Lock()
+	selected := t.dashboard.selected
+	loadedAt := t.dashboard.loadedAt
+	t.dashboard.mu.Unlock()
+
+	y := 2
+	index := 0
+	for _, line := range t.dashboardLines(width, height) {
+		if y >= height-1 {
+			break
+		}
+		style := line.style
+		if line.target != nil {
+			if index == selected {
+				style = style.Reverse(true)
+			}
+			index++
+		}
+		t.drawText(0, y, width, line.text, style)
+		y++
+	}
+
+	footer := fmt.Sprintf(" ESC Back │ ↑↓ Select │ Enter Open │ r Reload │ Refreshes every %v ", t.dashboardRefreshInterval())
+	if !loadedAt.IsZero() {
+		footer += fmt.Sprintf("│ Updated %s ", loadedAt.Format("15:04:05"))
+	}
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
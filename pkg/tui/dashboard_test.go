@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// TestTUIDashboard tests the cluster overview at two terminal sizes, and
+// that Enter on a section jumps to its tab with the not-ready filter
+func TestTUIDashboard(t *testing.T) {
+	node := func(name string, ready v1.ConditionStatus) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}}},
+		}
+	}
+	pod := func(namespace, name string, ready v1.ConditionStatus) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: ready}},
+			},
+		}
+	}
+	deployment := func(namespace, name string, replicas, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: available},
+		}
+	}
+	event := func(name, reason string) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "cart-1"},
+			Type:           v1.EventTypeWarning,
+			Reason:         reason,
+			Message:        "Back-off restarting failed container",
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-time.Minute)),
+		}
+	}
+
+	web, api, ok := deployment("default", "web", 3, 1), deployment("shop", "api", 2, 0), deployment("default", "ok", 1, 1)
+	objects := []runtime.Object{
+		node("node-1", v1.ConditionTrue), node("node-2", v1.ConditionTrue), node("node-3", v1.ConditionFalse),
+		pod("default", "web-1", v1.ConditionTrue), pod("default", "web-2", v1.ConditionFalse),
+		web, api, ok,
+		event("e1", "BackOff"), event("e2", "Unhealthy"),
+	}
+	for i := 0; i < 5; i++ {
+		objects = append(objects, pod("shop", fmt.Sprintf("cart-%d", i), v1.ConditionTrue))
+	}
+
+	tui, screen := newTestTUI(t, 120, 40, objects...)
+	tui.currentView = ResourcePods
+	tui.deployments = []appsv1.Deployment{*web, *ok}
+
+	tui.openDashboard()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tui.dashboard.mu.Lock()
+		loaded := !tui.dashboard.loadedAt.IsZero()
+		tui.dashboard.mu.Unlock()
+		if loaded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the overview")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if tui.getViewModeName() != "Dashboard" {
+		t.Errorf("Expected the dashboard, got %q", tui.getViewModeName())
+	}
+
+	// drawAt draws the dashboard on a width x height screen and returns its lines
+	drawAt := func(width, height int) []string {
+		screen.SetSize(width, height)
+		tui.draw()
+		screen.Show()
+		cells, w, h := screen.GetContents()
+		lines := make([]string, h)
+		for y := 0; y < h; y++ {
+			var line strings.Builder
+			for x := 0; x < w; x++ {
+				if runes := cells[y*w+x].Runes; len(runes) > 0 {
+					line.WriteRune(runes[0])
+				}
+			}
+			lines[y] = strings.TrimRight(line.String(), " ")
+		}
+		return lines
+	}
+	// find returns the first line starting with prefix
+	find := func(lines []string, prefix string) string {
+		for _, line := range lines {
+			if strings.HasPrefix(line, prefix) {
+				return line
+			}
+		}
+		return ""
+	}
+
+	lines := drawAt(120, 40)
+	text := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"Nodes        2/3 ready",
+		"Pods         7 total: 7 Running (1 not ready)",
+		"Deployments  2 deployments degraded (of 3)",
+		"  default/web  1/3 available",
+		"  shop/api  0/2 available",
+		"Warning events (last 60m)",
+		"Pod shop/cart-1  BackOff: Back-off restarting failed container",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q on the dashboard, got:\n%s", want, text)
+		}
+	}
+	// The busiest namespace fills the widest bar, the other is scaled to it
+	if line := find(lines, "  shop "); !strings.Contains(line, strings.Repeat("█", dashboardMaxBarWidth)+" 5") {
+		t.Errorf("Expected a full bar for shop, got %q", line)
+	}
+	if line := find(lines, "  default "); !strings.Contains(line, strings.Repeat("█", 16)+strings.Repeat("░", 24)+" 2 (1 not ready)") {
+		t.Errorf("Expected a bar of 2/5 for default, got %q", line)
+	}
+
+	// A narrower screen shrinks the bars, a shorter one cuts the lists short
+	lines = drawAt(60, 20)
+	if line := find(lines, "  shop "); !strings.Contains(line, strings.Repeat("█", 60-len("default")-24)+" 5") {
+		t.Errorf("Expected a bar scaled to 60 columns, got %q", line)
+	}
+	lines = drawAt(60, 14)
+	text = strings.Join(lines, "\n")
+	if !strings.Contains(text, "Nodes        2/3 ready") || !strings.Contains(text, "  … and 2 more") {
+		t.Errorf("Expected the summaries and shortened lists, got:\n%s", text)
+	}
+	if find(lines, "  shop ") != "" {
+		t.Errorf("Expected no room for the namespace bars, got:\n%s", text)
+	}
+	if !strings.HasPrefix(lines[13], " ESC Back") {
+		t.Errorf("Expected the footer on the last line, got %q", lines[13])
+	}
+
+	// Nodes, pods and then the degraded deployments summary
+	screen.SetSize(120, 40)
+	tui.handleDashboardKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	tui.handleDashboardKey(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone))
+	tui.handleDashboardKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if tui.viewMode != ViewModeList || tui.currentView != ResourceDeployments || !tui.notReadyFilter {
+		t.Fatalf("Expected the deployments tab with the not-ready filter, got %v %v %v", tui.getViewModeName(), tui.currentView, tui.notReadyFilter)
+	}
+	tui.dashboard.mu.Lock()
+	stopped := tui.dashboard.cancel == nil
+	tui.dashboard.mu.Unlock()
+	if !stopped {
+		t.Error("Expected leaving the dashboard to stop its reloads")
+	}
+	filtered := tui.getFilteredResources()
+	if len(filtered) != 1 || tui.getResourceName(filtered[0]) != "web" {
+		t.Errorf("Expected only the degraded deployment, got %v", filtered)
+	}
+	tui.clearFilter()
+	if tui.notReadyFilter || len(tui.getFilteredResources()) != 2 {
+		t.Error("Expected clearing the filter to show every deployment")
+	}
+
+	// A degraded deployment filters the tab by its name
+	tui.openDashboard()
+	tui.dashboard.mu.Lock()
+	tui.dashboard.selected = 3
+	tui.dashboard.mu.Unlock()
+	tui.handleDashboardKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if tui.currentView != ResourceDeployments || tui.filter != "web" || tui.notReadyFilter {
+		t.Errorf("Expected the deployments tab filtered by web, got %v %q %v", tui.currentView, tui.filter, tui.notReadyFilter)
+	}
+}
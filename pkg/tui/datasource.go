@@ -0,0 +1,254 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	kgogrpc "k8s-dashboard/pkg/grpc"
+	"k8s-dashboard/pkg/k8s"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DataSource is where the TUI loads resources from: the cluster's API
+// directly, or a kgo gRPC server. The lists return the resourceVersion to
+// pass to the next list of the same type; sources that cannot serve lists
+// from the watch cache ignore it and return an empty one.
+type DataSource interface {
+	ListPods(namespace, resourceVersion string) ([]v1.Pod, string, error)
+	ListDeployments(namespace, resourceVersion string) ([]appsv1.Deployment, string, error)
+	ListServices(namespace, resourceVersion string) ([]v1.Service, string, error)
+	ListConfigMaps(namespace, resourceVersion string) ([]k8s.ConfigMapSummary, string, error)
+	GetConfigMap(namespace, name string) (*v1.ConfigMap, error)
+	ListNamespaces() ([]v1.Namespace, error)
+	ListNodes() ([]v1.Node, error)
+	ListStatefulSets(namespace string) ([]appsv1.StatefulSet, error)
+	ListPVCs(namespace string) ([]v1.PersistentVolumeClaim, error)
+	ListDaemonSets(namespace string) ([]appsv1.DaemonSet, error)
+}
+
+// clientsetSource is implemented by data sources backed by a clientset. The
+// TUI's operations beyond loading resources use it directly, and are hidden
+// when the data source has none.
+type clientsetSource interface {
+	Clientset() kubernetes.Interface
+}
+
+// ClusterSource loads resources from the cluster's API
+type ClusterSource struct {
+	clientset kubernetes.Interface
+}
+
+// NewClusterSource returns a data source reading through clientset
+func NewClusterSource(clientset kubernetes.Interface) *ClusterSource {
+	return &ClusterSource{clientset: clientset}
+}
+
+// Clientset returns the clientset the source reads through
+func (s *ClusterSource) Clientset() kubernetes.Interface {
+	return s.clientset
+}
+
+func (s *ClusterSource) ListPods(namespace, resourceVersion string) ([]v1.Pod, string, error) {
+	return k8s.ListPodsSince(s.clientset, namespace, resourceVersion)
+}
+
+func (s *ClusterSource) ListDeployments(namespace, resourceVersion string) ([]appsv1.Deployment, string, error) {
+	return k8s.ListDeploymentsSince(s.clientset, namespace, resourceVersion)
+}
+
+func (s *ClusterSource) ListServices(namespace, resourceVersion string) ([]v1.Service, string, error) {
+	return k8s.ListServicesSince(s.clientset, namespace, resourceVersion)
+}
+
+func (s *ClusterSource) ListConfigMaps(namespace, resourceVersion string) ([]k8s.ConfigMapSummary, string, error) {
+	return k8s.ListConfigMapSummariesSince(s.clientset, namespace, resourceVersion)
+}
+
+func (s *ClusterSource) GetConfigMap(namespace, name string) (*v1.ConfigMap, error) {
+	return k8s.GetConfigMap(s.clientset, namespace, name)
+}
+
+func (s *ClusterSource) ListNamespaces() ([]v1.Namespace, error) {
+	return k8s.ListNamespaces(s.clientset)
+}
+
+func (s *ClusterSource) ListNodes() ([]v1.Node, error) {
+	return k8s.ListNodes(s.clientset)
+}
+
+func (s *ClusterSource) ListStatefulSets(namespace string) ([]appsv1.StatefulSet, error) {
+	return k8s.ListStatefulSets(s.clientset, namespace)
+}
+
+func (s *ClusterSource) ListPVCs(namespace string) ([]v1.PersistentVolumeClaim, error) {
+	return k8s.ListPVCs(s.clientset, namespace)
+}
+
+func (s *ClusterSource) ListDaemonSets(namespace string) ([]appsv1.DaemonSet, error) {
+	return k8s.ListDaemonSets(s.clientset, namespace)
+}
+
+// errNotServedOverGRPC is returned for resources the gRPC API has no RPC for
+var errNotServedOverGRPC = errors.New("not served by the kgo gRPC API")
+
+// GRPCSource loads resources from a kgo gRPC server, for running the TUI
+// without a kubeconfig
+type GRPCSource struct {
+	client *kgogrpc.Client
+}
+
+// NewGRPCSource returns a data source reading through a gRPC client
+func NewGRPCSource(client *kgogrpc.Client) *GRPCSource {
+	return &GRPCSource{client: client}
+}
+
+func (s *GRPCSource) ListPods(namespace, _ string) ([]v1.Pod, string, error) {
+	pods, _, err := s.client.ListAllPods(namespace)
+	return pods, "", err
+}
+
+func (s *GRPCSource) ListDeployments(namespace, _ string) ([]appsv1.Deployment, string, error) {
+	deployments, err := s.client.ListDeployments(namespace)
+	return deployments, "", err
+}
+
+func (s *GRPCSource) ListServices(namespace, _ string) ([]v1.Service, string, error) {
+	services, err := s.client.ListServices(namespace)
+	return services, "", err
+}
+
+func (s *GRPCSource) ListConfigMaps(namespace, _ string) ([]k8s.ConfigMapSummary, string, error) {
+	configMaps, err := s.client.ListConfigMaps(namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	return k8s.SummarizeConfigMaps(configMaps), "", nil
+}
+
+// GetConfigMap finds a configmap in the list of its namespace, as the gRPC
+// API has no RPC to get one
+func (s *GRPCSource) GetConfigMap(namespace, name string) (*v1.ConfigMap, error) {
+	configMaps, err := s.client.ListConfigMaps(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range configMaps {
+		if configMaps[i].Name == name {
+			return &configMaps[i], nil
+		}
+	}
+	return nil, fmt.Errorf("configmap %s not found in namespace %s", name, namespace)
+}
+
+// ListNamespaces lists the namespaces with their phase; the gRPC API does not
+// send their other fields
+func (s *GRPCSource) ListNamespaces() ([]v1.Namespace, error) {
+	protoNamespaces, err := s.client.ListNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make([]v1.Namespace, 0, len(protoNamespaces))
+	for _, ns := range protoNamespaces {
+		namespaces = append(namespaces, v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: ns.Name},
+			Status:     v1.NamespaceStatus{Phase: v1.NamespacePhase(ns.Status)},
+		})
+	}
+	return namespaces, nil
+}
+
+func (s *GRPCSource) ListNodes() ([]v1.Node, error) {
+	return nil, fmt.Errorf("nodes are %w", errNotServedOverGRPC)
+}
+
+func (s *GRPCSource) ListStatefulSets(namespace string) ([]appsv1.StatefulSet, error) {
+	return nil, fmt.Errorf("statefulsets are %w", errNotServedOverGRPC)
+}
+
+func (s *GRPCSource) ListPVCs(namespace string) ([]v1.PersistentVolumeClaim, error) {
+	return nil, fmt.Errorf("persistent volume claims are %w", errNotServedOverGRPC)
+}
+
+func (s *GRPCSource) ListDaemonSets(namespace string) ([]appsv1.DaemonSet, error) {
+	return nil, fmt.Errorf("daemonsets are %w", errNotServedOverGRPC)
+}
+
+// ServerVersion returns the version of the kgo server, "unknown" when it did
+// not report one
+func (s *GRPCSource) ServerVersion() string {
+	if s.client == nil || s.client.ServerVersion() == "" {
+		return "unknown"
+	}
+	return s.client.ServerVersion()
+}
+
+// clusterOnlyKeys are the keys of operations that use the clientset directly:
+// mutations, and lookups with no gRPC equivalent yet. They are ignored, and
+// left out of help, when the data source has no clientset.
+var clusterOnlyKeys = map[rune]bool{
+	'd': true,
+	'c': true,
+	'D': true,
+	'H': true,
+	'p': true,
+	'W': true,
+	'o': true,
+	'P': true,
+	'L': true,
+	'C': true,
+	'M': true,
+	':': true,
+	'`': true,
+	'I': true,
+	'x': true,
+	'F': true,
+	'=': true,
+	'O': true,
+	'X': true,
+}
+
+// data returns the TUI's data source, giving up on its lists after
+// ui.loadTimeoutSeconds; TUIs built without one read through their clientset
+func (t *TUI) data() DataSource {
+	var source DataSource = NewClusterSource(t.clientset)
+	if t.source != nil {
+		source = t.source
+	}
+	if timeout := t.loadTimeout(); timeout > 0 {
+		return &timeoutSource{source: source, timeout: timeout}
+	}
+	return source
+}
+
+// hasClientset reports whether operations beyond loading resources are
+// available
+func (t *TUI) hasClientset() bool {
+	return t.clientset != nil
+}
+
+// keyAvailable reports whether the operation on a key is available with the
+// TUI's data source
+func (t *TUI) keyAvailable(key rune) bool {
+	return t.hasClientset() || !clusterOnlyKeys[key]
+}
+
+// availableHelpLines drops the help lines of keys that are not available
+func (t *TUI) availableHelpLines(lines []string) []string {
+	if t.hasClientset() {
+		return lines
+	}
+	available := make([]string, 0, len(lines))
+	for _, line := range lines {
+		key, isKeyLine := strings.CutPrefix(line, "   ")
+		if isKeyLine && key != "" && !t.keyAvailable([]rune(key)[0]) {
+			continue
+		}
+		available = append(available, line)
+	}
+	return available
+}
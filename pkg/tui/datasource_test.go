@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	kgogrpc "k8s-dashboard/pkg/grpc"
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/version"
+	"k8s-dashboard/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newBufconnSource serves the gRPC API over a fake clientset holding objects
+// on an in-memory listener, and returns a data source reading from it
+func newBufconnSource(t *testing.T, objects ...runtime.Object) *GRPCSource {
+	guard, err := k8s.NewNamespaceGuard(nil)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	proto.RegisterK8SServiceServer(server, kgogrpc.NewServer(fake.NewSimpleClientset(objects...), guard))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial bufconn: %v", err)
+	}
+	client := kgogrpc.NewClientFromConn(conn)
+	t.Cleanup(func() { client.Close() })
+	return NewGRPCSource(client)
+}
+
+// TestTUIGRPCDataSource runs the TUI's data loads against a gRPC server
+func TestTUIGRPCDataSource(t *testing.T) {
+	replicas := int32(2)
+	source := newBufconnSource(t,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}, Status: v1.NamespaceStatus{Phase: v1.NamespaceActive}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "shop"}, Data: map[string]string{"mode": "fast"}},
+	)
+
+	tui := &TUI{source: source, namespace: "shop", dataChan: make(chan *DataUpdate, 10)}
+	if tui.hasClientset() {
+		t.Fatal("Expected no clientset on a gRPC data source")
+	}
+
+	for _, rt := range []ResourceType{ResourcePods, ResourceDeployments, ResourceServices, ResourceConfigMaps, ResourceNamespaces} {
+		tui.loadAsync(tui.newLoad(rt, false))
+		update := <-tui.dataChan
+		if update.Error != nil {
+			t.Fatalf("Loading %v failed: %v", rt, update.Error)
+		}
+		tui.handleDataUpdate(update)
+	}
+	if len(tui.pods) != 1 || tui.pods[0].Name != "web-1" || tui.pods[0].Status.Phase != v1.PodRunning {
+		t.Errorf("Expected pod web-1 of namespace shop, got %+v", tui.pods)
+	}
+	if len(tui.deployments) != 1 || *tui.deployments[0].Spec.Replicas != 2 {
+		t.Errorf("Expected deployment web with 2 replicas, got %+v", tui.deployments)
+	}
+	if len(tui.services) != 1 || tui.services[0].Name != "web" {
+		t.Errorf("Expected service web, got %+v", tui.services)
+	}
+	if len(tui.configMaps) != 1 || tui.configMaps[0].Name != "settings" || len(tui.configMaps[0].Keys) != 1 {
+		t.Errorf("Expected configmap settings with one key, got %+v", tui.configMaps)
+	}
+	if len(tui.namespaces) != 1 || tui.namespaces[0].Status.Phase != v1.NamespaceActive {
+		t.Errorf("Expected active namespace shop, got %+v", tui.namespaces)
+	}
+
+	// Nodes, statefulsets, PVCs and daemonsets have no RPC, so their tabs
+	// stay empty
+	for _, rt := range []ResourceType{ResourceNodes, ResourceStatefulSets, ResourcePVCs, ResourceDaemonSets} {
+		tui.loadAsync(tui.newLoad(rt, false))
+		if update := <-tui.dataChan; !errors.Is(update.Error, errNotServedOverGRPC) {
+			t.Errorf("Expected loading %v over gRPC to fail with %v, got %v", rt, errNotServedOverGRPC, update.Error)
+		}
+	}
+
+	values := tui.configMapValuesFor(tui.configMaps[0])
+	if values.err != nil || values.configMap.Data["mode"] != "fast" {
+		t.Errorf("Expected the configmap's values, got %+v", values)
+	}
+}
+
+// TestTUIGRPCStatusBarShowsServerVersion checks that a thin client names the
+// version of its server
+func TestTUIGRPCStatusBarShowsServerVersion(t *testing.T) {
+	screen := newTestScreen(t, 160, 5)
+
+	statusBar := func() string {
+		screen.Show()
+		cells, width, _ := screen.GetContents()
+		var text strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[x].Runes; len(runes) > 0 {
+				text.WriteRune(runes[0])
+			}
+		}
+		return text.String()
+	}
+
+	source := newBufconnSource(t)
+	tui := &TUI{source: source, screen: screen, namespace: "shop"}
+	tui.drawStatusBar(160, 0)
+	if line := statusBar(); !strings.Contains(line, "server unknown") {
+		t.Errorf("Expected an unknown server version before the check, got %q", line)
+	}
+
+	if err := source.client.CheckVersion(context.Background()); err != nil {
+		t.Fatalf("CheckVersion failed: %v", err)
+	}
+	tui.drawStatusBar(160, 0)
+	if line := statusBar(); !strings.Contains(line, "server "+version.Version) {
+		t.Errorf("Expected server version %s in the status bar, got %q", version.Version, line)
+	}
+}
+
+// TestTUIGRPCHidesClusterOperations checks that operations using the
+// clientset are ignored and left out of help without one
+func TestTUIGRPCHidesClusterOperations(t *testing.T) {
+	tui := &TUI{source: NewGRPCSource(nil)}
+	if tui.keyAvailable('d') || tui.keyAvailable('P') || tui.keyAvailable('X') || !tui.keyAvailable('n') || !tui.keyAvailable('/') {
+		t.Error("Expected only the keys that do not need the clientset to be available")
+	}
+
+	lines := tui.availableHelpLines([]string{
+		" Actions:",
+		"   d           Delete selected resource",
+		"   n           Change namespace",
+		"   `, F1       Cluster overview",
+	})
+	if got := strings.Join(lines, "\n"); got != " Actions:\n   n           Change namespace" {
+		t.Errorf("Expected the delete and overview lines to be hidden, got\n%s", got)
+	}
+
+	direct := &TUI{clientset: fake.NewSimpleClientset()}
+	if !direct.keyAvailable('d') || len(direct.availableHelpLines([]string{"   d  Delete"})) != 1 {
+		t.Error("Expected every key to be available with a clientset")
+	}
+}
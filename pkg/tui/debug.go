@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// debugStats measures the main loop for the debug overlay toggled with F12
+type debugStats struct {
+	show bool
+
+	// frameTime is how long the last frame took to draw and show
+	frameTime time.Duration
+	frames    int64
+
+	// eventsPerSec is the rate of redraw requests from background updates,
+	// sampled about once a second from the coalescer's event count
+	eventsPerSec float64
+	sampleEvents int64
+	sampleAt     time.Time
+}
+
+// recordFrame records a frame's draw time and samples the event rate from the
+// total number of events so far
+func (d *debugStats) recordFrame(frameTime time.Duration, events int64, now time.Time) {
+	d.frameTime = frameTime
+	d.frames++
+
+	if d.sampleAt.IsZero() {
+		d.sampleEvents, d.sampleAt = events, now
+		return
+	}
+	if elapsed := now.Sub(d.sampleAt); elapsed >= time.Second {
+		d.eventsPerSec = float64(events-d.sampleEvents) / elapsed.Seconds()
+		d.sampleEvents, d.sampleAt = events, now
+	}
+}
+
+// lines returns the overlay's text
+func (d *debugStats) lines() []string {
+	return []string{
+		fmt.Sprintf("Frame time  %v", d.frameTime.Round(time.Microsecond)),
+		fmt.Sprintf("Frames      %d", d.frames),
+		fmt.Sprintf("Events/sec  %.1f", d.eventsPerSec),
+		fmt.Sprintf("Goroutines  %d", runtime.NumGoroutine()),
+	}
+}
+
+// redrawEvents returns how many background updates have requested a redraw
+func (t *TUI) redrawEvents() int64 {
+	if t.redraws == nil {
+		return 0
+	}
+	return t.redraws.Events()
+}
+
+// requestRedraw schedules a redraw after a background update, coalesced with
+// other updates when a coalescer is running
+func (t *TUI) requestRedraw() {
+	if t.redraws != nil {
+		t.redraws.MarkDirty()
+		return
+	}
+	if t.screen != nil {
+		t.screen.PostEvent(tcell.NewEventInterrupt(nil))
+	}
+}
+
+// drawDebugOverlay draws the debug stats in the top right corner
+func (t *TUI) drawDebugOverlay(width, height int) {
+	lines := t.debug.lines()
+
+	boxWidth := 28
+	if boxWidth > width {
+		boxWidth = width
+	}
+	boxHeight := len(lines) + 2
+	if boxHeight > height {
+		return
+	}
+	x := width - boxWidth
+
+	boxStyle := tcell.StyleDefault.Background(t.theme.background).Foreground(tcell.ColorYellow)
+	for row := 0; row < boxHeight; row++ {
+		border := "│" + strings.Repeat(" ", boxWidth-2) + "│"
+		switch row {
+		case 0:
+			border = "┌" + strings.Repeat("─", boxWidth-2) + "┐"
+		case boxHeight - 1:
+			border = "└" + strings.Repeat("─", boxWidth-2) + "┘"
+		}
+		t.drawText(x, row, boxWidth, border, boxStyle)
+	}
+	t.drawText(x+2, 0, boxWidth-4, " Debug (F12) ", boxStyle.Bold(true))
+	for i, line := range lines {
+		t.drawText(x+2, i+1, boxWidth-4, line, boxStyle)
+	}
+}
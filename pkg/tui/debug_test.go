@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTUIDebugOverlay tests the debug overlay's stats and drawing
+func TestTUIDebugOverlay(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+
+	tui := &TUI{
+		screen:      screen,
+		namespace:   "default",
+		currentView: ResourcePods,
+		theme:       DefaultTheme(),
+		redraws:     NewRedrawCoalescer(time.Hour, func() {}),
+	}
+
+	start := time.Now()
+	tui.debug.recordFrame(2*time.Millisecond, tui.redrawEvents(), start)
+	for i := 0; i < 150; i++ {
+		tui.requestRedraw()
+	}
+	tui.debug.recordFrame(3*time.Millisecond, tui.redrawEvents(), start.Add(500*time.Millisecond))
+	if tui.debug.eventsPerSec != 0 {
+		t.Errorf("Expected the event rate to be sampled once a second, got %.1f", tui.debug.eventsPerSec)
+	}
+	tui.debug.recordFrame(4*time.Millisecond, tui.redrawEvents(), start.Add(1500*time.Millisecond))
+	if tui.debug.eventsPerSec != 100 {
+		t.Errorf("Expected 150 events in 1.5s to be 100 events/sec, got %.1f", tui.debug.eventsPerSec)
+	}
+
+	tui.draw()
+	screen.Show()
+	if strings.Contains(screenText(screen), "Debug (F12)") {
+		t.Error("Expected no debug overlay until F12 is pressed")
+	}
+
+	tui.debug.show = true
+	tui.draw()
+	screen.Show()
+	text := screenText(screen)
+	for _, want := range []string{"Debug (F12)", "Frame time  4ms", "Frames      3", "Events/sec  100.0", "Goroutines"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the overlay, got:\n%s", want, text)
+		}
+	}
+}
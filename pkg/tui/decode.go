@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// base64Pattern matches values that may be base64 encoded
+var base64Pattern = regexp.MustCompile(`^[A-Za-z0-9+/]+=*$`)
+
+// decodedMarker and binaryMarker prefix the values the YAML view decoded
+const (
+	decodedMarker = "[decoded]"
+	binaryMarker  = "[decoded, binary]"
+)
+
+// hexSnippetBytes is how many bytes of a binary value are shown in hex
+const hexSnippetBytes = 16
+
+// looksBase64 reports whether a value matches base64Pattern and decodes as
+// padded base64. Plain words of four letters pass too, so decoded values are
+// marked rather than trusted.
+func looksBase64(value string) bool {
+	if len(value)%4 != 0 || !base64Pattern.MatchString(value) {
+		return false
+	}
+	_, err := base64.StdEncoding.DecodeString(value)
+	return err == nil
+}
+
+// isPrintable reports whether decoded bytes are text that can be shown as is
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\t' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// hexSnippet shows the first hexSnippetBytes of binary data in hex, with its
+// size when it is cut
+func hexSnippet(data []byte) string {
+	if len(data) <= hexSnippetBytes {
+		return fmt.Sprintf("% x", data)
+	}
+	return fmt.Sprintf("% x ... (%s)", data[:hexSnippetBytes], formatSize(len(data)))
+}
+
+// decodeValue decodes a value that looks like base64 for display, marked with
+// decodedMarker, or with binaryMarker and a hex snippet when it is not text.
+// It returns false for values that do not look like base64.
+func decodeValue(value string) (string, bool) {
+	if !looksBase64(value) {
+		return "", false
+	}
+	data, _ := base64.StdEncoding.DecodeString(value)
+	if !isPrintable(data) {
+		return binaryMarker + " " + hexSnippet(data), true
+	}
+	return decodedMarker + " " + string(data), true
+}
+
+// decodedConfigMap returns a configmap as the YAML view shows it with values
+// decoded: binaryData, which is base64 in JSON, and the data values that look
+// like base64. Data values are text, so those that would decode to binary are
+// words like "true" that happen to look like base64, and are kept.
+func decodedConfigMap(configMap *v1.ConfigMap) (map[string]interface{}, error) {
+	data, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"data", "binaryData"} {
+		values, _ := object[field].(map[string]interface{})
+		for key, value := range values {
+			if text, ok := value.(string); ok {
+				decoded, ok := decodeValue(text)
+				if ok && (field == "binaryData" || !strings.HasPrefix(decoded, binaryMarker)) {
+					values[key] = decoded
+				}
+			}
+		}
+	}
+	return object, nil
+}
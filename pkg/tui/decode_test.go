@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLooksBase64(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{base64.StdEncoding.EncodeToString([]byte("hello world")), true},
+		{"aGVsbG8=", true},
+		{"YWJj", true},
+		{"with space", false},
+		{"https://example.com", false},
+		{"aGVsbG8", false},  // not padded to a multiple of four
+		{"a=b=", false},     // padding in the middle
+		{"abc-_def", false}, // URL alphabet
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := looksBase64(tt.value); got != tt.want {
+			t.Errorf("looksBase64(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	if got, ok := decodeValue(base64.StdEncoding.EncodeToString([]byte("user=admin\n"))); !ok || got != "[decoded] user=admin\n" {
+		t.Errorf("Expected a decoded text value, got %q, %v", got, ok)
+	}
+
+	// Binary content falls back to a hex snippet
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	if got, ok := decodeValue(base64.StdEncoding.EncodeToString(png)); !ok || got != "[decoded, binary] 89 50 4e 47 0d 0a 1a 0a" {
+		t.Errorf("Expected a hex snippet, got %q, %v", got, ok)
+	}
+	large := []byte(strings.Repeat("\xab", 100))
+	got, _ := decodeValue(base64.StdEncoding.EncodeToString(large))
+	if !strings.HasPrefix(got, "[decoded, binary] ab ab") || !strings.HasSuffix(got, "... (100B)") || strings.Count(got, "ab") != hexSnippetBytes {
+		t.Errorf("Expected the first %d bytes and the size, got %q", hexSnippetBytes, got)
+	}
+
+	if _, ok := decodeValue("plain text"); ok {
+		t.Error("Expected plain text not to be decoded")
+	}
+}
+
+func TestTUIConfigMapYAMLDecoded(t *testing.T) {
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "certs", Namespace: "shop", ResourceVersion: "7"},
+		Data: map[string]string{
+			"token": base64.StdEncoding.EncodeToString([]byte("s3cret")),
+			"mode":  "fast",
+		},
+		BinaryData: map[string][]byte{"key.der": {0x30, 0x82, 0x01, 0x0a}},
+	}
+	summary := k8s.SummarizeConfigMap(configMap)
+	tui := &TUI{configMapValues: &configMapValues{namespace: "shop", name: "certs", resourceVersion: "7", configMap: configMap}}
+
+	if yaml := tui.getResourceYAML(summary); strings.Contains(yaml, "[decoded") {
+		t.Errorf("Expected encoded values before toggling, got\n%s", yaml)
+	}
+
+	tui.decodeValues = true
+	yaml := tui.getResourceYAML(summary)
+	for _, want := range []string{`"token": "[decoded] s3cret"`, `"mode": "fast"`, `"key.der": "[decoded, binary] 30 82 01 0a"`} {
+		if !strings.Contains(yaml, want) {
+			t.Errorf("Expected %s in\n%s", want, yaml)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// deletionSafetyTimeout bounds the safety check before a delete
+const deletionSafetyTimeout = 5 * time.Second
+
+// deletionWarningStyle draws the warnings of a delete confirmation
+var deletionWarningStyle = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+
+// deletionWarnings returns what deleting an object of the current namespace
+// will do besides removing it, e.g. that its ReplicaSet recreates it. A
+// failed check warns about nothing, and the delete reports its own errors.
+func (t *TUI) deletionWarnings(resourceType, name string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), deletionSafetyTimeout)
+	defer cancel()
+	safety, err := k8s.GetDeletionSafety(ctx, t.clientset, t.namespace, resourceType+"s", name)
+	if err != nil {
+		klog.Warningf("Failed to check the deletion safety of %s %s: %v", resourceType, name, err)
+		return nil
+	}
+	return safety.Warnings()
+}
+
+// drawDeletionProtectedBadge draws a lock after the name of a namespace with
+// k8s.DeletionProtectedAnnotation, over the padding of its cell
+func (t *TUI) drawDeletionProtectedBadge(ns v1.Namespace, y int, layout tableLayout, style tcell.Style) {
+	x, width, ok := layout.cell(0)
+	if !ok || !k8s.IsDeletionProtected(&ns) {
+		return
+	}
+	// The lock is two cells wide
+	offset := len(ns.Name) + 1
+	if offset+2 > width {
+		return
+	}
+	t.drawText(x+offset, y, 1, lockGlyph, style)
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestTUIDeleteWarnings tests that delete confirmations warn about pods
+// their controller recreates and finalizers that hold objects
+func TestTUIDeleteWarnings(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+
+	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system", UID: "ds-uid"}}
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "agent-x", Namespace: "kube-system", Finalizers: []string{"example.com/drain"},
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))},
+	}}
+	guard, err := k8s.NewNamespaceGuard([]string{"kube-system"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	clientset := fake.NewSimpleClientset(ds, &pod)
+	tui := &TUI{clientset: clientset, screen: screen, namespace: "kube-system", currentView: ResourcePods, pods: []v1.Pod{pod}}
+
+	for _, protected := range []bool{false, true} {
+		tui.guard = nil
+		if protected {
+			tui.guard = guard
+		}
+		screen.Clear()
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+		tui.deleteSelectedResource()
+
+		text := screenText(screen)
+		for _, want := range []string{"⚠ It will be recreated by DaemonSet/agent.", "⚠ Deletion is blocked by finalizers example.com/drain."} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Protected %v: expected %q on screen, got:\n%s", protected, want, text)
+			}
+		}
+	}
+	if _, err := clientset.CoreV1().Pods("kube-system").Get(context.TODO(), "agent-x", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected the cancelled delete to leave the pod, got %v", err)
+	}
+}
+
+// TestTUIDeleteProtectedNamespace tests that deleting a deletion-protected
+// namespace needs its name typed, and that it is marked in the list
+func TestTUIDeleteProtectedNamespace(t *testing.T) {
+	payments := v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "payments",
+		Annotations: map[string]string{k8s.DeletionProtectedAnnotation: "true"},
+	}}
+	scratch := v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "scratch"}}
+	tui, screen := newTestTUI(t, 120, 30, &payments, &scratch)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceNamespaces
+	tui.namespaces = []v1.Namespace{payments, scratch}
+	exists := func(name string) bool {
+		_, err := clientset.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+		return err == nil
+	}
+
+	tui.drawResourceTable(120, 20, 5)
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	var row strings.Builder
+	for x := 0; x < width; x++ {
+		row.WriteString(string(cells[8*width+x].Runes))
+	}
+	if !strings.Contains(row.String(), "payments "+lockGlyph) {
+		t.Errorf("Expected a lock after payments, got %q", row.String())
+	}
+
+	// Others are confirmed with y
+	tui.selected = 1
+	screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone)
+	tui.deleteSelectedResource()
+	if exists("scratch") {
+		t.Error("Expected y to delete an unprotected namespace")
+	}
+	for tui.loading {
+		tui.handleDataUpdate(<-tui.dataChan)
+	}
+
+	// but do not for a protected namespace, whose name must be typed
+	tui.namespaces = []v1.Namespace{payments}
+	tui.selected = 0
+	screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tui.deleteSelectedResource()
+	if !exists("payments") {
+		t.Fatal("Expected y to leave the protected namespace")
+	}
+
+	for _, r := range "payments" {
+		screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tui.deleteSelectedResource()
+	if exists("payments") {
+		t.Error("Expected typing its name to delete the protected namespace")
+	}
+	if want := "kubectl delete namespace payments"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+}
@@ -0,0 +1,477 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// minSideBySideWidth is the narrowest terminal that fits the two halves of
+// the side-by-side diff
+const minSideBySideWidth = 120
+
+// diffLineKind is what a line of a unified diff is
+type diffLineKind int
+
+const (
+	diffContext diffLineKind = iota
+	diffAdded
+	diffRemoved
+	// diffFileHeader is a ---, +++, diff or index line before the hunks
+	diffFileHeader
+	diffHunkHeader
+	// diffNote is any other text, e.g. "\ No newline at end of file" or a
+	// message shown in place of a diff
+	diffNote
+)
+
+// diffLine is a parsed line of a unified diff. oldLine and newLine are its
+// line numbers on either side, 0 where it is not on that side.
+type diffLine struct {
+	kind             diffLineKind
+	text             string
+	oldLine, newLine int
+}
+
+// parseUnifiedDiff parses a unified diff into its lines. The hunk headers
+// count the lines of each hunk, so a removed line starting with "--" is not
+// taken for a file header.
+func parseUnifiedDiff(diff string) []diffLine {
+	diff = strings.TrimSuffix(diff, "\n")
+	if diff == "" {
+		return nil
+	}
+
+	var lines []diffLine
+	oldLine, newLine := 0, 0
+	oldLeft, newLeft := 0, 0
+	for _, text := range strings.Split(diff, "\n") {
+		line := diffLine{kind: diffNote, text: text}
+		inHunk := oldLeft > 0 || newLeft > 0
+		switch {
+		case strings.HasPrefix(text, "@@"):
+			if oldStart, oldCount, newStart, newCount, ok := parseHunkHeader(text); ok {
+				line.kind = diffHunkHeader
+				oldLine, oldLeft = oldStart, oldCount
+				newLine, newLeft = newStart, newCount
+			}
+		case inHunk && strings.HasPrefix(text, "+"):
+			line.kind, line.newLine = diffAdded, newLine
+			newLine++
+			newLeft--
+		case inHunk && strings.HasPrefix(text, "-"):
+			line.kind, line.oldLine = diffRemoved, oldLine
+			oldLine++
+			oldLeft--
+		case inHunk && strings.HasPrefix(text, "\\"):
+			// "\ No newline at end of file" belongs to the line above it
+		case inHunk:
+			// Some tools trim the space of empty context lines
+			line.kind, line.oldLine, line.newLine = diffContext, oldLine, newLine
+			oldLine++
+			newLine++
+			oldLeft--
+			newLeft--
+		case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "),
+			strings.HasPrefix(text, "diff "), strings.HasPrefix(text, "index "):
+			line.kind = diffFileHeader
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseHunkHeader parses a hunk header such as "@@ -12,3 +12,4 @@ spec:",
+// where a count left out is 1
+func parseHunkHeader(header string) (oldStart, oldCount, newStart, newCount int, ok bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" {
+		return 0, 0, 0, 0, false
+	}
+	oldRange, oldOK := strings.CutPrefix(fields[1], "-")
+	newRange, newOK := strings.CutPrefix(fields[2], "+")
+	if !oldOK || !newOK {
+		return 0, 0, 0, 0, false
+	}
+	oldStart, oldCount, oldOK = parseHunkRange(oldRange)
+	newStart, newCount, newOK = parseHunkRange(newRange)
+	return oldStart, oldCount, newStart, newCount, oldOK && newOK
+}
+
+func parseHunkRange(hunkRange string) (start, count int, ok bool) {
+	startText, countText, hasCount := strings.Cut(hunkRange, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// diffRow is a row of the side-by-side diff: the old line on the left and
+// the new one on the right, either of which may be missing, or a header or
+// note across both halves
+type diffRow struct {
+	left, right *diffLine
+	full        *diffLine
+}
+
+// sideBySideRows lays out diff lines side by side. Context lines are on
+// both sides, and a run of removed lines is paired with the added lines
+// following it, so that a changed line is next to what replaced it.
+func sideBySideRows(lines []diffLine) []diffRow {
+	var rows []diffRow
+	for i := 0; i < len(lines); {
+		line := &lines[i]
+		switch line.kind {
+		case diffContext:
+			rows = append(rows, diffRow{left: line, right: line})
+			i++
+		case diffRemoved, diffAdded:
+			var removed, added []*diffLine
+			for ; i < len(lines) && lines[i].kind == diffRemoved; i++ {
+				removed = append(removed, &lines[i])
+			}
+			for ; i < len(lines) && lines[i].kind == diffAdded; i++ {
+				added = append(added, &lines[i])
+			}
+			for j := 0; j < max(len(removed), len(added)); j++ {
+				var row diffRow
+				if j < len(removed) {
+					row.left = removed[j]
+				}
+				if j < len(added) {
+					row.right = added[j]
+				}
+				rows = append(rows, row)
+			}
+		default:
+			rows = append(rows, diffRow{full: line})
+			i++
+		}
+	}
+	return rows
+}
+
+// diffViewer is the state of ViewModeDiff: a unified diff shown in color,
+// scrolled, searched and optionally side by side. A diff previewing a change
+// has a confirm action run by Enter; ESC returns to the back view mode.
+type diffViewer struct {
+	title string
+	lines []diffLine
+	rows  []diffRow
+
+	scroll     int
+	sideBySide bool
+	// query is the search typed after '/', and match the row of the current
+	// match, -1 before n finds one
+	query  string
+	match  int
+	status string
+
+	back         ViewMode
+	confirmLabel string
+	confirm      func()
+}
+
+// newDiffViewer parses a unified diff for the diff view. Text that is not a
+// diff, such as a message explaining why there is none, is shown as it is.
+func newDiffViewer(title, diff string, back ViewMode) *diffViewer {
+	lines := parseUnifiedDiff(diff)
+	return &diffViewer{title: title, lines: lines, rows: sideBySideRows(lines), match: -1, back: back}
+}
+
+// split reports whether the diff is shown side by side at a terminal width
+func (v *diffViewer) split(width int) bool {
+	return v.sideBySide && width >= minSideBySideWidth
+}
+
+// rowCount returns the number of rows of the diff as it is shown
+func (v *diffViewer) rowCount(split bool) int {
+	if split {
+		return len(v.rows)
+	}
+	return len(v.lines)
+}
+
+// rowText returns the text of a row that search looks through
+func (v *diffViewer) rowText(split bool, row int) string {
+	if !split {
+		return v.lines[row].text
+	}
+	r := v.rows[row]
+	if r.full != nil {
+		return r.full.text
+	}
+	var texts []string
+	for _, line := range []*diffLine{r.left, r.right} {
+		if line != nil {
+			texts = append(texts, line.text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// findMatch moves to the next row matching the query in direction, 1 or -1,
+// wrapping around, and scrolls it into view
+func (v *diffViewer) findMatch(split bool, direction int) {
+	if v.query == "" {
+		return
+	}
+	count := v.rowCount(split)
+	if count == 0 {
+		return
+	}
+	start := v.match
+	if start < 0 {
+		start = v.scroll - direction
+	}
+	for i := 1; i <= count; i++ {
+		row := ((start+direction*i)%count + count) % count
+		if matchIndex(v.rowText(split, row), v.query) >= 0 {
+			v.match, v.scroll, v.status = row, row, ""
+			return
+		}
+	}
+	v.match = -1
+	v.status = fmt.Sprintf("No match for %q", v.query)
+}
+
+// matchIndex returns the rune index of the first case-insensitive match of
+// query in text, or -1
+func matchIndex(text, query string) int {
+	runes, want := []rune(text), []rune(query)
+	for i := 0; i+len(want) <= len(runes); i++ {
+		found := true
+		for j, r := range want {
+			if unicode.ToLower(runes[i+j]) != unicode.ToLower(r) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+// openDiffViewer shows a diff in the diff view, returning to back on ESC
+func (t *TUI) openDiffViewer(viewer *diffViewer) {
+	t.diffView = viewer
+	t.viewMode = ViewModeDiff
+}
+
+// closeDiffViewer returns from the diff view to the view it was opened from
+func (t *TUI) closeDiffViewer() {
+	back := ViewModeList
+	if t.diffView != nil {
+		back = t.diffView.back
+	}
+	t.diffView = nil
+	t.viewMode = back
+}
+
+// handleDiffKey handles the keys of the diff view: scrolling, '/' to search
+// and n/N to go through the matches, S to switch to side by side, and Enter
+// to confirm a previewed change
+func (t *TUI) handleDiffKey(ev *tcell.EventKey) bool {
+	viewer := t.diffView
+	if viewer == nil {
+		t.closeDiffViewer()
+		return true
+	}
+	width, height := t.screen.Size()
+	split := viewer.split(width)
+	page := max(height-4, 1)
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		t.closeDiffViewer()
+		return true
+	case tcell.KeyEnter:
+		if viewer.confirm == nil {
+			return false
+		}
+		t.closeDiffViewer()
+		viewer.confirm()
+		return true
+	case tcell.KeyUp:
+		viewer.scroll = max(viewer.scroll-1, 0)
+		return true
+	case tcell.KeyDown:
+		viewer.scroll++
+		return true
+	case tcell.KeyPgUp:
+		viewer.scroll = max(viewer.scroll-page, 0)
+		return true
+	case tcell.KeyPgDn:
+		viewer.scroll += page
+		return true
+	case tcell.KeyRune:
+	default:
+		return false
+	}
+
+	switch ev.Rune() {
+	case '/':
+		if query, ok := t.promptDiffSearch(viewer.query); ok {
+			viewer.query, viewer.match = query, -1
+			viewer.findMatch(split, 1)
+		}
+	case 'n':
+		viewer.findMatch(split, 1)
+	case 'N':
+		viewer.findMatch(split, -1)
+	case 'S':
+		if !viewer.sideBySide && width < minSideBySideWidth {
+			viewer.status = fmt.Sprintf("Side by side needs a terminal at least %d columns wide", minSideBySideWidth)
+			return true
+		}
+		viewer.sideBySide = !viewer.sideBySide
+		viewer.scroll, viewer.match, viewer.status = 0, -1, ""
+	default:
+		return false
+	}
+	return true
+}
+
+// promptDiffSearch reads a search query on the bottom line, and reports
+// whether one was entered
+func (t *TUI) promptDiffSearch(current string) (string, bool) {
+	input := current
+	for {
+		t.draw()
+		width, height := t.screen.Size()
+		prompt := "Search diff (Enter finds, Esc cancels): " + input + "_"
+		if len(prompt) < width {
+			prompt += strings.Repeat(" ", width-len(prompt))
+		}
+		t.drawText(0, height-1, width, prompt, tcell.StyleDefault.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyEnter:
+			return input, input != ""
+		case tcell.KeyEscape:
+			return "", false
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if runes := []rune(input); len(runes) > 0 {
+				input = string(runes[:len(runes)-1])
+			}
+		case tcell.KeyRune:
+			input += string(ev.Rune())
+		}
+	}
+}
+
+// diffLineStyle colors a diff line by its kind: additions green, removals
+// red and hunk headers in the accent color of the theme
+func (t *TUI) diffLineStyle(kind diffLineKind) tcell.Style {
+	switch kind {
+	case diffFileHeader:
+		return tcell.StyleDefault.Bold(true)
+	case diffHunkHeader:
+		return tcell.StyleDefault.Foreground(t.theme.accent)
+	case diffAdded:
+		return tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	case diffRemoved:
+		return tcell.StyleDefault.Foreground(tcell.ColorRed)
+	}
+	return tcell.StyleDefault
+}
+
+// drawDiffView draws the diff of the diff view, unified or side by side
+func (t *TUI) drawDiffView(width, height int) {
+	viewer := t.diffView
+	if viewer == nil {
+		t.drawText(0, 0, width, "No diff to show", tcell.StyleDefault)
+		return
+	}
+
+	header := fmt.Sprintf(" 🔀 %s ", viewer.title)
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	split := viewer.split(width)
+	rows := viewer.rowCount(split)
+	viewer.scroll = clampScroll(viewer.scroll, rows, height-4)
+
+	y := 2
+	for i := viewer.scroll; i < rows && y < height-2; i++ {
+		if !split {
+			t.drawDiffLine(0, y, width, &viewer.lines[i], 0, viewer.query)
+			y++
+			continue
+		}
+		row := viewer.rows[i]
+		if row.full != nil {
+			t.drawDiffLine(0, y, width, row.full, 0, viewer.query)
+			y++
+			continue
+		}
+		half := (width - 1) / 2
+		if row.left != nil {
+			t.drawDiffLine(0, y, half, row.left, row.left.oldLine, viewer.query)
+		}
+		t.screen.SetContent(half, y, '│', nil, tcell.StyleDefault.Foreground(t.theme.accent))
+		if row.right != nil {
+			t.drawDiffLine(half+1, y, width-half-1, row.right, row.right.newLine, viewer.query)
+		}
+		y++
+	}
+
+	status := viewer.status
+	if status == "" && viewer.query != "" {
+		status = fmt.Sprintf("Search: %s", viewer.query)
+	}
+	t.drawText(1, height-2, width-2, status, tcell.StyleDefault.Foreground(t.theme.foreground))
+
+	footer := " ESC Back │ ↑↓ Scroll │ / Search │ n/N Next/Previous match │ S Side by side "
+	if viewer.confirm != nil {
+		footer = fmt.Sprintf(" Enter %s │ ESC Cancel │ ↑↓ Scroll │ / Search │ n/N Next/Previous match │ S Side by side ", viewer.confirmLabel)
+	}
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
+
+// drawDiffLine draws a diff line within width columns, after its line number
+// on the halves of the side-by-side diff, with the matches of query in
+// reverse video
+func (t *TUI) drawDiffLine(x, y, width int, line *diffLine, number int, query string) {
+	style := t.diffLineStyle(line.kind)
+	text := line.text
+	if number > 0 {
+		text = fmt.Sprintf("%4d %s", number, text)
+	}
+	runes := []rune(text)
+	if len(runes) > width && width > 3 {
+		runes = append(runes[:width-3], []rune("...")...)
+	}
+	t.drawText(x, y, width, string(runes), style)
+
+	if query == "" {
+		return
+	}
+	for start := 0; start < len(runes); {
+		index := matchIndex(string(runes[start:]), query)
+		if index < 0 {
+			break
+		}
+		from := start + index
+		for i := from; i < from+len([]rune(query)) && i < width; i++ {
+			t.screen.SetContent(x+i, y, runes[i], nil, style.Reverse(true))
+		}
+		start = from + len([]rune(query))
+	}
+}
@@ -0,0 +1,324 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	type want struct {
+		kind             diffLineKind
+		oldLine, newLine int
+	}
+	tests := []struct {
+		name string
+		diff string
+		want []want
+	}{
+		{
+			name: "single hunk",
+			diff: "--- a/app.conf\n+++ b/app.conf\n@@ -1,3 +1,3 @@\n host = 0.0.0.0\n-port = 80\n+port = 8080\n debug = false\n",
+			want: []want{
+				{diffFileHeader, 0, 0}, {diffFileHeader, 0, 0}, {diffHunkHeader, 0, 0},
+				{diffContext, 1, 1}, {diffRemoved, 2, 0}, {diffAdded, 0, 2}, {diffContext, 3, 3},
+			},
+		},
+		{
+			// Counts left out are 1, and a removed "--" line is no file header
+			name: "default counts",
+			diff: "@@ -4 +4 @@ spec:\n---\n+++\n",
+			want: []want{{diffHunkHeader, 0, 0}, {diffRemoved, 4, 0}, {diffAdded, 0, 4}},
+		},
+		{
+			name: "new file",
+			diff: "--- /dev/null\n+++ b/new.yaml\n@@ -0,0 +1,2 @@\n+a: 1\n+b: 2\n\\ No newline at end of file\n",
+			want: []want{
+				{diffFileHeader, 0, 0}, {diffFileHeader, 0, 0}, {diffHunkHeader, 0, 0},
+				{diffAdded, 0, 1}, {diffAdded, 0, 2}, {diffNote, 0, 0},
+			},
+		},
+		{
+			// Context lines trimmed to nothing, two hunks and two files
+			name: "git diff",
+			diff: "diff --git a/x b/x\nindex 1a2b..3c4d 100644\n--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n-one\n+uno\n\n@@ -10,1 +10,2 @@\n ten\n+eleven\ndiff --git a/y b/y\n",
+			want: []want{
+				{diffFileHeader, 0, 0}, {diffFileHeader, 0, 0}, {diffFileHeader, 0, 0}, {diffFileHeader, 0, 0},
+				{diffHunkHeader, 0, 0}, {diffRemoved, 1, 0}, {diffAdded, 0, 1}, {diffContext, 2, 2},
+				{diffHunkHeader, 0, 0}, {diffContext, 10, 10}, {diffAdded, 0, 11},
+				{diffFileHeader, 0, 0},
+			},
+		},
+		{
+			name: "not a diff",
+			diff: "Pod template is unchanged since the previous ReplicaSet.",
+			want: []want{{diffNote, 0, 0}},
+		},
+		{
+			name: "malformed hunk header",
+			diff: "@@ -x +1 @@\n+a\n",
+			want: []want{{diffNote, 0, 0}, {diffNote, 0, 0}},
+		},
+		{
+			name: "empty",
+			diff: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := parseUnifiedDiff(tt.diff)
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Expected %d lines, got %+v", len(tt.want), lines)
+			}
+			for i, line := range lines {
+				got := want{line.kind, line.oldLine, line.newLine}
+				if got != tt.want[i] {
+					t.Errorf("Line %d %q: expected %+v, got %+v", i, line.text, tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestSideBySideRows(t *testing.T) {
+	lines := parseUnifiedDiff("@@ -1,5 +1,3 @@\n a\n-b\n-c\n+B\n d\n-e\n")
+	rows := sideBySideRows(lines)
+
+	side := func(line *diffLine) string {
+		if line == nil {
+			return ""
+		}
+		return line.text
+	}
+	var got []string
+	for _, row := range rows {
+		if row.full != nil {
+			got = append(got, row.full.text)
+			continue
+		}
+		got = append(got, side(row.left)+"|"+side(row.right))
+	}
+	want := []string{"@@ -1,5 +1,3 @@", " a| a", "-b|+B", "-c|", " d| d", "-e|"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected rows %q, got %q", want, got)
+	}
+}
+
+// newDiffTestTUI returns a TUI on a simulation screen of width columns
+// showing diff in the diff view
+func newDiffTestTUI(t *testing.T, width int, diff string) (*TUI, tcell.SimulationScreen) {
+	t.Helper()
+	screen := newTestScreen(t, width, 20)
+
+	tui := &TUI{screen: screen, theme: DefaultTheme(), viewMode: ViewModeDetails}
+	tui.openDiffViewer(newDiffViewer("Test Diff", diff, ViewModeDetails))
+	return tui, screen
+}
+
+// screenRow returns the text of row y of the screen and the style of each
+// of its cells
+func screenRow(screen tcell.SimulationScreen, y int) (string, []tcell.Style) {
+	cells, width, _ := screen.GetContents()
+	var text strings.Builder
+	var styles []tcell.Style
+	for x := 0; x < width; x++ {
+		cell := cells[y*width+x]
+		r := ' '
+		if len(cell.Runes) > 0 {
+			r = cell.Runes[0]
+		}
+		text.WriteRune(r)
+		styles = append(styles, cell.Style)
+	}
+	return text.String(), styles
+}
+
+const testDiff = "--- app.conf\n+++ app.conf (edited)\n@@ -1,3 +1,3 @@\n host = 0.0.0.0\n-port = 80\n+port = 8080\n debug = false\n"
+
+// TestTUIDiffViewColors tests that additions are green, removals red and
+// hunk headers in the accent color of the theme
+func TestTUIDiffViewColors(t *testing.T) {
+	tui, screen := newDiffTestTUI(t, 100, testDiff)
+	tui.drawDiffView(100, 20)
+	screen.Show()
+
+	// The diff starts on row 2, under the header
+	expected := map[int]struct {
+		prefix string
+		fg     tcell.Color
+	}{
+		4: {"@@ -1,3 +1,3 @@", tui.theme.accent},
+		5: {" host = 0.0.0.0", tcell.ColorDefault},
+		6: {"-port = 80", tcell.ColorRed},
+		7: {"+port = 8080", tcell.ColorGreen},
+	}
+	for y, want := range expected {
+		text, styles := screenRow(screen, y)
+		if !strings.HasPrefix(text, want.prefix) {
+			t.Errorf("Row %d: expected %q, got %q", y, want.prefix, text)
+		}
+		if fg, _, _ := styles[0].Decompose(); fg != want.fg {
+			t.Errorf("Row %d %q: expected foreground %v, got %v", y, want.prefix, want.fg, fg)
+		}
+	}
+	if _, styles := screenRow(screen, 2); styles[0] != tcell.StyleDefault.Bold(true) {
+		t.Error("Expected file headers to be bold")
+	}
+}
+
+// TestTUIDiffViewSideBySide tests that S splits a wide terminal into the old
+// and the new lines next to each other, and refuses a narrow one
+func TestTUIDiffViewSideBySide(t *testing.T) {
+	tui, screen := newDiffTestTUI(t, 100, testDiff)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone))
+	if tui.diffView.sideBySide || !strings.Contains(tui.diffView.status, "at least 120 columns") {
+		t.Errorf("Expected side by side to need a wider terminal, got %q", tui.diffView.status)
+	}
+
+	tui, screen = newDiffTestTUI(t, 140, testDiff)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone))
+	if !tui.diffView.sideBySide || tui.viewMode != ViewModeDiff {
+		t.Fatal("Expected S to show the diff side by side")
+	}
+	tui.drawDiffView(140, 20)
+	screen.Show()
+
+	// Rows 2-4 are the file and hunk headers across both halves
+	half := (140 - 1) / 2
+	text, styles := screenRow(screen, 6)
+	left, right := []rune(text)[:half], []rune(text)[half+1:]
+	if got := strings.TrimRight(string(left), " "); got != "   2 -port = 80" {
+		t.Errorf("Expected the removed line on the left, got %q", got)
+	}
+	if got := strings.TrimRight(string(right), " "); got != "   2 +port = 8080" {
+		t.Errorf("Expected the added line on the right, got %q", got)
+	}
+	if []rune(text)[half] != '│' {
+		t.Errorf("Expected a separator at column %d, got %q", half, text)
+	}
+	if fg, _, _ := styles[0].Decompose(); fg != tcell.ColorRed {
+		t.Errorf("Expected the left half in red, got %v", fg)
+	}
+	if fg, _, _ := styles[half+1].Decompose(); fg != tcell.ColorGreen {
+		t.Errorf("Expected the right half in green, got %v", fg)
+	}
+	text, _ = screenRow(screen, 5)
+	if !strings.HasPrefix(text, "   1  host = 0.0.0.0") || !strings.Contains(string([]rune(text)[half+1:]), "   1  host = 0.0.0.0") {
+		t.Errorf("Expected a context line on both halves, got %q", text)
+	}
+
+	// ESC returns to the view the diff was opened from
+	tui.handleKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if tui.viewMode != ViewModeDetails || tui.diffView != nil {
+		t.Errorf("Expected ESC to return to the details, got %s", tui.getViewModeName())
+	}
+}
+
+// TestTUIDiffViewSearch tests that '/' finds a line of the diff, n goes on
+// to the next match and the matched text is shown in reverse video
+func TestTUIDiffViewSearch(t *testing.T) {
+	var diff strings.Builder
+	diff.WriteString("@@ -1,40 +1,40 @@\n")
+	for i := 0; i < 40; i++ {
+		if i == 25 || i == 35 {
+			diff.WriteString("-port = 80\n+port = 8080\n")
+			continue
+		}
+		diff.WriteString(" filler\n")
+	}
+	tui, screen := newDiffTestTUI(t, 100, diff.String())
+
+	for _, r := range "PORT" {
+		screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone))
+	viewer := tui.diffView
+	if viewer.query != "PORT" || viewer.match != 26 || viewer.scroll != 26 {
+		t.Fatalf("Expected the first match on line 26, got match %d at scroll %d", viewer.match, viewer.scroll)
+	}
+
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone))
+	if viewer.match != 27 {
+		t.Errorf("Expected n to go to the added line 27, got %d", viewer.match)
+	}
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'N', tcell.ModNone))
+	if viewer.match != 26 {
+		t.Errorf("Expected N to go back to line 26, got %d", viewer.match)
+	}
+
+	tui.drawDiffView(100, 20)
+	screen.Show()
+	text, styles := screenRow(screen, 2)
+	if !strings.HasPrefix(text, "-port = 80") {
+		t.Fatalf("Expected the match at the top, got %q", text)
+	}
+	if _, _, attrs := styles[1].Decompose(); attrs&tcell.AttrReverse == 0 {
+		t.Error("Expected the matched text in reverse video")
+	}
+	if _, _, attrs := styles[0].Decompose(); attrs&tcell.AttrReverse != 0 {
+		t.Error("Expected the text around the match not to be reversed")
+	}
+
+	viewer.query, viewer.match = "missing", -1
+	viewer.findMatch(false, 1)
+	if viewer.status != `No match for "missing"` {
+		t.Errorf("Expected no match to be reported, got %q", viewer.status)
+	}
+}
+
+// TestTUIDeploymentDiff tests opening the pod template diff from deployment details
+func TestTUIDeploymentDiff(t *testing.T) {
+	deployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx:1.26"}}},
+			},
+		},
+	}
+	previous := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-1",
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "web"},
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+		},
+		Spec: appsv1.ReplicaSetSpec{Template: *deployment.Spec.Template.DeepCopy()},
+	}
+	previous.Spec.Template.Spec.Containers[0].Image = "nginx:1.25"
+
+	tui, _ := newTestTUI(t, 100, 30, &deployment, previous)
+	tui.currentView = ResourceDeployments
+	tui.viewMode = ViewModeDetails
+	tui.deployments = []appsv1.Deployment{deployment}
+
+	tui.showDeploymentDiff()
+	if tui.viewMode != ViewModeDiff {
+		t.Fatalf("Expected diff view mode, got %v", tui.viewMode)
+	}
+	var added []string
+	for _, line := range tui.diffView.lines {
+		if line.kind == diffAdded {
+			added = append(added, line.text)
+		}
+	}
+	if len(added) != 1 || added[0] != "+    - image: nginx:1.26" {
+		t.Errorf("Expected image change in diff, got %q", added)
+	}
+	tui.drawDiffView(100, 30)
+
+	// A deployment without an earlier rollout explains why there is no diff
+	tui.clientset = fake.NewSimpleClientset(&deployment)
+	tui.showDeploymentDiff()
+	if lines := tui.diffView.lines; len(lines) != 1 || lines[0].kind != diffNote || !strings.HasPrefix(lines[0].text, "No previous ReplicaSet") {
+		t.Errorf("Expected no previous ReplicaSet message, got %+v", lines)
+	}
+}
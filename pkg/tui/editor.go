@@ -0,0 +1,265 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// editorView is the state of ViewModeEditor: a text file of the container
+// open in the file browser, edited line by line. The cursor column counts
+// runes.
+type editorView struct {
+	path string
+	// saved is the file as it was read or last saved, which the preview of
+	// Ctrl+S diffs the edited lines against
+	saved    string
+	lines    []string
+	row, col int
+	scroll   int
+	modified bool
+	// discarding is set by ESC with unsaved changes; a second ESC drops them
+	discarding bool
+	status     string
+}
+
+// newEditorView opens the content of a file in the editor. Splitting on
+// newlines keeps a final newline as an empty last line, so saving writes
+// back exactly what was read when nothing changed.
+func newEditorView(path string, data []byte) *editorView {
+	return &editorView{path: path, saved: string(data), lines: strings.Split(string(data), "\n")}
+}
+
+// content returns the edited file
+func (e *editorView) content() []byte {
+	return []byte(strings.Join(e.lines, "\n"))
+}
+
+// clampCol keeps the cursor within its line
+func (e *editorView) clampCol() {
+	e.col = min(e.col, len([]rune(e.lines[e.row])))
+}
+
+// handleKey applies a key press other than saving and closing to the text
+func (e *editorView) handleKey(ev *tcell.EventKey, pageSize int) {
+	line := []rune(e.lines[e.row])
+	switch ev.Key() {
+	case tcell.KeyUp:
+		if e.row > 0 {
+			e.row--
+			e.clampCol()
+		}
+	case tcell.KeyDown:
+		if e.row < len(e.lines)-1 {
+			e.row++
+			e.clampCol()
+		}
+	case tcell.KeyPgUp:
+		e.row = max(e.row-pageSize, 0)
+		e.clampCol()
+	case tcell.KeyPgDn:
+		e.row = min(e.row+pageSize, len(e.lines)-1)
+		e.clampCol()
+	case tcell.KeyLeft:
+		if e.col > 0 {
+			e.col--
+		} else if e.row > 0 {
+			e.row--
+			e.col = len([]rune(e.lines[e.row]))
+		}
+	case tcell.KeyRight:
+		if e.col < len(line) {
+			e.col++
+		} else if e.row < len(e.lines)-1 {
+			e.row++
+			e.col = 0
+		}
+	case tcell.KeyHome:
+		e.col = 0
+	case tcell.KeyEnd:
+		e.col = len(line)
+	case tcell.KeyEnter:
+		e.lines[e.row] = string(line[:e.col])
+		e.lines = append(e.lines[:e.row+1], append([]string{string(line[e.col:])}, e.lines[e.row+1:]...)...)
+		e.row++
+		e.col = 0
+		e.modified = true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if e.col > 0 {
+			e.lines[e.row] = string(line[:e.col-1]) + string(line[e.col:])
+			e.col--
+			e.modified = true
+		} else if e.row > 0 {
+			// Join the line to the previous one
+			e.col = len([]rune(e.lines[e.row-1]))
+			e.lines[e.row-1] += e.lines[e.row]
+			e.lines = append(e.lines[:e.row], e.lines[e.row+1:]...)
+			e.row--
+			e.modified = true
+		}
+	case tcell.KeyDelete:
+		if e.col < len(line) {
+			e.lines[e.row] = string(line[:e.col]) + string(line[e.col+1:])
+			e.modified = true
+		} else if e.row < len(e.lines)-1 {
+			e.lines[e.row] += e.lines[e.row+1]
+			e.lines = append(e.lines[:e.row+1], e.lines[e.row+2:]...)
+			e.modified = true
+		}
+	case tcell.KeyTab, tcell.KeyRune:
+		r := ev.Rune()
+		if ev.Key() == tcell.KeyTab {
+			r = '\t'
+		}
+		e.lines[e.row] = string(line[:e.col]) + string(r) + string(line[e.col:])
+		e.col++
+		e.modified = true
+	}
+}
+
+// handleEditorKey handles the keys of the editor: Ctrl+S shows the changes
+// to save back into the container, ESC returns to the file browser, asking
+// for a second ESC to drop unsaved changes. Only Ctrl+C is left to the main
+// loop.
+func (t *TUI) handleEditorKey(ev *tcell.EventKey) bool {
+	editor := t.editor
+	if editor == nil {
+		t.closeEditor()
+		return true
+	}
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return false
+	case tcell.KeyEscape:
+		if editor.modified && !editor.discarding {
+			editor.discarding = true
+			editor.status = "Unsaved changes: Ctrl+S saves them, ESC again drops them"
+			return true
+		}
+		t.closeEditor()
+		return true
+	case tcell.KeyCtrlS:
+		t.previewEditedFile()
+		return true
+	}
+
+	editor.discarding, editor.status = false, ""
+	_, height := t.screen.Size()
+	editor.handleKey(ev, max(height-3, 1))
+	return true
+}
+
+// closeEditor returns from the editor to the file browser, listing the
+// directory again for the new size of a saved file
+func (t *TUI) closeEditor() {
+	t.editor = nil
+	if t.viewMode != ViewModeEditor {
+		return
+	}
+	t.viewMode = ViewModeFileBrowser
+	if t.fileBrowser == nil {
+		t.viewMode = ViewModeDetails
+		return
+	}
+	view := t.fileBrowser
+	selected := view.selected
+	t.listDirectory(view.dir)
+	view.selected = min(selected, max(len(view.entries)-1, 0))
+}
+
+// previewEditedFile shows the diff of the edited file in the diff view,
+// where Enter saves it and ESC returns to editing. An unmodified file is
+// saved right away.
+func (t *TUI) previewEditedFile() {
+	editor := t.editor
+	diff := k8s.UnifiedDiff(editor.path, editor.path+" (edited)", editor.saved, string(editor.content()))
+	if !editor.modified || diff == "" {
+		t.saveEditedFile()
+		return
+	}
+	viewer := newDiffViewer("Save "+editor.path, diff, ViewModeEditor)
+	viewer.confirmLabel, viewer.confirm = "Save", t.saveEditedFile
+	t.openDiffViewer(viewer)
+}
+
+// saveEditedFile writes the edited file back into the container with tee
+func (t *TUI) saveEditedFile() {
+	editor, view := t.editor, t.fileBrowser
+	if view == nil {
+		return
+	}
+	if !t.confirmProtectedActionIn(view.pod.Namespace, "write "+editor.path+" in", "pod", view.pod.Name) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), podFilesTimeout)
+	err := k8s.WritePodFile(ctx, t.podExec, view.pod.Namespace, view.pod.Name, view.container, editor.path, editor.content())
+	cancel()
+	if err != nil {
+		editor.status = fmt.Sprintf("Error: failed to save %s: %v", editor.path, err)
+		return
+	}
+	editor.saved = string(editor.content())
+	editor.modified, editor.discarding = false, false
+	editor.status = "Saved " + editor.path
+	t.recordAction(fmt.Sprintf("Saved %s in pod '%s'", editor.path, view.pod.Name),
+		k8s.KubectlWritePodFile(view.pod.Namespace, view.pod.Name, view.container, editor.path))
+}
+
+// drawEditorView draws the visible lines of the file with the cursor,
+// scrolled so that it stays on screen
+func (t *TUI) drawEditorView(width, height int) {
+	editor := t.editor
+	modified := ""
+	if editor.modified {
+		modified = " [modified]"
+	}
+	header := fmt.Sprintf(" ✎ Editor: %s:%s%s ", t.fileBrowser.pod.Name, editor.path, modified)
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	rows := max(height-3, 1)
+	if editor.row < editor.scroll {
+		editor.scroll = editor.row
+	} else if editor.row >= editor.scroll+rows {
+		editor.scroll = editor.row - rows + 1
+	}
+	// Long lines scroll sideways to keep the cursor visible
+	offset := max(editor.col-(width-2), 0)
+
+	style := tcell.StyleDefault.Foreground(t.theme.foreground)
+	for i := editor.scroll; i < len(editor.lines) && i < editor.scroll+rows; i++ {
+		y := 1 + i - editor.scroll
+		line := []rune(editor.lines[i])
+		for x := offset; x < len(line) && x-offset < width; x++ {
+			r := line[x]
+			if r == '\t' {
+				r = ' '
+			}
+			t.screen.SetContent(x-offset, y, r, nil, style)
+		}
+		if i == editor.row {
+			cursor := ' '
+			if editor.col < len(line) && line[editor.col] != '\t' {
+				cursor = line[editor.col]
+			}
+			t.screen.SetContent(editor.col-offset, y, cursor, nil, style.Reverse(true))
+		}
+	}
+
+	status := fmt.Sprintf("Line %d/%d, column %d", editor.row+1, len(editor.lines), editor.col+1)
+	if editor.status != "" {
+		status = editor.status
+	}
+	statusStyle := style
+	if strings.HasPrefix(status, "Error:") {
+		statusStyle = statusStyle.Foreground(tcell.ColorRed)
+	}
+	t.drawText(1, height-2, width-2, status, statusStyle)
+	footer := " Ctrl+S Save │ ESC Back to files "
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultDebugImage is the debug container image without a configured
+	// ui.debugImage
+	defaultDebugImage = "busybox:1.36"
+	// debugPodTimeout bounds adding a debug container to a pod
+	debugPodTimeout = 10 * time.Second
+)
+
+// debugImage returns the image of the debug containers 'x' adds
+func (t *TUI) debugImage() string {
+	if t.config == nil || t.config.UI.DebugImage == "" {
+		return defaultDebugImage
+	}
+	return t.config.UI.DebugImage
+}
+
+// debugSelectedPod adds a debug container running the debug image to the
+// selected pod, like kubectl debug, for images without a shell to exec into.
+// It shares the process namespace of the container logs are shown for, and
+// its logs are followed once it is added.
+func (t *TUI) debugSelectedPod() {
+	pod, ok := t.getSelectedResource().(v1.Pod)
+	if !ok {
+		return
+	}
+	image, target := t.debugImage(), logContainer(pod)
+	if !t.confirmProtectedActionIn(pod.Namespace, "debug", "pod", pod.Name) {
+		return
+	}
+
+	err := t.imagePolicy.CheckImage(image)
+	var updated *v1.Pod
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), debugPodTimeout)
+		updated, err = k8s.AddEphemeralContainer(ctx, t.clientset, pod.Namespace, pod.Name, k8s.NewDebugContainer(image, nil, target))
+		cancel()
+	}
+	if err != nil {
+		klog.Errorf("Failed to debug pod: %v", err)
+		errorMsg := fmt.Sprintf("Error: failed to debug pod: %v", err)
+		t.drawText(0, 3, 80, errorMsg, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+		time.Sleep(2 * time.Second)
+		return
+	}
+
+	container := updated.Spec.EphemeralContainers[len(updated.Spec.EphemeralContainers)-1].Name
+	t.recordAction(fmt.Sprintf("Added debug container '%s' (%s) to pod '%s'", container, image, pod.Name), k8s.KubectlDebug(pod.Namespace, pod.Name, image, target, nil))
+	t.openContainerLogs(*updated, container, t.initialLogTailLines())
+}
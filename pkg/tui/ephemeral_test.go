@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTUIDebugPod(t *testing.T) {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "distroless/app"}}},
+	}
+	tui, _ := newTestTUI(t, 120, 30, &pod)
+	clientset := tui.clientset.(*fake.Clientset)
+	cfg := config.DefaultConfig()
+	cfg.UI.DebugImage = "nicolaka/netshoot"
+	tui.config = cfg
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeDetails
+	tui.pods = []v1.Pod{pod}
+
+	tui.debugSelectedPod()
+	defer tui.closeLogs()
+
+	stored, err := clientset.CoreV1().Pods("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil || len(stored.Spec.EphemeralContainers) != 1 {
+		t.Fatalf("Expected a debug container in the pod, got %+v, %v", stored, err)
+	}
+	debugger := stored.Spec.EphemeralContainers[0]
+	if debugger.Image != "nicolaka/netshoot" || debugger.TargetContainerName != "app" {
+		t.Errorf("Expected the configured image targeting app, got %+v", debugger)
+	}
+	if tui.viewMode != ViewModeLogs || tui.logs.container != debugger.Name {
+		t.Errorf("Expected the logs of %s to be followed, got view %v of %q", debugger.Name, tui.viewMode, tui.logs.container)
+	}
+	if want := "kubectl -n default debug -it web --image=nicolaka/netshoot --target=app"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+
+	// Without a configured image busybox is used
+	tui.config = nil
+	if image := tui.debugImage(); image != "busybox:1.36" {
+		t.Errorf("Expected the default debug image, got %s", image)
+	}
+}
@@ -0,0 +1,333 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// podFilesTimeout bounds listing, reading and writing a container's
+	// files; copies get podCopyTimeout
+	podFilesTimeout = 15 * time.Second
+	podCopyTimeout  = 5 * time.Minute
+	// maxEditorFileSize is the largest file 'e' opens in the editor
+	maxEditorFileSize = 1 << 20
+)
+
+var (
+	// errFilesNeedExec is shown in the file browser without an exec client
+	errFilesNeedExec = errors.New("browsing files needs exec with direct cluster access")
+	// errExecDisabled is shown in the file browser when exec is turned off
+	errExecDisabled = errors.New("exec is disabled (features.enableExec)")
+)
+
+// fileBrowserView is the state of ViewModeFileBrowser: a directory of a
+// container of a pod, listed with ls over exec. The local file picker uses
+// it for local directories.
+type fileBrowserView struct {
+	pod       v1.Pod
+	container string
+	// local lists local directories instead of the container's
+	local   bool
+	dir     string
+	entries []k8s.FileEntry
+	// err is why the directory could not be listed
+	err      error
+	selected int
+	scroll   int
+	// status reports the last copy, or why it or opening a file failed
+	status string
+}
+
+// SetPodExec sets how commands run in containers, which the file browser
+// needs
+func (t *TUI) SetPodExec(exec k8s.PodExec) {
+	t.podExec = exec
+}
+
+// openFileBrowser opens the file browser on the root directory of the
+// selected pod's default container
+func (t *TUI) openFileBrowser() {
+	pod, ok := t.getSelectedResource().(v1.Pod)
+	if !ok {
+		return
+	}
+	t.fileBrowser = &fileBrowserView{pod: pod, container: logContainer(pod)}
+	t.viewMode = ViewModeFileBrowser
+	t.listDirectory("/")
+}
+
+// closeFileBrowser returns from the file browser to the pod details
+func (t *TUI) closeFileBrowser() {
+	t.fileBrowser = nil
+	if t.viewMode == ViewModeFileBrowser {
+		t.viewMode = ViewModeDetails
+	}
+}
+
+// listDirectory shows a directory of the container in the file browser
+func (t *TUI) listDirectory(dir string) {
+	view := t.fileBrowser
+	view.dir, view.entries, view.selected, view.scroll, view.status = dir, nil, 0, 0, ""
+
+	switch {
+	case view.local:
+		view.entries, view.err = k8s.ListLocalDirectory(dir)
+	case t.config != nil && !t.config.Features.EnableExec:
+		view.err = errExecDisabled
+	case t.podExec == nil:
+		view.err = errFilesNeedExec
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), podFilesTimeout)
+		view.entries, view.err = k8s.ListPodDirectory(ctx, t.podExec, view.pod.Namespace, view.pod.Name, view.container, dir)
+		cancel()
+	}
+}
+
+// selectedFile returns the selected entry of the file browser
+func (v *fileBrowserView) selectedFile() (k8s.FileEntry, bool) {
+	if v.selected < 0 || v.selected >= len(v.entries) {
+		return k8s.FileEntry{}, false
+	}
+	return v.entries[v.selected], true
+}
+
+// handleFileBrowserKey handles the keys of the file browser: Enter opens a
+// directory, Backspace goes up, c copies the selected file here and e edits
+// it. Only quitting is left to the main loop.
+func (t *TUI) handleFileBrowserKey(ev *tcell.EventKey) bool {
+	view := t.fileBrowser
+	if view == nil {
+		t.closeFileBrowser()
+		return true
+	}
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return false
+	case tcell.KeyEscape:
+		t.closeFileBrowser()
+	case tcell.KeyUp:
+		if view.selected > 0 {
+			view.selected--
+		}
+	case tcell.KeyDown:
+		if view.selected < len(view.entries)-1 {
+			view.selected++
+		}
+	case tcell.KeyEnter:
+		// Links are followed as directories; ls says when they are not
+		if file, ok := view.selectedFile(); ok && (file.IsDir() || file.IsLink()) {
+			t.listDirectory(path.Join(view.dir, file.Name))
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if view.dir != "/" {
+			t.listDirectory(path.Dir(view.dir))
+		}
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return false
+		case 'r':
+			selected := view.selected
+			t.listDirectory(view.dir)
+			view.selected = min(selected, max(len(view.entries)-1, 0))
+		case 'c':
+			t.copySelectedFile()
+		case 'e':
+			t.editSelectedFile()
+		}
+	}
+	return true
+}
+
+// copySelectedFile copies the selected file or directory into the working
+// directory, like kubectl cp
+func (t *TUI) copySelectedFile() {
+	view := t.fileBrowser
+	file, ok := view.selectedFile()
+	if !ok || view.err != nil {
+		return
+	}
+	dest, err := os.Getwd()
+	if err != nil {
+		view.status = "Error: " + err.Error()
+		return
+	}
+
+	src := path.Join(view.dir, file.Name)
+	view.status = fmt.Sprintf("Copying %s...", src)
+	t.draw()
+	t.screen.Show()
+
+	ctx, cancel := context.WithTimeout(context.Background(), podCopyTimeout)
+	copied, err := k8s.CopyFromPod(ctx, t.podExec, view.pod.Namespace, view.pod.Name, view.container, src, dest)
+	cancel()
+	if err != nil {
+		view.status = fmt.Sprintf("Error: failed to copy %s: %v", src, err)
+		return
+	}
+	view.status = fmt.Sprintf("Copied %s to %s", src, copied)
+	t.recordAction(fmt.Sprintf("Copied %s from pod '%s' to %s", src, view.pod.Name, copied),
+		k8s.KubectlCopyFromPod(view.pod.Namespace, view.pod.Name, view.container, src, file.Name))
+}
+
+// editSelectedFile opens the selected file in the editor. Directories,
+// binary files and files over maxEditorFileSize are not opened.
+func (t *TUI) editSelectedFile() {
+	view := t.fileBrowser
+	file, ok := view.selectedFile()
+	if !ok || view.err != nil {
+		return
+	}
+	file.Name = path.Join(view.dir, file.Name)
+	switch {
+	case file.IsDir():
+		view.status = fmt.Sprintf("%s is a directory, Enter opens it", file.Name)
+		return
+	case file.Size > maxEditorFileSize:
+		view.status = fmt.Sprintf("%s is over %s, c copies it instead", file.Name, formatSize(maxEditorFileSize))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), podFilesTimeout)
+	data, err := k8s.ReadPodFile(ctx, t.podExec, view.pod.Namespace, view.pod.Name, view.container, file.Name)
+	cancel()
+	switch {
+	case err != nil:
+		view.status = fmt.Sprintf("Error: failed to read %s: %v", file.Name, err)
+	case !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0:
+		view.status = fmt.Sprintf("%s is a binary file, c copies it instead", file.Name)
+	default:
+		t.editor = newEditorView(file.Name, data)
+		t.viewMode = ViewModeEditor
+	}
+}
+
+// fileBrowserLine renders an entry of the file browser: permissions, size,
+// modification time and name, with directories ending in / and links
+// showing their target
+func fileBrowserLine(file k8s.FileEntry) string {
+	name := file.Name
+	switch {
+	case file.IsDir():
+		name += "/"
+	case file.IsLink():
+		name += " -> " + file.LinkTarget
+	}
+	size := formatSize(int(file.Size))
+	if file.IsDir() {
+		size = "-"
+	}
+	return fmt.Sprintf("%-10s %9s  %-16s %s", file.Mode, size, file.Modified, name)
+}
+
+// drawFileBrowserView draws the listed directory, the selected entry
+// highlighted
+func (t *TUI) drawFileBrowserView(width, height int) {
+	view := t.fileBrowser
+	header := fmt.Sprintf(" 📁 Files: %s/%s (%s) %s ", view.pod.Namespace, view.pod.Name, view.container, view.dir)
+	footer := " ↑↓ Select │ Enter Open directory │ Backspace Up │ c Copy here │ e Edit │ r Reload │ ESC Back "
+	if view.local {
+		header = fmt.Sprintf(" 📁 Local files: %s ", view.dir)
+		footer = " ↑↓ Select │ Enter Open directory / Pick file │ s Pick this directory │ Backspace Up │ r Reload │ ESC Cancel "
+	}
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	style := tcell.StyleDefault.Foreground(t.theme.foreground)
+	t.drawText(1, 2, width-2, fmt.Sprintf("%-10s %9s  %-16s %s", "MODE", "SIZE", "MODIFIED", "NAME"), style.Bold(true))
+
+	y := 3
+	rows := max(height-5, 1)
+	switch {
+	case view.err != nil:
+		t.drawText(1, y, width-2, "Error: "+view.err.Error(), style.Foreground(tcell.ColorRed))
+	case len(view.entries) == 0:
+		t.drawText(1, y, width-2, "Empty directory", style.Foreground(tcell.ColorGray))
+	default:
+		if view.selected < view.scroll {
+			view.scroll = view.selected
+		} else if view.selected >= view.scroll+rows {
+			view.scroll = view.selected - rows + 1
+		}
+		for i := view.scroll; i < len(view.entries) && y < 3+rows; i++ {
+			lineStyle := style
+			if view.entries[i].IsDir() {
+				lineStyle = lineStyle.Foreground(t.theme.accent)
+			}
+			if i == view.selected {
+				lineStyle = lineStyle.Background(t.theme.selected)
+			}
+			t.drawText(1, y, width-2, fileBrowserLine(view.entries[i]), lineStyle)
+			y++
+		}
+	}
+
+	if view.status != "" {
+		statusStyle := style
+		if strings.HasPrefix(view.status, "Error:") {
+			statusStyle = statusStyle.Foreground(tcell.ColorRed)
+		}
+		t.drawText(1, height-2, width-2, view.status, statusStyle)
+	}
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
+
+// pickLocalPath lets the user pick a local file, or with s the directory
+// shown, in the file browser, starting in the working directory. It reports
+// whether a path was picked before Esc.
+func (t *TUI) pickLocalPath() (string, bool) {
+	start, err := os.Getwd()
+	if err != nil {
+		start = "/"
+	}
+	previous := t.fileBrowser
+	t.fileBrowser = &fileBrowserView{local: true}
+	defer func() { t.fileBrowser = previous }()
+	t.listDirectory(start)
+
+	for {
+		view := t.fileBrowser
+		t.screen.Clear()
+		width, height := t.screen.Size()
+		t.drawFileBrowserView(width, height)
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		file, selected := view.selectedFile()
+		picked := filepath.Join(view.dir, file.Name)
+		switch {
+		case ev.Key() == tcell.KeyEscape:
+			return "", false
+		case ev.Key() == tcell.KeyEnter && selected && !file.IsDir():
+			// A link is opened when it points to a directory
+			if info, err := os.Stat(picked); err == nil && info.IsDir() {
+				t.listDirectory(picked)
+				continue
+			}
+			return picked, true
+		case ev.Key() == tcell.KeyRune && ev.Rune() == 's':
+			return view.dir, true
+		case ev.Key() == tcell.KeyRune && (ev.Rune() == 'c' || ev.Rune() == 'e'):
+			// Copying and editing are for container files
+		default:
+			t.handleFileBrowserKey(ev)
+		}
+	}
+}
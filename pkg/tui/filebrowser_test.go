@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTUIFileBrowser(t *testing.T) {
+	listings := map[string]string{
+		"/":     "total 8\ndrwxr-xr-x 1 root root 4096 Mar  4 10:15 etc\n-rw-r--r-- 1 root root 3 Mar  4 10:15 blob\n",
+		"/etc/": "total 4\n-rw-r--r-- 1 root root 12 Mar  4 10:15 app.conf\n",
+	}
+	files := map[string]string{"/etc/app.conf": "port = 80\n", "/blob": "\x00\x01\x02"}
+	var commands [][]string
+	exec := func(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+		commands = append(commands, command)
+		switch command[0] {
+		case "ls":
+			_, err := io.WriteString(stdout, listings[command[2]])
+			return err
+		case "cat":
+			_, err := io.WriteString(stdout, files[command[1]])
+			return err
+		case "tee":
+			data, err := io.ReadAll(stdin)
+			files[command[1]] = string(data)
+			return err
+		}
+		return fmt.Errorf("%s: not found", command[0])
+	}
+
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+	}
+	tui, screen := newTestTUI(t, 120, 30, &pod)
+	tui.podExec = exec
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeDetails
+	tui.pods = []v1.Pod{pod}
+	key := func(k tcell.Key, r rune) {
+		t.Helper()
+		handled := false
+		switch tui.viewMode {
+		case ViewModeFileBrowser:
+			handled = tui.handleFileBrowserKey(tcell.NewEventKey(k, r, tcell.ModNone))
+		case ViewModeEditor:
+			handled = tui.handleEditorKey(tcell.NewEventKey(k, r, tcell.ModNone))
+		case ViewModeDiff:
+			handled = tui.handleDiffKey(tcell.NewEventKey(k, r, tcell.ModNone))
+		}
+		if !handled {
+			t.Fatalf("Expected %v %q to be handled in view %s", k, r, tui.getViewModeName())
+		}
+	}
+
+	tui.openFileBrowser()
+	if tui.viewMode != ViewModeFileBrowser || len(tui.fileBrowser.entries) != 2 || tui.fileBrowser.entries[0].Name != "etc" {
+		t.Fatalf("Expected the root directory, directories first, got %+v", tui.fileBrowser)
+	}
+	tui.drawFileBrowserView(120, 30)
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "Files: default/web (app) /") || !strings.Contains(text, "etc/") {
+		t.Errorf("Expected the root listing on screen, got:\n%s", text)
+	}
+
+	// A binary file is not opened
+	key(tcell.KeyDown, 0)
+	key(tcell.KeyRune, 'e')
+	if tui.viewMode != ViewModeFileBrowser || !strings.Contains(tui.fileBrowser.status, "binary") {
+		t.Errorf("Expected the binary file to be refused, got %q", tui.fileBrowser.status)
+	}
+
+	// Enter opens a directory, Backspace goes up
+	key(tcell.KeyUp, 0)
+	key(tcell.KeyEnter, 0)
+	if tui.fileBrowser.dir != "/etc" || len(tui.fileBrowser.entries) != 1 {
+		t.Fatalf("Expected /etc, got %s with %+v", tui.fileBrowser.dir, tui.fileBrowser.entries)
+	}
+	key(tcell.KeyBackspace2, 0)
+	if tui.fileBrowser.dir != "/" {
+		t.Errorf("Expected Backspace to go up to /, got %s", tui.fileBrowser.dir)
+	}
+	key(tcell.KeyEnter, 0)
+
+	// e edits the file, Ctrl+S shows the changes and Enter writes them back
+	// with tee
+	key(tcell.KeyRune, 'e')
+	if tui.viewMode != ViewModeEditor || tui.editor.path != "/etc/app.conf" {
+		t.Fatalf("Expected the editor on /etc/app.conf, got view %s", tui.getViewModeName())
+	}
+	key(tcell.KeyEnd, 0)
+	key(tcell.KeyBackspace2, 0)
+	key(tcell.KeyBackspace2, 0)
+	for _, r := range "8080" {
+		key(tcell.KeyRune, r)
+	}
+	key(tcell.KeyEscape, 0)
+	if tui.viewMode != ViewModeEditor || !strings.Contains(tui.editor.status, "Unsaved changes") {
+		t.Fatalf("Expected ESC to warn about unsaved changes, got %q", tui.editor.status)
+	}
+	key(tcell.KeyCtrlS, 0)
+	if tui.viewMode != ViewModeDiff || files["/etc/app.conf"] != "port = 80\n" {
+		t.Fatalf("Expected Ctrl+S to preview the changes before saving, got view %s", tui.getViewModeName())
+	}
+	var changes []string
+	for _, line := range tui.diffView.lines {
+		if line.kind == diffAdded || line.kind == diffRemoved {
+			changes = append(changes, line.text)
+		}
+	}
+	if strings.Join(changes, "\n") != "-port = 80\n+port = 8080" {
+		t.Errorf("Expected the changed port in the preview, got %q", changes)
+	}
+	key(tcell.KeyEnter, 0)
+	if got := files["/etc/app.conf"]; got != "port = 8080\n" {
+		t.Errorf("Expected the edited file to be saved, got %q", got)
+	}
+	if want := "kubectl -n default exec -i web -c app -- tee /etc/app.conf"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+	key(tcell.KeyEscape, 0)
+	if tui.viewMode != ViewModeFileBrowser {
+		t.Errorf("Expected ESC after saving to return to the files, got %s", tui.getViewModeName())
+	}
+	key(tcell.KeyEscape, 0)
+	if tui.viewMode != ViewModeDetails || tui.fileBrowser != nil {
+		t.Errorf("Expected ESC to return to the pod details, got %s", tui.getViewModeName())
+	}
+
+	// Nothing runs with exec turned off
+	tui.config.Features.EnableExec = false
+	commands = nil
+	tui.openFileBrowser()
+	if tui.fileBrowser.err != errExecDisabled || len(commands) != 0 {
+		t.Errorf("Expected the file browser to say exec is disabled, got %v after %v", tui.fileBrowser.err, commands)
+	}
+}
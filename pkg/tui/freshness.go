@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"k8s-dashboard/pkg/util"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// isStale reports whether data loaded at lastUpdated should be reloaded at
+// now. Data that was never loaded is always stale, and a non-positive maxAge
+// makes every load stale.
+func isStale(lastUpdated, now time.Time, maxAge time.Duration) bool {
+	return lastUpdated.IsZero() || maxAge <= 0 || now.Sub(lastUpdated) >= maxAge
+}
+
+// staleAfter is how old a tab's data may get before switching to it reloads
+// it, taken from ui.autoRefresh
+func (t *TUI) staleAfter() time.Duration {
+	if t.config == nil {
+		return 0
+	}
+	return time.Duration(t.config.UI.AutoRefresh) * time.Second
+}
+
+// shouldRefresh decides whether a resource type is reloaded at now: when its
+// data is stale and it is not already being loaded. Callers hold freshnessMu.
+func (t *TUI) shouldRefresh(rt ResourceType, now time.Time) bool {
+	return !t.refreshing[rt] && isStale(t.lastUpdated[rt], now, t.staleAfter())
+}
+
+// refreshIfStale reloads a resource type in the background when shouldRefresh
+// says so, and reports whether it did
+func (t *TUI) refreshIfStale(rt ResourceType) bool {
+	t.freshnessMu.Lock()
+	if !t.shouldRefresh(rt, time.Now()) {
+		t.freshnessMu.Unlock()
+		return false
+	}
+	if t.refreshing == nil {
+		t.refreshing = make(map[ResourceType]bool)
+	}
+	t.refreshing[rt] = true
+	load := t.newLoad(rt, true)
+	t.freshnessMu.Unlock()
+
+	go t.loadAsync(load)
+	return true
+}
+
+// dataLoad is a load of one resource type. What it loads is captured on the
+// main goroutine when it starts, as the loaders run on their own.
+type dataLoad struct {
+	resourceType ResourceType
+	namespace    string
+	generation   int
+	background   bool
+}
+
+// newLoad starts a load of a resource type in the current namespace and load
+// generation. Callers hold freshnessMu.
+func (t *TUI) newLoad(rt ResourceType, background bool) dataLoad {
+	return dataLoad{resourceType: rt, namespace: t.namespace, generation: t.loadGeneration, background: background}
+}
+
+// update returns the update the load sends, for the loader to fill in
+func (l dataLoad) update() *DataUpdate {
+	return &DataUpdate{
+		ResourceType: l.resourceType,
+		Namespace:    l.namespace,
+		Generation:   l.generation,
+		Background:   l.background,
+	}
+}
+
+// loadAsync loads a single resource type
+func (t *TUI) loadAsync(load dataLoad) {
+	switch load.resourceType {
+	case ResourcePods:
+		t.loadPodsAsync(load)
+	case ResourceDeployments:
+		t.loadDeploymentsAsync(load)
+	case ResourceServices:
+		t.loadServicesAsync(load)
+	case ResourceConfigMaps:
+		t.loadConfigMapsAsync(load)
+	case ResourceNamespaces:
+		t.loadNamespacesAsync(load)
+	case ResourceNodes:
+		t.loadNodesAsync(load)
+	case ResourceCRDs:
+		t.loadCRDsAsync(load)
+	case ResourceStatefulSets:
+		t.loadStatefulSetsAsync(load)
+	case ResourcePVCs:
+		t.loadPVCsAsync(load)
+	case ResourceDaemonSets:
+		t.loadDaemonSetsAsync(load)
+	}
+}
+
+// isCurrentLoad reports whether an update comes from a load of what is shown
+// now. A load started before the last refresh, which every namespace switch
+// does, must not overwrite the lists of the new one. Watcher updates are not
+// tied to a load.
+func (t *TUI) isCurrentLoad(update *DataUpdate) bool {
+	if update.Generation == 0 {
+		return true
+	}
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	return update.Generation == t.loadGeneration && update.Namespace == t.loadNamespace
+}
+
+// recordUpdate notes that a load of a resource type finished, and when it
+// succeeded
+func (t *TUI) recordUpdate(update *DataUpdate) {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+
+	delete(t.refreshing, update.ResourceType)
+	t.recordAccess(update)
+	if update.Error != nil {
+		return
+	}
+	if t.lastUpdated == nil {
+		t.lastUpdated = make(map[ResourceType]time.Time)
+	}
+	t.lastUpdated[update.ResourceType] = time.Now()
+	if update.ResourceVersion != "" {
+		if t.resourceVersions == nil {
+			t.resourceVersions = make(map[ResourceType]string)
+		}
+		t.resourceVersions[update.ResourceType] = update.ResourceVersion
+	}
+}
+
+// resourceVersion returns the resourceVersion of the last list of a resource
+// type, or an empty string before the first
+func (t *TUI) resourceVersion(rt ResourceType) string {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	return t.resourceVersions[rt]
+}
+
+// adjacentView returns the resource type delta tabs away from rt, wrapping
+// around like Tab does
+func adjacentView(rt ResourceType, delta int) ResourceType {
+	count := len(loadingResourceTypes)
+	return ResourceType(((int(rt)+delta)%count + count) % count)
+}
+
+// switchView shows another resource type. Stale data is reloaded in the
+// background; fresh data lets the neighbouring tabs be prefetched right away.
+func (t *TUI) switchView(rt ResourceType) {
+	t.currentView = rt
+	t.selected = 0
+	if !t.refreshIfStale(rt) {
+		t.prefetchAdjacent()
+	}
+	t.saveSession()
+}
+
+// prefetchEvent asks the main loop to prefetch the tabs either side of the
+// current one, once a load of resourceType has settled
+type prefetchEvent struct {
+	tcell.EventTime
+	resourceType ResourceType
+}
+
+// requestPrefetch posts a prefetchEvent after an update, as the tabs to
+// prefetch depend on the state of the main loop
+func (t *TUI) requestPrefetch(rt ResourceType) {
+	if t.screen == nil {
+		return
+	}
+	event := &prefetchEvent{resourceType: rt}
+	event.SetEventNow()
+	t.screen.PostEvent(event)
+}
+
+// handlePrefetch warms up the tabs either side of the current one once it
+// has settled
+func (t *TUI) handlePrefetch(ev *prefetchEvent) {
+	if ev.resourceType == t.currentView && !t.loading {
+		t.prefetchAdjacent()
+	}
+}
+
+// prefetchAdjacent reloads the tabs either side of the current one when they
+// are stale, so that cycling through tabs shows fresh data straight away. It
+// runs on the main loop.
+func (t *TUI) prefetchAdjacent() {
+	t.refreshIfStale(t.nextAccessibleView(t.currentView, 1))
+	t.refreshIfStale(t.nextAccessibleView(t.currentView, -1))
+}
+
+// freshnessStatus describes the age of the current tab's data at now for the
+// status bar, e.g. "🕒 12s ago", with a ⟳ while it is being reloaded
+func (t *TUI) freshnessStatus(now time.Time) string {
+	t.freshnessMu.Lock()
+	lastUpdated := t.lastUpdated[t.currentView]
+	refreshing := t.refreshing[t.currentView]
+	failed := t.loadFailures[t.currentView] != nil
+	t.freshnessMu.Unlock()
+
+	status := "🕒 never loaded"
+	if !lastUpdated.IsZero() {
+		status = fmt.Sprintf("🕒 %s ago", util.HumanDuration(now.Sub(lastUpdated).Truncate(time.Second)))
+	}
+	if refreshing {
+		status += " ⟳"
+	} else if failed {
+		status += " ✘ load failed (R: retry)"
+	}
+	return status
+}
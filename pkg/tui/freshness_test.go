@@ -0,0 +1,272 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestIsStale(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		lastUpdated time.Time
+		maxAge      time.Duration
+		want        bool
+	}{
+		{"never loaded", time.Time{}, 30 * time.Second, true},
+		{"fresh", now.Add(-10 * time.Second), 30 * time.Second, false},
+		{"at the threshold", now.Add(-30 * time.Second), 30 * time.Second, true},
+		{"older than the threshold", now.Add(-time.Minute), 30 * time.Second, true},
+		{"auto refresh disabled", now, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStale(tt.lastUpdated, now, tt.maxAge); got != tt.want {
+				t.Errorf("isStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTUIShouldRefresh(t *testing.T) {
+	now := time.Now()
+	cfg := config.DefaultConfig()
+	cfg.UI.AutoRefresh = 30
+	tui := &TUI{
+		config: cfg,
+		lastUpdated: map[ResourceType]time.Time{
+			ResourcePods:        now.Add(-5 * time.Second),
+			ResourceDeployments: now.Add(-45 * time.Second),
+			ResourceServices:    now.Add(-45 * time.Second),
+		},
+		refreshing: map[ResourceType]bool{ResourceServices: true},
+	}
+
+	if tui.shouldRefresh(ResourcePods, now) {
+		t.Error("Expected pods loaded 5s ago to be fresh with a 30s auto refresh")
+	}
+	if !tui.shouldRefresh(ResourceDeployments, now) {
+		t.Error("Expected deployments loaded 45s ago to be refreshed")
+	}
+	if tui.shouldRefresh(ResourceServices, now) {
+		t.Error("Expected services that are already loading not to be loaded again")
+	}
+	if !tui.shouldRefresh(ResourceConfigMaps, now) {
+		t.Error("Expected configmaps that were never loaded to be refreshed")
+	}
+
+	tui.config.UI.AutoRefresh = 0
+	if !tui.shouldRefresh(ResourcePods, now) {
+		t.Error("Expected every tab switch to refresh without an auto refresh interval")
+	}
+}
+
+func TestTUISwitchViewRefreshesStaleTab(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.UI.AutoRefresh = 30
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	tui := &TUI{
+		clientset:   fake.NewSimpleClientset(deployment),
+		config:      cfg,
+		namespace:   "default",
+		currentView: ResourcePods,
+		selected:    3,
+		dataChan:    make(chan *DataUpdate, 10),
+		lastUpdated: map[ResourceType]time.Time{
+			ResourcePods:        time.Now(),
+			ResourceDeployments: time.Now().Add(-time.Minute),
+			ResourceServices:    time.Now(),
+		},
+		loadingCounter: 1,
+	}
+
+	tui.switchView(ResourceDeployments)
+	if tui.currentView != ResourceDeployments || tui.selected != 0 {
+		t.Fatalf("Expected the deployments tab with the selection reset, got %v/%d", tui.currentView, tui.selected)
+	}
+	if got := tui.freshnessStatus(time.Now()); !strings.HasSuffix(got, "⟳") {
+		t.Errorf("Expected the status bar to show the reload, got %q", got)
+	}
+	// Switching again while the load is in flight does not start another
+	tui.switchView(ResourceDeployments)
+
+	update := <-tui.dataChan
+	if update.ResourceType != ResourceDeployments || !update.Background || len(update.Deployments) != 1 {
+		t.Fatalf("Expected a background deployments update, got %+v", update)
+	}
+	select {
+	case extra := <-tui.dataChan:
+		t.Fatalf("Expected a single load, got another %v update", extra.ResourceType)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tui.handleDataUpdate(update)
+	if len(tui.deployments) != 1 || tui.loadingCounter != 1 {
+		t.Errorf("Expected the deployments without touching the loading counter, got %d and %d", len(tui.deployments), tui.loadingCounter)
+	}
+	if got := tui.freshnessStatus(time.Now()); got != "🕒 0s ago" {
+		t.Errorf("Unexpected freshness %q", got)
+	}
+
+	// The neighbours are prefetched only if stale: pods and services are
+	// fresh, so switching back to fresh deployments loads nothing
+	tui.switchView(ResourceDeployments)
+	select {
+	case extra := <-tui.dataChan:
+		t.Errorf("Expected no load for fresh tabs, got a %v update", extra.ResourceType)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A failed background reload keeps the last deployments
+	tui.handleDataUpdate(&DataUpdate{ResourceType: ResourceDeployments, Background: true, Error: fmt.Errorf("timeout")})
+	if len(tui.deployments) != 1 {
+		t.Errorf("Expected the deployments to be kept after a failed reload, got %d", len(tui.deployments))
+	}
+}
+
+func TestTUIPrefetchAdjacent(t *testing.T) {
+	tui := &TUI{
+		clientset:   fake.NewSimpleClientset(),
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		dataChan:    make(chan *DataUpdate, 10),
+		lastUpdated: map[ResourceType]time.Time{ResourcePods: time.Now()},
+	}
+
+	// Pods are fresh, so the switch prefetches the tabs on either side
+	tui.switchView(ResourcePods)
+	loaded := map[ResourceType]bool{}
+	for i := 0; i < 2; i++ {
+		update := <-tui.dataChan
+		if !update.Background {
+			t.Errorf("Expected prefetches to be background updates")
+		}
+		loaded[update.ResourceType] = true
+	}
+	if !loaded[ResourceDeployments] || !loaded[ResourceDaemonSets] {
+		t.Errorf("Expected deployments and daemonsets to be prefetched, got %v", loaded)
+	}
+	if adjacentView(ResourceDaemonSets, 1) != ResourcePods || adjacentView(ResourcePods, -1) != ResourceDaemonSets {
+		t.Error("Expected adjacent tabs to wrap around")
+	}
+}
+
+func TestTUIDropsUpdatesOfEarlierLoads(t *testing.T) {
+	tui := &TUI{
+		clientset: fake.NewSimpleClientset(
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default"}},
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "shop"}},
+		),
+		config:         config.DefaultConfig(),
+		namespace:      "default",
+		currentView:    ResourcePods,
+		dataChan:       make(chan *DataUpdate, 10),
+		loadGeneration: 1,
+		loadNamespace:  "default",
+	}
+
+	// A prefetch of the old namespace finishes after switching to shop
+	tui.freshnessMu.Lock()
+	stale := tui.newLoad(ResourcePods, true)
+	tui.freshnessMu.Unlock()
+	tui.namespace = "shop"
+	tui.freshnessMu.Lock()
+	tui.loadGeneration++
+	tui.loadNamespace = tui.namespace
+	current := tui.newLoad(ResourcePods, true)
+	tui.freshnessMu.Unlock()
+
+	tui.loadAsync(current)
+	tui.handleDataUpdate(<-tui.dataChan)
+	tui.loadAsync(stale)
+	update := <-tui.dataChan
+	if update.Namespace != "default" || update.Generation != 1 {
+		t.Fatalf("Expected the update to carry the load's namespace and generation, got %q/%d", update.Namespace, update.Generation)
+	}
+	tui.handleDataUpdate(update)
+	if len(tui.pods) != 1 || tui.pods[0].Name != "new" {
+		t.Errorf("Expected the pods of shop to be kept, got %+v", tui.pods)
+	}
+
+	// Watcher updates are not tied to a load
+	tui.handleDataUpdate(&DataUpdate{ResourceType: ResourceNodes, Nodes: []v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}, Background: true})
+	if len(tui.nodes) != 1 {
+		t.Errorf("Expected the watcher's nodes, got %+v", tui.nodes)
+	}
+}
+
+func TestTUIHandlePrefetch(t *testing.T) {
+	tui := &TUI{
+		clientset:   fake.NewSimpleClientset(),
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		dataChan:    make(chan *DataUpdate, 10),
+		lastUpdated: map[ResourceType]time.Time{ResourcePods: time.Now()},
+	}
+
+	// An update of another tab, or one while loading, prefetches nothing
+	tui.handlePrefetch(&prefetchEvent{resourceType: ResourceServices})
+	tui.loading = true
+	tui.handlePrefetch(&prefetchEvent{resourceType: ResourcePods})
+	select {
+	case update := <-tui.dataChan:
+		t.Fatalf("Expected no prefetch, got a %v update", update.ResourceType)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tui.loading = false
+	tui.handlePrefetch(&prefetchEvent{resourceType: ResourcePods})
+	loaded := map[ResourceType]bool{}
+	for i := 0; i < 2; i++ {
+		loaded[(<-tui.dataChan).ResourceType] = true
+	}
+	if !loaded[ResourceDeployments] || !loaded[ResourceDaemonSets] {
+		t.Errorf("Expected deployments and daemonsets to be prefetched, got %v", loaded)
+	}
+}
+
+// TestTUIReloadsFromLastResourceVersion tests that each load remembers its
+// list's resourceVersion for the next one
+func TestTUIReloadsFromLastResourceVersion(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	listed := "7"
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &v1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: listed}}, nil
+	})
+	tui := &TUI{clientset: clientset, namespace: "default", dataChan: make(chan *DataUpdate, 1)}
+
+	if rv := tui.resourceVersion(ResourcePods); rv != "" {
+		t.Fatalf("Expected no resourceVersion before the first load, got %q", rv)
+	}
+	tui.loadPodsAsync(tui.newLoad(ResourcePods, false))
+	update := <-tui.dataChan
+	if update.ResourceVersion != "7" {
+		t.Fatalf("Expected the list's resourceVersion, got %q", update.ResourceVersion)
+	}
+	tui.recordUpdate(update)
+	if rv := tui.resourceVersion(ResourcePods); rv != "7" {
+		t.Errorf("Expected to reload pods from 7, got %q", rv)
+	}
+
+	// A failed load keeps the last resourceVersion
+	tui.recordUpdate(&DataUpdate{ResourceType: ResourcePods, Error: fmt.Errorf("timeout")})
+	listed = "9"
+	tui.loadPodsAsync(tui.newLoad(ResourcePods, true))
+	tui.recordUpdate(<-tui.dataChan)
+	if rv := tui.resourceVersion(ResourcePods); rv != "9" {
+		t.Errorf("Expected the newer resourceVersion 9, got %q", rv)
+	}
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+// gatedBadge follows the status of pods held back by scheduling gates
+const gatedBadge = "[gated]"
+
+// getPodStatus returns a pod's status for the Status column: its phase, with
+// gatedBadge when scheduling gates hold it back, or its init progress, e.g.
+// "Init:1/2", while it is initializing
+func getPodStatus(pod v1.Pod) string {
+	if k8s.IsSchedulingGated(&pod) {
+		return fmt.Sprintf("%s %s", pod.Status.Phase, gatedBadge)
+	}
+	return k8s.PodStatusReason(pod)
+}
+
+// gateDetails returns the scheduling and readiness gate sections of a pod's
+// details, or nothing for a pod without gates
+func gateDetails(pod v1.Pod) []string {
+	var lines []string
+	if len(pod.Spec.SchedulingGates) > 0 {
+		lines = append(lines, "", "Scheduling Gates:")
+		for _, gate := range pod.Spec.SchedulingGates {
+			lines = append(lines, "  "+gate.Name)
+		}
+	}
+	if gates := k8s.GetReadinessGates(&pod); len(gates) > 0 {
+		lines = append(lines, "", "Readiness Gates:")
+		for _, gate := range gates {
+			lines = append(lines, fmt.Sprintf("  %s: %s", gate.ConditionType, gate.Status))
+		}
+	}
+	return lines
+}
+
+// drawGatedBadge redraws the gatedBadge after a gated pod's status in orange
+func (t *TUI) drawGatedBadge(pod v1.Pod, y int, layout tableLayout, style tcell.Style) {
+	x, width, ok := layout.cell(1)
+	if !ok || !k8s.IsSchedulingGated(&pod) {
+		return
+	}
+	offset := len(pod.Status.Phase) + 1
+	if offset >= width {
+		return
+	}
+	t.drawText(x+offset, y, width-offset, gatedBadge, style.Foreground(tcell.ColorOrange).Bold(true))
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUIPodGates tests the gate sections of pod details and the [gated]
+// badge of pods held back by scheduling gates
+func TestTUIPodGates(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+
+	gated := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch-1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers:      []v1.Container{{Name: "app"}},
+			SchedulingGates: []v1.PodSchedulingGate{{Name: "example.com/quota"}},
+			ReadinessGates:  []v1.PodReadinessGate{{ConditionType: "example.com/lb-ready"}, {ConditionType: "example.com/warm"}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodScheduled, Status: v1.ConditionFalse, Reason: v1.PodReasonSchedulingGated},
+				{Type: "example.com/lb-ready", Status: v1.ConditionTrue},
+			},
+		},
+	}
+	running := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	}
+	tui := &TUI{
+		screen:      screen,
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		viewMode:    ViewModeList,
+		pods:        []v1.Pod{gated, running},
+		selected:    -1,
+		theme:       DefaultTheme(),
+	}
+
+	details := strings.Join(tui.getPodDetails(gated), "\n")
+	for _, want := range []string{
+		"Status: Pending [gated]",
+		"Scheduling Gates:\n  example.com/quota",
+		"Readiness Gates:\n  example.com/lb-ready: True\n  example.com/warm: Unknown",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+	if details := strings.Join(tui.getPodDetails(running), "\n"); strings.Contains(details, "Gates") {
+		t.Errorf("Expected no gate sections for a pod without gates, got:\n%s", details)
+	}
+
+	tui.draw()
+	screen.Show()
+	colWidths := tui.getColumnWidths(120, len(tui.getTableHeaders()))
+	badgeX := 2 + colWidths[0] + 3 + len("Pending ")
+	cells, width, _ := screen.GetContents()
+	found := false
+	for y := 0; y*width < len(cells); y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				line.WriteRune(runes[0])
+			}
+		}
+		if strings.Contains(line.String(), "│ web-1 ") && strings.Contains(line.String(), gatedBadge) {
+			t.Errorf("Expected no badge for a running pod, got %q", line.String())
+		}
+		if !strings.Contains(line.String(), "│ batch-1 ") {
+			continue
+		}
+		found = true
+		if !strings.Contains(line.String(), "Pending [gated]") {
+			t.Errorf("Expected the gated badge after the status, got %q", line.String())
+		}
+		_, _, style, _ := screen.GetContent(badgeX, y)
+		if fg, _, _ := style.Decompose(); fg != tcell.ColorOrange {
+			t.Errorf("Expected an orange badge, got %v", fg)
+		}
+	}
+	if !found {
+		t.Error("Expected a row for the gated pod")
+	}
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/registry"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// imageErrorPrefix starts the details line of a failed manifest lookup, drawn
+// in red
+const imageErrorPrefix = "    ✘ Image lookup failed"
+
+// imageManifestLookup is the registry manifest of an image looked up with k
+type imageManifestLookup struct {
+	manifest *registry.Manifest
+	err      error
+}
+
+// imagePullPolicy returns the pull policy of a container, defaulted as the API
+// server does when it is unset: Always for :latest or untagged images,
+// IfNotPresent otherwise
+func imagePullPolicy(container v1.Container) v1.PullPolicy {
+	if container.ImagePullPolicy != "" {
+		return container.ImagePullPolicy
+	}
+	image, _, _ := strings.Cut(container.Image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") && image[i+1:] != "latest" {
+		return v1.PullIfNotPresent
+	}
+	return v1.PullAlways
+}
+
+// imagePullStatus describes whether the image of a container is on its node:
+// the image ID once pulled, or why it is not there yet
+func imagePullStatus(status *v1.ContainerStatus) string {
+	switch {
+	case status == nil:
+		return "not yet"
+	case status.ImageID != "":
+		return status.ImageID
+	case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+		return "no (" + status.State.Waiting.Reason + ")"
+	}
+	return "not yet"
+}
+
+// imageDetails returns the image lines of a pod's details: each container's
+// image with its pull policy badge and pull status, and the size and layers
+// of images looked up with k
+func (t *TUI) imageDetails(pod v1.Pod) []string {
+	statuses := make(map[string]*v1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for i := range pod.Status.ContainerStatuses {
+		statuses[pod.Status.ContainerStatuses[i].Name] = &pod.Status.ContainerStatuses[i]
+	}
+	inspection := t.config != nil && t.config.Features.EnableRegistryInspection
+
+	lines := []string{"", "Images:"}
+	for _, container := range pod.Spec.Containers {
+		lines = append(lines,
+			fmt.Sprintf("  %s: %s [%s]", container.Name, container.Image, imagePullPolicy(container)),
+			fmt.Sprintf("    Pulled: %s", imagePullStatus(statuses[container.Name])),
+		)
+		lookup := t.imageManifests[container.Image]
+		switch {
+		case lookup != nil && lookup.err != nil:
+			lines = append(lines, fmt.Sprintf("%s: %v", imageErrorPrefix, lookup.err))
+		case lookup != nil:
+			lines = append(lines, fmt.Sprintf("    Size: %s (%d layers)", formatSize(int(lookup.manifest.Size())), len(lookup.manifest.Layers)))
+		case inspection:
+			lines = append(lines, "    Size: press k to look it up in the registry")
+		}
+	}
+	return lines
+}
+
+// lookupSelectedPodImages fetches the registry manifests of the selected
+// pod's images for their size and layer count
+func (t *TUI) lookupSelectedPodImages() {
+	pod, ok := t.getSelectedResource().(v1.Pod)
+	if !ok {
+		return
+	}
+	if t.imageManifests == nil {
+		t.imageManifests = make(map[string]*imageManifestLookup)
+	}
+
+	if t.config == nil || !t.config.Features.EnableRegistryInspection {
+		for _, container := range pod.Spec.Containers {
+			t.imageManifests[container.Image] = &imageManifestLookup{
+				err: fmt.Errorf("registry inspection is disabled. Set features.enableRegistryInspection to look up images"),
+			}
+		}
+		return
+	}
+
+	t.loading = true
+	if t.screen != nil {
+		t.draw()
+		t.screen.Show()
+	}
+	looked := make(map[string]bool)
+	for _, container := range pod.Spec.Containers {
+		if looked[container.Image] {
+			continue
+		}
+		looked[container.Image] = true
+		lookup := &imageManifestLookup{}
+		lookup.manifest, lookup.err = registry.GetManifest(container.Image)
+		t.imageManifests[container.Image] = lookup
+	}
+	t.loading = false
+}
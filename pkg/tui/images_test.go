@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/registry"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUIPodImages tests the image lines of pod details: pull policy badges,
+// pull status, and the size looked up with k
+func TestTUIPodImages(t *testing.T) {
+	var requests int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v2/team/app/manifests/v1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", registry.MediaTypeDockerManifest)
+		w.Write([]byte(`{"schemaVersion": 2, "mediaType": "` + registry.MediaTypeDockerManifest + `",
+			"config": {"digest": "sha256:c0", "size": 1024},
+			"layers": [{"digest": "sha256:l1", "size": 1048576}, {"digest": "sha256:l2", "size": 523264}]}`))
+	}))
+	defer server.Close()
+	previous := registry.DefaultClient
+	registry.DefaultClient = registry.NewClient(registry.WithHTTPClient(server.Client()))
+	defer func() { registry.DefaultClient = previous }()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "app", Image: host + "/team/app:v1"},
+			{Name: "sidecar", Image: host + "/team/app:v1", ImagePullPolicy: v1.PullNever},
+			{Name: "proxy", Image: host + "/team/proxy"},
+		}},
+		Status: v1.PodStatus{Phase: v1.PodPending, ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", ImageID: host + "/team/app@sha256:abc"},
+			{Name: "proxy", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+		}},
+	}
+	tui := &TUI{
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		viewMode:    ViewModeDetails,
+		pods:        []v1.Pod{pod},
+		selected:    0,
+		theme:       DefaultTheme(),
+	}
+
+	details := strings.Join(tui.getPodDetails(pod), "\n")
+	for _, want := range []string{
+		"  app: " + host + "/team/app:v1 [IfNotPresent]\n    Pulled: " + host + "/team/app@sha256:abc",
+		"  sidecar: " + host + "/team/app:v1 [Never]\n    Pulled: not yet",
+		"  proxy: " + host + "/team/proxy [Always]\n    Pulled: no (ImagePullBackOff)",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+	if strings.Contains(details, "Size:") {
+		t.Errorf("Expected no size lines while registry inspection is disabled, got:\n%s", details)
+	}
+
+	tui.lookupSelectedPodImages()
+	if requests != 0 || !strings.Contains(strings.Join(tui.getPodDetails(pod), "\n"), imageErrorPrefix+": registry inspection is disabled") {
+		t.Errorf("Expected no lookup while registry inspection is disabled, got %d requests", requests)
+	}
+
+	tui.config.Features.EnableRegistryInspection = true
+	tui.imageManifests = nil
+	if details := strings.Join(tui.getPodDetails(pod), "\n"); !strings.Contains(details, "Size: press k") {
+		t.Errorf("Expected a hint to press k, got:\n%s", details)
+	}
+	tui.lookupSelectedPodImages()
+	if requests != 2 {
+		t.Errorf("Expected one lookup per distinct image, got %d requests", requests)
+	}
+	details = strings.Join(tui.getPodDetails(pod), "\n")
+	for _, want := range []string{
+		"[IfNotPresent]\n    Pulled: " + host + "/team/app@sha256:abc\n    Size: 1.5MiB (2 layers)",
+		"[Always]\n    Pulled: no (ImagePullBackOff)\n" + imageErrorPrefix + ": registry " + host + " answered 404 Not Found",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+	if style := detailsLineStyle(imageErrorPrefix + ": boom"); style != tcell.StyleDefault.Foreground(tcell.ColorRed) {
+		t.Errorf("Expected failed lookups in red")
+	}
+}
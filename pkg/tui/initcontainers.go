@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/util"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+// initStepper renders the init containers of a pod as the steps of its
+// initialization, ending with its app containers, e.g.
+// "[✓ init-db 3s] → [⠋ init-config] → [ app]". Running steps show the
+// spinner frame, succeeded ones how long they took and failed ones their
+// exit code. Running sidecars show ↻.
+func initStepper(pod v1.Pod, spinner rune) string {
+	steps := k8s.GetInitContainerProgress(pod)
+	parts := make([]string, 0, len(steps)+1)
+	for _, step := range steps {
+		var part string
+		switch {
+		case step.Restartable && step.State == k8s.InitContainerRunning:
+			// A running sidecar no longer holds the next step back
+			part = "[↻ " + step.Name + "]"
+		case step.State == k8s.InitContainerRunning:
+			part = fmt.Sprintf("[%c %s]", spinner, step.Name)
+		case step.State == k8s.InitContainerSucceeded:
+			part = "[✓ " + step.Name
+			if step.Duration != nil {
+				part += " " + util.HumanDuration(step.Duration.Truncate(time.Second))
+			}
+			part += "]"
+		case step.State == k8s.InitContainerFailed:
+			part = fmt.Sprintf("[✗ %s exit %d]", step.Name, step.ExitCode)
+		default:
+			part = "[ " + step.Name + "]"
+		}
+		parts = append(parts, part)
+	}
+
+	names := make([]string, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	app := "[ " + strings.Join(names, ",") + "]"
+	if !k8s.IsInitializing(pod) {
+		app = "[▶ " + strings.Join(names, ",") + "]"
+	}
+	return strings.Join(append(parts, app), " → ")
+}
+
+// initContainerDetails returns the Init Containers section of a pod's
+// details: the steps, then each init container with its state. It is empty
+// for a pod without init containers.
+func (t *TUI) initContainerDetails(pod v1.Pod) []string {
+	if len(pod.Spec.InitContainers) == 0 {
+		return nil
+	}
+	title := "Init Containers:"
+	if k8s.IsInitializing(pod) {
+		title = fmt.Sprintf("Init Containers (%s):", k8s.PodStatusReason(pod))
+	}
+	lines := []string{"", title, "  " + initStepper(pod, t.spinner.Frame())}
+	for _, step := range k8s.GetInitContainerProgress(pod) {
+		name := step.Name
+		if step.Restartable {
+			name += " (sidecar)"
+		}
+		state := string(step.State)
+		switch {
+		case step.Restartable && step.State == k8s.InitContainerRunning && step.Ready:
+			state = "running, ready"
+		case step.Restartable && step.State == k8s.InitContainerRunning:
+			state = "running, not ready"
+		case step.State == k8s.InitContainerSucceeded && step.Duration != nil:
+			state = "completed in " + util.HumanDuration(step.Duration.Truncate(time.Second))
+		case step.State == k8s.InitContainerSucceeded:
+			state = "completed"
+		case step.State == k8s.InitContainerFailed:
+			state = fmt.Sprintf("failed, exit code %d", step.ExitCode)
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, state))
+	}
+	return lines
+}
+
+// drawInitStepper draws the init stepper of the selected pod over the
+// columns after its status while the pod is initializing, up to the right
+// border of the table
+func (t *TUI) drawInitStepper(pod v1.Pod, y, width int, layout tableLayout, style tcell.Style) {
+	x, _, ok := layout.cell(2)
+	if !ok || x >= width-2 || !k8s.IsInitializing(pod) {
+		return
+	}
+	stepper := initStepper(pod, t.spinner.Next())
+	if n := len([]rune(stepper)); n < width-2-x {
+		stepper += strings.Repeat(" ", width-2-x-n)
+	}
+	t.drawText(x, y, width-2-x, stepper, style)
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUIInitStepper tests the init container steps of a pod, drawn in its
+// row while it is selected and initializing
+func TestTUIInitStepper(t *testing.T) {
+	screen := newTestScreen(t, 160, 30)
+
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "init-db"}, {Name: "init-config"}, {Name: "init-cache"}},
+			Containers:     []v1.Container{{Name: "app"}},
+		},
+		Status: v1.PodStatus{
+			Phase:      v1.PodPending,
+			Conditions: []v1.PodCondition{{Type: v1.PodInitialized, Status: v1.ConditionFalse}},
+			InitContainerStatuses: []v1.ContainerStatus{
+				{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+					StartedAt: start, FinishedAt: metav1.NewTime(start.Add(3 * time.Second)),
+				}}},
+				{Name: "init-config", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+			},
+		},
+	}
+	if got, want := initStepper(pod, '*'), "[✓ init-db 3s] → [* init-config] → [ init-cache] → [ app]"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	tui := &TUI{
+		screen:      screen,
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		pods:        []v1.Pod{pod},
+		theme:       DefaultTheme(),
+	}
+	tui.drawResourceTable(160, 20, 5)
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	var row strings.Builder
+	for x := 0; x < width; x++ {
+		row.WriteString(string(cells[8*width+x].Runes))
+	}
+	if !strings.Contains(row.String(), "[✓ init-db 3s] → [") || !strings.Contains(row.String(), "web") {
+		t.Errorf("Expected the steps in the selected row, got %q", row.String())
+	}
+
+	details := strings.Join(tui.initContainerDetails(pod), "\n")
+	if !strings.Contains(details, "Init Containers (Init:1/3):") || !strings.Contains(details, "  init-db: completed in 3s") ||
+		!strings.Contains(details, "  init-config: running") || !strings.Contains(details, "  init-cache: pending") {
+		t.Errorf("Expected the init containers in the details, got %q", details)
+	}
+	if status, ready := getPodStatus(pod), tui.getReadyCount(pod); status != "Init:1/3" || ready != "0/1" {
+		t.Errorf("Expected Init:1/3 and 0/1 ready, got %s and %s", status, ready)
+	}
+
+	// A failed step shows its exit code, and an initialized pod its app
+	pod.Status.InitContainerStatuses[1].State = v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1}}
+	if got := initStepper(pod, '*'); !strings.Contains(got, "[✗ init-config exit 1]") {
+		t.Errorf("Expected the exit code of init-config, got %q", got)
+	}
+	if got := getPodStatus(pod); got != "Init:ExitCode:1" {
+		t.Errorf("Expected Init:ExitCode:1, got %s", got)
+	}
+	pod.Status.Conditions[0].Status = v1.ConditionTrue
+	if got := initStepper(pod, '*'); !strings.HasSuffix(got, "→ [▶ app]") {
+		t.Errorf("Expected the app to be started, got %q", got)
+	}
+
+	// A running sidecar is a step of its own, left out of the ready count
+	always := v1.ContainerRestartPolicyAlways
+	started := true
+	sidecar := v1.Pod{
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "proxy", RestartPolicy: &always}},
+			Containers:     []v1.Container{{Name: "app"}},
+		},
+		Status: v1.PodStatus{
+			Phase:                 v1.PodRunning,
+			InitContainerStatuses: []v1.ContainerStatus{{Name: "proxy", Ready: true, Started: &started, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}},
+			ContainerStatuses:     []v1.ContainerStatus{{Name: "app", Ready: true}},
+		},
+	}
+	if got := initStepper(sidecar, '*'); got != "[↻ proxy] → [▶ app]" {
+		t.Errorf("Expected the running sidecar, got %q", got)
+	}
+	if details := strings.Join(tui.initContainerDetails(sidecar), "\n"); !strings.Contains(details, "  proxy (sidecar): running, ready") {
+		t.Errorf("Expected the ready sidecar in the details, got %q", details)
+	}
+	if status, ready := getPodStatus(sidecar), tui.getReadyCount(sidecar); status != "Running" || ready != "1/1" {
+		t.Errorf("Expected Running and 1/1 ready, got %s and %s", status, ready)
+	}
+}
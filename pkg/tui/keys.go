@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// scrollToEnd moves the selection or a scrolled view as far as it goes;
+// views clamp their scroll when drawn
+const scrollToEnd = math.MaxInt32
+
+// vimKeys reports whether the vim key bindings are active: j/k move, h/l
+// switch tabs, l opens logs and Left/Right scroll tables sideways. With
+// ui.keyBindings "classic", Left/Right move, h shows help, j opens logs and
+// k looks up images, as before.
+func (t *TUI) vimKeys() bool {
+	return t.config == nil || t.config.UI.KeyBindings != config.KeyBindingsClassic
+}
+
+// navigationKey turns j and k into Down and Up with the vim key bindings, so
+// that every view moving with the arrows moves with them as well. Views
+// where they are typed, such as the editor, get the key before.
+func (t *TUI) navigationKey(ev *tcell.EventKey) *tcell.EventKey {
+	if !t.vimKeys() || ev.Key() != tcell.KeyRune {
+		return ev
+	}
+	switch ev.Rune() {
+	case 'j':
+		return tcell.NewEventKey(tcell.KeyDown, 0, ev.Modifiers())
+	case 'k':
+		return tcell.NewEventKey(tcell.KeyUp, 0, ev.Modifiers())
+	}
+	return ev
+}
+
+// navigate moves the selection of the list by delta rows, or scrolls the
+// other views by delta lines, down being positive
+func (t *TUI) navigate(delta int) {
+	switch t.viewMode {
+	case ViewModeList:
+		t.moveSelection(delta)
+	case ViewModeDetails, ViewModeYAML, ViewModeRollout:
+		t.detailsScroll = max(t.detailsScroll+delta, 0)
+	case ViewModeDiff:
+		if t.diffView != nil {
+			t.diffView.scroll = max(t.diffView.scroll+delta, 0)
+		}
+	case ViewModeLogs:
+		// The log view counts lines back from the newest one
+		t.logsScroll = max(t.logsScroll-delta, 0)
+	case ViewModeRelationships:
+		t.relationshipsScroll = max(t.relationshipsScroll+delta, 0)
+	}
+}
+
+// halfPage is how far Ctrl-D and Ctrl-U move
+func (t *TUI) halfPage() int {
+	_, height := t.screen.Size()
+	return max((height-4)/2, 1)
+}
+
+// scrollTable scrolls the list table sideways by delta columns; laying it
+// out keeps it within the columns
+func (t *TUI) scrollTable(delta int) {
+	if t.viewMode == ViewModeList {
+		t.columnScroll = max(t.columnScroll+delta, 0)
+	}
+}
+
+// clampScroll brings a view of lines, visible at a time, that is scrolled past
+// its last line back to its last page
+func clampScroll(scroll, lines, visible int) int {
+	if scroll < lines {
+		return scroll
+	}
+	return max(lines-visible, 0)
+}
+
+// helpLine formats a key and what it does as a line of help
+func helpLine(key, text string) string {
+	return fmt.Sprintf("   %-12s%s", key, text)
+}
+
+// navigationHelpLines returns the help on moving around with the active key
+// bindings
+func (t *TUI) navigationHelpLines() []string {
+	if !t.vimKeys() {
+		return []string{
+			helpLine("↑↓, ←→", "Navigate through resources; ↑↓ scroll details, YAML and logs"),
+			helpLine("^D, ^U", "Move half a page down or up"),
+			helpLine("gg, G", "Go to the top or the bottom"),
+			helpLine("Tab", "Switch between resource types"),
+			helpLine("Shift+←→", "Scroll the columns of tables wider than the terminal into view"),
+		}
+	}
+	return []string{
+		helpLine("↑↓, j/k", "Navigate through resources; scroll details, YAML and logs"),
+		helpLine("^D, ^U", "Move half a page down or up"),
+		helpLine("gg, G", "Go to the top or the bottom"),
+		helpLine("h/l, Tab", "Previous or next resource type"),
+		helpLine("←→", "Scroll the columns of tables wider than the terminal into view"),
+	}
+}
+
+// schemeKeys returns the keys of the bindings the schemes moved: the log
+// view, looking up images and help
+func (t *TUI) schemeKeys() (logs, images, help string) {
+	if !t.vimKeys() {
+		return "j", "k", "?, h"
+	}
+	return "l", "i", "?"
+}
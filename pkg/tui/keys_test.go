@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// keyBindingsTUI returns a TUI listing 30 pods on a screen too narrow for the
+// pod table, with the given key bindings
+func keyBindingsTUI(t *testing.T, keyBindings string) (*TUI, tcell.SimulationScreen) {
+	var pods []v1.Pod
+	for i := 0; i < 30; i++ {
+		pods = append(pods, v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%02d", i), Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}}},
+		})
+	}
+	cfg := config.DefaultConfig()
+	cfg.UI.KeyBindings = keyBindings
+	tui, screen := newTestTUI(t, 60, 20, &pods[0])
+	tui.config = cfg
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeList
+	tui.pods = pods
+	t.Cleanup(tui.closeLogs)
+	return tui, screen
+}
+
+// pressKeys feeds a script of keys to the main loop's dispatch: runes are
+// typed as they are, <name> stands for a special key and <s-name> for it
+// with Shift
+func pressKeys(t *testing.T, tui *TUI, script string) {
+	special := map[string]tcell.Key{
+		"enter": tcell.KeyEnter, "esc": tcell.KeyEscape, "left": tcell.KeyLeft, "right": tcell.KeyRight,
+		"up": tcell.KeyUp, "down": tcell.KeyDown, "c-d": tcell.KeyCtrlD, "c-u": tcell.KeyCtrlU,
+	}
+	for script != "" {
+		if name, rest, ok := strings.Cut(script[1:], ">"); script[0] == '<' && ok {
+			mod := tcell.ModNone
+			if unshifted, shifted := strings.CutPrefix(name, "s-"); shifted {
+				name, mod = unshifted, tcell.ModShift
+			}
+			key, known := special[name]
+			if !known {
+				t.Fatalf("Unknown key <%s>", name)
+			}
+			tui.handleKey(tcell.NewEventKey(key, 0, mod))
+			script = rest
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(script)
+		tui.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		script = script[size:]
+	}
+}
+
+func TestTUIVimKeys(t *testing.T) {
+	tui, screen := keyBindingsTUI(t, config.KeyBindingsVim)
+	drawnText := func() string {
+		screen.Clear()
+		tui.draw()
+		screen.Show()
+		return screenText(screen)
+	}
+
+	steps := []struct {
+		script   string
+		selected int
+	}{
+		{"jjj", 3},
+		{"k", 2},
+		{"G", 29},
+		{"gg", 0},
+		// Another key in between cancels the first g
+		{"jjgjg", 3},
+		{"g", 0},
+		{"<c-d>", 8},
+		{"<c-d><c-u>", 8},
+		{"<c-u>", 0},
+		// Left and Right leave the selection alone
+		{"j<right><left><right>", 1},
+	}
+	for _, step := range steps {
+		pressKeys(t, tui, step.script)
+		if tui.selected != step.selected {
+			t.Errorf("Expected %q to select pod %d, got %d", step.script, step.selected, tui.selected)
+		}
+	}
+
+	// Right scrolled the table sideways by a column, and the list follows
+	// the selection
+	if text := drawnText(); tui.columnScroll != 1 || !strings.Contains(text, "◀ 2 more columns ▶") || strings.Contains(text, "Status") {
+		t.Errorf("Expected the table scrolled past the Status column, got:\n%s", text)
+	}
+	pressKeys(t, tui, "<left>G")
+	if text := drawnText(); tui.columnScroll != 0 || !strings.Contains(text, "pod-29") || strings.Contains(text, "pod-00") {
+		t.Errorf("Expected the end of the unscrolled table, got:\n%s", text)
+	}
+
+	// h and l switch tabs
+	pressKeys(t, tui, "l")
+	if tui.currentView != ResourceDeployments {
+		t.Errorf("Expected l to switch to deployments, got %v", tui.currentView)
+	}
+	pressKeys(t, tui, "hh")
+	if tui.currentView != ResourceDaemonSets {
+		t.Errorf("Expected h to switch back past pods to daemonsets, got %v", tui.currentView)
+	}
+	pressKeys(t, tui, "l")
+
+	// In pod details j/k scroll, G stops at the last line and l opens logs
+	tui.pods = tui.pods[:1]
+	tui.selected = 0
+	pressKeys(t, tui, "<enter>jjk")
+	if tui.viewMode != ViewModeDetails || tui.detailsScroll != 1 {
+		t.Fatalf("Expected details scrolled by a line, got view %v scrolled by %d", tui.viewMode, tui.detailsScroll)
+	}
+	pressKeys(t, tui, "G")
+	drawnText()
+	if lines := len(tui.getResourceDetails(tui.pods[0])); tui.detailsScroll != max(lines-16, 0) {
+		t.Errorf("Expected G to scroll to the last of %d lines, got %d", lines, tui.detailsScroll)
+	}
+	pressKeys(t, tui, "l")
+	if tui.viewMode != ViewModeLogs {
+		t.Errorf("Expected l to open the logs, got view %v", tui.viewMode)
+	}
+	pressKeys(t, tui, "<esc>")
+
+	// Help lists the active keys
+	screen.SetSize(120, 80)
+	pressKeys(t, tui, "?")
+	help := drawnText()
+	for _, want := range []string{"↑↓, j/k", "h/l, Tab    Previous or next resource type", "l           Logs view", "i           Look up image", "?           Show this help"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("Expected %q in help, got:\n%s", want, help)
+		}
+	}
+	pressKeys(t, tui, "x")
+	if tui.showHelp {
+		t.Error("Expected any key to close help")
+	}
+}
+
+func TestTUIClassicKeys(t *testing.T) {
+	tui, _ := keyBindingsTUI(t, config.KeyBindingsClassic)
+
+	pressKeys(t, tui, "<right><right><down><left>")
+	if tui.selected != 2 || tui.columnScroll != 0 {
+		t.Errorf("Expected Left and Right to move the selection, got %d scrolled by %d", tui.selected, tui.columnScroll)
+	}
+	pressKeys(t, tui, "<s-right><s-right><s-left>")
+	if tui.selected != 2 || tui.columnScroll != 1 {
+		t.Errorf("Expected Shift with Left and Right to scroll, got %d scrolled by %d", tui.selected, tui.columnScroll)
+	}
+	pressKeys(t, tui, "lG")
+	if tui.currentView != ResourcePods || tui.selected != 29 {
+		t.Errorf("Expected l to do nothing and G to go to the bottom, got %v at %d", tui.currentView, tui.selected)
+	}
+
+	pressKeys(t, tui, "h")
+	if !tui.showHelp {
+		t.Fatal("Expected h to show help")
+	}
+	help := strings.Join(tui.navigationHelpLines(), "\n")
+	if !strings.Contains(help, "↑↓, ←→") || strings.Contains(help, "h/l") {
+		t.Errorf("Expected the classic navigation in help, got:\n%s", help)
+	}
+	if logs, images, helpKey := tui.schemeKeys(); logs != "j" || images != "k" || helpKey != "?, h" {
+		t.Errorf("Expected the classic keys in help, got %s, %s and %s", logs, images, helpKey)
+	}
+	pressKeys(t, tui, "x")
+
+	tui.selected = 0
+	pressKeys(t, tui, "<enter>j")
+	if tui.viewMode != ViewModeLogs {
+		t.Errorf("Expected j to open the logs in pod details, got view %v", tui.viewMode)
+	}
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// actionStatusDuration is how long the status bar shows the outcome of an action
+const actionStatusDuration = 10 * time.Second
+
+// actionStatus is the outcome of the last mutating action, shown in the
+// status bar with the kubectl command doing the same
+type actionStatus struct {
+	message string
+	kubectl string
+	until   time.Time
+	copied  bool
+}
+
+// recordAction shows the outcome of a successful action and its kubectl
+// equivalent in the status bar
+func (t *TUI) recordAction(message, kubectl string) {
+	klog.Infof("%s (kubectl equivalent: %s)", message, kubectl)
+	t.lastAction = &actionStatus{
+		message: message,
+		kubectl: kubectl,
+		until:   time.Now().Add(actionStatusDuration),
+	}
+}
+
+// copyLastKubectl copies the kubectl equivalent of the last action to the
+// clipboard, and shows it in the status bar again
+func (t *TUI) copyLastKubectl() {
+	if t.lastAction == nil {
+		return
+	}
+	t.screen.SetClipboard([]byte(t.lastAction.kubectl))
+	t.lastAction.copied = true
+	t.lastAction.until = time.Now().Add(actionStatusDuration)
+}
+
+// actionStatusText returns the status bar text of the last action, or an
+// empty string once it has been shown for actionStatusDuration
+func (t *TUI) actionStatusText(now time.Time) string {
+	if t.lastAction == nil || !now.Before(t.lastAction.until) {
+		return ""
+	}
+	hint := "K: copy"
+	if t.lastAction.copied {
+		hint = "copied"
+	}
+	return fmt.Sprintf("✔ %s | $ %s | %s", t.lastAction.message, t.lastAction.kubectl, hint)
+}
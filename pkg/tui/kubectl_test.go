@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUIKubectlEquivalent tests that a delete shows its kubectl equivalent
+// in the status bar and that K copies it to the clipboard
+func TestTUIKubectlEquivalent(t *testing.T) {
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web 1", Namespace: "default"}}
+	tui, screen := newTestTUI(t, 120, 30, &pod)
+	tui.currentView = ResourcePods
+	tui.pods = []v1.Pod{pod}
+
+	if tui.actionStatusText(time.Now()) != "" {
+		t.Error("Expected no action status before any action")
+	}
+	tui.copyLastKubectl()
+
+	screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone)
+	tui.deleteSelectedResource()
+
+	want := "kubectl -n default delete pod 'web 1'"
+	status := tui.actionStatusText(time.Now())
+	if !strings.Contains(status, "Deleted pod 'web 1'") || !strings.Contains(status, want) || !strings.Contains(status, "K: copy") {
+		t.Errorf("Expected the delete and %s in the status, got %q", want, status)
+	}
+
+	tui.copyLastKubectl()
+	if got := string(screen.GetClipboardData()); got != want {
+		t.Errorf("Expected %s on the clipboard, got %q", want, got)
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.HasSuffix(status, "copied") {
+		t.Errorf("Expected the status to confirm the copy, got %q", status)
+	}
+	if status := tui.actionStatusText(time.Now().Add(actionStatusDuration)); status != "" {
+		t.Errorf("Expected the status to clear after %v, got %q", actionStatusDuration, status)
+	}
+}
@@ -0,0 +1,194 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// loadTimeoutError is returned for a load that took longer than
+// ui.loadTimeoutSeconds. It is a context.DeadlineExceeded, and keeps the
+// timeout for the status bar to name.
+type loadTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *loadTimeoutError) Error() string {
+	return context.DeadlineExceeded.Error()
+}
+
+func (e *loadTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// loadTimeout returns how long a load may take, 0 for no limit
+func (t *TUI) loadTimeout() time.Duration {
+	if t.config == nil {
+		return 0
+	}
+	return time.Duration(t.config.UI.LoadTimeoutSeconds) * time.Second
+}
+
+// timeoutSource gives up on the lists of a data source after a timeout. The
+// lists take no context, so one that times out is left to finish in the
+// background and its result dropped.
+type timeoutSource struct {
+	source  DataSource
+	timeout time.Duration
+}
+
+// withTimeout runs list, returning a loadTimeoutError when it does not
+// return within timeout
+func withTimeout[T any](timeout time.Duration, list func() (T, string, error)) (T, string, error) {
+	type result struct {
+		items           T
+		resourceVersion string
+		err             error
+	}
+	done := make(chan result, 1)
+	go func() {
+		items, resourceVersion, err := list()
+		done <- result{items, resourceVersion, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.items, r.resourceVersion, r.err
+	case <-timer.C:
+		var none T
+		return none, "", &loadTimeoutError{timeout: timeout}
+	}
+}
+
+func (s *timeoutSource) ListPods(namespace, resourceVersion string) ([]v1.Pod, string, error) {
+	return withTimeout(s.timeout, func() ([]v1.Pod, string, error) {
+		return s.source.ListPods(namespace, resourceVersion)
+	})
+}
+
+func (s *timeoutSource) ListDeployments(namespace, resourceVersion string) ([]appsv1.Deployment, string, error) {
+	return withTimeout(s.timeout, func() ([]appsv1.Deployment, string, error) {
+		return s.source.ListDeployments(namespace, resourceVersion)
+	})
+}
+
+func (s *timeoutSource) ListServices(namespace, resourceVersion string) ([]v1.Service, string, error) {
+	return withTimeout(s.timeout, func() ([]v1.Service, string, error) {
+		return s.source.ListServices(namespace, resourceVersion)
+	})
+}
+
+func (s *timeoutSource) ListConfigMaps(namespace, resourceVersion string) ([]k8s.ConfigMapSummary, string, error) {
+	return withTimeout(s.timeout, func() ([]k8s.ConfigMapSummary, string, error) {
+		return s.source.ListConfigMaps(namespace, resourceVersion)
+	})
+}
+
+func (s *timeoutSource) GetConfigMap(namespace, name string) (*v1.ConfigMap, error) {
+	configMap, _, err := withTimeout(s.timeout, func() (*v1.ConfigMap, string, error) {
+		configMap, err := s.source.GetConfigMap(namespace, name)
+		return configMap, "", err
+	})
+	return configMap, err
+}
+
+func (s *timeoutSource) ListNamespaces() ([]v1.Namespace, error) {
+	namespaces, _, err := withTimeout(s.timeout, func() ([]v1.Namespace, string, error) {
+		namespaces, err := s.source.ListNamespaces()
+		return namespaces, "", err
+	})
+	return namespaces, err
+}
+
+func (s *timeoutSource) ListNodes() ([]v1.Node, error) {
+	nodes, _, err := withTimeout(s.timeout, func() ([]v1.Node, string, error) {
+		nodes, err := s.source.ListNodes()
+		return nodes, "", err
+	})
+	return nodes, err
+}
+
+func (s *timeoutSource) ListStatefulSets(namespace string) ([]appsv1.StatefulSet, error) {
+	statefulSets, _, err := withTimeout(s.timeout, func() ([]appsv1.StatefulSet, string, error) {
+		statefulSets, err := s.source.ListStatefulSets(namespace)
+		return statefulSets, "", err
+	})
+	return statefulSets, err
+}
+
+func (s *timeoutSource) ListPVCs(namespace string) ([]v1.PersistentVolumeClaim, error) {
+	pvcs, _, err := withTimeout(s.timeout, func() ([]v1.PersistentVolumeClaim, string, error) {
+		pvcs, err := s.source.ListPVCs(namespace)
+		return pvcs, "", err
+	})
+	return pvcs, err
+}
+
+func (s *timeoutSource) ListDaemonSets(namespace string) ([]appsv1.DaemonSet, error) {
+	daemonSets, _, err := withTimeout(s.timeout, func() ([]appsv1.DaemonSet, string, error) {
+		daemonSets, err := s.source.ListDaemonSets(namespace)
+		return daemonSets, "", err
+	})
+	return daemonSets, err
+}
+
+// recordLoadError records how the last load of a resource type ended: its
+// error, or nil once it loaded. Lists a retry cannot fix are left out: a
+// refused one shows the lock on its tab, and one the data source does not
+// serve has not failed.
+func (t *TUI) recordLoadError(rt ResourceType, err error) {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	if err == nil || !isRetriableLoadError(err) {
+		delete(t.lastErrors, rt)
+		return
+	}
+	if t.lastErrors == nil {
+		t.lastErrors = make(map[ResourceType]error)
+	}
+	t.lastErrors[rt] = err
+}
+
+// loadErrorOf returns the error of the last load of a resource type, or nil
+func (t *TUI) loadErrorOf(rt ResourceType) error {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	return t.lastErrors[rt]
+}
+
+// loadErrorStatus lists the resource types whose last load failed for the
+// status bar, e.g. "⚠ Pods: timeout after 15s", in the order of the tabs
+func (t *TUI) loadErrorStatus() string {
+	var failed []string
+	for _, rt := range loadingResourceTypes {
+		err := t.loadErrorOf(rt)
+		if err == nil {
+			continue
+		}
+		reason := "load failed"
+		var timeoutErr *loadTimeoutError
+		if errors.As(err, &timeoutErr) {
+			reason = fmt.Sprintf("timeout after %s", timeoutErr.timeout)
+		}
+		failed = append(failed, fmt.Sprintf("%s: %s", rt.DisplayName(), reason))
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return "⚠ " + strings.Join(failed, ", ")
+}
+
+// loadErrorMessage describes the failed last load of a resource type, shown
+// in place of its table and details
+func loadErrorMessage(err error) string {
+	return "Failed to load: " + err.Error()
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestTUILoadTimeout tests that a list taking longer than the load timeout
+// fails with context.DeadlineExceeded, which marks the tab, the status bar
+// and the details, and that r clears the error and loads again
+func TestTUILoadTimeout(t *testing.T) {
+	tui, screen := newTestTUI(t, 160, 30, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	clientset := tui.clientset.(*fake.Clientset)
+	var slow atomic.Bool
+	slow.Store(true)
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if slow.Load() {
+			time.Sleep(1500 * time.Millisecond)
+		}
+		return false, nil, nil
+	})
+	cfg := config.DefaultConfig()
+	cfg.UI.LoadRetries = 0
+	cfg.UI.LoadTimeoutSeconds = 1
+	tui.config = cfg
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeList
+	tui.dataChan = make(chan *DataUpdate, len(loadingResourceTypes))
+	tui.loadingCounter = 1
+	tui.loading = true
+
+	start := time.Now()
+	tui.loadAsync(tui.newLoad(ResourcePods, false))
+	update := <-tui.dataChan
+	if !errors.Is(update.Error, context.DeadlineExceeded) || time.Since(start) > 1400*time.Millisecond {
+		t.Fatalf("Expected the list to give up after 1s, got %v after %s", update.Error, time.Since(start))
+	}
+	tui.retryLoad(update)
+	tui.handleDataUpdate(update)
+	if err := tui.loadErrorOf(ResourcePods); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the timeout to be recorded, got %v", err)
+	}
+
+	tui.draw()
+	screen.Show()
+	text := screenText(screen)
+	for _, want := range []string{"1.Pods ◀!", "⚠ Pods: timeout after 1s", "Failed to load Pods after 1 attempt: context deadline exceeded"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q on screen, got:\n%s", want, text)
+		}
+	}
+	cells, width, _ := screen.GetContents()
+	tabs := []rune(strings.Split(text, "\n")[3])
+	for x, r := range tabs {
+		if r == '!' {
+			if fg, _, _ := cells[3*width+x].Style.Decompose(); fg != tcell.ColorRed {
+				t.Errorf("Expected a red ! on the tab, got %v", fg)
+			}
+			break
+		}
+	}
+
+	tui.viewMode = ViewModeDetails
+	tui.draw()
+	screen.Show()
+	if text := screenText(screen); !strings.Contains(text, "Failed to load: context deadline exceeded") {
+		t.Errorf("Expected the details to show the error, got:\n%s", text)
+	}
+	tui.viewMode = ViewModeList
+
+	// r clears the errors and loads everything again
+	slow.Store(false)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone))
+	if tui.loadErrorStatus() != "" {
+		t.Errorf("Expected r to clear the errors, got %q", tui.loadErrorStatus())
+	}
+	for range loadingResourceTypes {
+		select {
+		case update := <-tui.dataChan:
+			tui.handleDataUpdate(update)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the reload")
+		}
+	}
+	if len(tui.pods) != 1 || tui.loadErrorOf(ResourcePods) != nil || tui.loadErrorStatus() != "" {
+		t.Errorf("Expected the pods to load, got %d pods and %q", len(tui.pods), tui.loadErrorStatus())
+	}
+}
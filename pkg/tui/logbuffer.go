@@ -0,0 +1,63 @@
+package tui
+
+import "sync"
+
+// LogBuffer is a ring buffer of the most recent log lines: once it holds its
+// capacity, each new line drops the oldest. It is safe for concurrent use, as
+// the log stream writes it while the view reads it.
+type LogBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	// start is the index of the oldest line once the buffer wrapped around
+	start   int
+	dropped int
+}
+
+// NewLogBuffer creates a buffer of capacity lines, at least one
+func NewLogBuffer(capacity int) *LogBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LogBuffer{lines: make([]string, 0, capacity)}
+}
+
+// Add appends a line, dropping the oldest when the buffer is full
+func (b *LogBuffer) Add(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.lines) < cap(b.lines) {
+		b.lines = append(b.lines, line)
+		return
+	}
+	b.lines[b.start] = line
+	b.start = (b.start + 1) % len(b.lines)
+	b.dropped++
+}
+
+// Lines returns the lines held, oldest first
+func (b *LogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := make([]string, 0, len(b.lines))
+	lines = append(lines, b.lines[b.start:]...)
+	return append(lines, b.lines[:b.start]...)
+}
+
+// Len returns how many lines the buffer holds
+func (b *LogBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.lines)
+}
+
+// Cap returns how many lines the buffer holds at most
+func (b *LogBuffer) Cap() int {
+	return cap(b.lines)
+}
+
+// Dropped returns how many lines were dropped to make room for newer ones
+func (b *LogBuffer) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
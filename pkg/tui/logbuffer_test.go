@@ -0,0 +1,48 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLogBufferWrapAround(t *testing.T) {
+	buffer := NewLogBuffer(3)
+	if got := buffer.Lines(); len(got) != 0 {
+		t.Errorf("Expected an empty buffer, got %v", got)
+	}
+
+	buffer.Add("a")
+	buffer.Add("b")
+	if got := strings.Join(buffer.Lines(), ","); got != "a,b" || buffer.Dropped() != 0 {
+		t.Errorf("Expected a,b without drops, got %s with %d dropped", got, buffer.Dropped())
+	}
+
+	// Every line past the capacity replaces the oldest, across several
+	// wrap-arounds
+	for i := 0; i < 7; i++ {
+		buffer.Add(fmt.Sprintf("line-%d", i))
+	}
+	if got := strings.Join(buffer.Lines(), ","); got != "line-4,line-5,line-6" {
+		t.Errorf("Expected the last three lines oldest first, got %s", got)
+	}
+	if buffer.Len() != 3 || buffer.Cap() != 3 || buffer.Dropped() != 6 {
+		t.Errorf("Expected 3/3 lines with 6 dropped, got %d/%d with %d dropped", buffer.Len(), buffer.Cap(), buffer.Dropped())
+	}
+
+	// Lines returns a copy
+	lines := buffer.Lines()
+	lines[0] = "changed"
+	if buffer.Lines()[0] != "line-4" {
+		t.Error("Expected Lines to return a copy")
+	}
+}
+
+func TestLogBufferMinimumCapacity(t *testing.T) {
+	buffer := NewLogBuffer(0)
+	buffer.Add("first")
+	buffer.Add("second")
+	if got := strings.Join(buffer.Lines(), ","); got != "second" || buffer.Cap() != 1 || buffer.Dropped() != 1 {
+		t.Errorf("Expected a one-line buffer holding the newest line, got %s of %d with %d dropped", got, buffer.Cap(), buffer.Dropped())
+	}
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// logTailBytes is how much of the end of the log file :logs reads
+const logTailBytes = 64 << 10
+
+// DefaultLogPath returns where the TUI writes its log, ~/.cache/kgo/kgo.log
+// on Linux
+func DefaultLogPath() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "kgo", "kgo.log"), nil
+}
+
+// rotatingFile is a log file moved aside to path.1 once it would grow past
+// maxBytes, replacing the one moved aside before; 0 never rotates it
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// openRotatingFile opens a log file for appending, creating it and its
+// directory when missing
+func openRotatingFile(path string, maxBytes int64) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &rotatingFile{path: path, maxBytes: maxBytes, file: file, size: info.Size()}, nil
+}
+
+func (f *rotatingFile) Write(data []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(data)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(data)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file aside and starts an empty one
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		f.file = nil
+		return err
+	}
+	f.file, f.size = file, 0
+	return nil
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// klogFileFlags keep klog off stderr while it writes to a file: a fatal
+// error still reaches the terminal, and each message is written once rather
+// than once per severity up to its own
+var klogFileFlags = map[string]string{
+	"logtostderr":     "false",
+	"alsologtostderr": "false",
+	"stderrthreshold": "FATAL",
+	"one_output":      "true",
+}
+
+// RedirectKlog sends klog output to the file at path instead of stderr, where
+// it would write over the TUI, rotating the file at maxBytes. The returned
+// function flushes klog, restores logging to stderr and closes the file.
+func RedirectKlog(path string, maxBytes int64) (func(), error) {
+	file, err := openRotatingFile(path, maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	previous := make(map[string]string, len(klogFileFlags))
+	for name, value := range klogFileFlags {
+		previous[name] = flags.Lookup(name).Value.String()
+		if err := flags.Set(name, value); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to set klog flag %s: %w", name, err)
+		}
+	}
+	klog.Flush()
+	klog.SetOutput(file)
+
+	return func() {
+		klog.Flush()
+		for name, value := range previous {
+			if err := flags.Set(name, value); err != nil {
+				klog.Errorf("Failed to restore klog flag %s: %v", name, err)
+			}
+		}
+		klog.SetOutput(io.Discard)
+		if err := file.Close(); err != nil {
+			klog.Errorf("Failed to close log file %s: %v", path, err)
+		}
+	}, nil
+}
+
+// SetLogFile sets the file klog writes to while the TUI runs, which :logs
+// shows the end of
+func (t *TUI) SetLogFile(path string) {
+	t.logFile = path
+}
+
+// tailFile returns up to n last lines of a file, reading no more than
+// logTailBytes of it
+func tailFile(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := max(info.Size()-logTailBytes, 0)
+	data := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	// A read starting mid-file drops its partial first line
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	return lines[max(len(lines)-n, 0):], nil
+}
+
+// showLogTail returns the last lines of the log file that fit on screen,
+// for :logs
+func (t *TUI) showLogTail() []string {
+	lines := []string{"Logs"}
+	if t.logFile == "" {
+		return append(lines, "Error: kgo is logging to stderr, not to a file")
+	}
+	_, height := t.screen.Size()
+	// Room for the title, the path and the line asking for a key
+	tail, err := tailFile(t.logFile, max(height-6, 1))
+	if err != nil {
+		return append(lines, fmt.Sprintf("Error: failed to read %s: %v", t.logFile, err))
+	}
+	lines = append(lines, t.logFile, "")
+	if len(tail) == 0 {
+		return append(lines, "(empty)")
+	}
+	return append(lines, tail...)
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"k8s.io/klog/v2"
+)
+
+// captureStderr replaces os.Stderr with a pipe until the returned function
+// is called, which returns what was written to it
+func captureStderr(t *testing.T) func() string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+	return func() string {
+		os.Stderr = stderr
+		writer.Close()
+		return <-output
+	}
+}
+
+// TestRedirectKlog tests that klog writes to the log file rather than over
+// the screen while redirected, and to stderr again once restored
+func TestRedirectKlog(t *testing.T) {
+	screen := newTestScreen(t, 80, 10)
+	tui := &TUI{screen: screen, theme: DefaultTheme()}
+	tui.drawText(0, 0, 80, "kgo", tcell.StyleDefault)
+	screen.Show()
+	before, _, _ := screen.GetContents()
+	before = append([]tcell.SimCell(nil), before...)
+
+	path := filepath.Join(t.TempDir(), "logs", "kgo.log")
+	stderr := captureStderr(t)
+	restore, err := RedirectKlog(path, 0)
+	if err != nil {
+		stderr()
+		t.Fatalf("Failed to redirect klog: %v", err)
+	}
+	klog.Errorf("Failed to list pods: connection refused")
+	klog.Infof("Loaded 3 pods")
+	restore()
+	klog.Warningf("Back on stderr")
+	klog.Flush()
+	written := stderr()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	logged := string(data)
+	if strings.Count(logged, "Failed to list pods: connection refused") != 1 || !strings.Contains(logged, "Loaded 3 pods") {
+		t.Errorf("Expected each message once in the log file, got:\n%s", logged)
+	}
+	if strings.Contains(written, "connection refused") || strings.Contains(written, "Loaded 3 pods") {
+		t.Errorf("Expected nothing on stderr while redirected, got:\n%s", written)
+	}
+	if !strings.Contains(written, "Back on stderr") || strings.Contains(logged, "Back on stderr") {
+		t.Errorf("Expected stderr logging to be restored, got %q on stderr", written)
+	}
+
+	after, _, _ := screen.GetContents()
+	if !reflect.DeepEqual(before, after) {
+		t.Error("Expected the screen to be untouched by logging")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kgo.log")
+	file, err := openRotatingFile(path, 100)
+	if err != nil {
+		t.Fatalf("Failed to open log file: %v", err)
+	}
+	line := strings.Repeat("x", 59) + "\n"
+	for i := 0; i < 3; i++ {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+	file.Close()
+
+	current, _ := os.ReadFile(path)
+	rotated, _ := os.ReadFile(path + ".1")
+	if string(current) != line || string(rotated) != line {
+		t.Errorf("Expected one line in each file, got %q and %q", current, rotated)
+	}
+
+	// Reopening appends to the current file
+	file, err = openRotatingFile(path, 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen log file: %v", err)
+	}
+	file.Write([]byte("more\n"))
+	file.Close()
+	if current, _ := os.ReadFile(path); string(current) != line+"more\n" {
+		t.Errorf("Expected the reopened file to be appended to, got %q", current)
+	}
+}
+
+// TestTUILogsCommand tests that :logs shows the last lines of the log file
+// that fit on screen
+func TestTUILogsCommand(t *testing.T) {
+	screen := newTestScreen(t, 100, 10)
+	tui := &TUI{screen: screen}
+
+	if lines := tui.runCommand("logs"); lines[1] != "Error: kgo is logging to stderr, not to a file" {
+		t.Errorf("Expected logs without a file to be explained, got %q", lines)
+	}
+
+	path := filepath.Join(t.TempDir(), "kgo.log")
+	var content strings.Builder
+	for i := 1; i <= 20; i++ {
+		content.WriteString("line " + string(rune('a'+i-1)) + "\n")
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0600); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+	tui.SetLogFile(path)
+	lines := tui.runCommand("logs")
+	want := []string{"Logs", path, "", "line q", "line r", "line s", "line t"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected %q, got %q", want, lines)
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kgo.log")
+	// A file longer than logTailBytes is read from a line start
+	long := strings.Repeat(strings.Repeat("x", 99)+"\n", logTailBytes/100+10) + "last\n"
+	if err := os.WriteFile(path, []byte(long), 0600); err != nil {
+		t.Fatalf("Failed to write log file: %v", err)
+	}
+	lines, err := tailFile(path, 1000)
+	if err != nil {
+		t.Fatalf("Failed to tail: %v", err)
+	}
+	if lines[len(lines)-1] != "last" || len(lines[0]) != 99 {
+		t.Errorf("Expected whole lines up to the last one, got %q ... %q", lines[0], lines[len(lines)-1])
+	}
+
+	if _, err := tailFile(filepath.Join(t.TempDir(), "missing.log"), 10); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file to fail, got %v", err)
+	}
+}
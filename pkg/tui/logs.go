@@ -0,0 +1,279 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultMaxLogs is the log buffer size without a configured ui.maxLogs
+	defaultMaxLogs = 1000
+	// defaultLogTailLines is the initial tail without a configured
+	// ui.logTailLines
+	defaultLogTailLines = 100
+	// maxLogLineLength is the longest log line read; longer lines end the
+	// stream with an error
+	maxLogLineLength = 1 << 20
+)
+
+// errLogsNeedCluster is shown in the log view without a clientset
+var errLogsNeedCluster = errors.New("logs need direct cluster access")
+
+// logsView is the state of the log view, written by the goroutine following
+// the log stream and read when drawing, so guarded by mu
+type logsView struct {
+	mu        sync.Mutex
+	pod       string
+	container string
+	tailLines int
+	buffer    *LogBuffer
+	err       error
+	ended     bool
+	// cancel stops following the stream; nil while the view is closed
+	cancel context.CancelFunc
+}
+
+// maxLogs returns how many lines the log view keeps
+func (t *TUI) maxLogs() int {
+	if t.config == nil || t.config.UI.MaxLogs <= 0 {
+		return defaultMaxLogs
+	}
+	return t.config.UI.MaxLogs
+}
+
+// initialLogTailLines returns how many earlier lines the log view starts with
+// until 'T' changes it
+func (t *TUI) initialLogTailLines() int {
+	if t.config == nil || t.config.UI.LogTailLines <= 0 {
+		return defaultLogTailLines
+	}
+	return t.config.UI.LogTailLines
+}
+
+// logContainer returns the container whose logs are shown: the one named by
+// the kubectl default-container annotation, or else the first
+func logContainer(pod v1.Pod) string {
+	if name := pod.Annotations["kubectl.kubernetes.io/default-container"]; name != "" {
+		return name
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// openLogs follows the logs of the selected pod in the log view, starting
+// tailLines lines back. An open stream is closed first, dropping its lines.
+func (t *TUI) openLogs(tailLines int) {
+	pod, ok := t.getSelectedResource().(v1.Pod)
+	if !ok {
+		t.closeLogs()
+		return
+	}
+	t.openContainerLogs(pod, logContainer(pod), tailLines)
+}
+
+// openContainerLogs follows the logs of one container of a pod in the log
+// view, starting tailLines lines back
+func (t *TUI) openContainerLogs(pod v1.Pod, container string, tailLines int) {
+	t.closeLogs()
+	ctx, cancel := context.WithCancel(context.Background())
+	buffer := NewLogBuffer(t.maxLogs())
+	t.logs.mu.Lock()
+	t.logs.pod = pod.Name
+	t.logs.container = container
+	t.logs.tailLines = tailLines
+	t.logs.buffer = buffer
+	t.logs.err = nil
+	t.logs.ended = false
+	t.logs.cancel = cancel
+	t.logs.mu.Unlock()
+
+	t.logsScroll = 0
+	t.viewMode = ViewModeLogs
+	if !t.hasClientset() {
+		t.endLogs(buffer, errLogsNeedCluster)
+		return
+	}
+	go t.followLogs(ctx, buffer, pod.Namespace, pod.Name, container, tailLines)
+}
+
+// closeLogs stops following the log stream
+func (t *TUI) closeLogs() {
+	t.logs.mu.Lock()
+	defer t.logs.mu.Unlock()
+	if t.logs.cancel != nil {
+		t.logs.cancel()
+		t.logs.cancel = nil
+	}
+}
+
+// followLogs reads a pod's log stream into buffer until it ends or ctx is
+// done
+func (t *TUI) followLogs(ctx context.Context, buffer *LogBuffer, namespace, pod, container string, tailLines int) {
+	stream, err := k8s.FollowPodLogs(ctx, t.clientset, namespace, pod, container, int64(tailLines))
+	if err != nil {
+		t.endLogs(buffer, err)
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineLength)
+	for scanner.Scan() {
+		buffer.Add(scanner.Text())
+		t.requestRedraw()
+	}
+	t.endLogs(buffer, scanner.Err())
+}
+
+// endLogs records why the stream filling buffer ended, unless the view moved
+// on to another stream since
+func (t *TUI) endLogs(buffer *LogBuffer, err error) {
+	t.logs.mu.Lock()
+	if t.logs.buffer == buffer && t.logs.cancel != nil {
+		t.logs.err = err
+		t.logs.ended = true
+	}
+	t.logs.mu.Unlock()
+	t.requestRedraw()
+}
+
+// handleLogsKey handles the keys of the log view, and reports whether it
+// did
+func (t *TUI) handleLogsKey(ev *tcell.EventKey) bool {
+	if ev.Key() != tcell.KeyRune || ev.Rune() != 'T' {
+		return false
+	}
+	t.logs.mu.Lock()
+	container, tailLines := t.logs.container, t.logs.tailLines
+	t.logs.mu.Unlock()
+	if tailLines, ok := t.promptLogTailLines(tailLines); ok {
+		if pod, ok := t.getSelectedResource().(v1.Pod); ok {
+			t.openContainerLogs(pod, container, tailLines)
+		}
+	}
+	return true
+}
+
+// promptLogTailLines reads a new tail line count on the bottom line, and
+// reports whether one was entered
+func (t *TUI) promptLogTailLines(current int) (int, bool) {
+	input := strconv.Itoa(current)
+	for {
+		t.draw()
+		width, height := t.screen.Size()
+		prompt := "Tail lines (Enter reopens the stream, Esc cancels): " + input + "_"
+		if len(prompt) < width {
+			prompt += strings.Repeat(" ", width-len(prompt))
+		}
+		t.drawText(0, height-1, width, prompt, tcell.StyleDefault.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyEnter:
+			tailLines, err := strconv.Atoi(input)
+			if err != nil || tailLines <= 0 {
+				return 0, false
+			}
+			return tailLines, true
+		case tcell.KeyEscape:
+			return 0, false
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		case tcell.KeyRune:
+			if ev.Rune() >= '0' && ev.Rune() <= '9' && len(input) < 9 {
+				input += string(ev.Rune())
+			}
+		}
+	}
+}
+
+// formatCount formats a count with thousands separators, e.g. "10,000"
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	var formatted strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			formatted.WriteByte(',')
+		}
+		formatted.WriteRune(digit)
+	}
+	return formatted.String()
+}
+
+// logOccupancy describes how full the log buffer is, e.g. "5,000/10,000
+// lines, oldest dropped"
+func logOccupancy(buffer *LogBuffer) string {
+	occupancy := fmt.Sprintf("%s/%s lines", formatCount(buffer.Len()), formatCount(buffer.Cap()))
+	if buffer.Dropped() > 0 {
+		occupancy += ", oldest dropped"
+	}
+	return occupancy
+}
+
+// drawLogsView draws the followed logs of the selected pod, newest at the
+// bottom. logsScroll is how many lines the view is scrolled back.
+func (t *TUI) drawLogsView(width, height int) {
+	t.logs.mu.Lock()
+	pod, container, tailLines, buffer := t.logs.pod, t.logs.container, t.logs.tailLines, t.logs.buffer
+	err, ended := t.logs.err, t.logs.ended
+	t.logs.mu.Unlock()
+	if buffer == nil {
+		t.drawText(0, 0, width, "No pod selected", tcell.StyleDefault)
+		return
+	}
+
+	// Header
+	header := fmt.Sprintf(" 📋 Pod Logs: %s/%s (from %d lines back) ", pod, container, tailLines)
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	lines := buffer.Lines()
+	switch {
+	case err != nil:
+		lines = append(lines, fmt.Sprintf("Error: %v", err))
+	case ended:
+		lines = append(lines, "-- end of log stream --")
+	case len(lines) == 0:
+		lines = append(lines, "Waiting for log lines...")
+	}
+
+	visible := height - 4
+	if t.logsScroll > len(lines)-visible {
+		t.logsScroll = max(len(lines)-visible, 0)
+	}
+	start := max(len(lines)-visible-t.logsScroll, 0)
+	y := 2
+	for i := start; i < len(lines) && y < height-2; i++ {
+		line := lines[i]
+		if len(line) > width {
+			line = line[:width-3] + "..."
+		}
+		style := tcell.StyleDefault
+		if err != nil && i == len(lines)-1 {
+			style = style.Foreground(tcell.ColorRed)
+		}
+		t.drawText(0, y, width, line, style)
+		y++
+	}
+
+	// Footer
+	footer := fmt.Sprintf(" ESC Back │ ↑↓ Scroll │ T Tail lines │ %s ", logOccupancy(buffer))
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
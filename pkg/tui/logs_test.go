@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTUILogsView(t *testing.T) {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app"}, {Name: "sidecar"}}},
+	}
+	cfg := config.DefaultConfig()
+	cfg.UI.MaxLogs = 10000
+	cfg.UI.LogTailLines = 50
+	tui, screen := newTestTUI(t, 120, 30, &pod)
+	tui.config = cfg
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeDetails
+	tui.pods = []v1.Pod{pod}
+	waitForLogs := func() {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			tui.logs.mu.Lock()
+			ended := tui.logs.ended
+			tui.logs.mu.Unlock()
+			if ended {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the fake log stream to end")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	tui.openLogs(tui.initialLogTailLines())
+	waitForLogs()
+	tui.drawLogsView(120, 30)
+	screen.Show()
+	cells, width, height := screen.GetContents()
+	var text strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				text.WriteRune(runes[0])
+			}
+		}
+		text.WriteRune('\n')
+	}
+	for _, want := range []string{"Pod Logs: web/app (from 50 lines back)", "fake logs", "1/10,000 lines"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected %q in the logs view, got:\n%s", want, text.String())
+		}
+	}
+
+	// T reopens the stream from the entered number of lines back
+	screen.InjectKey(tcell.KeyBackspace, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyRune, 'x', tcell.ModNone)
+	screen.InjectKey(tcell.KeyRune, '0', tcell.ModNone)
+	screen.InjectKey(tcell.KeyRune, '0', tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	if !tui.handleLogsKey(tcell.NewEventKey(tcell.KeyRune, 'T', tcell.ModNone)) {
+		t.Fatal("Expected T to be handled in the logs view")
+	}
+	waitForLogs()
+	tui.logs.mu.Lock()
+	tailLines := tui.logs.tailLines
+	tui.logs.mu.Unlock()
+	if tailLines != 500 {
+		t.Errorf("Expected 50 edited to 500 lines, got %d", tailLines)
+	}
+	tui.closeLogs()
+
+	// A full buffer reports the dropped lines
+	buffer := NewLogBuffer(3)
+	for i := 0; i < 4; i++ {
+		buffer.Add("line")
+	}
+	if got := logOccupancy(buffer); got != "3/3 lines, oldest dropped" {
+		t.Errorf("Expected a full buffer with drops, got %q", got)
+	}
+	for n, want := range map[int]string{0: "0", 999: "999", 5000: "5,000", 1234567: "1,234,567"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("Expected %d formatted as %s, got %s", n, want, got)
+		}
+	}
+}
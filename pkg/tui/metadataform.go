@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// metadataEntry is one label or annotation in the metadata editor
+type metadataEntry struct {
+	key   string
+	value string
+}
+
+// metadataForm holds the state of the label and annotation editor of an
+// object. Entries are edited locally; saving patches only the difference
+// from the object's labels or annotations.
+type metadataForm struct {
+	resource string
+	meta     metav1.ObjectMeta
+	field    string
+	entries  []metadataEntry
+	selected int
+	// entry is the key and value being added or edited, nil while browsing.
+	// editing is the index of the edited entry, or -1 for a new one.
+	entry   []*wizardField
+	focus   int
+	editing int
+	errMsg  string
+}
+
+// newMetadataForm creates an editor of the labels of an object, starting
+// with its labels; resource names its kind in messages, e.g. "deployment"
+func newMetadataForm(resource string, meta metav1.ObjectMeta) *metadataForm {
+	f := &metadataForm{resource: resource, meta: meta}
+	f.switchField(k8s.LabelsField)
+	return f
+}
+
+// switchField starts editing the labels or the annotations of the object
+func (f *metadataForm) switchField(field string) {
+	f.field = field
+	f.entries = nil
+	for key, value := range f.current() {
+		f.entries = append(f.entries, metadataEntry{key: key, value: value})
+	}
+	f.sortEntries()
+	f.selected = 0
+	f.errMsg = ""
+}
+
+// current returns the object's labels or annotations, as last loaded
+func (f *metadataForm) current() map[string]string {
+	if f.field == k8s.AnnotationsField {
+		return f.meta.Annotations
+	}
+	return f.meta.Labels
+}
+
+// desired returns the labels or annotations as edited
+func (f *metadataForm) desired() map[string]string {
+	values := make(map[string]string, len(f.entries))
+	for _, entry := range f.entries {
+		values[entry.key] = entry.value
+	}
+	return values
+}
+
+// change returns the difference between the edited and the current values
+func (f *metadataForm) change() k8s.MetadataChange {
+	return k8s.DiffMetadata(f.current(), f.desired())
+}
+
+func (f *metadataForm) sortEntries() {
+	sort.Slice(f.entries, func(i, j int) bool { return f.entries[i].key < f.entries[j].key })
+}
+
+// handleKey applies a key press to the editor
+func (f *metadataForm) handleKey(ev *tcell.EventKey) wizardAction {
+	if f.entry != nil {
+		f.handleEntryKey(ev)
+		return wizardContinue
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return wizardCancel
+	case tcell.KeyDown:
+		if f.selected < len(f.entries)-1 {
+			f.selected++
+		}
+	case tcell.KeyUp:
+		if f.selected > 0 {
+			f.selected--
+		}
+	case tcell.KeyEnter:
+		if f.selected < len(f.entries) {
+			entry := f.entries[f.selected]
+			f.openEntry(f.selected, entry.key, entry.value)
+		}
+	case tcell.KeyTab:
+		if !f.change().IsEmpty() {
+			f.errMsg = fmt.Sprintf("Save or discard the %s changes first", f.field)
+			break
+		}
+		if f.field == k8s.LabelsField {
+			f.switchField(k8s.AnnotationsField)
+		} else {
+			f.switchField(k8s.LabelsField)
+		}
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'a':
+			f.openEntry(-1, "", "")
+		case 'd':
+			if f.selected < len(f.entries) {
+				f.entries = append(f.entries[:f.selected], f.entries[f.selected+1:]...)
+				f.selected = max(min(f.selected, len(f.entries)-1), 0)
+			}
+		case 's':
+			return wizardDone
+		}
+	}
+	return wizardContinue
+}
+
+// openEntry opens the key and value fields of entry i, or of a new entry
+// when i is -1
+func (f *metadataForm) openEntry(i int, key, value string) {
+	f.entry = []*wizardField{{label: "Key", value: key}, {label: "Value", value: value}}
+	f.focus = 0
+	if i >= 0 {
+		f.focus = 1
+	}
+	f.editing = i
+	f.errMsg = ""
+}
+
+// handleEntryKey applies a key press to the open entry: Enter keeps it,
+// replacing an entry with the same key, and Esc drops it
+func (f *metadataForm) handleEntryKey(ev *tcell.EventKey) {
+	if editFields(f.entry, &f.focus, ev) {
+		return
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		f.entry = nil
+	case tcell.KeyEnter:
+		key := strings.TrimSpace(f.entry[0].value)
+		if key == "" {
+			f.errMsg = "a key is required"
+			return
+		}
+		entry := metadataEntry{key: key, value: f.entry[1].value}
+		if f.editing >= 0 {
+			f.entries = append(f.entries[:f.editing], f.entries[f.editing+1:]...)
+		}
+		for i := range f.entries {
+			if f.entries[i].key == key {
+				f.entries = append(f.entries[:i], f.entries[i+1:]...)
+				break
+			}
+		}
+		f.entries = append(f.entries, entry)
+		f.sortEntries()
+		for i := range f.entries {
+			if f.entries[i].key == key {
+				f.selected = i
+			}
+		}
+		f.entry = nil
+		f.errMsg = ""
+	}
+}
+
+// lines renders the editor, marking added (+), changed (~) and removed (-)
+// entries
+func (f *metadataForm) lines() []string {
+	lines := []string{fmt.Sprintf("Edit %s of %s '%s' in '%s'", f.field, f.resource, f.meta.Name, f.meta.Namespace), ""}
+
+	current := f.current()
+	if len(f.entries) == 0 {
+		lines = append(lines, fmt.Sprintf("  No %s", f.field))
+	}
+	for i, entry := range f.entries {
+		marker := "  "
+		if i == f.selected && f.entry == nil {
+			marker = "▶ "
+		}
+		state := " "
+		if old, ok := current[entry.key]; !ok {
+			state = "+"
+		} else if old != entry.value {
+			state = "~"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s=%s", marker, state, entry.key, entry.value))
+	}
+	for _, key := range f.change().Remove {
+		lines = append(lines, fmt.Sprintf("  - %s=%s", key, current[key]))
+	}
+
+	if f.entry != nil {
+		lines = append(lines, "")
+		lines = append(lines, fieldLines(f.entry, f.focus)...)
+	}
+	if f.errMsg != "" {
+		lines = append(lines, "", "Error: "+f.errMsg)
+	}
+
+	other := "Annotations"
+	if f.field == k8s.AnnotationsField {
+		other = "Labels"
+	}
+	if f.entry != nil {
+		return append(lines, "", "Tab/↑↓: Field | Enter: Keep | Esc: Discard entry")
+	}
+	return append(lines, "", fmt.Sprintf("↑↓: Select | a: Add | Enter: Edit | d: Delete | Tab: %s | s: Save | Esc: Cancel", other))
+}
+
+// selectedObjectMeta returns the plural kind, singular resource name and
+// metadata of the selected object, if labels can be edited on its kind
+func (t *TUI) selectedObjectMeta() (string, string, metav1.ObjectMeta, bool) {
+	switch r := t.getSelectedResource().(type) {
+	case v1.Pod:
+		return "pods", "pod", r.ObjectMeta, true
+	case appsv1.Deployment:
+		return "deployments", "deployment", r.ObjectMeta, true
+	case v1.Service:
+		return "services", "service", r.ObjectMeta, true
+	case k8s.ConfigMapSummary:
+		return "configmaps", "configmap", r.ObjectMeta, true
+	}
+	return "", "", metav1.ObjectMeta{}, false
+}
+
+// editSelectedMetadata opens the label and annotation editor of the selected
+// object and patches the changes on save
+func (t *TUI) editSelectedMetadata() {
+	kind, resource, meta, ok := t.selectedObjectMeta()
+	if !ok {
+		return
+	}
+	f := newMetadataForm(resource, meta)
+
+	for {
+		t.drawLines(f.lines())
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch f.handleKey(ev) {
+		case wizardCancel:
+			return
+		case wizardDone:
+			change := f.change()
+			if change.IsEmpty() {
+				return
+			}
+			if err := validation.Metadata(resource, meta.Name, f.field, change.Set, change.Remove); err != nil {
+				f.errMsg = err.Error()
+				continue
+			}
+			if !t.confirmProtectedActionIn(meta.Namespace, "edit the "+f.field+" of", resource, meta.Name) {
+				return
+			}
+			if _, err := k8s.PatchMetadata(context.TODO(), t.clientset, kind, meta.Namespace, meta.Name, f.field, change); err != nil {
+				f.errMsg = err.Error()
+				continue
+			}
+			t.recordAction(fmt.Sprintf("Updated the %s of %s '%s'", f.field, resource, meta.Name),
+				k8s.KubectlEditMetadata(meta.Namespace, resource, meta.Name, f.field, change))
+			switch kind {
+			case "pods":
+				t.loadPods()
+			case "deployments":
+				t.loadDeployments()
+			case "services":
+				t.loadServices()
+			case "configmaps":
+				t.loadConfigMaps()
+			}
+			return
+		}
+	}
+}
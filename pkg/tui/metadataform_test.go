@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTUIEditMetadata(t *testing.T) {
+	deployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Namespace:   "default",
+		Labels:      map[string]string{"app": "web", "team": "a"},
+		Annotations: map[string]string{"owner": "alice"},
+	}}
+	tui, screen := newTestTUI(t, 120, 30, &deployment)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceDeployments
+	tui.deployments = []appsv1.Deployment{deployment}
+
+	getDeployment := func() *appsv1.Deployment {
+		t.Helper()
+		deployment, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		return deployment
+	}
+
+	// Delete team and add tier, whose invalid value is fixed after the
+	// validation error
+	go func() {
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'd', tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'a', tcell.ModNone)
+		typeText(screen, "tier")
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "front end")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 's', tcell.ModNone)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		for range "front end" {
+			screen.InjectKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+		}
+		typeText(screen, "frontend")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 's', tcell.ModNone)
+	}()
+	tui.editSelectedMetadata()
+
+	if labels := getDeployment().Labels; fmt.Sprint(labels) != "map[app:web tier:frontend]" {
+		t.Errorf("Expected labels app and tier, got %v", labels)
+	}
+	if len(tui.deployments) != 1 || tui.deployments[0].Labels["tier"] != "frontend" {
+		t.Errorf("Expected the deployment list to be reloaded, got %+v", tui.deployments)
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "kubectl -n default label deployment web tier=frontend team- --overwrite") {
+		t.Errorf("Expected the kubectl label command in the status bar, got %q", status)
+	}
+
+	// Tab switches to the annotations once there is nothing to save
+	go func() {
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		for range "alice" {
+			screen.InjectKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+		}
+		typeText(screen, "bob")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 's', tcell.ModNone)
+	}()
+	tui.editSelectedMetadata()
+	if annotations := getDeployment().Annotations; fmt.Sprint(annotations) != "map[owner:bob]" {
+		t.Errorf("Expected owner bob, got %v", annotations)
+	}
+
+	go func() {
+		screen.InjectKey(tcell.KeyRune, 'd', tcell.ModNone)
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	}()
+	tui.editSelectedMetadata()
+	text := screenText(screen)
+	if !strings.Contains(text, "- app=web") || !strings.Contains(text, "Error: Save or discard the labels changes first") {
+		t.Errorf("Expected the removed label and an error for Tab, got:\n%s", text)
+	}
+	if labels := getDeployment().Labels; len(labels) != 2 {
+		t.Errorf("Expected Esc to discard the changes, got %v", labels)
+	}
+}
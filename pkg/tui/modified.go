@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/util"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// unmodifiedAge is how long a pod or deployment goes without changes before
+// its row is greyed out
+const unmodifiedAge = 30 * 24 * time.Hour
+
+// formatModified describes how long ago obj was last modified at now, e.g.
+// "5h ago", or "-" when its managedFields do not tell
+func (t *TUI) formatModified(obj metav1.Object, now time.Time) string {
+	modified := k8s.GetLastModifiedTime(obj)
+	if modified == nil {
+		return "-"
+	}
+	return util.HumanDuration(now.Sub(*modified)) + " ago"
+}
+
+// isLongUnmodified reports whether a pod or deployment was last modified more
+// than unmodifiedAge before now. Other resources never are.
+func isLongUnmodified(resource interface{}, now time.Time) bool {
+	var obj metav1.Object
+	switch r := resource.(type) {
+	case v1.Pod:
+		obj = &r
+	case appsv1.Deployment:
+		obj = &r
+	default:
+		return false
+	}
+	modified := k8s.GetLastModifiedTime(obj)
+	return modified != nil && now.Sub(*modified) > unmodifiedAge
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUIModifiedColumn tests the Modified column of pods and deployments and
+// that long-unmodified rows are greyed out
+func TestTUIModifiedColumn(t *testing.T) {
+	tui := &TUI{currentView: ResourcePods}
+	now := time.Now()
+	managedAt := func(ago time.Duration) []metav1.ManagedFieldsEntry {
+		at := metav1.NewTime(now.Add(-ago))
+		return []metav1.ManagedFieldsEntry{{
+			Manager:  "kubectl",
+			Time:     &at,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)},
+		}}
+	}
+
+	recent := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "recent", ManagedFields: managedAt(5 * time.Hour)}}
+	old := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "old", ManagedFields: managedAt(45 * 24 * time.Hour)}}
+	unknown := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}}
+
+	if headers := tui.getTableHeaders(); headers[4] != "Modified" {
+		t.Fatalf("Expected a Modified column after Age, got %v", headers)
+	}
+	if got := tui.getResourceColumnValue(recent, 4); got != "5h ago" {
+		t.Errorf("Expected pod modified 5h ago, got %q", got)
+	}
+	if got := tui.getResourceColumnValue(unknown, 4); got != "-" {
+		t.Errorf("Expected - without managedFields, got %q", got)
+	}
+	tui.currentView = ResourceDeployments
+	if got := tui.getResourceColumnValue(old, 5); got != "45d ago" {
+		t.Errorf("Expected deployment modified 45d ago, got %q", got)
+	}
+
+	if isLongUnmodified(recent, now) || isLongUnmodified(unknown, now) {
+		t.Error("Expected recent and unknown pods not to be greyed out")
+	}
+	if !isLongUnmodified(old, now) {
+		t.Error("Expected a deployment unmodified for 45 days to be greyed out")
+	}
+}
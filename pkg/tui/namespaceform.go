@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// namespaceForm holds the state of the namespace creation form: the name, a
+// field per label template from ui.namespaceLabelTemplates, any other labels,
+// and the ResourceQuota and LimitRange presets to apply
+type namespaceForm struct {
+	fields []*wizardField
+	// templateKeys are the label keys of the fields after the name
+	templateKeys []string
+	// otherField, quotaField and limitRangeField are the indexes of the
+	// other labels and of the two preset choices
+	otherField      int
+	quotaField      int
+	limitRangeField int
+	field           int
+	errMsg          string
+}
+
+// newNamespaceForm creates a form offering a label per key=default template
+func newNamespaceForm(templates []string) *namespaceForm {
+	f := &namespaceForm{fields: []*wizardField{{label: "Name"}}}
+	for _, template := range templates {
+		key, value, _ := strings.Cut(template, "=")
+		f.templateKeys = append(f.templateKeys, key)
+		f.fields = append(f.fields, &wizardField{label: "Label " + key, value: value})
+	}
+	f.otherField = len(f.fields)
+	f.quotaField = f.otherField + 1
+	f.limitRangeField = f.otherField + 2
+	f.fields = append(f.fields,
+		&wizardField{label: "Other labels"},
+		&wizardField{label: "ResourceQuota", value: noPreset},
+		&wizardField{label: "LimitRange", value: noPreset})
+	return f
+}
+
+// isPreset reports whether a field is a preset choice rather than text
+func (f *namespaceForm) isPreset(field int) bool {
+	return field == f.quotaField || field == f.limitRangeField
+}
+
+// cyclePreset moves the focused preset choice by step through
+// namespacePresetSizes
+func (f *namespaceForm) cyclePreset(step int) {
+	field := f.fields[f.field]
+	for i, size := range namespacePresetSizes {
+		if size == field.value {
+			field.value = namespacePresetSizes[(i+step+len(namespacePresetSizes))%len(namespacePresetSizes)]
+			return
+		}
+	}
+	field.value = noPreset
+}
+
+// handleKey applies a key press to the form
+func (f *namespaceForm) handleKey(ev *tcell.EventKey) wizardAction {
+	// Presets are chosen with ←/→ or Space rather than typed
+	if f.isPreset(f.field) {
+		switch {
+		case ev.Key() == tcell.KeyRight || ev.Key() == tcell.KeyRune && ev.Rune() == ' ':
+			f.cyclePreset(1)
+			return wizardContinue
+		case ev.Key() == tcell.KeyLeft:
+			f.cyclePreset(-1)
+			return wizardContinue
+		case ev.Key() == tcell.KeyRune, ev.Key() == tcell.KeyBackspace, ev.Key() == tcell.KeyBackspace2:
+			return wizardContinue
+		}
+	}
+	if editFields(f.fields, &f.field, ev) {
+		return wizardContinue
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return wizardCancel
+	case tcell.KeyEnter:
+		return wizardDone
+	}
+	return wizardContinue
+}
+
+// build returns the namespace described by the form. Template labels left
+// empty are not set.
+func (f *namespaceForm) build() (*v1.Namespace, error) {
+	name := strings.TrimSpace(f.fields[0].value)
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid name: %s", errs[0])
+	}
+
+	labels, err := parseKeyValues(strings.TrimSpace(f.fields[f.otherField].value), true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labels: %v", err)
+	}
+	for i, key := range f.templateKeys {
+		value := strings.TrimSpace(f.fields[i+1].value)
+		if value == "" {
+			continue
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s label %q: %s", key, value, errs[0])
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = value
+	}
+
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}, nil
+}
+
+// presets returns the chosen quota and limit range preset sizes
+func (f *namespaceForm) presets() (string, string) {
+	return f.fields[f.quotaField].value, f.fields[f.limitRangeField].value
+}
+
+// lines renders the form
+func (f *namespaceForm) lines() []string {
+	lines := append([]string{"Create Namespace", ""}, fieldLines(f.fields, f.field)...)
+	lines = append(lines, "", "Names are lowercase RFC 1123 labels, e.g. team-a. Other labels are comma-separated key=value pairs.",
+		"ResourceQuota and LimitRange presets ("+strings.Join(namespacePresetSizes, ", ")+") are created after the namespace.")
+	if f.errMsg != "" {
+		lines = append(lines, "Error: "+f.errMsg)
+	}
+	if f.isPreset(f.field) {
+		return append(lines, "", "Tab/↑↓: Field | ←/→: Preset | Enter: Create | Esc: Cancel")
+	}
+	return append(lines, "", "Tab/↑↓: Field | Enter: Create | Esc: Cancel")
+}
+
+// createNamespaceError describes why a namespace could not be created
+func createNamespaceError(name string, err error) string {
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		return fmt.Sprintf("namespace %q already exists", name)
+	case apierrors.IsForbidden(err):
+		return fmt.Sprintf("not allowed to create namespaces: %v", err)
+	}
+	return err.Error()
+}
+
+// createNamespaceDialog runs the namespace creation form, creates the
+// namespace and then the chosen presets, and offers to switch into it. A
+// namespace that cannot be created is reported in the form; failed presets
+// are reported with the switch prompt, as the namespace exists by then.
+func (t *TUI) createNamespaceDialog() {
+	f := newNamespaceForm(t.config.UI.NamespaceLabelTemplates)
+
+	for {
+		t.drawLines(f.lines())
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch f.handleKey(ev) {
+		case wizardCancel:
+			return
+		case wizardDone:
+			namespace, err := f.build()
+			if err != nil {
+				f.errMsg = err.Error()
+				continue
+			}
+			if !t.confirmProtectedActionIn(namespace.Name, "create", "namespace", namespace.Name) {
+				return
+			}
+			if _, err := k8s.CreateNamespace(t.clientset, namespace.Name, namespace.Labels); err != nil {
+				f.errMsg = createNamespaceError(namespace.Name, err)
+				continue
+			}
+			steps := []namespaceStep{{kind: "Namespace", name: namespace.Name}}
+			quotaSize, limitRangeSize := f.presets()
+			steps = append(steps, applyNamespacePresets(t.clientset, namespace.Name, quotaSize, limitRangeSize)...)
+			t.recordAction(fmt.Sprintf("Created namespace '%s'", namespace.Name), k8s.KubectlCreate("", namespace))
+			t.offerNamespaceSwitch(namespace.Name, steps)
+			return
+		}
+	}
+}
+
+// offerNamespaceSwitch shows the steps of creating a namespace and asks
+// whether to switch into it, and otherwise reloads the namespace list to
+// show it
+func (t *TUI) offerNamespaceSwitch(name string, steps []namespaceStep) {
+	lines := make([]string, 0, len(steps)+2)
+	for _, step := range steps {
+		lines = append(lines, step.line())
+	}
+	t.drawLines(append(lines, "", "Switch to it now? (Y/n)"))
+
+	ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+	if ok && (ev.Key() == tcell.KeyEnter || ev.Rune() == 'y' || ev.Rune() == 'Y') {
+		t.namespace = name
+		t.refreshData()
+		t.saveSession()
+		return
+	}
+	if namespaces, err := k8s.ListNamespaces(t.clientset); err == nil {
+		t.namespaces = namespaces
+	}
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestTUICreateNamespace tests creating a namespace with template labels from
+// the Namespaces tab and switching into it
+func TestTUICreateNamespace(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.UI.NamespaceLabelTemplates = []string{"team=", "env=dev"}
+	tui, screen := newTestTUI(t, 120, 30, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.config = cfg
+	tui.currentView = ResourceNamespaces
+
+	// An invalid name is rejected in the form, then fixed
+	go func() {
+		typeText(screen, "Team_A")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		for range "Team_A" {
+			screen.InjectKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+		}
+		typeText(screen, "team-a")
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "payments")
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "tier=gold")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone) // Switch to it
+	}()
+	tui.createNamespaceDialog()
+
+	namespace, err := clientset.CoreV1().Namespaces().Get(context.TODO(), "team-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected namespace team-a to be created: %v", err)
+	}
+	want := map[string]string{"team": "payments", "env": "dev", "tier": "gold"}
+	if fmt.Sprint(namespace.Labels) != fmt.Sprint(want) {
+		t.Errorf("Expected labels %v, got %v", want, namespace.Labels)
+	}
+	if tui.namespace != "team-a" {
+		t.Errorf("Expected to switch into team-a, got %q", tui.namespace)
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "kubectl create -f -") {
+		t.Errorf("Expected the creation in the status bar, got %q", status)
+	}
+
+	// Creating it again fails inside the form
+	go func() {
+		typeText(screen, "team-a")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	}()
+	tui.createNamespaceDialog()
+	if text := screenText(screen); !strings.Contains(text, `Error: namespace "team-a" already exists`) {
+		t.Errorf("Expected the AlreadyExists error in the form, got:\n%s", text)
+	}
+
+	// Declining the switch stays in the current namespace and lists the new one
+	go func() {
+		typeText(screen, "team-b")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'n', tcell.ModNone)
+	}()
+	tui.createNamespaceDialog()
+	if tui.namespace != "team-a" || len(tui.namespaces) != 3 {
+		t.Errorf("Expected to stay in team-a with 3 namespaces listed, got %q and %d", tui.namespace, len(tui.namespaces))
+	}
+
+	// Presets are created after the namespace; a failed limit range is
+	// reported with the switch prompt and leaves the namespace and quota
+	clientset.PrependReactor("create", "limitranges", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("limitranges"), "limits-medium", errors.New("RBAC: access denied"))
+	})
+	go func() {
+		typeText(screen, "team-d")
+		for range 4 {
+			screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		}
+		screen.InjectKey(tcell.KeyRight, 0, tcell.ModNone) // ResourceQuota: small
+		typeText(screen, "x")                              // ignored on a preset
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyLeft, 0, tcell.ModNone) // LimitRange: large
+		screen.InjectKey(tcell.KeyLeft, 0, tcell.ModNone) // LimitRange: medium
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyRune, 'n', tcell.ModNone)
+	}()
+	tui.createNamespaceDialog()
+	text := screenText(screen)
+	for _, want := range []string{"✔ Namespace 'team-d' created", "✔ ResourceQuota 'quota-small' created", "✘ LimitRange 'limits-medium' failed"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q with the switch prompt, got:\n%s", want, text)
+		}
+	}
+	if _, err := clientset.CoreV1().ResourceQuotas("team-d").Get(context.TODO(), "quota-small", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected quota-small in team-d: %v", err)
+	}
+
+	// RBAC denials are shown in the form too
+	clientset.PrependReactor("create", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("namespaces"), "team-c", errors.New("RBAC: access denied"))
+	})
+	go func() {
+		typeText(screen, "team-c")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	}()
+	tui.createNamespaceDialog()
+	if text := screenText(screen); !strings.Contains(text, "Error: not allowed to create namespaces") {
+		t.Errorf("Expected the Forbidden error in the form, got:\n%s", text)
+	}
+}
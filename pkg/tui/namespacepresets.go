@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"embed"
+	"fmt"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// namespaceTemplates holds the ResourceQuota and LimitRange presets offered
+// when creating a namespace, as templates/quota-<size>.yaml and
+// templates/limitrange-<size>.yaml
+//
+//go:embed templates/*.yaml
+var namespaceTemplates embed.FS
+
+// noPreset is the preset choice creating no quota or limit range
+const noPreset = "none"
+
+// namespacePresetSizes are the preset choices of the namespace form, in the
+// order ←/→ cycles through them
+var namespacePresetSizes = []string{noPreset, "small", "medium", "large"}
+
+// readNamespaceTemplate decodes templates/<prefix>-<size>.yaml into obj
+func readNamespaceTemplate(prefix, size string, obj interface{}) error {
+	path := fmt.Sprintf("templates/%s-%s.yaml", prefix, size)
+	data, err := namespaceTemplates.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("no %s preset %q", prefix, size)
+	}
+	if err := yaml.UnmarshalStrict(data, obj); err != nil {
+		return fmt.Errorf("invalid template %s: %v", path, err)
+	}
+	return nil
+}
+
+// quotaPreset returns the ResourceQuota of a preset size
+func quotaPreset(size string) (*v1.ResourceQuota, error) {
+	quota := &v1.ResourceQuota{}
+	if err := readNamespaceTemplate("quota", size, quota); err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// limitRangePreset returns the LimitRange of a preset size
+func limitRangePreset(size string) (*v1.LimitRange, error) {
+	limitRange := &v1.LimitRange{}
+	if err := readNamespaceTemplate("limitrange", size, limitRange); err != nil {
+		return nil, err
+	}
+	return limitRange, nil
+}
+
+// namespaceStep is the outcome of one step of creating a namespace
+type namespaceStep struct {
+	kind string
+	name string
+	err  error
+}
+
+// line renders the step as a ✔ or ✘ line
+func (s namespaceStep) line() string {
+	if s.err != nil {
+		return fmt.Sprintf("✘ %s '%s' failed: %v", s.kind, s.name, s.err)
+	}
+	return fmt.Sprintf("✔ %s '%s' created", s.kind, s.name)
+}
+
+// applyNamespacePresets creates the quota and limit range presets chosen for
+// a new namespace, skipping those left at "none". Each is applied on its own:
+// a failed quota does not keep the limit range from being created.
+func applyNamespacePresets(clientset kubernetes.Interface, namespace, quotaSize, limitRangeSize string) []namespaceStep {
+	var steps []namespaceStep
+	if quotaSize != noPreset {
+		step := namespaceStep{kind: "ResourceQuota", name: "quota-" + quotaSize}
+		quota, err := quotaPreset(quotaSize)
+		if err == nil {
+			step.name = quota.Name
+			_, err = k8s.CreateResourceQuota(clientset, namespace, quota)
+		}
+		step.err = err
+		steps = append(steps, step)
+	}
+	if limitRangeSize != noPreset {
+		step := namespaceStep{kind: "LimitRange", name: "limits-" + limitRangeSize}
+		limitRange, err := limitRangePreset(limitRangeSize)
+		if err == nil {
+			step.name = limitRange.Name
+			_, err = k8s.CreateLimitRange(clientset, namespace, limitRange)
+		}
+		step.err = err
+		steps = append(steps, step)
+	}
+	return steps
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestNamespacePresetTemplates tests that every embedded quota and limit
+// range preset decodes
+func TestNamespacePresetTemplates(t *testing.T) {
+	for _, size := range namespacePresetSizes[1:] {
+		quota, err := quotaPreset(size)
+		if err != nil {
+			t.Errorf("quota %s: %v", size, err)
+		} else if quota.Name != "quota-"+size || quota.Spec.Hard.Pods().IsZero() || quota.Spec.Hard.Name(v1.ResourceLimitsCPU, resource.DecimalSI).IsZero() {
+			t.Errorf("quota %s: expected a named quota limiting pods and CPU, got %+v", size, quota)
+		}
+
+		limitRange, err := limitRangePreset(size)
+		if err != nil {
+			t.Errorf("limit range %s: %v", size, err)
+		} else if limitRange.Name != "limits-"+size || len(limitRange.Spec.Limits) != 1 || limitRange.Spec.Limits[0].Type != v1.LimitTypeContainer ||
+			limitRange.Spec.Limits[0].Default.Memory().IsZero() || limitRange.Spec.Limits[0].DefaultRequest.Cpu().IsZero() {
+			t.Errorf("limit range %s: expected container defaults, got %+v", size, limitRange)
+		}
+	}
+	if _, err := quotaPreset("huge"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
+
+// TestApplyNamespacePresets tests that each preset is applied on its own and
+// reported
+func TestApplyNamespacePresets(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	if steps := applyNamespacePresets(clientset, "team-a", noPreset, noPreset); len(steps) != 0 {
+		t.Errorf("Expected no steps without presets, got %+v", steps)
+	}
+
+	clientset.PrependReactor("create", "resourcequotas", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("resourcequotas"), "quota-large", errors.New("RBAC: access denied"))
+	})
+	steps := applyNamespacePresets(clientset, "team-a", "large", "small")
+	if len(steps) != 2 || steps[0].err == nil || steps[1].err != nil {
+		t.Fatalf("Expected a failed quota then a created limit range, got %+v", steps)
+	}
+	if line := steps[0].line(); !strings.HasPrefix(line, "✘ ResourceQuota 'quota-large' failed: ") {
+		t.Errorf("Unexpected quota line %q", line)
+	}
+	if line := steps[1].line(); line != "✔ LimitRange 'limits-small' created" {
+		t.Errorf("Unexpected limit range line %q", line)
+	}
+	limitRange, err := clientset.CoreV1().LimitRanges("team-a").Get(context.TODO(), "limits-small", metav1.GetOptions{})
+	if err != nil || limitRange.Spec.Limits[0].Max.Cpu().String() != "2" {
+		t.Errorf("Expected limits-small with a max of 2 CPUs, got %+v, %v", limitRange, err)
+	}
+}
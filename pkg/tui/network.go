@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// podNetworkTimeout bounds the service and endpoint lists of the pod
+	// shown in the details
+	podNetworkTimeout = 5 * time.Second
+	// podNetworkTTL is how long the network section of a pod is shown before
+	// its services and endpoints are listed again
+	podNetworkTTL = 15 * time.Second
+)
+
+// podNetworkLookup holds the network facts of the pod shown in the details
+// view
+type podNetworkLookup struct {
+	namespace string
+	name      string
+	network   *k8s.PodNetwork
+	err       error
+	at        time.Time
+}
+
+// podNetwork returns the network facts of a pod, joined again with the
+// services and endpoints of its namespace once the last join is older than
+// podNetworkTTL
+func (t *TUI) podNetwork(pod v1.Pod) (*k8s.PodNetwork, error) {
+	lookup := t.podNetworkLookup
+	if lookup == nil || lookup.namespace != pod.Namespace || lookup.name != pod.Name || time.Since(lookup.at) >= podNetworkTTL {
+		lookup = &podNetworkLookup{namespace: pod.Namespace, name: pod.Name}
+		ctx, cancel := context.WithTimeout(context.Background(), podNetworkTimeout)
+		lookup.network, lookup.err = k8s.DescribePodNetwork(ctx, t.clientset, &pod)
+		cancel()
+		lookup.at = time.Now()
+		t.podNetworkLookup = lookup
+	}
+	return lookup.network, lookup.err
+}
+
+// networkDetails returns the network lines of a pod's details: its IPs, the
+// ports its containers declare, the services sending it traffic and the
+// service ports targeting a port no container declares
+func (t *TUI) networkDetails(pod v1.Pod) []string {
+	if t.clientset == nil {
+		return nil
+	}
+
+	lines := []string{"", "Network:"}
+	network, err := t.podNetwork(pod)
+	if err != nil {
+		return append(lines, fmt.Sprintf("  Services unavailable: %v", err))
+	}
+
+	ips := "none yet"
+	if len(network.PodIPs) > 0 {
+		ips = strings.Join(network.PodIPs, ", ")
+	}
+	if network.HostNetwork {
+		ips += " (host network)"
+	}
+	lines = append(lines, "  Pod IPs: "+ips)
+	if network.NodeIP != "" {
+		lines = append(lines, "  Node IP: "+network.NodeIP)
+	}
+
+	ports := make([]string, 0, len(network.ContainerPorts))
+	for _, port := range network.ContainerPorts {
+		name := port.Container
+		if port.Name != "" {
+			name += "/" + port.Name
+		}
+		ports = append(ports, fmt.Sprintf("%s %d/%s", name, port.Port, port.Protocol))
+	}
+	if len(ports) == 0 {
+		ports = append(ports, "none declared")
+	}
+	lines = append(lines, "  Container ports: "+strings.Join(ports, ", "))
+
+	if len(network.Services) == 0 {
+		lines = append(lines, "  No services select this pod")
+	}
+	for _, svc := range network.Services {
+		lines = append(lines, fmt.Sprintf("  Service %s: %s (endpoint %s)", svc.Name, svc.DNSName, svc.Endpoint))
+		for _, port := range svc.Ports {
+			mark := "✔"
+			if !port.Declared {
+				mark = "✘"
+			}
+			lines = append(lines, fmt.Sprintf("    %s %d/%s → %s", mark, port.Port, port.Protocol, port.TargetPort))
+		}
+	}
+	for _, mismatch := range network.Mismatches {
+		lines = append(lines, "  ⚠ "+mismatch)
+	}
+	return lines
+}
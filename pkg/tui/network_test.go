@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestTUIPodIPs tests the Pod IP column on wide terminals and the IPs of a
+// dual-stack pod with the services exposing it in its details
+func TestTUIPodIPs(t *testing.T) {
+	screen := newTestScreen(t, 80, 25)
+
+	dualStack := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Status: v1.PodStatus{
+			Phase:  v1.PodRunning,
+			PodIP:  "10.0.0.5",
+			PodIPs: []v1.PodIP{{IP: "10.0.0.5"}, {IP: "fd00::5"}},
+		},
+	}
+	pending := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodPending}}
+	selecting := func(name string, selector map[string]string) v1.Service {
+		return v1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: v1.ServiceSpec{Selector: selector}}
+	}
+	tui := &TUI{
+		screen:      screen,
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		theme:       DefaultTheme(),
+		pods:        []v1.Pod{dualStack, pending},
+		services: []v1.Service{
+			selecting("web", map[string]string{"app": "web"}),
+			selecting("web-canary", map[string]string{"app": "web"}),
+			selecting("api", map[string]string{"app": "api"}),
+			selecting("external", nil),
+		},
+	}
+
+	screen.SetSize(120, 30)
+	if headers := tui.getTableHeaders(); len(headers) != 6 {
+		t.Errorf("Expected no Pod IP column at 120 columns, got %v", headers)
+	}
+	screen.SetSize(140, 30)
+	if headers := tui.getTableHeaders(); len(headers) != 7 || headers[6] != "Pod IP" {
+		t.Errorf("Expected a Pod IP column past 120 columns, got %v", headers)
+	}
+	if got := tui.getResourceColumnValue(dualStack, 6); got != "10.0.0.5" {
+		t.Errorf("Expected the primary IP in the column, got %q", got)
+	}
+	if got := tui.getResourceColumnValue(pending, 6); got != "<none>" {
+		t.Errorf("Expected <none> before an IP is assigned, got %q", got)
+	}
+
+	details := strings.Join(tui.getPodDetails(dualStack), "\n")
+	for _, want := range []string{"IPs: 10.0.0.5, fd00::5", "Exposed by: web, web-canary"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+	details = strings.Join(tui.getPodDetails(pending), "\n")
+	if !strings.Contains(details, "IPs: <none>") || strings.Contains(details, "Exposed by") {
+		t.Errorf("Expected no IPs and no services for the pending pod, got:\n%s", details)
+	}
+	if got := formatPodIPs(v1.Pod{Status: v1.PodStatus{PodIP: "10.0.0.7"}}); got != "10.0.0.7" {
+		t.Errorf("Expected the primary IP without PodIPs, got %q", got)
+	}
+}
+
+// TestTUIPodNetwork tests the network section of the pod details: the pod's
+// addresses, the services selecting it and a target port mismatch
+func TestTUIPodNetwork(t *testing.T) {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "app", Image: "nginx", Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+		}},
+		Status: v1.PodStatus{Phase: v1.PodRunning, HostIP: "192.168.1.10", PodIP: "10.0.0.5"},
+	}
+	clientset := fake.NewSimpleClientset(&pod,
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1.ServiceSpec{Selector: map[string]string{"app": "web"}, Ports: []v1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("http")},
+				{Port: 8443, TargetPort: intstr.FromInt32(8443)},
+			}},
+		},
+		&v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Subsets: []v1.EndpointSubset{{Addresses: []v1.EndpointAddress{
+				{IP: "10.0.0.5", TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "web-1"}},
+			}}},
+		},
+	)
+	tui := &TUI{
+		clientset:   clientset,
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		theme:       DefaultTheme(),
+	}
+
+	details := strings.Join(tui.getPodDetails(pod), "\n")
+	want := strings.Join([]string{
+		"Network:",
+		"  Pod IPs: 10.0.0.5",
+		"  Node IP: 192.168.1.10",
+		"  Container ports: app/http 8080/TCP",
+		"  Service web: web.default.svc.cluster.local (endpoint ready)",
+		"    ✔ 80/TCP → http",
+		"    ✘ 8443/TCP → 8443",
+		"  ⚠ service web port 8443 targets 8443/TCP, which no container declares",
+	}, "\n")
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected:\n%s\nin the details, got:\n%s", want, details)
+	}
+
+	// The join is cached between draws
+	lists := 0
+	clientset.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		return false, nil, nil
+	})
+	tui.getPodDetails(pod)
+	if lists != 0 {
+		t.Errorf("Expected the network to be cached between draws, got %d lists", lists)
+	}
+}
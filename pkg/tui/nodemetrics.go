@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// Node usage above nodeUsageWarning percent is drawn in yellow, and above
+// nodeUsageCritical percent in red
+const (
+	nodeUsageWarning  = 80
+	nodeUsageCritical = 95
+)
+
+// nodeUsageStyle colors style by how close a usage percentage is to the
+// node's allocatable; unknown and normal usage keep it unchanged
+func nodeUsageStyle(percent float64, style tcell.Style) tcell.Style {
+	switch {
+	case percent > nodeUsageCritical:
+		return style.Foreground(tcell.ColorRed).Bold(true)
+	case percent > nodeUsageWarning:
+		return style.Foreground(tcell.ColorYellow)
+	}
+	return style
+}
+
+// loadNodeUsage returns the usage of every node with metrics by name, or nil
+// without a metrics client or when metrics-server cannot be reached
+func (t *TUI) loadNodeUsage() map[string]k8s.NodeMetricSummary {
+	if t.metricsClientset == nil || t.clientset == nil {
+		return nil
+	}
+	nodes, err := k8s.GetNodeMetrics(context.Background(), t.metricsClientset, t.clientset)
+	if err != nil {
+		klog.Warningf("Node list shown without usage: %v", err)
+		return nil
+	}
+	usage := make(map[string]k8s.NodeMetricSummary, len(nodes))
+	for _, node := range nodes {
+		usage[node.NodeName] = node
+	}
+	return usage
+}
+
+// nodeUsageColumn returns a node's CPU% (cpu) or Mem% cell, "-" when its
+// usage is not known
+func (t *TUI) nodeUsageColumn(node v1.Node, cpu bool) string {
+	usage, ok := t.nodeUsage[node.Name]
+	if !ok {
+		return "-"
+	}
+	if cpu {
+		return formatPercent(usage.CPUPercent)
+	}
+	return formatPercent(usage.MemPercent)
+}
+
+// drawNodeUsageCells redraws the CPU% and Mem% cells of a node's row in
+// yellow or red when the node is running hot
+func (t *TUI) drawNodeUsageCells(node v1.Node, y int, layout tableLayout, style tcell.Style) {
+	usage, ok := t.nodeUsage[node.Name]
+	if !ok {
+		return
+	}
+	for i, percent := range []float64{usage.CPUPercent, usage.MemPercent} {
+		x, width, onScreen := layout.cell(5 + i)
+		if hot := nodeUsageStyle(percent, style); onScreen && hot != style {
+			t.drawText(x, y, width, fmt.Sprintf("%-*s", width, formatPercent(percent)), hot)
+		}
+	}
+}
+
+// dashboardNodeUsageSection draws the CPU and memory use of each node as bars
+// of its allocatable, colored by the busier of the two. It is left out
+// without a metrics client.
+func (t *TUI) dashboardNodeUsageSection(nodes []k8s.NodeMetricSummary, err error, width int) (dashboardSection, bool) {
+	if t.metricsClientset == nil {
+		return dashboardSection{}, false
+	}
+	header := tcell.StyleDefault.Foreground(t.theme.header).Bold(true)
+	if err != nil {
+		return dashboardSection{summary: []dashboardLine{
+			{text: "Node usage", style: header},
+			{text: fmt.Sprintf("  Unavailable, is metrics-server running? %v", err), style: tcell.StyleDefault.Foreground(tcell.ColorGray)},
+		}}, true
+	}
+
+	section := dashboardSection{summary: []dashboardLine{{text: "Node usage", style: header}}}
+	nameWidth := 0
+	for _, node := range nodes {
+		nameWidth = max(nameWidth, len(node.NodeName))
+	}
+	nameWidth = min(nameWidth, 24)
+	// Two bars share what is left after the indent, the name and the labels
+	barWidth := min((width-nameWidth-24)/2, dashboardMaxBarWidth/2)
+
+	for _, node := range nodes {
+		name := node.NodeName
+		if len(name) > nameWidth {
+			name = name[:nameWidth-3] + "..."
+		}
+		text := fmt.Sprintf("  %-*s", nameWidth, name)
+		for _, usage := range []struct {
+			label   string
+			percent float64
+		}{{"CPU", node.CPUPercent}, {"Mem", node.MemPercent}} {
+			text += " " + usage.label + " "
+			if barWidth > 0 {
+				text += usageBar(usage.percent, barWidth) + " "
+			}
+			text += fmt.Sprintf("%4s", formatPercent(usage.percent))
+		}
+		section.items = append(section.items, dashboardLine{
+			text:   text,
+			style:  nodeUsageStyle(max(node.CPUPercent, node.MemPercent), dashboardStatusStyle(true)),
+			target: &dashboardTarget{view: ResourceNodes, filter: node.NodeName},
+		})
+	}
+	return section, true
+}
+
+// usageBar draws a percentage as a bar of width block characters, empty when
+// the percentage is unknown and full past 100%
+func usageBar(percent float64, width int) string {
+	filled := 0
+	if percent > 0 {
+		filled = min(int(percent*float64(width)/100), width)
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestNodeUsageStyle(t *testing.T) {
+	base := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	tests := []struct {
+		percent float64
+		want    tcell.Color
+	}{
+		{-1, tcell.ColorWhite},
+		{50, tcell.ColorWhite},
+		{80, tcell.ColorWhite},
+		{80.5, tcell.ColorYellow},
+		{95, tcell.ColorYellow},
+		{96, tcell.ColorRed},
+		{130, tcell.ColorRed},
+	}
+	for _, tt := range tests {
+		if fg, _, _ := nodeUsageStyle(tt.percent, base).Decompose(); fg != tt.want {
+			t.Errorf("Expected %v for %v%%, got %v", tt.want, tt.percent, fg)
+		}
+	}
+
+	for percent, want := range map[float64]string{-1: "░░░░", 0: "░░░░", 50: "██░░", 99: "███░", 150: "████"} {
+		if got := usageBar(percent, 4); got != want {
+			t.Errorf("Expected %q for %v%%, got %q", want, percent, got)
+		}
+	}
+}
+
+// TestTUINodeUsage tests the CPU% and Mem% columns of the node list and the
+// node usage bars of the dashboard
+func TestTUINodeUsage(t *testing.T) {
+	node := func(name string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("1"),
+				v1.ResourceMemory: resource.MustParse("1Gi"),
+			}},
+		}
+	}
+	usage := func(name, cpu, memory string) metricsv1beta1.NodeMetrics {
+		return metricsv1beta1.NodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Usage:      v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(memory)},
+		}
+	}
+	metricsClient := &metricsfake.Clientset{}
+	metricsClient.AddReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{
+			usage("busy", "970m", "512Mi"),
+			usage("calm", "100m", "870Mi"),
+		}}, nil
+	})
+
+	tui, screen := newTestTUI(t, 140, 20, node("busy"), node("calm"), node("new"))
+	tui.currentView = ResourceNodes
+	tui.viewMode = ViewModeList
+	tui.selected = -1
+	tui.SetMetricsClientset(metricsClient)
+
+	go tui.loadNodesAsync(tui.newLoad(ResourceNodes, true))
+	tui.handleDataUpdate(<-tui.dataChan)
+	want := map[string][2]string{"busy": {"97%", "50%"}, "calm": {"10%", "85%"}, "new": {"-", "-"}}
+	for _, resource := range tui.getFilteredResources() {
+		name := tui.getResourceName(resource)
+		if got := [2]string{tui.getResourceColumnValue(resource, 5), tui.getResourceColumnValue(resource, 6)}; got != want[name] {
+			t.Errorf("Expected %s at %v, got %v", name, want[name], got)
+		}
+	}
+
+	// The hot cells are drawn in red and yellow
+	tui.draw()
+	screen.Show()
+	colWidths := tui.getColumnWidths(140, len(tui.getTableHeaders()))
+	cpuX := 2
+	for _, w := range colWidths[:5] {
+		cpuX += w + 3
+	}
+	memX := cpuX + colWidths[5] + 3
+	cells, width, _ := screen.GetContents()
+	found := 0
+	for y := 0; y*width < len(cells); y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				line.WriteRune(runes[0])
+			}
+		}
+		// Cells that are not hot keep the row's color, that of its name
+		_, _, rowStyle, _ := screen.GetContent(2, y)
+		normal, _, _ := rowStyle.Decompose()
+		var cpu, mem tcell.Color
+		switch {
+		case strings.Contains(line.String(), "│ busy "):
+			cpu, mem = tcell.ColorRed, normal
+		case strings.Contains(line.String(), "│ calm "):
+			cpu, mem = normal, tcell.ColorYellow
+		default:
+			continue
+		}
+		found++
+		for x, want := range map[int]tcell.Color{cpuX: cpu, memX: mem} {
+			_, _, style, _ := screen.GetContent(x, y)
+			if fg, _, _ := style.Decompose(); fg != want {
+				t.Errorf("Expected %v at column %d of %q, got %v", want, x, strings.TrimSpace(line.String()), fg)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("Expected both nodes with usage in the table, found %d", found)
+	}
+
+	// A pressure check carries no usage and keeps the last one
+	tui.handleDataUpdate(&DataUpdate{ResourceType: ResourceNodes, Nodes: tui.nodes, Background: true})
+	if len(tui.nodeUsage) != 2 {
+		t.Errorf("Expected the node usage to be kept, got %v", tui.nodeUsage)
+	}
+
+	// The dashboard draws a bar per node in the color of its busier resource
+	section, ok := tui.dashboardNodeUsageSection([]k8s.NodeMetricSummary{
+		{NodeName: "busy", CPUPercent: 97, MemPercent: 50},
+		{NodeName: "calm", CPUPercent: 10, MemPercent: 85},
+	}, nil, 120)
+	if !ok || len(section.items) != 2 {
+		t.Fatalf("Expected a line per node, got %+v", section)
+	}
+	for i, want := range []tcell.Color{tcell.ColorRed, tcell.ColorYellow} {
+		line := section.items[i]
+		if fg, _, _ := line.style.Decompose(); fg != want {
+			t.Errorf("Expected %q in %v, got %v", line.text, want, fg)
+		}
+	}
+	if text := section.items[0].text; !strings.Contains(text, "CPU ███████████████████░  97%") || !strings.Contains(text, "Mem ██████████░░░░░░░░░░  50%") {
+		t.Errorf("Unexpected bars %q", text)
+	}
+	if section, _ := tui.dashboardNodeUsageSection(nil, errors.New("the server could not find the requested resource"), 120); !strings.Contains(section.summary[1].text, "metrics-server") {
+		t.Errorf("Expected a hint about metrics-server, got %+v", section.summary)
+	}
+	tui.metricsClientset = nil
+	if _, ok := tui.dashboardNodeUsageSection(nil, nil, 120); ok {
+		t.Error("Expected no node usage section without a metrics client")
+	}
+}
@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/alerts"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// nodePressureInterval is how often the NodePressureWatcher checks the nodes
+const nodePressureInterval = 60 * time.Second
+
+// nodePressureRule names the notifications raised for node pressure
+const nodePressureRule = "node-pressure"
+
+// nodePressureBadges are the badges shown next to a node for each pressure
+// condition type
+var nodePressureBadges = map[string]string{
+	string(v1.NodeMemoryPressure): "[MemPressure]",
+	string(v1.NodeDiskPressure):   "[DiskPressure]",
+	string(v1.NodePIDPressure):    "[PIDPressure]",
+}
+
+// NodePressureWatcher polls the cluster's nodes and sends them to the TUI as
+// background updates, with a notification for every pressure condition a node
+// has gained since the previous check
+type NodePressureWatcher struct {
+	clientset kubernetes.Interface
+	interval  time.Duration
+	updates   chan<- *DataUpdate
+
+	// pressure holds each node's pressure conditions at the previous check
+	pressure map[string][]string
+}
+
+// NewNodePressureWatcher creates a watcher that sends node updates to updates
+// every interval
+func NewNodePressureWatcher(clientset kubernetes.Interface, interval time.Duration, updates chan<- *DataUpdate) *NodePressureWatcher {
+	return &NodePressureWatcher{
+		clientset: clientset,
+		interval:  interval,
+		updates:   updates,
+		pressure:  make(map[string][]string),
+	}
+}
+
+// Run checks the nodes immediately and then every interval until ctx is done
+func (w *NodePressureWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case w.updates <- w.check():
+		case <-ctx.Done():
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check lists the nodes and returns them with an event for every pressure
+// condition that was not present at the previous check
+func (w *NodePressureWatcher) check() *DataUpdate {
+	nodes, err := k8s.ListNodes(w.clientset)
+	update := &DataUpdate{ResourceType: ResourceNodes, Nodes: nodes, Error: err, Background: true}
+	if err != nil {
+		// Keep the previous state so a transient error does not re-raise
+		// every notification on recovery
+		return update
+	}
+
+	now := time.Now()
+	pressure := make(map[string][]string, len(nodes))
+	for _, node := range nodes {
+		conditions := k8s.GetNodePressureConditions(node)
+		if len(conditions) == 0 {
+			continue
+		}
+		pressure[node.Name] = conditions
+
+		var added []string
+		for _, condition := range conditions {
+			if !containsString(w.pressure[node.Name], condition) {
+				added = append(added, condition)
+			}
+		}
+		if len(added) > 0 {
+			update.Events = append(update.Events, alerts.Event{
+				Time:      now,
+				Rule:      nodePressureRule,
+				Condition: strings.Join(added, ","),
+				Kind:      "Node",
+				Name:      node.Name,
+			})
+		}
+	}
+	w.pressure = pressure
+
+	return update
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// nodePressureBadgeText returns the badges for a node's pressure conditions,
+// e.g. "[MemPressure] [DiskPressure]", or "" for a node without pressure
+func nodePressureBadgeText(node v1.Node) string {
+	var badges []string
+	for _, condition := range k8s.GetNodePressureConditions(node) {
+		badges = append(badges, nodePressureBadges[condition])
+	}
+	return strings.Join(badges, " ")
+}
+
+// nodesUnderPressure counts the nodes with at least one pressure condition
+func (t *TUI) nodesUnderPressure() int {
+	count := 0
+	for _, node := range t.nodes {
+		if len(k8s.GetNodePressureConditions(node)) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// nodePressureStatus returns the status bar alert for nodes under pressure,
+// or "" when there are none
+func (t *TUI) nodePressureStatus() string {
+	switch count := t.nodesUnderPressure(); count {
+	case 0:
+		return ""
+	case 1:
+		return "⚠ 1 node under pressure"
+	default:
+		return fmt.Sprintf("⚠ %d nodes under pressure", count)
+	}
+}
+
+// getNodeStatus returns Ready, NotReady or Unknown from a node's Ready condition
+func getNodeStatus(node v1.Node) string {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != v1.NodeReady {
+			continue
+		}
+		switch condition.Status {
+		case v1.ConditionTrue:
+			status := "Ready"
+			if node.Spec.Unschedulable {
+				status += ",SchedulingDisabled"
+			}
+			return status
+		case v1.ConditionFalse:
+			return "NotReady"
+		}
+	}
+	return "Unknown"
+}
+
+// getNodeRoles returns a node's roles from its node-role.kubernetes.io labels
+func getNodeRoles(node v1.Node) string {
+	var roles []string
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, "node-role.kubernetes.io/"); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return "<none>"
+	}
+	sort.Strings(roles)
+	return strings.Join(roles, ",")
+}
+
+// drawNodePressureBadges redraws a node row's pressure badges in red. The
+// badges follow the node name in the first column, which starts at x.
+func (t *TUI) drawNodePressureBadges(node v1.Node, x, y, colWidth int) {
+	badges := nodePressureBadgeText(node)
+	if badges == "" {
+		return
+	}
+	offset := len(node.Name) + 1
+	if offset >= colWidth {
+		return
+	}
+	style := tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite).Bold(true)
+	t.drawText(x+offset, y, colWidth-offset, badges, style)
+}
+
+// getNodeDetails returns formatted details for a node
+func (t *TUI) getNodeDetails(node v1.Node) []string {
+	details := []string{
+		fmt.Sprintf("Name: %s", node.Name),
+		fmt.Sprintf("Status: %s", getNodeStatus(node)),
+		fmt.Sprintf("Roles: %s", getNodeRoles(node)),
+		fmt.Sprintf("Version: %s", node.Status.NodeInfo.KubeletVersion),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(node.CreationTimestamp)),
+		"",
+		"Conditions:",
+	}
+
+	for _, condition := range node.Status.Conditions {
+		line := fmt.Sprintf("  - %s: %s", condition.Type, condition.Status)
+		if condition.Message != "" {
+			line += " (" + condition.Message + ")"
+		}
+		details = append(details, line)
+	}
+
+	return details
+}
@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/alerts"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// pressuredNode builds a ready node with the given pressure conditions True
+func pressuredNode(name string, pressure ...v1.NodeConditionType) *v1.Node {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue},
+		}},
+	}
+	for _, conditionType := range pressure {
+		node.Status.Conditions = append(node.Status.Conditions, v1.NodeCondition{Type: conditionType, Status: v1.ConditionTrue})
+	}
+	return node
+}
+
+// TestNodePressureWatcher tests that the watcher notifies only about pressure
+// conditions a node did not have at the previous check
+func TestNodePressureWatcher(t *testing.T) {
+	clientset := fake.NewSimpleClientset(pressuredNode("node-a", v1.NodeMemoryPressure), pressuredNode("node-b"))
+	watcher := NewNodePressureWatcher(clientset, time.Minute, nil)
+	ctx := context.Background()
+
+	update := watcher.check()
+	if !update.Background || update.ResourceType != ResourceNodes || len(update.Nodes) != 2 {
+		t.Fatalf("Expected a background update with 2 nodes, got %+v", update)
+	}
+	if len(update.Events) != 1 || update.Events[0].Name != "node-a" || update.Events[0].Condition != "MemoryPressure" {
+		t.Fatalf("Expected a MemoryPressure event for node-a, got %+v", update.Events)
+	}
+
+	if update := watcher.check(); len(update.Events) != 0 {
+		t.Errorf("Expected no repeated events, got %+v", update.Events)
+	}
+
+	clientset.CoreV1().Nodes().Update(ctx, pressuredNode("node-a", v1.NodeMemoryPressure, v1.NodeDiskPressure), metav1.UpdateOptions{})
+	clientset.CoreV1().Nodes().Update(ctx, pressuredNode("node-b", v1.NodePIDPressure), metav1.UpdateOptions{})
+	update = watcher.check()
+	conditions := map[string]string{}
+	for _, event := range update.Events {
+		conditions[event.Name] = event.Condition
+	}
+	if len(update.Events) != 2 || conditions["node-a"] != "DiskPressure" || conditions["node-b"] != "PIDPressure" {
+		t.Errorf("Expected only the new conditions, got %+v", update.Events)
+	}
+
+	// Pressure that clears and returns is notified again
+	clientset.CoreV1().Nodes().Update(ctx, pressuredNode("node-a"), metav1.UpdateOptions{})
+	watcher.check()
+	clientset.CoreV1().Nodes().Update(ctx, pressuredNode("node-a", v1.NodeMemoryPressure), metav1.UpdateOptions{})
+	if update := watcher.check(); len(update.Events) != 1 || update.Events[0].Name != "node-a" {
+		t.Errorf("Expected returning pressure to be notified, got %+v", update.Events)
+	}
+}
+
+// TestNodePressureWatcherRun tests that the watcher checks immediately and
+// stops when its context is cancelled
+func TestNodePressureWatcherRun(t *testing.T) {
+	updates := make(chan *DataUpdate)
+	watcher := NewNodePressureWatcher(fake.NewSimpleClientset(pressuredNode("node-a")), time.Millisecond, updates)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Run(ctx)
+		close(done)
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case update := <-updates:
+			if len(update.Nodes) != 1 {
+				t.Errorf("Expected 1 node, got %d", len(update.Nodes))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a node update")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher did not stop after cancellation")
+	}
+}
+
+// TestTUINodePressure tests the node badges, the status bar alert and that
+// background node updates raise notifications without touching the loading state
+func TestTUINodePressure(t *testing.T) {
+	tui := &TUI{currentView: ResourceNodes, loadingCounter: 2}
+
+	tui.handleDataUpdate(&DataUpdate{
+		ResourceType: ResourceNodes,
+		Nodes: []v1.Node{
+			*pressuredNode("node-a", v1.NodeMemoryPressure, v1.NodePIDPressure),
+			*pressuredNode("node-b", v1.NodeDiskPressure),
+			*pressuredNode("node-c"),
+		},
+		Background: true,
+		Events:     []alerts.Event{{Rule: nodePressureRule, Kind: "Node", Name: "node-a", Condition: "MemoryPressure"}},
+	})
+
+	if tui.loadingCounter != 2 {
+		t.Errorf("Expected a background update not to count towards loading, got counter %d", tui.loadingCounter)
+	}
+	if len(tui.notifications) != 1 || tui.notifications[0].Name != "node-a" {
+		t.Errorf("Expected a notification for node-a, got %+v", tui.notifications)
+	}
+	if got := tui.nodePressureStatus(); got != "⚠ 2 nodes under pressure" {
+		t.Errorf("Unexpected status bar alert %q", got)
+	}
+
+	resources := tui.getFilteredResources()
+	if len(resources) != 3 {
+		t.Fatalf("Expected 3 nodes, got %d", len(resources))
+	}
+	want := []string{"node-a [MemPressure] [PIDPressure]", "node-b [DiskPressure]", "node-c"}
+	for i, resource := range resources {
+		if got := tui.getResourceColumnValue(resource, 0); got != want[i] {
+			t.Errorf("Expected %q, got %q", want[i], got)
+		}
+		if got := tui.getResourceColumnValue(resource, 1); got != "Ready" {
+			t.Errorf("Expected Ready, got %q", got)
+		}
+	}
+
+	// A failed background check keeps the last known nodes
+	tui.handleDataUpdate(&DataUpdate{ResourceType: ResourceNodes, Background: true, Error: fmt.Errorf("timeout")})
+	if len(tui.nodes) != 3 {
+		t.Errorf("Expected the nodes to be kept after a failed check, got %d", len(tui.nodes))
+	}
+}
@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/util"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// permissionsCheckTimeout bounds the access reviews of one namespace
+	permissionsCheckTimeout = 10 * time.Second
+	// permissionsCacheTTL is how long the permissions of a namespace are shown
+	// before P checks them again
+	permissionsCacheTTL = 5 * time.Minute
+	// permissionsErrorPrefix starts the details line of a failed check, drawn
+	// in red
+	permissionsErrorPrefix = "  ✘ Permission check failed"
+	// permissionsColumnWidth is the width of a verb column of the grid
+	permissionsColumnWidth = 8
+)
+
+// namespacePermissions is what the current user can do in a namespace, as
+// shown in the Permissions section of its details
+type namespacePermissions struct {
+	allowed map[string]map[string]bool
+	err     error
+	// checking is set while the access reviews are running
+	checking bool
+	at       time.Time
+}
+
+// checkSelectedNamespacePermissions checks what the current user can do in
+// the selected namespace, unless it was checked within permissionsCacheTTL
+func (t *TUI) checkSelectedNamespacePermissions() {
+	ns, ok := t.getSelectedResource().(v1.Namespace)
+	if !ok {
+		return
+	}
+	t.permissionsMu.Lock()
+	cached := t.permissions[ns.Name]
+	if cached != nil && time.Since(cached.at) < permissionsCacheTTL {
+		t.permissionsMu.Unlock()
+		return
+	}
+	t.setPermissions(ns.Name, &namespacePermissions{checking: true})
+	t.permissionsMu.Unlock()
+	if t.screen != nil {
+		t.draw()
+		t.screen.Show()
+	}
+
+	checked := t.checkPermissions(ns.Name)
+	t.permissionsMu.Lock()
+	t.setPermissions(ns.Name, checked)
+	t.permissionsMu.Unlock()
+}
+
+// preflightPermissions checks in the background what the current user can do
+// in the current namespace, unless that is known or being checked, so that
+// the footer can grey out what RBAC forbids before it is attempted
+func (t *TUI) preflightPermissions() {
+	if !t.hasClientset() {
+		return
+	}
+	namespace := t.namespace
+	t.permissionsMu.Lock()
+	defer t.permissionsMu.Unlock()
+	if cached := t.permissions[namespace]; cached != nil && (cached.checking || time.Since(cached.at) < permissionsCacheTTL) {
+		return
+	}
+	t.setPermissions(namespace, &namespacePermissions{checking: true})
+
+	go func() {
+		checked := t.checkPermissions(namespace)
+		t.permissionsMu.Lock()
+		t.setPermissions(namespace, checked)
+		t.permissionsMu.Unlock()
+		t.requestRedraw()
+	}()
+}
+
+// checkPermissions runs the access reviews of a namespace
+func (t *TUI) checkPermissions(namespace string) *namespacePermissions {
+	ctx, cancel := context.WithTimeout(context.Background(), permissionsCheckTimeout)
+	defer cancel()
+	allowed, err := k8s.CheckPermissions(ctx, t.clientset, namespace, k8s.PermissionResources, k8s.PermissionVerbs)
+	return &namespacePermissions{allowed: allowed, err: err, at: time.Now()}
+}
+
+// setPermissions records the permissions of a namespace; permissionsMu must
+// be held
+func (t *TUI) setPermissions(namespace string, permissions *namespacePermissions) {
+	if t.permissions == nil {
+		t.permissions = make(map[string]*namespacePermissions)
+	}
+	t.permissions[namespace] = permissions
+}
+
+// forbidden reports whether RBAC is known to forbid verb on resource in the
+// current namespace. Unchecked or failed checks, and resources outside
+// k8s.PermissionResources, forbid nothing: the API server still has the last
+// word.
+func (t *TUI) forbidden(verb, resource string) bool {
+	t.permissionsMu.Lock()
+	defer t.permissionsMu.Unlock()
+	permissions := t.permissions[t.namespace]
+	if permissions == nil || permissions.checking || permissions.err != nil {
+		return false
+	}
+	allowed, checked := permissions.allowed[resource][verb]
+	return checked && !allowed
+}
+
+// showForbidden tells that RBAC forbids verb on resource in the current
+// namespace instead of attempting it, until a key is pressed
+func (t *TUI) showForbidden(verb, resource string) {
+	message := fmt.Sprintf("Cannot %s %s in namespace %s: forbidden by RBAC (press any key)", verb, resource, t.namespace)
+	width, _ := t.screen.Size()
+	t.drawText(0, 1, width, message, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+	t.screen.Show()
+	for {
+		if _, ok := t.screen.PollEvent().(*tcell.EventKey); ok {
+			return
+		}
+	}
+}
+
+// viewResource returns the permission grid resource listed in the current
+// view, or "" outside the grid
+func (t *TUI) viewResource() string {
+	switch t.currentView {
+	case ResourcePods:
+		return "pods"
+	case ResourceDeployments:
+		return "deployments"
+	case ResourceServices:
+		return "services"
+	case ResourceConfigMaps:
+		return "configmaps"
+	}
+	return ""
+}
+
+// createResource returns the resource c creates in the current view
+func (t *TUI) createResource() string {
+	switch t.currentView {
+	case ResourceDeployments:
+		return "deployments"
+	case ResourceNamespaces:
+		return "namespaces"
+	case ResourceConfigMaps:
+		return "configmaps"
+	}
+	return "pods"
+}
+
+// permissionsDetails returns the Permissions section of a namespace's details:
+// a grid of resources by verbs, or how to check them
+func (t *TUI) permissionsDetails(namespace string) []string {
+	lines := []string{"", "Permissions:"}
+
+	t.permissionsMu.Lock()
+	permissions := t.permissions[namespace]
+	t.permissionsMu.Unlock()
+	switch {
+	case permissions != nil && permissions.checking:
+		return append(lines, "  Checking...")
+	case permissions == nil || time.Since(permissions.at) >= permissionsCacheTTL:
+		return append(lines, "  Press W to check what you can do in this namespace")
+	case permissions.err != nil:
+		return append(lines, fmt.Sprintf("%s: %v", permissionsErrorPrefix, permissions.err))
+	}
+
+	header := fmt.Sprintf("  %-18s", "")
+	for _, verb := range k8s.PermissionVerbs {
+		header += fmt.Sprintf("%-*s", permissionsColumnWidth, verb)
+	}
+	lines = append(lines, header)
+	for _, resource := range k8s.PermissionResources {
+		row := fmt.Sprintf("  %-18s", resource)
+		for _, verb := range k8s.PermissionVerbs {
+			// The marks are two cells wide and cover the space after them
+			mark := "❌"
+			if permissions.allowed[resource][verb] {
+				mark = "✅"
+			}
+			row += mark + strings.Repeat(" ", permissionsColumnWidth-1)
+		}
+		lines = append(lines, row)
+	}
+	return append(lines, fmt.Sprintf("  checked %s ago", util.HumanDuration(time.Since(permissions.at))))
+}
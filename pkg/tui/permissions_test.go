@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTUINamespacePermissions(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	reviews := 0
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Namespace == "shop" && attrs.Resource == "pods" && (attrs.Verb == "get" || attrs.Verb == "list")
+		return true, review, nil
+	})
+
+	shop := v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}, Status: v1.NamespaceStatus{Phase: v1.NamespaceActive}}
+	tui := &TUI{
+		clientset:   clientset,
+		config:      config.DefaultConfig(),
+		currentView: ResourceNamespaces,
+		viewMode:    ViewModeDetails,
+		namespaces:  []v1.Namespace{shop},
+		theme:       DefaultTheme(),
+	}
+
+	details := strings.Join(tui.getNamespaceDetails(shop), "\n")
+	if !strings.Contains(details, "Press W to check") {
+		t.Errorf("Expected a hint before the check, got:\n%s", details)
+	}
+
+	tui.checkSelectedNamespacePermissions()
+	want := len(k8s.PermissionResources) * len(k8s.PermissionVerbs)
+	if reviews != want {
+		t.Errorf("Expected %d access reviews, got %d", want, reviews)
+	}
+	details = strings.Join(tui.getNamespaceDetails(shop), "\n")
+	for _, line := range []string{
+		"                    get     list    create  update  delete  ",
+		"  pods              ✅       ✅       ❌       ❌       ❌       ",
+		"  deployments       ❌       ❌       ❌       ❌       ❌       ",
+	} {
+		if !strings.Contains(details, line) {
+			t.Errorf("Expected %q in the grid, got:\n%s", line, details)
+		}
+	}
+
+	// Checks within the cache TTL reuse the results
+	tui.checkSelectedNamespacePermissions()
+	if reviews != want {
+		t.Errorf("Expected cached permissions to be reused, got %d reviews", reviews)
+	}
+	tui.permissions["shop"].at = time.Now().Add(-permissionsCacheTTL)
+	tui.checkSelectedNamespacePermissions()
+	if reviews != 2*want {
+		t.Errorf("Expected expired permissions to be checked again, got %d reviews", reviews)
+	}
+
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	tui.permissions["shop"].at = time.Time{}
+	tui.checkSelectedNamespacePermissions()
+	details = strings.Join(tui.getNamespaceDetails(shop), "\n")
+	if !strings.Contains(details, permissionsErrorPrefix+": connection refused") {
+		t.Errorf("Expected the check error, got:\n%s", details)
+	}
+}
+
+func TestTUIPermissionPreflight(t *testing.T) {
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}}
+	tui, screen := newTestTUI(t, 140, 30, &pod)
+	clientset := tui.clientset.(*fake.Clientset)
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Resource != "pods" || attrs.Verb == "get" || attrs.Verb == "list"
+		return true, review, nil
+	})
+	tui.namespace = "shop"
+	tui.currentView = ResourcePods
+	tui.pods = []v1.Pod{pod}
+
+	// Nothing is forbidden until the check is done
+	if tui.forbidden("delete", "pods") {
+		t.Error("Expected nothing to be forbidden before the check")
+	}
+	tui.preflightPermissions()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tui.permissionsMu.Lock()
+		checking := tui.permissions["shop"].checking
+		tui.permissionsMu.Unlock()
+		if !checking {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the permission check")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !tui.forbidden("delete", "pods") || !tui.forbidden("create", "pods") || tui.forbidden("delete", "deployments") || tui.forbidden("create", "namespaces") {
+		t.Errorf("Expected only pod creates and deletes to be forbidden, got %v", tui.permissions["shop"].allowed)
+	}
+
+	// The footer greys them out
+	width, _ := screen.Size()
+	tui.drawFooter(width, 0)
+	screen.Show()
+	cells, _, _ := screen.GetContents()
+	text := func(x, n int) (string, tcell.Style) {
+		var runes []rune
+		for i := x; i < x+n; i++ {
+			runes = append(runes, cells[i].Runes[0])
+		}
+		return string(runes), cells[x].Style
+	}
+	footer, _ := text(0, width)
+	x := utf8.RuneCountInString(footer[:strings.Index(footer, "d Delete")])
+	if label, style := text(x, len("d Delete")); label != "d Delete" || style != tcell.StyleDefault.Background(tcell.ColorDarkGray).Foreground(tcell.ColorGray).StrikeThrough(true) {
+		t.Errorf("Expected d Delete to be greyed out, got %q", label)
+	}
+	x = utf8.RuneCountInString(footer[:strings.Index(footer, "r Refresh")])
+	if _, style := text(x, 1); style != tcell.StyleDefault.Background(tcell.ColorDarkGray).Foreground(tcell.ColorWhite) {
+		t.Error("Expected r Refresh not to be greyed out")
+	}
+
+	// d says so instead of deleting
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tui.deleteSelectedResource()
+	line, _ := text(width, width)
+	if !strings.HasPrefix(line, "Cannot delete pods in namespace shop: forbidden by RBAC") {
+		t.Errorf("Expected the delete to be refused, got %q", line)
+	}
+	if _, err := clientset.CoreV1().Pods("shop").Get(context.Background(), "web-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected web-1 not to be deleted, got %v", err)
+	}
+
+	// A second preflight within the TTL checks nothing again
+	checked := tui.permissions["shop"]
+	tui.preflightPermissions()
+	if tui.permissions["shop"] != checked {
+		t.Error("Expected the checked permissions to be reused")
+	}
+}
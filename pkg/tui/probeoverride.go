@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+// probeOverrideTimeout bounds the lookups and the patch of the probe
+// override view
+const probeOverrideTimeout = 10 * time.Second
+
+// probeRow is a probe of a container in the probe override view
+type probeRow struct {
+	container   string
+	probeType   string
+	description string
+}
+
+// probeOverrideView is the state of the probe override view of a pod: its
+// probes, those marked for removal, and the deployment they are removed from
+type probeOverrideView struct {
+	pod  v1.Pod
+	rows []probeRow
+	// deployment manages the pod; ownerErr says why it could not be found
+	deployment string
+	ownerErr   error
+	selected   int
+	marked     map[int]bool
+	errMsg     string
+}
+
+// newProbeOverrideView lists the probes of a pod, container by container
+func newProbeOverrideView(pod v1.Pod) *probeOverrideView {
+	view := &probeOverrideView{pod: pod, marked: make(map[int]bool)}
+	for _, container := range pod.Spec.Containers {
+		for _, probeType := range k8s.ProbeTypes {
+			if probe := k8s.ContainerProbe(container, probeType); probe != nil {
+				view.rows = append(view.rows, probeRow{container: container.Name, probeType: probeType, description: k8s.DescribeProbe(probe)})
+			}
+		}
+	}
+	return view
+}
+
+// markedProbes returns the probes marked for removal, in display order
+func (v *probeOverrideView) markedProbes() []k8s.ProbeRef {
+	var probes []k8s.ProbeRef
+	for i, row := range v.rows {
+		if v.marked[i] {
+			probes = append(probes, k8s.ProbeRef{Container: row.container, Type: row.probeType})
+		}
+	}
+	return probes
+}
+
+// openProbeOverride opens the probe override view of the pod shown in the
+// details
+func (t *TUI) openProbeOverride() {
+	pod, ok := t.getSelectedResource().(v1.Pod)
+	if !ok {
+		return
+	}
+	view := newProbeOverrideView(pod)
+	ctx, cancel := context.WithTimeout(context.Background(), probeOverrideTimeout)
+	view.deployment, view.ownerErr = k8s.PodDeployment(ctx, t.clientset, &pod)
+	cancel()
+
+	t.probeOverride = view
+	t.viewMode = ViewModeProbeOverride
+}
+
+// closeProbeOverride returns from the probe override view to the details
+func (t *TUI) closeProbeOverride() {
+	t.probeOverride = nil
+	if t.viewMode == ViewModeProbeOverride {
+		t.viewMode = ViewModeDetails
+	}
+}
+
+// handleProbeOverrideKey handles a key in the probe override view, leaving
+// only quitting to the main loop
+func (t *TUI) handleProbeOverrideKey(ev *tcell.EventKey) bool {
+	view := t.probeOverride
+	if view == nil {
+		t.closeProbeOverride()
+		return true
+	}
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return false
+	case tcell.KeyEscape:
+		t.closeProbeOverride()
+	case tcell.KeyUp:
+		if view.selected > 0 {
+			view.selected--
+		}
+	case tcell.KeyDown:
+		if view.selected < len(view.rows)-1 {
+			view.selected++
+		}
+	case tcell.KeyCtrlS:
+		t.disableMarkedProbes()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return false
+		case 'd':
+			if view.selected < len(view.rows) {
+				view.marked[view.selected] = !view.marked[view.selected]
+			}
+		}
+	}
+	return true
+}
+
+// disableMarkedProbes removes the marked probes from the pod template of the
+// pod's deployment, which rolls out new pods without them
+func (t *TUI) disableMarkedProbes() {
+	view := t.probeOverride
+	probes := view.markedProbes()
+	switch {
+	case len(probes) == 0:
+		view.errMsg = "Mark probes to disable with d first"
+		return
+	case view.ownerErr != nil:
+		view.errMsg = view.ownerErr.Error()
+		return
+	}
+
+	namespace := view.pod.Namespace
+	if !t.confirmProtectedActionIn(namespace, "disable probes of", "deployment", view.deployment) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeOverrideTimeout)
+	patch, err := k8s.DisableContainerProbes(ctx, t.clientset, namespace, view.deployment, probes)
+	cancel()
+	if err != nil {
+		view.errMsg = err.Error()
+		return
+	}
+
+	t.recordAction(fmt.Sprintf("Disabled %d probes of deployment '%s'", len(probes), view.deployment),
+		k8s.KubectlJSONPatch(namespace, "deployment", view.deployment, patch))
+	t.closeProbeOverride()
+	t.loadDeployments()
+}
+
+// probeOverrideLines renders the probe override view
+func (t *TUI) probeOverrideLines() []string {
+	view := t.probeOverride
+	lines := []string{fmt.Sprintf("Probes of pod '%s'", view.pod.Name), ""}
+
+	container := ""
+	for i, row := range view.rows {
+		if row.container != container {
+			container = row.container
+			lines = append(lines, "  "+container+":")
+		}
+		marker, state := "  ", " "
+		if i == view.selected {
+			marker = "▶ "
+		}
+		if view.marked[i] {
+			state = "✘"
+		}
+		lines = append(lines, fmt.Sprintf("  %s%s %-9s %s", marker, state, row.probeType, row.description))
+	}
+	if len(view.rows) == 0 {
+		lines = append(lines, "  No probes")
+	}
+
+	lines = append(lines, "")
+	if view.ownerErr != nil {
+		lines = append(lines, "Probes cannot be disabled: "+view.ownerErr.Error())
+	} else if count := len(view.markedProbes()); count > 0 {
+		lines = append(lines, fmt.Sprintf("%d probes will be removed from the pod template of deployment '%s'", count, view.deployment))
+	}
+	if view.errMsg != "" {
+		lines = append(lines, "Error: "+view.errMsg)
+	}
+	return lines
+}
+
+// drawProbeOverrideView draws the probes of a pod with the rollout warning
+func (t *TUI) drawProbeOverrideView(width, height int) {
+	header := fmt.Sprintf(" 🩺 Probe Override: %s/%s ", t.probeOverride.pod.Namespace, t.probeOverride.pod.Name)
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	warning := " ⚠ Disabling probes will trigger a rolling restart "
+	t.drawText(0, 1, width, warning, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite).Bold(true))
+
+	for i, line := range t.probeOverrideLines() {
+		if 3+i >= height-1 {
+			break
+		}
+		t.drawText(0, 3+i, width, line, tcell.StyleDefault.Foreground(t.theme.foreground))
+	}
+
+	footer := " ↑↓ Select │ d Mark for removal │ Ctrl+S Disable marked probes │ ESC Back "
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
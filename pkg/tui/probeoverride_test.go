@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestTUIProbeOverride tests marking probes of a pod in the probe override
+// view and removing them from its deployment's pod template
+func TestTUIProbeOverride(t *testing.T) {
+	probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt32(8080)}}}
+	containers := []v1.Container{
+		{Name: "sidecar", Image: "envoy", ReadinessProbe: probe},
+		{Name: "web", Image: "nginx", LivenessProbe: probe, ReadinessProbe: probe},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: containers}}},
+	}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "web-5d9c",
+		Namespace:       "default",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))},
+	}}
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-5d9c-x7k2p",
+			Namespace:       "default",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(replicaSet, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))},
+		},
+		Spec: v1.PodSpec{Containers: containers},
+	}
+	bare := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default"}, Spec: v1.PodSpec{Containers: containers}}
+	tui, screen := newTestTUI(t, 120, 30, deployment, replicaSet, &pod)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeDetails
+	tui.pods = []v1.Pod{pod, bare}
+	key := func(k tcell.Key, r rune) {
+		if !tui.handleProbeOverrideKey(tcell.NewEventKey(k, r, tcell.ModNone)) {
+			t.Fatalf("Expected key %v %q to be handled", k, r)
+		}
+	}
+
+	tui.openProbeOverride()
+	if tui.viewMode != ViewModeProbeOverride || tui.probeOverride.deployment != "web" || len(tui.probeOverride.rows) != 3 {
+		t.Fatalf("Expected the three probes of the pod of deployment web, got %+v", tui.probeOverride)
+	}
+	tui.draw()
+	screen.Show()
+	text := strings.Join(tui.probeOverrideLines(), "\n")
+	if !strings.Contains(text, "  web:\n      liveness  httpGet :8080/healthz every 10s, 3 failures") {
+		t.Errorf("Expected the web liveness probe, got:\n%s", text)
+	}
+	cells, width, _ := screen.GetContents()
+	var warning strings.Builder
+	for x := 0; x < width; x++ {
+		if runes := cells[width+x].Runes; len(runes) > 0 {
+			warning.WriteRune(runes[0])
+		}
+	}
+	if !strings.Contains(warning.String(), "Disabling probes will trigger a rolling restart") {
+		t.Errorf("Expected the rollout warning under the header, got %q", warning.String())
+	}
+
+	// Saving without marks explains what to do
+	key(tcell.KeyCtrlS, 0)
+	if tui.probeOverride.errMsg == "" {
+		t.Error("Expected an error when no probe is marked")
+	}
+
+	// Mark the web liveness probe, then the readiness one and unmark it
+	key(tcell.KeyDown, 0)
+	key(tcell.KeyRune, 'd')
+	key(tcell.KeyDown, 0)
+	key(tcell.KeyRune, 'd')
+	key(tcell.KeyRune, 'd')
+	if text := strings.Join(tui.probeOverrideLines(), "\n"); !strings.Contains(text, "1 probes will be removed from the pod template of deployment 'web'") {
+		t.Errorf("Expected one marked probe, got:\n%s", text)
+	}
+	key(tcell.KeyCtrlS, 0)
+
+	updated, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	sidecar, web := updated.Spec.Template.Spec.Containers[0], updated.Spec.Template.Spec.Containers[1]
+	if web.LivenessProbe != nil || web.ReadinessProbe == nil || sidecar.ReadinessProbe == nil {
+		t.Errorf("Expected only the web liveness probe to be removed, got %+v and %+v", sidecar, web)
+	}
+	if tui.viewMode != ViewModeDetails || tui.probeOverride != nil {
+		t.Errorf("Expected to return to the details, got view mode %v", tui.viewMode)
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "kubectl -n default patch deployment web --type=json") {
+		t.Errorf("Expected the JSON patch in the status bar, got %q", status)
+	}
+
+	// A pod without a deployment cannot have its probes disabled
+	tui.selected = 1
+	tui.openProbeOverride()
+	key(tcell.KeyRune, 'd')
+	key(tcell.KeyCtrlS, 0)
+	if text := strings.Join(tui.probeOverrideLines(), "\n"); !strings.Contains(text, "Probes cannot be disabled: pod is not managed by a deployment: debug") {
+		t.Errorf("Expected the missing deployment to be explained, got:\n%s", text)
+	}
+	key(tcell.KeyEscape, 0)
+	if tui.viewMode != ViewModeDetails {
+		t.Errorf("Expected Esc to return to the details, got %v", tui.viewMode)
+	}
+}
@@ -0,0 +1,240 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// pvcSnapshotsTTL is how long the snapshots in the details of a PVC are
+	// shown before they are listed again, as readyToUse changes without the
+	// PVC changing
+	pvcSnapshotsTTL = 10 * time.Second
+	// snapshotTimeout bounds listing snapshot classes and creating a snapshot
+	snapshotTimeout = 10 * time.Second
+)
+
+// errNoSnapshotClient is the error of snapshot requests without a dynamic
+// client
+var errNoSnapshotClient = errors.New("volume snapshots need a dynamic client")
+
+// pvcSnapshots are the VolumeSnapshots of the namespace of the PVC shown in
+// the details view
+type pvcSnapshots struct {
+	namespace string
+	snapshots []k8s.VolumeSnapshotSummary
+	err       error
+	at        time.Time
+}
+
+// loadPVCsAsync loads persistent volume claims asynchronously
+func (t *TUI) loadPVCsAsync(load dataLoad) {
+	update := load.update()
+	update.PVCs, update.Error = t.data().ListPVCs(load.namespace)
+	t.dataChan <- update
+}
+
+// pvcSnapshotsIn returns the VolumeSnapshots of a namespace, listed again
+// once pvcSnapshotsTTL has passed
+func (t *TUI) pvcSnapshotsIn(namespace string) *pvcSnapshots {
+	cached := t.pvcSnapshots
+	if cached != nil && cached.namespace == namespace && time.Since(cached.at) < pvcSnapshotsTTL {
+		return cached
+	}
+
+	snapshots, err := []k8s.VolumeSnapshotSummary(nil), errNoSnapshotClient
+	if t.dynamicClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		snapshots, err = k8s.ListVolumeSnapshots(ctx, t.dynamicClient, namespace)
+		cancel()
+	}
+	t.pvcSnapshots = &pvcSnapshots{namespace: namespace, snapshots: snapshots, err: err, at: time.Now()}
+	return t.pvcSnapshots
+}
+
+// getPVCDetails returns formatted details for a persistent volume claim, with
+// the snapshots taken from it
+func (t *TUI) getPVCDetails(pvc v1.PersistentVolumeClaim) []string {
+	details := []string{
+		fmt.Sprintf("Name: %s", pvc.Name),
+		fmt.Sprintf("Namespace: %s", pvc.Namespace),
+		fmt.Sprintf("Status: %s", pvc.Status.Phase),
+		fmt.Sprintf("Volume: %s", pvcVolume(pvc)),
+		fmt.Sprintf("Capacity: %s", pvcCapacity(pvc)),
+		fmt.Sprintf("Access modes: %s", pvcAccessModes(pvc)),
+		fmt.Sprintf("Storage class: %s", pvcStorageClass(pvc)),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(pvc.CreationTimestamp)),
+		"",
+		"Snapshots:",
+	}
+
+	snapshots := t.pvcSnapshotsIn(pvc.Namespace)
+	if snapshots.err != nil {
+		return append(details, fmt.Sprintf("  %v", snapshots.err))
+	}
+	taken := k8s.PVCSnapshots(snapshots.snapshots, pvc.Name)
+	if len(taken) == 0 {
+		return append(details, "  none (V: snapshot)")
+	}
+	for _, snapshot := range taken {
+		details = append(details, formatSnapshotLine(snapshot, t.formatAge(snapshot.CreationTimestamp)))
+	}
+	return details
+}
+
+// formatSnapshotLine describes a snapshot in the details of its PVC, e.g.
+// "  - data-db-0-20240101-120000: readyToUse true, class csi-snap, 10Gi, 2m"
+func formatSnapshotLine(snapshot k8s.VolumeSnapshotSummary, age string) string {
+	line := fmt.Sprintf("  - %s: readyToUse %t", snapshot.Name, snapshot.ReadyToUse)
+	if snapshot.SnapshotClass != "" {
+		line += ", class " + snapshot.SnapshotClass
+	}
+	if snapshot.RestoreSize != "" {
+		line += ", " + snapshot.RestoreSize
+	}
+	line += ", " + age
+	if snapshot.Error != "" {
+		line += " (" + snapshot.Error + ")"
+	}
+	return line
+}
+
+func pvcVolume(pvc v1.PersistentVolumeClaim) string {
+	if pvc.Spec.VolumeName == "" {
+		return "<none>"
+	}
+	return pvc.Spec.VolumeName
+}
+
+func pvcCapacity(pvc v1.PersistentVolumeClaim) string {
+	if capacity, ok := pvc.Status.Capacity[v1.ResourceStorage]; ok {
+		return capacity.String()
+	}
+	return "<none>"
+}
+
+func pvcAccessModes(pvc v1.PersistentVolumeClaim) string {
+	modes := make([]string, 0, len(pvc.Spec.AccessModes))
+	for _, mode := range pvc.Spec.AccessModes {
+		modes = append(modes, string(mode))
+	}
+	return strings.Join(modes, ",")
+}
+
+func pvcStorageClass(pvc v1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return "<default>"
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// snapshotClassDialogLines returns the lines of the snapshot class picker of
+// a PVC with the class at selected highlighted
+func snapshotClassDialogLines(pvc v1.PersistentVolumeClaim, classes []k8s.VolumeSnapshotClass, selected int) []string {
+	lines := []string{fmt.Sprintf("Snapshot PVC '%s' with class:", pvc.Name)}
+	for i, class := range classes {
+		marker := "  "
+		if i == selected {
+			marker = "▶ "
+		}
+		line := fmt.Sprintf("%s%s (%s, deletionPolicy %s)", marker, class.Name, class.Driver, class.DeletionPolicy)
+		if class.Default {
+			line += " [default]"
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, "↑↓ Class │ Enter Snapshot │ Esc Cancel")
+}
+
+// snapshotClassDialog lets the user pick the VolumeSnapshotClass to snapshot
+// a PVC with, and reports whether Enter confirmed it
+func (t *TUI) snapshotClassDialog(pvc v1.PersistentVolumeClaim, classes []k8s.VolumeSnapshotClass) (string, bool) {
+	selected := 0
+	style := tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+	for {
+		t.draw()
+		lines := snapshotClassDialogLines(pvc, classes, selected)
+		width := 0
+		for _, line := range lines {
+			width = max(width, len([]rune(line))+2)
+		}
+		for i, line := range lines {
+			t.drawText(0, 1+i, width, " "+line+strings.Repeat(" ", width), style)
+		}
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyUp:
+			selected = max(selected-1, 0)
+		case tcell.KeyDown:
+			selected = min(selected+1, len(classes)-1)
+		case tcell.KeyEnter:
+			return classes[selected].Name, true
+		case tcell.KeyEscape:
+			return "", false
+		}
+	}
+}
+
+// snapshotSelectedPVC snapshots the selected PVC with the VolumeSnapshotClass
+// picked from the cluster's classes
+func (t *TUI) snapshotSelectedPVC() {
+	pvc, ok := t.getSelectedResource().(v1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+
+	err := errNoSnapshotClient
+	var classes []k8s.VolumeSnapshotClass
+	if t.dynamicClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		classes, err = k8s.ListVolumeSnapshotClasses(ctx, t.dynamicClient)
+		cancel()
+		if err == nil && len(classes) == 0 {
+			err = errors.New("no VolumeSnapshotClasses are installed")
+		}
+	}
+	if err != nil {
+		t.showSnapshotError(err)
+		return
+	}
+
+	class, ok := t.snapshotClassDialog(pvc, classes)
+	if !ok || !t.confirmProtectedActionIn(pvc.Namespace, "snapshot", "pvc", pvc.Name) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	name, err := k8s.CreateVolumeSnapshot(ctx, t.dynamicClient, pvc.Namespace, pvc.Name, class)
+	cancel()
+	if err != nil {
+		t.showSnapshotError(err)
+		return
+	}
+
+	t.recordAction(fmt.Sprintf("Created snapshot '%s' of PVC '%s'", name, pvc.Name), k8s.KubectlCreateFromManifest(pvc.Namespace))
+	// List the snapshots again for the new one to show
+	t.pvcSnapshots = nil
+}
+
+// showSnapshotError shows why a snapshot could not be taken
+func (t *TUI) showSnapshotError(err error) {
+	klog.Errorf("Failed to snapshot PVC: %v", err)
+	errorMsg := fmt.Sprintf("Error: failed to snapshot PVC: %v", err)
+	t.drawText(0, 3, 80, errorMsg, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+	t.screen.Show()
+	time.Sleep(2 * time.Second)
+}
@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newSnapshotTestClient returns a fake dynamic client serving VolumeSnapshots
+// and VolumeSnapshotClasses
+func newSnapshotTestClient(objects ...runtime.Object) *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		k8s.VolumeSnapshotResource:      "VolumeSnapshotList",
+		k8s.VolumeSnapshotClassResource: "VolumeSnapshotClassList",
+	}, objects...)
+}
+
+func newPVCTestSnapshot(name, pvc string, ready bool) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         "default",
+			"creationTimestamp": time.Now().Add(-2 * time.Minute).Format(time.RFC3339),
+		},
+		"spec": map[string]interface{}{
+			"source":                  map[string]interface{}{"persistentVolumeClaimName": pvc},
+			"volumeSnapshotClassName": "csi-snap",
+		},
+		"status": map[string]interface{}{"readyToUse": ready, "restoreSize": "10Gi"},
+	}}
+}
+
+func newPVCTestSnapshotClass(name string, isDefault bool) *unstructured.Unstructured {
+	class := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "snapshot.storage.k8s.io/v1",
+		"kind":           "VolumeSnapshotClass",
+		"metadata":       map[string]interface{}{"name": name},
+		"driver":         "ebs.csi.aws.com",
+		"deletionPolicy": "Delete",
+	}}
+	if isDefault {
+		class.SetAnnotations(map[string]string{"snapshot.storage.kubernetes.io/is-default-class": "true"})
+	}
+	return class
+}
+
+func newTestPVC(name string) *v1.PersistentVolumeClaim {
+	standard := "standard"
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &standard,
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			VolumeName:       "pv-" + name,
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase:    v1.ClaimBound,
+			Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+}
+
+// TestPVCDetails tests the PVCs tab and the snapshots with their readyToUse
+// in the details of a PVC
+func TestPVCDetails(t *testing.T) {
+	tui := &TUI{
+		clientset: fake.NewSimpleClientset(newTestPVC("data-db-0"), newTestPVC("data-db-1")),
+		dynamicClient: newSnapshotTestClient(
+			newPVCTestSnapshot("data-db-0-1", "data-db-0", true),
+			newPVCTestSnapshot("data-db-0-2", "data-db-0", false),
+			newPVCTestSnapshot("data-db-1-1", "data-db-1", true),
+		),
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePVCs,
+		dataChan:    make(chan *DataUpdate, 1),
+	}
+
+	tui.loadPVCsAsync(tui.newLoad(ResourcePVCs, false))
+	tui.handleDataUpdate(<-tui.dataChan)
+	if len(tui.pvcs) != 2 {
+		t.Fatalf("Expected 2 PVCs, got %d", len(tui.pvcs))
+	}
+	selected, ok := tui.getSelectedResource().(v1.PersistentVolumeClaim)
+	if !ok || selected.Name != "data-db-0" {
+		t.Fatalf("Expected PVC data-db-0 to be selected, got %+v", tui.getSelectedResource())
+	}
+	for col, want := range []string{"data-db-0", "Bound", "pv-data-db-0", "10Gi", "standard"} {
+		if got := tui.getResourceColumnValue(selected, col); got != want {
+			t.Errorf("Expected column %d to be %q, got %q", col, want, got)
+		}
+	}
+
+	details := strings.Join(tui.getResourceDetails(selected), "\n")
+	want := strings.Join([]string{
+		"Snapshots:",
+		"  - data-db-0-1: readyToUse true, class csi-snap, 10Gi, 2m",
+		"  - data-db-0-2: readyToUse false, class csi-snap, 10Gi, 2m",
+	}, "\n")
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected:\n%s\nin the details, got:\n%s", want, details)
+	}
+	if strings.Contains(details, "data-db-1-1") {
+		t.Errorf("Expected only the snapshots of data-db-0, got:\n%s", details)
+	}
+
+	// Without the snapshot CRDs, or a dynamic client, the details say why
+	unavailable := newSnapshotTestClient()
+	unavailable.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+	tui.dynamicClient = unavailable
+	if details := strings.Join(tui.getPVCDetails(selected), "\n"); !strings.Contains(details, "data-db-0-1") {
+		t.Errorf("Expected the cached snapshots within the TTL, got:\n%s", details)
+	}
+	tui.pvcSnapshots.at = time.Now().Add(-pvcSnapshotsTTL)
+	if details := strings.Join(tui.getPVCDetails(selected), "\n"); !strings.Contains(details, "Snapshots:\n  "+k8s.ErrVolumeSnapshotsUnavailable.Error()) {
+		t.Errorf("Expected %q in the details, got:\n%s", k8s.ErrVolumeSnapshotsUnavailable, details)
+	}
+	tui.dynamicClient = nil
+	tui.pvcSnapshots = nil
+	if details := tui.getPVCDetails(selected); details[len(details)-1] != "  "+errNoSnapshotClient.Error() {
+		t.Errorf("Expected %q, got %q", errNoSnapshotClient, details[len(details)-1])
+	}
+}
+
+func TestSnapshotClassDialogLines(t *testing.T) {
+	pvc := newTestPVC("data-db-0")
+	classes := []k8s.VolumeSnapshotClass{
+		{Name: "csi-snap", Driver: "ebs.csi.aws.com", DeletionPolicy: "Delete", Default: true},
+		{Name: "csi-retain", Driver: "ebs.csi.aws.com", DeletionPolicy: "Retain"},
+	}
+
+	got := strings.Join(snapshotClassDialogLines(*pvc, classes, 1), "\n")
+	want := strings.Join([]string{
+		"Snapshot PVC 'data-db-0' with class:",
+		"  csi-snap (ebs.csi.aws.com, deletionPolicy Delete) [default]",
+		"▶ csi-retain (ebs.csi.aws.com, deletionPolicy Retain)",
+		"↑↓ Class │ Enter Snapshot │ Esc Cancel",
+	}, "\n")
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// TestTUISnapshotPVC tests that S in PVC details snapshots the PVC with the
+// class picked, and that Esc creates nothing
+func TestTUISnapshotPVC(t *testing.T) {
+	dynamicClient := newSnapshotTestClient(
+		newPVCTestSnapshotClass("csi-snap", true),
+		newPVCTestSnapshotClass("csi-retain", false),
+	)
+	tui, screen := newTestTUI(t, 120, 30)
+	tui.dynamicClient = dynamicClient
+	tui.currentView = ResourcePVCs
+	tui.viewMode = ViewModeDetails
+	tui.layoutMode = LayoutSplitVertical
+	tui.pvcs = []v1.PersistentVolumeClaim{*newTestPVC("data-db-0")}
+	listSnapshots := func() []k8s.VolumeSnapshotSummary {
+		snapshots, err := k8s.ListVolumeSnapshots(context.Background(), dynamicClient, "default")
+		if err != nil {
+			t.Fatalf("Failed to list snapshots: %v", err)
+		}
+		return snapshots
+	}
+
+	go screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone))
+	if snapshots := listSnapshots(); len(snapshots) != 0 {
+		t.Fatalf("Expected Esc to create no snapshot, got %+v", snapshots)
+	}
+
+	go func() {
+		screen.InjectKey(tcell.KeyDown, 0, tcell.ModNone)
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'V', tcell.ModNone))
+	snapshots := listSnapshots()
+	if len(snapshots) != 1 || snapshots[0].PVC != "data-db-0" || snapshots[0].SnapshotClass != "csi-retain" {
+		t.Fatalf("Expected a csi-retain snapshot of data-db-0, got %+v", snapshots)
+	}
+	if tui.lastAction == nil || !strings.Contains(tui.lastAction.message, snapshots[0].Name) {
+		t.Errorf("Expected the snapshot to be reported, got %+v", tui.lastAction)
+	}
+	if tui.layoutMode != LayoutSplitVertical {
+		t.Error("Expected S in PVC details not to switch the split layout")
+	}
+}
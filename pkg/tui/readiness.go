@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/util"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// unreadyHighlightAge is how long a running pod goes unready before its
+	// Ready cell turns yellow
+	unreadyHighlightAge = 60 * time.Second
+	// podEventsTimeout bounds the event list of the pod shown in the details
+	podEventsTimeout = 5 * time.Second
+	// podEventsTTL is how long the events of a pod are shown before they are
+	// listed again
+	podEventsTTL = 15 * time.Second
+)
+
+// podEventsLookup holds the events of the pod shown in the details view
+type podEventsLookup struct {
+	namespace string
+	name      string
+	events    []v1.Event
+	err       error
+	at        time.Time
+}
+
+// podEvents returns the events of a pod, listed again once the last list is
+// older than podEventsTTL
+func (t *TUI) podEvents(pod v1.Pod) ([]v1.Event, error) {
+	if t.clientset == nil {
+		return nil, nil
+	}
+	lookup := t.podEventsLookup
+	if lookup == nil || lookup.namespace != pod.Namespace || lookup.name != pod.Name || time.Since(lookup.at) >= podEventsTTL {
+		lookup = &podEventsLookup{namespace: pod.Namespace, name: pod.Name}
+		ctx, cancel := context.WithTimeout(context.Background(), podEventsTimeout)
+		lookup.events, lookup.err = k8s.ListPodEvents(ctx, t.clientset, pod.Namespace, pod.Name)
+		cancel()
+		lookup.at = time.Now()
+		t.podEventsLookup = lookup
+	}
+	return lookup.events, lookup.err
+}
+
+// readinessDetails returns the readiness lines of a pod's details: how long
+// it has been unready, and the probes of each container with their last
+// failures
+func (t *TUI) readinessDetails(pod v1.Pod) []string {
+	now := time.Now()
+	events, err := t.podEvents(pod)
+	readiness := k8s.DerivePodReadiness(&pod, events, now)
+
+	ready := fmt.Sprintf("Ready: %d/%d", readiness.ReadyContainers, readiness.TotalContainers)
+	if readiness.NotReady && readiness.UnreadySince != nil {
+		ready += fmt.Sprintf(" (running but not ready for %s)", util.HumanDuration(time.Duration(readiness.UnreadySeconds)*time.Second))
+	}
+	lines := []string{ready, "", "Containers:"}
+
+	for _, container := range readiness.Containers {
+		state := "ready"
+		if !container.Ready {
+			state = "not ready"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s, %d restarts", container.Name, state, container.RestartCount))
+		if container.ReadinessProbe == "" && container.LivenessProbe == "" {
+			lines = append(lines, "    no probes")
+			continue
+		}
+		lines = append(lines, t.probeLines("Readiness", container.ReadinessProbe, container.LastReadinessFailure, container.LastReadinessFailureTime, now)...)
+		lines = append(lines, t.probeLines("Liveness", container.LivenessProbe, container.LastLivenessFailure, container.LastLivenessFailureTime, now)...)
+	}
+
+	if err != nil {
+		lines = append(lines, fmt.Sprintf("  Probe failures unavailable: %v", err))
+	}
+	return lines
+}
+
+// probeLines describes one probe of a container and its last failure
+func (t *TUI) probeLines(kind, probe, failure string, failedAt *metav1.Time, now time.Time) []string {
+	if probe == "" {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("    %s probe: %s", kind, probe)}
+	if failure != "" && failedAt != nil {
+		lines = append(lines, fmt.Sprintf("      last failure %s ago: %s", util.HumanDuration(now.Sub(failedAt.Time)), failure))
+	}
+	return lines
+}
+
+// drawUnreadyCell redraws the Ready cell of a pod's row in yellow when the
+// pod has been running but not ready for over unreadyHighlightAge
+func (t *TUI) drawUnreadyCell(pod v1.Pod, y int, layout tableLayout, style tcell.Style) {
+	x, width, ok := layout.cell(2)
+	if !ok || !k8s.IsUnreadyFor(&pod, unreadyHighlightAge, time.Now()) {
+		return
+	}
+	t.drawText(x, y, width, fmt.Sprintf("%-*s", width, t.getReadyCount(pod)), style.Foreground(tcell.ColorYellow))
+}
@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestTUIPodReadiness(t *testing.T) {
+	pod := func(name string, unreadyFor time.Duration) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name:           "web",
+				ReadinessProbe: &v1.Probe{ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/ready", Port: intstr.FromInt(8080)}}},
+			}}},
+			Status: v1.PodStatus{
+				Phase:             v1.PodRunning,
+				Conditions:        []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse, LastTransitionTime: metav1.NewTime(time.Now().Add(-unreadyFor))}},
+				ContainerStatuses: []v1.ContainerStatus{{Name: "web"}},
+			},
+		}
+	}
+	event := v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "stuck.1", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "stuck", FieldPath: "spec.containers{web}"},
+		Reason:         "Unhealthy",
+		Message:        "Readiness probe failed: HTTP probe failed with statuscode: 503",
+		LastTimestamp:  metav1.NewTime(time.Now().Add(-5 * time.Second)),
+	}
+	tui, screen := newTestTUI(t, 120, 30, &event)
+	tui.currentView = ResourcePods
+	tui.viewMode = ViewModeList
+	tui.pods = []v1.Pod{pod("starting", 10*time.Second), pod("stuck", 5*time.Minute)}
+	tui.selected = -1
+
+	details := strings.Join(tui.getPodDetails(tui.pods[1]), "\n")
+	for _, want := range []string{
+		"Ready: 0/1 (running but not ready for 5m",
+		"  web: not ready, 0 restarts",
+		"    Readiness probe: httpGet :8080/ready",
+		"      last failure 5s ago: HTTP probe failed with statuscode: 503",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+
+	tui.draw()
+	screen.Show()
+	colWidths := tui.getColumnWidths(120, len(tui.getTableHeaders()))
+	readyX := 2 + colWidths[0] + 3 + colWidths[1] + 3
+	cells, width, _ := screen.GetContents()
+	found := 0
+	for y := 0; y*width < len(cells); y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				line.WriteRune(runes[0])
+			}
+		}
+		want := tcell.ColorYellow
+		switch {
+		case strings.Contains(line.String(), "│ stuck "):
+		case strings.Contains(line.String(), "│ starting "):
+			// Unready for less than a minute
+			want = tui.theme.foreground
+		default:
+			continue
+		}
+		found++
+		_, _, style, _ := screen.GetContent(readyX, y)
+		if fg, _, _ := style.Decompose(); fg != want {
+			t.Errorf("Expected the Ready cell of %q in %v, got %v", strings.TrimSpace(line.String()), want, fg)
+		}
+	}
+	if found != 2 {
+		t.Errorf("Expected both pods in the table, found %d", found)
+	}
+}
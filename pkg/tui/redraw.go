@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+)
+
+// defaultRedrawInterval is the redraw interval used without a configured one
+const defaultRedrawInterval = 200 * time.Millisecond
+
+// RedrawCoalescer limits redraws caused by background updates to one per
+// interval. Updates mark the screen dirty; every interval a dirty screen is
+// flushed once, however many updates marked it. It knows nothing about the
+// screen: flush is whatever schedules a redraw.
+type RedrawCoalescer struct {
+	interval time.Duration
+	flush    func()
+
+	dirty atomic.Bool
+	// events counts MarkDirty calls, for the debug overlay's events/sec
+	events atomic.Int64
+	// flushes counts the flushes done
+	flushes atomic.Int64
+}
+
+// NewRedrawCoalescer creates a coalescer calling flush at most once per
+// interval while the screen is dirty
+func NewRedrawCoalescer(interval time.Duration, flush func()) *RedrawCoalescer {
+	return &RedrawCoalescer{interval: interval, flush: flush}
+}
+
+// MarkDirty records an update needing a redraw. It is cheap enough to call
+// for every event.
+func (c *RedrawCoalescer) MarkDirty() {
+	c.events.Add(1)
+	c.dirty.Store(true)
+}
+
+// Tick flushes if the screen was marked dirty since the last flush, and
+// reports whether it did
+func (c *RedrawCoalescer) Tick() bool {
+	if !c.dirty.Swap(false) {
+		return false
+	}
+	c.flushes.Add(1)
+	c.flush()
+	return true
+}
+
+// Run ticks every interval until ctx is done
+func (c *RedrawCoalescer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Tick()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Events returns how many updates have marked the screen dirty
+func (c *RedrawCoalescer) Events() int64 {
+	return c.events.Load()
+}
+
+// Flushes returns how many times the coalescer has flushed
+func (c *RedrawCoalescer) Flushes() int64 {
+	return c.flushes.Load()
+}
+
+// redrawInterval returns the configured redraw interval
+func redrawInterval(cfg *config.Config) time.Duration {
+	if cfg.UI.RedrawIntervalMs <= 0 {
+		return defaultRedrawInterval
+	}
+	return time.Duration(cfg.UI.RedrawIntervalMs) * time.Millisecond
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRedrawCoalescerTick(t *testing.T) {
+	flushes := 0
+	c := NewRedrawCoalescer(time.Hour, func() { flushes++ })
+
+	if c.Tick() {
+		t.Error("Expected no flush before any update")
+	}
+	for i := 0; i < 50; i++ {
+		c.MarkDirty()
+	}
+	if !c.Tick() || flushes != 1 {
+		t.Errorf("Expected 50 updates to flush once, got %d flushes", flushes)
+	}
+	if c.Tick() || flushes != 1 {
+		t.Errorf("Expected no flush without new updates, got %d flushes", flushes)
+	}
+	c.MarkDirty()
+	if !c.Tick() || flushes != 2 {
+		t.Errorf("Expected a new update to flush again, got %d flushes", flushes)
+	}
+	if c.Events() != 51 || c.Flushes() != 2 {
+		t.Errorf("Expected 51 events and 2 flushes, got %d and %d", c.Events(), c.Flushes())
+	}
+}
+
+func TestRedrawCoalescerRun(t *testing.T) {
+	flushed := make(chan struct{}, 100)
+	c := NewRedrawCoalescer(20*time.Millisecond, func() { flushed <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+
+	// An event every millisecond for 200ms is at most one flush per 20ms
+	for i := 0; i < 200; i++ {
+		c.MarkDirty()
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-flushed:
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for a flush")
+	}
+	cancel()
+	<-done
+
+	if flushes := c.Flushes(); flushes > c.Events()/10 {
+		t.Errorf("Expected the flushes to be bounded by the interval, got %d for %d events", flushes, c.Events())
+	}
+}
+
+func TestRedrawInterval(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if got := redrawInterval(cfg); got != 200*time.Millisecond {
+		t.Errorf("Expected the default interval of 200ms, got %v", got)
+	}
+	cfg.UI.RedrawIntervalMs = 50
+	if got := redrawInterval(cfg); got != 50*time.Millisecond {
+		t.Errorf("Expected 50ms, got %v", got)
+	}
+	cfg.UI.RedrawIntervalMs = 0
+	if got := redrawInterval(cfg); got != defaultRedrawInterval {
+		t.Errorf("Expected the default interval without one, got %v", got)
+	}
+}
+
+// BenchmarkRedraw100EventsPerSec compares drawing the pods list on every
+// event with coalesced redraws. Each op is one second of traffic at 100
+// events/sec, so ns/op is the CPU a second of such traffic costs.
+func BenchmarkRedraw100EventsPerSec(b *testing.B) {
+	const (
+		eventsPerSec = 100
+		interval     = 200 * time.Millisecond
+		// eventsPerTick is how many events arrive between two ticks
+		eventsPerTick = eventsPerSec * int(interval) / int(time.Second)
+	)
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		b.Fatalf("Failed to init simulation screen: %v", err)
+	}
+	defer screen.Fini()
+	screen.SetSize(160, 50)
+
+	var pods []v1.Pod
+	for i := 0; i < 200; i++ {
+		pods = append(pods, v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		})
+	}
+	tui := &TUI{
+		screen:      screen,
+		namespace:   "default",
+		currentView: ResourcePods,
+		pods:        pods,
+		theme:       DefaultTheme(),
+	}
+	draw := func() {
+		tui.draw()
+		screen.Show()
+	}
+
+	b.Run("per-event", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for event := 0; event < eventsPerSec; event++ {
+				draw()
+			}
+		}
+		b.ReportMetric(eventsPerSec, "draws/s")
+	})
+
+	b.Run("coalesced", func(b *testing.B) {
+		c := NewRedrawCoalescer(interval, draw)
+		for i := 0; i < b.N; i++ {
+			for event := 1; event <= eventsPerSec; event++ {
+				c.MarkDirty()
+				if event%eventsPerTick == 0 {
+					c.Tick()
+				}
+			}
+		}
+		b.ReportMetric(float64(c.Flushes())/float64(b.N), "draws/s")
+	})
+}
@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultLoadRetryBackoff is the wait before the first retry of a failed
+	// load when ui.loadRetryBackoffMs is unset; each next retry waits twice
+	// as long, up to maxLoadRetryBackoff
+	defaultLoadRetryBackoff = 500 * time.Millisecond
+	maxLoadRetryBackoff     = 8 * time.Second
+	// loadToastDuration is how long the status bar says a retried load
+	// succeeded
+	loadToastDuration = 3 * time.Second
+)
+
+// loadFailure is the last error of a load that failed after its retries
+type loadFailure struct {
+	err      error
+	attempts int
+}
+
+// loadToast is a transient status bar message about a load
+type loadToast struct {
+	message string
+	until   time.Time
+}
+
+// loadRetries returns how many times a failed load is retried
+func (t *TUI) loadRetries() int {
+	if t.config == nil {
+		return 0
+	}
+	return t.config.UI.LoadRetries
+}
+
+// loadRetryDelay returns the wait before retry number retry, counted from
+// zero: the configured backoff doubled for each earlier retry
+func (t *TUI) loadRetryDelay(retry int) time.Duration {
+	backoff := defaultLoadRetryBackoff
+	if t.config != nil && t.config.UI.LoadRetryBackoffMs > 0 {
+		backoff = time.Duration(t.config.UI.LoadRetryBackoffMs) * time.Millisecond
+	}
+	return retryBackoff(backoff, retry)
+}
+
+// retryBackoff doubles base for each earlier retry, up to
+// maxLoadRetryBackoff
+func retryBackoff(base time.Duration, retry int) time.Duration {
+	delay := base
+	for i := 0; i < retry && delay < maxLoadRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxLoadRetryBackoff)
+}
+
+// isRetriableLoadError reports whether a failed load may succeed when tried
+// again: refused and unsupported lists will not
+func isRetriableLoadError(err error) bool {
+	switch {
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err), apierrors.IsNotFound(err):
+		return false
+	case errors.Is(err, errNoDynamicClient), errors.Is(err, errNotServedOverGRPC):
+		return false
+	}
+	return true
+}
+
+// retryLoad runs around the loads of the loadAsync functions as their
+// updates arrive. A failed load with retries left is tried again after a
+// backoff and its update held back, so the tab keeps loading rather than
+// showing partial data; retryLoad then reports true. A success after
+// retries shows a toast, and a load out of retries is recorded for the tab
+// to offer R.
+func (t *TUI) retryLoad(update *DataUpdate) bool {
+	// Updates of earlier loads are dropped rather than retried
+	if !t.isCurrentLoad(update) {
+		return false
+	}
+	rt := update.ResourceType
+	t.freshnessMu.Lock()
+	retries := t.loadAttempts[rt]
+	if update.Error == nil || !isRetriableLoadError(update.Error) {
+		delete(t.loadAttempts, rt)
+		if update.Error == nil {
+			delete(t.loadFailures, rt)
+		}
+		t.freshnessMu.Unlock()
+		if update.Error == nil && retries > 0 {
+			klog.Infof("Loaded %v after %d retries", rt, retries)
+			t.showLoadToast(fmt.Sprintf("✔ Loaded %s after %d %s", rt.DisplayName(), retries, pluralize(retries, "retry", "retries")))
+		}
+		return false
+	}
+
+	if retries >= t.loadRetries() {
+		delete(t.loadAttempts, rt)
+		if t.loadFailures == nil {
+			t.loadFailures = make(map[ResourceType]*loadFailure)
+		}
+		t.loadFailures[rt] = &loadFailure{err: update.Error, attempts: retries + 1}
+		t.freshnessMu.Unlock()
+		return false
+	}
+	if t.loadAttempts == nil {
+		t.loadAttempts = make(map[ResourceType]int)
+	}
+	t.loadAttempts[rt] = retries + 1
+	generation := t.loadGeneration
+	t.freshnessMu.Unlock()
+
+	delay := t.loadRetryDelay(retries)
+	klog.Warningf("Failed to load %v, retrying in %s (%d/%d): %v", rt, delay, retries+1, t.loadRetries(), update.Error)
+	time.AfterFunc(delay, func() {
+		// A full refresh since has loaded everything again
+		t.freshnessMu.Lock()
+		current := generation == t.loadGeneration
+		t.freshnessMu.Unlock()
+		if current {
+			t.loadAsync(dataLoad{resourceType: rt, namespace: update.Namespace, generation: update.Generation, background: update.Background})
+		}
+	})
+	return true
+}
+
+// loadFailureOf returns how the last load of a resource type failed after
+// its retries, or nil
+func (t *TUI) loadFailureOf(rt ResourceType) *loadFailure {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	return t.loadFailures[rt]
+}
+
+// retryFailedLoad reloads the current tab in the background with R, when
+// its load has run out of retries; the data of the last successful load is
+// kept if it fails again
+func (t *TUI) retryFailedLoad() {
+	rt := t.currentView
+	t.freshnessMu.Lock()
+	if t.loadFailures[rt] == nil || t.refreshing[rt] {
+		t.freshnessMu.Unlock()
+		return
+	}
+	delete(t.loadFailures, rt)
+	delete(t.lastErrors, rt)
+	if t.refreshing == nil {
+		t.refreshing = make(map[ResourceType]bool)
+	}
+	t.refreshing[rt] = true
+	load := t.newLoad(rt, true)
+	t.freshnessMu.Unlock()
+
+	go t.loadAsync(load)
+}
+
+// loadFailureMessage describes a load of the current tab that failed after
+// its retries, shown in place of its table
+func (t *TUI) loadFailureMessage(failure *loadFailure) string {
+	return fmt.Sprintf("✘ Failed to load %s after %d %s: %v (R: retry)",
+		t.currentView.DisplayName(), failure.attempts, pluralize(failure.attempts, "attempt", "attempts"), failure.err)
+}
+
+// showLoadToast shows a message in the status bar for loadToastDuration
+func (t *TUI) showLoadToast(message string) {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	t.loadToast = &loadToast{message: message, until: time.Now().Add(loadToastDuration)}
+}
+
+// loadToastText returns the toast to show in the status bar at now, or an
+// empty string once it has expired
+func (t *TUI) loadToastText(now time.Time) string {
+	t.freshnessMu.Lock()
+	defer t.freshnessMu.Unlock()
+	if t.loadToast == nil || !now.Before(t.loadToast.until) {
+		return ""
+	}
+	return t.loadToast.message
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestTUIRetriesFailedLoads tests that a failed load is retried with backoff
+// until it succeeds, and offers R once its retries are used up
+func TestTUIRetriesFailedLoads(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+	var lists, failures atomic.Int32
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists.Add(1)
+		if failures.Add(-1) >= 0 {
+			return true, nil, errors.New("connection reset by peer")
+		}
+		return false, nil, nil
+	})
+	cfg := config.DefaultConfig()
+	cfg.UI.LoadRetries = 3
+	cfg.UI.LoadRetryBackoffMs = 1
+	tui := &TUI{
+		clientset:      clientset,
+		config:         cfg,
+		namespace:      "default",
+		currentView:    ResourcePods,
+		dataChan:       make(chan *DataUpdate, 1),
+		loadingCounter: 1,
+		loading:        true,
+	}
+	// settle reads updates as handleDataUpdates does, until one is not held
+	// back for a retry
+	settle := func() *DataUpdate {
+		for {
+			select {
+			case update := <-tui.dataChan:
+				if !tui.retryLoad(update) {
+					return update
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for the load to settle")
+				return nil
+			}
+		}
+	}
+
+	// Two failures are retried, and the third attempt loads the pods
+	failures.Store(2)
+	tui.loadAsync(tui.newLoad(ResourcePods, false))
+	update := settle()
+	if update.Error != nil || lists.Load() != 3 {
+		t.Fatalf("Expected the third attempt to succeed, got %v after %d lists", update.Error, lists.Load())
+	}
+	tui.handleDataUpdate(update)
+	if len(tui.pods) != 1 || tui.loading {
+		t.Errorf("Expected the pods to be loaded, got %+v", tui.pods)
+	}
+	if toast := tui.loadToastText(time.Now()); toast != "✔ Loaded Pods after 2 retries" {
+		t.Errorf("Expected a toast about the retries, got %q", toast)
+	}
+	if toast := tui.loadToastText(time.Now().Add(loadToastDuration)); toast != "" {
+		t.Errorf("Expected the toast to expire, got %q", toast)
+	}
+
+	// Out of retries the failure is kept for R, with the pods of the last load
+	lists.Store(0)
+	failures.Store(10)
+	tui.freshnessMu.Lock()
+	tui.refreshing = map[ResourceType]bool{ResourcePods: true}
+	tui.freshnessMu.Unlock()
+	tui.loadAsync(tui.newLoad(ResourcePods, true))
+	update = settle()
+	if update.Error == nil || lists.Load() != 4 {
+		t.Fatalf("Expected the load to fail after 4 attempts, got %v after %d lists", update.Error, lists.Load())
+	}
+	tui.handleDataUpdate(update)
+	failure := tui.loadFailureOf(ResourcePods)
+	if failure == nil || len(tui.pods) != 1 {
+		t.Fatalf("Expected the failure to be recorded and the pods kept, got %+v and %+v", failure, tui.pods)
+	}
+	if want := "✘ Failed to load Pods after 4 attempts: connection reset by peer (R: retry)"; tui.loadFailureMessage(failure) != want {
+		t.Errorf("Expected %q, got %q", want, tui.loadFailureMessage(failure))
+	}
+	if status := tui.freshnessStatus(time.Now()); !strings.HasSuffix(status, "✘ load failed (R: retry)") {
+		t.Errorf("Expected the status bar to offer R, got %q", status)
+	}
+
+	failures.Store(0)
+	tui.retryFailedLoad()
+	update = settle()
+	if update.Error != nil || !update.Background || tui.loadFailureOf(ResourcePods) != nil {
+		t.Errorf("Expected R to reload the pods in the background, got %+v", update)
+	}
+
+	// A refused list is not retried
+	lists.Store(0)
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists.Add(1)
+		return true, nil, apierrors.NewForbidden(v1.Resource("pods"), "", errors.New("RBAC: access denied"))
+	})
+	tui.loadAsync(tui.newLoad(ResourcePods, true))
+	if update := settle(); !apierrors.IsForbidden(update.Error) || lists.Load() != 1 {
+		t.Errorf("Expected a single Forbidden list, got %v after %d lists", update.Error, lists.Load())
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for retry, want := range []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		if got := retryBackoff(500*time.Millisecond, retry); got != want {
+			t.Errorf("Expected %s before retry %d, got %s", want, retry, got)
+		}
+	}
+	if got := retryBackoff(20*time.Second, 0); got != maxLoadRetryBackoff {
+		t.Errorf("Expected the backoff to be capped, got %s", got)
+	}
+}
@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/timefmt"
+	"k8s-dashboard/pkg/util"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// pausedBadge follows the name of deployments whose rollouts are paused
+const pausedBadge = "[PAUSED]"
+
+// rolloutTimeout bounds pausing, resuming and restarting a workload
+const rolloutTimeout = 10 * time.Second
+
+// getDeploymentName returns a deployment's name for the Name column, with
+// pausedBadge when its rollouts are paused
+func getDeploymentName(dep appsv1.Deployment) string {
+	if dep.Spec.Paused {
+		return fmt.Sprintf("%s %s", dep.Name, pausedBadge)
+	}
+	return dep.Name
+}
+
+// pauseDetails returns the rollout line of a deployment's details: whether it
+// is paused, and for how long
+func pauseDetails(dep appsv1.Deployment, now time.Time) string {
+	since, paused := k8s.DeploymentPausedSince(&dep)
+	switch {
+	case !paused:
+		return "Rollout: active"
+	case since.IsZero():
+		return "Rollout: paused"
+	}
+	return fmt.Sprintf("Rollout: paused for %s", util.HumanDuration(now.Sub(since)))
+}
+
+// drawPausedBadge redraws the pausedBadge after a paused deployment's name in
+// yellow
+func (t *TUI) drawPausedBadge(dep appsv1.Deployment, y int, layout tableLayout, style tcell.Style) {
+	x, width, ok := layout.cell(0)
+	if !ok || !dep.Spec.Paused {
+		return
+	}
+	offset := len(dep.Name) + 1
+	if offset+len(pausedBadge) > width {
+		return
+	}
+	t.drawText(x+offset, y, width-offset, pausedBadge, style.Foreground(tcell.ColorYellow).Bold(true))
+}
+
+// togglePauseSelectedDeployment pauses the rollouts of the selected
+// deployment, or resumes them when it is paused, after a y/N confirmation
+func (t *TUI) togglePauseSelectedDeployment() {
+	dep, ok := t.getSelectedResource().(appsv1.Deployment)
+	if !ok {
+		return
+	}
+	action, prompt, done := "pause", "Pause", "Paused"
+	pause, kubectl := k8s.PauseDeployment, k8s.KubectlPause
+	if dep.Spec.Paused {
+		action, prompt, done = "resume", "Resume", "Resumed"
+		pause, kubectl = k8s.ResumeDeployment, k8s.KubectlResume
+	}
+	confirmed := false
+	if t.guard.IsProtected(dep.Namespace) {
+		confirmed = t.confirmProtectedActionIn(dep.Namespace, action, "deployment", dep.Name)
+	} else {
+		confirmMsg := fmt.Sprintf("%s deployment '%s'? (y/N)", prompt, dep.Name)
+		t.drawText(0, 1, 50, confirmMsg, tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		confirmed = ok && ev.Rune() == 'y'
+	}
+	if !confirmed {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rolloutTimeout)
+	err := pause(ctx, t.clientset, dep.Namespace, dep.Name)
+	cancel()
+	if err != nil {
+		klog.Errorf("Failed to %s deployment: %v", action, err)
+		errorMsg := fmt.Sprintf("Error: failed to %s deployment: %v", action, err)
+		t.drawText(0, 3, 80, errorMsg, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+		time.Sleep(2 * time.Second)
+		return
+	}
+
+	t.recordAction(fmt.Sprintf("%s deployment '%s'", done, dep.Name), kubectl(dep.Namespace, "deployment", dep.Name))
+	t.loadDeployments()
+}
+
+// selectedRestartTarget returns the singular kind of the selected workload,
+// the workload and the loader of its kind, and false when a rollout restart
+// does not apply to it
+func (t *TUI) selectedRestartTarget() (string, metav1.Object, func() error, bool) {
+	switch r := t.getSelectedResource().(type) {
+	case appsv1.Deployment:
+		return "deployment", &r, t.loadDeployments, true
+	case appsv1.StatefulSet:
+		return "statefulset", &r, t.loadStatefulSets, true
+	case appsv1.DaemonSet:
+		return "daemonset", &r, t.loadDaemonSets, true
+	}
+	return "", nil, nil, false
+}
+
+// restartSelectedWorkload restarts the pods of the selected deployment,
+// statefulset or daemonset after confirmation, like kubectl rollout restart.
+// The confirmation warns about the pods the restart leaves alone.
+func (t *TUI) restartSelectedWorkload() {
+	kind, obj, reload, ok := t.selectedRestartTarget()
+	if !ok {
+		return
+	}
+	namespace, name := obj.GetNamespace(), obj.GetName()
+	var warnings []string
+	if warning := k8s.RestartWarning(obj); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	confirmed := false
+	if t.guard.IsProtected(namespace) {
+		confirmed = t.confirmProtectedActionWarned(namespace, "restart", kind, name, warnings)
+	} else {
+		confirmMsg := fmt.Sprintf("Restart %s '%s'? (y/N)", kind, name)
+		t.drawText(0, 1, 50, confirmMsg, tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+		for i, warning := range warnings {
+			t.drawText(0, 2+i, 100, "⚠ "+warning, deletionWarningStyle)
+		}
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		confirmed = ok && ev.Rune() == 'y'
+	}
+	if !confirmed {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rolloutTimeout)
+	_, err := k8s.RestartWorkload(ctx, t.clientset, kind+"s", namespace, name, time.Now())
+	cancel()
+	if err != nil {
+		klog.Errorf("Failed to restart %s: %v", kind, err)
+		errorMsg := fmt.Sprintf("Error: failed to restart %s: %v", kind, err)
+		t.drawText(0, 3, 80, errorMsg, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+		time.Sleep(2 * time.Second)
+		return
+	}
+
+	t.recordAction(fmt.Sprintf("Restarted %s '%s'", kind, name), k8s.KubectlRestart(namespace, kind, name))
+	reload()
+}
+
+// showRolloutTimeline loads the condition transitions of the selected
+// deployment from its events and switches to the rollout view
+func (t *TUI) showRolloutTimeline() {
+	dep, ok := t.getSelectedResource().(appsv1.Deployment)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rolloutTimeout)
+	t.rolloutTimeline, t.rolloutTimelineErr = k8s.GetDeploymentTimeline(ctx, t.clientset, dep.Namespace, dep.Name)
+	cancel()
+	t.detailsScroll = 0
+	t.viewMode = ViewModeRollout
+}
+
+// timelineLine describes a condition transition, with its time both absolute
+// and relative to now
+func (t *TUI) timelineLine(entry k8s.TimelineEntry, now time.Time) string {
+	at := timefmt.Formatter{Format: timefmt.Both, Location: t.timestamps.Location}.Short(entry.Time.Time, now)
+	line := fmt.Sprintf("%s  %s=%s (reason: %s)", at, entry.Condition, entry.Status, entry.Reason)
+	if entry.Message != "" {
+		line += " " + entry.Message
+	}
+	return line
+}
+
+// timelineStatusStyle colors a condition transition by its status: green for
+// True, red for False and grey for Unknown
+func timelineStatusStyle(status v1.ConditionStatus) tcell.Style {
+	switch status {
+	case v1.ConditionTrue:
+		return tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	case v1.ConditionFalse:
+		return tcell.StyleDefault.Foreground(tcell.ColorRed)
+	}
+	return tcell.StyleDefault.Foreground(tcell.ColorGray)
+}
+
+// drawRolloutView draws the condition timeline of the selected deployment
+func (t *TUI) drawRolloutView(width, height int) {
+	dep, ok := t.getSelectedResource().(appsv1.Deployment)
+	if !ok {
+		t.drawText(0, 0, width, "No deployment selected", tcell.StyleDefault)
+		return
+	}
+
+	// Header
+	header := fmt.Sprintf(" 🕒 Rollout Timeline: %s ", dep.Name)
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	switch {
+	case t.rolloutTimelineErr != nil:
+		t.drawText(0, 2, width, fmt.Sprintf("Error: %v", t.rolloutTimelineErr), tcell.StyleDefault.Foreground(tcell.ColorRed))
+	case len(t.rolloutTimeline) == 0:
+		t.drawText(0, 2, width, "No condition transitions in the deployment's events; events expire after an hour by default.", tcell.StyleDefault)
+	}
+
+	now := time.Now()
+	t.detailsScroll = clampScroll(t.detailsScroll, len(t.rolloutTimeline), height-4)
+	y := 2
+	for i := t.detailsScroll; i < len(t.rolloutTimeline) && y < height-2; i++ {
+		entry := t.rolloutTimeline[i]
+		line := t.timelineLine(entry, now)
+		if len(line) > width {
+			line = line[:width-3] + "..."
+		}
+		t.drawText(0, y, width, line, timelineStatusStyle(entry.Status))
+		y++
+	}
+
+	// Footer
+	footer := " ESC Back │ ↑↓ Scroll "
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestTUIRolloutTimeline tests the condition timeline of deployment details
+func TestTUIRolloutTimeline(t *testing.T) {
+	deployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	start := time.Now().Add(-10 * time.Minute)
+	event := func(name, eventType, reason string, at time.Time) *v1.Event {
+		return &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+			InvolvedObject: v1.ObjectReference{Kind: "Deployment", Namespace: "default", Name: "web"},
+			Type:           eventType,
+			Reason:         reason,
+			LastTimestamp:  metav1.NewTime(at),
+		}
+	}
+
+	tui, screen := newTestTUI(t, 120, 30,
+		&deployment,
+		event("unavailable", v1.EventTypeWarning, "MinimumReplicasUnavailable", start.Add(time.Minute)),
+		event("scaled", v1.EventTypeNormal, "ScalingReplicaSet", start),
+	)
+	tui.currentView = ResourceDeployments
+	tui.viewMode = ViewModeDetails
+	tui.deployments = []appsv1.Deployment{deployment}
+
+	tui.showRolloutTimeline()
+	if tui.viewMode != ViewModeRollout || tui.rolloutTimelineErr != nil || len(tui.rolloutTimeline) != 2 {
+		t.Fatalf("Expected the rollout view with two entries, got %v, %+v, %v", tui.viewMode, tui.rolloutTimeline, tui.rolloutTimelineErr)
+	}
+	line := tui.timelineLine(tui.rolloutTimeline[0], start.Add(5*time.Minute))
+	if !strings.HasPrefix(line, start.Local().Format("2006-01-02 15:04")+" (5m)") || !strings.Contains(line, "Progressing=True (reason: ScalingReplicaSet)") {
+		t.Errorf("Expected the absolute and relative time and the transition, got %q", line)
+	}
+
+	tui.drawRolloutView(120, 30)
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	row := func(y int) (string, tcell.Style) {
+		var text []rune
+		for x := 0; x < width; x++ {
+			text = append(text, cells[y*width+x].Runes...)
+		}
+		return string(text), cells[y*width].Style
+	}
+	if text, style := row(2); !strings.Contains(text, "Progressing=True") || style != tcell.StyleDefault.Foreground(tcell.ColorGreen) {
+		t.Errorf("Expected a green Progressing=True line first, got %q", text)
+	}
+	if text, style := row(3); !strings.Contains(text, "Available=False") || style != tcell.StyleDefault.Foreground(tcell.ColorRed) {
+		t.Errorf("Expected a red Available=False line second, got %q", text)
+	}
+	if style := timelineStatusStyle(v1.ConditionUnknown); style != tcell.StyleDefault.Foreground(tcell.ColorGray) {
+		t.Error("Expected Unknown transitions to be grey")
+	}
+
+	// Scrolling skips the first entry, and v leaves the view
+	tui.detailsScroll = 1
+	tui.drawRolloutView(120, 30)
+	screen.Show()
+	cells, width, _ = screen.GetContents()
+	if text, _ := row(2); !strings.Contains(text, "Available=False") {
+		t.Errorf("Expected the second entry on top after scrolling, got %q", text)
+	}
+	tui.nextViewMode()
+	if tui.viewMode != ViewModeList {
+		t.Errorf("Expected v to return to the list, got %v", tui.viewMode)
+	}
+}
+
+// TestTUIDeploymentPause tests the paused badge, the pause duration in the
+// details, and toggling the pause of the selected deployment
+func TestTUIDeploymentPause(t *testing.T) {
+	paused := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Paused: true},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{
+			Type:               appsv1.DeploymentProgressing,
+			Status:             v1.ConditionUnknown,
+			Reason:             "DeploymentPaused",
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-30 * time.Hour)),
+		}}},
+	}
+	running := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	tui, screen := newTestTUI(t, 120, 30, &paused, &running)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceDeployments
+	tui.viewMode = ViewModeList
+	tui.deployments = []appsv1.Deployment{paused, running}
+	tui.selected = -1
+
+	if got := getDeploymentName(paused); got != "api [PAUSED]" {
+		t.Errorf("Expected the paused badge after the name, got %q", got)
+	}
+	if got := getDeploymentName(running); got != "web" {
+		t.Errorf("Expected no badge for a running deployment, got %q", got)
+	}
+	details := strings.Join(tui.getDeploymentDetails(paused), "\n")
+	if !strings.Contains(details, "Rollout: paused for 30h") {
+		t.Errorf("Expected the pause duration in the details, got:\n%s", details)
+	}
+	if details := strings.Join(tui.getDeploymentDetails(running), "\n"); !strings.Contains(details, "Rollout: active") {
+		t.Errorf("Expected an active rollout, got:\n%s", details)
+	}
+
+	tui.draw()
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	found := false
+	for y := 0; y*width < len(cells); y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				line.WriteRune(runes[0])
+			}
+		}
+		if strings.Contains(line.String(), "│ web ") && strings.Contains(line.String(), pausedBadge) {
+			t.Errorf("Expected no badge for a running deployment, got %q", line.String())
+		}
+		if !strings.Contains(line.String(), "│ api [PAUSED] ") {
+			continue
+		}
+		found = true
+		_, _, style, _ := screen.GetContent(2+len("api "), y)
+		if fg, _, _ := style.Decompose(); fg != tcell.ColorYellow {
+			t.Errorf("Expected a yellow badge, got %v", fg)
+		}
+	}
+	if !found {
+		t.Error("Expected a row for the paused deployment")
+	}
+
+	getPaused := func() bool {
+		got, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		return got.Spec.Paused
+	}
+
+	// P asks first, and anything but y leaves the deployment alone
+	tui.selected = 0
+	go screen.InjectKey(tcell.KeyRune, 'n', tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone))
+	if !getPaused() {
+		t.Fatal("Expected the deployment to stay paused without confirmation")
+	}
+
+	// P resumes the paused deployment and pauses it again
+	for i, want := range []bool{false, true} {
+		go screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone)
+		tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone))
+		got, err := clientset.AppsV1().Deployments("default").Get(context.TODO(), "api", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		if got.Spec.Paused != want || tui.deployments[0].Spec.Paused != want {
+			t.Errorf("Toggle %d: expected paused=%t, got %t", i, want, got.Spec.Paused)
+		}
+	}
+	if status := tui.actionStatusText(time.Now()); !strings.Contains(status, "Paused deployment 'api'") || !strings.Contains(status, "kubectl -n default rollout pause deployment/api") {
+		t.Errorf("Expected the pause in the status bar, got %q", status)
+	}
+}
@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultMaxScaleReplicas is the end of the scale slider without a
+	// configured ui.maxScaleReplicas
+	defaultMaxScaleReplicas = 50
+	// scaleSliderWidth is how many cells the track of the slider has
+	scaleSliderWidth = 25
+	// scaleTimeout bounds scaling a workload
+	scaleTimeout = 10 * time.Second
+)
+
+// maxScaleReplicas returns the end of the scale slider
+func (t *TUI) maxScaleReplicas() int32 {
+	if t.config == nil || t.config.UI.MaxScaleReplicas <= 0 {
+		return defaultMaxScaleReplicas
+	}
+	return int32(t.config.UI.MaxScaleReplicas)
+}
+
+// replicaSlider renders replicas on a track of width cells going from 0 to
+// maxReplicas, e.g. "[──────●──────] 5"
+func replicaSlider(replicas, maxReplicas int32, width int) string {
+	position := 0
+	if maxReplicas > 0 && width > 1 {
+		// Rounded to the nearest cell
+		position = int((int64(replicas)*int64(width-1)*2 + int64(maxReplicas)) / (int64(maxReplicas) * 2))
+	}
+	position = max(min(position, width-1), 0)
+	return fmt.Sprintf("[%s●%s] %d", strings.Repeat("─", position), strings.Repeat("─", width-1-position), replicas)
+}
+
+// scaleCostLines estimates what the pods of a template request at replicas,
+// e.g. "CPU: 500m × 5 = 2500m", leaving out resources no container requests
+func scaleCostLines(spec v1.PodSpec, replicas int32) []string {
+	var cpu, memory resource.Quantity
+	for _, container := range spec.Containers {
+		if request, ok := container.Resources.Requests[v1.ResourceCPU]; ok {
+			cpu.Add(request)
+		}
+		if request, ok := container.Resources.Requests[v1.ResourceMemory]; ok {
+			memory.Add(request)
+		}
+	}
+
+	var lines []string
+	if !cpu.IsZero() {
+		lines = append(lines, fmt.Sprintf("CPU: %dm × %d = %dm", cpu.MilliValue(), replicas, cpu.MilliValue()*int64(replicas)))
+	}
+	if !memory.IsZero() {
+		total := resource.NewQuantity(memory.Value()*int64(replicas), memory.Format)
+		lines = append(lines, fmt.Sprintf("Memory: %s × %d = %s", memory.String(), replicas, total.String()))
+	}
+	return lines
+}
+
+// scaleTarget is a workload the scale dialog scales: a deployment or a
+// statefulset
+type scaleTarget struct {
+	// kind is the singular kind, e.g. "deployment"
+	kind      string
+	namespace string
+	name      string
+	replicas  int32
+	template  v1.PodSpec
+	// reload loads the workloads of the kind again after scaling
+	reload func() error
+}
+
+// selectedScaleTarget returns the selected workload as a scaleTarget, and
+// false when it cannot be scaled
+func (t *TUI) selectedScaleTarget() (scaleTarget, bool) {
+	var target scaleTarget
+	var replicas *int32
+	switch r := t.getSelectedResource().(type) {
+	case appsv1.Deployment:
+		target = scaleTarget{kind: "deployment", namespace: r.Namespace, name: r.Name, template: r.Spec.Template.Spec, reload: t.loadDeployments}
+		replicas = r.Spec.Replicas
+	case appsv1.StatefulSet:
+		target = scaleTarget{kind: "statefulset", namespace: r.Namespace, name: r.Name, template: r.Spec.Template.Spec, reload: t.loadStatefulSets}
+		replicas = r.Spec.Replicas
+	default:
+		return target, false
+	}
+	target.replicas = 1
+	if replicas != nil {
+		target.replicas = *replicas
+	}
+	return target, true
+}
+
+// scaleDialogLines returns the lines of the scale dialog of a workload at the
+// replicas chosen on the slider
+func scaleDialogLines(target scaleTarget, replicas, maxReplicas int32) []string {
+	lines := []string{
+		fmt.Sprintf("Scale %s '%s' (currently %d replicas)", target.kind, target.name, target.replicas),
+		replicaSlider(replicas, maxReplicas, scaleSliderWidth),
+	}
+	lines = append(lines, scaleCostLines(target.template, replicas)...)
+	return append(lines, "←→ Replicas │ Enter Scale │ Esc Cancel")
+}
+
+// scaleDialog lets the user choose the replicas of a workload on a slider,
+// and reports whether Enter confirmed them
+func (t *TUI) scaleDialog(target scaleTarget) (int32, bool) {
+	current := target.replicas
+	// A workload scaled past the configured end keeps its replicas in reach
+	maxReplicas := max(t.maxScaleReplicas(), current)
+	replicas := current
+	style := tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+	for {
+		t.draw()
+		lines := scaleDialogLines(target, replicas, maxReplicas)
+		width := 0
+		for _, line := range lines {
+			width = max(width, len([]rune(line))+2)
+		}
+		for i, line := range lines {
+			t.drawText(0, 1+i, width, " "+line+strings.Repeat(" ", width), style)
+		}
+		t.screen.Show()
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyLeft:
+			replicas = max(replicas-1, 0)
+		case tcell.KeyRight:
+			replicas = min(replicas+1, maxReplicas)
+		case tcell.KeyEnter:
+			return replicas, true
+		case tcell.KeyEscape:
+			return 0, false
+		}
+	}
+}
+
+// scaleSelectedWorkload opens the scale dialog on the selected deployment or
+// statefulset and scales it to the replicas chosen, like kubectl scale
+func (t *TUI) scaleSelectedWorkload() {
+	target, ok := t.selectedScaleTarget()
+	if !ok {
+		return
+	}
+
+	replicas, ok := t.scaleDialog(target)
+	if !ok || replicas == target.replicas {
+		return
+	}
+	if !t.confirmProtectedActionIn(target.namespace, fmt.Sprintf("scaling to %d replicas", replicas), target.kind, target.name) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), scaleTimeout)
+	err := k8s.ScaleWorkload(ctx, t.clientset, target.kind+"s", target.namespace, target.name, replicas)
+	cancel()
+	if err != nil {
+		klog.Errorf("Failed to scale %s: %v", target.kind, err)
+		errorMsg := fmt.Sprintf("Error: failed to scale %s: %v", target.kind, err)
+		t.drawText(0, 3, 80, errorMsg, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+		time.Sleep(2 * time.Second)
+		return
+	}
+
+	t.recordAction(fmt.Sprintf("Scaled %s '%s' to %d replicas", target.kind, target.name, replicas), k8s.KubectlScale(target.namespace, target.kind, target.name, replicas))
+	target.reload()
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReplicaSlider(t *testing.T) {
+	tests := []struct {
+		replicas, max int32
+		width         int
+		want          string
+	}{
+		{5, 10, 13, "[──────●──────] 5"},
+		{0, 10, 13, "[●────────────] 0"},
+		{10, 10, 13, "[────────────●] 10"},
+		{1, 10, 13, "[─●───────────] 1"},
+		{0, 50, 25, "[●────────────────────────] 0"},
+		{25, 50, 25, "[────────────●────────────] 25"},
+		{48, 50, 25, "[───────────────────────●─] 48"},
+		{50, 50, 25, "[────────────────────────●] 50"},
+		// Past the end stays at the end
+		{80, 50, 5, "[────●] 80"},
+		{0, 0, 5, "[●────] 0"},
+	}
+	for _, tt := range tests {
+		if got := replicaSlider(tt.replicas, tt.max, tt.width); got != tt.want {
+			t.Errorf("replicaSlider(%d, %d, %d) = %q, want %q", tt.replicas, tt.max, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestScaleCostLines(t *testing.T) {
+	requests := func(cpu, memory string) v1.Container {
+		container := v1.Container{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{}}}
+		if cpu != "" {
+			container.Resources.Requests[v1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			container.Resources.Requests[v1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return container
+	}
+
+	spec := v1.PodSpec{Containers: []v1.Container{requests("250m", "64Mi"), requests("0.25", "64Mi")}}
+	want := []string{"CPU: 500m × 5 = 2500m", "Memory: 128Mi × 5 = 640Mi"}
+	if got := scaleCostLines(spec, 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Only what is requested is estimated
+	spec = v1.PodSpec{Containers: []v1.Container{requests("1", ""), {}}}
+	want = []string{"CPU: 1000m × 0 = 0m"}
+	if got := scaleCostLines(spec, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := scaleCostLines(v1.PodSpec{Containers: []v1.Container{{}}}, 3); len(got) != 0 {
+		t.Errorf("Expected no estimate without requests, got %q", got)
+	}
+}
+
+func TestTUIScaleDeployment(t *testing.T) {
+	replicas := int32(3)
+	deployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name:      "app",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}},
+			}}}},
+		},
+	}
+	tui, screen := newTestTUI(t, 100, 30, &deployment)
+	clientset := tui.clientset.(*fake.Clientset)
+	// The fake clientset would read and store the scale subresource as the
+	// deployment itself
+	gvr := appsv1.SchemeGroupVersion.WithResource("deployments")
+	clientset.PrependReactor("get", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		obj, err := clientset.Tracker().Get(gvr, action.GetNamespace(), action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		dep := obj.(*appsv1.Deployment)
+		return true, &autoscalingv1.Scale{ObjectMeta: dep.ObjectMeta, Spec: autoscalingv1.ScaleSpec{Replicas: *dep.Spec.Replicas}}, nil
+	})
+	clientset.PrependReactor("update", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scale := action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		obj, err := clientset.Tracker().Get(gvr, action.GetNamespace(), scale.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		dep := obj.(*appsv1.Deployment)
+		dep.Spec.Replicas = &scale.Spec.Replicas
+		return true, scale, clientset.Tracker().Update(gvr, dep, action.GetNamespace())
+	})
+	cfg := config.DefaultConfig()
+	cfg.UI.MaxScaleReplicas = 4
+	tui.config = cfg
+	tui.currentView = ResourceDeployments
+	tui.viewMode = ViewModeList
+	tui.deployments = []appsv1.Deployment{deployment}
+
+	lines := scaleDialogLines(scaleTarget{kind: "deployment", name: "web", replicas: 3, template: deployment.Spec.Template.Spec}, 5, 10)
+	want := []string{"Scale deployment 'web' (currently 3 replicas)", replicaSlider(5, 10, scaleSliderWidth), "CPU: 500m × 5 = 2500m", "←→ Replicas │ Enter Scale │ Esc Cancel"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected dialog lines %q, got %q", want, lines)
+	}
+
+	// Esc leaves the deployment alone
+	screen.InjectKey(tcell.KeyRight, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone))
+	if tui.lastAction != nil {
+		t.Fatalf("Expected Esc to cancel, got %+v", tui.lastAction)
+	}
+
+	// The slider stops at ui.maxScaleReplicas
+	for i := 0; i < 3; i++ {
+		screen.InjectKey(tcell.KeyRight, 0, tcell.ModNone)
+	}
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone))
+	scaled, err := clientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	if err != nil || *scaled.Spec.Replicas != 4 {
+		t.Fatalf("Expected the deployment scaled to 4 replicas, got %+v, %v", scaled, err)
+	}
+	if want := "kubectl -n default scale deployment/web --replicas=4"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// securityRiskPrefix starts the details lines of risky settings of a pod,
+// drawn in red
+const securityRiskPrefix = "  ✘ Risk:"
+
+// securityDetails returns the security section of a pod's details: the host
+// namespaces and paths it uses, the effective security context of each
+// container and the pod security admission labels of its namespace
+func (t *TUI) securityDetails(pod v1.Pod) []string {
+	var namespace *v1.Namespace
+	for i := range t.namespaces {
+		if t.namespaces[i].Name == pod.Namespace {
+			namespace = &t.namespaces[i]
+			break
+		}
+	}
+	security := k8s.GetPodSecurity(&pod, namespace)
+
+	lines := []string{"", "Security:"}
+	var host []string
+	if security.HostNetwork {
+		host = append(host, "network")
+	}
+	if security.HostPID {
+		host = append(host, "PID")
+	}
+	if security.HostIPC {
+		host = append(host, "IPC")
+	}
+	if len(host) > 0 {
+		lines = append(lines, "  Host namespaces: "+strings.Join(host, ", "))
+	}
+	for _, volume := range security.HostPathVolumes {
+		mode := "read-only"
+		if volume.Writable {
+			mode = "writable"
+		}
+		lines = append(lines, fmt.Sprintf("  hostPath %s: %s (%s)", volume.Name, volume.Path, mode))
+	}
+
+	for _, container := range security.Containers {
+		name := container.Name
+		if container.Init {
+			name += " (init)"
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", name, formatContainerSecurity(container)))
+	}
+
+	switch {
+	case namespace == nil:
+		lines = append(lines, "  Pod security admission: namespace not loaded")
+	case len(security.PodSecurityLabels) == 0:
+		lines = append(lines, "  Pod security admission: no labels")
+	default:
+		lines = append(lines, "  Pod security admission: "+k8s.FormatPodSecurityLabels(security.PodSecurityLabels))
+	}
+
+	for _, risk := range security.Risks {
+		lines = append(lines, fmt.Sprintf("%s %s", securityRiskPrefix, risk))
+	}
+	return lines
+}
+
+// formatContainerSecurity formats the effective security context of a
+// container, e.g. "user 1000, non-root, seccomp RuntimeDefault, caps -ALL"
+func formatContainerSecurity(container k8s.ContainerSecurity) string {
+	var parts []string
+	if container.Privileged {
+		parts = append(parts, "privileged")
+	}
+	if container.RunAsUser != nil {
+		parts = append(parts, "user "+strconv.FormatInt(*container.RunAsUser, 10))
+	} else {
+		parts = append(parts, "user from image")
+	}
+	if container.RunAsNonRoot != nil && *container.RunAsNonRoot {
+		parts = append(parts, "non-root")
+	}
+	if container.SeccompProfile != "" {
+		parts = append(parts, "seccomp "+container.SeccompProfile)
+	}
+	if container.AppArmorProfile != "" {
+		parts = append(parts, "AppArmor "+container.AppArmorProfile)
+	}
+	var caps []string
+	for _, capability := range container.CapabilitiesAdded {
+		caps = append(caps, "+"+capability)
+	}
+	for _, capability := range container.CapabilitiesDropped {
+		caps = append(caps, "-"+capability)
+	}
+	if len(caps) > 0 {
+		parts = append(parts, "caps "+strings.Join(caps, " "))
+	}
+	return strings.Join(parts, ", ")
+}
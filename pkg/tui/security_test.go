@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTUISecurityDetails(t *testing.T) {
+	nonRoot := true
+	user := int64(1000)
+	privileged := true
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "ops"},
+		Spec: v1.PodSpec{
+			HostPID:         true,
+			SecurityContext: &v1.PodSecurityContext{RunAsUser: &user, RunAsNonRoot: &nonRoot, SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault}},
+			Containers: []v1.Container{
+				{Name: "app", SecurityContext: &v1.SecurityContext{Capabilities: &v1.Capabilities{Drop: []v1.Capability{"ALL"}}}},
+				{Name: "agent", SecurityContext: &v1.SecurityContext{Privileged: &privileged}, VolumeMounts: []v1.VolumeMount{{Name: "root", MountPath: "/host"}}},
+			},
+			Volumes: []v1.Volume{{Name: "root", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/"}}}},
+		},
+	}
+	tui := &TUI{config: config.DefaultConfig()}
+
+	details := strings.Join(tui.securityDetails(pod), "\n")
+	if !strings.Contains(details, "Pod security admission: namespace not loaded") {
+		t.Errorf("Expected the namespace not to be loaded, got:\n%s", details)
+	}
+
+	tui.namespaces = []v1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "ops", Labels: map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}}}}
+	lines := tui.securityDetails(pod)
+	details = strings.Join(tui.getPodDetails(pod), "\n")
+	for _, want := range []string{
+		"Security:\n  Host namespaces: PID\n  hostPath root: / (writable)",
+		"  app: user 1000, non-root, seccomp RuntimeDefault, caps -ALL",
+		"  agent: privileged, user 1000, non-root, seccomp RuntimeDefault",
+		"  Pod security admission: enforce=baseline",
+		"  ✘ Risk: container agent is privileged\n  ✘ Risk: hostPath volume root (/) is writable by agent",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+	red := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	if detailsLineStyle(lines[len(lines)-1]) != red || detailsLineStyle("  app: user 1000") == red {
+		t.Errorf("Expected only risks to be drawn in red, last line %q", lines[len(lines)-1])
+	}
+}
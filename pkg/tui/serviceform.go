@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/validation"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// selectorPreviewNames is how many matching names the selector preview lists
+// before summarizing the rest
+const selectorPreviewNames = 5
+
+// serviceForm holds the state of the ClusterIP service creation form
+type serviceForm struct {
+	namespace string
+	fields    []*wizardField
+	field     int
+	errMsg    string
+}
+
+// newServiceForm creates a form for a service in namespace
+func newServiceForm(namespace string) *serviceForm {
+	return &serviceForm{namespace: namespace, fields: []*wizardField{
+		{label: "Name"},
+		{label: "Selector"},
+		{label: "Ports"},
+	}}
+}
+
+// handleKey applies a key press to the form
+func (f *serviceForm) handleKey(ev *tcell.EventKey) wizardAction {
+	if editFields(f.fields, &f.field, ev) {
+		return wizardContinue
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return wizardCancel
+	case tcell.KeyEnter:
+		return wizardDone
+	}
+	return wizardContinue
+}
+
+// build returns the service described by the form
+func (f *serviceForm) build() (*v1.Service, error) {
+	name := strings.TrimSpace(f.fields[0].value)
+	selector, err := serviceSelector(f.fields[1].value)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := parseServicePorts(f.fields[2].value)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: f.namespace},
+		Spec:       v1.ServiceSpec{Selector: selector, Ports: ports},
+	}
+	if err := validation.Service(service); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// serviceSelector parses the selector of a service, which unlike the
+// selectors of workloads only takes key=value requirements
+func serviceSelector(text string) (map[string]string, error) {
+	if _, err := k8s.ParseSelector(text); err != nil {
+		return nil, err
+	}
+	selector, err := labels.ConvertSelectorToLabelsMap(text)
+	if err != nil {
+		return nil, fmt.Errorf("services only select with key=value labels: %v", err)
+	}
+	return selector, nil
+}
+
+// parseServicePorts parses comma-separated ports, each a port or
+// port:targetPort with a number or a container port name as target
+func parseServicePorts(text string) ([]v1.ServicePort, error) {
+	var ports []v1.ServicePort
+	for _, item := range splitList(text) {
+		port, target, hasTarget := strings.Cut(item, ":")
+		number, err := strconv.Atoi(strings.TrimSpace(port))
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", item)
+		}
+		servicePort := v1.ServicePort{Name: fmt.Sprintf("port-%d", number), Port: int32(number), Protocol: v1.ProtocolTCP}
+		if hasTarget {
+			servicePort.TargetPort = intstr.Parse(strings.TrimSpace(target))
+		}
+		ports = append(ports, servicePort)
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("at least one port is required")
+	}
+	if len(ports) == 1 {
+		ports[0].Name = ""
+	}
+	return ports, nil
+}
+
+// selectorPreviewLines previews what a service selector selects among the
+// pods and deployments of the form's namespace, as it is typed
+func selectorPreviewLines(text string, pods []v1.Pod, deployments []appsv1.Deployment) []string {
+	selector, err := k8s.ParseSelector(text)
+	if err != nil {
+		return []string{"  ✘ " + err.Error()}
+	}
+	// A service's empty selector selects no pods: its endpoints are managed
+	// by hand, unlike the empty selectors of workloads and network policies
+	if selector.Empty() {
+		return []string{"  No selector: the service selects no pods, its endpoints are managed by hand"}
+	}
+	if _, err := serviceSelector(text); err != nil {
+		return []string{"  ✘ " + err.Error()}
+	}
+
+	preview := k8s.PreviewSelector(selector, pods, deployments)
+	lines := []string{fmt.Sprintf("  Pods (%d): %s", len(preview.Pods), previewNames(preview.Pods))}
+	return append(lines, fmt.Sprintf("  Deployments (%d): %s", len(preview.Deployments), previewNames(preview.Deployments)))
+}
+
+// previewNames lists the first selectorPreviewNames names
+func previewNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	if len(names) > selectorPreviewNames {
+		return fmt.Sprintf("%s, +%d more", strings.Join(names[:selectorPreviewNames], ", "), len(names)-selectorPreviewNames)
+	}
+	return strings.Join(names, ", ")
+}
+
+// lines renders the form with the live preview of its selector
+func (f *serviceForm) lines(pods []v1.Pod, deployments []appsv1.Deployment) []string {
+	lines := append([]string{fmt.Sprintf("Create Service in '%s'", f.namespace), ""}, fieldLines(f.fields, f.field)...)
+	lines = append(lines, "", "Selector matches:")
+	lines = append(lines, selectorPreviewLines(f.fields[1].value, pods, deployments)...)
+	lines = append(lines, "", "The selector is comma-separated key=value labels, e.g. app=web,tier=frontend.",
+		"Ports are comma-separated port or port:targetPort, e.g. 80:8080,443:https.")
+	if f.errMsg != "" {
+		lines = append(lines, "Error: "+f.errMsg)
+	}
+	return append(lines, "", "Tab/↑↓: Field | Enter: Create | Esc: Cancel")
+}
+
+// createServiceError describes why a service could not be created
+func createServiceError(name string, err error) string {
+	switch {
+	case apierrors.IsAlreadyExists(err):
+		return fmt.Sprintf("service %q already exists", name)
+	case apierrors.IsForbidden(err):
+		return fmt.Sprintf("not allowed to create services: %v", err)
+	}
+	return err.Error()
+}
+
+// createServiceDialog runs the service creation form, previewing the loaded
+// pods and deployments its selector matches
+func (t *TUI) createServiceDialog() {
+	f := newServiceForm(t.namespace)
+	for {
+		t.drawLines(f.lines(t.pods, t.deployments))
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch f.handleKey(ev) {
+		case wizardCancel:
+			return
+		case wizardDone:
+			service, err := f.build()
+			if err != nil {
+				f.errMsg = err.Error()
+				continue
+			}
+			if !t.confirmProtectedActionIn(t.namespace, "create", "service", service.Name) {
+				return
+			}
+			if _, err := k8s.CreateService(t.clientset, t.namespace, service); err != nil {
+				f.errMsg = createServiceError(service.Name, err)
+				continue
+			}
+			t.recordAction(fmt.Sprintf("Created service '%s'", service.Name), k8s.KubectlCreate(t.namespace, service))
+			t.loadServices()
+			return
+		}
+	}
+}
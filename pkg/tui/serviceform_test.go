@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestTUICreateService tests the live selector preview of the service form
+// and creating a service from it
+func TestTUICreateService(t *testing.T) {
+	var pods []v1.Pod
+	for _, name := range []string{"web-1", "web-2", "db-0"} {
+		app, _, _ := strings.Cut(name, "-")
+		pods = append(pods, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": app}}})
+	}
+	web := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	web.Spec.Template.Labels = map[string]string{"app": "web"}
+	tui, screen := newTestTUI(t, 120, 30)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceServices
+	tui.pods = pods
+	tui.deployments = []appsv1.Deployment{web}
+
+	for _, tt := range []struct{ selector, want string }{
+		{"app=web", "Pods (2): web-1, web-2\n  Deployments (1): web"},
+		{"app=", "Pods (0): none\n  Deployments (0): none"},
+		{"", "No selector: the service selects no pods"},
+		{"app in (web", `✘ invalid selector "app in (web"`},
+		{"app!=web", "✘ services only select with key=value labels"},
+	} {
+		if preview := strings.Join(selectorPreviewLines(tt.selector, pods, tui.deployments), "\n"); !strings.Contains(preview, tt.want) {
+			t.Errorf("%q: expected %q in the preview, got:\n%s", tt.selector, tt.want, preview)
+		}
+	}
+
+	go func() {
+		typeText(screen, "web")
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "app=web,tier=frontend")
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "80:8080, 443:https")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	}()
+	tui.createServiceDialog()
+
+	service, err := clientset.CoreV1().Services("default").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected service web to be created: %v", err)
+	}
+	if want := map[string]string{"app": "web", "tier": "frontend"}; !reflect.DeepEqual(service.Spec.Selector, want) {
+		t.Errorf("Expected selector %v, got %v", want, service.Spec.Selector)
+	}
+	if len(service.Spec.Ports) != 2 || service.Spec.Ports[0].TargetPort.IntValue() != 8080 || service.Spec.Ports[1].TargetPort.StrVal != "https" {
+		t.Errorf("Expected ports 80:8080 and 443:https, got %+v", service.Spec.Ports)
+	}
+	if len(tui.services) != 1 {
+		t.Errorf("Expected the service list to be reloaded, got %d services", len(tui.services))
+	}
+}
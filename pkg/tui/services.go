@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+)
+
+// serviceProbeTimeout bounds each TCP dial of a service probe
+const serviceProbeTimeout = 2 * time.Second
+
+// serviceProbe is the content of the service probe modal
+type serviceProbe struct {
+	service string
+	results []k8s.EndpointProbeResult
+	// message is shown instead of results while probing, or when there is
+	// nothing to probe
+	message string
+}
+
+// probeSelectedService dials the cluster IP and ports of the selected service
+// and opens the probe modal with the results
+func (t *TUI) probeSelectedService() {
+	service, ok := t.getSelectedResource().(v1.Service)
+	if !ok {
+		return
+	}
+
+	if t.config == nil || !t.config.Features.EnableServiceProbing {
+		t.serviceProbe = &serviceProbe{
+			service: service.Name,
+			message: "Service probing is disabled. Set features.enableServiceProbing when kgo runs inside the cluster.",
+		}
+		return
+	}
+
+	t.serviceProbe = &serviceProbe{service: service.Name, message: "Probing..."}
+	if t.screen != nil {
+		t.draw()
+		t.screen.Show()
+	}
+
+	probe := &serviceProbe{
+		service: service.Name,
+		results: k8s.ProbeServiceEndpoints(context.TODO(), service, serviceProbeTimeout),
+	}
+	if len(probe.results) == 0 {
+		probe.message = "Nothing to probe: the service has no cluster IP or no TCP ports."
+	}
+	t.serviceProbe = probe
+}
+
+// serviceProbeLine formats one probe result, e.g.
+// "✔ 10.96.0.10:53  reachable  1.2ms"
+func serviceProbeLine(result k8s.EndpointProbeResult) string {
+	endpoint := net.JoinHostPort(result.IP, strconv.Itoa(int(result.Port)))
+	if result.Reachable {
+		return fmt.Sprintf("✔ %-22s reachable    %s", endpoint, result.Latency.Round(100*time.Microsecond))
+	}
+	return fmt.Sprintf("✘ %-22s unreachable  %s", endpoint, result.Error)
+}
+
+// drawServiceProbe draws the probe results in a box over the current view,
+// with unreachable endpoints in red
+func (t *TUI) drawServiceProbe(width, height int) {
+	probe := t.serviceProbe
+
+	lines := []string{probe.message}
+	if probe.message == "" {
+		lines = lines[:0]
+		for _, result := range probe.results {
+			lines = append(lines, serviceProbeLine(result))
+		}
+	}
+
+	boxWidth := 76
+	if boxWidth > width-2 {
+		boxWidth = width - 2
+	}
+	boxHeight := len(lines) + 4
+	if boxHeight > height-2 {
+		boxHeight = height - 2
+	}
+	x := (width - boxWidth) / 2
+	y := (height - boxHeight) / 2
+
+	boxStyle := tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground)
+	for row := 0; row < boxHeight; row++ {
+		border := "│" + strings.Repeat(" ", boxWidth-2) + "│"
+		switch row {
+		case 0:
+			border = "┌" + strings.Repeat("─", boxWidth-2) + "┐"
+		case boxHeight - 1:
+			border = "└" + strings.Repeat("─", boxWidth-2) + "┘"
+		}
+		t.drawText(x, y+row, boxWidth, border, boxStyle)
+	}
+
+	title := fmt.Sprintf(" 🔌 Probe: %s ", probe.service)
+	t.drawText(x+2, y, boxWidth-4, title, boxStyle.Bold(true))
+
+	for i, line := range lines {
+		if i >= boxHeight-4 {
+			break
+		}
+		style := boxStyle
+		if probe.message == "" {
+			style = boxStyle.Foreground(tcell.ColorGreen)
+			if !probe.results[i].Reachable {
+				style = boxStyle.Foreground(tcell.ColorRed)
+			}
+		}
+		if len(line) > boxWidth-4 {
+			line = line[:boxWidth-7] + "..."
+		}
+		t.drawText(x+2, y+2+i, boxWidth-4, line, style)
+	}
+
+	t.drawText(x+2, y+boxHeight-1, boxWidth-4, " Press any key to close ", boxStyle)
+}
+
+const (
+	// externalNameLookupTimeout bounds the DNS lookup of an external name
+	externalNameLookupTimeout = 2 * time.Second
+	// externalNameLookupTTL is how long a lookup is shown before it is repeated
+	externalNameLookupTTL = 30 * time.Second
+	// dnsErrorPrefix starts the details line of a failed lookup, drawn in red
+	dnsErrorPrefix = "  ✘ DNS lookup failed"
+)
+
+// externalNameLookup is the DNS lookup of an ExternalName service's external
+// name shown in its details
+type externalNameLookup struct {
+	namespace    string
+	name         string
+	externalName string
+	ips          []string
+	err          error
+	latency      time.Duration
+	at           time.Time
+}
+
+// externalNameDetails returns the details lines of an ExternalName service:
+// the external name and what it resolves to, looked up again once the last
+// lookup is older than externalNameLookupTTL
+func (t *TUI) externalNameDetails(svc v1.Service) []string {
+	lines := []string{fmt.Sprintf("External Name: %s", svc.Spec.ExternalName)}
+	if t.config == nil || !t.config.Features.EnableDNSResolution {
+		return append(lines, "  DNS resolution is disabled. Set features.enableDNSResolution to resolve it.")
+	}
+
+	lookup := t.externalNameLookup
+	if lookup == nil || lookup.namespace != svc.Namespace || lookup.name != svc.Name ||
+		lookup.externalName != svc.Spec.ExternalName || time.Since(lookup.at) >= externalNameLookupTTL {
+		lookup = &externalNameLookup{namespace: svc.Namespace, name: svc.Name, externalName: svc.Spec.ExternalName}
+		ctx, cancel := context.WithTimeout(context.Background(), externalNameLookupTimeout)
+		start := time.Now()
+		lookup.ips, lookup.err = k8s.ResolveExternalNameService(ctx, svc)
+		lookup.latency = time.Since(start)
+		cancel()
+		lookup.at = time.Now()
+		t.externalNameLookup = lookup
+	}
+
+	latency := lookup.latency.Round(100 * time.Microsecond)
+	if lookup.err != nil {
+		return append(lines, fmt.Sprintf("%s after %s: %v", dnsErrorPrefix, latency, lookup.err))
+	}
+	return append(lines, fmt.Sprintf("  ✔ Resolves to %s in %s", strings.Join(lookup.ips, ", "), latency))
+}
+
+// detailsLineStyle returns the style of a line in the details view
+func detailsLineStyle(line string) tcell.Style {
+	if strings.HasPrefix(line, dnsErrorPrefix) || strings.HasPrefix(line, permissionsErrorPrefix) ||
+		strings.HasPrefix(line, imageErrorPrefix) || strings.HasPrefix(line, securityRiskPrefix) {
+		return tcell.StyleDefault.Foreground(tcell.ColorRed)
+	}
+	if style, ok := pvcLineStyle(line); ok {
+		return style
+	}
+	return tcell.StyleDefault
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTUIServiceProbe(t *testing.T) {
+	screen := newTestScreen(t, 100, 30)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	open := int32(listener.Addr().(*net.TCPAddr).Port)
+
+	notListening, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closed := int32(notListening.Addr().(*net.TCPAddr).Port)
+	notListening.Close()
+
+	service := v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "127.0.0.1",
+			Ports:     []v1.ServicePort{{Port: open}, {Port: closed}},
+		},
+	}
+	tui := &TUI{
+		screen:      screen,
+		config:      config.DefaultConfig(),
+		currentView: ResourceServices,
+		viewMode:    ViewModeDetails,
+		services:    []v1.Service{service},
+	}
+
+	// Probing is off by default because cluster IPs only route in-cluster
+	tui.probeSelectedService()
+	if tui.serviceProbe == nil || !strings.Contains(tui.serviceProbe.message, "disabled") || len(tui.serviceProbe.results) != 0 {
+		t.Fatalf("Expected a disabled message, got %+v", tui.serviceProbe)
+	}
+
+	tui.config.Features.EnableServiceProbing = true
+	tui.probeSelectedService()
+	results := tui.serviceProbe.results
+	if len(results) != 2 || !results[0].Reachable || results[1].Reachable {
+		t.Fatalf("Expected the open port reachable and the closed one not, got %+v", results)
+	}
+
+	tui.draw()
+	width, height := screen.Size()
+	boxY := (height - (len(results) + 4)) / 2
+	x := (width-76)/2 + 2
+	for i, want := range []tcell.Color{tcell.ColorGreen, tcell.ColorRed} {
+		mainc, _, style, _ := screen.GetContent(x, boxY+2+i)
+		if fg, _, _ := style.Decompose(); fg != want {
+			t.Errorf("Expected line %d (%c) in %v, got %v", i, mainc, want, fg)
+		}
+	}
+}
+
+// externalNameResolver answers lookups from a map of host names to addresses
+// and counts them
+type externalNameResolver struct {
+	hosts   map[string][]string
+	lookups int
+}
+
+func (r *externalNameResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.lookups++
+	ips, ok := r.hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+	return ips, nil
+}
+
+// TestTUIExternalNameService tests the DNS lookup in the details of
+// ExternalName services
+func TestTUIExternalNameService(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+
+	resolver := &externalNameResolver{hosts: map[string][]string{"db.example.com": {"10.1.2.3", "10.1.2.4"}}}
+	previous := k8s.ExternalNameResolver
+	k8s.ExternalNameResolver = resolver
+	defer func() { k8s.ExternalNameResolver = previous }()
+
+	service := v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "db.example.com"},
+	}
+	tui := &TUI{
+		screen:      screen,
+		config:      config.DefaultConfig(),
+		currentView: ResourceServices,
+		viewMode:    ViewModeDetails,
+		services:    []v1.Service{service},
+		theme:       DefaultTheme(),
+	}
+
+	details := strings.Join(tui.getServiceDetails(service), "\n")
+	if !strings.Contains(details, "External Name: db.example.com") || !strings.Contains(details, "DNS resolution is disabled") {
+		t.Errorf("Expected the external name without a lookup, got:\n%s", details)
+	}
+	if resolver.lookups != 0 {
+		t.Errorf("Expected no lookup while DNS resolution is disabled, got %d", resolver.lookups)
+	}
+
+	tui.config.Features.EnableDNSResolution = true
+	tui.getServiceDetails(service)
+	details = strings.Join(tui.getServiceDetails(service), "\n")
+	if !strings.Contains(details, "Resolves to 10.1.2.3, 10.1.2.4 in ") {
+		t.Errorf("Expected the resolved addresses, got:\n%s", details)
+	}
+	if resolver.lookups != 1 {
+		t.Errorf("Expected the lookup to be reused between draws, got %d lookups", resolver.lookups)
+	}
+	tui.externalNameLookup.at = time.Now().Add(-externalNameLookupTTL)
+	tui.getServiceDetails(service)
+	if resolver.lookups != 2 {
+		t.Errorf("Expected an expired lookup to be repeated, got %d lookups", resolver.lookups)
+	}
+
+	// Other services get no DNS lines
+	clusterIP := v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}, Spec: v1.ServiceSpec{Type: v1.ServiceTypeClusterIP}}
+	if details := strings.Join(tui.getServiceDetails(clusterIP), "\n"); strings.Contains(details, "External Name") {
+		t.Errorf("Expected no external name for a ClusterIP service, got:\n%s", details)
+	}
+
+	tui.services[0].Spec.ExternalName = "missing.example.com"
+	tui.draw()
+	screen.Show()
+	cells, width, _ := screen.GetContents()
+	for y := 0; y*width < len(cells); y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				line.WriteRune(runes[0])
+			}
+		}
+		if !strings.Contains(line.String(), "DNS lookup failed") {
+			continue
+		}
+		if !strings.Contains(line.String(), "no such host") {
+			t.Errorf("Expected the DNS error, got %q", line.String())
+		}
+		_, _, style, _ := screen.GetContent(2, y)
+		if fg, _, _ := style.Decompose(); fg != tcell.ColorRed {
+			t.Errorf("Expected the DNS error in red, got %v", fg)
+		}
+		return
+	}
+	t.Error("Expected a DNS error line in the details view")
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+)
+
+// Session is the state of the TUI restored when it starts again
+type Session struct {
+	Namespace   string       `json:"namespace"`
+	CurrentView ResourceType `json:"currentView"`
+	// SelectedResource is the name of the selected resource of CurrentView
+	SelectedResource string `json:"selectedResource,omitempty"`
+	Theme            int    `json:"theme"`
+	// Bookmarks are kept whether or not the session is restored
+	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
+}
+
+// DefaultSessionPath returns ~/.kgo/session.json
+func DefaultSessionPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kgo", "session.json"), nil
+}
+
+// LoadSession reads a session file. A missing file is no session: it returns
+// nil and no error.
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %v", path, err)
+	}
+	return &session, nil
+}
+
+// SaveSession writes a session file, creating its directory. It writes a
+// temporary file next to it and renames it into place, so that a crash never
+// leaves a partial session behind.
+func SaveSession(path string, session *Session) error {
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".session-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SetSession sets the file the session is saved to on every namespace, view
+// and theme change and on quitting; empty saves nothing. With restore the
+// saved session is restored when Run starts.
+func (t *TUI) SetSession(path string, restore bool) {
+	t.sessionPath = path
+	t.restoreSessionOnRun = restore && path != ""
+}
+
+// saveSession writes the current namespace, view, selection and theme to the
+// session file
+func (t *TUI) saveSession() {
+	if t.sessionPath == "" {
+		return
+	}
+	session := &Session{
+		Namespace:   t.namespace,
+		CurrentView: t.currentView,
+		Theme:       t.currentThemeIndex,
+		Bookmarks:   t.bookmarks,
+	}
+	if resource := t.getSelectedResource(); resource != nil {
+		session.SelectedResource = t.getResourceName(resource)
+	}
+	if err := SaveSession(t.sessionPath, session); err != nil {
+		klog.Errorf("Failed to save session to %s: %v", t.sessionPath, err)
+	}
+}
+
+// restoreSession restores the namespace, view and theme of the session file,
+// and reports whether there was one. The saved resource is selected by
+// selectRestoredResource once the resources are loaded.
+func (t *TUI) restoreSession() bool {
+	session, err := LoadSession(t.sessionPath)
+	if err != nil {
+		klog.Errorf("Failed to restore session: %v", err)
+		return false
+	}
+	if session == nil {
+		return false
+	}
+
+	if session.Namespace != "" {
+		t.namespace = session.Namespace
+	}
+	if session.CurrentView >= 0 && int(session.CurrentView) < len(loadingResourceTypes) {
+		t.currentView = session.CurrentView
+	}
+	if themes := availableThemes(); session.Theme >= 0 && session.Theme < len(themes) {
+		t.currentThemeIndex = session.Theme
+		t.theme = themes[session.Theme]
+	}
+	t.restoredSelection = session.SelectedResource
+	return true
+}
+
+// selectRestoredResource selects the resource saved in the session, or the
+// first one when it no longer exists
+func (t *TUI) selectRestoredResource() {
+	name := t.restoredSelection
+	if name == "" {
+		return
+	}
+	t.restoredSelection = ""
+	t.selected = 0
+	for i, resource := range t.getFilteredResources() {
+		if t.getResourceName(resource) == name {
+			t.selected = i
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSaveAndLoadSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kgo", "session.json")
+
+	if session, err := LoadSession(path); session != nil || err != nil {
+		t.Fatalf("Expected no session before saving, got %+v, %v", session, err)
+	}
+
+	want := Session{Namespace: "production", CurrentView: ResourceDeployments, SelectedResource: "nginx-deployment", Theme: 3,
+		Bookmarks: []Bookmark{{Kind: "pod", Namespace: "prod", Name: "api-1"}}}
+	if err := SaveSession(path, &want); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	// Saving again replaces the file
+	want.Theme = 4
+	if err := SaveSession(path, &want); err != nil {
+		t.Fatalf("Failed to save session again: %v", err)
+	}
+	got, err := LoadSession(path)
+	if err != nil || got == nil || !reflect.DeepEqual(*got, want) {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+
+	// Only the session file is left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 || entries[0].Name() != "session.json" {
+		t.Errorf("Expected only session.json, got %v, %v", entries, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSession(path); err == nil {
+		t.Error("Expected an invalid session file to fail")
+	}
+}
+
+func TestRestoreSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	pods := []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "api-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "web-1"}},
+	}
+
+	// The saved session names the selected resource
+	saved := &TUI{namespace: "production", currentView: ResourcePods, pods: pods, selected: 1, currentThemeIndex: 2}
+	saved.SetSession(path, false)
+	saved.saveSession()
+
+	tui := &TUI{namespace: "kube-system", currentView: ResourceDeployments, theme: DefaultTheme()}
+	tui.SetSession(path, true)
+	if !tui.restoreSessionOnRun || !tui.restoreSession() {
+		t.Fatal("Expected the session to be restored")
+	}
+	if tui.namespace != "production" || tui.currentView != ResourcePods || tui.currentThemeIndex != 2 || tui.theme != availableThemes()[2] {
+		t.Errorf("Expected production pods with the third theme, got %s, %v, %d", tui.namespace, tui.currentView, tui.currentThemeIndex)
+	}
+
+	// The resource is selected once loaded, and only once
+	tui.pods = pods
+	tui.selectRestoredResource()
+	if tui.selected != 1 {
+		t.Errorf("Expected web-1 to be selected, got %d", tui.selected)
+	}
+	tui.selected = 0
+	tui.selectRestoredResource()
+	if tui.selected != 0 {
+		t.Errorf("Expected the restored selection to be applied once, got %d", tui.selected)
+	}
+
+	// A resource deleted since selects the first one
+	tui.restoredSelection = "web-1"
+	tui.pods = pods[:1]
+	tui.selected = 5
+	tui.selectRestoredResource()
+	if tui.selected != 0 {
+		t.Errorf("Expected the first pod to be selected, got %d", tui.selected)
+	}
+
+	// Out of range views and themes are ignored
+	if err := SaveSession(path, &Session{Namespace: "dev", CurrentView: 42, Theme: -1}); err != nil {
+		t.Fatal(err)
+	}
+	tui = &TUI{namespace: "kube-system", currentView: ResourceServices, currentThemeIndex: 1}
+	tui.SetSession(path, true)
+	if !tui.restoreSession() || tui.namespace != "dev" || tui.currentView != ResourceServices || tui.currentThemeIndex != 1 {
+		t.Errorf("Expected only the namespace to be restored, got %s, %v, %d", tui.namespace, tui.currentView, tui.currentThemeIndex)
+	}
+
+	// Without a path nothing is saved or restored
+	tui = &TUI{}
+	tui.SetSession("", true)
+	tui.saveSession()
+	if tui.restoreSessionOnRun {
+		t.Error("Expected no restore without a session path")
+	}
+}
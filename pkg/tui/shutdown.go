@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/gdamore/tcell/v2"
+	"k8s.io/klog/v2"
+)
+
+// shutdownEvent asks the main loop to return, as q does
+type shutdownEvent struct {
+	tcell.EventTime
+}
+
+// watchShutdownSignals posts a shutdownEvent on SIGINT or SIGTERM, so that
+// Run returns and restores the terminal instead of the process dying with
+// it in raw mode. The signals are caught from when it returns until ctx is
+// done.
+func (t *TUI) watchShutdownSignals(ctx context.Context) {
+	signals, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		defer stop()
+		<-signals.Done()
+		if ctx.Err() != nil {
+			return
+		}
+		klog.Info("Received shutdown signal, quitting")
+		event := &shutdownEvent{}
+		event.SetEventNow()
+		t.screen.PostEvent(event)
+	}()
+}
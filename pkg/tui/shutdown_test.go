@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestTUIQuitsOnSIGTERM(t *testing.T) {
+	screen := newTestScreen(t, 80, 25)
+
+	tui := &TUI{screen: screen}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tui.watchShutdownSignals(ctx)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to find the test process: %v", err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send SIGTERM: %v", err)
+	}
+
+	events := make(chan tcell.Event, 1)
+	go func() { events <- screen.PollEvent() }()
+	select {
+	case event := <-events:
+		if _, ok := event.(*shutdownEvent); !ok {
+			t.Errorf("Expected a shutdown event, got %T", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected SIGTERM to post a shutdown event")
+	}
+}
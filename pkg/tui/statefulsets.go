@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// statefulSetPVCsTTL is how long the PVCs in the details of a statefulset are
+// shown before they are listed again, as their phase changes without the
+// statefulset changing
+const statefulSetPVCsTTL = 10 * time.Second
+
+// pvcLinePrefix starts the line of a PVC in the details of a statefulset,
+// drawn in the color of its phase
+const pvcLinePrefix = "    ● "
+
+// pvcPhaseColors are the colors of the PVC lines by phase
+var pvcPhaseColors = map[v1.PersistentVolumeClaimPhase]tcell.Color{
+	v1.ClaimBound:   tcell.ColorGreen,
+	v1.ClaimPending: tcell.ColorYellow,
+	v1.ClaimLost:    tcell.ColorRed,
+}
+
+// statefulSetPVCs are the PVCs of the statefulset shown in the details view,
+// by pod name
+type statefulSetPVCs struct {
+	namespace string
+	name      string
+	pvcs      map[string][]v1.PersistentVolumeClaim
+	err       error
+	at        time.Time
+}
+
+// loadStatefulSetsAsync loads statefulsets asynchronously
+func (t *TUI) loadStatefulSetsAsync(load dataLoad) {
+	update := load.update()
+	update.StatefulSets, update.Error = t.data().ListStatefulSets(load.namespace)
+	t.dataChan <- update
+}
+
+// loadStatefulSets fetches statefulsets from the current namespace
+func (t *TUI) loadStatefulSets() error {
+	statefulSets, err := t.data().ListStatefulSets(t.namespace)
+	if err != nil {
+		klog.Errorf("Failed to list statefulsets: %v", err)
+		return err
+	}
+	t.statefulSets = statefulSets
+	return nil
+}
+
+// statefulSetPVCsFor returns the PVCs of a statefulset, listed again once
+// statefulSetPVCsTTL has passed
+func (t *TUI) statefulSetPVCsFor(sts appsv1.StatefulSet) *statefulSetPVCs {
+	cached := t.statefulSetPVCs
+	if cached != nil && cached.namespace == sts.Namespace && cached.name == sts.Name && time.Since(cached.at) < statefulSetPVCsTTL {
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pvcs, err := k8s.GetStatefulSetPVCs(ctx, t.clientset, sts.Namespace, sts.Name)
+	t.statefulSetPVCs = &statefulSetPVCs{namespace: sts.Namespace, name: sts.Name, pvcs: pvcs, err: err, at: time.Now()}
+	return t.statefulSetPVCs
+}
+
+// getStatefulSetDetails returns formatted details for a statefulset: its
+// rolling update partition and the pods a restart leaves alone, its volume
+// claim templates, and the PVCs created from them for each pod
+func (t *TUI) getStatefulSetDetails(sts appsv1.StatefulSet) []string {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	details := []string{
+		fmt.Sprintf("Name: %s", sts.Name),
+		fmt.Sprintf("Namespace: %s", sts.Namespace),
+		fmt.Sprintf("Replicas: %d", replicas),
+		fmt.Sprintf("Ready: %d", sts.Status.ReadyReplicas),
+		fmt.Sprintf("Updated: %d", sts.Status.UpdatedReplicas),
+		fmt.Sprintf("Service: %s", sts.Spec.ServiceName),
+		fmt.Sprintf("Update strategy: %s", sts.Spec.UpdateStrategy.Type),
+		fmt.Sprintf("Partition: %d", k8s.StatefulSetPartition(&sts)),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(sts.CreationTimestamp)),
+	}
+	if warning := k8s.RestartWarning(&sts); warning != "" {
+		details = append(details, "", "⚠ Restart: "+warning)
+	}
+	details = append(details, "", "Volume Claim Templates:")
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return append(details, "  none")
+	}
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		details = append(details, "  - "+formatClaimTemplate(template))
+	}
+
+	details = append(details, "", "PVCs:")
+	if !t.hasClientset() {
+		return append(details, "  not served by the kgo gRPC API")
+	}
+	return append(details, formatStatefulSetPVCs(t.statefulSetPVCsFor(sts))...)
+}
+
+// formatClaimTemplate describes a volume claim template, e.g. "data:
+// storageClass standard, accessModes ReadWriteOnce, size 1Gi"
+func formatClaimTemplate(template v1.PersistentVolumeClaim) string {
+	storageClass := "<default>"
+	if template.Spec.StorageClassName != nil {
+		storageClass = *template.Spec.StorageClassName
+	}
+	accessModes := make([]string, 0, len(template.Spec.AccessModes))
+	for _, mode := range template.Spec.AccessModes {
+		accessModes = append(accessModes, string(mode))
+	}
+	size := "<none>"
+	if request, ok := template.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		size = request.String()
+	}
+	return fmt.Sprintf("%s: storageClass %s, accessModes %s, size %s", template.Name, storageClass, strings.Join(accessModes, ","), size)
+}
+
+// formatStatefulSetPVCs lists the PVCs of a statefulset under the pod they
+// belong to, in ordinal order
+func formatStatefulSetPVCs(pvcs *statefulSetPVCs) []string {
+	if pvcs.err != nil {
+		return []string{fmt.Sprintf("  Failed to list PVCs: %v", pvcs.err)}
+	}
+	if len(pvcs.pvcs) == 0 {
+		return []string{"  none"}
+	}
+
+	pods := make([]string, 0, len(pvcs.pvcs))
+	for pod := range pvcs.pvcs {
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return podOrdinal(pods[i]) < podOrdinal(pods[j])
+	})
+
+	var lines []string
+	for _, pod := range pods {
+		lines = append(lines, "  "+pod+":")
+		for _, pvc := range pvcs.pvcs[pod] {
+			lines = append(lines, fmt.Sprintf("%s%s: %s", pvcLinePrefix, pvc.Name, pvc.Status.Phase))
+		}
+	}
+	return lines
+}
+
+// podOrdinal returns the ordinal a statefulset pod name ends with
+func podOrdinal(pod string) int {
+	ordinal, _ := strconv.Atoi(pod[strings.LastIndex(pod, "-")+1:])
+	return ordinal
+}
+
+// pvcLineStyle returns the style of a PVC line in the details of a
+// statefulset, colored by its phase, and false for other lines
+func pvcLineStyle(line string) (tcell.Style, bool) {
+	if !strings.HasPrefix(line, pvcLinePrefix) {
+		return tcell.StyleDefault, false
+	}
+	phase := line[strings.LastIndex(line, ": ")+2:]
+	color, ok := pvcPhaseColors[v1.PersistentVolumeClaimPhase(phase)]
+	if !ok {
+		return tcell.StyleDefault, false
+	}
+	return tcell.StyleDefault.Foreground(color), true
+}
@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestStatefulSetDetails tests the volume claim templates and per-pod PVCs
+// in the details of a statefulset, with the PVC lines colored by phase
+func TestStatefulSetDetails(t *testing.T) {
+	standard := "standard"
+	claimTemplate := func(name string, storageClass *string, size string) v1.PersistentVolumeClaim {
+		return v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.PersistentVolumeClaimSpec{
+				StorageClassName: storageClass,
+				AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		}
+	}
+	replicas := int32(3)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: "web",
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+				claimTemplate("data", &standard, "1Gi"),
+				claimTemplate("logs", nil, "512Mi"),
+			},
+		},
+	}
+	pvc := func(name string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     v1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	clientset := fake.NewSimpleClientset(sts,
+		pvc("data-web-0", v1.ClaimBound),
+		pvc("logs-web-0", v1.ClaimBound),
+		pvc("data-web-1", v1.ClaimPending),
+		pvc("data-web-10", v1.ClaimLost),
+	)
+	tui := &TUI{
+		clientset:   clientset,
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourceStatefulSets,
+		dataChan:    make(chan *DataUpdate, 1),
+	}
+
+	tui.loadStatefulSetsAsync(tui.newLoad(ResourceStatefulSets, false))
+	tui.handleDataUpdate(<-tui.dataChan)
+	selected, ok := tui.getSelectedResource().(appsv1.StatefulSet)
+	if !ok || selected.Name != "web" {
+		t.Fatalf("Expected statefulset web to be selected, got %+v", tui.getSelectedResource())
+	}
+
+	details := strings.Join(tui.getResourceDetails(selected), "\n")
+	want := strings.Join([]string{
+		"Volume Claim Templates:",
+		"  - data: storageClass standard, accessModes ReadWriteOnce, size 1Gi",
+		"  - logs: storageClass <default>, accessModes ReadWriteOnce, size 512Mi",
+		"",
+		"PVCs:",
+		"  web-0:",
+		"    ● data-web-0: Bound",
+		"    ● logs-web-0: Bound",
+		"  web-1:",
+		"    ● data-web-1: Pending",
+		"  web-10:",
+		"    ● data-web-10: Lost",
+	}, "\n")
+	if !strings.Contains(details, want) {
+		t.Errorf("Expected:\n%s\nin the details, got:\n%s", want, details)
+	}
+
+	colors := map[string]tcell.Color{
+		"    ● data-web-0: Bound":   tcell.ColorGreen,
+		"    ● data-web-1: Pending": tcell.ColorYellow,
+		"    ● data-web-10: Lost":   tcell.ColorRed,
+	}
+	for line, color := range colors {
+		if fg, _, _ := detailsLineStyle(line).Decompose(); fg != color {
+			t.Errorf("Expected %q in %v, got %v", line, color, fg)
+		}
+	}
+	if style := detailsLineStyle("  web-0:"); style != tcell.StyleDefault {
+		t.Errorf("Expected pod lines in the default style, got %v", style)
+	}
+
+	// A failed list is shown rather than read as no PVCs, once the cached
+	// PVCs have expired
+	clientset.PrependReactor("list", "persistentvolumeclaims", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(v1.Resource("persistentvolumeclaims"), "", errors.New("rbac"))
+	})
+	if details := strings.Join(tui.getStatefulSetDetails(selected), "\n"); !strings.Contains(details, "web-10") {
+		t.Errorf("Expected the cached PVCs within the TTL, got:\n%s", details)
+	}
+	tui.statefulSetPVCs.at = time.Now().Add(-statefulSetPVCsTTL)
+	if details := strings.Join(tui.getStatefulSetDetails(selected), "\n"); !strings.Contains(details, "  Failed to list PVCs: ") {
+		t.Errorf("Expected the list error in the details, got:\n%s", details)
+	}
+}
+
+// partitionedStatefulSet returns a statefulset of replicas pods whose rolling
+// updates only replace those with an ordinal of partition or more
+func partitionedStatefulSet(replicas, partition int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition},
+			},
+		},
+	}
+}
+
+// TestStatefulSetPartitionDetails tests the partition and the restart
+// warning in the details of a partitioned statefulset
+func TestStatefulSetPartitionDetails(t *testing.T) {
+	tui := &TUI{config: config.DefaultConfig()}
+
+	details := strings.Join(tui.getStatefulSetDetails(*partitionedStatefulSet(3, 2)), "\n")
+	for _, want := range []string{
+		"Update strategy: RollingUpdate\nPartition: 2\n",
+		"⚠ Restart: rolling update partition is 2, so pods with an ordinal below 2 are not restarted",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+
+	details = strings.Join(tui.getStatefulSetDetails(*partitionedStatefulSet(3, 0)), "\n")
+	if !strings.Contains(details, "Partition: 0") || strings.Contains(details, "Restart:") {
+		t.Errorf("Expected partition 0 without a restart warning, got:\n%s", details)
+	}
+}
+
+// TestTUIScaleStatefulSet tests that = scales a statefulset through its scale
+// subresource
+func TestTUIScaleStatefulSet(t *testing.T) {
+	sts := partitionedStatefulSet(3, 0)
+	tui, screen := newTestTUI(t, 100, 30, sts)
+	clientset := tui.clientset.(*fake.Clientset)
+	// The fake clientset would read and store the scale subresource as the
+	// statefulset itself
+	var scaled *autoscalingv1.Scale
+	clientset.PrependReactor("get", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		return true, &autoscalingv1.Scale{ObjectMeta: sts.ObjectMeta, Spec: autoscalingv1.ScaleSpec{Replicas: *sts.Spec.Replicas}}, nil
+	})
+	clientset.PrependReactor("update", "statefulsets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		scaled = action.(k8stesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		return true, scaled, nil
+	})
+	tui.currentView = ResourceStatefulSets
+	tui.viewMode = ViewModeList
+	tui.statefulSets = []appsv1.StatefulSet{*sts}
+
+	screen.InjectKey(tcell.KeyLeft, 0, tcell.ModNone)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, '=', tcell.ModNone))
+	if scaled == nil || scaled.Spec.Replicas != 2 {
+		t.Fatalf("Expected the statefulset scaled to 2 replicas, got %+v", scaled)
+	}
+	if want := "kubectl -n default scale statefulset/db --replicas=2"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+}
+
+// TestTUIRestartStatefulSet tests that O restarts a statefulset once y
+// confirms it, warning about the pods below the partition
+func TestTUIRestartStatefulSet(t *testing.T) {
+	sts := partitionedStatefulSet(3, 2)
+	tui, screen := newTestTUI(t, 120, 30, sts)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceStatefulSets
+	tui.viewMode = ViewModeDetails
+	tui.statefulSets = []appsv1.StatefulSet{*sts}
+	restartedAt := func() string {
+		restarted, err := clientset.AppsV1().StatefulSets("default").Get(context.Background(), "db", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get the statefulset: %v", err)
+		}
+		return restarted.Spec.Template.Annotations[k8s.RestartedAtAnnotation]
+	}
+
+	// The confirmation shows the warning, and anything but y cancels
+	screen.InjectKey(tcell.KeyRune, 'n', tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModNone))
+	for y, want := range []string{"Restart statefulset 'db'? (y/N)", "⚠ rolling update partition is 2, so pods with an ordinal below 2 are not restarted"} {
+		if row, _ := screenRow(screen, 1+y); !strings.HasPrefix(row, want) {
+			t.Errorf("Expected %q in the confirmation, got %q", want, row)
+		}
+	}
+	if restartedAt() != "" {
+		t.Fatal("Expected n to leave the statefulset alone")
+	}
+
+	screen.InjectKey(tcell.KeyRune, 'y', tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'O', tcell.ModNone))
+	if restartedAt() == "" {
+		t.Fatal("Expected y to set the restartedAt annotation of the pod template")
+	}
+	if want := "kubectl -n default rollout restart statefulset/db"; tui.lastAction == nil || tui.lastAction.kubectl != want {
+		t.Errorf("Expected kubectl equivalent %q, got %+v", want, tui.lastAction)
+	}
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// tolerationDetails returns the scheduling section of a pod's details: on
+// how many of the loaded nodes its tolerations let it be scheduled, with a
+// warning when too few are left to reschedule it on during a drain
+func (t *TUI) tolerationDetails(pod v1.Pod) []string {
+	lines := []string{"", "Scheduling:"}
+	if len(t.nodes) == 0 {
+		return append(lines, "  Schedulable nodes unavailable: nodes are not loaded")
+	}
+	schedulable := k8s.SimulateScheduling(pod, t.nodes)
+	lines = append(lines, fmt.Sprintf("  Schedulable on %d/%d nodes", len(schedulable), len(t.nodes)))
+	if len(schedulable) < k8s.MinReschedulableNodes {
+		lines = append(lines, fmt.Sprintf("  ⚠ Fewer than %d nodes: draining its node may leave it pending (A: suggest tolerations)", k8s.MinReschedulableNodes))
+	}
+	return lines
+}
+
+// tolerationAdvisor is the state of the toleration advisor dialog of a pod
+type tolerationAdvisor struct {
+	pod         v1.Pod
+	nodes       []v1.Node
+	schedulable int
+	suggestions []k8s.TolerationSuggestion
+	copied      bool
+}
+
+func newTolerationAdvisor(pod v1.Pod, nodes []v1.Node) *tolerationAdvisor {
+	return &tolerationAdvisor{
+		pod:         pod,
+		nodes:       nodes,
+		schedulable: len(k8s.SimulateScheduling(pod, nodes)),
+		suggestions: k8s.SuggestTolerations(pod, nodes),
+	}
+}
+
+// snippet returns the suggested tolerations as YAML to add to the pod
+// template of the pod's workload
+func (a *tolerationAdvisor) snippet() string {
+	tolerations := make([]v1.Toleration, 0, len(a.suggestions))
+	for _, suggestion := range a.suggestions {
+		tolerations = append(tolerations, suggestion.Toleration)
+	}
+	out, err := yaml.Marshal(map[string][]v1.Toleration{"tolerations": tolerations})
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// lines returns the lines of the dialog
+func (a *tolerationAdvisor) lines() []string {
+	lines := []string{
+		fmt.Sprintf("Toleration advisor: pod %s", a.pod.Name),
+		"",
+		fmt.Sprintf("Schedulable on %d/%d nodes as far as taints go.", a.schedulable, len(a.nodes)),
+		"",
+	}
+
+	blocked := false
+	for _, node := range a.nodes {
+		taints := k8s.BlockingTaints(a.pod, node)
+		if len(taints) == 0 {
+			continue
+		}
+		if !blocked {
+			lines = append(lines, "Blocked by taints:")
+			blocked = true
+		}
+		formatted := make([]string, 0, len(taints))
+		for _, taint := range taints {
+			formatted = append(formatted, taint.ToString())
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", node.Name, strings.Join(formatted, ", ")))
+	}
+	if !blocked {
+		return append(lines, "No node taint keeps this pod off a node.", "", "Esc: Close")
+	}
+
+	if len(a.suggestions) == 0 {
+		lines = append(lines, "", "No single toleration opens a node; each one is blocked by several taints.")
+		return append(lines, "", "Esc: Close")
+	}
+	lines = append(lines, "", "Suggested tolerations:")
+	for _, suggestion := range a.suggestions {
+		lines = append(lines, fmt.Sprintf("  %s opens %s", formatToleration(suggestion.Toleration), strings.Join(suggestion.Nodes, ", ")))
+	}
+	lines = append(lines, "", "Add to the pod template of its workload:")
+	for _, line := range strings.Split(strings.TrimRight(a.snippet(), "\n"), "\n") {
+		lines = append(lines, "  "+line)
+	}
+	hint := "c: Copy YAML | Esc: Close"
+	if a.copied {
+		hint = "Copied | Esc: Close"
+	}
+	return append(lines, "", hint)
+}
+
+// formatToleration formats a toleration as taints are, e.g.
+// "gpu=true:NoSchedule", or "gpu:NoSchedule" for any value
+func formatToleration(toleration v1.Toleration) string {
+	key := toleration.Key
+	if toleration.Operator != v1.TolerationOpExists {
+		key += "=" + toleration.Value
+	}
+	return key + ":" + string(toleration.Effect)
+}
+
+// showTolerationAdvisor runs the toleration advisor of the pod shown in the
+// details: the taints keeping it off nodes and the tolerations to add for it
+// to be schedulable on them
+func (t *TUI) showTolerationAdvisor() {
+	pod, ok := t.getSelectedResource().(v1.Pod)
+	if !ok || len(t.nodes) == 0 {
+		return
+	}
+	advisor := newTolerationAdvisor(pod, t.nodes)
+	for {
+		t.drawLines(advisor.lines())
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch {
+		case ev.Key() == tcell.KeyEscape:
+			return
+		case ev.Key() == tcell.KeyRune && ev.Rune() == 'c' && len(advisor.suggestions) > 0:
+			t.screen.SetClipboard([]byte(advisor.snippet()))
+			advisor.copied = true
+		}
+	}
+}
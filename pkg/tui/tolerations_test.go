@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUITolerationAdvisor tests the schedulable nodes in pod details and
+// the tolerations the advisor suggests
+func TestTUITolerationAdvisor(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+
+	gpu := v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoSchedule}
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "gpu-1"}, Spec: v1.NodeSpec{Taints: []v1.Taint{gpu}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "gpu-2"}, Spec: v1.NodeSpec{Taints: []v1.Taint{gpu}}},
+	}
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	tui := &TUI{
+		screen:      screen,
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		viewMode:    ViewModeDetails,
+		pods:        []v1.Pod{pod},
+		theme:       DefaultTheme(),
+	}
+
+	details := strings.Join(tui.getPodDetails(pod), "\n")
+	if !strings.Contains(details, "Schedulable nodes unavailable: nodes are not loaded") {
+		t.Errorf("Expected the schedulable nodes to be unavailable, got:\n%s", details)
+	}
+	tui.nodes = nodes
+	details = strings.Join(tui.getPodDetails(pod), "\n")
+	if !strings.Contains(details, "Scheduling:\n  Schedulable on 1/3 nodes\n  ⚠ Fewer than 2 nodes") {
+		t.Errorf("Expected the pod to be schedulable on 1/3 nodes with a warning, got:\n%s", details)
+	}
+
+	advisor := newTolerationAdvisor(pod, nodes)
+	lines := strings.Join(advisor.lines(), "\n")
+	for _, want := range []string{
+		"  gpu-1: gpu=true:NoSchedule",
+		"  gpu=true:NoSchedule opens gpu-1, gpu-2",
+		"  tolerations:\n  - effect: NoSchedule\n    key: gpu\n    operator: Equal\n    value: \"true\"",
+		"c: Copy YAML | Esc: Close",
+	} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("Expected %q in the advisor, got:\n%s", want, lines)
+		}
+	}
+
+	// A pod tolerating the taint has nothing to add
+	pod.Spec.Tolerations = []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpExists}}
+	tui.pods[0] = pod
+	if details := strings.Join(tui.getPodDetails(pod), "\n"); !strings.Contains(details, "Schedulable on 3/3 nodes") || strings.Contains(details, "⚠ Fewer") {
+		t.Errorf("Expected the pod to be schedulable on every node, got:\n%s", details)
+	}
+	if lines := strings.Join(newTolerationAdvisor(pod, nodes).lines(), "\n"); !strings.Contains(lines, "No node taint keeps this pod off a node.") {
+		t.Errorf("Expected no blocking taints, got:\n%s", lines)
+	}
+
+	// A opens the advisor from the pod details, Esc closes it
+	go screen.InjectKey(tcell.KeyEscape, 0, tcell.ModNone)
+	tui.handleKey(tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone))
+	if tui.viewMode != ViewModeDetails {
+		t.Errorf("Expected to be back in the details, got %v", tui.viewMode)
+	}
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// topologyDetails returns the topology spread section of a pod's details:
+// each constraint with the skew the pods it selects currently have across
+// its domains, or nothing for a pod without constraints. Skew is computed
+// from the loaded pods and nodes, so it is only as fresh as the last refresh.
+func (t *TUI) topologyDetails(pod v1.Pod) []string {
+	if len(pod.Spec.TopologySpreadConstraints) == 0 {
+		return nil
+	}
+
+	lines := []string{"", "Topology Spread:"}
+	var pods []v1.Pod
+	for _, p := range t.pods {
+		if p.Namespace == pod.Namespace {
+			pods = append(pods, p)
+		}
+	}
+	for _, constraint := range pod.Spec.TopologySpreadConstraints {
+		lines = append(lines, fmt.Sprintf("  %s (maxSkew %d, %s)", constraint.TopologyKey, constraint.MaxSkew, constraint.WhenUnsatisfiable))
+		if len(t.nodes) == 0 {
+			lines = append(lines, "    Skew unavailable: nodes are not loaded")
+			continue
+		}
+		counts, skew, err := k8s.ComputeTopologySkew(pods, t.nodes, constraint)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("    Skew unavailable: %v", err))
+			continue
+		}
+		if len(counts) == 0 {
+			lines = append(lines, "    ⚠ No node has the topology key")
+			continue
+		}
+		status := "✔ satisfied"
+		if skew > int(constraint.MaxSkew) {
+			status = "✘ exceeds maxSkew"
+		}
+		lines = append(lines, fmt.Sprintf("    Skew %d: %s", skew, status))
+
+		domains := make([]string, 0, len(counts))
+		for domain := range counts {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+		for i, domain := range domains {
+			domains[i] = fmt.Sprintf("%s=%d", domain, counts[domain])
+		}
+		lines = append(lines, "    Pods per domain: "+strings.Join(domains, ", "))
+	}
+	return lines
+}
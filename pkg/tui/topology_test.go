@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/config"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestTUIPodTopologySpread tests the topology spread section of pod details
+func TestTUIPodTopologySpread(t *testing.T) {
+	var nodes []v1.Node
+	for _, zone := range []string{"a", "b", "c"} {
+		nodes = append(nodes, v1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-" + zone,
+			Labels: map[string]string{"topology.kubernetes.io/zone": zone},
+		}})
+	}
+	constraints := []v1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: v1.DoNotSchedule,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+		{
+			MaxSkew:           2,
+			TopologyKey:       "example.com/rack",
+			WhenUnsatisfiable: v1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+	}
+	var pods []v1.Pod
+	for i, node := range []string{"node-a", "node-a", "node-b"} {
+		pods = append(pods, v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("web-%d", i), Namespace: "default", Labels: map[string]string{"app": "web"}},
+			Spec:       v1.PodSpec{NodeName: node, TopologySpreadConstraints: constraints},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		})
+	}
+	// Pods of other namespaces are not selected by the constraints
+	other := pods[2]
+	other.Name, other.Namespace, other.Spec.NodeName = "web-0", "staging", "node-c"
+	tui := &TUI{
+		config:    config.DefaultConfig(),
+		namespace: "default",
+		pods:      append(pods, other),
+		nodes:     nodes,
+	}
+
+	details := strings.Join(tui.getPodDetails(pods[0]), "\n")
+	for _, want := range []string{
+		"Topology Spread:\n  topology.kubernetes.io/zone (maxSkew 1, DoNotSchedule)\n    Skew 2: ✘ exceeds maxSkew\n    Pods per domain: a=2, b=1, c=0",
+		"  example.com/rack (maxSkew 2, ScheduleAnyway)\n    ⚠ No node has the topology key",
+	} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected %q in the details, got:\n%s", want, details)
+		}
+	}
+
+	tui.pods = append(tui.pods, v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-3", Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec:       v1.PodSpec{NodeName: "node-c"},
+		Status:     v1.PodStatus{Phase: v1.PodRunning},
+	})
+	if details := strings.Join(tui.getPodDetails(pods[0]), "\n"); !strings.Contains(details, "Skew 1: ✔ satisfied") {
+		t.Errorf("Expected the constraint to be satisfied, got:\n%s", details)
+	}
+
+	tui.nodes = nil
+	if details := strings.Join(tui.getPodDetails(pods[0]), "\n"); !strings.Contains(details, "Skew unavailable: nodes are not loaded") {
+		t.Errorf("Expected the skew to be unavailable without nodes, got:\n%s", details)
+	}
+	plain := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"}}
+	if details := strings.Join(tui.getPodDetails(plain), "\n"); strings.Contains(details, "Topology Spread") {
+		t.Errorf("Expected no topology section for a pod without constraints, got:\n%s", details)
+	}
+}
@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// topPodsInterval is how often the top pods view reloads pod metrics
+const topPodsInterval = 30 * time.Second
+
+// topPodsCount is how many pods the top pods view shows
+const topPodsCount = 20
+
+// errNoMetricsClient is the error of a top pods load without a metrics client
+var errNoMetricsClient = errors.New("pod metrics need a metrics client")
+
+// topPodsView is the state of the top pods view, written by the goroutine
+// reloading it and read when drawing, so guarded by mu
+type topPodsView struct {
+	mu       sync.Mutex
+	sortBy   string
+	pods     []k8s.PodMetricSummary
+	err      error
+	loadedAt time.Time
+	// cancel stops the goroutine reloading the view; nil while it is closed
+	cancel context.CancelFunc
+}
+
+// SetMetricsClientset sets the client pod metrics are read through. Without
+// one the top pods view only shows an error.
+func (t *TUI) SetMetricsClientset(metricsClientset metricsclientset.Interface) {
+	t.metricsClientset = metricsClientset
+}
+
+// openTopPods shows the pods of the current namespace using the most CPU or
+// memory, reloaded every topPodsInterval until the view is closed
+func (t *TUI) openTopPods(sortBy string) {
+	t.closeTopPods()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.topPods.mu.Lock()
+	t.topPods.sortBy = sortBy
+	t.topPods.pods = nil
+	t.topPods.err = nil
+	t.topPods.loadedAt = time.Time{}
+	t.topPods.cancel = cancel
+	t.topPods.mu.Unlock()
+
+	t.viewMode = ViewModeTopPods
+	go t.watchTopPods(ctx, t.namespace, sortBy)
+}
+
+// closeTopPods stops reloading the top pods view and returns to the list
+func (t *TUI) closeTopPods() {
+	t.topPods.mu.Lock()
+	if t.topPods.cancel != nil {
+		t.topPods.cancel()
+		t.topPods.cancel = nil
+	}
+	t.topPods.mu.Unlock()
+
+	if t.viewMode == ViewModeTopPods {
+		t.viewMode = ViewModeList
+	}
+}
+
+// watchTopPods loads the top pods immediately and then every topPodsInterval
+// until ctx is done
+func (t *TUI) watchTopPods(ctx context.Context, namespace, sortBy string) {
+	ticker := time.NewTicker(topPodsInterval)
+	defer ticker.Stop()
+
+	for {
+		top, err := []k8s.PodMetricSummary(nil), errNoMetricsClient
+		if t.metricsClientset != nil {
+			top, err = k8s.GetTopPods(ctx, t.metricsClientset, t.clientset, namespace, sortBy, topPodsCount)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		t.topPods.mu.Lock()
+		t.topPods.pods = top
+		t.topPods.err = err
+		t.topPods.loadedAt = time.Now()
+		t.topPods.mu.Unlock()
+		t.requestRedraw()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// topPodsLines returns the header and rows of the top pods table
+func (t *TUI) topPodsLines() (string, []string, string) {
+	t.topPods.mu.Lock()
+	defer t.topPods.mu.Unlock()
+
+	cpuHeader, memoryHeader := "CPU(m) ▼", "Memory(Mi)"
+	if t.topPods.sortBy == k8s.TopPodsByMemory {
+		cpuHeader, memoryHeader = "CPU(m)", "Memory(Mi) ▼"
+	}
+	header := fmt.Sprintf("%-5s %-50s %10s %13s %6s %6s", "Rank", "Name", cpuHeader, memoryHeader, "CPU%", "Mem%")
+
+	switch {
+	case t.topPods.loadedAt.IsZero():
+		return header, nil, "Loading pod metrics..."
+	case t.topPods.err != nil:
+		return header, nil, fmt.Sprintf("Metrics unavailable: %v", t.topPods.err)
+	case len(t.topPods.pods) == 0:
+		return header, nil, "No pod metrics in this namespace"
+	}
+
+	rows := make([]string, 0, len(t.topPods.pods))
+	for i, pod := range t.topPods.pods {
+		name := pod.Name
+		if len(name) > 50 {
+			name = name[:47] + "..."
+		}
+		rows = append(rows, fmt.Sprintf("%-5d %-50s %10d %13d %6s %6s",
+			i+1, name, pod.CPUMilli, pod.MemoryBytes/(1<<20), formatPercent(pod.CPUPercent), formatPercent(pod.MemoryPercent)))
+	}
+	return header, rows, fmt.Sprintf("Updated %s", t.topPods.loadedAt.Format("15:04:05"))
+}
+
+// formatPercent formats a usage percentage, or "-" when it is unknown
+func formatPercent(percent float64) string {
+	if percent < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", percent)
+}
+
+// drawTopPodsView draws the pods using the most CPU or memory
+func (t *TUI) drawTopPodsView(width, height int) {
+	sortName := "CPU"
+	t.topPods.mu.Lock()
+	if t.topPods.sortBy == k8s.TopPodsByMemory {
+		sortName = "Memory"
+	}
+	t.topPods.mu.Unlock()
+
+	// Header
+	header := fmt.Sprintf(" 📈 Top Pods by %s: %s ", sortName, t.namespace)
+	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+
+	columns, rows, status := t.topPodsLines()
+	t.drawText(0, 2, width, columns, tcell.StyleDefault.Foreground(t.theme.header).Bold(true))
+	y := 3
+	for _, row := range rows {
+		if y >= height-3 {
+			break
+		}
+		t.drawText(0, y, width, row, tcell.StyleDefault.Foreground(t.theme.foreground))
+		y++
+	}
+	t.drawText(0, y+1, width, status, tcell.StyleDefault.Foreground(tcell.ColorGray))
+
+	// Footer
+	footer := fmt.Sprintf(" ESC Back │ C Sort by CPU │ M Sort by memory │ Refreshes every %v ", topPodsInterval)
+	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+}
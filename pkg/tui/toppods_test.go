@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// TestTUITopPods tests the top pods view sorted by CPU and by memory, and
+// that closing it stops its reloads
+func TestTUITopPods(t *testing.T) {
+	usage := func(name, cpu, memory string) metricsv1beta1.PodMetrics {
+		return metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			}}},
+		}
+	}
+	metricsClient := &metricsfake.Clientset{}
+	metricsClient.AddReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+			usage("api", "250m", "512Mi"),
+			usage("web", "750m", "128Mi"),
+		}}, nil
+	})
+	limited := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app", Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("1"),
+			v1.ResourceMemory: resource.MustParse("512Mi"),
+		}}}}},
+	}
+
+	tui, screen := newTestTUI(t, 120, 30, &limited)
+	tui.currentView = ResourcePods
+	tui.SetMetricsClientset(metricsClient)
+
+	// showTopPods opens the view and draws it once the metrics are loaded
+	showTopPods := func(sortBy string) string {
+		tui.openTopPods(sortBy)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			tui.topPods.mu.Lock()
+			loaded := !tui.topPods.loadedAt.IsZero()
+			tui.topPods.mu.Unlock()
+			if loaded {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for pod metrics")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		screen.Clear()
+		tui.drawTopPodsView(120, 30)
+		screen.Show()
+		return screenText(screen)
+	}
+
+	text := showTopPods(k8s.TopPodsByCPU)
+	if tui.viewMode != ViewModeTopPods || tui.getViewModeName() != "Top Pods" {
+		t.Errorf("Expected the top pods view, got %q", tui.getViewModeName())
+	}
+	if !strings.Contains(text, "Top Pods by CPU: default") {
+		t.Errorf("Expected the CPU header, got:\n%s", text)
+	}
+	web, api := strings.Index(text, "web"), strings.Index(text, "api")
+	if web < 0 || api < 0 || web > api {
+		t.Errorf("Expected web above api by CPU, got:\n%s", text)
+	}
+	// web uses 750m of its 1 CPU limit and 128Mi of 512Mi; api has neither
+	// a limit nor a known node
+	if !strings.Contains(text, "75%") || !strings.Contains(text, "25%") {
+		t.Errorf("Expected web at 75%% CPU and 25%% memory, got:\n%s", text)
+	}
+
+	text = showTopPods(k8s.TopPodsByMemory)
+	if !strings.Contains(text, "Top Pods by Memory") {
+		t.Errorf("Expected the memory header, got:\n%s", text)
+	}
+	web, api = strings.Index(text, "web"), strings.Index(text, "api")
+	if web < 0 || api < 0 || api > web {
+		t.Errorf("Expected api above web by memory, got:\n%s", text)
+	}
+
+	tui.nextViewMode()
+	if tui.viewMode != ViewModeList || tui.topPods.cancel != nil {
+		t.Error("Expected leaving the view to stop its reloads")
+	}
+
+	tui.SetMetricsClientset(nil)
+	if text := showTopPods(k8s.TopPodsByCPU); !strings.Contains(text, "Metrics unavailable") {
+		t.Errorf("Expected an error without a metrics client, got:\n%s", text)
+	}
+	tui.closeTopPods()
+}
@@ -1,19 +1,32 @@
 package tui
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"k8s-dashboard/pkg/alerts"
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/crypto"
 	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/timefmt"
+	"k8s-dashboard/pkg/util"
 
 	"github.com/gdamore/tcell/v2"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 // DataUpdate represents an update to resource data
@@ -22,9 +35,29 @@ type DataUpdate struct {
 	Pods         []v1.Pod
 	Deployments  []appsv1.Deployment
 	Services     []v1.Service
-	ConfigMaps   []v1.ConfigMap
+	ConfigMaps   []k8s.ConfigMapSummary
 	Namespaces   []v1.Namespace
+	Nodes        []v1.Node
+	CRDs         []k8s.CRD
+	StatefulSets []appsv1.StatefulSet
+	PVCs         []v1.PersistentVolumeClaim
+	DaemonSets   []appsv1.DaemonSet
 	Error        error
+	// NodeUsage is the usage of the Nodes by name, nil when it was not loaded
+	NodeUsage map[string]k8s.NodeMetricSummary
+	// ResourceVersion is that of the list, for the next load to start from
+	ResourceVersion string
+	// Namespace and Generation are those of the load the update comes from,
+	// with a zero Generation for watchers not tied to a load
+	Namespace  string
+	Generation int
+
+	// Background updates come from watchers and tab switches rather than
+	// refreshData, so they do not count towards the loading progress, and a
+	// failed one keeps the data from the last successful load
+	Background bool
+	// Events are notifications raised by the update's source
+	Events []alerts.Event
 }
 
 // ResourceType represents different types of Kubernetes resources
@@ -36,6 +69,11 @@ const (
 	ResourceServices
 	ResourceConfigMaps
 	ResourceNamespaces
+	ResourceNodes
+	ResourceCRDs
+	ResourceStatefulSets
+	ResourcePVCs
+	ResourceDaemonSets
 )
 
 // ViewMode represents different view modes
@@ -47,6 +85,15 @@ const (
 	ViewModeYAML
 	ViewModeLogs
 	ViewModeRelationships
+	ViewModeDiff
+	ViewModeTopPods
+	ViewModeDashboard
+	ViewModeProbeOverride
+	ViewModeRollout
+	ViewModeClusterInfo
+	ViewModeFileBrowser
+	ViewModeEditor
+	ViewModeBookmarks
 )
 
 // LayoutMode represents different layout modes
@@ -186,14 +233,24 @@ func (rt ResourceType) DisplayName() string {
 		return "ConfigMaps"
 	case ResourceNamespaces:
 		return "Namespaces"
+	case ResourceNodes:
+		return "Nodes"
+	case ResourceCRDs:
+		return "CRDs"
+	case ResourceStatefulSets:
+		return "StatefulSets"
+	case ResourcePVCs:
+		return "PVCs"
+	case ResourceDaemonSets:
+		return "DaemonSets"
 	default:
 		return "Unknown"
 	}
 }
 
-// nextTheme cycles to the next available theme
-func (t *TUI) nextTheme() {
-	themes := []Theme{
+// availableThemes returns the themes nextTheme cycles through, in order
+func availableThemes() []Theme {
+	return []Theme{
 		DefaultTheme(),
 		DarkTheme(),
 		LightTheme(),
@@ -204,9 +261,14 @@ func (t *TUI) nextTheme() {
 		MonokaiTheme(),
 		CyberpunkTheme(),
 	}
+}
 
+// nextTheme cycles to the next available theme
+func (t *TUI) nextTheme() {
+	themes := availableThemes()
 	t.currentThemeIndex = (t.currentThemeIndex + 1) % len(themes)
 	t.theme = themes[t.currentThemeIndex]
+	t.saveSession()
 
 	// Force immediate redraw with clear
 	t.screen.Clear()
@@ -217,17 +279,36 @@ func (t *TUI) nextTheme() {
 
 // TUI represents the terminal user interface
 type TUI struct {
-	screen    tcell.Screen
+	screen tcell.Screen
+	// source loads resources; clientset is that of a ClusterSource, and nil
+	// for other sources
+	source    DataSource
 	clientset kubernetes.Interface
-	pods      []v1.Pod
-	selected  int
-	namespace string
-	filter    string
-	showHelp  bool
-	loading   bool
+	config    *config.Config
+	guard     *k8s.NamespaceGuard
+	// imagePolicy rejects pods and deployments with images from registries
+	// outside features.allowedRegistries
+	imagePolicy *k8s.ImagePolicy
+	pods        []v1.Pod
+	selected    int
+	namespace   string
+	filter      string
+	showHelp    bool
+	loading     bool
+
+	// dynamicClient lists CRDs; nil leaves the CRDs tab empty
+	dynamicClient dynamic.Interface
+	// metricsClientset reads pod metrics; nil leaves the top pods view empty
+	metricsClientset metricsclientset.Interface
 
 	// Async loading
-	loadingCounter int
+	loadingCounter  int
+	loadedResources map[ResourceType]bool
+	spinner         Spinner
+
+	// search is the '/' prompt while it is open, nil otherwise; the filter
+	// is applied as it is typed
+	search *searchPrompt
 
 	// Advanced filtering
 	filterMode    bool
@@ -249,25 +330,195 @@ type TUI struct {
 	viewMode    ViewMode
 
 	// Resource data
-	deployments []appsv1.Deployment
-	services    []v1.Service
-	configMaps  []v1.ConfigMap
-	namespaces  []v1.Namespace
+	deployments  []appsv1.Deployment
+	services     []v1.Service
+	configMaps   []k8s.ConfigMapSummary
+	namespaces   []v1.Namespace
+	nodes        []v1.Node
+	crds         []k8s.CRD
+	statefulSets []appsv1.StatefulSet
+	pvcs         []v1.PersistentVolumeClaim
+	daemonSets   []appsv1.DaemonSet
+	// nodeUsage is the usage of the nodes by name, empty without metrics
+	nodeUsage map[string]k8s.NodeMetricSummary
 
 	// Scrolling
 	detailsScroll       int
 	logsScroll          int
 	relationshipsScroll int
+	// listScroll is the first row of the list table on screen, and
+	// columnScroll how many columns it is scrolled sideways
+	listScroll   int
+	columnScroll int
+	// pendingG is set by g, which goes to the top when pressed twice
+	pendingG bool
 
 	// Relationships
 	relationships []Relationship
 
+	// Diff shown in ViewModeDiff, e.g. the pod template diff of the selected
+	// deployment or the changes of the editor before saving them
+	diffView *diffViewer
+
+	// Condition transitions of the deployment shown in ViewModeRollout
+	rolloutTimeline    []k8s.TimelineEntry
+	rolloutTimelineErr error
+
+	// Endpoint probe of the selected service, shown in a modal when set
+	serviceProbe *serviceProbe
+
+	// Cluster the clientset was configured for, and what the API server
+	// said about it in ViewModeClusterInfo
+	clientInfo  *k8s.ClientInfo
+	clusterInfo *clusterInfoView
+
+	// Values of the configmap shown in the details or YAML view
+	configMapValues *configMapValues
+	// configMapKeys decrypt the values of encrypted configmaps before they
+	// are shown; nil shows them as stored
+	configMapKeys *crypto.Keys
+	// PVCs of the statefulset shown in the details view
+	statefulSetPVCs *statefulSetPVCs
+	// Snapshots of the namespace of the PVC shown in the details view
+	pvcSnapshots *pvcSnapshots
+	// decodeValues shows base64 configmap values decoded in the YAML view,
+	// toggled with U
+	decodeValues bool
+
+	// DNS lookup of the ExternalName service shown in the details view
+	externalNameLookup *externalNameLookup
+
+	// Events of the pod shown in the details view, for its probe failures
+	podEventsLookup *podEventsLookup
+
+	// Usage of the containers of the pod shown in the details view
+	containerStatsLookup *containerStatsLookup
+
+	// Services and endpoints of the pod shown in the details view
+	podNetworkLookup *podNetworkLookup
+
+	// Registry manifests of the images looked up with k, by image
+	imageManifests map[string]*imageManifestLookup
+
+	// How ages and timestamps are shown, cycled with 'z'
+	timestamps timefmt.Formatter
+
+	// What the current user can do in each namespace, checked with W or in
+	// the background for the current namespace; guarded by permissionsMu
+	permissions   map[string]*namespacePermissions
+	permissionsMu sync.Mutex
+
+	// Outcome of the last mutating action and its kubectl equivalent
+	lastAction *actionStatus
+
+	// Pods using the most CPU or memory
+	topPods topPodsView
+
+	// Followed logs of the pod shown in ViewModeLogs
+	logs logsView
+
+	// sessionPath is the file the session is saved to; empty saves none.
+	// restoredSelection is the resource name to select once the restored
+	// session's resources are loaded.
+	sessionPath         string
+	restoreSessionOnRun bool
+	restoredSelection   string
+
+	// logFile is where klog writes while the TUI runs, shown by :logs;
+	// empty when it logs to stderr
+	logFile string
+
+	// Resources bookmarked with b across namespaces, saved with the session,
+	// and the state of ViewModeBookmarks listing them
+	bookmarks     []Bookmark
+	bookmarksView bookmarksView
+
+	// Probe failures injected from the chaos menu by namespace/deployment,
+	// written by their revert timers too, so guarded by chaosMu
+	chaos   map[string]ChaosInjection
+	chaosMu sync.Mutex
+
+	// Probes of the pod shown in ViewModeProbeOverride
+	probeOverride *probeOverrideView
+
+	// podExec runs the commands of the file browser in containers; the
+	// directory shown in ViewModeFileBrowser and the file in ViewModeEditor
+	podExec     k8s.PodExec
+	fileBrowser *fileBrowserView
+	editor      *editorView
+
+	// Cluster overview shown at start and with ` or F1
+	dashboard dashboardView
+	// notReadyFilter keeps only the pods, deployments and nodes that need
+	// attention, set when jumping from the dashboard and cleared with 'f'
+	notReadyFilter bool
+
+	// Redraws caused by background updates, at most one per interval
+	redraws *RedrawCoalescer
+	// Main loop measurements shown with F12
+	debug debugStats
+
 	// Async data loading
 	dataChan chan *DataUpdate
-}
 
-// NewTUI creates a new TUI instance
-func NewTUI(clientset kubernetes.Interface) (*TUI, error) {
+	// When each resource type was last loaded and which are being loaded,
+	// guarded by freshnessMu because updates are handled on their own goroutine
+	freshnessMu sync.Mutex
+	lastUpdated map[ResourceType]time.Time
+	refreshing  map[ResourceType]bool
+	// resourceVersions are those of the last lists, so that reloads can be
+	// served from the API server's watch cache
+	resourceVersions map[ResourceType]string
+	// noAccess holds the resource types whose last load was Forbidden
+	noAccess map[ResourceType]bool
+	// loadAttempts counts the retries of the failed loads being retried,
+	// and loadFailures holds the loads that failed after theirs. A full
+	// refresh bumps loadGeneration, dropping the retries of the last one.
+	loadAttempts   map[ResourceType]int
+	loadFailures   map[ResourceType]*loadFailure
+	loadGeneration int
+	// loadNamespace is the namespace the current load generation loads
+	loadNamespace string
+	// lastErrors holds the error of the last load of each resource type
+	// that failed, marked with a red ! on its tab until it loads again
+	lastErrors map[ResourceType]error
+	// loadToast says in the status bar that a retried load succeeded
+	loadToast *loadToast
+
+	// Alerting
+	alerts            *alerts.Engine
+	notifications     []alerts.Event
+	showNotifications bool
+	alertFlashUntil   time.Time
+}
+
+// maxNotifications caps how many alert events the notifications pane keeps
+const maxNotifications = 200
+
+// alertFlashDuration is how long the status bar flashes after an alert fires
+const alertFlashDuration = 3 * time.Second
+
+// podIPColumnMinWidth is the terminal width the pod list needs to exceed to
+// show the Pod IP column
+const podIPColumnMinWidth = 120
+
+// NewTUI creates a new TUI instance loading resources from source
+func NewTUI(source DataSource, cfg *config.Config) (*TUI, error) {
+	guard, err := k8s.NewNamespaceGuard(cfg.Kubernetes.ProtectedNamespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	alertEngine, err := newAlertEngine(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps, err := timefmt.New(cfg.UI.TimestampFormat, cfg.UI.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create screen: %v", err)
@@ -279,14 +530,24 @@ func NewTUI(clientset kubernetes.Interface) (*TUI, error) {
 
 	screen.SetStyle(tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite))
 
+	var clientset kubernetes.Interface
+	if withClientset, ok := source.(clientsetSource); ok {
+		clientset = withClientset.Clientset()
+	}
+
 	return &TUI{
 		screen:    screen,
+		source:    source,
 		clientset: clientset,
-		selected:  0,
-		namespace: "kube-system",
-		filter:    "",
-		showHelp:  false,
-		loading:   false,
+		config:    cfg,
+		guard:     guard,
+
+		imagePolicy: k8s.NewImagePolicy(cfg.Features.AllowedRegistries),
+		selected:    0,
+		namespace:   "kube-system",
+		filter:      "",
+		showHelp:    false,
+		loading:     false,
 
 		// Async loading
 		loadingCounter: 0,
@@ -301,6 +562,7 @@ func NewTUI(clientset kubernetes.Interface) (*TUI, error) {
 		// Theming
 		currentThemeIndex: 0,
 		theme:             DefaultTheme(),
+		timestamps:        timestamps,
 
 		// Split-pane
 		splitRatio: 0.5,
@@ -313,7 +575,7 @@ func NewTUI(clientset kubernetes.Interface) (*TUI, error) {
 		// Resource data
 		deployments: []appsv1.Deployment{},
 		services:    []v1.Service{},
-		configMaps:  []v1.ConfigMap{},
+		configMaps:  []k8s.ConfigMapSummary{},
 
 		// Scrolling
 		detailsScroll:       0,
@@ -325,9 +587,34 @@ func NewTUI(clientset kubernetes.Interface) (*TUI, error) {
 
 		// Async data loading
 		dataChan: make(chan *DataUpdate, 10),
+
+		// Alerting
+		alerts: alertEngine,
+
+		redraws: NewRedrawCoalescer(redrawInterval(cfg), func() {
+			screen.PostEvent(tcell.NewEventInterrupt(nil))
+		}),
 	}, nil
 }
 
+// newAlertEngine builds the alert engine from the configured rules, or returns
+// nil when no rules are configured
+func newAlertEngine(cfg *config.Config) (*alerts.Engine, error) {
+	if len(cfg.Alerts.Rules) == 0 {
+		return nil, nil
+	}
+
+	rules := make([]*alerts.Rule, 0, len(cfg.Alerts.Rules))
+	for _, r := range cfg.Alerts.Rules {
+		rule, err := alerts.NewRule(r.Name, r.Kind, r.Condition, r.Namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert rule: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+	return alerts.NewEngine(rules, time.Duration(cfg.Alerts.CooldownSeconds)*time.Second), nil
+}
+
 // Run starts the TUI main loop
 func (t *TUI) Run() error {
 	defer t.screen.Fini()
@@ -335,140 +622,381 @@ func (t *TUI) Run() error {
 	// Start data update handler
 	go t.handleDataUpdates()
 
+	// Watch nodes for memory, disk and PID pressure
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if t.hasClientset() {
+		go NewNodePressureWatcher(t.clientset, nodePressureInterval, t.dataChan).Run(ctx)
+	}
+	t.watchShutdownSignals(ctx)
+	defer t.closeTopPods()
+	defer t.closeDashboard()
+	defer t.endAllChaos()
+	if t.redraws != nil {
+		go t.redraws.Run(ctx)
+	}
+
+	// The restored session decides what to load
+	t.loadBookmarks()
+	restored := t.restoreSessionOnRun && t.restoreSession()
+	defer t.saveSession()
+
 	// Initial data load
 	if err := t.refreshData(); err != nil {
 		return fmt.Errorf("failed to load data: %v", err)
 	}
+	// Start on the cluster overview, which needs the clientset, unless the
+	// session was restored
+	if t.hasClientset() && !restored {
+		t.openDashboard()
+	}
 
 	// Main event loop
 	for {
+		start := time.Now()
 		t.draw()
 		t.screen.Show()
+		t.debug.recordFrame(time.Since(start), t.redrawEvents(), time.Now())
 
 		event := t.screen.PollEvent()
 		switch ev := event.(type) {
 		case *tcell.EventKey:
-			if t.showHelp {
-				// Any key exits help
-				t.showHelp = false
-				continue
+			if t.handleKey(ev) {
+				return nil
 			}
+		case *tcell.EventResize:
+			t.screen.Sync()
+		case *prefetchEvent:
+			t.handlePrefetch(ev)
+		case *shutdownEvent:
+			return nil
+		}
+	}
+}
 
-			// Handle view mode navigation
-			if t.viewMode != ViewModeList {
-				switch ev.Key() {
-				case tcell.KeyEscape:
-					t.viewMode = ViewModeList
-					continue
-				case tcell.KeyDown:
-					switch t.viewMode {
-					case ViewModeDetails, ViewModeYAML:
-						t.detailsScroll++
-					case ViewModeLogs:
-						t.logsScroll++
-					case ViewModeRelationships:
-						t.relationshipsScroll++
-					}
-					continue
-				case tcell.KeyUp:
-					switch t.viewMode {
-					case ViewModeDetails, ViewModeYAML:
-						if t.detailsScroll > 0 {
-							t.detailsScroll--
-						}
-					case ViewModeLogs:
-						if t.logsScroll > 0 {
-							t.logsScroll--
-						}
-					case ViewModeRelationships:
-						if t.relationshipsScroll > 0 {
-							t.relationshipsScroll--
-						}
-					}
-					continue
-				}
-			}
+// handleKey handles a key press of the main loop, and reports whether it
+// quits the TUI
+func (t *TUI) handleKey(ev *tcell.EventKey) bool {
+	// g waits for a second g to go to the top
+	pendingG := t.pendingG
+	t.pendingG = false
 
-			switch ev.Key() {
-			case tcell.KeyEscape, tcell.KeyCtrlC:
-				if t.viewMode != ViewModeList {
-					t.viewMode = ViewModeList
-				} else {
-					return nil
-				}
-			case tcell.KeyDown, tcell.KeyRight:
-				t.moveSelection(1)
-			case tcell.KeyUp, tcell.KeyLeft:
-				t.moveSelection(-1)
-			case tcell.KeyEnter:
-				if t.viewMode == ViewModeList {
-					t.viewMode = ViewModeDetails
-				}
-			case tcell.KeyTab:
-				t.currentView = ResourceType((int(t.currentView) + 1) % 5)
-				t.selected = 0
-			case tcell.KeyF5:
-				t.refreshData()
-			case tcell.KeyRune:
-				switch ev.Rune() {
-				case 'q':
-					return nil
-				case 'r':
-					t.refreshData()
-				case 'd':
-					t.deleteSelectedResource()
-				case 'n':
-					t.changeNamespace()
-				case 'c':
-					t.createPodDialog()
-				case 'h', '?':
-					t.showHelp = true
-				case '/':
-					t.searchDialog()
-				case 'f':
-					t.clearFilter()
-				case '1':
-					t.currentView = ResourcePods
-					t.selected = 0
-				case '2':
-					t.currentView = ResourceDeployments
-					t.selected = 0
-				case '3':
-					t.currentView = ResourceServices
-					t.selected = 0
-				case '4':
-					t.currentView = ResourceConfigMaps
-					t.selected = 0
-				case '5':
-					t.currentView = ResourceNamespaces
-					t.selected = 0
-				case 'v':
-					t.nextViewMode()
-				case 'y':
-					if t.viewMode == ViewModeDetails {
-						t.viewMode = ViewModeYAML
-					}
-				case 'j':
-					if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
-						t.viewMode = ViewModeLogs
-					}
-				case 's':
-					t.toggleSplitView()
-				case 'S':
-					t.switchSplitLayout()
-				case 't', 'T':
-					t.nextTheme()
-				}
+	if t.search != nil {
+		t.handleSearchKey(ev)
+		return false
+	}
+
+	if t.showHelp {
+		// Any key exits help
+		t.showHelp = false
+		return false
+	}
+
+	if t.showNotifications {
+		// Any key closes the notifications pane
+		t.showNotifications = false
+		return false
+	}
+
+	if t.serviceProbe != nil {
+		// Any key closes the service probe modal
+		t.serviceProbe = nil
+		return false
+	}
+
+	if t.viewMode == ViewModeClusterInfo && t.handleClusterInfoKey(ev) {
+		return false
+	}
+	if t.viewMode == ViewModeEditor && t.handleEditorKey(ev) {
+		return false
+	}
+
+	ev = t.navigationKey(ev)
+	if t.viewMode == ViewModeDiff && t.handleDiffKey(ev) {
+		return false
+	}
+	if t.viewMode == ViewModeDashboard && t.handleDashboardKey(ev) {
+		return false
+	}
+	if t.viewMode == ViewModeProbeOverride && t.handleProbeOverrideKey(ev) {
+		return false
+	}
+	if t.viewMode == ViewModeFileBrowser && t.handleFileBrowserKey(ev) {
+		return false
+	}
+	if t.viewMode == ViewModeBookmarks && t.handleBookmarksKey(ev) {
+		return false
+	}
+	if t.viewMode == ViewModeLogs && t.handleLogsKey(ev) {
+		return false
+	}
+
+	// Handle view mode navigation
+	if t.viewMode != ViewModeList {
+		switch ev.Key() {
+		case tcell.KeyEscape:
+			t.closeTopPods()
+			t.closeLogs()
+			t.viewMode = ViewModeList
+			return false
+		case tcell.KeyDown:
+			t.navigate(1)
+			return false
+		case tcell.KeyUp:
+			t.navigate(-1)
+			return false
+		}
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		if t.viewMode != ViewModeList {
+			t.viewMode = ViewModeList
+		} else {
+			return true
+		}
+	case tcell.KeyDown:
+		t.moveSelection(1)
+	case tcell.KeyUp:
+		t.moveSelection(-1)
+	case tcell.KeyLeft, tcell.KeyRight:
+		delta := 1
+		if ev.Key() == tcell.KeyLeft {
+			delta = -1
+		}
+		// The classic keys move with the arrows and scroll with Shift
+		if t.vimKeys() || ev.Modifiers()&tcell.ModShift != 0 {
+			t.scrollTable(delta)
+		} else {
+			t.moveSelection(delta)
+		}
+	case tcell.KeyCtrlD:
+		t.navigate(t.halfPage())
+	case tcell.KeyCtrlU:
+		t.navigate(-t.halfPage())
+	case tcell.KeyEnter:
+		if t.viewMode == ViewModeList {
+			t.viewMode = ViewModeDetails
+			t.detailsScroll = 0
+		}
+	case tcell.KeyTab:
+		// Most terminals send Ctrl+I as a plain Tab; those reporting the
+		// modifier, such as the Windows console, open the cluster info
+		if ev.Modifiers()&tcell.ModCtrl != 0 && t.hasClientset() {
+			t.showClusterInfo()
+		} else {
+			t.switchView(t.nextAccessibleView(t.currentView, 1))
+		}
+	case tcell.KeyF5:
+		t.refreshData()
+	case tcell.KeyF12:
+		t.debug.show = !t.debug.show
+	case tcell.KeyF1:
+		if t.hasClientset() {
+			t.openDashboard()
+		}
+	case tcell.KeyRune:
+		if !t.keyAvailable(ev.Rune()) {
+			return false
+		}
+		switch ev.Rune() {
+		case 'q':
+			return true
+		case 'r':
+			t.refreshData()
+		case 'R':
+			t.retryFailedLoad()
+		case 'd':
+			t.deleteSelectedResource()
+		case 'n':
+			t.changeNamespace()
+		case 'c':
+			if resource := t.createResource(); t.forbidden("create", resource) {
+				t.showForbidden("create", resource)
+				return false
+			}
+			switch t.currentView {
+			case ResourceDeployments:
+				t.createDeploymentDialog()
+			case ResourceNamespaces:
+				t.createNamespaceDialog()
+			case ResourceConfigMaps:
+				t.createConfigMapDialog()
+			case ResourceServices:
+				t.createServiceDialog()
+			default:
+				t.createPodDialog()
+			}
+		case '?':
+			t.showHelp = true
+		case 'h':
+			if t.vimKeys() {
+				t.switchView(t.nextAccessibleView(t.currentView, -1))
+			} else {
+				t.showHelp = true
+			}
+		case 'l':
+			if !t.vimKeys() {
+				break
+			}
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.openLogs(t.initialLogTailLines())
+			} else {
+				t.switchView(t.nextAccessibleView(t.currentView, 1))
+			}
+		case 'g':
+			if pendingG {
+				t.navigate(-scrollToEnd)
+			} else {
+				t.pendingG = true
+			}
+		case 'G':
+			t.navigate(scrollToEnd)
+		case 'N':
+			t.showNotifications = true
+		case '/':
+			t.openSearch()
+		case ':':
+			t.commandPrompt()
+		case 'f':
+			t.clearFilter()
+		case '1':
+			t.switchView(ResourcePods)
+		case '2':
+			t.switchView(ResourceDeployments)
+		case '3':
+			t.switchView(ResourceServices)
+		case '4':
+			t.switchView(ResourceConfigMaps)
+		case '5':
+			t.switchView(ResourceNamespaces)
+		case '6':
+			t.switchView(ResourceNodes)
+		case '7':
+			t.switchView(ResourceCRDs)
+		case '8':
+			t.switchView(ResourceStatefulSets)
+		case '9':
+			t.switchView(ResourcePVCs)
+		case '0':
+			t.switchView(ResourceDaemonSets)
+		case 'v':
+			t.nextViewMode()
+		case 'y':
+			if t.viewMode == ViewModeDetails {
+				t.viewMode = ViewModeYAML
+			}
+		case 'j':
+			// Only the classic keys get here; the vim keys made j Down
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.openLogs(t.initialLogTailLines())
+			}
+		case 'D':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourceDeployments {
+				t.showDeploymentDiff()
+			}
+		case 'H':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourceDeployments {
+				t.showRolloutTimeline()
+			}
+		case 'p':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourceServices {
+				t.probeSelectedService()
+			}
+		case 'W':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourceNamespaces {
+				t.checkSelectedNamespacePermissions()
+			}
+		case 'o':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.openProbeOverride()
+			}
+		case 'P':
+			if (t.viewMode == ViewModeList || t.viewMode == ViewModeDetails) && t.currentView == ResourceDeployments {
+				t.togglePauseSelectedDeployment()
+			}
+		case 'L':
+			if t.viewMode == ViewModeList || t.viewMode == ViewModeDetails {
+				t.editSelectedMetadata()
+			}
+		case 'E':
+			if (t.viewMode == ViewModeDetails || t.viewMode == ViewModeYAML) && t.currentView == ResourceConfigMaps {
+				t.loadFullConfigMapValues()
+			}
+		case 'O':
+			if t.viewMode == ViewModeList || t.viewMode == ViewModeDetails {
+				t.restartSelectedWorkload()
+			}
+		case '=':
+			if (t.viewMode == ViewModeList || t.viewMode == ViewModeDetails) && (t.currentView == ResourceDeployments || t.currentView == ResourceStatefulSets) {
+				t.scaleSelectedWorkload()
+			}
+		case 'B':
+			t.openBookmarks()
+		case 'U':
+			if t.viewMode == ViewModeYAML && t.currentView == ResourceConfigMaps {
+				t.decodeValues = !t.decodeValues
+			}
+		case 'b':
+			t.toggleBookmark()
+		case 's':
+			t.toggleSplitView()
+		case 'S':
+			t.switchSplitLayout()
+		case 'V':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePVCs {
+				t.snapshotSelectedPVC()
+			}
+		case 't', 'T':
+			t.nextTheme()
+		case 'k', 'i':
+			// k with the classic keys, i with the vim keys, which move with k
+			if ev.Rune() == 'i' && !t.vimKeys() {
+				break
+			}
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.lookupSelectedPodImages()
+			}
+		case 'x':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.debugSelectedPod()
+			}
+		case 'F':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.openFileBrowser()
+			}
+		case 'A':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourcePods {
+				t.showTolerationAdvisor()
+			}
+		case 'K':
+			t.copyLastKubectl()
+		case 'X':
+			if t.viewMode == ViewModeDetails && t.currentView == ResourceDeployments {
+				t.chaosMenuDialog()
+			}
+		case 'I':
+			t.showClusterInfo()
+		case 'z':
+			t.nextTimestampFormat()
+		case '`':
+			t.openDashboard()
+		case 'C', 'M':
+			sortBy := k8s.TopPodsByCPU
+			if ev.Rune() == 'M' {
+				sortBy = k8s.TopPodsByMemory
+			}
+			if t.viewMode == ViewModeTopPods || (t.viewMode == ViewModeList && t.currentView == ResourcePods) {
+				t.openTopPods(sortBy)
 			}
-		case *tcell.EventResize:
-			t.screen.Sync()
 		}
 	}
+	return false
 }
 
 // loadPods fetches pods from the current namespace
 func (t *TUI) loadPods() error {
-	pods, err := k8s.ListPods(t.clientset, t.namespace)
+	pods, _, err := t.data().ListPods(t.namespace, "")
 	if err != nil {
 		klog.Errorf("Failed to list pods: %v", err)
 		return err
@@ -487,8 +1015,10 @@ func (t *TUI) loadPods() error {
 
 // refreshData loads all resource types asynchronously
 func (t *TUI) refreshData() error {
+	t.preflightPermissions()
 	t.loading = true
-	t.loadingCounter = 5 // 5 resource types
+	t.loadingCounter = len(loadingResourceTypes)
+	t.loadedResources = make(map[ResourceType]bool)
 	t.draw()
 	t.screen.Show()
 
@@ -498,75 +1028,75 @@ func (t *TUI) refreshData() error {
 	t.services = nil
 	t.configMaps = nil
 	t.namespaces = nil
-
-	// Start async loading
-	go t.loadPodsAsync()
-	go t.loadDeploymentsAsync()
-	go t.loadServicesAsync()
-	go t.loadConfigMapsAsync()
-	go t.loadNamespacesAsync()
+	t.nodes = nil
+	t.crds = nil
+	t.statefulSets = nil
+	t.pvcs = nil
+	t.daemonSets = nil
+
+	// Start async loading, so tab switches do not load the same types again
+	t.freshnessMu.Lock()
+	t.refreshing = make(map[ResourceType]bool)
+	t.loadGeneration++
+	t.loadNamespace = t.namespace
+	t.loadAttempts = nil
+	t.loadFailures = nil
+	t.lastErrors = nil
+	for _, rt := range loadingResourceTypes {
+		t.refreshing[rt] = true
+		go t.loadAsync(t.newLoad(rt, false))
+	}
+	t.freshnessMu.Unlock()
 
 	return nil
 }
 
 // loadPodsAsync loads pods asynchronously
-func (t *TUI) loadPodsAsync() {
-	pods, err := k8s.ListPods(t.clientset, t.namespace)
-	update := &DataUpdate{
-		ResourceType: ResourcePods,
-		Pods:         pods,
-		Error:        err,
-	}
+func (t *TUI) loadPodsAsync(load dataLoad) {
+	update := load.update()
+	update.Pods, update.ResourceVersion, update.Error = t.data().ListPods(load.namespace, t.resourceVersion(ResourcePods))
 	t.dataChan <- update
 }
 
 // loadDeploymentsAsync loads deployments asynchronously
-func (t *TUI) loadDeploymentsAsync() {
-	deployments, err := k8s.ListDeployments(t.clientset, t.namespace)
-	update := &DataUpdate{
-		ResourceType: ResourceDeployments,
-		Deployments:  deployments,
-		Error:        err,
-	}
+func (t *TUI) loadDeploymentsAsync(load dataLoad) {
+	update := load.update()
+	update.Deployments, update.ResourceVersion, update.Error = t.data().ListDeployments(load.namespace, t.resourceVersion(ResourceDeployments))
 	t.dataChan <- update
 }
 
 // loadServicesAsync loads services asynchronously
-func (t *TUI) loadServicesAsync() {
-	services, err := k8s.ListServices(t.clientset, t.namespace)
-	update := &DataUpdate{
-		ResourceType: ResourceServices,
-		Services:     services,
-		Error:        err,
-	}
+func (t *TUI) loadServicesAsync(load dataLoad) {
+	update := load.update()
+	update.Services, update.ResourceVersion, update.Error = t.data().ListServices(load.namespace, t.resourceVersion(ResourceServices))
 	t.dataChan <- update
 }
 
 // loadConfigMapsAsync loads configmaps asynchronously
-func (t *TUI) loadConfigMapsAsync() {
-	configMaps, err := k8s.ListConfigMaps(t.clientset, t.namespace)
-	update := &DataUpdate{
-		ResourceType: ResourceConfigMaps,
-		ConfigMaps:   configMaps,
-		Error:        err,
-	}
+func (t *TUI) loadConfigMapsAsync(load dataLoad) {
+	update := load.update()
+	update.ConfigMaps, update.ResourceVersion, update.Error = t.data().ListConfigMaps(load.namespace, t.resourceVersion(ResourceConfigMaps))
 	t.dataChan <- update
 }
 
 // loadNamespacesAsync loads namespaces asynchronously
-func (t *TUI) loadNamespacesAsync() {
-	namespaces, err := k8s.ListNamespaces(t.clientset)
-	update := &DataUpdate{
-		ResourceType: ResourceNamespaces,
-		Namespaces:   namespaces,
-		Error:        err,
-	}
+func (t *TUI) loadNamespacesAsync(load dataLoad) {
+	update := load.update()
+	update.Namespaces, update.Error = t.data().ListNamespaces()
+	t.dataChan <- update
+}
+
+// loadNodesAsync loads nodes asynchronously
+func (t *TUI) loadNodesAsync(load dataLoad) {
+	update := load.update()
+	update.Nodes, update.Error = t.data().ListNodes()
+	update.NodeUsage = t.loadNodeUsage()
 	t.dataChan <- update
 }
 
 // loadDeployments fetches deployments from the current namespace
 func (t *TUI) loadDeployments() error {
-	deployments, err := k8s.ListDeployments(t.clientset, t.namespace)
+	deployments, _, err := t.data().ListDeployments(t.namespace, "")
 	if err != nil {
 		klog.Errorf("Failed to list deployments: %v", err)
 		return err
@@ -577,7 +1107,7 @@ func (t *TUI) loadDeployments() error {
 
 // loadServices fetches services from the current namespace
 func (t *TUI) loadServices() error {
-	services, err := k8s.ListServices(t.clientset, t.namespace)
+	services, _, err := t.data().ListServices(t.namespace, "")
 	if err != nil {
 		klog.Errorf("Failed to list services: %v", err)
 		return err
@@ -588,7 +1118,7 @@ func (t *TUI) loadServices() error {
 
 // loadConfigMaps fetches configmaps from the current namespace
 func (t *TUI) loadConfigMaps() error {
-	configMaps, err := k8s.ListConfigMaps(t.clientset, t.namespace)
+	configMaps, _, err := t.data().ListConfigMaps(t.namespace, "")
 	if err != nil {
 		klog.Errorf("Failed to list configmaps: %v", err)
 		return err
@@ -600,36 +1130,90 @@ func (t *TUI) loadConfigMaps() error {
 // handleDataUpdates runs in a goroutine to process async data updates
 func (t *TUI) handleDataUpdates() {
 	for update := range t.dataChan {
+		// Failed loads being retried are handled once they settle
+		if t.retryLoad(update) {
+			continue
+		}
 		t.handleDataUpdate(update)
-		// Trigger a redraw by sending a custom event or just continuing
-		// For now, we'll rely on the main loop redrawing on events
+		// Once the current tab has settled, warm up the tabs either side of it
+		t.requestPrefetch(update.ResourceType)
+		// Wake the main loop so the loading progress is redrawn
+		t.requestRedraw()
 	}
 }
 
 // handleDataUpdate processes a data update from async loading
 func (t *TUI) handleDataUpdate(update *DataUpdate) {
+	if !t.isCurrentLoad(update) {
+		klog.Infof("Dropping a %v update of namespace %q from an earlier load", update.ResourceType, update.Namespace)
+		return
+	}
 	if update.Error != nil {
 		klog.Errorf("Failed to load %v: %v", update.ResourceType, update.Error)
-		// Could show error in UI
+	}
+	t.recordLoadError(update.ResourceType, update.Error)
+	t.recordUpdate(update)
+
+	// A failed background load keeps the data from the last successful one
+	if update.Error == nil || !update.Background {
+		switch update.ResourceType {
+		case ResourcePods:
+			t.pods = update.Pods
+			klog.Infof("Loaded %d pods", len(t.pods))
+			for i := range t.pods {
+				t.checkAlerts(&t.pods[i])
+			}
+		case ResourceDeployments:
+			t.deployments = update.Deployments
+			klog.Infof("Loaded %d deployments", len(t.deployments))
+			for i := range t.deployments {
+				t.checkAlerts(&t.deployments[i])
+			}
+		case ResourceServices:
+			t.services = update.Services
+			klog.Infof("Loaded %d services", len(t.services))
+		case ResourceConfigMaps:
+			t.configMaps = update.ConfigMaps
+			klog.Infof("Loaded %d configmaps", len(t.configMaps))
+		case ResourceNamespaces:
+			t.namespaces = update.Namespaces
+			klog.Infof("Loaded %d namespaces", len(t.namespaces))
+		case ResourceNodes:
+			t.nodes = update.Nodes
+			// Pressure checks do not load usage, so keep the last one
+			if update.NodeUsage != nil {
+				t.nodeUsage = update.NodeUsage
+			}
+		case ResourceCRDs:
+			t.crds = update.CRDs
+			klog.Infof("Loaded %d CRDs", len(t.crds))
+		case ResourceStatefulSets:
+			t.statefulSets = update.StatefulSets
+			klog.Infof("Loaded %d statefulsets", len(t.statefulSets))
+		case ResourcePVCs:
+			t.pvcs = update.PVCs
+			klog.Infof("Loaded %d PVCs", len(t.pvcs))
+		case ResourceDaemonSets:
+			t.daemonSets = update.DaemonSets
+			klog.Infof("Loaded %d daemonsets", len(t.daemonSets))
+		}
 	}
 
-	switch update.ResourceType {
-	case ResourcePods:
-		t.pods = update.Pods
-		klog.Infof("Loaded %d pods", len(t.pods))
-	case ResourceDeployments:
-		t.deployments = update.Deployments
-		klog.Infof("Loaded %d deployments", len(t.deployments))
-	case ResourceServices:
-		t.services = update.Services
-		klog.Infof("Loaded %d services", len(t.services))
-	case ResourceConfigMaps:
-		t.configMaps = update.ConfigMaps
-		klog.Infof("Loaded %d configmaps", len(t.configMaps))
-	case ResourceNamespaces:
-		t.namespaces = update.Namespaces
-		klog.Infof("Loaded %d namespaces", len(t.namespaces))
+	for _, event := range update.Events {
+		klog.Warningf("Node %s under %s", event.Name, event.Condition)
+		t.notify(event)
+	}
+
+	if update.Background {
+		// The current tab may have shrunk under the selection
+		t.adjustSelection()
+		return
+	}
+
+	if t.loadedResources == nil {
+		t.loadedResources = make(map[ResourceType]bool)
 	}
+	t.loadedResources[update.ResourceType] = true
 
 	// Decrement counter
 	t.loadingCounter--
@@ -637,8 +1221,40 @@ func (t *TUI) handleDataUpdate(update *DataUpdate) {
 		t.loading = false
 		// Adjust selection if needed
 		t.adjustSelection()
-		klog.Infof("All resources loaded - Pods: %d, Deployments: %d, Services: %d, ConfigMaps: %d, Namespaces: %d in namespace: %s",
-			len(t.pods), len(t.deployments), len(t.services), len(t.configMaps), len(t.namespaces), t.namespace)
+		t.selectRestoredResource()
+		klog.Infof("All resources loaded - Pods: %d, Deployments: %d, Services: %d, ConfigMaps: %d, Namespaces: %d, Nodes: %d in namespace: %s",
+			len(t.pods), len(t.deployments), len(t.services), len(t.configMaps), len(t.namespaces), len(t.nodes), t.namespace)
+	}
+}
+
+// checkAlerts evaluates the alert rules against an object and notifies every
+// rule that fires
+func (t *TUI) checkAlerts(obj runtime.Object) {
+	for _, event := range t.alerts.Evaluate(obj) {
+		klog.Warningf("Alert %q fired for %s %s/%s", event.Rule, event.Kind, event.Namespace, event.Name)
+		t.notify(event)
+	}
+}
+
+// notify rings the bell, flashes the status bar, records a notification and
+// runs the configured alert command for an event
+func (t *TUI) notify(event alerts.Event) {
+	t.notifications = append(t.notifications, event)
+	if len(t.notifications) > maxNotifications {
+		t.notifications = t.notifications[len(t.notifications)-maxNotifications:]
+	}
+	t.alertFlashUntil = time.Now().Add(alertFlashDuration)
+
+	if t.screen != nil {
+		t.screen.Beep()
+	}
+
+	if t.config != nil && t.config.Alerts.Command != "" {
+		go func(event alerts.Event) {
+			if err := alerts.RunCommand(t.config.Alerts.Command, event); err != nil {
+				klog.Errorf("Failed to run alert command: %v", err)
+			}
+		}(event)
 	}
 }
 
@@ -654,6 +1270,18 @@ func (t *TUI) adjustSelection() {
 		maxItems = len(t.services)
 	case ResourceConfigMaps:
 		maxItems = len(t.configMaps)
+	case ResourceNamespaces:
+		maxItems = len(t.namespaces)
+	case ResourceNodes:
+		maxItems = len(t.nodes)
+	case ResourceCRDs:
+		maxItems = len(t.crds)
+	case ResourceStatefulSets:
+		maxItems = len(t.statefulSets)
+	case ResourcePVCs:
+		maxItems = len(t.pvcs)
+	case ResourceDaemonSets:
+		maxItems = len(t.daemonSets)
 	}
 
 	if t.selected >= maxItems {
@@ -685,12 +1313,34 @@ func (t *TUI) draw() {
 	t.screen.Clear()
 
 	width, height := t.screen.Size()
+	if t.debug.show {
+		defer t.drawDebugOverlay(width, height)
+	}
 
 	if t.showHelp {
 		t.drawHelpScreen(width, height)
 		return
 	}
 
+	if t.showNotifications {
+		t.drawNotifications(width, height)
+		return
+	}
+
+	// The dashboard has its own data, so it does not wait for the tabs
+	if t.viewMode == ViewModeDashboard {
+		t.drawDashboardView(width, height)
+		return
+	}
+	if t.viewMode == ViewModeClusterInfo && t.clusterInfo != nil {
+		t.drawClusterInfoView(width, height)
+		return
+	}
+	if t.viewMode == ViewModeBookmarks {
+		t.drawBookmarksView(width, height)
+		return
+	}
+
 	if t.loading {
 		t.drawLoadingScreen(width, height)
 		return
@@ -705,6 +1355,10 @@ func (t *TUI) draw() {
 	case LayoutSplitHorizontal:
 		t.drawSplitHorizontal(width, height)
 	}
+
+	if t.serviceProbe != nil {
+		t.drawServiceProbe(width, height)
+	}
 }
 
 // drawSingleView draws the single-pane view
@@ -721,6 +1375,18 @@ func (t *TUI) drawSingleView(width, height int) {
 		t.drawLogsView(width, height)
 	case ViewModeRelationships:
 		t.drawRelationshipsView(width, height)
+	case ViewModeDiff:
+		t.drawDiffView(width, height)
+	case ViewModeTopPods:
+		t.drawTopPodsView(width, height)
+	case ViewModeProbeOverride:
+		t.drawProbeOverrideView(width, height)
+	case ViewModeRollout:
+		t.drawRolloutView(width, height)
+	case ViewModeFileBrowser:
+		t.drawFileBrowserView(width, height)
+	case ViewModeEditor:
+		t.drawEditorView(width, height)
 	}
 }
 
@@ -733,14 +1399,31 @@ func (t *TUI) nextViewMode() {
 		t.viewMode = ViewModeYAML
 	case ViewModeYAML:
 		if t.currentView == ResourcePods {
-			t.viewMode = ViewModeLogs
+			t.openLogs(t.initialLogTailLines())
 		} else {
 			t.viewMode = ViewModeList
 		}
 	case ViewModeLogs:
+		t.closeLogs()
 		t.viewMode = ViewModeRelationships
-	case ViewModeRelationships:
+	case ViewModeDiff:
+		t.closeDiffViewer()
+	case ViewModeRelationships, ViewModeRollout:
 		t.viewMode = ViewModeList
+	case ViewModeTopPods:
+		t.closeTopPods()
+	case ViewModeDashboard:
+		t.closeDashboard()
+	case ViewModeProbeOverride:
+		t.closeProbeOverride()
+	case ViewModeClusterInfo:
+		t.closeClusterInfo()
+	case ViewModeFileBrowser:
+		t.closeFileBrowser()
+	case ViewModeEditor:
+		t.closeEditor()
+	case ViewModeBookmarks:
+		t.closeBookmarks()
 	}
 }
 
@@ -780,32 +1463,72 @@ func (t *TUI) deleteSelectedResource() {
 	case v1.Service:
 		name = r.Name
 		resourceType = "service"
-	case v1.ConfigMap:
+	case k8s.ConfigMapSummary:
 		name = r.Name
 		resourceType = "configmap"
+	case v1.Namespace:
+		name = r.Name
+		resourceType = "namespace"
 	default:
 		return
 	}
 
-	// Show confirmation
-	confirmMsg := fmt.Sprintf("Delete %s '%s'? (y/N)", resourceType, name)
-	t.drawText(0, 1, 50, confirmMsg, tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
-	t.screen.Show()
+	if t.forbidden("delete", resourceType+"s") {
+		t.showForbidden("delete", resourceType+"s")
+		return
+	}
 
-	// Wait for confirmation
-	event := t.screen.PollEvent()
-	if ev, ok := event.(*tcell.EventKey); ok && ev.Rune() == 'y' {
-		var err error
-		switch r := resource.(type) {
-		case v1.Pod:
-			err = k8s.DeletePod(t.clientset, t.namespace, r.Name)
-		case appsv1.Deployment:
-			err = k8s.DeleteDeployment(t.clientset, t.namespace, r.Name)
-		case v1.Service:
-			err = k8s.DeleteService(t.clientset, t.namespace, r.Name)
-		case v1.ConfigMap:
-			err = k8s.DeleteConfigMap(t.clientset, t.namespace, r.Name)
+	// A namespace is guarded by its own name, and takes its content with it
+	guarded, kubectlNamespace := t.namespace, t.namespace
+	ns, isNamespace := resource.(v1.Namespace)
+	var warnings []string
+	if isNamespace {
+		guarded, kubectlNamespace = name, ""
+		warnings = []string{fmt.Sprintf("Everything in namespace '%s' is deleted with it", name)}
+	} else {
+		warnings = t.deletionWarnings(resourceType, name)
+	}
+
+	// Protected namespaces require typing the resource name instead of y/N
+	confirmed := false
+	if isNamespace && k8s.IsDeletionProtected(&ns) {
+		confirmed = t.confirmByTyping(fmt.Sprintf("%s Namespace '%s' is deletion-protected", lockGlyph, name), "delete", resourceType, name, warnings)
+	} else if t.guard.IsProtected(guarded) {
+		confirmed = t.confirmProtectedActionWarned(guarded, "delete", resourceType, name, warnings)
+	} else {
+		// Show confirmation
+		confirmMsg := fmt.Sprintf("Delete %s '%s'? (y/N)", resourceType, name)
+		t.drawText(0, 1, 50, confirmMsg, tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack))
+		for i, warning := range warnings {
+			t.drawText(0, 2+i, 100, "⚠ "+warning, deletionWarningStyle)
 		}
+		t.screen.Show()
+
+		// Wait for confirmation
+		event := t.screen.PollEvent()
+		ev, ok := event.(*tcell.EventKey)
+		confirmed = ok && ev.Rune() == 'y'
+	}
+
+	if confirmed {
+		deletingMsg := fmt.Sprintf("Deleting %s '%s'...", resourceType, name)
+		deletingStyle := tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+		namespace := t.namespace
+		err := t.runWithSpinner(0, 1, 80, deletingMsg, deletingStyle, func() error {
+			switch r := resource.(type) {
+			case v1.Pod:
+				return k8s.DeletePod(t.clientset, namespace, r.Name)
+			case appsv1.Deployment:
+				return k8s.DeleteDeployment(t.clientset, namespace, r.Name)
+			case v1.Service:
+				return k8s.DeleteService(t.clientset, namespace, r.Name)
+			case k8s.ConfigMapSummary:
+				return k8s.DeleteConfigMap(t.clientset, namespace, r.Name)
+			case v1.Namespace:
+				return k8s.DeleteNamespace(t.clientset, r.Name)
+			}
+			return nil
+		})
 
 		if err != nil {
 			klog.Errorf("Failed to delete %s: %v", resourceType, err)
@@ -814,6 +1537,7 @@ func (t *TUI) deleteSelectedResource() {
 			t.screen.Show()
 			time.Sleep(2 * time.Second)
 		} else {
+			t.recordAction(fmt.Sprintf("Deleted %s '%s'", resourceType, name), k8s.KubectlDelete(kubectlNamespace, resourceType, name))
 			// Reload resources
 			t.refreshData()
 		}
@@ -895,13 +1619,13 @@ func (t *TUI) drawListView(width, height int) {
 	t.drawHeader(width)
 
 	// Draw search bar if filter is active
-	if t.filter != "" || t.filterMode {
+	if t.filter != "" || t.filterMode || t.notReadyFilter || t.search != nil {
 		t.drawSearchBar(width, 5)
 	}
 
 	// Draw main content area
 	contentStartY := 6
-	if t.filter != "" || t.filterMode {
+	if t.filter != "" || t.filterMode || t.notReadyFilter || t.search != nil {
 		contentStartY = 8
 	}
 	contentHeight := height - contentStartY - 2 // Leave space for status and footer
@@ -936,23 +1660,20 @@ func (t *TUI) drawHeader(width int) {
 	t.drawText(0, 2, width, sepLine, tcell.StyleDefault.Foreground(t.theme.accent))
 
 	// Resource tabs with better styling
-	tabs := []string{" 1.Pods ", " 2.Deployments ", " 3.Services ", " 4.ConfigMaps ", " 5.Namespaces "}
+	labels := []string{"1.Pods", "2.Deployments", "3.Services", "4.ConfigMaps", "5.Namespaces", "6.Nodes", "7.CRDs", "8.StatefulSets", "9.PVCs", "0.DaemonSets"}
 	tabsY := 3
 
 	x := 0
-	for i, tab := range tabs {
-		var style tcell.Style
-		if ResourceType(i) == t.currentView {
-			// Active tab styling
-			style = tcell.StyleDefault.Background(t.theme.selected).Foreground(tcell.ColorBlack).Bold(true)
-			// Add brackets around active tab
-			tab = "▶" + tab[1:] + "◀"
-		} else {
-			// Inactive tab styling
-			style = tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground)
+	for i, label := range labels {
+		tab, style := t.headerTab(ResourceType(i), label)
+		width := len([]rune(tab))
+		t.drawText(x, tabsY, width, tab, style)
+		x += width
+		// A tab whose last load failed is marked until it loads again
+		if t.loadErrorOf(ResourceType(i)) != nil {
+			t.screen.SetContent(x, tabsY, '!', nil, style.Foreground(tcell.ColorRed).Bold(true))
+			x++
 		}
-		t.drawText(x, tabsY, len(tab), tab, style)
-		x += len(tab)
 	}
 
 	// Bottom border for header section
@@ -965,41 +1686,58 @@ func (t *TUI) drawResourceTable(width, height, startY int) {
 	filtered := t.getFilteredResources()
 
 	if len(filtered) == 0 {
+		if !t.hasAccess(t.currentView) {
+			t.drawText(0, startY, width, t.noAccessMessage(t.currentView), tcell.StyleDefault.Foreground(tcell.ColorGray))
+			return
+		}
+		if failure := t.loadFailureOf(t.currentView); failure != nil {
+			t.drawText(0, startY, width, t.loadFailureMessage(failure), tcell.StyleDefault.Foreground(tcell.ColorRed))
+			return
+		}
+		if err := t.loadErrorOf(t.currentView); err != nil {
+			t.drawText(0, startY, width, loadErrorMessage(err), tcell.StyleDefault.Foreground(tcell.ColorRed))
+			return
+		}
 		t.drawText(0, startY, width, "No resources found", tcell.StyleDefault)
 		return
 	}
 
-	// Get table headers and column widths based on resource type
+	// Get table headers and the columns fitting on screen, scrolled
+	// sideways when they do not all fit
 	headers := t.getTableHeaders()
-	colWidths := t.getColumnWidths(width, len(headers))
+	layout := t.layoutTable(width, len(headers))
 
-	// Draw table header with enhanced styling
+	// Draw table header with enhanced styling, the top border saying how
+	// many columns are out of view
 	headerY := startY
 	headerText := "┌" + strings.Repeat("─", width-2) + "┐"
 	t.drawText(0, headerY, width, headerText, tcell.StyleDefault.Foreground(t.theme.accent))
-
-	headerLine := "│ "
-	for i, header := range headers {
-		headerLine += fmt.Sprintf("%-*s", colWidths[i], header)
-		if i < len(headers)-1 {
-			headerLine += " │ "
-		}
+	if indicator := layout.indicator(); indicator != "" {
+		indicator = " " + indicator + " "
+		x := max(width-2-len([]rune(indicator)), 1)
+		t.drawText(x, headerY, width-1-x, indicator, tcell.StyleDefault.Foreground(t.theme.accent).Bold(true))
 	}
-	headerLine += " │"
-	t.drawText(0, headerY+1, width, headerLine, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
+	t.drawText(0, headerY+1, width, layout.row(headers), tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
 
 	// Draw separator with enhanced styling
 	sepLine := "├" + strings.Repeat("─", width-2) + "┤"
 	t.drawText(0, headerY+2, width, sepLine, tcell.StyleDefault.Foreground(t.theme.accent))
 
+	// Leave space for borders and footer, scrolling to keep the selected
+	// resource on screen
+	rows := max(height-5, 0)
+	if t.selected < t.listScroll {
+		t.listScroll = t.selected
+	} else if t.selected >= t.listScroll+rows {
+		t.listScroll = t.selected - rows + 1
+	}
+	t.listScroll = max(min(t.listScroll, len(filtered)-rows), 0)
+
 	// Draw resources with alternating row colors
 	resourceStartY := headerY + 3
-	for i, resource := range filtered {
-		if i >= height-5 { // Leave space for borders and footer
-			break
-		}
-
-		y := resourceStartY + i
+	for i := t.listScroll; i < len(filtered) && i < t.listScroll+rows; i++ {
+		resource := filtered[i]
+		y := resourceStartY + i - t.listScroll
 		style := tcell.StyleDefault
 
 		// Highlight selected resource
@@ -1014,15 +1752,38 @@ func (t *TUI) drawResourceTable(width, height, startY int) {
 				style = style.Background(tcell.ColorBlack)
 			}
 			style = style.Foreground(t.theme.foreground)
+			// Grey out what nobody has touched in a long time
+			if isLongUnmodified(resource, time.Now()) {
+				style = style.Foreground(tcell.ColorGray)
+			}
 		}
 
-		line := t.formatResourceLine(resource, colWidths)
+		line := t.formatResourceLine(resource, layout)
 		t.drawText(0, y, width, line, style)
+		if node, ok := resource.(v1.Node); ok {
+			if x, colWidth, ok := layout.cell(0); ok {
+				t.drawNodePressureBadges(node, x, y, colWidth)
+			}
+			t.drawNodeUsageCells(node, y, layout, style)
+		}
+		if pod, ok := resource.(v1.Pod); ok {
+			t.drawUnreadyCell(pod, y, layout, style)
+			t.drawGatedBadge(pod, y, layout, style)
+			if i == t.selected {
+				t.drawInitStepper(pod, y, width, layout, style)
+			}
+		}
+		if dep, ok := resource.(appsv1.Deployment); ok {
+			t.drawPausedBadge(dep, y, layout, style)
+		}
+		if ns, ok := resource.(v1.Namespace); ok {
+			t.drawDeletionProtectedBadge(ns, y, layout, style)
+		}
 	}
 
 	// Draw bottom border
-	if len(filtered) > 0 && len(filtered) < height-5 {
-		bottomY := resourceStartY + len(filtered)
+	if len(filtered)-t.listScroll < rows {
+		bottomY := resourceStartY + len(filtered) - t.listScroll
 		bottomBorder := "└" + strings.Repeat("─", width-2) + "┘"
 		t.drawText(0, bottomY, width, bottomBorder, tcell.StyleDefault.Foreground(t.theme.accent))
 	}
@@ -1043,6 +1804,12 @@ func (t *TUI) drawTitleBar(width int) {
 // drawSearchBar draws the search/filter bar
 func (t *TUI) drawSearchBar(width, y int) {
 	searchText := fmt.Sprintf(" 🔍 Filter: %s ", t.filter)
+	if t.search != nil {
+		searchText = fmt.Sprintf(" 🔍 /%s_ (%d matches) | Enter: Keep | Esc: Cancel ", t.filter, len(t.getFilteredResources()))
+	}
+	if t.notReadyFilter {
+		searchText += "[not ready] "
+	}
 	if len(searchText) < width {
 		searchText += strings.Repeat(" ", width-len(searchText))
 	}
@@ -1148,13 +1915,13 @@ func (t *TUI) formatPodTableLine(pod v1.Pod, colWidths []int) string {
 		name = name[:colWidths[0]-3] + "..."
 	}
 
-	status := string(pod.Status.Phase)
+	status := getPodStatus(pod)
 	status = fmt.Sprintf("%-*s", colWidths[1], status)
 
 	ready := t.getReadyCount(pod)
 	ready = fmt.Sprintf("%-*s", colWidths[2], ready)
 
-	age := t.formatDuration(time.Since(pod.CreationTimestamp.Time))
+	age := t.formatAge(pod.CreationTimestamp)
 	age = fmt.Sprintf("%-*s", colWidths[3], age)
 
 	node := pod.Spec.NodeName
@@ -1199,16 +1966,36 @@ func (t *TUI) getFilteredResources() []interface{} {
 		for _, ns := range t.namespaces {
 			resources = append(resources, ns)
 		}
+	case ResourceNodes:
+		for _, node := range t.nodes {
+			resources = append(resources, node)
+		}
+	case ResourceCRDs:
+		for _, crd := range t.crds {
+			resources = append(resources, crd)
+		}
+	case ResourceStatefulSets:
+		for _, sts := range t.statefulSets {
+			resources = append(resources, sts)
+		}
+	case ResourcePVCs:
+		for _, pvc := range t.pvcs {
+			resources = append(resources, pvc)
+		}
+	case ResourceDaemonSets:
+		for _, ds := range t.daemonSets {
+			resources = append(resources, ds)
+		}
 	}
 
 	// Apply filters
-	if t.filter == "" && !t.filterMode {
+	if t.filter == "" && !t.filterMode && !t.notReadyFilter {
 		return resources
 	}
 
 	var filtered []interface{}
 	for _, resource := range resources {
-		if t.matchesFilter(resource) {
+		if t.matchesFilter(resource) && t.matchesNotReady(resource) {
 			filtered = append(filtered, resource)
 		}
 	}
@@ -1308,10 +2095,20 @@ func (t *TUI) getResourceName(resource interface{}) string {
 		return r.Name
 	case v1.Service:
 		return r.Name
-	case v1.ConfigMap:
+	case k8s.ConfigMapSummary:
 		return r.Name
 	case v1.Namespace:
 		return r.Name
+	case v1.Node:
+		return r.Name
+	case k8s.CRD:
+		return r.Name
+	case appsv1.StatefulSet:
+		return r.Name
+	case v1.PersistentVolumeClaim:
+		return r.Name
+	case appsv1.DaemonSet:
+		return r.Name
 	default:
 		return ""
 	}
@@ -1325,18 +2122,25 @@ func (t *TUI) getResourceColumnValue(resource interface{}, colIndex int) string
 		case 0:
 			return r.Name
 		case 1:
-			return string(r.Status.Phase)
+			return getPodStatus(r)
 		case 2:
 			return t.getReadyCount(r)
 		case 3:
-			return t.formatDuration(time.Since(r.CreationTimestamp.Time))
+			return t.formatAge(r.CreationTimestamp)
 		case 4:
+			return t.formatModified(&r, time.Now())
+		case 5:
 			return r.Spec.NodeName
+		case 6:
+			if r.Status.PodIP == "" {
+				return "<none>"
+			}
+			return r.Status.PodIP
 		}
 	case appsv1.Deployment:
 		switch colIndex {
 		case 0:
-			return r.Name
+			return getDeploymentName(r)
 		case 1:
 			return fmt.Sprintf("%d/%d", r.Status.ReadyReplicas, r.Status.Replicas)
 		case 2:
@@ -1344,7 +2148,9 @@ func (t *TUI) getResourceColumnValue(resource interface{}, colIndex int) string
 		case 3:
 			return fmt.Sprintf("%d", r.Status.AvailableReplicas)
 		case 4:
-			return t.formatDuration(time.Since(r.CreationTimestamp.Time))
+			return t.formatAge(r.CreationTimestamp)
+		case 5:
+			return t.formatModified(&r, time.Now())
 		}
 	case v1.Service:
 		switch colIndex {
@@ -1369,46 +2175,175 @@ func (t *TUI) getResourceColumnValue(resource interface{}, colIndex int) string
 			}
 			return ports
 		}
-	case v1.ConfigMap:
+	case k8s.ConfigMapSummary:
 		switch colIndex {
 		case 0:
 			return r.Name
 		case 1:
-			return fmt.Sprintf("%d", len(r.Data)+len(r.BinaryData))
+			return fmt.Sprintf("%d", len(r.Keys))
 		case 2:
-			return t.formatDuration(time.Since(r.CreationTimestamp.Time))
+			return formatSize(r.Size)
+		case 3:
+			return t.formatAge(r.CreationTimestamp)
 		}
 	case v1.Namespace:
+		termination := k8s.NamespaceTerminationStatus(&r, time.Now())
 		switch colIndex {
 		case 0:
 			return r.Name
 		case 1:
+			if termination != nil {
+				return fmt.Sprintf("%s %s", r.Status.Phase, util.HumanDuration(time.Duration(termination.Seconds)*time.Second))
+			}
 			return string(r.Status.Phase)
 		case 2:
-			return t.formatDuration(time.Since(r.CreationTimestamp.Time))
+			return t.formatAge(r.CreationTimestamp)
+		case 3:
+			if termination == nil {
+				return ""
+			}
+			return formatNamespaceBlockers(termination.Blockers)
+		}
+	case v1.Node:
+		switch colIndex {
+		case 0:
+			if badges := nodePressureBadgeText(r); badges != "" {
+				return r.Name + " " + badges
+			}
+			return r.Name
+		case 1:
+			return getNodeStatus(r)
+		case 2:
+			return getNodeRoles(r)
+		case 3:
+			return t.formatAge(r.CreationTimestamp)
+		case 4:
+			return r.Status.NodeInfo.KubeletVersion
+		case 5:
+			return t.nodeUsageColumn(r, true)
+		case 6:
+			return t.nodeUsageColumn(r, false)
+		}
+	case k8s.CRD:
+		switch colIndex {
+		case 0:
+			return r.Name
+		case 1:
+			return r.Group
+		case 2:
+			return r.Version
+		case 3:
+			return r.Scope
+		case 4:
+			return t.formatAge(r.CreationTimestamp)
+		}
+	case appsv1.StatefulSet:
+		replicas := int32(1)
+		if r.Spec.Replicas != nil {
+			replicas = *r.Spec.Replicas
+		}
+		switch colIndex {
+		case 0:
+			return r.Name
+		case 1:
+			return fmt.Sprintf("%d/%d", r.Status.ReadyReplicas, replicas)
+		case 2:
+			return fmt.Sprintf("%d", r.Status.UpdatedReplicas)
+		case 3:
+			return r.Spec.ServiceName
+		case 4:
+			return t.formatAge(r.CreationTimestamp)
+		}
+	case v1.PersistentVolumeClaim:
+		switch colIndex {
+		case 0:
+			return r.Name
+		case 1:
+			return string(r.Status.Phase)
+		case 2:
+			return pvcVolume(r)
+		case 3:
+			return pvcCapacity(r)
+		case 4:
+			return pvcStorageClass(r)
+		case 5:
+			return t.formatAge(r.CreationTimestamp)
+		}
+	case appsv1.DaemonSet:
+		switch colIndex {
+		case 0:
+			return r.Name
+		case 1:
+			return fmt.Sprintf("%d", r.Status.DesiredNumberScheduled)
+		case 2:
+			return fmt.Sprintf("%d", r.Status.NumberReady)
+		case 3:
+			return fmt.Sprintf("%d", r.Status.UpdatedNumberScheduled)
+		case 4:
+			return fmt.Sprintf("%d", r.Status.NumberAvailable)
+		case 5:
+			return t.formatAge(r.CreationTimestamp)
 		}
 	}
 	return ""
 }
 
+// formatNamespaceBlockers summarises what keeps a namespace from being deleted,
+// e.g. "pods(2), example.com/cleanup(1)"
+func formatNamespaceBlockers(blockers []k8s.NamespaceBlocker) string {
+	var parts []string
+	for _, blocker := range blockers {
+		name := blocker.Resource
+		if name == "" {
+			name = blocker.Finalizer
+		}
+		parts = append(parts, fmt.Sprintf("%s(%d)", name, blocker.Instances))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // getTableHeaders returns table headers for the current resource type
 func (t *TUI) getTableHeaders() []string {
+	age := t.timestamps.Header()
 	switch t.currentView {
 	case ResourcePods:
-		return []string{"Name", "Status", "Ready", "Age", "Node"}
+		if t.showPodIPColumn() {
+			return []string{"Name", "Status", "Ready", age, "Modified", "Node", "Pod IP"}
+		}
+		return []string{"Name", "Status", "Ready", age, "Modified", "Node"}
 	case ResourceDeployments:
-		return []string{"Name", "Ready", "Up-to-date", "Available", "Age"}
+		return []string{"Name", "Ready", "Up-to-date", "Available", age, "Modified"}
 	case ResourceServices:
 		return []string{"Name", "Type", "Cluster-IP", "External-IP", "Ports"}
 	case ResourceConfigMaps:
-		return []string{"Name", "Data", "Age"}
+		return []string{"Name", "Data", "Size", age}
 	case ResourceNamespaces:
-		return []string{"Name", "Status", "Age"}
+		return []string{"Name", "Status", age, "Blocking"}
+	case ResourceNodes:
+		return []string{"Name", "Status", "Roles", age, "Version", "CPU%", "Mem%"}
+	case ResourceCRDs:
+		return []string{"Name", "Group", "Version", "Scope", age}
+	case ResourceStatefulSets:
+		return []string{"Name", "Ready", "Up-to-date", "Service", age}
+	case ResourcePVCs:
+		return []string{"Name", "Status", "Volume", "Capacity", "Storage Class", age}
+	case ResourceDaemonSets:
+		return []string{"Name", "Desired", "Ready", "Up-to-date", "Available", age}
 	default:
-		return []string{"Name", "Status", "Age"}
+		return []string{"Name", "Status", age}
 	}
 }
 
+// showPodIPColumn reports whether the terminal is wider than
+// podIPColumnMinWidth, leaving room for the Pod IP column of the pod list
+func (t *TUI) showPodIPColumn() bool {
+	if t.screen == nil {
+		return false
+	}
+	width, _ := t.screen.Size()
+	return width > podIPColumnMinWidth
+}
+
 // getColumnWidths calculates column widths based on available space
 func (t *TUI) getColumnWidths(totalWidth, numColumns int) []int {
 	if numColumns == 0 {
@@ -1436,24 +2371,20 @@ func (t *TUI) getColumnWidths(totalWidth, numColumns int) []int {
 	return widths
 }
 
-// formatResourceLine formats a resource into a table line
-func (t *TUI) formatResourceLine(resource interface{}, colWidths []int) string {
+// resourceCells returns the value of each column of a resource's row
+func (t *TUI) resourceCells(resource interface{}) []string {
 	headers := t.getTableHeaders()
-	line := "│ "
-
+	cells := make([]string, len(headers))
 	for i := range headers {
-		value := t.getResourceColumnValue(resource, i)
-		if len(value) > colWidths[i] {
-			value = value[:colWidths[i]-3] + "..."
-		}
-		line += fmt.Sprintf("%-*s", colWidths[i], value)
-		if i < len(headers)-1 {
-			line += " │ "
-		}
+		cells[i] = t.getResourceColumnValue(resource, i)
 	}
+	return cells
+}
 
-	line += " │"
-	return line
+// formatResourceLine formats the columns of a resource on screen into a
+// table line
+func (t *TUI) formatResourceLine(resource interface{}, layout tableLayout) string {
+	return layout.row(t.resourceCells(resource))
 }
 
 // drawStatusBar draws the status information bar
@@ -1465,15 +2396,38 @@ func (t *TUI) drawStatusBar(width, y int) {
 	namespaceInfo := fmt.Sprintf("📁 %s", t.namespace)
 	resourceInfo := fmt.Sprintf("🎯 %s: %d/%d", t.currentView.DisplayName(), len(filtered), total)
 	viewModeInfo := fmt.Sprintf("👁️ %s", t.getViewModeName())
+	freshnessInfo := t.freshnessStatus(time.Now())
 
 	// Add filter info if active
 	var filterInfo string
 	if t.filter != "" || t.filterMode {
 		filterInfo = fmt.Sprintf(" | 🔍 '%s'", t.filter)
 	}
+	if t.notReadyFilter {
+		filterInfo += " | 🔍 not ready"
+	}
+
+	// The selected resource is marked when bookmarked
+	if bookmark, ok := t.selectedBookmark(); ok && t.isBookmarked(bookmark) {
+		filterInfo += " | ★ bookmarked"
+	}
+
+	if chaos := t.chaosStatus(); chaos != "" {
+		filterInfo += " | " + chaos
+	}
+
+	if loadErrors := t.loadErrorStatus(); loadErrors != "" {
+		filterInfo += " | " + loadErrors
+	}
+
+	// A thin client names the version of the kgo server it reads from
+	var serverInfo string
+	if source, ok := t.source.(*GRPCSource); ok {
+		serverInfo = " | 🔗 server " + source.ServerVersion()
+	}
 
 	// Combine status parts
-	status := fmt.Sprintf("%s | %s | %s%s", namespaceInfo, resourceInfo, viewModeInfo, filterInfo)
+	status := fmt.Sprintf("%s | %s | %s | %s%s%s", namespaceInfo, resourceInfo, freshnessInfo, viewModeInfo, filterInfo, serverInfo)
 
 	// Truncate if too long
 	if len(status) > width-2 {
@@ -1487,7 +2441,47 @@ func (t *TUI) drawStatusBar(width, y int) {
 
 	// Enhanced styling with gradient-like effect
 	style := tcell.StyleDefault.Background(t.theme.accent).Foreground(tcell.ColorBlack).Bold(true)
+	pressureInfo := t.nodePressureStatus()
+
+	// Say that a retried load succeeded, unless an action has just been done
+	if toast := t.loadToastText(time.Now()); toast != "" {
+		status = toast
+		if len(status) < width {
+			status += strings.Repeat(" ", width-len(status))
+		}
+		style = tcell.StyleDefault.Background(tcell.ColorGreen).Foreground(tcell.ColorBlack).Bold(true)
+	}
+
+	// Show the outcome of the last action in green for a few seconds after it
+	if action := t.actionStatusText(time.Now()); action != "" {
+		status = action
+		if len(status) < width {
+			status += strings.Repeat(" ", width-len(status))
+		}
+		style = tcell.StyleDefault.Background(tcell.ColorGreen).Foreground(tcell.ColorBlack).Bold(true)
+	}
+
+	// Flash the bar red with the latest alert for a few seconds after it fires
+	if time.Now().Before(t.alertFlashUntil) && len(t.notifications) > 0 {
+		latest := t.notifications[len(t.notifications)-1]
+		status = fmt.Sprintf("🔔 %s: %s %s/%s (N: notifications)", latest.Rule, latest.Kind, latest.Namespace, latest.Name)
+		if len(status) < width {
+			status += strings.Repeat(" ", width-len(status))
+		}
+		style = tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite).Bold(true)
+		pressureInfo = ""
+	}
 	t.drawText(0, y, width, status, style)
+
+	// Show the cluster-wide node pressure alert in red at the right
+	if pressureInfo != "" {
+		pressureInfo = " " + pressureInfo + " "
+		x := width - len([]rune(pressureInfo))
+		if x < 0 {
+			x = 0
+		}
+		t.drawText(x, y, width-x, pressureInfo, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite).Bold(true))
+	}
 }
 
 // getViewModeName returns a display name for the current view mode
@@ -1503,6 +2497,24 @@ func (t *TUI) getViewModeName() string {
 		return "Logs"
 	case ViewModeRelationships:
 		return "Relationships"
+	case ViewModeDiff:
+		return "Diff"
+	case ViewModeTopPods:
+		return "Top Pods"
+	case ViewModeDashboard:
+		return "Dashboard"
+	case ViewModeProbeOverride:
+		return "Probe Override"
+	case ViewModeRollout:
+		return "Rollout"
+	case ViewModeClusterInfo:
+		return "Cluster Info"
+	case ViewModeFileBrowser:
+		return "Files"
+	case ViewModeEditor:
+		return "Editor"
+	case ViewModeBookmarks:
+		return "Bookmarks"
 	default:
 		return "Unknown"
 	}
@@ -1519,6 +2531,18 @@ func (t *TUI) getCurrentViewCount() int {
 		return len(t.services)
 	case ResourceConfigMaps:
 		return len(t.configMaps)
+	case ResourceNamespaces:
+		return len(t.namespaces)
+	case ResourceNodes:
+		return len(t.nodes)
+	case ResourceCRDs:
+		return len(t.crds)
+	case ResourceStatefulSets:
+		return len(t.statefulSets)
+	case ResourcePVCs:
+		return len(t.pvcs)
+	case ResourceDaemonSets:
+		return len(t.daemonSets)
 	default:
 		return 0
 	}
@@ -1526,19 +2550,34 @@ func (t *TUI) getCurrentViewCount() int {
 
 // drawFooter draws the help/instruction footer
 func (t *TUI) drawFooter(width, y int) {
-	helpText := " ↑↓ Navigate │ Enter Details │ r Refresh │ d Delete │ c Create │ n Namespace │ / Search │ f Clear Filter │ h Help │ q Quit "
+	helpText := " ↑↓ Navigate │ Enter Details │ r Refresh │ d Delete │ c Create │ n Namespace │ / Search │ f Clear Filter │ ? Help │ q Quit "
 	if len(helpText) > width {
 		helpText = helpText[:width-3] + "..."
 	}
 
 	style := tcell.StyleDefault.Background(tcell.ColorDarkGray).Foreground(tcell.ColorWhite)
 	t.drawText(0, y, width, helpText, style)
+
+	// Grey out what RBAC forbids in the current namespace
+	for _, action := range []struct{ key, verb, resource string }{
+		{"d Delete", "delete", t.viewResource()},
+		{"c Create", "create", t.createResource()},
+	} {
+		if i := strings.Index(helpText, action.key); i >= 0 && t.forbidden(action.verb, action.resource) {
+			x := utf8.RuneCountInString(helpText[:i])
+			t.drawText(x, y, width-x, action.key, style.Foreground(tcell.ColorGray).StrikeThrough(true))
+		}
+	}
 }
 
 // drawDetailsView draws the details view for selected resource
 func (t *TUI) drawDetailsView(width, height int) {
 	resource := t.getSelectedResource()
 	if resource == nil {
+		if err := t.loadErrorOf(t.currentView); err != nil {
+			t.drawText(0, 0, width, loadErrorMessage(err), tcell.StyleDefault.Foreground(tcell.ColorRed))
+			return
+		}
 		t.drawText(0, 0, width, "No resource selected", tcell.StyleDefault)
 		return
 	}
@@ -1549,17 +2588,16 @@ func (t *TUI) drawDetailsView(width, height int) {
 
 	// Details content
 	details := t.getResourceDetails(resource)
+	t.detailsScroll = clampScroll(t.detailsScroll, len(details), height-4)
 	y := 2
-	for _, line := range details {
-		if y >= height-2 {
-			break
-		}
-		t.drawText(0, y, width, line, tcell.StyleDefault)
+	for i := t.detailsScroll; i < len(details) && y < height-2; i++ {
+		t.drawText(0, y, width, details[i], detailsLineStyle(details[i]))
 		y++
 	}
 
 	// Footer
-	footer := " ESC Back │ y YAML │ l Logs (pods only) "
+	logsKey, _, _ := t.schemeKeys()
+	footer := " ESC Back │ ↑↓ Scroll │ y YAML │ " + logsKey + " Logs (pods only) │ D Diff, H Timeline (deployments only) │ P Probe (services only) │ L Full values (configmaps only) │ S Snapshot (PVCs only) "
 	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
 }
 
@@ -1573,11 +2611,15 @@ func (t *TUI) drawYAMLView(width, height int) {
 
 	// Header
 	header := fmt.Sprintf(" 📄 %s YAML ", t.currentView.DisplayName())
+	if t.decodeValues && t.currentView == ResourceConfigMaps {
+		header = fmt.Sprintf(" 📄 %s YAML (values decoded) ", t.currentView.DisplayName())
+	}
 	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
 
 	// YAML content
 	yaml := t.getResourceYAML(resource)
 	lines := strings.Split(yaml, "\n")
+	t.detailsScroll = clampScroll(t.detailsScroll, len(lines), height-4)
 
 	y := 2
 	for i := t.detailsScroll; i < len(lines) && y < height-2; i++ {
@@ -1591,50 +2633,32 @@ func (t *TUI) drawYAMLView(width, height int) {
 
 	// Footer
 	footer := " ESC Back │ ↑↓ Scroll "
+	if t.currentView == ResourceConfigMaps {
+		footer += "│ B Decode base64 values "
+	}
 	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
 }
 
-// drawLogsView draws the logs view for selected pod
-func (t *TUI) drawLogsView(width, height int) {
-	if t.currentView != ResourcePods {
-		t.drawText(0, 0, width, "Logs only available for pods", tcell.StyleDefault)
-		return
-	}
-
-	resource := t.getSelectedResource()
-	pod, ok := resource.(v1.Pod)
+// showDeploymentDiff loads the pod template diff of the selected deployment
+// and switches to the diff view
+func (t *TUI) showDeploymentDiff() {
+	deployment, ok := t.getSelectedResource().(appsv1.Deployment)
 	if !ok {
-		t.drawText(0, 0, width, "No pod selected", tcell.StyleDefault)
 		return
 	}
 
-	// Header
-	header := fmt.Sprintf(" 📋 Pod Logs: %s ", pod.Name)
-	t.drawText(0, 0, width, header, tcell.StyleDefault.Background(t.theme.header).Foreground(tcell.ColorWhite).Bold(true))
-
-	// Logs content (placeholder for now)
-	logs := []string{
-		"Log streaming not yet implemented...",
-		"Use 'kubectl logs' command for now.",
-		"",
-		fmt.Sprintf("Pod: %s", pod.Name),
-		fmt.Sprintf("Namespace: %s", pod.Namespace),
-		fmt.Sprintf("Status: %s", pod.Status.Phase),
+	diff, err := k8s.GetDeploymentTemplateDiff(context.TODO(), t.clientset, t.namespace, deployment.Name)
+	switch {
+	case errors.Is(err, k8s.ErrNoPreviousReplicaSet):
+		diff = "No previous ReplicaSet: this deployment has only been rolled out once."
+	case err != nil:
+		klog.Errorf("Failed to diff deployment %s: %v", deployment.Name, err)
+		diff = fmt.Sprintf("Error: %v", err)
+	case diff == "":
+		diff = "Pod template is unchanged since the previous ReplicaSet."
 	}
 
-	y := 2
-	for i := t.logsScroll; i < len(logs) && y < height-2; i++ {
-		line := logs[i]
-		if len(line) > width {
-			line = line[:width-3] + "..."
-		}
-		t.drawText(0, y, width, line, tcell.StyleDefault)
-		y++
-	}
-
-	// Footer
-	footer := " ESC Back │ ↑↓ Scroll "
-	t.drawText(0, height-1, width, footer, tcell.StyleDefault.Background(t.theme.background).Foreground(t.theme.foreground))
+	t.openDiffViewer(newDiffViewer("Pod Template Diff: "+deployment.Name, diff, ViewModeList))
 }
 
 // drawRelationshipsView draws the relationships view showing resource connections
@@ -1652,6 +2676,7 @@ func (t *TUI) drawRelationshipsView(width, height int) {
 	}
 
 	// Display relationships
+	t.relationshipsScroll = clampScroll(t.relationshipsScroll, len(relationships), height-4)
 	y := 2
 	for i := t.relationshipsScroll; i < len(relationships) && y < height-2; i++ {
 		rel := relationships[i]
@@ -1686,14 +2711,29 @@ func (t *TUI) getResourceDetails(resource interface{}) []string {
 		return t.getDeploymentDetails(r)
 	case v1.Service:
 		return t.getServiceDetails(r)
-	case v1.ConfigMap:
+	case k8s.ConfigMapSummary:
 		return t.getConfigMapDetails(r)
+	case v1.Namespace:
+		return t.getNamespaceDetails(r)
+	case v1.Node:
+		return t.getNodeDetails(r)
+	case k8s.CRD:
+		return t.getCRDDetails(r)
+	case appsv1.StatefulSet:
+		return t.getStatefulSetDetails(r)
+	case v1.PersistentVolumeClaim:
+		return t.getPVCDetails(r)
+	case appsv1.DaemonSet:
+		return t.getDaemonSetDetails(r)
 	}
 	return []string{"Unknown resource type"}
 }
 
 // getResourceYAML returns YAML representation of a resource
 func (t *TUI) getResourceYAML(resource interface{}) string {
+	if summary, ok := resource.(k8s.ConfigMapSummary); ok {
+		resource = t.configMapYAMLObject(summary)
+	}
 	data, err := json.MarshalIndent(resource, "", "  ")
 	if err != nil {
 		return fmt.Sprintf("Error marshaling YAML: %v", err)
@@ -1738,7 +2778,7 @@ func (t *TUI) getPodRelationships() []Relationship {
 
 		// Pod to Service relationship (via selectors)
 		for _, svc := range t.services {
-			if t.podMatchesService(pod, svc) {
+			if k8s.ServiceSelectsPod(svc, pod) {
 				relationships = append(relationships, Relationship{
 					From:         pod.Name,
 					To:           svc.Name,
@@ -1780,7 +2820,7 @@ func (t *TUI) getServiceRelationships() []Relationship {
 	for _, svc := range t.services {
 		// Find pods exposed by this service
 		for _, pod := range t.pods {
-			if t.podMatchesService(pod, svc) {
+			if k8s.ServiceSelectsPod(svc, pod) {
 				relationships = append(relationships, Relationship{
 					From:         svc.Name,
 					To:           pod.Name,
@@ -1815,18 +2855,8 @@ func (t *TUI) getConfigMapRelationships() []Relationship {
 	return relationships
 }
 
-// podMatchesService checks if a pod matches a service selector
-func (t *TUI) podMatchesService(pod v1.Pod, svc v1.Service) bool {
-	for key, value := range svc.Spec.Selector {
-		if pod.Labels[key] != value {
-			return false
-		}
-	}
-	return true
-}
-
 // podUsesConfigMap checks if a pod uses a configmap
-func (t *TUI) podUsesConfigMap(pod v1.Pod, cm v1.ConfigMap) bool {
+func (t *TUI) podUsesConfigMap(pod v1.Pod, cm k8s.ConfigMapSummary) bool {
 	// Check volumes
 	for _, volume := range pod.Spec.Volumes {
 		if volume.ConfigMap != nil && volume.ConfigMap.Name == cm.Name {
@@ -1848,15 +2878,47 @@ func (t *TUI) podUsesConfigMap(pod v1.Pod, cm v1.ConfigMap) bool {
 
 // getPodDetails returns formatted details for a pod
 func (t *TUI) getPodDetails(pod v1.Pod) []string {
-	return []string{
+	details := []string{
 		fmt.Sprintf("Name: %s", pod.Name),
 		fmt.Sprintf("Namespace: %s", pod.Namespace),
-		fmt.Sprintf("Status: %s", pod.Status.Phase),
+		fmt.Sprintf("Status: %s", getPodStatus(pod)),
 		fmt.Sprintf("Node: %s", pod.Spec.NodeName),
-		fmt.Sprintf("Created: %s", pod.CreationTimestamp.Format("2006-01-02 15:04:05")),
-		"",
-		"Containers:",
+		fmt.Sprintf("IPs: %s", formatPodIPs(pod)),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(pod.CreationTimestamp)),
+	}
+	if services := k8s.FindServicesForPod(pod, t.services); len(services) > 0 {
+		names := make([]string, 0, len(services))
+		for _, svc := range services {
+			names = append(names, svc.Name)
+		}
+		details = append(details, fmt.Sprintf("Exposed by: %s", strings.Join(names, ", ")))
+	}
+	details = append(details, t.initContainerDetails(pod)...)
+	details = append(details, t.readinessDetails(pod)...)
+	details = append(details, t.usageDetails(pod)...)
+	details = append(details, t.networkDetails(pod)...)
+	details = append(details, t.imageDetails(pod)...)
+	details = append(details, t.topologyDetails(pod)...)
+	details = append(details, t.tolerationDetails(pod)...)
+	details = append(details, t.securityDetails(pod)...)
+	return append(details, gateDetails(pod)...)
+}
+
+// formatPodIPs lists every IP of a pod, e.g. "10.0.0.5, fd00::5" on a
+// dual-stack cluster, falling back to the primary IP for API servers that do
+// not set PodIPs
+func formatPodIPs(pod v1.Pod) string {
+	ips := make([]string, 0, len(pod.Status.PodIPs))
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
 	}
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	if len(ips) == 0 {
+		return "<none>"
+	}
+	return strings.Join(ips, ", ")
 }
 
 // getDeploymentDetails returns formatted details for a deployment
@@ -1868,40 +2930,64 @@ func (t *TUI) getDeploymentDetails(dep appsv1.Deployment) []string {
 		fmt.Sprintf("Ready: %d", dep.Status.ReadyReplicas),
 		fmt.Sprintf("Available: %d", dep.Status.AvailableReplicas),
 		fmt.Sprintf("Updated: %d", dep.Status.UpdatedReplicas),
-		fmt.Sprintf("Created: %s", dep.CreationTimestamp.Format("2006-01-02 15:04:05")),
+		pauseDetails(dep, time.Now()),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(dep.CreationTimestamp)),
 	}
 }
 
 // getServiceDetails returns formatted details for a service
 func (t *TUI) getServiceDetails(svc v1.Service) []string {
-	return []string{
+	details := []string{
 		fmt.Sprintf("Name: %s", svc.Name),
 		fmt.Sprintf("Namespace: %s", svc.Namespace),
 		fmt.Sprintf("Type: %s", svc.Spec.Type),
 		fmt.Sprintf("Cluster IP: %s", svc.Spec.ClusterIP),
-		fmt.Sprintf("Created: %s", svc.CreationTimestamp.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(svc.CreationTimestamp)),
 		"",
 		"Ports:",
 	}
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		details = append(details[:3], append(t.externalNameDetails(svc), details[3:]...)...)
+	}
+	return details
 }
 
-// getConfigMapDetails returns formatted details for a configmap
-func (t *TUI) getConfigMapDetails(cm v1.ConfigMap) []string {
+// getNamespaceDetails returns formatted details for a namespace, including
+// what is blocking its deletion when it is terminating
+func (t *TUI) getNamespaceDetails(ns v1.Namespace) []string {
 	details := []string{
-		fmt.Sprintf("Name: %s", cm.Name),
-		fmt.Sprintf("Namespace: %s", cm.Namespace),
-		fmt.Sprintf("Data items: %d", len(cm.Data)),
-		fmt.Sprintf("Binary data items: %d", len(cm.BinaryData)),
-		fmt.Sprintf("Created: %s", cm.CreationTimestamp.Format("2006-01-02 15:04:05")),
-		"",
-		"Data keys:",
+		fmt.Sprintf("Name: %s", ns.Name),
+		fmt.Sprintf("Status: %s", ns.Status.Phase),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(ns.CreationTimestamp)),
+	}
+	if k8s.IsDeletionProtected(&ns) {
+		details = append(details, fmt.Sprintf("Deletion protection: %s %s", lockGlyph, k8s.DeletionProtectedAnnotation))
 	}
 
-	for key := range cm.Data {
-		details = append(details, fmt.Sprintf("  - %s", key))
+	termination := k8s.NamespaceTerminationStatus(&ns, time.Now())
+	if termination == nil {
+		return append(details, t.permissionsDetails(ns.Name)...)
+	}
+	details = append(details,
+		fmt.Sprintf("Deleted: %s (terminating for %s)", t.formatTimestamp(termination.DeletionTimestamp), util.HumanDuration(time.Duration(termination.Seconds)*time.Second)),
+		"",
+		"Blocking deletion:",
+	)
+	if len(termination.Blockers) == 0 && len(termination.Failures) == 0 {
+		details = append(details, "  nothing reported by the namespace controller yet")
+	}
+	for _, blocker := range termination.Blockers {
+		if blocker.Resource != "" {
+			details = append(details, fmt.Sprintf("  - %d %s remaining", blocker.Instances, blocker.Resource))
+		} else {
+			details = append(details, fmt.Sprintf("  - %d objects holding finalizer %s", blocker.Instances, blocker.Finalizer))
+		}
+	}
+	for _, failure := range termination.Failures {
+		details = append(details, fmt.Sprintf("  - %s", failure))
 	}
 
-	return details
+	return append(details, t.permissionsDetails(ns.Name)...)
 }
 
 // drawHelpScreen shows the help screen
@@ -1913,19 +2999,35 @@ func (t *TUI) drawHelpScreen(width, height int) {
 	titleBar := strings.Repeat("═", padding) + title + strings.Repeat("═", width-padding-len(title))
 	t.drawText(0, 0, width, titleBar, tcell.StyleDefault.Background(tcell.ColorDarkBlue).Foreground(tcell.ColorWhite).Bold(true))
 
-	helpLines := []string{
-		"",
-		" Navigation:",
-		"   ↑↓, j/k     Navigate through resources",
-		"   Tab         Switch between resource types",
-		"   1-4         Jump to: Pods, Deployments, Services, ConfigMaps",
+	logsKey, imagesKey, helpKey := t.schemeKeys()
+	helpLines := append([]string{"", " Navigation:"}, t.navigationHelpLines()...)
+	helpLines = t.availableHelpLines(append(helpLines,
+		"   1-9, 0      Jump to: Pods, Deployments, Services, ConfigMaps, Namespaces, Nodes, CRDs, StatefulSets, PVCs, DaemonSets",
 		"   Enter       Show resource details",
 		"",
 		" View Modes:",
 		"   v           Cycle view modes (List → Details → YAML → Logs → Relationships)",
 		"   y           YAML view",
-		"   l           Logs view (pods only)",
+		helpLine(logsKey, "Logs view (pod details)"),
+		"   T           Reopen the log stream from a number of lines back (logs view)",
 		"   r           Relationships view",
+		"   D           Pod template diff against the previous rollout (deployment details)",
+		"   /, n, N, S  Search a diff, go to the next/previous match, show it side by side (diff view)",
+		"   H           Timeline of condition transitions from events (deployment details)",
+		"   p           TCP probe of the cluster IP and ports (service details)",
+		"   W           Check what you can do in the namespace (namespace details)",
+		"   o           Disable container probes via the owning deployment (pod details)",
+		"   P           Pause or resume the rollouts of a deployment, after confirmation",
+		"   =           Scale a deployment or statefulset on a replicas slider, with the requests it adds up to",
+		"   O           Rollout restart a deployment, statefulset or daemonset",
+		helpLine(imagesKey, "Look up image sizes and layers in the registry (pod details)"),
+		"   E           Load values over 64KiB in full (configmap details and YAML)",
+		"   U           Show base64 values decoded, binary ones as hex (configmap YAML)",
+		"   L           Edit labels and annotations (pods, deployments, services and configmaps)",
+		"   C, M        Top pods by CPU or memory usage (pods list; switches the sort there)",
+		"   `, F1       Cluster overview; Enter jumps to the selected section's tab",
+		"   b           Bookmark the selected pod, deployment, service or configmap, or remove its bookmark",
+		"   B           Bookmarks of all namespaces with their status; Enter jumps to one",
 		"",
 		" Split Pane:",
 		"   s           Toggle split-pane mode",
@@ -1933,17 +3035,32 @@ func (t *TUI) drawHelpScreen(width, height int) {
 		"",
 		" Actions:",
 		"   r, F5       Refresh all resources",
+		"   R           Retry the current tab's load after its retries failed",
 		"   d           Delete selected resource",
-		"   c           Create new resource",
+		"   c           Create new pod; deployment wizard, namespace, configmap or service form in those views",
+		"   F           Browse the files of the pod's container: Enter opens, Backspace goes up, c copies, e edits (pod details)",
+		"   V           Snapshot the PVC with a VolumeSnapshotClass (PVC details)",
+		"   A           Nodes whose taints keep the pod off them, and tolerations to add (pod details)",
+		"   x           Debug with an ephemeral busybox container (ui.debugImage) and follow its logs (pod details)",
 		"   n           Change namespace",
+		"   K           Copy the kubectl equivalent of the last action",
+		"   X           Chaos menu: fail a liveness probe for a while (deployment details)",
+		"   I, Ctrl+I   Show the API server, context, user, version, latency, searchable resource types and feature gates",
+		"   F12         Toggle the debug overlay (frame time, events/sec, goroutines)",
+		"   N           Show alert notifications",
 		"",
 		" Search & Filter:",
-		"   /           Search resources by name",
-		"   f           Clear current filter",
+		"   /           Filter by name as you type (Enter: keep, Esc: cancel)",
+		"   f           Clear current filter, including the dashboard's not-ready filter",
+		"",
+		" Commands:",
+		"   :snapshot <path>  Write the namespace's objects to a JSON/YAML snapshot",
+		"   :logs             Show the end of the kgo log file",
 		"",
 		" General:",
-		"   ?, h        Show this help",
+		helpLine(helpKey, "Show this help"),
 		"   t, T        Cycle through color themes",
+		"   z           Show times as ages, absolute timestamps or both",
 		"   q, Esc      Quit application",
 		"",
 		" Status Colors:",
@@ -1953,7 +3070,8 @@ func (t *TUI) drawHelpScreen(width, height int) {
 		"   🔵 Blue     Succeeded/Complete",
 		"",
 		" Press any key to return...",
-	}
+	))
+	helpLines = append(t.clientInfoHelpLines(), helpLines...)
 
 	y := 2
 	for _, line := range helpLines {
@@ -1965,15 +3083,87 @@ func (t *TUI) drawHelpScreen(width, height int) {
 	}
 }
 
-// drawLoadingScreen shows a loading screen
+// drawNotifications shows fired alerts, newest first
+func (t *TUI) drawNotifications(width, height int) {
+	t.screen.Clear()
+
+	title := fmt.Sprintf(" 🔔 Notifications (%d) ", len(t.notifications))
+	padding := (width - len(title)) / 2
+	if padding < 0 {
+		padding = 0
+	}
+	titleBar := strings.Repeat("═", padding) + title + strings.Repeat("═", padding)
+	t.drawText(0, 0, width, titleBar, tcell.StyleDefault.Background(tcell.ColorDarkRed).Foreground(tcell.ColorWhite).Bold(true))
+
+	if len(t.notifications) == 0 {
+		t.drawText(1, 2, width-1, "No alerts have fired", tcell.StyleDefault)
+	}
+
+	y := 2
+	for i := len(t.notifications) - 1; i >= 0 && y < height-2; i-- {
+		event := t.notifications[i]
+		line := fmt.Sprintf("%s  %-20s %s %s/%s (%s)",
+			event.Time.Format("15:04:05"), event.Rule, event.Kind, event.Namespace, event.Name, event.Condition)
+		t.drawText(1, y, width-1, line, tcell.StyleDefault)
+		y++
+	}
+
+	t.drawText(1, height-1, width-1, "Press any key to return...", tcell.StyleDefault)
+}
+
+// loadingResourceTypes are the resource types loaded by refreshData, in the
+// order their progress is shown
+var loadingResourceTypes = []ResourceType{
+	ResourcePods,
+	ResourceDeployments,
+	ResourceServices,
+	ResourceConfigMaps,
+	ResourceNamespaces,
+	ResourceNodes,
+	ResourceCRDs,
+	ResourceStatefulSets,
+	ResourcePVCs,
+	ResourceDaemonSets,
+}
+
+// drawLoadingScreen shows a loading screen with one progress bar per resource type
 func (t *TUI) drawLoadingScreen(width, height int) {
 	t.screen.Clear()
 
-	loadingText := " 🔄 Loading Kubernetes resources..."
-	y := height / 2
-	x := (width - len(loadingText)) / 2
+	barWidth := width - 4
+	if barWidth > 60 {
+		barWidth = 60
+	}
+	x := (width - barWidth) / 2
+	y := height/2 - (len(loadingResourceTypes)+3)/2
+	if y < 0 {
+		y = 0
+	}
+
+	titleStyle := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorYellow).Bold(true)
+	barStyle := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorAqua)
+	doneStyle := tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorGreen)
+
+	loadingText := fmt.Sprintf("%c Loading Kubernetes resources...", t.spinner.Next())
+	t.drawText(x, y, barWidth, loadingText, titleStyle)
 
-	t.drawText(x, y, width-x, loadingText, tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorYellow).Bold(true))
+	done := 0
+	for _, rt := range loadingResourceTypes {
+		if t.loadedResources[rt] {
+			done++
+		}
+	}
+	NewProgressBar(done, len(loadingResourceTypes), "Resources").Draw(t.screen, x, y+2, barWidth, titleStyle)
+
+	for i, rt := range loadingResourceTypes {
+		style := barStyle
+		current := 0
+		if t.loadedResources[rt] {
+			style = doneStyle
+			current = 1
+		}
+		NewProgressBar(current, 1, rt.DisplayName()).Draw(t.screen, x, y+4+i, barWidth, style)
+	}
 }
 
 // formatPodLine formats a pod into a table line
@@ -1997,8 +3187,7 @@ func (t *TUI) formatPodLine(pod v1.Pod) string {
 	ready := fmt.Sprintf("%d/%d", readyContainers, totalContainers)
 	ready = fmt.Sprintf("%-7s", ready)
 
-	age := time.Since(pod.CreationTimestamp.Time)
-	ageStr := t.formatDuration(age)
+	ageStr := t.formatAge(pod.CreationTimestamp)
 	ageStr = fmt.Sprintf("%-11s", ageStr)
 
 	node := pod.Spec.NodeName
@@ -2012,19 +3201,22 @@ func (t *TUI) formatPodLine(pod v1.Pod) string {
 	return name + status + ready + ageStr + node
 }
 
-// formatDuration formats a duration into a human-readable string
-func (t *TUI) formatDuration(d time.Duration) string {
-	if d.Hours() >= 24 {
-		days := int(d.Hours() / 24)
-		return fmt.Sprintf("%dd", days)
-	}
-	if d.Hours() >= 1 {
-		return fmt.Sprintf("%.0fh", d.Hours())
-	}
-	if d.Minutes() >= 1 {
-		return fmt.Sprintf("%.0fm", d.Minutes())
-	}
-	return fmt.Sprintf("%.0fs", d.Seconds())
+// formatAge formats a creation time for an Age column, as the timestamp
+// format toggled with 'z' says
+func (t *TUI) formatAge(created metav1.Time) string {
+	return t.timestamps.Short(created.Time, time.Now())
+}
+
+// formatTimestamp formats a time for the details view, as the timestamp
+// format toggled with 'z' says
+func (t *TUI) formatTimestamp(at metav1.Time) string {
+	return t.timestamps.Timestamp(at.Time, time.Now())
+}
+
+// nextTimestampFormat switches ages and timestamps to the next of relative,
+// absolute and both
+func (t *TUI) nextTimestampFormat() {
+	t.timestamps.Format = timefmt.Next(t.timestamps.Format)
 }
 
 // drawText draws text at the specified position
@@ -2052,7 +3244,7 @@ func (t *TUI) showPodDetails() {
 		fmt.Sprintf("Namespace: %s", pod.Namespace),
 		fmt.Sprintf("Status: %s", pod.Status.Phase),
 		fmt.Sprintf("Node: %s", pod.Spec.NodeName),
-		fmt.Sprintf("Created: %s", pod.CreationTimestamp.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("Created: %s", t.formatTimestamp(pod.CreationTimestamp)),
 		"",
 		"Containers:",
 	}
@@ -2117,7 +3309,16 @@ func (t *TUI) deleteSelectedPod() {
 // changeNamespace allows changing the current namespace
 func (t *TUI) changeNamespace() {
 	// Fetch available namespaces
-	namespaces, err := k8s.ListNamespaces(t.clientset)
+	namespaces, err := t.data().ListNamespaces()
+	if apierrors.IsForbidden(err) {
+		// Users allowed into only some namespaces type the one to show
+		if namespace, ok := t.promptNamespace(); ok && namespace != t.namespace {
+			t.namespace = namespace
+			t.refreshData()
+			t.saveSession()
+		}
+		return
+	}
 	if err != nil {
 		// Show error message
 		t.screen.Clear()
@@ -2175,6 +3376,7 @@ func (t *TUI) changeNamespace() {
 				if newNamespace != t.namespace {
 					t.namespace = newNamespace
 					t.refreshData()
+					t.saveSession()
 				}
 				return
 			case tcell.KeyEscape:
@@ -2192,67 +3394,58 @@ func (t *TUI) changeNamespace() {
 	}
 }
 
-// getReadyCount returns the ready container count as a string
+// getReadyCount returns the ready count of the app containers of a pod as a
+// string, e.g. "1/2". Init containers, sidecars included, are left out, and
+// app containers without a status yet count as not ready.
 func (t *TUI) getReadyCount(pod v1.Pod) string {
 	readyContainers := 0
-	totalContainers := len(pod.Status.ContainerStatuses)
 	for _, cs := range pod.Status.ContainerStatuses {
 		if cs.Ready {
 			readyContainers++
 		}
 	}
+	totalContainers := max(len(pod.Spec.Containers), len(pod.Status.ContainerStatuses))
 	return fmt.Sprintf("%d/%d", readyContainers, totalContainers)
 }
 
-// searchDialog shows a search input dialog
-func (t *TUI) searchDialog() {
-	input := t.filter
-	cursor := len(input)
-
-	for {
-		t.screen.Clear()
-
-		prompt := "Search pods (current: " + t.filter + "): " + input
-		t.drawText(0, 0, 80, prompt, tcell.StyleDefault)
-
-		// Show cursor
-		if cursor < len(input) {
-			t.screen.SetContent(len(prompt)-len(input)+cursor, 0, '_', nil, tcell.StyleDefault)
-		} else {
-			t.drawText(len(prompt), 0, 1, "_", tcell.StyleDefault)
-		}
-
-		t.screen.Show()
-
-		event := t.screen.PollEvent()
-		switch ev := event.(type) {
-		case *tcell.EventKey:
-			switch ev.Key() {
-			case tcell.KeyEnter:
-				t.filter = input
-				t.selected = 0 // Reset selection
-				return
-			case tcell.KeyEscape:
-				return
-			case tcell.KeyBackspace, tcell.KeyBackspace2:
-				if len(input) > 0 {
-					input = input[:len(input)-1]
-					cursor--
-					if cursor < 0 {
-						cursor = 0
-					}
-				}
-			case tcell.KeyRune:
-				input += string(ev.Rune())
-				cursor++
-			}
+// searchPrompt is the state of the '/' prompt: the filter and selection it
+// started from, for Escape to restore
+type searchPrompt struct {
+	previous         string
+	previousSelected int
+}
+
+// openSearch opens the '/' prompt. It is an input mode of the main loop
+// rather than a dialog of its own, so the table under the search bar keeps
+// taking data updates while the query is typed.
+func (t *TUI) openSearch() {
+	t.search = &searchPrompt{previous: t.filter, previousSelected: t.selected}
+}
+
+// handleSearchKey filters the list as the query is typed. Enter keeps the
+// filter and Escape restores the previous one.
+func (t *TUI) handleSearchKey(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		t.search = nil
+	case tcell.KeyEscape:
+		t.filter, t.selected = t.search.previous, t.search.previousSelected
+		t.search = nil
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if runes := []rune(t.filter); len(runes) > 0 {
+			t.filter = string(runes[:len(runes)-1])
+			t.selected = 0
 		}
+	case tcell.KeyRune:
+		t.filter += string(ev.Rune())
+		t.selected = 0
 	}
 }
 
 // clearFilter clears the current search filter
 func (t *TUI) clearFilter() {
 	t.filter = ""
+	t.notReadyFilter = false
 	t.selected = 0
 }
 
@@ -2332,6 +3525,10 @@ func (t *TUI) getCursorText(active bool, cursor, length int) string {
 
 // createPod creates a new pod with the given name and image
 func (t *TUI) createPod(name, image string) {
+	if !t.confirmProtectedAction("create", "pod", name) {
+		return
+	}
+
 	t.loading = true
 	t.draw()
 	t.screen.Show()
@@ -2356,7 +3553,10 @@ func (t *TUI) createPod(name, image string) {
 		},
 	}
 
-	_, err := k8s.CreatePod(t.clientset, t.namespace, pod)
+	err := t.imagePolicy.CheckObject(pod)
+	if err == nil {
+		_, err = k8s.CreatePod(t.clientset, t.namespace, pod)
+	}
 	t.loading = false
 
 	if err != nil {
@@ -2366,7 +3566,66 @@ func (t *TUI) createPod(name, image string) {
 		t.screen.Show()
 		time.Sleep(3 * time.Second)
 	} else {
+		t.recordAction(fmt.Sprintf("Created pod '%s'", name), k8s.KubectlCreate(t.namespace, pod))
 		// Reload pods
 		t.loadPods()
 	}
 }
+
+// confirmProtectedAction asks the user to type the resource name before a
+// mutating action in a protected namespace. It returns true immediately for
+// namespaces that are not protected.
+func (t *TUI) confirmProtectedAction(action, resourceType, name string) bool {
+	return t.confirmProtectedActionIn(t.namespace, action, resourceType, name)
+}
+
+// confirmProtectedActionIn is confirmProtectedAction for a resource in a
+// namespace other than the current one
+func (t *TUI) confirmProtectedActionIn(namespace, action, resourceType, name string) bool {
+	return t.confirmProtectedActionWarned(namespace, action, resourceType, name, nil)
+}
+
+// confirmProtectedActionWarned is confirmProtectedActionIn showing warnings
+// about the action below the prompt
+func (t *TUI) confirmProtectedActionWarned(namespace, action, resourceType, name string, warnings []string) bool {
+	if !t.guard.IsProtected(namespace) {
+		return true
+	}
+	return t.confirmByTyping(fmt.Sprintf("⚠ Namespace '%s' is protected", namespace), action, resourceType, name, warnings)
+}
+
+// confirmByTyping asks to confirm an action on a resource by typing its name
+// under title, reporting whether the name was typed exactly
+func (t *TUI) confirmByTyping(title, action, resourceType, name string, warnings []string) bool {
+	input := ""
+	for {
+		t.screen.Clear()
+
+		warnStyle := tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite).Bold(true)
+		t.drawText(0, 0, 80, title, warnStyle)
+		t.drawText(0, 2, 80, fmt.Sprintf("Type the %s name '%s' to confirm %s:", resourceType, name, action), tcell.StyleDefault)
+		t.drawText(0, 3, 80, "> "+input+"_", tcell.StyleDefault.Bold(true))
+		t.drawText(0, 5, 80, "Enter: Confirm | Esc: Cancel", tcell.StyleDefault)
+		for i, warning := range warnings {
+			t.drawText(0, 7+i, 100, "⚠ "+warning, deletionWarningStyle)
+		}
+		t.screen.Show()
+
+		event := t.screen.PollEvent()
+		switch ev := event.(type) {
+		case *tcell.EventKey:
+			switch ev.Key() {
+			case tcell.KeyEnter:
+				return input == name
+			case tcell.KeyEscape:
+				return false
+			case tcell.KeyBackspace, tcell.KeyBackspace2:
+				if len(input) > 0 {
+					input = input[:len(input)-1]
+				}
+			case tcell.KeyRune:
+				input += string(ev.Rune())
+			}
+		}
+	}
+}
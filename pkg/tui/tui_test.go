@@ -2,12 +2,19 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+	"k8s-dashboard/pkg/k8s"
+	"k8s-dashboard/pkg/timefmt"
 
 	"github.com/gdamore/tcell/v2"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 )
 
@@ -17,7 +24,7 @@ func TestTUIBasicInitialization(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
 
 	// Create TUI instance
-	tui, err := NewTUI(clientset)
+	tui, err := NewTUI(NewClusterSource(clientset), config.DefaultConfig())
 	if err != nil {
 		t.Fatalf("Failed to create TUI instance: %v", err)
 	}
@@ -51,7 +58,7 @@ func TestTUIDataUpdateHandling(t *testing.T) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 1, // Set to 1 so it decrements to 0 and doesn't trigger extra logging
 	}
 
@@ -119,7 +126,7 @@ func TestTUIAsyncDataLoading(t *testing.T) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 	}
 
@@ -146,7 +153,7 @@ func TestTUINavigation(t *testing.T) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 	}
 
@@ -173,6 +180,120 @@ func TestTUINavigation(t *testing.T) {
 	}
 }
 
+// TestTUIIncrementalSearch tests that the '/' prompt narrows the pod list on
+// every key, that Escape restores the previous filter and that Enter keeps
+// the typed one
+func TestTUIIncrementalSearch(t *testing.T) {
+	screen := newTestScreen(t, 120, 30)
+
+	names := []string{"web-1", "web-2", "worker-1", "api-1"}
+	var pods []v1.Pod
+	for _, name := range names {
+		pods = append(pods, v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodRunning}})
+	}
+	tui := &TUI{
+		screen:          screen,
+		config:          config.DefaultConfig(),
+		namespace:       "default",
+		currentView:     ResourcePods,
+		viewMode:        ViewModeList,
+		pods:            pods,
+		theme:           DefaultTheme(),
+		loadedResources: make(map[ResourceType]bool),
+	}
+
+	// press handles a key as the main loop does, then draws the screen
+	press := func(key tcell.Key, r rune) {
+		screen.InjectKey(key, r, tcell.ModNone)
+		ev, ok := screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			t.Fatal("Expected the injected key event")
+		}
+		tui.handleKey(ev)
+		tui.draw()
+		screen.Show()
+	}
+	// visibleRows counts the table rows of the pods, failing when the
+	// prompt does not show the query
+	visibleRows := func(query string) int {
+		text := screenText(screen)
+		if prompt := "/" + query + "_ ("; !strings.Contains(text, prompt) {
+			t.Fatalf("Prompt %q not shown, screen:\n%s", prompt, text)
+		}
+		rows := 0
+		for _, line := range strings.Split(text, "\n") {
+			for _, name := range names {
+				if strings.HasPrefix(line, "│ "+name+" ") {
+					rows++
+				}
+			}
+		}
+		return rows
+	}
+
+	// The prompt starts from the current filter
+	tui.filter = "api"
+	press(tcell.KeyRune, '/')
+	if rows := visibleRows("api"); rows != 1 {
+		t.Errorf("Expected 1 row for the current filter, got %d", rows)
+	}
+	for range "api" {
+		press(tcell.KeyBackspace2, 0)
+	}
+	if rows := visibleRows(""); rows != 4 {
+		t.Errorf("Expected every pod for an empty query, got %d", rows)
+	}
+	steps := []struct {
+		key   rune
+		query string
+		rows  int
+	}{
+		{'w', "w", 3},
+		{'e', "we", 2},
+		{'b', "web", 2},
+		{'-', "web-", 2},
+		{'2', "web-2", 1},
+		{'9', "web-29", 0},
+	}
+	for _, step := range steps {
+		press(tcell.KeyRune, step.key)
+		if rows := visibleRows(step.query); rows != step.rows {
+			t.Errorf("After %q: expected %d rows, got %d", step.query, step.rows, rows)
+		}
+	}
+	if text := screenText(screen); !strings.Contains(text, "/web-29_ (0 matches)") {
+		t.Errorf("Expected the match count in the prompt, got:\n%s", text)
+	}
+	press(tcell.KeyBackspace2, 0)
+	if rows := visibleRows("web-2"); rows != 1 {
+		t.Errorf("Expected 1 row after a backspace, got %d", rows)
+	}
+
+	// Keys that are bindings of the list are typed into the query
+	if tui.viewMode != ViewModeList || tui.currentView != ResourcePods {
+		t.Errorf("Expected the typed keys to stay in the prompt, got view %v/%v", tui.viewMode, tui.currentView)
+	}
+
+	// Escape restores the filter the prompt started from
+	press(tcell.KeyEscape, 0)
+	if tui.filter != "api" || tui.search != nil {
+		t.Errorf("Expected Escape to restore the filter \"api\", got %q (searching %v)", tui.filter, tui.search != nil)
+	}
+
+	// Enter keeps the typed filter
+	press(tcell.KeyRune, '/')
+	for range "api" {
+		press(tcell.KeyBackspace2, 0)
+	}
+	for _, r := range "wor" {
+		press(tcell.KeyRune, r)
+	}
+	press(tcell.KeyEnter, 0)
+	if tui.filter != "wor" || tui.search != nil || len(tui.getFilteredResources()) != 1 {
+		t.Errorf("Expected Enter to keep \"wor\" with one match, got %q", tui.filter)
+	}
+}
+
 // TestTUIFiltering tests filtering functionality
 func TestTUIFiltering(t *testing.T) {
 	clientset := fake.NewSimpleClientset()
@@ -185,7 +306,7 @@ func TestTUIFiltering(t *testing.T) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 		filter:         "",
 	}
@@ -271,7 +392,7 @@ func TestTUIConcurrentDataUpdates(t *testing.T) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 	}
 
@@ -317,7 +438,7 @@ func TestTUIMemoryCleanup(t *testing.T) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 	}
 
@@ -356,7 +477,7 @@ func BenchmarkTUIDataUpdate(b *testing.B) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 	}
 
@@ -389,7 +510,7 @@ func BenchmarkTUIFiltering(b *testing.B) {
 		pods:           []v1.Pod{},
 		deployments:    []appsv1.Deployment{},
 		services:       []v1.Service{},
-		configMaps:     []v1.ConfigMap{},
+		configMaps:     []k8s.ConfigMapSummary{},
 		loadingCounter: 0,
 		filter:         "",
 	}
@@ -413,3 +534,240 @@ func BenchmarkTUIFiltering(b *testing.B) {
 		_ = tui.getFilteredPods()
 	}
 }
+
+// TestTUIProtectedNamespaceConfirmation tests the typed confirmation for protected namespaces
+func TestTUIProtectedNamespaceConfirmation(t *testing.T) {
+	guard, err := k8s.NewNamespaceGuard([]string{"prod-*"})
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+
+	tui, screen := newTestTUI(t, 80, 24)
+	tui.namespace = "prod-eu"
+	tui.guard = guard
+
+	typeKeys := func(text string, final tcell.Key) {
+		typeText(screen, text)
+		screen.InjectKey(final, 0, tcell.ModNone)
+	}
+
+	// A plain 'y' is not enough in a protected namespace
+	typeKeys("y", tcell.KeyEnter)
+	if tui.confirmProtectedAction("delete", "pod", "web") {
+		t.Error("Expected 'y' to be rejected in protected namespace")
+	}
+
+	typeKeys("webx", tcell.KeyBackspace2)
+	screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+	if !tui.confirmProtectedAction("delete", "pod", "web") {
+		t.Error("Expected typed resource name to confirm")
+	}
+
+	typeKeys("web", tcell.KeyEscape)
+	if tui.confirmProtectedAction("delete", "pod", "web") {
+		t.Error("Expected Esc to cancel")
+	}
+
+	// Unprotected namespaces skip the prompt entirely
+	tui.namespace = "default"
+	if !tui.confirmProtectedAction("create", "pod", "web") {
+		t.Error("Expected unprotected namespace to need no confirmation")
+	}
+}
+
+// TestTUIAlertNotifications tests that firing alert rules are recorded and flash the status bar
+func TestTUIAlertNotifications(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Alerts.Rules = []config.AlertRule{{Name: "failed", Kind: "pod", Condition: "phase=Failed"}}
+
+	engine, err := newAlertEngine(cfg)
+	if err != nil {
+		t.Fatalf("Failed to build alert engine: %v", err)
+	}
+
+	tui := &TUI{
+		clientset:      fake.NewSimpleClientset(),
+		config:         cfg,
+		namespace:      "default",
+		currentView:    ResourcePods,
+		loadingCounter: 1,
+		alerts:         engine,
+	}
+
+	tui.handleDataUpdate(&DataUpdate{
+		ResourceType: ResourcePods,
+		Pods: []v1.Pod{
+			{ObjectMeta: metav1.ObjectMeta{Name: "ok", Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "bad", Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodFailed}},
+		},
+	})
+
+	if len(tui.notifications) != 1 || tui.notifications[0].Name != "bad" {
+		t.Fatalf("Expected one notification for pod 'bad', got %+v", tui.notifications)
+	}
+	if !time.Now().Before(tui.alertFlashUntil) {
+		t.Error("Expected status bar to flash after an alert")
+	}
+
+	// A refresh with the same failing pod does not fire again within the cooldown
+	tui.loadingCounter = 1
+	tui.handleDataUpdate(&DataUpdate{ResourceType: ResourcePods, Pods: tui.pods})
+	if len(tui.notifications) != 1 {
+		t.Errorf("Expected deduplicated notifications, got %d", len(tui.notifications))
+	}
+
+	cfg.Alerts.Rules = []config.AlertRule{{Kind: "pod", Condition: "bogus"}}
+	if _, err := newAlertEngine(cfg); err == nil {
+		t.Error("Expected invalid alert rule to be rejected")
+	}
+}
+
+// TestTUITerminatingNamespace tests that a stuck namespace shows how long it
+// has been terminating and what is blocking it
+func TestTUITerminatingNamespace(t *testing.T) {
+	deleted := metav1.NewTime(time.Now().Add(-5 * time.Hour))
+	ns := v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", DeletionTimestamp: &deleted},
+		Status: v1.NamespaceStatus{
+			Phase: v1.NamespaceTerminating,
+			Conditions: []v1.NamespaceCondition{
+				{
+					Type:    v1.NamespaceContentRemaining,
+					Status:  v1.ConditionTrue,
+					Message: "Some resources are remaining: persistentvolumeclaims has 2 resource instances",
+				},
+				{
+					Type:    v1.NamespaceFinalizersRemaining,
+					Status:  v1.ConditionTrue,
+					Message: "Some content in the namespace has finalizers remaining: kubernetes.io/pvc-protection in 2 resource instances",
+				},
+			},
+		},
+	}
+	tui := &TUI{currentView: ResourceNamespaces}
+
+	if got := tui.getResourceColumnValue(ns, 1); got != "Terminating 5h" {
+		t.Errorf("Expected 'Terminating 5h', got %q", got)
+	}
+	if got := tui.getResourceColumnValue(ns, 3); got != "persistentvolumeclaims(2), kubernetes.io/pvc-protection(2)" {
+		t.Errorf("Unexpected blocking column %q", got)
+	}
+	if got := tui.getResourceColumnValue(v1.Namespace{Status: v1.NamespaceStatus{Phase: v1.NamespaceActive}}, 3); got != "" {
+		t.Errorf("Expected an empty blocking column for an active namespace, got %q", got)
+	}
+
+	details := strings.Join(tui.getResourceDetails(ns), "\n")
+	for _, want := range []string{"terminating for 5h", "2 persistentvolumeclaims remaining", "2 objects holding finalizer kubernetes.io/pvc-protection"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("Expected details to contain %q, got:\n%s", want, details)
+		}
+	}
+}
+
+// TestTUITimestampFormat tests cycling ages and timestamps with z
+func TestTUITimestampFormat(t *testing.T) {
+	tui := &TUI{currentView: ResourceCRDs, timestamps: timefmt.Formatter{Format: timefmt.Relative, Location: time.UTC}}
+	created := time.Now().Add(-75 * time.Hour).UTC().Truncate(time.Minute)
+	crd := k8s.CRD{Name: "widgets.example.com", CreationTimestamp: metav1.NewTime(created)}
+
+	createdLine := func() string {
+		for _, line := range tui.getCRDDetails(crd) {
+			if strings.HasPrefix(line, "Created: ") {
+				return strings.TrimPrefix(line, "Created: ")
+			}
+		}
+		t.Fatal("Expected a Created line in the CRD details")
+		return ""
+	}
+
+	if got := tui.getResourceColumnValue(crd, 4); got != "3d3h" {
+		t.Errorf("Expected a relative age, got %q", got)
+	}
+	if headers := tui.getTableHeaders(); headers[4] != "Age" {
+		t.Errorf("Expected an Age column, got %v", headers)
+	}
+
+	tui.nextTimestampFormat()
+	if got, want := tui.getResourceColumnValue(crd, 4), created.Format("2006-01-02 15:04"); got != want {
+		t.Errorf("Expected the absolute time %q, got %q", want, got)
+	}
+	if headers := tui.getTableHeaders(); headers[4] != "Created" {
+		t.Errorf("Expected the column to be renamed Created, got %v", headers)
+	}
+	if got, want := createdLine(), created.Format(time.RFC3339); got != want {
+		t.Errorf("Expected the details to show %q, got %q", want, got)
+	}
+
+	tui.nextTimestampFormat()
+	if got, want := tui.getResourceColumnValue(crd, 4), created.Format("2006-01-02 15:04")+" (3d3h)"; got != want {
+		t.Errorf("Expected both %q, got %q", want, got)
+	}
+	if got, want := createdLine(), created.Format(time.RFC3339)+" (3d3h)"; got != want {
+		t.Errorf("Expected the details to show %q, got %q", want, got)
+	}
+
+	tui.nextTimestampFormat()
+	if tui.timestamps.Format != timefmt.Relative {
+		t.Errorf("Expected z to cycle back to relative, got %q", tui.timestamps.Format)
+	}
+}
+
+// newTestScreen returns a simulation screen of width by height cells, closed
+// when the test ends
+func newTestScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Failed to init simulation screen: %v", err)
+	}
+	t.Cleanup(screen.Fini)
+	screen.SetSize(width, height)
+	return screen
+}
+
+// newTestTUI returns a TUI drawing on a simulation screen of width by height
+// cells, backed by a fake clientset holding objects. It shows the default
+// namespace with the default config and theme, and its guard protects no
+// namespace.
+func newTestTUI(t *testing.T, width, height int, objects ...runtime.Object) (*TUI, tcell.SimulationScreen) {
+	t.Helper()
+	guard, err := k8s.NewNamespaceGuard(nil)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	screen := newTestScreen(t, width, height)
+	tui := &TUI{
+		clientset:       fake.NewSimpleClientset(objects...),
+		screen:          screen,
+		config:          config.DefaultConfig(),
+		theme:           DefaultTheme(),
+		guard:           guard,
+		namespace:       "default",
+		dataChan:        make(chan *DataUpdate, 20),
+		loadedResources: make(map[ResourceType]bool),
+	}
+	t.Cleanup(tui.closeLogs)
+	return tui, screen
+}
+
+// screenText returns the text on a simulation screen, one line per row
+func screenText(screen tcell.SimulationScreen) string {
+	cells, width, height := screen.GetContents()
+	var text strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				text.WriteRune(runes[0])
+			}
+		}
+		text.WriteRune('\n')
+	}
+	return text.String()
+}
+
+// typeText injects text into a simulation screen one key at a time
+func typeText(screen tcell.SimulationScreen, text string) {
+	for _, r := range text {
+		screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// containerStatsTimeout bounds the metrics lookup of the pod shown in the
+	// details
+	containerStatsTimeout = 5 * time.Second
+	// containerStatsTTL is how long the usage of a pod is shown before it is
+	// read again; metrics-server scrapes every 15 seconds by default
+	containerStatsTTL = 15 * time.Second
+)
+
+// containerStatsLookup holds the usage of the containers of the pod shown in
+// the details view
+type containerStatsLookup struct {
+	namespace string
+	name      string
+	stats     []*k8s.ContainerStats
+	err       error
+	at        time.Time
+}
+
+// containerStats returns the usage of a pod's containers, read again once
+// the last reading is older than containerStatsTTL
+func (t *TUI) containerStats(pod v1.Pod) ([]*k8s.ContainerStats, error) {
+	lookup := t.containerStatsLookup
+	if lookup == nil || lookup.namespace != pod.Namespace || lookup.name != pod.Name || time.Since(lookup.at) >= containerStatsTTL {
+		lookup = &containerStatsLookup{namespace: pod.Namespace, name: pod.Name}
+		ctx, cancel := context.WithTimeout(context.Background(), containerStatsTimeout)
+		for _, container := range pod.Spec.Containers {
+			stats, err := k8s.GetContainerStats(ctx, t.metricsClientset, t.clientset, pod.Namespace, pod.Name, container.Name)
+			if err != nil {
+				lookup.err = err
+				break
+			}
+			lookup.stats = append(lookup.stats, stats)
+		}
+		cancel()
+		lookup.at = time.Now()
+		t.containerStatsLookup = lookup
+	}
+	return lookup.stats, lookup.err
+}
+
+// usageDetails returns the usage lines of a running pod's details: each
+// container's CPU with its change since the previous reading, its memory and
+// the cost of its restarts
+func (t *TUI) usageDetails(pod v1.Pod) []string {
+	if t.metricsClientset == nil || t.clientset == nil || pod.Status.Phase != v1.PodRunning {
+		return nil
+	}
+
+	lines := []string{"", "Usage:"}
+	stats, err := t.containerStats(pod)
+	if err != nil {
+		return append(lines, fmt.Sprintf("  Metrics unavailable: %v", err))
+	}
+	for _, container := range stats {
+		lines = append(lines,
+			fmt.Sprintf("  %s: CPU: %s, Memory: %s", container.Container, formatCPUUsage(container.CPUMilli, container.CPURateOfChange), formatSize(int(container.MemoryRSS))),
+			fmt.Sprintf("    Restart cost: %d (%d restarts, %d failed probes)", container.RestartCost, container.RestartCount, container.FailedProbes),
+		)
+	}
+	return lines
+}
+
+// formatCPUUsage formats CPU usage in millicores with its rate of change,
+// e.g. "150m (↑12%)", or without one when it is unknown
+func formatCPUUsage(milli int64, rate *float64) string {
+	usage := fmt.Sprintf("%dm", milli)
+	if rate == nil {
+		return usage
+	}
+	percent := math.Round(*rate)
+	switch {
+	case percent > 0:
+		return fmt.Sprintf("%s (↑%.0f%%)", usage, percent)
+	case percent < 0:
+		return fmt.Sprintf("%s (↓%.0f%%)", usage, -percent)
+	}
+	return usage + " (→0%)"
+}
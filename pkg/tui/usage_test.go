@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"k8s-dashboard/pkg/config"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestTUIPodUsage(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	readings := []string{"150m", "168m"}
+	gets := 0
+	metricsClient := &metricsfake.Clientset{}
+	metricsClient.AddReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		i := min(gets, len(readings)-1)
+		gets++
+		return true, &metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "usage-1", Namespace: "default"},
+			Timestamp:  metav1.NewTime(at.Add(time.Duration(i) * 15 * time.Second)),
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(readings[i]),
+				v1.ResourceMemory: resource.MustParse("64Mi"),
+			}}},
+		}, nil
+	})
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "usage-1", Namespace: "default"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "app", Image: "nginx"}}},
+		Status: v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{
+			{Name: "app", RestartCount: 1},
+		}},
+	}
+	tui := &TUI{
+		clientset:   fake.NewSimpleClientset(&pod),
+		config:      config.DefaultConfig(),
+		namespace:   "default",
+		currentView: ResourcePods,
+		theme:       DefaultTheme(),
+	}
+
+	if details := strings.Join(tui.getPodDetails(pod), "\n"); strings.Contains(details, "Usage:") {
+		t.Errorf("Expected no usage without a metrics client, got:\n%s", details)
+	}
+
+	tui.SetMetricsClientset(metricsClient)
+	details := strings.Join(tui.getPodDetails(pod), "\n")
+	if want := "Usage:\n  app: CPU: 150m, Memory: 64.0MiB\n    Restart cost: 100 (1 restarts, 0 failed probes)"; !strings.Contains(details, want) {
+		t.Errorf("Expected %q in the details, got:\n%s", want, details)
+	}
+	tui.getPodDetails(pod)
+	if gets != 1 {
+		t.Errorf("Expected the usage to be cached between draws, got %d lookups", gets)
+	}
+
+	tui.containerStatsLookup.at = time.Now().Add(-containerStatsTTL)
+	if details := strings.Join(tui.getPodDetails(pod), "\n"); !strings.Contains(details, "app: CPU: 168m (↑12%)") {
+		t.Errorf("Expected the CPU rate of change, got:\n%s", details)
+	}
+
+	for rate, want := range map[float64]string{-4.6: "90m (↓5%)", 0.2: "90m (→0%)"} {
+		if got := formatCPUUsage(90, &rate); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
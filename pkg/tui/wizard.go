@@ -0,0 +1,460 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// Pages of the deployment wizard
+const (
+	wizardStepMetadata = iota
+	wizardStepContainers
+	wizardStepResources
+	wizardStepLabels
+	wizardSteps
+)
+
+// wizardStepTitles names each wizard page in its title
+var wizardStepTitles = [wizardSteps]string{"Name & Namespace", "Containers", "Resources", "Labels & Annotations"}
+
+// wizardAction is the outcome of a key press in the wizard
+type wizardAction int
+
+const (
+	wizardContinue wizardAction = iota
+	wizardCancel
+	wizardDone
+)
+
+// wizardField is a labelled text input on a wizard page or form
+type wizardField struct {
+	label string
+	value string
+}
+
+// editFields applies a key moving between or typing into fields, with the
+// focused field at *focus. It reports whether the key was one of those.
+func editFields(fields []*wizardField, focus *int, ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyTab, tcell.KeyDown:
+		*focus = (*focus + 1) % len(fields)
+	case tcell.KeyBacktab, tcell.KeyUp:
+		*focus = (*focus + len(fields) - 1) % len(fields)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if value := fields[*focus].value; len(value) > 0 {
+			fields[*focus].value = value[:len(value)-1]
+		}
+	case tcell.KeyRune:
+		fields[*focus].value += string(ev.Rune())
+	default:
+		return false
+	}
+	return true
+}
+
+// fieldLines renders fields one per line, marking the focused one
+func fieldLines(fields []*wizardField, focus int) []string {
+	lines := make([]string, 0, len(fields))
+	for i, field := range fields {
+		marker, cursor := "  ", ""
+		if i == focus {
+			marker, cursor = "▶ ", "_"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: %s%s", marker, field.label, field.value, cursor))
+	}
+	return lines
+}
+
+// containerFieldCount is the number of fields per container on the
+// containers page: name, image and ports
+const containerFieldCount = 3
+
+// deploymentWizard holds the state of the deployment creation wizard. Every
+// page keeps its fields while the user moves between pages.
+type deploymentWizard struct {
+	step   int
+	field  int
+	pages  [wizardSteps][]*wizardField
+	errMsg string
+}
+
+// newDeploymentWizard creates a wizard that defaults to the given namespace
+func newDeploymentWizard(namespace string) *deploymentWizard {
+	w := &deploymentWizard{}
+	w.pages[wizardStepMetadata] = []*wizardField{
+		{label: "Name"},
+		{label: "Namespace", value: namespace},
+	}
+	w.addContainer()
+	w.pages[wizardStepResources] = []*wizardField{
+		{label: "Replicas", value: "1"},
+		{label: "CPU request"},
+		{label: "Memory request"},
+		{label: "CPU limit"},
+		{label: "Memory limit"},
+	}
+	w.pages[wizardStepLabels] = []*wizardField{
+		{label: "Labels"},
+		{label: "Annotations"},
+	}
+	return w
+}
+
+// addContainer appends the fields of another container to the containers page
+func (w *deploymentWizard) addContainer() {
+	n := len(w.pages[wizardStepContainers])/containerFieldCount + 1
+	w.pages[wizardStepContainers] = append(w.pages[wizardStepContainers],
+		&wizardField{label: fmt.Sprintf("Container %d name", n)},
+		&wizardField{label: fmt.Sprintf("Container %d image", n)},
+		&wizardField{label: fmt.Sprintf("Container %d ports", n)},
+	)
+}
+
+// value returns the trimmed value of a field on a page
+func (w *deploymentWizard) value(step, field int) string {
+	return strings.TrimSpace(w.pages[step][field].value)
+}
+
+// title shows the wizard progress, e.g. "Create Deployment - Step 2/4: Containers"
+func (w *deploymentWizard) title() string {
+	return fmt.Sprintf("Create Deployment - Step %d/%d: %s", w.step+1, wizardSteps, wizardStepTitles[w.step])
+}
+
+// handleKey applies a key press to the wizard
+func (w *deploymentWizard) handleKey(ev *tcell.EventKey) wizardAction {
+	if editFields(w.pages[w.step], &w.field, ev) {
+		return wizardContinue
+	}
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return wizardCancel
+	case tcell.KeyEnter:
+		if err := w.validateStep(); err != nil {
+			w.errMsg = err.Error()
+			return wizardContinue
+		}
+		w.errMsg = ""
+		if w.step == wizardSteps-1 {
+			return wizardDone
+		}
+		w.step++
+		w.field = 0
+		if w.step == wizardStepLabels && w.value(wizardStepLabels, 0) == "" {
+			w.pages[wizardStepLabels][0].value = "app=" + w.value(wizardStepMetadata, 0)
+		}
+	case tcell.KeyPgUp:
+		if w.step > 0 {
+			w.step--
+			w.field = 0
+			w.errMsg = ""
+		}
+	case tcell.KeyCtrlA:
+		if w.step == wizardStepContainers {
+			w.addContainer()
+			w.field = len(w.pages[wizardStepContainers]) - containerFieldCount
+		}
+	}
+	return wizardContinue
+}
+
+// validateStep checks the fields of the current page before advancing
+func (w *deploymentWizard) validateStep() error {
+	switch w.step {
+	case wizardStepMetadata:
+		if errs := validation.IsDNS1123Subdomain(w.value(wizardStepMetadata, 0)); len(errs) > 0 {
+			return fmt.Errorf("invalid name: %s", errs[0])
+		}
+		if errs := validation.IsDNS1123Label(w.value(wizardStepMetadata, 1)); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace: %s", errs[0])
+		}
+	case wizardStepContainers:
+		_, err := w.containers()
+		return err
+	case wizardStepResources:
+		_, _, err := w.resources()
+		return err
+	case wizardStepLabels:
+		if _, err := parseKeyValues(w.value(wizardStepLabels, 0), true); err != nil {
+			return fmt.Errorf("invalid labels: %v", err)
+		}
+		if _, err := parseKeyValues(w.value(wizardStepLabels, 1), false); err != nil {
+			return fmt.Errorf("invalid annotations: %v", err)
+		}
+	}
+	return nil
+}
+
+// containers builds the containers from the containers page. A container
+// whose fields are all empty is skipped.
+func (w *deploymentWizard) containers() ([]v1.Container, error) {
+	var containers []v1.Container
+	seen := make(map[string]bool)
+	fields := w.pages[wizardStepContainers]
+	for i := 0; i < len(fields); i += containerFieldCount {
+		name := w.value(wizardStepContainers, i)
+		image := w.value(wizardStepContainers, i+1)
+		ports := w.value(wizardStepContainers, i+2)
+		n := i/containerFieldCount + 1
+		if name == "" && image == "" && ports == "" && i > 0 {
+			continue
+		}
+
+		if name == "" {
+			name = w.value(wizardStepMetadata, 0)
+			if n > 1 {
+				name = fmt.Sprintf("%s-%d", name, n)
+			}
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("container %d: invalid name: %s", n, errs[0])
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("container %d: duplicate name %q", n, name)
+		}
+		seen[name] = true
+		if image == "" {
+			return nil, fmt.Errorf("container %d: image is required", n)
+		}
+
+		container := v1.Container{Name: name, Image: image}
+		for _, port := range splitList(ports) {
+			number, err := strconv.Atoi(port)
+			if err != nil || number < 1 || number > 65535 {
+				return nil, fmt.Errorf("container %d: invalid port %q", n, port)
+			}
+			container.Ports = append(container.Ports, v1.ContainerPort{ContainerPort: int32(number)})
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// resources parses the replicas and the requests and limits applied to
+// every container
+func (w *deploymentWizard) resources() (int32, v1.ResourceRequirements, error) {
+	var requirements v1.ResourceRequirements
+
+	count, err := strconv.Atoi(w.value(wizardStepResources, 0))
+	if err != nil || count < 1 {
+		return 0, requirements, fmt.Errorf("replicas must be a number of at least 1")
+	}
+
+	quantities := []struct {
+		field int
+		list  *v1.ResourceList
+		name  v1.ResourceName
+	}{
+		{1, &requirements.Requests, v1.ResourceCPU},
+		{2, &requirements.Requests, v1.ResourceMemory},
+		{3, &requirements.Limits, v1.ResourceCPU},
+		{4, &requirements.Limits, v1.ResourceMemory},
+	}
+	for _, q := range quantities {
+		value := w.value(wizardStepResources, q.field)
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return 0, requirements, fmt.Errorf("invalid %s %q", strings.ToLower(w.pages[wizardStepResources][q.field].label), value)
+		}
+		if *q.list == nil {
+			*q.list = v1.ResourceList{}
+		}
+		(*q.list)[q.name] = quantity
+	}
+	return int32(count), requirements, nil
+}
+
+// build returns the deployment described by the wizard
+func (w *deploymentWizard) build() (*appsv1.Deployment, error) {
+	for step := 0; step < wizardSteps; step++ {
+		saved := w.step
+		w.step = step
+		err := w.validateStep()
+		w.step = saved
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name := w.value(wizardStepMetadata, 0)
+	containers, _ := w.containers()
+	replicas, requirements, _ := w.resources()
+	for i := range containers {
+		containers[i].Resources = requirements
+	}
+	labels, _ := parseKeyValues(w.value(wizardStepLabels, 0), true)
+	if len(labels) == 0 {
+		labels = map[string]string{"app": name}
+	}
+	annotations, _ := parseKeyValues(w.value(wizardStepLabels, 1), false)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   w.value(wizardStepMetadata, 1),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       v1.PodSpec{Containers: containers},
+			},
+		},
+	}, nil
+}
+
+// lines renders the current wizard page
+func (w *deploymentWizard) lines() []string {
+	lines := append([]string{w.title(), ""}, fieldLines(w.pages[w.step], w.field)...)
+	lines = append(lines, "")
+	switch w.step {
+	case wizardStepContainers:
+		lines = append(lines, "Ports are comma-separated, e.g. 80,443. An empty name defaults to the deployment name.")
+	case wizardStepResources:
+		lines = append(lines, "Requests and limits apply to every container, e.g. 100m, 128Mi. Leave empty for none.")
+	case wizardStepLabels:
+		lines = append(lines, "Comma-separated key=value pairs. Labels are also used as the selector.")
+	}
+	if w.errMsg != "" {
+		lines = append(lines, "Error: "+w.errMsg)
+	}
+	lines = append(lines, "")
+
+	help := "Tab/↑↓: Field | Enter: Next | PgUp: Back | Esc: Cancel"
+	if w.step == wizardStepContainers {
+		help += " | Ctrl-A: Add container"
+	}
+	if w.step == wizardSteps-1 {
+		help = strings.Replace(help, "Enter: Next", "Enter: Create", 1)
+	}
+	return append(lines, help)
+}
+
+// parseKeyValues parses comma-separated key=value pairs, validating keys as
+// label or annotation keys and values as label values when isLabel is set
+func parseKeyValues(text string, isLabel bool) (map[string]string, error) {
+	pairs := splitList(text)
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok {
+			return nil, fmt.Errorf("%q is not key=value", pair)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("key %q: %s", key, errs[0])
+		}
+		if isLabel {
+			if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+				return nil, fmt.Errorf("value %q: %s", value, errs[0])
+			}
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// drawLines clears the screen and draws a dialog, with the first line as its
+// title and lines starting with "Error: " in red
+func (t *TUI) drawLines(lines []string) {
+	t.screen.Clear()
+	for i, line := range lines {
+		style := tcell.StyleDefault
+		if i == 0 {
+			style = style.Bold(true)
+		} else if strings.HasPrefix(line, "Error: ") {
+			style = style.Foreground(tcell.ColorRed)
+		}
+		t.drawText(0, i, 100, line, style)
+	}
+	t.screen.Show()
+}
+
+// createDeploymentDialog runs the deployment creation wizard
+func (t *TUI) createDeploymentDialog() {
+	w := newDeploymentWizard(t.namespace)
+
+	for {
+		t.drawLines(w.lines())
+
+		ev, ok := t.screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch w.handleKey(ev) {
+		case wizardCancel:
+			return
+		case wizardDone:
+			deployment, err := w.build()
+			if err != nil {
+				w.errMsg = err.Error()
+				continue
+			}
+			t.createDeployment(deployment)
+			return
+		}
+	}
+}
+
+// createDeployment creates the deployment built by the wizard
+func (t *TUI) createDeployment(deployment *appsv1.Deployment) {
+	if !t.confirmProtectedActionIn(deployment.Namespace, "create", "deployment", deployment.Name) {
+		return
+	}
+
+	t.loading = true
+	t.draw()
+	t.screen.Show()
+
+	err := t.imagePolicy.CheckObject(deployment)
+	if err == nil {
+		_, err = k8s.CreateDeployment(t.clientset, deployment.Namespace, deployment)
+	}
+	t.loading = false
+
+	if err != nil {
+		klog.Errorf("Failed to create deployment: %v", err)
+		errorMsg := fmt.Sprintf("Error creating deployment: %v", err)
+		t.drawText(0, 3, 80, errorMsg, tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite))
+		t.screen.Show()
+		time.Sleep(3 * time.Second)
+		return
+	}
+
+	t.recordAction(fmt.Sprintf("Created deployment '%s'", deployment.Name), k8s.KubectlCreate(deployment.Namespace, deployment))
+	if deployment.Namespace == t.namespace {
+		t.loadDeployments()
+	}
+}
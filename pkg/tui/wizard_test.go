@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s-dashboard/pkg/k8s"
+
+	"github.com/gdamore/tcell/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestDeploymentWizardNavigation tests page validation and that going back keeps entered values
+func TestDeploymentWizardNavigation(t *testing.T) {
+	w := newDeploymentWizard("default")
+	key := func(k tcell.Key) wizardAction {
+		return w.handleKey(tcell.NewEventKey(k, 0, tcell.ModNone))
+	}
+	typeRunes := func(text string) {
+		for _, r := range text {
+			w.handleKey(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+	}
+
+	if !strings.Contains(w.title(), "Step 1/4") {
+		t.Errorf("Expected step 1/4 in title, got %q", w.title())
+	}
+
+	// An invalid name blocks advancing
+	typeRunes("Web_App")
+	key(tcell.KeyEnter)
+	if w.step != wizardStepMetadata || !strings.Contains(w.errMsg, "invalid name") {
+		t.Fatalf("Expected invalid name to block, got step %d, error %q", w.step, w.errMsg)
+	}
+	for range "Web_App" {
+		key(tcell.KeyBackspace2)
+	}
+	typeRunes("web")
+	key(tcell.KeyEnter)
+	if w.step != wizardStepContainers || w.errMsg != "" {
+		t.Fatalf("Expected step 2, got step %d, error %q", w.step, w.errMsg)
+	}
+	if !strings.Contains(w.title(), "Step 2/4") {
+		t.Errorf("Expected step 2/4 in title, got %q", w.title())
+	}
+
+	// The image is required
+	key(tcell.KeyEnter)
+	if w.step != wizardStepContainers || !strings.Contains(w.errMsg, "image is required") {
+		t.Fatalf("Expected missing image to block, got step %d, error %q", w.step, w.errMsg)
+	}
+	key(tcell.KeyTab)
+	typeRunes("nginx:1.27")
+	key(tcell.KeyEnter)
+	if w.step != wizardStepResources {
+		t.Fatalf("Expected step 3, got %d: %s", w.step, w.errMsg)
+	}
+
+	// Replicas must be at least 1
+	key(tcell.KeyBackspace2)
+	typeRunes("0")
+	key(tcell.KeyEnter)
+	if w.step != wizardStepResources || !strings.Contains(w.errMsg, "replicas") {
+		t.Fatalf("Expected zero replicas to block, got step %d, error %q", w.step, w.errMsg)
+	}
+
+	// PgUp goes back and keeps the state of every page
+	key(tcell.KeyPgUp)
+	key(tcell.KeyPgUp)
+	if w.step != wizardStepMetadata || w.pages[wizardStepMetadata][0].value != "web" {
+		t.Fatalf("Expected step 1 with name kept, got step %d, %+v", w.step, w.pages[wizardStepMetadata])
+	}
+	key(tcell.KeyPgUp)
+	if w.step != wizardStepMetadata {
+		t.Errorf("Expected PgUp on the first page to stay, got step %d", w.step)
+	}
+	key(tcell.KeyEnter)
+	key(tcell.KeyEnter)
+	if w.step != wizardStepResources || w.pages[wizardStepContainers][1].value != "nginx:1.27" || w.pages[wizardStepResources][0].value != "0" {
+		t.Fatalf("Expected entered values to be kept, got step %d", w.step)
+	}
+
+	if key(tcell.KeyEscape) != wizardCancel {
+		t.Error("Expected Esc to cancel the wizard")
+	}
+}
+
+// TestDeploymentWizardBuild tests the Deployment built from a completed wizard
+func TestDeploymentWizardBuild(t *testing.T) {
+	w := newDeploymentWizard("default")
+	set := func(step int, values ...string) {
+		for i, value := range values {
+			w.pages[step][i].value = value
+		}
+	}
+	set(wizardStepMetadata, "web", "staging")
+	set(wizardStepContainers, "", "nginx:1.27", "80, 443")
+	w.step = wizardStepContainers
+	w.handleKey(tcell.NewEventKey(tcell.KeyCtrlA, 0, tcell.ModNone))
+	if len(w.pages[wizardStepContainers]) != 2*containerFieldCount || w.field != containerFieldCount {
+		t.Fatalf("Expected Ctrl-A to add a container and focus it, got %d fields, field %d", len(w.pages[wizardStepContainers]), w.field)
+	}
+	w.pages[wizardStepContainers][4].value = "envoy:v1"
+	set(wizardStepResources, "3", "100m", "128Mi", "", "256Mi")
+	set(wizardStepLabels, "app=web, tier=frontend", "owner=platform")
+
+	// Walk the remaining pages so the labels page is validated too
+	w.step = wizardStepResources
+	w.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	if action := w.handleKey(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)); action != wizardDone {
+		t.Fatalf("Expected the last page to finish the wizard, got %v: %s", action, w.errMsg)
+	}
+
+	deployment, err := w.build()
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if deployment.Name != "web" || deployment.Namespace != "staging" || *deployment.Spec.Replicas != 3 {
+		t.Errorf("Unexpected metadata %s/%s replicas %d", deployment.Namespace, deployment.Name, *deployment.Spec.Replicas)
+	}
+	wantLabels := map[string]string{"app": "web", "tier": "frontend"}
+	if fmt.Sprint(deployment.Spec.Selector.MatchLabels) != fmt.Sprint(wantLabels) ||
+		fmt.Sprint(deployment.Spec.Template.Labels) != fmt.Sprint(wantLabels) {
+		t.Errorf("Expected selector and template labels %v, got %v and %v", wantLabels, deployment.Spec.Selector.MatchLabels, deployment.Spec.Template.Labels)
+	}
+	if deployment.Annotations["owner"] != "platform" {
+		t.Errorf("Expected annotation, got %v", deployment.Annotations)
+	}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) != 2 {
+		t.Fatalf("Expected 2 containers, got %d", len(containers))
+	}
+	if containers[0].Name != "web" || containers[0].Image != "nginx:1.27" || len(containers[0].Ports) != 2 || containers[0].Ports[1].ContainerPort != 443 {
+		t.Errorf("Unexpected first container %+v", containers[0])
+	}
+	if containers[1].Name != "web-2" || containers[1].Image != "envoy:v1" {
+		t.Errorf("Unexpected second container %+v", containers[1])
+	}
+	resources := containers[1].Resources
+	if resources.Requests.Cpu().String() != "100m" || resources.Requests.Memory().String() != "128Mi" || resources.Limits.Memory().String() != "256Mi" {
+		t.Errorf("Unexpected resources %+v", resources)
+	}
+	if _, ok := resources.Limits[v1.ResourceCPU]; ok {
+		t.Error("Expected no CPU limit when the field is empty")
+	}
+
+	// Invalid pages are caught even when skipped over
+	w.pages[wizardStepContainers][2].value = "http"
+	if _, err := w.build(); err == nil || !strings.Contains(err.Error(), "invalid port") {
+		t.Errorf("Expected an invalid port error, got %v", err)
+	}
+}
+
+// TestDeploymentWizardCreatesDeployment tests the wizard dialog end to end
+func TestDeploymentWizardCreatesDeployment(t *testing.T) {
+	tui, screen := newTestTUI(t, 120, 30)
+	clientset := tui.clientset.(*fake.Clientset)
+	tui.currentView = ResourceDeployments
+
+	// The simulation screen queues few events, so keys are sent while the
+	// dialog reads them
+	go func() {
+		typeText(screen, "api")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone) // Step 2
+		screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+		typeText(screen, "api:v2")
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone) // Step 3
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone) // Step 4
+		screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone) // Create
+	}()
+	tui.createDeploymentDialog()
+
+	deployments, err := k8s.ListDeployments(clientset, "default")
+	if err != nil || len(deployments) != 1 {
+		t.Fatalf("Expected 1 deployment, got %v, %v", deployments, err)
+	}
+	if deployments[0].Name != "api" || deployments[0].Spec.Template.Spec.Containers[0].Image != "api:v2" || deployments[0].Labels["app"] != "api" {
+		t.Errorf("Unexpected deployment %+v", deployments[0])
+	}
+	if len(tui.deployments) != 1 {
+		t.Errorf("Expected deployments to be reloaded, got %d", len(tui.deployments))
+	}
+}
@@ -0,0 +1,55 @@
+// Package util holds small helpers shared by the TUI, the REST API and gRPC.
+package util
+
+import (
+	"fmt"
+	"time"
+)
+
+// HumanDuration formats a duration the way kubectl prints ages: seconds up to
+// 2 minutes, then a second unit while the first is small ("3m10s", "2d1h",
+// "2y1d") and a single unit otherwise ("70m", "47h", "367d", "8y").
+// Negative durations, from clock skew with the API server, are "0s".
+func HumanDuration(d time.Duration) string {
+	seconds := int(d.Seconds())
+	switch {
+	case seconds < 0:
+		return "0s"
+	case seconds < 2*60:
+		return fmt.Sprintf("%ds", seconds)
+	}
+
+	minutes := int(d / time.Minute)
+	switch {
+	case minutes < 10:
+		return twoUnits(minutes, "m", seconds%60, "s")
+	case minutes < 3*60:
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	hours := int(d / time.Hour)
+	days := hours / 24
+	years := days / 365
+	switch {
+	case hours < 8:
+		return twoUnits(hours, "h", minutes%60, "m")
+	case hours < 2*24:
+		return fmt.Sprintf("%dh", hours)
+	case days < 8:
+		return twoUnits(days, "d", hours%24, "h")
+	case years < 2:
+		return fmt.Sprintf("%dd", days)
+	case years < 8:
+		return twoUnits(years, "y", days%365, "d")
+	}
+	return fmt.Sprintf("%dy", years)
+}
+
+// twoUnits formats a value in a unit followed by the remainder in a smaller
+// one, leaving out a zero remainder
+func twoUnits(value int, unit string, remainder int, remainderUnit string) string {
+	if remainder == 0 {
+		return fmt.Sprintf("%d%s", value, unit)
+	}
+	return fmt.Sprintf("%d%s%d%s", value, unit, remainder, remainderUnit)
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+// The cases follow kubectl's own (k8s.io/apimachinery/pkg/util/duration), so
+// that ages match what kubectl get shows
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Second, "1s"},
+		{70 * time.Second, "70s"},
+		{190 * time.Second, "3m10s"},
+		{70 * time.Minute, "70m"},
+		{47 * time.Hour, "47h"},
+		{49 * time.Hour, "2d1h"},
+		{(8*24 + 2) * time.Hour, "8d"},
+		{367 * 24 * time.Hour, "367d"},
+		{(365*2*24 + 25) * time.Hour, "2y1d"},
+		{(365*8*24 + 2) * time.Hour, "8y"},
+	}
+	for _, tt := range tests {
+		if got := HumanDuration(tt.d); got != tt.want {
+			t.Errorf("HumanDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestHumanDurationBoundaries(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		// kubectl prints "<invalid>" from -2s on; skew is not worth alarming about
+		{-time.Hour, "0s"},
+		{-2 * time.Second, "0s"},
+		{-2*time.Second + 1, "0s"},
+		{0, "0s"},
+		{time.Second - time.Millisecond, "0s"},
+		{2*time.Minute - time.Millisecond, "119s"},
+		{2 * time.Minute, "2m"},
+		{2*time.Minute + time.Second, "2m1s"},
+		{10*time.Minute - time.Millisecond, "9m59s"},
+		{10 * time.Minute, "10m"},
+		{10*time.Minute + time.Second, "10m"},
+		{3*time.Hour - time.Millisecond, "179m"},
+		{3 * time.Hour, "3h"},
+		{3*time.Hour + time.Minute, "3h1m"},
+		{8*time.Hour - time.Millisecond, "7h59m"},
+		{8 * time.Hour, "8h"},
+		{8*time.Hour + 59*time.Minute, "8h"},
+		{2*24*time.Hour - time.Millisecond, "47h"},
+		{2 * 24 * time.Hour, "2d"},
+		{2*24*time.Hour + time.Hour, "2d1h"},
+		{8*24*time.Hour - time.Millisecond, "7d23h"},
+		{8 * 24 * time.Hour, "8d"},
+		{8*24*time.Hour + 23*time.Hour, "8d"},
+		{2*365*24*time.Hour - time.Millisecond, "729d"},
+		{2 * 365 * 24 * time.Hour, "2y"},
+		{2*365*24*time.Hour + 23*time.Hour, "2y"},
+		{2*365*24*time.Hour + 23*time.Hour + 59*time.Minute, "2y"},
+		{2*365*24*time.Hour + 24*time.Hour - time.Millisecond, "2y"},
+		{2*365*24*time.Hour + 24*time.Hour, "2y1d"},
+		{3 * 365 * 24 * time.Hour, "3y"},
+		{4 * 365 * 24 * time.Hour, "4y"},
+		{5 * 365 * 24 * time.Hour, "5y"},
+		{6 * 365 * 24 * time.Hour, "6y"},
+		{7 * 365 * 24 * time.Hour, "7y"},
+		{8*365*24*time.Hour - time.Millisecond, "7y364d"},
+		{8 * 365 * 24 * time.Hour, "8y"},
+		{8*365*24*time.Hour + 364*24*time.Hour, "8y"},
+		{9 * 365 * 24 * time.Hour, "9y"},
+	}
+	for _, tt := range tests {
+		if got := HumanDuration(tt.d); got != tt.want {
+			t.Errorf("HumanDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
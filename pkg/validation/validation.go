@@ -0,0 +1,199 @@
+package validation
+
+import (
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	supportedProtocols    = []string{string(v1.ProtocolTCP), string(v1.ProtocolUDP), string(v1.ProtocolSCTP)}
+	supportedServiceTypes = []string{
+		string(v1.ServiceTypeClusterIP),
+		string(v1.ServiceTypeNodePort),
+		string(v1.ServiceTypeLoadBalancer),
+		string(v1.ServiceTypeExternalName),
+	}
+)
+
+// Pod checks the fields of a pod that kgo creates or updates. It returns a
+// Kubernetes Invalid error listing every invalid field, or nil.
+func Pod(pod *v1.Pod) error {
+	errs := objectName(pod.Name, pod.GenerateName, utilvalidation.IsDNS1123Subdomain)
+	errs = append(errs, podSpec(&pod.Spec, field.NewPath("spec"))...)
+	return invalid(schema.GroupKind{Kind: "Pod"}, pod.Name, errs)
+}
+
+// Deployment checks a deployment's name, replicas and pod template
+func Deployment(deployment *appsv1.Deployment) error {
+	errs := objectName(deployment.Name, deployment.GenerateName, utilvalidation.IsDNS1123Subdomain)
+	specPath := field.NewPath("spec")
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("replicas"), *deployment.Spec.Replicas, "must be greater than or equal to 0"))
+	}
+	errs = append(errs, podSpec(&deployment.Spec.Template.Spec, specPath.Child("template", "spec"))...)
+	return invalid(schema.GroupKind{Group: appsv1.GroupName, Kind: "Deployment"}, deployment.Name, errs)
+}
+
+// Service checks a service's name, type and ports
+func Service(service *v1.Service) error {
+	errs := objectName(service.Name, service.GenerateName, utilvalidation.IsDNS1035Label)
+	specPath := field.NewPath("spec")
+	if service.Spec.Type != "" && !contains(supportedServiceTypes, string(service.Spec.Type)) {
+		errs = append(errs, field.NotSupported(specPath.Child("type"), service.Spec.Type, supportedServiceTypes))
+	}
+	for i, port := range service.Spec.Ports {
+		portPath := specPath.Child("ports").Index(i)
+		errs = append(errs, portNumber(port.Port, portPath.Child("port"))...)
+		if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal != 0 {
+			errs = append(errs, portNumber(port.TargetPort.IntVal, portPath.Child("targetPort"))...)
+		}
+		errs = append(errs, protocol(port.Protocol, portPath.Child("protocol"))...)
+	}
+	return invalid(schema.GroupKind{Kind: "Service"}, service.Name, errs)
+}
+
+// ConfigMap checks a configmap's name and the keys of its data and
+// binaryData. Keys are often file names, so a path separator gets its own
+// message.
+func ConfigMap(configMap *v1.ConfigMap) error {
+	errs := objectName(configMap.Name, configMap.GenerateName, utilvalidation.IsDNS1123Subdomain)
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	errs = append(errs, configMapKeys(keys, field.NewPath("data"))...)
+	keys = make([]string, 0, len(configMap.BinaryData))
+	for key := range configMap.BinaryData {
+		keys = append(keys, key)
+	}
+	errs = append(errs, configMapKeys(keys, field.NewPath("binaryData"))...)
+	return invalid(schema.GroupKind{Kind: "ConfigMap"}, configMap.Name, errs)
+}
+
+// Metadata checks the labels or annotations set on an object and the keys
+// removed from it, as metadataField says: label keys must be qualified names
+// such as "app.kubernetes.io/name" and values at most 63 characters of
+// alphanumerics, '-', '_' and '.'; annotation keys must be qualified names.
+// resource and name only label the error.
+func Metadata(resource, name, metadataField string, set map[string]string, remove []string) error {
+	path := field.NewPath("metadata", metadataField)
+	var errs field.ErrorList
+	if metadataField == "labels" {
+		errs = metav1validation.ValidateLabels(set, path)
+	} else {
+		errs = apivalidation.ValidateAnnotations(set, path)
+	}
+	for _, key := range remove {
+		for _, msg := range utilvalidation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path.Key(key), key, msg))
+		}
+	}
+	return invalid(schema.GroupKind{Kind: resource}, name, errs)
+}
+
+// configMapKeys checks configmap keys in sorted order
+func configMapKeys(keys []string, path *field.Path) field.ErrorList {
+	sort.Strings(keys)
+	var errs field.ErrorList
+	for _, key := range keys {
+		if strings.ContainsAny(key, `/\`) {
+			errs = append(errs, field.Invalid(path.Key(key), key, "must not contain path separators"))
+			continue
+		}
+		for _, msg := range utilvalidation.IsConfigMapKey(key) {
+			errs = append(errs, field.Invalid(path.Key(key), key, msg))
+		}
+	}
+	return errs
+}
+
+// objectName requires a name, or a generateName prefix, in the given format
+func objectName(name, generateName string, format func(string) []string) field.ErrorList {
+	namePath := field.NewPath("metadata", "name")
+	if name == "" {
+		if generateName == "" {
+			return field.ErrorList{field.Required(namePath, "name or generateName is required")}
+		}
+		return nil
+	}
+
+	var errs field.ErrorList
+	for _, msg := range format(name) {
+		errs = append(errs, field.Invalid(namePath, name, msg))
+	}
+	return errs
+}
+
+// podSpec requires at least one container, each with a name, an image and
+// valid ports
+func podSpec(spec *v1.PodSpec, path *field.Path) field.ErrorList {
+	containersPath := path.Child("containers")
+	if len(spec.Containers) == 0 {
+		return field.ErrorList{field.Required(containersPath, "at least one container is required")}
+	}
+
+	var errs field.ErrorList
+	for i, container := range spec.Containers {
+		containerPath := containersPath.Index(i)
+		if container.Name == "" {
+			errs = append(errs, field.Required(containerPath.Child("name"), ""))
+		} else {
+			for _, msg := range utilvalidation.IsDNS1123Label(container.Name) {
+				errs = append(errs, field.Invalid(containerPath.Child("name"), container.Name, msg))
+			}
+		}
+		if container.Image == "" {
+			errs = append(errs, field.Required(containerPath.Child("image"), ""))
+		}
+		for j, port := range container.Ports {
+			portPath := containerPath.Child("ports").Index(j)
+			errs = append(errs, portNumber(port.ContainerPort, portPath.Child("containerPort"))...)
+			errs = append(errs, protocol(port.Protocol, portPath.Child("protocol"))...)
+		}
+	}
+	return errs
+}
+
+// portNumber requires a port in 1-65535
+func portNumber(port int32, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range utilvalidation.IsValidPortNum(int(port)) {
+		errs = append(errs, field.Invalid(path, port, msg))
+	}
+	return errs
+}
+
+// protocol accepts TCP, UDP and SCTP, or empty for the TCP default
+func protocol(protocol v1.Protocol, path *field.Path) field.ErrorList {
+	if protocol == "" || contains(supportedProtocols, string(protocol)) {
+		return nil
+	}
+	return field.ErrorList{field.NotSupported(path, protocol, supportedProtocols)}
+}
+
+// invalid returns errs as an Invalid API error, or nil when there are none
+func invalid(kind schema.GroupKind, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kind, name, errs)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
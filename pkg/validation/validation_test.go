@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// causeFields returns the invalid fields listed by an Invalid error
+func causeFields(t *testing.T, err error) []string {
+	t.Helper()
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("Expected an Invalid error, got %v", err)
+	}
+	var fields []string
+	for _, cause := range err.(*apierrors.StatusError).ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}
+
+func TestPod(t *testing.T) {
+	valid := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name:  "app",
+			Image: "nginx",
+			Ports: []v1.ContainerPort{{ContainerPort: 80}, {ContainerPort: 53, Protocol: v1.ProtocolUDP}},
+		}}},
+	}
+	if err := Pod(valid); err != nil {
+		t.Errorf("Expected a valid pod, got %v", err)
+	}
+
+	generated := valid.DeepCopy()
+	generated.Name, generated.GenerateName = "", "web-"
+	if err := Pod(generated); err != nil {
+		t.Errorf("Expected generateName to stand in for the name, got %v", err)
+	}
+
+	invalid := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "Web"},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name:  "app_1",
+			Ports: []v1.ContainerPort{{ContainerPort: 65536, Protocol: "HTTP"}},
+		}}},
+	}
+	want := []string{
+		"metadata.name",
+		"spec.containers[0].name",
+		"spec.containers[0].image",
+		"spec.containers[0].ports[0].containerPort",
+		"spec.containers[0].ports[0].protocol",
+	}
+	fields := causeFields(t, Pod(invalid))
+	if len(fields) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Errorf("Cause %d: expected %s, got %s", i, want[i], fields[i])
+		}
+	}
+}
+
+func TestDeploymentServiceAndConfigMap(t *testing.T) {
+	replicas := int32(-1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	if fields := causeFields(t, Deployment(deployment)); len(fields) != 2 || fields[0] != "spec.replicas" || fields[1] != "spec.template.spec.containers" {
+		t.Errorf("Unexpected deployment causes %v", fields)
+	}
+
+	service := &v1.Service{
+		// Service names are DNS-1035 labels, so they may not start with a digit
+		ObjectMeta: metav1.ObjectMeta{Name: "1web"},
+		Spec: v1.ServiceSpec{
+			Type:  "Internal",
+			Ports: []v1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(0)}, {Port: 443, TargetPort: intstr.FromInt(70000)}},
+		},
+	}
+	if fields := causeFields(t, Service(service)); len(fields) != 3 || fields[0] != "metadata.name" || fields[1] != "spec.type" || fields[2] != "spec.ports[1].targetPort" {
+		t.Errorf("Unexpected service causes %v", fields)
+	}
+
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Data:       map[string]string{"ok.conf": "", "b/key": "", "a key": ""},
+	}
+	if fields := causeFields(t, ConfigMap(configMap)); len(fields) != 2 || fields[0] != "data[a key]" || fields[1] != "data[b/key]" {
+		t.Errorf("Expected invalid keys in order, got %v", fields)
+	}
+
+	configMap = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		BinaryData: map[string][]byte{`certs\ca.der`: nil, "logo.png": nil},
+	}
+	err := ConfigMap(configMap)
+	if fields := causeFields(t, err); len(fields) != 1 || fields[0] != `binaryData[certs\ca.der]` {
+		t.Errorf("Expected the binaryData key with a backslash to be invalid, got %v", fields)
+	}
+	if !strings.Contains(err.Error(), "must not contain path separators") {
+		t.Errorf("Expected the path separator message, got %v", err)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	if err := Metadata("deployments", "web", "labels", map[string]string{"app.kubernetes.io/name": "web", "tier": ""}, []string{"team"}); err != nil {
+		t.Errorf("Expected valid labels, got %v", err)
+	}
+	// Annotation values are free text
+	if err := Metadata("deployments", "web", "annotations", map[string]string{"example.com/note": "hello, world!"}, nil); err != nil {
+		t.Errorf("Expected valid annotations, got %v", err)
+	}
+
+	err := Metadata("deployments", "web", "labels", map[string]string{"bad key": "web", "tier": "front end"}, []string{"-team"})
+	fields := causeFields(t, err)
+	want := "[metadata.labels metadata.labels metadata.labels[-team]]"
+	if got := "[" + strings.Join(fields, " ") + "]"; got != want {
+		t.Errorf("Expected invalid fields %s, got %s", want, got)
+	}
+	if !strings.Contains(err.Error(), `deployments "web" is invalid`) {
+		t.Errorf("Expected the object in the error, got %v", err)
+	}
+
+	if err := Metadata("pods", "web-1", "annotations", map[string]string{"bad key": ""}, nil); !apierrors.IsInvalid(err) {
+		t.Errorf("Expected an invalid annotation key, got %v", err)
+	}
+}
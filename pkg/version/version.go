@@ -0,0 +1,108 @@
+// Package version is the version of kgo, which servers report and clients
+// compare to their own on connect. Releases set it when building:
+//
+//	go build -ldflags "-X k8s-dashboard/pkg/version.Version=1.4.0" ./cmd/server
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// Version is the semantic version of this binary
+var Version = "0.0.0-dev"
+
+// Semver is a parsed semantic version, e.g. 1.4.0-rc.1
+type Semver struct {
+	Major, Minor, Patch int
+	// Prerelease follows a "-", e.g. rc.1; build metadata after "+" is
+	// dropped
+	Prerelease string
+}
+
+// Parse parses a semantic version, with or without a leading "v"
+func Parse(version string) (Semver, error) {
+	v := strings.TrimPrefix(version, "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, prerelease, _ := strings.Cut(v, "-")
+
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return Semver{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", version)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Semver{}, fmt.Errorf("invalid version %q: %q is not a number", version, part)
+		}
+		numbers[i] = n
+	}
+	return Semver{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, nil
+}
+
+// Skew is how far apart two versions are
+type Skew int
+
+const (
+	// SkewNone is the same major and minor version; patches do not change
+	// behavior
+	SkewNone Skew = iota
+	// SkewMinor is the same major version with a different minor one
+	SkewMinor
+	// SkewMajor is a different major version
+	SkewMajor
+)
+
+// Compare returns the skew between two versions
+func Compare(a, b string) (Skew, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return SkewNone, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return SkewNone, err
+	}
+	switch {
+	case va.Major != vb.Major:
+		return SkewMajor, nil
+	case va.Minor != vb.Minor:
+		return SkewMinor, nil
+	}
+	return SkewNone, nil
+}
+
+// SkewError is returned by CheckServer for a server of another major version
+type SkewError struct {
+	Client, Server string
+}
+
+func (e *SkewError) Error() string {
+	return fmt.Sprintf("kgo server version %s is incompatible with client version %s; upgrade one of them, or allow the skew", e.Server, e.Client)
+}
+
+// CheckServer compares the version a server reports to Version. Minor skew
+// is logged. Major skew is a *SkewError unless allowMajorSkew is set, when it
+// is logged as well. A server that does not report a parseable version
+// predates the check, and is logged.
+func CheckServer(server string, allowMajorSkew bool) error {
+	skew, err := Compare(Version, server)
+	if err != nil {
+		klog.Warningf("Cannot compare the kgo server version %q to client version %s: %v", server, Version, err)
+		return nil
+	}
+	switch skew {
+	case SkewMajor:
+		if !allowMajorSkew {
+			return &SkewError{Client: Version, Server: server}
+		}
+		klog.Warningf("kgo server version %s differs in major version from client version %s; behavior may differ", server, Version)
+	case SkewMinor:
+		klog.Warningf("kgo server version %s differs from client version %s; behavior may differ", server, Version)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		version string
+		want    Semver
+	}{
+		{"1.4.0", Semver{Major: 1, Minor: 4}},
+		{"v2.10.3", Semver{Major: 2, Minor: 10, Patch: 3}},
+		{"1.5.0-rc.1", Semver{Major: 1, Minor: 5, Prerelease: "rc.1"}},
+		{"0.0.0-dev+abc123", Semver{Prerelease: "dev"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.version)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v; expected %+v", tt.version, got, err, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"", "dev", "1.4", "1.4.0.1", "1.x.0", "1.-1.0"} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want Skew
+	}{
+		{"1.4.0", "1.4.0", SkewNone},
+		{"1.4.0", "v1.4.7", SkewNone},
+		{"1.4.0-rc.1", "1.4.0", SkewNone},
+		{"1.4.0", "1.5.0", SkewMinor},
+		{"1.4.0", "1.3.9", SkewMinor},
+		{"1.4.0", "2.0.0", SkewMajor},
+		{"2.0.0", "1.9.0", SkewMajor},
+	}
+	for _, tt := range tests {
+		got, err := Compare(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("Compare(%q, %q) = %v, %v; expected %v", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	if _, err := Compare("1.4.0", "dev"); err == nil {
+		t.Error("Expected an unparseable version to fail")
+	}
+}
+
+func TestCheckServer(t *testing.T) {
+	saved := Version
+	Version = "1.4.0"
+	t.Cleanup(func() { Version = saved })
+
+	for _, server := range []string{"1.4.2", "1.6.0", "unknown"} {
+		if err := CheckServer(server, false); err != nil {
+			t.Errorf("Expected server %s to be accepted, got %v", server, err)
+		}
+	}
+
+	err := CheckServer("2.0.0", false)
+	var skewErr *SkewError
+	if !errors.As(err, &skewErr) || skewErr.Client != "1.4.0" || skewErr.Server != "2.0.0" {
+		t.Errorf("Expected a SkewError for a major skew, got %v", err)
+	}
+	if err := CheckServer("2.0.0", true); err != nil {
+		t.Errorf("Expected an allowed major skew to be accepted, got %v", err)
+	}
+}
@@ -22,10 +22,70 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Resource types that WatchResources can watch
+type ResourceType int32
+
+const (
+	ResourceType_RESOURCE_TYPE_UNSPECIFIED ResourceType = 0
+	ResourceType_RESOURCE_TYPE_PODS        ResourceType = 1
+	ResourceType_RESOURCE_TYPE_DEPLOYMENTS ResourceType = 2
+	ResourceType_RESOURCE_TYPE_SERVICES    ResourceType = 3
+	ResourceType_RESOURCE_TYPE_CONFIGMAPS  ResourceType = 4
+)
+
+// Enum value maps for ResourceType.
+var (
+	ResourceType_name = map[int32]string{
+		0: "RESOURCE_TYPE_UNSPECIFIED",
+		1: "RESOURCE_TYPE_PODS",
+		2: "RESOURCE_TYPE_DEPLOYMENTS",
+		3: "RESOURCE_TYPE_SERVICES",
+		4: "RESOURCE_TYPE_CONFIGMAPS",
+	}
+	ResourceType_value = map[string]int32{
+		"RESOURCE_TYPE_UNSPECIFIED": 0,
+		"RESOURCE_TYPE_PODS":        1,
+		"RESOURCE_TYPE_DEPLOYMENTS": 2,
+		"RESOURCE_TYPE_SERVICES":    3,
+		"RESOURCE_TYPE_CONFIGMAPS":  4,
+	}
+)
+
+func (x ResourceType) Enum() *ResourceType {
+	p := new(ResourceType)
+	*p = x
+	return p
+}
+
+func (x ResourceType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResourceType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_k8s_proto_enumTypes[0].Descriptor()
+}
+
+func (ResourceType) Type() protoreflect.EnumType {
+	return &file_proto_k8s_proto_enumTypes[0]
+}
+
+func (x ResourceType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResourceType.Descriptor instead.
+func (ResourceType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{0}
+}
+
 // Common request/response messages
 type ListRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Namespace string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Maximum number of items to return; 0 returns everything
+	PageSize int64 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token from a previous response to continue a paged list
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -67,7 +127,21 @@ func (x *ListRequest) GetNamespace() string {
 	return ""
 }
 
-type DeleteRequest struct {
+func (x *ListRequest) GetPageSize() int64 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type GetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
@@ -75,9 +149,63 @@ type DeleteRequest struct {
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Namespace string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Must equal namespace when it is protected
+	Confirm       string `protobuf:"bytes,3,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
 func (x *DeleteRequest) Reset() {
 	*x = DeleteRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[1]
+	mi := &file_proto_k8s_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -89,7 +217,7 @@ func (x *DeleteRequest) String() string {
 func (*DeleteRequest) ProtoMessage() {}
 
 func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[1]
+	mi := &file_proto_k8s_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -102,7 +230,7 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
 func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{1}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *DeleteRequest) GetNamespace() string {
@@ -119,17 +247,28 @@ func (x *DeleteRequest) GetName() string {
 	return ""
 }
 
+func (x *DeleteRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 // Pod messages
 type PodListResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Pods          []*Pod                 `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Pods  []*Pod                 `protobuf:"bytes,1,rep,name=pods,proto3" json:"pods,omitempty"`
+	// Token for the next page; empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Resource version of the list, usable to start a watch
+	ResourceVersion string `protobuf:"bytes,3,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *PodListResponse) Reset() {
 	*x = PodListResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[2]
+	mi := &file_proto_k8s_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -141,7 +280,7 @@ func (x *PodListResponse) String() string {
 func (*PodListResponse) ProtoMessage() {}
 
 func (x *PodListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[2]
+	mi := &file_proto_k8s_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -154,7 +293,7 @@ func (x *PodListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodListResponse.ProtoReflect.Descriptor instead.
 func (*PodListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{2}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *PodListResponse) GetPods() []*Pod {
@@ -164,6 +303,20 @@ func (x *PodListResponse) GetPods() []*Pod {
 	return nil
 }
 
+func (x *PodListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *PodListResponse) GetResourceVersion() string {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return ""
+}
+
 type Pod struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -179,7 +332,7 @@ type Pod struct {
 
 func (x *Pod) Reset() {
 	*x = Pod{}
-	mi := &file_proto_k8s_proto_msgTypes[3]
+	mi := &file_proto_k8s_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -191,7 +344,7 @@ func (x *Pod) String() string {
 func (*Pod) ProtoMessage() {}
 
 func (x *Pod) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[3]
+	mi := &file_proto_k8s_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -204,7 +357,7 @@ func (x *Pod) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Pod.ProtoReflect.Descriptor instead.
 func (*Pod) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{3}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *Pod) GetName() string {
@@ -268,7 +421,7 @@ type Container struct {
 
 func (x *Container) Reset() {
 	*x = Container{}
-	mi := &file_proto_k8s_proto_msgTypes[4]
+	mi := &file_proto_k8s_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -280,7 +433,7 @@ func (x *Container) String() string {
 func (*Container) ProtoMessage() {}
 
 func (x *Container) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[4]
+	mi := &file_proto_k8s_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -293,7 +446,7 @@ func (x *Container) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Container.ProtoReflect.Descriptor instead.
 func (*Container) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{4}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Container) GetName() string {
@@ -334,7 +487,7 @@ type Port struct {
 
 func (x *Port) Reset() {
 	*x = Port{}
-	mi := &file_proto_k8s_proto_msgTypes[5]
+	mi := &file_proto_k8s_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -346,7 +499,7 @@ func (x *Port) String() string {
 func (*Port) ProtoMessage() {}
 
 func (x *Port) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[5]
+	mi := &file_proto_k8s_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -359,7 +512,7 @@ func (x *Port) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Port.ProtoReflect.Descriptor instead.
 func (*Port) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{5}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Port) GetProtocol() string {
@@ -380,13 +533,14 @@ type CreatePodRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Spec          *PodSpec               `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,3,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreatePodRequest) Reset() {
 	*x = CreatePodRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[6]
+	mi := &file_proto_k8s_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -398,7 +552,7 @@ func (x *CreatePodRequest) String() string {
 func (*CreatePodRequest) ProtoMessage() {}
 
 func (x *CreatePodRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[6]
+	mi := &file_proto_k8s_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -411,7 +565,7 @@ func (x *CreatePodRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreatePodRequest.ProtoReflect.Descriptor instead.
 func (*CreatePodRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{6}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CreatePodRequest) GetNamespace() string {
@@ -428,6 +582,13 @@ func (x *CreatePodRequest) GetSpec() *PodSpec {
 	return nil
 }
 
+func (x *CreatePodRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type PodSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -439,7 +600,7 @@ type PodSpec struct {
 
 func (x *PodSpec) Reset() {
 	*x = PodSpec{}
-	mi := &file_proto_k8s_proto_msgTypes[7]
+	mi := &file_proto_k8s_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -451,7 +612,7 @@ func (x *PodSpec) String() string {
 func (*PodSpec) ProtoMessage() {}
 
 func (x *PodSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[7]
+	mi := &file_proto_k8s_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -464,7 +625,7 @@ func (x *PodSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodSpec.ProtoReflect.Descriptor instead.
 func (*PodSpec) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{7}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *PodSpec) GetName() string {
@@ -499,7 +660,7 @@ type ContainerSpec struct {
 
 func (x *ContainerSpec) Reset() {
 	*x = ContainerSpec{}
-	mi := &file_proto_k8s_proto_msgTypes[8]
+	mi := &file_proto_k8s_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -511,7 +672,7 @@ func (x *ContainerSpec) String() string {
 func (*ContainerSpec) ProtoMessage() {}
 
 func (x *ContainerSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[8]
+	mi := &file_proto_k8s_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -524,7 +685,7 @@ func (x *ContainerSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ContainerSpec.ProtoReflect.Descriptor instead.
 func (*ContainerSpec) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{8}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ContainerSpec) GetName() string {
@@ -558,7 +719,7 @@ type PortSpec struct {
 
 func (x *PortSpec) Reset() {
 	*x = PortSpec{}
-	mi := &file_proto_k8s_proto_msgTypes[9]
+	mi := &file_proto_k8s_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -570,7 +731,7 @@ func (x *PortSpec) String() string {
 func (*PortSpec) ProtoMessage() {}
 
 func (x *PortSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[9]
+	mi := &file_proto_k8s_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -583,7 +744,7 @@ func (x *PortSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PortSpec.ProtoReflect.Descriptor instead.
 func (*PortSpec) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{9}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *PortSpec) GetProtocol() string {
@@ -605,13 +766,14 @@ type UpdatePodRequest struct {
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Spec          *PodSpec               `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,4,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdatePodRequest) Reset() {
 	*x = UpdatePodRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[10]
+	mi := &file_proto_k8s_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -623,7 +785,7 @@ func (x *UpdatePodRequest) String() string {
 func (*UpdatePodRequest) ProtoMessage() {}
 
 func (x *UpdatePodRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[10]
+	mi := &file_proto_k8s_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -636,7 +798,7 @@ func (x *UpdatePodRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdatePodRequest.ProtoReflect.Descriptor instead.
 func (*UpdatePodRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{10}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *UpdatePodRequest) GetNamespace() string {
@@ -660,6 +822,13 @@ func (x *UpdatePodRequest) GetSpec() *PodSpec {
 	return nil
 }
 
+func (x *UpdatePodRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type PodResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Pod           *Pod                   `protobuf:"bytes,1,opt,name=pod,proto3" json:"pod,omitempty"`
@@ -669,7 +838,7 @@ type PodResponse struct {
 
 func (x *PodResponse) Reset() {
 	*x = PodResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[11]
+	mi := &file_proto_k8s_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -681,7 +850,7 @@ func (x *PodResponse) String() string {
 func (*PodResponse) ProtoMessage() {}
 
 func (x *PodResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[11]
+	mi := &file_proto_k8s_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -694,7 +863,7 @@ func (x *PodResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodResponse.ProtoReflect.Descriptor instead.
 func (*PodResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{11}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *PodResponse) GetPod() *Pod {
@@ -714,7 +883,7 @@ type DeploymentListResponse struct {
 
 func (x *DeploymentListResponse) Reset() {
 	*x = DeploymentListResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[12]
+	mi := &file_proto_k8s_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -726,7 +895,7 @@ func (x *DeploymentListResponse) String() string {
 func (*DeploymentListResponse) ProtoMessage() {}
 
 func (x *DeploymentListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[12]
+	mi := &file_proto_k8s_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,7 +908,7 @@ func (x *DeploymentListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeploymentListResponse.ProtoReflect.Descriptor instead.
 func (*DeploymentListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{12}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *DeploymentListResponse) GetDeployments() []*Deployment {
@@ -764,7 +933,7 @@ type Deployment struct {
 
 func (x *Deployment) Reset() {
 	*x = Deployment{}
-	mi := &file_proto_k8s_proto_msgTypes[13]
+	mi := &file_proto_k8s_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -776,7 +945,7 @@ func (x *Deployment) String() string {
 func (*Deployment) ProtoMessage() {}
 
 func (x *Deployment) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[13]
+	mi := &file_proto_k8s_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -789,7 +958,7 @@ func (x *Deployment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Deployment.ProtoReflect.Descriptor instead.
 func (*Deployment) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{13}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *Deployment) GetName() string {
@@ -845,13 +1014,14 @@ type CreateDeploymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Spec          *DeploymentSpec        `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,3,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateDeploymentRequest) Reset() {
 	*x = CreateDeploymentRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[14]
+	mi := &file_proto_k8s_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -863,7 +1033,7 @@ func (x *CreateDeploymentRequest) String() string {
 func (*CreateDeploymentRequest) ProtoMessage() {}
 
 func (x *CreateDeploymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[14]
+	mi := &file_proto_k8s_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -876,7 +1046,7 @@ func (x *CreateDeploymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateDeploymentRequest.ProtoReflect.Descriptor instead.
 func (*CreateDeploymentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{14}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *CreateDeploymentRequest) GetNamespace() string {
@@ -893,6 +1063,13 @@ func (x *CreateDeploymentRequest) GetSpec() *DeploymentSpec {
 	return nil
 }
 
+func (x *CreateDeploymentRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type DeploymentSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -905,7 +1082,7 @@ type DeploymentSpec struct {
 
 func (x *DeploymentSpec) Reset() {
 	*x = DeploymentSpec{}
-	mi := &file_proto_k8s_proto_msgTypes[15]
+	mi := &file_proto_k8s_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -917,7 +1094,7 @@ func (x *DeploymentSpec) String() string {
 func (*DeploymentSpec) ProtoMessage() {}
 
 func (x *DeploymentSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[15]
+	mi := &file_proto_k8s_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -930,7 +1107,7 @@ func (x *DeploymentSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeploymentSpec.ProtoReflect.Descriptor instead.
 func (*DeploymentSpec) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{15}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *DeploymentSpec) GetName() string {
@@ -966,13 +1143,14 @@ type UpdateDeploymentRequest struct {
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Spec          *DeploymentSpec        `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,4,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateDeploymentRequest) Reset() {
 	*x = UpdateDeploymentRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[16]
+	mi := &file_proto_k8s_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -984,7 +1162,7 @@ func (x *UpdateDeploymentRequest) String() string {
 func (*UpdateDeploymentRequest) ProtoMessage() {}
 
 func (x *UpdateDeploymentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[16]
+	mi := &file_proto_k8s_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -997,7 +1175,7 @@ func (x *UpdateDeploymentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateDeploymentRequest.ProtoReflect.Descriptor instead.
 func (*UpdateDeploymentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{16}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *UpdateDeploymentRequest) GetNamespace() string {
@@ -1021,6 +1199,13 @@ func (x *UpdateDeploymentRequest) GetSpec() *DeploymentSpec {
 	return nil
 }
 
+func (x *UpdateDeploymentRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type DeploymentResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Deployment    *Deployment            `protobuf:"bytes,1,opt,name=deployment,proto3" json:"deployment,omitempty"`
@@ -1030,7 +1215,7 @@ type DeploymentResponse struct {
 
 func (x *DeploymentResponse) Reset() {
 	*x = DeploymentResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[17]
+	mi := &file_proto_k8s_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1042,7 +1227,7 @@ func (x *DeploymentResponse) String() string {
 func (*DeploymentResponse) ProtoMessage() {}
 
 func (x *DeploymentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[17]
+	mi := &file_proto_k8s_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1055,7 +1240,7 @@ func (x *DeploymentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeploymentResponse.ProtoReflect.Descriptor instead.
 func (*DeploymentResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{17}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *DeploymentResponse) GetDeployment() *Deployment {
@@ -1075,7 +1260,7 @@ type ServiceListResponse struct {
 
 func (x *ServiceListResponse) Reset() {
 	*x = ServiceListResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[18]
+	mi := &file_proto_k8s_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1087,7 +1272,7 @@ func (x *ServiceListResponse) String() string {
 func (*ServiceListResponse) ProtoMessage() {}
 
 func (x *ServiceListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[18]
+	mi := &file_proto_k8s_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1100,7 +1285,7 @@ func (x *ServiceListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceListResponse.ProtoReflect.Descriptor instead.
 func (*ServiceListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{18}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *ServiceListResponse) GetServices() []*Service {
@@ -1126,7 +1311,7 @@ type Service struct {
 
 func (x *Service) Reset() {
 	*x = Service{}
-	mi := &file_proto_k8s_proto_msgTypes[19]
+	mi := &file_proto_k8s_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1138,7 +1323,7 @@ func (x *Service) String() string {
 func (*Service) ProtoMessage() {}
 
 func (x *Service) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[19]
+	mi := &file_proto_k8s_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1151,7 +1336,7 @@ func (x *Service) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Service.ProtoReflect.Descriptor instead.
 func (*Service) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{19}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *Service) GetName() string {
@@ -1214,13 +1399,14 @@ type CreateServiceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Spec          *ServiceSpec           `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,3,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateServiceRequest) Reset() {
 	*x = CreateServiceRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[20]
+	mi := &file_proto_k8s_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1232,7 +1418,7 @@ func (x *CreateServiceRequest) String() string {
 func (*CreateServiceRequest) ProtoMessage() {}
 
 func (x *CreateServiceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[20]
+	mi := &file_proto_k8s_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1245,7 +1431,7 @@ func (x *CreateServiceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateServiceRequest.ProtoReflect.Descriptor instead.
 func (*CreateServiceRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{20}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *CreateServiceRequest) GetNamespace() string {
@@ -1262,6 +1448,13 @@ func (x *CreateServiceRequest) GetSpec() *ServiceSpec {
 	return nil
 }
 
+func (x *CreateServiceRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type ServiceSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -1274,7 +1467,7 @@ type ServiceSpec struct {
 
 func (x *ServiceSpec) Reset() {
 	*x = ServiceSpec{}
-	mi := &file_proto_k8s_proto_msgTypes[21]
+	mi := &file_proto_k8s_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1286,7 +1479,7 @@ func (x *ServiceSpec) String() string {
 func (*ServiceSpec) ProtoMessage() {}
 
 func (x *ServiceSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[21]
+	mi := &file_proto_k8s_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1299,7 +1492,7 @@ func (x *ServiceSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceSpec.ProtoReflect.Descriptor instead.
 func (*ServiceSpec) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{21}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ServiceSpec) GetName() string {
@@ -1335,13 +1528,14 @@ type UpdateServiceRequest struct {
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Spec          *ServiceSpec           `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,4,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UpdateServiceRequest) Reset() {
 	*x = UpdateServiceRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[22]
+	mi := &file_proto_k8s_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1353,7 +1547,7 @@ func (x *UpdateServiceRequest) String() string {
 func (*UpdateServiceRequest) ProtoMessage() {}
 
 func (x *UpdateServiceRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[22]
+	mi := &file_proto_k8s_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1366,7 +1560,7 @@ func (x *UpdateServiceRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateServiceRequest.ProtoReflect.Descriptor instead.
 func (*UpdateServiceRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{22}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *UpdateServiceRequest) GetNamespace() string {
@@ -1390,6 +1584,13 @@ func (x *UpdateServiceRequest) GetSpec() *ServiceSpec {
 	return nil
 }
 
+func (x *UpdateServiceRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type ServiceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Service       *Service               `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
@@ -1399,7 +1600,7 @@ type ServiceResponse struct {
 
 func (x *ServiceResponse) Reset() {
 	*x = ServiceResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[23]
+	mi := &file_proto_k8s_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1411,7 +1612,7 @@ func (x *ServiceResponse) String() string {
 func (*ServiceResponse) ProtoMessage() {}
 
 func (x *ServiceResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[23]
+	mi := &file_proto_k8s_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1424,7 +1625,7 @@ func (x *ServiceResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceResponse.ProtoReflect.Descriptor instead.
 func (*ServiceResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{23}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *ServiceResponse) GetService() *Service {
@@ -1444,7 +1645,7 @@ type ConfigMapListResponse struct {
 
 func (x *ConfigMapListResponse) Reset() {
 	*x = ConfigMapListResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[24]
+	mi := &file_proto_k8s_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1456,7 +1657,7 @@ func (x *ConfigMapListResponse) String() string {
 func (*ConfigMapListResponse) ProtoMessage() {}
 
 func (x *ConfigMapListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[24]
+	mi := &file_proto_k8s_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1469,7 +1670,7 @@ func (x *ConfigMapListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigMapListResponse.ProtoReflect.Descriptor instead.
 func (*ConfigMapListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{24}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *ConfigMapListResponse) GetConfigmaps() []*ConfigMap {
@@ -1492,7 +1693,7 @@ type ConfigMap struct {
 
 func (x *ConfigMap) Reset() {
 	*x = ConfigMap{}
-	mi := &file_proto_k8s_proto_msgTypes[25]
+	mi := &file_proto_k8s_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1504,7 +1705,7 @@ func (x *ConfigMap) String() string {
 func (*ConfigMap) ProtoMessage() {}
 
 func (x *ConfigMap) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[25]
+	mi := &file_proto_k8s_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1517,7 +1718,7 @@ func (x *ConfigMap) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigMap.ProtoReflect.Descriptor instead.
 func (*ConfigMap) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{25}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ConfigMap) GetName() string {
@@ -1559,13 +1760,14 @@ type CreateConfigMapRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	Spec          *ConfigMapSpec         `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,3,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateConfigMapRequest) Reset() {
 	*x = CreateConfigMapRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[26]
+	mi := &file_proto_k8s_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1577,7 +1779,7 @@ func (x *CreateConfigMapRequest) String() string {
 func (*CreateConfigMapRequest) ProtoMessage() {}
 
 func (x *CreateConfigMapRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[26]
+	mi := &file_proto_k8s_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1590,7 +1792,7 @@ func (x *CreateConfigMapRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateConfigMapRequest.ProtoReflect.Descriptor instead.
 func (*CreateConfigMapRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{26}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *CreateConfigMapRequest) GetNamespace() string {
@@ -1607,6 +1809,13 @@ func (x *CreateConfigMapRequest) GetSpec() *ConfigMapSpec {
 	return nil
 }
 
+func (x *CreateConfigMapRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
 type ConfigMapSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -1618,7 +1827,7 @@ type ConfigMapSpec struct {
 
 func (x *ConfigMapSpec) Reset() {
 	*x = ConfigMapSpec{}
-	mi := &file_proto_k8s_proto_msgTypes[27]
+	mi := &file_proto_k8s_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1630,7 +1839,7 @@ func (x *ConfigMapSpec) String() string {
 func (*ConfigMapSpec) ProtoMessage() {}
 
 func (x *ConfigMapSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[27]
+	mi := &file_proto_k8s_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1643,7 +1852,7 @@ func (x *ConfigMapSpec) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigMapSpec.ProtoReflect.Descriptor instead.
 func (*ConfigMapSpec) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{27}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *ConfigMapSpec) GetName() string {
@@ -1660,37 +1869,835 @@ func (x *ConfigMapSpec) GetData() map[string]string {
 	return nil
 }
 
-func (x *ConfigMapSpec) GetLabels() map[string]string {
+func (x *ConfigMapSpec) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type UpdateConfigMapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Spec          *ConfigMapSpec         `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+	Confirm       string                 `protobuf:"bytes,4,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateConfigMapRequest) Reset() {
+	*x = UpdateConfigMapRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateConfigMapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateConfigMapRequest) ProtoMessage() {}
+
+func (x *UpdateConfigMapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateConfigMapRequest.ProtoReflect.Descriptor instead.
+func (*UpdateConfigMapRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UpdateConfigMapRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *UpdateConfigMapRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateConfigMapRequest) GetSpec() *ConfigMapSpec {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *UpdateConfigMapRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
+type ConfigMapResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Configmap     *ConfigMap             `protobuf:"bytes,1,opt,name=configmap,proto3" json:"configmap,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigMapResponse) Reset() {
+	*x = ConfigMapResponse{}
+	mi := &file_proto_k8s_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigMapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigMapResponse) ProtoMessage() {}
+
+func (x *ConfigMapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigMapResponse.ProtoReflect.Descriptor instead.
+func (*ConfigMapResponse) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ConfigMapResponse) GetConfigmap() *ConfigMap {
+	if x != nil {
+		return x.Configmap
+	}
+	return nil
+}
+
+// Workload messages
+type ScaleRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// deployments or statefulsets
+	Kind      string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Replicas  int32  `protobuf:"varint,4,opt,name=replicas,proto3" json:"replicas,omitempty"`
+	// Must equal namespace when it is protected
+	Confirm       string `protobuf:"bytes,5,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScaleRequest) Reset() {
+	*x = ScaleRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScaleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScaleRequest) ProtoMessage() {}
+
+func (x *ScaleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScaleRequest.ProtoReflect.Descriptor instead.
+func (*ScaleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ScaleRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *ScaleRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ScaleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ScaleRequest) GetReplicas() int32 {
+	if x != nil {
+		return x.Replicas
+	}
+	return 0
+}
+
+func (x *ScaleRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
+type RestartRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// deployments, statefulsets or daemonsets
+	Kind      string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Must equal namespace when it is protected
+	Confirm       string `protobuf:"bytes,4,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartRequest) Reset() {
+	*x = RestartRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartRequest) ProtoMessage() {}
+
+func (x *RestartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartRequest.ProtoReflect.Descriptor instead.
+func (*RestartRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RestartRequest) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RestartRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RestartRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RestartRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
+type RestartResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// RFC 3339 time set in the kubectl.kubernetes.io/restartedAt annotation
+	RestartedAt string `protobuf:"bytes,1,opt,name=restarted_at,json=restartedAt,proto3" json:"restarted_at,omitempty"`
+	// Which pods the restart leaves alone, e.g. below a statefulset partition
+	Warning       string `protobuf:"bytes,2,opt,name=warning,proto3" json:"warning,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartResponse) Reset() {
+	*x = RestartResponse{}
+	mi := &file_proto_k8s_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartResponse) ProtoMessage() {}
+
+func (x *RestartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartResponse.ProtoReflect.Descriptor instead.
+func (*RestartResponse) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *RestartResponse) GetRestartedAt() string {
+	if x != nil {
+		return x.RestartedAt
+	}
+	return ""
+}
+
+func (x *RestartResponse) GetWarning() string {
+	if x != nil {
+		return x.Warning
+	}
+	return ""
+}
+
+type RolloutStatusRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Namespace string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// How long to stream before giving up; 10 minutes when 0
+	TimeoutSeconds int32 `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RolloutStatusRequest) Reset() {
+	*x = RolloutStatusRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolloutStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolloutStatusRequest) ProtoMessage() {}
+
+func (x *RolloutStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolloutStatusRequest.ProtoReflect.Descriptor instead.
+func (*RolloutStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RolloutStatusRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RolloutStatusRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RolloutStatusRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type RolloutStatusResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	DeploymentName    string                 `protobuf:"bytes,1,opt,name=deployment_name,json=deploymentName,proto3" json:"deployment_name,omitempty"`
+	DesiredReplicas   int32                  `protobuf:"varint,2,opt,name=desired_replicas,json=desiredReplicas,proto3" json:"desired_replicas,omitempty"`
+	UpdatedReplicas   int32                  `protobuf:"varint,3,opt,name=updated_replicas,json=updatedReplicas,proto3" json:"updated_replicas,omitempty"`
+	ReadyReplicas     int32                  `protobuf:"varint,4,opt,name=ready_replicas,json=readyReplicas,proto3" json:"ready_replicas,omitempty"`
+	AvailableReplicas int32                  `protobuf:"varint,5,opt,name=available_replicas,json=availableReplicas,proto3" json:"available_replicas,omitempty"`
+	IsPaused          bool                   `protobuf:"varint,6,opt,name=is_paused,json=isPaused,proto3" json:"is_paused,omitempty"`
+	Conditions        []*DeploymentCondition `protobuf:"bytes,7,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	// The ReplicaSets of the deployment, the newest revision first
+	Replicasets []*ReplicaSetSummary `protobuf:"bytes,8,rep,name=replicasets,proto3" json:"replicasets,omitempty"`
+	// Every replica runs the current template and is available
+	Complete bool `protobuf:"varint,9,opt,name=complete,proto3" json:"complete,omitempty"`
+	// The rollout exceeded its progress deadline
+	Failed bool `protobuf:"varint,10,opt,name=failed,proto3" json:"failed,omitempty"`
+	// What the rollout waits for, as kubectl rollout status reports it
+	Message       string `protobuf:"bytes,11,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolloutStatusResponse) Reset() {
+	*x = RolloutStatusResponse{}
+	mi := &file_proto_k8s_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolloutStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolloutStatusResponse) ProtoMessage() {}
+
+func (x *RolloutStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolloutStatusResponse.ProtoReflect.Descriptor instead.
+func (*RolloutStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *RolloutStatusResponse) GetDeploymentName() string {
+	if x != nil {
+		return x.DeploymentName
+	}
+	return ""
+}
+
+func (x *RolloutStatusResponse) GetDesiredReplicas() int32 {
+	if x != nil {
+		return x.DesiredReplicas
+	}
+	return 0
+}
+
+func (x *RolloutStatusResponse) GetUpdatedReplicas() int32 {
+	if x != nil {
+		return x.UpdatedReplicas
+	}
+	return 0
+}
+
+func (x *RolloutStatusResponse) GetReadyReplicas() int32 {
+	if x != nil {
+		return x.ReadyReplicas
+	}
+	return 0
+}
+
+func (x *RolloutStatusResponse) GetAvailableReplicas() int32 {
+	if x != nil {
+		return x.AvailableReplicas
+	}
+	return 0
+}
+
+func (x *RolloutStatusResponse) GetIsPaused() bool {
+	if x != nil {
+		return x.IsPaused
+	}
+	return false
+}
+
+func (x *RolloutStatusResponse) GetConditions() []*DeploymentCondition {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *RolloutStatusResponse) GetReplicasets() []*ReplicaSetSummary {
+	if x != nil {
+		return x.Replicasets
+	}
+	return nil
+}
+
+func (x *RolloutStatusResponse) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+func (x *RolloutStatusResponse) GetFailed() bool {
+	if x != nil {
+		return x.Failed
+	}
+	return false
+}
+
+func (x *RolloutStatusResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type DeploymentCondition struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Type    string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Status  string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Reason  string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// RFC 3339
+	LastUpdateTime string `protobuf:"bytes,5,opt,name=last_update_time,json=lastUpdateTime,proto3" json:"last_update_time,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeploymentCondition) Reset() {
+	*x = DeploymentCondition{}
+	mi := &file_proto_k8s_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeploymentCondition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeploymentCondition) ProtoMessage() {}
+
+func (x *DeploymentCondition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeploymentCondition.ProtoReflect.Descriptor instead.
+func (*DeploymentCondition) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DeploymentCondition) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeploymentCondition) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DeploymentCondition) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DeploymentCondition) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *DeploymentCondition) GetLastUpdateTime() string {
+	if x != nil {
+		return x.LastUpdateTime
+	}
+	return ""
+}
+
+type ReplicaSetSummary struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Revision          int64                  `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	DesiredReplicas   int32                  `protobuf:"varint,3,opt,name=desired_replicas,json=desiredReplicas,proto3" json:"desired_replicas,omitempty"`
+	ReadyReplicas     int32                  `protobuf:"varint,4,opt,name=ready_replicas,json=readyReplicas,proto3" json:"ready_replicas,omitempty"`
+	AvailableReplicas int32                  `protobuf:"varint,5,opt,name=available_replicas,json=availableReplicas,proto3" json:"available_replicas,omitempty"`
+	// Runs the deployment's current pod template
+	IsCurrent     bool `protobuf:"varint,6,opt,name=is_current,json=isCurrent,proto3" json:"is_current,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicaSetSummary) Reset() {
+	*x = ReplicaSetSummary{}
+	mi := &file_proto_k8s_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicaSetSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicaSetSummary) ProtoMessage() {}
+
+func (x *ReplicaSetSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicaSetSummary.ProtoReflect.Descriptor instead.
+func (*ReplicaSetSummary) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ReplicaSetSummary) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReplicaSetSummary) GetRevision() int64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *ReplicaSetSummary) GetDesiredReplicas() int32 {
+	if x != nil {
+		return x.DesiredReplicas
+	}
+	return 0
+}
+
+func (x *ReplicaSetSummary) GetReadyReplicas() int32 {
+	if x != nil {
+		return x.ReadyReplicas
+	}
+	return 0
+}
+
+func (x *ReplicaSetSummary) GetAvailableReplicas() int32 {
+	if x != nil {
+		return x.AvailableReplicas
+	}
+	return 0
+}
+
+func (x *ReplicaSetSummary) GetIsCurrent() bool {
+	if x != nil {
+		return x.IsCurrent
+	}
+	return false
+}
+
+// Manifest messages
+type ApplyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// YAML documents separated by ---. ApplyYAMLStream concatenates the
+	// yaml_content of its messages.
+	YamlContent string `protobuf:"bytes,1,opt,name=yaml_content,json=yamlContent,proto3" json:"yaml_content,omitempty"`
+	// The other fields are read from the first message of ApplyYAMLStream
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Reports what applying would do without changing the cluster
+	DryRun bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Manager of the applied fields; kgo when empty
+	FieldManager string `protobuf:"bytes,4,opt,name=field_manager,json=fieldManager,proto3" json:"field_manager,omitempty"`
+	// Must equal namespace when it is protected, unless dry_run is set
+	Confirm       string `protobuf:"bytes,5,opt,name=confirm,proto3" json:"confirm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyRequest) Reset() {
+	*x = ApplyRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRequest) ProtoMessage() {}
+
+func (x *ApplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ApplyRequest) GetYamlContent() string {
+	if x != nil {
+		return x.YamlContent
+	}
+	return ""
+}
+
+func (x *ApplyRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ApplyRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ApplyRequest) GetFieldManager() string {
+	if x != nil {
+		return x.FieldManager
+	}
+	return ""
+}
+
+func (x *ApplyRequest) GetConfirm() string {
+	if x != nil {
+		return x.Confirm
+	}
+	return ""
+}
+
+type ApplyResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One result per document, in the order of the manifest
+	Results       []*ApplyResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyResponse) Reset() {
+	*x = ApplyResponse{}
+	mi := &file_proto_k8s_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyResponse) ProtoMessage() {}
+
+func (x *ApplyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyResponse.ProtoReflect.Descriptor instead.
+func (*ApplyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ApplyResponse) GetResults() []*ApplyResult {
 	if x != nil {
-		return x.Labels
+		return x.Results
 	}
 	return nil
 }
 
-type UpdateConfigMapRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Spec          *ConfigMapSpec         `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+type ApplyResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Kind      string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// created, updated or unchanged; empty when error is set
+	Action string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	// Why the document was not applied
+	Error         string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateConfigMapRequest) Reset() {
-	*x = UpdateConfigMapRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[28]
+func (x *ApplyResult) Reset() {
+	*x = ApplyResult{}
+	mi := &file_proto_k8s_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateConfigMapRequest) String() string {
+func (x *ApplyResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateConfigMapRequest) ProtoMessage() {}
+func (*ApplyResult) ProtoMessage() {}
 
-func (x *UpdateConfigMapRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[28]
+func (x *ApplyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1701,54 +2708,70 @@ func (x *UpdateConfigMapRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateConfigMapRequest.ProtoReflect.Descriptor instead.
-func (*UpdateConfigMapRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use ApplyResult.ProtoReflect.Descriptor instead.
+func (*ApplyResult) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *UpdateConfigMapRequest) GetNamespace() string {
+func (x *ApplyResult) GetKind() string {
 	if x != nil {
-		return x.Namespace
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *UpdateConfigMapRequest) GetName() string {
+func (x *ApplyResult) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *UpdateConfigMapRequest) GetSpec() *ConfigMapSpec {
+func (x *ApplyResult) GetNamespace() string {
 	if x != nil {
-		return x.Spec
+		return x.Namespace
 	}
-	return nil
+	return ""
 }
 
-type ConfigMapResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Configmap     *ConfigMap             `protobuf:"bytes,1,opt,name=configmap,proto3" json:"configmap,omitempty"`
+func (x *ApplyResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ApplyResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Version messages
+type VersionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Semantic version, e.g. 1.4.0
+	Version       string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConfigMapResponse) Reset() {
-	*x = ConfigMapResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[29]
+func (x *VersionResponse) Reset() {
+	*x = VersionResponse{}
+	mi := &file_proto_k8s_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConfigMapResponse) String() string {
+func (x *VersionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConfigMapResponse) ProtoMessage() {}
+func (*VersionResponse) ProtoMessage() {}
 
-func (x *ConfigMapResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[29]
+func (x *VersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1759,16 +2782,16 @@ func (x *ConfigMapResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConfigMapResponse.ProtoReflect.Descriptor instead.
-func (*ConfigMapResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use VersionResponse.ProtoReflect.Descriptor instead.
+func (*VersionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *ConfigMapResponse) GetConfigmap() *ConfigMap {
+func (x *VersionResponse) GetVersion() string {
 	if x != nil {
-		return x.Configmap
+		return x.Version
 	}
-	return nil
+	return ""
 }
 
 // Namespace messages
@@ -1781,7 +2804,7 @@ type NamespaceListResponse struct {
 
 func (x *NamespaceListResponse) Reset() {
 	*x = NamespaceListResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[30]
+	mi := &file_proto_k8s_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1793,7 +2816,7 @@ func (x *NamespaceListResponse) String() string {
 func (*NamespaceListResponse) ProtoMessage() {}
 
 func (x *NamespaceListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[30]
+	mi := &file_proto_k8s_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1806,7 +2829,7 @@ func (x *NamespaceListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NamespaceListResponse.ProtoReflect.Descriptor instead.
 func (*NamespaceListResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{30}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *NamespaceListResponse) GetNamespaces() []*Namespace {
@@ -1827,7 +2850,7 @@ type Namespace struct {
 
 func (x *Namespace) Reset() {
 	*x = Namespace{}
-	mi := &file_proto_k8s_proto_msgTypes[31]
+	mi := &file_proto_k8s_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1839,7 +2862,7 @@ func (x *Namespace) String() string {
 func (*Namespace) ProtoMessage() {}
 
 func (x *Namespace) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[31]
+	mi := &file_proto_k8s_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1852,7 +2875,7 @@ func (x *Namespace) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Namespace.ProtoReflect.Descriptor instead.
 func (*Namespace) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{31}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *Namespace) GetName() string {
@@ -1890,7 +2913,7 @@ type PodLogsRequest struct {
 
 func (x *PodLogsRequest) Reset() {
 	*x = PodLogsRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[32]
+	mi := &file_proto_k8s_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1902,7 +2925,7 @@ func (x *PodLogsRequest) String() string {
 func (*PodLogsRequest) ProtoMessage() {}
 
 func (x *PodLogsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[32]
+	mi := &file_proto_k8s_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1915,7 +2938,7 @@ func (x *PodLogsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodLogsRequest.ProtoReflect.Descriptor instead.
 func (*PodLogsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{32}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *PodLogsRequest) GetNamespace() string {
@@ -1962,7 +2985,7 @@ type LogsResponse struct {
 
 func (x *LogsResponse) Reset() {
 	*x = LogsResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[33]
+	mi := &file_proto_k8s_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1974,7 +2997,7 @@ func (x *LogsResponse) String() string {
 func (*LogsResponse) ProtoMessage() {}
 
 func (x *LogsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[33]
+	mi := &file_proto_k8s_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1987,7 +3010,7 @@ func (x *LogsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogsResponse.ProtoReflect.Descriptor instead.
 func (*LogsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{33}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *LogsResponse) GetLogs() string {
@@ -2009,7 +3032,7 @@ type ExecRequest struct {
 
 func (x *ExecRequest) Reset() {
 	*x = ExecRequest{}
-	mi := &file_proto_k8s_proto_msgTypes[34]
+	mi := &file_proto_k8s_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2021,7 +3044,7 @@ func (x *ExecRequest) String() string {
 func (*ExecRequest) ProtoMessage() {}
 
 func (x *ExecRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[34]
+	mi := &file_proto_k8s_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2034,7 +3057,7 @@ func (x *ExecRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecRequest.ProtoReflect.Descriptor instead.
 func (*ExecRequest) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{34}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *ExecRequest) GetNamespace() string {
@@ -2075,7 +3098,7 @@ type ExecResponse struct {
 
 func (x *ExecResponse) Reset() {
 	*x = ExecResponse{}
-	mi := &file_proto_k8s_proto_msgTypes[35]
+	mi := &file_proto_k8s_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2087,7 +3110,7 @@ func (x *ExecResponse) String() string {
 func (*ExecResponse) ProtoMessage() {}
 
 func (x *ExecResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_k8s_proto_msgTypes[35]
+	mi := &file_proto_k8s_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2100,7 +3123,7 @@ func (x *ExecResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecResponse.ProtoReflect.Descriptor instead.
 func (*ExecResponse) Descriptor() ([]byte, []int) {
-	return file_proto_k8s_proto_rawDescGZIP(), []int{35}
+	return file_proto_k8s_proto_rawDescGZIP(), []int{47}
 }
 
 func (x *ExecResponse) GetOutput() string {
@@ -2117,18 +3140,307 @@ func (x *ExecResponse) GetIsError() bool {
 	return false
 }
 
+// Watch messages
+type WatchRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Namespace string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Resource version to start after, e.g. of a list or of the last event
+	// received; empty starts at the current state
+	ResourceVersion string `protobuf:"bytes,2,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	// Type watched by WatchResources; ignored by WatchPods
+	ResourceType ResourceType `protobuf:"varint,3,opt,name=resource_type,json=resourceType,proto3,enum=k8s.ResourceType" json:"resource_type,omitempty"`
+	// Further types watched by WatchResources in the same stream
+	ResourceTypes []ResourceType `protobuf:"varint,4,rep,packed,name=resource_types,json=resourceTypes,proto3,enum=k8s.ResourceType" json:"resource_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_k8s_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *WatchRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetResourceVersion() string {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetResourceType() ResourceType {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ResourceType_RESOURCE_TYPE_UNSPECIFIED
+}
+
+func (x *WatchRequest) GetResourceTypes() []ResourceType {
+	if x != nil {
+		return x.ResourceTypes
+	}
+	return nil
+}
+
+type PodWatchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ADDED, MODIFIED, DELETED or BOOKMARK
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// The changed pod; unset for bookmarks
+	Pod *Pod `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
+	// Resource version to resume the watch from after this event
+	ResourceVersion string `protobuf:"bytes,3,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PodWatchEvent) Reset() {
+	*x = PodWatchEvent{}
+	mi := &file_proto_k8s_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PodWatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodWatchEvent) ProtoMessage() {}
+
+func (x *PodWatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodWatchEvent.ProtoReflect.Descriptor instead.
+func (*PodWatchEvent) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *PodWatchEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *PodWatchEvent) GetPod() *Pod {
+	if x != nil {
+		return x.Pod
+	}
+	return nil
+}
+
+func (x *PodWatchEvent) GetResourceVersion() string {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return ""
+}
+
+type ResourceEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ADDED, MODIFIED, DELETED or BOOKMARK
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Type of the changed resource, or of the watch a bookmark belongs to
+	ResourceType ResourceType `protobuf:"varint,2,opt,name=resource_type,json=resourceType,proto3,enum=k8s.ResourceType" json:"resource_type,omitempty"`
+	// Resource version to resume the watch of resource_type from
+	ResourceVersion string `protobuf:"bytes,3,opt,name=resource_version,json=resourceVersion,proto3" json:"resource_version,omitempty"`
+	// The changed resource; unset for bookmarks
+	//
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*ResourceEvent_Pod
+	//	*ResourceEvent_Deployment
+	//	*ResourceEvent_Service
+	//	*ResourceEvent_ConfigMap
+	Payload       isResourceEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceEvent) Reset() {
+	*x = ResourceEvent{}
+	mi := &file_proto_k8s_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceEvent) ProtoMessage() {}
+
+func (x *ResourceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_k8s_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceEvent.ProtoReflect.Descriptor instead.
+func (*ResourceEvent) Descriptor() ([]byte, []int) {
+	return file_proto_k8s_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ResourceEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ResourceEvent) GetResourceType() ResourceType {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ResourceType_RESOURCE_TYPE_UNSPECIFIED
+}
+
+func (x *ResourceEvent) GetResourceVersion() string {
+	if x != nil {
+		return x.ResourceVersion
+	}
+	return ""
+}
+
+func (x *ResourceEvent) GetPayload() isResourceEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ResourceEvent) GetPod() *Pod {
+	if x != nil {
+		if x, ok := x.Payload.(*ResourceEvent_Pod); ok {
+			return x.Pod
+		}
+	}
+	return nil
+}
+
+func (x *ResourceEvent) GetDeployment() *Deployment {
+	if x != nil {
+		if x, ok := x.Payload.(*ResourceEvent_Deployment); ok {
+			return x.Deployment
+		}
+	}
+	return nil
+}
+
+func (x *ResourceEvent) GetService() *Service {
+	if x != nil {
+		if x, ok := x.Payload.(*ResourceEvent_Service); ok {
+			return x.Service
+		}
+	}
+	return nil
+}
+
+func (x *ResourceEvent) GetConfigMap() *ConfigMap {
+	if x != nil {
+		if x, ok := x.Payload.(*ResourceEvent_ConfigMap); ok {
+			return x.ConfigMap
+		}
+	}
+	return nil
+}
+
+type isResourceEvent_Payload interface {
+	isResourceEvent_Payload()
+}
+
+type ResourceEvent_Pod struct {
+	Pod *Pod `protobuf:"bytes,4,opt,name=pod,proto3,oneof"`
+}
+
+type ResourceEvent_Deployment struct {
+	Deployment *Deployment `protobuf:"bytes,5,opt,name=deployment,proto3,oneof"`
+}
+
+type ResourceEvent_Service struct {
+	Service *Service `protobuf:"bytes,6,opt,name=service,proto3,oneof"`
+}
+
+type ResourceEvent_ConfigMap struct {
+	ConfigMap *ConfigMap `protobuf:"bytes,7,opt,name=config_map,json=configMap,proto3,oneof"`
+}
+
+func (*ResourceEvent_Pod) isResourceEvent_Payload() {}
+
+func (*ResourceEvent_Deployment) isResourceEvent_Payload() {}
+
+func (*ResourceEvent_Service) isResourceEvent_Payload() {}
+
+func (*ResourceEvent_ConfigMap) isResourceEvent_Payload() {}
+
 var File_proto_k8s_proto protoreflect.FileDescriptor
 
 const file_proto_k8s_proto_rawDesc = "" +
 	"\n" +
-	"\x0fproto/k8s.proto\x12\x03k8s\x1a\x1bgoogle/protobuf/empty.proto\"+\n" +
+	"\x0fproto/k8s.proto\x12\x03k8s\x1a\x1bgoogle/protobuf/empty.proto\"g\n" +
 	"\vListRequest\x12\x1c\n" +
-	"\tnamespace\x18\x01 \x01(\tR\tnamespace\"A\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x03R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\">\n" +
+	"\n" +
+	"GetRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"[\n" +
 	"\rDeleteRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\"/\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aconfirm\x18\x03 \x01(\tR\aconfirm\"\x82\x01\n" +
 	"\x0fPodListResponse\x12\x1c\n" +
-	"\x04pods\x18\x01 \x03(\v2\b.k8s.PodR\x04pods\"\x8e\x02\n" +
+	"\x04pods\x18\x01 \x03(\v2\b.k8s.PodR\x04pods\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12)\n" +
+	"\x10resource_version\x18\x03 \x01(\tR\x0fresourceVersion\"\x8e\x02\n" +
 	"\x03Pod\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
 	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x16\n" +
@@ -2149,10 +3461,11 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\x05ports\x18\x04 \x03(\v2\t.k8s.PortR\x05ports\"I\n" +
 	"\x04Port\x12\x1a\n" +
 	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12%\n" +
-	"\x0econtainer_port\x18\x02 \x01(\x05R\rcontainerPort\"R\n" +
+	"\x0econtainer_port\x18\x02 \x01(\x05R\rcontainerPort\"l\n" +
 	"\x10CreatePodRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12 \n" +
-	"\x04spec\x18\x02 \x01(\v2\f.k8s.PodSpecR\x04spec\"\xbe\x01\n" +
+	"\x04spec\x18\x02 \x01(\v2\f.k8s.PodSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x03 \x01(\tR\aconfirm\"\xbe\x01\n" +
 	"\aPodSpec\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x120\n" +
 	"\x06labels\x18\x02 \x03(\v2\x18.k8s.PodSpec.LabelsEntryR\x06labels\x122\n" +
@@ -2168,11 +3481,12 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\x05ports\x18\x03 \x03(\v2\r.k8s.PortSpecR\x05ports\"M\n" +
 	"\bPortSpec\x12\x1a\n" +
 	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12%\n" +
-	"\x0econtainer_port\x18\x02 \x01(\x05R\rcontainerPort\"f\n" +
+	"\x0econtainer_port\x18\x02 \x01(\x05R\rcontainerPort\"\x80\x01\n" +
 	"\x10UpdatePodRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\x04spec\x18\x03 \x01(\v2\f.k8s.PodSpecR\x04spec\")\n" +
+	"\x04spec\x18\x03 \x01(\v2\f.k8s.PodSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x04 \x01(\tR\aconfirm\")\n" +
 	"\vPodResponse\x12\x1a\n" +
 	"\x03pod\x18\x01 \x01(\v2\b.k8s.PodR\x03pod\"K\n" +
 	"\x16DeploymentListResponse\x121\n" +
@@ -2188,10 +3502,11 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\x06labels\x18\a \x03(\v2\x1b.k8s.Deployment.LabelsEntryR\x06labels\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"`\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"z\n" +
 	"\x17CreateDeploymentRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12'\n" +
-	"\x04spec\x18\x02 \x01(\v2\x13.k8s.DeploymentSpecR\x04spec\"\xde\x01\n" +
+	"\x04spec\x18\x02 \x01(\v2\x13.k8s.DeploymentSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x03 \x01(\tR\aconfirm\"\xde\x01\n" +
 	"\x0eDeploymentSpec\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
 	"\breplicas\x18\x02 \x01(\x05R\breplicas\x127\n" +
@@ -2199,11 +3514,12 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\btemplate\x18\x04 \x01(\v2\f.k8s.PodSpecR\btemplate\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"t\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8e\x01\n" +
 	"\x17UpdateDeploymentRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12'\n" +
-	"\x04spec\x18\x03 \x01(\v2\x13.k8s.DeploymentSpecR\x04spec\"E\n" +
+	"\x04spec\x18\x03 \x01(\v2\x13.k8s.DeploymentSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x04 \x01(\tR\aconfirm\"E\n" +
 	"\x12DeploymentResponse\x12/\n" +
 	"\n" +
 	"deployment\x18\x01 \x01(\v2\x0f.k8s.DeploymentR\n" +
@@ -2223,10 +3539,11 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\x06labels\x18\b \x03(\v2\x18.k8s.Service.LabelsEntryR\x06labels\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"Z\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"t\n" +
 	"\x14CreateServiceRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12$\n" +
-	"\x04spec\x18\x02 \x01(\v2\x10.k8s.ServiceSpecR\x04spec\"\xd3\x01\n" +
+	"\x04spec\x18\x02 \x01(\v2\x10.k8s.ServiceSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x03 \x01(\tR\aconfirm\"\xd3\x01\n" +
 	"\vServiceSpec\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12#\n" +
@@ -2234,11 +3551,12 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\bselector\x18\x04 \x03(\v2\x1e.k8s.ServiceSpec.SelectorEntryR\bselector\x1a;\n" +
 	"\rSelectorEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"n\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x88\x01\n" +
 	"\x14UpdateServiceRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12$\n" +
-	"\x04spec\x18\x03 \x01(\v2\x10.k8s.ServiceSpecR\x04spec\"9\n" +
+	"\x04spec\x18\x03 \x01(\v2\x10.k8s.ServiceSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x04 \x01(\tR\aconfirm\"9\n" +
 	"\x0fServiceResponse\x12&\n" +
 	"\aservice\x18\x01 \x01(\v2\f.k8s.ServiceR\aservice\"G\n" +
 	"\x15ConfigMapListResponse\x12.\n" +
@@ -2256,10 +3574,11 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"^\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"x\n" +
 	"\x16CreateConfigMapRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12&\n" +
-	"\x04spec\x18\x02 \x01(\v2\x12.k8s.ConfigMapSpecR\x04spec\"\x81\x02\n" +
+	"\x04spec\x18\x02 \x01(\v2\x12.k8s.ConfigMapSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x03 \x01(\tR\aconfirm\"\x81\x02\n" +
 	"\rConfigMapSpec\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x120\n" +
 	"\x04data\x18\x02 \x03(\v2\x1c.k8s.ConfigMapSpec.DataEntryR\x04data\x126\n" +
@@ -2269,13 +3588,77 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
 	"\vLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"r\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8c\x01\n" +
 	"\x16UpdateConfigMapRequest\x12\x1c\n" +
 	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
-	"\x04spec\x18\x03 \x01(\v2\x12.k8s.ConfigMapSpecR\x04spec\"A\n" +
+	"\x04spec\x18\x03 \x01(\v2\x12.k8s.ConfigMapSpecR\x04spec\x12\x18\n" +
+	"\aconfirm\x18\x04 \x01(\tR\aconfirm\"A\n" +
 	"\x11ConfigMapResponse\x12,\n" +
-	"\tconfigmap\x18\x01 \x01(\v2\x0e.k8s.ConfigMapR\tconfigmap\"G\n" +
+	"\tconfigmap\x18\x01 \x01(\v2\x0e.k8s.ConfigMapR\tconfigmap\"\x8a\x01\n" +
+	"\fScaleRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1a\n" +
+	"\breplicas\x18\x04 \x01(\x05R\breplicas\x12\x18\n" +
+	"\aconfirm\x18\x05 \x01(\tR\aconfirm\"p\n" +
+	"\x0eRestartRequest\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aconfirm\x18\x04 \x01(\tR\aconfirm\"N\n" +
+	"\x0fRestartResponse\x12!\n" +
+	"\frestarted_at\x18\x01 \x01(\tR\vrestartedAt\x12\x18\n" +
+	"\awarning\x18\x02 \x01(\tR\awarning\"q\n" +
+	"\x14RolloutStatusRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12'\n" +
+	"\x0ftimeout_seconds\x18\x03 \x01(\x05R\x0etimeoutSeconds\"\xcb\x03\n" +
+	"\x15RolloutStatusResponse\x12'\n" +
+	"\x0fdeployment_name\x18\x01 \x01(\tR\x0edeploymentName\x12)\n" +
+	"\x10desired_replicas\x18\x02 \x01(\x05R\x0fdesiredReplicas\x12)\n" +
+	"\x10updated_replicas\x18\x03 \x01(\x05R\x0fupdatedReplicas\x12%\n" +
+	"\x0eready_replicas\x18\x04 \x01(\x05R\rreadyReplicas\x12-\n" +
+	"\x12available_replicas\x18\x05 \x01(\x05R\x11availableReplicas\x12\x1b\n" +
+	"\tis_paused\x18\x06 \x01(\bR\bisPaused\x128\n" +
+	"\n" +
+	"conditions\x18\a \x03(\v2\x18.k8s.DeploymentConditionR\n" +
+	"conditions\x128\n" +
+	"\vreplicasets\x18\b \x03(\v2\x16.k8s.ReplicaSetSummaryR\vreplicasets\x12\x1a\n" +
+	"\bcomplete\x18\t \x01(\bR\bcomplete\x12\x16\n" +
+	"\x06failed\x18\n" +
+	" \x01(\bR\x06failed\x12\x18\n" +
+	"\amessage\x18\v \x01(\tR\amessage\"\x9d\x01\n" +
+	"\x13DeploymentCondition\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12(\n" +
+	"\x10last_update_time\x18\x05 \x01(\tR\x0elastUpdateTime\"\xe3\x01\n" +
+	"\x11ReplicaSetSummary\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x03R\brevision\x12)\n" +
+	"\x10desired_replicas\x18\x03 \x01(\x05R\x0fdesiredReplicas\x12%\n" +
+	"\x0eready_replicas\x18\x04 \x01(\x05R\rreadyReplicas\x12-\n" +
+	"\x12available_replicas\x18\x05 \x01(\x05R\x11availableReplicas\x12\x1d\n" +
+	"\n" +
+	"is_current\x18\x06 \x01(\bR\tisCurrent\"\xa7\x01\n" +
+	"\fApplyRequest\x12!\n" +
+	"\fyaml_content\x18\x01 \x01(\tR\vyamlContent\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\x12#\n" +
+	"\rfield_manager\x18\x04 \x01(\tR\ffieldManager\x12\x18\n" +
+	"\aconfirm\x18\x05 \x01(\tR\aconfirm\";\n" +
+	"\rApplyResponse\x12*\n" +
+	"\aresults\x18\x01 \x03(\v2\x10.k8s.ApplyResultR\aresults\"\x81\x01\n" +
+	"\vApplyResult\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"+\n" +
+	"\x0fVersionResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"G\n" +
 	"\x15NamespaceListResponse\x12.\n" +
 	"\n" +
 	"namespaces\x18\x01 \x03(\v2\x0e.k8s.NamespaceR\n" +
@@ -2300,7 +3683,34 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\acommand\x18\x04 \x01(\tR\acommand\"A\n" +
 	"\fExecResponse\x12\x16\n" +
 	"\x06output\x18\x01 \x01(\tR\x06output\x12\x19\n" +
-	"\bis_error\x18\x02 \x01(\bR\aisError2\xb7\t\n" +
+	"\bis_error\x18\x02 \x01(\bR\aisError\"\xc9\x01\n" +
+	"\fWatchRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12)\n" +
+	"\x10resource_version\x18\x02 \x01(\tR\x0fresourceVersion\x126\n" +
+	"\rresource_type\x18\x03 \x01(\x0e2\x11.k8s.ResourceTypeR\fresourceType\x128\n" +
+	"\x0eresource_types\x18\x04 \x03(\x0e2\x11.k8s.ResourceTypeR\rresourceTypes\"j\n" +
+	"\rPodWatchEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1a\n" +
+	"\x03pod\x18\x02 \x01(\v2\b.k8s.PodR\x03pod\x12)\n" +
+	"\x10resource_version\x18\x03 \x01(\tR\x0fresourceVersion\"\xbd\x02\n" +
+	"\rResourceEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x126\n" +
+	"\rresource_type\x18\x02 \x01(\x0e2\x11.k8s.ResourceTypeR\fresourceType\x12)\n" +
+	"\x10resource_version\x18\x03 \x01(\tR\x0fresourceVersion\x12\x1c\n" +
+	"\x03pod\x18\x04 \x01(\v2\b.k8s.PodH\x00R\x03pod\x121\n" +
+	"\n" +
+	"deployment\x18\x05 \x01(\v2\x0f.k8s.DeploymentH\x00R\n" +
+	"deployment\x12(\n" +
+	"\aservice\x18\x06 \x01(\v2\f.k8s.ServiceH\x00R\aservice\x12/\n" +
+	"\n" +
+	"config_map\x18\a \x01(\v2\x0e.k8s.ConfigMapH\x00R\tconfigMapB\t\n" +
+	"\apayload*\x9e\x01\n" +
+	"\fResourceType\x12\x1d\n" +
+	"\x19RESOURCE_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12RESOURCE_TYPE_PODS\x10\x01\x12\x1d\n" +
+	"\x19RESOURCE_TYPE_DEPLOYMENTS\x10\x02\x12\x1a\n" +
+	"\x16RESOURCE_TYPE_SERVICES\x10\x03\x12\x1c\n" +
+	"\x18RESOURCE_TYPE_CONFIGMAPS\x10\x042\xe9\r\n" +
 	"\n" +
 	"K8sService\x122\n" +
 	"\bListPods\x12\x10.k8s.ListRequest\x1a\x14.k8s.PodListResponse\x12@\n" +
@@ -2318,11 +3728,21 @@ const file_proto_k8s_proto_rawDesc = "" +
 	"\rDeleteService\x12\x12.k8s.DeleteRequest\x1a\x16.google.protobuf.Empty\x12F\n" +
 	"\x0fCreateConfigMap\x12\x1b.k8s.CreateConfigMapRequest\x1a\x16.k8s.ConfigMapResponse\x12F\n" +
 	"\x0fUpdateConfigMap\x12\x1b.k8s.UpdateConfigMapRequest\x1a\x16.k8s.ConfigMapResponse\x12=\n" +
-	"\x0fDeleteConfigMap\x12\x12.k8s.DeleteRequest\x1a\x16.google.protobuf.Empty\x12D\n" +
+	"\x0fDeleteConfigMap\x12\x12.k8s.DeleteRequest\x1a\x16.google.protobuf.Empty\x12:\n" +
+	"\rScaleWorkload\x12\x11.k8s.ScaleRequest\x1a\x16.google.protobuf.Empty\x12<\n" +
+	"\x0fRestartWorkload\x12\x13.k8s.RestartRequest\x1a\x14.k8s.RestartResponse\x12I\n" +
+	"\x1aGetDeploymentRolloutStatus\x12\x0f.k8s.GetRequest\x1a\x1a.k8s.RolloutStatusResponse\x12N\n" +
+	"\x13StreamRolloutStatus\x12\x19.k8s.RolloutStatusRequest\x1a\x1a.k8s.RolloutStatusResponse0\x01\x122\n" +
+	"\tApplyYAML\x12\x11.k8s.ApplyRequest\x1a\x12.k8s.ApplyResponse\x12:\n" +
+	"\x0fApplyYAMLStream\x12\x11.k8s.ApplyRequest\x1a\x10.k8s.ApplyResult(\x010\x01\x12:\n" +
+	"\n" +
+	"GetVersion\x12\x16.google.protobuf.Empty\x1a\x14.k8s.VersionResponse\x12D\n" +
 	"\x0eListNamespaces\x12\x16.google.protobuf.Empty\x1a\x1a.k8s.NamespaceListResponse\x124\n" +
 	"\n" +
 	"GetPodLogs\x12\x13.k8s.PodLogsRequest\x1a\x11.k8s.LogsResponse\x120\n" +
-	"\aExecPod\x12\x10.k8s.ExecRequest\x1a\x11.k8s.ExecResponse0\x01B\x15Z\x13k8s-dashboard/protob\x06proto3"
+	"\aExecPod\x12\x10.k8s.ExecRequest\x1a\x11.k8s.ExecResponse0\x01\x124\n" +
+	"\tWatchPods\x12\x11.k8s.WatchRequest\x1a\x12.k8s.PodWatchEvent0\x01\x129\n" +
+	"\x0eWatchResources\x12\x11.k8s.WatchRequest\x1a\x12.k8s.ResourceEvent0\x01B\x15Z\x13k8s-dashboard/protob\x06proto3"
 
 var (
 	file_proto_k8s_proto_rawDescOnce sync.Once
@@ -2336,133 +3756,179 @@ func file_proto_k8s_proto_rawDescGZIP() []byte {
 	return file_proto_k8s_proto_rawDescData
 }
 
-var file_proto_k8s_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
+var file_proto_k8s_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_k8s_proto_msgTypes = make([]protoimpl.MessageInfo, 61)
 var file_proto_k8s_proto_goTypes = []any{
-	(*ListRequest)(nil),             // 0: k8s.ListRequest
-	(*DeleteRequest)(nil),           // 1: k8s.DeleteRequest
-	(*PodListResponse)(nil),         // 2: k8s.PodListResponse
-	(*Pod)(nil),                     // 3: k8s.Pod
-	(*Container)(nil),               // 4: k8s.Container
-	(*Port)(nil),                    // 5: k8s.Port
-	(*CreatePodRequest)(nil),        // 6: k8s.CreatePodRequest
-	(*PodSpec)(nil),                 // 7: k8s.PodSpec
-	(*ContainerSpec)(nil),           // 8: k8s.ContainerSpec
-	(*PortSpec)(nil),                // 9: k8s.PortSpec
-	(*UpdatePodRequest)(nil),        // 10: k8s.UpdatePodRequest
-	(*PodResponse)(nil),             // 11: k8s.PodResponse
-	(*DeploymentListResponse)(nil),  // 12: k8s.DeploymentListResponse
-	(*Deployment)(nil),              // 13: k8s.Deployment
-	(*CreateDeploymentRequest)(nil), // 14: k8s.CreateDeploymentRequest
-	(*DeploymentSpec)(nil),          // 15: k8s.DeploymentSpec
-	(*UpdateDeploymentRequest)(nil), // 16: k8s.UpdateDeploymentRequest
-	(*DeploymentResponse)(nil),      // 17: k8s.DeploymentResponse
-	(*ServiceListResponse)(nil),     // 18: k8s.ServiceListResponse
-	(*Service)(nil),                 // 19: k8s.Service
-	(*CreateServiceRequest)(nil),    // 20: k8s.CreateServiceRequest
-	(*ServiceSpec)(nil),             // 21: k8s.ServiceSpec
-	(*UpdateServiceRequest)(nil),    // 22: k8s.UpdateServiceRequest
-	(*ServiceResponse)(nil),         // 23: k8s.ServiceResponse
-	(*ConfigMapListResponse)(nil),   // 24: k8s.ConfigMapListResponse
-	(*ConfigMap)(nil),               // 25: k8s.ConfigMap
-	(*CreateConfigMapRequest)(nil),  // 26: k8s.CreateConfigMapRequest
-	(*ConfigMapSpec)(nil),           // 27: k8s.ConfigMapSpec
-	(*UpdateConfigMapRequest)(nil),  // 28: k8s.UpdateConfigMapRequest
-	(*ConfigMapResponse)(nil),       // 29: k8s.ConfigMapResponse
-	(*NamespaceListResponse)(nil),   // 30: k8s.NamespaceListResponse
-	(*Namespace)(nil),               // 31: k8s.Namespace
-	(*PodLogsRequest)(nil),          // 32: k8s.PodLogsRequest
-	(*LogsResponse)(nil),            // 33: k8s.LogsResponse
-	(*ExecRequest)(nil),             // 34: k8s.ExecRequest
-	(*ExecResponse)(nil),            // 35: k8s.ExecResponse
-	nil,                             // 36: k8s.Pod.LabelsEntry
-	nil,                             // 37: k8s.PodSpec.LabelsEntry
-	nil,                             // 38: k8s.Deployment.LabelsEntry
-	nil,                             // 39: k8s.DeploymentSpec.LabelsEntry
-	nil,                             // 40: k8s.Service.LabelsEntry
-	nil,                             // 41: k8s.ServiceSpec.SelectorEntry
-	nil,                             // 42: k8s.ConfigMap.DataEntry
-	nil,                             // 43: k8s.ConfigMap.LabelsEntry
-	nil,                             // 44: k8s.ConfigMapSpec.DataEntry
-	nil,                             // 45: k8s.ConfigMapSpec.LabelsEntry
-	(*emptypb.Empty)(nil),           // 46: google.protobuf.Empty
+	(ResourceType)(0),               // 0: k8s.ResourceType
+	(*ListRequest)(nil),             // 1: k8s.ListRequest
+	(*GetRequest)(nil),              // 2: k8s.GetRequest
+	(*DeleteRequest)(nil),           // 3: k8s.DeleteRequest
+	(*PodListResponse)(nil),         // 4: k8s.PodListResponse
+	(*Pod)(nil),                     // 5: k8s.Pod
+	(*Container)(nil),               // 6: k8s.Container
+	(*Port)(nil),                    // 7: k8s.Port
+	(*CreatePodRequest)(nil),        // 8: k8s.CreatePodRequest
+	(*PodSpec)(nil),                 // 9: k8s.PodSpec
+	(*ContainerSpec)(nil),           // 10: k8s.ContainerSpec
+	(*PortSpec)(nil),                // 11: k8s.PortSpec
+	(*UpdatePodRequest)(nil),        // 12: k8s.UpdatePodRequest
+	(*PodResponse)(nil),             // 13: k8s.PodResponse
+	(*DeploymentListResponse)(nil),  // 14: k8s.DeploymentListResponse
+	(*Deployment)(nil),              // 15: k8s.Deployment
+	(*CreateDeploymentRequest)(nil), // 16: k8s.CreateDeploymentRequest
+	(*DeploymentSpec)(nil),          // 17: k8s.DeploymentSpec
+	(*UpdateDeploymentRequest)(nil), // 18: k8s.UpdateDeploymentRequest
+	(*DeploymentResponse)(nil),      // 19: k8s.DeploymentResponse
+	(*ServiceListResponse)(nil),     // 20: k8s.ServiceListResponse
+	(*Service)(nil),                 // 21: k8s.Service
+	(*CreateServiceRequest)(nil),    // 22: k8s.CreateServiceRequest
+	(*ServiceSpec)(nil),             // 23: k8s.ServiceSpec
+	(*UpdateServiceRequest)(nil),    // 24: k8s.UpdateServiceRequest
+	(*ServiceResponse)(nil),         // 25: k8s.ServiceResponse
+	(*ConfigMapListResponse)(nil),   // 26: k8s.ConfigMapListResponse
+	(*ConfigMap)(nil),               // 27: k8s.ConfigMap
+	(*CreateConfigMapRequest)(nil),  // 28: k8s.CreateConfigMapRequest
+	(*ConfigMapSpec)(nil),           // 29: k8s.ConfigMapSpec
+	(*UpdateConfigMapRequest)(nil),  // 30: k8s.UpdateConfigMapRequest
+	(*ConfigMapResponse)(nil),       // 31: k8s.ConfigMapResponse
+	(*ScaleRequest)(nil),            // 32: k8s.ScaleRequest
+	(*RestartRequest)(nil),          // 33: k8s.RestartRequest
+	(*RestartResponse)(nil),         // 34: k8s.RestartResponse
+	(*RolloutStatusRequest)(nil),    // 35: k8s.RolloutStatusRequest
+	(*RolloutStatusResponse)(nil),   // 36: k8s.RolloutStatusResponse
+	(*DeploymentCondition)(nil),     // 37: k8s.DeploymentCondition
+	(*ReplicaSetSummary)(nil),       // 38: k8s.ReplicaSetSummary
+	(*ApplyRequest)(nil),            // 39: k8s.ApplyRequest
+	(*ApplyResponse)(nil),           // 40: k8s.ApplyResponse
+	(*ApplyResult)(nil),             // 41: k8s.ApplyResult
+	(*VersionResponse)(nil),         // 42: k8s.VersionResponse
+	(*NamespaceListResponse)(nil),   // 43: k8s.NamespaceListResponse
+	(*Namespace)(nil),               // 44: k8s.Namespace
+	(*PodLogsRequest)(nil),          // 45: k8s.PodLogsRequest
+	(*LogsResponse)(nil),            // 46: k8s.LogsResponse
+	(*ExecRequest)(nil),             // 47: k8s.ExecRequest
+	(*ExecResponse)(nil),            // 48: k8s.ExecResponse
+	(*WatchRequest)(nil),            // 49: k8s.WatchRequest
+	(*PodWatchEvent)(nil),           // 50: k8s.PodWatchEvent
+	(*ResourceEvent)(nil),           // 51: k8s.ResourceEvent
+	nil,                             // 52: k8s.Pod.LabelsEntry
+	nil,                             // 53: k8s.PodSpec.LabelsEntry
+	nil,                             // 54: k8s.Deployment.LabelsEntry
+	nil,                             // 55: k8s.DeploymentSpec.LabelsEntry
+	nil,                             // 56: k8s.Service.LabelsEntry
+	nil,                             // 57: k8s.ServiceSpec.SelectorEntry
+	nil,                             // 58: k8s.ConfigMap.DataEntry
+	nil,                             // 59: k8s.ConfigMap.LabelsEntry
+	nil,                             // 60: k8s.ConfigMapSpec.DataEntry
+	nil,                             // 61: k8s.ConfigMapSpec.LabelsEntry
+	(*emptypb.Empty)(nil),           // 62: google.protobuf.Empty
 }
 var file_proto_k8s_proto_depIdxs = []int32{
-	3,  // 0: k8s.PodListResponse.pods:type_name -> k8s.Pod
-	4,  // 1: k8s.Pod.containers:type_name -> k8s.Container
-	36, // 2: k8s.Pod.labels:type_name -> k8s.Pod.LabelsEntry
-	5,  // 3: k8s.Container.ports:type_name -> k8s.Port
-	7,  // 4: k8s.CreatePodRequest.spec:type_name -> k8s.PodSpec
-	37, // 5: k8s.PodSpec.labels:type_name -> k8s.PodSpec.LabelsEntry
-	8,  // 6: k8s.PodSpec.containers:type_name -> k8s.ContainerSpec
-	9,  // 7: k8s.ContainerSpec.ports:type_name -> k8s.PortSpec
-	7,  // 8: k8s.UpdatePodRequest.spec:type_name -> k8s.PodSpec
-	3,  // 9: k8s.PodResponse.pod:type_name -> k8s.Pod
-	13, // 10: k8s.DeploymentListResponse.deployments:type_name -> k8s.Deployment
-	38, // 11: k8s.Deployment.labels:type_name -> k8s.Deployment.LabelsEntry
-	15, // 12: k8s.CreateDeploymentRequest.spec:type_name -> k8s.DeploymentSpec
-	39, // 13: k8s.DeploymentSpec.labels:type_name -> k8s.DeploymentSpec.LabelsEntry
-	7,  // 14: k8s.DeploymentSpec.template:type_name -> k8s.PodSpec
-	15, // 15: k8s.UpdateDeploymentRequest.spec:type_name -> k8s.DeploymentSpec
-	13, // 16: k8s.DeploymentResponse.deployment:type_name -> k8s.Deployment
-	19, // 17: k8s.ServiceListResponse.services:type_name -> k8s.Service
-	40, // 18: k8s.Service.labels:type_name -> k8s.Service.LabelsEntry
-	21, // 19: k8s.CreateServiceRequest.spec:type_name -> k8s.ServiceSpec
-	9,  // 20: k8s.ServiceSpec.ports:type_name -> k8s.PortSpec
-	41, // 21: k8s.ServiceSpec.selector:type_name -> k8s.ServiceSpec.SelectorEntry
-	21, // 22: k8s.UpdateServiceRequest.spec:type_name -> k8s.ServiceSpec
-	19, // 23: k8s.ServiceResponse.service:type_name -> k8s.Service
-	25, // 24: k8s.ConfigMapListResponse.configmaps:type_name -> k8s.ConfigMap
-	42, // 25: k8s.ConfigMap.data:type_name -> k8s.ConfigMap.DataEntry
-	43, // 26: k8s.ConfigMap.labels:type_name -> k8s.ConfigMap.LabelsEntry
-	27, // 27: k8s.CreateConfigMapRequest.spec:type_name -> k8s.ConfigMapSpec
-	44, // 28: k8s.ConfigMapSpec.data:type_name -> k8s.ConfigMapSpec.DataEntry
-	45, // 29: k8s.ConfigMapSpec.labels:type_name -> k8s.ConfigMapSpec.LabelsEntry
-	27, // 30: k8s.UpdateConfigMapRequest.spec:type_name -> k8s.ConfigMapSpec
-	25, // 31: k8s.ConfigMapResponse.configmap:type_name -> k8s.ConfigMap
-	31, // 32: k8s.NamespaceListResponse.namespaces:type_name -> k8s.Namespace
-	0,  // 33: k8s.K8sService.ListPods:input_type -> k8s.ListRequest
-	0,  // 34: k8s.K8sService.ListDeployments:input_type -> k8s.ListRequest
-	0,  // 35: k8s.K8sService.ListServices:input_type -> k8s.ListRequest
-	0,  // 36: k8s.K8sService.ListConfigMaps:input_type -> k8s.ListRequest
-	6,  // 37: k8s.K8sService.CreatePod:input_type -> k8s.CreatePodRequest
-	10, // 38: k8s.K8sService.UpdatePod:input_type -> k8s.UpdatePodRequest
-	1,  // 39: k8s.K8sService.DeletePod:input_type -> k8s.DeleteRequest
-	14, // 40: k8s.K8sService.CreateDeployment:input_type -> k8s.CreateDeploymentRequest
-	16, // 41: k8s.K8sService.UpdateDeployment:input_type -> k8s.UpdateDeploymentRequest
-	1,  // 42: k8s.K8sService.DeleteDeployment:input_type -> k8s.DeleteRequest
-	20, // 43: k8s.K8sService.CreateService:input_type -> k8s.CreateServiceRequest
-	22, // 44: k8s.K8sService.UpdateService:input_type -> k8s.UpdateServiceRequest
-	1,  // 45: k8s.K8sService.DeleteService:input_type -> k8s.DeleteRequest
-	26, // 46: k8s.K8sService.CreateConfigMap:input_type -> k8s.CreateConfigMapRequest
-	28, // 47: k8s.K8sService.UpdateConfigMap:input_type -> k8s.UpdateConfigMapRequest
-	1,  // 48: k8s.K8sService.DeleteConfigMap:input_type -> k8s.DeleteRequest
-	46, // 49: k8s.K8sService.ListNamespaces:input_type -> google.protobuf.Empty
-	32, // 50: k8s.K8sService.GetPodLogs:input_type -> k8s.PodLogsRequest
-	34, // 51: k8s.K8sService.ExecPod:input_type -> k8s.ExecRequest
-	2,  // 52: k8s.K8sService.ListPods:output_type -> k8s.PodListResponse
-	12, // 53: k8s.K8sService.ListDeployments:output_type -> k8s.DeploymentListResponse
-	18, // 54: k8s.K8sService.ListServices:output_type -> k8s.ServiceListResponse
-	24, // 55: k8s.K8sService.ListConfigMaps:output_type -> k8s.ConfigMapListResponse
-	11, // 56: k8s.K8sService.CreatePod:output_type -> k8s.PodResponse
-	11, // 57: k8s.K8sService.UpdatePod:output_type -> k8s.PodResponse
-	46, // 58: k8s.K8sService.DeletePod:output_type -> google.protobuf.Empty
-	17, // 59: k8s.K8sService.CreateDeployment:output_type -> k8s.DeploymentResponse
-	17, // 60: k8s.K8sService.UpdateDeployment:output_type -> k8s.DeploymentResponse
-	46, // 61: k8s.K8sService.DeleteDeployment:output_type -> google.protobuf.Empty
-	23, // 62: k8s.K8sService.CreateService:output_type -> k8s.ServiceResponse
-	23, // 63: k8s.K8sService.UpdateService:output_type -> k8s.ServiceResponse
-	46, // 64: k8s.K8sService.DeleteService:output_type -> google.protobuf.Empty
-	29, // 65: k8s.K8sService.CreateConfigMap:output_type -> k8s.ConfigMapResponse
-	29, // 66: k8s.K8sService.UpdateConfigMap:output_type -> k8s.ConfigMapResponse
-	46, // 67: k8s.K8sService.DeleteConfigMap:output_type -> google.protobuf.Empty
-	30, // 68: k8s.K8sService.ListNamespaces:output_type -> k8s.NamespaceListResponse
-	33, // 69: k8s.K8sService.GetPodLogs:output_type -> k8s.LogsResponse
-	35, // 70: k8s.K8sService.ExecPod:output_type -> k8s.ExecResponse
-	52, // [52:71] is the sub-list for method output_type
-	33, // [33:52] is the sub-list for method input_type
-	33, // [33:33] is the sub-list for extension type_name
-	33, // [33:33] is the sub-list for extension extendee
-	0,  // [0:33] is the sub-list for field type_name
+	5,  // 0: k8s.PodListResponse.pods:type_name -> k8s.Pod
+	6,  // 1: k8s.Pod.containers:type_name -> k8s.Container
+	52, // 2: k8s.Pod.labels:type_name -> k8s.Pod.LabelsEntry
+	7,  // 3: k8s.Container.ports:type_name -> k8s.Port
+	9,  // 4: k8s.CreatePodRequest.spec:type_name -> k8s.PodSpec
+	53, // 5: k8s.PodSpec.labels:type_name -> k8s.PodSpec.LabelsEntry
+	10, // 6: k8s.PodSpec.containers:type_name -> k8s.ContainerSpec
+	11, // 7: k8s.ContainerSpec.ports:type_name -> k8s.PortSpec
+	9,  // 8: k8s.UpdatePodRequest.spec:type_name -> k8s.PodSpec
+	5,  // 9: k8s.PodResponse.pod:type_name -> k8s.Pod
+	15, // 10: k8s.DeploymentListResponse.deployments:type_name -> k8s.Deployment
+	54, // 11: k8s.Deployment.labels:type_name -> k8s.Deployment.LabelsEntry
+	17, // 12: k8s.CreateDeploymentRequest.spec:type_name -> k8s.DeploymentSpec
+	55, // 13: k8s.DeploymentSpec.labels:type_name -> k8s.DeploymentSpec.LabelsEntry
+	9,  // 14: k8s.DeploymentSpec.template:type_name -> k8s.PodSpec
+	17, // 15: k8s.UpdateDeploymentRequest.spec:type_name -> k8s.DeploymentSpec
+	15, // 16: k8s.DeploymentResponse.deployment:type_name -> k8s.Deployment
+	21, // 17: k8s.ServiceListResponse.services:type_name -> k8s.Service
+	56, // 18: k8s.Service.labels:type_name -> k8s.Service.LabelsEntry
+	23, // 19: k8s.CreateServiceRequest.spec:type_name -> k8s.ServiceSpec
+	11, // 20: k8s.ServiceSpec.ports:type_name -> k8s.PortSpec
+	57, // 21: k8s.ServiceSpec.selector:type_name -> k8s.ServiceSpec.SelectorEntry
+	23, // 22: k8s.UpdateServiceRequest.spec:type_name -> k8s.ServiceSpec
+	21, // 23: k8s.ServiceResponse.service:type_name -> k8s.Service
+	27, // 24: k8s.ConfigMapListResponse.configmaps:type_name -> k8s.ConfigMap
+	58, // 25: k8s.ConfigMap.data:type_name -> k8s.ConfigMap.DataEntry
+	59, // 26: k8s.ConfigMap.labels:type_name -> k8s.ConfigMap.LabelsEntry
+	29, // 27: k8s.CreateConfigMapRequest.spec:type_name -> k8s.ConfigMapSpec
+	60, // 28: k8s.ConfigMapSpec.data:type_name -> k8s.ConfigMapSpec.DataEntry
+	61, // 29: k8s.ConfigMapSpec.labels:type_name -> k8s.ConfigMapSpec.LabelsEntry
+	29, // 30: k8s.UpdateConfigMapRequest.spec:type_name -> k8s.ConfigMapSpec
+	27, // 31: k8s.ConfigMapResponse.configmap:type_name -> k8s.ConfigMap
+	37, // 32: k8s.RolloutStatusResponse.conditions:type_name -> k8s.DeploymentCondition
+	38, // 33: k8s.RolloutStatusResponse.replicasets:type_name -> k8s.ReplicaSetSummary
+	41, // 34: k8s.ApplyResponse.results:type_name -> k8s.ApplyResult
+	44, // 35: k8s.NamespaceListResponse.namespaces:type_name -> k8s.Namespace
+	0,  // 36: k8s.WatchRequest.resource_type:type_name -> k8s.ResourceType
+	0,  // 37: k8s.WatchRequest.resource_types:type_name -> k8s.ResourceType
+	5,  // 38: k8s.PodWatchEvent.pod:type_name -> k8s.Pod
+	0,  // 39: k8s.ResourceEvent.resource_type:type_name -> k8s.ResourceType
+	5,  // 40: k8s.ResourceEvent.pod:type_name -> k8s.Pod
+	15, // 41: k8s.ResourceEvent.deployment:type_name -> k8s.Deployment
+	21, // 42: k8s.ResourceEvent.service:type_name -> k8s.Service
+	27, // 43: k8s.ResourceEvent.config_map:type_name -> k8s.ConfigMap
+	1,  // 44: k8s.K8sService.ListPods:input_type -> k8s.ListRequest
+	1,  // 45: k8s.K8sService.ListDeployments:input_type -> k8s.ListRequest
+	1,  // 46: k8s.K8sService.ListServices:input_type -> k8s.ListRequest
+	1,  // 47: k8s.K8sService.ListConfigMaps:input_type -> k8s.ListRequest
+	8,  // 48: k8s.K8sService.CreatePod:input_type -> k8s.CreatePodRequest
+	12, // 49: k8s.K8sService.UpdatePod:input_type -> k8s.UpdatePodRequest
+	3,  // 50: k8s.K8sService.DeletePod:input_type -> k8s.DeleteRequest
+	16, // 51: k8s.K8sService.CreateDeployment:input_type -> k8s.CreateDeploymentRequest
+	18, // 52: k8s.K8sService.UpdateDeployment:input_type -> k8s.UpdateDeploymentRequest
+	3,  // 53: k8s.K8sService.DeleteDeployment:input_type -> k8s.DeleteRequest
+	22, // 54: k8s.K8sService.CreateService:input_type -> k8s.CreateServiceRequest
+	24, // 55: k8s.K8sService.UpdateService:input_type -> k8s.UpdateServiceRequest
+	3,  // 56: k8s.K8sService.DeleteService:input_type -> k8s.DeleteRequest
+	28, // 57: k8s.K8sService.CreateConfigMap:input_type -> k8s.CreateConfigMapRequest
+	30, // 58: k8s.K8sService.UpdateConfigMap:input_type -> k8s.UpdateConfigMapRequest
+	3,  // 59: k8s.K8sService.DeleteConfigMap:input_type -> k8s.DeleteRequest
+	32, // 60: k8s.K8sService.ScaleWorkload:input_type -> k8s.ScaleRequest
+	33, // 61: k8s.K8sService.RestartWorkload:input_type -> k8s.RestartRequest
+	2,  // 62: k8s.K8sService.GetDeploymentRolloutStatus:input_type -> k8s.GetRequest
+	35, // 63: k8s.K8sService.StreamRolloutStatus:input_type -> k8s.RolloutStatusRequest
+	39, // 64: k8s.K8sService.ApplyYAML:input_type -> k8s.ApplyRequest
+	39, // 65: k8s.K8sService.ApplyYAMLStream:input_type -> k8s.ApplyRequest
+	62, // 66: k8s.K8sService.GetVersion:input_type -> google.protobuf.Empty
+	62, // 67: k8s.K8sService.ListNamespaces:input_type -> google.protobuf.Empty
+	45, // 68: k8s.K8sService.GetPodLogs:input_type -> k8s.PodLogsRequest
+	47, // 69: k8s.K8sService.ExecPod:input_type -> k8s.ExecRequest
+	49, // 70: k8s.K8sService.WatchPods:input_type -> k8s.WatchRequest
+	49, // 71: k8s.K8sService.WatchResources:input_type -> k8s.WatchRequest
+	4,  // 72: k8s.K8sService.ListPods:output_type -> k8s.PodListResponse
+	14, // 73: k8s.K8sService.ListDeployments:output_type -> k8s.DeploymentListResponse
+	20, // 74: k8s.K8sService.ListServices:output_type -> k8s.ServiceListResponse
+	26, // 75: k8s.K8sService.ListConfigMaps:output_type -> k8s.ConfigMapListResponse
+	13, // 76: k8s.K8sService.CreatePod:output_type -> k8s.PodResponse
+	13, // 77: k8s.K8sService.UpdatePod:output_type -> k8s.PodResponse
+	62, // 78: k8s.K8sService.DeletePod:output_type -> google.protobuf.Empty
+	19, // 79: k8s.K8sService.CreateDeployment:output_type -> k8s.DeploymentResponse
+	19, // 80: k8s.K8sService.UpdateDeployment:output_type -> k8s.DeploymentResponse
+	62, // 81: k8s.K8sService.DeleteDeployment:output_type -> google.protobuf.Empty
+	25, // 82: k8s.K8sService.CreateService:output_type -> k8s.ServiceResponse
+	25, // 83: k8s.K8sService.UpdateService:output_type -> k8s.ServiceResponse
+	62, // 84: k8s.K8sService.DeleteService:output_type -> google.protobuf.Empty
+	31, // 85: k8s.K8sService.CreateConfigMap:output_type -> k8s.ConfigMapResponse
+	31, // 86: k8s.K8sService.UpdateConfigMap:output_type -> k8s.ConfigMapResponse
+	62, // 87: k8s.K8sService.DeleteConfigMap:output_type -> google.protobuf.Empty
+	62, // 88: k8s.K8sService.ScaleWorkload:output_type -> google.protobuf.Empty
+	34, // 89: k8s.K8sService.RestartWorkload:output_type -> k8s.RestartResponse
+	36, // 90: k8s.K8sService.GetDeploymentRolloutStatus:output_type -> k8s.RolloutStatusResponse
+	36, // 91: k8s.K8sService.StreamRolloutStatus:output_type -> k8s.RolloutStatusResponse
+	40, // 92: k8s.K8sService.ApplyYAML:output_type -> k8s.ApplyResponse
+	41, // 93: k8s.K8sService.ApplyYAMLStream:output_type -> k8s.ApplyResult
+	42, // 94: k8s.K8sService.GetVersion:output_type -> k8s.VersionResponse
+	43, // 95: k8s.K8sService.ListNamespaces:output_type -> k8s.NamespaceListResponse
+	46, // 96: k8s.K8sService.GetPodLogs:output_type -> k8s.LogsResponse
+	48, // 97: k8s.K8sService.ExecPod:output_type -> k8s.ExecResponse
+	50, // 98: k8s.K8sService.WatchPods:output_type -> k8s.PodWatchEvent
+	51, // 99: k8s.K8sService.WatchResources:output_type -> k8s.ResourceEvent
+	72, // [72:100] is the sub-list for method output_type
+	44, // [44:72] is the sub-list for method input_type
+	44, // [44:44] is the sub-list for extension type_name
+	44, // [44:44] is the sub-list for extension extendee
+	0,  // [0:44] is the sub-list for field type_name
 }
 
 func init() { file_proto_k8s_proto_init() }
@@ -2470,18 +3936,25 @@ func file_proto_k8s_proto_init() {
 	if File_proto_k8s_proto != nil {
 		return
 	}
+	file_proto_k8s_proto_msgTypes[50].OneofWrappers = []any{
+		(*ResourceEvent_Pod)(nil),
+		(*ResourceEvent_Deployment)(nil),
+		(*ResourceEvent_Service)(nil),
+		(*ResourceEvent_ConfigMap)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_k8s_proto_rawDesc), len(file_proto_k8s_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   46,
+			NumEnums:      1,
+			NumMessages:   61,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_k8s_proto_goTypes,
 		DependencyIndexes: file_proto_k8s_proto_depIdxs,
+		EnumInfos:         file_proto_k8s_proto_enumTypes,
 		MessageInfos:      file_proto_k8s_proto_msgTypes,
 	}.Build()
 	File_proto_k8s_proto = out.File
@@ -20,25 +20,34 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	K8SService_ListPods_FullMethodName         = "/k8s.K8sService/ListPods"
-	K8SService_ListDeployments_FullMethodName  = "/k8s.K8sService/ListDeployments"
-	K8SService_ListServices_FullMethodName     = "/k8s.K8sService/ListServices"
-	K8SService_ListConfigMaps_FullMethodName   = "/k8s.K8sService/ListConfigMaps"
-	K8SService_CreatePod_FullMethodName        = "/k8s.K8sService/CreatePod"
-	K8SService_UpdatePod_FullMethodName        = "/k8s.K8sService/UpdatePod"
-	K8SService_DeletePod_FullMethodName        = "/k8s.K8sService/DeletePod"
-	K8SService_CreateDeployment_FullMethodName = "/k8s.K8sService/CreateDeployment"
-	K8SService_UpdateDeployment_FullMethodName = "/k8s.K8sService/UpdateDeployment"
-	K8SService_DeleteDeployment_FullMethodName = "/k8s.K8sService/DeleteDeployment"
-	K8SService_CreateService_FullMethodName    = "/k8s.K8sService/CreateService"
-	K8SService_UpdateService_FullMethodName    = "/k8s.K8sService/UpdateService"
-	K8SService_DeleteService_FullMethodName    = "/k8s.K8sService/DeleteService"
-	K8SService_CreateConfigMap_FullMethodName  = "/k8s.K8sService/CreateConfigMap"
-	K8SService_UpdateConfigMap_FullMethodName  = "/k8s.K8sService/UpdateConfigMap"
-	K8SService_DeleteConfigMap_FullMethodName  = "/k8s.K8sService/DeleteConfigMap"
-	K8SService_ListNamespaces_FullMethodName   = "/k8s.K8sService/ListNamespaces"
-	K8SService_GetPodLogs_FullMethodName       = "/k8s.K8sService/GetPodLogs"
-	K8SService_ExecPod_FullMethodName          = "/k8s.K8sService/ExecPod"
+	K8SService_ListPods_FullMethodName                   = "/k8s.K8sService/ListPods"
+	K8SService_ListDeployments_FullMethodName            = "/k8s.K8sService/ListDeployments"
+	K8SService_ListServices_FullMethodName               = "/k8s.K8sService/ListServices"
+	K8SService_ListConfigMaps_FullMethodName             = "/k8s.K8sService/ListConfigMaps"
+	K8SService_CreatePod_FullMethodName                  = "/k8s.K8sService/CreatePod"
+	K8SService_UpdatePod_FullMethodName                  = "/k8s.K8sService/UpdatePod"
+	K8SService_DeletePod_FullMethodName                  = "/k8s.K8sService/DeletePod"
+	K8SService_CreateDeployment_FullMethodName           = "/k8s.K8sService/CreateDeployment"
+	K8SService_UpdateDeployment_FullMethodName           = "/k8s.K8sService/UpdateDeployment"
+	K8SService_DeleteDeployment_FullMethodName           = "/k8s.K8sService/DeleteDeployment"
+	K8SService_CreateService_FullMethodName              = "/k8s.K8sService/CreateService"
+	K8SService_UpdateService_FullMethodName              = "/k8s.K8sService/UpdateService"
+	K8SService_DeleteService_FullMethodName              = "/k8s.K8sService/DeleteService"
+	K8SService_CreateConfigMap_FullMethodName            = "/k8s.K8sService/CreateConfigMap"
+	K8SService_UpdateConfigMap_FullMethodName            = "/k8s.K8sService/UpdateConfigMap"
+	K8SService_DeleteConfigMap_FullMethodName            = "/k8s.K8sService/DeleteConfigMap"
+	K8SService_ScaleWorkload_FullMethodName              = "/k8s.K8sService/ScaleWorkload"
+	K8SService_RestartWorkload_FullMethodName            = "/k8s.K8sService/RestartWorkload"
+	K8SService_GetDeploymentRolloutStatus_FullMethodName = "/k8s.K8sService/GetDeploymentRolloutStatus"
+	K8SService_StreamRolloutStatus_FullMethodName        = "/k8s.K8sService/StreamRolloutStatus"
+	K8SService_ApplyYAML_FullMethodName                  = "/k8s.K8sService/ApplyYAML"
+	K8SService_ApplyYAMLStream_FullMethodName            = "/k8s.K8sService/ApplyYAMLStream"
+	K8SService_GetVersion_FullMethodName                 = "/k8s.K8sService/GetVersion"
+	K8SService_ListNamespaces_FullMethodName             = "/k8s.K8sService/ListNamespaces"
+	K8SService_GetPodLogs_FullMethodName                 = "/k8s.K8sService/GetPodLogs"
+	K8SService_ExecPod_FullMethodName                    = "/k8s.K8sService/ExecPod"
+	K8SService_WatchPods_FullMethodName                  = "/k8s.K8sService/WatchPods"
+	K8SService_WatchResources_FullMethodName             = "/k8s.K8sService/WatchResources"
 )
 
 // K8SServiceClient is the client API for K8SService service.
@@ -65,11 +74,34 @@ type K8SServiceClient interface {
 	CreateConfigMap(ctx context.Context, in *CreateConfigMapRequest, opts ...grpc.CallOption) (*ConfigMapResponse, error)
 	UpdateConfigMap(ctx context.Context, in *UpdateConfigMapRequest, opts ...grpc.CallOption) (*ConfigMapResponse, error)
 	DeleteConfigMap(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Workload operations, by plural kind as in the REST API
+	ScaleWorkload(ctx context.Context, in *ScaleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RestartWorkload(ctx context.Context, in *RestartRequest, opts ...grpc.CallOption) (*RestartResponse, error)
+	// Rollout progress of a deployment, like kubectl rollout status.
+	// StreamRolloutStatus sends it every few seconds until the rollout
+	// completes or fails, and fails with DEADLINE_EXCEEDED at its timeout.
+	GetDeploymentRolloutStatus(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*RolloutStatusResponse, error)
+	StreamRolloutStatus(ctx context.Context, in *RolloutStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RolloutStatusResponse], error)
+	// Manifest operations. ApplyYAML applies the documents of a manifest like
+	// kubectl apply. ApplyYAMLStream takes a large manifest in chunks and
+	// streams back the result of each document as soon as it is applied.
+	ApplyYAML(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error)
+	ApplyYAMLStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ApplyRequest, ApplyResult], error)
+	// Version of the kgo server, which clients compare to their own
+	GetVersion(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionResponse, error)
 	// Namespace operations
 	ListNamespaces(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NamespaceListResponse, error)
 	// Pod logs and exec
 	GetPodLogs(ctx context.Context, in *PodLogsRequest, opts ...grpc.CallOption) (*LogsResponse, error)
 	ExecPod(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecResponse], error)
+	// Watch operations. Every event carries the resource version to resume
+	// from, BOOKMARK events repeat it while nothing changes, and a resource
+	// version too old to resume from fails the stream with OUT_OF_RANGE and a
+	// WATCH_EXPIRED ErrorInfo.
+	WatchPods(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PodWatchEvent], error)
+	// Watches the changes to several resource types in one stream, with a
+	// BOOKMARK per type
+	WatchResources(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResourceEvent], error)
 }
 
 type k8SServiceClient struct {
@@ -240,6 +272,88 @@ func (c *k8SServiceClient) DeleteConfigMap(ctx context.Context, in *DeleteReques
 	return out, nil
 }
 
+func (c *k8SServiceClient) ScaleWorkload(ctx context.Context, in *ScaleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, K8SService_ScaleWorkload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *k8SServiceClient) RestartWorkload(ctx context.Context, in *RestartRequest, opts ...grpc.CallOption) (*RestartResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestartResponse)
+	err := c.cc.Invoke(ctx, K8SService_RestartWorkload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *k8SServiceClient) GetDeploymentRolloutStatus(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*RolloutStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RolloutStatusResponse)
+	err := c.cc.Invoke(ctx, K8SService_GetDeploymentRolloutStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *k8SServiceClient) StreamRolloutStatus(ctx context.Context, in *RolloutStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RolloutStatusResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &K8SService_ServiceDesc.Streams[0], K8SService_StreamRolloutStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RolloutStatusRequest, RolloutStatusResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_StreamRolloutStatusClient = grpc.ServerStreamingClient[RolloutStatusResponse]
+
+func (c *k8SServiceClient) ApplyYAML(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyResponse)
+	err := c.cc.Invoke(ctx, K8SService_ApplyYAML_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *k8SServiceClient) ApplyYAMLStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ApplyRequest, ApplyResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &K8SService_ServiceDesc.Streams[1], K8SService_ApplyYAMLStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ApplyRequest, ApplyResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_ApplyYAMLStreamClient = grpc.BidiStreamingClient[ApplyRequest, ApplyResult]
+
+func (c *k8SServiceClient) GetVersion(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*VersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VersionResponse)
+	err := c.cc.Invoke(ctx, K8SService_GetVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *k8SServiceClient) ListNamespaces(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NamespaceListResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(NamespaceListResponse)
@@ -262,7 +376,7 @@ func (c *k8SServiceClient) GetPodLogs(ctx context.Context, in *PodLogsRequest, o
 
 func (c *k8SServiceClient) ExecPod(ctx context.Context, in *ExecRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExecResponse], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	stream, err := c.cc.NewStream(ctx, &K8SService_ServiceDesc.Streams[0], K8SService_ExecPod_FullMethodName, cOpts...)
+	stream, err := c.cc.NewStream(ctx, &K8SService_ServiceDesc.Streams[2], K8SService_ExecPod_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -279,6 +393,44 @@ func (c *k8SServiceClient) ExecPod(ctx context.Context, in *ExecRequest, opts ..
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type K8SService_ExecPodClient = grpc.ServerStreamingClient[ExecResponse]
 
+func (c *k8SServiceClient) WatchPods(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PodWatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &K8SService_ServiceDesc.Streams[3], K8SService_WatchPods_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, PodWatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_WatchPodsClient = grpc.ServerStreamingClient[PodWatchEvent]
+
+func (c *k8SServiceClient) WatchResources(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResourceEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &K8SService_ServiceDesc.Streams[4], K8SService_WatchResources_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, ResourceEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_WatchResourcesClient = grpc.ServerStreamingClient[ResourceEvent]
+
 // K8SServiceServer is the server API for K8SService service.
 // All implementations must embed UnimplementedK8SServiceServer
 // for forward compatibility.
@@ -303,11 +455,34 @@ type K8SServiceServer interface {
 	CreateConfigMap(context.Context, *CreateConfigMapRequest) (*ConfigMapResponse, error)
 	UpdateConfigMap(context.Context, *UpdateConfigMapRequest) (*ConfigMapResponse, error)
 	DeleteConfigMap(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	// Workload operations, by plural kind as in the REST API
+	ScaleWorkload(context.Context, *ScaleRequest) (*emptypb.Empty, error)
+	RestartWorkload(context.Context, *RestartRequest) (*RestartResponse, error)
+	// Rollout progress of a deployment, like kubectl rollout status.
+	// StreamRolloutStatus sends it every few seconds until the rollout
+	// completes or fails, and fails with DEADLINE_EXCEEDED at its timeout.
+	GetDeploymentRolloutStatus(context.Context, *GetRequest) (*RolloutStatusResponse, error)
+	StreamRolloutStatus(*RolloutStatusRequest, grpc.ServerStreamingServer[RolloutStatusResponse]) error
+	// Manifest operations. ApplyYAML applies the documents of a manifest like
+	// kubectl apply. ApplyYAMLStream takes a large manifest in chunks and
+	// streams back the result of each document as soon as it is applied.
+	ApplyYAML(context.Context, *ApplyRequest) (*ApplyResponse, error)
+	ApplyYAMLStream(grpc.BidiStreamingServer[ApplyRequest, ApplyResult]) error
+	// Version of the kgo server, which clients compare to their own
+	GetVersion(context.Context, *emptypb.Empty) (*VersionResponse, error)
 	// Namespace operations
 	ListNamespaces(context.Context, *emptypb.Empty) (*NamespaceListResponse, error)
 	// Pod logs and exec
 	GetPodLogs(context.Context, *PodLogsRequest) (*LogsResponse, error)
 	ExecPod(*ExecRequest, grpc.ServerStreamingServer[ExecResponse]) error
+	// Watch operations. Every event carries the resource version to resume
+	// from, BOOKMARK events repeat it while nothing changes, and a resource
+	// version too old to resume from fails the stream with OUT_OF_RANGE and a
+	// WATCH_EXPIRED ErrorInfo.
+	WatchPods(*WatchRequest, grpc.ServerStreamingServer[PodWatchEvent]) error
+	// Watches the changes to several resource types in one stream, with a
+	// BOOKMARK per type
+	WatchResources(*WatchRequest, grpc.ServerStreamingServer[ResourceEvent]) error
 	mustEmbedUnimplementedK8SServiceServer()
 }
 
@@ -366,6 +541,27 @@ func (UnimplementedK8SServiceServer) UpdateConfigMap(context.Context, *UpdateCon
 func (UnimplementedK8SServiceServer) DeleteConfigMap(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteConfigMap not implemented")
 }
+func (UnimplementedK8SServiceServer) ScaleWorkload(context.Context, *ScaleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ScaleWorkload not implemented")
+}
+func (UnimplementedK8SServiceServer) RestartWorkload(context.Context, *RestartRequest) (*RestartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestartWorkload not implemented")
+}
+func (UnimplementedK8SServiceServer) GetDeploymentRolloutStatus(context.Context, *GetRequest) (*RolloutStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeploymentRolloutStatus not implemented")
+}
+func (UnimplementedK8SServiceServer) StreamRolloutStatus(*RolloutStatusRequest, grpc.ServerStreamingServer[RolloutStatusResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRolloutStatus not implemented")
+}
+func (UnimplementedK8SServiceServer) ApplyYAML(context.Context, *ApplyRequest) (*ApplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyYAML not implemented")
+}
+func (UnimplementedK8SServiceServer) ApplyYAMLStream(grpc.BidiStreamingServer[ApplyRequest, ApplyResult]) error {
+	return status.Errorf(codes.Unimplemented, "method ApplyYAMLStream not implemented")
+}
+func (UnimplementedK8SServiceServer) GetVersion(context.Context, *emptypb.Empty) (*VersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
 func (UnimplementedK8SServiceServer) ListNamespaces(context.Context, *emptypb.Empty) (*NamespaceListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListNamespaces not implemented")
 }
@@ -375,6 +571,12 @@ func (UnimplementedK8SServiceServer) GetPodLogs(context.Context, *PodLogsRequest
 func (UnimplementedK8SServiceServer) ExecPod(*ExecRequest, grpc.ServerStreamingServer[ExecResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method ExecPod not implemented")
 }
+func (UnimplementedK8SServiceServer) WatchPods(*WatchRequest, grpc.ServerStreamingServer[PodWatchEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPods not implemented")
+}
+func (UnimplementedK8SServiceServer) WatchResources(*WatchRequest, grpc.ServerStreamingServer[ResourceEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchResources not implemented")
+}
 func (UnimplementedK8SServiceServer) mustEmbedUnimplementedK8SServiceServer() {}
 func (UnimplementedK8SServiceServer) testEmbeddedByValue()                    {}
 
@@ -684,6 +886,114 @@ func _K8SService_DeleteConfigMap_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _K8SService_ScaleWorkload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(K8SServiceServer).ScaleWorkload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: K8SService_ScaleWorkload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(K8SServiceServer).ScaleWorkload(ctx, req.(*ScaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _K8SService_RestartWorkload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(K8SServiceServer).RestartWorkload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: K8SService_RestartWorkload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(K8SServiceServer).RestartWorkload(ctx, req.(*RestartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _K8SService_GetDeploymentRolloutStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(K8SServiceServer).GetDeploymentRolloutStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: K8SService_GetDeploymentRolloutStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(K8SServiceServer).GetDeploymentRolloutStatus(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _K8SService_StreamRolloutStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RolloutStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(K8SServiceServer).StreamRolloutStatus(m, &grpc.GenericServerStream[RolloutStatusRequest, RolloutStatusResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_StreamRolloutStatusServer = grpc.ServerStreamingServer[RolloutStatusResponse]
+
+func _K8SService_ApplyYAML_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(K8SServiceServer).ApplyYAML(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: K8SService_ApplyYAML_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(K8SServiceServer).ApplyYAML(ctx, req.(*ApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _K8SService_ApplyYAMLStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(K8SServiceServer).ApplyYAMLStream(&grpc.GenericServerStream[ApplyRequest, ApplyResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_ApplyYAMLStreamServer = grpc.BidiStreamingServer[ApplyRequest, ApplyResult]
+
+func _K8SService_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(K8SServiceServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: K8SService_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(K8SServiceServer).GetVersion(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _K8SService_ListNamespaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -731,6 +1041,28 @@ func _K8SService_ExecPod_Handler(srv interface{}, stream grpc.ServerStream) erro
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type K8SService_ExecPodServer = grpc.ServerStreamingServer[ExecResponse]
 
+func _K8SService_WatchPods_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(K8SServiceServer).WatchPods(m, &grpc.GenericServerStream[WatchRequest, PodWatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_WatchPodsServer = grpc.ServerStreamingServer[PodWatchEvent]
+
+func _K8SService_WatchResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(K8SServiceServer).WatchResources(m, &grpc.GenericServerStream[WatchRequest, ResourceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type K8SService_WatchResourcesServer = grpc.ServerStreamingServer[ResourceEvent]
+
 // K8SService_ServiceDesc is the grpc.ServiceDesc for K8SService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -802,6 +1134,26 @@ var K8SService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteConfigMap",
 			Handler:    _K8SService_DeleteConfigMap_Handler,
 		},
+		{
+			MethodName: "ScaleWorkload",
+			Handler:    _K8SService_ScaleWorkload_Handler,
+		},
+		{
+			MethodName: "RestartWorkload",
+			Handler:    _K8SService_RestartWorkload_Handler,
+		},
+		{
+			MethodName: "GetDeploymentRolloutStatus",
+			Handler:    _K8SService_GetDeploymentRolloutStatus_Handler,
+		},
+		{
+			MethodName: "ApplyYAML",
+			Handler:    _K8SService_ApplyYAML_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _K8SService_GetVersion_Handler,
+		},
 		{
 			MethodName: "ListNamespaces",
 			Handler:    _K8SService_ListNamespaces_Handler,
@@ -812,11 +1164,32 @@ var K8SService_ServiceDesc = grpc.ServiceDesc{
 		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRolloutStatus",
+			Handler:       _K8SService_StreamRolloutStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ApplyYAMLStream",
+			Handler:       _K8SService_ApplyYAMLStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "ExecPod",
 			Handler:       _K8SService_ExecPod_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchPods",
+			Handler:       _K8SService_WatchPods_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchResources",
+			Handler:       _K8SService_WatchResources_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/k8s.proto",
 }